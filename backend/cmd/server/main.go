@@ -3,7 +3,6 @@ package main
 //go:generate go run github.com/google/wire/cmd/wire
 
 import (
-	"context"
 	_ "embed"
 	"errors"
 	"flag"
@@ -19,6 +18,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/server"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/setup"
 	"github.com/Wei-Shaw/sub2api/internal/web"
@@ -170,10 +170,8 @@ func runMainServer() {
 
 	log.Println("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := app.Server.Shutdown(ctx); err != nil {
+	grace := time.Duration(cfg.Server.ShutdownGraceSeconds) * time.Second
+	if err := server.GracefulShutdown(app.Server, app.Drainer, grace); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
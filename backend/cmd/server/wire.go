@@ -25,6 +25,7 @@ import (
 
 type Application struct {
 	Server  *http.Server
+	Drainer *server.RequestDrainer
 	Cleanup func()
 }
 
@@ -53,7 +54,7 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 		provideCleanup,
 
 		// Application struct
-		wire.Struct(new(Application), "Server", "Cleanup"),
+		wire.Struct(new(Application), "Server", "Drainer", "Cleanup"),
 	)
 	return nil, nil
 }
@@ -85,10 +86,12 @@ func provideCleanup(
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
+	auditLog *service.AuditLogService,
 	pricing *service.PricingService,
 	emailQueue *service.EmailQueueService,
 	billingCache *service.BillingCacheService,
 	usageRecordWorkerPool *service.UsageRecordWorkerPool,
+	apiKeyCapture *service.APIKeyCaptureService,
 	subscriptionService *service.SubscriptionService,
 	oauth *service.OAuthService,
 	openaiOAuth *service.OpenAIOAuthService,
@@ -99,8 +102,13 @@ func provideCleanup(
 	scheduledTestRunner *service.ScheduledTestRunnerService,
 	backupSvc *service.BackupService,
 	paymentOrderExpiry *service.PaymentOrderExpiryService,
+	accountHealthProbe *service.AccountHealthProbeService,
+	upstreamRateLimits *service.UpstreamRateLimitTracker,
+	logComponentLevels *service.LogComponentLevelService,
+	usageRecordRetry *service.UsageRecordRetryService,
 	channelMonitorRunner *service.ChannelMonitorRunner,
 	quotaFlusher *service.UserPlatformQuotaUsageFlusher,
+	concurrencyService *service.ConcurrencyService,
 ) func() {
 	return func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -167,6 +175,12 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"AuditLogService", func() error {
+				if auditLog != nil {
+					auditLog.Stop()
+				}
+				return nil
+			}},
 			{"TokenRefreshService", func() error {
 				tokenRefresh.Stop()
 				return nil
@@ -207,6 +221,12 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"APIKeyCaptureService", func() error {
+				if apiKeyCapture != nil {
+					apiKeyCapture.Stop()
+				}
+				return nil
+			}},
 			{"OAuthService", func() error {
 				oauth.Stop()
 				return nil
@@ -253,6 +273,30 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"AccountHealthProbeService", func() error {
+				if accountHealthProbe != nil {
+					accountHealthProbe.Stop()
+				}
+				return nil
+			}},
+			{"UpstreamRateLimitTracker", func() error {
+				if upstreamRateLimits != nil {
+					upstreamRateLimits.Stop()
+				}
+				return nil
+			}},
+			{"LogComponentLevelService", func() error {
+				if logComponentLevels != nil {
+					logComponentLevels.Stop()
+				}
+				return nil
+			}},
+			{"UsageRecordRetryService", func() error {
+				if usageRecordRetry != nil {
+					usageRecordRetry.Stop()
+				}
+				return nil
+			}},
 			{"ChannelMonitorRunner", func() error {
 				if channelMonitorRunner != nil {
 					channelMonitorRunner.Stop()
@@ -265,6 +309,12 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"ConcurrencyService", func() error {
+				if concurrencyService != nil {
+					concurrencyService.Stop()
+				}
+				return nil
+			}},
 		}
 
 		infraSteps := []cleanupStep{
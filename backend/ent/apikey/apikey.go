@@ -37,6 +37,16 @@ const (
 	FieldIPWhitelist = "ip_whitelist"
 	// FieldIPBlacklist holds the string denoting the ip_blacklist field in the database.
 	FieldIPBlacklist = "ip_blacklist"
+	// FieldAccountLabels holds the string denoting the account_labels field in the database.
+	FieldAccountLabels = "account_labels"
+	// FieldResponseCacheEnabled holds the string denoting the response_cache_enabled field in the database.
+	FieldResponseCacheEnabled = "response_cache_enabled"
+	// FieldCostPreviewEnabled holds the string denoting the cost_preview_enabled field in the database.
+	FieldCostPreviewEnabled = "cost_preview_enabled"
+	// FieldScopes holds the string denoting the scopes field in the database.
+	FieldScopes = "scopes"
+	// FieldAllowedModels holds the string denoting the allowed_models field in the database.
+	FieldAllowedModels = "allowed_models"
 	// FieldQuota holds the string denoting the quota field in the database.
 	FieldQuota = "quota"
 	// FieldQuotaUsed holds the string denoting the quota_used field in the database.
@@ -61,6 +71,8 @@ const (
 	FieldWindow1dStart = "window_1d_start"
 	// FieldWindow7dStart holds the string denoting the window_7d_start field in the database.
 	FieldWindow7dStart = "window_7d_start"
+	// FieldCaptureUntil holds the string denoting the capture_until field in the database.
+	FieldCaptureUntil = "capture_until"
 	// EdgeUser holds the string denoting the user edge name in mutations.
 	EdgeUser = "user"
 	// EdgeGroup holds the string denoting the group edge name in mutations.
@@ -106,6 +118,11 @@ var Columns = []string{
 	FieldLastUsedAt,
 	FieldIPWhitelist,
 	FieldIPBlacklist,
+	FieldAccountLabels,
+	FieldResponseCacheEnabled,
+	FieldCostPreviewEnabled,
+	FieldScopes,
+	FieldAllowedModels,
 	FieldQuota,
 	FieldQuotaUsed,
 	FieldExpiresAt,
@@ -118,6 +135,7 @@ var Columns = []string{
 	FieldWindow5hStart,
 	FieldWindow1dStart,
 	FieldWindow7dStart,
+	FieldCaptureUntil,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -152,6 +170,10 @@ var (
 	DefaultStatus string
 	// StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	StatusValidator func(string) error
+	// DefaultResponseCacheEnabled holds the default value on creation for the "response_cache_enabled" field.
+	DefaultResponseCacheEnabled bool
+	// DefaultCostPreviewEnabled holds the default value on creation for the "cost_preview_enabled" field.
+	DefaultCostPreviewEnabled bool
 	// DefaultQuota holds the default value on creation for the "quota" field.
 	DefaultQuota float64
 	// DefaultQuotaUsed holds the default value on creation for the "quota_used" field.
@@ -223,6 +245,16 @@ func ByLastUsedAt(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldLastUsedAt, opts...).ToFunc()
 }
 
+// ByResponseCacheEnabled orders the results by the response_cache_enabled field.
+func ByResponseCacheEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheEnabled, opts...).ToFunc()
+}
+
+// ByCostPreviewEnabled orders the results by the cost_preview_enabled field.
+func ByCostPreviewEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCostPreviewEnabled, opts...).ToFunc()
+}
+
 // ByQuota orders the results by the quota field.
 func ByQuota(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldQuota, opts...).ToFunc()
@@ -283,6 +315,11 @@ func ByWindow7dStart(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldWindow7dStart, opts...).ToFunc()
 }
 
+// ByCaptureUntil orders the results by the capture_until field.
+func ByCaptureUntil(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCaptureUntil, opts...).ToFunc()
+}
+
 // ByUserField orders the results by user field.
 func ByUserField(field string, opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
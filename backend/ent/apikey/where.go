@@ -100,6 +100,16 @@ func LastUsedAt(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldLastUsedAt, v))
 }
 
+// ResponseCacheEnabled applies equality check predicate on the "response_cache_enabled" field. It's identical to ResponseCacheEnabledEQ.
+func ResponseCacheEnabled(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// CostPreviewEnabled applies equality check predicate on the "cost_preview_enabled" field. It's identical to CostPreviewEnabledEQ.
+func CostPreviewEnabled(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldCostPreviewEnabled, v))
+}
+
 // Quota applies equality check predicate on the "quota" field. It's identical to QuotaEQ.
 func Quota(v float64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldQuota, v))
@@ -160,6 +170,11 @@ func Window7dStart(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldWindow7dStart, v))
 }
 
+// CaptureUntil applies equality check predicate on the "capture_until" field. It's identical to CaptureUntilEQ.
+func CaptureUntil(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldCaptureUntil, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldCreatedAt, v))
@@ -605,6 +620,56 @@ func IPBlacklistNotNil() predicate.APIKey {
 	return predicate.APIKey(sql.FieldNotNull(FieldIPBlacklist))
 }
 
+// AccountLabelsIsNil applies the IsNil predicate on the "account_labels" field.
+func AccountLabelsIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldAccountLabels))
+}
+
+// AccountLabelsNotNil applies the NotNil predicate on the "account_labels" field.
+func AccountLabelsNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldAccountLabels))
+}
+
+// ResponseCacheEnabledEQ applies the EQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheEnabledNEQ applies the NEQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldResponseCacheEnabled, v))
+}
+
+// CostPreviewEnabledEQ applies the EQ predicate on the "cost_preview_enabled" field.
+func CostPreviewEnabledEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldCostPreviewEnabled, v))
+}
+
+// CostPreviewEnabledNEQ applies the NEQ predicate on the "cost_preview_enabled" field.
+func CostPreviewEnabledNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldCostPreviewEnabled, v))
+}
+
+// ScopesIsNil applies the IsNil predicate on the "scopes" field.
+func ScopesIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldScopes))
+}
+
+// ScopesNotNil applies the NotNil predicate on the "scopes" field.
+func ScopesNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldScopes))
+}
+
+// AllowedModelsIsNil applies the IsNil predicate on the "allowed_models" field.
+func AllowedModelsIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldAllowedModels))
+}
+
+// AllowedModelsNotNil applies the NotNil predicate on the "allowed_models" field.
+func AllowedModelsNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldAllowedModels))
+}
+
 // QuotaEQ applies the EQ predicate on the "quota" field.
 func QuotaEQ(v float64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldQuota, v))
@@ -1125,6 +1190,56 @@ func Window7dStartNotNil() predicate.APIKey {
 	return predicate.APIKey(sql.FieldNotNull(FieldWindow7dStart))
 }
 
+// CaptureUntilEQ applies the EQ predicate on the "capture_until" field.
+func CaptureUntilEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldCaptureUntil, v))
+}
+
+// CaptureUntilNEQ applies the NEQ predicate on the "capture_until" field.
+func CaptureUntilNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldCaptureUntil, v))
+}
+
+// CaptureUntilIn applies the In predicate on the "capture_until" field.
+func CaptureUntilIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldCaptureUntil, vs...))
+}
+
+// CaptureUntilNotIn applies the NotIn predicate on the "capture_until" field.
+func CaptureUntilNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldCaptureUntil, vs...))
+}
+
+// CaptureUntilGT applies the GT predicate on the "capture_until" field.
+func CaptureUntilGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldCaptureUntil, v))
+}
+
+// CaptureUntilGTE applies the GTE predicate on the "capture_until" field.
+func CaptureUntilGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldCaptureUntil, v))
+}
+
+// CaptureUntilLT applies the LT predicate on the "capture_until" field.
+func CaptureUntilLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldCaptureUntil, v))
+}
+
+// CaptureUntilLTE applies the LTE predicate on the "capture_until" field.
+func CaptureUntilLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldCaptureUntil, v))
+}
+
+// CaptureUntilIsNil applies the IsNil predicate on the "capture_until" field.
+func CaptureUntilIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldCaptureUntil))
+}
+
+// CaptureUntilNotNil applies the NotNil predicate on the "capture_until" field.
+func CaptureUntilNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldCaptureUntil))
+}
+
 // HasUser applies the HasEdge predicate on the "user" edge.
 func HasUser() predicate.APIKey {
 	return predicate.APIKey(func(s *sql.Selector) {
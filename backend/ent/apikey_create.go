@@ -139,6 +139,52 @@ func (_c *APIKeyCreate) SetIPBlacklist(v []string) *APIKeyCreate {
 	return _c
 }
 
+// SetAccountLabels sets the "account_labels" field.
+func (_c *APIKeyCreate) SetAccountLabels(v []string) *APIKeyCreate {
+	_c.mutation.SetAccountLabels(v)
+	return _c
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_c *APIKeyCreate) SetResponseCacheEnabled(v bool) *APIKeyCreate {
+	_c.mutation.SetResponseCacheEnabled(v)
+	return _c
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableResponseCacheEnabled(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetResponseCacheEnabled(*v)
+	}
+	return _c
+}
+
+// SetCostPreviewEnabled sets the "cost_preview_enabled" field.
+func (_c *APIKeyCreate) SetCostPreviewEnabled(v bool) *APIKeyCreate {
+	_c.mutation.SetCostPreviewEnabled(v)
+	return _c
+}
+
+// SetNillableCostPreviewEnabled sets the "cost_preview_enabled" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableCostPreviewEnabled(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetCostPreviewEnabled(*v)
+	}
+	return _c
+}
+
+// SetScopes sets the "scopes" field.
+func (_c *APIKeyCreate) SetScopes(v []string) *APIKeyCreate {
+	_c.mutation.SetScopes(v)
+	return _c
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_c *APIKeyCreate) SetAllowedModels(v []string) *APIKeyCreate {
+	_c.mutation.SetAllowedModels(v)
+	return _c
+}
+
 // SetQuota sets the "quota" field.
 func (_c *APIKeyCreate) SetQuota(v float64) *APIKeyCreate {
 	_c.mutation.SetQuota(v)
@@ -307,6 +353,20 @@ func (_c *APIKeyCreate) SetNillableWindow7dStart(v *time.Time) *APIKeyCreate {
 	return _c
 }
 
+// SetCaptureUntil sets the "capture_until" field.
+func (_c *APIKeyCreate) SetCaptureUntil(v time.Time) *APIKeyCreate {
+	_c.mutation.SetCaptureUntil(v)
+	return _c
+}
+
+// SetNillableCaptureUntil sets the "capture_until" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableCaptureUntil(v *time.Time) *APIKeyCreate {
+	if v != nil {
+		_c.SetCaptureUntil(*v)
+	}
+	return _c
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_c *APIKeyCreate) SetUser(v *User) *APIKeyCreate {
 	return _c.SetUserID(v.ID)
@@ -387,6 +447,14 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultStatus
 		_c.mutation.SetStatus(v)
 	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		v := apikey.DefaultResponseCacheEnabled
+		_c.mutation.SetResponseCacheEnabled(v)
+	}
+	if _, ok := _c.mutation.CostPreviewEnabled(); !ok {
+		v := apikey.DefaultCostPreviewEnabled
+		_c.mutation.SetCostPreviewEnabled(v)
+	}
 	if _, ok := _c.mutation.Quota(); !ok {
 		v := apikey.DefaultQuota
 		_c.mutation.SetQuota(v)
@@ -457,6 +525,12 @@ func (_c *APIKeyCreate) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		return &ValidationError{Name: "response_cache_enabled", err: errors.New(`ent: missing required field "APIKey.response_cache_enabled"`)}
+	}
+	if _, ok := _c.mutation.CostPreviewEnabled(); !ok {
+		return &ValidationError{Name: "cost_preview_enabled", err: errors.New(`ent: missing required field "APIKey.cost_preview_enabled"`)}
+	}
 	if _, ok := _c.mutation.Quota(); !ok {
 		return &ValidationError{Name: "quota", err: errors.New(`ent: missing required field "APIKey.quota"`)}
 	}
@@ -547,6 +621,26 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldIPBlacklist, field.TypeJSON, value)
 		_node.IPBlacklist = value
 	}
+	if value, ok := _c.mutation.AccountLabels(); ok {
+		_spec.SetField(apikey.FieldAccountLabels, field.TypeJSON, value)
+		_node.AccountLabels = value
+	}
+	if value, ok := _c.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(apikey.FieldResponseCacheEnabled, field.TypeBool, value)
+		_node.ResponseCacheEnabled = value
+	}
+	if value, ok := _c.mutation.CostPreviewEnabled(); ok {
+		_spec.SetField(apikey.FieldCostPreviewEnabled, field.TypeBool, value)
+		_node.CostPreviewEnabled = value
+	}
+	if value, ok := _c.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+		_node.Scopes = value
+	}
+	if value, ok := _c.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+		_node.AllowedModels = value
+	}
 	if value, ok := _c.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 		_node.Quota = value
@@ -595,6 +689,10 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldWindow7dStart, field.TypeTime, value)
 		_node.Window7dStart = &value
 	}
+	if value, ok := _c.mutation.CaptureUntil(); ok {
+		_spec.SetField(apikey.FieldCaptureUntil, field.TypeTime, value)
+		_node.CaptureUntil = &value
+	}
 	if nodes := _c.mutation.UserIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -847,6 +945,84 @@ func (u *APIKeyUpsert) ClearIPBlacklist() *APIKeyUpsert {
 	return u
 }
 
+// SetAccountLabels sets the "account_labels" field.
+func (u *APIKeyUpsert) SetAccountLabels(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldAccountLabels, v)
+	return u
+}
+
+// UpdateAccountLabels sets the "account_labels" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAccountLabels() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAccountLabels)
+	return u
+}
+
+// ClearAccountLabels clears the value of the "account_labels" field.
+func (u *APIKeyUpsert) ClearAccountLabels() *APIKeyUpsert {
+	u.SetNull(apikey.FieldAccountLabels)
+	return u
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *APIKeyUpsert) SetResponseCacheEnabled(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldResponseCacheEnabled, v)
+	return u
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateResponseCacheEnabled() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldResponseCacheEnabled)
+	return u
+}
+
+// SetCostPreviewEnabled sets the "cost_preview_enabled" field.
+func (u *APIKeyUpsert) SetCostPreviewEnabled(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldCostPreviewEnabled, v)
+	return u
+}
+
+// UpdateCostPreviewEnabled sets the "cost_preview_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateCostPreviewEnabled() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldCostPreviewEnabled)
+	return u
+}
+
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsert) SetScopes(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldScopes, v)
+	return u
+}
+
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateScopes() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldScopes)
+	return u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsert) ClearScopes() *APIKeyUpsert {
+	u.SetNull(apikey.FieldScopes)
+	return u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsert) SetAllowedModels(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldAllowedModels, v)
+	return u
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAllowedModels() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAllowedModels)
+	return u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsert) ClearAllowedModels() *APIKeyUpsert {
+	u.SetNull(apikey.FieldAllowedModels)
+	return u
+}
+
 // SetQuota sets the "quota" field.
 func (u *APIKeyUpsert) SetQuota(v float64) *APIKeyUpsert {
 	u.Set(apikey.FieldQuota, v)
@@ -1063,6 +1239,24 @@ func (u *APIKeyUpsert) ClearWindow7dStart() *APIKeyUpsert {
 	return u
 }
 
+// SetCaptureUntil sets the "capture_until" field.
+func (u *APIKeyUpsert) SetCaptureUntil(v time.Time) *APIKeyUpsert {
+	u.Set(apikey.FieldCaptureUntil, v)
+	return u
+}
+
+// UpdateCaptureUntil sets the "capture_until" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateCaptureUntil() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldCaptureUntil)
+	return u
+}
+
+// ClearCaptureUntil clears the value of the "capture_until" field.
+func (u *APIKeyUpsert) ClearCaptureUntil() *APIKeyUpsert {
+	u.SetNull(apikey.FieldCaptureUntil)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1283,6 +1477,97 @@ func (u *APIKeyUpsertOne) ClearIPBlacklist() *APIKeyUpsertOne {
 	})
 }
 
+// SetAccountLabels sets the "account_labels" field.
+func (u *APIKeyUpsertOne) SetAccountLabels(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAccountLabels(v)
+	})
+}
+
+// UpdateAccountLabels sets the "account_labels" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAccountLabels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAccountLabels()
+	})
+}
+
+// ClearAccountLabels clears the value of the "account_labels" field.
+func (u *APIKeyUpsertOne) ClearAccountLabels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAccountLabels()
+	})
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *APIKeyUpsertOne) SetResponseCacheEnabled(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateResponseCacheEnabled() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetCostPreviewEnabled sets the "cost_preview_enabled" field.
+func (u *APIKeyUpsertOne) SetCostPreviewEnabled(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetCostPreviewEnabled(v)
+	})
+}
+
+// UpdateCostPreviewEnabled sets the "cost_preview_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateCostPreviewEnabled() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateCostPreviewEnabled()
+	})
+}
+
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsertOne) SetScopes(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetScopes(v)
+	})
+}
+
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateScopes() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateScopes()
+	})
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsertOne) ClearScopes() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearScopes()
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertOne) SetAllowedModels(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsertOne) ClearAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAllowedModels()
+	})
+}
+
 // SetQuota sets the "quota" field.
 func (u *APIKeyUpsertOne) SetQuota(v float64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
@@ -1535,6 +1820,27 @@ func (u *APIKeyUpsertOne) ClearWindow7dStart() *APIKeyUpsertOne {
 	})
 }
 
+// SetCaptureUntil sets the "capture_until" field.
+func (u *APIKeyUpsertOne) SetCaptureUntil(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetCaptureUntil(v)
+	})
+}
+
+// UpdateCaptureUntil sets the "capture_until" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateCaptureUntil() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateCaptureUntil()
+	})
+}
+
+// ClearCaptureUntil clears the value of the "capture_until" field.
+func (u *APIKeyUpsertOne) ClearCaptureUntil() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearCaptureUntil()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1921,6 +2227,97 @@ func (u *APIKeyUpsertBulk) ClearIPBlacklist() *APIKeyUpsertBulk {
 	})
 }
 
+// SetAccountLabels sets the "account_labels" field.
+func (u *APIKeyUpsertBulk) SetAccountLabels(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAccountLabels(v)
+	})
+}
+
+// UpdateAccountLabels sets the "account_labels" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAccountLabels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAccountLabels()
+	})
+}
+
+// ClearAccountLabels clears the value of the "account_labels" field.
+func (u *APIKeyUpsertBulk) ClearAccountLabels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAccountLabels()
+	})
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *APIKeyUpsertBulk) SetResponseCacheEnabled(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateResponseCacheEnabled() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetCostPreviewEnabled sets the "cost_preview_enabled" field.
+func (u *APIKeyUpsertBulk) SetCostPreviewEnabled(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetCostPreviewEnabled(v)
+	})
+}
+
+// UpdateCostPreviewEnabled sets the "cost_preview_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateCostPreviewEnabled() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateCostPreviewEnabled()
+	})
+}
+
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsertBulk) SetScopes(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetScopes(v)
+	})
+}
+
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateScopes() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateScopes()
+	})
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsertBulk) ClearScopes() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearScopes()
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertBulk) SetAllowedModels(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAllowedModels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsertBulk) ClearAllowedModels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAllowedModels()
+	})
+}
+
 // SetQuota sets the "quota" field.
 func (u *APIKeyUpsertBulk) SetQuota(v float64) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
@@ -2173,6 +2570,27 @@ func (u *APIKeyUpsertBulk) ClearWindow7dStart() *APIKeyUpsertBulk {
 	})
 }
 
+// SetCaptureUntil sets the "capture_until" field.
+func (u *APIKeyUpsertBulk) SetCaptureUntil(v time.Time) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetCaptureUntil(v)
+	})
+}
+
+// UpdateCaptureUntil sets the "capture_until" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateCaptureUntil() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateCaptureUntil()
+	})
+}
+
+// ClearCaptureUntil clears the value of the "capture_until" field.
+func (u *APIKeyUpsertBulk) ClearCaptureUntil() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearCaptureUntil()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
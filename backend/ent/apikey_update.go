@@ -190,6 +190,88 @@ func (_u *APIKeyUpdate) ClearIPBlacklist() *APIKeyUpdate {
 	return _u
 }
 
+// SetAccountLabels sets the "account_labels" field.
+func (_u *APIKeyUpdate) SetAccountLabels(v []string) *APIKeyUpdate {
+	_u.mutation.SetAccountLabels(v)
+	return _u
+}
+
+// AppendAccountLabels appends value to the "account_labels" field.
+func (_u *APIKeyUpdate) AppendAccountLabels(v []string) *APIKeyUpdate {
+	_u.mutation.AppendAccountLabels(v)
+	return _u
+}
+
+// ClearAccountLabels clears the value of the "account_labels" field.
+func (_u *APIKeyUpdate) ClearAccountLabels() *APIKeyUpdate {
+	_u.mutation.ClearAccountLabels()
+	return _u
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *APIKeyUpdate) SetResponseCacheEnabled(v bool) *APIKeyUpdate {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableResponseCacheEnabled(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetCostPreviewEnabled sets the "cost_preview_enabled" field.
+func (_u *APIKeyUpdate) SetCostPreviewEnabled(v bool) *APIKeyUpdate {
+	_u.mutation.SetCostPreviewEnabled(v)
+	return _u
+}
+
+// SetNillableCostPreviewEnabled sets the "cost_preview_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableCostPreviewEnabled(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetCostPreviewEnabled(*v)
+	}
+	return _u
+}
+
+// SetScopes sets the "scopes" field.
+func (_u *APIKeyUpdate) SetScopes(v []string) *APIKeyUpdate {
+	_u.mutation.SetScopes(v)
+	return _u
+}
+
+// AppendScopes appends value to the "scopes" field.
+func (_u *APIKeyUpdate) AppendScopes(v []string) *APIKeyUpdate {
+	_u.mutation.AppendScopes(v)
+	return _u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (_u *APIKeyUpdate) ClearScopes() *APIKeyUpdate {
+	_u.mutation.ClearScopes()
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdate) SetAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdate) AppendAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (_u *APIKeyUpdate) ClearAllowedModels() *APIKeyUpdate {
+	_u.mutation.ClearAllowedModels()
+	return _u
+}
+
 // SetQuota sets the "quota" field.
 func (_u *APIKeyUpdate) SetQuota(v float64) *APIKeyUpdate {
 	_u.mutation.ResetQuota()
@@ -438,6 +520,26 @@ func (_u *APIKeyUpdate) ClearWindow7dStart() *APIKeyUpdate {
 	return _u
 }
 
+// SetCaptureUntil sets the "capture_until" field.
+func (_u *APIKeyUpdate) SetCaptureUntil(v time.Time) *APIKeyUpdate {
+	_u.mutation.SetCaptureUntil(v)
+	return _u
+}
+
+// SetNillableCaptureUntil sets the "capture_until" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableCaptureUntil(v *time.Time) *APIKeyUpdate {
+	if v != nil {
+		_u.SetCaptureUntil(*v)
+	}
+	return _u
+}
+
+// ClearCaptureUntil clears the value of the "capture_until" field.
+func (_u *APIKeyUpdate) ClearCaptureUntil() *APIKeyUpdate {
+	_u.mutation.ClearCaptureUntil()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdate) SetUser(v *User) *APIKeyUpdate {
 	return _u.SetUserID(v.ID)
@@ -624,6 +726,45 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.IPBlacklistCleared() {
 		_spec.ClearField(apikey.FieldIPBlacklist, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.AccountLabels(); ok {
+		_spec.SetField(apikey.FieldAccountLabels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAccountLabels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAccountLabels, value)
+		})
+	}
+	if _u.mutation.AccountLabelsCleared() {
+		_spec.ClearField(apikey.FieldAccountLabels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(apikey.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.CostPreviewEnabled(); ok {
+		_spec.SetField(apikey.FieldCostPreviewEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedScopes(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldScopes, value)
+		})
+	}
+	if _u.mutation.ScopesCleared() {
+		_spec.ClearField(apikey.FieldScopes, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
+	if _u.mutation.AllowedModelsCleared() {
+		_spec.ClearField(apikey.FieldAllowedModels, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 	}
@@ -696,6 +837,12 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.CaptureUntil(); ok {
+		_spec.SetField(apikey.FieldCaptureUntil, field.TypeTime, value)
+	}
+	if _u.mutation.CaptureUntilCleared() {
+		_spec.ClearField(apikey.FieldCaptureUntil, field.TypeTime)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -977,6 +1124,88 @@ func (_u *APIKeyUpdateOne) ClearIPBlacklist() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetAccountLabels sets the "account_labels" field.
+func (_u *APIKeyUpdateOne) SetAccountLabels(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetAccountLabels(v)
+	return _u
+}
+
+// AppendAccountLabels appends value to the "account_labels" field.
+func (_u *APIKeyUpdateOne) AppendAccountLabels(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendAccountLabels(v)
+	return _u
+}
+
+// ClearAccountLabels clears the value of the "account_labels" field.
+func (_u *APIKeyUpdateOne) ClearAccountLabels() *APIKeyUpdateOne {
+	_u.mutation.ClearAccountLabels()
+	return _u
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *APIKeyUpdateOne) SetResponseCacheEnabled(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableResponseCacheEnabled(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetCostPreviewEnabled sets the "cost_preview_enabled" field.
+func (_u *APIKeyUpdateOne) SetCostPreviewEnabled(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetCostPreviewEnabled(v)
+	return _u
+}
+
+// SetNillableCostPreviewEnabled sets the "cost_preview_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableCostPreviewEnabled(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetCostPreviewEnabled(*v)
+	}
+	return _u
+}
+
+// SetScopes sets the "scopes" field.
+func (_u *APIKeyUpdateOne) SetScopes(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetScopes(v)
+	return _u
+}
+
+// AppendScopes appends value to the "scopes" field.
+func (_u *APIKeyUpdateOne) AppendScopes(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendScopes(v)
+	return _u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (_u *APIKeyUpdateOne) ClearScopes() *APIKeyUpdateOne {
+	_u.mutation.ClearScopes()
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdateOne) SetAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdateOne) AppendAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (_u *APIKeyUpdateOne) ClearAllowedModels() *APIKeyUpdateOne {
+	_u.mutation.ClearAllowedModels()
+	return _u
+}
+
 // SetQuota sets the "quota" field.
 func (_u *APIKeyUpdateOne) SetQuota(v float64) *APIKeyUpdateOne {
 	_u.mutation.ResetQuota()
@@ -1225,6 +1454,26 @@ func (_u *APIKeyUpdateOne) ClearWindow7dStart() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetCaptureUntil sets the "capture_until" field.
+func (_u *APIKeyUpdateOne) SetCaptureUntil(v time.Time) *APIKeyUpdateOne {
+	_u.mutation.SetCaptureUntil(v)
+	return _u
+}
+
+// SetNillableCaptureUntil sets the "capture_until" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableCaptureUntil(v *time.Time) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetCaptureUntil(*v)
+	}
+	return _u
+}
+
+// ClearCaptureUntil clears the value of the "capture_until" field.
+func (_u *APIKeyUpdateOne) ClearCaptureUntil() *APIKeyUpdateOne {
+	_u.mutation.ClearCaptureUntil()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdateOne) SetUser(v *User) *APIKeyUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -1441,6 +1690,45 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.IPBlacklistCleared() {
 		_spec.ClearField(apikey.FieldIPBlacklist, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.AccountLabels(); ok {
+		_spec.SetField(apikey.FieldAccountLabels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAccountLabels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAccountLabels, value)
+		})
+	}
+	if _u.mutation.AccountLabelsCleared() {
+		_spec.ClearField(apikey.FieldAccountLabels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(apikey.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.CostPreviewEnabled(); ok {
+		_spec.SetField(apikey.FieldCostPreviewEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedScopes(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldScopes, value)
+		})
+	}
+	if _u.mutation.ScopesCleared() {
+		_spec.ClearField(apikey.FieldScopes, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
+	if _u.mutation.AllowedModelsCleared() {
+		_spec.ClearField(apikey.FieldAllowedModels, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 	}
@@ -1513,6 +1801,12 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.CaptureUntil(); ok {
+		_spec.SetField(apikey.FieldCaptureUntil, field.TypeTime, value)
+	}
+	if _u.mutation.CaptureUntilCleared() {
+		_spec.ClearField(apikey.FieldCaptureUntil, field.TypeTime)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
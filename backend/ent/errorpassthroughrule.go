@@ -47,8 +47,12 @@ type ErrorPassthroughRule struct {
 	// SkipMonitoring holds the value of the "skip_monitoring" field.
 	SkipMonitoring bool `json:"skip_monitoring,omitempty"`
 	// Description holds the value of the "description" field.
-	Description  *string `json:"description,omitempty"`
-	selectValues sql.SelectValues
+	Description *string `json:"description,omitempty"`
+	// BodyRegex holds the value of the "body_regex" field.
+	BodyRegex *string `json:"body_regex,omitempty"`
+	// HeaderMatchers holds the value of the "header_matchers" field.
+	HeaderMatchers []map[string]string `json:"header_matchers,omitempty"`
+	selectValues   sql.SelectValues
 }
 
 // scanValues returns the types for scanning values from sql.Rows.
@@ -56,13 +60,13 @@ func (*ErrorPassthroughRule) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case errorpassthroughrule.FieldErrorCodes, errorpassthroughrule.FieldKeywords, errorpassthroughrule.FieldPlatforms:
+		case errorpassthroughrule.FieldErrorCodes, errorpassthroughrule.FieldKeywords, errorpassthroughrule.FieldPlatforms, errorpassthroughrule.FieldHeaderMatchers:
 			values[i] = new([]byte)
 		case errorpassthroughrule.FieldEnabled, errorpassthroughrule.FieldPassthroughCode, errorpassthroughrule.FieldPassthroughBody, errorpassthroughrule.FieldSkipMonitoring:
 			values[i] = new(sql.NullBool)
 		case errorpassthroughrule.FieldID, errorpassthroughrule.FieldPriority, errorpassthroughrule.FieldResponseCode:
 			values[i] = new(sql.NullInt64)
-		case errorpassthroughrule.FieldName, errorpassthroughrule.FieldMatchMode, errorpassthroughrule.FieldCustomMessage, errorpassthroughrule.FieldDescription:
+		case errorpassthroughrule.FieldName, errorpassthroughrule.FieldMatchMode, errorpassthroughrule.FieldCustomMessage, errorpassthroughrule.FieldDescription, errorpassthroughrule.FieldBodyRegex:
 			values[i] = new(sql.NullString)
 		case errorpassthroughrule.FieldCreatedAt, errorpassthroughrule.FieldUpdatedAt:
 			values[i] = new(sql.NullTime)
@@ -186,6 +190,21 @@ func (_m *ErrorPassthroughRule) assignValues(columns []string, values []any) err
 				_m.Description = new(string)
 				*_m.Description = value.String
 			}
+		case errorpassthroughrule.FieldBodyRegex:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field body_regex", values[i])
+			} else if value.Valid {
+				_m.BodyRegex = new(string)
+				*_m.BodyRegex = value.String
+			}
+		case errorpassthroughrule.FieldHeaderMatchers:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field header_matchers", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.HeaderMatchers); err != nil {
+					return fmt.Errorf("unmarshal field header_matchers: %w", err)
+				}
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -272,6 +291,14 @@ func (_m *ErrorPassthroughRule) String() string {
 		builder.WriteString("description=")
 		builder.WriteString(*v)
 	}
+	builder.WriteString(", ")
+	if v := _m.BodyRegex; v != nil {
+		builder.WriteString("body_regex=")
+		builder.WriteString(*v)
+	}
+	builder.WriteString(", ")
+	builder.WriteString("header_matchers=")
+	builder.WriteString(fmt.Sprintf("%v", _m.HeaderMatchers))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -43,6 +43,10 @@ const (
 	FieldSkipMonitoring = "skip_monitoring"
 	// FieldDescription holds the string denoting the description field in the database.
 	FieldDescription = "description"
+	// FieldBodyRegex holds the string denoting the body_regex field in the database.
+	FieldBodyRegex = "body_regex"
+	// FieldHeaderMatchers holds the string denoting the header_matchers field in the database.
+	FieldHeaderMatchers = "header_matchers"
 	// Table holds the table name of the errorpassthroughrule in the database.
 	Table = "error_passthrough_rules"
 )
@@ -65,6 +69,8 @@ var Columns = []string{
 	FieldCustomMessage,
 	FieldSkipMonitoring,
 	FieldDescription,
+	FieldBodyRegex,
+	FieldHeaderMatchers,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -169,3 +175,8 @@ func BySkipMonitoring(opts ...sql.OrderTermOption) OrderOption {
 func ByDescription(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldDescription, opts...).ToFunc()
 }
+
+// ByBodyRegex orders the results by the body_regex field.
+func ByBodyRegex(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBodyRegex, opts...).ToFunc()
+}
@@ -114,6 +114,11 @@ func Description(v string) predicate.ErrorPassthroughRule {
 	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldDescription, v))
 }
 
+// BodyRegex applies equality check predicate on the "body_regex" field. It's identical to BodyRegexEQ.
+func BodyRegex(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldBodyRegex, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.ErrorPassthroughRule {
 	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldCreatedAt, v))
@@ -634,6 +639,91 @@ func DescriptionContainsFold(v string) predicate.ErrorPassthroughRule {
 	return predicate.ErrorPassthroughRule(sql.FieldContainsFold(FieldDescription, v))
 }
 
+// BodyRegexEQ applies the EQ predicate on the "body_regex" field.
+func BodyRegexEQ(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldBodyRegex, v))
+}
+
+// BodyRegexNEQ applies the NEQ predicate on the "body_regex" field.
+func BodyRegexNEQ(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNEQ(FieldBodyRegex, v))
+}
+
+// BodyRegexIn applies the In predicate on the "body_regex" field.
+func BodyRegexIn(vs ...string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldIn(FieldBodyRegex, vs...))
+}
+
+// BodyRegexNotIn applies the NotIn predicate on the "body_regex" field.
+func BodyRegexNotIn(vs ...string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNotIn(FieldBodyRegex, vs...))
+}
+
+// BodyRegexGT applies the GT predicate on the "body_regex" field.
+func BodyRegexGT(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldGT(FieldBodyRegex, v))
+}
+
+// BodyRegexGTE applies the GTE predicate on the "body_regex" field.
+func BodyRegexGTE(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldGTE(FieldBodyRegex, v))
+}
+
+// BodyRegexLT applies the LT predicate on the "body_regex" field.
+func BodyRegexLT(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldLT(FieldBodyRegex, v))
+}
+
+// BodyRegexLTE applies the LTE predicate on the "body_regex" field.
+func BodyRegexLTE(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldLTE(FieldBodyRegex, v))
+}
+
+// BodyRegexContains applies the Contains predicate on the "body_regex" field.
+func BodyRegexContains(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldContains(FieldBodyRegex, v))
+}
+
+// BodyRegexHasPrefix applies the HasPrefix predicate on the "body_regex" field.
+func BodyRegexHasPrefix(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldHasPrefix(FieldBodyRegex, v))
+}
+
+// BodyRegexHasSuffix applies the HasSuffix predicate on the "body_regex" field.
+func BodyRegexHasSuffix(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldHasSuffix(FieldBodyRegex, v))
+}
+
+// BodyRegexIsNil applies the IsNil predicate on the "body_regex" field.
+func BodyRegexIsNil() predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldIsNull(FieldBodyRegex))
+}
+
+// BodyRegexNotNil applies the NotNil predicate on the "body_regex" field.
+func BodyRegexNotNil() predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNotNull(FieldBodyRegex))
+}
+
+// BodyRegexEqualFold applies the EqualFold predicate on the "body_regex" field.
+func BodyRegexEqualFold(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEqualFold(FieldBodyRegex, v))
+}
+
+// BodyRegexContainsFold applies the ContainsFold predicate on the "body_regex" field.
+func BodyRegexContainsFold(v string) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldContainsFold(FieldBodyRegex, v))
+}
+
+// HeaderMatchersIsNil applies the IsNil predicate on the "header_matchers" field.
+func HeaderMatchersIsNil() predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldIsNull(FieldHeaderMatchers))
+}
+
+// HeaderMatchersNotNil applies the NotNil predicate on the "header_matchers" field.
+func HeaderMatchersNotNil() predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNotNull(FieldHeaderMatchers))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.ErrorPassthroughRule) predicate.ErrorPassthroughRule {
 	return predicate.ErrorPassthroughRule(sql.AndPredicates(predicates...))
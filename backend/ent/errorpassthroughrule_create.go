@@ -200,6 +200,26 @@ func (_c *ErrorPassthroughRuleCreate) SetNillableDescription(v *string) *ErrorPa
 	return _c
 }
 
+// SetBodyRegex sets the "body_regex" field.
+func (_c *ErrorPassthroughRuleCreate) SetBodyRegex(v string) *ErrorPassthroughRuleCreate {
+	_c.mutation.SetBodyRegex(v)
+	return _c
+}
+
+// SetNillableBodyRegex sets the "body_regex" field if the given value is not nil.
+func (_c *ErrorPassthroughRuleCreate) SetNillableBodyRegex(v *string) *ErrorPassthroughRuleCreate {
+	if v != nil {
+		_c.SetBodyRegex(*v)
+	}
+	return _c
+}
+
+// SetHeaderMatchers sets the "header_matchers" field.
+func (_c *ErrorPassthroughRuleCreate) SetHeaderMatchers(v []map[string]string) *ErrorPassthroughRuleCreate {
+	_c.mutation.SetHeaderMatchers(v)
+	return _c
+}
+
 // Mutation returns the ErrorPassthroughRuleMutation object of the builder.
 func (_c *ErrorPassthroughRuleCreate) Mutation() *ErrorPassthroughRuleMutation {
 	return _c.mutation
@@ -395,6 +415,14 @@ func (_c *ErrorPassthroughRuleCreate) createSpec() (*ErrorPassthroughRule, *sqlg
 		_spec.SetField(errorpassthroughrule.FieldDescription, field.TypeString, value)
 		_node.Description = &value
 	}
+	if value, ok := _c.mutation.BodyRegex(); ok {
+		_spec.SetField(errorpassthroughrule.FieldBodyRegex, field.TypeString, value)
+		_node.BodyRegex = &value
+	}
+	if value, ok := _c.mutation.HeaderMatchers(); ok {
+		_spec.SetField(errorpassthroughrule.FieldHeaderMatchers, field.TypeJSON, value)
+		_node.HeaderMatchers = value
+	}
 	return _node, _spec
 }
 
@@ -663,6 +691,42 @@ func (u *ErrorPassthroughRuleUpsert) ClearDescription() *ErrorPassthroughRuleUps
 	return u
 }
 
+// SetBodyRegex sets the "body_regex" field.
+func (u *ErrorPassthroughRuleUpsert) SetBodyRegex(v string) *ErrorPassthroughRuleUpsert {
+	u.Set(errorpassthroughrule.FieldBodyRegex, v)
+	return u
+}
+
+// UpdateBodyRegex sets the "body_regex" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsert) UpdateBodyRegex() *ErrorPassthroughRuleUpsert {
+	u.SetExcluded(errorpassthroughrule.FieldBodyRegex)
+	return u
+}
+
+// ClearBodyRegex clears the value of the "body_regex" field.
+func (u *ErrorPassthroughRuleUpsert) ClearBodyRegex() *ErrorPassthroughRuleUpsert {
+	u.SetNull(errorpassthroughrule.FieldBodyRegex)
+	return u
+}
+
+// SetHeaderMatchers sets the "header_matchers" field.
+func (u *ErrorPassthroughRuleUpsert) SetHeaderMatchers(v []map[string]string) *ErrorPassthroughRuleUpsert {
+	u.Set(errorpassthroughrule.FieldHeaderMatchers, v)
+	return u
+}
+
+// UpdateHeaderMatchers sets the "header_matchers" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsert) UpdateHeaderMatchers() *ErrorPassthroughRuleUpsert {
+	u.SetExcluded(errorpassthroughrule.FieldHeaderMatchers)
+	return u
+}
+
+// ClearHeaderMatchers clears the value of the "header_matchers" field.
+func (u *ErrorPassthroughRuleUpsert) ClearHeaderMatchers() *ErrorPassthroughRuleUpsert {
+	u.SetNull(errorpassthroughrule.FieldHeaderMatchers)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -960,6 +1024,48 @@ func (u *ErrorPassthroughRuleUpsertOne) ClearDescription() *ErrorPassthroughRule
 	})
 }
 
+// SetBodyRegex sets the "body_regex" field.
+func (u *ErrorPassthroughRuleUpsertOne) SetBodyRegex(v string) *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetBodyRegex(v)
+	})
+}
+
+// UpdateBodyRegex sets the "body_regex" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertOne) UpdateBodyRegex() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateBodyRegex()
+	})
+}
+
+// ClearBodyRegex clears the value of the "body_regex" field.
+func (u *ErrorPassthroughRuleUpsertOne) ClearBodyRegex() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.ClearBodyRegex()
+	})
+}
+
+// SetHeaderMatchers sets the "header_matchers" field.
+func (u *ErrorPassthroughRuleUpsertOne) SetHeaderMatchers(v []map[string]string) *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetHeaderMatchers(v)
+	})
+}
+
+// UpdateHeaderMatchers sets the "header_matchers" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertOne) UpdateHeaderMatchers() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateHeaderMatchers()
+	})
+}
+
+// ClearHeaderMatchers clears the value of the "header_matchers" field.
+func (u *ErrorPassthroughRuleUpsertOne) ClearHeaderMatchers() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.ClearHeaderMatchers()
+	})
+}
+
 // Exec executes the query.
 func (u *ErrorPassthroughRuleUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1423,6 +1529,48 @@ func (u *ErrorPassthroughRuleUpsertBulk) ClearDescription() *ErrorPassthroughRul
 	})
 }
 
+// SetBodyRegex sets the "body_regex" field.
+func (u *ErrorPassthroughRuleUpsertBulk) SetBodyRegex(v string) *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetBodyRegex(v)
+	})
+}
+
+// UpdateBodyRegex sets the "body_regex" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertBulk) UpdateBodyRegex() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateBodyRegex()
+	})
+}
+
+// ClearBodyRegex clears the value of the "body_regex" field.
+func (u *ErrorPassthroughRuleUpsertBulk) ClearBodyRegex() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.ClearBodyRegex()
+	})
+}
+
+// SetHeaderMatchers sets the "header_matchers" field.
+func (u *ErrorPassthroughRuleUpsertBulk) SetHeaderMatchers(v []map[string]string) *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetHeaderMatchers(v)
+	})
+}
+
+// UpdateHeaderMatchers sets the "header_matchers" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertBulk) UpdateHeaderMatchers() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateHeaderMatchers()
+	})
+}
+
+// ClearHeaderMatchers clears the value of the "header_matchers" field.
+func (u *ErrorPassthroughRuleUpsertBulk) ClearHeaderMatchers() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.ClearHeaderMatchers()
+	})
+}
+
 // Exec executes the query.
 func (u *ErrorPassthroughRuleUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -261,6 +261,44 @@ func (_u *ErrorPassthroughRuleUpdate) ClearDescription() *ErrorPassthroughRuleUp
 	return _u
 }
 
+// SetBodyRegex sets the "body_regex" field.
+func (_u *ErrorPassthroughRuleUpdate) SetBodyRegex(v string) *ErrorPassthroughRuleUpdate {
+	_u.mutation.SetBodyRegex(v)
+	return _u
+}
+
+// SetNillableBodyRegex sets the "body_regex" field if the given value is not nil.
+func (_u *ErrorPassthroughRuleUpdate) SetNillableBodyRegex(v *string) *ErrorPassthroughRuleUpdate {
+	if v != nil {
+		_u.SetBodyRegex(*v)
+	}
+	return _u
+}
+
+// ClearBodyRegex clears the value of the "body_regex" field.
+func (_u *ErrorPassthroughRuleUpdate) ClearBodyRegex() *ErrorPassthroughRuleUpdate {
+	_u.mutation.ClearBodyRegex()
+	return _u
+}
+
+// SetHeaderMatchers sets the "header_matchers" field.
+func (_u *ErrorPassthroughRuleUpdate) SetHeaderMatchers(v []map[string]string) *ErrorPassthroughRuleUpdate {
+	_u.mutation.SetHeaderMatchers(v)
+	return _u
+}
+
+// AppendHeaderMatchers appends value to the "header_matchers" field.
+func (_u *ErrorPassthroughRuleUpdate) AppendHeaderMatchers(v []map[string]string) *ErrorPassthroughRuleUpdate {
+	_u.mutation.AppendHeaderMatchers(v)
+	return _u
+}
+
+// ClearHeaderMatchers clears the value of the "header_matchers" field.
+func (_u *ErrorPassthroughRuleUpdate) ClearHeaderMatchers() *ErrorPassthroughRuleUpdate {
+	_u.mutation.ClearHeaderMatchers()
+	return _u
+}
+
 // Mutation returns the ErrorPassthroughRuleMutation object of the builder.
 func (_u *ErrorPassthroughRuleUpdate) Mutation() *ErrorPassthroughRuleMutation {
 	return _u.mutation
@@ -410,6 +448,23 @@ func (_u *ErrorPassthroughRuleUpdate) sqlSave(ctx context.Context) (_node int, e
 	if _u.mutation.DescriptionCleared() {
 		_spec.ClearField(errorpassthroughrule.FieldDescription, field.TypeString)
 	}
+	if value, ok := _u.mutation.BodyRegex(); ok {
+		_spec.SetField(errorpassthroughrule.FieldBodyRegex, field.TypeString, value)
+	}
+	if _u.mutation.BodyRegexCleared() {
+		_spec.ClearField(errorpassthroughrule.FieldBodyRegex, field.TypeString)
+	}
+	if value, ok := _u.mutation.HeaderMatchers(); ok {
+		_spec.SetField(errorpassthroughrule.FieldHeaderMatchers, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedHeaderMatchers(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, errorpassthroughrule.FieldHeaderMatchers, value)
+		})
+	}
+	if _u.mutation.HeaderMatchersCleared() {
+		_spec.ClearField(errorpassthroughrule.FieldHeaderMatchers, field.TypeJSON)
+	}
 	if _node, err = sqlgraph.UpdateNodes(ctx, _u.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{errorpassthroughrule.Label}
@@ -662,6 +717,44 @@ func (_u *ErrorPassthroughRuleUpdateOne) ClearDescription() *ErrorPassthroughRul
 	return _u
 }
 
+// SetBodyRegex sets the "body_regex" field.
+func (_u *ErrorPassthroughRuleUpdateOne) SetBodyRegex(v string) *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.SetBodyRegex(v)
+	return _u
+}
+
+// SetNillableBodyRegex sets the "body_regex" field if the given value is not nil.
+func (_u *ErrorPassthroughRuleUpdateOne) SetNillableBodyRegex(v *string) *ErrorPassthroughRuleUpdateOne {
+	if v != nil {
+		_u.SetBodyRegex(*v)
+	}
+	return _u
+}
+
+// ClearBodyRegex clears the value of the "body_regex" field.
+func (_u *ErrorPassthroughRuleUpdateOne) ClearBodyRegex() *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.ClearBodyRegex()
+	return _u
+}
+
+// SetHeaderMatchers sets the "header_matchers" field.
+func (_u *ErrorPassthroughRuleUpdateOne) SetHeaderMatchers(v []map[string]string) *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.SetHeaderMatchers(v)
+	return _u
+}
+
+// AppendHeaderMatchers appends value to the "header_matchers" field.
+func (_u *ErrorPassthroughRuleUpdateOne) AppendHeaderMatchers(v []map[string]string) *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.AppendHeaderMatchers(v)
+	return _u
+}
+
+// ClearHeaderMatchers clears the value of the "header_matchers" field.
+func (_u *ErrorPassthroughRuleUpdateOne) ClearHeaderMatchers() *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.ClearHeaderMatchers()
+	return _u
+}
+
 // Mutation returns the ErrorPassthroughRuleMutation object of the builder.
 func (_u *ErrorPassthroughRuleUpdateOne) Mutation() *ErrorPassthroughRuleMutation {
 	return _u.mutation
@@ -841,6 +934,23 @@ func (_u *ErrorPassthroughRuleUpdateOne) sqlSave(ctx context.Context) (_node *Er
 	if _u.mutation.DescriptionCleared() {
 		_spec.ClearField(errorpassthroughrule.FieldDescription, field.TypeString)
 	}
+	if value, ok := _u.mutation.BodyRegex(); ok {
+		_spec.SetField(errorpassthroughrule.FieldBodyRegex, field.TypeString, value)
+	}
+	if _u.mutation.BodyRegexCleared() {
+		_spec.ClearField(errorpassthroughrule.FieldBodyRegex, field.TypeString)
+	}
+	if value, ok := _u.mutation.HeaderMatchers(); ok {
+		_spec.SetField(errorpassthroughrule.FieldHeaderMatchers, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedHeaderMatchers(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, errorpassthroughrule.FieldHeaderMatchers, value)
+		})
+	}
+	if _u.mutation.HeaderMatchersCleared() {
+		_spec.ClearField(errorpassthroughrule.FieldHeaderMatchers, field.TypeJSON)
+	}
 	_node = &ErrorPassthroughRule{config: _u.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
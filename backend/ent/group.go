@@ -89,6 +89,18 @@ type Group struct {
 	ModelsListConfig domain.GroupModelsListConfig `json:"models_list_config,omitempty"`
 	// 分组 RPM 上限，0 表示不限制；设置后接管该分组用户的限流
 	RpmLimit int `json:"rpm_limit,omitempty"`
+	// 请求未携带任何 cache_control 时，自动在 system 与最后一个 tool 上注入 ephemeral 缓存断点（仅 anthropic 平台）
+	PromptCacheInject bool `json:"prompt_cache_inject,omitempty"`
+	// 模型降级配置：请求模型无可用账号时按有序规则尝试降级模型；mask_fallback 控制响应 model 是否回写为原请求模型
+	ModelFallbackConfig domain.GroupModelFallbackConfig `json:"model_fallback_config,omitempty"`
+	// 分组每日总消费上限（USD），NULL 表示不限制
+	SpendCapDailyUsd *float64 `json:"spend_cap_daily_usd,omitempty"`
+	// 分组每月总消费上限（USD），NULL 表示不限制
+	SpendCapMonthlyUsd *float64 `json:"spend_cap_monthly_usd,omitempty"`
+	// 消费上限周期边界使用的时区（IANA 名称），空串表示使用系统时区
+	SpendCapTimezone string `json:"spend_cap_timezone,omitempty"`
+	// OpenAI Responses instructions 注入：mode 为 prepend / if_absent / replace，text 支持 {{key_name}} {{group_name}} {{date}} 模板变量
+	InstructionInjectionConfig domain.GroupInstructionInjectionConfig `json:"instruction_injection_config,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the GroupQuery when eager-loading is set.
 	Edges        GroupEdges `json:"edges"`
@@ -195,15 +207,15 @@ func (*Group) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldModelsListConfig:
+		case group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldModelsListConfig, group.FieldModelFallbackConfig, group.FieldInstructionInjectionConfig:
 			values[i] = new([]byte)
-		case group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldImageRateIndependent, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet:
+		case group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldImageRateIndependent, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldPromptCacheInject:
 			values[i] = new(sql.NullBool)
-		case group.FieldRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k:
+		case group.FieldRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldSpendCapDailyUsd, group.FieldSpendCapMonthlyUsd:
 			values[i] = new(sql.NullFloat64)
 		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit:
 			values[i] = new(sql.NullInt64)
-		case group.FieldName, group.FieldDescription, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel:
+		case group.FieldName, group.FieldDescription, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel, group.FieldSpendCapTimezone:
 			values[i] = new(sql.NullString)
 		case group.FieldCreatedAt, group.FieldUpdatedAt, group.FieldDeletedAt:
 			values[i] = new(sql.NullTime)
@@ -456,6 +468,48 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.RpmLimit = int(value.Int64)
 			}
+		case group.FieldPromptCacheInject:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field prompt_cache_inject", values[i])
+			} else if value.Valid {
+				_m.PromptCacheInject = value.Bool
+			}
+		case group.FieldModelFallbackConfig:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field model_fallback_config", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ModelFallbackConfig); err != nil {
+					return fmt.Errorf("unmarshal field model_fallback_config: %w", err)
+				}
+			}
+		case group.FieldSpendCapDailyUsd:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field spend_cap_daily_usd", values[i])
+			} else if value.Valid {
+				_m.SpendCapDailyUsd = new(float64)
+				*_m.SpendCapDailyUsd = value.Float64
+			}
+		case group.FieldSpendCapMonthlyUsd:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field spend_cap_monthly_usd", values[i])
+			} else if value.Valid {
+				_m.SpendCapMonthlyUsd = new(float64)
+				*_m.SpendCapMonthlyUsd = value.Float64
+			}
+		case group.FieldSpendCapTimezone:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field spend_cap_timezone", values[i])
+			} else if value.Valid {
+				_m.SpendCapTimezone = value.String
+			}
+		case group.FieldInstructionInjectionConfig:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field instruction_injection_config", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.InstructionInjectionConfig); err != nil {
+					return fmt.Errorf("unmarshal field instruction_injection_config: %w", err)
+				}
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -656,6 +710,28 @@ func (_m *Group) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("rpm_limit=")
 	builder.WriteString(fmt.Sprintf("%v", _m.RpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("prompt_cache_inject=")
+	builder.WriteString(fmt.Sprintf("%v", _m.PromptCacheInject))
+	builder.WriteString(", ")
+	builder.WriteString("model_fallback_config=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ModelFallbackConfig))
+	builder.WriteString(", ")
+	if v := _m.SpendCapDailyUsd; v != nil {
+		builder.WriteString("spend_cap_daily_usd=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	if v := _m.SpendCapMonthlyUsd; v != nil {
+		builder.WriteString("spend_cap_monthly_usd=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("spend_cap_timezone=")
+	builder.WriteString(_m.SpendCapTimezone)
+	builder.WriteString(", ")
+	builder.WriteString("instruction_injection_config=")
+	builder.WriteString(fmt.Sprintf("%v", _m.InstructionInjectionConfig))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -86,6 +86,18 @@ const (
 	FieldModelsListConfig = "models_list_config"
 	// FieldRpmLimit holds the string denoting the rpm_limit field in the database.
 	FieldRpmLimit = "rpm_limit"
+	// FieldPromptCacheInject holds the string denoting the prompt_cache_inject field in the database.
+	FieldPromptCacheInject = "prompt_cache_inject"
+	// FieldModelFallbackConfig holds the string denoting the model_fallback_config field in the database.
+	FieldModelFallbackConfig = "model_fallback_config"
+	// FieldSpendCapDailyUsd holds the string denoting the spend_cap_daily_usd field in the database.
+	FieldSpendCapDailyUsd = "spend_cap_daily_usd"
+	// FieldSpendCapMonthlyUsd holds the string denoting the spend_cap_monthly_usd field in the database.
+	FieldSpendCapMonthlyUsd = "spend_cap_monthly_usd"
+	// FieldSpendCapTimezone holds the string denoting the spend_cap_timezone field in the database.
+	FieldSpendCapTimezone = "spend_cap_timezone"
+	// FieldInstructionInjectionConfig holds the string denoting the instruction_injection_config field in the database.
+	FieldInstructionInjectionConfig = "instruction_injection_config"
 	// EdgeAPIKeys holds the string denoting the api_keys edge name in mutations.
 	EdgeAPIKeys = "api_keys"
 	// EdgeRedeemCodes holds the string denoting the redeem_codes edge name in mutations.
@@ -196,6 +208,12 @@ var Columns = []string{
 	FieldMessagesDispatchModelConfig,
 	FieldModelsListConfig,
 	FieldRpmLimit,
+	FieldPromptCacheInject,
+	FieldModelFallbackConfig,
+	FieldSpendCapDailyUsd,
+	FieldSpendCapMonthlyUsd,
+	FieldSpendCapTimezone,
+	FieldInstructionInjectionConfig,
 }
 
 var (
@@ -283,6 +301,16 @@ var (
 	DefaultModelsListConfig domain.GroupModelsListConfig
 	// DefaultRpmLimit holds the default value on creation for the "rpm_limit" field.
 	DefaultRpmLimit int
+	// DefaultPromptCacheInject holds the default value on creation for the "prompt_cache_inject" field.
+	DefaultPromptCacheInject bool
+	// DefaultModelFallbackConfig holds the default value on creation for the "model_fallback_config" field.
+	DefaultModelFallbackConfig domain.GroupModelFallbackConfig
+	// DefaultSpendCapTimezone holds the default value on creation for the "spend_cap_timezone" field.
+	DefaultSpendCapTimezone string
+	// SpendCapTimezoneValidator is a validator for the "spend_cap_timezone" field. It is called by the builders before save.
+	SpendCapTimezoneValidator func(string) error
+	// DefaultInstructionInjectionConfig holds the default value on creation for the "instruction_injection_config" field.
+	DefaultInstructionInjectionConfig domain.GroupInstructionInjectionConfig
 )
 
 // OrderOption defines the ordering options for the Group queries.
@@ -448,6 +476,26 @@ func ByRpmLimit(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldRpmLimit, opts...).ToFunc()
 }
 
+// ByPromptCacheInject orders the results by the prompt_cache_inject field.
+func ByPromptCacheInject(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPromptCacheInject, opts...).ToFunc()
+}
+
+// BySpendCapDailyUsd orders the results by the spend_cap_daily_usd field.
+func BySpendCapDailyUsd(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSpendCapDailyUsd, opts...).ToFunc()
+}
+
+// BySpendCapMonthlyUsd orders the results by the spend_cap_monthly_usd field.
+func BySpendCapMonthlyUsd(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSpendCapMonthlyUsd, opts...).ToFunc()
+}
+
+// BySpendCapTimezone orders the results by the spend_cap_timezone field.
+func BySpendCapTimezone(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSpendCapTimezone, opts...).ToFunc()
+}
+
 // ByAPIKeysCount orders the results by api_keys count.
 func ByAPIKeysCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
@@ -210,6 +210,26 @@ func RpmLimit(v int) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldRpmLimit, v))
 }
 
+// PromptCacheInject applies equality check predicate on the "prompt_cache_inject" field. It's identical to PromptCacheInjectEQ.
+func PromptCacheInject(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldPromptCacheInject, v))
+}
+
+// SpendCapDailyUsd applies equality check predicate on the "spend_cap_daily_usd" field. It's identical to SpendCapDailyUsdEQ.
+func SpendCapDailyUsd(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSpendCapDailyUsd, v))
+}
+
+// SpendCapMonthlyUsd applies equality check predicate on the "spend_cap_monthly_usd" field. It's identical to SpendCapMonthlyUsdEQ.
+func SpendCapMonthlyUsd(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSpendCapMonthlyUsd, v))
+}
+
+// SpendCapTimezone applies equality check predicate on the "spend_cap_timezone" field. It's identical to SpendCapTimezoneEQ.
+func SpendCapTimezone(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSpendCapTimezone, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldCreatedAt, v))
@@ -1440,6 +1460,181 @@ func RpmLimitLTE(v int) predicate.Group {
 	return predicate.Group(sql.FieldLTE(FieldRpmLimit, v))
 }
 
+// PromptCacheInjectEQ applies the EQ predicate on the "prompt_cache_inject" field.
+func PromptCacheInjectEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldPromptCacheInject, v))
+}
+
+// PromptCacheInjectNEQ applies the NEQ predicate on the "prompt_cache_inject" field.
+func PromptCacheInjectNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldPromptCacheInject, v))
+}
+
+// SpendCapDailyUsdEQ applies the EQ predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSpendCapDailyUsd, v))
+}
+
+// SpendCapDailyUsdNEQ applies the NEQ predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldSpendCapDailyUsd, v))
+}
+
+// SpendCapDailyUsdIn applies the In predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldSpendCapDailyUsd, vs...))
+}
+
+// SpendCapDailyUsdNotIn applies the NotIn predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldSpendCapDailyUsd, vs...))
+}
+
+// SpendCapDailyUsdGT applies the GT predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldSpendCapDailyUsd, v))
+}
+
+// SpendCapDailyUsdGTE applies the GTE predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldSpendCapDailyUsd, v))
+}
+
+// SpendCapDailyUsdLT applies the LT predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldSpendCapDailyUsd, v))
+}
+
+// SpendCapDailyUsdLTE applies the LTE predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldSpendCapDailyUsd, v))
+}
+
+// SpendCapDailyUsdIsNil applies the IsNil predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldSpendCapDailyUsd))
+}
+
+// SpendCapDailyUsdNotNil applies the NotNil predicate on the "spend_cap_daily_usd" field.
+func SpendCapDailyUsdNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldSpendCapDailyUsd))
+}
+
+// SpendCapMonthlyUsdEQ applies the EQ predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSpendCapMonthlyUsd, v))
+}
+
+// SpendCapMonthlyUsdNEQ applies the NEQ predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldSpendCapMonthlyUsd, v))
+}
+
+// SpendCapMonthlyUsdIn applies the In predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldSpendCapMonthlyUsd, vs...))
+}
+
+// SpendCapMonthlyUsdNotIn applies the NotIn predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldSpendCapMonthlyUsd, vs...))
+}
+
+// SpendCapMonthlyUsdGT applies the GT predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldSpendCapMonthlyUsd, v))
+}
+
+// SpendCapMonthlyUsdGTE applies the GTE predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldSpendCapMonthlyUsd, v))
+}
+
+// SpendCapMonthlyUsdLT applies the LT predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldSpendCapMonthlyUsd, v))
+}
+
+// SpendCapMonthlyUsdLTE applies the LTE predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldSpendCapMonthlyUsd, v))
+}
+
+// SpendCapMonthlyUsdIsNil applies the IsNil predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldSpendCapMonthlyUsd))
+}
+
+// SpendCapMonthlyUsdNotNil applies the NotNil predicate on the "spend_cap_monthly_usd" field.
+func SpendCapMonthlyUsdNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldSpendCapMonthlyUsd))
+}
+
+// SpendCapTimezoneEQ applies the EQ predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneNEQ applies the NEQ predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneIn applies the In predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldSpendCapTimezone, vs...))
+}
+
+// SpendCapTimezoneNotIn applies the NotIn predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldSpendCapTimezone, vs...))
+}
+
+// SpendCapTimezoneGT applies the GT predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneGTE applies the GTE predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneLT applies the LT predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneLTE applies the LTE predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneContains applies the Contains predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneHasPrefix applies the HasPrefix predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneHasSuffix applies the HasSuffix predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneEqualFold applies the EqualFold predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldSpendCapTimezone, v))
+}
+
+// SpendCapTimezoneContainsFold applies the ContainsFold predicate on the "spend_cap_timezone" field.
+func SpendCapTimezoneContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldSpendCapTimezone, v))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.Group {
 	return predicate.Group(func(s *sql.Selector) {
@@ -495,6 +495,90 @@ func (_c *GroupCreate) SetNillableRpmLimit(v *int) *GroupCreate {
 	return _c
 }
 
+// SetPromptCacheInject sets the "prompt_cache_inject" field.
+func (_c *GroupCreate) SetPromptCacheInject(v bool) *GroupCreate {
+	_c.mutation.SetPromptCacheInject(v)
+	return _c
+}
+
+// SetNillablePromptCacheInject sets the "prompt_cache_inject" field if the given value is not nil.
+func (_c *GroupCreate) SetNillablePromptCacheInject(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetPromptCacheInject(*v)
+	}
+	return _c
+}
+
+// SetModelFallbackConfig sets the "model_fallback_config" field.
+func (_c *GroupCreate) SetModelFallbackConfig(v domain.GroupModelFallbackConfig) *GroupCreate {
+	_c.mutation.SetModelFallbackConfig(v)
+	return _c
+}
+
+// SetNillableModelFallbackConfig sets the "model_fallback_config" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableModelFallbackConfig(v *domain.GroupModelFallbackConfig) *GroupCreate {
+	if v != nil {
+		_c.SetModelFallbackConfig(*v)
+	}
+	return _c
+}
+
+// SetSpendCapDailyUsd sets the "spend_cap_daily_usd" field.
+func (_c *GroupCreate) SetSpendCapDailyUsd(v float64) *GroupCreate {
+	_c.mutation.SetSpendCapDailyUsd(v)
+	return _c
+}
+
+// SetNillableSpendCapDailyUsd sets the "spend_cap_daily_usd" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSpendCapDailyUsd(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetSpendCapDailyUsd(*v)
+	}
+	return _c
+}
+
+// SetSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field.
+func (_c *GroupCreate) SetSpendCapMonthlyUsd(v float64) *GroupCreate {
+	_c.mutation.SetSpendCapMonthlyUsd(v)
+	return _c
+}
+
+// SetNillableSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSpendCapMonthlyUsd(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetSpendCapMonthlyUsd(*v)
+	}
+	return _c
+}
+
+// SetSpendCapTimezone sets the "spend_cap_timezone" field.
+func (_c *GroupCreate) SetSpendCapTimezone(v string) *GroupCreate {
+	_c.mutation.SetSpendCapTimezone(v)
+	return _c
+}
+
+// SetNillableSpendCapTimezone sets the "spend_cap_timezone" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSpendCapTimezone(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetSpendCapTimezone(*v)
+	}
+	return _c
+}
+
+// SetInstructionInjectionConfig sets the "instruction_injection_config" field.
+func (_c *GroupCreate) SetInstructionInjectionConfig(v domain.GroupInstructionInjectionConfig) *GroupCreate {
+	_c.mutation.SetInstructionInjectionConfig(v)
+	return _c
+}
+
+// SetNillableInstructionInjectionConfig sets the "instruction_injection_config" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableInstructionInjectionConfig(v *domain.GroupInstructionInjectionConfig) *GroupCreate {
+	if v != nil {
+		_c.SetInstructionInjectionConfig(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *GroupCreate) AddAPIKeyIDs(ids ...int64) *GroupCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -720,6 +804,22 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultRpmLimit
 		_c.mutation.SetRpmLimit(v)
 	}
+	if _, ok := _c.mutation.PromptCacheInject(); !ok {
+		v := group.DefaultPromptCacheInject
+		_c.mutation.SetPromptCacheInject(v)
+	}
+	if _, ok := _c.mutation.ModelFallbackConfig(); !ok {
+		v := group.DefaultModelFallbackConfig
+		_c.mutation.SetModelFallbackConfig(v)
+	}
+	if _, ok := _c.mutation.SpendCapTimezone(); !ok {
+		v := group.DefaultSpendCapTimezone
+		_c.mutation.SetSpendCapTimezone(v)
+	}
+	if _, ok := _c.mutation.InstructionInjectionConfig(); !ok {
+		v := group.DefaultInstructionInjectionConfig
+		_c.mutation.SetInstructionInjectionConfig(v)
+	}
 	return nil
 }
 
@@ -822,6 +922,23 @@ func (_c *GroupCreate) check() error {
 	if _, ok := _c.mutation.RpmLimit(); !ok {
 		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "Group.rpm_limit"`)}
 	}
+	if _, ok := _c.mutation.PromptCacheInject(); !ok {
+		return &ValidationError{Name: "prompt_cache_inject", err: errors.New(`ent: missing required field "Group.prompt_cache_inject"`)}
+	}
+	if _, ok := _c.mutation.ModelFallbackConfig(); !ok {
+		return &ValidationError{Name: "model_fallback_config", err: errors.New(`ent: missing required field "Group.model_fallback_config"`)}
+	}
+	if _, ok := _c.mutation.SpendCapTimezone(); !ok {
+		return &ValidationError{Name: "spend_cap_timezone", err: errors.New(`ent: missing required field "Group.spend_cap_timezone"`)}
+	}
+	if v, ok := _c.mutation.SpendCapTimezone(); ok {
+		if err := group.SpendCapTimezoneValidator(v); err != nil {
+			return &ValidationError{Name: "spend_cap_timezone", err: fmt.Errorf(`ent: validator failed for field "Group.spend_cap_timezone": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.InstructionInjectionConfig(); !ok {
+		return &ValidationError{Name: "instruction_injection_config", err: errors.New(`ent: missing required field "Group.instruction_injection_config"`)}
+	}
 	return nil
 }
 
@@ -989,6 +1106,30 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldRpmLimit, field.TypeInt, value)
 		_node.RpmLimit = value
 	}
+	if value, ok := _c.mutation.PromptCacheInject(); ok {
+		_spec.SetField(group.FieldPromptCacheInject, field.TypeBool, value)
+		_node.PromptCacheInject = value
+	}
+	if value, ok := _c.mutation.ModelFallbackConfig(); ok {
+		_spec.SetField(group.FieldModelFallbackConfig, field.TypeJSON, value)
+		_node.ModelFallbackConfig = value
+	}
+	if value, ok := _c.mutation.SpendCapDailyUsd(); ok {
+		_spec.SetField(group.FieldSpendCapDailyUsd, field.TypeFloat64, value)
+		_node.SpendCapDailyUsd = &value
+	}
+	if value, ok := _c.mutation.SpendCapMonthlyUsd(); ok {
+		_spec.SetField(group.FieldSpendCapMonthlyUsd, field.TypeFloat64, value)
+		_node.SpendCapMonthlyUsd = &value
+	}
+	if value, ok := _c.mutation.SpendCapTimezone(); ok {
+		_spec.SetField(group.FieldSpendCapTimezone, field.TypeString, value)
+		_node.SpendCapTimezone = value
+	}
+	if value, ok := _c.mutation.InstructionInjectionConfig(); ok {
+		_spec.SetField(group.FieldInstructionInjectionConfig, field.TypeJSON, value)
+		_node.InstructionInjectionConfig = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1697,6 +1838,102 @@ func (u *GroupUpsert) AddRpmLimit(v int) *GroupUpsert {
 	return u
 }
 
+// SetPromptCacheInject sets the "prompt_cache_inject" field.
+func (u *GroupUpsert) SetPromptCacheInject(v bool) *GroupUpsert {
+	u.Set(group.FieldPromptCacheInject, v)
+	return u
+}
+
+// UpdatePromptCacheInject sets the "prompt_cache_inject" field to the value that was provided on create.
+func (u *GroupUpsert) UpdatePromptCacheInject() *GroupUpsert {
+	u.SetExcluded(group.FieldPromptCacheInject)
+	return u
+}
+
+// SetModelFallbackConfig sets the "model_fallback_config" field.
+func (u *GroupUpsert) SetModelFallbackConfig(v domain.GroupModelFallbackConfig) *GroupUpsert {
+	u.Set(group.FieldModelFallbackConfig, v)
+	return u
+}
+
+// UpdateModelFallbackConfig sets the "model_fallback_config" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateModelFallbackConfig() *GroupUpsert {
+	u.SetExcluded(group.FieldModelFallbackConfig)
+	return u
+}
+
+// SetSpendCapDailyUsd sets the "spend_cap_daily_usd" field.
+func (u *GroupUpsert) SetSpendCapDailyUsd(v float64) *GroupUpsert {
+	u.Set(group.FieldSpendCapDailyUsd, v)
+	return u
+}
+
+// UpdateSpendCapDailyUsd sets the "spend_cap_daily_usd" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSpendCapDailyUsd() *GroupUpsert {
+	u.SetExcluded(group.FieldSpendCapDailyUsd)
+	return u
+}
+
+// AddSpendCapDailyUsd adds v to the "spend_cap_daily_usd" field.
+func (u *GroupUpsert) AddSpendCapDailyUsd(v float64) *GroupUpsert {
+	u.Add(group.FieldSpendCapDailyUsd, v)
+	return u
+}
+
+// ClearSpendCapDailyUsd clears the value of the "spend_cap_daily_usd" field.
+func (u *GroupUpsert) ClearSpendCapDailyUsd() *GroupUpsert {
+	u.SetNull(group.FieldSpendCapDailyUsd)
+	return u
+}
+
+// SetSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field.
+func (u *GroupUpsert) SetSpendCapMonthlyUsd(v float64) *GroupUpsert {
+	u.Set(group.FieldSpendCapMonthlyUsd, v)
+	return u
+}
+
+// UpdateSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSpendCapMonthlyUsd() *GroupUpsert {
+	u.SetExcluded(group.FieldSpendCapMonthlyUsd)
+	return u
+}
+
+// AddSpendCapMonthlyUsd adds v to the "spend_cap_monthly_usd" field.
+func (u *GroupUpsert) AddSpendCapMonthlyUsd(v float64) *GroupUpsert {
+	u.Add(group.FieldSpendCapMonthlyUsd, v)
+	return u
+}
+
+// ClearSpendCapMonthlyUsd clears the value of the "spend_cap_monthly_usd" field.
+func (u *GroupUpsert) ClearSpendCapMonthlyUsd() *GroupUpsert {
+	u.SetNull(group.FieldSpendCapMonthlyUsd)
+	return u
+}
+
+// SetSpendCapTimezone sets the "spend_cap_timezone" field.
+func (u *GroupUpsert) SetSpendCapTimezone(v string) *GroupUpsert {
+	u.Set(group.FieldSpendCapTimezone, v)
+	return u
+}
+
+// UpdateSpendCapTimezone sets the "spend_cap_timezone" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSpendCapTimezone() *GroupUpsert {
+	u.SetExcluded(group.FieldSpendCapTimezone)
+	return u
+}
+
+// SetInstructionInjectionConfig sets the "instruction_injection_config" field.
+func (u *GroupUpsert) SetInstructionInjectionConfig(v domain.GroupInstructionInjectionConfig) *GroupUpsert {
+	u.Set(group.FieldInstructionInjectionConfig, v)
+	return u
+}
+
+// UpdateInstructionInjectionConfig sets the "instruction_injection_config" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateInstructionInjectionConfig() *GroupUpsert {
+	u.SetExcluded(group.FieldInstructionInjectionConfig)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -2386,6 +2623,118 @@ func (u *GroupUpsertOne) UpdateRpmLimit() *GroupUpsertOne {
 	})
 }
 
+// SetPromptCacheInject sets the "prompt_cache_inject" field.
+func (u *GroupUpsertOne) SetPromptCacheInject(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetPromptCacheInject(v)
+	})
+}
+
+// UpdatePromptCacheInject sets the "prompt_cache_inject" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdatePromptCacheInject() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdatePromptCacheInject()
+	})
+}
+
+// SetModelFallbackConfig sets the "model_fallback_config" field.
+func (u *GroupUpsertOne) SetModelFallbackConfig(v domain.GroupModelFallbackConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModelFallbackConfig(v)
+	})
+}
+
+// UpdateModelFallbackConfig sets the "model_fallback_config" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateModelFallbackConfig() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModelFallbackConfig()
+	})
+}
+
+// SetSpendCapDailyUsd sets the "spend_cap_daily_usd" field.
+func (u *GroupUpsertOne) SetSpendCapDailyUsd(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSpendCapDailyUsd(v)
+	})
+}
+
+// AddSpendCapDailyUsd adds v to the "spend_cap_daily_usd" field.
+func (u *GroupUpsertOne) AddSpendCapDailyUsd(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddSpendCapDailyUsd(v)
+	})
+}
+
+// UpdateSpendCapDailyUsd sets the "spend_cap_daily_usd" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSpendCapDailyUsd() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSpendCapDailyUsd()
+	})
+}
+
+// ClearSpendCapDailyUsd clears the value of the "spend_cap_daily_usd" field.
+func (u *GroupUpsertOne) ClearSpendCapDailyUsd() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearSpendCapDailyUsd()
+	})
+}
+
+// SetSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field.
+func (u *GroupUpsertOne) SetSpendCapMonthlyUsd(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSpendCapMonthlyUsd(v)
+	})
+}
+
+// AddSpendCapMonthlyUsd adds v to the "spend_cap_monthly_usd" field.
+func (u *GroupUpsertOne) AddSpendCapMonthlyUsd(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddSpendCapMonthlyUsd(v)
+	})
+}
+
+// UpdateSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSpendCapMonthlyUsd() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSpendCapMonthlyUsd()
+	})
+}
+
+// ClearSpendCapMonthlyUsd clears the value of the "spend_cap_monthly_usd" field.
+func (u *GroupUpsertOne) ClearSpendCapMonthlyUsd() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearSpendCapMonthlyUsd()
+	})
+}
+
+// SetSpendCapTimezone sets the "spend_cap_timezone" field.
+func (u *GroupUpsertOne) SetSpendCapTimezone(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSpendCapTimezone(v)
+	})
+}
+
+// UpdateSpendCapTimezone sets the "spend_cap_timezone" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSpendCapTimezone() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSpendCapTimezone()
+	})
+}
+
+// SetInstructionInjectionConfig sets the "instruction_injection_config" field.
+func (u *GroupUpsertOne) SetInstructionInjectionConfig(v domain.GroupInstructionInjectionConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetInstructionInjectionConfig(v)
+	})
+}
+
+// UpdateInstructionInjectionConfig sets the "instruction_injection_config" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateInstructionInjectionConfig() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateInstructionInjectionConfig()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -3241,6 +3590,118 @@ func (u *GroupUpsertBulk) UpdateRpmLimit() *GroupUpsertBulk {
 	})
 }
 
+// SetPromptCacheInject sets the "prompt_cache_inject" field.
+func (u *GroupUpsertBulk) SetPromptCacheInject(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetPromptCacheInject(v)
+	})
+}
+
+// UpdatePromptCacheInject sets the "prompt_cache_inject" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdatePromptCacheInject() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdatePromptCacheInject()
+	})
+}
+
+// SetModelFallbackConfig sets the "model_fallback_config" field.
+func (u *GroupUpsertBulk) SetModelFallbackConfig(v domain.GroupModelFallbackConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModelFallbackConfig(v)
+	})
+}
+
+// UpdateModelFallbackConfig sets the "model_fallback_config" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateModelFallbackConfig() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModelFallbackConfig()
+	})
+}
+
+// SetSpendCapDailyUsd sets the "spend_cap_daily_usd" field.
+func (u *GroupUpsertBulk) SetSpendCapDailyUsd(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSpendCapDailyUsd(v)
+	})
+}
+
+// AddSpendCapDailyUsd adds v to the "spend_cap_daily_usd" field.
+func (u *GroupUpsertBulk) AddSpendCapDailyUsd(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddSpendCapDailyUsd(v)
+	})
+}
+
+// UpdateSpendCapDailyUsd sets the "spend_cap_daily_usd" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSpendCapDailyUsd() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSpendCapDailyUsd()
+	})
+}
+
+// ClearSpendCapDailyUsd clears the value of the "spend_cap_daily_usd" field.
+func (u *GroupUpsertBulk) ClearSpendCapDailyUsd() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearSpendCapDailyUsd()
+	})
+}
+
+// SetSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field.
+func (u *GroupUpsertBulk) SetSpendCapMonthlyUsd(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSpendCapMonthlyUsd(v)
+	})
+}
+
+// AddSpendCapMonthlyUsd adds v to the "spend_cap_monthly_usd" field.
+func (u *GroupUpsertBulk) AddSpendCapMonthlyUsd(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddSpendCapMonthlyUsd(v)
+	})
+}
+
+// UpdateSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSpendCapMonthlyUsd() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSpendCapMonthlyUsd()
+	})
+}
+
+// ClearSpendCapMonthlyUsd clears the value of the "spend_cap_monthly_usd" field.
+func (u *GroupUpsertBulk) ClearSpendCapMonthlyUsd() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearSpendCapMonthlyUsd()
+	})
+}
+
+// SetSpendCapTimezone sets the "spend_cap_timezone" field.
+func (u *GroupUpsertBulk) SetSpendCapTimezone(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSpendCapTimezone(v)
+	})
+}
+
+// UpdateSpendCapTimezone sets the "spend_cap_timezone" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSpendCapTimezone() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSpendCapTimezone()
+	})
+}
+
+// SetInstructionInjectionConfig sets the "instruction_injection_config" field.
+func (u *GroupUpsertBulk) SetInstructionInjectionConfig(v domain.GroupInstructionInjectionConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetInstructionInjectionConfig(v)
+	})
+}
+
+// UpdateInstructionInjectionConfig sets the "instruction_injection_config" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateInstructionInjectionConfig() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateInstructionInjectionConfig()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -651,6 +651,116 @@ func (_u *GroupUpdate) AddRpmLimit(v int) *GroupUpdate {
 	return _u
 }
 
+// SetPromptCacheInject sets the "prompt_cache_inject" field.
+func (_u *GroupUpdate) SetPromptCacheInject(v bool) *GroupUpdate {
+	_u.mutation.SetPromptCacheInject(v)
+	return _u
+}
+
+// SetNillablePromptCacheInject sets the "prompt_cache_inject" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillablePromptCacheInject(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetPromptCacheInject(*v)
+	}
+	return _u
+}
+
+// SetModelFallbackConfig sets the "model_fallback_config" field.
+func (_u *GroupUpdate) SetModelFallbackConfig(v domain.GroupModelFallbackConfig) *GroupUpdate {
+	_u.mutation.SetModelFallbackConfig(v)
+	return _u
+}
+
+// SetNillableModelFallbackConfig sets the "model_fallback_config" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableModelFallbackConfig(v *domain.GroupModelFallbackConfig) *GroupUpdate {
+	if v != nil {
+		_u.SetModelFallbackConfig(*v)
+	}
+	return _u
+}
+
+// SetSpendCapDailyUsd sets the "spend_cap_daily_usd" field.
+func (_u *GroupUpdate) SetSpendCapDailyUsd(v float64) *GroupUpdate {
+	_u.mutation.ResetSpendCapDailyUsd()
+	_u.mutation.SetSpendCapDailyUsd(v)
+	return _u
+}
+
+// SetNillableSpendCapDailyUsd sets the "spend_cap_daily_usd" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSpendCapDailyUsd(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetSpendCapDailyUsd(*v)
+	}
+	return _u
+}
+
+// AddSpendCapDailyUsd adds value to the "spend_cap_daily_usd" field.
+func (_u *GroupUpdate) AddSpendCapDailyUsd(v float64) *GroupUpdate {
+	_u.mutation.AddSpendCapDailyUsd(v)
+	return _u
+}
+
+// ClearSpendCapDailyUsd clears the value of the "spend_cap_daily_usd" field.
+func (_u *GroupUpdate) ClearSpendCapDailyUsd() *GroupUpdate {
+	_u.mutation.ClearSpendCapDailyUsd()
+	return _u
+}
+
+// SetSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field.
+func (_u *GroupUpdate) SetSpendCapMonthlyUsd(v float64) *GroupUpdate {
+	_u.mutation.ResetSpendCapMonthlyUsd()
+	_u.mutation.SetSpendCapMonthlyUsd(v)
+	return _u
+}
+
+// SetNillableSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSpendCapMonthlyUsd(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetSpendCapMonthlyUsd(*v)
+	}
+	return _u
+}
+
+// AddSpendCapMonthlyUsd adds value to the "spend_cap_monthly_usd" field.
+func (_u *GroupUpdate) AddSpendCapMonthlyUsd(v float64) *GroupUpdate {
+	_u.mutation.AddSpendCapMonthlyUsd(v)
+	return _u
+}
+
+// ClearSpendCapMonthlyUsd clears the value of the "spend_cap_monthly_usd" field.
+func (_u *GroupUpdate) ClearSpendCapMonthlyUsd() *GroupUpdate {
+	_u.mutation.ClearSpendCapMonthlyUsd()
+	return _u
+}
+
+// SetSpendCapTimezone sets the "spend_cap_timezone" field.
+func (_u *GroupUpdate) SetSpendCapTimezone(v string) *GroupUpdate {
+	_u.mutation.SetSpendCapTimezone(v)
+	return _u
+}
+
+// SetNillableSpendCapTimezone sets the "spend_cap_timezone" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSpendCapTimezone(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetSpendCapTimezone(*v)
+	}
+	return _u
+}
+
+// SetInstructionInjectionConfig sets the "instruction_injection_config" field.
+func (_u *GroupUpdate) SetInstructionInjectionConfig(v domain.GroupInstructionInjectionConfig) *GroupUpdate {
+	_u.mutation.SetInstructionInjectionConfig(v)
+	return _u
+}
+
+// SetNillableInstructionInjectionConfig sets the "instruction_injection_config" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableInstructionInjectionConfig(v *domain.GroupInstructionInjectionConfig) *GroupUpdate {
+	if v != nil {
+		_u.SetInstructionInjectionConfig(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdate) AddAPIKeyIDs(ids ...int64) *GroupUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -941,6 +1051,11 @@ func (_u *GroupUpdate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SpendCapTimezone(); ok {
+		if err := group.SpendCapTimezoneValidator(v); err != nil {
+			return &ValidationError{Name: "spend_cap_timezone", err: fmt.Errorf(`ent: validator failed for field "Group.spend_cap_timezone": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1135,6 +1250,36 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.PromptCacheInject(); ok {
+		_spec.SetField(group.FieldPromptCacheInject, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ModelFallbackConfig(); ok {
+		_spec.SetField(group.FieldModelFallbackConfig, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.SpendCapDailyUsd(); ok {
+		_spec.SetField(group.FieldSpendCapDailyUsd, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedSpendCapDailyUsd(); ok {
+		_spec.AddField(group.FieldSpendCapDailyUsd, field.TypeFloat64, value)
+	}
+	if _u.mutation.SpendCapDailyUsdCleared() {
+		_spec.ClearField(group.FieldSpendCapDailyUsd, field.TypeFloat64)
+	}
+	if value, ok := _u.mutation.SpendCapMonthlyUsd(); ok {
+		_spec.SetField(group.FieldSpendCapMonthlyUsd, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedSpendCapMonthlyUsd(); ok {
+		_spec.AddField(group.FieldSpendCapMonthlyUsd, field.TypeFloat64, value)
+	}
+	if _u.mutation.SpendCapMonthlyUsdCleared() {
+		_spec.ClearField(group.FieldSpendCapMonthlyUsd, field.TypeFloat64)
+	}
+	if value, ok := _u.mutation.SpendCapTimezone(); ok {
+		_spec.SetField(group.FieldSpendCapTimezone, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.InstructionInjectionConfig(); ok {
+		_spec.SetField(group.FieldInstructionInjectionConfig, field.TypeJSON, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -2064,6 +2209,116 @@ func (_u *GroupUpdateOne) AddRpmLimit(v int) *GroupUpdateOne {
 	return _u
 }
 
+// SetPromptCacheInject sets the "prompt_cache_inject" field.
+func (_u *GroupUpdateOne) SetPromptCacheInject(v bool) *GroupUpdateOne {
+	_u.mutation.SetPromptCacheInject(v)
+	return _u
+}
+
+// SetNillablePromptCacheInject sets the "prompt_cache_inject" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillablePromptCacheInject(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetPromptCacheInject(*v)
+	}
+	return _u
+}
+
+// SetModelFallbackConfig sets the "model_fallback_config" field.
+func (_u *GroupUpdateOne) SetModelFallbackConfig(v domain.GroupModelFallbackConfig) *GroupUpdateOne {
+	_u.mutation.SetModelFallbackConfig(v)
+	return _u
+}
+
+// SetNillableModelFallbackConfig sets the "model_fallback_config" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableModelFallbackConfig(v *domain.GroupModelFallbackConfig) *GroupUpdateOne {
+	if v != nil {
+		_u.SetModelFallbackConfig(*v)
+	}
+	return _u
+}
+
+// SetSpendCapDailyUsd sets the "spend_cap_daily_usd" field.
+func (_u *GroupUpdateOne) SetSpendCapDailyUsd(v float64) *GroupUpdateOne {
+	_u.mutation.ResetSpendCapDailyUsd()
+	_u.mutation.SetSpendCapDailyUsd(v)
+	return _u
+}
+
+// SetNillableSpendCapDailyUsd sets the "spend_cap_daily_usd" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSpendCapDailyUsd(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSpendCapDailyUsd(*v)
+	}
+	return _u
+}
+
+// AddSpendCapDailyUsd adds value to the "spend_cap_daily_usd" field.
+func (_u *GroupUpdateOne) AddSpendCapDailyUsd(v float64) *GroupUpdateOne {
+	_u.mutation.AddSpendCapDailyUsd(v)
+	return _u
+}
+
+// ClearSpendCapDailyUsd clears the value of the "spend_cap_daily_usd" field.
+func (_u *GroupUpdateOne) ClearSpendCapDailyUsd() *GroupUpdateOne {
+	_u.mutation.ClearSpendCapDailyUsd()
+	return _u
+}
+
+// SetSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field.
+func (_u *GroupUpdateOne) SetSpendCapMonthlyUsd(v float64) *GroupUpdateOne {
+	_u.mutation.ResetSpendCapMonthlyUsd()
+	_u.mutation.SetSpendCapMonthlyUsd(v)
+	return _u
+}
+
+// SetNillableSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSpendCapMonthlyUsd(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSpendCapMonthlyUsd(*v)
+	}
+	return _u
+}
+
+// AddSpendCapMonthlyUsd adds value to the "spend_cap_monthly_usd" field.
+func (_u *GroupUpdateOne) AddSpendCapMonthlyUsd(v float64) *GroupUpdateOne {
+	_u.mutation.AddSpendCapMonthlyUsd(v)
+	return _u
+}
+
+// ClearSpendCapMonthlyUsd clears the value of the "spend_cap_monthly_usd" field.
+func (_u *GroupUpdateOne) ClearSpendCapMonthlyUsd() *GroupUpdateOne {
+	_u.mutation.ClearSpendCapMonthlyUsd()
+	return _u
+}
+
+// SetSpendCapTimezone sets the "spend_cap_timezone" field.
+func (_u *GroupUpdateOne) SetSpendCapTimezone(v string) *GroupUpdateOne {
+	_u.mutation.SetSpendCapTimezone(v)
+	return _u
+}
+
+// SetNillableSpendCapTimezone sets the "spend_cap_timezone" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSpendCapTimezone(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSpendCapTimezone(*v)
+	}
+	return _u
+}
+
+// SetInstructionInjectionConfig sets the "instruction_injection_config" field.
+func (_u *GroupUpdateOne) SetInstructionInjectionConfig(v domain.GroupInstructionInjectionConfig) *GroupUpdateOne {
+	_u.mutation.SetInstructionInjectionConfig(v)
+	return _u
+}
+
+// SetNillableInstructionInjectionConfig sets the "instruction_injection_config" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableInstructionInjectionConfig(v *domain.GroupInstructionInjectionConfig) *GroupUpdateOne {
+	if v != nil {
+		_u.SetInstructionInjectionConfig(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdateOne) AddAPIKeyIDs(ids ...int64) *GroupUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -2367,6 +2622,11 @@ func (_u *GroupUpdateOne) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SpendCapTimezone(); ok {
+		if err := group.SpendCapTimezoneValidator(v); err != nil {
+			return &ValidationError{Name: "spend_cap_timezone", err: fmt.Errorf(`ent: validator failed for field "Group.spend_cap_timezone": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -2578,6 +2838,36 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.PromptCacheInject(); ok {
+		_spec.SetField(group.FieldPromptCacheInject, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ModelFallbackConfig(); ok {
+		_spec.SetField(group.FieldModelFallbackConfig, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.SpendCapDailyUsd(); ok {
+		_spec.SetField(group.FieldSpendCapDailyUsd, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedSpendCapDailyUsd(); ok {
+		_spec.AddField(group.FieldSpendCapDailyUsd, field.TypeFloat64, value)
+	}
+	if _u.mutation.SpendCapDailyUsdCleared() {
+		_spec.ClearField(group.FieldSpendCapDailyUsd, field.TypeFloat64)
+	}
+	if value, ok := _u.mutation.SpendCapMonthlyUsd(); ok {
+		_spec.SetField(group.FieldSpendCapMonthlyUsd, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedSpendCapMonthlyUsd(); ok {
+		_spec.AddField(group.FieldSpendCapMonthlyUsd, field.TypeFloat64, value)
+	}
+	if _u.mutation.SpendCapMonthlyUsdCleared() {
+		_spec.ClearField(group.FieldSpendCapMonthlyUsd, field.TypeFloat64)
+	}
+	if value, ok := _u.mutation.SpendCapTimezone(); ok {
+		_spec.SetField(group.FieldSpendCapTimezone, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.InstructionInjectionConfig(); ok {
+		_spec.SetField(group.FieldInstructionInjectionConfig, field.TypeJSON, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
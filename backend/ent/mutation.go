@@ -4,7 +4,7 @@ package ent
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/json/jsontext"
 	"errors"
 	"fmt"
 	"sync"
@@ -100,51 +100,60 @@ const (
 // APIKeyMutation represents an operation that mutates the APIKey nodes in the graph.
 type APIKeyMutation struct {
 	config
-	op                 Op
-	typ                string
-	id                 *int64
-	created_at         *time.Time
-	updated_at         *time.Time
-	deleted_at         *time.Time
-	key                *string
-	name               *string
-	status             *string
-	last_used_at       *time.Time
-	ip_whitelist       *[]string
-	appendip_whitelist []string
-	ip_blacklist       *[]string
-	appendip_blacklist []string
-	quota              *float64
-	addquota           *float64
-	quota_used         *float64
-	addquota_used      *float64
-	expires_at         *time.Time
-	rate_limit_5h      *float64
-	addrate_limit_5h   *float64
-	rate_limit_1d      *float64
-	addrate_limit_1d   *float64
-	rate_limit_7d      *float64
-	addrate_limit_7d   *float64
-	usage_5h           *float64
-	addusage_5h        *float64
-	usage_1d           *float64
-	addusage_1d        *float64
-	usage_7d           *float64
-	addusage_7d        *float64
-	window_5h_start    *time.Time
-	window_1d_start    *time.Time
-	window_7d_start    *time.Time
-	clearedFields      map[string]struct{}
-	user               *int64
-	cleareduser        bool
-	group              *int64
-	clearedgroup       bool
-	usage_logs         map[int64]struct{}
-	removedusage_logs  map[int64]struct{}
-	clearedusage_logs  bool
-	done               bool
-	oldValue           func(context.Context) (*APIKey, error)
-	predicates         []predicate.APIKey
+	op                     Op
+	typ                    string
+	id                     *int64
+	created_at             *time.Time
+	updated_at             *time.Time
+	deleted_at             *time.Time
+	key                    *string
+	name                   *string
+	status                 *string
+	last_used_at           *time.Time
+	ip_whitelist           *[]string
+	appendip_whitelist     []string
+	ip_blacklist           *[]string
+	appendip_blacklist     []string
+	account_labels         *[]string
+	appendaccount_labels   []string
+	response_cache_enabled *bool
+	cost_preview_enabled   *bool
+	scopes                 *[]string
+	appendscopes           []string
+	allowed_models         *[]string
+	appendallowed_models   []string
+	quota                  *float64
+	addquota               *float64
+	quota_used             *float64
+	addquota_used          *float64
+	expires_at             *time.Time
+	rate_limit_5h          *float64
+	addrate_limit_5h       *float64
+	rate_limit_1d          *float64
+	addrate_limit_1d       *float64
+	rate_limit_7d          *float64
+	addrate_limit_7d       *float64
+	usage_5h               *float64
+	addusage_5h            *float64
+	usage_1d               *float64
+	addusage_1d            *float64
+	usage_7d               *float64
+	addusage_7d            *float64
+	window_5h_start        *time.Time
+	window_1d_start        *time.Time
+	window_7d_start        *time.Time
+	capture_until          *time.Time
+	clearedFields          map[string]struct{}
+	user                   *int64
+	cleareduser            bool
+	group                  *int64
+	clearedgroup           bool
+	usage_logs             map[int64]struct{}
+	removedusage_logs      map[int64]struct{}
+	clearedusage_logs      bool
+	done                   bool
+	oldValue               func(context.Context) (*APIKey, error)
+	predicates             []predicate.APIKey
 }
 
 var _ ent.Mutation = (*APIKeyMutation)(nil)
@@ -738,6 +747,273 @@ func (m *APIKeyMutation) ResetIPBlacklist() {
 	delete(m.clearedFields, apikey.FieldIPBlacklist)
 }
 
+// SetAccountLabels sets the "account_labels" field.
+func (m *APIKeyMutation) SetAccountLabels(s []string) {
+	m.account_labels = &s
+	m.appendaccount_labels = nil
+}
+
+// AccountLabels returns the value of the "account_labels" field in the mutation.
+func (m *APIKeyMutation) AccountLabels() (r []string, exists bool) {
+	v := m.account_labels
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAccountLabels returns the old "account_labels" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldAccountLabels(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAccountLabels is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAccountLabels requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAccountLabels: %w", err)
+	}
+	return oldValue.AccountLabels, nil
+}
+
+// AppendAccountLabels adds s to the "account_labels" field.
+func (m *APIKeyMutation) AppendAccountLabels(s []string) {
+	m.appendaccount_labels = append(m.appendaccount_labels, s...)
+}
+
+// AppendedAccountLabels returns the list of values that were appended to the "account_labels" field in this mutation.
+func (m *APIKeyMutation) AppendedAccountLabels() ([]string, bool) {
+	if len(m.appendaccount_labels) == 0 {
+		return nil, false
+	}
+	return m.appendaccount_labels, true
+}
+
+// ClearAccountLabels clears the value of the "account_labels" field.
+func (m *APIKeyMutation) ClearAccountLabels() {
+	m.account_labels = nil
+	m.appendaccount_labels = nil
+	m.clearedFields[apikey.FieldAccountLabels] = struct{}{}
+}
+
+// AccountLabelsCleared returns if the "account_labels" field was cleared in this mutation.
+func (m *APIKeyMutation) AccountLabelsCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldAccountLabels]
+	return ok
+}
+
+// ResetAccountLabels resets all changes to the "account_labels" field.
+func (m *APIKeyMutation) ResetAccountLabels() {
+	m.account_labels = nil
+	m.appendaccount_labels = nil
+	delete(m.clearedFields, apikey.FieldAccountLabels)
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (m *APIKeyMutation) SetResponseCacheEnabled(b bool) {
+	m.response_cache_enabled = &b
+}
+
+// ResponseCacheEnabled returns the value of the "response_cache_enabled" field in the mutation.
+func (m *APIKeyMutation) ResponseCacheEnabled() (r bool, exists bool) {
+	v := m.response_cache_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheEnabled returns the old "response_cache_enabled" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldResponseCacheEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheEnabled: %w", err)
+	}
+	return oldValue.ResponseCacheEnabled, nil
+}
+
+// ResetResponseCacheEnabled resets all changes to the "response_cache_enabled" field.
+func (m *APIKeyMutation) ResetResponseCacheEnabled() {
+	m.response_cache_enabled = nil
+}
+
+// SetCostPreviewEnabled sets the "cost_preview_enabled" field.
+func (m *APIKeyMutation) SetCostPreviewEnabled(b bool) {
+	m.cost_preview_enabled = &b
+}
+
+// CostPreviewEnabled returns the value of the "cost_preview_enabled" field in the mutation.
+func (m *APIKeyMutation) CostPreviewEnabled() (r bool, exists bool) {
+	v := m.cost_preview_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCostPreviewEnabled returns the old "cost_preview_enabled" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldCostPreviewEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCostPreviewEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCostPreviewEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCostPreviewEnabled: %w", err)
+	}
+	return oldValue.CostPreviewEnabled, nil
+}
+
+// ResetCostPreviewEnabled resets all changes to the "cost_preview_enabled" field.
+func (m *APIKeyMutation) ResetCostPreviewEnabled() {
+	m.cost_preview_enabled = nil
+}
+
+// SetScopes sets the "scopes" field.
+func (m *APIKeyMutation) SetScopes(s []string) {
+	m.scopes = &s
+	m.appendscopes = nil
+}
+
+// Scopes returns the value of the "scopes" field in the mutation.
+func (m *APIKeyMutation) Scopes() (r []string, exists bool) {
+	v := m.scopes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldScopes returns the old "scopes" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldScopes(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldScopes is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldScopes requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldScopes: %w", err)
+	}
+	return oldValue.Scopes, nil
+}
+
+// AppendScopes adds s to the "scopes" field.
+func (m *APIKeyMutation) AppendScopes(s []string) {
+	m.appendscopes = append(m.appendscopes, s...)
+}
+
+// AppendedScopes returns the list of values that were appended to the "scopes" field in this mutation.
+func (m *APIKeyMutation) AppendedScopes() ([]string, bool) {
+	if len(m.appendscopes) == 0 {
+		return nil, false
+	}
+	return m.appendscopes, true
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (m *APIKeyMutation) ClearScopes() {
+	m.scopes = nil
+	m.appendscopes = nil
+	m.clearedFields[apikey.FieldScopes] = struct{}{}
+}
+
+// ScopesCleared returns if the "scopes" field was cleared in this mutation.
+func (m *APIKeyMutation) ScopesCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldScopes]
+	return ok
+}
+
+// ResetScopes resets all changes to the "scopes" field.
+func (m *APIKeyMutation) ResetScopes() {
+	m.scopes = nil
+	m.appendscopes = nil
+	delete(m.clearedFields, apikey.FieldScopes)
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (m *APIKeyMutation) SetAllowedModels(s []string) {
+	m.allowed_models = &s
+	m.appendallowed_models = nil
+}
+
+// AllowedModels returns the value of the "allowed_models" field in the mutation.
+func (m *APIKeyMutation) AllowedModels() (r []string, exists bool) {
+	v := m.allowed_models
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowedModels returns the old "allowed_models" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldAllowedModels(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedModels is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedModels requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedModels: %w", err)
+	}
+	return oldValue.AllowedModels, nil
+}
+
+// AppendAllowedModels adds s to the "allowed_models" field.
+func (m *APIKeyMutation) AppendAllowedModels(s []string) {
+	m.appendallowed_models = append(m.appendallowed_models, s...)
+}
+
+// AppendedAllowedModels returns the list of values that were appended to the "allowed_models" field in this mutation.
+func (m *APIKeyMutation) AppendedAllowedModels() ([]string, bool) {
+	if len(m.appendallowed_models) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_models, true
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (m *APIKeyMutation) ClearAllowedModels() {
+	m.allowed_models = nil
+	m.appendallowed_models = nil
+	m.clearedFields[apikey.FieldAllowedModels] = struct{}{}
+}
+
+// AllowedModelsCleared returns if the "allowed_models" field was cleared in this mutation.
+func (m *APIKeyMutation) AllowedModelsCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldAllowedModels]
+	return ok
+}
+
+// ResetAllowedModels resets all changes to the "allowed_models" field.
+func (m *APIKeyMutation) ResetAllowedModels() {
+	m.allowed_models = nil
+	m.appendallowed_models = nil
+	delete(m.clearedFields, apikey.FieldAllowedModels)
+}
+
 // SetQuota sets the "quota" field.
 func (m *APIKeyMutation) SetQuota(f float64) {
 	m.quota = &f
@@ -1382,6 +1658,55 @@ func (m *APIKeyMutation) ResetWindow7dStart() {
 	delete(m.clearedFields, apikey.FieldWindow7dStart)
 }
 
+// SetCaptureUntil sets the "capture_until" field.
+func (m *APIKeyMutation) SetCaptureUntil(t time.Time) {
+	m.capture_until = &t
+}
+
+// CaptureUntil returns the value of the "capture_until" field in the mutation.
+func (m *APIKeyMutation) CaptureUntil() (r time.Time, exists bool) {
+	v := m.capture_until
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCaptureUntil returns the old "capture_until" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldCaptureUntil(ctx context.Context) (v *time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCaptureUntil is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCaptureUntil requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCaptureUntil: %w", err)
+	}
+	return oldValue.CaptureUntil, nil
+}
+
+// ClearCaptureUntil clears the value of the "capture_until" field.
+func (m *APIKeyMutation) ClearCaptureUntil() {
+	m.capture_until = nil
+	m.clearedFields[apikey.FieldCaptureUntil] = struct{}{}
+}
+
+// CaptureUntilCleared returns if the "capture_until" field was cleared in this mutation.
+func (m *APIKeyMutation) CaptureUntilCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldCaptureUntil]
+	return ok
+}
+
+// ResetCaptureUntil resets all changes to the "capture_until" field.
+func (m *APIKeyMutation) ResetCaptureUntil() {
+	m.capture_until = nil
+	delete(m.clearedFields, apikey.FieldCaptureUntil)
+}
+
 // ClearUser clears the "user" edge to the User entity.
 func (m *APIKeyMutation) ClearUser() {
 	m.cleareduser = true
@@ -1524,7 +1849,7 @@ func (m *APIKeyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *APIKeyMutation) Fields() []string {
-	fields := make([]string, 0, 23)
+	fields := make([]string, 0, 29)
 	if m.created_at != nil {
 		fields = append(fields, apikey.FieldCreatedAt)
 	}
@@ -1558,6 +1883,21 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.ip_blacklist != nil {
 		fields = append(fields, apikey.FieldIPBlacklist)
 	}
+	if m.account_labels != nil {
+		fields = append(fields, apikey.FieldAccountLabels)
+	}
+	if m.response_cache_enabled != nil {
+		fields = append(fields, apikey.FieldResponseCacheEnabled)
+	}
+	if m.cost_preview_enabled != nil {
+		fields = append(fields, apikey.FieldCostPreviewEnabled)
+	}
+	if m.scopes != nil {
+		fields = append(fields, apikey.FieldScopes)
+	}
+	if m.allowed_models != nil {
+		fields = append(fields, apikey.FieldAllowedModels)
+	}
 	if m.quota != nil {
 		fields = append(fields, apikey.FieldQuota)
 	}
@@ -1594,6 +1934,9 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.window_7d_start != nil {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.capture_until != nil {
+		fields = append(fields, apikey.FieldCaptureUntil)
+	}
 	return fields
 }
 
@@ -1624,6 +1967,16 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.IPWhitelist()
 	case apikey.FieldIPBlacklist:
 		return m.IPBlacklist()
+	case apikey.FieldAccountLabels:
+		return m.AccountLabels()
+	case apikey.FieldResponseCacheEnabled:
+		return m.ResponseCacheEnabled()
+	case apikey.FieldCostPreviewEnabled:
+		return m.CostPreviewEnabled()
+	case apikey.FieldScopes:
+		return m.Scopes()
+	case apikey.FieldAllowedModels:
+		return m.AllowedModels()
 	case apikey.FieldQuota:
 		return m.Quota()
 	case apikey.FieldQuotaUsed:
@@ -1648,6 +2001,8 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.Window1dStart()
 	case apikey.FieldWindow7dStart:
 		return m.Window7dStart()
+	case apikey.FieldCaptureUntil:
+		return m.CaptureUntil()
 	}
 	return nil, false
 }
@@ -1679,6 +2034,16 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldIPWhitelist(ctx)
 	case apikey.FieldIPBlacklist:
 		return m.OldIPBlacklist(ctx)
+	case apikey.FieldAccountLabels:
+		return m.OldAccountLabels(ctx)
+	case apikey.FieldResponseCacheEnabled:
+		return m.OldResponseCacheEnabled(ctx)
+	case apikey.FieldCostPreviewEnabled:
+		return m.OldCostPreviewEnabled(ctx)
+	case apikey.FieldScopes:
+		return m.OldScopes(ctx)
+	case apikey.FieldAllowedModels:
+		return m.OldAllowedModels(ctx)
 	case apikey.FieldQuota:
 		return m.OldQuota(ctx)
 	case apikey.FieldQuotaUsed:
@@ -1703,6 +2068,8 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldWindow1dStart(ctx)
 	case apikey.FieldWindow7dStart:
 		return m.OldWindow7dStart(ctx)
+	case apikey.FieldCaptureUntil:
+		return m.OldCaptureUntil(ctx)
 	}
 	return nil, fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1789,6 +2156,41 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetIPBlacklist(v)
 		return nil
+	case apikey.FieldAccountLabels:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAccountLabels(v)
+		return nil
+	case apikey.FieldResponseCacheEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheEnabled(v)
+		return nil
+	case apikey.FieldCostPreviewEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCostPreviewEnabled(v)
+		return nil
+	case apikey.FieldScopes:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetScopes(v)
+		return nil
+	case apikey.FieldAllowedModels:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedModels(v)
+		return nil
 	case apikey.FieldQuota:
 		v, ok := value.(float64)
 		if !ok {
@@ -1873,6 +2275,13 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetWindow7dStart(v)
 		return nil
+	case apikey.FieldCaptureUntil:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCaptureUntil(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -2017,6 +2426,15 @@ func (m *APIKeyMutation) ClearedFields() []string {
 	if m.FieldCleared(apikey.FieldIPBlacklist) {
 		fields = append(fields, apikey.FieldIPBlacklist)
 	}
+	if m.FieldCleared(apikey.FieldAccountLabels) {
+		fields = append(fields, apikey.FieldAccountLabels)
+	}
+	if m.FieldCleared(apikey.FieldScopes) {
+		fields = append(fields, apikey.FieldScopes)
+	}
+	if m.FieldCleared(apikey.FieldAllowedModels) {
+		fields = append(fields, apikey.FieldAllowedModels)
+	}
 	if m.FieldCleared(apikey.FieldExpiresAt) {
 		fields = append(fields, apikey.FieldExpiresAt)
 	}
@@ -2029,6 +2447,9 @@ func (m *APIKeyMutation) ClearedFields() []string {
 	if m.FieldCleared(apikey.FieldWindow7dStart) {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.FieldCleared(apikey.FieldCaptureUntil) {
+		fields = append(fields, apikey.FieldCaptureUntil)
+	}
 	return fields
 }
 
@@ -2058,6 +2479,15 @@ func (m *APIKeyMutation) ClearField(name string) error {
 	case apikey.FieldIPBlacklist:
 		m.ClearIPBlacklist()
 		return nil
+	case apikey.FieldAccountLabels:
+		m.ClearAccountLabels()
+		return nil
+	case apikey.FieldScopes:
+		m.ClearScopes()
+		return nil
+	case apikey.FieldAllowedModels:
+		m.ClearAllowedModels()
+		return nil
 	case apikey.FieldExpiresAt:
 		m.ClearExpiresAt()
 		return nil
@@ -2070,6 +2500,9 @@ func (m *APIKeyMutation) ClearField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ClearWindow7dStart()
 		return nil
+	case apikey.FieldCaptureUntil:
+		m.ClearCaptureUntil()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey nullable field %s", name)
 }
@@ -2111,6 +2544,21 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldIPBlacklist:
 		m.ResetIPBlacklist()
 		return nil
+	case apikey.FieldAccountLabels:
+		m.ResetAccountLabels()
+		return nil
+	case apikey.FieldResponseCacheEnabled:
+		m.ResetResponseCacheEnabled()
+		return nil
+	case apikey.FieldCostPreviewEnabled:
+		m.ResetCostPreviewEnabled()
+		return nil
+	case apikey.FieldScopes:
+		m.ResetScopes()
+		return nil
+	case apikey.FieldAllowedModels:
+		m.ResetAllowedModels()
+		return nil
 	case apikey.FieldQuota:
 		m.ResetQuota()
 		return nil
@@ -2147,6 +2595,9 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ResetWindow7dStart()
 		return nil
+	case apikey.FieldCaptureUntil:
+		m.ResetCaptureUntil()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -13726,33 +14177,36 @@ func (m *ChannelMonitorRequestTemplateMutation) ResetEdge(name string) error {
 // ErrorPassthroughRuleMutation represents an operation that mutates the ErrorPassthroughRule nodes in the graph.
 type ErrorPassthroughRuleMutation struct {
 	config
-	op                Op
-	typ               string
-	id                *int64
-	created_at        *time.Time
-	updated_at        *time.Time
-	name              *string
-	enabled           *bool
-	priority          *int
-	addpriority       *int
-	error_codes       *[]int
-	appenderror_codes []int
-	keywords          *[]string
-	appendkeywords    []string
-	match_mode        *string
-	platforms         *[]string
-	appendplatforms   []string
-	passthrough_code  *bool
-	response_code     *int
-	addresponse_code  *int
-	passthrough_body  *bool
-	custom_message    *string
-	skip_monitoring   *bool
-	description       *string
-	clearedFields     map[string]struct{}
-	done              bool
-	oldValue          func(context.Context) (*ErrorPassthroughRule, error)
-	predicates        []predicate.ErrorPassthroughRule
+	op                    Op
+	typ                   string
+	id                    *int64
+	created_at            *time.Time
+	updated_at            *time.Time
+	name                  *string
+	enabled               *bool
+	priority              *int
+	addpriority           *int
+	error_codes           *[]int
+	appenderror_codes     []int
+	keywords              *[]string
+	appendkeywords        []string
+	match_mode            *string
+	platforms             *[]string
+	appendplatforms       []string
+	passthrough_code      *bool
+	response_code         *int
+	addresponse_code      *int
+	passthrough_body      *bool
+	custom_message        *string
+	skip_monitoring       *bool
+	description           *string
+	body_regex            *string
+	header_matchers       *[]map[string]string
+	appendheader_matchers []map[string]string
+	clearedFields         map[string]struct{}
+	done                  bool
+	oldValue              func(context.Context) (*ErrorPassthroughRule, error)
+	predicates            []predicate.ErrorPassthroughRule
 }
 
 var _ ent.Mutation = (*ErrorPassthroughRuleMutation)(nil)
@@ -14560,6 +15014,120 @@ func (m *ErrorPassthroughRuleMutation) ResetDescription() {
 	delete(m.clearedFields, errorpassthroughrule.FieldDescription)
 }
 
+// SetBodyRegex sets the "body_regex" field.
+func (m *ErrorPassthroughRuleMutation) SetBodyRegex(s string) {
+	m.body_regex = &s
+}
+
+// BodyRegex returns the value of the "body_regex" field in the mutation.
+func (m *ErrorPassthroughRuleMutation) BodyRegex() (r string, exists bool) {
+	v := m.body_regex
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBodyRegex returns the old "body_regex" field's value of the ErrorPassthroughRule entity.
+// If the ErrorPassthroughRule object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ErrorPassthroughRuleMutation) OldBodyRegex(ctx context.Context) (v *string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBodyRegex is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBodyRegex requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBodyRegex: %w", err)
+	}
+	return oldValue.BodyRegex, nil
+}
+
+// ClearBodyRegex clears the value of the "body_regex" field.
+func (m *ErrorPassthroughRuleMutation) ClearBodyRegex() {
+	m.body_regex = nil
+	m.clearedFields[errorpassthroughrule.FieldBodyRegex] = struct{}{}
+}
+
+// BodyRegexCleared returns if the "body_regex" field was cleared in this mutation.
+func (m *ErrorPassthroughRuleMutation) BodyRegexCleared() bool {
+	_, ok := m.clearedFields[errorpassthroughrule.FieldBodyRegex]
+	return ok
+}
+
+// ResetBodyRegex resets all changes to the "body_regex" field.
+func (m *ErrorPassthroughRuleMutation) ResetBodyRegex() {
+	m.body_regex = nil
+	delete(m.clearedFields, errorpassthroughrule.FieldBodyRegex)
+}
+
+// SetHeaderMatchers sets the "header_matchers" field.
+func (m *ErrorPassthroughRuleMutation) SetHeaderMatchers(value []map[string]string) {
+	m.header_matchers = &value
+	m.appendheader_matchers = nil
+}
+
+// HeaderMatchers returns the value of the "header_matchers" field in the mutation.
+func (m *ErrorPassthroughRuleMutation) HeaderMatchers() (r []map[string]string, exists bool) {
+	v := m.header_matchers
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldHeaderMatchers returns the old "header_matchers" field's value of the ErrorPassthroughRule entity.
+// If the ErrorPassthroughRule object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ErrorPassthroughRuleMutation) OldHeaderMatchers(ctx context.Context) (v []map[string]string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldHeaderMatchers is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldHeaderMatchers requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldHeaderMatchers: %w", err)
+	}
+	return oldValue.HeaderMatchers, nil
+}
+
+// AppendHeaderMatchers adds value to the "header_matchers" field.
+func (m *ErrorPassthroughRuleMutation) AppendHeaderMatchers(value []map[string]string) {
+	m.appendheader_matchers = append(m.appendheader_matchers, value...)
+}
+
+// AppendedHeaderMatchers returns the list of values that were appended to the "header_matchers" field in this mutation.
+func (m *ErrorPassthroughRuleMutation) AppendedHeaderMatchers() ([]map[string]string, bool) {
+	if len(m.appendheader_matchers) == 0 {
+		return nil, false
+	}
+	return m.appendheader_matchers, true
+}
+
+// ClearHeaderMatchers clears the value of the "header_matchers" field.
+func (m *ErrorPassthroughRuleMutation) ClearHeaderMatchers() {
+	m.header_matchers = nil
+	m.appendheader_matchers = nil
+	m.clearedFields[errorpassthroughrule.FieldHeaderMatchers] = struct{}{}
+}
+
+// HeaderMatchersCleared returns if the "header_matchers" field was cleared in this mutation.
+func (m *ErrorPassthroughRuleMutation) HeaderMatchersCleared() bool {
+	_, ok := m.clearedFields[errorpassthroughrule.FieldHeaderMatchers]
+	return ok
+}
+
+// ResetHeaderMatchers resets all changes to the "header_matchers" field.
+func (m *ErrorPassthroughRuleMutation) ResetHeaderMatchers() {
+	m.header_matchers = nil
+	m.appendheader_matchers = nil
+	delete(m.clearedFields, errorpassthroughrule.FieldHeaderMatchers)
+}
+
 // Where appends a list predicates to the ErrorPassthroughRuleMutation builder.
 func (m *ErrorPassthroughRuleMutation) Where(ps ...predicate.ErrorPassthroughRule) {
 	m.predicates = append(m.predicates, ps...)
@@ -14594,7 +15162,7 @@ func (m *ErrorPassthroughRuleMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *ErrorPassthroughRuleMutation) Fields() []string {
-	fields := make([]string, 0, 15)
+	fields := make([]string, 0, 17)
 	if m.created_at != nil {
 		fields = append(fields, errorpassthroughrule.FieldCreatedAt)
 	}
@@ -14640,6 +15208,12 @@ func (m *ErrorPassthroughRuleMutation) Fields() []string {
 	if m.description != nil {
 		fields = append(fields, errorpassthroughrule.FieldDescription)
 	}
+	if m.body_regex != nil {
+		fields = append(fields, errorpassthroughrule.FieldBodyRegex)
+	}
+	if m.header_matchers != nil {
+		fields = append(fields, errorpassthroughrule.FieldHeaderMatchers)
+	}
 	return fields
 }
 
@@ -14678,6 +15252,10 @@ func (m *ErrorPassthroughRuleMutation) Field(name string) (ent.Value, bool) {
 		return m.SkipMonitoring()
 	case errorpassthroughrule.FieldDescription:
 		return m.Description()
+	case errorpassthroughrule.FieldBodyRegex:
+		return m.BodyRegex()
+	case errorpassthroughrule.FieldHeaderMatchers:
+		return m.HeaderMatchers()
 	}
 	return nil, false
 }
@@ -14717,6 +15295,10 @@ func (m *ErrorPassthroughRuleMutation) OldField(ctx context.Context, name string
 		return m.OldSkipMonitoring(ctx)
 	case errorpassthroughrule.FieldDescription:
 		return m.OldDescription(ctx)
+	case errorpassthroughrule.FieldBodyRegex:
+		return m.OldBodyRegex(ctx)
+	case errorpassthroughrule.FieldHeaderMatchers:
+		return m.OldHeaderMatchers(ctx)
 	}
 	return nil, fmt.Errorf("unknown ErrorPassthroughRule field %s", name)
 }
@@ -14831,6 +15413,20 @@ func (m *ErrorPassthroughRuleMutation) SetField(name string, value ent.Value) er
 		}
 		m.SetDescription(v)
 		return nil
+	case errorpassthroughrule.FieldBodyRegex:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBodyRegex(v)
+		return nil
+	case errorpassthroughrule.FieldHeaderMatchers:
+		v, ok := value.([]map[string]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetHeaderMatchers(v)
+		return nil
 	}
 	return fmt.Errorf("unknown ErrorPassthroughRule field %s", name)
 }
@@ -14906,6 +15502,12 @@ func (m *ErrorPassthroughRuleMutation) ClearedFields() []string {
 	if m.FieldCleared(errorpassthroughrule.FieldDescription) {
 		fields = append(fields, errorpassthroughrule.FieldDescription)
 	}
+	if m.FieldCleared(errorpassthroughrule.FieldBodyRegex) {
+		fields = append(fields, errorpassthroughrule.FieldBodyRegex)
+	}
+	if m.FieldCleared(errorpassthroughrule.FieldHeaderMatchers) {
+		fields = append(fields, errorpassthroughrule.FieldHeaderMatchers)
+	}
 	return fields
 }
 
@@ -14938,6 +15540,12 @@ func (m *ErrorPassthroughRuleMutation) ClearField(name string) error {
 	case errorpassthroughrule.FieldDescription:
 		m.ClearDescription()
 		return nil
+	case errorpassthroughrule.FieldBodyRegex:
+		m.ClearBodyRegex()
+		return nil
+	case errorpassthroughrule.FieldHeaderMatchers:
+		m.ClearHeaderMatchers()
+		return nil
 	}
 	return fmt.Errorf("unknown ErrorPassthroughRule nullable field %s", name)
 }
@@ -14991,6 +15599,12 @@ func (m *ErrorPassthroughRuleMutation) ResetField(name string) error {
 	case errorpassthroughrule.FieldDescription:
 		m.ResetDescription()
 		return nil
+	case errorpassthroughrule.FieldBodyRegex:
+		m.ResetBodyRegex()
+		return nil
+	case errorpassthroughrule.FieldHeaderMatchers:
+		m.ResetHeaderMatchers()
+		return nil
 	}
 	return fmt.Errorf("unknown ErrorPassthroughRule field %s", name)
 }
@@ -15098,6 +15712,14 @@ type GroupMutation struct {
 	models_list_config                      *domain.GroupModelsListConfig
 	rpm_limit                               *int
 	addrpm_limit                            *int
+	prompt_cache_inject                     *bool
+	model_fallback_config                   *domain.GroupModelFallbackConfig
+	spend_cap_daily_usd                     *float64
+	addspend_cap_daily_usd                  *float64
+	spend_cap_monthly_usd                   *float64
+	addspend_cap_monthly_usd                *float64
+	spend_cap_timezone                      *string
+	instruction_injection_config            *domain.GroupInstructionInjectionConfig
 	clearedFields                           map[string]struct{}
 	api_keys                                map[int64]struct{}
 	removedapi_keys                         map[int64]struct{}
@@ -16906,6 +17528,290 @@ func (m *GroupMutation) ResetRpmLimit() {
 	m.addrpm_limit = nil
 }
 
+// SetPromptCacheInject sets the "prompt_cache_inject" field.
+func (m *GroupMutation) SetPromptCacheInject(b bool) {
+	m.prompt_cache_inject = &b
+}
+
+// PromptCacheInject returns the value of the "prompt_cache_inject" field in the mutation.
+func (m *GroupMutation) PromptCacheInject() (r bool, exists bool) {
+	v := m.prompt_cache_inject
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPromptCacheInject returns the old "prompt_cache_inject" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldPromptCacheInject(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPromptCacheInject is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPromptCacheInject requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPromptCacheInject: %w", err)
+	}
+	return oldValue.PromptCacheInject, nil
+}
+
+// ResetPromptCacheInject resets all changes to the "prompt_cache_inject" field.
+func (m *GroupMutation) ResetPromptCacheInject() {
+	m.prompt_cache_inject = nil
+}
+
+// SetModelFallbackConfig sets the "model_fallback_config" field.
+func (m *GroupMutation) SetModelFallbackConfig(dmfc domain.GroupModelFallbackConfig) {
+	m.model_fallback_config = &dmfc
+}
+
+// ModelFallbackConfig returns the value of the "model_fallback_config" field in the mutation.
+func (m *GroupMutation) ModelFallbackConfig() (r domain.GroupModelFallbackConfig, exists bool) {
+	v := m.model_fallback_config
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModelFallbackConfig returns the old "model_fallback_config" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldModelFallbackConfig(ctx context.Context) (v domain.GroupModelFallbackConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModelFallbackConfig is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModelFallbackConfig requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModelFallbackConfig: %w", err)
+	}
+	return oldValue.ModelFallbackConfig, nil
+}
+
+// ResetModelFallbackConfig resets all changes to the "model_fallback_config" field.
+func (m *GroupMutation) ResetModelFallbackConfig() {
+	m.model_fallback_config = nil
+}
+
+// SetSpendCapDailyUsd sets the "spend_cap_daily_usd" field.
+func (m *GroupMutation) SetSpendCapDailyUsd(f float64) {
+	m.spend_cap_daily_usd = &f
+	m.addspend_cap_daily_usd = nil
+}
+
+// SpendCapDailyUsd returns the value of the "spend_cap_daily_usd" field in the mutation.
+func (m *GroupMutation) SpendCapDailyUsd() (r float64, exists bool) {
+	v := m.spend_cap_daily_usd
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSpendCapDailyUsd returns the old "spend_cap_daily_usd" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldSpendCapDailyUsd(ctx context.Context) (v *float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSpendCapDailyUsd is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSpendCapDailyUsd requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSpendCapDailyUsd: %w", err)
+	}
+	return oldValue.SpendCapDailyUsd, nil
+}
+
+// AddSpendCapDailyUsd adds f to the "spend_cap_daily_usd" field.
+func (m *GroupMutation) AddSpendCapDailyUsd(f float64) {
+	if m.addspend_cap_daily_usd != nil {
+		*m.addspend_cap_daily_usd += f
+	} else {
+		m.addspend_cap_daily_usd = &f
+	}
+}
+
+// AddedSpendCapDailyUsd returns the value that was added to the "spend_cap_daily_usd" field in this mutation.
+func (m *GroupMutation) AddedSpendCapDailyUsd() (r float64, exists bool) {
+	v := m.addspend_cap_daily_usd
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearSpendCapDailyUsd clears the value of the "spend_cap_daily_usd" field.
+func (m *GroupMutation) ClearSpendCapDailyUsd() {
+	m.spend_cap_daily_usd = nil
+	m.addspend_cap_daily_usd = nil
+	m.clearedFields[group.FieldSpendCapDailyUsd] = struct{}{}
+}
+
+// SpendCapDailyUsdCleared returns if the "spend_cap_daily_usd" field was cleared in this mutation.
+func (m *GroupMutation) SpendCapDailyUsdCleared() bool {
+	_, ok := m.clearedFields[group.FieldSpendCapDailyUsd]
+	return ok
+}
+
+// ResetSpendCapDailyUsd resets all changes to the "spend_cap_daily_usd" field.
+func (m *GroupMutation) ResetSpendCapDailyUsd() {
+	m.spend_cap_daily_usd = nil
+	m.addspend_cap_daily_usd = nil
+	delete(m.clearedFields, group.FieldSpendCapDailyUsd)
+}
+
+// SetSpendCapMonthlyUsd sets the "spend_cap_monthly_usd" field.
+func (m *GroupMutation) SetSpendCapMonthlyUsd(f float64) {
+	m.spend_cap_monthly_usd = &f
+	m.addspend_cap_monthly_usd = nil
+}
+
+// SpendCapMonthlyUsd returns the value of the "spend_cap_monthly_usd" field in the mutation.
+func (m *GroupMutation) SpendCapMonthlyUsd() (r float64, exists bool) {
+	v := m.spend_cap_monthly_usd
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSpendCapMonthlyUsd returns the old "spend_cap_monthly_usd" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldSpendCapMonthlyUsd(ctx context.Context) (v *float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSpendCapMonthlyUsd is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSpendCapMonthlyUsd requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSpendCapMonthlyUsd: %w", err)
+	}
+	return oldValue.SpendCapMonthlyUsd, nil
+}
+
+// AddSpendCapMonthlyUsd adds f to the "spend_cap_monthly_usd" field.
+func (m *GroupMutation) AddSpendCapMonthlyUsd(f float64) {
+	if m.addspend_cap_monthly_usd != nil {
+		*m.addspend_cap_monthly_usd += f
+	} else {
+		m.addspend_cap_monthly_usd = &f
+	}
+}
+
+// AddedSpendCapMonthlyUsd returns the value that was added to the "spend_cap_monthly_usd" field in this mutation.
+func (m *GroupMutation) AddedSpendCapMonthlyUsd() (r float64, exists bool) {
+	v := m.addspend_cap_monthly_usd
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearSpendCapMonthlyUsd clears the value of the "spend_cap_monthly_usd" field.
+func (m *GroupMutation) ClearSpendCapMonthlyUsd() {
+	m.spend_cap_monthly_usd = nil
+	m.addspend_cap_monthly_usd = nil
+	m.clearedFields[group.FieldSpendCapMonthlyUsd] = struct{}{}
+}
+
+// SpendCapMonthlyUsdCleared returns if the "spend_cap_monthly_usd" field was cleared in this mutation.
+func (m *GroupMutation) SpendCapMonthlyUsdCleared() bool {
+	_, ok := m.clearedFields[group.FieldSpendCapMonthlyUsd]
+	return ok
+}
+
+// ResetSpendCapMonthlyUsd resets all changes to the "spend_cap_monthly_usd" field.
+func (m *GroupMutation) ResetSpendCapMonthlyUsd() {
+	m.spend_cap_monthly_usd = nil
+	m.addspend_cap_monthly_usd = nil
+	delete(m.clearedFields, group.FieldSpendCapMonthlyUsd)
+}
+
+// SetSpendCapTimezone sets the "spend_cap_timezone" field.
+func (m *GroupMutation) SetSpendCapTimezone(s string) {
+	m.spend_cap_timezone = &s
+}
+
+// SpendCapTimezone returns the value of the "spend_cap_timezone" field in the mutation.
+func (m *GroupMutation) SpendCapTimezone() (r string, exists bool) {
+	v := m.spend_cap_timezone
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSpendCapTimezone returns the old "spend_cap_timezone" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldSpendCapTimezone(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSpendCapTimezone is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSpendCapTimezone requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSpendCapTimezone: %w", err)
+	}
+	return oldValue.SpendCapTimezone, nil
+}
+
+// ResetSpendCapTimezone resets all changes to the "spend_cap_timezone" field.
+func (m *GroupMutation) ResetSpendCapTimezone() {
+	m.spend_cap_timezone = nil
+}
+
+// SetInstructionInjectionConfig sets the "instruction_injection_config" field.
+func (m *GroupMutation) SetInstructionInjectionConfig(diic domain.GroupInstructionInjectionConfig) {
+	m.instruction_injection_config = &diic
+}
+
+// InstructionInjectionConfig returns the value of the "instruction_injection_config" field in the mutation.
+func (m *GroupMutation) InstructionInjectionConfig() (r domain.GroupInstructionInjectionConfig, exists bool) {
+	v := m.instruction_injection_config
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldInstructionInjectionConfig returns the old "instruction_injection_config" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldInstructionInjectionConfig(ctx context.Context) (v domain.GroupInstructionInjectionConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldInstructionInjectionConfig is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldInstructionInjectionConfig requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldInstructionInjectionConfig: %w", err)
+	}
+	return oldValue.InstructionInjectionConfig, nil
+}
+
+// ResetInstructionInjectionConfig resets all changes to the "instruction_injection_config" field.
+func (m *GroupMutation) ResetInstructionInjectionConfig() {
+	m.instruction_injection_config = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *GroupMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -17264,7 +18170,7 @@ func (m *GroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *GroupMutation) Fields() []string {
-	fields := make([]string, 0, 35)
+	fields := make([]string, 0, 41)
 	if m.created_at != nil {
 		fields = append(fields, group.FieldCreatedAt)
 	}
@@ -17370,6 +18276,24 @@ func (m *GroupMutation) Fields() []string {
 	if m.rpm_limit != nil {
 		fields = append(fields, group.FieldRpmLimit)
 	}
+	if m.prompt_cache_inject != nil {
+		fields = append(fields, group.FieldPromptCacheInject)
+	}
+	if m.model_fallback_config != nil {
+		fields = append(fields, group.FieldModelFallbackConfig)
+	}
+	if m.spend_cap_daily_usd != nil {
+		fields = append(fields, group.FieldSpendCapDailyUsd)
+	}
+	if m.spend_cap_monthly_usd != nil {
+		fields = append(fields, group.FieldSpendCapMonthlyUsd)
+	}
+	if m.spend_cap_timezone != nil {
+		fields = append(fields, group.FieldSpendCapTimezone)
+	}
+	if m.instruction_injection_config != nil {
+		fields = append(fields, group.FieldInstructionInjectionConfig)
+	}
 	return fields
 }
 
@@ -17448,6 +18372,18 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.ModelsListConfig()
 	case group.FieldRpmLimit:
 		return m.RpmLimit()
+	case group.FieldPromptCacheInject:
+		return m.PromptCacheInject()
+	case group.FieldModelFallbackConfig:
+		return m.ModelFallbackConfig()
+	case group.FieldSpendCapDailyUsd:
+		return m.SpendCapDailyUsd()
+	case group.FieldSpendCapMonthlyUsd:
+		return m.SpendCapMonthlyUsd()
+	case group.FieldSpendCapTimezone:
+		return m.SpendCapTimezone()
+	case group.FieldInstructionInjectionConfig:
+		return m.InstructionInjectionConfig()
 	}
 	return nil, false
 }
@@ -17527,6 +18463,18 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldModelsListConfig(ctx)
 	case group.FieldRpmLimit:
 		return m.OldRpmLimit(ctx)
+	case group.FieldPromptCacheInject:
+		return m.OldPromptCacheInject(ctx)
+	case group.FieldModelFallbackConfig:
+		return m.OldModelFallbackConfig(ctx)
+	case group.FieldSpendCapDailyUsd:
+		return m.OldSpendCapDailyUsd(ctx)
+	case group.FieldSpendCapMonthlyUsd:
+		return m.OldSpendCapMonthlyUsd(ctx)
+	case group.FieldSpendCapTimezone:
+		return m.OldSpendCapTimezone(ctx)
+	case group.FieldInstructionInjectionConfig:
+		return m.OldInstructionInjectionConfig(ctx)
 	}
 	return nil, fmt.Errorf("unknown Group field %s", name)
 }
@@ -17781,6 +18729,48 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetRpmLimit(v)
 		return nil
+	case group.FieldPromptCacheInject:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPromptCacheInject(v)
+		return nil
+	case group.FieldModelFallbackConfig:
+		v, ok := value.(domain.GroupModelFallbackConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModelFallbackConfig(v)
+		return nil
+	case group.FieldSpendCapDailyUsd:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSpendCapDailyUsd(v)
+		return nil
+	case group.FieldSpendCapMonthlyUsd:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSpendCapMonthlyUsd(v)
+		return nil
+	case group.FieldSpendCapTimezone:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSpendCapTimezone(v)
+		return nil
+	case group.FieldInstructionInjectionConfig:
+		v, ok := value.(domain.GroupInstructionInjectionConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetInstructionInjectionConfig(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -17828,6 +18818,12 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addrpm_limit != nil {
 		fields = append(fields, group.FieldRpmLimit)
 	}
+	if m.addspend_cap_daily_usd != nil {
+		fields = append(fields, group.FieldSpendCapDailyUsd)
+	}
+	if m.addspend_cap_monthly_usd != nil {
+		fields = append(fields, group.FieldSpendCapMonthlyUsd)
+	}
 	return fields
 }
 
@@ -17862,6 +18858,10 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedSortOrder()
 	case group.FieldRpmLimit:
 		return m.AddedRpmLimit()
+	case group.FieldSpendCapDailyUsd:
+		return m.AddedSpendCapDailyUsd()
+	case group.FieldSpendCapMonthlyUsd:
+		return m.AddedSpendCapMonthlyUsd()
 	}
 	return nil, false
 }
@@ -17962,6 +18962,20 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddRpmLimit(v)
 		return nil
+	case group.FieldSpendCapDailyUsd:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddSpendCapDailyUsd(v)
+		return nil
+	case group.FieldSpendCapMonthlyUsd:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddSpendCapMonthlyUsd(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group numeric field %s", name)
 }
@@ -18003,6 +19017,12 @@ func (m *GroupMutation) ClearedFields() []string {
 	if m.FieldCleared(group.FieldModelRouting) {
 		fields = append(fields, group.FieldModelRouting)
 	}
+	if m.FieldCleared(group.FieldSpendCapDailyUsd) {
+		fields = append(fields, group.FieldSpendCapDailyUsd)
+	}
+	if m.FieldCleared(group.FieldSpendCapMonthlyUsd) {
+		fields = append(fields, group.FieldSpendCapMonthlyUsd)
+	}
 	return fields
 }
 
@@ -18050,6 +19070,12 @@ func (m *GroupMutation) ClearField(name string) error {
 	case group.FieldModelRouting:
 		m.ClearModelRouting()
 		return nil
+	case group.FieldSpendCapDailyUsd:
+		m.ClearSpendCapDailyUsd()
+		return nil
+	case group.FieldSpendCapMonthlyUsd:
+		m.ClearSpendCapMonthlyUsd()
+		return nil
 	}
 	return fmt.Errorf("unknown Group nullable field %s", name)
 }
@@ -18163,6 +19189,24 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldRpmLimit:
 		m.ResetRpmLimit()
 		return nil
+	case group.FieldPromptCacheInject:
+		m.ResetPromptCacheInject()
+		return nil
+	case group.FieldModelFallbackConfig:
+		m.ResetModelFallbackConfig()
+		return nil
+	case group.FieldSpendCapDailyUsd:
+		m.ResetSpendCapDailyUsd()
+		return nil
+	case group.FieldSpendCapMonthlyUsd:
+		m.ResetSpendCapMonthlyUsd()
+		return nil
+	case group.FieldSpendCapTimezone:
+		m.ResetSpendCapTimezone()
+		return nil
+	case group.FieldInstructionInjectionConfig:
+		m.ResetInstructionInjectionConfig()
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -33895,8 +34939,8 @@ type UsageCleanupTaskMutation struct {
 	created_at      *time.Time
 	updated_at      *time.Time
 	status          *string
-	filters         *json.RawMessage
-	appendfilters   json.RawMessage
+	filters         *jsontext.Value
+	appendfilters   jsontext.Value
 	created_by      *int64
 	addcreated_by   *int64
 	deleted_rows    *int64
@@ -34120,13 +35164,13 @@ func (m *UsageCleanupTaskMutation) ResetStatus() {
 }
 
 // SetFilters sets the "filters" field.
-func (m *UsageCleanupTaskMutation) SetFilters(jm json.RawMessage) {
-	m.filters = &jm
+func (m *UsageCleanupTaskMutation) SetFilters(j jsontext.Value) {
+	m.filters = &j
 	m.appendfilters = nil
 }
 
 // Filters returns the value of the "filters" field in the mutation.
-func (m *UsageCleanupTaskMutation) Filters() (r json.RawMessage, exists bool) {
+func (m *UsageCleanupTaskMutation) Filters() (r jsontext.Value, exists bool) {
 	v := m.filters
 	if v == nil {
 		return
@@ -34137,7 +35181,7 @@ func (m *UsageCleanupTaskMutation) Filters() (r json.RawMessage, exists bool) {
 // OldFilters returns the old "filters" field's value of the UsageCleanupTask entity.
 // If the UsageCleanupTask object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMessage, err error) {
+func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v jsontext.Value, err error) {
 	if !m.op.Is(OpUpdateOne) {
 		return v, errors.New("OldFilters is only allowed on UpdateOne operations")
 	}
@@ -34151,13 +35195,13 @@ func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMe
 	return oldValue.Filters, nil
 }
 
-// AppendFilters adds jm to the "filters" field.
-func (m *UsageCleanupTaskMutation) AppendFilters(jm json.RawMessage) {
-	m.appendfilters = append(m.appendfilters, jm...)
+// AppendFilters adds j to the "filters" field.
+func (m *UsageCleanupTaskMutation) AppendFilters(j jsontext.Value) {
+	m.appendfilters = append(m.appendfilters, j...)
 }
 
 // AppendedFilters returns the list of values that were appended to the "filters" field in this mutation.
-func (m *UsageCleanupTaskMutation) AppendedFilters() (json.RawMessage, bool) {
+func (m *UsageCleanupTaskMutation) AppendedFilters() (jsontext.Value, bool) {
 	if len(m.appendfilters) == 0 {
 		return nil, false
 	}
@@ -34708,7 +35752,7 @@ func (m *UsageCleanupTaskMutation) SetField(name string, value ent.Value) error
 		m.SetStatus(v)
 		return nil
 	case usagecleanuptask.FieldFilters:
-		v, ok := value.(json.RawMessage)
+		v, ok := value.(jsontext.Value)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
@@ -35029,6 +36073,17 @@ type UsageLogMutation struct {
 	image_size_source           *string
 	image_size_breakdown        *map[string]int
 	cache_ttl_overridden        *bool
+	usage_estimated             *bool
+	billing_unverified          *bool
+	request_bytes               *int64
+	addrequest_bytes            *int64
+	response_bytes              *int64
+	addresponse_bytes           *int64
+	response_cache_hit          *bool
+	pricing_unavailable         *bool
+	estimated_cost              *float64
+	addestimated_cost           *float64
+	cost_overage                *bool
 	created_at                  *time.Time
 	clearedFields               map[string]struct{}
 	user                        *int64
@@ -37202,6 +38257,396 @@ func (m *UsageLogMutation) ResetCacheTTLOverridden() {
 	m.cache_ttl_overridden = nil
 }
 
+// SetUsageEstimated sets the "usage_estimated" field.
+func (m *UsageLogMutation) SetUsageEstimated(b bool) {
+	m.usage_estimated = &b
+}
+
+// UsageEstimated returns the value of the "usage_estimated" field in the mutation.
+func (m *UsageLogMutation) UsageEstimated() (r bool, exists bool) {
+	v := m.usage_estimated
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldUsageEstimated returns the old "usage_estimated" field's value of the UsageLog entity.
+// If the UsageLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UsageLogMutation) OldUsageEstimated(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldUsageEstimated is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldUsageEstimated requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldUsageEstimated: %w", err)
+	}
+	return oldValue.UsageEstimated, nil
+}
+
+// ResetUsageEstimated resets all changes to the "usage_estimated" field.
+func (m *UsageLogMutation) ResetUsageEstimated() {
+	m.usage_estimated = nil
+}
+
+// SetBillingUnverified sets the "billing_unverified" field.
+func (m *UsageLogMutation) SetBillingUnverified(b bool) {
+	m.billing_unverified = &b
+}
+
+// BillingUnverified returns the value of the "billing_unverified" field in the mutation.
+func (m *UsageLogMutation) BillingUnverified() (r bool, exists bool) {
+	v := m.billing_unverified
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBillingUnverified returns the old "billing_unverified" field's value of the UsageLog entity.
+// If the UsageLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UsageLogMutation) OldBillingUnverified(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBillingUnverified is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBillingUnverified requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBillingUnverified: %w", err)
+	}
+	return oldValue.BillingUnverified, nil
+}
+
+// ResetBillingUnverified resets all changes to the "billing_unverified" field.
+func (m *UsageLogMutation) ResetBillingUnverified() {
+	m.billing_unverified = nil
+}
+
+// SetRequestBytes sets the "request_bytes" field.
+func (m *UsageLogMutation) SetRequestBytes(i int64) {
+	m.request_bytes = &i
+	m.addrequest_bytes = nil
+}
+
+// RequestBytes returns the value of the "request_bytes" field in the mutation.
+func (m *UsageLogMutation) RequestBytes() (r int64, exists bool) {
+	v := m.request_bytes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequestBytes returns the old "request_bytes" field's value of the UsageLog entity.
+// If the UsageLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UsageLogMutation) OldRequestBytes(ctx context.Context) (v *int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequestBytes is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequestBytes requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequestBytes: %w", err)
+	}
+	return oldValue.RequestBytes, nil
+}
+
+// AddRequestBytes adds i to the "request_bytes" field.
+func (m *UsageLogMutation) AddRequestBytes(i int64) {
+	if m.addrequest_bytes != nil {
+		*m.addrequest_bytes += i
+	} else {
+		m.addrequest_bytes = &i
+	}
+}
+
+// AddedRequestBytes returns the value that was added to the "request_bytes" field in this mutation.
+func (m *UsageLogMutation) AddedRequestBytes() (r int64, exists bool) {
+	v := m.addrequest_bytes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearRequestBytes clears the value of the "request_bytes" field.
+func (m *UsageLogMutation) ClearRequestBytes() {
+	m.request_bytes = nil
+	m.addrequest_bytes = nil
+	m.clearedFields[usagelog.FieldRequestBytes] = struct{}{}
+}
+
+// RequestBytesCleared returns if the "request_bytes" field was cleared in this mutation.
+func (m *UsageLogMutation) RequestBytesCleared() bool {
+	_, ok := m.clearedFields[usagelog.FieldRequestBytes]
+	return ok
+}
+
+// ResetRequestBytes resets all changes to the "request_bytes" field.
+func (m *UsageLogMutation) ResetRequestBytes() {
+	m.request_bytes = nil
+	m.addrequest_bytes = nil
+	delete(m.clearedFields, usagelog.FieldRequestBytes)
+}
+
+// SetResponseBytes sets the "response_bytes" field.
+func (m *UsageLogMutation) SetResponseBytes(i int64) {
+	m.response_bytes = &i
+	m.addresponse_bytes = nil
+}
+
+// ResponseBytes returns the value of the "response_bytes" field in the mutation.
+func (m *UsageLogMutation) ResponseBytes() (r int64, exists bool) {
+	v := m.response_bytes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseBytes returns the old "response_bytes" field's value of the UsageLog entity.
+// If the UsageLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UsageLogMutation) OldResponseBytes(ctx context.Context) (v *int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseBytes is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseBytes requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseBytes: %w", err)
+	}
+	return oldValue.ResponseBytes, nil
+}
+
+// AddResponseBytes adds i to the "response_bytes" field.
+func (m *UsageLogMutation) AddResponseBytes(i int64) {
+	if m.addresponse_bytes != nil {
+		*m.addresponse_bytes += i
+	} else {
+		m.addresponse_bytes = &i
+	}
+}
+
+// AddedResponseBytes returns the value that was added to the "response_bytes" field in this mutation.
+func (m *UsageLogMutation) AddedResponseBytes() (r int64, exists bool) {
+	v := m.addresponse_bytes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearResponseBytes clears the value of the "response_bytes" field.
+func (m *UsageLogMutation) ClearResponseBytes() {
+	m.response_bytes = nil
+	m.addresponse_bytes = nil
+	m.clearedFields[usagelog.FieldResponseBytes] = struct{}{}
+}
+
+// ResponseBytesCleared returns if the "response_bytes" field was cleared in this mutation.
+func (m *UsageLogMutation) ResponseBytesCleared() bool {
+	_, ok := m.clearedFields[usagelog.FieldResponseBytes]
+	return ok
+}
+
+// ResetResponseBytes resets all changes to the "response_bytes" field.
+func (m *UsageLogMutation) ResetResponseBytes() {
+	m.response_bytes = nil
+	m.addresponse_bytes = nil
+	delete(m.clearedFields, usagelog.FieldResponseBytes)
+}
+
+// SetResponseCacheHit sets the "response_cache_hit" field.
+func (m *UsageLogMutation) SetResponseCacheHit(b bool) {
+	m.response_cache_hit = &b
+}
+
+// ResponseCacheHit returns the value of the "response_cache_hit" field in the mutation.
+func (m *UsageLogMutation) ResponseCacheHit() (r bool, exists bool) {
+	v := m.response_cache_hit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheHit returns the old "response_cache_hit" field's value of the UsageLog entity.
+// If the UsageLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UsageLogMutation) OldResponseCacheHit(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheHit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheHit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheHit: %w", err)
+	}
+	return oldValue.ResponseCacheHit, nil
+}
+
+// ResetResponseCacheHit resets all changes to the "response_cache_hit" field.
+func (m *UsageLogMutation) ResetResponseCacheHit() {
+	m.response_cache_hit = nil
+}
+
+// SetPricingUnavailable sets the "pricing_unavailable" field.
+func (m *UsageLogMutation) SetPricingUnavailable(b bool) {
+	m.pricing_unavailable = &b
+}
+
+// PricingUnavailable returns the value of the "pricing_unavailable" field in the mutation.
+func (m *UsageLogMutation) PricingUnavailable() (r bool, exists bool) {
+	v := m.pricing_unavailable
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPricingUnavailable returns the old "pricing_unavailable" field's value of the UsageLog entity.
+// If the UsageLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UsageLogMutation) OldPricingUnavailable(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPricingUnavailable is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPricingUnavailable requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPricingUnavailable: %w", err)
+	}
+	return oldValue.PricingUnavailable, nil
+}
+
+// ResetPricingUnavailable resets all changes to the "pricing_unavailable" field.
+func (m *UsageLogMutation) ResetPricingUnavailable() {
+	m.pricing_unavailable = nil
+}
+
+// SetEstimatedCost sets the "estimated_cost" field.
+func (m *UsageLogMutation) SetEstimatedCost(f float64) {
+	m.estimated_cost = &f
+	m.addestimated_cost = nil
+}
+
+// EstimatedCost returns the value of the "estimated_cost" field in the mutation.
+func (m *UsageLogMutation) EstimatedCost() (r float64, exists bool) {
+	v := m.estimated_cost
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldEstimatedCost returns the old "estimated_cost" field's value of the UsageLog entity.
+// If the UsageLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UsageLogMutation) OldEstimatedCost(ctx context.Context) (v *float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldEstimatedCost is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldEstimatedCost requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldEstimatedCost: %w", err)
+	}
+	return oldValue.EstimatedCost, nil
+}
+
+// AddEstimatedCost adds f to the "estimated_cost" field.
+func (m *UsageLogMutation) AddEstimatedCost(f float64) {
+	if m.addestimated_cost != nil {
+		*m.addestimated_cost += f
+	} else {
+		m.addestimated_cost = &f
+	}
+}
+
+// AddedEstimatedCost returns the value that was added to the "estimated_cost" field in this mutation.
+func (m *UsageLogMutation) AddedEstimatedCost() (r float64, exists bool) {
+	v := m.addestimated_cost
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearEstimatedCost clears the value of the "estimated_cost" field.
+func (m *UsageLogMutation) ClearEstimatedCost() {
+	m.estimated_cost = nil
+	m.addestimated_cost = nil
+	m.clearedFields[usagelog.FieldEstimatedCost] = struct{}{}
+}
+
+// EstimatedCostCleared returns if the "estimated_cost" field was cleared in this mutation.
+func (m *UsageLogMutation) EstimatedCostCleared() bool {
+	_, ok := m.clearedFields[usagelog.FieldEstimatedCost]
+	return ok
+}
+
+// ResetEstimatedCost resets all changes to the "estimated_cost" field.
+func (m *UsageLogMutation) ResetEstimatedCost() {
+	m.estimated_cost = nil
+	m.addestimated_cost = nil
+	delete(m.clearedFields, usagelog.FieldEstimatedCost)
+}
+
+// SetCostOverage sets the "cost_overage" field.
+func (m *UsageLogMutation) SetCostOverage(b bool) {
+	m.cost_overage = &b
+}
+
+// CostOverage returns the value of the "cost_overage" field in the mutation.
+func (m *UsageLogMutation) CostOverage() (r bool, exists bool) {
+	v := m.cost_overage
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCostOverage returns the old "cost_overage" field's value of the UsageLog entity.
+// If the UsageLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UsageLogMutation) OldCostOverage(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCostOverage is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCostOverage requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCostOverage: %w", err)
+	}
+	return oldValue.CostOverage, nil
+}
+
+// ResetCostOverage resets all changes to the "cost_overage" field.
+func (m *UsageLogMutation) ResetCostOverage() {
+	m.cost_overage = nil
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (m *UsageLogMutation) SetCreatedAt(t time.Time) {
 	m.created_at = &t
@@ -37407,7 +38852,7 @@ func (m *UsageLogMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *UsageLogMutation) Fields() []string {
-	fields := make([]string, 0, 41)
+	fields := make([]string, 0, 49)
 	if m.user != nil {
 		fields = append(fields, usagelog.FieldUserID)
 	}
@@ -37528,6 +38973,30 @@ func (m *UsageLogMutation) Fields() []string {
 	if m.cache_ttl_overridden != nil {
 		fields = append(fields, usagelog.FieldCacheTTLOverridden)
 	}
+	if m.usage_estimated != nil {
+		fields = append(fields, usagelog.FieldUsageEstimated)
+	}
+	if m.billing_unverified != nil {
+		fields = append(fields, usagelog.FieldBillingUnverified)
+	}
+	if m.request_bytes != nil {
+		fields = append(fields, usagelog.FieldRequestBytes)
+	}
+	if m.response_bytes != nil {
+		fields = append(fields, usagelog.FieldResponseBytes)
+	}
+	if m.response_cache_hit != nil {
+		fields = append(fields, usagelog.FieldResponseCacheHit)
+	}
+	if m.pricing_unavailable != nil {
+		fields = append(fields, usagelog.FieldPricingUnavailable)
+	}
+	if m.estimated_cost != nil {
+		fields = append(fields, usagelog.FieldEstimatedCost)
+	}
+	if m.cost_overage != nil {
+		fields = append(fields, usagelog.FieldCostOverage)
+	}
 	if m.created_at != nil {
 		fields = append(fields, usagelog.FieldCreatedAt)
 	}
@@ -37619,6 +39088,22 @@ func (m *UsageLogMutation) Field(name string) (ent.Value, bool) {
 		return m.ImageSizeBreakdown()
 	case usagelog.FieldCacheTTLOverridden:
 		return m.CacheTTLOverridden()
+	case usagelog.FieldUsageEstimated:
+		return m.UsageEstimated()
+	case usagelog.FieldBillingUnverified:
+		return m.BillingUnverified()
+	case usagelog.FieldRequestBytes:
+		return m.RequestBytes()
+	case usagelog.FieldResponseBytes:
+		return m.ResponseBytes()
+	case usagelog.FieldResponseCacheHit:
+		return m.ResponseCacheHit()
+	case usagelog.FieldPricingUnavailable:
+		return m.PricingUnavailable()
+	case usagelog.FieldEstimatedCost:
+		return m.EstimatedCost()
+	case usagelog.FieldCostOverage:
+		return m.CostOverage()
 	case usagelog.FieldCreatedAt:
 		return m.CreatedAt()
 	}
@@ -37710,6 +39195,22 @@ func (m *UsageLogMutation) OldField(ctx context.Context, name string) (ent.Value
 		return m.OldImageSizeBreakdown(ctx)
 	case usagelog.FieldCacheTTLOverridden:
 		return m.OldCacheTTLOverridden(ctx)
+	case usagelog.FieldUsageEstimated:
+		return m.OldUsageEstimated(ctx)
+	case usagelog.FieldBillingUnverified:
+		return m.OldBillingUnverified(ctx)
+	case usagelog.FieldRequestBytes:
+		return m.OldRequestBytes(ctx)
+	case usagelog.FieldResponseBytes:
+		return m.OldResponseBytes(ctx)
+	case usagelog.FieldResponseCacheHit:
+		return m.OldResponseCacheHit(ctx)
+	case usagelog.FieldPricingUnavailable:
+		return m.OldPricingUnavailable(ctx)
+	case usagelog.FieldEstimatedCost:
+		return m.OldEstimatedCost(ctx)
+	case usagelog.FieldCostOverage:
+		return m.OldCostOverage(ctx)
 	case usagelog.FieldCreatedAt:
 		return m.OldCreatedAt(ctx)
 	}
@@ -38001,6 +39502,62 @@ func (m *UsageLogMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetCacheTTLOverridden(v)
 		return nil
+	case usagelog.FieldUsageEstimated:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetUsageEstimated(v)
+		return nil
+	case usagelog.FieldBillingUnverified:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBillingUnverified(v)
+		return nil
+	case usagelog.FieldRequestBytes:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestBytes(v)
+		return nil
+	case usagelog.FieldResponseBytes:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseBytes(v)
+		return nil
+	case usagelog.FieldResponseCacheHit:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheHit(v)
+		return nil
+	case usagelog.FieldPricingUnavailable:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPricingUnavailable(v)
+		return nil
+	case usagelog.FieldEstimatedCost:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetEstimatedCost(v)
+		return nil
+	case usagelog.FieldCostOverage:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCostOverage(v)
+		return nil
 	case usagelog.FieldCreatedAt:
 		v, ok := value.(time.Time)
 		if !ok {
@@ -38073,6 +39630,15 @@ func (m *UsageLogMutation) AddedFields() []string {
 	if m.addimage_count != nil {
 		fields = append(fields, usagelog.FieldImageCount)
 	}
+	if m.addrequest_bytes != nil {
+		fields = append(fields, usagelog.FieldRequestBytes)
+	}
+	if m.addresponse_bytes != nil {
+		fields = append(fields, usagelog.FieldResponseBytes)
+	}
+	if m.addestimated_cost != nil {
+		fields = append(fields, usagelog.FieldEstimatedCost)
+	}
 	return fields
 }
 
@@ -38119,6 +39685,12 @@ func (m *UsageLogMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedFirstTokenMs()
 	case usagelog.FieldImageCount:
 		return m.AddedImageCount()
+	case usagelog.FieldRequestBytes:
+		return m.AddedRequestBytes()
+	case usagelog.FieldResponseBytes:
+		return m.AddedResponseBytes()
+	case usagelog.FieldEstimatedCost:
+		return m.AddedEstimatedCost()
 	}
 	return nil, false
 }
@@ -38261,6 +39833,27 @@ func (m *UsageLogMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddImageCount(v)
 		return nil
+	case usagelog.FieldRequestBytes:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRequestBytes(v)
+		return nil
+	case usagelog.FieldResponseBytes:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseBytes(v)
+		return nil
+	case usagelog.FieldEstimatedCost:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddEstimatedCost(v)
+		return nil
 	}
 	return fmt.Errorf("unknown UsageLog numeric field %s", name)
 }
@@ -38323,6 +39916,15 @@ func (m *UsageLogMutation) ClearedFields() []string {
 	if m.FieldCleared(usagelog.FieldImageSizeBreakdown) {
 		fields = append(fields, usagelog.FieldImageSizeBreakdown)
 	}
+	if m.FieldCleared(usagelog.FieldRequestBytes) {
+		fields = append(fields, usagelog.FieldRequestBytes)
+	}
+	if m.FieldCleared(usagelog.FieldResponseBytes) {
+		fields = append(fields, usagelog.FieldResponseBytes)
+	}
+	if m.FieldCleared(usagelog.FieldEstimatedCost) {
+		fields = append(fields, usagelog.FieldEstimatedCost)
+	}
 	return fields
 }
 
@@ -38391,6 +39993,15 @@ func (m *UsageLogMutation) ClearField(name string) error {
 	case usagelog.FieldImageSizeBreakdown:
 		m.ClearImageSizeBreakdown()
 		return nil
+	case usagelog.FieldRequestBytes:
+		m.ClearRequestBytes()
+		return nil
+	case usagelog.FieldResponseBytes:
+		m.ClearResponseBytes()
+		return nil
+	case usagelog.FieldEstimatedCost:
+		m.ClearEstimatedCost()
+		return nil
 	}
 	return fmt.Errorf("unknown UsageLog nullable field %s", name)
 }
@@ -38519,6 +40130,30 @@ func (m *UsageLogMutation) ResetField(name string) error {
 	case usagelog.FieldCacheTTLOverridden:
 		m.ResetCacheTTLOverridden()
 		return nil
+	case usagelog.FieldUsageEstimated:
+		m.ResetUsageEstimated()
+		return nil
+	case usagelog.FieldBillingUnverified:
+		m.ResetBillingUnverified()
+		return nil
+	case usagelog.FieldRequestBytes:
+		m.ResetRequestBytes()
+		return nil
+	case usagelog.FieldResponseBytes:
+		m.ResetResponseBytes()
+		return nil
+	case usagelog.FieldResponseCacheHit:
+		m.ResetResponseCacheHit()
+		return nil
+	case usagelog.FieldPricingUnavailable:
+		m.ResetPricingUnavailable()
+		return nil
+	case usagelog.FieldEstimatedCost:
+		m.ResetEstimatedCost()
+		return nil
+	case usagelog.FieldCostOverage:
+		m.ResetCostOverage()
+		return nil
 	case usagelog.FieldCreatedAt:
 		m.ResetCreatedAt()
 		return nil
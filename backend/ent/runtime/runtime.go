@@ -109,36 +109,44 @@ func init() {
 	apikey.DefaultStatus = apikeyDescStatus.Default.(string)
 	// apikey.StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	apikey.StatusValidator = apikeyDescStatus.Validators[0].(func(string) error)
+	// apikeyDescResponseCacheEnabled is the schema descriptor for response_cache_enabled field.
+	apikeyDescResponseCacheEnabled := apikeyFields[9].Descriptor()
+	// apikey.DefaultResponseCacheEnabled holds the default value on creation for the response_cache_enabled field.
+	apikey.DefaultResponseCacheEnabled = apikeyDescResponseCacheEnabled.Default.(bool)
+	// apikeyDescCostPreviewEnabled is the schema descriptor for cost_preview_enabled field.
+	apikeyDescCostPreviewEnabled := apikeyFields[10].Descriptor()
+	// apikey.DefaultCostPreviewEnabled holds the default value on creation for the cost_preview_enabled field.
+	apikey.DefaultCostPreviewEnabled = apikeyDescCostPreviewEnabled.Default.(bool)
 	// apikeyDescQuota is the schema descriptor for quota field.
-	apikeyDescQuota := apikeyFields[8].Descriptor()
+	apikeyDescQuota := apikeyFields[13].Descriptor()
 	// apikey.DefaultQuota holds the default value on creation for the quota field.
 	apikey.DefaultQuota = apikeyDescQuota.Default.(float64)
 	// apikeyDescQuotaUsed is the schema descriptor for quota_used field.
-	apikeyDescQuotaUsed := apikeyFields[9].Descriptor()
+	apikeyDescQuotaUsed := apikeyFields[14].Descriptor()
 	// apikey.DefaultQuotaUsed holds the default value on creation for the quota_used field.
 	apikey.DefaultQuotaUsed = apikeyDescQuotaUsed.Default.(float64)
 	// apikeyDescRateLimit5h is the schema descriptor for rate_limit_5h field.
-	apikeyDescRateLimit5h := apikeyFields[11].Descriptor()
+	apikeyDescRateLimit5h := apikeyFields[16].Descriptor()
 	// apikey.DefaultRateLimit5h holds the default value on creation for the rate_limit_5h field.
 	apikey.DefaultRateLimit5h = apikeyDescRateLimit5h.Default.(float64)
 	// apikeyDescRateLimit1d is the schema descriptor for rate_limit_1d field.
-	apikeyDescRateLimit1d := apikeyFields[12].Descriptor()
+	apikeyDescRateLimit1d := apikeyFields[17].Descriptor()
 	// apikey.DefaultRateLimit1d holds the default value on creation for the rate_limit_1d field.
 	apikey.DefaultRateLimit1d = apikeyDescRateLimit1d.Default.(float64)
 	// apikeyDescRateLimit7d is the schema descriptor for rate_limit_7d field.
-	apikeyDescRateLimit7d := apikeyFields[13].Descriptor()
+	apikeyDescRateLimit7d := apikeyFields[18].Descriptor()
 	// apikey.DefaultRateLimit7d holds the default value on creation for the rate_limit_7d field.
 	apikey.DefaultRateLimit7d = apikeyDescRateLimit7d.Default.(float64)
 	// apikeyDescUsage5h is the schema descriptor for usage_5h field.
-	apikeyDescUsage5h := apikeyFields[14].Descriptor()
+	apikeyDescUsage5h := apikeyFields[19].Descriptor()
 	// apikey.DefaultUsage5h holds the default value on creation for the usage_5h field.
 	apikey.DefaultUsage5h = apikeyDescUsage5h.Default.(float64)
 	// apikeyDescUsage1d is the schema descriptor for usage_1d field.
-	apikeyDescUsage1d := apikeyFields[15].Descriptor()
+	apikeyDescUsage1d := apikeyFields[20].Descriptor()
 	// apikey.DefaultUsage1d holds the default value on creation for the usage_1d field.
 	apikey.DefaultUsage1d = apikeyDescUsage1d.Default.(float64)
 	// apikeyDescUsage7d is the schema descriptor for usage_7d field.
-	apikeyDescUsage7d := apikeyFields[16].Descriptor()
+	apikeyDescUsage7d := apikeyFields[21].Descriptor()
 	// apikey.DefaultUsage7d holds the default value on creation for the usage_7d field.
 	apikey.DefaultUsage7d = apikeyDescUsage7d.Default.(float64)
 	accountMixin := schema.Account{}.Mixin()
@@ -884,6 +892,24 @@ func init() {
 	groupDescRpmLimit := groupFields[31].Descriptor()
 	// group.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
 	group.DefaultRpmLimit = groupDescRpmLimit.Default.(int)
+	// groupDescPromptCacheInject is the schema descriptor for prompt_cache_inject field.
+	groupDescPromptCacheInject := groupFields[32].Descriptor()
+	// group.DefaultPromptCacheInject holds the default value on creation for the prompt_cache_inject field.
+	group.DefaultPromptCacheInject = groupDescPromptCacheInject.Default.(bool)
+	// groupDescModelFallbackConfig is the schema descriptor for model_fallback_config field.
+	groupDescModelFallbackConfig := groupFields[33].Descriptor()
+	// group.DefaultModelFallbackConfig holds the default value on creation for the model_fallback_config field.
+	group.DefaultModelFallbackConfig = groupDescModelFallbackConfig.Default.(domain.GroupModelFallbackConfig)
+	// groupDescSpendCapTimezone is the schema descriptor for spend_cap_timezone field.
+	groupDescSpendCapTimezone := groupFields[36].Descriptor()
+	// group.DefaultSpendCapTimezone holds the default value on creation for the spend_cap_timezone field.
+	group.DefaultSpendCapTimezone = groupDescSpendCapTimezone.Default.(string)
+	// group.SpendCapTimezoneValidator is a validator for the "spend_cap_timezone" field. It is called by the builders before save.
+	group.SpendCapTimezoneValidator = groupDescSpendCapTimezone.Validators[0].(func(string) error)
+	// groupDescInstructionInjectionConfig is the schema descriptor for instruction_injection_config field.
+	groupDescInstructionInjectionConfig := groupFields[37].Descriptor()
+	// group.DefaultInstructionInjectionConfig holds the default value on creation for the instruction_injection_config field.
+	group.DefaultInstructionInjectionConfig = groupDescInstructionInjectionConfig.Default.(domain.GroupInstructionInjectionConfig)
 	idempotencyrecordMixin := schema.IdempotencyRecord{}.Mixin()
 	idempotencyrecordMixinFields0 := idempotencyrecordMixin[0].Fields()
 	_ = idempotencyrecordMixinFields0
@@ -1771,8 +1797,28 @@ func init() {
 	usagelogDescCacheTTLOverridden := usagelogFields[39].Descriptor()
 	// usagelog.DefaultCacheTTLOverridden holds the default value on creation for the cache_ttl_overridden field.
 	usagelog.DefaultCacheTTLOverridden = usagelogDescCacheTTLOverridden.Default.(bool)
+	// usagelogDescUsageEstimated is the schema descriptor for usage_estimated field.
+	usagelogDescUsageEstimated := usagelogFields[40].Descriptor()
+	// usagelog.DefaultUsageEstimated holds the default value on creation for the usage_estimated field.
+	usagelog.DefaultUsageEstimated = usagelogDescUsageEstimated.Default.(bool)
+	// usagelogDescBillingUnverified is the schema descriptor for billing_unverified field.
+	usagelogDescBillingUnverified := usagelogFields[41].Descriptor()
+	// usagelog.DefaultBillingUnverified holds the default value on creation for the billing_unverified field.
+	usagelog.DefaultBillingUnverified = usagelogDescBillingUnverified.Default.(bool)
+	// usagelogDescResponseCacheHit is the schema descriptor for response_cache_hit field.
+	usagelogDescResponseCacheHit := usagelogFields[44].Descriptor()
+	// usagelog.DefaultResponseCacheHit holds the default value on creation for the response_cache_hit field.
+	usagelog.DefaultResponseCacheHit = usagelogDescResponseCacheHit.Default.(bool)
+	// usagelogDescPricingUnavailable is the schema descriptor for pricing_unavailable field.
+	usagelogDescPricingUnavailable := usagelogFields[45].Descriptor()
+	// usagelog.DefaultPricingUnavailable holds the default value on creation for the pricing_unavailable field.
+	usagelog.DefaultPricingUnavailable = usagelogDescPricingUnavailable.Default.(bool)
+	// usagelogDescCostOverage is the schema descriptor for cost_overage field.
+	usagelogDescCostOverage := usagelogFields[47].Descriptor()
+	// usagelog.DefaultCostOverage holds the default value on creation for the cost_overage field.
+	usagelog.DefaultCostOverage = usagelogDescCostOverage.Default.(bool)
 	// usagelogDescCreatedAt is the schema descriptor for created_at field.
-	usagelogDescCreatedAt := usagelogFields[40].Descriptor()
+	usagelogDescCreatedAt := usagelogFields[48].Descriptor()
 	// usagelog.DefaultCreatedAt holds the default value on creation for the created_at field.
 	usagelog.DefaultCreatedAt = usagelogDescCreatedAt.Default.(func() time.Time)
 	userMixin := schema.User{}.Mixin()
@@ -57,6 +57,21 @@ func (APIKey) Fields() []ent.Field {
 		field.JSON("ip_blacklist", []string{}).
 			Optional().
 			Comment("Blocked IPs/CIDRs"),
+		field.JSON("account_labels", []string{}).
+			Optional().
+			Comment("Account label selector: only accounts carrying all labels are scheduled"),
+		field.Bool("response_cache_enabled").
+			Default(false).
+			Comment("Opt-in response cache for deterministic non-streaming requests"),
+		field.Bool("cost_preview_enabled").
+			Default(false).
+			Comment("Return the estimated request cost in the X-Estimated-Cost response header"),
+		field.JSON("scopes", []string{}).
+			Optional().
+			Comment("Endpoint scopes this key may call, e.g. [\"chat\", \"images\"] (empty = all scopes)"),
+		field.JSON("allowed_models", []string{}).
+			Optional().
+			Comment("Models this key may call after alias resolution, e.g. [\"gpt-5.1\", \"gpt-5*\"] (empty = all models)"),
 
 		// ========== Quota fields ==========
 		// Quota limit in USD (0 = unlimited)
@@ -115,6 +130,12 @@ func (APIKey) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			Comment("Start time of the current 7d rate limit window"),
+
+		// ========== Debug capture ==========
+		field.Time("capture_until").
+			Optional().
+			Nillable().
+			Comment("Upstream request/response capture is enabled until this time (null = disabled)"),
 	}
 }
 
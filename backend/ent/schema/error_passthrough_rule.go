@@ -115,6 +115,17 @@ func (ErrorPassthroughRule) Fields() []ent.Field {
 		field.Text("description").
 			Optional().
 			Nillable(),
+
+		// body_regex: 响应体正则（RE2），与错误码/关键词按 match_mode 组合
+		field.Text("body_regex").
+			Optional().
+			Nillable(),
+
+		// header_matchers: 响应头匹配条件列表（AND关系）
+		// 例如：[{"name": "x-error-type", "regex": "^billing"}]
+		field.JSON("header_matchers", []map[string]string{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}),
 	}
 }
 
@@ -164,6 +164,39 @@ func (Group) Fields() []ent.Field {
 		field.Int("rpm_limit").
 			Default(0).
 			Comment("分组 RPM 上限，0 表示不限制；设置后接管该分组用户的限流"),
+
+		// Claude 请求自动 prompt caching 断点注入 (added by migration 163)
+		field.Bool("prompt_cache_inject").
+			Default(false).
+			Comment("请求未携带任何 cache_control 时，自动在 system 与最后一个 tool 上注入 ephemeral 缓存断点（仅 anthropic 平台）"),
+
+		// 分组模型降级配置 (added by migration 168)
+		field.JSON("model_fallback_config", domain.GroupModelFallbackConfig{}).
+			Default(domain.GroupModelFallbackConfig{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("模型降级配置：请求模型无可用账号时按有序规则尝试降级模型；mask_fallback 控制响应 model 是否回写为原请求模型"),
+
+		// 分组消费上限 (added by migration 172)
+		field.Float("spend_cap_daily_usd").
+			Optional().
+			Nillable().
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Comment("分组每日总消费上限（USD），NULL 表示不限制"),
+		field.Float("spend_cap_monthly_usd").
+			Optional().
+			Nillable().
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Comment("分组每月总消费上限（USD），NULL 表示不限制"),
+		field.String("spend_cap_timezone").
+			MaxLen(64).
+			Default("").
+			Comment("消费上限周期边界使用的时区（IANA 名称），空串表示使用系统时区"),
+
+		// 分组 instructions 注入 (added by migration 174)
+		field.JSON("instruction_injection_config", domain.GroupInstructionInjectionConfig{}).
+			Default(domain.GroupInstructionInjectionConfig{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("OpenAI Responses instructions 注入：mode 为 prepend / if_absent / replace，text 支持 {{key_name}} {{group_name}} {{date}} 模板变量"),
 	}
 }
 
@@ -152,6 +152,33 @@ func (UsageLog) Fields() []ent.Field {
 		// Cache TTL Override 标记（管理员强制替换了缓存 TTL 计费）
 		field.Bool("cache_ttl_overridden").
 			Default(false),
+		// 用量估算标记（上游流式响应缺失 usage，按文本估算 token）
+		field.Bool("usage_estimated").
+			Default(false),
+		// 计费未校验标记（计费缓存故障降级放行的请求）
+		field.Bool("billing_unverified").
+			Default(false),
+		// 原始请求/响应字节数（响应为实际写给客户端的字节数，流式累加；未统计时为空）
+		field.Int64("request_bytes").
+			Optional().
+			Nillable(),
+		field.Int64("response_bytes").
+			Optional().
+			Nillable(),
+		// 响应缓存命中标记（直接返回缓存的响应，未请求上游、不计费）
+		field.Bool("response_cache_hit").
+			Default(false),
+		// 定价缺失标记（模型没有任何可用价格，仅记录 token，费用不计）
+		field.Bool("pricing_unavailable").
+			Default(false),
+		// 请求前估算费用（仅设置了 max_cost 或开启费用预览时记录）
+		field.Float("estimated_cost").
+			Optional().
+			Nillable().
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,10)"}),
+		// 实际费用超出估算费用配置倍数的标记
+		field.Bool("cost_overage").
+			Default(false),
 
 		// 时间戳（只有 created_at，日志不可修改）
 		field.Time("created_at").
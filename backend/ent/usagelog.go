@@ -103,6 +103,22 @@ type UsageLog struct {
 	ImageSizeBreakdown map[string]int `json:"image_size_breakdown,omitempty"`
 	// CacheTTLOverridden holds the value of the "cache_ttl_overridden" field.
 	CacheTTLOverridden bool `json:"cache_ttl_overridden,omitempty"`
+	// UsageEstimated holds the value of the "usage_estimated" field.
+	UsageEstimated bool `json:"usage_estimated,omitempty"`
+	// BillingUnverified holds the value of the "billing_unverified" field.
+	BillingUnverified bool `json:"billing_unverified,omitempty"`
+	// RequestBytes holds the value of the "request_bytes" field.
+	RequestBytes *int64 `json:"request_bytes,omitempty"`
+	// ResponseBytes holds the value of the "response_bytes" field.
+	ResponseBytes *int64 `json:"response_bytes,omitempty"`
+	// ResponseCacheHit holds the value of the "response_cache_hit" field.
+	ResponseCacheHit bool `json:"response_cache_hit,omitempty"`
+	// PricingUnavailable holds the value of the "pricing_unavailable" field.
+	PricingUnavailable bool `json:"pricing_unavailable,omitempty"`
+	// EstimatedCost holds the value of the "estimated_cost" field.
+	EstimatedCost *float64 `json:"estimated_cost,omitempty"`
+	// CostOverage holds the value of the "cost_overage" field.
+	CostOverage bool `json:"cost_overage,omitempty"`
 	// CreatedAt holds the value of the "created_at" field.
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
@@ -190,11 +206,11 @@ func (*UsageLog) scanValues(columns []string) ([]any, error) {
 		switch columns[i] {
 		case usagelog.FieldImageSizeBreakdown:
 			values[i] = new([]byte)
-		case usagelog.FieldStream, usagelog.FieldCacheTTLOverridden:
+		case usagelog.FieldStream, usagelog.FieldCacheTTLOverridden, usagelog.FieldUsageEstimated, usagelog.FieldBillingUnverified, usagelog.FieldResponseCacheHit, usagelog.FieldPricingUnavailable, usagelog.FieldCostOverage:
 			values[i] = new(sql.NullBool)
-		case usagelog.FieldInputCost, usagelog.FieldOutputCost, usagelog.FieldCacheCreationCost, usagelog.FieldCacheReadCost, usagelog.FieldTotalCost, usagelog.FieldActualCost, usagelog.FieldRateMultiplier, usagelog.FieldAccountRateMultiplier:
+		case usagelog.FieldInputCost, usagelog.FieldOutputCost, usagelog.FieldCacheCreationCost, usagelog.FieldCacheReadCost, usagelog.FieldTotalCost, usagelog.FieldActualCost, usagelog.FieldRateMultiplier, usagelog.FieldAccountRateMultiplier, usagelog.FieldEstimatedCost:
 			values[i] = new(sql.NullFloat64)
-		case usagelog.FieldID, usagelog.FieldUserID, usagelog.FieldAPIKeyID, usagelog.FieldAccountID, usagelog.FieldChannelID, usagelog.FieldGroupID, usagelog.FieldSubscriptionID, usagelog.FieldInputTokens, usagelog.FieldOutputTokens, usagelog.FieldCacheCreationTokens, usagelog.FieldCacheReadTokens, usagelog.FieldCacheCreation5mTokens, usagelog.FieldCacheCreation1hTokens, usagelog.FieldBillingType, usagelog.FieldDurationMs, usagelog.FieldFirstTokenMs, usagelog.FieldImageCount:
+		case usagelog.FieldID, usagelog.FieldUserID, usagelog.FieldAPIKeyID, usagelog.FieldAccountID, usagelog.FieldChannelID, usagelog.FieldGroupID, usagelog.FieldSubscriptionID, usagelog.FieldInputTokens, usagelog.FieldOutputTokens, usagelog.FieldCacheCreationTokens, usagelog.FieldCacheReadTokens, usagelog.FieldCacheCreation5mTokens, usagelog.FieldCacheCreation1hTokens, usagelog.FieldBillingType, usagelog.FieldDurationMs, usagelog.FieldFirstTokenMs, usagelog.FieldImageCount, usagelog.FieldRequestBytes, usagelog.FieldResponseBytes:
 			values[i] = new(sql.NullInt64)
 		case usagelog.FieldRequestID, usagelog.FieldModel, usagelog.FieldRequestedModel, usagelog.FieldUpstreamModel, usagelog.FieldModelMappingChain, usagelog.FieldBillingTier, usagelog.FieldBillingMode, usagelog.FieldUserAgent, usagelog.FieldIPAddress, usagelog.FieldImageSize, usagelog.FieldImageInputSize, usagelog.FieldImageOutputSize, usagelog.FieldImageSizeSource:
 			values[i] = new(sql.NullString)
@@ -480,6 +496,57 @@ func (_m *UsageLog) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.CacheTTLOverridden = value.Bool
 			}
+		case usagelog.FieldUsageEstimated:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field usage_estimated", values[i])
+			} else if value.Valid {
+				_m.UsageEstimated = value.Bool
+			}
+		case usagelog.FieldBillingUnverified:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field billing_unverified", values[i])
+			} else if value.Valid {
+				_m.BillingUnverified = value.Bool
+			}
+		case usagelog.FieldRequestBytes:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field request_bytes", values[i])
+			} else if value.Valid {
+				_m.RequestBytes = new(int64)
+				*_m.RequestBytes = value.Int64
+			}
+		case usagelog.FieldResponseBytes:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field response_bytes", values[i])
+			} else if value.Valid {
+				_m.ResponseBytes = new(int64)
+				*_m.ResponseBytes = value.Int64
+			}
+		case usagelog.FieldResponseCacheHit:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_hit", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheHit = value.Bool
+			}
+		case usagelog.FieldPricingUnavailable:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field pricing_unavailable", values[i])
+			} else if value.Valid {
+				_m.PricingUnavailable = value.Bool
+			}
+		case usagelog.FieldEstimatedCost:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field estimated_cost", values[i])
+			} else if value.Valid {
+				_m.EstimatedCost = new(float64)
+				*_m.EstimatedCost = value.Float64
+			}
+		case usagelog.FieldCostOverage:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field cost_overage", values[i])
+			} else if value.Valid {
+				_m.CostOverage = value.Bool
+			}
 		case usagelog.FieldCreatedAt:
 			if value, ok := values[i].(*sql.NullTime); !ok {
 				return fmt.Errorf("unexpected type %T for field created_at", values[i])
@@ -701,6 +768,36 @@ func (_m *UsageLog) String() string {
 	builder.WriteString("cache_ttl_overridden=")
 	builder.WriteString(fmt.Sprintf("%v", _m.CacheTTLOverridden))
 	builder.WriteString(", ")
+	builder.WriteString("usage_estimated=")
+	builder.WriteString(fmt.Sprintf("%v", _m.UsageEstimated))
+	builder.WriteString(", ")
+	builder.WriteString("billing_unverified=")
+	builder.WriteString(fmt.Sprintf("%v", _m.BillingUnverified))
+	builder.WriteString(", ")
+	if v := _m.RequestBytes; v != nil {
+		builder.WriteString("request_bytes=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	if v := _m.ResponseBytes; v != nil {
+		builder.WriteString("response_bytes=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_hit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheHit))
+	builder.WriteString(", ")
+	builder.WriteString("pricing_unavailable=")
+	builder.WriteString(fmt.Sprintf("%v", _m.PricingUnavailable))
+	builder.WriteString(", ")
+	if v := _m.EstimatedCost; v != nil {
+		builder.WriteString("estimated_cost=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("cost_overage=")
+	builder.WriteString(fmt.Sprintf("%v", _m.CostOverage))
+	builder.WriteString(", ")
 	builder.WriteString("created_at=")
 	builder.WriteString(_m.CreatedAt.Format(time.ANSIC))
 	builder.WriteByte(')')
@@ -94,6 +94,22 @@ const (
 	FieldImageSizeBreakdown = "image_size_breakdown"
 	// FieldCacheTTLOverridden holds the string denoting the cache_ttl_overridden field in the database.
 	FieldCacheTTLOverridden = "cache_ttl_overridden"
+	// FieldUsageEstimated holds the string denoting the usage_estimated field in the database.
+	FieldUsageEstimated = "usage_estimated"
+	// FieldBillingUnverified holds the string denoting the billing_unverified field in the database.
+	FieldBillingUnverified = "billing_unverified"
+	// FieldRequestBytes holds the string denoting the request_bytes field in the database.
+	FieldRequestBytes = "request_bytes"
+	// FieldResponseBytes holds the string denoting the response_bytes field in the database.
+	FieldResponseBytes = "response_bytes"
+	// FieldResponseCacheHit holds the string denoting the response_cache_hit field in the database.
+	FieldResponseCacheHit = "response_cache_hit"
+	// FieldPricingUnavailable holds the string denoting the pricing_unavailable field in the database.
+	FieldPricingUnavailable = "pricing_unavailable"
+	// FieldEstimatedCost holds the string denoting the estimated_cost field in the database.
+	FieldEstimatedCost = "estimated_cost"
+	// FieldCostOverage holds the string denoting the cost_overage field in the database.
+	FieldCostOverage = "cost_overage"
 	// FieldCreatedAt holds the string denoting the created_at field in the database.
 	FieldCreatedAt = "created_at"
 	// EdgeUser holds the string denoting the user edge name in mutations.
@@ -188,6 +204,14 @@ var Columns = []string{
 	FieldImageSizeSource,
 	FieldImageSizeBreakdown,
 	FieldCacheTTLOverridden,
+	FieldUsageEstimated,
+	FieldBillingUnverified,
+	FieldRequestBytes,
+	FieldResponseBytes,
+	FieldResponseCacheHit,
+	FieldPricingUnavailable,
+	FieldEstimatedCost,
+	FieldCostOverage,
 	FieldCreatedAt,
 }
 
@@ -262,6 +286,16 @@ var (
 	ImageSizeSourceValidator func(string) error
 	// DefaultCacheTTLOverridden holds the default value on creation for the "cache_ttl_overridden" field.
 	DefaultCacheTTLOverridden bool
+	// DefaultUsageEstimated holds the default value on creation for the "usage_estimated" field.
+	DefaultUsageEstimated bool
+	// DefaultBillingUnverified holds the default value on creation for the "billing_unverified" field.
+	DefaultBillingUnverified bool
+	// DefaultResponseCacheHit holds the default value on creation for the "response_cache_hit" field.
+	DefaultResponseCacheHit bool
+	// DefaultPricingUnavailable holds the default value on creation for the "pricing_unavailable" field.
+	DefaultPricingUnavailable bool
+	// DefaultCostOverage holds the default value on creation for the "cost_overage" field.
+	DefaultCostOverage bool
 	// DefaultCreatedAt holds the default value on creation for the "created_at" field.
 	DefaultCreatedAt func() time.Time
 )
@@ -469,6 +503,46 @@ func ByCacheTTLOverridden(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldCacheTTLOverridden, opts...).ToFunc()
 }
 
+// ByUsageEstimated orders the results by the usage_estimated field.
+func ByUsageEstimated(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldUsageEstimated, opts...).ToFunc()
+}
+
+// ByBillingUnverified orders the results by the billing_unverified field.
+func ByBillingUnverified(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBillingUnverified, opts...).ToFunc()
+}
+
+// ByRequestBytes orders the results by the request_bytes field.
+func ByRequestBytes(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRequestBytes, opts...).ToFunc()
+}
+
+// ByResponseBytes orders the results by the response_bytes field.
+func ByResponseBytes(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseBytes, opts...).ToFunc()
+}
+
+// ByResponseCacheHit orders the results by the response_cache_hit field.
+func ByResponseCacheHit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheHit, opts...).ToFunc()
+}
+
+// ByPricingUnavailable orders the results by the pricing_unavailable field.
+func ByPricingUnavailable(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPricingUnavailable, opts...).ToFunc()
+}
+
+// ByEstimatedCost orders the results by the estimated_cost field.
+func ByEstimatedCost(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldEstimatedCost, opts...).ToFunc()
+}
+
+// ByCostOverage orders the results by the cost_overage field.
+func ByCostOverage(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCostOverage, opts...).ToFunc()
+}
+
 // ByCreatedAt orders the results by the created_at field.
 func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldCreatedAt, opts...).ToFunc()
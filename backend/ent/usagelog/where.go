@@ -250,6 +250,46 @@ func CacheTTLOverridden(v bool) predicate.UsageLog {
 	return predicate.UsageLog(sql.FieldEQ(FieldCacheTTLOverridden, v))
 }
 
+// UsageEstimated applies equality check predicate on the "usage_estimated" field. It's identical to UsageEstimatedEQ.
+func UsageEstimated(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldUsageEstimated, v))
+}
+
+// BillingUnverified applies equality check predicate on the "billing_unverified" field. It's identical to BillingUnverifiedEQ.
+func BillingUnverified(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldBillingUnverified, v))
+}
+
+// RequestBytes applies equality check predicate on the "request_bytes" field. It's identical to RequestBytesEQ.
+func RequestBytes(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldRequestBytes, v))
+}
+
+// ResponseBytes applies equality check predicate on the "response_bytes" field. It's identical to ResponseBytesEQ.
+func ResponseBytes(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldResponseBytes, v))
+}
+
+// ResponseCacheHit applies equality check predicate on the "response_cache_hit" field. It's identical to ResponseCacheHitEQ.
+func ResponseCacheHit(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldResponseCacheHit, v))
+}
+
+// PricingUnavailable applies equality check predicate on the "pricing_unavailable" field. It's identical to PricingUnavailableEQ.
+func PricingUnavailable(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldPricingUnavailable, v))
+}
+
+// EstimatedCost applies equality check predicate on the "estimated_cost" field. It's identical to EstimatedCostEQ.
+func EstimatedCost(v float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldEstimatedCost, v))
+}
+
+// CostOverage applies equality check predicate on the "cost_overage" field. It's identical to CostOverageEQ.
+func CostOverage(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldCostOverage, v))
+}
+
 // CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
 func CreatedAt(v time.Time) predicate.UsageLog {
 	return predicate.UsageLog(sql.FieldEQ(FieldCreatedAt, v))
@@ -2160,6 +2200,206 @@ func CacheTTLOverriddenNEQ(v bool) predicate.UsageLog {
 	return predicate.UsageLog(sql.FieldNEQ(FieldCacheTTLOverridden, v))
 }
 
+// UsageEstimatedEQ applies the EQ predicate on the "usage_estimated" field.
+func UsageEstimatedEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldUsageEstimated, v))
+}
+
+// UsageEstimatedNEQ applies the NEQ predicate on the "usage_estimated" field.
+func UsageEstimatedNEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNEQ(FieldUsageEstimated, v))
+}
+
+// BillingUnverifiedEQ applies the EQ predicate on the "billing_unverified" field.
+func BillingUnverifiedEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldBillingUnverified, v))
+}
+
+// BillingUnverifiedNEQ applies the NEQ predicate on the "billing_unverified" field.
+func BillingUnverifiedNEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNEQ(FieldBillingUnverified, v))
+}
+
+// RequestBytesEQ applies the EQ predicate on the "request_bytes" field.
+func RequestBytesEQ(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldRequestBytes, v))
+}
+
+// RequestBytesNEQ applies the NEQ predicate on the "request_bytes" field.
+func RequestBytesNEQ(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNEQ(FieldRequestBytes, v))
+}
+
+// RequestBytesIn applies the In predicate on the "request_bytes" field.
+func RequestBytesIn(vs ...int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldIn(FieldRequestBytes, vs...))
+}
+
+// RequestBytesNotIn applies the NotIn predicate on the "request_bytes" field.
+func RequestBytesNotIn(vs ...int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNotIn(FieldRequestBytes, vs...))
+}
+
+// RequestBytesGT applies the GT predicate on the "request_bytes" field.
+func RequestBytesGT(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldGT(FieldRequestBytes, v))
+}
+
+// RequestBytesGTE applies the GTE predicate on the "request_bytes" field.
+func RequestBytesGTE(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldGTE(FieldRequestBytes, v))
+}
+
+// RequestBytesLT applies the LT predicate on the "request_bytes" field.
+func RequestBytesLT(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldLT(FieldRequestBytes, v))
+}
+
+// RequestBytesLTE applies the LTE predicate on the "request_bytes" field.
+func RequestBytesLTE(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldLTE(FieldRequestBytes, v))
+}
+
+// RequestBytesIsNil applies the IsNil predicate on the "request_bytes" field.
+func RequestBytesIsNil() predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldIsNull(FieldRequestBytes))
+}
+
+// RequestBytesNotNil applies the NotNil predicate on the "request_bytes" field.
+func RequestBytesNotNil() predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNotNull(FieldRequestBytes))
+}
+
+// ResponseBytesEQ applies the EQ predicate on the "response_bytes" field.
+func ResponseBytesEQ(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldResponseBytes, v))
+}
+
+// ResponseBytesNEQ applies the NEQ predicate on the "response_bytes" field.
+func ResponseBytesNEQ(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNEQ(FieldResponseBytes, v))
+}
+
+// ResponseBytesIn applies the In predicate on the "response_bytes" field.
+func ResponseBytesIn(vs ...int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldIn(FieldResponseBytes, vs...))
+}
+
+// ResponseBytesNotIn applies the NotIn predicate on the "response_bytes" field.
+func ResponseBytesNotIn(vs ...int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNotIn(FieldResponseBytes, vs...))
+}
+
+// ResponseBytesGT applies the GT predicate on the "response_bytes" field.
+func ResponseBytesGT(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldGT(FieldResponseBytes, v))
+}
+
+// ResponseBytesGTE applies the GTE predicate on the "response_bytes" field.
+func ResponseBytesGTE(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldGTE(FieldResponseBytes, v))
+}
+
+// ResponseBytesLT applies the LT predicate on the "response_bytes" field.
+func ResponseBytesLT(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldLT(FieldResponseBytes, v))
+}
+
+// ResponseBytesLTE applies the LTE predicate on the "response_bytes" field.
+func ResponseBytesLTE(v int64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldLTE(FieldResponseBytes, v))
+}
+
+// ResponseBytesIsNil applies the IsNil predicate on the "response_bytes" field.
+func ResponseBytesIsNil() predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldIsNull(FieldResponseBytes))
+}
+
+// ResponseBytesNotNil applies the NotNil predicate on the "response_bytes" field.
+func ResponseBytesNotNil() predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNotNull(FieldResponseBytes))
+}
+
+// ResponseCacheHitEQ applies the EQ predicate on the "response_cache_hit" field.
+func ResponseCacheHitEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldResponseCacheHit, v))
+}
+
+// ResponseCacheHitNEQ applies the NEQ predicate on the "response_cache_hit" field.
+func ResponseCacheHitNEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNEQ(FieldResponseCacheHit, v))
+}
+
+// PricingUnavailableEQ applies the EQ predicate on the "pricing_unavailable" field.
+func PricingUnavailableEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldPricingUnavailable, v))
+}
+
+// PricingUnavailableNEQ applies the NEQ predicate on the "pricing_unavailable" field.
+func PricingUnavailableNEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNEQ(FieldPricingUnavailable, v))
+}
+
+// EstimatedCostEQ applies the EQ predicate on the "estimated_cost" field.
+func EstimatedCostEQ(v float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldEstimatedCost, v))
+}
+
+// EstimatedCostNEQ applies the NEQ predicate on the "estimated_cost" field.
+func EstimatedCostNEQ(v float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNEQ(FieldEstimatedCost, v))
+}
+
+// EstimatedCostIn applies the In predicate on the "estimated_cost" field.
+func EstimatedCostIn(vs ...float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldIn(FieldEstimatedCost, vs...))
+}
+
+// EstimatedCostNotIn applies the NotIn predicate on the "estimated_cost" field.
+func EstimatedCostNotIn(vs ...float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNotIn(FieldEstimatedCost, vs...))
+}
+
+// EstimatedCostGT applies the GT predicate on the "estimated_cost" field.
+func EstimatedCostGT(v float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldGT(FieldEstimatedCost, v))
+}
+
+// EstimatedCostGTE applies the GTE predicate on the "estimated_cost" field.
+func EstimatedCostGTE(v float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldGTE(FieldEstimatedCost, v))
+}
+
+// EstimatedCostLT applies the LT predicate on the "estimated_cost" field.
+func EstimatedCostLT(v float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldLT(FieldEstimatedCost, v))
+}
+
+// EstimatedCostLTE applies the LTE predicate on the "estimated_cost" field.
+func EstimatedCostLTE(v float64) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldLTE(FieldEstimatedCost, v))
+}
+
+// EstimatedCostIsNil applies the IsNil predicate on the "estimated_cost" field.
+func EstimatedCostIsNil() predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldIsNull(FieldEstimatedCost))
+}
+
+// EstimatedCostNotNil applies the NotNil predicate on the "estimated_cost" field.
+func EstimatedCostNotNil() predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNotNull(FieldEstimatedCost))
+}
+
+// CostOverageEQ applies the EQ predicate on the "cost_overage" field.
+func CostOverageEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldEQ(FieldCostOverage, v))
+}
+
+// CostOverageNEQ applies the NEQ predicate on the "cost_overage" field.
+func CostOverageNEQ(v bool) predicate.UsageLog {
+	return predicate.UsageLog(sql.FieldNEQ(FieldCostOverage, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.UsageLog {
 	return predicate.UsageLog(sql.FieldEQ(FieldCreatedAt, v))
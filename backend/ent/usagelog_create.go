@@ -539,6 +539,118 @@ func (_c *UsageLogCreate) SetNillableCacheTTLOverridden(v *bool) *UsageLogCreate
 	return _c
 }
 
+// SetUsageEstimated sets the "usage_estimated" field.
+func (_c *UsageLogCreate) SetUsageEstimated(v bool) *UsageLogCreate {
+	_c.mutation.SetUsageEstimated(v)
+	return _c
+}
+
+// SetNillableUsageEstimated sets the "usage_estimated" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableUsageEstimated(v *bool) *UsageLogCreate {
+	if v != nil {
+		_c.SetUsageEstimated(*v)
+	}
+	return _c
+}
+
+// SetBillingUnverified sets the "billing_unverified" field.
+func (_c *UsageLogCreate) SetBillingUnverified(v bool) *UsageLogCreate {
+	_c.mutation.SetBillingUnverified(v)
+	return _c
+}
+
+// SetNillableBillingUnverified sets the "billing_unverified" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableBillingUnverified(v *bool) *UsageLogCreate {
+	if v != nil {
+		_c.SetBillingUnverified(*v)
+	}
+	return _c
+}
+
+// SetRequestBytes sets the "request_bytes" field.
+func (_c *UsageLogCreate) SetRequestBytes(v int64) *UsageLogCreate {
+	_c.mutation.SetRequestBytes(v)
+	return _c
+}
+
+// SetNillableRequestBytes sets the "request_bytes" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableRequestBytes(v *int64) *UsageLogCreate {
+	if v != nil {
+		_c.SetRequestBytes(*v)
+	}
+	return _c
+}
+
+// SetResponseBytes sets the "response_bytes" field.
+func (_c *UsageLogCreate) SetResponseBytes(v int64) *UsageLogCreate {
+	_c.mutation.SetResponseBytes(v)
+	return _c
+}
+
+// SetNillableResponseBytes sets the "response_bytes" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableResponseBytes(v *int64) *UsageLogCreate {
+	if v != nil {
+		_c.SetResponseBytes(*v)
+	}
+	return _c
+}
+
+// SetResponseCacheHit sets the "response_cache_hit" field.
+func (_c *UsageLogCreate) SetResponseCacheHit(v bool) *UsageLogCreate {
+	_c.mutation.SetResponseCacheHit(v)
+	return _c
+}
+
+// SetNillableResponseCacheHit sets the "response_cache_hit" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableResponseCacheHit(v *bool) *UsageLogCreate {
+	if v != nil {
+		_c.SetResponseCacheHit(*v)
+	}
+	return _c
+}
+
+// SetPricingUnavailable sets the "pricing_unavailable" field.
+func (_c *UsageLogCreate) SetPricingUnavailable(v bool) *UsageLogCreate {
+	_c.mutation.SetPricingUnavailable(v)
+	return _c
+}
+
+// SetNillablePricingUnavailable sets the "pricing_unavailable" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillablePricingUnavailable(v *bool) *UsageLogCreate {
+	if v != nil {
+		_c.SetPricingUnavailable(*v)
+	}
+	return _c
+}
+
+// SetEstimatedCost sets the "estimated_cost" field.
+func (_c *UsageLogCreate) SetEstimatedCost(v float64) *UsageLogCreate {
+	_c.mutation.SetEstimatedCost(v)
+	return _c
+}
+
+// SetNillableEstimatedCost sets the "estimated_cost" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableEstimatedCost(v *float64) *UsageLogCreate {
+	if v != nil {
+		_c.SetEstimatedCost(*v)
+	}
+	return _c
+}
+
+// SetCostOverage sets the "cost_overage" field.
+func (_c *UsageLogCreate) SetCostOverage(v bool) *UsageLogCreate {
+	_c.mutation.SetCostOverage(v)
+	return _c
+}
+
+// SetNillableCostOverage sets the "cost_overage" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableCostOverage(v *bool) *UsageLogCreate {
+	if v != nil {
+		_c.SetCostOverage(*v)
+	}
+	return _c
+}
+
 // SetCreatedAt sets the "created_at" field.
 func (_c *UsageLogCreate) SetCreatedAt(v time.Time) *UsageLogCreate {
 	_c.mutation.SetCreatedAt(v)
@@ -681,6 +793,26 @@ func (_c *UsageLogCreate) defaults() {
 		v := usagelog.DefaultCacheTTLOverridden
 		_c.mutation.SetCacheTTLOverridden(v)
 	}
+	if _, ok := _c.mutation.UsageEstimated(); !ok {
+		v := usagelog.DefaultUsageEstimated
+		_c.mutation.SetUsageEstimated(v)
+	}
+	if _, ok := _c.mutation.BillingUnverified(); !ok {
+		v := usagelog.DefaultBillingUnverified
+		_c.mutation.SetBillingUnverified(v)
+	}
+	if _, ok := _c.mutation.ResponseCacheHit(); !ok {
+		v := usagelog.DefaultResponseCacheHit
+		_c.mutation.SetResponseCacheHit(v)
+	}
+	if _, ok := _c.mutation.PricingUnavailable(); !ok {
+		v := usagelog.DefaultPricingUnavailable
+		_c.mutation.SetPricingUnavailable(v)
+	}
+	if _, ok := _c.mutation.CostOverage(); !ok {
+		v := usagelog.DefaultCostOverage
+		_c.mutation.SetCostOverage(v)
+	}
 	if _, ok := _c.mutation.CreatedAt(); !ok {
 		v := usagelog.DefaultCreatedAt()
 		_c.mutation.SetCreatedAt(v)
@@ -820,6 +952,21 @@ func (_c *UsageLogCreate) check() error {
 	if _, ok := _c.mutation.CacheTTLOverridden(); !ok {
 		return &ValidationError{Name: "cache_ttl_overridden", err: errors.New(`ent: missing required field "UsageLog.cache_ttl_overridden"`)}
 	}
+	if _, ok := _c.mutation.UsageEstimated(); !ok {
+		return &ValidationError{Name: "usage_estimated", err: errors.New(`ent: missing required field "UsageLog.usage_estimated"`)}
+	}
+	if _, ok := _c.mutation.BillingUnverified(); !ok {
+		return &ValidationError{Name: "billing_unverified", err: errors.New(`ent: missing required field "UsageLog.billing_unverified"`)}
+	}
+	if _, ok := _c.mutation.ResponseCacheHit(); !ok {
+		return &ValidationError{Name: "response_cache_hit", err: errors.New(`ent: missing required field "UsageLog.response_cache_hit"`)}
+	}
+	if _, ok := _c.mutation.PricingUnavailable(); !ok {
+		return &ValidationError{Name: "pricing_unavailable", err: errors.New(`ent: missing required field "UsageLog.pricing_unavailable"`)}
+	}
+	if _, ok := _c.mutation.CostOverage(); !ok {
+		return &ValidationError{Name: "cost_overage", err: errors.New(`ent: missing required field "UsageLog.cost_overage"`)}
+	}
 	if _, ok := _c.mutation.CreatedAt(); !ok {
 		return &ValidationError{Name: "created_at", err: errors.New(`ent: missing required field "UsageLog.created_at"`)}
 	}
@@ -999,6 +1146,38 @@ func (_c *UsageLogCreate) createSpec() (*UsageLog, *sqlgraph.CreateSpec) {
 		_spec.SetField(usagelog.FieldCacheTTLOverridden, field.TypeBool, value)
 		_node.CacheTTLOverridden = value
 	}
+	if value, ok := _c.mutation.UsageEstimated(); ok {
+		_spec.SetField(usagelog.FieldUsageEstimated, field.TypeBool, value)
+		_node.UsageEstimated = value
+	}
+	if value, ok := _c.mutation.BillingUnverified(); ok {
+		_spec.SetField(usagelog.FieldBillingUnverified, field.TypeBool, value)
+		_node.BillingUnverified = value
+	}
+	if value, ok := _c.mutation.RequestBytes(); ok {
+		_spec.SetField(usagelog.FieldRequestBytes, field.TypeInt64, value)
+		_node.RequestBytes = &value
+	}
+	if value, ok := _c.mutation.ResponseBytes(); ok {
+		_spec.SetField(usagelog.FieldResponseBytes, field.TypeInt64, value)
+		_node.ResponseBytes = &value
+	}
+	if value, ok := _c.mutation.ResponseCacheHit(); ok {
+		_spec.SetField(usagelog.FieldResponseCacheHit, field.TypeBool, value)
+		_node.ResponseCacheHit = value
+	}
+	if value, ok := _c.mutation.PricingUnavailable(); ok {
+		_spec.SetField(usagelog.FieldPricingUnavailable, field.TypeBool, value)
+		_node.PricingUnavailable = value
+	}
+	if value, ok := _c.mutation.EstimatedCost(); ok {
+		_spec.SetField(usagelog.FieldEstimatedCost, field.TypeFloat64, value)
+		_node.EstimatedCost = &value
+	}
+	if value, ok := _c.mutation.CostOverage(); ok {
+		_spec.SetField(usagelog.FieldCostOverage, field.TypeBool, value)
+		_node.CostOverage = value
+	}
 	if value, ok := _c.mutation.CreatedAt(); ok {
 		_spec.SetField(usagelog.FieldCreatedAt, field.TypeTime, value)
 		_node.CreatedAt = value
@@ -1842,6 +2021,138 @@ func (u *UsageLogUpsert) UpdateCacheTTLOverridden() *UsageLogUpsert {
 	return u
 }
 
+// SetUsageEstimated sets the "usage_estimated" field.
+func (u *UsageLogUpsert) SetUsageEstimated(v bool) *UsageLogUpsert {
+	u.Set(usagelog.FieldUsageEstimated, v)
+	return u
+}
+
+// UpdateUsageEstimated sets the "usage_estimated" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateUsageEstimated() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldUsageEstimated)
+	return u
+}
+
+// SetBillingUnverified sets the "billing_unverified" field.
+func (u *UsageLogUpsert) SetBillingUnverified(v bool) *UsageLogUpsert {
+	u.Set(usagelog.FieldBillingUnverified, v)
+	return u
+}
+
+// UpdateBillingUnverified sets the "billing_unverified" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateBillingUnverified() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldBillingUnverified)
+	return u
+}
+
+// SetRequestBytes sets the "request_bytes" field.
+func (u *UsageLogUpsert) SetRequestBytes(v int64) *UsageLogUpsert {
+	u.Set(usagelog.FieldRequestBytes, v)
+	return u
+}
+
+// UpdateRequestBytes sets the "request_bytes" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateRequestBytes() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldRequestBytes)
+	return u
+}
+
+// AddRequestBytes adds v to the "request_bytes" field.
+func (u *UsageLogUpsert) AddRequestBytes(v int64) *UsageLogUpsert {
+	u.Add(usagelog.FieldRequestBytes, v)
+	return u
+}
+
+// ClearRequestBytes clears the value of the "request_bytes" field.
+func (u *UsageLogUpsert) ClearRequestBytes() *UsageLogUpsert {
+	u.SetNull(usagelog.FieldRequestBytes)
+	return u
+}
+
+// SetResponseBytes sets the "response_bytes" field.
+func (u *UsageLogUpsert) SetResponseBytes(v int64) *UsageLogUpsert {
+	u.Set(usagelog.FieldResponseBytes, v)
+	return u
+}
+
+// UpdateResponseBytes sets the "response_bytes" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateResponseBytes() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldResponseBytes)
+	return u
+}
+
+// AddResponseBytes adds v to the "response_bytes" field.
+func (u *UsageLogUpsert) AddResponseBytes(v int64) *UsageLogUpsert {
+	u.Add(usagelog.FieldResponseBytes, v)
+	return u
+}
+
+// ClearResponseBytes clears the value of the "response_bytes" field.
+func (u *UsageLogUpsert) ClearResponseBytes() *UsageLogUpsert {
+	u.SetNull(usagelog.FieldResponseBytes)
+	return u
+}
+
+// SetResponseCacheHit sets the "response_cache_hit" field.
+func (u *UsageLogUpsert) SetResponseCacheHit(v bool) *UsageLogUpsert {
+	u.Set(usagelog.FieldResponseCacheHit, v)
+	return u
+}
+
+// UpdateResponseCacheHit sets the "response_cache_hit" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateResponseCacheHit() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldResponseCacheHit)
+	return u
+}
+
+// SetPricingUnavailable sets the "pricing_unavailable" field.
+func (u *UsageLogUpsert) SetPricingUnavailable(v bool) *UsageLogUpsert {
+	u.Set(usagelog.FieldPricingUnavailable, v)
+	return u
+}
+
+// UpdatePricingUnavailable sets the "pricing_unavailable" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdatePricingUnavailable() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldPricingUnavailable)
+	return u
+}
+
+// SetEstimatedCost sets the "estimated_cost" field.
+func (u *UsageLogUpsert) SetEstimatedCost(v float64) *UsageLogUpsert {
+	u.Set(usagelog.FieldEstimatedCost, v)
+	return u
+}
+
+// UpdateEstimatedCost sets the "estimated_cost" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateEstimatedCost() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldEstimatedCost)
+	return u
+}
+
+// AddEstimatedCost adds v to the "estimated_cost" field.
+func (u *UsageLogUpsert) AddEstimatedCost(v float64) *UsageLogUpsert {
+	u.Add(usagelog.FieldEstimatedCost, v)
+	return u
+}
+
+// ClearEstimatedCost clears the value of the "estimated_cost" field.
+func (u *UsageLogUpsert) ClearEstimatedCost() *UsageLogUpsert {
+	u.SetNull(usagelog.FieldEstimatedCost)
+	return u
+}
+
+// SetCostOverage sets the "cost_overage" field.
+func (u *UsageLogUpsert) SetCostOverage(v bool) *UsageLogUpsert {
+	u.Set(usagelog.FieldCostOverage, v)
+	return u
+}
+
+// UpdateCostOverage sets the "cost_overage" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateCostOverage() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldCostOverage)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -2706,6 +3017,160 @@ func (u *UsageLogUpsertOne) UpdateCacheTTLOverridden() *UsageLogUpsertOne {
 	})
 }
 
+// SetUsageEstimated sets the "usage_estimated" field.
+func (u *UsageLogUpsertOne) SetUsageEstimated(v bool) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetUsageEstimated(v)
+	})
+}
+
+// UpdateUsageEstimated sets the "usage_estimated" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateUsageEstimated() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateUsageEstimated()
+	})
+}
+
+// SetBillingUnverified sets the "billing_unverified" field.
+func (u *UsageLogUpsertOne) SetBillingUnverified(v bool) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetBillingUnverified(v)
+	})
+}
+
+// UpdateBillingUnverified sets the "billing_unverified" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateBillingUnverified() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateBillingUnverified()
+	})
+}
+
+// SetRequestBytes sets the "request_bytes" field.
+func (u *UsageLogUpsertOne) SetRequestBytes(v int64) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetRequestBytes(v)
+	})
+}
+
+// AddRequestBytes adds v to the "request_bytes" field.
+func (u *UsageLogUpsertOne) AddRequestBytes(v int64) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.AddRequestBytes(v)
+	})
+}
+
+// UpdateRequestBytes sets the "request_bytes" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateRequestBytes() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateRequestBytes()
+	})
+}
+
+// ClearRequestBytes clears the value of the "request_bytes" field.
+func (u *UsageLogUpsertOne) ClearRequestBytes() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearRequestBytes()
+	})
+}
+
+// SetResponseBytes sets the "response_bytes" field.
+func (u *UsageLogUpsertOne) SetResponseBytes(v int64) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetResponseBytes(v)
+	})
+}
+
+// AddResponseBytes adds v to the "response_bytes" field.
+func (u *UsageLogUpsertOne) AddResponseBytes(v int64) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.AddResponseBytes(v)
+	})
+}
+
+// UpdateResponseBytes sets the "response_bytes" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateResponseBytes() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateResponseBytes()
+	})
+}
+
+// ClearResponseBytes clears the value of the "response_bytes" field.
+func (u *UsageLogUpsertOne) ClearResponseBytes() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearResponseBytes()
+	})
+}
+
+// SetResponseCacheHit sets the "response_cache_hit" field.
+func (u *UsageLogUpsertOne) SetResponseCacheHit(v bool) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetResponseCacheHit(v)
+	})
+}
+
+// UpdateResponseCacheHit sets the "response_cache_hit" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateResponseCacheHit() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateResponseCacheHit()
+	})
+}
+
+// SetPricingUnavailable sets the "pricing_unavailable" field.
+func (u *UsageLogUpsertOne) SetPricingUnavailable(v bool) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetPricingUnavailable(v)
+	})
+}
+
+// UpdatePricingUnavailable sets the "pricing_unavailable" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdatePricingUnavailable() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdatePricingUnavailable()
+	})
+}
+
+// SetEstimatedCost sets the "estimated_cost" field.
+func (u *UsageLogUpsertOne) SetEstimatedCost(v float64) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetEstimatedCost(v)
+	})
+}
+
+// AddEstimatedCost adds v to the "estimated_cost" field.
+func (u *UsageLogUpsertOne) AddEstimatedCost(v float64) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.AddEstimatedCost(v)
+	})
+}
+
+// UpdateEstimatedCost sets the "estimated_cost" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateEstimatedCost() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateEstimatedCost()
+	})
+}
+
+// ClearEstimatedCost clears the value of the "estimated_cost" field.
+func (u *UsageLogUpsertOne) ClearEstimatedCost() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearEstimatedCost()
+	})
+}
+
+// SetCostOverage sets the "cost_overage" field.
+func (u *UsageLogUpsertOne) SetCostOverage(v bool) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetCostOverage(v)
+	})
+}
+
+// UpdateCostOverage sets the "cost_overage" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateCostOverage() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateCostOverage()
+	})
+}
+
 // Exec executes the query.
 func (u *UsageLogUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -3736,6 +4201,160 @@ func (u *UsageLogUpsertBulk) UpdateCacheTTLOverridden() *UsageLogUpsertBulk {
 	})
 }
 
+// SetUsageEstimated sets the "usage_estimated" field.
+func (u *UsageLogUpsertBulk) SetUsageEstimated(v bool) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetUsageEstimated(v)
+	})
+}
+
+// UpdateUsageEstimated sets the "usage_estimated" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateUsageEstimated() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateUsageEstimated()
+	})
+}
+
+// SetBillingUnverified sets the "billing_unverified" field.
+func (u *UsageLogUpsertBulk) SetBillingUnverified(v bool) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetBillingUnverified(v)
+	})
+}
+
+// UpdateBillingUnverified sets the "billing_unverified" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateBillingUnverified() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateBillingUnverified()
+	})
+}
+
+// SetRequestBytes sets the "request_bytes" field.
+func (u *UsageLogUpsertBulk) SetRequestBytes(v int64) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetRequestBytes(v)
+	})
+}
+
+// AddRequestBytes adds v to the "request_bytes" field.
+func (u *UsageLogUpsertBulk) AddRequestBytes(v int64) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.AddRequestBytes(v)
+	})
+}
+
+// UpdateRequestBytes sets the "request_bytes" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateRequestBytes() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateRequestBytes()
+	})
+}
+
+// ClearRequestBytes clears the value of the "request_bytes" field.
+func (u *UsageLogUpsertBulk) ClearRequestBytes() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearRequestBytes()
+	})
+}
+
+// SetResponseBytes sets the "response_bytes" field.
+func (u *UsageLogUpsertBulk) SetResponseBytes(v int64) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetResponseBytes(v)
+	})
+}
+
+// AddResponseBytes adds v to the "response_bytes" field.
+func (u *UsageLogUpsertBulk) AddResponseBytes(v int64) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.AddResponseBytes(v)
+	})
+}
+
+// UpdateResponseBytes sets the "response_bytes" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateResponseBytes() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateResponseBytes()
+	})
+}
+
+// ClearResponseBytes clears the value of the "response_bytes" field.
+func (u *UsageLogUpsertBulk) ClearResponseBytes() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearResponseBytes()
+	})
+}
+
+// SetResponseCacheHit sets the "response_cache_hit" field.
+func (u *UsageLogUpsertBulk) SetResponseCacheHit(v bool) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetResponseCacheHit(v)
+	})
+}
+
+// UpdateResponseCacheHit sets the "response_cache_hit" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateResponseCacheHit() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateResponseCacheHit()
+	})
+}
+
+// SetPricingUnavailable sets the "pricing_unavailable" field.
+func (u *UsageLogUpsertBulk) SetPricingUnavailable(v bool) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetPricingUnavailable(v)
+	})
+}
+
+// UpdatePricingUnavailable sets the "pricing_unavailable" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdatePricingUnavailable() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdatePricingUnavailable()
+	})
+}
+
+// SetEstimatedCost sets the "estimated_cost" field.
+func (u *UsageLogUpsertBulk) SetEstimatedCost(v float64) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetEstimatedCost(v)
+	})
+}
+
+// AddEstimatedCost adds v to the "estimated_cost" field.
+func (u *UsageLogUpsertBulk) AddEstimatedCost(v float64) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.AddEstimatedCost(v)
+	})
+}
+
+// UpdateEstimatedCost sets the "estimated_cost" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateEstimatedCost() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateEstimatedCost()
+	})
+}
+
+// ClearEstimatedCost clears the value of the "estimated_cost" field.
+func (u *UsageLogUpsertBulk) ClearEstimatedCost() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearEstimatedCost()
+	})
+}
+
+// SetCostOverage sets the "cost_overage" field.
+func (u *UsageLogUpsertBulk) SetCostOverage(v bool) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetCostOverage(v)
+	})
+}
+
+// UpdateCostOverage sets the "cost_overage" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateCostOverage() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateCostOverage()
+	})
+}
+
 // Exec executes the query.
 func (u *UsageLogUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
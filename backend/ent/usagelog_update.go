@@ -825,6 +825,157 @@ func (_u *UsageLogUpdate) SetNillableCacheTTLOverridden(v *bool) *UsageLogUpdate
 	return _u
 }
 
+// SetUsageEstimated sets the "usage_estimated" field.
+func (_u *UsageLogUpdate) SetUsageEstimated(v bool) *UsageLogUpdate {
+	_u.mutation.SetUsageEstimated(v)
+	return _u
+}
+
+// SetNillableUsageEstimated sets the "usage_estimated" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableUsageEstimated(v *bool) *UsageLogUpdate {
+	if v != nil {
+		_u.SetUsageEstimated(*v)
+	}
+	return _u
+}
+
+// SetBillingUnverified sets the "billing_unverified" field.
+func (_u *UsageLogUpdate) SetBillingUnverified(v bool) *UsageLogUpdate {
+	_u.mutation.SetBillingUnverified(v)
+	return _u
+}
+
+// SetNillableBillingUnverified sets the "billing_unverified" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableBillingUnverified(v *bool) *UsageLogUpdate {
+	if v != nil {
+		_u.SetBillingUnverified(*v)
+	}
+	return _u
+}
+
+// SetRequestBytes sets the "request_bytes" field.
+func (_u *UsageLogUpdate) SetRequestBytes(v int64) *UsageLogUpdate {
+	_u.mutation.ResetRequestBytes()
+	_u.mutation.SetRequestBytes(v)
+	return _u
+}
+
+// SetNillableRequestBytes sets the "request_bytes" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableRequestBytes(v *int64) *UsageLogUpdate {
+	if v != nil {
+		_u.SetRequestBytes(*v)
+	}
+	return _u
+}
+
+// AddRequestBytes adds value to the "request_bytes" field.
+func (_u *UsageLogUpdate) AddRequestBytes(v int64) *UsageLogUpdate {
+	_u.mutation.AddRequestBytes(v)
+	return _u
+}
+
+// ClearRequestBytes clears the value of the "request_bytes" field.
+func (_u *UsageLogUpdate) ClearRequestBytes() *UsageLogUpdate {
+	_u.mutation.ClearRequestBytes()
+	return _u
+}
+
+// SetResponseBytes sets the "response_bytes" field.
+func (_u *UsageLogUpdate) SetResponseBytes(v int64) *UsageLogUpdate {
+	_u.mutation.ResetResponseBytes()
+	_u.mutation.SetResponseBytes(v)
+	return _u
+}
+
+// SetNillableResponseBytes sets the "response_bytes" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableResponseBytes(v *int64) *UsageLogUpdate {
+	if v != nil {
+		_u.SetResponseBytes(*v)
+	}
+	return _u
+}
+
+// AddResponseBytes adds value to the "response_bytes" field.
+func (_u *UsageLogUpdate) AddResponseBytes(v int64) *UsageLogUpdate {
+	_u.mutation.AddResponseBytes(v)
+	return _u
+}
+
+// ClearResponseBytes clears the value of the "response_bytes" field.
+func (_u *UsageLogUpdate) ClearResponseBytes() *UsageLogUpdate {
+	_u.mutation.ClearResponseBytes()
+	return _u
+}
+
+// SetResponseCacheHit sets the "response_cache_hit" field.
+func (_u *UsageLogUpdate) SetResponseCacheHit(v bool) *UsageLogUpdate {
+	_u.mutation.SetResponseCacheHit(v)
+	return _u
+}
+
+// SetNillableResponseCacheHit sets the "response_cache_hit" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableResponseCacheHit(v *bool) *UsageLogUpdate {
+	if v != nil {
+		_u.SetResponseCacheHit(*v)
+	}
+	return _u
+}
+
+// SetPricingUnavailable sets the "pricing_unavailable" field.
+func (_u *UsageLogUpdate) SetPricingUnavailable(v bool) *UsageLogUpdate {
+	_u.mutation.SetPricingUnavailable(v)
+	return _u
+}
+
+// SetNillablePricingUnavailable sets the "pricing_unavailable" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillablePricingUnavailable(v *bool) *UsageLogUpdate {
+	if v != nil {
+		_u.SetPricingUnavailable(*v)
+	}
+	return _u
+}
+
+// SetEstimatedCost sets the "estimated_cost" field.
+func (_u *UsageLogUpdate) SetEstimatedCost(v float64) *UsageLogUpdate {
+	_u.mutation.ResetEstimatedCost()
+	_u.mutation.SetEstimatedCost(v)
+	return _u
+}
+
+// SetNillableEstimatedCost sets the "estimated_cost" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableEstimatedCost(v *float64) *UsageLogUpdate {
+	if v != nil {
+		_u.SetEstimatedCost(*v)
+	}
+	return _u
+}
+
+// AddEstimatedCost adds value to the "estimated_cost" field.
+func (_u *UsageLogUpdate) AddEstimatedCost(v float64) *UsageLogUpdate {
+	_u.mutation.AddEstimatedCost(v)
+	return _u
+}
+
+// ClearEstimatedCost clears the value of the "estimated_cost" field.
+func (_u *UsageLogUpdate) ClearEstimatedCost() *UsageLogUpdate {
+	_u.mutation.ClearEstimatedCost()
+	return _u
+}
+
+// SetCostOverage sets the "cost_overage" field.
+func (_u *UsageLogUpdate) SetCostOverage(v bool) *UsageLogUpdate {
+	_u.mutation.SetCostOverage(v)
+	return _u
+}
+
+// SetNillableCostOverage sets the "cost_overage" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableCostOverage(v *bool) *UsageLogUpdate {
+	if v != nil {
+		_u.SetCostOverage(*v)
+	}
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *UsageLogUpdate) SetUser(v *User) *UsageLogUpdate {
 	return _u.SetUserID(v.ID)
@@ -1213,6 +1364,48 @@ func (_u *UsageLogUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.CacheTTLOverridden(); ok {
 		_spec.SetField(usagelog.FieldCacheTTLOverridden, field.TypeBool, value)
 	}
+	if value, ok := _u.mutation.UsageEstimated(); ok {
+		_spec.SetField(usagelog.FieldUsageEstimated, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.BillingUnverified(); ok {
+		_spec.SetField(usagelog.FieldBillingUnverified, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.RequestBytes(); ok {
+		_spec.SetField(usagelog.FieldRequestBytes, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedRequestBytes(); ok {
+		_spec.AddField(usagelog.FieldRequestBytes, field.TypeInt64, value)
+	}
+	if _u.mutation.RequestBytesCleared() {
+		_spec.ClearField(usagelog.FieldRequestBytes, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.ResponseBytes(); ok {
+		_spec.SetField(usagelog.FieldResponseBytes, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseBytes(); ok {
+		_spec.AddField(usagelog.FieldResponseBytes, field.TypeInt64, value)
+	}
+	if _u.mutation.ResponseBytesCleared() {
+		_spec.ClearField(usagelog.FieldResponseBytes, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.ResponseCacheHit(); ok {
+		_spec.SetField(usagelog.FieldResponseCacheHit, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.PricingUnavailable(); ok {
+		_spec.SetField(usagelog.FieldPricingUnavailable, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.EstimatedCost(); ok {
+		_spec.SetField(usagelog.FieldEstimatedCost, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedEstimatedCost(); ok {
+		_spec.AddField(usagelog.FieldEstimatedCost, field.TypeFloat64, value)
+	}
+	if _u.mutation.EstimatedCostCleared() {
+		_spec.ClearField(usagelog.FieldEstimatedCost, field.TypeFloat64)
+	}
+	if value, ok := _u.mutation.CostOverage(); ok {
+		_spec.SetField(usagelog.FieldCostOverage, field.TypeBool, value)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -2171,6 +2364,157 @@ func (_u *UsageLogUpdateOne) SetNillableCacheTTLOverridden(v *bool) *UsageLogUpd
 	return _u
 }
 
+// SetUsageEstimated sets the "usage_estimated" field.
+func (_u *UsageLogUpdateOne) SetUsageEstimated(v bool) *UsageLogUpdateOne {
+	_u.mutation.SetUsageEstimated(v)
+	return _u
+}
+
+// SetNillableUsageEstimated sets the "usage_estimated" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableUsageEstimated(v *bool) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetUsageEstimated(*v)
+	}
+	return _u
+}
+
+// SetBillingUnverified sets the "billing_unverified" field.
+func (_u *UsageLogUpdateOne) SetBillingUnverified(v bool) *UsageLogUpdateOne {
+	_u.mutation.SetBillingUnverified(v)
+	return _u
+}
+
+// SetNillableBillingUnverified sets the "billing_unverified" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableBillingUnverified(v *bool) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetBillingUnverified(*v)
+	}
+	return _u
+}
+
+// SetRequestBytes sets the "request_bytes" field.
+func (_u *UsageLogUpdateOne) SetRequestBytes(v int64) *UsageLogUpdateOne {
+	_u.mutation.ResetRequestBytes()
+	_u.mutation.SetRequestBytes(v)
+	return _u
+}
+
+// SetNillableRequestBytes sets the "request_bytes" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableRequestBytes(v *int64) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetRequestBytes(*v)
+	}
+	return _u
+}
+
+// AddRequestBytes adds value to the "request_bytes" field.
+func (_u *UsageLogUpdateOne) AddRequestBytes(v int64) *UsageLogUpdateOne {
+	_u.mutation.AddRequestBytes(v)
+	return _u
+}
+
+// ClearRequestBytes clears the value of the "request_bytes" field.
+func (_u *UsageLogUpdateOne) ClearRequestBytes() *UsageLogUpdateOne {
+	_u.mutation.ClearRequestBytes()
+	return _u
+}
+
+// SetResponseBytes sets the "response_bytes" field.
+func (_u *UsageLogUpdateOne) SetResponseBytes(v int64) *UsageLogUpdateOne {
+	_u.mutation.ResetResponseBytes()
+	_u.mutation.SetResponseBytes(v)
+	return _u
+}
+
+// SetNillableResponseBytes sets the "response_bytes" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableResponseBytes(v *int64) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetResponseBytes(*v)
+	}
+	return _u
+}
+
+// AddResponseBytes adds value to the "response_bytes" field.
+func (_u *UsageLogUpdateOne) AddResponseBytes(v int64) *UsageLogUpdateOne {
+	_u.mutation.AddResponseBytes(v)
+	return _u
+}
+
+// ClearResponseBytes clears the value of the "response_bytes" field.
+func (_u *UsageLogUpdateOne) ClearResponseBytes() *UsageLogUpdateOne {
+	_u.mutation.ClearResponseBytes()
+	return _u
+}
+
+// SetResponseCacheHit sets the "response_cache_hit" field.
+func (_u *UsageLogUpdateOne) SetResponseCacheHit(v bool) *UsageLogUpdateOne {
+	_u.mutation.SetResponseCacheHit(v)
+	return _u
+}
+
+// SetNillableResponseCacheHit sets the "response_cache_hit" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableResponseCacheHit(v *bool) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheHit(*v)
+	}
+	return _u
+}
+
+// SetPricingUnavailable sets the "pricing_unavailable" field.
+func (_u *UsageLogUpdateOne) SetPricingUnavailable(v bool) *UsageLogUpdateOne {
+	_u.mutation.SetPricingUnavailable(v)
+	return _u
+}
+
+// SetNillablePricingUnavailable sets the "pricing_unavailable" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillablePricingUnavailable(v *bool) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetPricingUnavailable(*v)
+	}
+	return _u
+}
+
+// SetEstimatedCost sets the "estimated_cost" field.
+func (_u *UsageLogUpdateOne) SetEstimatedCost(v float64) *UsageLogUpdateOne {
+	_u.mutation.ResetEstimatedCost()
+	_u.mutation.SetEstimatedCost(v)
+	return _u
+}
+
+// SetNillableEstimatedCost sets the "estimated_cost" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableEstimatedCost(v *float64) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetEstimatedCost(*v)
+	}
+	return _u
+}
+
+// AddEstimatedCost adds value to the "estimated_cost" field.
+func (_u *UsageLogUpdateOne) AddEstimatedCost(v float64) *UsageLogUpdateOne {
+	_u.mutation.AddEstimatedCost(v)
+	return _u
+}
+
+// ClearEstimatedCost clears the value of the "estimated_cost" field.
+func (_u *UsageLogUpdateOne) ClearEstimatedCost() *UsageLogUpdateOne {
+	_u.mutation.ClearEstimatedCost()
+	return _u
+}
+
+// SetCostOverage sets the "cost_overage" field.
+func (_u *UsageLogUpdateOne) SetCostOverage(v bool) *UsageLogUpdateOne {
+	_u.mutation.SetCostOverage(v)
+	return _u
+}
+
+// SetNillableCostOverage sets the "cost_overage" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableCostOverage(v *bool) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetCostOverage(*v)
+	}
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *UsageLogUpdateOne) SetUser(v *User) *UsageLogUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -2589,6 +2933,48 @@ func (_u *UsageLogUpdateOne) sqlSave(ctx context.Context) (_node *UsageLog, err
 	if value, ok := _u.mutation.CacheTTLOverridden(); ok {
 		_spec.SetField(usagelog.FieldCacheTTLOverridden, field.TypeBool, value)
 	}
+	if value, ok := _u.mutation.UsageEstimated(); ok {
+		_spec.SetField(usagelog.FieldUsageEstimated, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.BillingUnverified(); ok {
+		_spec.SetField(usagelog.FieldBillingUnverified, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.RequestBytes(); ok {
+		_spec.SetField(usagelog.FieldRequestBytes, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedRequestBytes(); ok {
+		_spec.AddField(usagelog.FieldRequestBytes, field.TypeInt64, value)
+	}
+	if _u.mutation.RequestBytesCleared() {
+		_spec.ClearField(usagelog.FieldRequestBytes, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.ResponseBytes(); ok {
+		_spec.SetField(usagelog.FieldResponseBytes, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseBytes(); ok {
+		_spec.AddField(usagelog.FieldResponseBytes, field.TypeInt64, value)
+	}
+	if _u.mutation.ResponseBytesCleared() {
+		_spec.ClearField(usagelog.FieldResponseBytes, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.ResponseCacheHit(); ok {
+		_spec.SetField(usagelog.FieldResponseCacheHit, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.PricingUnavailable(); ok {
+		_spec.SetField(usagelog.FieldPricingUnavailable, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.EstimatedCost(); ok {
+		_spec.SetField(usagelog.FieldEstimatedCost, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedEstimatedCost(); ok {
+		_spec.AddField(usagelog.FieldEstimatedCost, field.TypeFloat64, value)
+	}
+	if _u.mutation.EstimatedCostCleared() {
+		_spec.ClearField(usagelog.FieldEstimatedCost, field.TypeFloat64)
+	}
+	if value, ok := _u.mutation.CostOverage(); ok {
+		_spec.SetField(usagelog.FieldCostOverage, field.TypeBool, value)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -8,10 +8,13 @@ import (
 	"log/slog"
 	"net/url"
 	"os"
+	"path"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/spf13/viper"
 )
 
@@ -88,11 +91,13 @@ type Config struct {
 	UsageCleanup            UsageCleanupConfig            `mapstructure:"usage_cleanup"`
 	Concurrency             ConcurrencyConfig             `mapstructure:"concurrency"`
 	TokenRefresh            TokenRefreshConfig            `mapstructure:"token_refresh"`
+	AccountHealthProbe      AccountHealthProbeConfig      `mapstructure:"account_health_probe"`
 	RunMode                 string                        `mapstructure:"run_mode" yaml:"run_mode"`
 	Timezone                string                        `mapstructure:"timezone"` // e.g. "Asia/Shanghai", "UTC"
 	Gemini                  GeminiConfig                  `mapstructure:"gemini"`
 	Update                  UpdateConfig                  `mapstructure:"update"`
 	Idempotency             IdempotencyConfig             `mapstructure:"idempotency"`
+	Audit                   AuditConfig                   `mapstructure:"audit"`
 }
 
 type LogConfig struct {
@@ -175,6 +180,16 @@ type IdempotencyConfig struct {
 	CleanupBatchSize int `mapstructure:"cleanup_batch_size"`
 }
 
+// AuditConfig 管理员操作审计日志配置
+type AuditConfig struct {
+	// RetentionDays 审计日志保留天数；0 表示永久保留（不清理）。
+	RetentionDays int `mapstructure:"retention_days"`
+	// CleanupIntervalSeconds 过期审计日志清理周期（秒）。
+	CleanupIntervalSeconds int `mapstructure:"cleanup_interval_seconds"`
+	// CleanupBatchSize 每轮清理的最大删除条数。
+	CleanupBatchSize int `mapstructure:"cleanup_batch_size"`
+}
+
 type LinuxDoConnectConfig struct {
 	Enabled             bool   `mapstructure:"enabled"`
 	ClientID            string `mapstructure:"client_id"`
@@ -531,6 +546,27 @@ type TokenRefreshConfig struct {
 	RetryBackoffSeconds int `mapstructure:"retry_backoff_seconds"`
 }
 
+// AccountHealthProbeConfig 账号主动健康探测配置。
+// 定期对每个启用的账号发起一次低成本的鉴权请求（模型列表 / countTokens 等），
+// 连续失败达到阈值的账号在选号打分中降权，探测成功后自动恢复。
+type AccountHealthProbeConfig struct {
+	// Enabled 是否启用（默认 false）
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalSeconds 探测周期（秒）
+	IntervalSeconds int `mapstructure:"interval_seconds"`
+	// Concurrency 同时进行的探测数
+	Concurrency int `mapstructure:"concurrency"`
+	// TimeoutSeconds 单次探测超时（秒）
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// FailureThreshold 连续失败多少次判定为不健康
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// Probes 各平台的探测方式：models（模型列表）/ count_tokens（仅 gemini）/ none（不探测）；
+	// 未配置的平台使用内置默认值
+	Probes map[string]string `mapstructure:"probes"`
+	// GeminiModel gemini count_tokens 探测使用的模型
+	GeminiModel string `mapstructure:"gemini_model"`
+}
+
 type PricingConfig struct {
 	// 价格数据远程URL（默认使用LiteLLM镜像）
 	RemoteURL string `mapstructure:"remote_url"`
@@ -544,6 +580,17 @@ type PricingConfig struct {
 	UpdateIntervalHours int `mapstructure:"update_interval_hours"`
 	// 哈希校验间隔（分钟）
 	HashCheckIntervalMinutes int `mapstructure:"hash_check_interval_minutes"`
+	// 自定义模型价格表（按模型名精确匹配，大小写不敏感），优先于同步的价格数据与内置回退价格
+	ModelPrices []ModelPriceConfig `mapstructure:"model_prices"`
+}
+
+// ModelPriceConfig 单个模型的价格（USD / 1K tokens），未填写的项按 0 计
+type ModelPriceConfig struct {
+	Model              string  `mapstructure:"model"`
+	InputPer1K         float64 `mapstructure:"input_per_1k"`
+	OutputPer1K        float64 `mapstructure:"output_per_1k"`
+	CacheCreationPer1K float64 `mapstructure:"cache_creation_per_1k"`
+	CacheReadPer1K     float64 `mapstructure:"cache_read_per_1k"`
 }
 
 type ServerConfig struct {
@@ -556,6 +603,9 @@ type ServerConfig struct {
 	TrustedProxies     []string  `mapstructure:"trusted_proxies"`       // 可信代理列表（CIDR/IP）
 	MaxRequestBodySize int64     `mapstructure:"max_request_body_size"` // 全局最大请求体限制
 	H2C                H2CConfig `mapstructure:"h2c"`                   // HTTP/2 Cleartext 配置
+	// ShutdownGraceSeconds 优雅停机宽限期（秒）：停止接收新请求后等待在途请求（含流式转发）完成，
+	// 超时后中断剩余请求并向流式客户端写出 SSE 错误事件，0 表示立即中断
+	ShutdownGraceSeconds int `mapstructure:"shutdown_grace_seconds"`
 }
 
 // H2CConfig HTTP/2 Cleartext 配置
@@ -574,13 +624,14 @@ type CORSConfig struct {
 }
 
 type SecurityConfig struct {
-	URLAllowlist                     URLAllowlistConfig   `mapstructure:"url_allowlist"`
-	ResponseHeaders                  ResponseHeaderConfig `mapstructure:"response_headers"`
-	CSP                              CSPConfig            `mapstructure:"csp"`
-	ProxyFallback                    ProxyFallbackConfig  `mapstructure:"proxy_fallback"`
-	ProxyProbe                       ProxyProbeConfig     `mapstructure:"proxy_probe"`
-	TrustForwardedIPForAPIKeyACL     bool                 `mapstructure:"trust_forwarded_ip_for_api_key_acl"`
-	trustForwardedIPForAPIKeyACLLive *atomic.Bool         `mapstructure:"-"`
+	URLAllowlist                     URLAllowlistConfig      `mapstructure:"url_allowlist"`
+	ResponseHeaders                  ResponseHeaderConfig    `mapstructure:"response_headers"`
+	ResponseRedaction                ResponseRedactionConfig `mapstructure:"response_redaction"`
+	CSP                              CSPConfig               `mapstructure:"csp"`
+	ProxyFallback                    ProxyFallbackConfig     `mapstructure:"proxy_fallback"`
+	ProxyProbe                       ProxyProbeConfig        `mapstructure:"proxy_probe"`
+	TrustForwardedIPForAPIKeyACL     bool                    `mapstructure:"trust_forwarded_ip_for_api_key_acl"`
+	trustForwardedIPForAPIKeyACLLive *atomic.Bool            `mapstructure:"-"`
 }
 
 func (c *Config) TrustForwardedIPForAPIKeyACL() bool {
@@ -615,10 +666,47 @@ type URLAllowlistConfig struct {
 	AllowInsecureHTTP bool `mapstructure:"allow_insecure_http"`
 }
 
+// GatewayForwardHeadersConfig 客户端请求头透传策略。
+// 仅作用于透传客户端头的路径；身份指纹与上游鉴权头在其后写入，始终优先。
+type GatewayForwardHeadersConfig struct {
+	// AdditionalAllowed: 在内置白名单之外额外透传的请求头（如 X-Trace-Id）
+	AdditionalAllowed []string `mapstructure:"additional_allowed"`
+	// ForceRemove: 强制剥离的请求头，优先级高于白名单（如 Accept-Language）
+	ForceRemove []string `mapstructure:"force_remove"`
+}
+
 type ResponseHeaderConfig struct {
 	Enabled           bool     `mapstructure:"enabled"`
 	AdditionalAllowed []string `mapstructure:"additional_allowed"`
 	ForceRemove       []string `mapstructure:"force_remove"`
+	// PlatformAllowed 按上游平台（anthropic/openai/gemini）额外透传的响应头，支持以 * 结尾的前缀匹配。
+	// 认证/Cookie 类头部（Authorization、Set-Cookie 等）始终不会透传。
+	PlatformAllowed map[string][]string `mapstructure:"platform_allowed"`
+}
+
+// ResponseRedactionConfig 上游响应内容改写：按平台对每个 SSE data 帧与非流式响应体应用 JSON 路径规则，
+// 避免共享网关向终端用户泄露上游账号/组织标识。
+type ResponseRedactionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxFrameBytes 单个 SSE data 帧或非流式响应体的处理上限（字节），超出时原样透传并记录告警
+	MaxFrameBytes int `mapstructure:"max_frame_bytes"`
+	// Platforms 按平台（anthropic/openai/gemini）的改写规则；不内置默认规则，未配置或为空列表的平台不改写
+	Platforms map[string][]ResponseRedactionRule `mapstructure:"platforms"`
+}
+
+// 响应改写动作
+const (
+	ResponseRedactionActionDelete        = "delete"         // 删除字段
+	ResponseRedactionActionBlank         = "blank"          // 置为空字符串
+	ResponseRedactionActionReplacePrefix = "replace_prefix" // 字符串以 From 开头时替换为 To
+)
+
+type ResponseRedactionRule struct {
+	// Path gjson/sjson 点路径，如 message.organization、response.id（不支持通配符）
+	Path   string `mapstructure:"path"`
+	Action string `mapstructure:"action"`
+	From   string `mapstructure:"from"`
+	To     string `mapstructure:"to"`
 }
 
 type CSPConfig struct {
@@ -656,6 +744,42 @@ type BillingConfig struct {
 	// UserPlatformQuotaSentinelTTLSeconds sentinel(无 limit 占位)entry 的 TTL,
 	// 显著短于 quota cache 默认 86400s 以控 Redis 内存;默认 3600=1h。
 	UserPlatformQuotaSentinelTTLSeconds int `mapstructure:"user_platform_quota_sentinel_ttl_seconds"`
+	// DegradedMode 计费缓存/数据库故障时的降级放行策略
+	DegradedMode BillingDegradedModeConfig `mapstructure:"degraded_mode"`
+	// UsageRetry 用量记录/扣费写入失败后的延迟重试队列
+	UsageRetry BillingUsageRetryConfig `mapstructure:"usage_retry"`
+}
+
+// BillingUsageRetryConfig 用量记录重试队列配置。
+// RecordUsage 因数据库死锁、短暂故障等失败时，输入（去除密钥等敏感字段）写入 Redis 队列，
+// 后台按指数退避重放；超过 MaxAgeSeconds 仍未成功的条目移入死信列表供管理员排查。
+// 重放按原请求 ID 幂等去重，不会重复扣费或重复写用量记录。
+type BillingUsageRetryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// PollIntervalSeconds 后台扫描重试队列的间隔（秒）
+	PollIntervalSeconds int `mapstructure:"poll_interval_seconds"`
+	// BatchSize 每次扫描最多处理的条目数
+	BatchSize int `mapstructure:"batch_size"`
+	// BaseBackoffSeconds 首次重试的退避时间（秒），之后每次翻倍
+	BaseBackoffSeconds int `mapstructure:"base_backoff_seconds"`
+	// MaxBackoffSeconds 单次退避上限（秒）
+	MaxBackoffSeconds int `mapstructure:"max_backoff_seconds"`
+	// MaxAgeSeconds 自首次失败起的最长重试时间（秒），超过后移入死信列表
+	MaxAgeSeconds int `mapstructure:"max_age_seconds"`
+	// DeadLetterMax 死信列表保留的最大条目数（超出时丢弃最旧条目）
+	DeadLetterMax int `mapstructure:"dead_letter_max"`
+}
+
+// BillingDegradedModeConfig 计费降级放行配置。
+// 计费资格校验因基础设施故障（Redis/DB 异常、熔断打开）无法给出结论时，
+// 按 API Key 在宽限额度内放行请求，并将用量记录标记为 billing_unverified；
+// 余额不足、订阅失效等确定性拒绝不受影响。
+type BillingDegradedModeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxRequests 每个 API Key 单次故障期间最多放行的请求数（0 表示不按次数限制）
+	MaxRequests int `mapstructure:"max_requests"`
+	// MaxMinutes 每个 API Key 自首次降级放行起的最长宽限时间（分钟，0 表示不按时间限制）
+	MaxMinutes int `mapstructure:"max_minutes"`
 }
 
 type CircuitBreakerConfig struct {
@@ -665,9 +789,84 @@ type CircuitBreakerConfig struct {
 	HalfOpenRequests    int  `mapstructure:"half_open_requests"`
 }
 
+// SSE keepalive ping 格式
+const (
+	// SSEPingFormatDefault 使用路由自身的默认格式（Claude: data ping；OpenAI: 注释 ping）
+	SSEPingFormatDefault = ""
+	// SSEPingFormatComment SSE 注释行 ":\n\n"
+	SSEPingFormatComment = "comment"
+	// SSEPingFormatEvent 具名事件 "event: ping\ndata: {...}\n\n"，适用于不接受注释行的严格 SSE 解析器
+	SSEPingFormatEvent = "event"
+	// SSEPingFormatData 仅 data 行 "data: {\"type\": \"ping\"}\n\n"（Claude 路由历史默认）
+	SSEPingFormatData = "data"
+)
+
 type ConcurrencyConfig struct {
 	// PingInterval: 并发等待期间的 SSE ping 间隔（秒）
 	PingInterval int `mapstructure:"ping_interval"`
+	// Claude: Claude / Gemini 兼容路由（/v1/messages 等）的 ping 覆盖配置
+	Claude SSEPingRouteConfig `mapstructure:"claude"`
+	// OpenAI: OpenAI 路由（/v1/responses、/v1/chat/completions 等）的 ping 覆盖配置
+	OpenAI SSEPingRouteConfig `mapstructure:"openai"`
+	// WaitQueueMultiplier: 用户等待队列深度 = ceil(并发数 × 倍数)，0 表示不随并发数缩放
+	WaitQueueMultiplier float64 `mapstructure:"wait_queue_multiplier"`
+	// WaitQueueMin: 用户等待队列深度下限（默认 20）
+	WaitQueueMin int `mapstructure:"wait_queue_min"`
+	// WaitQueueMax: 用户等待队列深度上限，0 表示不限制
+	WaitQueueMax int `mapstructure:"wait_queue_max"`
+	// InstanceHeartbeatGraceSeconds: 实例心跳宽限期（秒），超时未刷新心跳的实例视为已崩溃，
+	// 其遗留在 Redis 中的并发槽位会被其他实例回收；0 表示使用默认值 60
+	InstanceHeartbeatGraceSeconds int `mapstructure:"instance_heartbeat_grace_seconds"`
+	// QueuedNotice: 流式请求排队等待账号槽位时的 "queued" 提示
+	QueuedNotice QueuedNoticeConfig `mapstructure:"queued_notice"`
+}
+
+// 排队提示格式
+const (
+	// QueuedNoticeFormatComment SSE 注释行 ": queued ..."，所有 SSE 解析器都会忽略
+	QueuedNoticeFormatComment = "comment"
+	// QueuedNoticeFormatEvent 具名事件 "event: queue"，data 携带已等待时长
+	QueuedNoticeFormatEvent = "event"
+)
+
+// QueuedNoticeConfig 流式请求等待账号并发槽位期间，按固定间隔向客户端发送排队提示，
+// 让客户端区分「排队中」与「连接卡住」。提示均为完整 SSE 消息，不影响随后的真实响应流。
+type QueuedNoticeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalSeconds: 提示间隔（秒，1-60）
+	IntervalSeconds int `mapstructure:"interval_seconds"`
+	// Format: comment / event
+	Format string `mapstructure:"format"`
+}
+
+// GatewayTimeoutTier 单个上游超时分级，各超时为 0 表示该阶段不限制
+type GatewayTimeoutTier struct {
+	// Name: 分级名称（记录到日志与运维错误上下文），default 为兜底分级
+	Name string `mapstructure:"name"`
+	// Models: 模型名 glob 列表（大小写不敏感），如 "claude-*haiku*"、"gpt-*-mini"、"o1*"
+	Models []string `mapstructure:"models"`
+	// ConnectTimeoutSeconds: 从开始转发到收到上游响应头的超时（秒）
+	ConnectTimeoutSeconds int `mapstructure:"connect_timeout_seconds"`
+	// FirstTokenTimeoutSeconds: 从开始转发到收到首个内容事件的超时（秒）
+	FirstTokenTimeoutSeconds int `mapstructure:"first_token_timeout_seconds"`
+	// TotalTimeoutSeconds: 单次转发的总超时（秒），包含流式输出全过程
+	TotalTimeoutSeconds int `mapstructure:"total_timeout_seconds"`
+}
+
+// SSEPingRouteConfig 单类路由的 SSE keepalive ping 配置，零值表示继承全局/路由默认值。
+type SSEPingRouteConfig struct {
+	// PingInterval: ping 间隔（秒），0 表示使用 concurrency.ping_interval
+	PingInterval int `mapstructure:"ping_interval"`
+	// PingFormat: ping 格式（comment/event/data），空表示使用路由默认格式
+	PingFormat string `mapstructure:"ping_format"`
+}
+
+// ResolvedPingInterval 返回路由实际生效的 ping 间隔（秒）。
+func (c SSEPingRouteConfig) ResolvedPingInterval(fallback int) int {
+	if c.PingInterval > 0 {
+		return c.PingInterval
+	}
+	return fallback
 }
 
 type ImageConcurrencyConfig struct {
@@ -729,6 +928,10 @@ type GatewayConfig struct {
 	OpenAIHTTP2 GatewayOpenAIHTTP2Config `mapstructure:"openai_http2"`
 	// ImageConcurrency: 图片生成独立并发限制配置（默认关闭）
 	ImageConcurrency ImageConcurrencyConfig `mapstructure:"image_concurrency"`
+	// ForwardHeaders: 客户端请求头透传策略（在内置白名单基础上追加放行/强制剥离）
+	ForwardHeaders GatewayForwardHeadersConfig `mapstructure:"forward_headers"`
+	// CountTokensMaxConcurrency: 单账号 count_tokens 请求的进程内并发上限（不排队，超限直接 429），0表示不限制
+	CountTokensMaxConcurrency int `mapstructure:"count_tokens_max_concurrency"`
 
 	// HTTP 上游连接池配置（性能优化：支持高并发场景调优）
 	// MaxIdleConns: 所有主机的最大空闲连接总数
@@ -758,19 +961,38 @@ type GatewayConfig struct {
 
 	// StreamDataIntervalTimeout: 流数据间隔超时（秒），0表示禁用
 	StreamDataIntervalTimeout int `mapstructure:"stream_data_interval_timeout"`
+	// StreamIdleTimeoutSeconds: 流式读取空闲超时（秒），0表示禁用
+	// 上游在该时长内没有发送任何字节时关闭上游连接，并向客户端发送 stream_timeout 错误事件；
+	// 在读取层计时，与首 token 超时、整体超时以及 StreamDataIntervalTimeout 的巡检相互独立
+	StreamIdleTimeoutSeconds int `mapstructure:"stream_idle_timeout_seconds"`
 	// StreamKeepaliveInterval: 流式 keepalive 间隔（秒），0表示禁用
 	StreamKeepaliveInterval int `mapstructure:"stream_keepalive_interval"`
+	// FirstTokenTimeoutSeconds: 流式首 token 超时（秒），0表示禁用
+	// 上游接受请求后迟迟不输出首个内容事件时中止该上游，并在尚未向客户端写出任何数据时切换账号重试
+	FirstTokenTimeoutSeconds int `mapstructure:"first_token_timeout_seconds"`
 	// ImageStreamDataIntervalTimeout: 图片流数据间隔超时（秒），0表示禁用
 	ImageStreamDataIntervalTimeout int `mapstructure:"image_stream_data_interval_timeout"`
 	// ImageStreamKeepaliveInterval: 图片流式 keepalive 间隔（秒），0表示禁用
 	ImageStreamKeepaliveInterval int `mapstructure:"image_stream_keepalive_interval"`
 	// MaxLineSize: 上游 SSE 单行最大字节数（0使用默认值）
 	MaxLineSize int `mapstructure:"max_line_size"`
+	// EstimatedUsageRateMultiplier: 上游流式响应缺失 usage、按文本估算 token 时叠加的费率倍数
+	// 1.0 表示与正常计费一致，>1 为加收，<1 为折扣
+	EstimatedUsageRateMultiplier float64 `mapstructure:"estimated_usage_rate_multiplier"`
+	// ForceNonStreamingModels: 强制以非流式转发的模型列表（按渠道映射后的模型名精确匹配）
+	// 客户端请求 stream=true 时，网关把上游完整响应重新封装为单个 SSE chunk + [DONE] 返回。
+	// 仅作用于 OpenAI 平台的 /v1/chat/completions；/v1/responses、/v1/messages 等其他端点不受影响，照常流式转发
+	ForceNonStreamingModels []string `mapstructure:"force_non_streaming_models"`
+	// TimeoutTiers: 按模型族分级的上游超时（按请求模型名 glob 匹配，精确匹配优先，其次字面量最长的 pattern）
+	// 未命中任何分级时使用名为 default 的分级；未配置 default 时各阶段均不限制（不继承 first_token_timeout_seconds）
+	TimeoutTiers []GatewayTimeoutTier `mapstructure:"timeout_tiers"`
 
 	// 是否记录上游错误响应体摘要（避免输出请求内容）
 	LogUpstreamErrorBody bool `mapstructure:"log_upstream_error_body"`
 	// 上游错误响应体记录最大字节数（超过会截断）
 	LogUpstreamErrorBodyMaxBytes int `mapstructure:"log_upstream_error_body_max_bytes"`
+	// 是否将上游请求 ID（x-request-id / cf-ray 等）以 X-Upstream-Request-Id 响应头回传给客户端
+	UpstreamRequestIDPassthrough bool `mapstructure:"upstream_request_id_passthrough"`
 
 	// API-key 账号在客户端未提供 anthropic-beta 时，是否按需自动补齐（默认关闭以保持兼容）
 	InjectBetaForAPIKey bool `mapstructure:"inject_beta_for_apikey"`
@@ -782,6 +1004,9 @@ type GatewayConfig struct {
 	MaxAccountSwitches int `mapstructure:"max_account_switches"`
 	// Gemini 账户切换最大次数（Gemini 平台单独配置，因 API 限制更严格）
 	MaxAccountSwitchesGemini int `mapstructure:"max_account_switches_gemini"`
+	// TransientRetryAttempts 建立连接阶段的瞬时网络错误（拨号超时/被重置、代理握手失败）在同一账号上原地重试的次数（0 表示不重试）。
+	// 此类错误发生时请求尚未写出，重发不会重复执行；连接建立后的错误不重试。与切换账号的 failover 相互独立。
+	TransientRetryAttempts int `mapstructure:"transient_retry_attempts"`
 
 	// Antigravity 429 fallback 限流时间（分钟），解析重置时间失败时使用
 	AntigravityFallbackCooldownMinutes int `mapstructure:"antigravity_fallback_cooldown_minutes"`
@@ -789,12 +1014,48 @@ type GatewayConfig struct {
 	// Scheduling: 账号调度相关配置
 	Scheduling GatewaySchedulingConfig `mapstructure:"scheduling"`
 
+	// Routing: 负载感知选号的加权打分配置（Anthropic/Gemini/Antigravity 调度路径）
+	Routing GatewayRoutingConfig `mapstructure:"routing"`
+
+	// UpstreamRateLimit: 基于上游限流响应头（x-ratelimit-* / anthropic-ratelimit-*）的选号降权
+	UpstreamRateLimit GatewayUpstreamRateLimitConfig `mapstructure:"upstream_ratelimit"`
+
+	// ServerErrorPenalty: 短时间内持续返回 5xx 的账号临时选号降权（轻于熔断，到期自动恢复）
+	ServerErrorPenalty GatewayServerErrorPenaltyConfig `mapstructure:"server_error_penalty"`
+
+	// RequestCost: 单请求费用上限（max_cost / X-Max-Cost）与费用预览（X-Estimated-Cost）
+	RequestCost GatewayRequestCostConfig `mapstructure:"request_cost"`
+
+	// GeminiAttachments: Claude 兼容入口转换到 Gemini 时的图片 / 文档附件处理（大小上限、URL 下载内联）
+	GeminiAttachments GatewayGeminiAttachmentsConfig `mapstructure:"gemini_attachments"`
+
+	// GeminiRateLimitReset: Gemini 429 重置等待（quotaResetDelay / "Please retry in Xs" / 每日配额）的取整策略
+	GeminiRateLimitReset GatewayGeminiRateLimitResetConfig `mapstructure:"gemini_rate_limit_reset"`
+
+	// SessionAffinity: 客户端显式控制粘性会话（X-Session-Affinity / X-Session-Affinity-TTL 头）
+	SessionAffinity GatewaySessionAffinityConfig `mapstructure:"session_affinity"`
+
 	// TLSFingerprint: TLS指纹伪装配置
 	TLSFingerprint TLSFingerprintConfig `mapstructure:"tls_fingerprint"`
 
 	// UsageRecord: 使用量记录异步队列配置（有界队列 + 固定 worker）
 	UsageRecord GatewayUsageRecordConfig `mapstructure:"usage_record"`
 
+	// Capture: 按 API Key 抓取最终上游请求/响应，用于排查转换问题（管理员按 Key 开启，自动过期）
+	Capture GatewayCaptureConfig `mapstructure:"capture"`
+
+	// Idempotency: OpenAI Responses 非流式请求的 Idempotency-Key 支持（Redis 保存最终响应用于重放）
+	Idempotency GatewayIdempotencyConfig `mapstructure:"idempotency"`
+
+	// ResponseCache: 确定性（temperature=0）非流式请求的响应缓存（按 API Key 或 X-Cache 请求头启用）
+	ResponseCache GatewayResponseCacheConfig `mapstructure:"response_cache"`
+
+	// PayloadValidation: 转发前校验 OpenAI 请求 input 中的 base64 图片/文件大小与声明类型
+	PayloadValidation GatewayPayloadValidationConfig `mapstructure:"payload_validation"`
+
+	// TTFTStats: 按模型统计首 token 延迟分位数（进程内滚动窗口）
+	TTFTStats GatewayTTFTStatsConfig `mapstructure:"ttft_stats"`
+
 	// UserGroupRateCacheTTLSeconds: 用户分组倍率热路径缓存 TTL（秒）
 	UserGroupRateCacheTTLSeconds int `mapstructure:"user_group_rate_cache_ttl_seconds"`
 	// ModelsListCacheTTLSeconds: /v1/models 模型列表短缓存 TTL（秒）
@@ -979,6 +1240,65 @@ type GatewayOpenAISchedulerConfig struct {
 }
 
 // GatewayUsageRecordConfig 使用量记录异步队列配置
+// GatewayCaptureConfig API Key 请求/响应抓取配置
+type GatewayCaptureConfig struct {
+	// MaxBodyBytes: 请求体/响应体保存的最大字节数；超出部分截断，流式响应超出时整条跳过
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// MaxHours: 单次开启抓取允许的最长时长（小时），到期自动关闭
+	MaxHours int `mapstructure:"max_hours"`
+	// QueueSize: 异步写入队列容量；队列满时丢弃抓取记录，不阻塞请求
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+// GatewayIdempotencyConfig 网关 Idempotency-Key 配置。
+// 同一 API Key 下相同 Idempotency-Key + 相同请求体的重试直接重放已保存的响应（不转发上游、不计费）；
+// 相同 Key 不同请求体返回 409；进行中的重复请求短暂等待原请求完成。
+type GatewayIdempotencyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WindowSeconds: 已完成响应的保存时长（秒）
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// MaxBodyBytes: 可保存的最大响应体字节数；超出时不保存，重试会再次转发
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// InFlightTimeoutSeconds: 进行中标记的有效期（秒），防止进程异常退出后 Key 永久被占用
+	InFlightTimeoutSeconds int `mapstructure:"in_flight_timeout_seconds"`
+	// WaitTimeoutSeconds: 重复请求等待原请求完成的最长时间（秒），超时返回 409
+	WaitTimeoutSeconds int `mapstructure:"wait_timeout_seconds"`
+}
+
+// GatewayResponseCacheConfig 网关响应缓存配置。
+// API Key 开启 response_cache_enabled 或请求携带 X-Cache: true 时，temperature=0 的非流式请求
+// 按规范化请求体缓存成功响应；命中时直接返回缓存内容（不转发上游、不计费），使用记录标记为缓存命中。
+type GatewayResponseCacheConfig struct {
+	// Enabled: 总开关，关闭后忽略 API Key 设置与请求头
+	Enabled bool `mapstructure:"enabled"`
+	// TTLSeconds: 缓存响应的保存时长（秒）
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// MaxBodyBytes: 可缓存的最大响应体字节数；超出时不缓存
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+}
+
+// GatewayPayloadValidationConfig OpenAI 请求内嵌 base64 图片/文件的预校验配置。
+// 在占用账号槽位之前拒绝超限或类型不符的内容，避免上游返回难以定位的 400。
+type GatewayPayloadValidationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxPartBytes: 单个图片/文件解码后的最大字节数，超出返回 413
+	MaxPartBytes int64 `mapstructure:"max_part_bytes"`
+	// MaxTotalBytes: 单个请求内所有图片/文件解码后的总字节数上限，超出返回 413
+	MaxTotalBytes int64 `mapstructure:"max_total_bytes"`
+	// VerifyMediaType: 按文件头校验常见格式（PNG/JPEG/GIF/WebP/PDF）与声明的 media type 是否一致，不一致返回 400（默认关闭）
+	VerifyMediaType bool `mapstructure:"verify_media_type"`
+}
+
+// GatewayTTFTStatsConfig 首 token 延迟（TTFT）分位数统计配置。
+// 在异步用量记录路径按模型（可选按账号）累计直方图，窗口到期后整体重置。
+type GatewayTTFTStatsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WindowMinutes: 统计窗口长度（分钟），到期后重置
+	WindowMinutes int `mapstructure:"window_minutes"`
+	// TrackAccounts: 是否额外按 模型+账号 维度统计（账号多时会增加内存占用）
+	TrackAccounts bool `mapstructure:"track_accounts"`
+}
+
 type GatewayUsageRecordConfig struct {
 	// WorkerCount: worker 初始数量（自动扩缩容开启时作为初始并发上限）
 	WorkerCount int `mapstructure:"worker_count"`
@@ -1104,6 +1424,108 @@ type GatewaySchedulingConfig struct {
 	FullRebuildIntervalSeconds int `mapstructure:"full_rebuild_interval_seconds"`
 }
 
+// GatewayRoutingConfig 负载感知选号的加权打分配置。
+// 开启后，同一优先级内不再按「负载率最低 → LRU」分层过滤，而是按
+// load*负载余量 + queue*排队余量 + error_rate*(1-近期错误率) + weight*账号权重/100 打分，
+// 得分最高者优先；同分按账号 ID 升序。粘性会话仍优先于打分。
+type GatewayRoutingConfig struct {
+	// WeightedScoringEnabled 是否启用加权打分（默认 false，保持原有分层过滤行为）
+	WeightedScoringEnabled bool `mapstructure:"weighted_scoring_enabled"`
+	// Load 当前负载率（并发占用/上限）系数
+	Load float64 `mapstructure:"load"`
+	// Queue 等待队列深度系数（按候选中最大排队数归一化）
+	Queue float64 `mapstructure:"queue"`
+	// ErrorRate 近期错误率（EWMA）系数
+	ErrorRate float64 `mapstructure:"error_rate"`
+	// Weight 账号权重（extra.routing_weight，1-100，默认 50）系数
+	Weight float64 `mapstructure:"weight"`
+	// Latency 上游延迟（EWMA，相对候选中最快账号）系数
+	Latency float64 `mapstructure:"latency"`
+	// LatencyHalfLifeSeconds 延迟样本的衰减半衰期（秒）；长时间无新样本的账号延迟因子逐步回到中性值
+	LatencyHalfLifeSeconds int `mapstructure:"latency_half_life_seconds"`
+	// Health 主动健康探测结果系数（account_health_probe 判定不健康的账号该因子为 0）
+	Health float64 `mapstructure:"health"`
+}
+
+// GatewayUpstreamRateLimitConfig 基于上游限流响应头的选号降权配置。
+// 开启后记录每个账号最近一次响应报告的剩余请求数/token 数及重置时间（Redis 多实例共享），
+// 余量低于阈值或重置即将到来的账号在同优先级内仅作为最后选择。
+type GatewayUpstreamRateLimitConfig struct {
+	// Enabled 是否启用（默认 false）
+	Enabled bool `mapstructure:"enabled"`
+	// MinRemainingTokens 剩余 token 数低于该值时降权，0 表示不按 token 余量判断
+	MinRemainingTokens int64 `mapstructure:"min_remaining_tokens"`
+	// MinRemainingRequests 剩余请求数低于该值时降权，0 表示不按请求余量判断
+	MinRemainingRequests int64 `mapstructure:"min_remaining_requests"`
+	// ResetImminentSeconds 限流窗口将在该秒数内重置时降权，0 表示禁用
+	ResetImminentSeconds int `mapstructure:"reset_imminent_seconds"`
+	// RefreshIntervalSeconds 从共享缓存合并其他实例观测值的周期（秒）
+	RefreshIntervalSeconds int `mapstructure:"refresh_interval_seconds"`
+}
+
+// GatewayServerErrorPenaltyConfig 上游 5xx 选号降权配置。
+// 账号在 WindowSeconds 内累计返回 Threshold 次 500/502/503/504 时，接下来 PenaltySeconds 内
+// 在同优先级中仅作为最后选择（不会被停止调度），到期自动恢复。
+type GatewayServerErrorPenaltyConfig struct {
+	// Enabled 是否启用（默认 true）
+	Enabled bool `mapstructure:"enabled"`
+	// WindowSeconds 统计 5xx 的滑动窗口（秒）
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// Threshold 窗口内触发降权的 5xx 次数
+	Threshold int `mapstructure:"threshold"`
+	// PenaltySeconds 降权持续时间（秒）
+	PenaltySeconds int `mapstructure:"penalty_seconds"`
+}
+
+// GatewayRequestCostConfig 单请求费用估算配置。
+// 请求体 max_cost 字段或 X-Max-Cost 请求头设置单次请求的费用上限（USD，含倍率）：转发前按计费价格表
+// 与 token 估算器估算费用，超出上限时以 402 拒绝；API Key 开启费用预览时在响应头 X-Estimated-Cost 返回估算值。
+// 请求完成后实际费用超过估算值 OverageFactor 倍时，在用量记录中标记 cost_overage。
+type GatewayRequestCostConfig struct {
+	// Enabled 是否启用（默认 true）；关闭后忽略 max_cost 且不返回费用预览
+	Enabled bool `mapstructure:"enabled"`
+	// DefaultOutputTokens 请求未设置 max_tokens 类字段时按该输出 token 数估算
+	DefaultOutputTokens int `mapstructure:"default_output_tokens"`
+	// OverageFactor 实际费用超过估算费用的倍数阈值（>= 1）
+	OverageFactor float64 `mapstructure:"overage_factor"`
+}
+
+// GatewayGeminiAttachmentsConfig Claude 请求转换为 Gemini generateContent 时的附件处理配置。
+// base64 图片 / 文档转换为 inlineData；gs:// 与 Gemini Files API 的 URL 转换为 fileData；
+// 其他 URL 在启用下载时下载后内联（受 URLFetchMaxBytes 限制），否则拒绝。
+type GatewayGeminiAttachmentsConfig struct {
+	// MaxInlineBytes 单个内联附件解码后的最大字节数，超出时返回 400
+	MaxInlineBytes int64 `mapstructure:"max_inline_bytes"`
+	// URLFetchEnabled 是否下载 URL 附件后内联（默认关闭；仅 https，且遵循 security.url_allowlist.allow_private_hosts）
+	URLFetchEnabled bool `mapstructure:"url_fetch_enabled"`
+	// URLFetchMaxBytes 下载内联的最大字节数
+	URLFetchMaxBytes int64 `mapstructure:"url_fetch_max_bytes"`
+	// URLFetchTimeoutSeconds 单个附件下载超时（秒）
+	URLFetchTimeoutSeconds int `mapstructure:"url_fetch_timeout_seconds"`
+}
+
+// GatewayGeminiRateLimitResetConfig Gemini 限流重置时间解析策略。
+// 默认向上取整（12.345s → 13s）；round 按四舍五入（12.345s → 12s），减少亚秒抖动带来的多余等待。
+type GatewayGeminiRateLimitResetConfig struct {
+	// Rounding 取整方式：ceil（默认）或 round
+	Rounding string `mapstructure:"rounding"`
+	// MinSeconds 重置等待的下限（秒），0 表示不设下限
+	MinSeconds int `mapstructure:"min_seconds"`
+}
+
+// GatewaySessionAffinityConfig 客户端显式会话亲和配置。
+// X-Session-Affinity 头的值（hash 后）直接作为粘性会话键；
+// X-Session-Affinity-TTL 头（秒）控制本次绑定的有效期，并被限制在 [MinTTLSeconds, MaxTTLSeconds]，
+// 取 0 表示本次请求不使用粘性会话。
+type GatewaySessionAffinityConfig struct {
+	// HeaderEnabled 是否接受客户端的会话亲和请求头（默认 true）
+	HeaderEnabled bool `mapstructure:"header_enabled"`
+	// MinTTLSeconds X-Session-Affinity-TTL 允许的最小值（秒）
+	MinTTLSeconds int `mapstructure:"min_ttl_seconds"`
+	// MaxTTLSeconds X-Session-Affinity-TTL 允许的最大值（秒）
+	MaxTTLSeconds int `mapstructure:"max_ttl_seconds"`
+}
+
 func (s *ServerConfig) Address() string {
 	return fmt.Sprintf("%s:%d", s.Host, s.Port)
 }
@@ -1273,6 +1695,11 @@ type DefaultConfig struct {
 type RateLimitConfig struct {
 	OverloadCooldownMinutes int `mapstructure:"overload_cooldown_minutes"`  // 529过载冷却时间(分钟)
 	OAuth401CooldownMinutes int `mapstructure:"oauth_401_cooldown_minutes"` // OAuth 401临时不可调度冷却(分钟)
+	// AuthAllowlistCIDRs 认证接口限流白名单（单个 IP / CIDR / "private"），
+	// 命中的客户端（按可信代理链解析的 IP）跳过 /api/v1/auth/* 的限流。
+	AuthAllowlistCIDRs []string `mapstructure:"auth_allowlist_cidrs"`
+	// AuthAllowlist 校验时由 AuthAllowlistCIDRs 解析得到（未配置时为 nil），路由注册直接复用。
+	AuthAllowlist *ip.IPAllowlist `mapstructure:"-"`
 }
 
 // APIKeyAuthCacheConfig API Key 认证缓存配置
@@ -1465,6 +1892,28 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.CORS.AllowedOrigins = normalizeStringSlice(cfg.CORS.AllowedOrigins)
 	cfg.Security.ResponseHeaders.AdditionalAllowed = normalizeStringSlice(cfg.Security.ResponseHeaders.AdditionalAllowed)
 	cfg.Security.ResponseHeaders.ForceRemove = normalizeStringSlice(cfg.Security.ResponseHeaders.ForceRemove)
+	if len(cfg.Security.ResponseHeaders.PlatformAllowed) > 0 {
+		platformAllowed := make(map[string][]string, len(cfg.Security.ResponseHeaders.PlatformAllowed))
+		for platform, headers := range cfg.Security.ResponseHeaders.PlatformAllowed {
+			platformAllowed[strings.ToLower(strings.TrimSpace(platform))] = normalizeStringSlice(headers)
+		}
+		cfg.Security.ResponseHeaders.PlatformAllowed = platformAllowed
+	}
+	if len(cfg.Security.ResponseRedaction.Platforms) > 0 {
+		redactionPlatforms := make(map[string][]ResponseRedactionRule, len(cfg.Security.ResponseRedaction.Platforms))
+		for platform, rules := range cfg.Security.ResponseRedaction.Platforms {
+			normalized := make([]ResponseRedactionRule, 0, len(rules))
+			for _, rule := range rules {
+				rule.Path = strings.TrimSpace(rule.Path)
+				rule.Action = strings.ToLower(strings.TrimSpace(rule.Action))
+				normalized = append(normalized, rule)
+			}
+			redactionPlatforms[strings.ToLower(strings.TrimSpace(platform))] = normalized
+		}
+		cfg.Security.ResponseRedaction.Platforms = redactionPlatforms
+	}
+	cfg.Gateway.ForwardHeaders.AdditionalAllowed = normalizeStringSlice(cfg.Gateway.ForwardHeaders.AdditionalAllowed)
+	cfg.Gateway.ForwardHeaders.ForceRemove = normalizeStringSlice(cfg.Gateway.ForwardHeaders.ForceRemove)
 	cfg.Security.CSP.Policy = strings.TrimSpace(cfg.Security.CSP.Policy)
 	cfg.SetTrustForwardedIPForAPIKeyACL(cfg.Security.TrustForwardedIPForAPIKeyACL)
 	cfg.Log.Level = strings.ToLower(strings.TrimSpace(cfg.Log.Level))
@@ -1534,10 +1983,11 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	if cfg.JWT.Secret != "" && isWeakJWTSecret(cfg.JWT.Secret) {
 		slog.Warn("JWT secret appears weak; use a 32+ character random secret in production.")
 	}
-	if len(cfg.Security.ResponseHeaders.AdditionalAllowed) > 0 || len(cfg.Security.ResponseHeaders.ForceRemove) > 0 {
+	if len(cfg.Security.ResponseHeaders.AdditionalAllowed) > 0 || len(cfg.Security.ResponseHeaders.ForceRemove) > 0 || len(cfg.Security.ResponseHeaders.PlatformAllowed) > 0 {
 		slog.Info("response header policy configured",
 			"additional_allowed", cfg.Security.ResponseHeaders.AdditionalAllowed,
 			"force_remove", cfg.Security.ResponseHeaders.ForceRemove,
+			"platform_allowed", cfg.Security.ResponseHeaders.PlatformAllowed,
 		)
 	}
 
@@ -1554,6 +2004,7 @@ func setDefaults() {
 	viper.SetDefault("server.frontend_url", "")
 	viper.SetDefault("server.read_header_timeout", 30) // 30秒读取请求头
 	viper.SetDefault("server.idle_timeout", 120)       // 120秒空闲超时
+	viper.SetDefault("server.shutdown_grace_seconds", 30)
 	viper.SetDefault("server.trusted_proxies", []string{})
 	viper.SetDefault("server.max_request_body_size", int64(256*1024*1024))
 	// H2C 默认配置
@@ -1608,6 +2059,10 @@ func setDefaults() {
 	viper.SetDefault("security.response_headers.enabled", true)
 	viper.SetDefault("security.response_headers.additional_allowed", []string{})
 	viper.SetDefault("security.response_headers.force_remove", []string{})
+	viper.SetDefault("security.response_headers.platform_allowed", map[string][]string{})
+	viper.SetDefault("security.response_redaction.enabled", false)
+	viper.SetDefault("security.response_redaction.max_frame_bytes", 1<<20)
+	viper.SetDefault("security.response_redaction.platforms", map[string]any{})
 	viper.SetDefault("security.csp.enabled", true)
 	viper.SetDefault("security.csp.policy", DefaultCSPPolicy)
 	viper.SetDefault("security.proxy_probe.insecure_skip_verify", false)
@@ -1624,6 +2079,16 @@ func setDefaults() {
 	viper.SetDefault("billing.minimum_balance_reserve", 0.000001)
 	viper.SetDefault("billing.user_platform_quota_cache_ttl_seconds", 86400)
 	viper.SetDefault("billing.user_platform_quota_sentinel_ttl_seconds", 3600)
+	viper.SetDefault("billing.degraded_mode.enabled", false)
+	viper.SetDefault("billing.degraded_mode.max_requests", 100)
+	viper.SetDefault("billing.degraded_mode.max_minutes", 10)
+	viper.SetDefault("billing.usage_retry.enabled", true)
+	viper.SetDefault("billing.usage_retry.poll_interval_seconds", 10)
+	viper.SetDefault("billing.usage_retry.batch_size", 100)
+	viper.SetDefault("billing.usage_retry.base_backoff_seconds", 5)
+	viper.SetDefault("billing.usage_retry.max_backoff_seconds", 600)
+	viper.SetDefault("billing.usage_retry.max_age_seconds", 86400)
+	viper.SetDefault("billing.usage_retry.dead_letter_max", 1000)
 
 	// Turnstile
 	viper.SetDefault("turnstile.required", false)
@@ -1763,6 +2228,7 @@ func setDefaults() {
 	// RateLimit
 	viper.SetDefault("rate_limit.overload_cooldown_minutes", 10)
 	viper.SetDefault("rate_limit.oauth_401_cooldown_minutes", 10)
+	viper.SetDefault("rate_limit.auth_allowlist_cidrs", []string{})
 
 	// Pricing - 从 model-price-repo 同步模型定价和上下文窗口数据（固定到 commit，避免分支漂移）
 	viper.SetDefault("pricing.remote_url", "https://raw.githubusercontent.com/Wei-Shaw/model-price-repo/main/model_prices_and_context_window.json")
@@ -1824,15 +2290,22 @@ func setDefaults() {
 	viper.SetDefault("idempotency.cleanup_interval_seconds", 60)
 	viper.SetDefault("idempotency.cleanup_batch_size", 500)
 
+	// Audit
+	viper.SetDefault("audit.retention_days", 180)
+	viper.SetDefault("audit.cleanup_interval_seconds", 3600)
+	viper.SetDefault("audit.cleanup_batch_size", 1000)
+
 	// Gateway
 	viper.SetDefault("gateway.response_header_timeout", 600) // 600秒(10分钟)等待上游响应头，LLM高负载时可能排队较久
 	viper.SetDefault("gateway.openai_response_header_timeout", 0)
 	viper.SetDefault("gateway.log_upstream_error_body", true)
+	viper.SetDefault("gateway.upstream_request_id_passthrough", false)
 	viper.SetDefault("gateway.log_upstream_error_body_max_bytes", 2048)
 	viper.SetDefault("gateway.inject_beta_for_apikey", false)
 	viper.SetDefault("gateway.failover_on_400", false)
 	viper.SetDefault("gateway.max_account_switches", 10)
 	viper.SetDefault("gateway.max_account_switches_gemini", 3)
+	viper.SetDefault("gateway.transient_retry_attempts", 1)
 	viper.SetDefault("gateway.force_codex_cli", false)
 	viper.SetDefault("gateway.codex_image_generation_bridge_enabled", false)
 	viper.SetDefault("gateway.openai_passthrough_allow_timeout_headers", false)
@@ -1914,10 +2387,17 @@ func setDefaults() {
 	viper.SetDefault("gateway.client_idle_ttl_seconds", 900)
 	viper.SetDefault("gateway.concurrency_slot_ttl_minutes", 30) // 并发槽位过期时间（支持超长请求）
 	viper.SetDefault("gateway.stream_data_interval_timeout", 180)
+	viper.SetDefault("gateway.stream_idle_timeout_seconds", 0)
+	viper.SetDefault("gateway.count_tokens_max_concurrency", 4)
+	viper.SetDefault("gateway.forward_headers.additional_allowed", []string{})
+	viper.SetDefault("gateway.forward_headers.force_remove", []string{})
 	viper.SetDefault("gateway.stream_keepalive_interval", 10)
+	viper.SetDefault("gateway.first_token_timeout_seconds", 0)
 	viper.SetDefault("gateway.image_stream_data_interval_timeout", 900)
 	viper.SetDefault("gateway.image_stream_keepalive_interval", 10)
 	viper.SetDefault("gateway.max_line_size", 500*1024*1024)
+	viper.SetDefault("gateway.estimated_usage_rate_multiplier", 1.0)
+	viper.SetDefault("gateway.force_non_streaming_models", []string{})
 	viper.SetDefault("gateway.scheduling.sticky_session_max_waiting", 3)
 	viper.SetDefault("gateway.scheduling.sticky_session_wait_timeout", 120*time.Second)
 	viper.SetDefault("gateway.scheduling.fallback_wait_timeout", 30*time.Second)
@@ -1938,6 +2418,53 @@ func setDefaults() {
 	viper.SetDefault("gateway.scheduling.outbox_lag_rebuild_failures", 3)
 	viper.SetDefault("gateway.scheduling.outbox_backlog_rebuild_rows", 10000)
 	viper.SetDefault("gateway.scheduling.full_rebuild_interval_seconds", 300)
+	viper.SetDefault("gateway.routing.weighted_scoring_enabled", false)
+	viper.SetDefault("gateway.routing.load", 1.0)
+	viper.SetDefault("gateway.routing.queue", 0.7)
+	viper.SetDefault("gateway.routing.error_rate", 0.8)
+	viper.SetDefault("gateway.routing.weight", 1.0)
+	viper.SetDefault("gateway.routing.latency", 0.5)
+	viper.SetDefault("gateway.routing.latency_half_life_seconds", 300)
+	viper.SetDefault("gateway.routing.health", 2.0)
+	viper.SetDefault("gateway.upstream_ratelimit.enabled", false)
+	viper.SetDefault("gateway.upstream_ratelimit.min_remaining_tokens", 10000)
+	viper.SetDefault("gateway.upstream_ratelimit.min_remaining_requests", 1)
+	viper.SetDefault("gateway.upstream_ratelimit.reset_imminent_seconds", 0)
+	viper.SetDefault("gateway.upstream_ratelimit.refresh_interval_seconds", 10)
+	viper.SetDefault("gateway.server_error_penalty.enabled", true)
+	viper.SetDefault("gateway.server_error_penalty.window_seconds", 60)
+	viper.SetDefault("gateway.server_error_penalty.threshold", 5)
+	viper.SetDefault("gateway.server_error_penalty.penalty_seconds", 120)
+	viper.SetDefault("gateway.request_cost.enabled", true)
+	viper.SetDefault("gateway.request_cost.default_output_tokens", 4096)
+	viper.SetDefault("gateway.request_cost.overage_factor", 2.0)
+	viper.SetDefault("gateway.gemini_attachments.max_inline_bytes", 20*1024*1024)
+	viper.SetDefault("gateway.gemini_attachments.url_fetch_enabled", false)
+	viper.SetDefault("gateway.gemini_attachments.url_fetch_max_bytes", 5*1024*1024)
+	viper.SetDefault("gateway.gemini_attachments.url_fetch_timeout_seconds", 10)
+	viper.SetDefault("gateway.gemini_rate_limit_reset.rounding", "ceil")
+	viper.SetDefault("gateway.gemini_rate_limit_reset.min_seconds", 0)
+	viper.SetDefault("gateway.session_affinity.header_enabled", true)
+	viper.SetDefault("gateway.session_affinity.min_ttl_seconds", 60)
+	viper.SetDefault("gateway.session_affinity.max_ttl_seconds", 86400)
+	viper.SetDefault("gateway.capture.max_body_bytes", 64*1024)
+	viper.SetDefault("gateway.capture.max_hours", 72)
+	viper.SetDefault("gateway.capture.queue_size", 256)
+	viper.SetDefault("gateway.idempotency.enabled", true)
+	viper.SetDefault("gateway.idempotency.window_seconds", 3600)
+	viper.SetDefault("gateway.idempotency.max_body_bytes", 1024*1024)
+	viper.SetDefault("gateway.idempotency.in_flight_timeout_seconds", 600)
+	viper.SetDefault("gateway.idempotency.wait_timeout_seconds", 10)
+	viper.SetDefault("gateway.response_cache.enabled", true)
+	viper.SetDefault("gateway.response_cache.ttl_seconds", 3600)
+	viper.SetDefault("gateway.response_cache.max_body_bytes", 1024*1024)
+	viper.SetDefault("gateway.payload_validation.enabled", true)
+	viper.SetDefault("gateway.payload_validation.max_part_bytes", int64(64*1024*1024))
+	viper.SetDefault("gateway.payload_validation.max_total_bytes", int64(192*1024*1024))
+	viper.SetDefault("gateway.payload_validation.verify_media_type", false)
+	viper.SetDefault("gateway.ttft_stats.enabled", true)
+	viper.SetDefault("gateway.ttft_stats.window_minutes", 60)
+	viper.SetDefault("gateway.ttft_stats.track_accounts", false)
 	viper.SetDefault("gateway.usage_record.worker_count", 128)
 	viper.SetDefault("gateway.usage_record.queue_size", 16384)
 	viper.SetDefault("gateway.usage_record.task_timeout_seconds", 5)
@@ -1965,6 +2492,17 @@ func setDefaults() {
 
 	viper.SetDefault("gateway.tls_fingerprint.enabled", true)
 	viper.SetDefault("concurrency.ping_interval", 10)
+	viper.SetDefault("concurrency.wait_queue_multiplier", 0.0)
+	viper.SetDefault("concurrency.wait_queue_min", 20)
+	viper.SetDefault("concurrency.wait_queue_max", 0)
+	viper.SetDefault("concurrency.instance_heartbeat_grace_seconds", 60)
+	viper.SetDefault("concurrency.queued_notice.enabled", false)
+	viper.SetDefault("concurrency.queued_notice.interval_seconds", 5)
+	viper.SetDefault("concurrency.queued_notice.format", QueuedNoticeFormatComment)
+	viper.SetDefault("concurrency.claude.ping_interval", 0)
+	viper.SetDefault("concurrency.claude.ping_format", SSEPingFormatDefault)
+	viper.SetDefault("concurrency.openai.ping_interval", 0)
+	viper.SetDefault("concurrency.openai.ping_format", SSEPingFormatDefault)
 
 	// TokenRefresh
 	viper.SetDefault("token_refresh.enabled", true)
@@ -1973,6 +2511,14 @@ func setDefaults() {
 	viper.SetDefault("token_refresh.max_retries", 3)                   // 最多重试3次
 	viper.SetDefault("token_refresh.retry_backoff_seconds", 2)         // 重试退避基础2秒
 
+	// Account health probe
+	viper.SetDefault("account_health_probe.enabled", false)
+	viper.SetDefault("account_health_probe.interval_seconds", 300)
+	viper.SetDefault("account_health_probe.concurrency", 4)
+	viper.SetDefault("account_health_probe.timeout_seconds", 15)
+	viper.SetDefault("account_health_probe.failure_threshold", 2)
+	viper.SetDefault("account_health_probe.gemini_model", "gemini-2.5-flash")
+
 	// Gemini OAuth - configure via environment variables or config file
 	// GEMINI_OAUTH_CLIENT_ID and GEMINI_OAUTH_CLIENT_SECRET
 	// Default: uses Gemini CLI public credentials (set via environment)
@@ -2018,6 +2564,47 @@ func (c *Config) Validate() error {
 	default:
 		return fmt.Errorf("log.stacktrace_level must be one of: none/error/fatal")
 	}
+	for platform, headers := range c.Security.ResponseHeaders.PlatformAllowed {
+		switch strings.ToLower(platform) {
+		case "anthropic", "openai", "gemini":
+		default:
+			return fmt.Errorf("security.response_headers.platform_allowed: unknown platform %q (allowed: anthropic/openai/gemini)", platform)
+		}
+		for _, header := range headers {
+			if strings.Count(header, "*") > 1 || (strings.Contains(header, "*") && !strings.HasSuffix(header, "*")) || header == "*" {
+				return fmt.Errorf("security.response_headers.platform_allowed.%s: %q must be a header name or a prefix ending with *", platform, header)
+			}
+		}
+	}
+	if c.Security.ResponseRedaction.MaxFrameBytes < 0 {
+		return fmt.Errorf("security.response_redaction.max_frame_bytes must be non-negative")
+	}
+	for platform, rules := range c.Security.ResponseRedaction.Platforms {
+		switch platform {
+		case "anthropic", "openai", "gemini":
+		default:
+			return fmt.Errorf("security.response_redaction.platforms: unknown platform %q (allowed: anthropic/openai/gemini)", platform)
+		}
+		for i, rule := range rules {
+			if rule.Path == "" || strings.ContainsAny(rule.Path, "*?#|@") {
+				return fmt.Errorf("security.response_redaction.platforms.%s[%d].path must be a plain dot path, got %q", platform, i, rule.Path)
+			}
+			switch rule.Action {
+			case ResponseRedactionActionDelete, ResponseRedactionActionBlank:
+			case ResponseRedactionActionReplacePrefix:
+				if rule.From == "" {
+					return fmt.Errorf("security.response_redaction.platforms.%s[%d].from is required for replace_prefix", platform, i)
+				}
+			default:
+				return fmt.Errorf("security.response_redaction.platforms.%s[%d].action must be one of: delete/blank/replace_prefix", platform, i)
+			}
+		}
+	}
+	authAllowlist, err := ip.ParseIPAllowlist(c.RateLimit.AuthAllowlistCIDRs)
+	if err != nil {
+		return fmt.Errorf("rate_limit.auth_allowlist_cidrs: %w", err)
+	}
+	c.RateLimit.AuthAllowlist = authAllowlist
 	if !c.Log.Output.ToStdout && !c.Log.Output.ToFile {
 		return fmt.Errorf("log.output.to_stdout and log.output.to_file cannot both be false")
 	}
@@ -2061,6 +2648,9 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("gemini.oauth.client_id and gemini.oauth.client_secret must be both set or both empty")
 	}
 
+	if c.Server.ShutdownGraceSeconds < 0 {
+		return fmt.Errorf("server.shutdown_grace_seconds must be non-negative")
+	}
 	if strings.TrimSpace(c.Server.FrontendURL) != "" {
 		if err := ValidateAbsoluteHTTPURL(c.Server.FrontendURL); err != nil {
 			return fmt.Errorf("server.frontend_url invalid: %w", err)
@@ -2288,6 +2878,26 @@ func (c *Config) Validate() error {
 	if c.Billing.MinimumBalanceReserve < 0 {
 		return fmt.Errorf("billing.minimum_balance_reserve must be non-negative")
 	}
+	if err := validateModelPrices(c.Pricing.ModelPrices); err != nil {
+		return err
+	}
+	if c.Billing.DegradedMode.MaxRequests < 0 || c.Billing.DegradedMode.MaxMinutes < 0 {
+		return fmt.Errorf("billing.degraded_mode.max_requests and max_minutes must be non-negative")
+	}
+	if c.Billing.DegradedMode.Enabled && c.Billing.DegradedMode.MaxRequests == 0 && c.Billing.DegradedMode.MaxMinutes == 0 {
+		return fmt.Errorf("billing.degraded_mode requires max_requests or max_minutes when enabled")
+	}
+	if retry := c.Billing.UsageRetry; retry.Enabled {
+		if retry.PollIntervalSeconds <= 0 || retry.BatchSize <= 0 || retry.BaseBackoffSeconds <= 0 || retry.MaxAgeSeconds <= 0 {
+			return fmt.Errorf("billing.usage_retry poll_interval_seconds, batch_size, base_backoff_seconds and max_age_seconds must be positive when enabled")
+		}
+		if retry.MaxBackoffSeconds < retry.BaseBackoffSeconds {
+			return fmt.Errorf("billing.usage_retry.max_backoff_seconds must be >= base_backoff_seconds")
+		}
+		if retry.DeadLetterMax <= 0 {
+			return fmt.Errorf("billing.usage_retry.dead_letter_max must be positive when enabled")
+		}
+	}
 	if c.Database.MaxOpenConns <= 0 {
 		return fmt.Errorf("database.max_open_conns must be positive")
 	}
@@ -2455,6 +3065,15 @@ func (c *Config) Validate() error {
 	if c.Idempotency.CleanupBatchSize <= 0 {
 		return fmt.Errorf("idempotency.cleanup_batch_size must be positive")
 	}
+	if c.Audit.RetentionDays < 0 {
+		return fmt.Errorf("audit.retention_days must be non-negative")
+	}
+	if c.Audit.CleanupIntervalSeconds < 0 {
+		return fmt.Errorf("audit.cleanup_interval_seconds must be non-negative")
+	}
+	if c.Audit.CleanupBatchSize < 0 {
+		return fmt.Errorf("audit.cleanup_batch_size must be non-negative")
+	}
 	if c.Gateway.MaxBodySize <= 0 {
 		return fmt.Errorf("gateway.max_body_size must be positive")
 	}
@@ -2524,6 +3143,17 @@ func (c *Config) Validate() error {
 		(c.Gateway.StreamDataIntervalTimeout < 30 || c.Gateway.StreamDataIntervalTimeout > 300) {
 		return fmt.Errorf("gateway.stream_data_interval_timeout must be 0 or between 30-300 seconds")
 	}
+	if c.Gateway.StreamIdleTimeoutSeconds < 0 {
+		return fmt.Errorf("gateway.stream_idle_timeout_seconds must be non-negative")
+	}
+	if c.Gateway.CountTokensMaxConcurrency < 0 {
+		return fmt.Errorf("gateway.count_tokens_max_concurrency must be non-negative")
+	}
+	for _, header := range c.Gateway.ForwardHeaders.AdditionalAllowed {
+		if isProtectedForwardHeader(header) {
+			return fmt.Errorf("gateway.forward_headers.additional_allowed cannot include %q (credential or hop-by-hop header)", header)
+		}
+	}
 	if c.Gateway.StreamKeepaliveInterval < 0 {
 		return fmt.Errorf("gateway.stream_keepalive_interval must be non-negative")
 	}
@@ -2534,6 +3164,12 @@ func (c *Config) Validate() error {
 	if c.Gateway.ImageStreamDataIntervalTimeout < 0 {
 		return fmt.Errorf("gateway.image_stream_data_interval_timeout must be non-negative")
 	}
+	if c.Gateway.FirstTokenTimeoutSeconds < 0 {
+		return fmt.Errorf("gateway.first_token_timeout_seconds must be non-negative")
+	}
+	if err := validateGatewayTimeoutTiers(c.Gateway.TimeoutTiers); err != nil {
+		return err
+	}
 	if c.Gateway.ImageStreamDataIntervalTimeout != 0 &&
 		(c.Gateway.ImageStreamDataIntervalTimeout < 60 || c.Gateway.ImageStreamDataIntervalTimeout > 1800) {
 		return fmt.Errorf("gateway.image_stream_data_interval_timeout must be 0 or between 60-1800 seconds")
@@ -2545,6 +3181,108 @@ func (c *Config) Validate() error {
 		(c.Gateway.ImageStreamKeepaliveInterval < 5 || c.Gateway.ImageStreamKeepaliveInterval > 60) {
 		return fmt.Errorf("gateway.image_stream_keepalive_interval must be 0 or between 5-60 seconds")
 	}
+	if c.Gateway.TransientRetryAttempts < 0 || c.Gateway.TransientRetryAttempts > 3 {
+		return fmt.Errorf("gateway.transient_retry_attempts must be between 0-3")
+	}
+	if c.Gateway.EstimatedUsageRateMultiplier <= 0 {
+		return fmt.Errorf("gateway.estimated_usage_rate_multiplier must be positive")
+	}
+	if c.Gateway.Routing.Load < 0 || c.Gateway.Routing.Queue < 0 ||
+		c.Gateway.Routing.ErrorRate < 0 || c.Gateway.Routing.Weight < 0 || c.Gateway.Routing.Latency < 0 {
+		return fmt.Errorf("gateway.routing.* coefficients must be non-negative")
+	}
+	if c.Gateway.Routing.WeightedScoringEnabled &&
+		c.Gateway.Routing.Load+c.Gateway.Routing.Queue+c.Gateway.Routing.ErrorRate+c.Gateway.Routing.Weight+c.Gateway.Routing.Latency <= 0 {
+		return fmt.Errorf("gateway.routing coefficients must not all be zero when weighted_scoring_enabled is true")
+	}
+	if c.Gateway.Routing.LatencyHalfLifeSeconds <= 0 {
+		return fmt.Errorf("gateway.routing.latency_half_life_seconds must be positive")
+	}
+	if c.Gateway.Routing.Health < 0 {
+		return fmt.Errorf("gateway.routing.health must be non-negative")
+	}
+	if c.Gateway.UpstreamRateLimit.MinRemainingTokens < 0 || c.Gateway.UpstreamRateLimit.MinRemainingRequests < 0 ||
+		c.Gateway.UpstreamRateLimit.ResetImminentSeconds < 0 {
+		return fmt.Errorf("gateway.upstream_ratelimit thresholds must be non-negative")
+	}
+	if c.Gateway.UpstreamRateLimit.Enabled && c.Gateway.UpstreamRateLimit.RefreshIntervalSeconds <= 0 {
+		return fmt.Errorf("gateway.upstream_ratelimit.refresh_interval_seconds must be positive when enabled")
+	}
+	if p := c.Gateway.ServerErrorPenalty; p.Enabled && (p.WindowSeconds <= 0 || p.Threshold <= 0 || p.PenaltySeconds <= 0) {
+		return fmt.Errorf("gateway.server_error_penalty window_seconds/threshold/penalty_seconds must be positive when enabled")
+	}
+	if rc := c.Gateway.RequestCost; rc.Enabled {
+		if rc.DefaultOutputTokens <= 0 {
+			return fmt.Errorf("gateway.request_cost.default_output_tokens must be positive when enabled")
+		}
+		if rc.OverageFactor < 1 {
+			return fmt.Errorf("gateway.request_cost.overage_factor must be >= 1 when enabled")
+		}
+	}
+	if ga := c.Gateway.GeminiAttachments; ga.MaxInlineBytes <= 0 {
+		return fmt.Errorf("gateway.gemini_attachments.max_inline_bytes must be positive")
+	} else if ga.URLFetchEnabled && (ga.URLFetchMaxBytes <= 0 || ga.URLFetchTimeoutSeconds <= 0) {
+		return fmt.Errorf("gateway.gemini_attachments url_fetch_max_bytes/url_fetch_timeout_seconds must be positive when url_fetch_enabled")
+	}
+	switch c.Gateway.GeminiRateLimitReset.Rounding {
+	case "", "ceil", "round":
+	default:
+		return fmt.Errorf("gateway.gemini_rate_limit_reset.rounding must be one of: ceil, round")
+	}
+	if c.Gateway.GeminiRateLimitReset.MinSeconds < 0 {
+		return fmt.Errorf("gateway.gemini_rate_limit_reset.min_seconds must be non-negative")
+	}
+	if err := validateAccountHealthProbe(c.AccountHealthProbe); err != nil {
+		return err
+	}
+	if c.Gateway.SessionAffinity.MinTTLSeconds <= 0 {
+		return fmt.Errorf("gateway.session_affinity.min_ttl_seconds must be positive")
+	}
+	if c.Gateway.SessionAffinity.MaxTTLSeconds < c.Gateway.SessionAffinity.MinTTLSeconds {
+		return fmt.Errorf("gateway.session_affinity.max_ttl_seconds must be >= min_ttl_seconds")
+	}
+	if c.Gateway.Capture.MaxBodyBytes <= 0 {
+		return fmt.Errorf("gateway.capture.max_body_bytes must be positive")
+	}
+	if c.Gateway.Capture.MaxHours <= 0 {
+		return fmt.Errorf("gateway.capture.max_hours must be positive")
+	}
+	if c.Gateway.Capture.QueueSize <= 0 {
+		return fmt.Errorf("gateway.capture.queue_size must be positive")
+	}
+	if c.Gateway.Idempotency.Enabled {
+		if c.Gateway.Idempotency.WindowSeconds <= 0 {
+			return fmt.Errorf("gateway.idempotency.window_seconds must be positive")
+		}
+		if c.Gateway.Idempotency.MaxBodyBytes <= 0 {
+			return fmt.Errorf("gateway.idempotency.max_body_bytes must be positive")
+		}
+		if c.Gateway.Idempotency.InFlightTimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.idempotency.in_flight_timeout_seconds must be positive")
+		}
+		if c.Gateway.Idempotency.WaitTimeoutSeconds < 0 {
+			return fmt.Errorf("gateway.idempotency.wait_timeout_seconds must be non-negative")
+		}
+	}
+	if c.Gateway.ResponseCache.Enabled {
+		if c.Gateway.ResponseCache.TTLSeconds <= 0 {
+			return fmt.Errorf("gateway.response_cache.ttl_seconds must be positive")
+		}
+		if c.Gateway.ResponseCache.MaxBodyBytes <= 0 {
+			return fmt.Errorf("gateway.response_cache.max_body_bytes must be positive")
+		}
+	}
+	if c.Gateway.PayloadValidation.Enabled {
+		if c.Gateway.PayloadValidation.MaxPartBytes <= 0 {
+			return fmt.Errorf("gateway.payload_validation.max_part_bytes must be positive")
+		}
+		if c.Gateway.PayloadValidation.MaxTotalBytes < c.Gateway.PayloadValidation.MaxPartBytes {
+			return fmt.Errorf("gateway.payload_validation.max_total_bytes must be >= max_part_bytes")
+		}
+	}
+	if c.Gateway.TTFTStats.Enabled && c.Gateway.TTFTStats.WindowMinutes <= 0 {
+		return fmt.Errorf("gateway.ttft_stats.window_minutes must be positive")
+	}
 	// 兼容旧键 sticky_previous_response_ttl_seconds
 	if c.Gateway.OpenAIWS.StickyResponseIDTTLSeconds <= 0 && c.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds > 0 {
 		c.Gateway.OpenAIWS.StickyResponseIDTTLSeconds = c.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds
@@ -2826,12 +3564,64 @@ func (c *Config) Validate() error {
 	if c.Concurrency.PingInterval < 5 || c.Concurrency.PingInterval > 30 {
 		return fmt.Errorf("concurrency.ping_interval must be between 5-30 seconds")
 	}
+	if c.Concurrency.WaitQueueMultiplier < 0 {
+		return fmt.Errorf("concurrency.wait_queue_multiplier must be non-negative")
+	}
+	if c.Concurrency.WaitQueueMin < 0 {
+		return fmt.Errorf("concurrency.wait_queue_min must be non-negative")
+	}
+	if c.Concurrency.WaitQueueMax < 0 {
+		return fmt.Errorf("concurrency.wait_queue_max must be non-negative")
+	}
+	if c.Concurrency.InstanceHeartbeatGraceSeconds != 0 && c.Concurrency.InstanceHeartbeatGraceSeconds < 3 {
+		return fmt.Errorf("concurrency.instance_heartbeat_grace_seconds must be 0 (default) or >= 3")
+	}
+	if c.Concurrency.WaitQueueMax > 0 && c.Concurrency.WaitQueueMax < c.Concurrency.WaitQueueMin {
+		return fmt.Errorf("concurrency.wait_queue_max must be 0 (unlimited) or >= concurrency.wait_queue_min")
+	}
+	for _, item := range []struct {
+		name  string
+		route SSEPingRouteConfig
+	}{
+		{name: "claude", route: c.Concurrency.Claude},
+		{name: "openai", route: c.Concurrency.OpenAI},
+	} {
+		name, route := item.name, item.route
+		if route.PingInterval != 0 && (route.PingInterval < 5 || route.PingInterval > 30) {
+			return fmt.Errorf("concurrency.%s.ping_interval must be 0 (inherit) or between 5-30 seconds", name)
+		}
+		switch route.PingFormat {
+		case SSEPingFormatDefault, SSEPingFormatComment, SSEPingFormatEvent, SSEPingFormatData:
+		default:
+			return fmt.Errorf("concurrency.%s.ping_format must be one of: %s/%s/%s", name,
+				SSEPingFormatComment, SSEPingFormatEvent, SSEPingFormatData)
+		}
+	}
+	if notice := c.Concurrency.QueuedNotice; notice.Enabled {
+		if notice.IntervalSeconds < 1 || notice.IntervalSeconds > 60 {
+			return fmt.Errorf("concurrency.queued_notice.interval_seconds must be between 1-60 seconds")
+		}
+		if notice.Format != QueuedNoticeFormatComment && notice.Format != QueuedNoticeFormatEvent {
+			return fmt.Errorf("concurrency.queued_notice.format must be one of: %s/%s", QueuedNoticeFormatComment, QueuedNoticeFormatEvent)
+		}
+	}
 	if err := ValidateDingTalkConfig(c.DingTalk); err != nil {
 		return fmt.Errorf("dingtalk_connect: %w", err)
 	}
 	return nil
 }
 
+// isProtectedForwardHeader 判断请求头是否为不可透传的鉴权/hop-by-hop 头。
+func isProtectedForwardHeader(header string) bool {
+	switch strings.ToLower(strings.TrimSpace(header)) {
+	case "authorization", "x-api-key", "x-goog-api-key", "cookie", "proxy-authorization",
+		"host", "content-length", "transfer-encoding", "connection", "upgrade", "te", "trailer", "keep-alive":
+		return true
+	default:
+		return false
+	}
+}
+
 func normalizeStringSlice(values []string) []string {
 	if len(values) == 0 {
 		return values
@@ -2985,3 +3775,90 @@ func warnIfInsecureURL(field, raw string) {
 		slog.Warn("url uses http scheme; use https in production to avoid token leakage", "field", field)
 	}
 }
+
+// accountHealthProbeTypes 各平台支持的探测方式
+var accountHealthProbeTypes = map[string][]string{
+	"anthropic":   {"models", "none"},
+	"openai":      {"models", "none"},
+	"gemini":      {"count_tokens", "none"},
+	"antigravity": {"none"},
+	"grok":        {"none"},
+}
+
+func validateAccountHealthProbe(cfg AccountHealthProbeConfig) error {
+	for platform, probe := range cfg.Probes {
+		allowed, ok := accountHealthProbeTypes[strings.ToLower(strings.TrimSpace(platform))]
+		if !ok {
+			return fmt.Errorf("account_health_probe.probes: unknown platform %q", platform)
+		}
+		if !slices.Contains(allowed, strings.ToLower(strings.TrimSpace(probe))) {
+			return fmt.Errorf("account_health_probe.probes.%s must be one of %s", platform, strings.Join(allowed, "|"))
+		}
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.IntervalSeconds <= 0 {
+		return fmt.Errorf("account_health_probe.interval_seconds must be positive")
+	}
+	if cfg.Concurrency <= 0 {
+		return fmt.Errorf("account_health_probe.concurrency must be positive")
+	}
+	if cfg.TimeoutSeconds <= 0 || cfg.TimeoutSeconds >= cfg.IntervalSeconds {
+		return fmt.Errorf("account_health_probe.timeout_seconds must be positive and less than interval_seconds")
+	}
+	if cfg.FailureThreshold <= 0 {
+		return fmt.Errorf("account_health_probe.failure_threshold must be positive")
+	}
+	if strings.TrimSpace(cfg.GeminiModel) == "" {
+		return fmt.Errorf("account_health_probe.gemini_model is required")
+	}
+	return nil
+}
+
+func validateModelPrices(prices []ModelPriceConfig) error {
+	seen := make(map[string]struct{}, len(prices))
+	for i, price := range prices {
+		model := strings.ToLower(strings.TrimSpace(price.Model))
+		if model == "" {
+			return fmt.Errorf("pricing.model_prices[%d].model is required", i)
+		}
+		if _, ok := seen[model]; ok {
+			return fmt.Errorf("pricing.model_prices[%d].model %q is duplicated", i, price.Model)
+		}
+		seen[model] = struct{}{}
+		if price.InputPer1K < 0 || price.OutputPer1K < 0 || price.CacheCreationPer1K < 0 || price.CacheReadPer1K < 0 {
+			return fmt.Errorf("pricing.model_prices[%d] prices must be non-negative", i)
+		}
+	}
+	return nil
+}
+
+func validateGatewayTimeoutTiers(tiers []GatewayTimeoutTier) error {
+	seen := make(map[string]struct{}, len(tiers))
+	for i, tier := range tiers {
+		name := strings.TrimSpace(tier.Name)
+		if name == "" {
+			return fmt.Errorf("gateway.timeout_tiers[%d].name is required", i)
+		}
+		if _, ok := seen[strings.ToLower(name)]; ok {
+			return fmt.Errorf("gateway.timeout_tiers[%d].name %q is duplicated", i, name)
+		}
+		seen[strings.ToLower(name)] = struct{}{}
+		if len(tier.Models) == 0 && !strings.EqualFold(name, "default") {
+			return fmt.Errorf("gateway.timeout_tiers[%d].models must not be empty", i)
+		}
+		for _, pattern := range tier.Models {
+			if strings.TrimSpace(pattern) == "" {
+				return fmt.Errorf("gateway.timeout_tiers[%d].models contains an empty pattern", i)
+			}
+			if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+				return fmt.Errorf("gateway.timeout_tiers[%d].models pattern %q is invalid: %w", i, pattern, err)
+			}
+		}
+		if tier.ConnectTimeoutSeconds < 0 || tier.FirstTokenTimeoutSeconds < 0 || tier.TotalTimeoutSeconds < 0 {
+			return fmt.Errorf("gateway.timeout_tiers[%d] timeouts must be non-negative", i)
+		}
+	}
+	return nil
+}
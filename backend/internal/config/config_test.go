@@ -946,6 +946,98 @@ func TestValidateConcurrencyPingInterval(t *testing.T) {
 	}
 }
 
+func TestValidateConcurrencyWaitQueue(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Concurrency.WaitQueueMin != 20 || cfg.Concurrency.WaitQueueMax != 0 || cfg.Concurrency.WaitQueueMultiplier != 0 {
+		t.Fatalf("unexpected wait queue defaults: %+v", cfg.Concurrency)
+	}
+
+	cfg.Concurrency.WaitQueueMin = 10
+	cfg.Concurrency.WaitQueueMax = 5
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "concurrency.wait_queue_max") {
+		t.Fatalf("Validate() expected concurrency.wait_queue_max error, got: %v", err)
+	}
+
+	cfg.Concurrency.WaitQueueMax = 0
+	cfg.Concurrency.WaitQueueMultiplier = -1
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "concurrency.wait_queue_multiplier") {
+		t.Fatalf("Validate() expected concurrency.wait_queue_multiplier error, got: %v", err)
+	}
+}
+
+func TestValidateRateLimitAuthAllowlistCIDRs(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.RateLimit.AuthAllowlist != nil {
+		t.Fatalf("AuthAllowlist = %v, want nil when rate_limit.auth_allowlist_cidrs is empty", cfg.RateLimit.AuthAllowlist)
+	}
+	cfg.RateLimit.AuthAllowlistCIDRs = []string{"10.0.0.0/8", "private", "203.0.113.7"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if !cfg.RateLimit.AuthAllowlist.Contains("10.1.2.3") || !cfg.RateLimit.AuthAllowlist.Contains("203.0.113.7") {
+		t.Fatalf("AuthAllowlist should contain configured CIDRs after Validate()")
+	}
+	if cfg.RateLimit.AuthAllowlist.Contains("198.51.100.1") {
+		t.Fatalf("AuthAllowlist should not contain unconfigured IPs")
+	}
+
+	cfg.RateLimit.AuthAllowlistCIDRs = []string{"10.0.0.0/8", "10.0.0.0/33"}
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatalf("Validate() expected error for malformed rate_limit.auth_allowlist_cidrs")
+	}
+	if !strings.Contains(err.Error(), "rate_limit.auth_allowlist_cidrs") || !strings.Contains(err.Error(), "10.0.0.0/33") {
+		t.Fatalf("Validate() expected rate_limit.auth_allowlist_cidrs error, got: %v", err)
+	}
+}
+
+func TestValidateConcurrencyRoutePingConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Concurrency.OpenAI.ResolvedPingInterval(cfg.Concurrency.PingInterval) != cfg.Concurrency.PingInterval {
+		t.Fatalf("openai ping interval should inherit concurrency.ping_interval by default")
+	}
+
+	cfg.Concurrency.OpenAI.PingFormat = SSEPingFormatEvent
+	cfg.Concurrency.OpenAI.PingInterval = 15
+	cfg.Concurrency.Claude.PingFormat = SSEPingFormatComment
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if got := cfg.Concurrency.OpenAI.ResolvedPingInterval(cfg.Concurrency.PingInterval); got != 15 {
+		t.Fatalf("ResolvedPingInterval() = %d, want 15", got)
+	}
+
+	cfg.Concurrency.Claude.PingFormat = "bogus"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "concurrency.claude.ping_format") {
+		t.Fatalf("Validate() expected concurrency.claude.ping_format error, got: %v", err)
+	}
+
+	cfg.Concurrency.Claude.PingFormat = SSEPingFormatDefault
+	cfg.Concurrency.OpenAI.PingInterval = 2
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "concurrency.openai.ping_interval") {
+		t.Fatalf("Validate() expected concurrency.openai.ping_interval error, got: %v", err)
+	}
+}
+
 func TestProvideConfig(t *testing.T) {
 	resetViperWithJWTSecret(t)
 	if _, err := ProvideConfig(); err != nil {
@@ -1165,6 +1257,37 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Billing.MinimumBalanceReserve = -0.01 },
 			wantErr: "billing.minimum_balance_reserve",
 		},
+		{
+			name: "billing degraded mode without budget",
+			mutate: func(c *Config) {
+				c.Billing.DegradedMode = BillingDegradedModeConfig{Enabled: true}
+			},
+			wantErr: "billing.degraded_mode requires max_requests or max_minutes",
+		},
+		{
+			name:    "billing usage retry zero backoff",
+			mutate:  func(c *Config) { c.Billing.UsageRetry.BaseBackoffSeconds = 0 },
+			wantErr: "billing.usage_retry poll_interval_seconds",
+		},
+		{
+			name:    "billing usage retry max backoff below base",
+			mutate:  func(c *Config) { c.Billing.UsageRetry.MaxBackoffSeconds = c.Billing.UsageRetry.BaseBackoffSeconds - 1 },
+			wantErr: "billing.usage_retry.max_backoff_seconds",
+		},
+		{
+			name: "concurrency queued notice interval",
+			mutate: func(c *Config) {
+				c.Concurrency.QueuedNotice = QueuedNoticeConfig{Enabled: true, IntervalSeconds: 0, Format: QueuedNoticeFormatComment}
+			},
+			wantErr: "concurrency.queued_notice.interval_seconds",
+		},
+		{
+			name: "concurrency queued notice format",
+			mutate: func(c *Config) {
+				c.Concurrency.QueuedNotice = QueuedNoticeConfig{Enabled: true, IntervalSeconds: 5, Format: "data"}
+			},
+			wantErr: "concurrency.queued_notice.format",
+		},
 		{
 			name:    "database max open conns",
 			mutate:  func(c *Config) { c.Database.MaxOpenConns = 0 },
@@ -1381,6 +1504,70 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Gateway.ImageStreamKeepaliveInterval = -1 },
 			wantErr: "gateway.image_stream_keepalive_interval must be non-negative",
 		},
+		{
+			name:    "gateway first token timeout negative",
+			mutate:  func(c *Config) { c.Gateway.FirstTokenTimeoutSeconds = -1 },
+			wantErr: "gateway.first_token_timeout_seconds must be non-negative",
+		},
+		{
+			name:    "gateway estimated usage rate multiplier non-positive",
+			mutate:  func(c *Config) { c.Gateway.EstimatedUsageRateMultiplier = -0.5 },
+			wantErr: "gateway.estimated_usage_rate_multiplier must be positive",
+		},
+		{
+			name:    "gateway capture max body bytes non-positive",
+			mutate:  func(c *Config) { c.Gateway.Capture.MaxBodyBytes = 0 },
+			wantErr: "gateway.capture.max_body_bytes must be positive",
+		},
+		{
+			name:    "gateway capture max hours non-positive",
+			mutate:  func(c *Config) { c.Gateway.Capture.MaxHours = 0 },
+			wantErr: "gateway.capture.max_hours must be positive",
+		},
+		{
+			name:    "gateway routing negative coefficient",
+			mutate:  func(c *Config) { c.Gateway.Routing.ErrorRate = -1 },
+			wantErr: "gateway.routing.* coefficients must be non-negative",
+		},
+		{
+			name: "gateway routing all-zero coefficients when enabled",
+			mutate: func(c *Config) {
+				c.Gateway.Routing = GatewayRoutingConfig{WeightedScoringEnabled: true}
+			},
+			wantErr: "gateway.routing coefficients must not all be zero",
+		},
+		{
+			name:    "gateway routing latency half-life non-positive",
+			mutate:  func(c *Config) { c.Gateway.Routing.LatencyHalfLifeSeconds = 0 },
+			wantErr: "gateway.routing.latency_half_life_seconds must be positive",
+		},
+		{
+			name:    "gateway session affinity max below min",
+			mutate:  func(c *Config) { c.Gateway.SessionAffinity.MaxTTLSeconds = 30 },
+			wantErr: "gateway.session_affinity.max_ttl_seconds must be >= min_ttl_seconds",
+		},
+		{
+			name:    "gateway idempotency window non-positive",
+			mutate:  func(c *Config) { c.Gateway.Idempotency.WindowSeconds = 0 },
+			wantErr: "gateway.idempotency.window_seconds must be positive",
+		},
+		{
+			name:    "server shutdown grace negative",
+			mutate:  func(c *Config) { c.Server.ShutdownGraceSeconds = -1 },
+			wantErr: "server.shutdown_grace_seconds must be non-negative",
+		},
+		{
+			name: "gateway payload validation total below part",
+			mutate: func(c *Config) {
+				c.Gateway.PayloadValidation.MaxTotalBytes = c.Gateway.PayloadValidation.MaxPartBytes - 1
+			},
+			wantErr: "gateway.payload_validation.max_total_bytes must be >= max_part_bytes",
+		},
+		{
+			name:    "gateway ttft stats window non-positive",
+			mutate:  func(c *Config) { c.Gateway.TTFTStats.WindowMinutes = 0 },
+			wantErr: "gateway.ttft_stats.window_minutes must be positive",
+		},
 		{
 			name:    "gateway image stream data interval range",
 			mutate:  func(c *Config) { c.Gateway.ImageStreamDataIntervalTimeout = 30 },
@@ -1939,6 +2126,31 @@ func TestLoad_DefaultGatewayImageStreamConfig(t *testing.T) {
 	if cfg.Gateway.ImageStreamKeepaliveInterval != 10 {
 		t.Fatalf("image_stream_keepalive_interval = %d, want 10", cfg.Gateway.ImageStreamKeepaliveInterval)
 	}
+	if cfg.Gateway.FirstTokenTimeoutSeconds != 0 {
+		t.Fatalf("first_token_timeout_seconds = %d, want 0", cfg.Gateway.FirstTokenTimeoutSeconds)
+	}
+	if cfg.Gateway.EstimatedUsageRateMultiplier != 1.0 {
+		t.Fatalf("estimated_usage_rate_multiplier = %v, want 1.0", cfg.Gateway.EstimatedUsageRateMultiplier)
+	}
+	if cfg.Gateway.Capture.MaxBodyBytes != 64*1024 || cfg.Gateway.Capture.MaxHours != 72 || cfg.Gateway.Capture.QueueSize != 256 {
+		t.Fatalf("capture = %+v, want max_body_bytes=65536 max_hours=72 queue_size=256", cfg.Gateway.Capture)
+	}
+	if !cfg.Gateway.Idempotency.Enabled || cfg.Gateway.Idempotency.WindowSeconds != 3600 || cfg.Gateway.Idempotency.WaitTimeoutSeconds != 10 {
+		t.Fatalf("idempotency = %+v, want enabled window=3600 wait=10", cfg.Gateway.Idempotency)
+	}
+	if pv := cfg.Gateway.PayloadValidation; !pv.Enabled || pv.VerifyMediaType || pv.MaxPartBytes != 64*1024*1024 || pv.MaxTotalBytes != 192*1024*1024 {
+		t.Fatalf("payload_validation = %+v, want enabled with 64MiB part / 192MiB total and verify_media_type off", pv)
+	}
+	if !cfg.Gateway.TTFTStats.Enabled || cfg.Gateway.TTFTStats.WindowMinutes != 60 || cfg.Gateway.TTFTStats.TrackAccounts {
+		t.Fatalf("ttft_stats = %+v, want enabled window=60 without accounts", cfg.Gateway.TTFTStats)
+	}
+	if cfg.Gateway.Routing.WeightedScoringEnabled || cfg.Gateway.Routing.Load != 1.0 || cfg.Gateway.Routing.Weight != 1.0 ||
+		cfg.Gateway.Routing.Latency != 0.5 || cfg.Gateway.Routing.LatencyHalfLifeSeconds != 300 {
+		t.Fatalf("routing = %+v, want disabled with load=1 weight=1 latency=0.5 half_life=300", cfg.Gateway.Routing)
+	}
+	if !cfg.Gateway.SessionAffinity.HeaderEnabled || cfg.Gateway.SessionAffinity.MinTTLSeconds != 60 || cfg.Gateway.SessionAffinity.MaxTTLSeconds != 86400 {
+		t.Fatalf("session_affinity = %+v, want header_enabled min=60 max=86400", cfg.Gateway.SessionAffinity)
+	}
 	if cfg.Gateway.ImageConcurrency.Enabled {
 		t.Fatalf("image_concurrency.enabled = true, want false")
 	}
@@ -1958,3 +2170,457 @@ func TestLoad_DefaultGatewayImageStreamConfig(t *testing.T) {
 		t.Fatalf("image stream timeout = %d, want greater than ordinary stream timeout %d", cfg.Gateway.ImageStreamDataIntervalTimeout, cfg.Gateway.StreamDataIntervalTimeout)
 	}
 }
+
+func TestValidateGatewayTimeoutTiers(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		tiers   []GatewayTimeoutTier
+		wantErr string
+	}{
+		{
+			name: "valid",
+			tiers: []GatewayTimeoutTier{
+				{Name: "fast", Models: []string{"*haiku*", "gpt-*-mini"}, ConnectTimeoutSeconds: 10, FirstTokenTimeoutSeconds: 20, TotalTimeoutSeconds: 120},
+				{Name: "default", FirstTokenTimeoutSeconds: 60},
+			},
+		},
+		{name: "missing name", tiers: []GatewayTimeoutTier{{Models: []string{"*"}}}, wantErr: "name is required"},
+		{name: "duplicate name", tiers: []GatewayTimeoutTier{{Name: "a", Models: []string{"x"}}, {Name: "A", Models: []string{"y"}}}, wantErr: "duplicated"},
+		{name: "missing models", tiers: []GatewayTimeoutTier{{Name: "fast"}}, wantErr: "models"},
+		{name: "bad pattern", tiers: []GatewayTimeoutTier{{Name: "fast", Models: []string{"gpt-[5"}}}, wantErr: "invalid"},
+		{name: "negative timeout", tiers: []GatewayTimeoutTier{{Name: "fast", Models: []string{"x"}, TotalTimeoutSeconds: -1}}, wantErr: "non-negative"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.Gateway.TimeoutTiers = tc.tiers
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() error = %v, want substring %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateGatewayResponseCache(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !cfg.Gateway.ResponseCache.Enabled || cfg.Gateway.ResponseCache.TTLSeconds != 3600 || cfg.Gateway.ResponseCache.MaxBodyBytes != 1<<20 {
+		t.Fatalf("unexpected response cache defaults: %+v", cfg.Gateway.ResponseCache)
+	}
+
+	cfg.Gateway.ResponseCache.TTLSeconds = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "response_cache.ttl_seconds") {
+		t.Fatalf("Validate() error = %v, want ttl_seconds error", err)
+	}
+	cfg.Gateway.ResponseCache.TTLSeconds = 60
+	cfg.Gateway.ResponseCache.MaxBodyBytes = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "response_cache.max_body_bytes") {
+		t.Fatalf("Validate() error = %v, want max_body_bytes error", err)
+	}
+
+	// 关闭时不校验
+	cfg.Gateway.ResponseCache.Enabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidateGatewayTransientRetryAttempts(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Gateway.TransientRetryAttempts != 1 {
+		t.Fatalf("TransientRetryAttempts = %d, want 1", cfg.Gateway.TransientRetryAttempts)
+	}
+
+	for _, attempts := range []int{-1, 4} {
+		cfg.Gateway.TransientRetryAttempts = attempts
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.transient_retry_attempts") {
+			t.Fatalf("Validate(%d) error = %v, want transient_retry_attempts error", attempts, err)
+		}
+	}
+	cfg.Gateway.TransientRetryAttempts = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidateGatewayUpstreamRateLimit(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	rl := cfg.Gateway.UpstreamRateLimit
+	if rl.Enabled || rl.MinRemainingTokens != 10000 || rl.MinRemainingRequests != 1 || rl.RefreshIntervalSeconds != 10 {
+		t.Fatalf("unexpected upstream_ratelimit defaults: %+v", rl)
+	}
+
+	cfg.Gateway.UpstreamRateLimit.MinRemainingTokens = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.upstream_ratelimit") {
+		t.Fatalf("Validate() error = %v, want upstream_ratelimit threshold error", err)
+	}
+	cfg.Gateway.UpstreamRateLimit.MinRemainingTokens = 0
+	cfg.Gateway.UpstreamRateLimit.Enabled = true
+	cfg.Gateway.UpstreamRateLimit.RefreshIntervalSeconds = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.upstream_ratelimit.refresh_interval_seconds") {
+		t.Fatalf("Validate() error = %v, want refresh_interval_seconds error", err)
+	}
+	cfg.Gateway.UpstreamRateLimit.RefreshIntervalSeconds = 5
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidateGatewayCountTokensMaxConcurrency(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Gateway.CountTokensMaxConcurrency != 4 {
+		t.Fatalf("CountTokensMaxConcurrency = %d, want 4", cfg.Gateway.CountTokensMaxConcurrency)
+	}
+
+	cfg.Gateway.CountTokensMaxConcurrency = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.count_tokens_max_concurrency") {
+		t.Fatalf("Validate() error = %v, want count_tokens_max_concurrency error", err)
+	}
+	cfg.Gateway.CountTokensMaxConcurrency = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidateGatewayServerErrorPenalty(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	p := cfg.Gateway.ServerErrorPenalty
+	if !p.Enabled || p.WindowSeconds != 60 || p.Threshold != 5 || p.PenaltySeconds != 120 {
+		t.Fatalf("unexpected server_error_penalty defaults: %+v", p)
+	}
+
+	cfg.Gateway.ServerErrorPenalty.Threshold = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.server_error_penalty") {
+		t.Fatalf("Validate() error = %v, want server_error_penalty error", err)
+	}
+	cfg.Gateway.ServerErrorPenalty.Enabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error when disabled: %v", err)
+	}
+}
+
+func TestValidateGatewayRequestCost(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	rc := cfg.Gateway.RequestCost
+	if !rc.Enabled || rc.DefaultOutputTokens != 4096 || rc.OverageFactor != 2 {
+		t.Fatalf("unexpected request_cost defaults: %+v", rc)
+	}
+
+	cfg.Gateway.RequestCost.OverageFactor = 0.5
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.request_cost.overage_factor") {
+		t.Fatalf("Validate() error = %v, want overage_factor error", err)
+	}
+	cfg.Gateway.RequestCost.OverageFactor = 2
+	cfg.Gateway.RequestCost.DefaultOutputTokens = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.request_cost.default_output_tokens") {
+		t.Fatalf("Validate() error = %v, want default_output_tokens error", err)
+	}
+	cfg.Gateway.RequestCost.Enabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error when disabled: %v", err)
+	}
+}
+
+func TestValidateGatewayGeminiAttachments(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	ga := cfg.Gateway.GeminiAttachments
+	if ga.MaxInlineBytes != 20*1024*1024 || ga.URLFetchEnabled || ga.URLFetchMaxBytes != 5*1024*1024 || ga.URLFetchTimeoutSeconds != 10 {
+		t.Fatalf("unexpected gemini_attachments defaults: %+v", ga)
+	}
+
+	cfg.Gateway.GeminiAttachments.URLFetchEnabled = true
+	cfg.Gateway.GeminiAttachments.URLFetchMaxBytes = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.gemini_attachments") {
+		t.Fatalf("Validate() error = %v, want url_fetch error", err)
+	}
+	cfg.Gateway.GeminiAttachments.URLFetchEnabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error when url fetch disabled: %v", err)
+	}
+	cfg.Gateway.GeminiAttachments.MaxInlineBytes = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.gemini_attachments.max_inline_bytes") {
+		t.Fatalf("Validate() error = %v, want max_inline_bytes error", err)
+	}
+}
+
+func TestValidateGatewayGeminiRateLimitReset(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if r := cfg.Gateway.GeminiRateLimitReset; r.Rounding != "ceil" || r.MinSeconds != 0 {
+		t.Fatalf("unexpected gemini_rate_limit_reset defaults: %+v", r)
+	}
+
+	cfg.Gateway.GeminiRateLimitReset.Rounding = "floor"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.gemini_rate_limit_reset.rounding") {
+		t.Fatalf("Validate() error = %v, want rounding error", err)
+	}
+	cfg.Gateway.GeminiRateLimitReset.Rounding = "round"
+	cfg.Gateway.GeminiRateLimitReset.MinSeconds = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.gemini_rate_limit_reset.min_seconds") {
+		t.Fatalf("Validate() error = %v, want min_seconds error", err)
+	}
+	cfg.Gateway.GeminiRateLimitReset.MinSeconds = 2
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidateGatewayForwardHeaders(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.Gateway.ForwardHeaders.ForceRemove) != 0 {
+		t.Fatalf("ForceRemove = %v, want empty", cfg.Gateway.ForwardHeaders.ForceRemove)
+	}
+
+	cfg.Gateway.ForwardHeaders.AdditionalAllowed = []string{"X-Trace-Id", "Authorization"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "gateway.forward_headers.additional_allowed") {
+		t.Fatalf("Validate() error = %v, want forward_headers error", err)
+	}
+	cfg.Gateway.ForwardHeaders.AdditionalAllowed = []string{"X-Trace-Id"}
+	cfg.Gateway.ForwardHeaders.ForceRemove = []string{"Accept-Language"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidateModelPrices(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		prices  []ModelPriceConfig
+		wantErr string
+	}{
+		{name: "valid", prices: []ModelPriceConfig{{Model: "my-model", InputPer1K: 0.003, OutputPer1K: 0.015}}},
+		{name: "missing model", prices: []ModelPriceConfig{{InputPer1K: 0.001}}, wantErr: "model is required"},
+		{name: "duplicate model", prices: []ModelPriceConfig{{Model: "a"}, {Model: " A "}}, wantErr: "duplicated"},
+		{name: "negative price", prices: []ModelPriceConfig{{Model: "a", CacheReadPer1K: -0.1}}, wantErr: "non-negative"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.Pricing.ModelPrices = tc.prices
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() error = %v, want substring %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAccountHealthProbe(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.AccountHealthProbe.Enabled || cfg.AccountHealthProbe.IntervalSeconds != 300 || cfg.AccountHealthProbe.FailureThreshold != 2 {
+		t.Fatalf("unexpected account_health_probe defaults: %+v", cfg.AccountHealthProbe)
+	}
+	base := cfg.AccountHealthProbe
+
+	cases := []struct {
+		name    string
+		mutate  func(c *AccountHealthProbeConfig)
+		wantErr string
+	}{
+		{name: "valid", mutate: func(c *AccountHealthProbeConfig) {
+			c.Enabled = true
+			c.Probes = map[string]string{"gemini": "count_tokens", "OpenAI": "none"}
+		}},
+		{name: "unknown platform", mutate: func(c *AccountHealthProbeConfig) { c.Probes = map[string]string{"sora": "models"} }, wantErr: "unknown platform"},
+		{name: "unsupported probe", mutate: func(c *AccountHealthProbeConfig) { c.Probes = map[string]string{"anthropic": "count_tokens"} }, wantErr: "must be one of"},
+		{name: "timeout exceeds interval", mutate: func(c *AccountHealthProbeConfig) {
+			c.Enabled = true
+			c.TimeoutSeconds = c.IntervalSeconds
+		}, wantErr: "timeout_seconds"},
+		{name: "zero concurrency", mutate: func(c *AccountHealthProbeConfig) {
+			c.Enabled = true
+			c.Concurrency = 0
+		}, wantErr: "concurrency"},
+		{name: "disabled skips interval checks", mutate: func(c *AccountHealthProbeConfig) { c.Concurrency = 0 }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.AccountHealthProbe = base
+			tc.mutate(&cfg.AccountHealthProbe)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() error = %v, want substring %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateResponseHeadersPlatformAllowed(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.Security.ResponseHeaders.PlatformAllowed) != 0 {
+		t.Fatalf("PlatformAllowed = %v, want empty", cfg.Security.ResponseHeaders.PlatformAllowed)
+	}
+
+	cases := []struct {
+		name    string
+		allowed map[string][]string
+		wantErr string
+	}{
+		{name: "valid", allowed: map[string][]string{"anthropic": {"request-id"}, "openai": {"openai-*"}, "gemini": {}}},
+		{name: "unknown platform", allowed: map[string][]string{"sora": {"x-foo"}}, wantErr: "unknown platform"},
+		{name: "wildcard only", allowed: map[string][]string{"openai": {"*"}}, wantErr: "prefix ending with *"},
+		{name: "inner wildcard", allowed: map[string][]string{"openai": {"x-*-ms"}}, wantErr: "prefix ending with *"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.Security.ResponseHeaders.PlatformAllowed = tc.allowed
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() error = %v, want substring %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateResponseRedaction(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Security.ResponseRedaction.Enabled {
+		t.Fatalf("ResponseRedaction.Enabled = true, want false by default")
+	}
+	if cfg.Security.ResponseRedaction.MaxFrameBytes != 1<<20 {
+		t.Fatalf("ResponseRedaction.MaxFrameBytes = %d, want %d", cfg.Security.ResponseRedaction.MaxFrameBytes, 1<<20)
+	}
+
+	cases := []struct {
+		name    string
+		rules   map[string][]ResponseRedactionRule
+		wantErr string
+	}{
+		{name: "valid", rules: map[string][]ResponseRedactionRule{
+			"openai":    {{Path: "organization", Action: "delete"}, {Path: "id", Action: "replace_prefix", From: "chatcmpl-", To: "x-"}},
+			"anthropic": {{Path: "message.organization", Action: "blank"}},
+			"gemini":    {},
+		}},
+		{name: "unknown platform", rules: map[string][]ResponseRedactionRule{"sora": {{Path: "id", Action: "delete"}}}, wantErr: "unknown platform"},
+		{name: "empty path", rules: map[string][]ResponseRedactionRule{"openai": {{Action: "delete"}}}, wantErr: "openai[0].path"},
+		{name: "wildcard path", rules: map[string][]ResponseRedactionRule{"openai": {{Path: "choices.#.id", Action: "delete"}}}, wantErr: "plain dot path"},
+		{name: "unknown action", rules: map[string][]ResponseRedactionRule{"openai": {{Path: "id", Action: "hash"}}}, wantErr: "openai[0].action"},
+		{name: "replace_prefix without from", rules: map[string][]ResponseRedactionRule{"gemini": {{Path: "responseId", Action: "replace_prefix"}}}, wantErr: "gemini[0].from"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.Security.ResponseRedaction.Platforms = tc.rules
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() error = %v, want substring %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadNormalizesResponseRedactionRules(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	viper.Set("security.response_redaction.platforms", map[string]any{
+		" OpenAI ": []any{map[string]any{"path": " organization ", "action": " Delete "}},
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	rules := cfg.Security.ResponseRedaction.Platforms["openai"]
+	if len(rules) != 1 || rules[0].Path != "organization" || rules[0].Action != ResponseRedactionActionDelete {
+		t.Fatalf("Platforms = %+v, want normalized openai rule", cfg.Security.ResponseRedaction.Platforms)
+	}
+}
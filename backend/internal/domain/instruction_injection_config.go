@@ -0,0 +1,20 @@
+package domain
+
+// Instruction injection modes.
+const (
+	// InstructionInjectionModePrepend puts the group text before any client instructions.
+	InstructionInjectionModePrepend = "prepend"
+	// InstructionInjectionModeIfAbsent only sets instructions when the client sent none.
+	InstructionInjectionModeIfAbsent = "if_absent"
+	// InstructionInjectionModeReplace discards client instructions in favour of the group text.
+	InstructionInjectionModeReplace = "replace"
+)
+
+// GroupInstructionInjectionConfig controls per-group instructions injection for
+// OpenAI Responses requests. Text may reference {{key_name}}, {{group_name}} and
+// {{date}} (UTC, YYYY-MM-DD).
+type GroupInstructionInjectionConfig struct {
+	Enabled bool   `json:"enabled"`
+	Mode    string `json:"mode,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+// GroupModelFallbackConfig controls per-group model downgrade when no account
+// can serve the requested model.
+type GroupModelFallbackConfig struct {
+	// Rules are evaluated in order; the first rule matching the requested model wins.
+	Rules []GroupModelFallbackRule `json:"rules,omitempty"`
+	// MaskFallback rewrites the response model field back to the requested model.
+	MaskFallback bool `json:"mask_fallback"`
+}
+
+// GroupModelFallbackRule maps a requested model (exact or trailing "*" pattern)
+// onto an ordered list of fallback models.
+type GroupModelFallbackRule struct {
+	Model     string   `json:"model"`
+	Fallbacks []string `json:"fallbacks"`
+}
@@ -63,6 +63,20 @@ type DataAccount struct {
 	RateMultiplier     *float64       `json:"rate_multiplier,omitempty"`
 	ExpiresAt          *int64         `json:"expires_at,omitempty"`
 	AutoPauseOnExpired *bool          `json:"auto_pause_on_expired,omitempty"`
+	// Fingerprint 仅存在于缓存中的客户端身份指纹（ClientID 与 stainless 头），只随加密导出携带
+	Fingerprint *DataFingerprint `json:"fingerprint,omitempty"`
+}
+
+// DataFingerprint 账号缓存指纹的导出形态，导入后账号沿用相同的 ClientID 与 stainless 头
+type DataFingerprint struct {
+	ClientID                string `json:"client_id"`
+	UserAgent               string `json:"user_agent"`
+	StainlessLang           string `json:"stainless_lang,omitempty"`
+	StainlessPackageVersion string `json:"stainless_package_version,omitempty"`
+	StainlessOS             string `json:"stainless_os,omitempty"`
+	StainlessArch           string `json:"stainless_arch,omitempty"`
+	StainlessRuntime        string `json:"stainless_runtime,omitempty"`
+	StainlessRuntimeVersion string `json:"stainless_runtime_version,omitempty"`
 }
 
 type DataImportRequest struct {
@@ -71,14 +85,52 @@ type DataImportRequest struct {
 }
 
 type DataImportResult struct {
+	DryRun         bool              `json:"dry_run,omitempty"`
 	ProxyCreated   int               `json:"proxy_created"`
 	ProxyReused    int               `json:"proxy_reused"`
 	ProxyFailed    int               `json:"proxy_failed"`
 	AccountCreated int               `json:"account_created"`
+	AccountUpdated int               `json:"account_updated,omitempty"`
+	AccountSkipped int               `json:"account_skipped,omitempty"`
 	AccountFailed  int               `json:"account_failed"`
+	Accounts       []DataImportItem  `json:"accounts,omitempty"`
 	Errors         []DataImportError `json:"errors,omitempty"`
 }
 
+// DataImportItem 记录单个账号的导入动作（create/update/skip），只包含名称等非敏感字段；
+// 仅在指定冲突策略或 dry_run 时返回，用于预览与核对
+type DataImportItem struct {
+	Name       string `json:"name"`
+	Platform   string `json:"platform"`
+	Type       string `json:"type"`
+	Action     string `json:"action"`
+	ExistingID int64  `json:"existing_id,omitempty"`
+}
+
+// 账号导入冲突策略：同平台、同类型、同名的已有账号视为冲突
+const (
+	dataConflictSkip      = "skip"
+	dataConflictOverwrite = "overwrite"
+	dataConflictDuplicate = "duplicate"
+)
+
+// 账号导入动作
+const (
+	dataImportActionCreate = "create"
+	dataImportActionUpdate = "update"
+	dataImportActionSkip   = "skip"
+)
+
+// dataImportOptions 控制导入行为；零值即明文导入的历史行为（总是新建、不做冲突检测）
+type dataImportOptions struct {
+	// ConflictStrategy 为空时不检测冲突
+	ConflictStrategy string
+	// DryRun 只计算将要发生的变更，不写入任何代理/账号
+	DryRun bool
+	// StrictCredentials 按账号类型校验凭证字段是否齐全
+	StrictCredentials bool
+}
+
 type DataImportError struct {
 	Kind     string `json:"kind"`
 	Name     string `json:"name,omitempty"`
@@ -91,32 +143,42 @@ func buildProxyKey(protocol, host string, port int, username, password string) s
 }
 
 func (h *AccountHandler) ExportData(c *gin.Context) {
-	ctx := c.Request.Context()
-
 	selectedIDs, err := parseAccountIDs(c)
 	if err != nil {
 		response.BadRequest(c, err.Error())
 		return
 	}
 
-	accounts, err := h.resolveExportAccounts(ctx, selectedIDs, c)
+	includeProxies, err := parseIncludeProxies(c)
 	if err != nil {
-		response.ErrorFrom(c, err)
+		response.BadRequest(c, err.Error())
 		return
 	}
 
-	includeProxies, err := parseIncludeProxies(c)
+	payload, err := h.buildDataPayload(c, selectedIDs, includeProxies, false)
 	if err != nil {
-		response.BadRequest(c, err.Error())
+		response.ErrorFrom(c, err)
 		return
 	}
 
+	response.Success(c, payload)
+}
+
+// buildDataPayload 按选中的账号 ID（为空时按 query 过滤条件）构建导出数据，明文导出与加密导出共用；
+// includeFingerprints 为 true 时附带缓存中的身份指纹（仅加密导出使用）
+func (h *AccountHandler) buildDataPayload(c *gin.Context, selectedIDs []int64, includeProxies, includeFingerprints bool) (DataPayload, error) {
+	ctx := c.Request.Context()
+
+	accounts, err := h.resolveExportAccounts(ctx, selectedIDs, c)
+	if err != nil {
+		return DataPayload{}, err
+	}
+
 	var proxies []service.Proxy
 	if includeProxies {
 		proxies, err = h.resolveExportProxies(ctx, accounts)
 		if err != nil {
-			response.ErrorFrom(c, err)
-			return
+			return DataPayload{}, err
 		}
 	} else {
 		proxies = []service.Proxy{}
@@ -174,6 +236,13 @@ func (h *AccountHandler) ExportData(c *gin.Context) {
 			v := acc.ExpiresAt.Unix()
 			expiresAt = &v
 		}
+		var fingerprint *DataFingerprint
+		if includeFingerprints {
+			fingerprint, err = h.exportDataFingerprint(ctx, acc.ID)
+			if err != nil {
+				return DataPayload{}, err
+			}
+		}
 		dataAccounts = append(dataAccounts, DataAccount{
 			Name:               acc.Name,
 			Notes:              acc.Notes,
@@ -187,16 +256,16 @@ func (h *AccountHandler) ExportData(c *gin.Context) {
 			RateMultiplier:     acc.RateMultiplier,
 			ExpiresAt:          expiresAt,
 			AutoPauseOnExpired: &acc.AutoPauseOnExpired,
+			Fingerprint:        fingerprint,
 		})
 	}
 
-	payload := DataPayload{
+	return DataPayload{
 		ExportedAt: time.Now().UTC().Format(time.RFC3339),
 		Proxies:    dataProxies,
 		Accounts:   dataAccounts,
-	}
+	}, nil
 
-	response.Success(c, payload)
 }
 
 func (h *AccountHandler) ImportData(c *gin.Context) {
@@ -212,18 +281,18 @@ func (h *AccountHandler) ImportData(c *gin.Context) {
 	}
 
 	executeAdminIdempotentJSON(c, "admin.accounts.import_data", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
-		return h.importData(ctx, req)
+		return h.importData(ctx, req, dataImportOptions{})
 	})
 }
 
-func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest) (DataImportResult, error) {
+func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest, opts dataImportOptions) (DataImportResult, error) {
 	skipDefaultGroupBind := true
 	if req.SkipDefaultGroupBind != nil {
 		skipDefaultGroupBind = *req.SkipDefaultGroupBind
 	}
 
 	dataPayload := req.Data
-	result := DataImportResult{}
+	result := DataImportResult{DryRun: opts.DryRun}
 
 	existingProxies, err := h.listAllProxies(ctx)
 	if err != nil {
@@ -262,7 +331,7 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 		if existingID, ok := proxyKeyToID[key]; ok {
 			proxyKeyToID[key] = existingID
 			result.ProxyReused++
-			if normalizedStatus != "" {
+			if normalizedStatus != "" && !opts.DryRun {
 				if proxy, getErr := h.adminService.GetProxy(ctx, existingID); getErr == nil && proxy != nil && proxy.Status != normalizedStatus {
 					// 同步 status 时传入完整字段，避免零值覆盖已存在代理的有效期/fallback 配置。
 					var existingExpiresAt *time.Time
@@ -298,6 +367,16 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 			continue
 		}
 
+		if opts.DryRun {
+			// dry_run 不创建代理，以占位 ID 让后续账号能解析到该 proxy_key
+			proxyKeyToID[key] = 0
+			if item.Name != "" {
+				proxyNameToID[item.Name] = 0
+			}
+			result.ProxyCreated++
+			continue
+		}
+
 		// 解析 expires_at（unix 秒 → *time.Time）
 		var expiresAt *time.Time
 		if item.ExpiresAt != nil {
@@ -370,12 +449,24 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 		}
 	}
 
+	var existingAccountIDs map[string]int64
+	if opts.ConflictStrategy != "" {
+		existingAccountIDs, err = h.indexExistingAccounts(ctx)
+		if err != nil {
+			return result, err
+		}
+	}
+
 	// 收集需要异步设置隐私的 Antigravity OAuth 账号
 	var privacyAccounts []*service.Account
 
 	for i := range dataPayload.Accounts {
 		item := dataPayload.Accounts[i]
-		if err := validateDataAccount(item); err != nil {
+		err := validateDataAccount(item)
+		if err == nil && opts.StrictCredentials {
+			err = validateDataAccountCredentials(item)
+		}
+		if err != nil {
 			result.AccountFailed++
 			result.Errors = append(result.Errors, DataImportError{
 				Kind:    "account",
@@ -403,6 +494,41 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 
 		enrichCredentialsFromIDToken(&item)
 
+		importItem := DataImportItem{Name: item.Name, Platform: item.Platform, Type: item.Type, Action: dataImportActionCreate}
+		if existingID, ok := existingAccountIDs[dataAccountConflictKey(item.Platform, item.Type, item.Name)]; ok {
+			importItem.ExistingID = existingID
+			switch opts.ConflictStrategy {
+			case dataConflictSkip:
+				importItem.Action = dataImportActionSkip
+			case dataConflictOverwrite:
+				importItem.Action = dataImportActionUpdate
+			}
+		}
+		if opts.DryRun || importItem.Action != dataImportActionCreate {
+			if !opts.DryRun && importItem.Action == dataImportActionUpdate {
+				if err := h.overwriteDataAccount(ctx, importItem.ExistingID, item, proxyID); err != nil {
+					result.AccountFailed++
+					result.Errors = append(result.Errors, DataImportError{
+						Kind:    "account",
+						Name:    item.Name,
+						Message: err.Error(),
+					})
+					continue
+				}
+				h.importDataFingerprint(ctx, importItem.ExistingID, item, &result)
+			}
+			switch importItem.Action {
+			case dataImportActionCreate:
+				result.AccountCreated++
+			case dataImportActionUpdate:
+				result.AccountUpdated++
+			case dataImportActionSkip:
+				result.AccountSkipped++
+			}
+			result.Accounts = append(result.Accounts, importItem)
+			continue
+		}
+
 		accountInput := &service.CreateAccountInput{
 			Name:                 item.Name,
 			Notes:                item.Notes,
@@ -430,11 +556,15 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 			})
 			continue
 		}
+		h.importDataFingerprint(ctx, created.ID, item, &result)
 		// 收集 Antigravity OAuth 账号，稍后异步设置隐私
 		if created.Platform == service.PlatformAntigravity && created.Type == service.AccountTypeOAuth {
 			privacyAccounts = append(privacyAccounts, created)
 		}
 		result.AccountCreated++
+		if opts.ConflictStrategy != "" {
+			result.Accounts = append(result.Accounts, importItem)
+		}
 	}
 
 	// 异步设置 Antigravity 隐私，避免大量导入时阻塞请求
@@ -457,6 +587,101 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 	return result, nil
 }
 
+// indexExistingAccounts 以 platform|type|name 为键索引已有账号，用于导入冲突检测；同名多个时取第一个
+func (h *AccountHandler) indexExistingAccounts(ctx context.Context) (map[string]int64, error) {
+	accounts, err := h.listAccountsFiltered(ctx, "", "", "", "", 0, "", "id", "asc")
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]int64, len(accounts))
+	for i := range accounts {
+		key := dataAccountConflictKey(accounts[i].Platform, accounts[i].Type, accounts[i].Name)
+		if _, ok := out[key]; !ok {
+			out[key] = accounts[i].ID
+		}
+	}
+	return out, nil
+}
+
+func dataAccountConflictKey(platform, accountType, name string) string {
+	return strings.ToLower(strings.TrimSpace(platform)) + "|" + strings.ToLower(strings.TrimSpace(accountType)) + "|" + strings.TrimSpace(name)
+}
+
+// overwriteDataAccount 以导入数据覆盖已有账号的凭证、代理与调度配置，不改动分组绑定与状态；
+// 导入数据未指定代理时清除账号原有代理（UpdateAccount 以 0 表示清除）
+func (h *AccountHandler) overwriteDataAccount(ctx context.Context, id int64, item DataAccount, proxyID *int64) error {
+	concurrency := item.Concurrency
+	priority := item.Priority
+	if proxyID == nil {
+		noProxy := int64(0)
+		proxyID = &noProxy
+	}
+	input := &service.UpdateAccountInput{
+		Name:               item.Name,
+		Notes:              item.Notes,
+		Type:               item.Type,
+		Credentials:        item.Credentials,
+		Extra:              item.Extra,
+		ProxyID:            proxyID,
+		Concurrency:        &concurrency,
+		Priority:           &priority,
+		RateMultiplier:     item.RateMultiplier,
+		ExpiresAt:          item.ExpiresAt,
+		AutoPauseOnExpired: item.AutoPauseOnExpired,
+	}
+	_, err := h.adminService.UpdateAccount(ctx, id, input)
+	return err
+}
+
+// exportDataFingerprint 读取账号缓存中的身份指纹；从未转发过请求的账号没有指纹，返回 nil
+func (h *AccountHandler) exportDataFingerprint(ctx context.Context, accountID int64) (*DataFingerprint, error) {
+	if h.identityService == nil {
+		return nil, nil
+	}
+	fp, err := h.identityService.ExportFingerprint(ctx, accountID)
+	if errors.Is(err, service.ErrFingerprintNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &DataFingerprint{
+		ClientID:                fp.ClientID,
+		UserAgent:               fp.UserAgent,
+		StainlessLang:           fp.StainlessLang,
+		StainlessPackageVersion: fp.StainlessPackageVersion,
+		StainlessOS:             fp.StainlessOS,
+		StainlessArch:           fp.StainlessArch,
+		StainlessRuntime:        fp.StainlessRuntime,
+		StainlessRuntimeVersion: fp.StainlessRuntimeVersion,
+	}, nil
+}
+
+// importDataFingerprint 把导入数据中的身份指纹写入账号缓存；账号本身已导入成功，失败只记录错误
+func (h *AccountHandler) importDataFingerprint(ctx context.Context, accountID int64, item DataAccount, result *DataImportResult) {
+	if item.Fingerprint == nil || h.identityService == nil {
+		return
+	}
+	fp := item.Fingerprint
+	err := h.identityService.ImportFingerprint(ctx, accountID, &service.Fingerprint{
+		ClientID:                fp.ClientID,
+		UserAgent:               fp.UserAgent,
+		StainlessLang:           fp.StainlessLang,
+		StainlessPackageVersion: fp.StainlessPackageVersion,
+		StainlessOS:             fp.StainlessOS,
+		StainlessArch:           fp.StainlessArch,
+		StainlessRuntime:        fp.StainlessRuntime,
+		StainlessRuntimeVersion: fp.StainlessRuntimeVersion,
+	})
+	if err != nil {
+		result.Errors = append(result.Errors, DataImportError{
+			Kind:    "fingerprint",
+			Name:    item.Name,
+			Message: err.Error(),
+		})
+	}
+}
+
 func (h *AccountHandler) listAllProxies(ctx context.Context) ([]service.Proxy, error) {
 	page := 1
 	pageSize := dataPageCap
@@ -662,7 +887,8 @@ func validateDataAccount(item DataAccount) error {
 		return errors.New("account credentials is required")
 	}
 	switch item.Type {
-	case service.AccountTypeOAuth, service.AccountTypeSetupToken, service.AccountTypeAPIKey, service.AccountTypeUpstream:
+	case service.AccountTypeOAuth, service.AccountTypeSetupToken, service.AccountTypeAPIKey, service.AccountTypeUpstream,
+		service.AccountTypeBedrock, service.AccountTypeServiceAccount:
 	default:
 		return fmt.Errorf("account type is invalid: %s", item.Type)
 	}
@@ -678,6 +904,48 @@ func validateDataAccount(item DataAccount) error {
 	return nil
 }
 
+// validateDataAccountCredentials 按账号类型校验凭证字段是否齐全，避免导入后才在调度时失败；
+// 只报告缺失的字段名，不回显凭证内容
+func validateDataAccountCredentials(item DataAccount) error {
+	has := func(key string) bool {
+		v, _ := item.Credentials[key].(string)
+		return strings.TrimSpace(v) != ""
+	}
+	switch item.Type {
+	case service.AccountTypeOAuth:
+		if !has("access_token") && !has("refresh_token") {
+			return errors.New("oauth credentials require access_token or refresh_token")
+		}
+	case service.AccountTypeSetupToken:
+		if !has("access_token") {
+			return errors.New("setup-token credentials require access_token")
+		}
+	case service.AccountTypeAPIKey:
+		if !has("api_key") {
+			return errors.New("apikey credentials require api_key")
+		}
+	case service.AccountTypeUpstream:
+		if !has("api_key") || !has("base_url") {
+			return errors.New("upstream credentials require api_key and base_url")
+		}
+	case service.AccountTypeBedrock:
+		if v, _ := item.Credentials["auth_mode"].(string); v == "apikey" {
+			if !has("api_key") {
+				return errors.New("bedrock apikey credentials require api_key")
+			}
+		} else if !has("aws_access_key_id") || !has("aws_secret_access_key") {
+			return errors.New("bedrock credentials require aws_access_key_id and aws_secret_access_key")
+		}
+	case service.AccountTypeServiceAccount:
+		_, nested := item.Credentials["service_account_json"].(map[string]any)
+		_, nestedLegacy := item.Credentials["service_account"].(map[string]any)
+		if !has("service_account_json") && !has("service_account") && !nested && !nestedLegacy {
+			return errors.New("service_account credentials require service_account_json")
+		}
+	}
+	return nil
+}
+
 func defaultProxyName(name string) string {
 	if strings.TrimSpace(name) == "" {
 		return "imported-proxy"
@@ -0,0 +1,306 @@
+package admin
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	encryptedDataType    = "sub2api-encrypted-data"
+	encryptedDataVersion = 1
+	encryptedDataKDF     = "scrypt"
+	encryptedDataCipher  = "aes-256-gcm"
+
+	// scrypt 默认参数（约 32MB 内存）；导入时按包内参数派生，但限制上限避免恶意包耗尽资源
+	encryptedDataScryptN    = 1 << 15
+	encryptedDataScryptR    = 8
+	encryptedDataScryptP    = 1
+	encryptedDataScryptMaxN = 1 << 20
+	encryptedDataScryptMaxR = 16
+	encryptedDataScryptMaxP = 4
+	encryptedDataKeyLen     = 32
+	encryptedDataSaltLen    = 16
+
+	encryptedDataMinPassphraseLen = 8
+)
+
+var errEncryptedDataDecrypt = infraerrors.BadRequest("DATA_BUNDLE_DECRYPT_FAILED", "failed to decrypt bundle: wrong passphrase or corrupted data")
+
+// EncryptedDataBundle 是加密后的账号导出包：DataPayload 以 JSON 序列化后用 AES-256-GCM 加密，
+// 密钥由运维提供的口令经 scrypt 派生。包头（type/version/kdf 参数）作为 AAD 参与认证，
+// 明文字段只保留导出时间与账号数量，不包含任何凭证。
+type EncryptedDataBundle struct {
+	Type         string                 `json:"type"`
+	Version      int                    `json:"version"`
+	ExportedAt   string                 `json:"exported_at"`
+	AccountCount int                    `json:"account_count"`
+	KDF          EncryptedDataKDFParams `json:"kdf"`
+	Cipher       string                 `json:"cipher"`
+	Nonce        string                 `json:"nonce"`
+	Ciphertext   string                 `json:"ciphertext"`
+}
+
+type EncryptedDataKDFParams struct {
+	Name string `json:"name"`
+	Salt string `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+type EncryptedDataExportRequest struct {
+	IDs            []int64 `json:"ids"`
+	IncludeProxies *bool   `json:"include_proxies"`
+	Passphrase     string  `json:"passphrase"`
+}
+
+type EncryptedDataImportRequest struct {
+	Bundle               EncryptedDataBundle `json:"bundle"`
+	Passphrase           string              `json:"passphrase"`
+	ConflictStrategy     string              `json:"conflict_strategy"`
+	DryRun               bool                `json:"dry_run"`
+	SkipDefaultGroupBind *bool               `json:"skip_default_group_bind"`
+}
+
+// encryptedDataImportIdempotencyPayload 用于幂等指纹，刻意排除口令
+type encryptedDataImportIdempotencyPayload struct {
+	Bundle               EncryptedDataBundle `json:"bundle"`
+	ConflictStrategy     string              `json:"conflict_strategy"`
+	SkipDefaultGroupBind *bool               `json:"skip_default_group_bind"`
+}
+
+// ExportEncryptedData 导出选中账号（含凭证、代理、Extra 中的指纹配置及缓存中的身份指纹）为口令加密的数据包
+// POST /api/v1/admin/accounts/data/encrypted-export
+func (h *AccountHandler) ExportEncryptedData(c *gin.Context) {
+	var req EncryptedDataExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if err := validateDataPassphrase(req.Passphrase); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	for _, id := range req.IDs {
+		if id <= 0 {
+			response.BadRequest(c, fmt.Sprintf("invalid account id: %d", id))
+			return
+		}
+	}
+	includeProxies := true
+	if req.IncludeProxies != nil {
+		includeProxies = *req.IncludeProxies
+	}
+
+	payload, err := h.buildDataPayload(c, req.IDs, includeProxies, true)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	payload.Type = dataType
+	payload.Version = dataVersion
+
+	bundle, err := encryptDataPayload(payload, req.Passphrase)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, bundle)
+}
+
+// ImportEncryptedData 解密并导入加密数据包，支持冲突策略（skip/overwrite/duplicate）与 dry_run 预览
+// POST /api/v1/admin/accounts/data/encrypted-import
+func (h *AccountHandler) ImportEncryptedData(c *gin.Context) {
+	var req EncryptedDataImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	strategy, err := normalizeDataConflictStrategy(req.ConflictStrategy)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Passphrase) == "" {
+		response.BadRequest(c, "passphrase is required")
+		return
+	}
+
+	payload, err := decryptDataBundle(req.Bundle, req.Passphrase)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if err := validateDataHeader(payload); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	importReq := DataImportRequest{Data: payload, SkipDefaultGroupBind: req.SkipDefaultGroupBind}
+	opts := dataImportOptions{ConflictStrategy: strategy, DryRun: req.DryRun, StrictCredentials: true}
+	if req.DryRun {
+		// dry_run 不产生写入，无需幂等保护
+		result, err := h.importData(c.Request.Context(), importReq, opts)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		response.Success(c, result)
+		return
+	}
+
+	idempotencyPayload := encryptedDataImportIdempotencyPayload{
+		Bundle:               req.Bundle,
+		ConflictStrategy:     strategy,
+		SkipDefaultGroupBind: req.SkipDefaultGroupBind,
+	}
+	executeAdminIdempotentJSON(c, "admin.accounts.import_encrypted_data", idempotencyPayload, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		return h.importData(ctx, importReq, opts)
+	})
+}
+
+func normalizeDataConflictStrategy(raw string) (string, error) {
+	strategy := strings.ToLower(strings.TrimSpace(raw))
+	switch strategy {
+	case "":
+		return dataConflictSkip, nil
+	case dataConflictSkip, dataConflictOverwrite, dataConflictDuplicate:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid conflict_strategy: %s", raw)
+	}
+}
+
+func validateDataPassphrase(passphrase string) error {
+	if len([]rune(passphrase)) < encryptedDataMinPassphraseLen {
+		return fmt.Errorf("passphrase must be at least %d characters", encryptedDataMinPassphraseLen)
+	}
+	return nil
+}
+
+func encryptDataPayload(payload DataPayload, passphrase string) (EncryptedDataBundle, error) {
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return EncryptedDataBundle{}, fmt.Errorf("marshal data payload: %w", err)
+	}
+
+	salt := make([]byte, encryptedDataSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return EncryptedDataBundle{}, fmt.Errorf("generate salt: %w", err)
+	}
+	bundle := EncryptedDataBundle{
+		Type:         encryptedDataType,
+		Version:      encryptedDataVersion,
+		ExportedAt:   payload.ExportedAt,
+		AccountCount: len(payload.Accounts),
+		KDF: EncryptedDataKDFParams{
+			Name: encryptedDataKDF,
+			Salt: base64.StdEncoding.EncodeToString(salt),
+			N:    encryptedDataScryptN,
+			R:    encryptedDataScryptR,
+			P:    encryptedDataScryptP,
+		},
+		Cipher: encryptedDataCipher,
+	}
+	if bundle.ExportedAt == "" {
+		bundle.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	gcm, err := newDataBundleGCM(passphrase, salt, bundle.KDF)
+	if err != nil {
+		return EncryptedDataBundle{}, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return EncryptedDataBundle{}, fmt.Errorf("generate nonce: %w", err)
+	}
+	ciphertext := gcm.Seal(nil, nonce, plaintext, dataBundleAAD(bundle))
+	bundle.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	bundle.Ciphertext = base64.StdEncoding.EncodeToString(ciphertext)
+	return bundle, nil
+}
+
+// decryptDataBundle 校验包头并解密；口令错误与数据被篡改统一返回同一错误，不泄露具体原因
+func decryptDataBundle(bundle EncryptedDataBundle, passphrase string) (DataPayload, error) {
+	if bundle.Type != encryptedDataType {
+		return DataPayload{}, infraerrors.BadRequest("DATA_BUNDLE_INVALID", fmt.Sprintf("unsupported bundle type: %s", bundle.Type))
+	}
+	if bundle.Version != encryptedDataVersion {
+		return DataPayload{}, infraerrors.BadRequest("DATA_BUNDLE_INVALID", fmt.Sprintf("unsupported bundle version: %d", bundle.Version))
+	}
+	if bundle.Cipher != encryptedDataCipher || bundle.KDF.Name != encryptedDataKDF {
+		return DataPayload{}, infraerrors.BadRequest("DATA_BUNDLE_INVALID", "unsupported bundle cipher or kdf")
+	}
+	if bundle.KDF.N <= 1 || bundle.KDF.N > encryptedDataScryptMaxN || bundle.KDF.N&(bundle.KDF.N-1) != 0 ||
+		bundle.KDF.R <= 0 || bundle.KDF.R > encryptedDataScryptMaxR ||
+		bundle.KDF.P <= 0 || bundle.KDF.P > encryptedDataScryptMaxP {
+		return DataPayload{}, infraerrors.BadRequest("DATA_BUNDLE_INVALID", "invalid bundle kdf parameters")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(bundle.KDF.Salt)
+	if err != nil || len(salt) == 0 {
+		return DataPayload{}, infraerrors.BadRequest("DATA_BUNDLE_INVALID", "invalid bundle salt")
+	}
+	nonce, err := base64.StdEncoding.DecodeString(bundle.Nonce)
+	if err != nil {
+		return DataPayload{}, infraerrors.BadRequest("DATA_BUNDLE_INVALID", "invalid bundle nonce")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(bundle.Ciphertext)
+	if err != nil {
+		return DataPayload{}, infraerrors.BadRequest("DATA_BUNDLE_INVALID", "invalid bundle ciphertext")
+	}
+
+	gcm, err := newDataBundleGCM(passphrase, salt, bundle.KDF)
+	if err != nil {
+		return DataPayload{}, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return DataPayload{}, infraerrors.BadRequest("DATA_BUNDLE_INVALID", "invalid bundle nonce")
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, dataBundleAAD(bundle))
+	if err != nil {
+		return DataPayload{}, errEncryptedDataDecrypt
+	}
+
+	var payload DataPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return DataPayload{}, errEncryptedDataDecrypt
+	}
+	return payload, nil
+}
+
+func newDataBundleGCM(passphrase string, salt []byte, params EncryptedDataKDFParams) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, encryptedDataKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("derive bundle key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return gcm, nil
+}
+
+// dataBundleAAD 把包头与 KDF 参数绑定进认证标签，防止篡改参数或账号数量
+func dataBundleAAD(bundle EncryptedDataBundle) []byte {
+	return []byte(fmt.Sprintf("%s|%d|%s|%d|%s|%s|%d|%d|%d",
+		bundle.Type, bundle.Version, bundle.ExportedAt, bundle.AccountCount,
+		bundle.Cipher, bundle.KDF.Salt, bundle.KDF.N, bundle.KDF.R, bundle.KDF.P))
+}
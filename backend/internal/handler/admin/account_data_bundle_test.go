@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+const testBundlePassphrase = "correct horse battery"
+
+func setupEncryptedDataRouter() (*gin.Engine, *stubAdminService) {
+	router, adminSvc := setupAccountDataRouter()
+	h := NewAccountHandler(adminSvc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	router.POST("/api/v1/admin/accounts/data/encrypted-export", h.ExportEncryptedData)
+	router.POST("/api/v1/admin/accounts/data/encrypted-import", h.ImportEncryptedData)
+	return router, adminSvc
+}
+
+func postJSON(t *testing.T, router *gin.Engine, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func exportTestBundle(t *testing.T, accounts []service.Account) EncryptedDataBundle {
+	t.Helper()
+	router, adminSvc := setupEncryptedDataRouter()
+	adminSvc.accounts = accounts
+
+	rec := postJSON(t, router, "/api/v1/admin/accounts/data/encrypted-export", map[string]any{"passphrase": testBundlePassphrase})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NotContains(t, rec.Body.String(), "sk-secret")
+
+	var resp struct {
+		Code int                 `json:"code"`
+		Data EncryptedDataBundle `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, 0, resp.Code)
+	require.Equal(t, encryptedDataType, resp.Data.Type)
+	require.Equal(t, len(accounts), resp.Data.AccountCount)
+	return resp.Data
+}
+
+type encryptedImportResponse struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    DataImportResult `json:"data"`
+}
+
+func importTestBundle(t *testing.T, router *gin.Engine, body map[string]any) (*httptest.ResponseRecorder, encryptedImportResponse) {
+	t.Helper()
+	rec := postJSON(t, router, "/api/v1/admin/accounts/data/encrypted-import", body)
+	var resp encryptedImportResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec, resp
+}
+
+func bundleTestAccounts() []service.Account {
+	return []service.Account{
+		{
+			ID:          21,
+			Name:        "acc",
+			Platform:    service.PlatformAnthropic,
+			Type:        service.AccountTypeAPIKey,
+			Credentials: map[string]any{"api_key": "sk-secret-1"},
+			Extra:       map[string]any{"enable_tls_fingerprint": true},
+			Concurrency: 3,
+			Priority:    10,
+		},
+		{
+			ID:          22,
+			Name:        "new",
+			Platform:    service.PlatformOpenAI,
+			Type:        service.AccountTypeOAuth,
+			Credentials: map[string]any{"refresh_token": "sk-secret-2"},
+			Concurrency: 1,
+			Priority:    20,
+		},
+	}
+}
+
+func TestEncryptedDataRoundTrip(t *testing.T) {
+	bundle := exportTestBundle(t, bundleTestAccounts())
+
+	router, adminSvc := setupEncryptedDataRouter()
+	rec, resp := importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": testBundlePassphrase})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NotContains(t, rec.Body.String(), "sk-secret")
+	require.Equal(t, 2, resp.Data.AccountCreated)
+
+	require.Len(t, adminSvc.createdAccounts, 2)
+	require.Equal(t, "acc", adminSvc.createdAccounts[0].Name)
+	require.Equal(t, "sk-secret-1", adminSvc.createdAccounts[0].Credentials["api_key"])
+	require.Equal(t, true, adminSvc.createdAccounts[0].Extra["enable_tls_fingerprint"])
+	require.Equal(t, "sk-secret-2", adminSvc.createdAccounts[1].Credentials["refresh_token"])
+	require.Equal(t, 20, adminSvc.createdAccounts[1].Priority)
+}
+
+func TestEncryptedDataImportRejectsWrongPassphrase(t *testing.T) {
+	bundle := exportTestBundle(t, bundleTestAccounts())
+
+	router, adminSvc := setupEncryptedDataRouter()
+	rec, resp := importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": "wrong passphrase"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, resp.Message, "wrong passphrase or corrupted data")
+	require.Empty(t, adminSvc.createdAccounts)
+
+	// 篡改明文包头同样无法通过认证
+	bundle.AccountCount = 99
+	rec, _ = importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": testBundlePassphrase})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Empty(t, adminSvc.createdAccounts)
+}
+
+func TestEncryptedDataExportRequiresPassphrase(t *testing.T) {
+	router, _ := setupEncryptedDataRouter()
+	rec := postJSON(t, router, "/api/v1/admin/accounts/data/encrypted-export", map[string]any{"passphrase": "short"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestEncryptedDataImportConflictStrategies(t *testing.T) {
+	bundle := exportTestBundle(t, bundleTestAccounts())
+	existing := []service.Account{{ID: 7, Name: "acc", Platform: service.PlatformAnthropic, Type: service.AccountTypeAPIKey}}
+
+	t.Run("skip", func(t *testing.T) {
+		router, adminSvc := setupEncryptedDataRouter()
+		adminSvc.accounts = existing
+		rec, resp := importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": testBundlePassphrase, "conflict_strategy": "skip"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, 1, resp.Data.AccountSkipped)
+		require.Equal(t, 1, resp.Data.AccountCreated)
+		require.Len(t, adminSvc.createdAccounts, 1)
+		require.Equal(t, "new", adminSvc.createdAccounts[0].Name)
+		require.Empty(t, adminSvc.updatedAccounts)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		router, adminSvc := setupEncryptedDataRouter()
+		adminSvc.accounts = existing
+		rec, resp := importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": testBundlePassphrase, "conflict_strategy": "overwrite"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, 1, resp.Data.AccountUpdated)
+		require.Equal(t, []int64{7}, adminSvc.updatedAccountIDs)
+		require.Equal(t, "sk-secret-1", adminSvc.updatedAccounts[0].Credentials["api_key"])
+		// 导出包未携带代理：覆盖时清除已有账号的代理
+		require.NotNil(t, adminSvc.updatedAccounts[0].ProxyID)
+		require.Zero(t, *adminSvc.updatedAccounts[0].ProxyID)
+		require.Len(t, adminSvc.createdAccounts, 1)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		router, adminSvc := setupEncryptedDataRouter()
+		adminSvc.accounts = existing
+		rec, resp := importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": testBundlePassphrase, "conflict_strategy": "duplicate"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, 2, resp.Data.AccountCreated)
+		require.Len(t, adminSvc.createdAccounts, 2)
+		require.Empty(t, adminSvc.updatedAccounts)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		router, adminSvc := setupEncryptedDataRouter()
+		adminSvc.accounts = existing
+		rec, resp := importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": testBundlePassphrase, "conflict_strategy": "overwrite", "dry_run": true})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.True(t, resp.Data.DryRun)
+		require.Equal(t, 1, resp.Data.AccountUpdated)
+		require.Equal(t, 1, resp.Data.AccountCreated)
+		require.Equal(t, []DataImportItem{
+			{Name: "acc", Platform: service.PlatformAnthropic, Type: service.AccountTypeAPIKey, Action: dataImportActionUpdate, ExistingID: 7},
+			{Name: "new", Platform: service.PlatformOpenAI, Type: service.AccountTypeOAuth, Action: dataImportActionCreate},
+		}, resp.Data.Accounts)
+		require.Empty(t, adminSvc.createdAccounts)
+		require.Empty(t, adminSvc.updatedAccounts)
+	})
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		router, _ := setupEncryptedDataRouter()
+		rec, _ := importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": testBundlePassphrase, "conflict_strategy": "merge"})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestEncryptedDataImportValidatesCredentialShape(t *testing.T) {
+	accounts := bundleTestAccounts()
+	accounts[1].Credentials = map[string]any{"id_token": "x"}
+	bundle := exportTestBundle(t, accounts)
+
+	router, adminSvc := setupEncryptedDataRouter()
+	rec, resp := importTestBundle(t, router, map[string]any{"bundle": bundle, "passphrase": testBundlePassphrase})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, 1, resp.Data.AccountCreated)
+	require.Equal(t, 1, resp.Data.AccountFailed)
+	require.Len(t, resp.Data.Errors, 1)
+	require.Equal(t, "new", resp.Data.Errors[0].Name)
+	require.Contains(t, resp.Data.Errors[0].Message, "refresh_token")
+	require.Len(t, adminSvc.createdAccounts, 1)
+}
+
+type fingerprintCacheStub struct {
+	service.IdentityCache
+	fingerprints map[int64]*service.Fingerprint
+}
+
+func (s *fingerprintCacheStub) GetFingerprint(_ context.Context, accountID int64) (*service.Fingerprint, error) {
+	if fp, ok := s.fingerprints[accountID]; ok {
+		return fp, nil
+	}
+	return nil, redis.Nil
+}
+
+func (s *fingerprintCacheStub) SetFingerprint(_ context.Context, accountID int64, fp *service.Fingerprint) error {
+	s.fingerprints[accountID] = fp
+	return nil
+}
+
+func TestEncryptedDataCarriesIdentityFingerprint(t *testing.T) {
+	clientID := strings.Repeat("ab", 32)
+	exportCache := &fingerprintCacheStub{fingerprints: map[int64]*service.Fingerprint{
+		21: {ClientID: clientID, UserAgent: "claude-cli/2.1.0", StainlessOS: "MacOS", StainlessRuntimeVersion: "v22.1.0"},
+	}}
+
+	router, adminSvc := setupAccountDataRouter()
+	adminSvc.accounts = bundleTestAccounts()
+	h := NewAccountHandler(adminSvc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h.SetIdentityService(service.NewIdentityService(exportCache))
+	router.POST("/api/v1/admin/accounts/data/encrypted-export", h.ExportEncryptedData)
+	rec := postJSON(t, router, "/api/v1/admin/accounts/data/encrypted-export", map[string]any{"passphrase": testBundlePassphrase})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NotContains(t, rec.Body.String(), clientID)
+	var exported struct {
+		Data EncryptedDataBundle `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &exported))
+
+	importCache := &fingerprintCacheStub{fingerprints: map[int64]*service.Fingerprint{}}
+	router, adminSvc = setupAccountDataRouter()
+	h = NewAccountHandler(adminSvc, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h.SetIdentityService(service.NewIdentityService(importCache))
+	router.POST("/api/v1/admin/accounts/data/encrypted-import", h.ImportEncryptedData)
+	rec, resp := importTestBundle(t, router, map[string]any{"bundle": exported.Data, "passphrase": testBundlePassphrase})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, 2, resp.Data.AccountCreated)
+	require.Empty(t, resp.Data.Errors)
+
+	// 只有 acc 带有缓存指纹；stub 新建账号统一返回 ID 300
+	require.Len(t, importCache.fingerprints, 1)
+	imported := importCache.fingerprints[300]
+	require.NotNil(t, imported)
+	require.Equal(t, clientID, imported.ClientID)
+	require.Equal(t, "claude-cli/2.1.0", imported.UserAgent)
+	require.Equal(t, "MacOS", imported.StainlessOS)
+	require.Equal(t, "v22.1.0", imported.StainlessRuntimeVersion)
+}
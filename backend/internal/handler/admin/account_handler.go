@@ -58,6 +58,12 @@ type AccountHandler struct {
 	sessionLimitCache       service.SessionLimitCache
 	rpmCache                service.RPMCache
 	tokenCacheInvalidator   service.TokenCacheInvalidator
+	accountHealthProbe      *service.AccountHealthProbeService
+	upstreamRateLimits      *service.UpstreamRateLimitTracker
+	serverErrors            *service.AccountServerErrorTracker
+	identityService         *service.IdentityService
+
+	auditRecorder
 }
 
 // NewAccountHandler creates a new admin account handler
@@ -582,6 +588,9 @@ func (h *AccountHandler) Create(c *gin.Context) {
 	if result != nil && result.Replayed {
 		c.Header("X-Idempotency-Replayed", "true")
 	}
+	if createdAccount != nil {
+		h.recordAudit(c, service.AuditActionCreate, service.AuditEntityAccount, createdAccount.ID, nil, auditAccountSnapshot(createdAccount))
+	}
 	// OpenAI APIKey 账号创建后异步探测上游 /v1/responses 能力。
 	// 探测失败不影响账号创建响应。
 	h.scheduleOpenAIResponsesProbe(createdAccount)
@@ -612,6 +621,10 @@ func (h *AccountHandler) Update(c *gin.Context) {
 	// 确定是否跳过混合渠道检查
 	skipCheck := req.ConfirmMixedChannelRisk != nil && *req.ConfirmMixedChannelRisk
 
+	var before *service.Account
+	if h.auditEnabled() {
+		before, _ = h.adminService.GetAccount(c.Request.Context(), accountID)
+	}
 	account, err := h.adminService.UpdateAccount(c.Request.Context(), accountID, &service.UpdateAccountInput{
 		Name:                  req.Name,
 		Notes:                 req.Notes,
@@ -645,6 +658,8 @@ func (h *AccountHandler) Update(c *gin.Context) {
 		return
 	}
 
+	h.recordAudit(c, service.AuditActionUpdate, service.AuditEntityAccount, accountID, auditAccountSnapshot(before), auditAccountSnapshot(account))
+
 	// OpenAI APIKey: credentials 修改后重新探测上游能力（base_url/api_key 可能变更）。
 	// 异步执行，探测失败不影响账号更新响应。
 	if len(req.Credentials) > 0 {
@@ -687,11 +702,16 @@ func (h *AccountHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	var before *service.Account
+	if h.auditEnabled() {
+		before, _ = h.adminService.GetAccount(c.Request.Context(), accountID)
+	}
 	err = h.adminService.DeleteAccount(c.Request.Context(), accountID)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
+	h.recordAudit(c, service.AuditActionDelete, service.AuditEntityAccount, accountID, auditAccountSnapshot(before), nil)
 
 	response.Success(c, gin.H{"message": "Account deleted successfully"})
 }
@@ -2403,6 +2423,51 @@ func (h *AccountHandler) BatchRefreshTier(c *gin.Context) {
 	response.Success(c, results)
 }
 
+// SetAccountHealthProbeService 挂载账号健康探测服务，不改变 handler 构造函数签名
+func (h *AccountHandler) SetAccountHealthProbeService(probe *service.AccountHealthProbeService) {
+	h.accountHealthProbe = probe
+}
+
+// SetUpstreamRateLimitTracker 挂载上游限流余量跟踪器，不改变 handler 构造函数签名
+func (h *AccountHandler) SetUpstreamRateLimitTracker(tracker *service.UpstreamRateLimitTracker) {
+	h.upstreamRateLimits = tracker
+}
+
+// SetAccountServerErrorTracker 挂载上游 5xx 降权跟踪器，不改变 handler 构造函数签名
+func (h *AccountHandler) SetAccountServerErrorTracker(tracker *service.AccountServerErrorTracker) {
+	h.serverErrors = tracker
+}
+
+// SetIdentityService 挂载身份指纹服务（加密导出/导入携带缓存指纹），不改变 handler 构造函数签名
+func (h *AccountHandler) SetIdentityService(identityService *service.IdentityService) {
+	h.identityService = identityService
+}
+
+// ListHealth 获取账号主动健康探测结果、上游限流余量及 5xx 降权状态
+// GET /api/v1/admin/accounts/health
+func (h *AccountHandler) ListHealth(c *gin.Context) {
+	statuses := h.accountHealthProbe.ListStatuses()
+	if statuses == nil {
+		statuses = []service.AccountHealthStatus{}
+	}
+	rateLimits := h.upstreamRateLimits.ListSnapshots()
+	if rateLimits == nil {
+		rateLimits = []service.UpstreamRateLimitSnapshot{}
+	}
+	penalties := h.serverErrors.ListPenalties()
+	if penalties == nil {
+		penalties = []service.AccountServerErrorPenalty{}
+	}
+	response.Success(c, gin.H{
+		"enabled":                        h.accountHealthProbe.Enabled(),
+		"statuses":                       statuses,
+		"rate_limits_enabled":            h.upstreamRateLimits.Enabled(),
+		"rate_limits":                    rateLimits,
+		"server_error_penalties_enabled": h.serverErrors.Enabled(),
+		"server_error_penalties":         penalties,
+	})
+}
+
 // GetAntigravityDefaultModelMapping 获取 Antigravity 平台的默认模型映射
 // GET /api/v1/admin/accounts/antigravity/default-model-mapping
 func (h *AccountHandler) GetAntigravityDefaultModelMapping(c *gin.Context) {
@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyCaptureHandler 处理 API Key 请求/响应抓取的管理接口
+type APIKeyCaptureHandler struct {
+	captureService *service.APIKeyCaptureService
+}
+
+// NewAPIKeyCaptureHandler 创建 API Key 抓取管理 handler
+func NewAPIKeyCaptureHandler(captureService *service.APIKeyCaptureService) *APIKeyCaptureHandler {
+	return &APIKeyCaptureHandler{captureService: captureService}
+}
+
+// EnableAPIKeyCaptureRequest 开启抓取请求
+type EnableAPIKeyCaptureRequest struct {
+	// Hours 抓取持续时长（小时），到期自动关闭
+	Hours int `json:"hours" binding:"required,min=1"`
+}
+
+func parseAPIKeyCaptureID(c *gin.Context) (int64, bool) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || keyID <= 0 {
+		response.BadRequest(c, "Invalid API key ID")
+		return 0, false
+	}
+	return keyID, true
+}
+
+// GetStatus 查询 Key 的抓取开关状态
+// GET /api/v1/admin/api-keys/:id/capture
+func (h *APIKeyCaptureHandler) GetStatus(c *gin.Context) {
+	keyID, ok := parseAPIKeyCaptureID(c)
+	if !ok {
+		return
+	}
+	status, err := h.captureService.Status(c.Request.Context(), keyID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// Enable 为 Key 开启抓取
+// POST /api/v1/admin/api-keys/:id/capture
+func (h *APIKeyCaptureHandler) Enable(c *gin.Context) {
+	keyID, ok := parseAPIKeyCaptureID(c)
+	if !ok {
+		return
+	}
+	var req EnableAPIKeyCaptureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	status, err := h.captureService.Enable(c.Request.Context(), keyID, req.Hours)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// Disable 立即关闭 Key 的抓取
+// DELETE /api/v1/admin/api-keys/:id/capture
+func (h *APIKeyCaptureHandler) Disable(c *gin.Context) {
+	keyID, ok := parseAPIKeyCaptureID(c)
+	if !ok {
+		return
+	}
+	status, err := h.captureService.Disable(c.Request.Context(), keyID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// ListCaptures 分页查询 Key 的抓取记录
+// GET /api/v1/admin/api-keys/:id/captures
+func (h *APIKeyCaptureHandler) ListCaptures(c *gin.Context) {
+	keyID, ok := parseAPIKeyCaptureID(c)
+	if !ok {
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize, SortOrder: pagination.SortOrderDesc}
+	items, result, err := h.captureService.List(c.Request.Context(), keyID, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	total := int64(0)
+	if result != nil {
+		total = result.Total
+	}
+	response.Paginated(c, items, total, page, pageSize)
+}
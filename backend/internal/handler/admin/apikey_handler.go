@@ -13,6 +13,8 @@ import (
 // AdminAPIKeyHandler handles admin API key management
 type AdminAPIKeyHandler struct {
 	adminService service.AdminService
+
+	auditRecorder
 }
 
 // NewAdminAPIKeyHandler creates a new admin API key handler
@@ -60,6 +62,8 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 	if resetKey != nil && req.GroupID == nil {
 		result.APIKey = resetKey
 	}
+	// 没有单 key 查询接口，审计只记录本次实际生效的管理字段
+	h.recordAudit(c, service.AuditActionUpdate, service.AuditEntityAPIKey, keyID, nil, req)
 
 	resp := struct {
 		APIKey                 *dto.APIKey `json:"api_key"`
@@ -0,0 +1,155 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogHandler 处理管理员操作审计日志的查询接口
+type AuditLogHandler struct {
+	auditLogService *service.AuditLogService
+}
+
+// NewAuditLogHandler 创建审计日志 handler
+func NewAuditLogHandler(auditLogService *service.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{auditLogService: auditLogService}
+}
+
+// List 分页查询审计日志，支持按实体类型 / 实体 ID / 时间范围过滤
+// GET /api/v1/admin/audit
+func (h *AuditLogHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := service.AuditLogFilter{
+		Pagination: pagination.PaginationParams{
+			Page:      page,
+			PageSize:  pageSize,
+			SortOrder: pagination.SortOrderDesc,
+		},
+		EntityType: c.Query("entity_type"),
+	}
+	if raw := strings.TrimSpace(c.Query("entity_id")); raw != "" {
+		entityID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || entityID <= 0 {
+			response.BadRequest(c, "Invalid entity_id")
+			return
+		}
+		filter.EntityID = &entityID
+	}
+	if raw := strings.TrimSpace(c.Query("from")); raw != "" {
+		t, _, err := parseContentModerationDate(raw)
+		if err != nil {
+			response.BadRequest(c, "Invalid from")
+			return
+		}
+		filter.From = &t
+	}
+	if raw := strings.TrimSpace(c.Query("to")); raw != "" {
+		t, dateOnly, err := parseContentModerationDate(raw)
+		if err != nil {
+			response.BadRequest(c, "Invalid to")
+			return
+		}
+		if dateOnly {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		filter.To = &t
+	}
+	items, pageResult, err := h.auditLogService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, items, pageResult.Total, pageResult.Page, pageResult.PageSize)
+}
+
+// auditRecorder 嵌入需要记录审计日志的 admin handler，通过 SetAuditLogService 注入（未注入时不记录）
+type auditRecorder struct {
+	auditLogService *service.AuditLogService
+}
+
+// SetAuditLogService 挂载审计日志服务，不改变 handler 构造函数签名
+func (r *auditRecorder) SetAuditLogService(auditLogService *service.AuditLogService) {
+	r.auditLogService = auditLogService
+}
+
+// recordAudit 记录一次管理操作：before/after 为变更前后的实体快照（创建时 before 为 nil，删除时 after 为 nil）。
+// 更新操作没有实际字段变化时不落库。
+func (r *auditRecorder) recordAudit(c *gin.Context, action, entityType string, entityID int64, before, after any) {
+	if r == nil || r.auditLogService == nil || c == nil {
+		return
+	}
+	diff := service.BuildAuditDiff(before, after)
+	if action == service.AuditActionUpdate && len(diff) == 0 {
+		return
+	}
+	entry := &service.AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Diff:       diff,
+		IP:         ip.GetClientIP(c),
+	}
+	if subject, ok := middleware.GetAuthSubjectFromContext(c); ok {
+		entry.ActorUserID = subject.UserID
+	}
+	r.auditLogService.Record(c.Request.Context(), entry)
+}
+
+// auditEnabled 用于在更新 / 删除前按需读取变更前快照，未启用审计时省掉这次查询
+func (r *auditRecorder) auditEnabled() bool {
+	return r != nil && r.auditLogService != nil
+}
+
+// auditAccountSnapshot 账号审计快照：只保留管理员可修改的字段，credentials 的敏感值由 diff 统一脱敏
+func auditAccountSnapshot(a *service.Account) map[string]any {
+	if a == nil {
+		return nil
+	}
+	return map[string]any{
+		"name":                  a.Name,
+		"notes":                 a.Notes,
+		"platform":              a.Platform,
+		"type":                  a.Type,
+		"credentials":           a.Credentials,
+		"extra":                 a.Extra,
+		"proxy_id":              a.ProxyID,
+		"concurrency":           a.Concurrency,
+		"priority":              a.Priority,
+		"rate_multiplier":       a.RateMultiplier,
+		"load_factor":           a.LoadFactor,
+		"status":                a.Status,
+		"schedulable":           a.Schedulable,
+		"group_ids":             a.GroupIDs,
+		"expires_at":            a.ExpiresAt,
+		"auto_pause_on_expired": a.AutoPauseOnExpired,
+	}
+}
+
+// auditProxySnapshot 代理审计快照（password 由 diff 统一脱敏）
+func auditProxySnapshot(p *service.Proxy) map[string]any {
+	if p == nil {
+		return nil
+	}
+	return map[string]any{
+		"name":             p.Name,
+		"protocol":         p.Protocol,
+		"host":             p.Host,
+		"port":             p.Port,
+		"username":         p.Username,
+		"password":         p.Password,
+		"status":           p.Status,
+		"expires_at":       p.ExpiresAt,
+		"fallback_mode":    p.FallbackMode,
+		"backup_proxy_id":  p.BackupProxyID,
+		"expiry_warn_days": p.ExpiryWarnDays,
+	}
+}
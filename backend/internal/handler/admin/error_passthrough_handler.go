@@ -1,7 +1,9 @@
 package admin
 
 import (
+	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/model"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
@@ -12,6 +14,8 @@ import (
 // ErrorPassthroughHandler 处理错误透传规则的 HTTP 请求
 type ErrorPassthroughHandler struct {
 	service *service.ErrorPassthroughService
+
+	auditRecorder
 }
 
 // NewErrorPassthroughHandler 创建错误透传规则处理器
@@ -34,6 +38,10 @@ type CreateErrorPassthroughRuleRequest struct {
 	CustomMessage   *string  `json:"custom_message"`
 	SkipMonitoring  *bool    `json:"skip_monitoring"`
 	Description     *string  `json:"description"`
+	// BodyRegex 响应体正则（RE2）；更新时传空字符串表示清除
+	BodyRegex *string `json:"body_regex"`
+	// HeaderMatchers 响应头匹配条件；更新时传空数组表示清除
+	HeaderMatchers []model.ErrorPassthroughHeaderMatcher `json:"header_matchers"`
 }
 
 // UpdateErrorPassthroughRuleRequest 更新规则请求（部分更新，所有字段可选）
@@ -51,6 +59,10 @@ type UpdateErrorPassthroughRuleRequest struct {
 	CustomMessage   *string  `json:"custom_message"`
 	SkipMonitoring  *bool    `json:"skip_monitoring"`
 	Description     *string  `json:"description"`
+	// BodyRegex 响应体正则（RE2）；更新时传空字符串表示清除
+	BodyRegex *string `json:"body_regex"`
+	// HeaderMatchers 响应头匹配条件；更新时传空数组表示清除
+	HeaderMatchers []model.ErrorPassthroughHeaderMatcher `json:"header_matchers"`
 }
 
 // List 获取所有规则
@@ -130,6 +142,8 @@ func (h *ErrorPassthroughHandler) Create(c *gin.Context) {
 	rule.ResponseCode = req.ResponseCode
 	rule.CustomMessage = req.CustomMessage
 	rule.Description = req.Description
+	rule.BodyRegex = normalizeErrorPassthroughBodyRegex(req.BodyRegex)
+	rule.HeaderMatchers = req.HeaderMatchers
 
 	// 确保切片不为 nil
 	if rule.ErrorCodes == nil {
@@ -141,6 +155,9 @@ func (h *ErrorPassthroughHandler) Create(c *gin.Context) {
 	if rule.Platforms == nil {
 		rule.Platforms = []string{}
 	}
+	if rule.HeaderMatchers == nil {
+		rule.HeaderMatchers = []model.ErrorPassthroughHeaderMatcher{}
+	}
 
 	created, err := h.service.Create(c.Request.Context(), rule)
 	if err != nil {
@@ -151,6 +168,7 @@ func (h *ErrorPassthroughHandler) Create(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
+	h.recordAudit(c, service.AuditActionCreate, service.AuditEntityErrorPassthroughRule, created.ID, nil, created)
 
 	response.Success(c, created)
 }
@@ -197,6 +215,8 @@ func (h *ErrorPassthroughHandler) Update(c *gin.Context) {
 		CustomMessage:   existing.CustomMessage,
 		SkipMonitoring:  existing.SkipMonitoring,
 		Description:     existing.Description,
+		BodyRegex:       existing.BodyRegex,
+		HeaderMatchers:  existing.HeaderMatchers,
 	}
 
 	// 应用请求中提供的更新
@@ -239,6 +259,12 @@ func (h *ErrorPassthroughHandler) Update(c *gin.Context) {
 	if req.SkipMonitoring != nil {
 		rule.SkipMonitoring = *req.SkipMonitoring
 	}
+	if req.BodyRegex != nil {
+		rule.BodyRegex = normalizeErrorPassthroughBodyRegex(req.BodyRegex)
+	}
+	if req.HeaderMatchers != nil {
+		rule.HeaderMatchers = req.HeaderMatchers
+	}
 
 	// 确保切片不为 nil
 	if rule.ErrorCodes == nil {
@@ -250,6 +276,9 @@ func (h *ErrorPassthroughHandler) Update(c *gin.Context) {
 	if rule.Platforms == nil {
 		rule.Platforms = []string{}
 	}
+	if rule.HeaderMatchers == nil {
+		rule.HeaderMatchers = []model.ErrorPassthroughHeaderMatcher{}
+	}
 
 	updated, err := h.service.Update(c.Request.Context(), rule)
 	if err != nil {
@@ -260,6 +289,7 @@ func (h *ErrorPassthroughHandler) Update(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
+	h.recordAudit(c, service.AuditActionUpdate, service.AuditEntityErrorPassthroughRule, id, existing, updated)
 
 	response.Success(c, updated)
 }
@@ -273,10 +303,66 @@ func (h *ErrorPassthroughHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	var before *model.ErrorPassthroughRule
+	if h.auditEnabled() {
+		before, _ = h.service.GetByID(c.Request.Context(), id)
+	}
 	if err := h.service.Delete(c.Request.Context(), id); err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
+	h.recordAudit(c, service.AuditActionDelete, service.AuditEntityErrorPassthroughRule, id, before, nil)
 
 	response.Success(c, gin.H{"message": "Rule deleted successfully"})
 }
+
+// normalizeErrorPassthroughBodyRegex 空白正则视为未配置
+func normalizeErrorPassthroughBodyRegex(v *string) *string {
+	if v == nil || strings.TrimSpace(*v) == "" {
+		return nil
+	}
+	return v
+}
+
+// DryRunErrorPassthroughRequest dry-run 测试请求：模拟一次上游错误响应
+type DryRunErrorPassthroughRequest struct {
+	Platform        string            `json:"platform" binding:"required"`
+	StatusCode      int               `json:"status_code" binding:"required"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	IncludeDisabled bool              `json:"include_disabled"`
+}
+
+// DryRun 使用当前规则集测试匹配结果，不产生副作用
+// POST /api/v1/admin/error-passthrough-rules/dry-run
+func (h *ErrorPassthroughHandler) DryRun(c *gin.Context) {
+	var req DryRunErrorPassthroughRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	headers := make(http.Header, len(req.Headers))
+	for name, value := range req.Headers {
+		headers.Add(name, value)
+	}
+
+	result := h.service.DryRun(service.ErrorPassthroughDryRunInput{
+		Platform:        req.Platform,
+		StatusCode:      req.StatusCode,
+		Headers:         headers,
+		Body:            []byte(req.Body),
+		IncludeDisabled: req.IncludeDisabled,
+	})
+	response.Success(c, result)
+}
+
+// Reload 强制从数据库重新加载规则并通知其他实例
+// POST /api/v1/admin/error-passthrough-rules/reload
+func (h *ErrorPassthroughHandler) Reload(c *gin.Context) {
+	if err := h.service.Reload(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Rules reloaded successfully"})
+}
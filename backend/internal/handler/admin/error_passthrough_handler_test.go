@@ -0,0 +1,153 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/model"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type errorPassthroughRepoStub struct {
+	rules []*model.ErrorPassthroughRule
+}
+
+func (r *errorPassthroughRepoStub) List(context.Context) ([]*model.ErrorPassthroughRule, error) {
+	return r.rules, nil
+}
+
+func (r *errorPassthroughRepoStub) GetByID(_ context.Context, id int64) (*model.ErrorPassthroughRule, error) {
+	for _, rule := range r.rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *errorPassthroughRepoStub) Create(_ context.Context, rule *model.ErrorPassthroughRule) (*model.ErrorPassthroughRule, error) {
+	rule.ID = int64(len(r.rules) + 1)
+	r.rules = append(r.rules, rule)
+	return rule, nil
+}
+
+func (r *errorPassthroughRepoStub) Update(_ context.Context, rule *model.ErrorPassthroughRule) (*model.ErrorPassthroughRule, error) {
+	for i, existing := range r.rules {
+		if existing.ID == rule.ID {
+			r.rules[i] = rule
+		}
+	}
+	return rule, nil
+}
+
+func (r *errorPassthroughRepoStub) Delete(context.Context, int64) error { return nil }
+
+func setupErrorPassthroughRouter(repo *errorPassthroughRepoStub) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewErrorPassthroughHandler(service.NewErrorPassthroughService(repo, nil))
+	router := gin.New()
+	router.POST("/api/v1/admin/error-passthrough-rules", h.Create)
+	router.POST("/api/v1/admin/error-passthrough-rules/dry-run", h.DryRun)
+	router.POST("/api/v1/admin/error-passthrough-rules/reload", h.Reload)
+	return router
+}
+
+func postErrorPassthroughJSON(t *testing.T, router *gin.Engine, path string, body any) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec, resp
+}
+
+func TestErrorPassthroughHandler_DryRunMatchesHeaderRule(t *testing.T) {
+	responseCode := 402
+	customMessage := "Billing limit reached"
+	repo := &errorPassthroughRepoStub{rules: []*model.ErrorPassthroughRule{{
+		ID:              3,
+		Name:            "billing header",
+		Enabled:         true,
+		MatchMode:       model.MatchModeAll,
+		ErrorCodes:      []int{429},
+		HeaderMatchers:  []model.ErrorPassthroughHeaderMatcher{{Name: "x-error-type", Regex: "^billing"}},
+		ResponseCode:    &responseCode,
+		CustomMessage:   &customMessage,
+		PassthroughCode: false,
+		PassthroughBody: false,
+	}}}
+	router := setupErrorPassthroughRouter(repo)
+
+	rec, resp := postErrorPassthroughJSON(t, router, "/api/v1/admin/error-passthrough-rules/dry-run", map[string]any{
+		"platform":    "openai",
+		"status_code": 429,
+		"headers":     map[string]string{"X-Error-Type": "billing_hard_limit"},
+		"body":        `{"error":{"message":"quota"}}`,
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	data := resp["data"].(map[string]any)
+	require.Equal(t, true, data["matched"])
+	require.Equal(t, float64(402), data["response_code"])
+	require.Equal(t, "Billing limit reached", data["message"])
+	require.Equal(t, float64(3), data["rule"].(map[string]any)["id"])
+
+	rec, resp = postErrorPassthroughJSON(t, router, "/api/v1/admin/error-passthrough-rules/dry-run", map[string]any{
+		"platform":    "openai",
+		"status_code": 429,
+		"body":        `{"error":{"message":"quota"}}`,
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, false, resp["data"].(map[string]any)["matched"])
+}
+
+func TestErrorPassthroughHandler_DryRunRequiresPlatformAndStatus(t *testing.T) {
+	router := setupErrorPassthroughRouter(&errorPassthroughRepoStub{})
+	rec, _ := postErrorPassthroughJSON(t, router, "/api/v1/admin/error-passthrough-rules/dry-run", map[string]any{
+		"body": "x",
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestErrorPassthroughHandler_CreateRejectsInvalidRegex(t *testing.T) {
+	repo := &errorPassthroughRepoStub{}
+	router := setupErrorPassthroughRouter(repo)
+
+	rec, resp := postErrorPassthroughJSON(t, router, "/api/v1/admin/error-passthrough-rules", map[string]any{
+		"name":       "bad regex",
+		"body_regex": "(unclosed",
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, resp["message"], "body_regex")
+	require.Empty(t, repo.rules)
+}
+
+func TestErrorPassthroughHandler_ReloadPicksUpRepoChanges(t *testing.T) {
+	repo := &errorPassthroughRepoStub{}
+	router := setupErrorPassthroughRouter(repo)
+	dryRun := map[string]any{"platform": "anthropic", "status_code": 529, "body": "overloaded"}
+
+	_, resp := postErrorPassthroughJSON(t, router, "/api/v1/admin/error-passthrough-rules/dry-run", dryRun)
+	require.Equal(t, false, resp["data"].(map[string]any)["matched"])
+
+	// 模拟直接写库：需要 reload 才会生效
+	repo.rules = append(repo.rules, &model.ErrorPassthroughRule{
+		ID: 1, Name: "overloaded", Enabled: true, ErrorCodes: []int{529}, MatchMode: model.MatchModeAny,
+		PassthroughCode: true, PassthroughBody: true,
+	})
+	rec, _ := postErrorPassthroughJSON(t, router, "/api/v1/admin/error-passthrough-rules/reload", map[string]any{})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	_, resp = postErrorPassthroughJSON(t, router, "/api/v1/admin/error-passthrough-rules/dry-run", dryRun)
+	require.Equal(t, true, resp["data"].(map[string]any)["matched"])
+}
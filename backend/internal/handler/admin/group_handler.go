@@ -20,6 +20,7 @@ type GroupHandler struct {
 	adminService         service.AdminService
 	dashboardService     *service.DashboardService
 	groupCapacityService *service.GroupCapacityService
+	billingCacheService  *service.BillingCacheService
 }
 
 type optionalLimitField struct {
@@ -105,6 +106,7 @@ type CreateGroupRequest struct {
 	ModelRouting        map[string][]int64 `json:"model_routing"`
 	ModelRoutingEnabled bool               `json:"model_routing_enabled"`
 	MCPXMLInject        *bool              `json:"mcp_xml_inject"`
+	PromptCacheInject   *bool              `json:"prompt_cache_inject"`
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes []string `json:"supported_model_scopes"`
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
@@ -116,6 +118,14 @@ type CreateGroupRequest struct {
 	ModelsListConfig            service.GroupModelsListConfig             `json:"models_list_config"`
 	// 分组 RPM 上限（0 = 不限制）
 	RPMLimit int `json:"rpm_limit"`
+	// 模型降级规则（请求模型无可用账号时按序尝试）
+	ModelFallbackConfig service.GroupModelFallbackConfig `json:"model_fallback_config"`
+	// OpenAI Responses instructions 注入配置（仅 openai 平台使用）
+	InstructionInjectionConfig service.GroupInstructionInjectionConfig `json:"instruction_injection_config"`
+	// 分组日/月消费上限（null/0 表示不限制）及周期边界时区（空串表示系统时区）
+	SpendCapDailyUSD   *float64 `json:"spend_cap_daily_usd"`
+	SpendCapMonthlyUSD *float64 `json:"spend_cap_monthly_usd"`
+	SpendCapTimezone   string   `json:"spend_cap_timezone"`
 	// 从指定分组复制账号（创建后自动绑定）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -146,6 +156,7 @@ type UpdateGroupRequest struct {
 	ModelRouting        map[string][]int64 `json:"model_routing"`
 	ModelRoutingEnabled *bool              `json:"model_routing_enabled"`
 	MCPXMLInject        *bool              `json:"mcp_xml_inject"`
+	PromptCacheInject   *bool              `json:"prompt_cache_inject"`
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes *[]string `json:"supported_model_scopes"`
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
@@ -157,6 +168,15 @@ type UpdateGroupRequest struct {
 	ModelsListConfig            *service.GroupModelsListConfig             `json:"models_list_config"`
 	// 分组 RPM 上限（0 = 不限制）；nil 表示未提供不改动
 	RPMLimit *int `json:"rpm_limit"`
+	// 模型降级规则；nil 表示未提供不改动
+	ModelFallbackConfig *service.GroupModelFallbackConfig `json:"model_fallback_config"`
+	// instructions 注入配置；nil 表示未提供不改动
+	InstructionInjectionConfig *service.GroupInstructionInjectionConfig `json:"instruction_injection_config"`
+	// 分组日/月消费上限：未提供不改动，null/0 表示取消上限
+	SpendCapDailyUSD   optionalLimitField `json:"spend_cap_daily_usd"`
+	SpendCapMonthlyUSD optionalLimitField `json:"spend_cap_monthly_usd"`
+	// 消费上限周期边界时区；nil 表示未提供不改动
+	SpendCapTimezone *string `json:"spend_cap_timezone"`
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -299,6 +319,7 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		ModelRouting:                    req.ModelRouting,
 		ModelRoutingEnabled:             req.ModelRoutingEnabled,
 		MCPXMLInject:                    req.MCPXMLInject,
+		PromptCacheInject:               req.PromptCacheInject,
 		SupportedModelScopes:            req.SupportedModelScopes,
 		AllowMessagesDispatch:           req.AllowMessagesDispatch,
 		RequireOAuthOnly:                req.RequireOAuthOnly,
@@ -307,6 +328,11 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		MessagesDispatchModelConfig:     req.MessagesDispatchModelConfig,
 		ModelsListConfig:                req.ModelsListConfig,
 		RPMLimit:                        req.RPMLimit,
+		ModelFallbackConfig:             req.ModelFallbackConfig,
+		InstructionInjectionConfig:      req.InstructionInjectionConfig,
+		SpendCapDailyUSD:                req.SpendCapDailyUSD,
+		SpendCapMonthlyUSD:              req.SpendCapMonthlyUSD,
+		SpendCapTimezone:                req.SpendCapTimezone,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -355,6 +381,7 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		ModelRouting:                    req.ModelRouting,
 		ModelRoutingEnabled:             req.ModelRoutingEnabled,
 		MCPXMLInject:                    req.MCPXMLInject,
+		PromptCacheInject:               req.PromptCacheInject,
 		SupportedModelScopes:            req.SupportedModelScopes,
 		AllowMessagesDispatch:           req.AllowMessagesDispatch,
 		RequireOAuthOnly:                req.RequireOAuthOnly,
@@ -363,6 +390,11 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		MessagesDispatchModelConfig:     req.MessagesDispatchModelConfig,
 		ModelsListConfig:                req.ModelsListConfig,
 		RPMLimit:                        req.RPMLimit,
+		ModelFallbackConfig:             req.ModelFallbackConfig,
+		InstructionInjectionConfig:      req.InstructionInjectionConfig,
+		SpendCapDailyUSD:                req.SpendCapDailyUSD.ToServiceInput(),
+		SpendCapMonthlyUSD:              req.SpendCapMonthlyUSD.ToServiceInput(),
+		SpendCapTimezone:                req.SpendCapTimezone,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetGroupInstructionInjectionRequest instructions 注入配置更新请求
+type SetGroupInstructionInjectionRequest struct {
+	Enabled bool `json:"enabled"`
+	// Mode prepend（默认）/ if_absent / replace
+	Mode string `json:"mode"`
+	// Text 注入文本，支持 {{key_name}} / {{group_name}} / {{date}}
+	Text string `json:"text"`
+}
+
+// GetInstructionInjection returns the group's instruction injection config.
+// GET /api/v1/admin/groups/:id/instruction-injection
+func (h *GroupHandler) GetInstructionInjection(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+	group, err := h.adminService.GetGroup(c.Request.Context(), groupID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, group.InstructionInjectionConfig)
+}
+
+// SetInstructionInjection replaces the group's instruction injection config.
+// PUT /api/v1/admin/groups/:id/instruction-injection
+func (h *GroupHandler) SetInstructionInjection(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+	var req SetGroupInstructionInjectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	group, err := h.adminService.SetGroupInstructionInjection(c.Request.Context(), groupID, service.GroupInstructionInjectionConfig{
+		Enabled: req.Enabled,
+		Mode:    req.Mode,
+		Text:    req.Text,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, group.InstructionInjectionConfig)
+}
+
+// ClearInstructionInjection disables and clears the group's instruction injection config.
+// DELETE /api/v1/admin/groups/:id/instruction-injection
+func (h *GroupHandler) ClearInstructionInjection(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+	if _, err := h.adminService.SetGroupInstructionInjection(c.Request.Context(), groupID, service.GroupInstructionInjectionConfig{}); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Instruction injection cleared"})
+}
@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetBillingCacheService 挂载计费缓存服务，用于分组消费上限的查询与临时放行
+func (h *GroupHandler) SetBillingCacheService(billingCacheService *service.BillingCacheService) {
+	h.billingCacheService = billingCacheService
+}
+
+// GrantGroupSpendCapOverrideRequest 临时放行请求
+type GrantGroupSpendCapOverrideRequest struct {
+	// DurationMinutes 放行时长（分钟），1 分钟 ~ 31 天
+	DurationMinutes int    `json:"duration_minutes" binding:"required,min=1"`
+	Reason          string `json:"reason"`
+}
+
+// GetSpendCap returns the group's current daily/monthly spend against its caps.
+// GET /api/v1/admin/groups/:id/spend-cap
+func (h *GroupHandler) GetSpendCap(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+	group, err := h.adminService.GetGroup(c.Request.Context(), groupID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if h.billingCacheService == nil {
+		response.ErrorFrom(c, service.ErrGroupSpendCapUnavailable)
+		return
+	}
+	status, err := h.billingCacheService.GetGroupSpendCapStatus(c.Request.Context(), group)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// GrantSpendCapOverride temporarily lifts the group's spend caps.
+// POST /api/v1/admin/groups/:id/spend-cap/override
+func (h *GroupHandler) GrantSpendCapOverride(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+	var req GrantGroupSpendCapOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if _, err := h.adminService.GetGroup(c.Request.Context(), groupID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if h.billingCacheService == nil {
+		response.ErrorFrom(c, service.ErrGroupSpendCapUnavailable)
+		return
+	}
+	var grantedBy int64
+	if subject, ok := middleware.GetAuthSubjectFromContext(c); ok {
+		grantedBy = subject.UserID
+	}
+	override, err := h.billingCacheService.GrantGroupSpendCapOverride(
+		c.Request.Context(), groupID, time.Duration(req.DurationMinutes)*time.Minute, req.Reason, grantedBy,
+	)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, override)
+}
+
+// RevokeSpendCapOverride removes an active spend cap override.
+// DELETE /api/v1/admin/groups/:id/spend-cap/override
+func (h *GroupHandler) RevokeSpendCapOverride(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid group ID")
+		return
+	}
+	if h.billingCacheService == nil {
+		response.ErrorFrom(c, service.ErrGroupSpendCapUnavailable)
+		return
+	}
+	if err := h.billingCacheService.RevokeGroupSpendCapOverride(c.Request.Context(), groupID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Spend cap override revoked"})
+}
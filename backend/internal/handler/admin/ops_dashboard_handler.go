@@ -351,3 +351,67 @@ func parseOpsQueryMode(c *gin.Context) service.OpsQueryMode {
 	}
 	return service.ParseOpsQueryMode(raw)
 }
+
+// GetDashboardDimensionMetrics returns latency percentiles / error rate / failover rate / token throughput
+// grouped by model, account or group.
+// GET /api/v1/admin/ops/dashboard/dimension-metrics?dimension=model&window=1h&limit=50
+func (h *OpsHandler) GetDashboardDimensionMetrics(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	filter := &service.OpsDimensionMetricsFilter{
+		Dimension: strings.TrimSpace(c.DefaultQuery("dimension", service.OpsDimensionModel)),
+		Window:    strings.TrimSpace(c.DefaultQuery("window", "1h")),
+	}
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	data, err := h.opsService.GetDimensionMetrics(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetDashboardTopOffenders ranks accounts by their recent error contribution.
+// GET /api/v1/admin/ops/dashboard/top-offenders?window=1h&limit=10
+func (h *OpsHandler) GetDashboardTopOffenders(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	limit := 0
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	data, err := h.opsService.GetTopOffenders(c.Request.Context(), strings.TrimSpace(c.DefaultQuery("window", "1h")), limit)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, data)
+}
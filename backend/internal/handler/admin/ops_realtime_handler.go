@@ -248,3 +248,21 @@ func (h *OpsHandler) GetRealtimeTrafficSummary(c *gin.Context) {
 		"timestamp": endTime,
 	})
 }
+
+// GetTTFTPercentiles returns per-model time-to-first-token percentiles for the current window.
+// GET /api/v1/admin/ops/ttft-percentiles
+//
+// Query params:
+// - model: optional exact model filter
+func (h *OpsHandler) GetTTFTPercentiles(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, h.opsService.GetTTFTPercentiles(c.Query("model")))
+}
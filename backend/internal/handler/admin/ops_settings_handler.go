@@ -180,6 +180,51 @@ func (h *OpsHandler) ResetRuntimeLogConfig(c *gin.Context) {
 	response.Success(c, updated)
 }
 
+// SetLogComponentLevelService 挂载组件日志级别服务，不改变 handler 构造函数签名
+func (h *OpsHandler) SetLogComponentLevelService(svc *service.LogComponentLevelService) {
+	h.logComponentLevels = svc
+}
+
+// ListLogComponentLevels returns per-component runtime log levels (shared across instances via Redis).
+// GET /api/v1/admin/ops/runtime/logging/components
+func (h *OpsHandler) ListLogComponentLevels(c *gin.Context) {
+	response.Success(c, h.logComponentLevels.List())
+}
+
+// UpdateLogComponentLevelRequest 单组件日志级别调整请求。
+type UpdateLogComponentLevelRequest struct {
+	Component          string `json:"component" binding:"required"`
+	Level              string `json:"level" binding:"required"`
+	RevertAfterMinutes int    `json:"revert_after_minutes"`
+}
+
+// UpdateLogComponentLevel sets a component log level at runtime, optionally reverting after N minutes.
+// PUT /api/v1/admin/ops/runtime/logging/components
+func (h *OpsHandler) UpdateLogComponentLevel(c *gin.Context) {
+	var req UpdateLogComponentLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	updated, err := h.logComponentLevels.Set(c.Request.Context(), req.Component, req.Level, req.RevertAfterMinutes)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// ResetLogComponentLevel restores a component to its startup default level.
+// POST /api/v1/admin/ops/runtime/logging/components/:component/reset
+func (h *OpsHandler) ResetLogComponentLevel(c *gin.Context) {
+	updated, err := h.logComponentLevels.Reset(c.Request.Context(), c.Param("component"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
 // GetAdvancedSettings returns Ops advanced settings (DB-backed).
 // GET /api/v1/admin/ops/advanced-settings
 func (h *OpsHandler) GetAdvancedSettings(c *gin.Context) {
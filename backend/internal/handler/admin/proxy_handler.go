@@ -16,6 +16,8 @@ import (
 // ProxyHandler handles admin proxy management
 type ProxyHandler struct {
 	adminService service.AdminService
+
+	auditRecorder
 }
 
 // NewProxyHandler creates a new admin proxy handler
@@ -163,6 +165,7 @@ func (h *ProxyHandler) Create(c *gin.Context) {
 		if err != nil {
 			return nil, err
 		}
+		h.recordAudit(c, service.AuditActionCreate, service.AuditEntityProxy, proxy.ID, nil, auditProxySnapshot(proxy))
 		return dto.ProxyFromServiceAdmin(proxy), nil
 	})
 }
@@ -187,6 +190,10 @@ func (h *ProxyHandler) Update(c *gin.Context) {
 		t := time.Unix(*req.ExpiresAt, 0).UTC()
 		expiresAt = &t
 	}
+	var before *service.Proxy
+	if h.auditEnabled() {
+		before, _ = h.adminService.GetProxy(c.Request.Context(), proxyID)
+	}
 	proxy, err := h.adminService.UpdateProxy(c.Request.Context(), proxyID, &service.UpdateProxyInput{
 		Name:           strings.TrimSpace(req.Name),
 		Protocol:       strings.TrimSpace(req.Protocol),
@@ -204,6 +211,7 @@ func (h *ProxyHandler) Update(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
+	h.recordAudit(c, service.AuditActionUpdate, service.AuditEntityProxy, proxyID, auditProxySnapshot(before), auditProxySnapshot(proxy))
 
 	response.Success(c, dto.ProxyFromServiceAdmin(proxy))
 }
@@ -217,11 +225,16 @@ func (h *ProxyHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	var before *service.Proxy
+	if h.auditEnabled() {
+		before, _ = h.adminService.GetProxy(c.Request.Context(), proxyID)
+	}
 	err = h.adminService.DeleteProxy(c.Request.Context(), proxyID)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
+	h.recordAudit(c, service.AuditActionDelete, service.AuditEntityProxy, proxyID, auditProxySnapshot(before), nil)
 
 	response.Success(c, gin.H{"message": "Proxy deleted successfully"})
 }
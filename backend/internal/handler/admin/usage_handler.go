@@ -25,6 +25,7 @@ type UsageHandler struct {
 	apiKeyService  *service.APIKeyService
 	adminService   service.AdminService
 	cleanupService *service.UsageCleanupService
+	retryService   *service.UsageRecordRetryService
 }
 
 // NewUsageHandler creates a new admin usage handler
@@ -42,6 +43,11 @@ func NewUsageHandler(
 	}
 }
 
+// SetUsageRecordRetryService 挂载用量记录重试队列（死信查看），不改变 handler 构造函数签名
+func (h *UsageHandler) SetUsageRecordRetryService(retry *service.UsageRecordRetryService) {
+	h.retryService = retry
+}
+
 // CreateUsageCleanupTaskRequest represents cleanup task creation request
 type CreateUsageCleanupTaskRequest struct {
 	StartDate   string  `json:"start_date"`
@@ -111,6 +117,11 @@ func (h *UsageHandler) List(c *gin.Context) {
 
 	model := c.Query("model")
 	billingMode := strings.TrimSpace(c.Query("billing_mode"))
+	scope := strings.TrimSpace(c.Query("scope"))
+	if scope != "" && !service.IsValidAPIKeyScope(scope) {
+		response.BadRequest(c, "Invalid scope, use chat, images, video or embeddings")
+		return
+	}
 
 	var requestType *int16
 	var stream *bool
@@ -181,6 +192,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		Stream:      stream,
 		BillingType: billingType,
 		BillingMode: billingMode,
+		Scope:       scope,
 		StartTime:   startTime,
 		EndTime:     endTime,
 		ExactTotal:  exactTotal,
@@ -242,6 +254,11 @@ func (h *UsageHandler) Stats(c *gin.Context) {
 
 	model := c.Query("model")
 	billingMode := strings.TrimSpace(c.Query("billing_mode"))
+	scope := strings.TrimSpace(c.Query("scope"))
+	if scope != "" && !service.IsValidAPIKeyScope(scope) {
+		response.BadRequest(c, "Invalid scope, use chat, images, video or embeddings")
+		return
+	}
 
 	var requestType *int16
 	var stream *bool
@@ -321,6 +338,7 @@ func (h *UsageHandler) Stats(c *gin.Context) {
 		Stream:      stream,
 		BillingType: billingType,
 		BillingMode: billingMode,
+		Scope:       scope,
 		StartTime:   &startTime,
 		EndTime:     &endTime,
 	}
@@ -452,6 +470,32 @@ func (h *UsageHandler) ListCleanupTasks(c *gin.Context) {
 	response.Paginated(c, out, result.Total, page, pageSize)
 }
 
+// ListRetryDeadLetters 列出超过最长重试时间仍未写入成功的用量记录（最新在前）
+// GET /api/v1/admin/usage/retry-dead-letters?limit=100
+func (h *UsageHandler) ListRetryDeadLetters(c *gin.Context) {
+	limit := 100
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		limit = min(parsed, 1000)
+	}
+	entries, err := h.retryService.ListDeadLetters(c.Request.Context(), limit)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if entries == nil {
+		entries = []service.UsageRecordRetryEntry{}
+	}
+	response.Success(c, gin.H{
+		"enabled": h.retryService.Enabled(),
+		"items":   entries,
+	})
+}
+
 // CreateCleanupTask handles creating a usage cleanup task
 // POST /api/v1/admin/usage/cleanup-tasks
 func (h *UsageHandler) CreateCleanupTask(c *gin.Context) {
@@ -30,13 +30,18 @@ func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
 
 // CreateAPIKeyRequest represents the create API key request payload
 type CreateAPIKeyRequest struct {
-	Name          string   `json:"name" binding:"required"`
-	GroupID       *int64   `json:"group_id"`        // nullable
-	CustomKey     *string  `json:"custom_key"`      // 可选的自定义key
-	IPWhitelist   []string `json:"ip_whitelist"`    // IP 白名单
-	IPBlacklist   []string `json:"ip_blacklist"`    // IP 黑名单
-	Quota         *float64 `json:"quota"`           // 配额限制 (USD)
-	ExpiresInDays *int     `json:"expires_in_days"` // 过期天数
+	Name                 string   `json:"name" binding:"required"`
+	GroupID              *int64   `json:"group_id"`               // nullable
+	CustomKey            *string  `json:"custom_key"`             // 可选的自定义key
+	IPWhitelist          []string `json:"ip_whitelist"`           // IP 白名单
+	IPBlacklist          []string `json:"ip_blacklist"`           // IP 黑名单
+	AccountLabels        []string `json:"account_labels"`         // 账号标签选择器
+	ResponseCacheEnabled bool     `json:"response_cache_enabled"` // 启用响应缓存
+	CostPreviewEnabled   bool     `json:"cost_preview_enabled"`   // 返回请求费用估算响应头
+	Scopes               []string `json:"scopes"`                 // 端点作用域（空表示全部）
+	AllowedModels        []string `json:"allowed_models"`         // 模型白名单（空表示全部）
+	Quota                *float64 `json:"quota"`                  // 配额限制 (USD)
+	ExpiresInDays        *int     `json:"expires_in_days"`        // 过期天数
 
 	// Rate limit fields (0 = unlimited)
 	RateLimit5h *float64 `json:"rate_limit_5h"`
@@ -46,14 +51,19 @@ type CreateAPIKeyRequest struct {
 
 // UpdateAPIKeyRequest represents the update API key request payload
 type UpdateAPIKeyRequest struct {
-	Name        string   `json:"name"`
-	GroupID     *int64   `json:"group_id"`
-	Status      string   `json:"status" binding:"omitempty,oneof=active inactive"`
-	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单
-	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单
-	Quota       *float64 `json:"quota"`        // 配额限制 (USD), 0=无限制
-	ExpiresAt   *string  `json:"expires_at"`   // 过期时间 (ISO 8601)
-	ResetQuota  *bool    `json:"reset_quota"`  // 重置已用配额
+	Name                 string   `json:"name"`
+	GroupID              *int64   `json:"group_id"`
+	Status               string   `json:"status" binding:"omitempty,oneof=active inactive"`
+	IPWhitelist          []string `json:"ip_whitelist"`           // IP 白名单
+	IPBlacklist          []string `json:"ip_blacklist"`           // IP 黑名单
+	AccountLabels        []string `json:"account_labels"`         // 账号标签选择器（不传则不修改）
+	ResponseCacheEnabled *bool    `json:"response_cache_enabled"` // 启用响应缓存（不传则不修改）
+	CostPreviewEnabled   *bool    `json:"cost_preview_enabled"`   // 返回请求费用估算响应头（不传则不修改）
+	Scopes               []string `json:"scopes"`                 // 端点作用域（不传则不修改，空数组恢复为全部）
+	AllowedModels        []string `json:"allowed_models"`         // 模型白名单（不传则不修改，空数组恢复为全部）
+	Quota                *float64 `json:"quota"`                  // 配额限制 (USD), 0=无限制
+	ExpiresAt            *string  `json:"expires_at"`             // 过期时间 (ISO 8601)
+	ResetQuota           *bool    `json:"reset_quota"`            // 重置已用配额
 
 	// Rate limit fields (nil = no change, 0 = unlimited)
 	RateLimit5h         *float64 `json:"rate_limit_5h"`
@@ -154,12 +164,17 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	}
 
 	svcReq := service.CreateAPIKeyRequest{
-		Name:          req.Name,
-		GroupID:       req.GroupID,
-		CustomKey:     req.CustomKey,
-		IPWhitelist:   req.IPWhitelist,
-		IPBlacklist:   req.IPBlacklist,
-		ExpiresInDays: req.ExpiresInDays,
+		Name:                 req.Name,
+		GroupID:              req.GroupID,
+		CustomKey:            req.CustomKey,
+		IPWhitelist:          req.IPWhitelist,
+		IPBlacklist:          req.IPBlacklist,
+		AccountLabels:        req.AccountLabels,
+		ResponseCacheEnabled: req.ResponseCacheEnabled,
+		CostPreviewEnabled:   req.CostPreviewEnabled,
+		Scopes:               req.Scopes,
+		AllowedModels:        req.AllowedModels,
+		ExpiresInDays:        req.ExpiresInDays,
 	}
 	if req.Quota != nil {
 		svcReq.Quota = *req.Quota
@@ -205,14 +220,19 @@ func (h *APIKeyHandler) Update(c *gin.Context) {
 	}
 
 	svcReq := service.UpdateAPIKeyRequest{
-		IPWhitelist:         req.IPWhitelist,
-		IPBlacklist:         req.IPBlacklist,
-		Quota:               req.Quota,
-		ResetQuota:          req.ResetQuota,
-		RateLimit5h:         req.RateLimit5h,
-		RateLimit1d:         req.RateLimit1d,
-		RateLimit7d:         req.RateLimit7d,
-		ResetRateLimitUsage: req.ResetRateLimitUsage,
+		IPWhitelist:          req.IPWhitelist,
+		IPBlacklist:          req.IPBlacklist,
+		AccountLabels:        req.AccountLabels,
+		ResponseCacheEnabled: req.ResponseCacheEnabled,
+		CostPreviewEnabled:   req.CostPreviewEnabled,
+		Scopes:               req.Scopes,
+		AllowedModels:        req.AllowedModels,
+		Quota:                req.Quota,
+		ResetQuota:           req.ResetQuota,
+		RateLimit5h:          req.RateLimit5h,
+		RateLimit1d:          req.RateLimit1d,
+		RateLimit7d:          req.RateLimit7d,
+		ResetRateLimitUsage:  req.ResetRateLimitUsage,
 	}
 	if req.Name != "" {
 		svcReq.Name = &req.Name
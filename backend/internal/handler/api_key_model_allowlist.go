@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"go.uber.org/zap"
+)
+
+// apiKeyModelDenied 校验 API Key 模型白名单（别名解析后）。不允许时记录日志并返回 true，
+// 由调用方按各自协议格式写 403。所有网关入口（Claude / OpenAI / Gemini，含 count_tokens、
+// embeddings、images 与 WebSocket）须在解析出请求模型后、选号与转发之前调用。
+func apiKeyModelDenied(apiKey *service.APIKey, model string, reqLog *zap.Logger) bool {
+	if apiKey.IsModelAllowed(model) {
+		return false
+	}
+	if reqLog != nil {
+		reqLog.Info("gateway.request_validation_failed",
+			zap.String("reason", "model_not_allowed_for_api_key"),
+			zap.String("model", model),
+			zap.String("resolved_model", service.ResolveAPIKeyAllowlistModel(model)),
+		)
+	}
+	return true
+}
+
+// apiKeyMappedModelDenied 渠道映射生效时，对映射后的目标模型再做一次白名单校验，
+// 避免通过渠道别名调用白名单外的上游模型。须在 ResolveChannelMappingAndRestrict 之后调用。
+func apiKeyMappedModelDenied(apiKey *service.APIKey, mapping service.ChannelMappingResult, reqLog *zap.Logger) bool {
+	if !mapping.Mapped {
+		return false
+	}
+	return apiKeyModelDenied(apiKey, mapping.MappedModel, reqLog)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// liveConcurrency 记录进程内所有尚未归还的并发槽位与等待计数。
+// 各网关 handler 各自持有 ConcurrencyHelper，但停机时需要统一归还，因此使用包级注册表。
+var liveConcurrency = newConcurrencyRegistry()
+
+// concurrencyRegistryShards 注册表分片数（2 的幂）。每个请求都会登记/注销，
+// 按槽位 ID 与等待计数 key 分片加锁，避免所有请求争用同一把锁。
+const concurrencyRegistryShards = 64
+
+type waitCounterKey struct {
+	slotType string
+	id       int64
+}
+
+// concurrencyRegistry 跟踪在途请求持有的 Redis 并发资源。
+// 正常路径下请求结束时自行释放并从注册表注销；优雅停机强制中断后，
+// 由 ReleaseLiveConcurrency 兜底归还，避免槽位与等待计数残留到 TTL 过期。
+type concurrencyRegistry struct {
+	nextID atomic.Uint64
+	shards [concurrencyRegistryShards]concurrencyRegistryShard
+}
+
+type concurrencyRegistryShard struct {
+	mu       sync.Mutex
+	releases map[uint64]func()
+	waits    map[waitCounterKey][]func()
+	// released 停机兜底已代为递减、但请求尚未执行 DecrementWaitCount 的次数；
+	// 迟到的递减逐次消耗，消耗完后该 key 恢复正常递减
+	released map[waitCounterKey]int
+}
+
+func newConcurrencyRegistry() *concurrencyRegistry {
+	r := &concurrencyRegistry{}
+	for i := range r.shards {
+		r.shards[i].releases = make(map[uint64]func())
+		r.shards[i].waits = make(map[waitCounterKey][]func())
+		r.shards[i].released = make(map[waitCounterKey]int)
+	}
+	return r
+}
+
+func (r *concurrencyRegistry) releaseShard(id uint64) *concurrencyRegistryShard {
+	return &r.shards[id&(concurrencyRegistryShards-1)]
+}
+
+func (r *concurrencyRegistry) waitShard(key waitCounterKey) *concurrencyRegistryShard {
+	h := uint64(key.id)
+	if key.slotType == "account" {
+		h = ^h
+	}
+	return &r.shards[h&(concurrencyRegistryShards-1)]
+}
+
+// trackRelease 登记一个槽位释放函数，返回注销函数（释放完成后调用）。
+func (r *concurrencyRegistry) trackRelease(release func()) func() {
+	id := r.nextID.Add(1)
+	shard := r.releaseShard(id)
+	shard.mu.Lock()
+	shard.releases[id] = release
+	shard.mu.Unlock()
+	return func() {
+		shard.mu.Lock()
+		delete(shard.releases, id)
+		shard.mu.Unlock()
+	}
+}
+
+// trackWait 登记一次成功的等待计数递增及其对应的递减操作。
+func (r *concurrencyRegistry) trackWait(key waitCounterKey, decrement func()) {
+	shard := r.waitShard(key)
+	shard.mu.Lock()
+	shard.waits[key] = append(shard.waits[key], decrement)
+	shard.mu.Unlock()
+}
+
+// untrackWait 注销一次等待计数，返回调用方是否仍需执行递减。
+// 停机兜底已归还的计数不再重复递减；未登记的计数（如测试直接调用）保持原有行为。
+func (r *concurrencyRegistry) untrackWait(key waitCounterKey) bool {
+	shard := r.waitShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	pending := shard.waits[key]
+	if len(pending) == 0 {
+		if n := shard.released[key]; n > 0 {
+			if n == 1 {
+				delete(shard.released, key)
+			} else {
+				shard.released[key] = n - 1
+			}
+			return false
+		}
+		return true
+	}
+	if len(pending) == 1 {
+		delete(shard.waits, key)
+	} else {
+		shard.waits[key] = pending[:len(pending)-1]
+	}
+	return true
+}
+
+// releaseAll 归还所有登记中的槽位与等待计数，返回归还条数。
+func (r *concurrencyRegistry) releaseAll() int {
+	var pending []func()
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mu.Lock()
+		for _, release := range shard.releases {
+			pending = append(pending, release)
+		}
+		for key, decrements := range shard.waits {
+			pending = append(pending, decrements...)
+			shard.released[key] += len(decrements)
+		}
+		shard.waits = make(map[waitCounterKey][]func())
+		shard.mu.Unlock()
+	}
+
+	// 槽位释放函数经 wrapReleaseOnDone 包装，执行时会自行注销且保证只执行一次
+	for _, fn := range pending {
+		fn()
+	}
+	return len(pending)
+}
+
+// ReleaseLiveConcurrency 归还所有在途请求仍持有的并发槽位与等待计数，返回归还条数。
+// 仅在优雅停机中断在途请求之后调用；调用后迟到的 DecrementWaitCount 不会重复递减。
+func ReleaseLiveConcurrency() int {
+	return liveConcurrency.releaseAll()
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyRegistry_ReleaseAllRunsPendingOnce(t *testing.T) {
+	r := newConcurrencyRegistry()
+	var released, decremented int
+
+	var untrack func()
+	release := func() {
+		released++
+		untrack()
+	}
+	untrack = r.trackRelease(release)
+	r.trackWait(waitCounterKey{slotType: "account", id: 1}, func() { decremented++ })
+
+	require.Equal(t, 2, r.releaseAll())
+	require.Equal(t, 1, released)
+	require.Equal(t, 1, decremented)
+
+	// 排空后迟到的递减不再执行，已注销的释放函数不会被重复调用
+	require.False(t, r.untrackWait(waitCounterKey{slotType: "account", id: 1}))
+	require.Zero(t, r.releaseAll())
+
+	// 兜底归还的计数消耗完后恢复正常递减，不会永久屏蔽该 key
+	require.True(t, r.untrackWait(waitCounterKey{slotType: "account", id: 1}))
+	r.trackWait(waitCounterKey{slotType: "account", id: 1}, func() {})
+	require.True(t, r.untrackWait(waitCounterKey{slotType: "account", id: 1}))
+}
+
+func TestConcurrencyRegistry_UntrackWaitBeforeDrain(t *testing.T) {
+	r := newConcurrencyRegistry()
+	key := waitCounterKey{slotType: "user", id: 7}
+	r.trackWait(key, func() { t.Fatal("decrement already handled by caller") })
+
+	require.True(t, r.untrackWait(key))
+	// 未登记的计数保持原有行为：调用方照常递减
+	require.True(t, r.untrackWait(key))
+	require.Zero(t, r.releaseAll())
+}
+
+func TestConcurrencyRegistry_ConcurrentTrackAcrossShards(t *testing.T) {
+	r := newConcurrencyRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			untrack := r.trackRelease(func() {})
+			key := waitCounterKey{slotType: "user", id: id % 7}
+			r.trackWait(key, func() {})
+			require.True(t, r.untrackWait(key))
+			untrack()
+		}(int64(i))
+	}
+	wg.Wait()
+	require.Zero(t, r.releaseAll())
+}
@@ -0,0 +1,43 @@
+package handler
+
+import "sync"
+
+// countTokensLimiter 为 count_tokens 请求提供按账号的进程内轻量并发限制。
+// 与正式请求的并发槽位相互独立：不进入等待队列，超过上限立即拒绝。
+type countTokensLimiter struct {
+	mu     sync.Mutex
+	active map[int64]int
+}
+
+func newCountTokensLimiter() *countTokensLimiter {
+	return &countTokensLimiter{active: make(map[int64]int)}
+}
+
+// TryAcquire 尝试为账号占用一个 count_tokens 槽位；limit<=0 表示不限制。
+func (l *countTokensLimiter) TryAcquire(accountID int64, limit int) (func(), bool) {
+	if l == nil || limit <= 0 {
+		return nil, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active == nil {
+		l.active = make(map[int64]int)
+	}
+	if l.active[accountID] >= limit {
+		return nil, false
+	}
+	l.active[accountID]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.active[accountID] <= 1 {
+				delete(l.active, accountID)
+				return
+			}
+			l.active[accountID]--
+		})
+	}, true
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountTokensLimiter_ZeroLimitAllowsRequests(t *testing.T) {
+	limiter := newCountTokensLimiter()
+
+	release, acquired := limiter.TryAcquire(1, 0)
+
+	require.True(t, acquired)
+	require.Nil(t, release)
+}
+
+func TestCountTokensLimiter_PerAccountLimitAndRelease(t *testing.T) {
+	limiter := newCountTokensLimiter()
+
+	release, acquired := limiter.TryAcquire(1, 1)
+	require.True(t, acquired)
+	require.NotNil(t, release)
+
+	_, acquired = limiter.TryAcquire(1, 1)
+	require.False(t, acquired, "同账号超过上限应立即拒绝")
+
+	otherRelease, acquired := limiter.TryAcquire(2, 1)
+	require.True(t, acquired, "不同账号的限额互不影响")
+	otherRelease()
+
+	release()
+	release() // 重复释放不应使计数变为负数
+	require.Empty(t, limiter.active)
+
+	again, acquired := limiter.TryAcquire(1, 1)
+	require.True(t, acquired)
+	again()
+}
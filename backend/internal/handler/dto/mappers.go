@@ -79,31 +79,36 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		return nil
 	}
 	out := &APIKey{
-		ID:            k.ID,
-		UserID:        k.UserID,
-		Key:           k.Key,
-		Name:          k.Name,
-		GroupID:       k.GroupID,
-		Status:        k.Status,
-		IPWhitelist:   k.IPWhitelist,
-		IPBlacklist:   k.IPBlacklist,
-		LastUsedAt:    k.LastUsedAt,
-		Quota:         k.Quota,
-		QuotaUsed:     k.QuotaUsed,
-		ExpiresAt:     k.ExpiresAt,
-		CreatedAt:     k.CreatedAt,
-		UpdatedAt:     k.UpdatedAt,
-		RateLimit5h:   k.RateLimit5h,
-		RateLimit1d:   k.RateLimit1d,
-		RateLimit7d:   k.RateLimit7d,
-		Usage5h:       k.EffectiveUsage5h(),
-		Usage1d:       k.EffectiveUsage1d(),
-		Usage7d:       k.EffectiveUsage7d(),
-		Window5hStart: k.Window5hStart,
-		Window1dStart: k.Window1dStart,
-		Window7dStart: k.Window7dStart,
-		User:          UserFromServiceShallow(k.User),
-		Group:         GroupFromServiceShallow(k.Group),
+		ID:                   k.ID,
+		UserID:               k.UserID,
+		Key:                  k.Key,
+		Name:                 k.Name,
+		GroupID:              k.GroupID,
+		Status:               k.Status,
+		IPWhitelist:          k.IPWhitelist,
+		IPBlacklist:          k.IPBlacklist,
+		AccountLabels:        k.AccountLabels,
+		ResponseCacheEnabled: k.ResponseCacheEnabled,
+		CostPreviewEnabled:   k.CostPreviewEnabled,
+		Scopes:               k.EffectiveScopes(),
+		AllowedModels:        k.AllowedModels,
+		LastUsedAt:           k.LastUsedAt,
+		Quota:                k.Quota,
+		QuotaUsed:            k.QuotaUsed,
+		ExpiresAt:            k.ExpiresAt,
+		CreatedAt:            k.CreatedAt,
+		UpdatedAt:            k.UpdatedAt,
+		RateLimit5h:          k.RateLimit5h,
+		RateLimit1d:          k.RateLimit1d,
+		RateLimit7d:          k.RateLimit7d,
+		Usage5h:              k.EffectiveUsage5h(),
+		Usage1d:              k.EffectiveUsage1d(),
+		Usage7d:              k.EffectiveUsage7d(),
+		Window5hStart:        k.Window5hStart,
+		Window1dStart:        k.Window1dStart,
+		Window7dStart:        k.Window7dStart,
+		User:                 UserFromServiceShallow(k.User),
+		Group:                GroupFromServiceShallow(k.Group),
 	}
 	if k.Window5hStart != nil && !service.IsWindowExpired(k.Window5hStart, service.RateLimitWindow5h) {
 		t := k.Window5hStart.Add(service.RateLimitWindow5h)
@@ -146,6 +151,12 @@ func GroupFromServiceAdmin(g *service.Group) *AdminGroup {
 		ModelRouting:                g.ModelRouting,
 		ModelRoutingEnabled:         g.ModelRoutingEnabled,
 		MCPXMLInject:                g.MCPXMLInject,
+		PromptCacheInject:           g.PromptCacheInject,
+		ModelFallbackConfig:         g.ModelFallbackConfig,
+		InstructionInjectionConfig:  g.InstructionInjectionConfig,
+		SpendCapDailyUSD:            g.SpendCapDailyUSD,
+		SpendCapMonthlyUSD:          g.SpendCapMonthlyUSD,
+		SpendCapTimezone:            g.SpendCapTimezone,
 		DefaultMappedModel:          g.DefaultMappedModel,
 		MessagesDispatchModelConfig: g.MessagesDispatchModelConfig,
 		ModelsListConfig:            g.ModelsListConfig,
@@ -623,6 +634,14 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		MediaType:             l.MediaType,
 		UserAgent:             l.UserAgent,
 		CacheTTLOverridden:    l.CacheTTLOverridden,
+		UsageEstimated:        l.UsageEstimated,
+		BillingUnverified:     l.BillingUnverified,
+		RequestBytes:          l.RequestBytes,
+		ResponseBytes:         l.ResponseBytes,
+		ResponseCacheHit:      l.ResponseCacheHit,
+		PricingUnavailable:    l.PricingUnavailable,
+		EstimatedCost:         l.EstimatedCost,
+		CostOverage:           l.CostOverage,
 		BillingMode:           l.BillingMode,
 		CreatedAt:             l.CreatedAt,
 		User:                  UserFromServiceShallow(l.User),
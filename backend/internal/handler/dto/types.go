@@ -49,20 +49,29 @@ type AdminUser struct {
 }
 
 type APIKey struct {
-	ID          int64      `json:"id"`
-	UserID      int64      `json:"user_id"`
-	Key         string     `json:"key"`
-	Name        string     `json:"name"`
-	GroupID     *int64     `json:"group_id"`
-	Status      string     `json:"status"`
-	IPWhitelist []string   `json:"ip_whitelist"`
-	IPBlacklist []string   `json:"ip_blacklist"`
-	LastUsedAt  *time.Time `json:"last_used_at"`
-	Quota       float64    `json:"quota"`      // Quota limit in USD (0 = unlimited)
-	QuotaUsed   float64    `json:"quota_used"` // Used quota amount in USD
-	ExpiresAt   *time.Time `json:"expires_at"` // Expiration time (nil = never expires)
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID            int64    `json:"id"`
+	UserID        int64    `json:"user_id"`
+	Key           string   `json:"key"`
+	Name          string   `json:"name"`
+	GroupID       *int64   `json:"group_id"`
+	Status        string   `json:"status"`
+	IPWhitelist   []string `json:"ip_whitelist"`
+	IPBlacklist   []string `json:"ip_blacklist"`
+	AccountLabels []string `json:"account_labels"`
+	// ResponseCacheEnabled 对确定性（temperature=0）非流式请求启用响应缓存
+	ResponseCacheEnabled bool `json:"response_cache_enabled"`
+	// CostPreviewEnabled 在响应头 X-Estimated-Cost 中返回请求前的费用估算
+	CostPreviewEnabled bool `json:"cost_preview_enabled"`
+	// Scopes 可调用的端点作用域（历史 Key 未设置时为全部作用域）
+	Scopes []string `json:"scopes"`
+	// AllowedModels 可调用的模型白名单（为空表示全部模型）
+	AllowedModels []string   `json:"allowed_models"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	Quota         float64    `json:"quota"`      // Quota limit in USD (0 = unlimited)
+	QuotaUsed     float64    `json:"quota_used"` // Used quota amount in USD
+	ExpiresAt     *time.Time `json:"expires_at"` // Expiration time (nil = never expires)
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 
 	// Rate limit fields
 	RateLimit5h   float64    `json:"rate_limit_5h"`
@@ -136,6 +145,20 @@ type AdminGroup struct {
 	// MCP XML 协议注入（仅 antigravity 平台使用）
 	MCPXMLInject bool `json:"mcp_xml_inject"`
 
+	// 自动 prompt caching 断点注入（仅 anthropic 平台使用）
+	PromptCacheInject bool `json:"prompt_cache_inject"`
+
+	// 模型降级规则（请求模型无可用账号时按序尝试）
+	ModelFallbackConfig domain.GroupModelFallbackConfig `json:"model_fallback_config"`
+
+	// OpenAI Responses instructions 注入配置（仅 openai 平台使用）
+	InstructionInjectionConfig domain.GroupInstructionInjectionConfig `json:"instruction_injection_config"`
+
+	// 分组日/月消费上限（null 表示不限制）及周期边界时区
+	SpendCapDailyUSD   *float64 `json:"spend_cap_daily_usd"`
+	SpendCapMonthlyUSD *float64 `json:"spend_cap_monthly_usd"`
+	SpendCapTimezone   string   `json:"spend_cap_timezone"`
+
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	DefaultMappedModel          string                                   `json:"default_mapped_model"`
 	MessagesDispatchModelConfig domain.OpenAIMessagesDispatchModelConfig `json:"messages_dispatch_model_config"`
@@ -484,6 +507,25 @@ type UsageLog struct {
 	// Cache TTL Override 标记
 	CacheTTLOverridden bool `json:"cache_ttl_overridden"`
 
+	// UsageEstimated 标记 token 用量为估算值
+	UsageEstimated bool `json:"usage_estimated"`
+
+	// BillingUnverified 标记请求在计费降级模式下放行（未完成计费资格校验）
+	BillingUnverified bool `json:"billing_unverified"`
+
+	// RequestBytes / ResponseBytes 原始请求与响应字节数
+	RequestBytes  *int64 `json:"request_bytes"`
+	ResponseBytes *int64 `json:"response_bytes"`
+
+	// ResponseCacheHit 标记响应由响应缓存直接返回（未请求上游、不计费）
+	ResponseCacheHit bool `json:"response_cache_hit"`
+	// PricingUnavailable 模型没有可用价格，费用字段无意义（未计费）
+	PricingUnavailable bool `json:"pricing_unavailable"`
+	// EstimatedCost 请求前估算费用（未估算时为 null）
+	EstimatedCost *float64 `json:"estimated_cost"`
+	// CostOverage 实际费用超出估算费用的配置倍数
+	CostOverage bool `json:"cost_overage"`
+
 	// BillingMode 计费模式：token/image
 	BillingMode *string `json:"billing_mode,omitempty"`
 
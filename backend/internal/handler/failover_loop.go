@@ -82,6 +82,7 @@ func (s *FailoverState) HandleFailoverError(
 		logger.FromContext(ctx).Warn("gateway.failover_same_account_retry",
 			zap.Int64("account_id", accountID),
 			zap.Int("upstream_status", failoverErr.StatusCode),
+			zap.String("upstream_request_id", failoverErr.UpstreamRequestID),
 			zap.Int("same_account_retry_count", s.SameAccountRetryCount[accountID]),
 			zap.Int("same_account_retry_max", maxSameAccountRetries),
 		)
@@ -109,6 +110,7 @@ func (s *FailoverState) HandleFailoverError(
 	logger.FromContext(ctx).Warn("gateway.failover_switch_account",
 		zap.Int64("account_id", accountID),
 		zap.Int("upstream_status", failoverErr.StatusCode),
+		zap.String("upstream_request_id", failoverErr.UpstreamRequestID),
 		zap.Int("switch_count", s.SwitchCount),
 		zap.Int("max_switches", s.MaxSwitches),
 	)
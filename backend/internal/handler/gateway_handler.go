@@ -49,8 +49,11 @@ type GatewayHandler struct {
 	usageRecordWorkerPool     *service.UsageRecordWorkerPool
 	errorPassthroughService   *service.ErrorPassthroughService
 	contentModerationService  *service.ContentModerationService
+	responseCacheService      *service.GatewayResponseCacheService
+	requestCostService        *service.RequestCostService
 	concurrencyHelper         *ConcurrencyHelper
 	userMsgQueueHelper        *UserMsgQueueHelper
+	countTokensLimiter        *countTokensLimiter
 	maxAccountSwitches        int
 	maxAccountSwitchesGemini  int
 	cfg                       *config.Config
@@ -71,14 +74,18 @@ func NewGatewayHandler(
 	errorPassthroughService *service.ErrorPassthroughService,
 	contentModerationService *service.ContentModerationService,
 	userMsgQueueService *service.UserMessageQueueService,
+	responseCacheService *service.GatewayResponseCacheService,
+	requestCostService *service.RequestCostService,
 	cfg *config.Config,
 	settingService *service.SettingService,
 ) *GatewayHandler {
 	pingInterval := time.Duration(0)
+	pingFormat := SSEPingFormatClaude
 	maxAccountSwitches := 10
 	maxAccountSwitchesGemini := 3
 	if cfg != nil {
-		pingInterval = time.Duration(cfg.Concurrency.PingInterval) * time.Second
+		pingInterval = time.Duration(cfg.Concurrency.Claude.ResolvedPingInterval(cfg.Concurrency.PingInterval)) * time.Second
+		pingFormat = resolveSSEPingFormat(cfg.Concurrency.Claude.PingFormat, SSEPingFormatClaude)
 		if cfg.Gateway.MaxAccountSwitches > 0 {
 			maxAccountSwitches = cfg.Gateway.MaxAccountSwitches
 		}
@@ -90,7 +97,12 @@ func NewGatewayHandler(
 	// 初始化用户消息串行队列 helper
 	var umqHelper *UserMsgQueueHelper
 	if userMsgQueueService != nil && cfg != nil {
-		umqHelper = NewUserMsgQueueHelper(userMsgQueueService, SSEPingFormatClaude, pingInterval)
+		umqHelper = NewUserMsgQueueHelper(userMsgQueueService, pingFormat, pingInterval)
+	}
+
+	concurrencyHelper := NewConcurrencyHelper(concurrencyService, pingFormat, pingInterval)
+	if cfg != nil {
+		concurrencyHelper.SetQueuedNotice(cfg.Concurrency.QueuedNotice)
 	}
 
 	return &GatewayHandler{
@@ -104,8 +116,11 @@ func NewGatewayHandler(
 		usageRecordWorkerPool:     usageRecordWorkerPool,
 		errorPassthroughService:   errorPassthroughService,
 		contentModerationService:  contentModerationService,
-		concurrencyHelper:         NewConcurrencyHelper(concurrencyService, SSEPingFormatClaude, pingInterval),
+		responseCacheService:      responseCacheService,
+		requestCostService:        requestCostService,
+		concurrencyHelper:         concurrencyHelper,
 		userMsgQueueHelper:        umqHelper,
+		countTokensLimiter:        newCountTokensLimiter(),
 		maxAccountSwitches:        maxAccountSwitches,
 		maxAccountSwitchesGemini:  maxAccountSwitchesGemini,
 		cfg:                       cfg,
@@ -153,6 +168,12 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	maxCost, body, rejection := extractRequestMaxCost(c, body)
+	if rejection != nil {
+		h.errorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
+
 	setOpsRequestContext(c, "", false)
 
 	bodyRef := service.NewRequestBodyRef(body)
@@ -195,12 +216,28 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
 		return
 	}
+	if apiKeyModelDenied(apiKey, reqModel, reqLog) || apiKeyMappedModelDenied(apiKey, channelMapping, reqLog) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
 
 	if decision := h.checkContentModeration(c, reqLog, apiKey, subject, service.ContentModerationProtocolAnthropicMessages, reqModel, body); decision != nil && decision.Blocked {
 		h.errorResponse(c, contentModerationStatus(decision), contentModerationErrorCode(decision), decision.Message)
 		return
 	}
 
+	if rejection := checkRequestCost(c, h.requestCostService, apiKey, channelMapping.EstimateBillingModel(reqModel), body, maxCost, reqLog); rejection != nil {
+		h.errorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
+
+	// 响应缓存：命中时直接返回缓存内容，不再选号、不访问上游、不计费
+	responseCache, handled := beginResponseCache(c, h.responseCacheService, service.ResponseCacheScopeAnthropicMessages, apiKey, body, reqModel, reqLog)
+	if handled {
+		return
+	}
+	defer responseCache.Release()
+
 	// Track if we've started streaming (for error handling)
 	streamStarted := false
 
@@ -208,6 +245,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
 	}
+	h.concurrencyHelper.BindSSEPingFormat(c)
 
 	// 获取订阅信息（可能为nil）- 提前获取用于后续检查
 	subscription, _ := middleware2.GetSubscriptionFromContext(c)
@@ -245,7 +283,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		UserAgent: c.GetHeader("User-Agent"),
 		APIKeyID:  apiKey.ID,
 	}
-	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
+	sessionHash := h.applySessionAffinity(c, parsedReq)
 
 	// [DEBUG-STICKY] 打印会话 hash 生成结果
 	reqLog.Info("sticky.session_hash_generated",
@@ -288,7 +326,24 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	// 判断是否真的绑定了粘性会话：有 sessionKey 且已经绑定到某个账号
 	hasBoundSession := sessionKey != "" && sessionBoundAccountID > 0
 
+	modelFallback := newModelFallbackState(apiKey, reqModel)
+
 	if platform == service.PlatformGemini {
+		// URL 附件与账号无关，在 failover 循环前下载内联一次，切换账号时不重复下载
+		if h.geminiCompatService != nil {
+			inlined, err := h.geminiCompatService.InlineClaudeAttachmentURLs(c.Request.Context(), body)
+			if err != nil {
+				if service.IsClaudeAttachmentError(err) {
+					h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+				} else {
+					reqLog.Error("gateway.attachment_inline_failed", zap.Error(err))
+					h.errorResponse(c, http.StatusInternalServerError, "api_error", "Failed to prepare attachments")
+				}
+				return
+			}
+			body = inlined
+		}
+
 		fs := NewFailoverState(h.maxAccountSwitchesGemini, hasBoundSession)
 
 		// 单账号分组提前设置 SingleAccountRetry 标记，让 Service 层首次 503 就不设模型限流标记。
@@ -302,6 +357,20 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			selection, err := h.gatewayService.SelectAccountWithLoadAwareness(c.Request.Context(), apiKey.GroupID, sessionKey, reqModel, fs.FailedAccountIDs, "", int64(0)) // Gemini 不使用会话限制
 			if err != nil {
 				if len(fs.FailedAccountIDs) == 0 {
+					// 分组模型降级：当前模型无可用账号时，按分组规则切换到下一个降级模型重新选号
+					if fallbackModel, ok := modelFallback.Next(); ok {
+						reqLog.Info("gateway.model_fallback",
+							zap.String("from_model", reqModel),
+							zap.String("fallback_model", fallbackModel),
+							zap.Error(err),
+						)
+						body = modelFallback.switchTo(c, body, fallbackModel)
+						parsedReq.Model = fallbackModel
+						reqModel = fallbackModel
+						channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, fallbackModel)
+						modelFallback.maskChannelMapping(channelMapping)
+						continue
+					}
 					cls := classifyNoAccountErrorFromGin(c, h.gatewayService, apiKey, reqModel, reqModel, service.PlatformGemini)
 					if !cls.ModelNotFound {
 						markOpsRoutingCapacityLimitedIfNoAvailable(c, err)
@@ -374,6 +443,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				if err != nil {
 					reqLog.Warn("gateway.account_wait_counter_increment_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 				} else if !canWait {
+					recordOpsSlotAcquire(c, "account", slotAcquirePathQueueFull, 0)
 					reqLog.Info("gateway.account_wait_queue_full",
 						zap.Int64("account_id", account.ID),
 						zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
@@ -410,6 +480,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				if err := h.gatewayService.BindStickySession(c.Request.Context(), apiKey.GroupID, sessionKey, account.ID); err != nil {
 					reqLog.Warn("gateway.bind_sticky_session_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 				}
+			} else {
+				recordOpsSlotAcquire(c, "account", slotAcquirePathFast, 0)
 			}
 			// 账号槽位/等待计数需要在超时或断开时安全回收
 			accountReleaseFunc = wrapReleaseOnDone(c.Request.Context(), accountReleaseFunc)
@@ -443,6 +515,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			if err != nil {
 				var failoverErr *service.UpstreamFailoverError
 				if errors.As(err, &failoverErr) {
+					h.gatewayService.ReportAccountRoutingResult(account.ID, false)
 					// 流式内容已写入客户端，无法撤销，禁止 failover 以防止流拼接腐化
 					if c.Writer.Size() != writerSizeBeforeForward {
 						h.handleFailoverExhausted(c, failoverErr, service.PlatformGemini, true)
@@ -486,6 +559,11 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				return
 			}
 
+			h.gatewayService.ReportAccountRoutingResult(account.ID, true)
+			if upstreamLatencyMs, ok := getContextInt64(c, service.OpsUpstreamLatencyMsKey); ok {
+				h.gatewayService.ReportAccountUpstreamLatency(account.ID, upstreamLatencyMs)
+			}
+
 			// RPM 计数递增（Forward 成功后）
 			// 注意：TOCTOU 竞态是已知且可接受的设计权衡，与 WindowCost 一致的 soft-limit 模式。
 			// 在高并发下可能短暂超出 RPM 限制，但不会导致请求失败。
@@ -519,7 +597,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
 			// ForceCacheBilling 提前拍成标量，避免 worker 闭包保活 failover 状态里的响应体。
 			forceCacheBilling := fs.ForceCacheBilling
+			responseCache.Commit(account.ID, result.Model)
 			quotaPlatform := service.QuotaPlatform(c.Request.Context(), apiKey)
+			fallbackFromModel := modelFallback.FallbackFromModel()
 			h.submitUsageRecordTask(c.Request.Context(), func(ctx context.Context) {
 				if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 					Result:             result,
@@ -535,6 +615,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					RequestPayloadHash: requestPayloadHash,
 					ForceCacheBilling:  forceCacheBilling,
 					APIKeyService:      h.apiKeyService,
+					FallbackFromModel:  fallbackFromModel,
 					ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
 				}); err != nil {
 					logger.L().With(
@@ -587,6 +668,20 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			selection, err := h.gatewayService.SelectAccountWithLoadAwareness(c.Request.Context(), currentAPIKey.GroupID, sessionKey, reqModel, fs.FailedAccountIDs, parsedReq.MetadataUserID, subject.UserID)
 			if err != nil {
 				if len(fs.FailedAccountIDs) == 0 {
+					// 分组模型降级：当前模型无可用账号时，按分组规则切换到下一个降级模型重新选号
+					if fallbackModel, ok := modelFallback.Next(); ok {
+						reqLog.Info("gateway.model_fallback",
+							zap.String("from_model", reqModel),
+							zap.String("fallback_model", fallbackModel),
+							zap.Error(err),
+						)
+						body = modelFallback.switchTo(c, body, fallbackModel)
+						parsedReq.Model = fallbackModel
+						reqModel = fallbackModel
+						channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), currentAPIKey.GroupID, fallbackModel)
+						modelFallback.maskChannelMapping(channelMapping)
+						continue
+					}
 					cls := classifyNoAccountErrorFromGin(c, h.gatewayService, currentAPIKey, reqModel, reqModel, platform)
 					if !cls.ModelNotFound {
 						markOpsRoutingCapacityLimitedIfNoAvailable(c, err)
@@ -670,6 +765,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				if err != nil {
 					reqLog.Warn("gateway.account_wait_counter_increment_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 				} else if !canWait {
+					recordOpsSlotAcquire(c, "account", slotAcquirePathQueueFull, 0)
 					reqLog.Info("gateway.account_wait_queue_full",
 						zap.Int64("account_id", account.ID),
 						zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
@@ -710,6 +806,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				if err := h.gatewayService.BindStickySession(c.Request.Context(), currentAPIKey.GroupID, sessionKey, account.ID); err != nil {
 					reqLog.Warn("gateway.bind_sticky_session_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 				}
+			} else {
+				recordOpsSlotAcquire(c, "account", slotAcquirePathFast, 0)
 			}
 			// 账号槽位/等待计数需要在超时或断开时安全回收
 			accountReleaseFunc = wrapReleaseOnDone(c.Request.Context(), accountReleaseFunc)
@@ -863,6 +961,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				}
 				var failoverErr *service.UpstreamFailoverError
 				if errors.As(err, &failoverErr) {
+					h.gatewayService.ReportAccountRoutingResult(account.ID, false)
 					// 流式内容已写入客户端，无法撤销，禁止 failover 以防止流拼接腐化
 					if c.Writer.Size() != writerSizeBeforeForward {
 						h.handleFailoverExhausted(c, failoverErr, account.Platform, true)
@@ -906,6 +1005,11 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				return
 			}
 
+			h.gatewayService.ReportAccountRoutingResult(account.ID, true)
+			if upstreamLatencyMs, ok := getContextInt64(c, service.OpsUpstreamLatencyMsKey); ok {
+				h.gatewayService.ReportAccountUpstreamLatency(account.ID, upstreamLatencyMs)
+			}
+
 			// RPM 计数递增（Forward 成功后）
 			// 注意：TOCTOU 竞态是已知且可接受的设计权衡，与 WindowCost 一致的 soft-limit 模式。
 			// 在高并发下可能短暂超出 RPM 限制，但不会导致请求失败。
@@ -949,7 +1053,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
 			// ForceCacheBilling 提前拍成标量，避免 worker 闭包保活 failover 状态里的响应体。
 			forceCacheBilling := fs.ForceCacheBilling
+			responseCache.Commit(account.ID, result.Model)
 			quotaPlatform := service.QuotaPlatform(c.Request.Context(), currentAPIKey)
+			fallbackFromModel := modelFallback.FallbackFromModel()
 			h.submitUsageRecordTask(c.Request.Context(), func(ctx context.Context) {
 				if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 					Result:             result,
@@ -965,6 +1071,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					RequestPayloadHash: requestPayloadHash,
 					ForceCacheBilling:  forceCacheBilling,
 					APIKeyService:      h.apiKeyService,
+					FallbackFromModel:  fallbackFromModel,
 					ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
 				}); err != nil {
 					logger.L().With(
@@ -1314,6 +1421,7 @@ func (h *GatewayHandler) usageQuotaLimited(c *gin.Context, ctx context.Context,
 		"mode":    "quota_limited",
 		"isValid": apiKey.Status == service.StatusAPIKeyActive || apiKey.Status == service.StatusAPIKeyQuotaExhausted || apiKey.Status == service.StatusAPIKeyExpired,
 		"status":  apiKey.Status,
+		"scopes":  apiKey.EffectiveScopes(),
 	}
 
 	// 总额度信息
@@ -1410,6 +1518,7 @@ func (h *GatewayHandler) usageUnrestricted(c *gin.Context, ctx context.Context,
 			"isValid":  true,
 			"planName": apiKey.Group.Name,
 			"unit":     "USD",
+			"scopes":   apiKey.EffectiveScopes(),
 		}
 
 		// 订阅信息可能不在 context 中（/v1/usage 路径跳过了中间件的计费检查）
@@ -1455,6 +1564,7 @@ func (h *GatewayHandler) usageUnrestricted(c *gin.Context, ctx context.Context,
 		"remaining": latestUser.Balance,
 		"unit":      "USD",
 		"balance":   latestUser.Balance,
+		"scopes":    apiKey.EffectiveScopes(),
 	}
 	if usageData != nil {
 		resp["usage"] = usageData
@@ -1524,6 +1634,7 @@ func (h *GatewayHandler) handleConcurrencyError(c *gin.Context, err error, slotT
 }
 
 func (h *GatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError, platform string, streamStarted bool) {
+	service.SetUpstreamRequestIDHeader(c, h.cfg, failoverErr.UpstreamRequestID)
 	statusCode := failoverErr.StatusCode
 	responseBody := failoverErr.ResponseBody
 	if service.IsOpenAISilentRefusalErrorBody(responseBody) {
@@ -1534,7 +1645,7 @@ func (h *GatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *se
 
 	// 先检查透传规则
 	if h.errorPassthroughService != nil && len(responseBody) > 0 {
-		if rule := h.errorPassthroughService.MatchRule(platform, statusCode, responseBody); rule != nil {
+		if rule := h.errorPassthroughService.MatchRuleWithHeaders(platform, statusCode, failoverErr.ResponseHeaders, responseBody); rule != nil {
 			// 确定响应状态码
 			respCode := statusCode
 			if !rule.PassthroughCode && rule.ResponseCode != nil {
@@ -1560,6 +1671,16 @@ func (h *GatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *se
 	upstreamMsg := service.ExtractUpstreamErrorMessage(responseBody)
 	service.SetOpsUpstreamError(c, statusCode, upstreamMsg, "")
 
+	// Gemini 上游错误翻译为 Claude 错误类型，限流时带上 Retry-After
+	if platform == service.PlatformGemini && service.IsGeminiErrorBody(responseBody) {
+		translated := service.TranslateGeminiErrorToClaude(statusCode, responseBody)
+		if translated.RetryAfterSeconds > 0 && !streamStarted {
+			c.Header("Retry-After", strconv.Itoa(translated.RetryAfterSeconds))
+		}
+		h.handleStreamingAwareError(c, translated.StatusCode, translated.Type, translated.Message, streamStarted)
+		return
+	}
+
 	// 使用默认的错误映射
 	status, errType, errMsg := h.mapUpstreamError(statusCode)
 	h.handleStreamingAwareError(c, status, errType, errMsg, streamStarted)
@@ -1631,7 +1752,8 @@ func (h *GatewayHandler) ensureForwardErrorResponse(c *gin.Context, streamStarte
 	if c.Writer.Written() {
 		streamStarted = true
 	}
-	h.handleStreamingAwareError(c, http.StatusBadGateway, "upstream_error", "Upstream request failed", streamStarted)
+	status, errType, message := forwardErrorFallback(c)
+	h.handleStreamingAwareError(c, status, errType, message, streamStarted)
 	return true
 }
 
@@ -1708,10 +1830,10 @@ func (h *GatewayHandler) checkClaudeCodeVersion(c *gin.Context) bool {
 func (h *GatewayHandler) errorResponse(c *gin.Context, status int, errType, message string) {
 	c.JSON(status, gin.H{
 		"type": "error",
-		"error": gin.H{
+		"error": withErrorTraceID(c, gin.H{
 			"type":    errType,
 			"message": message,
-		},
+		}),
 	})
 }
 
@@ -1774,6 +1896,10 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
 		return
 	}
+	if apiKeyModelDenied(apiKey, parsedReq.Model, reqLog) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", service.APIKeyModelNotAllowedMessage(parsedReq.Model))
+		return
+	}
 
 	setOpsRequestContext(c, parsedReq.Model, parsedReq.Stream)
 	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(parsedReq.Stream, false)))
@@ -1798,7 +1924,7 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 		UserAgent: c.GetHeader("User-Agent"),
 		APIKeyID:  apiKey.ID,
 	}
-	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
+	sessionHash := h.applySessionAffinity(c, parsedReq)
 
 	// 选择支持该模型的账号
 	account, err := h.gatewayService.SelectAccountForModel(c.Request.Context(), apiKey.GroupID, sessionHash, parsedReq.Model)
@@ -1813,6 +1939,17 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 	}
 	setOpsSelectedAccount(c, account.ID, account.Platform)
 
+	// count_tokens 不占用账号/用户并发槽位，也不进入等待队列，仅受按账号的轻量独立上限约束
+	release, acquired := h.countTokensLimiter.TryAcquire(account.ID, h.countTokensMaxConcurrency())
+	if !acquired {
+		c.Header("Retry-After", "1")
+		h.errorResponse(c, http.StatusTooManyRequests, "rate_limit_error", "count_tokens concurrency limit exceeded, please retry later")
+		return
+	}
+	if release != nil {
+		defer release()
+	}
+
 	// 转发请求（不记录使用量）
 	if err := h.gatewayService.ForwardCountTokens(c.Request.Context(), c, account, parsedReq); err != nil {
 		reqLog.Error("gateway.count_tokens_forward_failed", zap.Int64("account_id", account.ID), zap.Error(err))
@@ -1821,6 +1958,14 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 	}
 }
 
+// countTokensMaxConcurrency 返回 count_tokens 单账号并发上限（<=0 表示不限制）
+func (h *GatewayHandler) countTokensMaxConcurrency() int {
+	if h.cfg == nil {
+		return 0
+	}
+	return h.cfg.Gateway.CountTokensMaxConcurrency
+}
+
 // InterceptType 表示请求拦截类型
 type InterceptType int
 
@@ -2098,6 +2243,11 @@ func billingErrorDetails(err error) (status int, code, message string, retryAfte
 		msg := pkgerrors.Message(err)
 		return http.StatusTooManyRequests, "rate_limit_exceeded", msg, extractQuotaResetSeconds(err)
 	}
+	// 分组日/月消费上限：429 + Retry-After（距周期重置的秒数），独立错误码便于客户端区分于普通限流。
+	if errors.Is(err, service.ErrGroupSpendCapExceeded) {
+		msg := pkgerrors.Message(err)
+		return http.StatusTooManyRequests, "spend_cap_exceeded", msg, extractQuotaResetSeconds(err)
+	}
 	msg := pkgerrors.Message(err)
 	if msg == "" {
 		logger.L().With(
@@ -126,3 +126,15 @@ func TestBillingErrorDetails_T10_QuotaExhaustedReturns429WithRetryAfter(t *testi
 		})
 	}
 }
+
+func TestBillingErrorDetails_GroupSpendCapExceededHasDistinctCode(t *testing.T) {
+	err := service.ErrGroupSpendCapExceeded.WithMetadata(map[string]string{
+		"period":           service.GroupSpendCapPeriodDaily,
+		"window_resets_at": time.Now().Add(30 * time.Minute).UTC().Format(time.RFC3339),
+	})
+	status, code, msg, retryAfter := billingErrorDetails(err)
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.Equal(t, "spend_cap_exceeded", code)
+	require.NotEmpty(t, msg)
+	require.InDelta(t, 1800, retryAfter, 1)
+}
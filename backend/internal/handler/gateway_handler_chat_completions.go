@@ -60,6 +60,12 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	maxCost, body, rejection := extractRequestMaxCost(c, body)
+	if rejection != nil {
+		h.chatCompletionsErrorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
+
 	setOpsRequestContext(c, "", false)
 
 	// Validate JSON
@@ -75,6 +81,10 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 	reqModel := modelResult.String()
+	if apiKeyModelDenied(apiKey, reqModel, reqLog) {
+		h.chatCompletionsErrorResponse(c, http.StatusForbidden, "invalid_request_error", service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
 	reqStream, ok := parseOpenAICompatibleStream(body)
 	if !ok {
 		h.chatCompletionsErrorResponse(c, http.StatusBadRequest, "invalid_request_error", invalidStreamFieldTypeMessage)
@@ -87,6 +97,10 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 
 	// 解析渠道级模型映射
 	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+	if apiKeyMappedModelDenied(apiKey, channelMapping, reqLog) {
+		h.chatCompletionsErrorResponse(c, http.StatusForbidden, "invalid_request_error", service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
 
 	// Claude Code only restriction
 	if apiKey.Group != nil && apiKey.Group.ClaudeCodeOnly {
@@ -100,10 +114,16 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	if rejection := checkRequestCost(c, h.requestCostService, apiKey, channelMapping.EstimateBillingModel(reqModel), body, maxCost, reqLog); rejection != nil {
+		h.chatCompletionsErrorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
+
 	// Error passthrough binding
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
 	}
+	h.concurrencyHelper.BindSSEPingFormat(c)
 
 	subscription, _ := middleware2.GetSubscriptionFromContext(c)
 
@@ -142,7 +162,7 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		UserAgent: c.GetHeader("User-Agent"),
 		APIKeyID:  apiKey.ID,
 	}
-	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
+	sessionHash := h.applySessionAffinity(c, parsedReq)
 	groupPlatform := ""
 	if apiKey.Group != nil {
 		groupPlatform = apiKey.Group.Platform
@@ -212,6 +232,8 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 				h.handleConcurrencyError(c, err, "account", streamStarted)
 				return
 			}
+		} else {
+			recordOpsSlotAcquire(c, "account", slotAcquirePathFast, 0)
 		}
 		accountReleaseFunc = wrapReleaseOnDone(c.Request.Context(), accountReleaseFunc)
 
@@ -250,6 +272,7 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 		if err != nil {
 			var failoverErr *service.UpstreamFailoverError
 			if errors.As(err, &failoverErr) {
+				h.gatewayService.ReportAccountRoutingResult(account.ID, false)
 				if c.Writer.Size() != writerSizeBeforeForward {
 					h.handleCCFailoverExhausted(c, failoverErr, true)
 					return
@@ -279,6 +302,11 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 			return
 		}
 
+		h.gatewayService.ReportAccountRoutingResult(account.ID, true)
+		if upstreamLatencyMs, ok := getContextInt64(c, service.OpsUpstreamLatencyMsKey); ok {
+			h.gatewayService.ReportAccountUpstreamLatency(account.ID, upstreamLatencyMs)
+		}
+
 		// 6. Record usage
 		userAgent := c.GetHeader("User-Agent")
 		clientIP := ip.GetClientIP(c)
@@ -316,10 +344,10 @@ func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
 // chatCompletionsErrorResponse writes an error in OpenAI Chat Completions format.
 func (h *GatewayHandler) chatCompletionsErrorResponse(c *gin.Context, status int, errType, message string) {
 	c.JSON(status, gin.H{
-		"error": gin.H{
+		"error": withErrorTraceID(c, gin.H{
 			"type":    errType,
 			"message": message,
-		},
+		}),
 	})
 }
 
@@ -328,6 +356,9 @@ func (h *GatewayHandler) handleCCFailoverExhausted(c *gin.Context, lastErr *serv
 	if streamStarted {
 		return
 	}
+	if lastErr != nil {
+		service.SetUpstreamRequestIDHeader(c, h.cfg, lastErr.UpstreamRequestID)
+	}
 	statusCode := http.StatusBadGateway
 	if lastErr != nil && lastErr.StatusCode > 0 {
 		statusCode = lastErr.StatusCode
@@ -0,0 +1,171 @@
+//go:build unit
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type countTokensHTTPUpstream struct {
+	service.HTTPUpstream
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+func (u *countTokensHTTPUpstream) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	u.mu.Lock()
+	u.bodies = append(u.bodies, body)
+	u.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"input_tokens":42}`)),
+	}, nil
+}
+
+func (u *countTokensHTTPUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+type countTokensUsageLogRepo struct {
+	service.UsageLogRepository
+	mu      sync.Mutex
+	created int
+}
+
+func (r *countTokensUsageLogRepo) Create(context.Context, *service.UsageLog) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created++
+	return true, nil
+}
+
+func newCountTokensTestHandler(t *testing.T, maxConcurrency int) (*GatewayHandler, *countTokensHTTPUpstream, *countTokensUsageLogRepo, *service.APIKey) {
+	t.Helper()
+
+	groupID := int64(2101)
+	accountID := int64(1101)
+	group := &service.Group{
+		ID:       groupID,
+		Hydrated: true,
+		Platform: service.PlatformAnthropic,
+		Status:   service.StatusActive,
+	}
+	account := &service.Account{
+		ID:            accountID,
+		Name:          "setup-token-count",
+		Platform:      service.PlatformAnthropic,
+		Type:          service.AccountTypeSetupToken,
+		Credentials:   map[string]any{"access_token": "tok_count"},
+		Concurrency:   1,
+		Priority:      1,
+		Status:        service.StatusActive,
+		Schedulable:   true,
+		AccountGroups: []service.AccountGroup{{AccountID: accountID, GroupID: groupID}},
+	}
+
+	upstream := &countTokensHTTPUpstream{}
+	usageRepo := &countTokensUsageLogRepo{}
+	cfg := &config.Config{
+		RunMode: config.RunModeSimple,
+		Gateway: config.GatewayConfig{CountTokensMaxConcurrency: maxConcurrency},
+	}
+	schedulerSnapshot := service.NewSchedulerSnapshotService(&fakeSchedulerCache{accounts: []*service.Account{account}}, nil, nil, nil, nil)
+	gwSvc := service.NewGatewayService(
+		nil, &fakeGroupRepo{group: group}, usageRepo, nil, nil, nil, nil, nil, cfg,
+		schedulerSnapshot, nil, nil, nil, nil, nil, upstream, nil, nil, nil, nil,
+		nil, nil, nil, nil, nil, nil, nil,
+	)
+	billingCacheSvc := service.NewBillingCacheService(nil, nil, nil, nil, nil, nil, cfg, nil)
+	t.Cleanup(billingCacheSvc.Stop)
+
+	h := &GatewayHandler{
+		gatewayService:      gwSvc,
+		billingCacheService: billingCacheSvc,
+		concurrencyHelper:   NewConcurrencyHelper(service.NewConcurrencyService(&fakeConcurrencyCache{}), SSEPingFormatClaude, 0),
+		countTokensLimiter:  newCountTokensLimiter(),
+		cfg:                 cfg,
+	}
+
+	apiKey := &service.APIKey{
+		ID:      3101,
+		UserID:  4101,
+		GroupID: &groupID,
+		Status:  service.StatusActive,
+		User:    &service.User{ID: 4101, Concurrency: 10, Balance: 100},
+		Group:   group,
+	}
+	return h, upstream, usageRepo, apiKey
+}
+
+func newCountTokensTestContext(apiKey *service.APIKey) (*gin.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	body := []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hello"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages/count_tokens", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), ctxkey.Group, apiKey.Group))
+	c.Request = req
+	c.Set(string(middleware.ContextKeyAPIKey), apiKey)
+	c.Set(string(middleware.ContextKeyUser), middleware.AuthSubject{UserID: apiKey.UserID, Concurrency: 10})
+	return c, rec
+}
+
+func TestGatewayHandlerCountTokens_ForwardsWithoutRecordingUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, upstream, usageRepo, apiKey := newCountTokensTestHandler(t, 1)
+
+	c, rec := newCountTokensTestContext(apiKey)
+	h.CountTokens(c)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.JSONEq(t, `{"input_tokens":42}`, rec.Body.String())
+	require.Len(t, upstream.bodies, 1)
+	require.Equal(t, "claude-sonnet-4-5-20250929", gjson.GetBytes(upstream.bodies[0], "model").String())
+
+	// 给可能存在的异步记录留出时间，确认 count_tokens 不产生 usage 记录
+	time.Sleep(50 * time.Millisecond)
+	usageRepo.mu.Lock()
+	defer usageRepo.mu.Unlock()
+	require.Zero(t, usageRepo.created)
+}
+
+func TestGatewayHandlerCountTokens_RejectsWhenDedicatedLimitReached(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, upstream, _, apiKey := newCountTokensTestHandler(t, 1)
+
+	release, acquired := h.countTokensLimiter.TryAcquire(1101, 1)
+	require.True(t, acquired)
+
+	c, rec := newCountTokensTestContext(apiKey)
+	h.CountTokens(c)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	require.Equal(t, "rate_limit_error", gjson.Get(rec.Body.String(), "error.type").String())
+	require.Empty(t, upstream.bodies)
+
+	release()
+	c, rec = newCountTokensTestContext(apiKey)
+	h.CountTokens(c)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, upstream.bodies, 1)
+}
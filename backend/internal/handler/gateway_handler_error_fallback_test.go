@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -165,3 +166,21 @@ func TestGatewayForwardErrorAlreadyCommunicated(t *testing.T) {
 		require.False(t, reported)
 	})
 }
+
+// 优雅停机宽限期结束后中断的流式请求应收到 shutdown 错误事件，而不是误报上游故障。
+func TestGatewayEnsureForwardErrorResponse_ServerShuttingDown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrServerShuttingDown)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil).WithContext(ctx)
+	c.String(http.StatusOK, "data: {}\n\n")
+
+	h := &GatewayHandler{}
+	wrote := h.ensureForwardErrorResponse(c, true)
+
+	require.True(t, wrote)
+	assert.Contains(t, w.Body.String(), "Server is shutting down, please retry")
+	assert.NotContains(t, w.Body.String(), "Upstream request failed")
+}
@@ -60,6 +60,12 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 
+	maxCost, body, rejection := extractRequestMaxCost(c, body)
+	if rejection != nil {
+		h.responsesErrorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
+
 	setOpsRequestContext(c, "", false)
 
 	// Validate JSON
@@ -75,6 +81,10 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 	reqModel := modelResult.String()
+	if apiKeyModelDenied(apiKey, reqModel, reqLog) {
+		h.responsesErrorResponse(c, http.StatusForbidden, "invalid_request_error", service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
 	reqStream, ok := parseOpenAICompatibleStream(body)
 	if !ok {
 		h.responsesErrorResponse(c, http.StatusBadRequest, "invalid_request_error", invalidStreamFieldTypeMessage)
@@ -84,6 +94,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 	setOpsRequestContext(c, reqModel, reqStream)
 	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(reqStream, false)))
+
 	requestCtx := c.Request.Context()
 	if service.IsImageGenerationIntent("/v1/responses", reqModel, body) {
 		requestCtx = service.WithOpenAIImageGenerationIntent(requestCtx)
@@ -91,6 +102,15 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 	// 解析渠道级模型映射
 	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(requestCtx, apiKey.GroupID, reqModel)
+	if apiKeyMappedModelDenied(apiKey, channelMapping, reqLog) {
+		h.responsesErrorResponse(c, http.StatusForbidden, "invalid_request_error", service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
+
+	if rejection := checkRequestCost(c, h.requestCostService, apiKey, channelMapping.EstimateBillingModel(reqModel), body, maxCost, reqLog); rejection != nil {
+		h.responsesErrorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
 
 	// Claude Code only restriction:
 	// /v1/responses is never a Claude Code endpoint.
@@ -113,6 +133,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
 	}
+	h.concurrencyHelper.BindSSEPingFormat(c)
 
 	subscription, _ := middleware2.GetSubscriptionFromContext(c)
 
@@ -151,7 +172,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		UserAgent: c.GetHeader("User-Agent"),
 		APIKeyID:  apiKey.ID,
 	}
-	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
+	sessionHash := h.applySessionAffinity(c, parsedReq)
 
 	// 3. Account selection + failover loop
 	fs := NewFailoverState(h.maxAccountSwitches, false)
@@ -210,6 +231,8 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 				h.handleConcurrencyError(c, err, "account", streamStarted)
 				return
 			}
+		} else {
+			recordOpsSlotAcquire(c, "account", slotAcquirePathFast, 0)
 		}
 		accountReleaseFunc = wrapReleaseOnDone(c.Request.Context(), accountReleaseFunc)
 
@@ -228,6 +251,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		if err != nil {
 			var failoverErr *service.UpstreamFailoverError
 			if errors.As(err, &failoverErr) {
+				h.gatewayService.ReportAccountRoutingResult(account.ID, false)
 				// Can't failover if streaming content already sent
 				if c.Writer.Size() != writerSizeBeforeForward {
 					h.handleResponsesFailoverExhausted(c, failoverErr, true)
@@ -258,6 +282,11 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 			return
 		}
 
+		h.gatewayService.ReportAccountRoutingResult(account.ID, true)
+		if upstreamLatencyMs, ok := getContextInt64(c, service.OpsUpstreamLatencyMsKey); ok {
+			h.gatewayService.ReportAccountUpstreamLatency(account.ID, upstreamLatencyMs)
+		}
+
 		// 6. Record usage
 		userAgent := c.GetHeader("User-Agent")
 		clientIP := ip.GetClientIP(c)
@@ -295,10 +324,10 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 // responsesErrorResponse writes an error in OpenAI Responses API format.
 func (h *GatewayHandler) responsesErrorResponse(c *gin.Context, status int, code, message string) {
 	c.JSON(status, gin.H{
-		"error": gin.H{
+		"error": withErrorTraceID(c, gin.H{
 			"code":    code,
 			"message": message,
-		},
+		}),
 	})
 }
 
@@ -307,6 +336,9 @@ func (h *GatewayHandler) handleResponsesFailoverExhausted(c *gin.Context, lastEr
 	if streamStarted {
 		return // Can't write error after stream started
 	}
+	if lastErr != nil {
+		service.SetUpstreamRequestIDHeader(c, h.cfg, lastErr.UpstreamRequestID)
+	}
 	statusCode := http.StatusBadGateway
 	if lastErr != nil && lastErr.StatusCode > 0 {
 		statusCode = lastErr.StatusCode
@@ -3,13 +3,16 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -112,8 +115,84 @@ const (
 	SSEPingFormatNone SSEPingFormat = ""
 	// SSEPingFormatComment is an SSE comment ping for OpenAI/Codex CLI clients
 	SSEPingFormatComment SSEPingFormat = ":\n\n"
+	// SSEPingFormatEvent is a named "ping" event for strict SSE parsers that reject comment lines
+	SSEPingFormatEvent SSEPingFormat = "event: ping\ndata: {\"type\": \"ping\"}\n\n"
 )
 
+// noKeepaliveHeader lets clients opt out of all keep-alive pings (wait-phase and mid-stream)
+const noKeepaliveHeader = "X-No-Keepalive"
+
+// clientDisablesKeepalive reports whether the request carries a truthy X-No-Keepalive header.
+func clientDisablesKeepalive(c *gin.Context) bool {
+	if c == nil || c.Request == nil {
+		return false
+	}
+	v := strings.TrimSpace(c.GetHeader(noKeepaliveHeader))
+	if v == "" {
+		return false
+	}
+	disabled, err := strconv.ParseBool(v)
+	return err != nil || disabled
+}
+
+// requestSSEPingFormat returns the wait-phase ping format for this request: the route
+// default, or none when the client sent X-No-Keepalive.
+func requestSSEPingFormat(c *gin.Context, routeDefault SSEPingFormat) SSEPingFormat {
+	if clientDisablesKeepalive(c) {
+		return SSEPingFormatNone
+	}
+	return routeDefault
+}
+
+// midStreamSSEPingFormat maps a wait-phase ping format to the bytes used for keep-alives once
+// the upstream stream has started. The bare data ping is only safe before the first upstream
+// event; mid-stream, Anthropic SDKs dispatch on the event name, so it keeps the "event: ping" line.
+func midStreamSSEPingFormat(format SSEPingFormat) SSEPingFormat {
+	if format == SSEPingFormatClaude {
+		return SSEPingFormatEvent
+	}
+	return format
+}
+
+// resolveSSEPingFormat maps a configured ping format name (config.SSEPingFormat*) to
+// the bytes written on the wire; an empty name keeps the route default.
+func resolveSSEPingFormat(name string, routeDefault SSEPingFormat) SSEPingFormat {
+	switch name {
+	case config.SSEPingFormatComment:
+		return SSEPingFormatComment
+	case config.SSEPingFormatEvent:
+		return SSEPingFormatEvent
+	case config.SSEPingFormatData:
+		return SSEPingFormatClaude
+	default:
+		return routeDefault
+	}
+}
+
+// isSpecCompliantSSEPing reports whether a ping payload is a complete SSE message:
+// terminated by a blank line, and every line is either a comment or a known field.
+func isSpecCompliantSSEPing(format SSEPingFormat) bool {
+	s := string(format)
+	if s == "" {
+		return true
+	}
+	if !strings.HasSuffix(s, "\n\n") {
+		return false
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n\n"), "\n") {
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, _, _ := strings.Cut(line, ":")
+		switch field {
+		case "event", "data", "id", "retry":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // ConcurrencyError represents a concurrency limit error with context
 type ConcurrencyError struct {
 	SlotType  string
@@ -140,6 +219,9 @@ type ConcurrencyHelper struct {
 	concurrencyService *service.ConcurrencyService
 	pingFormat         SSEPingFormat
 	pingInterval       time.Duration
+	// 等待账号槽位期间的排队提示（config.QueuedNoticeFormat*，空表示不发送）
+	queuedNoticeFormat   string
+	queuedNoticeInterval time.Duration
 }
 
 // NewConcurrencyHelper creates a new ConcurrencyHelper
@@ -147,6 +229,9 @@ func NewConcurrencyHelper(concurrencyService *service.ConcurrencyService, pingFo
 	if pingInterval <= 0 {
 		pingInterval = defaultPingInterval
 	}
+	if !isSpecCompliantSSEPing(pingFormat) {
+		pingFormat = SSEPingFormatComment
+	}
 	return &ConcurrencyHelper{
 		concurrencyService: concurrencyService,
 		pingFormat:         pingFormat,
@@ -154,6 +239,36 @@ func NewConcurrencyHelper(concurrencyService *service.ConcurrencyService, pingFo
 	}
 }
 
+// SetQueuedNotice 开启流式请求等待账号槽位期间的排队提示（concurrency.queued_notice）
+func (h *ConcurrencyHelper) SetQueuedNotice(cfg config.QueuedNoticeConfig) {
+	if h == nil || !cfg.Enabled || cfg.IntervalSeconds <= 0 {
+		return
+	}
+	h.queuedNoticeFormat = cfg.Format
+	h.queuedNoticeInterval = time.Duration(cfg.IntervalSeconds) * time.Second
+}
+
+// queuedNotice 返回一条完整的排队提示 SSE 消息（以空行结尾，不会与随后的响应事件粘连）
+func queuedNotice(format string, waited time.Duration) string {
+	waitedMs := waited.Milliseconds()
+	if format == config.QueuedNoticeFormatEvent {
+		return fmt.Sprintf("event: queue\ndata: {\"type\":\"queue\",\"status\":\"queued\",\"position\":null,\"waited_ms\":%d}\n\n", waitedMs)
+	}
+	return fmt.Sprintf(": queued position unknown, waited %dms\n\n", waitedMs)
+}
+
+// BindSSEPingFormat chooses the ping format for this request before any streaming starts and
+// binds the matching mid-stream keep-alive to the context (see midStreamSSEPingFormat).
+// It returns the wait-phase format.
+func (h *ConcurrencyHelper) BindSSEPingFormat(c *gin.Context) SSEPingFormat {
+	if h == nil || c == nil {
+		return SSEPingFormatNone
+	}
+	format := requestSSEPingFormat(c, h.pingFormat)
+	service.BindStreamKeepalivePing(c, string(midStreamSSEPingFormat(format)))
+	return format
+}
+
 // wrapReleaseOnDone ensures release runs at most once and still triggers on context cancellation.
 // 用于避免客户端断开或上游超时导致的并发槽位泄漏。
 // 优化：基于 context.AfterFunc 注册回调，避免每请求额外守护 goroutine。
@@ -163,6 +278,7 @@ func wrapReleaseOnDone(ctx context.Context, releaseFunc func()) func() {
 	}
 	var once sync.Once
 	var stop func() bool
+	var untrack func()
 
 	release := func() {
 		once.Do(func() {
@@ -170,9 +286,14 @@ func wrapReleaseOnDone(ctx context.Context, releaseFunc func()) func() {
 				_ = stop()
 			}
 			releaseFunc()
+			if untrack != nil {
+				untrack()
+			}
 		})
 	}
 
+	// 登记到 liveConcurrency，停机强制中断后由 ReleaseLiveConcurrency 兜底归还
+	untrack = liveConcurrency.trackRelease(release)
 	stop = context.AfterFunc(ctx, release)
 
 	return release
@@ -180,21 +301,39 @@ func wrapReleaseOnDone(ctx context.Context, releaseFunc func()) func() {
 
 // IncrementWaitCount increments the wait count for a user
 func (h *ConcurrencyHelper) IncrementWaitCount(ctx context.Context, userID int64, maxWait int) (bool, error) {
-	return h.concurrencyService.IncrementWaitCount(ctx, userID, maxWait)
+	ok, err := h.concurrencyService.IncrementWaitCount(ctx, userID, maxWait)
+	if ok && err == nil {
+		liveConcurrency.trackWait(waitCounterKey{slotType: "user", id: userID}, func() {
+			h.concurrencyService.DecrementWaitCount(context.Background(), userID)
+		})
+	}
+	return ok, err
 }
 
 // DecrementWaitCount decrements the wait count for a user
 func (h *ConcurrencyHelper) DecrementWaitCount(ctx context.Context, userID int64) {
+	if !liveConcurrency.untrackWait(waitCounterKey{slotType: "user", id: userID}) {
+		return
+	}
 	h.concurrencyService.DecrementWaitCount(ctx, userID)
 }
 
 // IncrementAccountWaitCount increments the wait count for an account
 func (h *ConcurrencyHelper) IncrementAccountWaitCount(ctx context.Context, accountID int64, maxWait int) (bool, error) {
-	return h.concurrencyService.IncrementAccountWaitCount(ctx, accountID, maxWait)
+	ok, err := h.concurrencyService.IncrementAccountWaitCount(ctx, accountID, maxWait)
+	if ok && err == nil {
+		liveConcurrency.trackWait(waitCounterKey{slotType: "account", id: accountID}, func() {
+			h.concurrencyService.DecrementAccountWaitCount(context.Background(), accountID)
+		})
+	}
+	return ok, err
 }
 
 // DecrementAccountWaitCount decrements the wait count for an account
 func (h *ConcurrencyHelper) DecrementAccountWaitCount(ctx context.Context, accountID int64) {
+	if !liveConcurrency.untrackWait(waitCounterKey{slotType: "account", id: accountID}) {
+		return
+	}
 	h.concurrencyService.DecrementAccountWaitCount(ctx, accountID)
 }
 
@@ -233,6 +372,7 @@ func (h *ConcurrencyHelper) AcquireUserSlotWithWait(c *gin.Context, userID int64
 
 func (h *ConcurrencyHelper) acquireUserSlotWithWaitTimeout(c *gin.Context, userID int64, maxConcurrency int, timeout time.Duration, isStream bool, streamStarted *bool) (func(), error) {
 	ctx := c.Request.Context()
+	acquireStart := time.Now()
 
 	// Try to acquire immediately
 	releaseFunc, acquired, err := h.TryAcquireUserSlot(ctx, userID, maxConcurrency)
@@ -241,10 +381,11 @@ func (h *ConcurrencyHelper) acquireUserSlotWithWaitTimeout(c *gin.Context, userI
 	}
 
 	if acquired {
+		recordOpsSlotAcquire(c, "user", slotAcquirePathFast, 0)
 		return releaseFunc, nil
 	}
 
-	queueLimit := service.CalculateMaxWait(maxConcurrency) - maxConcurrency
+	queueLimit := h.concurrencyService.CalculateMaxWait(maxConcurrency) - maxConcurrency
 	if queueLimit < 1 {
 		queueLimit = 1
 	}
@@ -253,12 +394,15 @@ func (h *ConcurrencyHelper) acquireUserSlotWithWaitTimeout(c *gin.Context, userI
 		return nil, err
 	}
 	if !canWait {
+		recordOpsSlotAcquire(c, "user", slotAcquirePathQueueFull, 0)
 		return nil, &WaitQueueFullError{SlotType: "user"}
 	}
 	defer h.DecrementWaitCount(ctx, userID)
 
 	// Need to wait - handle streaming ping if needed
-	return h.waitForSlotWithPingTimeout(c, "user", userID, maxConcurrency, timeout, isStream, streamStarted, false)
+	releaseFunc, err = h.waitForSlotWithPingTimeout(c, "user", userID, maxConcurrency, timeout, isStream, streamStarted, false)
+	recordOpsSlotAcquire(c, "user", slotAcquirePathAfterWait(err), time.Since(acquireStart))
+	return releaseFunc, err
 }
 
 // AcquireAccountSlotWithWait acquires an account concurrency slot, waiting if necessary.
@@ -266,6 +410,7 @@ func (h *ConcurrencyHelper) acquireUserSlotWithWaitTimeout(c *gin.Context, userI
 // streamStarted is updated if streaming response has begun.
 func (h *ConcurrencyHelper) AcquireAccountSlotWithWait(c *gin.Context, accountID int64, maxConcurrency int, isStream bool, streamStarted *bool) (func(), error) {
 	ctx := c.Request.Context()
+	acquireStart := time.Now()
 
 	// Try to acquire immediately
 	releaseFunc, acquired, err := h.TryAcquireAccountSlot(ctx, accountID, maxConcurrency)
@@ -274,11 +419,14 @@ func (h *ConcurrencyHelper) AcquireAccountSlotWithWait(c *gin.Context, accountID
 	}
 
 	if acquired {
+		recordOpsSlotAcquire(c, "account", slotAcquirePathFast, 0)
 		return releaseFunc, nil
 	}
 
 	// Need to wait - handle streaming ping if needed
-	return h.waitForSlotWithPing(c, "account", accountID, maxConcurrency, isStream, streamStarted)
+	releaseFunc, err = h.waitForSlotWithPing(c, "account", accountID, maxConcurrency, isStream, streamStarted)
+	recordOpsSlotAcquire(c, "account", slotAcquirePathAfterWait(err), time.Since(acquireStart))
+	return releaseFunc, err
 }
 
 // waitForSlotWithPing waits for a concurrency slot, sending ping events for streaming requests.
@@ -310,7 +458,8 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 	}
 
 	// Determine if ping is needed (streaming + ping format defined)
-	needPing := isStream && h.pingFormat != ""
+	pingFormat := requestSSEPingFormat(c, h.pingFormat)
+	needPing := isStream && pingFormat != ""
 
 	var flusher http.Flusher
 	if needPing {
@@ -329,6 +478,15 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 		pingCh = pingTicker.C
 	}
 
+	// 排队提示仅用于账号槽位等待，且沿用 ping 的开关（X-No-Keepalive 客户端与 Gemini 原生路由不发送）
+	var noticeCh <-chan time.Time
+	waitStart := time.Now()
+	if needPing && slotType == "account" && h.queuedNoticeFormat != "" {
+		noticeTicker := time.NewTicker(h.queuedNoticeInterval)
+		defer noticeTicker.Stop()
+		noticeCh = noticeTicker.C
+	}
+
 	backoff := initialBackoff
 	timer := time.NewTimer(backoff)
 	defer timer.Stop()
@@ -346,17 +504,14 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 
 		case <-pingCh:
 			// Send ping to keep connection alive
-			if !*streamStarted {
-				c.Header("Content-Type", "text/event-stream")
-				c.Header("Cache-Control", "no-cache")
-				c.Header("Connection", "keep-alive")
-				c.Header("X-Accel-Buffering", "no")
-				*streamStarted = true
+			if err := writeWaitSSE(c, flusher, streamStarted, string(pingFormat)); err != nil {
+				return nil, err
 			}
-			if _, err := fmt.Fprint(c.Writer, string(h.pingFormat)); err != nil {
+
+		case <-noticeCh:
+			if err := writeWaitSSE(c, flusher, streamStarted, queuedNotice(h.queuedNoticeFormat, time.Since(waitStart))); err != nil {
 				return nil, err
 			}
-			flusher.Flush()
 
 		case <-timer.C:
 			// Try to acquire slot
@@ -374,9 +529,74 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 	}
 }
 
+// writeWaitSSE 在等待阶段写出一条完整的 SSE 消息；首次写出时补齐流式响应头并标记 streamStarted，
+// 之后的错误与真实响应都按 SSE 流写出
+func writeWaitSSE(c *gin.Context, flusher http.Flusher, streamStarted *bool, message string) error {
+	if !*streamStarted {
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		*streamStarted = true
+	}
+	if _, err := fmt.Fprint(c.Writer, message); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
 // AcquireAccountSlotWithWaitTimeout acquires an account slot with a custom timeout (keeps SSE ping).
 func (h *ConcurrencyHelper) AcquireAccountSlotWithWaitTimeout(c *gin.Context, accountID int64, maxConcurrency int, timeout time.Duration, isStream bool, streamStarted *bool) (func(), error) {
-	return h.waitForSlotWithPingTimeout(c, "account", accountID, maxConcurrency, timeout, isStream, streamStarted, true)
+	acquireStart := time.Now()
+	releaseFunc, err := h.waitForSlotWithPingTimeout(c, "account", accountID, maxConcurrency, timeout, isStream, streamStarted, true)
+	recordOpsSlotAcquire(c, "account", slotAcquirePathAfterWait(err), time.Since(acquireStart))
+	return releaseFunc, err
+}
+
+// 并发槽位获取路径（写入 ops 上下文 OpsSlotAcquirePathKey）
+const (
+	slotAcquirePathFast      = "fast"       // 立即获取（含调度阶段已获取）
+	slotAcquirePathWait      = "wait"       // 排队等待后获取
+	slotAcquirePathTimeout   = "timeout"    // 排队等待超时
+	slotAcquirePathQueueFull = "queue_full" // 等待队列已满，直接拒绝
+	slotAcquirePathAborted   = "aborted"    // 等待期间客户端断开或获取出错
+)
+
+func slotAcquirePathAfterWait(err error) string {
+	if err == nil {
+		return slotAcquirePathWait
+	}
+	var concurrencyErr *ConcurrencyError
+	if errors.As(err, &concurrencyErr) && concurrencyErr.IsTimeout {
+		return slotAcquirePathTimeout
+	}
+	return slotAcquirePathAborted
+}
+
+// recordOpsSlotAcquire 记录槽位获取路径与等待耗时，便于区分「上游慢」与「排队慢」。
+// 同一请求多次获取（如账号切换重试）时等待耗时累加、路径依次追加。
+func recordOpsSlotAcquire(c *gin.Context, slotType, path string, waited time.Duration) {
+	if c == nil {
+		return
+	}
+	waitKey := service.OpsUserSlotWaitMsKey
+	if slotType == "account" {
+		waitKey = service.OpsAccountSlotWaitMsKey
+	}
+	waitMs := waited.Milliseconds()
+	if prev, ok := c.Get(waitKey); ok {
+		if prevMs, ok := prev.(int64); ok {
+			waitMs += prevMs
+		}
+	}
+	service.SetOpsLatencyMs(c, waitKey, waitMs)
+
+	entry := slotType + "=" + path
+	if prev := c.GetString(service.OpsSlotAcquirePathKey); prev != "" {
+		entry = prev + "," + entry
+	}
+	c.Set(service.OpsSlotAcquirePathKey, entry)
 }
 
 // nextBackoff 计算下一次退避时间
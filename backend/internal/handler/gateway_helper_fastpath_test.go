@@ -2,11 +2,16 @@ package handler
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
 
@@ -93,6 +98,14 @@ func (m *concurrencyCacheMock) CleanupStaleProcessSlots(ctx context.Context, act
 	return nil
 }
 
+func (m *concurrencyCacheMock) HeartbeatInstance(ctx context.Context, instanceID string, ttl time.Duration) error {
+	return nil
+}
+
+func (m *concurrencyCacheMock) ReconcileStaleSlots(ctx context.Context, activeRequestPrefix string) (int, error) {
+	return 0, nil
+}
+
 func TestConcurrencyHelper_TryAcquireUserSlot(t *testing.T) {
 	cache := &concurrencyCacheMock{
 		acquireUserSlotFn: func(ctx context.Context, userID int64, maxConcurrency int, requestID string) (bool, error) {
@@ -124,3 +137,119 @@ func TestConcurrencyHelper_TryAcquireAccountSlot_NotAcquired(t *testing.T) {
 	require.Nil(t, release)
 	require.Equal(t, int32(0), atomic.LoadInt32(&cache.releaseAccountCalled))
 }
+
+func TestConcurrencyHelper_RecordsOpsSlotAcquirePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var accountAttempts int32
+	cache := &concurrencyCacheMock{
+		acquireUserSlotFn: func(ctx context.Context, userID int64, maxConcurrency int, requestID string) (bool, error) {
+			return true, nil
+		},
+		acquireAccountSlotFn: func(ctx context.Context, accountID int64, maxConcurrency int, requestID string) (bool, error) {
+			// 第一次获取（含 tryImmediate 与等待重试）全部失败直到超时，第二次获取的首个尝试成功
+			return atomic.AddInt32(&accountAttempts, 1) > 3, nil
+		},
+	}
+	helper := NewConcurrencyHelper(service.NewConcurrencyService(cache), SSEPingFormatNone, time.Second)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	streamStarted := false
+
+	release, err := helper.AcquireUserSlotWithWait(c, 1, 1, false, &streamStarted)
+	require.NoError(t, err)
+	release()
+
+	_, err = helper.AcquireAccountSlotWithWaitTimeout(c, 2, 1, 250*time.Millisecond, false, &streamStarted)
+	var concurrencyErr *ConcurrencyError
+	require.ErrorAs(t, err, &concurrencyErr)
+	require.True(t, concurrencyErr.IsTimeout)
+
+	release, err = helper.AcquireAccountSlotWithWaitTimeout(c, 2, 1, time.Second, false, &streamStarted)
+	require.NoError(t, err)
+	release()
+
+	require.Equal(t, "user=fast,account=timeout,account=wait", c.GetString(service.OpsSlotAcquirePathKey))
+	require.Equal(t, int64(0), c.GetInt64(service.OpsUserSlotWaitMsKey))
+	require.GreaterOrEqual(t, c.GetInt64(service.OpsAccountSlotWaitMsKey), int64(200))
+
+	// 槽位观测字段随 ops 错误条目落库
+	entry := &service.OpsInsertErrorLogInput{}
+	applyOpsLatencyFieldsFromContext(c, entry)
+	require.Equal(t, "user=fast,account=timeout,account=wait", entry.SlotAcquirePath)
+	require.NotNil(t, entry.UserSlotWaitMs)
+	require.NotNil(t, entry.AccountSlotWaitMs)
+	require.GreaterOrEqual(t, *entry.AccountSlotWaitMs, int64(200))
+}
+
+func TestConcurrencyHelper_QueuedNoticeWhileWaitingForAccountSlot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, format := range []string{config.QueuedNoticeFormatComment, config.QueuedNoticeFormatEvent} {
+		t.Run(format, func(t *testing.T) {
+			start := time.Now()
+			cache := &concurrencyCacheMock{
+				acquireAccountSlotFn: func(ctx context.Context, accountID int64, maxConcurrency int, requestID string) (bool, error) {
+					return time.Since(start) > 150*time.Millisecond, nil
+				},
+			}
+			helper := NewConcurrencyHelper(service.NewConcurrencyService(cache), SSEPingFormatClaude, 10*time.Second)
+			helper.SetQueuedNotice(config.QueuedNoticeConfig{Enabled: true, IntervalSeconds: 1, Format: format})
+			helper.queuedNoticeInterval = 20 * time.Millisecond
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+			streamStarted := false
+
+			release, err := helper.AcquireAccountSlotWithWaitTimeout(c, 1, 1, time.Second, true, &streamStarted)
+			require.NoError(t, err)
+			release()
+			require.True(t, streamStarted)
+			require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+			// 槽位获取后写出真实响应：每条排队提示都是完整消息，不与真实事件粘连
+			_, _ = rec.WriteString("event: message_start\ndata: {\"type\":\"message_start\"}\n\n")
+			blocks := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n\n"), "\n\n")
+			require.GreaterOrEqual(t, len(blocks), 3)
+			for _, block := range blocks[:len(blocks)-1] {
+				if format == config.QueuedNoticeFormatEvent {
+					require.True(t, strings.HasPrefix(block, "event: queue\ndata: {\"type\":\"queue\""), block)
+				} else {
+					require.True(t, strings.HasPrefix(block, ": queued position unknown"), block)
+					require.NotContains(t, block, "\n")
+				}
+			}
+			require.Equal(t, "event: message_start\ndata: {\"type\":\"message_start\"}", blocks[len(blocks)-1])
+		})
+	}
+}
+
+func TestConcurrencyHelper_QueuedNoticeSkippedForUserSlotsAndNoKeepalive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	start := time.Now()
+	acquired := func(ctx context.Context, id int64, maxConcurrency int, requestID string) (bool, error) {
+		return time.Since(start) > 100*time.Millisecond, nil
+	}
+	cache := &concurrencyCacheMock{acquireUserSlotFn: acquired, acquireAccountSlotFn: acquired}
+	helper := NewConcurrencyHelper(service.NewConcurrencyService(cache), SSEPingFormatClaude, 10*time.Second)
+	helper.SetQueuedNotice(config.QueuedNoticeConfig{Enabled: true, IntervalSeconds: 1, Format: config.QueuedNoticeFormatComment})
+	helper.queuedNoticeInterval = 10 * time.Millisecond
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	streamStarted := false
+	release, err := helper.AcquireUserSlotWithWait(c, 1, 1, true, &streamStarted)
+	require.NoError(t, err)
+	release()
+	require.Empty(t, rec.Body.String(), "user slot waits do not emit queued notices")
+
+	start = time.Now()
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Request.Header.Set(noKeepaliveHeader, "true")
+	release, err = helper.AcquireAccountSlotWithWaitTimeout(c, 2, 1, time.Second, true, &streamStarted)
+	require.NoError(t, err)
+	release()
+	require.Empty(t, rec.Body.String(), "X-No-Keepalive clients get no queued notices")
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -144,6 +146,14 @@ func (s *helperConcurrencyCacheStub) CleanupStaleProcessSlots(ctx context.Contex
 	return nil
 }
 
+func (s *helperConcurrencyCacheStub) HeartbeatInstance(ctx context.Context, instanceID string, ttl time.Duration) error {
+	return nil
+}
+
+func (s *helperConcurrencyCacheStub) ReconcileStaleSlots(ctx context.Context, activeRequestPrefix string) (int, error) {
+	return 0, nil
+}
+
 func newHelperTestContext(method, path string) (*gin.Context, *httptest.ResponseRecorder) {
 	gin.SetMode(gin.TestMode)
 	rec := httptest.NewRecorder()
@@ -369,6 +379,120 @@ func TestWaitForSlotWithPingTimeout_TimeoutAndStreamPing(t *testing.T) {
 	})
 }
 
+func TestWaitForSlotWithPingTimeout_PingFormats(t *testing.T) {
+	tests := []struct {
+		name       string
+		configName string
+		want       string
+	}{
+		{name: "comment", configName: config.SSEPingFormatComment, want: ":\n\n"},
+		{name: "event", configName: config.SSEPingFormatEvent, want: "event: ping\ndata: {\"type\": \"ping\"}\n\n"},
+		{name: "data", configName: config.SSEPingFormatData, want: "data: {\"type\": \"ping\"}\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := resolveSSEPingFormat(tt.configName, SSEPingFormatNone)
+			require.True(t, isSpecCompliantSSEPing(format))
+
+			cache := &helperConcurrencyCacheStub{accountSeq: []bool{false, false, false}}
+			helper := NewConcurrencyHelper(service.NewConcurrencyService(cache), format, 10*time.Millisecond)
+			c, rec := newHelperTestContext(http.MethodPost, "/v1/messages")
+			streamStarted := false
+			release, err := helper.waitForSlotWithPingTimeout(c, "account", 101, 2, 35*time.Millisecond, true, &streamStarted, true)
+			require.Nil(t, release)
+			require.Error(t, err)
+
+			body := rec.Body.String()
+			require.NotEmpty(t, body)
+			require.Equal(t, tt.want, body[:len(tt.want)])
+			require.Empty(t, strings.ReplaceAll(body, tt.want, ""), "only whole ping messages should be written")
+		})
+	}
+}
+
+func TestBindSSEPingFormat_PerClientWaitPhaseBytes(t *testing.T) {
+	tests := []struct {
+		name         string
+		routeDefault SSEPingFormat
+		noKeepalive  string
+		want         string
+		wantStream   string
+	}{
+		// Claude 路由：等待阶段发裸 data ping，流中 keepalive 保留 Anthropic 原生 event 行
+		{name: "claude_data_ping", routeDefault: SSEPingFormatClaude, want: "data: {\"type\": \"ping\"}\n\n", wantStream: "event: ping\ndata: {\"type\": \"ping\"}\n\n"},
+		{name: "openai_comment_ping", routeDefault: SSEPingFormatComment, want: ":\n\n", wantStream: ":\n\n"},
+		{name: "no_keepalive_header", routeDefault: SSEPingFormatClaude, noKeepalive: "true", want: "", wantStream: ""},
+		{name: "no_keepalive_false_keeps_default", routeDefault: SSEPingFormatComment, noKeepalive: "false", want: ":\n\n", wantStream: ":\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &helperConcurrencyCacheStub{accountSeq: []bool{false, false, false}}
+			helper := NewConcurrencyHelper(service.NewConcurrencyService(cache), tt.routeDefault, 10*time.Millisecond)
+			c, rec := newHelperTestContext(http.MethodPost, "/v1/messages")
+			if tt.noKeepalive != "" {
+				c.Request.Header.Set("X-No-Keepalive", tt.noKeepalive)
+			}
+
+			require.Equal(t, SSEPingFormat(tt.want), helper.BindSSEPingFormat(c))
+			bound, ok := service.BoundStreamKeepalivePing(c)
+			require.True(t, ok)
+			require.Equal(t, tt.wantStream, bound)
+
+			streamStarted := false
+			_, err := helper.waitForSlotWithPingTimeout(c, "account", 101, 2, 35*time.Millisecond, true, &streamStarted, true)
+			require.Error(t, err)
+
+			body := rec.Body.String()
+			if tt.want == "" {
+				require.Empty(t, body)
+				require.False(t, streamStarted)
+				return
+			}
+			require.Equal(t, tt.want, body[:len(tt.want)])
+			require.Empty(t, strings.ReplaceAll(body, tt.want, ""))
+		})
+	}
+}
+
+func TestWaitForSlotWithPing_StopsAfterSlotAcquired(t *testing.T) {
+	// 前两次轮询失败、第三次获取成功：等待期间发 ping，获取后不再写出任何字节。
+	cache := &helperConcurrencyCacheStub{accountSeq: []bool{false, false, true}}
+	helper := NewConcurrencyHelper(service.NewConcurrencyService(cache), SSEPingFormatClaude, 20*time.Millisecond)
+	c, rec := newHelperTestContext(http.MethodPost, "/v1/messages")
+	helper.BindSSEPingFormat(c)
+
+	streamStarted := false
+	release, err := helper.waitForSlotWithPingTimeout(c, "account", 101, 2, time.Second, true, &streamStarted, true)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	defer release()
+
+	written := rec.Body.String()
+	require.NotEmpty(t, written)
+	require.Empty(t, strings.ReplaceAll(written, string(SSEPingFormatClaude), ""))
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, written, rec.Body.String())
+}
+
+func TestResolveSSEPingFormat_DefaultKeepsRouteFormat(t *testing.T) {
+	require.Equal(t, SSEPingFormatClaude, resolveSSEPingFormat(config.SSEPingFormatDefault, SSEPingFormatClaude))
+	require.Equal(t, SSEPingFormatComment, resolveSSEPingFormat(config.SSEPingFormatDefault, SSEPingFormatComment))
+}
+
+func TestIsSpecCompliantSSEPing(t *testing.T) {
+	require.True(t, isSpecCompliantSSEPing(SSEPingFormatNone))
+	require.True(t, isSpecCompliantSSEPing(SSEPingFormatClaude))
+	require.True(t, isSpecCompliantSSEPing(SSEPingFormatComment))
+	require.True(t, isSpecCompliantSSEPing(SSEPingFormatEvent))
+	require.False(t, isSpecCompliantSSEPing(SSEPingFormat("ping\n\n")))
+	require.False(t, isSpecCompliantSSEPing(SSEPingFormat("data: {}\n")))
+
+	helper := NewConcurrencyHelper(nil, SSEPingFormat("ping"), time.Second)
+	require.Equal(t, SSEPingFormatComment, helper.pingFormat)
+}
+
 func TestWaitForSlotWithPingTimeout_ParentContextCanceled(t *testing.T) {
 	cache := &helperConcurrencyCacheStub{
 		accountSeq: []bool{false},
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const openAIResponsesIdempotencyScope = "openai_responses"
+
+// responseCaptureWriter 在写给客户端的同时缓存最终响应（幂等重放、响应缓存共用），超过上限后停止缓存并标记溢出。
+type responseCaptureWriter struct {
+	gin.ResponseWriter
+	limit    int
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (w *responseCaptureWriter) capture(n int, write func()) {
+	if w.overflow {
+		return
+	}
+	if w.buf.Len()+n > w.limit {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	write()
+}
+
+func (w *responseCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(len(b), func() { _, _ = w.buf.Write(b) })
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseCaptureWriter) WriteString(s string) (int, error) {
+	w.capture(len(s), func() { _, _ = w.buf.WriteString(s) })
+	return w.ResponseWriter.WriteString(s)
+}
+
+// beginIdempotentRequest 处理 Idempotency-Key 请求头。
+// handled=true 表示响应已写出（重放或拒绝），调用方直接返回；
+// 否则调用方须在请求结束时调用 finish（可能为 nil）保存或释放幂等记录。
+func (h *OpenAIGatewayHandler) beginIdempotentRequest(c *gin.Context, scope string, apiKeyID int64, body []byte, stream bool, reqLog *zap.Logger) (finish func(), handled bool) {
+	rawKey := strings.TrimSpace(c.GetHeader(service.IdempotencyKeyHeader))
+	if rawKey == "" || !h.idempotencyService.Enabled() {
+		return nil, false
+	}
+	if stream {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(service.ErrGatewayIdempotencyStreaming))
+		return nil, true
+	}
+
+	claim, replay, err := h.idempotencyService.Begin(c.Request.Context(), scope, apiKeyID, rawKey, body)
+	if err != nil {
+		reqLog.Info("openai.idempotency_rejected", zap.Error(err))
+		if errors.Is(err, service.ErrGatewayIdempotencyInProgress) {
+			c.Header("Retry-After", "1")
+		}
+		status := infraerrors.Code(err)
+		if status < http.StatusBadRequest || status >= http.StatusInternalServerError {
+			status = http.StatusConflict
+		}
+		h.errorResponse(c, status, "invalid_request_error", infraerrors.Message(err))
+		return nil, true
+	}
+	if replay != nil {
+		for name, value := range replay.Headers {
+			c.Header(name, value)
+		}
+		c.Header(service.IdempotencyReplayedHeader, "true")
+		c.Status(replay.Status)
+		_, _ = c.Writer.Write(replay.Body)
+		return nil, true
+	}
+	if claim == nil {
+		return nil, false
+	}
+
+	originalWriter := c.Writer
+	capture := &responseCaptureWriter{ResponseWriter: originalWriter, limit: h.cfg.Gateway.Idempotency.MaxBodyBytes}
+	c.Writer = capture
+	return func() {
+		c.Writer = originalWriter
+		ctx := context.WithoutCancel(c.Request.Context())
+		if capture.overflow || !capture.Written() {
+			claim.Abort(ctx)
+			return
+		}
+		claim.Complete(ctx, capture.Status(), capture.Header(), bytes.Clone(capture.buf.Bytes()))
+	}, false
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type idempotencyCacheStub struct {
+	mu      sync.Mutex
+	records map[string][]byte
+}
+
+func (c *idempotencyCacheStub) CreateIdempotencyRecord(_ context.Context, key string, value []byte, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.records[key]; ok {
+		return false, nil
+	}
+	c.records[key] = value
+	return true, nil
+}
+
+func (c *idempotencyCacheStub) GetIdempotencyRecord(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.records[key], nil
+}
+
+func (c *idempotencyCacheStub) SetIdempotencyRecord(_ context.Context, key string, value []byte, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records[key] = value
+	return nil
+}
+
+func (c *idempotencyCacheStub) DeleteIdempotencyRecord(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.records, key)
+	return nil
+}
+
+func newIdempotencyTestHandler() *OpenAIGatewayHandler {
+	cfg := &config.Config{}
+	cfg.Gateway.Idempotency = config.GatewayIdempotencyConfig{
+		Enabled:                true,
+		WindowSeconds:          3600,
+		MaxBodyBytes:           1024,
+		InFlightTimeoutSeconds: 60,
+	}
+	cache := &idempotencyCacheStub{records: make(map[string][]byte)}
+	return &OpenAIGatewayHandler{idempotencyService: service.NewGatewayIdempotencyService(cache, cfg), cfg: cfg}
+}
+
+func runIdempotentRequest(h *OpenAIGatewayHandler, key, body string, stream bool, upstream func(c *gin.Context)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(body))
+	c.Request.Header.Set(service.IdempotencyKeyHeader, key)
+
+	finish, handled := h.beginIdempotentRequest(c, openAIResponsesIdempotencyScope, 1, []byte(body), stream, zap.NewNop())
+	if handled {
+		return w
+	}
+	if finish != nil {
+		defer finish()
+	}
+	upstream(c)
+	return w
+}
+
+func TestBeginIdempotentRequest_ReplaysWithoutUpstream(t *testing.T) {
+	h := newIdempotencyTestHandler()
+	calls := 0
+	upstream := func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"id": "resp_1"})
+	}
+	body := `{"model":"gpt-5","input":"hi"}`
+
+	first := runIdempotentRequest(h, "key-1", body, false, upstream)
+	require.Equal(t, http.StatusOK, first.Code)
+
+	second := runIdempotentRequest(h, "key-1", body, false, upstream)
+	require.Equal(t, 1, calls)
+	require.Equal(t, http.StatusOK, second.Code)
+	require.Equal(t, first.Body.String(), second.Body.String())
+	require.Equal(t, "true", second.Header().Get(service.IdempotencyReplayedHeader))
+	require.Contains(t, second.Header().Get("Content-Type"), "application/json")
+}
+
+func TestBeginIdempotentRequest_ConflictOnDifferentBody(t *testing.T) {
+	h := newIdempotencyTestHandler()
+	upstream := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": "resp_1"}) }
+
+	runIdempotentRequest(h, "key-1", `{"input":"a"}`, false, upstream)
+	w := runIdempotentRequest(h, "key-1", `{"input":"b"}`, false, upstream)
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestBeginIdempotentRequest_RejectsStreaming(t *testing.T) {
+	h := newIdempotencyTestHandler()
+	w := runIdempotentRequest(h, "key-1", `{"stream":true}`, true, func(c *gin.Context) {
+		t.Fatal("streaming request must not reach upstream")
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "not supported for streaming")
+}
+
+func TestBeginIdempotentRequest_OversizedResponseNotStored(t *testing.T) {
+	h := newIdempotencyTestHandler()
+	calls := 0
+	upstream := func(c *gin.Context) {
+		calls++
+		c.String(http.StatusOK, strings.Repeat("x", 2048))
+	}
+
+	runIdempotentRequest(h, "key-1", `{}`, false, upstream)
+	w := runIdempotentRequest(h, "key-1", `{}`, false, upstream)
+	require.Equal(t, 2, calls)
+	require.Empty(t, w.Header().Get(service.IdempotencyReplayedHeader))
+}
@@ -0,0 +1,51 @@
+//go:build unit
+
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestGatewayHandlerCountTokens_RejectsModelOutsideAPIKeyAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, upstream, _, apiKey := newCountTokensTestHandler(t, 1)
+	apiKey.AllowedModels = []string{"claude-opus-4*"}
+
+	c, rec := newCountTokensTestContext(apiKey)
+	h.CountTokens(c)
+
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	require.Equal(t, "permission_error", gjson.Get(rec.Body.String(), "error.type").String())
+	require.Empty(t, upstream.bodies)
+}
+
+func TestGatewayHandlerMessages_RejectsModelOutsideAPIKeyAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, upstream, _, apiKey := newCountTokensTestHandler(t, 1)
+	apiKey.AllowedModels = []string{"claude-opus-4*"}
+
+	c, rec := newCountTokensTestContext(apiKey)
+	c.Request.URL.Path = "/v1/messages"
+	h.Messages(c)
+
+	require.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	require.Equal(t, "permission_error", gjson.Get(rec.Body.String(), "error.type").String())
+	require.Empty(t, upstream.bodies)
+}
+
+func TestGatewayHandlerCountTokens_AllowsModelMatchingAPIKeyAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, upstream, _, apiKey := newCountTokensTestHandler(t, 1)
+	apiKey.AllowedModels = []string{"claude-sonnet-4*"}
+
+	c, rec := newCountTokensTestContext(apiKey)
+	h.CountTokens(c)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, upstream.bodies, 1)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// modelFallbackState 单次请求的分组模型降级进度。
+// 原模型选不到账号时按分组规则依次切换到下一个降级模型；每个降级模型只尝试一次。
+// 不在 API Key 模型白名单内的降级模型会被剔除，全部剔除时调用方返回原始的选号错误。
+type modelFallbackState struct {
+	requestedModel string
+	candidates     []string
+	next           int
+	mask           bool
+	maskWriter     *modelFallbackMaskWriter
+}
+
+func newModelFallbackState(apiKey *service.APIKey, requestedModel string) *modelFallbackState {
+	state := &modelFallbackState{requestedModel: requestedModel}
+	if apiKey == nil || apiKey.Group == nil {
+		return state
+	}
+	for _, candidate := range apiKey.Group.ModelFallbackCandidates(requestedModel) {
+		if apiKey.IsModelAllowed(candidate) {
+			state.candidates = append(state.candidates, candidate)
+		}
+	}
+	state.mask = apiKey.Group.ModelFallbackConfig.MaskFallback
+	return state
+}
+
+// Next 返回下一个待尝试的降级模型；规则耗尽时返回 false。
+func (s *modelFallbackState) Next() (string, bool) {
+	if s == nil || s.next >= len(s.candidates) {
+		return "", false
+	}
+	model := s.candidates[s.next]
+	s.next++
+	return model, true
+}
+
+// FallbackFromModel 已发生降级时返回客户端原始请求模型，否则返回空串（用于 usage 记录）。
+func (s *modelFallbackState) FallbackFromModel() string {
+	if s == nil || s.next == 0 {
+		return ""
+	}
+	return s.requestedModel
+}
+
+// switchTo 切换到降级模型：用 sjson 改写请求体 model 字段；开启 mask_fallback 时包装 c.Writer，
+// 把响应中的降级模型名回写为客户端请求的模型。
+func (s *modelFallbackState) switchTo(c *gin.Context, body []byte, model string) []byte {
+	body = service.ReplaceModelInBody(body, model)
+	if s.mask {
+		if s.maskWriter == nil {
+			s.maskWriter = &modelFallbackMaskWriter{ResponseWriter: c.Writer, requested: s.requestedModel}
+			c.Writer = s.maskWriter
+		}
+		s.maskWriter.served = model
+		s.maskWriter.upstream = ""
+	}
+	return body
+}
+
+// maskChannelMapping 降级模型经渠道映射改写为其他上游模型时，响应中的上游模型名同样回写为原请求模型。
+func (s *modelFallbackState) maskChannelMapping(mapping service.ChannelMappingResult) {
+	if s == nil || s.maskWriter == nil || !mapping.Mapped {
+		return
+	}
+	s.maskWriter.upstream = mapping.MappedModel
+}
+
+// modelFallbackMaskWriter 在写回客户端前把响应 chunk 中的降级模型名改写为原请求模型。
+// 改写会改变长度，因此丢弃上游透传的 Content-Length。
+type modelFallbackMaskWriter struct {
+	gin.ResponseWriter
+	served    string
+	upstream  string
+	requested string
+}
+
+func (w *modelFallbackMaskWriter) WriteHeader(code int) {
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *modelFallbackMaskWriter) Write(b []byte) (int, error) {
+	w.Header().Del("Content-Length")
+	masked := service.MaskFallbackModel(b, w.served, w.requested)
+	masked = service.MaskFallbackModel(masked, w.upstream, w.requested)
+	if _, err := w.ResponseWriter.Write(masked); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *modelFallbackMaskWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,96 @@
+//go:build unit
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	middleware "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// 降级目标模型只有 sonnet 账号可服务（warmup 拦截，不依赖上游）；
+// Key 白名单不含降级目标时不得降级，返回原模型的选号错误。
+func TestGatewayHandlerMessages_ModelFallbackRespectsKeyAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, tc := range []struct {
+		name          string
+		allowedModels []string
+		wantStatus    int
+	}{
+		{name: "fallback_allowed", allowedModels: []string{"claude-opus-*", "claude-sonnet-*"}, wantStatus: http.StatusOK},
+		{name: "fallback_not_allowed", allowedModels: []string{"claude-opus-*"}, wantStatus: http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			groupID := int64(2101)
+			accountID := int64(1101)
+			group := &service.Group{
+				ID:       groupID,
+				Hydrated: true,
+				Platform: service.PlatformAnthropic,
+				Status:   service.StatusActive,
+				ModelFallbackConfig: service.GroupModelFallbackConfig{
+					Rules: []service.GroupModelFallbackRule{{Model: "claude-opus-4-6", Fallbacks: []string{"claude-sonnet-4-5"}}},
+				},
+			}
+			account := &service.Account{
+				ID:       accountID,
+				Name:     "ag-sonnet",
+				Platform: service.PlatformAntigravity,
+				Type:     service.AccountTypeOAuth,
+				Credentials: map[string]any{
+					"access_token":              "tok_xxx",
+					"intercept_warmup_requests": true,
+					"model_mapping":             map[string]any{"claude-sonnet-4-5": "claude-sonnet-4-5"},
+				},
+				Extra:         map[string]any{"mixed_scheduling": true},
+				Concurrency:   1,
+				Priority:      1,
+				Status:        service.StatusActive,
+				Schedulable:   true,
+				AccountGroups: []service.AccountGroup{{AccountID: accountID, GroupID: groupID}},
+			}
+
+			h, cleanup := newTestGatewayHandler(t, group, []*service.Account{account})
+			defer cleanup()
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			body := []byte(`{"model":"claude-opus-4-6","max_tokens":256,"messages":[{"role":"user","content":[{"type":"text","text":"Warmup"}]}]}`)
+			req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req.WithContext(context.WithValue(req.Context(), ctxkey.Group, group))
+
+			apiKey := &service.APIKey{
+				ID:            3101,
+				UserID:        4101,
+				GroupID:       &groupID,
+				Status:        service.StatusActive,
+				AllowedModels: tc.allowedModels,
+				User:          &service.User{ID: 4101, Concurrency: 10, Balance: 100},
+				Group:         group,
+			}
+			c.Set(string(middleware.ContextKeyAPIKey), apiKey)
+			c.Set(string(middleware.ContextKeyUser), middleware.AuthSubject{UserID: apiKey.UserID, Concurrency: 10})
+
+			h.Messages(c)
+
+			require.Equal(t, tc.wantStatus, rec.Code, rec.Body.String())
+			if tc.wantStatus != http.StatusOK {
+				_, selected := c.Get(opsAccountIDKey)
+				require.False(t, selected, "白名单外的降级模型不应参与选号")
+				require.Contains(t, gjson.Get(rec.Body.String(), "error.message").String(), "claude-opus-4-6")
+			}
+		})
+	}
+}
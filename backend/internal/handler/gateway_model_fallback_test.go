@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestModelFallbackState_TriesRulesInOrder(t *testing.T) {
+	group := &service.Group{ModelFallbackConfig: service.GroupModelFallbackConfig{
+		Rules: []service.GroupModelFallbackRule{
+			{Model: "claude-opus-4-6", Fallbacks: []string{"claude-sonnet-4-6", "claude-haiku-4-5"}},
+		},
+	}}
+	state := newModelFallbackState(&service.APIKey{Group: group}, "claude-opus-4-6")
+	require.Empty(t, state.FallbackFromModel())
+
+	next, ok := state.Next()
+	require.True(t, ok)
+	require.Equal(t, "claude-sonnet-4-6", next)
+	require.Equal(t, "claude-opus-4-6", state.FallbackFromModel())
+
+	next, ok = state.Next()
+	require.True(t, ok)
+	require.Equal(t, "claude-haiku-4-5", next)
+
+	_, ok = state.Next()
+	require.False(t, ok)
+
+	_, ok = newModelFallbackState(nil, "claude-opus-4-6").Next()
+	require.False(t, ok)
+}
+
+func TestModelFallbackState_SkipsCandidatesOutsideKeyAllowlist(t *testing.T) {
+	group := &service.Group{ModelFallbackConfig: service.GroupModelFallbackConfig{
+		Rules: []service.GroupModelFallbackRule{
+			{Model: "claude-opus-4-6", Fallbacks: []string{"claude-sonnet-4-6", "claude-haiku-4-5"}},
+		},
+	}}
+	apiKey := &service.APIKey{Group: group, AllowedModels: []string{"claude-opus-*", "claude-haiku-*"}}
+	state := newModelFallbackState(apiKey, "claude-opus-4-6")
+
+	next, ok := state.Next()
+	require.True(t, ok)
+	require.Equal(t, "claude-haiku-4-5", next)
+	_, ok = state.Next()
+	require.False(t, ok)
+
+	// 降级模型全部不在白名单内时不再降级
+	apiKey.AllowedModels = []string{"claude-opus-*"}
+	_, ok = newModelFallbackState(apiKey, "claude-opus-4-6").Next()
+	require.False(t, ok)
+}
+
+func TestModelFallbackState_SwitchToRewritesBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := []byte(`{"model":"claude-opus-4-6","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`)
+
+	for _, mask := range []bool{false, true} {
+		group := &service.Group{ModelFallbackConfig: service.GroupModelFallbackConfig{
+			Rules:        []service.GroupModelFallbackRule{{Model: "claude-opus-4-6", Fallbacks: []string{"claude-sonnet-4-6"}}},
+			MaskFallback: mask,
+		}}
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		state := newModelFallbackState(&service.APIKey{Group: group}, "claude-opus-4-6")
+
+		next, ok := state.Next()
+		require.True(t, ok)
+		rewritten := state.switchTo(c, body, next)
+		require.Equal(t, "claude-sonnet-4-6", gjson.GetBytes(rewritten, "model").String())
+		require.Equal(t, "hi", gjson.GetBytes(rewritten, "messages.0.content").String())
+
+		// mask_fallback 开启时响应中的降级模型名回写为原请求模型
+		c.Header("Content-Length", "52")
+		c.Data(http.StatusOK, "application/json", []byte(`{"id":"msg_1","model":"claude-sonnet-4-6","content":[]}`))
+		want := "claude-sonnet-4-6"
+		if mask {
+			want = "claude-opus-4-6"
+			require.Empty(t, w.Header().Get("Content-Length"))
+		}
+		require.Equal(t, want, gjson.Get(w.Body.String(), "model").String())
+	}
+}
+
+func TestModelFallbackMaskWriter_MasksStreamingEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	group := &service.Group{ModelFallbackConfig: service.GroupModelFallbackConfig{
+		Rules:        []service.GroupModelFallbackRule{{Model: "gpt-5", Fallbacks: []string{"gpt-5-mini", "gpt-5-nano"}}},
+		MaskFallback: true,
+	}}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	state := newModelFallbackState(&service.APIKey{Group: group}, "gpt-5")
+	for {
+		next, ok := state.Next()
+		if !ok {
+			break
+		}
+		state.switchTo(c, []byte(`{"model":"gpt-5"}`), next)
+	}
+
+	_, err := c.Writer.WriteString("data: {\"type\":\"response.created\",\"response\":{\"model\":\"gpt-5-nano\"}}\n\n")
+	require.NoError(t, err)
+	_, err = c.Writer.WriteString("data: {\"object\":\"chat.completion.chunk\",\"model\":\"gpt-5-nano\"}\n\n")
+	require.NoError(t, err)
+	require.NotContains(t, w.Body.String(), "gpt-5-nano")
+	require.Equal(t, 2, strings.Count(w.Body.String(), `"model":"gpt-5"`))
+	require.Equal(t, "gpt-5", state.FallbackFromModel())
+}
+
+// 降级模型经渠道映射转发到其他上游模型时，响应中的上游模型名同样回写为原请求模型
+func TestModelFallbackMaskWriter_MasksChannelMappedFallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	groupID := int64(7)
+	group := &service.Group{ID: groupID, ModelFallbackConfig: service.GroupModelFallbackConfig{
+		Rules:        []service.GroupModelFallbackRule{{Model: "gpt-5", Fallbacks: []string{"gpt-5-mini"}}},
+		MaskFallback: true,
+	}}
+	channelSvc := service.NewChannelService(&openAIWSUsageHandlerChannelRepoStub{
+		channels: []service.Channel{{
+			ID:           7704,
+			Name:         "fallback-channel",
+			Status:       service.StatusActive,
+			GroupIDs:     []int64{groupID},
+			ModelMapping: map[string]map[string]string{service.PlatformOpenAI: {"gpt-5-mini": "gpt-5.4-mini"}},
+		}},
+		groupPlatforms: map[int64]string{groupID: service.PlatformOpenAI},
+	}, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	state := newModelFallbackState(&service.APIKey{GroupID: &groupID, Group: group}, "gpt-5")
+	next, ok := state.Next()
+	require.True(t, ok)
+	state.switchTo(c, []byte(`{"model":"gpt-5"}`), next)
+	mapping, _ := channelSvc.ResolveChannelMappingAndRestrict(context.Background(), &groupID, next)
+	require.Equal(t, "gpt-5.4-mini", mapping.MappedModel)
+	state.maskChannelMapping(mapping)
+
+	_, err := c.Writer.WriteString("data: {\"type\":\"response.created\",\"response\":{\"model\":\"gpt-5.4-mini\"}}\n\n")
+	require.NoError(t, err)
+	_, err = c.Writer.WriteString("data: {\"object\":\"chat.completion.chunk\",\"model\":\"gpt-5-mini\"}\n\n")
+	require.NoError(t, err)
+	require.NotContains(t, w.Body.String(), "gpt-5.4-mini")
+	require.NotContains(t, w.Body.String(), "gpt-5-mini")
+	require.Equal(t, 2, strings.Count(w.Body.String(), `"model":"gpt-5"`))
+}
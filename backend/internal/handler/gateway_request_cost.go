@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// requestCostRejection 请求费用校验未通过时的错误响应
+type requestCostRejection struct {
+	Status  int
+	ErrType string
+	Message string
+}
+
+// extractRequestMaxCost 读取 X-Max-Cost 请求头 / 请求体 max_cost 字段，返回移除 max_cost 后的请求体（避免透传上游）。
+func extractRequestMaxCost(c *gin.Context, body []byte) (*float64, []byte, *requestCostRejection) {
+	maxCost, body, err := service.ExtractRequestMaxCost(c.GetHeader(service.MaxCostHeader), body)
+	if err != nil {
+		return nil, body, &requestCostRejection{
+			Status:  http.StatusBadRequest,
+			ErrType: "invalid_request_error",
+			Message: infraerrors.Message(err),
+		}
+	}
+	return maxCost, body, nil
+}
+
+// checkRequestCost 在选号转发前估算本次请求费用：
+//   - billingModel 为计费模型（渠道映射后按计费来源确定，见 ChannelMappingResult.EstimateBillingModel）；
+//   - API Key 开启费用预览时写入 X-Estimated-Cost 响应头；
+//   - 估算结果挂到请求 context，用量记录时与实际费用比对；
+//   - 估算费用超出 max_cost 返回 402，设置了 max_cost 但无法估算时返回 400。
+func checkRequestCost(c *gin.Context, svc *service.RequestCostService, apiKey *service.APIKey, billingModel string, body []byte, maxCost *float64, reqLog *zap.Logger) *requestCostRejection {
+	estimate, err := svc.Estimate(c.Request.Context(), apiKey, billingModel, body, maxCost)
+	return resolveRequestCostEstimate(c, apiKey, estimate, err, reqLog)
+}
+
+// checkEmbeddingsRequestCost 同 checkRequestCost，按 Embeddings 输入 token 估算（不计输出）。
+func checkEmbeddingsRequestCost(c *gin.Context, svc *service.RequestCostService, apiKey *service.APIKey, billingModel string, body []byte, maxCost *float64, reqLog *zap.Logger) *requestCostRejection {
+	estimate, err := svc.EstimateEmbeddings(c.Request.Context(), apiKey, billingModel, body, maxCost)
+	return resolveRequestCostEstimate(c, apiKey, estimate, err, reqLog)
+}
+
+// checkImagesRequestCost 同 checkRequestCost，按图片单价 × 张数估算。
+func checkImagesRequestCost(c *gin.Context, svc *service.RequestCostService, apiKey *service.APIKey, billingModel string, parsed *service.OpenAIImagesRequest, maxCost *float64, reqLog *zap.Logger) *requestCostRejection {
+	estimate, err := svc.EstimateImages(c.Request.Context(), apiKey, billingModel, parsed.SizeTier, parsed.N, maxCost)
+	return resolveRequestCostEstimate(c, apiKey, estimate, err, reqLog)
+}
+
+// resolveRequestCostEstimate 写入 X-Estimated-Cost 响应头与请求 context，并把估算错误映射为拒绝响应。
+func resolveRequestCostEstimate(c *gin.Context, apiKey *service.APIKey, estimate *service.RequestCostEstimate, err error, reqLog *zap.Logger) *requestCostRejection {
+	if estimate != nil {
+		// 超出上限时即使未开启预览也返回估算值，便于客户端调整 max_cost
+		if apiKey.CostPreviewEnabled || err != nil {
+			c.Header(service.EstimatedCostHeader, estimate.HeaderValue())
+		}
+		c.Request = c.Request.WithContext(service.WithRequestCostEstimate(c.Request.Context(), estimate))
+	}
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, service.ErrRequestCostExceeded) && estimate != nil && estimate.MaxCost != nil {
+		reqLog.Info("gateway.request_cost_exceeded",
+			zap.Float64("estimated_cost", estimate.Cost),
+			zap.Float64("max_cost", *estimate.MaxCost),
+		)
+		return &requestCostRejection{
+			Status:  http.StatusPaymentRequired,
+			ErrType: "cost_limit_exceeded",
+			Message: fmt.Sprintf("Estimated request cost $%.6f exceeds max_cost $%.6f", estimate.Cost, *estimate.MaxCost),
+		}
+	}
+	return &requestCostRejection{
+		Status:  infraerrors.Code(err),
+		ErrType: "invalid_request_error",
+		Message: infraerrors.Message(err),
+	}
+}
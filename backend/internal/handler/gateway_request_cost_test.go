@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+func newRequestCostTestService() *service.RequestCostService {
+	cfg := &config.Config{}
+	cfg.Default.RateMultiplier = 1
+	cfg.Gateway.RequestCost = config.GatewayRequestCostConfig{Enabled: true, DefaultOutputTokens: 4096, OverageFactor: 2}
+	return service.NewRequestCostService(service.NewBillingService(cfg, nil), nil, cfg)
+}
+
+// runRequestCostCheck 模拟网关 handler 的调用顺序：先提取 max_cost，再在转发前估算费用
+func runRequestCostCheck(t *testing.T, apiKey *service.APIKey, header, body string) (*httptest.ResponseRecorder, *gin.Context, []byte) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	if header != "" {
+		c.Request.Header.Set(service.MaxCostHeader, header)
+	}
+
+	maxCost, forwarded, rejection := extractRequestMaxCost(c, []byte(body))
+	if rejection == nil {
+		rejection = checkRequestCost(c, newRequestCostTestService(), apiKey, "claude-sonnet-4", forwarded, maxCost, zap.NewNop())
+	}
+	if rejection != nil {
+		c.JSON(rejection.Status, gin.H{"type": "error", "error": gin.H{"type": rejection.ErrType, "message": rejection.Message}})
+	}
+	return w, c, forwarded
+}
+
+func TestRequestCost_RejectsOverMaxCost(t *testing.T) {
+	body := `{"model":"claude-sonnet-4","max_tokens":1000,"max_cost":0.01,"messages":[{"role":"user","content":"hi"}]}`
+	w, _, _ := runRequestCostCheck(t, &service.APIKey{ID: 1}, "", body)
+
+	require.Equal(t, http.StatusPaymentRequired, w.Code)
+	require.Equal(t, "cost_limit_exceeded", gjson.Get(w.Body.String(), "error.type").String())
+	require.Contains(t, gjson.Get(w.Body.String(), "error.message").String(), "max_cost $0.010000")
+	require.NotEmpty(t, w.Header().Get(service.EstimatedCostHeader))
+}
+
+func TestRequestCost_InvalidMaxCost(t *testing.T) {
+	w, _, _ := runRequestCostCheck(t, &service.APIKey{ID: 1}, "-1", `{"model":"claude-sonnet-4"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "invalid_request_error", gjson.Get(w.Body.String(), "error.type").String())
+}
+
+// 未启用费用上限校验时，客户端设置的 max_cost 无法保证，必须拒绝而不是静默忽略
+func TestRequestCost_RejectsMaxCostWhenDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	disabled := service.NewRequestCostService(service.NewBillingService(&config.Config{}, nil), nil, &config.Config{})
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		body   string
+	}{
+		{name: "body", body: `{"model":"claude-sonnet-4","max_cost":1,"messages":[{"role":"user","content":"hi"}]}`},
+		{name: "header", header: "1", body: `{"model":"claude-sonnet-4","messages":[{"role":"user","content":"hi"}]}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(tc.body))
+			if tc.header != "" {
+				c.Request.Header.Set(service.MaxCostHeader, tc.header)
+			}
+			maxCost, forwarded, rejection := extractRequestMaxCost(c, []byte(tc.body))
+			require.Nil(t, rejection)
+			rejection = checkRequestCost(c, disabled, &service.APIKey{ID: 1}, "claude-sonnet-4", forwarded, maxCost, zap.NewNop())
+			require.NotNil(t, rejection)
+			require.Equal(t, http.StatusBadRequest, rejection.Status)
+			require.Equal(t, "invalid_request_error", rejection.ErrType)
+			require.Contains(t, rejection.Message, "max_cost not supported")
+		})
+	}
+
+	// 未设置 max_cost 时不受影响
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	require.Nil(t, checkRequestCost(c, disabled, &service.APIKey{ID: 1, CostPreviewEnabled: true}, "claude-sonnet-4", []byte(`{"model":"claude-sonnet-4"}`), nil, zap.NewNop()))
+	require.Empty(t, w.Header().Get(service.EstimatedCostHeader))
+}
+
+func TestRequestCost_EmitsPreviewHeaderAndAttachesEstimate(t *testing.T) {
+	body := `{"model":"claude-sonnet-4","max_tokens":100,"max_cost":5,"messages":[{"role":"user","content":"hi"}]}`
+	w, c, forwarded := runRequestCostCheck(t, &service.APIKey{ID: 1, CostPreviewEnabled: true}, "", body)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.False(t, gjson.GetBytes(forwarded, "max_cost").Exists(), "max_cost must not be forwarded upstream")
+	estimate := service.RequestCostEstimateFromContext(c.Request.Context())
+	require.NotNil(t, estimate)
+	require.Equal(t, estimate.HeaderValue(), w.Header().Get(service.EstimatedCostHeader))
+}
+
+func TestRequestCost_NoPreviewHeaderWhenDisabledOnKey(t *testing.T) {
+	body := `{"model":"claude-sonnet-4","max_tokens":100,"messages":[{"role":"user","content":"hi"}]}`
+	w, c, _ := runRequestCostCheck(t, &service.APIKey{ID: 1}, "2", body)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get(service.EstimatedCostHeader))
+	// 设置了上限时仍记录估算，用于用量记录的偏差标记
+	require.NotNil(t, service.RequestCostEstimateFromContext(c.Request.Context()))
+}
+
+func TestRequestCost_ImagesEmitPreviewHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/images/generations", nil)
+
+	price := 0.05
+	apiKey := &service.APIKey{ID: 1, CostPreviewEnabled: true, Group: &service.Group{ImagePrice1K: &price}}
+	parsed := &service.OpenAIImagesRequest{Model: "gpt-image-1", N: 2, SizeTier: "1K"}
+	require.Nil(t, checkImagesRequestCost(c, newRequestCostTestService(), apiKey, parsed.Model, parsed, nil, zap.NewNop()))
+	require.Equal(t, "0.100000", w.Header().Get(service.EstimatedCostHeader))
+	require.NotNil(t, service.RequestCostEstimateFromContext(c.Request.Context()))
+}
+
+func TestRequestCost_EmbeddingsEmitPreviewHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", nil)
+
+	body := []byte(`{"model":"doubao-embedding-vision","input":"hello"}`)
+	require.Nil(t, checkEmbeddingsRequestCost(c, newRequestCostTestService(), &service.APIKey{ID: 1, CostPreviewEnabled: true}, "doubao-embedding-vision", body, nil, zap.NewNop()))
+	require.NotEmpty(t, w.Header().Get(service.EstimatedCostHeader))
+	estimate := service.RequestCostEstimateFromContext(c.Request.Context())
+	require.NotNil(t, estimate)
+	require.Zero(t, estimate.OutputTokens)
+}
+
+// 分组 instructions 注入发生在费用预估之前，三个 OpenAI 入口的预估 token 均包含注入文本
+func TestRequestCost_CountsGroupInstructionInjection(t *testing.T) {
+	injection := strings.Repeat("Follow the compliance policy. ", 20)
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		handle func(h *OpenAIGatewayHandler, c *gin.Context)
+	}{
+		{name: "responses", path: "/openai/v1/responses", body: `{"model":"gpt-5","input":"hello"}`, handle: (*OpenAIGatewayHandler).Responses},
+		{name: "chat_completions", path: "/openai/v1/chat/completions", body: `{"model":"gpt-5","messages":[{"role":"user","content":"hello"}]}`, handle: (*OpenAIGatewayHandler).ChatCompletions},
+		{name: "messages", path: "/openai/v1/messages", body: `{"model":"gpt-5","max_tokens":16,"messages":[{"role":"user","content":"hello"}]}`, handle: (*OpenAIGatewayHandler).Messages},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputTokens := func(enabled bool) int {
+				c, rec := newOpenAICompatibleStreamValidationContext(tt.path, tt.body, false)
+				c.Request.Header.Set(service.MaxCostHeader, "0.0000001")
+				apiKey := c.MustGet("api_key").(*service.APIKey)
+				apiKey.Group.RateMultiplier = 1
+				apiKey.Group.AllowMessagesDispatch = true
+				apiKey.Group.InstructionInjectionConfig = service.GroupInstructionInjectionConfig{
+					Enabled: enabled,
+					Mode:    service.InstructionInjectionModePrepend,
+					Text:    injection,
+				}
+				h := newOpenAIDryRunTestHandler(t)
+				h.requestCostService = newRequestCostTestService()
+
+				tt.handle(h, c)
+
+				require.Equal(t, http.StatusPaymentRequired, rec.Code, rec.Body.String())
+				require.Equal(t, enabled, c.GetBool(service.OpsInstructionsInjectedKey))
+				estimate := service.RequestCostEstimateFromContext(c.Request.Context())
+				require.NotNil(t, estimate)
+				return estimate.InputTokens
+			}
+			require.Greater(t, inputTokens(true), inputTokens(false))
+		})
+	}
+}
+
+// 渠道映射到不同价格的模型时，费用预估按计费模型（映射后模型）定价
+func TestRequestCost_PricesChannelMappedBillingModel(t *testing.T) {
+	groupID := int64(7)
+	for _, tt := range []struct {
+		source    string
+		wantModel string
+	}{
+		{source: service.BillingModelSourceChannelMapped, wantModel: "gpt-5.4"},
+		{source: service.BillingModelSourceRequested, wantModel: "gpt-5.4-nano"},
+	} {
+		t.Run(tt.source, func(t *testing.T) {
+			channelSvc := service.NewChannelService(&openAIWSUsageHandlerChannelRepoStub{
+				channels: []service.Channel{{
+					ID:                 7703,
+					Name:               "cost-channel",
+					Status:             service.StatusActive,
+					GroupIDs:           []int64{groupID},
+					ModelMapping:       map[string]map[string]string{service.PlatformOpenAI: {"gpt-5.4-nano": "gpt-5.4"}},
+					BillingModelSource: tt.source,
+				}},
+				groupPlatforms: map[int64]string{groupID: service.PlatformOpenAI},
+			}, nil, nil, nil)
+			c, rec := newOpenAICompatibleStreamValidationContext("/openai/v1/responses", `{"model":"gpt-5.4-nano","input":"hello"}`, false)
+			c.Request.Header.Set(service.MaxCostHeader, "0.0000001")
+			c.MustGet("api_key").(*service.APIKey).Group.RateMultiplier = 1
+			h := newOpenAIDryRunTestHandler(t)
+			h.requestCostService = newRequestCostTestService()
+			h.gatewayService = service.NewOpenAIGatewayService(nil, nil, nil, nil, nil, nil, nil, &config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, channelSvc, nil, nil, nil)
+
+			h.Responses(c)
+
+			require.Equal(t, http.StatusPaymentRequired, rec.Code, rec.Body.String())
+			estimate := service.RequestCostEstimateFromContext(c.Request.Context())
+			require.NotNil(t, estimate)
+			require.Equal(t, tt.wantModel, estimate.Model)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// responseCacheCapture 响应缓存未命中时捕获最终响应；转发成功后 Commit 写入缓存，请求结束时 Release 还原 writer。
+// 方法均可在 nil 上调用（请求不可缓存时）。
+type responseCacheCapture struct {
+	c              *gin.Context
+	svc            *service.GatewayResponseCacheService
+	key            string
+	originalWriter gin.ResponseWriter
+	writer         *responseCaptureWriter
+	committed      bool
+	accountID      int64
+	model          string
+}
+
+// beginResponseCache 处理响应缓存：
+//   - 命中：直接写出缓存的响应（X-Cache: hit）并记录命中的使用记录，handled=true；
+//   - 未命中：标记 X-Cache: miss 并开始捕获响应，调用方在转发成功后 Commit，并 defer Release；
+//   - 未启用、流式或不满足缓存条件的请求：返回 nil, false，按普通请求转发。
+func beginResponseCache(c *gin.Context, svc *service.GatewayResponseCacheService, scope string, apiKey *service.APIKey, body []byte, reqModel string, reqLog *zap.Logger) (capture *responseCacheCapture, handled bool) {
+	if apiKey == nil || !svc.Requested(apiKey, c.GetHeader(service.ResponseCacheHeader)) {
+		return nil, false
+	}
+	key, ok := service.ResponseCacheKey(scope, apiKey.ID, body)
+	if !ok {
+		return nil, false
+	}
+
+	startTime := time.Now()
+	if cached := svc.Lookup(c.Request.Context(), key); cached != nil {
+		for name, value := range cached.Headers {
+			c.Header(name, value)
+		}
+		c.Header(service.ResponseCacheHeader, service.ResponseCacheHitValue)
+		c.Status(cached.Status)
+		_, _ = c.Writer.Write(cached.Body)
+		reqLog.Debug("gateway.response_cache_hit", zap.String("scope", scope), zap.Int64("cached_account_id", cached.AccountID))
+		subscription, _ := middleware2.GetSubscriptionFromContext(c)
+		svc.RecordHit(c.Request.Context(), &service.ResponseCacheHitInput{
+			APIKey:          apiKey,
+			Subscription:    subscription,
+			Response:        cached,
+			RequestedModel:  reqModel,
+			InboundEndpoint: GetInboundEndpoint(c),
+			UserAgent:       c.GetHeader("User-Agent"),
+			IPAddress:       ip.GetClientIP(c),
+			Duration:        time.Since(startTime),
+		})
+		return nil, true
+	}
+
+	c.Header(service.ResponseCacheHeader, service.ResponseCacheMissValue)
+	capture = &responseCacheCapture{
+		c:              c,
+		svc:            svc,
+		key:            key,
+		originalWriter: c.Writer,
+		writer:         &responseCaptureWriter{ResponseWriter: c.Writer, limit: svc.MaxBodyBytes()},
+	}
+	c.Writer = capture.writer
+	return capture, false
+}
+
+// Commit 标记转发成功并记录产生响应的账号与模型，Release 时写入缓存
+func (r *responseCacheCapture) Commit(accountID int64, model string) {
+	if r == nil {
+		return
+	}
+	r.committed = true
+	r.accountID = accountID
+	r.model = model
+}
+
+// Release 还原 writer；已 Commit 且响应完整捕获时写入缓存（仅保存 2xx，见 GatewayResponseCacheService.Store）
+func (r *responseCacheCapture) Release() {
+	if r == nil {
+		return
+	}
+	r.c.Writer = r.originalWriter
+	if !r.committed || r.writer.overflow || !r.writer.Written() {
+		return
+	}
+	ctx := context.WithoutCancel(r.c.Request.Context())
+	r.svc.Store(ctx, r.key, r.writer.Status(), r.writer.Header(), bytes.Clone(r.writer.buf.Bytes()), r.accountID, r.model)
+}
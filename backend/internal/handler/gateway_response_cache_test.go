@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type responseCacheStub struct {
+	mu      sync.Mutex
+	records map[string][]byte
+}
+
+func (c *responseCacheStub) GetCachedResponse(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.records[key], nil
+}
+
+func (c *responseCacheStub) SetCachedResponse(_ context.Context, key string, value []byte, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records[key] = value
+	return nil
+}
+
+func newResponseCacheTestService() (*service.GatewayResponseCacheService, *responseCacheStub) {
+	cfg := &config.Config{}
+	cfg.Gateway.ResponseCache = config.GatewayResponseCacheConfig{Enabled: true, TTLSeconds: 60, MaxBodyBytes: 1024}
+	cache := &responseCacheStub{records: make(map[string][]byte)}
+	return service.NewGatewayResponseCacheService(cache, nil, cfg), cache
+}
+
+// runResponseCacheRequest 模拟一次经过响应缓存的请求；upstream 为 nil 表示不应访问上游
+func runResponseCacheRequest(t *testing.T, svc *service.GatewayResponseCacheService, body, cacheHeader string, upstream func(c *gin.Context) bool) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	if cacheHeader != "" {
+		c.Request.Header.Set(service.ResponseCacheHeader, cacheHeader)
+	}
+	apiKey := &service.APIKey{ID: 1, UserID: 2}
+
+	capture, handled := beginResponseCache(c, svc, service.ResponseCacheScopeOpenAIChatCompletions, apiKey, []byte(body), "gpt-5", zap.NewNop())
+	if handled {
+		return w
+	}
+	defer capture.Release()
+	if upstream(c) {
+		capture.Commit(9, "gpt-5")
+	}
+	return w
+}
+
+func TestBeginResponseCache_MissThenHit(t *testing.T) {
+	svc, cache := newResponseCacheTestService()
+	body := `{"model":"gpt-5","temperature":0,"messages":[{"role":"user","content":"hi"}]}`
+	upstreamCalls := 0
+	upstream := func(c *gin.Context) bool {
+		upstreamCalls++
+		c.JSON(http.StatusOK, gin.H{"id": "chatcmpl-1"})
+		return true
+	}
+
+	first := runResponseCacheRequest(t, svc, body, "true", upstream)
+	require.Equal(t, http.StatusOK, first.Code)
+	require.Equal(t, service.ResponseCacheMissValue, first.Header().Get(service.ResponseCacheHeader))
+	require.Len(t, cache.records, 1)
+
+	second := runResponseCacheRequest(t, svc, body, "true", upstream)
+	require.Equal(t, 1, upstreamCalls)
+	require.Equal(t, http.StatusOK, second.Code)
+	require.Equal(t, service.ResponseCacheHitValue, second.Header().Get(service.ResponseCacheHeader))
+	require.Equal(t, first.Body.String(), second.Body.String())
+	require.Contains(t, second.Header().Get("Content-Type"), "application/json")
+}
+
+func TestBeginResponseCache_SkipsUncacheableResponses(t *testing.T) {
+	svc, cache := newResponseCacheTestService()
+	body := `{"model":"gpt-5","temperature":0,"messages":[]}`
+
+	// 上游失败：未 Commit，不缓存
+	w := runResponseCacheRequest(t, svc, body, "true", func(c *gin.Context) bool {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "bad gateway"})
+		return false
+	})
+	require.Equal(t, http.StatusBadGateway, w.Code)
+	require.Empty(t, cache.records)
+
+	// 流式请求不参与缓存，也不回写 X-Cache
+	streamBody := `{"model":"gpt-5","temperature":0,"stream":true,"messages":[]}`
+	w = runResponseCacheRequest(t, svc, streamBody, "true", func(c *gin.Context) bool {
+		c.String(http.StatusOK, "data: [DONE]\n\n")
+		return true
+	})
+	require.Empty(t, w.Header().Get(service.ResponseCacheHeader))
+	require.Empty(t, cache.records)
+
+	// 未开启（API Key 未启用且请求头未要求）时不缓存
+	w = runResponseCacheRequest(t, svc, body, "", func(c *gin.Context) bool {
+		c.JSON(http.StatusOK, gin.H{"id": "chatcmpl-2"})
+		return true
+	})
+	require.Empty(t, w.Header().Get(service.ResponseCacheHeader))
+	require.Empty(t, cache.records)
+}
@@ -169,6 +169,10 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 
 	stream := action == "streamGenerateContent"
 	reqLog = reqLog.With(zap.String("model", modelName), zap.String("action", action), zap.Bool("stream", stream))
+	if apiKeyModelDenied(apiKey, modelName, reqLog) {
+		googleError(c, http.StatusForbidden, service.APIKeyModelNotAllowedMessage(modelName))
+		return
+	}
 
 	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
 	if err != nil {
@@ -183,6 +187,11 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		googleError(c, http.StatusBadRequest, "Request body is empty")
 		return
 	}
+	maxCost, body, rejection := extractRequestMaxCost(c, body)
+	if rejection != nil {
+		googleError(c, rejection.Status, rejection.Message)
+		return
+	}
 
 	setOpsRequestContext(c, modelName, stream)
 	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(stream, false)))
@@ -194,6 +203,18 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 
 	// 解析渠道级模型映射
 	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, modelName)
+	if apiKeyMappedModelDenied(apiKey, channelMapping, reqLog) {
+		googleError(c, http.StatusForbidden, service.APIKeyModelNotAllowedMessage(modelName))
+		return
+	}
+
+	// countTokens 不计费，不做费用预估
+	if action != "countTokens" {
+		if rejection := checkRequestCost(c, h.requestCostService, apiKey, channelMapping.EstimateBillingModel(modelName), body, maxCost, reqLog); rejection != nil {
+			googleError(c, rejection.Status, rejection.Message)
+			return
+		}
+	}
 	reqModel := modelName // 保存映射前的原始模型名
 	if channelMapping.Mapped {
 		modelName = channelMapping.MappedModel
@@ -414,6 +435,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 			if err != nil {
 				reqLog.Warn("gemini.account_wait_counter_increment_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 			} else if !canWait {
+				recordOpsSlotAcquire(c, "account", slotAcquirePathQueueFull, 0)
 				reqLog.Info("gemini.account_wait_queue_full",
 					zap.Int64("account_id", account.ID),
 					zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
@@ -450,6 +472,8 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 			if err := h.gatewayService.BindStickySession(c.Request.Context(), apiKey.GroupID, sessionKey, account.ID); err != nil {
 				reqLog.Warn("gemini.bind_sticky_session_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 			}
+		} else {
+			recordOpsSlotAcquire(c, "account", slotAcquirePathFast, 0)
 		}
 		// 账号槽位/等待计数需要在超时或断开时安全回收
 		accountReleaseFunc = wrapReleaseOnDone(c.Request.Context(), accountReleaseFunc)
@@ -482,6 +506,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		if err != nil {
 			var failoverErr *service.UpstreamFailoverError
 			if errors.As(err, &failoverErr) {
+				h.gatewayService.ReportAccountRoutingResult(account.ID, false)
 				failoverAction := fs.HandleFailoverError(c.Request.Context(), h.gatewayService, account.ID, account.Platform, failoverErr)
 				switch failoverAction {
 				case FailoverContinue:
@@ -497,6 +522,10 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 			reqLog.Error("gemini.forward_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 			return
 		}
+		h.gatewayService.ReportAccountRoutingResult(account.ID, true)
+		if upstreamLatencyMs, ok := getContextInt64(c, service.OpsUpstreamLatencyMsKey); ok {
+			h.gatewayService.ReportAccountUpstreamLatency(account.ID, upstreamLatencyMs)
+		}
 
 		// 捕获请求信息（用于异步记录，避免在 goroutine 中访问 gin.Context）
 		userAgent := c.GetHeader("User-Agent")
@@ -585,13 +614,14 @@ func (h *GatewayHandler) handleGeminiFailoverExhausted(c *gin.Context, failoverE
 		googleError(c, http.StatusBadGateway, "Upstream request failed")
 		return
 	}
+	service.SetUpstreamRequestIDHeader(c, h.cfg, failoverErr.UpstreamRequestID)
 
 	statusCode := failoverErr.StatusCode
 	responseBody := failoverErr.ResponseBody
 
 	// 先检查透传规则
 	if h.errorPassthroughService != nil && len(responseBody) > 0 {
-		if rule := h.errorPassthroughService.MatchRule(service.PlatformGemini, statusCode, responseBody); rule != nil {
+		if rule := h.errorPassthroughService.MatchRuleWithHeaders(service.PlatformGemini, statusCode, failoverErr.ResponseHeaders, responseBody); rule != nil {
 			// 确定响应状态码
 			respCode := statusCode
 			if !rule.PassthroughCode && rule.ResponseCode != nil {
@@ -645,11 +675,11 @@ func (e *pathParseError) Error() string { return e.msg }
 
 func googleError(c *gin.Context, status int, message string) {
 	c.JSON(status, gin.H{
-		"error": gin.H{
+		"error": withErrorTraceID(c, gin.H{
 			"code":    status,
 			"message": message,
 			"status":  googleapi.HTTPStatusToGoogleStatus(status),
-		},
+		}),
 	})
 }
 
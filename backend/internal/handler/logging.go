@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -17,3 +18,15 @@ func requestLogger(c *gin.Context, component string, fields ...zap.Field) *zap.L
 	}
 	return base.With(fields...)
 }
+
+// withErrorTraceID 在错误响应的 error 对象中附带 trace_id，
+// 与 X-Trace-ID 响应头及日志中的 request_id 同值，便于用户反馈时直接定位日志与 Ops 记录。
+func withErrorTraceID(c *gin.Context, errObj gin.H) gin.H {
+	if c == nil || c.Request == nil {
+		return errObj
+	}
+	if traceID := service.TraceIDFromContext(c.Request.Context()); traceID != "" {
+		errObj["trace_id"] = traceID
+	}
+	return errObj
+}
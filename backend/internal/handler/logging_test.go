@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTraceIDTestContext(traceID string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	if traceID != "" {
+		req = req.WithContext(context.WithValue(req.Context(), ctxkey.RequestID, traceID))
+	}
+	c.Request = req
+	return c, w
+}
+
+func TestErrorResponses_IncludeTraceID(t *testing.T) {
+	writers := map[string]func(c *gin.Context){
+		"anthropic": func(c *gin.Context) {
+			(&GatewayHandler{}).errorResponse(c, http.StatusBadGateway, "upstream_error", "boom")
+		},
+		"openai": func(c *gin.Context) {
+			(&OpenAIGatewayHandler{}).errorResponse(c, http.StatusBadGateway, "upstream_error", "boom")
+		},
+		"google": func(c *gin.Context) {
+			googleError(c, http.StatusBadGateway, "boom")
+		},
+	}
+	for name, write := range writers {
+		t.Run(name, func(t *testing.T) {
+			c, w := newTraceIDTestContext("trace-abc")
+			write(c)
+
+			var body struct {
+				Error map[string]any `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Equal(t, "trace-abc", body.Error["trace_id"])
+			require.Equal(t, "boom", body.Error["message"])
+		})
+	}
+}
+
+func TestErrorResponses_OmitTraceIDWhenMissing(t *testing.T) {
+	c, w := newTraceIDTestContext("")
+	(&GatewayHandler{}).errorResponse(c, http.StatusBadGateway, "upstream_error", "boom")
+
+	var body struct {
+		Error map[string]any `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.NotContains(t, body.Error, "trace_id")
+}
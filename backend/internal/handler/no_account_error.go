@@ -20,6 +20,10 @@ import (
 //     model). Returning 503 here misleads operators and trips reverse-proxy
 //     health checks; 404 lets the client surface the real problem.
 //
+//   - 400 invalid_request_error — the request carries an account label
+//     selector (API key account_labels / X-Account-Labels header) and no
+//     account in the pool has all of those labels. Retrying cannot help.
+//
 //   - 503 api_error — accounts that could serve the model exist but are
 //     temporarily exhausted (rate limit, quota auto-pause, runtime block) OR
 //     the group has no accounts at all. Both stay on 503 because retrying
@@ -79,6 +83,13 @@ func classifyNoAccountError(
 	}
 
 	result := diag.DiagnoseModelAvailabilityForPlatform(ctx, apiKey.GroupID, routingModel, platform)
+	if result.NoLabelMatch {
+		return noAccountErrorClassification{
+			Status:  http.StatusBadRequest,
+			ErrType: "invalid_request_error",
+			Message: fmt.Sprintf("No accounts matching labels [%s] in this group", strings.Join(service.AccountLabelSelectorFromContext(ctx), ", ")),
+		}
+	}
 	if result.HasAccountsInPool && !result.HasModelSupport {
 		return noAccountErrorClassification{
 			Status:        http.StatusNotFound,
@@ -159,3 +159,20 @@ func TestClassifyNoAccountError_FromGin_NilContextStillSafe(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, cls.Status, "even with a nil gin context the classifier must still run and yield a coherent response")
 	require.True(t, cls.ModelNotFound)
 }
+
+func TestClassifyNoAccountError_NoLabelMatch_Returns400(t *testing.T) {
+	c := newTestGinContextWithRequest()
+	c.Request = c.Request.WithContext(service.WithAccountLabelSelector(c.Request.Context(), []string{"premium", "eu"}))
+	apiKey := &service.APIKey{GroupID: ptrInt64(7)}
+	diag := &fakeDiagnoser{resp: service.ModelAvailabilityDiagnosis{
+		HasAccountsInPool: true,
+		HasModelSupport:   true,
+		NoLabelMatch:      true,
+	}}
+
+	cls := classifyNoAccountErrorFromGin(c, diag, apiKey, "gpt-5", "gpt-5", service.PlatformOpenAI)
+
+	require.Equal(t, http.StatusBadRequest, cls.Status)
+	require.Equal(t, "invalid_request_error", cls.ErrType)
+	require.Equal(t, "No accounts matching labels [premium, eu] in this group", cls.Message)
+}
@@ -49,6 +49,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	byteCounter := newUsageByteCounter(c)
 	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
 	if err != nil {
 		if maxErr, ok := extractMaxBytesError(err); ok {
@@ -62,6 +63,11 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
 		return
 	}
+	maxCost, body, rejection := extractRequestMaxCost(c, body)
+	if rejection != nil {
+		h.errorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
 
 	if !gjson.ValidBytes(body) {
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
@@ -74,6 +80,9 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 	reqModel := modelResult.String()
+	if h.rejectIfModelNotAllowed(c, apiKey, reqModel, reqLog) {
+		return
+	}
 	reqStream, ok := parseOpenAICompatibleStream(body)
 	if !ok {
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", invalidStreamFieldTypeMessage)
@@ -89,16 +98,47 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		h.errorResponse(c, contentModerationStatus(decision), contentModerationErrorCode(decision), decision.Message)
 		return
 	}
+
+	// 分组 instructions 注入须在费用预估之前，使注入文本计入预估 token
+	body, reqLog = h.applyGroupInstructionInjection(c, apiKey, body, reqLog)
+
+	// 解析渠道级模型映射（费用预估按映射后的计费模型定价）
+	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+	if h.rejectIfMappedModelNotAllowed(c, apiKey, reqModel, channelMapping, reqLog) {
+		return
+	}
+
+	if rejection := checkRequestCost(c, h.requestCostService, apiKey, channelMapping.EstimateBillingModel(reqModel), body, maxCost, reqLog); rejection != nil {
+		h.errorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
+
+	// 会话封禁须先于响应缓存，避免被封禁的会话拿到缓存的应答
 	if h.rejectIfCyberSessionBlocked(c, apiKey, body, reqModel, cyberBlockFormatChat) {
 		return
 	}
 
-	// 解析渠道级模型映射
-	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+	// 响应缓存：temperature=0 的非流式确定性请求命中时直接返回，不访问上游、不计费
+	responseCache, handled := beginResponseCache(c, h.responseCacheService, service.ResponseCacheScopeOpenAIChatCompletions, apiKey, body, reqModel, reqLog)
+	if handled {
+		return
+	}
+	defer responseCache.Release()
+
+	// 命中 force_non_streaming_models 时上游按非流式转发，完成后再以单个 SSE chunk 回给流式客户端
+	forcedModel := reqModel
+	if channelMapping.Mapped {
+		forcedModel = channelMapping.MappedModel
+	}
+	forceNonStream := reqStream && h.shouldForceNonStreaming(forcedModel)
+	if forceNonStream {
+		reqLog.Debug("openai_chat_completions.force_non_streaming", zap.String("forced_model", forcedModel))
+	}
 
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
 	}
+	h.concurrencyHelper.BindSSEPingFormat(c)
 
 	subscription, _ := middleware2.GetSubscriptionFromContext(c)
 	requestPlatform := openAICompatibleRequestPlatform(apiKey)
@@ -124,7 +164,9 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
-	sessionHash := h.gatewayService.GenerateSessionHash(c, body)
+	sessionHash := h.applySessionAffinity(c, func() string {
+		return h.gatewayService.GenerateSessionHash(c, body)
+	})
 	promptCacheKey := h.gatewayService.ExtractSessionID(c, body)
 
 	maxAccountSwitches := h.maxAccountSwitches
@@ -132,6 +174,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 	failedAccountIDs := make(map[int64]struct{})
 	sameAccountRetryCount := make(map[int64]int)
 	var lastFailoverErr *service.UpstreamFailoverError
+	modelFallback := newModelFallbackState(apiKey, reqModel)
 
 	for {
 		reqLog.Debug("openai_chat_completions.account_selecting", zap.Int("excluded_account_count", len(failedAccountIDs)))
@@ -153,6 +196,24 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 				zap.Int("excluded_account_count", len(failedAccountIDs)),
 			)
 			if len(failedAccountIDs) == 0 {
+				// 分组模型降级：当前模型无可用账号时，按分组规则切换到下一个降级模型重新选号
+				if fallbackModel, ok := modelFallback.Next(); ok {
+					reqLog.Info("openai_chat_completions.model_fallback",
+						zap.String("from_model", reqModel),
+						zap.String("fallback_model", fallbackModel),
+						zap.Error(err),
+					)
+					body = modelFallback.switchTo(c, body, fallbackModel)
+					reqModel = fallbackModel
+					channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, fallbackModel)
+					modelFallback.maskChannelMapping(channelMapping)
+					forcedModel = reqModel
+					if channelMapping.Mapped {
+						forcedModel = channelMapping.MappedModel
+					}
+					forceNonStream = reqStream && h.shouldForceNonStreaming(forcedModel)
+					continue
+				}
 				cls := classifyNoAccountErrorFromGin(c, h.gatewayService, apiKey, reqModel, reqModel, service.PlatformOpenAI)
 				if !cls.ModelNotFound {
 					markOpsRoutingCapacityLimitedIfNoAvailable(c, err)
@@ -194,6 +255,18 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		if channelMapping.Mapped {
 			forwardBody = h.gatewayService.ReplaceModelInBody(body, channelMapping.MappedModel)
 		}
+		var forceWriter *forceNonStreamWriter
+		if forceNonStream {
+			nonStreamBody, bodyErr := forceNonStreamingChatBody(forwardBody)
+			if bodyErr != nil {
+				if accountReleaseFunc != nil {
+					accountReleaseFunc()
+				}
+				h.handleStreamingAwareError(c, http.StatusInternalServerError, "api_error", "Failed to process request", streamStarted)
+				return
+			}
+			forwardBody = nonStreamBody
+		}
 		writerSizeBeforeForward := c.Writer.Size()
 		result, err := func() (*service.OpenAIForwardResult, error) {
 			defer func() {
@@ -201,8 +274,22 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 					accountReleaseFunc()
 				}
 			}()
+			if forceNonStream {
+				var restoreWriter func()
+				forceWriter, restoreWriter = beginForceNonStream(c)
+				defer restoreWriter()
+			}
 			return h.gatewayService.ForwardAsChatCompletions(c.Request.Context(), c, account, forwardBody, promptCacheKey, "")
 		}()
+		if forceWriter != nil {
+			var failoverErr *service.UpstreamFailoverError
+			switch {
+			case err == nil:
+				forceWriter.writeChatCompletionAsSSE(c.Writer)
+			case !errors.As(err, &failoverErr):
+				forceWriter.writeVerbatim(c.Writer)
+			}
+		}
 		cyberBlockKeyChat := ""
 		if service.GetOpsCyberPolicy(c) != nil {
 			cyberBlockKeyChat = service.CyberSessionBlockKey(apiKey.ID, c, body)
@@ -268,6 +355,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 					reqLog.Warn("openai_chat_completions.upstream_failover_switching",
 						zap.Int64("account_id", account.ID),
 						zap.Int("upstream_status", failoverErr.StatusCode),
+						zap.String("upstream_request_id", failoverErr.UpstreamRequestID),
 						zap.Int("switch_count", switchCount),
 						zap.Int("max_switches", maxAccountSwitches),
 					)
@@ -298,7 +386,10 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		clientIP := ip.GetClientIP(c)
 		inboundEndpoint := GetInboundEndpoint(c)
 		upstreamEndpoint := resolveOpenAIUpstreamEndpoint(c, account)
+		responseCache.Commit(account.ID, reqModel)
 		quotaPlatform := service.QuotaPlatform(c.Request.Context(), apiKey)
+		requestBytes, responseBytes := byteCounter.RequestBytes(), byteCounter.ResponseBytes()
+		fallbackFromModel := modelFallback.FallbackFromModel()
 
 		cyberBlocked := service.GetOpsCyberPolicy(c) != nil
 		h.submitOpenAIUsageRecordTask(c.Request.Context(), result, func(ctx context.Context) {
@@ -314,6 +405,9 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 				IPAddress:          clientIP,
 				APIKeyService:      h.apiKeyService,
 				QuotaPlatform:      quotaPlatform,
+				RequestBytes:       requestBytes,
+				ResponseBytes:      responseBytes,
+				FallbackFromModel:  fallbackFromModel,
 				ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
 				CyberBlocked:       cyberBlocked,
 			}); err != nil {
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// dryRunHeader 请求头 X-Dry-Run: true 时仅校验请求，不选号、不占用并发槽位、不计费、不转发上游
+	dryRunHeader = "X-Dry-Run"
+	// dryRunQuery 与 dryRunHeader 等价的查询参数（?dry_run=1）
+	dryRunQuery = "dry_run"
+)
+
+// openAIResponsesDryRunChecks dry-run 执行的校验项（与正常请求的校验顺序一致）
+var openAIResponsesDryRunChecks = []string{
+	"body",
+	"model",
+	"stream",
+	"previous_response_id",
+	"inline_payload",
+	"image_generation_permission",
+	"function_call_output",
+}
+
+// isDryRunRequest 判断请求是否为 dry-run（X-Dry-Run 头或 dry_run 查询参数为真）
+func isDryRunRequest(c *gin.Context) bool {
+	if c == nil || c.Request == nil {
+		return false
+	}
+	for _, raw := range []string{c.GetHeader(dryRunHeader), c.Query(dryRunQuery)} {
+		if enabled, err := strconv.ParseBool(strings.TrimSpace(raw)); err == nil && enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// respondResponsesDryRun 执行剩余的请求级校验并返回校验摘要。
+// dry-run 永远不会选择账号，因此不校验账号可用性、并发与计费资格。
+func (h *OpenAIGatewayHandler) respondResponsesDryRun(c *gin.Context, apiKey *service.APIKey, body []byte, reqModel string, reqStream bool, reqLog *zap.Logger) {
+	if service.IsImageGenerationIntent("/v1/responses", reqModel, body) && !service.GroupAllowsImageGeneration(apiKey.Group) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", service.ImageGenerationPermissionMessage())
+		return
+	}
+	if !h.validateFunctionCallOutputRequest(c, body, reqLog) {
+		return
+	}
+
+	summary := gin.H{
+		"object": "response.dry_run",
+		"valid":  true,
+		"model":  reqModel,
+		"stream": reqStream,
+		"checks": openAIResponsesDryRunChecks,
+	}
+	// 与正常请求一致地预演分组 instructions 注入，便于确认注入模式与额外 token
+	if _, injection := service.InjectGroupInstructions(c, apiKey, body); injection != nil {
+		summary["instructions_injected"] = true
+		summary["instruction_injection_mode"] = injection.Mode
+		summary["instruction_injection_tokens"] = injection.InjectedTokens
+	}
+	if h.gatewayService != nil {
+		mapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+		if h.rejectIfMappedModelNotAllowed(c, apiKey, reqModel, mapping, reqLog) {
+			return
+		}
+		if mapping.Mapped {
+			summary["mapped_model"] = mapping.MappedModel
+		}
+	}
+	reqLog.Info("openai.dry_run_validated")
+	c.JSON(http.StatusOK, summary)
+}
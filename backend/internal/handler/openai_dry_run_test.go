@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newOpenAIDryRunTestHandler(t *testing.T) *OpenAIGatewayHandler {
+	t.Helper()
+	return newOpenAIHandlerForPreviousResponseIDValidation(t, &concurrencyCacheMock{
+		acquireUserSlotFn: func(context.Context, int64, int, string) (bool, error) {
+			t.Fatal("dry-run must not acquire a user slot")
+			return false, nil
+		},
+	})
+}
+
+func TestOpenAIResponsesDryRun_ReturnsValidationSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, rec := newOpenAICompatibleStreamValidationContext("/openai/v1/responses", `{"model":"gpt-5","stream":true,"input":"hello"}`, false)
+	c.Request.Header.Set(dryRunHeader, "true")
+
+	newOpenAIDryRunTestHandler(t).Responses(c)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, gjson.Get(rec.Body.String(), "valid").Bool())
+	require.Equal(t, "gpt-5", gjson.Get(rec.Body.String(), "model").String())
+	require.True(t, gjson.Get(rec.Body.String(), "stream").Bool())
+	require.Equal(t, "response.dry_run", gjson.Get(rec.Body.String(), "object").String())
+}
+
+func TestOpenAIResponsesDryRun_QueryParameter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, rec := newOpenAICompatibleStreamValidationContext("/openai/v1/responses?dry_run=1", `{"model":"gpt-5","input":"hello"}`, false)
+
+	newOpenAIDryRunTestHandler(t).Responses(c)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, gjson.Get(rec.Body.String(), "valid").Bool())
+}
+
+func TestOpenAIResponsesDryRun_ReportsInstructionInjection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, rec := newOpenAICompatibleStreamValidationContext("/openai/v1/responses?dry_run=1", `{"model":"gpt-5","input":"hello"}`, false)
+	apiKey := c.MustGet("api_key").(*service.APIKey)
+	apiKey.Group.InstructionInjectionConfig = service.GroupInstructionInjectionConfig{
+		Enabled: true,
+		Mode:    service.InstructionInjectionModePrepend,
+		Text:    "Follow policy.",
+	}
+
+	newOpenAIDryRunTestHandler(t).Responses(c)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, gjson.Get(rec.Body.String(), "instructions_injected").Bool())
+	require.Equal(t, service.InstructionInjectionModePrepend, gjson.Get(rec.Body.String(), "instruction_injection_mode").String())
+	require.Positive(t, gjson.Get(rec.Body.String(), "instruction_injection_tokens").Int())
+}
+
+func TestOpenAIResponsesDryRun_ReportsValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{name: "missing_model", body: `{"input":"hello"}`, message: "model is required"},
+		{name: "invalid_stream", body: `{"model":"gpt-5","stream":"yes","input":"hello"}`, message: "stream"},
+		{
+			name:    "function_call_output_without_context",
+			body:    `{"model":"gpt-5","input":[{"type":"function_call_output","call_id":"call_1","output":"ok"}]}`,
+			message: "item_reference",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, rec := newOpenAICompatibleStreamValidationContext("/openai/v1/responses", tt.body, false)
+			c.Request.Header.Set(dryRunHeader, "1")
+
+			newOpenAIDryRunTestHandler(t).Responses(c)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			require.Contains(t, gjson.Get(rec.Body.String(), "error.message").String(), tt.message)
+		})
+	}
+}
+
+func TestIsDryRunRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := newOpenAICompatibleStreamValidationContext("/openai/v1/responses?dry_run=false", `{}`, false)
+	c.Request.Header.Set(dryRunHeader, "no")
+	require.False(t, isDryRunRequest(c))
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/sjson"
+)
+
+// shouldForceNonStreaming 判断模型是否命中 gateway.force_non_streaming_models（精确匹配渠道映射后的模型名）
+func (h *OpenAIGatewayHandler) shouldForceNonStreaming(model string) bool {
+	if h == nil || h.cfg == nil {
+		return false
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return false
+	}
+	for _, candidate := range h.cfg.Gateway.ForceNonStreamingModels {
+		if strings.TrimSpace(candidate) == model {
+			return true
+		}
+	}
+	return false
+}
+
+// forceNonStreamingChatBody 把 Chat Completions 请求体改写为非流式（stream_options 仅对流式有效，一并移除）
+func forceNonStreamingChatBody(body []byte) ([]byte, error) {
+	out, err := sjson.SetBytes(body, "stream", false)
+	if err != nil {
+		return nil, err
+	}
+	if stripped, err := sjson.DeleteBytes(out, "stream_options"); err == nil {
+		out = stripped
+	}
+	return out, nil
+}
+
+// forceNonStreamWriter 缓冲强制非流式转发期间写出的响应，转发结束后由 handler 决定
+// 原样写回（错误响应）、重新封装为 SSE（成功响应）或直接丢弃（failover 切号）。
+type forceNonStreamWriter struct {
+	gin.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (w *forceNonStreamWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *forceNonStreamWriter) WriteHeaderNow() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+}
+
+func (w *forceNonStreamWriter) Write(b []byte) (int, error) {
+	w.WriteHeaderNow()
+	return w.buf.Write(b)
+}
+
+func (w *forceNonStreamWriter) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	return w.buf.WriteString(s)
+}
+
+func (w *forceNonStreamWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *forceNonStreamWriter) Size() int {
+	if !w.Written() {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+func (w *forceNonStreamWriter) Written() bool {
+	return w.status != 0
+}
+
+func (w *forceNonStreamWriter) Flush() {}
+
+// beginForceNonStream 用缓冲 writer 替换 c.Writer，返回的 restore 必须在转发结束后调用。
+func beginForceNonStream(c *gin.Context) (*forceNonStreamWriter, func()) {
+	original := c.Writer
+	w := &forceNonStreamWriter{ResponseWriter: original}
+	c.Writer = w
+	return w, func() { c.Writer = original }
+}
+
+// writeVerbatim 把缓冲的响应原样写回客户端（用于错误响应）
+func (w *forceNonStreamWriter) writeVerbatim(dst gin.ResponseWriter) {
+	if !w.Written() {
+		return
+	}
+	dst.WriteHeader(w.Status())
+	_, _ = dst.Write(w.buf.Bytes())
+}
+
+// writeChatCompletionAsSSE 把缓冲的非流式 Chat Completions 响应重新封装为单个 SSE chunk + [DONE]。
+// 响应无法解析时退回原样写回，保证客户端至少拿到上游的原始内容。
+func (w *forceNonStreamWriter) writeChatCompletionAsSSE(dst gin.ResponseWriter) {
+	var resp apicompat.ChatCompletionsResponse
+	if w.Status() != http.StatusOK || json.Unmarshal(w.buf.Bytes(), &resp) != nil {
+		w.writeVerbatim(dst)
+		return
+	}
+	sse, err := apicompat.ChatChunkToSSE(apicompat.ChatCompletionsResponseToChunk(&resp))
+	if err != nil {
+		w.writeVerbatim(dst)
+		return
+	}
+
+	header := dst.Header()
+	header.Del("Content-Length")
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	dst.WriteHeader(http.StatusOK)
+	_, _ = dst.WriteString(sse)
+	_, _ = dst.WriteString("data: [DONE]\n\n")
+	dst.Flush()
+}
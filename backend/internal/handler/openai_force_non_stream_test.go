@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newForceNonStreamTestHandler(models ...string) *OpenAIGatewayHandler {
+	cfg := &config.Config{}
+	cfg.Gateway.ForceNonStreamingModels = models
+	return &OpenAIGatewayHandler{cfg: cfg}
+}
+
+func TestShouldForceNonStreaming_ExactMatchOnly(t *testing.T) {
+	h := newForceNonStreamTestHandler("deepseek-reasoner", " glm-4.6 ")
+
+	require.True(t, h.shouldForceNonStreaming("deepseek-reasoner"))
+	require.True(t, h.shouldForceNonStreaming("glm-4.6"))
+	require.False(t, h.shouldForceNonStreaming("deepseek-reasoner-v2"))
+	require.False(t, h.shouldForceNonStreaming("DeepSeek-Reasoner"))
+	require.False(t, h.shouldForceNonStreaming("gpt-5"))
+	require.False(t, h.shouldForceNonStreaming(""))
+	require.False(t, newForceNonStreamTestHandler().shouldForceNonStreaming("gpt-5"))
+}
+
+func TestForceNonStreamingChatBody_DisablesStream(t *testing.T) {
+	body, err := forceNonStreamingChatBody([]byte(`{"model":"m","stream":true,"stream_options":{"include_usage":true},"messages":[]}`))
+	require.NoError(t, err)
+	require.False(t, gjson.GetBytes(body, "stream").Bool())
+	require.True(t, gjson.GetBytes(body, "stream").Exists())
+	require.False(t, gjson.GetBytes(body, "stream_options").Exists())
+	require.Equal(t, "m", gjson.GetBytes(body, "model").String())
+}
+
+func TestForceNonStreamWriter_ReemitsCompletionAsSingleSSEChunk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	original := c.Writer
+
+	w, restore := beginForceNonStream(c)
+	c.JSON(http.StatusOK, gin.H{
+		"id":      "chatcmpl-1",
+		"object":  "chat.completion",
+		"created": 1700000000,
+		"model":   "deepseek-reasoner",
+		"choices": []gin.H{{
+			"index":         0,
+			"message":       gin.H{"role": "assistant", "content": "hello"},
+			"finish_reason": "stop",
+		}},
+		"usage": gin.H{"prompt_tokens": 3, "completion_tokens": 1, "total_tokens": 4},
+	})
+	require.Equal(t, 0, rec.Body.Len(), "buffered writer must not reach the client during forward")
+	restore()
+	require.Same(t, original, c.Writer)
+
+	w.writeChatCompletionAsSSE(c.Writer)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+	require.Len(t, events, 2)
+	require.Equal(t, "data: [DONE]", events[1])
+
+	chunk := strings.TrimPrefix(events[0], "data: ")
+	require.Equal(t, "chat.completion.chunk", gjson.Get(chunk, "object").String())
+	require.Equal(t, "chatcmpl-1", gjson.Get(chunk, "id").String())
+	require.Equal(t, "assistant", gjson.Get(chunk, "choices.0.delta.role").String())
+	require.Equal(t, "hello", gjson.Get(chunk, "choices.0.delta.content").String())
+	require.Equal(t, "stop", gjson.Get(chunk, "choices.0.finish_reason").String())
+	require.Equal(t, int64(4), gjson.Get(chunk, "usage.total_tokens").Int())
+}
+
+func TestForceNonStreamWriter_ErrorResponsePassesThroughVerbatim(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	w, restore := beginForceNonStream(c)
+	c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"type": "invalid_request_error", "message": "bad"}})
+	restore()
+
+	w.writeChatCompletionAsSSE(c.Writer)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+	require.Equal(t, "bad", gjson.Get(rec.Body.String(), "error.message").String())
+}
@@ -35,6 +35,9 @@ type OpenAIGatewayHandler struct {
 	errorPassthroughService  *service.ErrorPassthroughService
 	contentModerationService *service.ContentModerationService
 	opsService               *service.OpsService
+	idempotencyService       *service.GatewayIdempotencyService
+	responseCacheService     *service.GatewayResponseCacheService
+	requestCostService       *service.RequestCostService
 	concurrencyHelper        *ConcurrencyHelper
 	imageLimiter             *imageConcurrencyLimiter
 	maxAccountSwitches       int
@@ -85,7 +88,7 @@ func usageRecordContext(parent context.Context, base context.Context) context.Co
 	if requestID, _ := parent.Value(ctxkey.RequestID).(string); strings.TrimSpace(requestID) != "" {
 		base = context.WithValue(base, ctxkey.RequestID, strings.TrimSpace(requestID))
 	}
-	return base
+	return service.CopyRequestCostEstimate(service.CopyBillingVerification(base, parent), parent)
 }
 
 func wrapUsageRecordTaskContext(parent context.Context, task service.UsageRecordTask) service.UsageRecordTask {
@@ -114,16 +117,25 @@ func NewOpenAIGatewayHandler(
 	errorPassthroughService *service.ErrorPassthroughService,
 	contentModerationService *service.ContentModerationService,
 	opsService *service.OpsService,
+	idempotencyService *service.GatewayIdempotencyService,
+	responseCacheService *service.GatewayResponseCacheService,
+	requestCostService *service.RequestCostService,
 	cfg *config.Config,
 ) *OpenAIGatewayHandler {
 	pingInterval := time.Duration(0)
+	pingFormat := SSEPingFormatComment
 	maxAccountSwitches := 3
 	if cfg != nil {
-		pingInterval = time.Duration(cfg.Concurrency.PingInterval) * time.Second
+		pingInterval = time.Duration(cfg.Concurrency.OpenAI.ResolvedPingInterval(cfg.Concurrency.PingInterval)) * time.Second
+		pingFormat = resolveSSEPingFormat(cfg.Concurrency.OpenAI.PingFormat, SSEPingFormatComment)
 		if cfg.Gateway.MaxAccountSwitches > 0 {
 			maxAccountSwitches = cfg.Gateway.MaxAccountSwitches
 		}
 	}
+	concurrencyHelper := NewConcurrencyHelper(concurrencyService, pingFormat, pingInterval)
+	if cfg != nil {
+		concurrencyHelper.SetQueuedNotice(cfg.Concurrency.QueuedNotice)
+	}
 	return &OpenAIGatewayHandler{
 		gatewayService:           gatewayService,
 		billingCacheService:      billingCacheService,
@@ -132,7 +144,10 @@ func NewOpenAIGatewayHandler(
 		errorPassthroughService:  errorPassthroughService,
 		contentModerationService: contentModerationService,
 		opsService:               opsService,
-		concurrencyHelper:        NewConcurrencyHelper(concurrencyService, SSEPingFormatComment, pingInterval),
+		idempotencyService:       idempotencyService,
+		responseCacheService:     responseCacheService,
+		requestCostService:       requestCostService,
+		concurrencyHelper:        concurrencyHelper,
 		imageLimiter:             &imageConcurrencyLimiter{},
 		maxAccountSwitches:       maxAccountSwitches,
 		cfg:                      cfg,
@@ -175,6 +190,7 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 	}
 
 	// Read request body
+	byteCounter := newUsageByteCounter(c)
 	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
 	if err != nil {
 		if maxErr, ok := extractMaxBytesError(err); ok {
@@ -190,6 +206,12 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 
+	maxCost, body, rejection := extractRequestMaxCost(c, body)
+	if rejection != nil {
+		h.errorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
+
 	setOpsRequestContext(c, "", false)
 	sessionHashBody := body
 	if service.IsOpenAIResponsesCompactPathForTest(c) {
@@ -219,6 +241,9 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 	reqModel := modelResult.String()
+	if h.rejectIfModelNotAllowed(c, apiKey, reqModel, reqLog) {
+		return
+	}
 
 	reqStream, ok := parseOpenAICompatibleStream(body)
 	if !ok {
@@ -251,11 +276,65 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 	setOpsRequestContext(c, reqModel, reqStream)
 	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(reqStream, false)))
 
+	// 内嵌图片/文件预校验：超限或类型不符在占用账号槽位前直接拒绝
+	if h.cfg != nil {
+		if payloadErr := service.ValidateOpenAIInputPayload(body, h.cfg.Gateway.PayloadValidation); payloadErr != nil {
+			reqLog.Warn("openai.request_validation_failed",
+				zap.String("reason", "inline_payload_rejected"),
+				zap.Int("item_index", payloadErr.ItemIndex),
+				zap.Int("status", payloadErr.Status),
+			)
+			h.errorResponse(c, payloadErr.Status, "invalid_request_error", payloadErr.Message)
+			return
+		}
+	}
+
+	// Dry-run：只做请求校验并返回摘要，不选号、不占用槽位、不计费、不转发
+	if isDryRunRequest(c) {
+		h.respondResponsesDryRun(c, apiKey, body, reqModel, reqStream, reqLog)
+		return
+	}
+
+	// Idempotency-Key：重放已完成的响应时不再访问上游，也不重复计费
+	idempotencyFinish, handled := h.beginIdempotentRequest(c, openAIResponsesIdempotencyScope, apiKey.ID, body, reqStream, reqLog)
+	if handled {
+		return
+	}
+	if idempotencyFinish != nil {
+		defer idempotencyFinish()
+	}
+
 	if decision := h.checkContentModeration(c, reqLog, apiKey, subject, service.ContentModerationProtocolOpenAIResponses, reqModel, body); decision != nil && decision.Blocked {
 		h.errorResponse(c, contentModerationStatus(decision), contentModerationErrorCode(decision), decision.Message)
 		return
 	}
 
+	// 分组 instructions 注入须在费用预估之前，使注入文本计入预估 token
+	body, reqLog = h.applyGroupInstructionInjection(c, apiKey, body, reqLog)
+
+	// 解析渠道级模型映射（费用预估按映射后的计费模型定价）
+	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+	if h.rejectIfMappedModelNotAllowed(c, apiKey, reqModel, channelMapping, reqLog) {
+		return
+	}
+
+	if rejection := checkRequestCost(c, h.requestCostService, apiKey, channelMapping.EstimateBillingModel(reqModel), body, maxCost, reqLog); rejection != nil {
+		h.errorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
+
+	// 会话封禁须先于响应缓存，避免被封禁的会话拿到缓存的应答
+	if h.rejectIfCyberSessionBlocked(c, apiKey, sessionHashBody, reqModel, cyberBlockFormatResponses) {
+		return
+	}
+
+	// 响应缓存：temperature=0 的非流式确定性请求命中时直接返回，不访问上游、不计费
+	responseCache, handled := beginResponseCache(c, h.responseCacheService, service.ResponseCacheScopeOpenAIResponses, apiKey, body, reqModel, reqLog)
+	if handled {
+		return
+	}
+	defer responseCache.Release()
+
 	imageIntent := service.IsImageGenerationIntent("/v1/responses", reqModel, body)
 	if imageIntent && !service.GroupAllowsImageGeneration(apiKey.Group) {
 		h.errorResponse(c, http.StatusForbidden, "permission_error", service.ImageGenerationPermissionMessage())
@@ -273,8 +352,6 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 		}
 	}
 
-	// 解析渠道级模型映射
-	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
 	forwardBody := openAIModelMappedBody(body, channelMapping.Mapped, channelMapping.MappedModel, h.gatewayService.ReplaceModelInBody)
 
 	// 提前校验 function_call_output 是否具备可关联上下文，避免上游 400。
@@ -286,6 +363,7 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
 	}
+	h.concurrencyHelper.BindSSEPingFormat(c)
 
 	// Get subscription info (may be nil)
 	subscription, _ := middleware2.GetSubscriptionFromContext(c)
@@ -314,11 +392,10 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 
-	// Generate session hash (header first; fallback to prompt_cache_key)
-	sessionHash := h.gatewayService.GenerateSessionHash(c, sessionHashBody)
-	if h.rejectIfCyberSessionBlocked(c, apiKey, sessionHashBody, reqModel, cyberBlockFormatResponses) {
-		return
-	}
+	// Generate session hash (X-Session-Affinity, then session headers; fallback to prompt_cache_key)
+	sessionHash := h.applySessionAffinity(c, func() string {
+		return h.gatewayService.GenerateSessionHash(c, sessionHashBody)
+	})
 	requireCompact := isOpenAIRemoteCompactPath(c)
 
 	maxAccountSwitches := h.maxAccountSwitches
@@ -326,6 +403,7 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 	failedAccountIDs := make(map[int64]struct{})
 	sameAccountRetryCount := make(map[int64]int)
 	var lastFailoverErr *service.UpstreamFailoverError
+	modelFallback := newModelFallbackState(apiKey, reqModel)
 
 	for {
 		// Select account supporting the requested model
@@ -353,6 +431,20 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 					h.handleStreamingAwareError(c, http.StatusServiceUnavailable, "compact_not_supported", "No available OpenAI accounts support /responses/compact", streamStarted)
 					return
 				}
+				// 分组模型降级：当前模型无可用账号时，按分组规则切换到下一个降级模型重新选号
+				if fallbackModel, ok := modelFallback.Next(); ok {
+					reqLog.Info("openai.model_fallback",
+						zap.String("from_model", reqModel),
+						zap.String("fallback_model", fallbackModel),
+						zap.Error(err),
+					)
+					body = modelFallback.switchTo(c, body, fallbackModel)
+					reqModel = fallbackModel
+					channelMapping, _ = h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, fallbackModel)
+					modelFallback.maskChannelMapping(channelMapping)
+					forwardBody = openAIModelMappedBody(body, channelMapping.Mapped, channelMapping.MappedModel, h.gatewayService.ReplaceModelInBody)
+					continue
+				}
 				cls := classifyNoAccountErrorFromGin(c, h.gatewayService, apiKey, reqModel, reqModel, service.PlatformOpenAI)
 				if !cls.ModelNotFound {
 					markOpsRoutingCapacityLimitedIfNoAvailable(c, err)
@@ -473,6 +565,7 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 					reqLog.Warn("openai.upstream_failover_switching",
 						zap.Int64("account_id", account.ID),
 						zap.Int("upstream_status", failoverErr.StatusCode),
+						zap.String("upstream_request_id", failoverErr.UpstreamRequestID),
 						zap.Int("switch_count", switchCount),
 						zap.Int("max_switches", maxAccountSwitches),
 					)
@@ -513,7 +606,10 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 		requestPayloadHash := service.HashUsageRequestPayload(body)
 		inboundEndpoint := GetInboundEndpoint(c)
 		upstreamEndpoint := resolveOpenAIUpstreamEndpoint(c, account)
+		responseCache.Commit(account.ID, reqModel)
 		quotaPlatform := service.QuotaPlatform(c.Request.Context(), apiKey)
+		requestBytes, responseBytes := byteCounter.RequestBytes(), byteCounter.ResponseBytes()
+		fallbackFromModel := modelFallback.FallbackFromModel()
 
 		// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
 		cyberBlocked := service.GetOpsCyberPolicy(c) != nil
@@ -531,6 +627,9 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 				RequestPayloadHash: requestPayloadHash,
 				APIKeyService:      h.apiKeyService,
 				QuotaPlatform:      quotaPlatform,
+				RequestBytes:       requestBytes,
+				ResponseBytes:      responseBytes,
+				FallbackFromModel:  fallbackFromModel,
 				ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
 				CyberBlocked:       cyberBlocked,
 			}); err != nil {
@@ -670,6 +769,7 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	byteCounter := newUsageByteCounter(c)
 	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
 	if err != nil {
 		if maxErr, ok := extractMaxBytesError(err); ok {
@@ -683,6 +783,11 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 		h.anthropicErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
 		return
 	}
+	maxCost, body, rejection := extractRequestMaxCost(c, body)
+	if rejection != nil {
+		h.anthropicErrorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
 
 	if !gjson.ValidBytes(body) {
 		h.anthropicErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
@@ -695,6 +800,10 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 	reqModel := modelResult.String()
+	if apiKeyModelDenied(apiKey, reqModel, reqLog) {
+		h.anthropicErrorResponse(c, http.StatusForbidden, "permission_error", service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
 	routingModel := service.NormalizeOpenAICompatRequestedModel(reqModel)
 	preferredMappedModel := resolveOpenAIMessagesDispatchMappedModel(apiKey, reqModel)
 	reqStream := gjson.GetBytes(body, "stream").Bool()
@@ -709,14 +818,27 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
-	// 解析渠道级模型映射
+	// 分组 instructions 注入须在费用预估之前，使注入文本计入预估 token
+	body, reqLog = h.applyGroupInstructionInjection(c, apiKey, body, reqLog)
+
+	// 解析渠道级模型映射（费用预估按映射后的计费模型定价）
 	channelMappingMsg, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+	if apiKeyMappedModelDenied(apiKey, channelMappingMsg, reqLog) {
+		h.anthropicErrorResponse(c, http.StatusForbidden, "permission_error", service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
+
+	if rejection := checkRequestCost(c, h.requestCostService, apiKey, channelMappingMsg.EstimateBillingModel(reqModel), body, maxCost, reqLog); rejection != nil {
+		h.anthropicErrorResponse(c, rejection.Status, rejection.ErrType, rejection.Message)
+		return
+	}
 	mappedBodyForMessages := newOpenAIModelMappedBodyCache(body, h.gatewayService.ReplaceModelInBody)
 
 	// 绑定错误透传服务，允许 service 层在非 failover 错误场景复用规则。
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
 	}
+	h.concurrencyHelper.BindSSEPingFormat(c)
 
 	subscription, _ := middleware2.GetSubscriptionFromContext(c)
 	requestPlatform := openAICompatibleRequestPlatform(apiKey)
@@ -742,9 +864,12 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
-	sessionHash := h.gatewayService.GenerateSessionHash(c, body)
 	promptCacheKey := h.gatewayService.ExtractSessionID(c, body)
-	sessionHash, promptCacheKey = resolveOpenAIMessagesMetadataSession(sessionHash, promptCacheKey, reqModel, body)
+	sessionHash := h.applySessionAffinity(c, func() string {
+		hash := h.gatewayService.GenerateSessionHash(c, body)
+		hash, promptCacheKey = resolveOpenAIMessagesMetadataSession(hash, promptCacheKey, reqModel, body)
+		return hash
+	})
 	if h.rejectIfCyberSessionBlocked(c, apiKey, body, reqModel, cyberBlockFormatAnthropic) {
 		return
 	}
@@ -895,6 +1020,7 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 					reqLog.Warn("openai_messages.upstream_failover_switching",
 						zap.Int64("account_id", account.ID),
 						zap.Int("upstream_status", failoverErr.StatusCode),
+						zap.String("upstream_request_id", failoverErr.UpstreamRequestID),
 						zap.Int("switch_count", switchCount),
 						zap.Int("max_switches", maxAccountSwitches),
 					)
@@ -929,6 +1055,7 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 		inboundEndpoint := GetInboundEndpoint(c)
 		upstreamEndpoint := resolveOpenAIUpstreamEndpoint(c, account)
 		quotaPlatform := service.QuotaPlatform(c.Request.Context(), apiKey)
+		requestBytes, responseBytes := byteCounter.RequestBytes(), byteCounter.ResponseBytes()
 
 		cyberBlocked := service.GetOpsCyberPolicy(c) != nil
 		h.submitOpenAIUsageRecordTask(c.Request.Context(), result, func(ctx context.Context) {
@@ -945,6 +1072,8 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 				RequestPayloadHash: requestPayloadHash,
 				APIKeyService:      h.apiKeyService,
 				QuotaPlatform:      quotaPlatform,
+				RequestBytes:       requestBytes,
+				ResponseBytes:      responseBytes,
 				ChannelUsageFields: channelMappingMsg.ToUsageFields(reqModel, result.UpstreamModel),
 				CyberBlocked:       cyberBlocked,
 			}); err != nil {
@@ -984,10 +1113,10 @@ func resolveOpenAIMessagesMetadataSession(sessionHash, promptCacheKey, reqModel
 func (h *OpenAIGatewayHandler) anthropicErrorResponse(c *gin.Context, status int, errType, message string) {
 	c.JSON(status, gin.H{
 		"type": "error",
-		"error": gin.H{
+		"error": withErrorTraceID(c, gin.H{
 			"type":    errType,
 			"message": message,
-		},
+		}),
 	})
 }
 
@@ -1014,6 +1143,7 @@ func (h *OpenAIGatewayHandler) anthropicStreamingAwareError(c *gin.Context, stat
 
 // handleAnthropicFailoverExhausted maps upstream failover errors to Anthropic format.
 func (h *OpenAIGatewayHandler) handleAnthropicFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError, streamStarted bool) {
+	service.SetUpstreamRequestIDHeader(c, h.cfg, failoverErr.UpstreamRequestID)
 	status, errType, errMsg := h.mapUpstreamError(failoverErr.StatusCode)
 	h.anthropicStreamingAwareError(c, status, errType, errMsg, streamStarted)
 }
@@ -1096,6 +1226,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesAccountSlot(
 	ctx := c.Request.Context()
 	account := selection.Account
 	if selection.Acquired {
+		recordOpsSlotAcquire(c, "account", slotAcquirePathFast, 0)
 		return wrapReleaseOnDone(ctx, selection.ReleaseFunc), true
 	}
 	if selection.WaitPlan == nil {
@@ -1115,6 +1246,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesAccountSlot(
 		return nil, false
 	}
 	if fastAcquired {
+		recordOpsSlotAcquire(c, "account", slotAcquirePathFast, 0)
 		if err := h.gatewayService.BindStickySession(ctx, groupID, sessionHash, account.ID); err != nil {
 			reqLog.Warn("openai.bind_sticky_session_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 		}
@@ -1125,6 +1257,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesAccountSlot(
 	if waitErr != nil {
 		reqLog.Warn("openai.account_wait_counter_increment_failed", zap.Int64("account_id", account.ID), zap.Error(waitErr))
 	} else if !canWait {
+		recordOpsSlotAcquire(c, "account", slotAcquirePathQueueFull, 0)
 		reqLog.Info("openai.account_wait_queue_full",
 			zap.Int64("account_id", account.ID),
 			zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
@@ -1249,6 +1382,10 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 		closeOpenAIClientWS(wsConn, coderws.StatusPolicyViolation, "model is required in first response.create payload")
 		return
 	}
+	if apiKeyModelDenied(apiKey, reqModel, reqLog) {
+		closeOpenAIClientWS(wsConn, coderws.StatusPolicyViolation, service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
 	previousResponseID := strings.TrimSpace(gjson.GetBytes(firstMessage, "previous_response_id").String())
 	previousResponseIDKind := service.ClassifyOpenAIPreviousResponseIDKind(previousResponseID)
 	if previousResponseID != "" && previousResponseIDKind == service.OpenAIPreviousResponseIDKindMessageID {
@@ -1288,6 +1425,10 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 
 	// 解析渠道级模型映射
 	channelMappingWS, _ := h.gatewayService.ResolveChannelMappingAndRestrict(ctx, apiKey.GroupID, reqModel)
+	if apiKeyMappedModelDenied(apiKey, channelMappingWS, reqLog) {
+		closeOpenAIClientWS(wsConn, coderws.StatusPolicyViolation, service.APIKeyModelNotAllowedMessage(reqModel))
+		return
+	}
 
 	var currentUserRelease func()
 	var currentAccountRelease func()
@@ -1452,6 +1593,13 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 				if model == "" {
 					model = reqModel
 				}
+				// 后续 turn 可更换模型，同样须通过 Key 白名单
+				if apiKeyModelDenied(apiKey, model, reqLog) {
+					return service.NewOpenAIWSClientCloseError(coderws.StatusPolicyViolation, service.APIKeyModelNotAllowedMessage(model), nil)
+				}
+				if turnMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(ctx, apiKey.GroupID, model); apiKeyMappedModelDenied(apiKey, turnMapping, reqLog) {
+					return service.NewOpenAIWSClientCloseError(coderws.StatusPolicyViolation, service.APIKeyModelNotAllowedMessage(model), nil)
+				}
 				if decision := h.checkContentModeration(c, reqLog, apiKey, subject, service.ContentModerationProtocolOpenAIResponses, model, payload); decision != nil && decision.Blocked {
 					writeContentModerationWSError(ctx, wsConn, decision)
 					return service.NewOpenAIWSClientCloseError(coderws.StatusPolicyViolation, decision.Message, nil)
@@ -1818,6 +1966,7 @@ func (h *OpenAIGatewayHandler) handleConcurrencyError(c *gin.Context, err error,
 func (h *OpenAIGatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError, streamStarted bool) {
 	statusCode := failoverErr.StatusCode
 	responseBody := failoverErr.ResponseBody
+	service.SetUpstreamRequestIDHeader(c, h.cfg, failoverErr.UpstreamRequestID)
 	if service.IsOpenAISilentRefusalErrorBody(responseBody) {
 		service.SetOpsUpstreamError(c, statusCode, service.OpenAISilentRefusalClientMessage(), "")
 		h.handleStreamingAwareError(c, http.StatusBadGateway, "upstream_error", service.OpenAISilentRefusalClientMessage(), streamStarted)
@@ -1826,7 +1975,7 @@ func (h *OpenAIGatewayHandler) handleFailoverExhausted(c *gin.Context, failoverE
 
 	// 先检查透传规则
 	if h.errorPassthroughService != nil && len(responseBody) > 0 {
-		if rule := h.errorPassthroughService.MatchRule("openai", statusCode, responseBody); rule != nil {
+		if rule := h.errorPassthroughService.MatchRuleWithHeaders("openai", statusCode, failoverErr.ResponseHeaders, responseBody); rule != nil {
 			// 确定响应状态码
 			respCode := statusCode
 			if !rule.PassthroughCode && rule.ResponseCode != nil {
@@ -1921,7 +2070,8 @@ func (h *OpenAIGatewayHandler) ensureForwardErrorResponse(c *gin.Context, stream
 	if c.Writer.Written() {
 		streamStarted = true
 	}
-	h.handleStreamingAwareError(c, http.StatusBadGateway, "upstream_error", "Upstream request failed", streamStarted)
+	status, errType, message := forwardErrorFallback(c)
+	h.handleStreamingAwareError(c, status, errType, message, streamStarted)
 	return true
 }
 
@@ -1977,10 +2127,10 @@ func openAIForwardErrorAlreadyCommunicated(c *gin.Context, writerSizeBeforeForwa
 // errorResponse returns OpenAI API format error response
 func (h *OpenAIGatewayHandler) errorResponse(c *gin.Context, status int, errType, message string) {
 	c.JSON(status, gin.H{
-		"error": gin.H{
+		"error": withErrorTraceID(c, gin.H{
 			"type":    errType,
 			"message": message,
-		},
+		}),
 	})
 }
 
@@ -2110,6 +2260,7 @@ const cyberPolicyRecordedKey = "ops_cyber_recorded"
 type cyberPolicyOpsErrorMeta struct {
 	RequestID       string
 	ClientRequestID string
+	TraceID         string
 	Platform        string
 	Model           string
 	RequestPath     string
@@ -2134,6 +2285,7 @@ func buildCyberPolicyOpsErrorEntry(meta cyberPolicyOpsErrorMeta, mark *service.C
 	entry := &service.OpsInsertErrorLogInput{
 		RequestID:         meta.RequestID,
 		ClientRequestID:   meta.ClientRequestID,
+		TraceID:           meta.TraceID,
 		Platform:          meta.Platform,
 		Model:             meta.Model,
 		RequestPath:       meta.RequestPath,
@@ -2182,6 +2334,7 @@ func buildCyberSessionBlockedOpsEntry(meta cyberPolicyOpsErrorMeta) *service.Ops
 	entry := &service.OpsInsertErrorLogInput{
 		RequestID:         meta.RequestID,
 		ClientRequestID:   meta.ClientRequestID,
+		TraceID:           meta.TraceID,
 		Platform:          meta.Platform,
 		Model:             meta.Model,
 		RequestPath:       meta.RequestPath,
@@ -2227,6 +2380,41 @@ const (
 	cyberBlockFormatAnthropic
 )
 
+// rejectIfModelNotAllowed 模型（别名解析后）不在 API Key 白名单时返回 403；
+// 须在选号、占用槽位与转发之前调用。返回 true 表示已写入错误响应。
+func (h *OpenAIGatewayHandler) rejectIfModelNotAllowed(c *gin.Context, apiKey *service.APIKey, model string, reqLog *zap.Logger) bool {
+	if !apiKeyModelDenied(apiKey, model, reqLog) {
+		return false
+	}
+	h.errorResponse(c, http.StatusForbidden, "invalid_request_error", service.APIKeyModelNotAllowedMessage(model))
+	return true
+}
+
+// rejectIfMappedModelNotAllowed 渠道映射后的目标模型不在 API Key 白名单时返回 403，
+// 错误信息使用客户端请求的模型名。返回 true 表示已写入错误响应。
+func (h *OpenAIGatewayHandler) rejectIfMappedModelNotAllowed(c *gin.Context, apiKey *service.APIKey, model string, mapping service.ChannelMappingResult, reqLog *zap.Logger) bool {
+	if !apiKeyMappedModelDenied(apiKey, mapping, reqLog) {
+		return false
+	}
+	h.errorResponse(c, http.StatusForbidden, "invalid_request_error", service.APIKeyModelNotAllowedMessage(model))
+	return true
+}
+
+// applyGroupInstructionInjection 按分组配置改写请求体顶层 instructions 字段并标记 ops 上下文；
+// Responses / Chat Completions / Anthropic Messages 入口共用，转换为 Responses 时该字段映射为 instructions。
+// 改写失败时记录告警并原样转发（fail-open）。
+func (h *OpenAIGatewayHandler) applyGroupInstructionInjection(c *gin.Context, apiKey *service.APIKey, body []byte, reqLog *zap.Logger) ([]byte, *zap.Logger) {
+	updated, result := service.InjectGroupInstructions(c, apiKey, body)
+	if result == nil {
+		return body, reqLog
+	}
+	reqLog = reqLog.With(
+		zap.String("instruction_injection_mode", result.Mode),
+		zap.Int("instruction_injection_tokens", result.InjectedTokens),
+	)
+	return updated, reqLog
+}
+
 // rejectIfCyberSessionBlocked checks the session-block table BEFORE account
 // selection. Returns true when the request was rejected (response already
 // written + ops entry enqueued). Fail-open: disabled switch / empty key /
@@ -2282,6 +2470,7 @@ func (h *OpenAIGatewayHandler) enqueueCyberSessionBlockedOpsEntry(c *gin.Context
 	meta.Platform = resolveOpsPlatform(apiKey, guessPlatformFromPath(meta.RequestPath))
 	if c.Request != nil {
 		meta.ClientRequestID, _ = c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+		meta.TraceID = service.TraceIDFromContext(c.Request.Context())
 		meta.UserAgent = c.GetHeader("User-Agent")
 		meta.ClientIP = strings.TrimSpace(ip.GetClientIP(c))
 	}
@@ -2346,9 +2535,10 @@ func (h *OpenAIGatewayHandler) recordCyberPolicyIfMarked(c *gin.Context, apiKey
 		requestPath = c.Request.URL.Path
 	}
 	platform := resolveOpsPlatform(apiKey, guessPlatformFromPath(requestPath))
-	var clientRequestID, userAgent, clientIPStr string
+	var clientRequestID, traceID, userAgent, clientIPStr string
 	if c.Request != nil {
 		clientRequestID, _ = c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+		traceID = service.TraceIDFromContext(c.Request.Context())
 		userAgent = c.GetHeader("User-Agent")
 		clientIPStr = strings.TrimSpace(ip.GetClientIP(c))
 	}
@@ -2359,6 +2549,7 @@ func (h *OpenAIGatewayHandler) recordCyberPolicyIfMarked(c *gin.Context, apiKey
 	opsMeta := cyberPolicyOpsErrorMeta{
 		RequestID:       requestID,
 		ClientRequestID: clientRequestID,
+		TraceID:         traceID,
 		Platform:        platform,
 		Model:           model,
 		RequestPath:     requestPath,
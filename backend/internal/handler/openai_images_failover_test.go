@@ -150,6 +150,9 @@ func TestOpenAIGatewayHandlerImages_ServerErrorFailsOverAndReturnsClearErrorWhen
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
 		cfg,
 	)
 	handler.maxAccountSwitches = 10
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+func TestOpenAIApplyGroupInstructionInjection_MarksOpsContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &OpenAIGatewayHandler{}
+	apiKey := &service.APIKey{
+		Name: "ci-bot",
+		Group: &service.Group{
+			Name: "compliance",
+			InstructionInjectionConfig: service.GroupInstructionInjectionConfig{
+				Enabled: true,
+				Mode:    service.InstructionInjectionModeIfAbsent,
+				Text:    "You serve {{key_name}}.",
+			},
+		},
+	}
+
+	// 客户端已提供 instructions：if_absent 不注入，ops 上下文不标记
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	body, _ := h.applyGroupInstructionInjection(c, apiKey, []byte(`{"model":"gpt-5","instructions":"client"}`), zap.NewNop())
+	require.Equal(t, "client", gjson.GetBytes(body, "instructions").String())
+	require.False(t, c.GetBool(service.OpsInstructionsInjectedKey))
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	body, _ = h.applyGroupInstructionInjection(c, apiKey, []byte(`{"model":"gpt-5"}`), zap.NewNop())
+	require.Equal(t, "You serve ci-bot.", gjson.GetBytes(body, "instructions").String())
+	require.True(t, c.GetBool(service.OpsInstructionsInjectedKey))
+}
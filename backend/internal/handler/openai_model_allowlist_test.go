@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIResponses_APIKeyModelAllowlist(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowedModels []string
+		model         string
+		wantStatus    int
+	}{
+		{name: "empty_allows_all", allowedModels: nil, model: "gpt-5.1", wantStatus: http.StatusOK},
+		{name: "exact_match", allowedModels: []string{"gpt-5.1"}, model: "gpt-5.1", wantStatus: http.StatusOK},
+		{name: "prefix_match", allowedModels: []string{"gpt-5*"}, model: "gpt-5.1-codex", wantStatus: http.StatusOK},
+		{name: "alias_resolved_before_check", allowedModels: []string{"gpt-5.1-codex"}, model: "GPT5.1_Codex", wantStatus: http.StatusOK},
+		{name: "denied", allowedModels: []string{"gpt-5.1"}, model: "gpt-5.4", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			// dry-run 在校验通过后直接返回摘要，且 newOpenAIDryRunTestHandler 禁止占用槽位
+			c, rec := newOpenAICompatibleStreamValidationContext("/openai/v1/responses", `{"model":"`+tt.model+`","input":"hello"}`, false)
+			c.Request.Header.Set(dryRunHeader, "1")
+			apiKey := c.MustGet(string(middleware2.ContextKeyAPIKey)).(*service.APIKey)
+			apiKey.AllowedModels = tt.allowedModels
+
+			newOpenAIDryRunTestHandler(t).Responses(c)
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus == http.StatusForbidden {
+				require.Equal(t, "invalid_request_error", gjson.Get(rec.Body.String(), "error.type").String())
+				require.Contains(t, gjson.Get(rec.Body.String(), "error.message").String(), `"gpt-5.4"`)
+			}
+		})
+	}
+}
+
+func TestOpenAIResponses_DisallowedModelRejectedBeforeSlot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// 非 dry-run 请求：被拒绝时同样不得占用用户槽位
+	c, rec := newOpenAICompatibleStreamValidationContext("/openai/v1/responses", `{"model":"gpt-4o","input":"hello"}`, false)
+	apiKey := c.MustGet(string(middleware2.ContextKeyAPIKey)).(*service.APIKey)
+	apiKey.AllowedModels = []string{"gpt-5*"}
+
+	newOpenAIDryRunTestHandler(t).Responses(c)
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "is not allowed for this API key")
+}
+
+func TestOpenAIResponses_APIKeyModelAllowlistChecksChannelMappedModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	groupID := int64(7)
+	channelSvc := service.NewChannelService(&openAIWSUsageHandlerChannelRepoStub{
+		channels: []service.Channel{{
+			ID:           7702,
+			Name:         "allowlist-channel",
+			Status:       service.StatusActive,
+			GroupIDs:     []int64{groupID},
+			ModelMapping: map[string]map[string]string{service.PlatformOpenAI: {"gpt-5.1": "gpt-5.4"}},
+		}},
+		groupPlatforms: map[int64]string{groupID: service.PlatformOpenAI},
+	}, nil, nil, nil)
+	gatewaySvc := service.NewOpenAIGatewayService(nil, nil, nil, nil, nil, nil, nil, &config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, channelSvc, nil, nil, nil)
+
+	tests := []struct {
+		name          string
+		allowedModels []string
+		wantStatus    int
+	}{
+		{name: "mapped_target_allowed", allowedModels: []string{"gpt-5.1", "gpt-5.4"}, wantStatus: http.StatusOK},
+		// 别名本身在白名单内，但渠道映射后的目标模型不在
+		{name: "mapped_target_denied", allowedModels: []string{"gpt-5.1"}, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := newOpenAICompatibleStreamValidationContext("/openai/v1/responses?dry_run=1", `{"model":"gpt-5.1","input":"hello"}`, false)
+			apiKey := c.MustGet(string(middleware2.ContextKeyAPIKey)).(*service.APIKey)
+			apiKey.AllowedModels = tt.allowedModels
+			h := newOpenAIDryRunTestHandler(t)
+			h.gatewayService = gatewaySvc
+
+			h.Responses(c)
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				require.Equal(t, "gpt-5.4", gjson.Get(rec.Body.String(), "mapped_model").String())
+			} else {
+				require.Contains(t, gjson.Get(rec.Body.String(), "error.message").String(), `"gpt-5.1"`)
+			}
+		})
+	}
+}
@@ -553,6 +553,8 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			return
 		}
 
+		ops.AnnotateServerErrorPenalties(c)
+
 		status := c.Writer.Status()
 		if status < 400 {
 			// Even when the client request succeeds, we still want to persist upstream error attempts
@@ -707,6 +709,7 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			entry := &service.OpsInsertErrorLogInput{
 				RequestID:       requestID,
 				ClientRequestID: clientRequestID,
+				TraceID:         service.TraceIDFromContext(c.Request.Context()),
 
 				AccountID: accountID,
 				Platform:  platform,
@@ -765,6 +768,7 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 				CreatedAt: time.Now(),
 			}
 			applyOpsLatencyFieldsFromContext(c, entry)
+			applyOpsRequestFlagsFromContext(c, entry)
 
 			if apiKey != nil {
 				entry.APIKeyID = &apiKey.ID
@@ -849,6 +853,7 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 		entry := &service.OpsInsertErrorLogInput{
 			RequestID:       requestID,
 			ClientRequestID: clientRequestID,
+			TraceID:         service.TraceIDFromContext(c.Request.Context()),
 
 			AccountID: accountID,
 			Platform:  platform,
@@ -902,6 +907,7 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			CreatedAt: time.Now(),
 		}
 		applyOpsLatencyFieldsFromContext(c, entry)
+		applyOpsRequestFlagsFromContext(c, entry)
 
 		// Capture upstream error context set by gateway services (if present).
 		// This does NOT affect the client response; it enriches Ops troubleshooting data.
@@ -1016,6 +1022,17 @@ func applyOpsLatencyFieldsFromContext(c *gin.Context, entry *service.OpsInsertEr
 	entry.UpstreamLatencyMs = getContextLatencyMs(c, service.OpsUpstreamLatencyMsKey)
 	entry.ResponseLatencyMs = getContextLatencyMs(c, service.OpsResponseLatencyMsKey)
 	entry.TimeToFirstTokenMs = getContextLatencyMs(c, service.OpsTimeToFirstTokenMsKey)
+	entry.UserSlotWaitMs = getContextLatencyMs(c, service.OpsUserSlotWaitMsKey)
+	entry.AccountSlotWaitMs = getContextLatencyMs(c, service.OpsAccountSlotWaitMsKey)
+	entry.SlotAcquirePath = c.GetString(service.OpsSlotAcquirePathKey)
+}
+
+// applyOpsRequestFlagsFromContext 把网关在请求处理中标记的请求改写标志写入 ops 错误条目
+func applyOpsRequestFlagsFromContext(c *gin.Context, entry *service.OpsInsertErrorLogInput) {
+	if c == nil || entry == nil {
+		return
+	}
+	entry.InstructionsInjected = c.GetBool(service.OpsInstructionsInjectedKey)
 }
 
 func getContextLatencyMs(c *gin.Context, key string) *int64 {
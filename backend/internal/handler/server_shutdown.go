@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrServerShuttingDown 作为请求 context 的取消原因：优雅停机宽限期已过，服务端主动中断在途请求。
+var ErrServerShuttingDown = errors.New("server is shutting down")
+
+// isServerShuttingDown 判断请求是否因优雅停机超时被中断。
+// net/http 的请求 context 派生自 Server.BaseContext，取消原因会沿 context 链传递。
+func isServerShuttingDown(c *gin.Context) bool {
+	if c == nil || c.Request == nil {
+		return false
+	}
+	return errors.Is(context.Cause(c.Request.Context()), ErrServerShuttingDown)
+}
+
+// forwardErrorFallback 返回 Forward 失败时兜底错误响应的状态码、类型与消息；
+// 停机中断的请求提示客户端重试，而不是误报为上游故障。
+func forwardErrorFallback(c *gin.Context) (int, string, string) {
+	if isServerShuttingDown(c) {
+		return http.StatusServiceUnavailable, "api_error", "Server is shutting down, please retry"
+	}
+	return http.StatusBadGateway, "upstream_error", "Upstream request failed"
+}
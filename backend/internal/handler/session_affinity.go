@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sessionAffinityHeader 客户端显式指定的会话亲和键，hash 后作为粘性会话键
+	sessionAffinityHeader = "X-Session-Affinity"
+	// sessionAffinityTTLHeader 客户端指定的粘性绑定有效期（秒），0 表示本次请求不使用粘性会话
+	sessionAffinityTTLHeader = "X-Session-Affinity-TTL"
+	// sessionAffinityHashHeader 回显实际使用的会话键 hash，便于客户端排查
+	sessionAffinityHashHeader = "X-Session-Affinity-Hash"
+
+	maxSessionAffinityKeyLen = 256
+)
+
+// sessionAffinity 客户端会话亲和请求头的解析结果
+type sessionAffinity struct {
+	key    string
+	ttl    time.Duration
+	optOut bool
+}
+
+// parseSessionAffinity 解析 X-Session-Affinity / X-Session-Affinity-TTL 请求头。
+// TTL 非法时忽略（使用默认 TTL），合法时裁剪到配置的 [min, max]；TTL 为 0 表示本次请求不使用粘性会话。
+func parseSessionAffinity(c *gin.Context, cfg *config.Config) sessionAffinity {
+	var out sessionAffinity
+	if c == nil || cfg == nil || !cfg.Gateway.SessionAffinity.HeaderEnabled {
+		return out
+	}
+	key := strings.TrimSpace(c.GetHeader(sessionAffinityHeader))
+	if len(key) > maxSessionAffinityKeyLen {
+		key = key[:maxSessionAffinityKeyLen]
+	}
+	out.key = key
+
+	rawTTL := strings.TrimSpace(c.GetHeader(sessionAffinityTTLHeader))
+	if rawTTL == "" {
+		return out
+	}
+	seconds, err := strconv.Atoi(rawTTL)
+	if err != nil || seconds < 0 {
+		return out
+	}
+	if seconds == 0 {
+		out.optOut = true
+		return out
+	}
+	minTTL := cfg.Gateway.SessionAffinity.MinTTLSeconds
+	maxTTL := cfg.Gateway.SessionAffinity.MaxTTLSeconds
+	if seconds < minTTL {
+		seconds = minTTL
+	}
+	if maxTTL > 0 && seconds > maxTTL {
+		seconds = maxTTL
+	}
+	out.ttl = time.Duration(seconds) * time.Second
+	return out
+}
+
+// applySessionAffinity 将会话亲和请求头应用到本次请求并返回最终的会话 hash：
+// 亲和键写入 parsedReq，TTL 写入请求上下文，opt-out 时返回空 hash（不查询也不绑定粘性会话）。
+// 非空 hash 通过 X-Session-Affinity-Hash 响应头回显。
+func (h *GatewayHandler) applySessionAffinity(c *gin.Context, parsedReq *service.ParsedRequest) string {
+	affinity := parseSessionAffinity(c, h.cfg)
+	if affinity.optOut {
+		return ""
+	}
+	if parsedReq != nil {
+		parsedReq.SessionAffinityKey = affinity.key
+	}
+	if affinity.ttl > 0 {
+		c.Request = c.Request.WithContext(service.WithStickySessionTTL(c.Request.Context(), affinity.ttl))
+	}
+	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
+	if sessionHash != "" {
+		c.Header(sessionAffinityHashHeader, sessionHash)
+	}
+	return sessionHash
+}
+
+// applySessionAffinity OpenAI 调度路径的会话亲和：X-Session-Affinity 非空时其 hash 优先于
+// session_id / prompt_cache_key 等信号，否则使用 generate（各端点原有的 hash 生成方式）；
+// TTL 与 opt-out 语义和 GatewayHandler 一致，非空 hash 同样通过 X-Session-Affinity-Hash 回显。
+func (h *OpenAIGatewayHandler) applySessionAffinity(c *gin.Context, generate func() string) string {
+	affinity := parseSessionAffinity(c, h.cfg)
+	if affinity.optOut {
+		return ""
+	}
+	if affinity.ttl > 0 {
+		c.Request = c.Request.WithContext(service.WithStickySessionTTL(c.Request.Context(), affinity.ttl))
+	}
+	var sessionHash string
+	if affinity.key != "" {
+		sessionHash = h.gatewayService.GenerateSessionAffinityHash(c, affinity.key)
+	} else {
+		sessionHash = generate()
+	}
+	if sessionHash != "" {
+		c.Header(sessionAffinityHashHeader, sessionHash)
+	}
+	return sessionHash
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionAffinityTestHandler() *GatewayHandler {
+	cfg := &config.Config{}
+	cfg.Gateway.SessionAffinity = config.GatewaySessionAffinityConfig{
+		HeaderEnabled: true,
+		MinTTLSeconds: 60,
+		MaxTTLSeconds: 3600,
+	}
+	return &GatewayHandler{gatewayService: &service.GatewayService{}, cfg: cfg}
+}
+
+func newSessionAffinityTestContext(headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	c.Request = req
+	return c, w
+}
+
+func TestApplySessionAffinity_UsesHeaderKeyAndEchoesHash(t *testing.T) {
+	h := newSessionAffinityTestHandler()
+	c, w := newSessionAffinityTestContext(map[string]string{sessionAffinityHeader: "conv-42"})
+
+	hash := h.applySessionAffinity(c, &service.ParsedRequest{MetadataUserID: "ignored"})
+	require.NotEmpty(t, hash)
+	require.Equal(t, hash, w.Header().Get(sessionAffinityHashHeader))
+
+	c2, _ := newSessionAffinityTestContext(map[string]string{sessionAffinityHeader: "conv-42"})
+	require.Equal(t, hash, h.applySessionAffinity(c2, &service.ParsedRequest{}), "same key must map to the same session")
+
+	_, ok := service.StickySessionTTLFromContext(c.Request.Context())
+	require.False(t, ok, "no TTL header keeps the default TTL")
+}
+
+func TestApplySessionAffinity_ClampsTTL(t *testing.T) {
+	cases := []struct {
+		header string
+		want   time.Duration
+	}{
+		{header: "5", want: time.Minute},
+		{header: "600", want: 10 * time.Minute},
+		{header: "999999", want: time.Hour},
+	}
+	for _, tc := range cases {
+		t.Run(tc.header, func(t *testing.T) {
+			h := newSessionAffinityTestHandler()
+			c, _ := newSessionAffinityTestContext(map[string]string{
+				sessionAffinityHeader:    "conv-1",
+				sessionAffinityTTLHeader: tc.header,
+			})
+
+			h.applySessionAffinity(c, &service.ParsedRequest{})
+			ttl, ok := service.StickySessionTTLFromContext(c.Request.Context())
+			require.True(t, ok)
+			require.Equal(t, tc.want, ttl)
+		})
+	}
+}
+
+func TestApplySessionAffinity_InvalidTTLIgnored(t *testing.T) {
+	h := newSessionAffinityTestHandler()
+	c, _ := newSessionAffinityTestContext(map[string]string{
+		sessionAffinityHeader:    "conv-1",
+		sessionAffinityTTLHeader: "soon",
+	})
+
+	require.NotEmpty(t, h.applySessionAffinity(c, &service.ParsedRequest{}))
+	_, ok := service.StickySessionTTLFromContext(c.Request.Context())
+	require.False(t, ok)
+}
+
+func TestApplySessionAffinity_ZeroTTLOptsOut(t *testing.T) {
+	h := newSessionAffinityTestHandler()
+	c, w := newSessionAffinityTestContext(map[string]string{
+		sessionAffinityHeader:    "conv-1",
+		sessionAffinityTTLHeader: "0",
+	})
+
+	require.Empty(t, h.applySessionAffinity(c, &service.ParsedRequest{}))
+	require.Empty(t, w.Header().Get(sessionAffinityHashHeader))
+}
+
+func TestApplySessionAffinity_HeadersIgnoredWhenDisabled(t *testing.T) {
+	h := newSessionAffinityTestHandler()
+	h.cfg.Gateway.SessionAffinity.HeaderEnabled = false
+	c, w := newSessionAffinityTestContext(map[string]string{
+		sessionAffinityHeader:    "conv-1",
+		sessionAffinityTTLHeader: "0",
+	})
+
+	require.Empty(t, h.applySessionAffinity(c, &service.ParsedRequest{}), "no key and no body content means no session")
+	require.Empty(t, w.Header().Get(sessionAffinityHashHeader))
+}
+
+func newOpenAISessionAffinityTestHandler() *OpenAIGatewayHandler {
+	return &OpenAIGatewayHandler{gatewayService: &service.OpenAIGatewayService{}, cfg: newSessionAffinityTestHandler().cfg}
+}
+
+func TestOpenAIApplySessionAffinity_HeaderKeyOverridesSessionSignals(t *testing.T) {
+	h := newOpenAISessionAffinityTestHandler()
+	c, w := newSessionAffinityTestContext(map[string]string{
+		sessionAffinityHeader:    "conv-42",
+		sessionAffinityTTLHeader: "600",
+		"session_id":             "codex-session",
+	})
+
+	generated := false
+	hash := h.applySessionAffinity(c, func() string {
+		generated = true
+		return "from-session-id"
+	})
+	require.False(t, generated, "X-Session-Affinity takes precedence over session_id / prompt_cache_key")
+	require.Equal(t, service.DeriveSessionHashFromSeed("conv-42"), hash)
+	require.Equal(t, hash, w.Header().Get(sessionAffinityHashHeader))
+
+	ttl, ok := service.StickySessionTTLFromContext(c.Request.Context())
+	require.True(t, ok)
+	require.Equal(t, 10*time.Minute, ttl)
+}
+
+func TestOpenAIApplySessionAffinity_FallsBackAndOptsOut(t *testing.T) {
+	h := newOpenAISessionAffinityTestHandler()
+	c, w := newSessionAffinityTestContext(nil)
+	require.Equal(t, "from-session-id", h.applySessionAffinity(c, func() string { return "from-session-id" }))
+	require.Equal(t, "from-session-id", w.Header().Get(sessionAffinityHashHeader))
+
+	c, w = newSessionAffinityTestContext(map[string]string{sessionAffinityTTLHeader: "0"})
+	require.Empty(t, h.applySessionAffinity(c, func() string { return "from-session-id" }))
+	require.Empty(t, w.Header().Get(sessionAffinityHashHeader))
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/gin-gonic/gin"
+)
+
+// usageByteCounter 统计请求体读取的原始字节数与写给客户端的响应字节数，供用量记录使用。
+// 请求侧用计数 reader 包装 c.Request.Body（流式读取，不额外缓冲）；
+// 响应侧复用 gin ResponseWriter 自带的已写字节计数，流式响应天然累加。
+type usageByteCounter struct {
+	body   *pkghttputil.CountingReadCloser
+	writer gin.ResponseWriter
+}
+
+// newUsageByteCounter 必须在读取请求体之前调用。
+func newUsageByteCounter(c *gin.Context) *usageByteCounter {
+	counter := &usageByteCounter{writer: c.Writer}
+	if c.Request != nil && c.Request.Body != nil {
+		counter.body = pkghttputil.NewCountingReadCloser(c.Request.Body)
+		c.Request.Body = counter.body
+	}
+	return counter
+}
+
+func (u *usageByteCounter) RequestBytes() int64 {
+	if u == nil {
+		return 0
+	}
+	return u.body.BytesRead()
+}
+
+func (u *usageByteCounter) ResponseBytes() int64 {
+	if u == nil || u.writer == nil {
+		return 0
+	}
+	if size := u.writer.Size(); size > 0 {
+		return int64(size)
+	}
+	return 0
+}
@@ -86,7 +86,8 @@ func (h *UserMsgQueueHelper) waitForLockWithPing(
 	streamStarted *bool,
 	reqLog *zap.Logger,
 ) (func(), error) {
-	needPing := isStream && h.pingFormat != ""
+	pingFormat := requestSSEPingFormat(c, h.pingFormat)
+	needPing := isStream && pingFormat != ""
 
 	var flusher http.Flusher
 	if needPing {
@@ -121,7 +122,7 @@ func (h *UserMsgQueueHelper) waitForLockWithPing(
 				c.Header("X-Accel-Buffering", "no")
 				*streamStarted = true
 			}
-			if _, err := fmt.Fprint(c.Writer, string(h.pingFormat)); err != nil {
+			if _, err := fmt.Fprint(c.Writer, string(pingFormat)); err != nil {
 				return nil, err
 			}
 			flusher.Flush()
@@ -194,7 +195,8 @@ func (h *UserMsgQueueHelper) ThrottleWithPing(
 	)
 
 	// 延迟期间发送 SSE ping（复用 waitForLockWithPing 的 ping 逻辑）
-	needPing := isStream && h.pingFormat != ""
+	pingFormat := requestSSEPingFormat(c, h.pingFormat)
+	needPing := isStream && pingFormat != ""
 	var flusher http.Flusher
 	if needPing {
 		flusher, _ = c.Writer.(http.Flusher)
@@ -226,7 +228,7 @@ func (h *UserMsgQueueHelper) ThrottleWithPing(
 				c.Header("X-Accel-Buffering", "no")
 				*streamStarted = true
 			}
-			if _, err := fmt.Fprint(c.Writer, string(h.pingFormat)); err != nil {
+			if _, err := fmt.Fprint(c.Writer, string(pingFormat)); err != nil {
 				return err
 			}
 			flusher.Flush()
@@ -33,6 +33,7 @@ func ProvideAdminHandlers(
 	errorPassthroughHandler *admin.ErrorPassthroughHandler,
 	tlsFingerprintProfileHandler *admin.TLSFingerprintProfileHandler,
 	apiKeyHandler *admin.AdminAPIKeyHandler,
+	apiKeyCaptureHandler *admin.APIKeyCaptureHandler,
 	scheduledTestHandler *admin.ScheduledTestHandler,
 	channelHandler *admin.ChannelHandler,
 	channelMonitorHandler *admin.ChannelMonitorHandler,
@@ -41,7 +42,29 @@ func ProvideAdminHandlers(
 	paymentHandler *admin.PaymentHandler,
 	affiliateHandler *admin.AffiliateHandler,
 	complianceHandler *admin.ComplianceHandler,
+	auditLogHandler *admin.AuditLogHandler,
+	auditLogService *service.AuditLogService,
+	accountHealthProbe *service.AccountHealthProbeService,
+	upstreamRateLimits *service.UpstreamRateLimitTracker,
+	serverErrors *service.AccountServerErrorTracker,
+	usageRecordRetry *service.UsageRecordRetryService,
+	billingCacheService *service.BillingCacheService,
+	identityService *service.IdentityService,
+	logComponentLevels *service.LogComponentLevelService,
 ) *AdminHandlers {
+	// 审计日志通过 setter 挂载，避免改动各 handler 的构造函数签名
+	accountHandler.SetAuditLogService(auditLogService)
+	proxyHandler.SetAuditLogService(auditLogService)
+	apiKeyHandler.SetAuditLogService(auditLogService)
+	errorPassthroughHandler.SetAuditLogService(auditLogService)
+	accountHandler.SetAccountHealthProbeService(accountHealthProbe)
+	accountHandler.SetUpstreamRateLimitTracker(upstreamRateLimits)
+	accountHandler.SetAccountServerErrorTracker(serverErrors)
+	accountHandler.SetIdentityService(identityService)
+	usageHandler.SetUsageRecordRetryService(usageRecordRetry)
+	groupHandler.SetBillingCacheService(billingCacheService)
+	opsHandler.SetLogComponentLevelService(logComponentLevels)
+
 	return &AdminHandlers{
 		Dashboard:              dashboardHandler,
 		User:                   userHandler,
@@ -67,6 +90,7 @@ func ProvideAdminHandlers(
 		ErrorPassthrough:       errorPassthroughHandler,
 		TLSFingerprintProfile:  tlsFingerprintProfileHandler,
 		APIKey:                 apiKeyHandler,
+		APIKeyCapture:          apiKeyCaptureHandler,
 		ScheduledTest:          scheduledTestHandler,
 		Channel:                channelHandler,
 		ChannelMonitor:         channelMonitorHandler,
@@ -75,6 +99,7 @@ func ProvideAdminHandlers(
 		Payment:                paymentHandler,
 		Affiliate:              affiliateHandler,
 		Compliance:             complianceHandler,
+		AuditLog:               auditLogHandler,
 	}
 }
 
@@ -180,6 +205,7 @@ var ProviderSet = wire.NewSet(
 	admin.NewUsageHandler,
 	admin.NewUserAttributeHandler,
 	admin.NewErrorPassthroughHandler,
+	admin.NewAPIKeyCaptureHandler,
 	admin.NewTLSFingerprintProfileHandler,
 	admin.NewAdminAPIKeyHandler,
 	admin.NewScheduledTestHandler,
@@ -190,6 +216,7 @@ var ProviderSet = wire.NewSet(
 	admin.NewPaymentHandler,
 	admin.NewAffiliateHandler,
 	admin.NewComplianceHandler,
+	admin.NewAuditLogHandler,
 
 	// AdminHandlers and Handlers constructors
 	ProvideAdminHandlers,
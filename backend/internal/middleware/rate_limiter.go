@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 )
@@ -60,8 +61,9 @@ var rateLimitRun = func(ctx context.Context, client *redis.Client, key string, w
 
 // RateLimiter Redis 速率限制器
 type RateLimiter struct {
-	redis  *redis.Client
-	prefix string
+	redis     *redis.Client
+	prefix    string
+	allowlist *ip.IPAllowlist
 }
 
 // NewRateLimiter 创建速率限制器实例
@@ -72,6 +74,12 @@ func NewRateLimiter(redisClient *redis.Client) *RateLimiter {
 	}
 }
 
+// SetAllowlist 设置限流豁免白名单，命中的客户端 IP（按可信代理链解析）直接放行。
+// 需在注册路由前调用；nil 表示不豁免任何 IP。
+func (r *RateLimiter) SetAllowlist(allowlist *ip.IPAllowlist) {
+	r.allowlist = allowlist
+}
+
 // Limit 返回速率限制中间件
 // key: 限制类型标识
 // limit: 时间窗口内最大请求数
@@ -88,8 +96,14 @@ func (r *RateLimiter) LimitWithOptions(key string, limit int, window time.Durati
 	}
 
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		redisKey := r.prefix + key + ":" + ip
+		// 白名单与限流键使用同一可信客户端 IP，避免代理场景下两者判定不一致
+		clientIP := ip.GetTrustedClientIP(c)
+		if r.allowlist != nil && r.allowlist.Contains(clientIP) {
+			c.Next()
+			return
+		}
+
+		redisKey := r.prefix + key + ":" + clientIP
 
 		ctx := c.Request.Context()
 
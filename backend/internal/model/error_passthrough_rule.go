@@ -1,7 +1,11 @@
 // Package model 定义服务层使用的数据模型。
 package model
 
-import "time"
+import (
+	"regexp"
+	"strings"
+	"time"
+)
 
 // ErrorPassthroughRule 全局错误透传规则
 // 用于控制上游错误如何返回给客户端
@@ -22,6 +26,22 @@ type ErrorPassthroughRule struct {
 	Description     *string   `json:"description"`      // 规则描述
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+
+	// BodyRegex 响应体正则（RE2，区分大小写，可用 (?i) 忽略大小写）
+	BodyRegex *string `json:"body_regex"`
+	// HeaderMatchers 响应头匹配条件（AND关系，全部满足才算命中）
+	HeaderMatchers []ErrorPassthroughHeaderMatcher `json:"header_matchers"`
+}
+
+// ErrorPassthroughHeaderMatcher 响应头匹配条件：指定响应头的值需匹配正则
+type ErrorPassthroughHeaderMatcher struct {
+	Name  string `json:"name"`  // 响应头名称（不区分大小写）
+	Regex string `json:"regex"` // 响应头值正则（RE2）
+}
+
+// HasBodyRegex 是否配置了响应体正则
+func (r *ErrorPassthroughRule) HasBodyRegex() bool {
+	return r.BodyRegex != nil && strings.TrimSpace(*r.BodyRegex) != ""
 }
 
 // MatchModeAny 表示任一条件匹配即可
@@ -52,9 +72,23 @@ func (r *ErrorPassthroughRule) Validate() error {
 	if r.MatchMode != MatchModeAny && r.MatchMode != MatchModeAll {
 		return &ValidationError{Field: "match_mode", Message: "match_mode must be 'any' or 'all'"}
 	}
-	// 至少需要配置一个匹配条件（错误码或关键词）
-	if len(r.ErrorCodes) == 0 && len(r.Keywords) == 0 {
-		return &ValidationError{Field: "conditions", Message: "at least one error_code or keyword is required"}
+	// 至少需要配置一个匹配条件（错误码、关键词、响应体正则或响应头）
+	if len(r.ErrorCodes) == 0 && len(r.Keywords) == 0 && !r.HasBodyRegex() && len(r.HeaderMatchers) == 0 {
+		return &ValidationError{Field: "conditions", Message: "at least one error_code, keyword, body_regex or header_matcher is required"}
+	}
+	// 正则在创建/更新时编译校验，避免匹配阶段才暴露错误
+	if r.HasBodyRegex() {
+		if _, err := regexp.Compile(*r.BodyRegex); err != nil {
+			return &ValidationError{Field: "body_regex", Message: "invalid body_regex: " + err.Error()}
+		}
+	}
+	for _, hm := range r.HeaderMatchers {
+		if strings.TrimSpace(hm.Name) == "" {
+			return &ValidationError{Field: "header_matchers", Message: "header matcher name is required"}
+		}
+		if _, err := regexp.Compile(hm.Regex); err != nil {
+			return &ValidationError{Field: "header_matchers", Message: "invalid regex for header " + hm.Name + ": " + err.Error()}
+		}
 	}
 	if !r.PassthroughCode && (r.ResponseCode == nil || *r.ResponseCode <= 0) {
 		return &ValidationError{Field: "response_code", Message: "response_code is required when passthrough_code is false"}
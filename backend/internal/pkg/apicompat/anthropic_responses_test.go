@@ -1388,6 +1388,59 @@ func TestAnthropicToResponses_ToolResultMixed(t *testing.T) {
 	assert.Equal(t, "data:image/png;base64,AAAA", parts[0].ImageURL)
 }
 
+func TestAnthropicToResponses_TwoToolResultsOneWithImage(t *testing.T) {
+	req := &AnthropicRequest{
+		Model:     "gpt-5.2",
+		MaxTokens: 1024,
+		Messages: []AnthropicMessage{
+			{Role: "user", Content: json.RawMessage(`"check the page"`)},
+			{Role: "assistant", Content: json.RawMessage(`[
+				{"type":"tool_use","id":"toolu_a","name":"read_log","input":{}},
+				{"type":"tool_use","id":"toolu_b","name":"screenshot","input":{}}
+			]`)},
+			{Role: "user", Content: json.RawMessage(`[
+				{"type":"tool_result","tool_use_id":"toolu_a","content":"log ok"},
+				{"type":"tool_result","tool_use_id":"toolu_b","content":[
+					{"type":"text","text":"captured 800x600"},
+					{"type":"image","source":{"type":"base64","media_type":"image/png","data":"BBBB"}}
+				]},
+				{"type":"text","text":"what do you see?"}
+			]`)},
+		},
+	}
+
+	resp, err := AnthropicToResponses(req)
+	require.NoError(t, err)
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	// user + 2×function_call + 2×function_call_output + user(image, text) = 6
+	require.Len(t, items, 6)
+
+	assert.Equal(t, "function_call", items[1].Type)
+	assert.Equal(t, "toolu_a", items[1].CallID)
+	assert.Equal(t, "function_call", items[2].Type)
+	assert.Equal(t, "toolu_b", items[2].CallID)
+
+	assert.Equal(t, "function_call_output", items[3].Type)
+	assert.Equal(t, "toolu_a", items[3].CallID)
+	assert.Equal(t, "log ok", items[3].Output)
+	assert.Equal(t, "function_call_output", items[4].Type)
+	assert.Equal(t, "toolu_b", items[4].CallID)
+	assert.Equal(t, "captured 800x600", items[4].Output)
+
+	// The screenshot follows the outputs and precedes the user's own text.
+	assert.Equal(t, "message", items[5].Type)
+	assert.Equal(t, "user", items[5].Role)
+	var parts []ResponsesContentPart
+	require.NoError(t, json.Unmarshal(items[5].Content, &parts))
+	require.Len(t, parts, 2)
+	assert.Equal(t, "input_image", parts[0].Type)
+	assert.Equal(t, "data:image/png;base64,BBBB", parts[0].ImageURL)
+	assert.Equal(t, "input_text", parts[1].Type)
+	assert.Equal(t, "what do you see?", parts[1].Text)
+}
+
 func TestAnthropicToResponses_TextOnlyToolResultBackwardCompat(t *testing.T) {
 	req := &AnthropicRequest{
 		Model:     "gpt-5.2",
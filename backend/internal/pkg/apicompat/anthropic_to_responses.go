@@ -22,10 +22,11 @@ func AnthropicToResponses(req *AnthropicRequest) (*ResponsesRequest, error) {
 	}
 
 	out := &ResponsesRequest{
-		Model:   req.Model,
-		Input:   inputJSON,
-		Stream:  req.Stream,
-		Include: []string{"reasoning.encrypted_content"},
+		Model:        req.Model,
+		Instructions: req.Instructions,
+		Input:        inputJSON,
+		Stream:       req.Stream,
+		Include:      []string{"reasoning.encrypted_content"},
 	}
 
 	// Reasoning models (gpt-5.x) served via the Responses API do not accept
@@ -226,9 +227,10 @@ func anthropicUserToResponses(raw json.RawMessage) ([]ResponsesInputItem, error)
 		toolResultImageParts = append(toolResultImageParts, imageParts...)
 	}
 
-	// Remaining text + image blocks → user message with content parts.
-	// Also include images extracted from tool_results so the model can see them.
-	var parts []ResponsesContentPart
+	// Images extracted from tool_results lead the follow-up user message so the
+	// model sees them right after the function_call_output items they belong to;
+	// the remaining text + image blocks follow in their original order.
+	parts := toolResultImageParts
 	for _, b := range blocks {
 		switch b.Type {
 		case "text":
@@ -241,7 +243,6 @@ func anthropicUserToResponses(raw json.RawMessage) ([]ResponsesInputItem, error)
 			}
 		}
 	}
-	parts = append(parts, toolResultImageParts...)
 
 	if len(parts) > 0 {
 		content, err := json.Marshal(parts)
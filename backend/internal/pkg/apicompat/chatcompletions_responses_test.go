@@ -1555,3 +1555,36 @@ func TestBufferedResponseAccumulator_IgnoresNonFunctionCallItems(t *testing.T) {
 
 	assert.False(t, acc.HasContent())
 }
+
+func TestChatCompletionsResponseToChunk_ToolCalls(t *testing.T) {
+	resp := &ChatCompletionsResponse{
+		ID:      "chatcmpl-2",
+		Created: 1700000000,
+		Model:   "gpt-5",
+		Choices: []ChatChoice{{
+			Index: 0,
+			Message: ChatMessage{
+				Role:             "assistant",
+				ReasoningContent: "thinking",
+				ToolCalls: []ChatToolCall{
+					{ID: "call_a", Type: "function", Function: ChatFunctionCall{Name: "a", Arguments: "{}"}},
+					{ID: "call_b", Type: "function", Function: ChatFunctionCall{Name: "b", Arguments: "{}"}},
+				},
+			},
+			FinishReason: "tool_calls",
+		}},
+	}
+
+	chunk := ChatCompletionsResponseToChunk(resp)
+
+	require.Equal(t, "chat.completion.chunk", chunk.Object)
+	require.Len(t, chunk.Choices, 1)
+	delta := chunk.Choices[0].Delta
+	require.Nil(t, delta.Content)
+	require.NotNil(t, delta.ReasoningContent)
+	require.Equal(t, "thinking", *delta.ReasoningContent)
+	require.Len(t, delta.ToolCalls, 2)
+	require.Equal(t, 0, *delta.ToolCalls[0].Index)
+	require.Equal(t, 1, *delta.ToolCalls[1].Index)
+	require.Equal(t, "tool_calls", *chunk.Choices[0].FinishReason)
+}
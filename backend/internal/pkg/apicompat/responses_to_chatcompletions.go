@@ -203,6 +203,48 @@ func ChatChunkToSSE(chunk ChatCompletionsChunk) (string, error) {
 	return fmt.Sprintf("data: %s\n\n", data), nil
 }
 
+// ChatCompletionsResponseToChunk folds a complete non-streaming Chat
+// Completions response into a single chat.completion.chunk carrying the full
+// delta, finish_reason and usage, so it can be replayed to a streaming client.
+func ChatCompletionsResponseToChunk(resp *ChatCompletionsResponse) ChatCompletionsChunk {
+	chunk := ChatCompletionsChunk{
+		ID:                resp.ID,
+		Object:            "chat.completion.chunk",
+		Created:           resp.Created,
+		Model:             resp.Model,
+		Choices:           make([]ChatChunkChoice, 0, len(resp.Choices)),
+		Usage:             resp.Usage,
+		SystemFingerprint: resp.SystemFingerprint,
+		ServiceTier:       resp.ServiceTier,
+	}
+	for _, choice := range resp.Choices {
+		delta := ChatDelta{Role: nonEmpty(choice.Message.Role, "assistant")}
+		if text := chatMessageContentText(choice.Message.Content); text != "" || len(choice.Message.ToolCalls) == 0 {
+			delta.Content = &text
+		}
+		if choice.Message.ReasoningContent != "" {
+			reasoning := choice.Message.ReasoningContent
+			delta.ReasoningContent = &reasoning
+		}
+		for i, call := range choice.Message.ToolCalls {
+			idx := i
+			call.Index = &idx
+			delta.ToolCalls = append(delta.ToolCalls, call)
+		}
+		var finishReason *string
+		if choice.FinishReason != "" {
+			reason := choice.FinishReason
+			finishReason = &reason
+		}
+		chunk.Choices = append(chunk.Choices, ChatChunkChoice{
+			Index:        choice.Index,
+			Delta:        delta,
+			FinishReason: finishReason,
+		})
+	}
+	return chunk
+}
+
 // --- internal handlers ---
 
 func resToChatHandleCreated(evt *ResponsesStreamEvent, state *ResponsesEventToChatState) []ChatCompletionsChunk {
@@ -30,6 +30,8 @@ type AnthropicRequest struct {
 	// user_id，进而导致请求被归类为第三方 app。
 	Metadata     json.RawMessage        `json:"metadata,omitempty"`
 	OutputConfig *AnthropicOutputConfig `json:"output_config,omitempty"`
+	// Instructions 非 Anthropic 协议字段：承载网关注入的分组 instructions，转换时映射为 Responses instructions。
+	Instructions string `json:"instructions,omitempty"`
 }
 
 // AnthropicOutputConfig controls output generation parameters.
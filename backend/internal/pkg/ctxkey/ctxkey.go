@@ -41,6 +41,9 @@ const (
 	// Group 认证后的分组信息，由 API Key 认证中间件设置
 	Group Key = "ctx_group"
 
+	// APIKeyID 认证后的 API Key ID，由 API Key 认证中间件设置（供上游请求抓取等按 Key 生效的功能使用）
+	APIKeyID Key = "ctx_api_key_id"
+
 	// IsMaxTokensOneHaikuRequest 标识当前请求是否为 max_tokens=1 + haiku 模型的探测请求
 	// 用于 ClaudeCodeOnly 验证绕过（绕过 system prompt 检查，但仍需验证 User-Agent）
 	IsMaxTokensOneHaikuRequest Key = "ctx_is_max_tokens_one_haiku"
@@ -59,4 +62,8 @@ const (
 
 	// ClaudeCodeVersion stores the extracted Claude Code version from User-Agent (e.g. "2.1.22")
 	ClaudeCodeVersion Key = "ctx_claude_code_version"
+
+	// AccountLabelSelector 账号标签选择器（[]string）：API Key 配置与 X-Account-Labels 请求头合并，
+	// 调度时仅在同时带有全部标签的账号中选择
+	AccountLabelSelector Key = "ctx_account_label_selector"
 )
@@ -0,0 +1,35 @@
+package httputil
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// CountingReadCloser wraps an io.ReadCloser and counts the bytes read through
+// it without retaining them, so callers can measure wire-level body sizes
+// without buffering the body a second time.
+type CountingReadCloser struct {
+	io.ReadCloser
+	n atomic.Int64
+}
+
+// NewCountingReadCloser returns a CountingReadCloser around rc.
+func NewCountingReadCloser(rc io.ReadCloser) *CountingReadCloser {
+	return &CountingReadCloser{ReadCloser: rc}
+}
+
+func (r *CountingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.n.Add(int64(n))
+	}
+	return n, err
+}
+
+// BytesRead reports the number of bytes read so far.
+func (r *CountingReadCloser) BytesRead() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.n.Load()
+}
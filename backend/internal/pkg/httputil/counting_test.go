@@ -0,0 +1,51 @@
+package httputil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestCountingReadCloser_CountsBytesRead(t *testing.T) {
+	rc := NewCountingReadCloser(io.NopCloser(bytes.NewReader([]byte(samplePayload))))
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != samplePayload {
+		t.Fatalf("body mismatch: %q", got)
+	}
+	if rc.BytesRead() != int64(len(samplePayload)) {
+		t.Fatalf("BytesRead = %d, want %d", rc.BytesRead(), len(samplePayload))
+	}
+}
+
+func TestCountingReadCloser_CountsWireBytesBeforeDecoding(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, _ = gw.Write([]byte(samplePayload))
+	_ = gw.Close()
+	compressedLen := int64(buf.Len())
+
+	req := newRequestWithBody(t, buf.Bytes(), "gzip")
+	counter := NewCountingReadCloser(req.Body)
+	req.Body = counter
+	got, err := ReadRequestBodyWithPrealloc(req)
+	if err != nil {
+		t.Fatalf("ReadRequestBodyWithPrealloc: %v", err)
+	}
+	if string(got) != samplePayload {
+		t.Fatalf("decoded body mismatch: %q", got)
+	}
+	if counter.BytesRead() != compressedLen {
+		t.Fatalf("BytesRead = %d, want %d", counter.BytesRead(), compressedLen)
+	}
+}
+
+func TestCountingReadCloser_NilSafe(t *testing.T) {
+	var rc *CountingReadCloser
+	if rc.BytesRead() != 0 {
+		t.Fatal("nil counter should report 0")
+	}
+}
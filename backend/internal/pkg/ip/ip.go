@@ -2,6 +2,7 @@
 package ip
 
 import (
+	"fmt"
 	"net"
 	"strings"
 
@@ -249,3 +250,53 @@ func ValidateIPPatterns(patterns []string) []string {
 	}
 	return invalid
 }
+
+// PrivateRangesPattern 是 IP 白名单中的特殊关键字，匹配所有私有/回环地址（见 isPrivateIP）。
+const PrivateRangesPattern = "private"
+
+// IPAllowlist 是严格解析后的 IP 白名单，用于限流豁免等启动期固定的配置。
+type IPAllowlist struct {
+	rules        *CompiledIPRules
+	allowPrivate bool
+}
+
+// ParseIPAllowlist 严格解析 IP/CIDR 白名单。
+// 与 CompileIPRules 不同，任何非法规则都会返回错误而不是被静默忽略；
+// 空列表返回 nil（表示未配置白名单）。
+func ParseIPAllowlist(patterns []string) (*IPAllowlist, error) {
+	normalized := make([]string, 0, len(patterns))
+	allowPrivate := false
+	for _, pattern := range patterns {
+		p := strings.TrimSpace(pattern)
+		if p == "" {
+			continue
+		}
+		if strings.EqualFold(p, PrivateRangesPattern) {
+			allowPrivate = true
+			continue
+		}
+		if !ValidateIPPattern(p) {
+			return nil, fmt.Errorf("invalid IP or CIDR %q", pattern)
+		}
+		normalized = append(normalized, p)
+	}
+	if len(normalized) == 0 && !allowPrivate {
+		return nil, nil
+	}
+	return &IPAllowlist{
+		rules:        CompileIPRules(normalized),
+		allowPrivate: allowPrivate,
+	}, nil
+}
+
+// Contains 检查客户端 IP 是否命中白名单。nil 白名单不匹配任何 IP。
+func (a *IPAllowlist) Contains(clientIP string) bool {
+	if a == nil {
+		return false
+	}
+	clientIP = normalizeIP(clientIP)
+	if a.allowPrivate && isPrivateIP(clientIP) {
+		return true
+	}
+	return matchesCompiledRules(net.ParseIP(clientIP), a.rules)
+}
@@ -94,3 +94,21 @@ func TestCheckIPRestrictionWithCompiledRules_InvalidWhitelistStillDenies(t *test
 	require.False(t, allowed)
 	require.Equal(t, "access denied", reason)
 }
+
+func TestParseIPAllowlist(t *testing.T) {
+	allowlist, err := ParseIPAllowlist([]string{" 198.51.100.0/24 ", "203.0.113.7", "private"})
+	require.NoError(t, err)
+	require.True(t, allowlist.Contains("198.51.100.20"))
+	require.True(t, allowlist.Contains("203.0.113.7:443"))
+	require.True(t, allowlist.Contains("10.1.2.3"))
+	require.False(t, allowlist.Contains("8.8.8.8"))
+
+	empty, err := ParseIPAllowlist([]string{"", "  "})
+	require.NoError(t, err)
+	require.Nil(t, empty)
+	require.False(t, empty.Contains("10.1.2.3"))
+
+	_, err = ParseIPAllowlist([]string{"198.51.100.0/24", "not-a-cidr"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not-a-cidr")
+}
@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ComponentLevel 描述单个组件的运行时日志级别。
+type ComponentLevel struct {
+	Component    string     `json:"component"`
+	Level        string     `json:"level"`
+	DefaultLevel string     `json:"default_level"`
+	RevertAt     *time.Time `json:"revert_at,omitempty"`
+}
+
+type componentLevelEntry struct {
+	level        zap.AtomicLevel
+	defaultLevel Level
+	overridden   bool
+	revertAt     time.Time
+	revertTimer  *time.Timer
+}
+
+var (
+	componentMu     sync.RWMutex
+	componentLevels = map[string]*componentLevelEntry{}
+)
+
+func normalizeComponent(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// RegisterComponent 注册组件并设置启动默认级别（通常来自配置/环境变量）。
+// 重复注册只更新默认级别；存在运行时覆盖时保留当前级别。
+func RegisterComponent(name string, defaultLevel Level) {
+	name = normalizeComponent(name)
+	if name == "" {
+		return
+	}
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	entry, ok := componentLevels[name]
+	if !ok {
+		componentLevels[name] = &componentLevelEntry{
+			level:        zap.NewAtomicLevelAt(defaultLevel),
+			defaultLevel: defaultLevel,
+		}
+		return
+	}
+	entry.defaultLevel = defaultLevel
+	if !entry.overridden {
+		entry.level.SetLevel(defaultLevel)
+	}
+}
+
+// ComponentEnabled 判断组件在给定级别是否启用；未注册组件按 info 处理。
+func ComponentEnabled(name string, level Level) bool {
+	componentMu.RLock()
+	entry, ok := componentLevels[normalizeComponent(name)]
+	componentMu.RUnlock()
+	if !ok {
+		return level >= LevelInfo
+	}
+	return entry.level.Enabled(level)
+}
+
+// SetComponentLevel 在运行时修改组件级别，无需重启。
+// revertAfter>0 时到期自动恢复为默认级别；再次设置会替换之前的自动恢复计划。
+func SetComponentLevel(name, level string, revertAfter time.Duration) (ComponentLevel, error) {
+	lv, ok := parseLevel(level)
+	if !ok {
+		return ComponentLevel{}, fmt.Errorf("invalid log level: %s", level)
+	}
+	name = normalizeComponent(name)
+
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	entry, ok := componentLevels[name]
+	if !ok {
+		return ComponentLevel{}, fmt.Errorf("unknown log component: %s", name)
+	}
+	entry.stopRevertLocked()
+	entry.level.SetLevel(lv)
+	entry.overridden = true
+	if revertAfter > 0 {
+		entry.revertAt = time.Now().Add(revertAfter)
+		var timer *time.Timer
+		timer = time.AfterFunc(revertAfter, func() {
+			componentMu.Lock()
+			defer componentMu.Unlock()
+			// 仅恢复本次计划；期间若已被重新设置则忽略
+			if entry.revertTimer != timer {
+				return
+			}
+			entry.revertTimer = nil
+			entry.revertAt = time.Time{}
+			entry.overridden = false
+			entry.level.SetLevel(entry.defaultLevel)
+		})
+		entry.revertTimer = timer
+	}
+	return entry.snapshot(name), nil
+}
+
+// ResetComponentLevel 将组件恢复为默认级别并取消自动恢复计划。
+func ResetComponentLevel(name string) (ComponentLevel, error) {
+	name = normalizeComponent(name)
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	entry, ok := componentLevels[name]
+	if !ok {
+		return ComponentLevel{}, fmt.Errorf("unknown log component: %s", name)
+	}
+	entry.stopRevertLocked()
+	entry.overridden = false
+	entry.level.SetLevel(entry.defaultLevel)
+	return entry.snapshot(name), nil
+}
+
+// ComponentLevels 返回所有已注册组件的当前级别（按名称排序）。
+func ComponentLevels() []ComponentLevel {
+	componentMu.RLock()
+	defer componentMu.RUnlock()
+	out := make([]ComponentLevel, 0, len(componentLevels))
+	for name, entry := range componentLevels {
+		out = append(out, entry.snapshot(name))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Component < out[j].Component })
+	return out
+}
+
+func (e *componentLevelEntry) stopRevertLocked() {
+	if e.revertTimer != nil {
+		e.revertTimer.Stop()
+		e.revertTimer = nil
+	}
+	e.revertAt = time.Time{}
+}
+
+func (e *componentLevelEntry) snapshot(name string) ComponentLevel {
+	out := ComponentLevel{
+		Component:    name,
+		Level:        e.level.Level().String(),
+		DefaultLevel: e.defaultLevel.String(),
+	}
+	if !e.revertAt.IsZero() {
+		revertAt := e.revertAt
+		out.RevertAt = &revertAt
+	}
+	return out
+}
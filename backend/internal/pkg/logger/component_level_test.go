@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComponentLevel_SetAndAutoRevert(t *testing.T) {
+	const name = "test.auto_revert"
+	RegisterComponent(name, LevelInfo)
+	t.Cleanup(func() { _, _ = ResetComponentLevel(name) })
+
+	if ComponentEnabled(name, LevelDebug) {
+		t.Fatalf("debug should be disabled by default")
+	}
+	got, err := SetComponentLevel(name, "debug", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("SetComponentLevel() error: %v", err)
+	}
+	if got.Level != "debug" || got.DefaultLevel != "info" || got.RevertAt == nil {
+		t.Fatalf("unexpected snapshot: %+v", got)
+	}
+	if !ComponentEnabled(name, LevelDebug) {
+		t.Fatalf("debug should be enabled after runtime change")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for ComponentEnabled(name, LevelDebug) {
+		if time.Now().After(deadline) {
+			t.Fatalf("level did not revert to default")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, level := range ComponentLevels() {
+		if level.Component == name && level.RevertAt != nil {
+			t.Fatalf("revert_at should be cleared after revert: %+v", level)
+		}
+	}
+}
+
+func TestComponentLevel_SetReplacesPendingRevert(t *testing.T) {
+	const name = "test.replace_revert"
+	RegisterComponent(name, LevelInfo)
+	t.Cleanup(func() { _, _ = ResetComponentLevel(name) })
+
+	if _, err := SetComponentLevel(name, "debug", 30*time.Millisecond); err != nil {
+		t.Fatalf("SetComponentLevel() error: %v", err)
+	}
+	if _, err := SetComponentLevel(name, "debug", 0); err != nil {
+		t.Fatalf("SetComponentLevel() error: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if !ComponentEnabled(name, LevelDebug) {
+		t.Fatalf("replaced revert schedule should not fire")
+	}
+}
+
+func TestComponentLevel_UnknownAndInvalid(t *testing.T) {
+	if ComponentEnabled("test.unregistered", LevelDebug) {
+		t.Fatalf("unregistered component should default to info")
+	}
+	if !ComponentEnabled("test.unregistered", LevelInfo) {
+		t.Fatalf("unregistered component should allow info")
+	}
+	if _, err := SetComponentLevel("test.unregistered", "debug", 0); err == nil {
+		t.Fatalf("expected error for unknown component")
+	}
+	RegisterComponent("test.invalid_level", LevelInfo)
+	if _, err := SetComponentLevel("test.invalid_level", "verbose", 0); err == nil {
+		t.Fatalf("expected error for invalid level")
+	}
+}
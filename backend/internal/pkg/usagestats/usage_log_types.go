@@ -270,8 +270,10 @@ type UsageLogFilters struct {
 	Stream      *bool
 	BillingType *int8
 	BillingMode string
-	StartTime   *time.Time
-	EndTime     *time.Time
+	// Scope 按 API Key 作用域（chat/images/video/embeddings）筛选，依据入站端点归类
+	Scope     string
+	StartTime *time.Time
+	EndTime   *time.Time
 	// ExactTotal requests exact COUNT(*) for pagination. Default false for fast large-table paging.
 	ExactTotal bool
 }
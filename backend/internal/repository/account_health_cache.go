@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// accountHealthKey 账号健康探测结果：Hash，field 为账号 ID，value 为 JSON
+const accountHealthKey = "account_health"
+
+type accountHealthCache struct {
+	rdb *redis.Client
+}
+
+func NewAccountHealthCache(rdb *redis.Client) service.AccountHealthCache {
+	return &accountHealthCache{rdb: rdb}
+}
+
+func (c *accountHealthCache) SaveAccountHealth(ctx context.Context, statuses []service.AccountHealthStatus, ttl time.Duration) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	values := make(map[string]any, len(statuses))
+	for _, st := range statuses {
+		raw, err := json.Marshal(st)
+		if err != nil {
+			return err
+		}
+		values[strconv.FormatInt(st.AccountID, 10)] = raw
+	}
+	pipe := c.rdb.TxPipeline()
+	pipe.HSet(ctx, accountHealthKey, values)
+	if ttl > 0 {
+		pipe.Expire(ctx, accountHealthKey, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *accountHealthCache) ListAccountHealth(ctx context.Context) ([]service.AccountHealthStatus, error) {
+	entries, err := c.rdb.HGetAll(ctx, accountHealthKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]service.AccountHealthStatus, 0, len(entries))
+	for _, raw := range entries {
+		var st service.AccountHealthStatus
+		if err := json.Unmarshal([]byte(raw), &st); err != nil || st.AccountID <= 0 {
+			continue
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+func (c *accountHealthCache) DeleteAccountHealth(ctx context.Context, accountIDs []int64) error {
+	if len(accountIDs) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		fields = append(fields, strconv.FormatInt(id, 10))
+	}
+	return c.rdb.HDel(ctx, accountHealthKey, fields...).Err()
+}
@@ -0,0 +1,48 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountHealthCache_SaveListDelete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewAccountHealthCache(rdb)
+	ctx := context.Background()
+
+	checkedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, cache.SaveAccountHealth(ctx, []service.AccountHealthStatus{
+		{AccountID: 1, Platform: service.PlatformOpenAI, Healthy: true, LatencyMs: 120, CheckedAt: checkedAt},
+		{AccountID: 2, Platform: service.PlatformAnthropic, ConsecutiveFailures: 3, StatusCode: 401, CheckedAt: checkedAt},
+	}, time.Minute))
+	require.Equal(t, time.Minute, mr.TTL(accountHealthKey))
+
+	statuses, err := cache.ListAccountHealth(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	byID := map[int64]service.AccountHealthStatus{}
+	for _, st := range statuses {
+		byID[st.AccountID] = st
+	}
+	require.True(t, byID[1].Healthy)
+	require.EqualValues(t, 120, byID[1].LatencyMs)
+	require.False(t, byID[2].Healthy)
+	require.Equal(t, 3, byID[2].ConsecutiveFailures)
+	require.True(t, byID[2].CheckedAt.Equal(checkedAt))
+
+	require.NoError(t, cache.DeleteAccountHealth(ctx, []int64{2}))
+	statuses, err = cache.ListAccountHealth(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	require.EqualValues(t, 1, statuses[0].AccountID)
+}
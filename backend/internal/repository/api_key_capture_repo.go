@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/ent/apikey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type apiKeyCaptureRepository struct {
+	client *dbent.Client
+	db     *sql.DB
+}
+
+// NewAPIKeyCaptureRepository 创建 API Key 抓取仓储：开关存于 api_keys（Ent），记录存于 api_key_request_captures（原生 SQL）。
+func NewAPIKeyCaptureRepository(client *dbent.Client, db *sql.DB) service.APIKeyCaptureRepository {
+	return &apiKeyCaptureRepository{client: client, db: db}
+}
+
+func (r *apiKeyCaptureRepository) SetCaptureUntil(ctx context.Context, apiKeyID int64, until *time.Time) error {
+	update := r.client.APIKey.Update().
+		Where(apikey.IDEQ(apiKeyID), apikey.DeletedAtIsNil())
+	if until != nil {
+		update.SetCaptureUntil(*until)
+	} else {
+		update.ClearCaptureUntil()
+	}
+	affected, err := update.Save(ctx)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyCaptureRepository) GetCaptureUntil(ctx context.Context, apiKeyID int64) (*time.Time, error) {
+	m, err := r.client.APIKey.Query().
+		Where(apikey.IDEQ(apiKeyID), apikey.DeletedAtIsNil()).
+		Select(apikey.FieldID, apikey.FieldCaptureUntil).
+		Only(ctx)
+	if err != nil {
+		if dbent.IsNotFound(err) {
+			return nil, service.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return m.CaptureUntil, nil
+}
+
+func (r *apiKeyCaptureRepository) ListActiveCaptures(ctx context.Context, now time.Time) (map[int64]time.Time, error) {
+	rows, err := r.client.APIKey.Query().
+		Where(apikey.CaptureUntilGT(now), apikey.DeletedAtIsNil()).
+		Select(apikey.FieldID, apikey.FieldCaptureUntil).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]time.Time, len(rows))
+	for _, m := range rows {
+		if m.CaptureUntil != nil {
+			out[m.ID] = *m.CaptureUntil
+		}
+	}
+	return out, nil
+}
+
+const insertAPIKeyCaptureSQL = `
+INSERT INTO api_key_request_captures (
+  api_key_id, account_id, method, url,
+  request_headers, request_body, request_truncated,
+  response_status, response_headers, response_body, response_truncated,
+  error_message, duration_ms, created_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())
+RETURNING id, created_at`
+
+func (r *apiKeyCaptureRepository) Insert(ctx context.Context, capture *service.APIKeyCapture) error {
+	requestHeaders, err := json.Marshal(capture.RequestHeaders)
+	if err != nil {
+		return err
+	}
+	responseHeaders, err := json.Marshal(capture.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx, insertAPIKeyCaptureSQL,
+		capture.APIKeyID,
+		capture.AccountID,
+		capture.Method,
+		capture.URL,
+		requestHeaders,
+		capture.RequestBody,
+		capture.RequestTruncated,
+		capture.ResponseStatus,
+		responseHeaders,
+		capture.ResponseBody,
+		capture.ResponseTruncated,
+		capture.ErrorMessage,
+		capture.DurationMs,
+	).Scan(&capture.ID, &capture.CreatedAt)
+}
+
+func (r *apiKeyCaptureRepository) List(ctx context.Context, apiKeyID int64, params pagination.PaginationParams) ([]service.APIKeyCapture, *pagination.PaginationResult, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM api_key_request_captures WHERE api_key_id = $1`, apiKeyID,
+	).Scan(&total); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, api_key_id, account_id, method, url,
+  request_headers, request_body, request_truncated,
+  response_status, response_headers, response_body, response_truncated,
+  error_message, duration_ms, created_at
+FROM api_key_request_captures
+WHERE api_key_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3`, apiKeyID, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.APIKeyCapture, 0, params.Limit())
+	for rows.Next() {
+		var (
+			item            service.APIKeyCapture
+			requestHeaders  []byte
+			responseHeaders []byte
+		)
+		if err := rows.Scan(
+			&item.ID, &item.APIKeyID, &item.AccountID, &item.Method, &item.URL,
+			&requestHeaders, &item.RequestBody, &item.RequestTruncated,
+			&item.ResponseStatus, &responseHeaders, &item.ResponseBody, &item.ResponseTruncated,
+			&item.ErrorMessage, &item.DurationMs, &item.CreatedAt,
+		); err != nil {
+			return nil, nil, err
+		}
+		if len(requestHeaders) > 0 {
+			_ = json.Unmarshal(requestHeaders, &item.RequestHeaders)
+		}
+		if len(responseHeaders) > 0 {
+			_ = json.Unmarshal(responseHeaders, &item.ResponseHeaders)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
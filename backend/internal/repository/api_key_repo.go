@@ -59,6 +59,21 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 	if len(key.IPBlacklist) > 0 {
 		builder.SetIPBlacklist(key.IPBlacklist)
 	}
+	if len(key.AccountLabels) > 0 {
+		builder.SetAccountLabels(key.AccountLabels)
+	}
+	if key.ResponseCacheEnabled {
+		builder.SetResponseCacheEnabled(true)
+	}
+	if key.CostPreviewEnabled {
+		builder.SetCostPreviewEnabled(true)
+	}
+	if len(key.Scopes) > 0 {
+		builder.SetScopes(key.Scopes)
+	}
+	if len(key.AllowedModels) > 0 {
+		builder.SetAllowedModels(key.AllowedModels)
+	}
 
 	created, err := builder.Save(ctx)
 	if err == nil {
@@ -134,6 +149,11 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 			apikey.FieldStatus,
 			apikey.FieldIPWhitelist,
 			apikey.FieldIPBlacklist,
+			apikey.FieldAccountLabels,
+			apikey.FieldResponseCacheEnabled,
+			apikey.FieldCostPreviewEnabled,
+			apikey.FieldScopes,
+			apikey.FieldAllowedModels,
 			apikey.FieldQuota,
 			apikey.FieldQuotaUsed,
 			apikey.FieldExpiresAt,
@@ -194,6 +214,12 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldMessagesDispatchModelConfig,
 				group.FieldModelsListConfig,
 				group.FieldRpmLimit,
+				group.FieldPromptCacheInject,
+				group.FieldModelFallbackConfig,
+				group.FieldInstructionInjectionConfig,
+				group.FieldSpendCapDailyUsd,
+				group.FieldSpendCapMonthlyUsd,
+				group.FieldSpendCapTimezone,
 			)
 		}).
 		Only(ctx)
@@ -268,6 +294,23 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 	} else {
 		builder.ClearIPBlacklist()
 	}
+	if len(key.AccountLabels) > 0 {
+		builder.SetAccountLabels(key.AccountLabels)
+	} else {
+		builder.ClearAccountLabels()
+	}
+	builder.SetResponseCacheEnabled(key.ResponseCacheEnabled)
+	builder.SetCostPreviewEnabled(key.CostPreviewEnabled)
+	if len(key.Scopes) > 0 {
+		builder.SetScopes(key.Scopes)
+	} else {
+		builder.ClearScopes()
+	}
+	if len(key.AllowedModels) > 0 {
+		builder.SetAllowedModels(key.AllowedModels)
+	} else {
+		builder.ClearAllowedModels()
+	}
 
 	affected, err := builder.Save(ctx)
 	if err != nil {
@@ -698,29 +741,34 @@ func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 		return nil
 	}
 	out := &service.APIKey{
-		ID:            m.ID,
-		UserID:        m.UserID,
-		Key:           m.Key,
-		Name:          m.Name,
-		Status:        m.Status,
-		IPWhitelist:   m.IPWhitelist,
-		IPBlacklist:   m.IPBlacklist,
-		LastUsedAt:    m.LastUsedAt,
-		CreatedAt:     m.CreatedAt,
-		UpdatedAt:     m.UpdatedAt,
-		GroupID:       m.GroupID,
-		Quota:         m.Quota,
-		QuotaUsed:     m.QuotaUsed,
-		ExpiresAt:     m.ExpiresAt,
-		RateLimit5h:   m.RateLimit5h,
-		RateLimit1d:   m.RateLimit1d,
-		RateLimit7d:   m.RateLimit7d,
-		Usage5h:       m.Usage5h,
-		Usage1d:       m.Usage1d,
-		Usage7d:       m.Usage7d,
-		Window5hStart: m.Window5hStart,
-		Window1dStart: m.Window1dStart,
-		Window7dStart: m.Window7dStart,
+		ID:                   m.ID,
+		UserID:               m.UserID,
+		Key:                  m.Key,
+		Name:                 m.Name,
+		Status:               m.Status,
+		IPWhitelist:          m.IPWhitelist,
+		IPBlacklist:          m.IPBlacklist,
+		AccountLabels:        m.AccountLabels,
+		ResponseCacheEnabled: m.ResponseCacheEnabled,
+		CostPreviewEnabled:   m.CostPreviewEnabled,
+		Scopes:               m.Scopes,
+		AllowedModels:        m.AllowedModels,
+		LastUsedAt:           m.LastUsedAt,
+		CreatedAt:            m.CreatedAt,
+		UpdatedAt:            m.UpdatedAt,
+		GroupID:              m.GroupID,
+		Quota:                m.Quota,
+		QuotaUsed:            m.QuotaUsed,
+		ExpiresAt:            m.ExpiresAt,
+		RateLimit5h:          m.RateLimit5h,
+		RateLimit1d:          m.RateLimit1d,
+		RateLimit7d:          m.RateLimit7d,
+		Usage5h:              m.Usage5h,
+		Usage1d:              m.Usage1d,
+		Usage7d:              m.Usage7d,
+		Window5hStart:        m.Window5hStart,
+		Window1dStart:        m.Window1dStart,
+		Window7dStart:        m.Window7dStart,
 	}
 	if m.Edges.User != nil {
 		out.User = userEntityToService(m.Edges.User)
@@ -814,6 +862,12 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		MessagesDispatchModelConfig:     g.MessagesDispatchModelConfig,
 		ModelsListConfig:                g.ModelsListConfig,
 		RPMLimit:                        g.RpmLimit,
+		PromptCacheInject:               g.PromptCacheInject,
+		ModelFallbackConfig:             g.ModelFallbackConfig,
+		InstructionInjectionConfig:      g.InstructionInjectionConfig,
+		SpendCapDailyUSD:                g.SpendCapDailyUsd,
+		SpendCapMonthlyUSD:              g.SpendCapMonthlyUsd,
+		SpendCapTimezone:                g.SpendCapTimezone,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
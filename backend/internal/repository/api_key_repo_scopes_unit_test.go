@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepository_LegacyKeyWithoutScopesHasAllScopes(t *testing.T) {
+	repo, client := newAPIKeyRepoSQLite(t)
+	ctx := context.Background()
+	user := mustCreateAPIKeyRepoUser(t, ctx, client, "scopes-legacy@test.com")
+
+	// 模拟升级前创建的 Key：scopes 列为 NULL
+	_, err := client.APIKey.Create().
+		SetUserID(user.ID).
+		SetKey("sk-scopes-legacy").
+		SetName("Legacy").
+		SetStatus(service.StatusActive).
+		Save(ctx)
+	require.NoError(t, err)
+
+	got, err := repo.GetByKeyForAuth(ctx, "sk-scopes-legacy")
+	require.NoError(t, err)
+	require.Empty(t, got.Scopes)
+	require.Equal(t, service.AllAPIKeyScopes, got.EffectiveScopes())
+	require.True(t, got.HasScope(service.APIKeyScopeVideo))
+}
+
+func TestAPIKeyRepository_ScopesRoundTrip(t *testing.T) {
+	repo, client := newAPIKeyRepoSQLite(t)
+	ctx := context.Background()
+	user := mustCreateAPIKeyRepoUser(t, ctx, client, "scopes-roundtrip@test.com")
+
+	key := &service.APIKey{
+		UserID: user.ID,
+		Key:    "sk-scopes-roundtrip",
+		Name:   "Video only",
+		Status: service.StatusActive,
+		Scopes: []string{service.APIKeyScopeVideo},
+	}
+	require.NoError(t, repo.Create(ctx, key))
+
+	got, err := repo.GetByKeyForAuth(ctx, key.Key)
+	require.NoError(t, err)
+	require.Equal(t, []string{service.APIKeyScopeVideo}, got.Scopes)
+	require.False(t, got.HasScope(service.APIKeyScopeChat))
+
+	// 清空作用域后恢复为全部
+	key.Scopes = nil
+	require.NoError(t, repo.Update(ctx, key))
+	got, err = repo.GetByID(ctx, key.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.Scopes)
+	require.True(t, got.HasScope(service.APIKeyScopeChat))
+}
+
+func TestAppendUsageLogScopeWhereCondition(t *testing.T) {
+	conditions, args := appendUsageLogScopeWhereCondition([]string{"user_id = $1"}, []any{int64(7)}, service.APIKeyScopeImages)
+	require.Equal(t, []string{"user_id = $1", "inbound_endpoint = ANY($2)"}, conditions)
+	require.Equal(t, []any{int64(7), pq.Array([]string{"/v1/images/generations", "/v1/images/edits"})}, args)
+
+	conditions, args = appendUsageLogScopeWhereCondition(nil, nil, "")
+	require.Empty(t, conditions)
+	require.Empty(t, args)
+}
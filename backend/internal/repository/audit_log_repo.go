@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository 创建管理员操作审计日志仓储（原生 SQL）
+func NewAuditLogRepository(db *sql.DB) service.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+const insertAuditLogSQL = `
+INSERT INTO audit_logs (actor_user_id, action, entity_type, entity_id, diff, ip, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING id, created_at`
+
+func (r *auditLogRepository) Insert(ctx context.Context, entry *service.AuditLog) error {
+	diff, err := json.Marshal(entry.Diff)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx, insertAuditLogSQL,
+		entry.ActorUserID,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		diff,
+		entry.IP,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (r *auditLogRepository) List(ctx context.Context, filter service.AuditLogFilter) ([]service.AuditLog, *pagination.PaginationResult, error) {
+	where, args := buildAuditLogWhere(filter)
+	whereSQL := "WHERE " + strings.Join(where, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_logs "+whereSQL, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count audit logs: %w", err)
+	}
+
+	params := filter.Pagination
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 20
+	}
+	if params.PageSize > 100 {
+		params.PageSize = 100
+	}
+	queryArgs := append([]any{}, args...)
+	queryArgs = append(queryArgs, params.Limit(), params.Offset())
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, actor_user_id, action, entity_type, entity_id, diff, ip, created_at
+FROM audit_logs `+whereSQL+`
+ORDER BY created_at DESC, id DESC
+LIMIT $`+fmt.Sprint(len(queryArgs)-1)+` OFFSET $`+fmt.Sprint(len(queryArgs)),
+		queryArgs...,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list audit logs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]service.AuditLog, 0)
+	for rows.Next() {
+		var (
+			item service.AuditLog
+			diff []byte
+		)
+		if err := rows.Scan(&item.ID, &item.ActorUserID, &item.Action, &item.EntityType, &item.EntityID, &diff, &item.IP, &item.CreatedAt); err != nil {
+			return nil, nil, fmt.Errorf("scan audit log: %w", err)
+		}
+		item.Diff = map[string]service.AuditFieldChange{}
+		if len(diff) > 0 {
+			_ = json.Unmarshal(diff, &item.Diff)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate audit logs: %w", err)
+	}
+	return items, paginationResultFromTotal(total, params), nil
+}
+
+func (r *auditLogRepository) DeleteBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+DELETE FROM audit_logs
+WHERE id IN (
+  SELECT id FROM audit_logs WHERE created_at < $1 ORDER BY id LIMIT $2
+)`, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired audit logs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func buildAuditLogWhere(filter service.AuditLogFilter) ([]string, []any) {
+	where := []string{"1 = 1"}
+	args := make([]any, 0)
+	add := func(expr string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(expr, len(args)))
+	}
+	if entityType := strings.TrimSpace(filter.EntityType); entityType != "" {
+		add("entity_type = $%d", entityType)
+	}
+	if filter.EntityID != nil {
+		add("entity_id = $%d", *filter.EntityID)
+	}
+	if filter.From != nil && !filter.From.IsZero() {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil && !filter.To.IsZero() {
+		add("created_at <= $%d", *filter.To)
+	}
+	return where, args
+}
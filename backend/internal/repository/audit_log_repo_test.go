@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAuditLogWhere_Filters(t *testing.T) {
+	entityID := int64(7)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	where, args := buildAuditLogWhere(service.AuditLogFilter{
+		EntityType: " account ",
+		EntityID:   &entityID,
+		From:       &from,
+		To:         &to,
+	})
+
+	require.Equal(t, "1 = 1 AND entity_type = $1 AND entity_id = $2 AND created_at >= $3 AND created_at <= $4", strings.Join(where, " AND "))
+	require.Equal(t, []any{"account", entityID, from, to}, args)
+}
+
+func TestBuildAuditLogWhere_Empty(t *testing.T) {
+	where, args := buildAuditLogWhere(service.AuditLogFilter{})
+
+	require.Equal(t, []string{"1 = 1"}, where)
+	require.Empty(t, args)
+}
+
+func TestAuditLogRepositoryList_DecodesDiff(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewAuditLogRepository(db)
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM audit_logs WHERE 1 = 1 AND entity_type = $1")).
+		WithArgs("proxy").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("LIMIT $2 OFFSET $3")).
+		WithArgs("proxy", 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_user_id", "action", "entity_type", "entity_id", "diff", "ip", "created_at"}).
+			AddRow(int64(1), int64(2), "update", "proxy", int64(3), []byte(`{"port":{"before":80,"after":8080}}`), "127.0.0.1", now))
+
+	items, page, err := repo.List(context.Background(), service.AuditLogFilter{
+		Pagination: pagination.PaginationParams{Page: 1, PageSize: 20},
+		EntityType: "proxy",
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, int64(1), page.Total)
+	require.Equal(t, service.AuditFieldChange{Before: float64(80), After: float64(8080)}, items[0].Diff["port"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
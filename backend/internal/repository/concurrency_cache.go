@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/redis/go-redis/v9"
@@ -31,6 +33,8 @@ const (
 	waitQueueKeyPrefix = "concurrency:wait:"
 	// 账号级等待队列计数器格式: wait:account:{accountID}
 	accountWaitKeyPrefix = "wait:account:"
+	// 实例心跳键格式: concurrency:instance:{instanceID}（值无意义，TTL 即宽限期）
+	instanceHeartbeatKeyPrefix = "concurrency:instance:"
 
 	// 默认槽位过期时间（分钟），可通过配置覆盖
 	defaultSlotTTLMinutes = 15
@@ -174,26 +178,21 @@ var (
 		return 1
 	`)
 
-	// startupCleanupScript 清理非当前进程前缀的槽位成员。
-	// KEYS 是有序集合键列表，ARGV[1] 是当前进程前缀，ARGV[2] 是槽位 TTL。
-	// 遍历每个 KEYS[i]，移除前缀不匹配的成员，清空后删 key，否则刷新 EXPIRE。
-	startupCleanupScript = redis.NewScript(`
-		local activePrefix = ARGV[1]
-		local slotTTL = tonumber(ARGV[2])
+	// removeSlotMembersScript 从单个有序集合中移除指定成员（已确认属于失联实例），
+	// 清空后删 key，否则刷新 EXPIRE。单 key 操作，兼容 Redis Cluster。
+	// KEYS[1] = 有序集合键
+	// ARGV[1] = 槽位 TTL（秒），ARGV[2..n] = 待移除成员
+	removeSlotMembersScript = redis.NewScript(`
+		local key = KEYS[1]
+		local slotTTL = tonumber(ARGV[1])
 		local removed = 0
-		for i = 1, #KEYS do
-			local key = KEYS[i]
-			local members = redis.call('ZRANGE', key, 0, -1)
-			for _, member in ipairs(members) do
-				if string.sub(member, 1, string.len(activePrefix)) ~= activePrefix then
-					removed = removed + redis.call('ZREM', key, member)
-				end
-			end
-			if redis.call('ZCARD', key) == 0 then
-				redis.call('DEL', key)
-			else
-				redis.call('EXPIRE', key, slotTTL)
-			end
+		for i = 2, #ARGV do
+			removed = removed + redis.call('ZREM', key, ARGV[i])
+		end
+		if redis.call('ZCARD', key) == 0 then
+			redis.call('DEL', key)
+		else
+			redis.call('EXPIRE', key, slotTTL)
 		end
 		return removed
 	`)
@@ -503,20 +502,28 @@ func (c *concurrencyCache) CleanupExpiredAccountSlots(ctx context.Context, accou
 	return err
 }
 
+// CleanupStaleProcessSlots 启动时清理失联实例遗留的槽位与等待计数。
+// 槽位只移除心跳已过期实例的成员，其他存活实例的槽位保留；
+// 等待计数器无法归属到实例，仅在没有其他存活实例时整体删除（单实例重启场景），
+// 多实例部署下遗留的等待计数依赖自身 TTL 过期。
 func (c *concurrencyCache) CleanupStaleProcessSlots(ctx context.Context, activeRequestPrefix string) error {
 	if activeRequestPrefix == "" {
 		return nil
 	}
 
-	// 1. 清理有序集合中非当前进程前缀的成员
-	slotPatterns := []string{accountSlotKeyPrefix + "*", userSlotKeyPrefix + "*"}
-	for _, pattern := range slotPatterns {
-		if err := c.cleanupSlotsByPattern(ctx, pattern, activeRequestPrefix); err != nil {
-			return err
-		}
+	// 1. 清理失联实例遗留的槽位
+	if _, err := c.ReconcileStaleSlots(ctx, activeRequestPrefix); err != nil {
+		return err
 	}
 
-	// 2. 删除所有等待队列计数器（重启后计数器失效）
+	// 2. 没有其他存活实例时删除所有等待队列计数器（重启后计数器失效）
+	othersAlive, err := c.hasOtherLiveInstances(ctx, activeRequestPrefix)
+	if err != nil {
+		return err
+	}
+	if othersAlive {
+		return nil
+	}
 	waitPatterns := []string{accountWaitKeyPrefix + "*", waitQueueKeyPrefix + "*"}
 	for _, pattern := range waitPatterns {
 		if err := c.deleteKeysByPattern(ctx, pattern); err != nil {
@@ -527,19 +534,63 @@ func (c *concurrencyCache) CleanupStaleProcessSlots(ctx context.Context, activeR
 	return nil
 }
 
-// cleanupSlotsByPattern 扫描匹配 pattern 的有序集合键，批量调用 Lua 脚本清理非当前进程成员。
-func (c *concurrencyCache) cleanupSlotsByPattern(ctx context.Context, pattern, activePrefix string) error {
+func instanceHeartbeatKey(instanceID string) string {
+	return instanceHeartbeatKeyPrefix + instanceID
+}
+
+// slotOwner 从槽位成员（requestID，格式 {instanceID}-{seq}）解析所属实例 ID
+func slotOwner(member string) string {
+	if i := strings.LastIndexByte(member, '-'); i > 0 {
+		return member[:i]
+	}
+	return member
+}
+
+// HeartbeatInstance 刷新实例心跳，ttl 即宽限期：超过 ttl 未刷新的实例视为已失联。
+func (c *concurrencyCache) HeartbeatInstance(ctx context.Context, instanceID string, ttl time.Duration) error {
+	if instanceID == "" || ttl <= 0 {
+		return nil
+	}
+	return c.rdb.Set(ctx, instanceHeartbeatKey(instanceID), time.Now().Unix(), ttl).Err()
+}
+
+// ReconcileStaleSlots 扫描账号/用户槽位，移除心跳已过期实例持有的成员，返回移除数量。
+// 当前实例（activeRequestPrefix 前缀）及心跳存活实例的槽位不会被移除。
+func (c *concurrencyCache) ReconcileStaleSlots(ctx context.Context, activeRequestPrefix string) (int, error) {
+	if activeRequestPrefix == "" {
+		return 0, nil
+	}
+	// 同一轮内缓存实例存活状态；实例 ID 每次启动随机生成，失联实例不会以同一 ID 恢复
+	liveness := make(map[string]bool)
+	total := 0
+	slotPatterns := []string{accountSlotKeyPrefix + "*", userSlotKeyPrefix + "*"}
+	for _, pattern := range slotPatterns {
+		removed, err := c.reconcileSlotsByPattern(ctx, pattern, activeRequestPrefix, liveness)
+		total += removed
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// reconcileSlotsByPattern 扫描匹配 pattern 的有序集合键，移除失联实例的成员。
+func (c *concurrencyCache) reconcileSlotsByPattern(ctx context.Context, pattern, activePrefix string, liveness map[string]bool) (int, error) {
 	const scanCount = 200
-	var cursor uint64
+	var (
+		cursor uint64
+		total  int
+	)
 	for {
 		keys, nextCursor, err := c.rdb.Scan(ctx, cursor, pattern, scanCount).Result()
 		if err != nil {
-			return fmt.Errorf("scan %s: %w", pattern, err)
+			return total, fmt.Errorf("scan %s: %w", pattern, err)
 		}
 		if len(keys) > 0 {
-			_, err := startupCleanupScript.Run(ctx, c.rdb, keys, activePrefix, c.slotTTLSeconds).Result()
+			removed, err := c.reconcileSlotKeys(ctx, keys, activePrefix, liveness)
+			total += removed
 			if err != nil {
-				return fmt.Errorf("cleanup slots %s: %w", pattern, err)
+				return total, fmt.Errorf("reconcile slots %s: %w", pattern, err)
 			}
 		}
 		cursor = nextCursor
@@ -547,7 +598,93 @@ func (c *concurrencyCache) cleanupSlotsByPattern(ctx context.Context, pattern, a
 			break
 		}
 	}
-	return nil
+	return total, nil
+}
+
+func (c *concurrencyCache) reconcileSlotKeys(ctx context.Context, keys []string, activePrefix string, liveness map[string]bool) (int, error) {
+	pipe := c.rdb.Pipeline()
+	rangeCmds := make([]*redis.StringSliceCmd, len(keys))
+	for i, key := range keys {
+		rangeCmds[i] = pipe.ZRange(ctx, key, 0, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	// 批量查询本批次中尚未确认的实例心跳
+	unknown := make([]string, 0)
+	for _, cmd := range rangeCmds {
+		for _, member := range cmd.Val() {
+			if strings.HasPrefix(member, activePrefix) {
+				continue
+			}
+			owner := slotOwner(member)
+			if _, ok := liveness[owner]; !ok {
+				liveness[owner] = true
+				unknown = append(unknown, owner)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		pipe = c.rdb.Pipeline()
+		existsCmds := make([]*redis.IntCmd, len(unknown))
+		for i, owner := range unknown {
+			existsCmds[i] = pipe.Exists(ctx, instanceHeartbeatKey(owner))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			// 查询失败时宁可保留，避免误删存活实例的槽位
+			for _, owner := range unknown {
+				delete(liveness, owner)
+			}
+			return 0, err
+		}
+		for i, owner := range unknown {
+			liveness[owner] = existsCmds[i].Val() > 0
+		}
+	}
+
+	total := 0
+	for i, key := range keys {
+		args := []any{c.slotTTLSeconds}
+		for _, member := range rangeCmds[i].Val() {
+			if strings.HasPrefix(member, activePrefix) || liveness[slotOwner(member)] {
+				continue
+			}
+			args = append(args, member)
+		}
+		if len(args) == 1 {
+			continue
+		}
+		removed, err := removeSlotMembersScript.Run(ctx, c.rdb, []string{key}, args...).Int()
+		if err != nil {
+			return total, err
+		}
+		total += removed
+	}
+	return total, nil
+}
+
+// hasOtherLiveInstances 是否存在除当前实例外心跳未过期的实例
+func (c *concurrencyCache) hasOtherLiveInstances(ctx context.Context, activeRequestPrefix string) (bool, error) {
+	const scanCount = 200
+	self := instanceHeartbeatKey(activeRequestPrefix)
+	pattern := instanceHeartbeatKeyPrefix + "*"
+	var cursor uint64
+	for {
+		keys, nextCursor, err := c.rdb.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return false, fmt.Errorf("scan %s: %w", pattern, err)
+		}
+		for _, key := range keys {
+			if key != self {
+				return true, nil
+			}
+		}
+		cursor = nextCursor
+		if cursor == 0 {
+			return false, nil
+		}
+	}
 }
 
 // deleteKeysByPattern 扫描匹配 pattern 的键并删除。
@@ -0,0 +1,128 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newReconcileTestCache(t *testing.T) (*concurrencyCache, *redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewConcurrencyCache(rdb, defaultSlotTTLMinutes, 0).(*concurrencyCache)
+	return cache, rdb, mr
+}
+
+func TestReconcileStaleSlots_ReclaimsDeadInstanceKeepsLive(t *testing.T) {
+	cache, rdb, _ := newReconcileTestCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.HeartbeatInstance(ctx, "rself", time.Minute))
+	require.NoError(t, cache.HeartbeatInstance(ctx, "rlive", time.Minute))
+
+	// 死亡实例 rdead 崩溃前持有的槽位（没有心跳），存活实例 rlive 与当前实例的槽位正常持有
+	for _, member := range []string{"rdead-1", "rdead-2", "rlive-1", "rself-1"} {
+		ok, err := cache.AcquireAccountSlot(ctx, 7, 10, member)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	for _, member := range []string{"rdead-3", "rlive-2"} {
+		ok, err := cache.AcquireUserSlot(ctx, 9, 10, member)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	ok, err := cache.AcquireUserSlot(ctx, 10, 10, "rdead-4")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	removed, err := cache.ReconcileStaleSlots(ctx, "rself")
+	require.NoError(t, err)
+	require.Equal(t, 4, removed)
+
+	accountMembers, err := rdb.ZRange(ctx, accountSlotKey(7), 0, -1).Result()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"rlive-1", "rself-1"}, accountMembers)
+
+	userMembers, err := rdb.ZRange(ctx, userSlotKey(9), 0, -1).Result()
+	require.NoError(t, err)
+	require.Equal(t, []string{"rlive-2"}, userMembers)
+
+	exists, err := rdb.Exists(ctx, userSlotKey(10)).Result()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, exists, "slot key emptied by reclaim should be deleted")
+
+	// 回收后并发数恢复，可以继续获取槽位
+	cur, err := cache.GetAccountConcurrency(ctx, 7)
+	require.NoError(t, err)
+	require.Equal(t, 2, cur)
+}
+
+func TestReconcileStaleSlots_ReclaimsAfterHeartbeatGraceExpires(t *testing.T) {
+	cache, rdb, mr := newReconcileTestCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.HeartbeatInstance(ctx, "rcrashed", 10*time.Second))
+	ok, err := cache.AcquireAccountSlot(ctx, 3, 1, "rcrashed-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	removed, err := cache.ReconcileStaleSlots(ctx, "rself")
+	require.NoError(t, err)
+	require.Zero(t, removed, "slots must survive while the owner heartbeat is within grace")
+
+	mr.FastForward(11 * time.Second)
+
+	removed, err = cache.ReconcileStaleSlots(ctx, "rself")
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	exists, err := rdb.Exists(ctx, accountSlotKey(3)).Result()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, exists)
+}
+
+func TestCleanupStaleProcessSlots_KeepsWaitCountersWhileOtherInstancesAlive(t *testing.T) {
+	cache, rdb, _ := newReconcileTestCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.HeartbeatInstance(ctx, "rself", time.Minute))
+	require.NoError(t, cache.HeartbeatInstance(ctx, "rlive", time.Minute))
+	require.NoError(t, rdb.Set(ctx, waitQueueKey(1), 2, time.Minute).Err())
+	require.NoError(t, rdb.Set(ctx, accountWaitKey(2), 1, time.Minute).Err())
+	ok, err := cache.AcquireAccountSlot(ctx, 2, 5, "rlive-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, cache.CleanupStaleProcessSlots(ctx, "rself"))
+
+	val, err := rdb.Get(ctx, waitQueueKey(1)).Int()
+	require.NoError(t, err)
+	require.Equal(t, 2, val)
+	val, err = rdb.Get(ctx, accountWaitKey(2)).Int()
+	require.NoError(t, err)
+	require.Equal(t, 1, val)
+	cur, err := cache.GetAccountConcurrency(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, 1, cur)
+
+	// 其他实例心跳过期后，单实例重启场景下等待计数整体重置
+	require.NoError(t, rdb.Del(ctx, instanceHeartbeatKey("rlive")).Err())
+	require.NoError(t, cache.CleanupStaleProcessSlots(ctx, "rself"))
+
+	exists, err := rdb.Exists(ctx, waitQueueKey(1), accountWaitKey(2), accountSlotKey(2)).Result()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, exists)
+}
+
+func TestSlotOwner(t *testing.T) {
+	require.Equal(t, "rabc", slotOwner("rabc-1z"))
+	require.Equal(t, "oldproc", slotOwner("oldproc-1"))
+	require.Equal(t, "legacy", slotOwner("legacy"))
+}
@@ -75,6 +75,12 @@ func (r *errorPassthroughRepository) Create(ctx context.Context, rule *model.Err
 	if rule.Description != nil {
 		builder.SetDescription(*rule.Description)
 	}
+	if rule.HasBodyRegex() {
+		builder.SetBodyRegex(*rule.BodyRegex)
+	}
+	if len(rule.HeaderMatchers) > 0 {
+		builder.SetHeaderMatchers(headerMatchersToEnt(rule.HeaderMatchers))
+	}
 
 	created, err := builder.Save(ctx)
 	if err != nil {
@@ -125,6 +131,16 @@ func (r *errorPassthroughRepository) Update(ctx context.Context, rule *model.Err
 	} else {
 		builder.ClearDescription()
 	}
+	if rule.HasBodyRegex() {
+		builder.SetBodyRegex(*rule.BodyRegex)
+	} else {
+		builder.ClearBodyRegex()
+	}
+	if len(rule.HeaderMatchers) > 0 {
+		builder.SetHeaderMatchers(headerMatchersToEnt(rule.HeaderMatchers))
+	} else {
+		builder.ClearHeaderMatchers()
+	}
 
 	updated, err := builder.Save(ctx)
 	if err != nil {
@@ -165,6 +181,10 @@ func (r *errorPassthroughRepository) toModel(e *ent.ErrorPassthroughRule) *model
 	if e.Description != nil {
 		rule.Description = e.Description
 	}
+	if e.BodyRegex != nil {
+		rule.BodyRegex = e.BodyRegex
+	}
+	rule.HeaderMatchers = headerMatchersFromEnt(e.HeaderMatchers)
 
 	// 确保切片不为 nil
 	if rule.ErrorCodes == nil {
@@ -179,3 +199,21 @@ func (r *errorPassthroughRepository) toModel(e *ent.ErrorPassthroughRule) *model
 
 	return rule
 }
+
+// headerMatchersToEnt 将响应头匹配条件转换为 JSON 存储结构
+func headerMatchersToEnt(matchers []model.ErrorPassthroughHeaderMatcher) []map[string]string {
+	out := make([]map[string]string, 0, len(matchers))
+	for _, m := range matchers {
+		out = append(out, map[string]string{"name": m.Name, "regex": m.Regex})
+	}
+	return out
+}
+
+// headerMatchersFromEnt 将 JSON 存储结构转换为响应头匹配条件（始终返回非 nil 切片）
+func headerMatchersFromEnt(raw []map[string]string) []model.ErrorPassthroughHeaderMatcher {
+	out := make([]model.ErrorPassthroughHeaderMatcher, 0, len(raw))
+	for _, m := range raw {
+		out = append(out, model.ErrorPassthroughHeaderMatcher{Name: m["name"], Regex: m["regex"]})
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const gatewayIdempotencyKeyPrefix = "gateway_idem:"
+
+type gatewayIdempotencyCache struct {
+	rdb *redis.Client
+}
+
+func NewGatewayIdempotencyCache(rdb *redis.Client) service.GatewayIdempotencyCache {
+	return &gatewayIdempotencyCache{rdb: rdb}
+}
+
+func (c *gatewayIdempotencyCache) CreateIdempotencyRecord(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, gatewayIdempotencyKeyPrefix+key, value, ttl).Result()
+}
+
+func (c *gatewayIdempotencyCache) GetIdempotencyRecord(ctx context.Context, key string) ([]byte, error) {
+	val, err := c.rdb.Get(ctx, gatewayIdempotencyKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func (c *gatewayIdempotencyCache) SetIdempotencyRecord(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.rdb.Set(ctx, gatewayIdempotencyKeyPrefix+key, value, ttl).Err()
+}
+
+func (c *gatewayIdempotencyCache) DeleteIdempotencyRecord(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, gatewayIdempotencyKeyPrefix+key).Err()
+}
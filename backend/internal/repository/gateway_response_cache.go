@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const gatewayResponseCacheKeyPrefix = "gateway_resp_cache:"
+
+type gatewayResponseCache struct {
+	rdb *redis.Client
+}
+
+func NewGatewayResponseCache(rdb *redis.Client) service.GatewayResponseCache {
+	return &gatewayResponseCache{rdb: rdb}
+}
+
+func (c *gatewayResponseCache) GetCachedResponse(ctx context.Context, key string) ([]byte, error) {
+	val, err := c.rdb.Get(ctx, gatewayResponseCacheKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func (c *gatewayResponseCache) SetCachedResponse(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.rdb.Set(ctx, gatewayResponseCacheKeyPrefix+key, value, ttl).Err()
+}
@@ -67,7 +67,13 @@ func (r *groupRepository) Create(ctx context.Context, groupIn *service.Group) er
 		SetDefaultMappedModel(groupIn.DefaultMappedModel).
 		SetMessagesDispatchModelConfig(groupIn.MessagesDispatchModelConfig).
 		SetModelsListConfig(groupIn.ModelsListConfig).
-		SetRpmLimit(groupIn.RPMLimit)
+		SetRpmLimit(groupIn.RPMLimit).
+		SetPromptCacheInject(groupIn.PromptCacheInject).
+		SetModelFallbackConfig(groupIn.ModelFallbackConfig).
+		SetInstructionInjectionConfig(groupIn.InstructionInjectionConfig).
+		SetNillableSpendCapDailyUsd(groupIn.SpendCapDailyUSD).
+		SetNillableSpendCapMonthlyUsd(groupIn.SpendCapMonthlyUSD).
+		SetSpendCapTimezone(groupIn.SpendCapTimezone)
 
 	// 设置模型路由配置
 	if groupIn.ModelRouting != nil {
@@ -143,9 +149,23 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetDefaultMappedModel(groupIn.DefaultMappedModel).
 		SetMessagesDispatchModelConfig(groupIn.MessagesDispatchModelConfig).
 		SetModelsListConfig(groupIn.ModelsListConfig).
-		SetRpmLimit(groupIn.RPMLimit)
+		SetRpmLimit(groupIn.RPMLimit).
+		SetPromptCacheInject(groupIn.PromptCacheInject).
+		SetModelFallbackConfig(groupIn.ModelFallbackConfig).
+		SetInstructionInjectionConfig(groupIn.InstructionInjectionConfig).
+		SetSpendCapTimezone(groupIn.SpendCapTimezone)
 
 	// 显式处理可空字段：nil 需要 clear，非 nil 需要 set。
+	if groupIn.SpendCapDailyUSD != nil {
+		builder = builder.SetSpendCapDailyUsd(*groupIn.SpendCapDailyUSD)
+	} else {
+		builder = builder.ClearSpendCapDailyUsd()
+	}
+	if groupIn.SpendCapMonthlyUSD != nil {
+		builder = builder.SetSpendCapMonthlyUsd(*groupIn.SpendCapMonthlyUSD)
+	} else {
+		builder = builder.ClearSpendCapMonthlyUsd()
+	}
 	if groupIn.DailyLimitUSD != nil {
 		builder = builder.SetDailyLimitUsd(*groupIn.DailyLimitUSD)
 	} else {
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// groupSpendKeyPrefix 分组消费计数：group_spend:{groupID}:{period}，period 为 2026-10-17 或 2026-10
+	groupSpendKeyPrefix = "group_spend:"
+	// groupSpendOverrideKeyPrefix 分组消费上限临时放行：group_spend_override:{groupID}，TTL 对齐到期时间
+	groupSpendOverrideKeyPrefix = "group_spend_override:"
+
+	// 计数 key 的保留时长需覆盖整个周期并留出时区偏移余量，过期后自然清理
+	groupSpendDailyTTL   = 48 * time.Hour
+	groupSpendMonthlyTTL = 32 * 24 * time.Hour
+)
+
+type groupSpendCapCache struct {
+	rdb *redis.Client
+}
+
+func NewGroupSpendCapCache(rdb *redis.Client) service.GroupSpendCapCache {
+	return &groupSpendCapCache{rdb: rdb}
+}
+
+func groupSpendKey(groupID int64, period string) string {
+	return fmt.Sprintf("%s%d:%s", groupSpendKeyPrefix, groupID, period)
+}
+
+func groupSpendOverrideKey(groupID int64) string {
+	return fmt.Sprintf("%s%d", groupSpendOverrideKeyPrefix, groupID)
+}
+
+func (c *groupSpendCapCache) IncrGroupSpend(ctx context.Context, groupID int64, dayPeriod, monthPeriod string, cost float64) (float64, float64, error) {
+	dayKey := groupSpendKey(groupID, dayPeriod)
+	monthKey := groupSpendKey(groupID, monthPeriod)
+	pipe := c.rdb.TxPipeline()
+	daily := pipe.IncrByFloat(ctx, dayKey, cost)
+	pipe.Expire(ctx, dayKey, groupSpendDailyTTL)
+	monthly := pipe.IncrByFloat(ctx, monthKey, cost)
+	pipe.Expire(ctx, monthKey, groupSpendMonthlyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	return daily.Val(), monthly.Val(), nil
+}
+
+func (c *groupSpendCapCache) GetGroupSpend(ctx context.Context, groupID int64, dayPeriod, monthPeriod string) (float64, float64, error) {
+	vals, err := c.rdb.MGet(ctx, groupSpendKey(groupID, dayPeriod), groupSpendKey(groupID, monthPeriod)).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	parse := func(v any) (float64, error) {
+		s, ok := v.(string)
+		if !ok {
+			return 0, nil
+		}
+		return strconv.ParseFloat(s, 64)
+	}
+	daily, err := parse(vals[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	monthly, err := parse(vals[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return daily, monthly, nil
+}
+
+func (c *groupSpendCapCache) GetGroupSpendCapOverride(ctx context.Context, groupID int64) (*service.GroupSpendCapOverride, error) {
+	raw, err := c.rdb.Get(ctx, groupSpendOverrideKey(groupID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var override service.GroupSpendCapOverride
+	if err := json.Unmarshal(raw, &override); err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (c *groupSpendCapCache) SetGroupSpendCapOverride(ctx context.Context, groupID int64, override *service.GroupSpendCapOverride) error {
+	ttl := time.Until(override.ExpiresAt)
+	if ttl <= 0 {
+		return c.DeleteGroupSpendCapOverride(ctx, groupID)
+	}
+	raw, err := json.Marshal(override)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, groupSpendOverrideKey(groupID), raw, ttl).Err()
+}
+
+func (c *groupSpendCapCache) DeleteGroupSpendCapOverride(ctx context.Context, groupID int64) error {
+	return c.rdb.Del(ctx, groupSpendOverrideKey(groupID)).Err()
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// logComponentLevelKey 运行时组件日志级别覆盖：Hash，field 为组件名，value 为 JSON
+const logComponentLevelKey = "log_component_levels"
+
+type logComponentLevelCache struct {
+	rdb *redis.Client
+}
+
+func NewLogComponentLevelCache(rdb *redis.Client) service.LogComponentLevelStore {
+	return &logComponentLevelCache{rdb: rdb}
+}
+
+func (c *logComponentLevelCache) SaveLogComponentOverride(ctx context.Context, override service.LogComponentOverride) error {
+	raw, err := json.Marshal(override)
+	if err != nil {
+		return err
+	}
+	return c.rdb.HSet(ctx, logComponentLevelKey, override.Component, raw).Err()
+}
+
+func (c *logComponentLevelCache) ListLogComponentOverrides(ctx context.Context) ([]service.LogComponentOverride, error) {
+	entries, err := c.rdb.HGetAll(ctx, logComponentLevelKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	overrides := make([]service.LogComponentOverride, 0, len(entries))
+	for component, raw := range entries {
+		var override service.LogComponentOverride
+		if err := json.Unmarshal([]byte(raw), &override); err != nil || override.Component != component {
+			continue
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+func (c *logComponentLevelCache) DeleteLogComponentOverride(ctx context.Context, component string) error {
+	return c.rdb.HDel(ctx, logComponentLevelKey, component).Err()
+}
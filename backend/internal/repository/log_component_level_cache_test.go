@@ -0,0 +1,42 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogComponentLevelCache_SaveListDelete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewLogComponentLevelCache(rdb)
+	ctx := context.Background()
+
+	revertAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, cache.SaveLogComponentOverride(ctx, service.LogComponentOverride{
+		Component: "identity",
+		Level:     "debug",
+		RevertAt:  &revertAt,
+		UpdatedAt: time.Now().UTC(),
+	}))
+	mr.HSet(logComponentLevelKey, "gateway.gemini", "not-json")
+
+	overrides, err := cache.ListLogComponentOverrides(ctx)
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	require.Equal(t, "identity", overrides[0].Component)
+	require.Equal(t, revertAt, overrides[0].RevertAt.UTC())
+
+	require.NoError(t, cache.DeleteLogComponentOverride(ctx, "identity"))
+	overrides, err = cache.ListLogComponentOverrides(ctx)
+	require.NoError(t, err)
+	require.Empty(t, overrides)
+}
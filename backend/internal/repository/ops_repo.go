@@ -59,9 +59,14 @@ INSERT INTO ops_error_logs (
   attempted_key_prefix,
   deleted_key_owner_user_id,
   deleted_key_name,
-  api_key_prefix
+  api_key_prefix,
+  instructions_injected,
+  user_slot_wait_ms,
+  account_slot_wait_ms,
+  slot_acquire_path,
+  trace_id
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43,$44,$45,$46
 )`
 
 func NewOpsRepository(db *sql.DB) service.OpsRepository {
@@ -174,6 +179,11 @@ func opsInsertErrorLogArgs(input *service.OpsInsertErrorLogInput) []any {
 		opsNullInt64(input.DeletedKeyOwnerUserID),
 		opsNullString(input.DeletedKeyName),
 		opsNullString(input.APIKeyPrefix),
+		input.InstructionsInjected,
+		opsNullInt64(input.UserSlotWaitMs),
+		opsNullInt64(input.AccountSlotWaitMs),
+		opsNullString(input.SlotAcquirePath),
+		opsNullString(input.TraceID),
 	}
 }
 
@@ -225,6 +235,7 @@ SELECT
   COALESCE(u2.email, ''),
   COALESCE(e.client_request_id, ''),
   COALESCE(e.request_id, ''),
+  COALESCE(e.trace_id, ''),
   COALESCE(e.error_message, ''),
   e.user_id,
   COALESCE(u.email, ''),
@@ -296,6 +307,7 @@ LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
 			&resolvedByName,
 			&item.ClientRequestID,
 			&item.RequestID,
+			&item.TraceID,
 			&item.Message,
 			&userID,
 			&userEmail,
@@ -403,6 +415,7 @@ SELECT
   e.resolved_by_user_id,
   COALESCE(e.client_request_id, ''),
   COALESCE(e.request_id, ''),
+  COALESCE(e.trace_id, ''),
   COALESCE(e.error_message, ''),
   COALESCE(e.error_body, ''),
   e.upstream_status_code,
@@ -436,6 +449,10 @@ SELECT
   COALESCE(du.email, ''),
   COALESCE(e.deleted_key_name, ''),
   COALESCE(e.api_key_prefix, ''),
+  COALESCE(e.instructions_injected, false),
+  e.user_slot_wait_ms,
+  e.account_slot_wait_ms,
+  COALESCE(e.slot_acquire_path, ''),
   COALESCE(ak.name, ''),
   ak.deleted_at
 FROM ops_error_logs e
@@ -462,6 +479,8 @@ LIMIT 1`
 	var upstreamLatency sql.NullInt64
 	var responseLatency sql.NullInt64
 	var ttft sql.NullInt64
+	var userSlotWait sql.NullInt64
+	var accountSlotWait sql.NullInt64
 	var requestType sql.NullInt64
 	var deletedKeyOwnerUserID sql.NullInt64
 	var detailAPIKeyName string
@@ -483,6 +502,7 @@ LIMIT 1`
 		&resolvedBy,
 		&out.ClientRequestID,
 		&out.RequestID,
+		&out.TraceID,
 		&out.Message,
 		&out.ErrorBody,
 		&upstreamStatusCode,
@@ -516,6 +536,10 @@ LIMIT 1`
 		&out.DeletedKeyOwnerEmail,
 		&out.DeletedKeyName,
 		&out.APIKeyPrefix,
+		&out.InstructionsInjected,
+		&userSlotWait,
+		&accountSlotWait,
+		&out.SlotAcquirePath,
 		&detailAPIKeyName,
 		&detailAPIKeyDeletedAt,
 	)
@@ -576,6 +600,14 @@ LIMIT 1`
 		v := ttft.Int64
 		out.TimeToFirstTokenMs = &v
 	}
+	if userSlotWait.Valid {
+		v := userSlotWait.Int64
+		out.UserSlotWaitMs = &v
+	}
+	if accountSlotWait.Valid {
+		v := accountSlotWait.Int64
+		out.AccountSlotWaitMs = &v
+	}
 	if requestType.Valid {
 		v := int16(requestType.Int64)
 		out.RequestType = &v
@@ -1012,12 +1044,16 @@ func buildOpsErrorLogsWhere(filter *service.OpsErrorLogFilter) (string, []any) {
 		args = append(args, crid)
 		clauses = append(clauses, "COALESCE(e.client_request_id,'') = $"+itoa(len(args)))
 	}
+	if tid := strings.TrimSpace(filter.TraceID); tid != "" {
+		args = append(args, tid)
+		clauses = append(clauses, "e.trace_id = $"+itoa(len(args)))
+	}
 
 	if q := strings.TrimSpace(filter.Query); q != "" {
 		like := "%" + q + "%"
 		args = append(args, like)
 		n := itoa(len(args))
-		clauses = append(clauses, "(e.request_id ILIKE $"+n+" OR e.client_request_id ILIKE $"+n+" OR e.error_message ILIKE $"+n+")")
+		clauses = append(clauses, "(e.request_id ILIKE $"+n+" OR e.client_request_id ILIKE $"+n+" OR e.trace_id ILIKE $"+n+" OR e.error_message ILIKE $"+n+")")
 	}
 
 	if userQuery := strings.TrimSpace(filter.UserQuery); userQuery != "" {
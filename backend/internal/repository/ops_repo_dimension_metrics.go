@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// opsDimensionRollupUpsertBatch 单条 INSERT 的最大 rollup 行数（每行 17 个参数）。
+const opsDimensionRollupUpsertBatch = 500
+
+// ListDimensionSamples 读取 [start,end) 内的逐请求原始记录：
+//   - usage_logs：成功请求（延迟 / TTFT / token）；
+//   - ops_error_logs：status_code>=400 计为失败请求；其余行仅在包含 failover 事件时返回（FailoverOnly）。
+func (r *opsRepository) ListDimensionSamples(ctx context.Context, startTime, endTime time.Time) ([]*service.OpsDimensionSample, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if startTime.IsZero() || endTime.IsZero() || !endTime.After(startTime) {
+		return nil, nil
+	}
+
+	q := `
+SELECT
+  ul.created_at,
+  COALESCE(ul.model, '') AS model,
+  ul.account_id,
+  ul.group_id,
+  ul.duration_ms::bigint,
+  ul.first_token_ms::bigint,
+  (ul.input_tokens + ul.output_tokens + ul.cache_creation_tokens + ul.cache_read_tokens)::bigint AS total_tokens,
+  ul.output_tokens::bigint,
+  FALSE AS from_error_log,
+  FALSE AS is_error,
+  0::bigint AS failover_count
+FROM usage_logs ul
+WHERE ul.created_at >= $1 AND ul.created_at < $2
+UNION ALL
+SELECT * FROM (
+  SELECT
+    e.created_at,
+    COALESCE(e.model, '') AS model,
+    e.account_id,
+    e.group_id,
+    NULL::bigint,
+    NULL::bigint,
+    0::bigint,
+    0::bigint,
+    TRUE,
+    COALESCE(e.status_code, 0) >= 400 AS is_error,
+    (
+      SELECT COUNT(*)
+      FROM jsonb_array_elements(COALESCE(NULLIF(e.upstream_errors, 'null'::jsonb), '[]'::jsonb)) AS ev
+      WHERE split_part(ev->>'kind', ':', 1) IN ('failover', 'retry_exhausted_failover', 'failover_on_400')
+    )::bigint AS failover_count
+  FROM ops_error_logs e
+  WHERE e.created_at >= $1 AND e.created_at < $2
+    AND e.is_count_tokens = FALSE
+) err
+WHERE err.is_error OR err.failover_count > 0`
+
+	rows, err := r.db.QueryContext(ctx, q, startTime.UTC(), endTime.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	toInt64Ptr := func(v sql.NullInt64) *int64 {
+		if !v.Valid {
+			return nil
+		}
+		i := v.Int64
+		return &i
+	}
+
+	out := make([]*service.OpsDimensionSample, 0, 256)
+	for rows.Next() {
+		var (
+			sample       service.OpsDimensionSample
+			accountID    sql.NullInt64
+			groupID      sql.NullInt64
+			durationMs   sql.NullInt64
+			firstTokenMs sql.NullInt64
+			fromErrorLog bool
+		)
+		if err := rows.Scan(
+			&sample.CreatedAt,
+			&sample.Model,
+			&accountID,
+			&groupID,
+			&durationMs,
+			&firstTokenMs,
+			&sample.TotalTokens,
+			&sample.OutputTokens,
+			&fromErrorLog,
+			&sample.IsError,
+			&sample.FailoverCount,
+		); err != nil {
+			return nil, err
+		}
+		sample.CreatedAt = sample.CreatedAt.UTC()
+		sample.AccountID = toInt64Ptr(accountID)
+		sample.GroupID = toInt64Ptr(groupID)
+		sample.DurationMs = toInt64Ptr(durationMs)
+		sample.FirstTokenMs = toInt64Ptr(firstTokenMs)
+		sample.FailoverOnly = fromErrorLog && !sample.IsError
+		out = append(out, &sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *opsRepository) UpsertDimensionRollups(ctx context.Context, rollups []*service.OpsDimensionRollup) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil ops repository")
+	}
+	for start := 0; start < len(rollups); start += opsDimensionRollupUpsertBatch {
+		end := min(start+opsDimensionRollupUpsertBatch, len(rollups))
+		if err := r.upsertDimensionRollupBatch(ctx, rollups[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *opsRepository) upsertDimensionRollupBatch(ctx context.Context, rollups []*service.OpsDimensionRollup) error {
+	const cols = 17
+	values := make([]string, 0, len(rollups))
+	args := make([]any, 0, len(rollups)*cols)
+	for _, item := range rollups {
+		if item == nil {
+			continue
+		}
+		placeholders := make([]string, cols)
+		for i := range placeholders {
+			placeholders[i] = fmt.Sprintf("$%d", len(args)+i+1)
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args,
+			item.BucketStart.UTC(),
+			item.Dimension,
+			item.DimensionKey,
+			item.RequestCount,
+			item.ErrorCount,
+			item.FailoverCount,
+			item.TotalTokens,
+			item.OutputTokens,
+			item.LatencyCount,
+			item.LatencySumMs,
+			item.LatencyMaxMs,
+			pq.Array(item.LatencyHistogram),
+			item.TTFTCount,
+			item.TTFTSumMs,
+			item.TTFTMaxMs,
+			pq.Array(item.TTFTHistogram),
+			time.Now().UTC(),
+		)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	q := `
+INSERT INTO ops_dimension_metrics_5m (
+  bucket_start, dimension, dimension_key,
+  request_count, error_count, failover_count,
+  total_tokens, output_tokens,
+  latency_count, latency_sum_ms, latency_max_ms, latency_histogram,
+  ttft_count, ttft_sum_ms, ttft_max_ms, ttft_histogram,
+  computed_at
+) VALUES ` + strings.Join(values, ",\n") + `
+ON CONFLICT (bucket_start, dimension, dimension_key) DO UPDATE SET
+  request_count = EXCLUDED.request_count,
+  error_count = EXCLUDED.error_count,
+  failover_count = EXCLUDED.failover_count,
+  total_tokens = EXCLUDED.total_tokens,
+  output_tokens = EXCLUDED.output_tokens,
+  latency_count = EXCLUDED.latency_count,
+  latency_sum_ms = EXCLUDED.latency_sum_ms,
+  latency_max_ms = EXCLUDED.latency_max_ms,
+  latency_histogram = EXCLUDED.latency_histogram,
+  ttft_count = EXCLUDED.ttft_count,
+  ttft_sum_ms = EXCLUDED.ttft_sum_ms,
+  ttft_max_ms = EXCLUDED.ttft_max_ms,
+  ttft_histogram = EXCLUDED.ttft_histogram,
+  computed_at = EXCLUDED.computed_at`
+
+	_, err := r.db.ExecContext(ctx, q, args...)
+	return err
+}
+
+func (r *opsRepository) ListDimensionRollups(ctx context.Context, dimension string, startTime, endTime time.Time) ([]*service.OpsDimensionRollup, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if startTime.IsZero() || endTime.IsZero() || !endTime.After(startTime) {
+		return nil, nil
+	}
+
+	q := `
+SELECT
+  bucket_start, dimension, dimension_key,
+  request_count, error_count, failover_count,
+  total_tokens, output_tokens,
+  latency_count, latency_sum_ms, latency_max_ms, latency_histogram,
+  ttft_count, ttft_sum_ms, ttft_max_ms, ttft_histogram
+FROM ops_dimension_metrics_5m
+WHERE dimension = $1 AND bucket_start >= $2 AND bucket_start < $3
+ORDER BY bucket_start ASC`
+
+	rows, err := r.db.QueryContext(ctx, q, dimension, startTime.UTC(), endTime.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.OpsDimensionRollup, 0, 256)
+	for rows.Next() {
+		var (
+			item        service.OpsDimensionRollup
+			latencyHist pq.Int64Array
+			ttftHist    pq.Int64Array
+		)
+		if err := rows.Scan(
+			&item.BucketStart,
+			&item.Dimension,
+			&item.DimensionKey,
+			&item.RequestCount,
+			&item.ErrorCount,
+			&item.FailoverCount,
+			&item.TotalTokens,
+			&item.OutputTokens,
+			&item.LatencyCount,
+			&item.LatencySumMs,
+			&item.LatencyMaxMs,
+			&latencyHist,
+			&item.TTFTCount,
+			&item.TTFTSumMs,
+			&item.TTFTMaxMs,
+			&ttftHist,
+		); err != nil {
+			return nil, err
+		}
+		item.BucketStart = item.BucketStart.UTC()
+		item.LatencyHistogram = []int64(latencyHist)
+		item.TTFTHistogram = []int64(ttftHist)
+		out = append(out, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *opsRepository) GetLatestDimensionRollupBucketStart(ctx context.Context) (time.Time, bool, error) {
+	if r == nil || r.db == nil {
+		return time.Time{}, false, fmt.Errorf("nil ops repository")
+	}
+
+	var value sql.NullTime
+	if err := r.db.QueryRowContext(ctx, `SELECT MAX(bucket_start) FROM ops_dimension_metrics_5m`).Scan(&value); err != nil {
+		return time.Time{}, false, err
+	}
+	if !value.Valid {
+		return time.Time{}, false, nil
+	}
+	return value.Time.UTC(), true, nil
+}
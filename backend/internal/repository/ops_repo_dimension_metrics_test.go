@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsRepositoryListDimensionSamples_MapsRows(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &opsRepository{db: db}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(5 * time.Minute)
+
+	rows := sqlmock.NewRows([]string{
+		"created_at", "model", "account_id", "group_id", "duration_ms", "first_token_ms",
+		"total_tokens", "output_tokens", "from_error_log", "is_error", "failover_count",
+	}).
+		AddRow(start, "gpt-5", int64(1), int64(2), int64(850), int64(120), int64(300), int64(100), false, false, int64(0)).
+		AddRow(start.Add(time.Minute), "gpt-5", int64(1), nil, nil, nil, int64(0), int64(0), true, true, int64(2)).
+		AddRow(start.Add(2*time.Minute), "", nil, nil, nil, nil, int64(0), int64(0), true, false, int64(1))
+
+	mock.ExpectQuery(`FROM usage_logs ul[\s\S]+FROM ops_error_logs e`).
+		WithArgs(start, end).
+		WillReturnRows(rows)
+
+	samples, err := repo.ListDimensionSamples(context.Background(), start, end)
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+
+	require.False(t, samples[0].IsError)
+	require.False(t, samples[0].FailoverOnly)
+	require.Equal(t, int64(850), *samples[0].DurationMs)
+	require.Equal(t, int64(2), *samples[0].GroupID)
+
+	require.True(t, samples[1].IsError)
+	require.False(t, samples[1].FailoverOnly)
+	require.Nil(t, samples[1].GroupID)
+	require.Equal(t, int64(2), samples[1].FailoverCount)
+
+	require.True(t, samples[2].FailoverOnly)
+	require.Nil(t, samples[2].AccountID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpsRepositoryUpsertDimensionRollups_Batches(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &opsRepository{db: db}
+
+	bucket := time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC)
+	rollups := make([]*service.OpsDimensionRollup, 0, opsDimensionRollupUpsertBatch+1)
+	for i := 0; i < opsDimensionRollupUpsertBatch+1; i++ {
+		rollups = append(rollups, &service.OpsDimensionRollup{
+			BucketStart:      bucket,
+			Dimension:        service.OpsDimensionModel,
+			DimensionKey:     "m",
+			LatencyHistogram: []int64{1, 2},
+			TTFTHistogram:    []int64{0, 1},
+		})
+	}
+
+	mock.ExpectExec(`INSERT INTO ops_dimension_metrics_5m[\s\S]+ON CONFLICT \(bucket_start, dimension, dimension_key\) DO UPDATE`).
+		WillReturnResult(sqlmock.NewResult(0, opsDimensionRollupUpsertBatch))
+	mock.ExpectExec(`INSERT INTO ops_dimension_metrics_5m`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.UpsertDimensionRollups(context.Background(), rollups))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+func TestOpsErrorLogInsertArgsMatchPlaceholders(t *testing.T) {
+	placeholders := regexp.MustCompile(`\$\d+`).FindAllString(insertOpsErrorLogSQL, -1)
+	args := opsInsertErrorLogArgs(&service.OpsInsertErrorLogInput{TraceID: "trace-1"})
+	if len(placeholders) != len(args) {
+		t.Fatalf("insert placeholders=%d args=%d", len(placeholders), len(args))
+	}
+	if got := args[len(args)-1]; got != opsNullString("trace-1") {
+		t.Fatalf("trace_id should be the last insert arg, got %#v", got)
+	}
+}
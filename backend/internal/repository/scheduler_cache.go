@@ -560,6 +560,9 @@ func filterSchedulerExtra(extra map[string]any) map[string]any {
 		"auto_pause_5h_disabled",
 		"auto_pause_7d_disabled",
 		"model_rate_limits",
+		service.AccountLabelsExtraKey,
+		service.AccountScheduleWindowsExtraKey,
+		service.AccountScheduleTimezoneExtraKey,
 	}
 	filtered := make(map[string]any)
 	for _, key := range keys {
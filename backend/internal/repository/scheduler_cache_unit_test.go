@@ -134,3 +134,32 @@ func TestBuildSchedulerMetadataAccount_KeepsModelRateLimits(t *testing.T) {
 	require.Contains(t, limits, "antigravity:gemini")
 	require.Nil(t, got.Extra["unused_large_field"])
 }
+
+func TestBuildSchedulerMetadataAccount_KeepsScheduleWindows(t *testing.T) {
+	windows := []any{map[string]any{"weekday": 1, "start": "22:00", "end": "24:00"}}
+	account := service.Account{
+		ID: 91,
+		Extra: map[string]any{
+			service.AccountScheduleWindowsExtraKey:  windows,
+			service.AccountScheduleTimezoneExtraKey: "Asia/Shanghai",
+		},
+	}
+
+	got := buildSchedulerMetadataAccount(account)
+
+	require.Equal(t, windows, got.Extra[service.AccountScheduleWindowsExtraKey])
+	require.Equal(t, "Asia/Shanghai", got.Extra[service.AccountScheduleTimezoneExtraKey])
+}
+
+func TestBuildSchedulerMetadataAccount_KeepsLabels(t *testing.T) {
+	labels := []any{"premium", "eu"}
+	account := service.Account{
+		ID:    92,
+		Extra: map[string]any{service.AccountLabelsExtraKey: labels},
+	}
+
+	got := buildSchedulerMetadataAccount(account)
+
+	require.Equal(t, labels, got.Extra[service.AccountLabelsExtraKey])
+	require.True(t, got.MatchesLabelSelector([]string{"premium"}))
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// upstreamRateLimitKey 上游限流余量观测值：Hash，field 为账号 ID，value 为 JSON
+const upstreamRateLimitKey = "upstream_ratelimit"
+
+type upstreamRateLimitCache struct {
+	rdb *redis.Client
+}
+
+func NewUpstreamRateLimitCache(rdb *redis.Client) service.UpstreamRateLimitCache {
+	return &upstreamRateLimitCache{rdb: rdb}
+}
+
+func (c *upstreamRateLimitCache) SaveUpstreamRateLimit(ctx context.Context, snapshot service.UpstreamRateLimitSnapshot, ttl time.Duration) error {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	pipe := c.rdb.TxPipeline()
+	pipe.HSet(ctx, upstreamRateLimitKey, strconv.FormatInt(snapshot.AccountID, 10), raw)
+	if ttl > 0 {
+		pipe.Expire(ctx, upstreamRateLimitKey, ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *upstreamRateLimitCache) ListUpstreamRateLimits(ctx context.Context) ([]service.UpstreamRateLimitSnapshot, error) {
+	entries, err := c.rdb.HGetAll(ctx, upstreamRateLimitKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]service.UpstreamRateLimitSnapshot, 0, len(entries))
+	for _, raw := range entries {
+		var snapshot service.UpstreamRateLimitSnapshot
+		if err := json.Unmarshal([]byte(raw), &snapshot); err != nil || snapshot.AccountID <= 0 {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func (c *upstreamRateLimitCache) DeleteUpstreamRateLimits(ctx context.Context, accountIDs []int64) error {
+	if len(accountIDs) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		fields = append(fields, strconv.FormatInt(id, 10))
+	}
+	return c.rdb.HDel(ctx, upstreamRateLimitKey, fields...).Err()
+}
@@ -0,0 +1,72 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpstreamRateLimitCache_SaveList(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewUpstreamRateLimitCache(rdb)
+	ctx := context.Background()
+
+	observedAt := time.Now().UTC().Truncate(time.Second)
+	resetAt := observedAt.Add(time.Minute)
+	remaining := int64(1200)
+	require.NoError(t, cache.SaveUpstreamRateLimit(ctx, service.UpstreamRateLimitSnapshot{
+		AccountID:  3,
+		Platform:   service.PlatformOpenAI,
+		Tokens:     &service.UpstreamRateLimitWindow{Remaining: &remaining, ResetAt: &resetAt},
+		ObservedAt: observedAt,
+	}, time.Minute))
+	require.NoError(t, cache.SaveUpstreamRateLimit(ctx, service.UpstreamRateLimitSnapshot{
+		AccountID:  3,
+		Platform:   service.PlatformOpenAI,
+		ObservedAt: observedAt.Add(time.Second),
+	}, time.Minute))
+	require.Equal(t, time.Minute, mr.TTL(upstreamRateLimitKey))
+
+	snapshots, err := cache.ListUpstreamRateLimits(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	require.Equal(t, observedAt.Add(time.Second), snapshots[0].ObservedAt.UTC())
+	require.Nil(t, snapshots[0].Tokens)
+
+	mr.HSet(upstreamRateLimitKey, "bad", "not-json")
+	snapshots, err = cache.ListUpstreamRateLimits(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+}
+
+func TestUpstreamRateLimitCache_Delete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := NewUpstreamRateLimitCache(rdb)
+	ctx := context.Background()
+
+	for _, id := range []int64{1, 2} {
+		require.NoError(t, cache.SaveUpstreamRateLimit(ctx, service.UpstreamRateLimitSnapshot{
+			AccountID:  id,
+			Platform:   service.PlatformOpenAI,
+			ObservedAt: time.Now(),
+		}, time.Minute))
+	}
+	require.NoError(t, cache.DeleteUpstreamRateLimits(ctx, nil))
+	require.NoError(t, cache.DeleteUpstreamRateLimits(ctx, []int64{2}))
+
+	snapshots, err := cache.ListUpstreamRateLimits(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	require.EqualValues(t, 1, snapshots[0].AccountID)
+}
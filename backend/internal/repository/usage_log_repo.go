@@ -30,7 +30,7 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, requested_model, upstream_model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, image_output_tokens, image_output_cost, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, image_input_size, image_output_size, image_size_source, image_size_breakdown, service_tier, reasoning_effort, inbound_endpoint, upstream_endpoint, cache_ttl_overridden, channel_id, model_mapping_chain, billing_tier, billing_mode, account_stats_cost, created_at"
+const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, requested_model, upstream_model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, image_output_tokens, image_output_cost, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, image_input_size, image_output_size, image_size_source, image_size_breakdown, service_tier, reasoning_effort, inbound_endpoint, upstream_endpoint, cache_ttl_overridden, channel_id, model_mapping_chain, billing_tier, billing_mode, account_stats_cost, usage_estimated, billing_unverified, request_bytes, response_bytes, response_cache_hit, pricing_unavailable, estimated_cost, cost_overage, created_at"
 
 // usageLogInsertArgTypes must stay in the same order as:
 //  1. prepareUsageLogInsert().args
@@ -89,6 +89,14 @@ var usageLogInsertArgTypes = [...]string{
 	"text",        // billing_tier
 	"text",        // billing_mode
 	"numeric",     // account_stats_cost
+	"boolean",     // usage_estimated
+	"boolean",     // billing_unverified
+	"bigint",      // request_bytes
+	"bigint",      // response_bytes
+	"boolean",     // response_cache_hit
+	"boolean",     // pricing_unavailable
+	"numeric",     // estimated_cost
+	"boolean",     // cost_overage
 	"timestamptz", // created_at
 }
 
@@ -160,6 +168,17 @@ func appendUsageLogBillingModeWhereCondition(conditions []string, args []any, bi
 	return conditions, args
 }
 
+// appendUsageLogScopeWhereCondition 按 API Key 作用域筛选：作用域映射为一组规范化入站端点
+func appendUsageLogScopeWhereCondition(conditions []string, args []any, scope string) ([]string, []any) {
+	endpoints := service.APIKeyScopeInboundEndpoints(strings.TrimSpace(scope))
+	if len(endpoints) == 0 {
+		return conditions, args
+	}
+	conditions = append(conditions, fmt.Sprintf("inbound_endpoint = ANY($%d)", len(args)+1))
+	args = append(args, pq.Array(endpoints))
+	return conditions, args
+}
+
 // appendRawUsageLogModelQueryFilter keeps direct model filters on the raw model column for backward
 // compatibility with historical rows. Requested/upstream analytics must use
 // resolveModelDimensionExpression instead.
@@ -406,6 +425,14 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			usage_estimated,
+			billing_unverified,
+			request_bytes,
+			response_bytes,
+			response_cache_hit,
+			pricing_unavailable,
+			estimated_cost,
+			cost_overage,
 			created_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
@@ -413,7 +440,7 @@ func (r *usageLogRepository) createSingle(ctx context.Context, sqlq sqlExecutor,
 			$10, $11, $12, $13,
 			$14, $15, $16, $17,
 			$18, $19, $20, $21, $22, $23,
-			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50
+			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 		RETURNING id, created_at
@@ -848,10 +875,18 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			usage_estimated,
+			billing_unverified,
+			request_bytes,
+			response_bytes,
+			response_cache_hit,
+			pricing_unavailable,
+			estimated_cost,
+			cost_overage,
 			created_at
 		) AS (VALUES `)
 
-	args := make([]any, 0, len(keys)*50)
+	args := make([]any, 0, len(keys)*58)
 	argPos := 1
 	for idx, key := range keys {
 		if idx > 0 {
@@ -929,6 +964,14 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				billing_tier,
 				billing_mode,
 				account_stats_cost,
+				usage_estimated,
+				billing_unverified,
+				request_bytes,
+				response_bytes,
+				response_cache_hit,
+				pricing_unavailable,
+				estimated_cost,
+				cost_overage,
 				created_at
 			)
 			SELECT
@@ -981,6 +1024,14 @@ func buildUsageLogBatchInsertQuery(keys []string, preparedByKey map[string]usage
 				billing_tier,
 				billing_mode,
 				account_stats_cost,
+				usage_estimated,
+				billing_unverified,
+				request_bytes,
+				response_bytes,
+				response_cache_hit,
+				pricing_unavailable,
+				estimated_cost,
+				cost_overage,
 				created_at
 			FROM input
 			ON CONFLICT (request_id, api_key_id) DO NOTHING
@@ -1073,10 +1124,18 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			usage_estimated,
+			billing_unverified,
+			request_bytes,
+			response_bytes,
+			response_cache_hit,
+			pricing_unavailable,
+			estimated_cost,
+			cost_overage,
 			created_at
 		) AS (VALUES `)
 
-	args := make([]any, 0, len(preparedList)*50)
+	args := make([]any, 0, len(preparedList)*58)
 	argPos := 1
 	for idx, prepared := range preparedList {
 		if idx > 0 {
@@ -1151,6 +1210,14 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			usage_estimated,
+			billing_unverified,
+			request_bytes,
+			response_bytes,
+			response_cache_hit,
+			pricing_unavailable,
+			estimated_cost,
+			cost_overage,
 			created_at
 		)
 		SELECT
@@ -1203,6 +1270,14 @@ func buildUsageLogBestEffortInsertQuery(preparedList []usageLogInsertPrepared) (
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			usage_estimated,
+			billing_unverified,
+			request_bytes,
+			response_bytes,
+			response_cache_hit,
+			pricing_unavailable,
+			estimated_cost,
+			cost_overage,
 			created_at
 		FROM input
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
@@ -1263,6 +1338,14 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			billing_tier,
 			billing_mode,
 			account_stats_cost,
+			usage_estimated,
+			billing_unverified,
+			request_bytes,
+			response_bytes,
+			response_cache_hit,
+			pricing_unavailable,
+			estimated_cost,
+			cost_overage,
 			created_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
@@ -1270,7 +1353,7 @@ func execUsageLogInsertNoResult(ctx context.Context, sqlq sqlExecutor, prepared
 			$10, $11, $12, $13,
 			$14, $15, $16, $17,
 			$18, $19, $20, $21, $22, $23,
-			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50
+			$24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 	`, prepared.args...)
@@ -1375,6 +1458,14 @@ func prepareUsageLogInsert(log *service.UsageLog) usageLogInsertPrepared {
 			billingTier,
 			billingMode,
 			log.AccountStatsCost, // account_stats_cost
+			log.UsageEstimated,
+			log.BillingUnverified,
+			nullInt64(log.RequestBytes),
+			nullInt64(log.ResponseBytes),
+			log.ResponseCacheHit,
+			log.PricingUnavailable,
+			log.EstimatedCost, // estimated_cost
+			log.CostOverage,
 			createdAt,
 		},
 	}
@@ -2813,6 +2904,7 @@ func (r *usageLogRepository) ListWithFilters(ctx context.Context, params paginat
 		args = append(args, int16(*filters.BillingType))
 	}
 	conditions, args = appendUsageLogBillingModeWhereCondition(conditions, args, filters.BillingMode)
+	conditions, args = appendUsageLogScopeWhereCondition(conditions, args, filters.Scope)
 	if filters.StartTime != nil {
 		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)+1))
 		args = append(args, *filters.StartTime)
@@ -3530,6 +3622,7 @@ func (r *usageLogRepository) GetStatsWithFilters(ctx context.Context, filters Us
 		args = append(args, int16(*filters.BillingType))
 	}
 	conditions, args = appendUsageLogBillingModeWhereCondition(conditions, args, filters.BillingMode)
+	conditions, args = appendUsageLogScopeWhereCondition(conditions, args, filters.Scope)
 	if filters.StartTime != nil {
 		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)+1))
 		args = append(args, *filters.StartTime)
@@ -4303,6 +4396,14 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		billingTier           sql.NullString
 		billingMode           sql.NullString
 		accountStatsCost      sql.NullFloat64
+		usageEstimated        bool
+		billingUnverified     bool
+		requestBytes          sql.NullInt64
+		responseBytes         sql.NullInt64
+		responseCacheHit      bool
+		pricingUnavailable    bool
+		estimatedCost         sql.NullFloat64
+		costOverage           bool
 		createdAt             time.Time
 	)
 
@@ -4357,6 +4458,14 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		&billingTier,
 		&billingMode,
 		&accountStatsCost,
+		&usageEstimated,
+		&billingUnverified,
+		&requestBytes,
+		&responseBytes,
+		&responseCacheHit,
+		&pricingUnavailable,
+		&estimatedCost,
+		&costOverage,
 		&createdAt,
 	); err != nil {
 		return nil, err
@@ -4389,6 +4498,11 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		RequestType:           service.RequestTypeFromInt16(requestTypeRaw),
 		ImageCount:            imageCount,
 		CacheTTLOverridden:    cacheTTLOverridden,
+		UsageEstimated:        usageEstimated,
+		BillingUnverified:     billingUnverified,
+		ResponseCacheHit:      responseCacheHit,
+		PricingUnavailable:    pricingUnavailable,
+		CostOverage:           costOverage,
 		CreatedAt:             createdAt,
 	}
 	// 先回填 legacy 字段，再基于 legacy + request_type 计算最终请求类型，保证历史数据兼容。
@@ -4416,6 +4530,12 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		value := int(firstTokenMs.Int64)
 		log.FirstTokenMs = &value
 	}
+	if requestBytes.Valid {
+		log.RequestBytes = &requestBytes.Int64
+	}
+	if responseBytes.Valid {
+		log.ResponseBytes = &responseBytes.Int64
+	}
 	if userAgent.Valid {
 		log.UserAgent = &userAgent.String
 	}
@@ -4466,6 +4586,9 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 	if accountStatsCost.Valid {
 		log.AccountStatsCost = &accountStatsCost.Float64
 	}
+	if estimatedCost.Valid {
+		log.EstimatedCost = &estimatedCost.Float64
+	}
 
 	return log, nil
 }
@@ -90,6 +90,14 @@ func TestUsageLogRepositoryCreateSyncRequestTypeAndLegacyFields(t *testing.T) {
 			sqlmock.AnyArg(), // billing_tier
 			sqlmock.AnyArg(), // billing_mode
 			sqlmock.AnyArg(), // account_stats_cost
+			false,            // usage_estimated
+			false,            // billing_unverified
+			sqlmock.AnyArg(), // request_bytes
+			sqlmock.AnyArg(), // response_bytes
+			false,            // response_cache_hit
+			false,            // pricing_unavailable
+			sqlmock.AnyArg(), // estimated_cost
+			false,            // cost_overage
 			createdAt,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(99), createdAt))
@@ -173,6 +181,14 @@ func TestUsageLogRepositoryCreate_PersistsServiceTier(t *testing.T) {
 			sqlmock.AnyArg(), // billing_tier
 			sqlmock.AnyArg(), // billing_mode
 			sqlmock.AnyArg(), // account_stats_cost
+			false,            // usage_estimated
+			false,            // billing_unverified
+			sqlmock.AnyArg(), // request_bytes
+			sqlmock.AnyArg(), // response_bytes
+			false,            // response_cache_hit
+			false,            // pricing_unavailable
+			sqlmock.AnyArg(), // estimated_cost
+			false,            // cost_overage
 			createdAt,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(100), createdAt))
@@ -643,6 +659,14 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			sql.NullString{},
 			sql.NullFloat64{},
+			false,
+			false,
+			sql.NullInt64{Valid: true, Int64: 2048},
+			sql.NullInt64{Valid: true, Int64: 6291456},
+			true,
+			true,
+			sql.NullFloat64{Valid: true, Float64: 0.25},
+			true,
 			now,
 		}})
 		require.NoError(t, err)
@@ -656,6 +680,15 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 		require.NotNil(t, log.ImageSizeSource)
 		require.Equal(t, "output", *log.ImageSizeSource)
 		require.Equal(t, map[string]int{"4K": 2}, log.ImageSizeBreakdown)
+		require.NotNil(t, log.RequestBytes)
+		require.Equal(t, int64(2048), *log.RequestBytes)
+		require.NotNil(t, log.ResponseBytes)
+		require.Equal(t, int64(6291456), *log.ResponseBytes)
+		require.True(t, log.ResponseCacheHit)
+		require.True(t, log.PricingUnavailable)
+		require.NotNil(t, log.EstimatedCost)
+		require.InDelta(t, 0.25, *log.EstimatedCost, 1e-12)
+		require.True(t, log.CostOverage)
 	})
 
 	t.Run("request_type_ws_v2_overrides_legacy", func(t *testing.T) {
@@ -711,6 +744,14 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},  // billing_tier
 			sql.NullString{},  // billing_mode
 			sql.NullFloat64{}, // account_stats_cost
+			false,             // usage_estimated
+			false,             // billing_unverified
+			sql.NullInt64{},   // request_bytes
+			sql.NullInt64{},   // response_bytes
+			false,             // response_cache_hit
+			false,             // pricing_unavailable
+			sql.NullFloat64{}, // estimated_cost
+			false,             // cost_overage
 			now,
 		}})
 		require.NoError(t, err)
@@ -763,6 +804,14 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},  // billing_tier
 			sql.NullString{},  // billing_mode
 			sql.NullFloat64{}, // account_stats_cost
+			false,             // usage_estimated
+			false,             // billing_unverified
+			sql.NullInt64{},   // request_bytes
+			sql.NullInt64{},   // response_bytes
+			false,             // response_cache_hit
+			false,             // pricing_unavailable
+			sql.NullFloat64{}, // estimated_cost
+			false,             // cost_overage
 			now,
 		}})
 		require.NoError(t, err)
@@ -815,6 +864,14 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},  // billing_tier
 			sql.NullString{},  // billing_mode
 			sql.NullFloat64{}, // account_stats_cost
+			false,             // usage_estimated
+			false,             // billing_unverified
+			sql.NullInt64{},   // request_bytes
+			sql.NullInt64{},   // response_bytes
+			false,             // response_cache_hit
+			false,             // pricing_unavailable
+			sql.NullFloat64{}, // estimated_cost
+			false,             // cost_overage
 			now,
 		}})
 		require.NoError(t, err)
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// usageRecordRetryPendingKey 待重试的用量记录：List，LPUSH 入队、LMOVE 出队（先进先出）
+	usageRecordRetryPendingKey = "usage_record_retry:pending"
+	// usageRecordRetryProcessingKey 已出队、尚未确认的用量记录：List，处理完成后 LREM 确认；
+	// 进程在出队与确认之间退出时条目留在此列表，下次启动时移回待重试队列
+	usageRecordRetryProcessingKey = "usage_record_retry:processing"
+	// usageRecordRetryDeadLetterKey 超过最长重试时间的用量记录：List，最新条目在表头
+	usageRecordRetryDeadLetterKey = "usage_record_retry:dead_letter"
+)
+
+type usageRecordRetryCache struct {
+	rdb *redis.Client
+}
+
+func NewUsageRecordRetryCache(rdb *redis.Client) service.UsageRecordRetryCache {
+	return &usageRecordRetryCache{rdb: rdb}
+}
+
+func (c *usageRecordRetryCache) PushUsageRecordRetry(ctx context.Context, entry *service.UsageRecordRetryEntry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return c.rdb.LPush(ctx, usageRecordRetryPendingKey, raw).Err()
+}
+
+func (c *usageRecordRetryCache) PopUsageRecordRetry(ctx context.Context) (*service.UsageRecordRetryEntry, error) {
+	raw, err := c.rdb.LMove(ctx, usageRecordRetryPendingKey, usageRecordRetryProcessingKey, "RIGHT", "LEFT").Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry service.UsageRecordRetryEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		// 无法解析的条目永远无法重放，直接移出处理中列表，避免每次启动反复恢复
+		_ = c.rdb.LRem(ctx, usageRecordRetryProcessingKey, 1, raw).Err()
+		return nil, err
+	}
+	entry.Receipt = raw
+	return &entry, nil
+}
+
+func (c *usageRecordRetryCache) AckUsageRecordRetry(ctx context.Context, entry *service.UsageRecordRetryEntry) error {
+	if entry == nil || entry.Receipt == "" {
+		return nil
+	}
+	return c.rdb.LRem(ctx, usageRecordRetryProcessingKey, 1, entry.Receipt).Err()
+}
+
+func (c *usageRecordRetryCache) RestoreUsageRecordRetryProcessing(ctx context.Context) (int, error) {
+	// 以开始时的长度为上限，避免与其他实例并发出队时无限循环
+	total, err := c.rdb.LLen(ctx, usageRecordRetryProcessingKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	restored := 0
+	for ; int64(restored) < total; restored++ {
+		err := c.rdb.LMove(ctx, usageRecordRetryProcessingKey, usageRecordRetryPendingKey, "RIGHT", "RIGHT").Err()
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+		if err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
+
+func (c *usageRecordRetryCache) UsageRecordRetryPendingCount(ctx context.Context) (int64, error) {
+	return c.rdb.LLen(ctx, usageRecordRetryPendingKey).Result()
+}
+
+func (c *usageRecordRetryCache) PushUsageRecordDeadLetter(ctx context.Context, entry *service.UsageRecordRetryEntry, maxLen int) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	pipe := c.rdb.TxPipeline()
+	pipe.LPush(ctx, usageRecordRetryDeadLetterKey, raw)
+	if maxLen > 0 {
+		pipe.LTrim(ctx, usageRecordRetryDeadLetterKey, 0, int64(maxLen-1))
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *usageRecordRetryCache) ListUsageRecordDeadLetters(ctx context.Context, limit int) ([]service.UsageRecordRetryEntry, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	raws, err := c.rdb.LRange(ctx, usageRecordRetryDeadLetterKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]service.UsageRecordRetryEntry, 0, len(raws))
+	for _, raw := range raws {
+		var entry service.UsageRecordRetryEntry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,82 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newUsageRecordRetryTestCache(t *testing.T) (*usageRecordRetryCache, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return &usageRecordRetryCache{rdb: rdb}, rdb
+}
+
+func TestUsageRecordRetryCache_PopMovesToProcessingUntilAck(t *testing.T) {
+	cache, rdb := newUsageRecordRetryTestCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.PushUsageRecordRetry(ctx, &service.UsageRecordRetryEntry{ID: "a", Kind: service.UsageRecordRetryKindGateway}))
+	require.NoError(t, cache.PushUsageRecordRetry(ctx, &service.UsageRecordRetryEntry{ID: "b", Kind: service.UsageRecordRetryKindOpenAI}))
+
+	entry, err := cache.PopUsageRecordRetry(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "a", entry.ID, "oldest entry first")
+	require.NotEmpty(t, entry.Receipt)
+	require.EqualValues(t, 1, rdb.LLen(ctx, usageRecordRetryPendingKey).Val())
+	require.EqualValues(t, 1, rdb.LLen(ctx, usageRecordRetryProcessingKey).Val())
+
+	require.NoError(t, cache.AckUsageRecordRetry(ctx, entry))
+	require.Zero(t, rdb.LLen(ctx, usageRecordRetryProcessingKey).Val())
+}
+
+func TestUsageRecordRetryCache_RestoreProcessing(t *testing.T) {
+	cache, rdb := newUsageRecordRetryTestCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.PushUsageRecordRetry(ctx, &service.UsageRecordRetryEntry{ID: "a"}))
+	require.NoError(t, cache.PushUsageRecordRetry(ctx, &service.UsageRecordRetryEntry{ID: "b"}))
+	_, err := cache.PopUsageRecordRetry(ctx)
+	require.NoError(t, err)
+	_, err = cache.PopUsageRecordRetry(ctx)
+	require.NoError(t, err)
+
+	// 出队后未确认（进程退出）：重启时全部移回待重试队列
+	restored, err := cache.RestoreUsageRecordRetryProcessing(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, restored)
+	require.Zero(t, rdb.LLen(ctx, usageRecordRetryProcessingKey).Val())
+	count, err := cache.UsageRecordRetryPendingCount(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, count)
+
+	ids := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		entry, err := cache.PopUsageRecordRetry(ctx)
+		require.NoError(t, err)
+		ids[entry.ID] = true
+	}
+	require.Equal(t, map[string]bool{"a": true, "b": true}, ids)
+
+	restored, err = cache.RestoreUsageRecordRetryProcessing(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, restored)
+}
+
+func TestUsageRecordRetryCache_MalformedEntryIsDropped(t *testing.T) {
+	cache, rdb := newUsageRecordRetryTestCache(t)
+	ctx := context.Background()
+	require.NoError(t, rdb.LPush(ctx, usageRecordRetryPendingKey, "not-json").Err())
+
+	_, err := cache.PopUsageRecordRetry(ctx)
+	require.Error(t, err)
+	require.Zero(t, rdb.LLen(ctx, usageRecordRetryProcessingKey).Val())
+}
@@ -62,6 +62,11 @@ func ProvideSchedulerCache(rdb *redis.Client, cfg *config.Config) service.Schedu
 	return newSchedulerCacheWithChunkSizes(rdb, mgetChunkSize, writeChunkSize)
 }
 
+// ProvideHTTPUpstream 创建上游 HTTP 客户端，并叠加按 API Key 的请求/响应抓取。
+func ProvideHTTPUpstream(cfg *config.Config, captureService *service.APIKeyCaptureService) service.HTTPUpstream {
+	return service.NewCapturingHTTPUpstream(NewHTTPUpstream(cfg), captureService)
+}
+
 // ProviderSet is the Wire provider set for all repositories
 var ProviderSet = wire.NewSet(
 	NewUserRepository,
@@ -87,11 +92,13 @@ var ProviderSet = wire.NewSet(
 	NewUserAttributeValueRepository,
 	NewUserGroupRateRepository,
 	NewErrorPassthroughRepository,
+	NewAPIKeyCaptureRepository,
 	NewTLSFingerprintProfileRepository,
 	NewChannelRepository,
 	NewChannelMonitorRepository,
 	NewChannelMonitorRequestTemplateRepository,
 	NewContentModerationRepository,
+	NewAuditLogRepository,
 	NewAffiliateRepository,
 	NewUserPlatformQuotaRepository,     // T14: user × platform quota
 	NewUserPlatformQuotaServiceAdapter, // T14: adapter → service.UserPlatformQuotaRepository
@@ -124,6 +131,13 @@ var ProviderSet = wire.NewSet(
 	NewErrorPassthroughCache,
 	NewTLSFingerprintProfileCache,
 	NewContentModerationHashCache,
+	NewGatewayIdempotencyCache,
+	NewGatewayResponseCache,
+	NewAccountHealthCache,
+	NewUpstreamRateLimitCache,
+	NewLogComponentLevelCache,
+	NewUsageRecordRetryCache,
+	NewGroupSpendCapCache,
 
 	// Encryptors
 	NewAESEncryptor,
@@ -139,7 +153,7 @@ var ProviderSet = wire.NewSet(
 	NewProxyExitInfoProber,
 	NewClaudeUsageFetcher,
 	NewClaudeOAuthClient,
-	NewHTTPUpstream,
+	ProvideHTTPUpstream,
 	NewOpenAIOAuthClient,
 	NewGrokOAuthClient,
 	NewGeminiOAuthClient,
@@ -232,6 +232,11 @@ func TestAPIContracts(t *testing.T) {
 					"status": "active",
 					"ip_whitelist": null,
 					"ip_blacklist": null,
+					"account_labels": null,
+					"response_cache_enabled": false,
+					"cost_preview_enabled": false,
+					"scopes": ["chat", "images", "video", "embeddings"],
+					"allowed_models": null,
 					"last_used_at": null,
 					"quota": 0,
 					"quota_used": 0,
@@ -281,6 +286,12 @@ func TestAPIContracts(t *testing.T) {
 							"status": "active",
 							"ip_whitelist": null,
 							"ip_blacklist": null,
+							"account_labels": null,
+							"response_cache_enabled": false,
+							"cost_preview_enabled": false,
+							"scopes": ["chat", "images", "video", "embeddings"],
+							"allowed_models": null,
+					"allowed_models": null,
 							"last_used_at": null,
 							"quota": 0,
 							"quota_used": 0,
@@ -589,6 +600,14 @@ func TestAPIContracts(t *testing.T) {
 							"image_size_breakdown": null,
 							"media_type": null,
 							"cache_ttl_overridden": false,
+							"usage_estimated": false,
+							"billing_unverified": false,
+							"request_bytes": null,
+							"response_bytes": null,
+							"response_cache_hit": false,
+							"pricing_unavailable": false,
+							"estimated_cost": null,
+							"cost_overage": false,
 							"created_at": "2025-01-02T03:04:05Z",
 							"user_agent": null
 						}
@@ -846,6 +865,7 @@ func TestAPIContracts(t *testing.T) {
 					"claude_oauth_system_prompt_blocks": "",
 					"enable_anthropic_cache_ttl_1h_injection": false,
 					"rewrite_message_cache_control": false,
+					"rate_limit_headers_per_key": false,
 					"antigravity_user_agent_version": "",
 					"enable_fingerprint_unification": true,
 					"enable_metadata_passthrough": false,
@@ -1090,6 +1110,7 @@ func TestAPIContracts(t *testing.T) {
 					"claude_oauth_system_prompt_blocks": "",
 					"enable_anthropic_cache_ttl_1h_injection": false,
 					"rewrite_message_cache_control": false,
+					"rate_limit_headers_per_key": false,
 					"antigravity_user_agent_version": "",
 					"min_codex_version": "",
 					"max_codex_version": "",
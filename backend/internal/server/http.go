@@ -24,6 +24,7 @@ import (
 var ProviderSet = wire.NewSet(
 	ProvideRouter,
 	ProvideHTTPServer,
+	NewRequestDrainer,
 )
 
 // ProvideRouter 提供路由器
@@ -98,8 +99,9 @@ func ProvideRouter(
 }
 
 // ProvideHTTPServer 提供 HTTP 服务器
-func ProvideHTTPServer(cfg *config.Config, router *gin.Engine) *http.Server {
-	httpHandler := http.Handler(router)
+func ProvideHTTPServer(cfg *config.Config, router *gin.Engine, drainer *RequestDrainer) *http.Server {
+	// 停机排空期间拒绝新请求，在途请求不受影响
+	httpHandler := drainer.Guard(router)
 	server := &http.Server{
 		Addr:    cfg.Server.Address(),
 		Handler: httpHandler,
@@ -109,6 +111,8 @@ func ProvideHTTPServer(cfg *config.Config, router *gin.Engine) *http.Server {
 		IdleTimeout: time.Duration(cfg.Server.IdleTimeout) * time.Second,
 		// 注意：不设置 WriteTimeout，因为流式响应可能持续十几分钟
 		// 不设置 ReadTimeout，因为大请求体可能需要较长时间读取
+		// BaseContext: 优雅停机宽限期结束后统一取消在途请求
+		BaseContext: drainer.BaseContext,
 	}
 
 	globalMaxSize := cfg.Server.MaxRequestBodySize
@@ -137,6 +137,8 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			})
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
+			setAPIKeyIDContext(c, apiKey.ID)
+			setAccountLabelSelectorContext(c, apiKey)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 			c.Next()
 			return
@@ -232,6 +234,8 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 		})
 		c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 		setGroupContext(c, apiKey.Group)
+		setAPIKeyIDContext(c, apiKey.ID)
+		setAccountLabelSelectorContext(c, apiKey)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 
 		c.Next()
@@ -289,6 +293,34 @@ func setGroupContext(c *gin.Context, group *service.Group) {
 	c.Request = c.Request.WithContext(ctx)
 }
 
+// setAPIKeyIDContext 把已认证的 API Key ID 写入请求 context，供 service 层（如上游请求抓取）读取；
+// 同时挂载请求级计费校验状态（计费降级放行时用于标记用量记录）。
+func setAPIKeyIDContext(c *gin.Context, apiKeyID int64) {
+	if apiKeyID <= 0 {
+		return
+	}
+	ctx := context.WithValue(c.Request.Context(), ctxkey.APIKeyID, apiKeyID)
+	ctx = service.WithBillingVerification(ctx)
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// accountLabelsHeader 请求级账号标签选择器（逗号分隔），与 API Key 的 account_labels 合并生效
+const accountLabelsHeader = "X-Account-Labels"
+
+// setAccountLabelSelectorContext 合并 API Key 配置与请求头中的账号标签，写入请求 context。
+// 两者取并集：请求头只能进一步收窄 Key 已限定的账号范围，不能绕过。
+func setAccountLabelSelectorContext(c *gin.Context, apiKey *service.APIKey) {
+	var selector []string
+	if apiKey != nil {
+		selector = append(selector, apiKey.AccountLabels...)
+	}
+	selector = append(selector, service.ParseAccountLabelSelector(c.GetHeader(accountLabelsHeader))...)
+	if len(selector) == 0 {
+		return
+	}
+	c.Request = c.Request.WithContext(service.WithAccountLabelSelector(c.Request.Context(), selector))
+}
+
 func abortIfAPIKeyGroupUnavailable(c *gin.Context, apiKey *service.APIKey) bool {
 	code, message, ok := validateAPIKeyGroupAvailable(apiKey)
 	if ok {
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyScopeForRequest 将网关请求映射为所需的 API Key 作用域。
+// 模型列表、用量查询等只读元数据接口返回空字符串，表示任意作用域均可访问。
+//
+//	POST /v1/messages、/v1/chat/completions、/v1/responses、Gemini generateContent → chat
+//	GET  /v1/responses（WebSocket）                                            → chat
+//	POST /v1/images/generations、/v1/images/edits、Gemini predict（Imagen）     → images
+//	POST /v1/videos、Gemini predictLongRunning（Veo）                          → video
+//	POST /v1/embeddings、Gemini embedContent / batchEmbedContents              → embeddings
+func APIKeyScopeForRequest(method, path string) string {
+	path = strings.TrimRight(strings.TrimSpace(path), "/")
+	if method != http.MethodPost {
+		// GET /responses 为 Responses WebSocket 升级请求，其余 GET 均为元数据接口
+		if method == http.MethodGet && strings.HasSuffix(path, "/responses") {
+			return service.APIKeyScopeChat
+		}
+		return ""
+	}
+
+	// Gemini 原生接口：/v1beta/models/{model}:{action}
+	if idx := strings.LastIndex(path, ":"); idx >= 0 && strings.Contains(path, "/models/") {
+		switch path[idx+1:] {
+		case "embedContent", "batchEmbedContents":
+			return service.APIKeyScopeEmbeddings
+		case "predict":
+			return service.APIKeyScopeImages
+		case "predictLongRunning":
+			return service.APIKeyScopeVideo
+		default:
+			return service.APIKeyScopeChat
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(path, "/embeddings"):
+		return service.APIKeyScopeEmbeddings
+	case strings.Contains(path, "/images/"):
+		return service.APIKeyScopeImages
+	case strings.Contains(path, "/videos"):
+		return service.APIKeyScopeVideo
+	default:
+		return service.APIKeyScopeChat
+	}
+}
+
+// RequireAPIKeyScope 检查 API Key 是否拥有当前路由所需的作用域，否则返回 403 并列出 Key 的作用域。
+// 需在 API Key 认证中间件之后使用。
+func RequireAPIKeyScope(writeError GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		scope := APIKeyScopeForRequest(c.Request.Method, c.Request.URL.Path)
+		if scope == "" || apiKey.HasScope(scope) {
+			c.Next()
+			return
+		}
+		service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalPolicyDenied)
+		writeError(c, http.StatusForbidden, fmt.Sprintf(
+			"API key does not have the %q scope required by this endpoint (key scopes: %s)",
+			scope, strings.Join(apiKey.EffectiveScopes(), ", "),
+		))
+		c.Abort()
+	}
+}
@@ -0,0 +1,106 @@
+//go:build unit
+
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyScopeForRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodPost, "/v1/messages", service.APIKeyScopeChat},
+		{http.MethodPost, "/v1/messages/count_tokens", service.APIKeyScopeChat},
+		{http.MethodPost, "/antigravity/v1/messages", service.APIKeyScopeChat},
+		{http.MethodPost, "/v1/chat/completions", service.APIKeyScopeChat},
+		{http.MethodPost, "/chat/completions", service.APIKeyScopeChat},
+		{http.MethodPost, "/v1/responses", service.APIKeyScopeChat},
+		{http.MethodPost, "/backend-api/codex/responses/compact", service.APIKeyScopeChat},
+		{http.MethodGet, "/v1/responses", service.APIKeyScopeChat},
+		{http.MethodGet, "/responses", service.APIKeyScopeChat},
+		{http.MethodPost, "/v1/images/generations", service.APIKeyScopeImages},
+		{http.MethodPost, "/images/edits", service.APIKeyScopeImages},
+		{http.MethodPost, "/v1/videos", service.APIKeyScopeVideo},
+		{http.MethodPost, "/v1/embeddings", service.APIKeyScopeEmbeddings},
+		{http.MethodPost, "/embeddings", service.APIKeyScopeEmbeddings},
+		{http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", service.APIKeyScopeChat},
+		{http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", service.APIKeyScopeChat},
+		{http.MethodPost, "/v1beta/models/text-embedding-004:embedContent", service.APIKeyScopeEmbeddings},
+		{http.MethodPost, "/v1beta/models/text-embedding-004:batchEmbedContents", service.APIKeyScopeEmbeddings},
+		{http.MethodPost, "/v1beta/models/imagen-3.0-generate-002:predict", service.APIKeyScopeImages},
+		{http.MethodPost, "/antigravity/v1beta/models/veo-3.0-generate-preview:predictLongRunning", service.APIKeyScopeVideo},
+		// 元数据接口不要求作用域
+		{http.MethodGet, "/v1/models", ""},
+		{http.MethodGet, "/v1/usage", ""},
+		{http.MethodGet, "/v1beta/models", ""},
+		{http.MethodGet, "/v1beta/models/gemini-2.5-pro", ""},
+		{http.MethodGet, "/antigravity/models", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			require.Equal(t, tt.want, APIKeyScopeForRequest(tt.method, tt.path))
+		})
+	}
+}
+
+func newScopeTestRouter(apiKey *service.APIKey) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.Use(RequireAPIKeyScope(AnthropicErrorWriter))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.POST("/v1/chat/completions", ok)
+	r.POST("/v1/images/generations", ok)
+	r.GET("/v1/models", ok)
+	return r
+}
+
+func TestRequireAPIKeyScope_RejectsOutOfScope(t *testing.T) {
+	router := newScopeTestRouter(&service.APIKey{Scopes: []string{service.APIKeyScopeImages, service.APIKeyScopeVideo}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, "permission_error", body.Error.Type)
+	require.Contains(t, body.Error.Message, `"chat" scope`)
+	require.Contains(t, body.Error.Message, "key scopes: images, video")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/images/generations", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireAPIKeyScope_LegacyKeyHasAllScopes(t *testing.T) {
+	router := newScopeTestRouter(&service.APIKey{})
+
+	for _, path := range []string{"/v1/chat/completions", "/v1/images/generations"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, "path=%s", path)
+	}
+}
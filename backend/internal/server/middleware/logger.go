@@ -6,6 +6,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -56,6 +57,18 @@ func Logger() gin.HandlerFunc {
 		if model != "" {
 			fields = append(fields, zap.String("model", model))
 		}
+		// 并发槽位获取路径与排队耗时（仅网关请求由 handler 写入），用于区分「排队慢」与「上游慢」
+		if slotPath := c.GetString(service.OpsSlotAcquirePathKey); slotPath != "" {
+			fields = append(fields,
+				zap.String("slot_acquire_path", slotPath),
+				zap.Int64("user_slot_wait_ms", c.GetInt64(service.OpsUserSlotWaitMsKey)),
+				zap.Int64("account_slot_wait_ms", c.GetInt64(service.OpsAccountSlotWaitMsKey)),
+			)
+		}
+
+		if c.GetBool(service.OpsInstructionsInjectedKey) {
+			fields = append(fields, zap.Bool("instructions_injected", true))
+		}
 
 		l := logger.FromContext(c.Request.Context()).With(fields...)
 		l.Info("http request completed", zap.Time("completed_at", endTime))
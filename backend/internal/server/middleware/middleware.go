@@ -24,6 +24,8 @@ const (
 	ContextKeySubscription ContextKey = "subscription"
 	// ContextKeyForcePlatform 强制平台（用于 /antigravity 路由）
 	ContextKeyForcePlatform ContextKey = "force_platform"
+	// ContextKeyTraceID 请求 trace ID（string，与 ctxkey.RequestID 同值），由 RequestLogger 设置
+	ContextKeyTraceID ContextKey = "trace_id"
 	// ContextKeyOpsFallbackAPIKey 运维错误日志专用回退键。
 	// 鉴权早退（分组停用/删除、Key 停用/过期/额度、用户停用、IP 限制等）时，
 	// apiKey 已加载但尚未写入 ContextKeyAPIKey；该键让 Ops 错误日志仍能取到
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitHeaders 按系统设置改写网关响应中的限流头。
+// 默认透传上游账号的限流头（anthropic-ratelimit-*、x-ratelimit-* 等，反映共享账号的整体用量）；
+// 开启「限流头按 API Key 改写」后，在响应头写出前剥离上游限流头，改为当前 Key 的额度与限速配置：
+//
+//	x-ratelimit-limit-usd / x-ratelimit-remaining-usd   Key 总额度（未设置额度时不输出）
+//	x-ratelimit-limit-usd-5h / -1d / -7d                Key 各窗口限速（未设置的窗口不输出）
+//
+// 需在 API Key 认证中间件之后使用；流式响应同样在首次写出前完成改写。
+func RateLimitHeaders(settingService *service.SettingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if settingService == nil || !settingService.IsRateLimitHeadersPerKeyEnabled(c.Request.Context()) {
+			c.Next()
+			return
+		}
+		original := c.Writer
+		c.Writer = &perKeyRateLimitHeaderWriter{ResponseWriter: original, c: c}
+		defer func() { c.Writer = original }()
+		c.Next()
+	}
+}
+
+// perKeyRateLimitHeaderWriter 在响应头提交前（首次 Write/Flush）替换限流头
+type perKeyRateLimitHeaderWriter struct {
+	gin.ResponseWriter
+	c         *gin.Context
+	rewritten bool
+}
+
+func (w *perKeyRateLimitHeaderWriter) rewriteHeaders() {
+	if w.rewritten {
+		return
+	}
+	w.rewritten = true
+	header := w.ResponseWriter.Header()
+	for name := range header {
+		if responseheaders.IsRateLimitHeader(name) {
+			header.Del(name)
+		}
+	}
+	if apiKey, ok := GetAPIKeyFromContext(w.c); ok {
+		setPerKeyRateLimitHeaders(header, apiKey)
+	}
+}
+
+func (w *perKeyRateLimitHeaderWriter) WriteHeaderNow() {
+	w.rewriteHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *perKeyRateLimitHeaderWriter) Write(b []byte) (int, error) {
+	w.rewriteHeaders()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *perKeyRateLimitHeaderWriter) WriteString(s string) (int, error) {
+	w.rewriteHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *perKeyRateLimitHeaderWriter) Flush() {
+	w.rewriteHeaders()
+	w.ResponseWriter.Flush()
+}
+
+// setPerKeyRateLimitHeaders 写入 Key 级限流头；剩余额度取自认证时的 Key 快照
+func setPerKeyRateLimitHeaders(header http.Header, apiKey *service.APIKey) {
+	formatUSD := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	if apiKey.Quota > 0 {
+		header.Set("x-ratelimit-limit-usd", formatUSD(apiKey.Quota))
+		header.Set("x-ratelimit-remaining-usd", formatUSD(apiKey.GetQuotaRemaining()))
+	}
+	windows := []struct {
+		suffix string
+		limit  float64
+	}{
+		{"5h", apiKey.RateLimit5h},
+		{"1d", apiKey.RateLimit1d},
+		{"7d", apiKey.RateLimit7d},
+	}
+	for _, window := range windows {
+		if window.limit > 0 {
+			header.Set("x-ratelimit-limit-usd-"+window.suffix, formatUSD(window.limit))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitHeaderSettingService(t *testing.T, perKey bool) *service.SettingService {
+	t.Helper()
+	svc := service.NewSettingService(&bmSettingRepo{}, &config.Config{})
+	require.NoError(t, svc.UpdateSettings(context.Background(), &service.SystemSettings{
+		RateLimitHeadersPerKey: perKey,
+	}))
+	return svc
+}
+
+// newRateLimitHeaderTestRouter 模拟转发器：按 anthropic 平台白名单复制上游响应头后写出流式/非流式响应
+func newRateLimitHeaderTestRouter(settingService *service.SettingService, apiKey *service.APIKey) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	upstream := http.Header{}
+	upstream.Set("Content-Type", "text/event-stream")
+	upstream.Set("Anthropic-Ratelimit-Requests-Remaining", "4999")
+	upstream.Set("Anthropic-Ratelimit-Tokens-Limit", "400000")
+	upstream.Set("Set-Cookie", "upstream_session=abc")
+	upstream.Set("X-Upstream-Internal", "nope")
+	filter := responseheaders.CompileHeaderFilterForPlatform(config.ResponseHeaderConfig{Enabled: true}, service.PlatformAnthropic)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.Use(RateLimitHeaders(settingService))
+	r.POST("/stream", func(c *gin.Context) {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), upstream, filter)
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("data: {}\n\n")
+		c.Writer.Flush()
+	})
+	r.POST("/json", func(c *gin.Context) {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), upstream, filter)
+		c.Data(http.StatusOK, "application/json", []byte(`{}`))
+	})
+	return r
+}
+
+func TestRateLimitHeaders_UpstreamMode(t *testing.T) {
+	router := newRateLimitHeaderTestRouter(newRateLimitHeaderSettingService(t, false), &service.APIKey{Quota: 10})
+
+	for _, path := range []string{"/stream", "/json"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, "path=%s", path)
+		require.Equal(t, "4999", w.Header().Get("Anthropic-Ratelimit-Requests-Remaining"), "path=%s", path)
+		require.Equal(t, "400000", w.Header().Get("Anthropic-Ratelimit-Tokens-Limit"), "path=%s", path)
+		require.Empty(t, w.Header().Get("Set-Cookie"), "path=%s", path)
+		require.Empty(t, w.Header().Get("X-Upstream-Internal"), "path=%s", path)
+		require.Empty(t, w.Header().Get("X-Ratelimit-Limit-Usd"), "path=%s", path)
+	}
+}
+
+func TestRateLimitHeaders_PerKeyMode(t *testing.T) {
+	apiKey := &service.APIKey{Quota: 10, QuotaUsed: 2.5, RateLimit5h: 3, RateLimit7d: 50}
+	router := newRateLimitHeaderTestRouter(newRateLimitHeaderSettingService(t, true), apiKey)
+
+	for _, path := range []string{"/stream", "/json"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, "path=%s", path)
+		require.Empty(t, w.Header().Get("Anthropic-Ratelimit-Requests-Remaining"), "path=%s", path)
+		require.Empty(t, w.Header().Get("Anthropic-Ratelimit-Tokens-Limit"), "path=%s", path)
+		require.Empty(t, w.Header().Get("Set-Cookie"), "path=%s", path)
+		require.Empty(t, w.Header().Get("X-Upstream-Internal"), "path=%s", path)
+		require.Equal(t, "10", w.Header().Get("X-Ratelimit-Limit-Usd"), "path=%s", path)
+		require.Equal(t, "7.5", w.Header().Get("X-Ratelimit-Remaining-Usd"), "path=%s", path)
+		require.Equal(t, "3", w.Header().Get("X-Ratelimit-Limit-Usd-5h"), "path=%s", path)
+		require.Empty(t, w.Header().Get("X-Ratelimit-Limit-Usd-1d"), "path=%s", path)
+		require.Equal(t, "50", w.Header().Get("X-Ratelimit-Limit-Usd-7d"), "path=%s", path)
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -112,6 +114,50 @@ func TestRequestLogger_KeepIncomingRequestID(t *testing.T) {
 	}
 }
 
+func TestRequestLogger_ExposesTraceID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger())
+	r.GET("/t", func(c *gin.Context) {
+		traceID := c.GetString(string(ContextKeyTraceID))
+		if traceID != "rid-fixed" {
+			t.Fatalf("gin trace_id=%q, want rid-fixed", traceID)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set(requestIDHeader, "rid-fixed")
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(service.TraceIDHeader); got != "rid-fixed" {
+		t.Fatalf("X-Trace-ID=%q, want rid-fixed", got)
+	}
+}
+
+func TestRequestLogger_RejectsUnsafeIncomingRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger())
+	r.GET("/t", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, incoming := range []string{"rid with space", "rid\"quote", strings.Repeat("a", maxIncomingRequestIDLen+1)} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set(requestIDHeader, incoming)
+		r.ServeHTTP(w, req)
+		got := w.Header().Get(requestIDHeader)
+		if got == "" || got == incoming {
+			t.Fatalf("incoming %q should be replaced, got %q", incoming, got)
+		}
+		if w.Header().Get(service.TraceIDHeader) != got {
+			t.Fatalf("X-Trace-ID should match regenerated request id")
+		}
+	}
+}
+
 func TestLogger_AccessLogIncludesCoreFields(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	sink := initMiddlewareTestLogger(t)
@@ -211,6 +257,40 @@ func TestLogger_AccessLogUsesForwardedClientIP(t *testing.T) {
 	t.Fatalf("access log event not found")
 }
 
+func TestLogger_AccessLogIncludesSlotAcquireFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := initMiddlewareTestLogger(t)
+
+	r := gin.New()
+	r.Use(Logger())
+	r.POST("/v1/messages", func(c *gin.Context) {
+		service.SetOpsLatencyMs(c, service.OpsUserSlotWaitMsKey, 0)
+		service.SetOpsLatencyMs(c, service.OpsAccountSlotWaitMsKey, 1500)
+		c.Set(service.OpsSlotAcquirePathKey, "user=fast,account=wait")
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	for _, event := range sink.list() {
+		if event == nil || event.Message != "http request completed" {
+			continue
+		}
+		if got := event.Fields["slot_acquire_path"]; got != "user=fast,account=wait" {
+			t.Fatalf("slot_acquire_path=%v", got)
+		}
+		if got := fmt.Sprint(event.Fields["account_slot_wait_ms"]); got != "1500" {
+			t.Fatalf("account_slot_wait_ms=%v", got)
+		}
+		if got := fmt.Sprint(event.Fields["user_slot_wait_ms"]); got != "0" {
+			t.Fatalf("user_slot_wait_ms=%v", got)
+		}
+		return
+	}
+	t.Fatalf("access log event not found")
+}
+
 func TestLogger_HealthPathSkipped(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	sink := initMiddlewareTestLogger(t)
@@ -6,14 +6,22 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
-const requestIDHeader = "X-Request-ID"
+const (
+	requestIDHeader = "X-Request-ID"
+	// maxIncomingRequestIDLen 客户端透传 X-Request-ID 的最大长度（与 ops 表 request_id 列一致），超出或含非法字符时重新生成
+	maxIncomingRequestIDLen = 64
+)
 
 // RequestLogger 在请求入口注入 request-scoped logger。
+//
+// request_id 同时作为全链路 trace ID：优先沿用客户端 X-Request-ID，
+// 写入 request.Context、gin.Context 与日志字段，并通过 X-Request-ID / X-Trace-ID 响应头返回。
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request == nil {
@@ -21,11 +29,13 @@ func RequestLogger() gin.HandlerFunc {
 			return
 		}
 
-		requestID := strings.TrimSpace(c.GetHeader(requestIDHeader))
+		requestID := sanitizeIncomingRequestID(c.GetHeader(requestIDHeader))
 		if requestID == "" {
 			requestID = uuid.NewString()
 		}
 		c.Header(requestIDHeader, requestID)
+		c.Header(service.TraceIDHeader, requestID)
+		c.Set(string(ContextKeyTraceID), requestID)
 
 		ctx := context.WithValue(c.Request.Context(), ctxkey.RequestID, requestID)
 		clientRequestID, _ := ctx.Value(ctxkey.ClientRequestID).(string)
@@ -43,3 +53,22 @@ func RequestLogger() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// sanitizeIncomingRequestID 校验客户端传入的 request ID：
+// 该值会写入日志、上游请求头与 Ops 记录，仅接受长度受限的 [A-Za-z0-9._:-] 字符。
+func sanitizeIncomingRequestID(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len(raw) > maxIncomingRequestIDLen {
+		return ""
+	}
+	for i := 0; i < len(raw); i++ {
+		ch := raw[i]
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return ""
+		}
+	}
+	return raw
+}
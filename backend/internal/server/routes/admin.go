@@ -106,6 +106,9 @@ func RegisterAdminRoutes(
 
 		// 邀请返利（专属用户管理）
 		registerAffiliateRoutes(admin, h)
+
+		// 管理操作审计日志
+		registerAuditLogRoutes(admin, h)
 	}
 }
 
@@ -131,10 +134,18 @@ func registerContentModerationRoutes(admin *gin.RouterGroup, h *handler.Handlers
 	}
 }
 
+func registerAuditLogRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	admin.GET("/audit", h.Admin.AuditLog.List)
+}
+
 func registerAdminAPIKeyRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	apiKeys := admin.Group("/api-keys")
 	{
 		apiKeys.PUT("/:id", h.Admin.APIKey.UpdateGroup)
+		apiKeys.GET("/:id/capture", h.Admin.APIKeyCapture.GetStatus)
+		apiKeys.POST("/:id/capture", h.Admin.APIKeyCapture.Enable)
+		apiKeys.DELETE("/:id/capture", h.Admin.APIKeyCapture.Disable)
+		apiKeys.GET("/:id/captures", h.Admin.APIKeyCapture.ListCaptures)
 	}
 }
 
@@ -146,6 +157,7 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/user-concurrency", h.Admin.Ops.GetUserConcurrencyStats)
 		ops.GET("/account-availability", h.Admin.Ops.GetAccountAvailability)
 		ops.GET("/realtime-traffic", h.Admin.Ops.GetRealtimeTrafficSummary)
+		ops.GET("/ttft-percentiles", h.Admin.Ops.GetTTFTPercentiles)
 
 		// Alerts (rules + events)
 		ops.GET("/alert-rules", h.Admin.Ops.ListAlertRules)
@@ -169,6 +181,9 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 			runtime.GET("/logging", h.Admin.Ops.GetRuntimeLogConfig)
 			runtime.PUT("/logging", h.Admin.Ops.UpdateRuntimeLogConfig)
 			runtime.POST("/logging/reset", h.Admin.Ops.ResetRuntimeLogConfig)
+			runtime.GET("/logging/components", h.Admin.Ops.ListLogComponentLevels)
+			runtime.PUT("/logging/components", h.Admin.Ops.UpdateLogComponentLevel)
+			runtime.POST("/logging/components/:component/reset", h.Admin.Ops.ResetLogComponentLevel)
 		}
 
 		// Advanced settings (DB-backed)
@@ -220,6 +235,8 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/dashboard/error-trend", h.Admin.Ops.GetDashboardErrorTrend)
 		ops.GET("/dashboard/error-distribution", h.Admin.Ops.GetDashboardErrorDistribution)
 		ops.GET("/dashboard/openai-token-stats", h.Admin.Ops.GetDashboardOpenAITokenStats)
+		ops.GET("/dashboard/dimension-metrics", h.Admin.Ops.GetDashboardDimensionMetrics)
+		ops.GET("/dashboard/top-offenders", h.Admin.Ops.GetDashboardTopOffenders)
 	}
 }
 
@@ -287,6 +304,12 @@ func registerGroupRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		groups.DELETE("/:id/rate-multipliers", h.Admin.Group.ClearGroupRateMultipliers)
 		groups.PUT("/:id/rpm-overrides", h.Admin.Group.BatchSetGroupRPMOverrides)
 		groups.DELETE("/:id/rpm-overrides", h.Admin.Group.ClearGroupRPMOverrides)
+		groups.GET("/:id/spend-cap", h.Admin.Group.GetSpendCap)
+		groups.POST("/:id/spend-cap/override", h.Admin.Group.GrantSpendCapOverride)
+		groups.DELETE("/:id/spend-cap/override", h.Admin.Group.RevokeSpendCapOverride)
+		groups.GET("/:id/instruction-injection", h.Admin.Group.GetInstructionInjection)
+		groups.PUT("/:id/instruction-injection", h.Admin.Group.SetInstructionInjection)
+		groups.DELETE("/:id/instruction-injection", h.Admin.Group.ClearInstructionInjection)
 		groups.GET("/:id/api-keys", h.Admin.Group.GetGroupAPIKeys)
 	}
 }
@@ -326,11 +349,14 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		accounts.POST("/batch", h.Admin.Account.BatchCreate)
 		accounts.GET("/data", h.Admin.Account.ExportData)
 		accounts.POST("/data", h.Admin.Account.ImportData)
+		accounts.POST("/data/encrypted-export", h.Admin.Account.ExportEncryptedData)
+		accounts.POST("/data/encrypted-import", h.Admin.Account.ImportEncryptedData)
 		accounts.POST("/batch-update-credentials", h.Admin.Account.BatchUpdateCredentials)
 		accounts.POST("/batch-refresh-tier", h.Admin.Account.BatchRefreshTier)
 		accounts.POST("/bulk-update", h.Admin.Account.BulkUpdate)
 		accounts.POST("/batch-clear-error", h.Admin.Account.BatchClearError)
 		accounts.POST("/batch-refresh", h.Admin.Account.BatchRefresh)
+		accounts.GET("/health", h.Admin.Account.ListHealth)
 
 		// Antigravity 默认模型映射
 		accounts.GET("/antigravity/default-model-mapping", h.Admin.Account.GetAntigravityDefaultModelMapping)
@@ -575,6 +601,7 @@ func registerUsageRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		usage.GET("/stats", h.Admin.Usage.Stats)
 		usage.GET("/search-users", h.Admin.Usage.SearchUsers)
 		usage.GET("/search-api-keys", h.Admin.Usage.SearchAPIKeys)
+		usage.GET("/retry-dead-letters", h.Admin.Usage.ListRetryDeadLetters)
 		usage.GET("/cleanup-tasks", h.Admin.Usage.ListCleanupTasks)
 		usage.POST("/cleanup-tasks", h.Admin.Usage.CreateCleanupTask)
 		usage.POST("/cleanup-tasks/:id/cancel", h.Admin.Usage.CancelCleanupTask)
@@ -613,6 +640,8 @@ func registerErrorPassthroughRoutes(admin *gin.RouterGroup, h *handler.Handlers)
 		rules.POST("", h.Admin.ErrorPassthrough.Create)
 		rules.PUT("/:id", h.Admin.ErrorPassthrough.Update)
 		rules.DELETE("/:id", h.Admin.ErrorPassthrough.Delete)
+		rules.POST("/dry-run", h.Admin.ErrorPassthrough.DryRun)
+		rules.POST("/reload", h.Admin.ErrorPassthrough.Reload)
 	}
 }
 
@@ -15,13 +15,16 @@ import (
 )
 
 func newGatewayRoutesTestRouter(platform ...string) *gin.Engine {
-	gin.SetMode(gin.TestMode)
-	router := gin.New()
-
 	groupPlatform := service.PlatformOpenAI
 	if len(platform) > 0 && platform[0] != "" {
 		groupPlatform = platform[0]
 	}
+	return newGatewayRoutesTestRouterWithKey(&service.APIKey{Group: &service.Group{Platform: groupPlatform}})
+}
+
+func newGatewayRoutesTestRouterWithKey(apiKey *service.APIKey) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
 
 	RegisterGatewayRoutes(
 		router,
@@ -31,10 +34,9 @@ func newGatewayRoutesTestRouter(platform ...string) *gin.Engine {
 		},
 		servermiddleware.APIKeyAuthMiddleware(func(c *gin.Context) {
 			groupID := int64(1)
-			c.Set(string(servermiddleware.ContextKeyAPIKey), &service.APIKey{
-				GroupID: &groupID,
-				Group:   &service.Group{Platform: groupPlatform},
-			})
+			key := *apiKey
+			key.GroupID = &groupID
+			c.Set(string(servermiddleware.ContextKeyAPIKey), &key)
 			c.Next()
 		}),
 		nil,
@@ -119,3 +121,41 @@ func TestGatewayRoutesGrokOnlyAllowsResponsesHTTP(t *testing.T) {
 		require.NotEqual(t, http.StatusNotFound, w.Code, "path=%s should still reach Responses handler", path)
 	}
 }
+
+func TestGatewayRoutesEnforceAPIKeyScopes(t *testing.T) {
+	router := newGatewayRoutesTestRouterWithKey(&service.APIKey{
+		Group:  &service.Group{Platform: service.PlatformOpenAI},
+		Scopes: []string{service.APIKeyScopeImages},
+	})
+
+	for _, path := range []string{
+		"/v1/messages",
+		"/v1/chat/completions",
+		"/chat/completions",
+		"/v1/responses",
+		"/responses",
+		"/backend-api/codex/responses",
+		"/v1/embeddings",
+		"/embeddings",
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"model":"gpt-5"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code, "path=%s", path)
+		require.Contains(t, w.Body.String(), "key scopes: images", "path=%s", path)
+	}
+
+	for _, path := range []string{
+		"/v1/images/generations",
+		"/images/edits",
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"model":"gpt-image-2","prompt":"draw a cat"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		require.NotEqual(t, http.StatusForbidden, w.Code, "path=%s should reach the images handler", path)
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
+)
+
+const (
+	// shutdownAbortWait 宽限期结束、中断在途请求后，留给 handler 写出终止事件并释放并发槽位的时间
+	shutdownAbortWait = 5 * time.Second
+
+	// shutdownRetryAfterSeconds 停机排空期间拒绝新请求时返回的 Retry-After（秒），
+	// 负载均衡或客户端 SDK 据此切换到其他实例重试
+	shutdownRetryAfterSeconds = 5
+
+	shutdownRejectMessage = "Server is shutting down, please retry"
+)
+
+// RequestDrainer 为 HTTP 服务器的所有请求提供统一的基础 context。
+// 优雅停机宽限期结束后以 handler.ErrServerShuttingDown 取消该 context，
+// 仍在处理的请求随之中断：上游请求被取消，并发槽位经 wrapReleaseOnDone 释放，
+// 流式响应由 handler 写出 SSE 错误事件。
+//
+// 进入排空状态后，经 Guard 到达的新请求直接返回 503 + Retry-After，
+// 避免 keep-alive / HTTP/2 连接上的新请求在停机过程中被半途截断。
+type RequestDrainer struct {
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	draining atomic.Bool
+}
+
+// NewRequestDrainer 创建请求排空控制器
+func NewRequestDrainer() *RequestDrainer {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &RequestDrainer{ctx: ctx, cancel: cancel}
+}
+
+// BaseContext 用作 http.Server.BaseContext
+func (d *RequestDrainer) BaseContext(net.Listener) context.Context {
+	return d.ctx
+}
+
+// Abort 中断所有在途请求
+func (d *RequestDrainer) Abort() {
+	d.cancel(handler.ErrServerShuttingDown)
+}
+
+// BeginDrain 进入排空状态：此后经 Guard 的新请求一律拒绝
+func (d *RequestDrainer) BeginDrain() {
+	d.draining.Store(true)
+}
+
+// Draining 是否处于排空状态
+func (d *RequestDrainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// Guard 包装 HTTP handler：排空期间拒绝新请求（503 + Retry-After + Connection: close）
+func (d *RequestDrainer) Guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.Draining() {
+			w.Header().Set("Retry-After", strconv.Itoa(shutdownRetryAfterSeconds))
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write(drainingErrorBody(r.URL.Path))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// drainingErrorBody 按请求路径对应的协议构造 503 错误体：
+// Gemini 原生路由使用 Google 格式，OpenAI 兼容路由使用 OpenAI 格式，其余使用 Anthropic 格式。
+func drainingErrorBody(path string) []byte {
+	var body any
+	switch {
+	case strings.Contains(path, "/v1beta/"):
+		body = map[string]any{"error": map[string]any{
+			"code":    http.StatusServiceUnavailable,
+			"message": shutdownRejectMessage,
+			"status":  googleapi.HTTPStatusToGoogleStatus(http.StatusServiceUnavailable),
+		}}
+	case isOpenAIProtocolPath(path):
+		body = map[string]any{"error": map[string]any{
+			"type":    "api_error",
+			"message": shutdownRejectMessage,
+		}}
+	default:
+		body = map[string]any{"type": "error", "error": map[string]any{
+			"type":    "overloaded_error",
+			"message": shutdownRejectMessage,
+		}}
+	}
+	encoded, _ := json.Marshal(body)
+	return encoded
+}
+
+func isOpenAIProtocolPath(path string) bool {
+	for _, segment := range []string{"/chat/completions", "/responses", "/embeddings", "/images/"} {
+		if strings.Contains(path, segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// GracefulShutdown 停止接收新请求并等待在途请求在 grace 内完成；
+// 超时后通过 drainer 中断剩余请求，再等待 shutdownAbortWait 后强制关闭连接。
+// 返回前归还仍登记在册的并发槽位与等待计数，避免强制关闭后 Redis 计数残留。
+func GracefulShutdown(srv *http.Server, drainer *RequestDrainer, grace time.Duration) error {
+	if grace < 0 {
+		grace = 0
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), grace+shutdownAbortWait)
+	defer cancel()
+	defer func() {
+		if n := handler.ReleaseLiveConcurrency(); n > 0 {
+			log.Printf("Released %d concurrency slot(s)/wait counter(s) held by aborted requests", n)
+		}
+	}()
+
+	if drainer != nil {
+		drainer.BeginDrain()
+		timer := time.AfterFunc(grace, func() {
+			log.Printf("Shutdown grace period (%s) elapsed, aborting in-flight requests", grace)
+			drainer.Abort()
+		})
+		defer timer.Stop()
+	}
+
+	if err := srv.Shutdown(ctx); err != nil {
+		_ = srv.Close()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+//go:build unit
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler"
+	"github.com/Wei-Shaw/sub2api/internal/repository"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func startDrainTestServer(t *testing.T, h http.HandlerFunc) (*http.Server, *RequestDrainer, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	drainer := NewRequestDrainer()
+	srv := &http.Server{Handler: h, BaseContext: drainer.BaseContext}
+	go func() { _ = srv.Serve(ln) }()
+	return srv, drainer, "http://" + ln.Addr().String()
+}
+
+func TestGracefulShutdown_AbortsStreamsAfterGrace(t *testing.T) {
+	started := make(chan struct{})
+	srv, drainer, url := startDrainTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		close(started)
+		<-r.Context().Done()
+		if errors.Is(context.Cause(r.Context()), handler.ErrServerShuttingDown) {
+			_, _ = io.WriteString(w, "event: error\ndata: shutting down\n\n")
+		}
+	})
+
+	bodyCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			bodyCh <- err.Error()
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		bodyCh <- string(body)
+	}()
+	<-started
+
+	begin := time.Now()
+	require.NoError(t, GracefulShutdown(srv, drainer, 50*time.Millisecond))
+	require.GreaterOrEqual(t, time.Since(begin), 50*time.Millisecond)
+	require.Less(t, time.Since(begin), shutdownAbortWait)
+
+	body := <-bodyCh
+	require.Contains(t, body, "data: first")
+	require.Contains(t, body, "event: error")
+}
+
+func TestGracefulShutdown_WaitsForInFlightWithinGrace(t *testing.T) {
+	started := make(chan struct{})
+	srv, drainer, url := startDrainTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-time.After(50 * time.Millisecond):
+			_, _ = io.WriteString(w, "done")
+		case <-r.Context().Done():
+		}
+	})
+
+	bodyCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			bodyCh <- err.Error()
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		bodyCh <- string(body)
+	}()
+	<-started
+
+	require.NoError(t, GracefulShutdown(srv, drainer, 5*time.Second))
+	require.Equal(t, "done", <-bodyCh)
+	require.NoError(t, drainer.ctx.Err(), "请求在宽限期内完成时不应中断")
+}
+
+func TestRequestDrainerGuard_RejectsNewRequestsWhileDraining(t *testing.T) {
+	drainer := NewRequestDrainer()
+	guarded := drainer.Guard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	rec := httptest.NewRecorder()
+	guarded.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	drainer.BeginDrain()
+	rec = httptest.NewRecorder()
+	guarded.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Equal(t, "5", rec.Header().Get("Retry-After"))
+	require.Contains(t, rec.Body.String(), "shutting down")
+}
+
+func TestRequestDrainerGuard_ErrorBodyFollowsRouteProtocol(t *testing.T) {
+	drainer := NewRequestDrainer()
+	guarded := drainer.Guard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	drainer.BeginDrain()
+
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{path: "/v1/messages", want: `{"type":"error","error":{"type":"overloaded_error","message":"Server is shutting down, please retry"}}`},
+		{path: "/v1/chat/completions", want: `{"error":{"type":"api_error","message":"Server is shutting down, please retry"}}`},
+		{path: "/openai/v1/responses", want: `{"error":{"type":"api_error","message":"Server is shutting down, please retry"}}`},
+		{path: "/v1/images/generations", want: `{"error":{"type":"api_error","message":"Server is shutting down, please retry"}}`},
+		{path: "/v1beta/models/gemini-2.5-pro:generateContent", want: `{"error":{"code":503,"message":"Server is shutting down, please retry","status":"INTERNAL"}}`},
+	} {
+		rec := httptest.NewRecorder()
+		guarded.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code, tc.path)
+		require.JSONEq(t, tc.want, rec.Body.String(), tc.path)
+	}
+}
+
+func newDrainConcurrencyFixture(t *testing.T) (*handler.ConcurrencyHelper, service.ConcurrencyCache) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cache := repository.NewConcurrencyCache(rdb, 5, 60)
+	return handler.NewConcurrencyHelper(service.NewConcurrencyService(cache), handler.SSEPingFormatNone, time.Second), cache
+}
+
+func TestGracefulShutdown_SlowStreamCompletesAndCountersReturnToZero(t *testing.T) {
+	const userID, accountID = int64(11), int64(22)
+	helper, cache := newDrainConcurrencyFixture(t)
+
+	started := make(chan struct{})
+	srv, drainer, url := startDrainTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		release, ok, err := helper.TryAcquireUserSlot(r.Context(), userID, 1)
+		if err != nil || !ok {
+			http.Error(w, "slot", http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+		if ok, _ := helper.IncrementAccountWaitCount(r.Context(), accountID, 10); ok {
+			defer helper.DecrementAccountWaitCount(context.Background(), accountID)
+		}
+		close(started)
+		for i := 0; i < 3; i++ {
+			_, _ = io.WriteString(w, "data: chunk\n\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(30 * time.Millisecond)
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	})
+
+	bodyCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			bodyCh <- err.Error()
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		bodyCh <- string(body)
+	}()
+	<-started
+
+	ctx := context.Background()
+	inflight, err := cache.GetUserConcurrency(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, 1, inflight)
+
+	require.NoError(t, GracefulShutdown(srv, drainer, 2*time.Second))
+	require.True(t, drainer.Draining())
+	require.Contains(t, <-bodyCh, "data: [DONE]")
+
+	inflight, err = cache.GetUserConcurrency(ctx, userID)
+	require.NoError(t, err)
+	require.Zero(t, inflight)
+	waiting, err := cache.GetAccountWaitingCount(ctx, accountID)
+	require.NoError(t, err)
+	require.Zero(t, waiting)
+}
+
+func TestGracefulShutdown_ForcedCloseReleasesWaitCounters(t *testing.T) {
+	const accountID = int64(33)
+	helper, cache := newDrainConcurrencyFixture(t)
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	done := make(chan struct{})
+	srv, drainer, url := startDrainTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		ok, _ := helper.IncrementAccountWaitCount(r.Context(), accountID, 10)
+		if !ok {
+			return
+		}
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		close(started)
+		// 模拟不响应取消的上游调用：handler 在强制关闭之后才返回
+		<-unblock
+		helper.DecrementAccountWaitCount(context.Background(), accountID)
+	})
+
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx := context.Background()
+	waiting, err := cache.GetAccountWaitingCount(ctx, accountID)
+	require.NoError(t, err)
+	require.Equal(t, 1, waiting)
+
+	require.Error(t, GracefulShutdown(srv, drainer, 10*time.Millisecond))
+
+	waiting, err = cache.GetAccountWaitingCount(ctx, accountID)
+	require.NoError(t, err)
+	require.Zero(t, waiting, "强制关闭后应归还等待计数")
+
+	close(unblock)
+	<-done
+	waiting, err = cache.GetAccountWaitingCount(ctx, accountID)
+	require.NoError(t, err)
+	require.Zero(t, waiting, "迟到的递减不应重复执行")
+}
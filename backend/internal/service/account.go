@@ -67,6 +67,13 @@ type Account struct {
 	modelMappingCacheRawPtr         uintptr
 	modelMappingCacheRawLen         int
 	modelMappingCacheRawSig         uint64
+
+	// schedule_windows 热路径缓存（非持久化字段）：按 extra 与原始值的身份失效
+	scheduleWindowsCache         []AccountScheduleWindow
+	scheduleWindowsCacheReady    bool
+	scheduleWindowsCacheExtraPtr uintptr
+	scheduleWindowsCacheRawPtr   uintptr
+	scheduleWindowsCacheRawLen   int
 }
 
 type OpenAIEndpointCapability string
@@ -151,6 +158,9 @@ func (a *Account) IsSchedulable() bool {
 	if a.IsAPIKeyOrBedrock() && a.IsQuotaExceeded() {
 		return false
 	}
+	if !a.IsWithinSchedule(now) {
+		return false
+	}
 	return true
 }
 
@@ -2327,6 +2337,26 @@ func (a *Account) GetMaxSessions() int {
 	return 0
 }
 
+// GetRoutingWeight 获取加权选号时的账号权重（extra.routing_weight）
+// 取值 1-100，未设置或非法时默认 50；超出范围时截断到边界
+func (a *Account) GetRoutingWeight() int {
+	if a == nil || a.Extra == nil {
+		return defaultAccountRoutingWeight
+	}
+	v, ok := a.Extra[accountRoutingWeightExtraKey]
+	if !ok {
+		return defaultAccountRoutingWeight
+	}
+	weight := parseExtraInt(v)
+	switch {
+	case weight <= 0:
+		return defaultAccountRoutingWeight
+	case weight > maxAccountRoutingWeight:
+		return maxAccountRoutingWeight
+	}
+	return weight
+}
+
 // GetSessionIdleTimeoutMinutes 获取会话空闲超时分钟数
 // 默认值为 5 分钟
 func (a *Account) GetSessionIdleTimeoutMinutes() int {
@@ -0,0 +1,599 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"github.com/google/uuid"
+)
+
+const (
+	AccountHealthProbeModels      = "models"
+	AccountHealthProbeCountTokens = "count_tokens"
+	AccountHealthProbeNone        = "none"
+
+	accountHealthProbeLeaderLockKey = "account:health_probe:leader"
+	// accountHealthStaleIntervals 超过多少个探测周期未更新的结果视为过期，不再参与选号
+	accountHealthStaleIntervals = 3
+	// accountHealthErrorMaxLen 记录的错误信息最大长度
+	accountHealthErrorMaxLen = 240
+)
+
+// defaultAccountHealthProbes 各平台内置的探测方式（可被 account_health_probe.probes 覆盖）。
+// 仅使用不消耗用户可见配额的接口：模型列表、countTokens、ChatGPT 用量查询。
+var defaultAccountHealthProbes = map[string]string{
+	PlatformAnthropic:   AccountHealthProbeModels,
+	PlatformOpenAI:      AccountHealthProbeModels,
+	PlatformGemini:      AccountHealthProbeCountTokens,
+	PlatformAntigravity: AccountHealthProbeNone,
+	PlatformGrok:        AccountHealthProbeNone,
+}
+
+// AccountHealthStatus 单个账号最近一次主动探测的结果
+type AccountHealthStatus struct {
+	AccountID           int64      `json:"account_id"`
+	Platform            string     `json:"platform"`
+	ProbeType           string     `json:"probe_type"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LatencyMs           int64      `json:"latency_ms"`
+	StatusCode          int        `json:"status_code,omitempty"`
+	Error               string     `json:"error,omitempty"`
+	CheckedAt           time.Time  `json:"checked_at"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+}
+
+// AccountHealthCache 账号健康探测结果存储（Redis），供多实例共享
+type AccountHealthCache interface {
+	SaveAccountHealth(ctx context.Context, statuses []AccountHealthStatus, ttl time.Duration) error
+	ListAccountHealth(ctx context.Context) ([]AccountHealthStatus, error)
+	DeleteAccountHealth(ctx context.Context, accountIDs []int64) error
+}
+
+// AccountHealthProbeService 定期对启用的账号做低成本的鉴权探测。
+// 探测由单个 leader 实例执行并写入共享缓存；所有实例从缓存刷新本地快照，
+// 连续失败达到阈值的账号在选号时降权，探测恢复成功后自动回到正常。
+type AccountHealthProbeService struct {
+	accountRepo         AccountRepository
+	cache               AccountHealthCache
+	httpUpstream        HTTPUpstream
+	geminiTokenProvider *GeminiTokenProvider
+	tlsFPProfileService *TLSFingerprintProfileService
+	cfg                 *config.Config
+	probeCfg            config.AccountHealthProbeConfig
+
+	mu       sync.RWMutex
+	statuses map[int64]AccountHealthStatus
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+// NewAccountHealthProbeService 创建账号健康探测服务
+func NewAccountHealthProbeService(
+	accountRepo AccountRepository,
+	cache AccountHealthCache,
+	httpUpstream HTTPUpstream,
+	geminiTokenProvider *GeminiTokenProvider,
+	tlsFPProfileService *TLSFingerprintProfileService,
+	cfg *config.Config,
+) *AccountHealthProbeService {
+	svc := &AccountHealthProbeService{
+		accountRepo:         accountRepo,
+		cache:               cache,
+		httpUpstream:        httpUpstream,
+		geminiTokenProvider: geminiTokenProvider,
+		tlsFPProfileService: tlsFPProfileService,
+		cfg:                 cfg,
+		statuses:            make(map[int64]AccountHealthStatus),
+		stopCh:              make(chan struct{}),
+		instanceID:          uuid.NewString(),
+		now:                 time.Now,
+	}
+	if cfg != nil {
+		svc.probeCfg = cfg.AccountHealthProbe
+	}
+	return svc
+}
+
+// SetLeaderLock 注入 leader 锁，多实例部署时只有一个实例执行探测
+func (s *AccountHealthProbeService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Enabled 是否启用主动健康探测
+func (s *AccountHealthProbeService) Enabled() bool {
+	return s != nil && s.probeCfg.Enabled && s.probeCfg.IntervalSeconds > 0
+}
+
+func (s *AccountHealthProbeService) interval() time.Duration {
+	return time.Duration(s.probeCfg.IntervalSeconds) * time.Second
+}
+
+// Start 启动后台探测循环
+func (s *AccountHealthProbeService) Start() {
+	if !s.Enabled() || s.accountRepo == nil || s.httpUpstream == nil {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval())
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台探测循环
+func (s *AccountHealthProbeService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *AccountHealthProbeService) runOnce() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, accountHealthProbeLeaderLockKey, s.instanceID, s.interval())
+	lockCancel()
+	if !ok {
+		// 非 leader 实例从共享缓存刷新快照
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.refreshSnapshot(ctx)
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval())
+	defer cancel()
+	if err := s.ProbeAll(ctx); err != nil {
+		slog.Warn("account_health_probe_cycle_failed", "error", err)
+	}
+}
+
+// ProbeAll 对所有启用的账号执行一轮探测，结果写入缓存与本地快照
+func (s *AccountHealthProbeService) ProbeAll(ctx context.Context) error {
+	accounts, err := s.accountRepo.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("list active accounts: %w", err)
+	}
+	previous := s.loadPrevious(ctx)
+
+	targets := make([]*Account, 0, len(accounts))
+	probed := make(map[int64]struct{}, len(accounts))
+	for i := range accounts {
+		account := &accounts[i]
+		if s.probeTypeFor(account) == AccountHealthProbeNone {
+			continue
+		}
+		targets = append(targets, account)
+		probed[account.ID] = struct{}{}
+	}
+
+	concurrency := s.probeCfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	results := make([]AccountHealthStatus, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, account := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, account *Account) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var prev *AccountHealthStatus
+			if p, ok := previous[account.ID]; ok {
+				prev = &p
+			}
+			results[i] = s.probeAccount(ctx, account, prev)
+		}(i, account)
+	}
+	wg.Wait()
+
+	stale := make([]int64, 0)
+	for id := range previous {
+		if _, ok := probed[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	if s.cache != nil {
+		if err := s.cache.SaveAccountHealth(ctx, results, accountHealthStaleIntervals*s.interval()); err != nil {
+			slog.Warn("account_health_probe_save_failed", "error", err)
+		}
+		if len(stale) > 0 {
+			if err := s.cache.DeleteAccountHealth(ctx, stale); err != nil {
+				slog.Warn("account_health_probe_delete_failed", "error", err)
+			}
+		}
+	}
+	s.replaceSnapshot(results)
+	return nil
+}
+
+// loadPrevious 读取上一轮结果，用于跨实例延续连续失败计数
+func (s *AccountHealthProbeService) loadPrevious(ctx context.Context) map[int64]AccountHealthStatus {
+	if s.cache != nil {
+		statuses, err := s.cache.ListAccountHealth(ctx)
+		if err == nil {
+			previous := make(map[int64]AccountHealthStatus, len(statuses))
+			for _, st := range statuses {
+				previous[st.AccountID] = st
+			}
+			return previous
+		}
+		slog.Warn("account_health_probe_load_failed", "error", err)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	previous := make(map[int64]AccountHealthStatus, len(s.statuses))
+	for id, st := range s.statuses {
+		previous[id] = st
+	}
+	return previous
+}
+
+func (s *AccountHealthProbeService) refreshSnapshot(ctx context.Context) {
+	if s.cache == nil {
+		return
+	}
+	statuses, err := s.cache.ListAccountHealth(ctx)
+	if err != nil {
+		slog.Warn("account_health_probe_refresh_failed", "error", err)
+		return
+	}
+	s.replaceSnapshot(statuses)
+}
+
+func (s *AccountHealthProbeService) replaceSnapshot(statuses []AccountHealthStatus) {
+	next := make(map[int64]AccountHealthStatus, len(statuses))
+	for _, st := range statuses {
+		next[st.AccountID] = st
+	}
+	s.mu.Lock()
+	s.statuses = next
+	s.mu.Unlock()
+}
+
+// IsAccountUnhealthy 账号是否被主动探测判定为不健康（未启用、无结果或结果过期时返回 false）
+func (s *AccountHealthProbeService) IsAccountUnhealthy(accountID int64) bool {
+	if !s.Enabled() {
+		return false
+	}
+	s.mu.RLock()
+	st, ok := s.statuses[accountID]
+	s.mu.RUnlock()
+	if !ok || st.Healthy {
+		return false
+	}
+	return s.now().Sub(st.CheckedAt) <= accountHealthStaleIntervals*s.interval()
+}
+
+// ListStatuses 返回当前快照中的探测结果（按账号 ID 升序）
+func (s *AccountHealthProbeService) ListStatuses() []AccountHealthStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	statuses := make([]AccountHealthStatus, 0, len(s.statuses))
+	for _, st := range s.statuses {
+		statuses = append(statuses, st)
+	}
+	s.mu.RUnlock()
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].AccountID < statuses[j].AccountID })
+	return statuses
+}
+
+// probeTypeFor 返回账号所属平台的探测方式；不支持探测的账号类型返回 none
+func (s *AccountHealthProbeService) probeTypeFor(account *Account) string {
+	if account == nil {
+		return AccountHealthProbeNone
+	}
+	probe := defaultAccountHealthProbes[account.Platform]
+	for platform, configured := range s.probeCfg.Probes {
+		if strings.EqualFold(strings.TrimSpace(platform), account.Platform) {
+			probe = strings.ToLower(strings.TrimSpace(configured))
+			break
+		}
+	}
+	if probe == "" {
+		return AccountHealthProbeNone
+	}
+	// Bedrock / Vertex 服务账号没有免费的探测接口
+	if account.IsBedrock() || (account.Platform == PlatformAnthropic && account.Type == AccountTypeServiceAccount) {
+		return AccountHealthProbeNone
+	}
+	return probe
+}
+
+// probeAccount 执行单次探测并结合上一轮结果计算健康状态
+func (s *AccountHealthProbeService) probeAccount(ctx context.Context, account *Account, prev *AccountHealthStatus) AccountHealthStatus {
+	probeType := s.probeTypeFor(account)
+	status := AccountHealthStatus{
+		AccountID: account.ID,
+		Platform:  account.Platform,
+		ProbeType: probeType,
+	}
+	if prev != nil {
+		status.LastSuccessAt = prev.LastSuccessAt
+		status.ConsecutiveFailures = prev.ConsecutiveFailures
+	}
+
+	timeout := time.Duration(s.probeCfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := s.now()
+	statusCode, err := s.doProbe(probeCtx, account, probeType)
+	checkedAt := s.now()
+	status.CheckedAt = checkedAt
+	status.LatencyMs = checkedAt.Sub(start).Milliseconds()
+	status.StatusCode = statusCode
+
+	if err == nil && !isAccountHealthProbeFailureStatus(statusCode) {
+		status.ConsecutiveFailures = 0
+		status.LastSuccessAt = &checkedAt
+	} else {
+		status.ConsecutiveFailures++
+		if err != nil {
+			status.Error = truncateString(err.Error(), accountHealthErrorMaxLen)
+		} else {
+			status.Error = fmt.Sprintf("upstream returned %d", statusCode)
+		}
+	}
+	threshold := s.probeCfg.FailureThreshold
+	if threshold <= 0 {
+		threshold = 1
+	}
+	status.Healthy = status.ConsecutiveFailures < threshold
+	if prev != nil && prev.Healthy != status.Healthy {
+		slog.Info("account_health_probe_state_changed",
+			"account_id", account.ID,
+			"platform", account.Platform,
+			"healthy", status.Healthy,
+			"status_code", statusCode,
+			"error", status.Error)
+	}
+	return status
+}
+
+// isAccountHealthProbeFailureStatus 判断探测响应是否视为失败：
+// 鉴权失败（401/403）与上游故障（5xx）计为失败；429 等说明凭证有效、上游可达，不计入。
+func isAccountHealthProbeFailureStatus(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden || statusCode >= 500
+}
+
+func (s *AccountHealthProbeService) doProbe(ctx context.Context, account *Account, probeType string) (int, error) {
+	req, err := s.buildProbeRequest(ctx, account, probeType)
+	if err != nil {
+		return 0, err
+	}
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.DoWithTLS(req, proxyURL, account.ID, account.Concurrency, s.resolveTLSProfile(account))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+func (s *AccountHealthProbeService) resolveTLSProfile(account *Account) *tlsfingerprint.Profile {
+	if s.tlsFPProfileService == nil {
+		return nil
+	}
+	return s.tlsFPProfileService.ResolveTLSProfile(account)
+}
+
+func (s *AccountHealthProbeService) buildProbeRequest(ctx context.Context, account *Account, probeType string) (*http.Request, error) {
+	switch {
+	case account.Platform == PlatformAnthropic && probeType == AccountHealthProbeModels:
+		return s.buildClaudeModelsRequest(ctx, account)
+	case account.Platform == PlatformOpenAI && probeType == AccountHealthProbeModels:
+		return s.buildOpenAIProbeRequest(ctx, account)
+	case account.Platform == PlatformGemini && probeType == AccountHealthProbeCountTokens:
+		return s.buildGeminiCountTokensRequest(ctx, account)
+	}
+	return nil, fmt.Errorf("probe %q is not supported for platform %s", probeType, account.Platform)
+}
+
+func (s *AccountHealthProbeService) buildClaudeModelsRequest(ctx context.Context, account *Account) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	switch {
+	case account.IsOAuth():
+		token := account.GetCredential("access_token")
+		if token == "" {
+			return nil, errors.New("no access token available")
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, "https://api.anthropic.com/v1/models", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("anthropic-beta", claude.DefaultBetaHeader)
+		req.Header.Set("Authorization", "Bearer "+token)
+	case account.Type == AccountTypeAPIKey:
+		apiKey := account.GetCredential("api_key")
+		if apiKey == "" {
+			return nil, errors.New("no API key available")
+		}
+		normalized, verr := s.validateUpstreamBaseURL(account.GetBaseURL())
+		if verr != nil {
+			return nil, verr
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(normalized, "/")+"/v1/models", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("x-api-key", apiKey)
+	default:
+		return nil, fmt.Errorf("unsupported account type: %s", account.Type)
+	}
+	req.Header.Set("anthropic-version", "2023-06-01")
+	return req, nil
+}
+
+// buildOpenAIProbeRequest API Key 账号查询模型列表；OAuth 账号查询 ChatGPT 用量（不消耗额度）
+func (s *AccountHealthProbeService) buildOpenAIProbeRequest(ctx context.Context, account *Account) (*http.Request, error) {
+	if account.IsOpenAIOAuth() {
+		token := account.GetOpenAIAccessToken()
+		if token == "" {
+			return nil, errors.New("no access token available")
+		}
+		chatGPTAccountID := strings.TrimSpace(account.GetCredential("chatgpt_account_id"))
+		if chatGPTAccountID == "" {
+			chatGPTAccountID = strings.TrimSpace(account.GetCredential("organization_id"))
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, chatGPTUsageURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range buildCodexCommonHeaders(token, chatGPTAccountID, account.IsChatGPTAccountFedRAMP()) {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	}
+	apiKey := account.GetOpenAIApiKey()
+	if apiKey == "" {
+		return nil, errors.New("no API key available")
+	}
+	normalized, err := s.validateUpstreamBaseURL(account.GetOpenAIBaseURL())
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(normalized, "/")+"/v1/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return req, nil
+}
+
+// buildGeminiCountTokensRequest countTokens 不计入生成配额
+func (s *AccountHealthProbeService) buildGeminiCountTokensRequest(ctx context.Context, account *Account) (*http.Request, error) {
+	model := strings.TrimSpace(s.probeCfg.GeminiModel)
+	if model == "" {
+		model = "gemini-2.5-flash"
+	}
+	payload := []byte(`{"contents":[{"role":"user","parts":[{"text":"ping"}]}]}`)
+
+	if account.Type == AccountTypeAPIKey {
+		apiKey := strings.TrimSpace(account.GetCredential("api_key"))
+		if apiKey == "" {
+			return nil, errors.New("no API key available")
+		}
+		fullURL, err := s.geminiAIStudioURL(account, model)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullURL, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-goog-api-key", apiKey)
+		return req, nil
+	}
+
+	if s.geminiTokenProvider == nil {
+		return nil, errors.New("gemini token provider not configured")
+	}
+	token, err := s.geminiTokenProvider.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	var fullURL string
+	body := payload
+	switch projectID := strings.TrimSpace(account.GetCredential("project_id")); {
+	case account.Type == AccountTypeServiceAccount:
+		fullURL, err = buildVertexGeminiURL(account.VertexProjectID(), account.VertexLocation(model), model, "countTokens", false)
+	case projectID != "":
+		// Code Assist 模式：countTokens 请求体包裹在 request 中
+		body = []byte(fmt.Sprintf(`{"request":{"model":"models/%s","contents":[{"role":"user","parts":[{"text":"ping"}]}]}}`, model))
+		fullURL = strings.TrimRight(geminicli.GeminiCliBaseURL, "/") + "/v1internal:countTokens"
+	default:
+		fullURL, err = s.geminiAIStudioURL(account, model)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+func (s *AccountHealthProbeService) geminiAIStudioURL(account *Account, model string) (string, error) {
+	baseURL := strings.TrimSpace(account.GetCredential("base_url"))
+	if baseURL == "" {
+		baseURL = geminicli.AIStudioBaseURL
+	}
+	normalized, err := s.validateUpstreamBaseURL(baseURL)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/v1beta/models/%s:countTokens", strings.TrimRight(normalized, "/"), model), nil
+}
+
+func (s *AccountHealthProbeService) validateUpstreamBaseURL(raw string) (string, error) {
+	if s.cfg == nil {
+		return "", errors.New("config is not available")
+	}
+	if !s.cfg.Security.URLAllowlist.Enabled {
+		return urlvalidator.ValidateURLFormat(raw, s.cfg.Security.URLAllowlist.AllowInsecureHTTP)
+	}
+	return urlvalidator.ValidateHTTPSURL(raw, urlvalidator.ValidationOptions{
+		AllowedHosts:     s.cfg.Security.URLAllowlist.UpstreamHosts,
+		RequireAllowlist: true,
+		AllowPrivate:     s.cfg.Security.URLAllowlist.AllowPrivateHosts,
+	})
+}
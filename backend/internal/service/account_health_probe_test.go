@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/stretchr/testify/require"
+)
+
+type healthProbeAccountRepoStub struct {
+	AccountRepository
+	accounts []Account
+}
+
+func (r *healthProbeAccountRepoStub) ListActive(context.Context) ([]Account, error) {
+	return r.accounts, nil
+}
+
+type healthProbeCacheStub struct {
+	mu       sync.Mutex
+	statuses map[int64]AccountHealthStatus
+}
+
+func (c *healthProbeCacheStub) SaveAccountHealth(_ context.Context, statuses []AccountHealthStatus, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, st := range statuses {
+		c.statuses[st.AccountID] = st
+	}
+	return nil
+}
+
+func (c *healthProbeCacheStub) ListAccountHealth(context.Context) ([]AccountHealthStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	statuses := make([]AccountHealthStatus, 0, len(c.statuses))
+	for _, st := range c.statuses {
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+func (c *healthProbeCacheStub) DeleteAccountHealth(_ context.Context, accountIDs []int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range accountIDs {
+		delete(c.statuses, id)
+	}
+	return nil
+}
+
+// healthProbeUpstreamStub 直接转发到测试服务器，并记录每次请求使用的代理
+type healthProbeUpstreamStub struct {
+	mu      sync.Mutex
+	proxies map[int64]string
+}
+
+func (u *healthProbeUpstreamStub) Do(req *http.Request, proxyURL string, accountID int64, _ int) (*http.Response, error) {
+	u.mu.Lock()
+	u.proxies[accountID] = proxyURL
+	u.mu.Unlock()
+	return http.DefaultClient.Do(req)
+}
+
+func (u *healthProbeUpstreamStub) DoWithTLS(req *http.Request, proxyURL string, accountID int64, concurrency int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, concurrency)
+}
+
+func newHealthProbeTestService(t *testing.T, accounts []Account, probes map[string]string) (*AccountHealthProbeService, *healthProbeCacheStub, *healthProbeUpstreamStub) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Security.URLAllowlist.AllowInsecureHTTP = true
+	cfg.AccountHealthProbe = config.AccountHealthProbeConfig{
+		Enabled:          true,
+		IntervalSeconds:  60,
+		Concurrency:      2,
+		TimeoutSeconds:   5,
+		FailureThreshold: 2,
+		Probes:           probes,
+		GeminiModel:      "gemini-2.5-flash",
+	}
+	cache := &healthProbeCacheStub{statuses: make(map[int64]AccountHealthStatus)}
+	upstream := &healthProbeUpstreamStub{proxies: make(map[int64]string)}
+	svc := NewAccountHealthProbeService(&healthProbeAccountRepoStub{accounts: accounts}, cache, upstream, nil, nil, cfg)
+	return svc, cache, upstream
+}
+
+func TestAccountHealthProbeService_MarksUnhealthyAndRecovers(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	var lastPath, lastAuth atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastPath.Store(r.Method + " " + r.URL.Path)
+		lastAuth.Store(r.Header.Get("Authorization"))
+		w.WriteHeader(int(status.Load()))
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	proxyID := int64(5)
+	account := Account{
+		ID:          1,
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeAPIKey,
+		Status:      StatusActive,
+		Concurrency: 3,
+		Credentials: map[string]any{"api_key": "sk-test", "base_url": server.URL},
+		ProxyID:     &proxyID,
+		Proxy:       &Proxy{ID: proxyID, Protocol: "http", Host: "10.0.0.1", Port: 8080},
+	}
+	svc, cache, upstream := newHealthProbeTestService(t, []Account{account}, nil)
+	ctx := context.Background()
+
+	probe := func() AccountHealthStatus {
+		t.Helper()
+		require.NoError(t, svc.ProbeAll(ctx))
+		statuses := svc.ListStatuses()
+		require.Len(t, statuses, 1)
+		return statuses[0]
+	}
+
+	st := probe()
+	require.True(t, st.Healthy)
+	require.Equal(t, AccountHealthProbeModels, st.ProbeType)
+	require.Equal(t, http.StatusOK, st.StatusCode)
+	require.NotNil(t, st.LastSuccessAt)
+	require.Equal(t, "GET /v1/models", lastPath.Load())
+	require.Equal(t, "Bearer sk-test", lastAuth.Load())
+	require.Equal(t, "http://10.0.0.1:8080", upstream.proxies[1], "probe must go through the account proxy")
+	require.False(t, svc.IsAccountUnhealthy(1))
+
+	// 429 说明凭证有效，不计为失败
+	status.Store(http.StatusTooManyRequests)
+	st = probe()
+	require.True(t, st.Healthy)
+	require.Zero(t, st.ConsecutiveFailures)
+
+	// 连续失败未达阈值前仍视为健康
+	status.Store(http.StatusUnauthorized)
+	st = probe()
+	require.True(t, st.Healthy)
+	require.Equal(t, 1, st.ConsecutiveFailures)
+	require.False(t, svc.IsAccountUnhealthy(1))
+
+	st = probe()
+	require.False(t, st.Healthy)
+	require.Equal(t, 2, st.ConsecutiveFailures)
+	require.Equal(t, "upstream returned 401", st.Error)
+	require.True(t, svc.IsAccountUnhealthy(1))
+	require.False(t, cache.statuses[1].Healthy)
+
+	// 探测恢复成功后自动回到健康
+	status.Store(http.StatusOK)
+	st = probe()
+	require.True(t, st.Healthy)
+	require.Zero(t, st.ConsecutiveFailures)
+	require.Empty(t, st.Error)
+	require.False(t, svc.IsAccountUnhealthy(1))
+}
+
+func TestAccountHealthProbeService_PerPlatformProbes(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]http.Header{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Method+" "+r.URL.Path] = r.Header.Clone()
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	accounts := []Account{
+		{ID: 1, Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "sk-ant", "base_url": server.URL}},
+		{ID: 2, Platform: PlatformGemini, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "g-key", "base_url": server.URL}},
+		{ID: 3, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "sk-openai", "base_url": server.URL}},
+		{ID: 4, Platform: PlatformAntigravity, Type: AccountTypeOAuth},
+	}
+	svc, cache, _ := newHealthProbeTestService(t, accounts, map[string]string{"openai": "none"})
+	cache.statuses[99] = AccountHealthStatus{AccountID: 99, Healthy: false, CheckedAt: time.Now()}
+
+	require.NoError(t, svc.ProbeAll(context.Background()))
+
+	require.Equal(t, "sk-ant", seen["GET /v1/models"].Get("x-api-key"))
+	require.Equal(t, "2023-06-01", seen["GET /v1/models"].Get("anthropic-version"))
+	require.Equal(t, "g-key", seen["POST /v1beta/models/gemini-2.5-flash:countTokens"].Get("x-goog-api-key"))
+	require.Len(t, seen, 2, "openai probe disabled by config, antigravity has no probe")
+
+	statuses := svc.ListStatuses()
+	require.Len(t, statuses, 2)
+	require.EqualValues(t, 1, statuses[0].AccountID)
+	require.Equal(t, AccountHealthProbeCountTokens, statuses[1].ProbeType)
+	_, stale := cache.statuses[99]
+	require.False(t, stale, "results of accounts no longer probed are removed")
+}
+
+func TestAccountHealthProbeService_IgnoresStaleOrDisabledResults(t *testing.T) {
+	svc, _, _ := newHealthProbeTestService(t, nil, nil)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	svc.replaceSnapshot([]AccountHealthStatus{
+		{AccountID: 1, Healthy: false, CheckedAt: now.Add(-time.Minute)},
+		{AccountID: 2, Healthy: false, CheckedAt: now.Add(-time.Hour)},
+	})
+	require.True(t, svc.IsAccountUnhealthy(1))
+	require.False(t, svc.IsAccountUnhealthy(2), "results older than 3 intervals are ignored")
+	require.False(t, svc.IsAccountUnhealthy(3))
+
+	svc.probeCfg.Enabled = false
+	require.False(t, svc.IsAccountUnhealthy(1))
+	var nilSvc *AccountHealthProbeService
+	require.False(t, nilSvc.IsAccountUnhealthy(1))
+	require.Nil(t, nilSvc.ListStatuses())
+}
+
+func TestAccountHealthProbe_AffectsRouting(t *testing.T) {
+	accounts := []accountWithLoad{
+		{account: &Account{ID: 1}, loadInfo: &AccountLoadInfo{AccountID: 1}},
+		{account: &Account{ID: 2}, loadInfo: &AccountLoadInfo{AccountID: 2, LoadRate: 50}},
+	}
+	unhealthy := func(accountID int64) bool { return accountID == 1 }
+
+	weights := config.GatewayRoutingConfig{Load: 1, Health: 2}
+	scored := scoreAccountsForRouting(accounts, weights, accountRoutingSignals{unhealthy: unhealthy})
+	require.EqualValues(t, 2, scored[0].account.ID)
+	require.Zero(t, scored[1].score.HealthFactor)
+	require.Equal(t, 1.0, scored[0].score.HealthFactor)
+
+	filtered := filterBySoftPredicate(accounts, unhealthy)
+	require.Len(t, filtered, 1)
+	require.EqualValues(t, 2, filtered[0].account.ID)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// AccountLabelsExtraKey 账号标签在 accounts.extra 中的键（值为字符串数组）
+const AccountLabelsExtraKey = "labels"
+
+// maxAccountLabelLength 单个标签的最大长度，超出部分截断
+const maxAccountLabelLength = 64
+
+// ErrNoAccountsMatchingLabels 表示分组内有可调度账号，但没有账号同时带有请求要求的全部标签。
+// 与 ErrNoAvailableAccounts 区分：重试不会改变结果，需要调整标签配置。
+var ErrNoAccountsMatchingLabels = errors.New("no accounts matching labels")
+
+// NormalizeAccountLabels 规范化标签：去除首尾空白、转小写、去重（保持原有顺序），丢弃空标签。
+func NormalizeAccountLabels(labels []string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(labels))
+	seen := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" {
+			continue
+		}
+		if len(label) > maxAccountLabelLength {
+			label = label[:maxAccountLabelLength]
+		}
+		if _, ok := seen[label]; ok {
+			continue
+		}
+		seen[label] = struct{}{}
+		out = append(out, label)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// ParseAccountLabelSelector 解析逗号分隔的标签选择器（如请求头 X-Account-Labels: premium,eu）
+func ParseAccountLabelSelector(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	return NormalizeAccountLabels(strings.Split(raw, ","))
+}
+
+// NormalizeAccountExtraLabels 规范化 extra.labels 后写回；规范化后为空则删除该键。
+func NormalizeAccountExtraLabels(extra map[string]any) {
+	if extra == nil {
+		return
+	}
+	if _, ok := extra[AccountLabelsExtraKey]; !ok {
+		return
+	}
+	labels := (&Account{Extra: extra}).Labels()
+	if len(labels) == 0 {
+		delete(extra, AccountLabelsExtraKey)
+		return
+	}
+	extra[AccountLabelsExtraKey] = labels
+}
+
+// Labels 返回账号标签（来自 extra.labels，已规范化）
+func (a *Account) Labels() []string {
+	if a == nil || a.Extra == nil {
+		return nil
+	}
+	switch v := a.Extra[AccountLabelsExtraKey].(type) {
+	case []string:
+		return NormalizeAccountLabels(v)
+	case []any:
+		labels := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				labels = append(labels, s)
+			}
+		}
+		return NormalizeAccountLabels(labels)
+	case string:
+		return ParseAccountLabelSelector(v)
+	}
+	return nil
+}
+
+// MatchesLabelSelector 账号是否带有选择器要求的全部标签；空选择器匹配所有账号。
+func (a *Account) MatchesLabelSelector(selector []string) bool {
+	if len(selector) == 0 {
+		return true
+	}
+	labels := a.Labels()
+	if len(labels) == 0 {
+		return false
+	}
+	have := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		have[label] = struct{}{}
+	}
+	for _, want := range selector {
+		if _, ok := have[want]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// WithAccountLabelSelector 将账号标签选择器写入 context，调度时只在匹配的账号中选择。
+func WithAccountLabelSelector(ctx context.Context, selector []string) context.Context {
+	selector = NormalizeAccountLabels(selector)
+	if len(selector) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxkey.AccountLabelSelector, selector)
+}
+
+// AccountLabelSelectorFromContext 读取请求携带的账号标签选择器
+func AccountLabelSelectorFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	selector, _ := ctx.Value(ctxkey.AccountLabelSelector).([]string)
+	return selector
+}
+
+// filterAccountsByLabelSelector 按 context 中的标签选择器过滤候选账号；无选择器时原样返回。
+// 候选池非空但无账号匹配时返回 ErrNoAccountsMatchingLabels。
+func filterAccountsByLabelSelector(ctx context.Context, accounts []Account) ([]Account, error) {
+	selector := AccountLabelSelectorFromContext(ctx)
+	if len(selector) == 0 || len(accounts) == 0 {
+		return accounts, nil
+	}
+	filtered := make([]Account, 0, len(accounts))
+	for i := range accounts {
+		if accounts[i].MatchesLabelSelector(selector) {
+			filtered = append(filtered, accounts[i])
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("%w [%s]", ErrNoAccountsMatchingLabels, strings.Join(selector, ", "))
+	}
+	return filtered, nil
+}
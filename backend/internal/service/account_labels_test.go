@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAccountLabels(t *testing.T) {
+	require.Nil(t, NormalizeAccountLabels(nil))
+	require.Nil(t, NormalizeAccountLabels([]string{" ", ""}))
+	require.Equal(t, []string{"premium", "eu"}, NormalizeAccountLabels([]string{" Premium", "eu", "PREMIUM", ""}))
+	require.Equal(t, []string{"premium", "eu"}, ParseAccountLabelSelector("premium, EU,,"))
+}
+
+func TestAccountMatchesLabelSelector(t *testing.T) {
+	account := &Account{Extra: map[string]any{AccountLabelsExtraKey: []any{"Premium", "eu", 1}}}
+
+	require.Equal(t, []string{"premium", "eu"}, account.Labels())
+	require.True(t, account.MatchesLabelSelector(nil))
+	require.True(t, account.MatchesLabelSelector([]string{"premium"}))
+	require.True(t, account.MatchesLabelSelector([]string{"eu", "premium"}))
+	require.False(t, account.MatchesLabelSelector([]string{"premium", "us"}))
+	require.False(t, (&Account{}).MatchesLabelSelector([]string{"premium"}))
+}
+
+func TestNormalizeAccountExtraLabels(t *testing.T) {
+	extra := map[string]any{AccountLabelsExtraKey: []any{" EU ", "eu", "premium"}}
+	NormalizeAccountExtraLabels(extra)
+	require.Equal(t, []string{"eu", "premium"}, extra[AccountLabelsExtraKey])
+
+	extra = map[string]any{AccountLabelsExtraKey: []any{" "}}
+	NormalizeAccountExtraLabels(extra)
+	require.NotContains(t, extra, AccountLabelsExtraKey)
+}
+
+func TestFilterAccountsByLabelSelector(t *testing.T) {
+	accounts := []Account{
+		{ID: 1, Extra: map[string]any{AccountLabelsExtraKey: []string{"premium", "eu"}}},
+		{ID: 2, Extra: map[string]any{AccountLabelsExtraKey: []string{"premium"}}},
+		{ID: 3},
+	}
+
+	got, err := filterAccountsByLabelSelector(context.Background(), accounts)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	ctx := WithAccountLabelSelector(context.Background(), []string{"Premium"})
+	got, err = filterAccountsByLabelSelector(ctx, accounts)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, []int64{got[0].ID, got[1].ID})
+
+	ctx = WithAccountLabelSelector(context.Background(), []string{"premium", "us"})
+	got, err = filterAccountsByLabelSelector(ctx, accounts)
+	require.Nil(t, got)
+	require.True(t, errors.Is(err, ErrNoAccountsMatchingLabels))
+	require.False(t, errors.Is(err, ErrNoAvailableAccounts))
+	require.Contains(t, err.Error(), "[premium, us]")
+}
+
+func TestOpenAISelectAccountWithScheduler_HonorsLabelSelector(t *testing.T) {
+	resetOpenAIAdvancedSchedulerSettingCacheForTest()
+
+	groupID := int64(10320)
+	accounts := []Account{
+		{ID: 32001, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 0},
+		{ID: 32002, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 5,
+			Extra: map[string]any{AccountLabelsExtraKey: []any{"eu"}}},
+	}
+	cfg := &config.Config{}
+	cfg.Gateway.Scheduling.LoadBatchEnabled = false
+	svc := &OpenAIGatewayService{
+		accountRepo:        schedulerTestOpenAIAccountRepo{accounts: accounts},
+		cache:              &schedulerTestGatewayCache{},
+		cfg:                cfg,
+		concurrencyService: NewConcurrencyService(schedulerTestConcurrencyCache{}),
+	}
+
+	// previous_response_id 绑定到未带标签的账号：粘连不得绕过选择器
+	store := svc.getOpenAIWSStateStore()
+	require.NoError(t, store.BindResponseAccount(context.Background(), groupID, "resp_label_001", 32001, time.Hour))
+
+	ctx := WithAccountLabelSelector(context.Background(), []string{"EU"})
+	selection, _, err := svc.SelectAccountWithScheduler(ctx, &groupID, "resp_label_001", "", "gpt-5.1", nil, OpenAIUpstreamTransportAny, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(32002), selection.Account.ID)
+
+	ctx = WithAccountLabelSelector(context.Background(), []string{"us"})
+	_, _, err = svc.SelectAccountWithScheduler(ctx, &groupID, "", "", "gpt-5.1", nil, OpenAIUpstreamTransportAny, false)
+	require.ErrorIs(t, err, ErrNoAccountsMatchingLabels)
+	require.True(t, svc.DiagnoseModelAvailabilityForPlatform(ctx, &groupID, "gpt-5.1", PlatformOpenAI).NoLabelMatch)
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// 账号调度时间窗：只在指定的星期+时段内参与调度（如个人订阅账号仅夜间使用，避免与本人白天使用冲突）。
+// 时间窗外账号视为不可调度；已在进行中的请求不受影响（仅在选号时判断）。
+const (
+	// AccountScheduleWindowsExtraKey 调度时间窗在 accounts.extra 中的键（值为 [{weekday,start,end}]）
+	AccountScheduleWindowsExtraKey = "schedule_windows"
+	// AccountScheduleTimezoneExtraKey 调度时间窗所用时区（IANA 名称，空值使用服务器时区）
+	AccountScheduleTimezoneExtraKey = "schedule_timezone"
+	// AccountScheduleExclusionReason 账号因不在调度时间窗内被排除时的原因（调度调试输出使用）
+	AccountScheduleExclusionReason = "outside schedule"
+)
+
+const minutesPerDay = 24 * 60
+
+// AccountScheduleWindow 单个调度时间窗：某个星期几的 [Start, End) 时段（按账号时区的本地时间）
+type AccountScheduleWindow struct {
+	Weekday int    `json:"weekday"` // 0=周日 … 6=周六（与 time.Weekday 一致）
+	Start   string `json:"start"`   // HH:MM，包含
+	End     string `json:"end"`     // HH:MM，不包含；允许 24:00 表示当天结束
+}
+
+func invalidAccountSchedule(format string, args ...any) error {
+	return infraerrors.BadRequest("INVALID_ACCOUNT_SCHEDULE", fmt.Sprintf(format, args...))
+}
+
+// parseScheduleClock 解析 HH:MM（00:00-24:00）为当天分钟数
+func parseScheduleClock(value string) (int, bool) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok || len(hh) == 0 || len(hh) > 2 || len(mm) != 2 {
+		return 0, false
+	}
+	hour, err := strconv.Atoi(hh)
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, false
+	}
+	minute, err := strconv.Atoi(mm)
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, false
+	}
+	total := hour*60 + minute
+	if total > minutesPerDay {
+		return 0, false
+	}
+	return total, true
+}
+
+func formatScheduleClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// NormalizeAccountScheduleWindows 校验并规范化调度时间窗：
+// 拒绝非法星期/时间与倒置或空区间（start >= end，跨午夜需拆成两段），
+// 同一天内重叠或首尾相接的区间合并，结果按星期、开始时间排序。
+func NormalizeAccountScheduleWindows(windows []AccountScheduleWindow) ([]AccountScheduleWindow, error) {
+	type span struct{ start, end int }
+	byDay := make(map[int][]span, 7)
+	for i, w := range windows {
+		if w.Weekday < 0 || w.Weekday > 6 {
+			return nil, invalidAccountSchedule("schedule_windows[%d]: weekday must be between 0 (Sunday) and 6 (Saturday)", i)
+		}
+		start, ok := parseScheduleClock(w.Start)
+		if !ok {
+			return nil, invalidAccountSchedule("schedule_windows[%d]: invalid start %q, expected HH:MM", i, w.Start)
+		}
+		end, ok := parseScheduleClock(w.End)
+		if !ok {
+			return nil, invalidAccountSchedule("schedule_windows[%d]: invalid end %q, expected HH:MM", i, w.End)
+		}
+		if start >= end {
+			return nil, invalidAccountSchedule("schedule_windows[%d]: start %s must be before end %s (split ranges that cross midnight)", i, w.Start, w.End)
+		}
+		byDay[w.Weekday] = append(byDay[w.Weekday], span{start, end})
+	}
+
+	var out []AccountScheduleWindow
+	for day := 0; day <= 6; day++ {
+		spans := byDay[day]
+		if len(spans) == 0 {
+			continue
+		}
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+		merged := []span{spans[0]}
+		for _, s := range spans[1:] {
+			last := &merged[len(merged)-1]
+			if s.start <= last.end {
+				last.end = max(last.end, s.end)
+				continue
+			}
+			merged = append(merged, s)
+		}
+		for _, s := range merged {
+			out = append(out, AccountScheduleWindow{Weekday: day, Start: formatScheduleClock(s.start), End: formatScheduleClock(s.end)})
+		}
+	}
+	return out, nil
+}
+
+// NormalizeAccountExtraSchedule 校验并规范化 extra 中的调度时间窗与时区后写回；
+// 时间窗为空时删除两个键（不限制调度时段）。
+func NormalizeAccountExtraSchedule(extra map[string]any) error {
+	if extra == nil {
+		return nil
+	}
+	raw, hasWindows := extra[AccountScheduleWindowsExtraKey]
+	tzName, _ := extra[AccountScheduleTimezoneExtraKey].(string)
+	tzName = strings.TrimSpace(tzName)
+	if tzName != "" {
+		if _, err := time.LoadLocation(tzName); err != nil {
+			return invalidAccountSchedule("invalid schedule_timezone %q: must be a valid IANA timezone name", tzName)
+		}
+	}
+	if !hasWindows {
+		return nil
+	}
+	windows, err := decodeAccountScheduleWindows(raw)
+	if err != nil {
+		return invalidAccountSchedule("invalid schedule_windows: %v", err)
+	}
+	windows, err = NormalizeAccountScheduleWindows(windows)
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		delete(extra, AccountScheduleWindowsExtraKey)
+		delete(extra, AccountScheduleTimezoneExtraKey)
+		return nil
+	}
+	extra[AccountScheduleWindowsExtraKey] = windows
+	if tzName == "" {
+		delete(extra, AccountScheduleTimezoneExtraKey)
+	} else {
+		extra[AccountScheduleTimezoneExtraKey] = tzName
+	}
+	return nil
+}
+
+// decodeAccountScheduleWindows 兼容内存中的 []AccountScheduleWindow 与 JSON 反序列化得到的 []any
+func decodeAccountScheduleWindows(raw any) ([]AccountScheduleWindow, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case []AccountScheduleWindow:
+		return v, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var windows []AccountScheduleWindow
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// scheduleLocationCache 时区缓存，避免调度热路径重复读取 zoneinfo
+var scheduleLocationCache sync.Map // string -> *time.Location
+
+func scheduleLocation(name string) *time.Location {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return timezone.Location()
+	}
+	if loc, ok := scheduleLocationCache.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return timezone.Location()
+	}
+	scheduleLocationCache.Store(name, loc)
+	return loc
+}
+
+// scheduleWindowsRawIdentity 返回原始时间窗值的底层数组地址与长度，用于判断缓存是否失效。
+// 非切片值无法解析为时间窗，统一返回零值。
+func scheduleWindowsRawIdentity(raw any) (uintptr, int) {
+	v := reflect.ValueOf(raw)
+	if v.Kind() != reflect.Slice {
+		return 0, 0
+	}
+	return v.Pointer(), v.Len()
+}
+
+// ScheduleWindows 返回账号的调度时间窗（未配置或数据无法解析时返回 nil，表示不限制）。
+// 调度热路径每次判断可调度性都会调用，解析结果缓存在账号上，extra 或原始值被替换时重新解析。
+func (a *Account) ScheduleWindows() []AccountScheduleWindow {
+	if a == nil || a.Extra == nil {
+		return nil
+	}
+	raw := a.Extra[AccountScheduleWindowsExtraKey]
+	extraPtr := mapPtr(a.Extra)
+	rawPtr, rawLen := scheduleWindowsRawIdentity(raw)
+	if a.scheduleWindowsCacheReady &&
+		a.scheduleWindowsCacheExtraPtr == extraPtr &&
+		a.scheduleWindowsCacheRawPtr == rawPtr &&
+		a.scheduleWindowsCacheRawLen == rawLen {
+		return a.scheduleWindowsCache
+	}
+
+	windows, err := decodeAccountScheduleWindows(raw)
+	if err != nil {
+		windows = nil
+	}
+	a.scheduleWindowsCache = windows
+	a.scheduleWindowsCacheReady = true
+	a.scheduleWindowsCacheExtraPtr = extraPtr
+	a.scheduleWindowsCacheRawPtr = rawPtr
+	a.scheduleWindowsCacheRawLen = rawLen
+	return windows
+}
+
+// IsWithinSchedule 账号在 now 时刻是否处于调度时间窗内；未配置时间窗时始终为 true。
+// 按账号时区的本地星期与时刻判断，夏令时切换由时区规则自然处理。
+func (a *Account) IsWithinSchedule(now time.Time) bool {
+	windows := a.ScheduleWindows()
+	if len(windows) == 0 {
+		return true
+	}
+	tzName, _ := a.Extra[AccountScheduleTimezoneExtraKey].(string)
+	local := now.In(scheduleLocation(tzName))
+	weekday := int(local.Weekday())
+	minute := local.Hour()*60 + local.Minute()
+	for _, w := range windows {
+		if w.Weekday != weekday {
+			continue
+		}
+		start, okStart := parseScheduleClock(w.Start)
+		end, okEnd := parseScheduleClock(w.End)
+		if okStart && okEnd && minute >= start && minute < end {
+			return true
+		}
+	}
+	return false
+}
+
+// accountScheduleExclusionReason 账号因不在调度时间窗内不可调度时返回 AccountScheduleExclusionReason，否则返回空字符串
+func accountScheduleExclusionReason(account *Account, now time.Time) string {
+	if account == nil || account.IsWithinSchedule(now) {
+		return ""
+	}
+	return AccountScheduleExclusionReason
+}
+
+// logAccountScheduleExclusion 选号调试输出：记录因不在调度时间窗内被排除的账号（仅在 debug 级别开启时计算）
+func logAccountScheduleExclusion(ctx context.Context, account *Account) {
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	if reason := accountScheduleExclusionReason(account, time.Now()); reason != "" {
+		slog.Debug("account_select.excluded",
+			"account_id", account.ID,
+			"reason", reason,
+			"timezone", account.Extra[AccountScheduleTimezoneExtraKey],
+		)
+	}
+}
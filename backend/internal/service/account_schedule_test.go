@@ -0,0 +1,166 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAccountScheduleWindows(t *testing.T) {
+	got, err := NormalizeAccountScheduleWindows([]AccountScheduleWindow{
+		{Weekday: 1, Start: "13:00", End: "15:00"},
+		{Weekday: 1, Start: "09:00", End: "12:00"},
+		{Weekday: 1, Start: "11:30", End: "13:00"}, // 与前后两段重叠/相接，三段合并
+		{Weekday: 0, Start: "22:00", End: "24:00"},
+		{Weekday: 1, Start: "18:00", End: "19:00"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []AccountScheduleWindow{
+		{Weekday: 0, Start: "22:00", End: "24:00"},
+		{Weekday: 1, Start: "09:00", End: "15:00"},
+		{Weekday: 1, Start: "18:00", End: "19:00"},
+	}, got)
+
+	invalid := []AccountScheduleWindow{
+		{Weekday: 7, Start: "09:00", End: "10:00"},
+		{Weekday: -1, Start: "09:00", End: "10:00"},
+		{Weekday: 1, Start: "9", End: "10:00"},
+		{Weekday: 1, Start: "09:00", End: "24:01"},
+		{Weekday: 1, Start: "09:60", End: "10:00"},
+		{Weekday: 1, Start: "22:00", End: "02:00"}, // 倒置（跨午夜需拆分）
+		{Weekday: 1, Start: "10:00", End: "10:00"}, // 空区间
+	}
+	for _, w := range invalid {
+		_, err := NormalizeAccountScheduleWindows([]AccountScheduleWindow{w})
+		require.Error(t, err, "window=%+v", w)
+	}
+}
+
+func TestNormalizeAccountExtraSchedule(t *testing.T) {
+	// 模拟 JSON 请求体反序列化后的 extra
+	var extra map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schedule_windows": [{"weekday": 2, "start": "10:00", "end": "12:00"}, {"weekday": 2, "start": "08:00", "end": "10:00"}],
+		"schedule_timezone": " Asia/Shanghai "
+	}`), &extra))
+	require.NoError(t, NormalizeAccountExtraSchedule(extra))
+	require.Equal(t, []AccountScheduleWindow{{Weekday: 2, Start: "08:00", End: "12:00"}}, extra[AccountScheduleWindowsExtraKey])
+	require.Equal(t, "Asia/Shanghai", extra[AccountScheduleTimezoneExtraKey])
+
+	// 清空时间窗时同时删除时区
+	extra = map[string]any{AccountScheduleWindowsExtraKey: []any{}, AccountScheduleTimezoneExtraKey: "UTC"}
+	require.NoError(t, NormalizeAccountExtraSchedule(extra))
+	require.NotContains(t, extra, AccountScheduleWindowsExtraKey)
+	require.NotContains(t, extra, AccountScheduleTimezoneExtraKey)
+
+	require.Error(t, NormalizeAccountExtraSchedule(map[string]any{
+		AccountScheduleWindowsExtraKey:  []any{map[string]any{"weekday": 1, "start": "09:00", "end": "10:00"}},
+		AccountScheduleTimezoneExtraKey: "Mars/Olympus",
+	}))
+	require.Error(t, NormalizeAccountExtraSchedule(map[string]any{AccountScheduleWindowsExtraKey: "09:00-10:00"}))
+	require.Error(t, NormalizeAccountExtraSchedule(map[string]any{
+		AccountScheduleWindowsExtraKey: []any{map[string]any{"weekday": 1, "start": "12:00", "end": "09:00"}},
+	}))
+}
+
+func TestAccountIsWithinSchedule_BoundaryMinutes(t *testing.T) {
+	account := &Account{Extra: map[string]any{
+		AccountScheduleWindowsExtraKey: []any{
+			map[string]any{"weekday": 1, "start": "09:00", "end": "17:30"},
+			map[string]any{"weekday": 2, "start": "00:00", "end": "24:00"},
+		},
+		AccountScheduleTimezoneExtraKey: "UTC",
+	}}
+	at := func(value string) time.Time {
+		ts, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return ts
+	}
+
+	// 2026-10-19 为周一
+	require.False(t, account.IsWithinSchedule(at("2026-10-19T08:59:59Z")))
+	require.True(t, account.IsWithinSchedule(at("2026-10-19T09:00:00Z")), "start is inclusive")
+	require.True(t, account.IsWithinSchedule(at("2026-10-19T17:29:59Z")))
+	require.False(t, account.IsWithinSchedule(at("2026-10-19T17:30:00Z")), "end is exclusive")
+	require.True(t, account.IsWithinSchedule(at("2026-10-20T00:00:00Z")))
+	require.True(t, account.IsWithinSchedule(at("2026-10-20T23:59:59Z")), "24:00 covers the last minute")
+	require.False(t, account.IsWithinSchedule(at("2026-10-21T00:00:00Z")))
+
+	// 未配置时间窗时不限制
+	require.True(t, (&Account{}).IsWithinSchedule(at("2026-10-21T00:00:00Z")))
+}
+
+func TestAccountIsWithinSchedule_TimezoneAndDST(t *testing.T) {
+	account := &Account{Extra: map[string]any{
+		AccountScheduleWindowsExtraKey: []any{
+			map[string]any{"weekday": 0, "start": "01:30", "end": "03:30"},
+		},
+		AccountScheduleTimezoneExtraKey: "America/New_York",
+	}}
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 按账号时区本地时间判断：周日 02:00 EST = 07:00 UTC
+	require.True(t, account.IsWithinSchedule(time.Date(2026, 1, 4, 7, 0, 0, 0, time.UTC)))
+	require.False(t, account.IsWithinSchedule(time.Date(2026, 1, 4, 2, 0, 0, 0, time.UTC)), "Saturday 21:00 local")
+
+	// 2026-03-08 夏令时开始：02:00 直接跳到 03:00，窗口实际只持续 1 小时
+	springStart := time.Date(2026, 3, 8, 1, 30, 0, 0, loc)
+	require.True(t, account.IsWithinSchedule(springStart))
+	require.True(t, account.IsWithinSchedule(springStart.Add(29*time.Minute)), "01:59 EST")
+	require.True(t, account.IsWithinSchedule(springStart.Add(30*time.Minute)), "03:00 EDT")
+	require.True(t, account.IsWithinSchedule(springStart.Add(59*time.Minute)), "03:29 EDT")
+	require.False(t, account.IsWithinSchedule(springStart.Add(60*time.Minute)), "03:30 EDT")
+
+	// 2026-11-01 夏令时结束：01:00-02:00 重复一次，窗口实际持续 3 小时
+	fallStart := time.Date(2026, 11, 1, 1, 30, 0, 0, loc)
+	require.True(t, account.IsWithinSchedule(fallStart))
+	require.True(t, account.IsWithinSchedule(fallStart.Add(time.Hour)), "repeated 01:30 EST")
+	require.True(t, account.IsWithinSchedule(fallStart.Add(179*time.Minute)), "03:29 EST")
+	require.False(t, account.IsWithinSchedule(fallStart.Add(180*time.Minute)), "03:30 EST")
+}
+
+func TestAccountIsSchedulable_OutsideSchedule(t *testing.T) {
+	now := time.Now().UTC()
+	window := func(weekday time.Weekday) []any {
+		return []any{map[string]any{"weekday": int(weekday), "start": "00:00", "end": "24:00"}}
+	}
+	// 覆盖今天与明天，避免测试恰好跨越午夜
+	inside := &Account{Status: StatusActive, Schedulable: true, Extra: map[string]any{
+		AccountScheduleWindowsExtraKey:  append(window(now.Weekday()), window((now.Weekday()+1)%7)...),
+		AccountScheduleTimezoneExtraKey: "UTC",
+	}}
+	outside := &Account{Status: StatusActive, Schedulable: true, Extra: map[string]any{
+		AccountScheduleWindowsExtraKey:  window((now.Weekday() + 3) % 7),
+		AccountScheduleTimezoneExtraKey: "UTC",
+	}}
+
+	require.True(t, inside.IsSchedulable())
+	require.Empty(t, accountScheduleExclusionReason(inside, now))
+	require.False(t, outside.IsSchedulable())
+	require.Equal(t, AccountScheduleExclusionReason, accountScheduleExclusionReason(outside, now))
+}
+
+func TestAccountScheduleWindows_CachedUntilExtraChanges(t *testing.T) {
+	account := &Account{Extra: map[string]any{
+		AccountScheduleWindowsExtraKey: []any{map[string]any{"weekday": 1, "start": "09:00", "end": "17:00"}},
+	}}
+	first := account.ScheduleWindows()
+	require.Len(t, first, 1)
+	require.Same(t, &first[0], &account.ScheduleWindows()[0], "repeated calls reuse the parsed windows")
+	require.Zero(t, testing.AllocsPerRun(10, func() { account.IsWithinSchedule(time.Now()) }))
+
+	// 替换原始值后重新解析
+	account.Extra[AccountScheduleWindowsExtraKey] = []any{
+		map[string]any{"weekday": 2, "start": "10:00", "end": "11:00"},
+		map[string]any{"weekday": 3, "start": "10:00", "end": "11:00"},
+	}
+	require.Len(t, account.ScheduleWindows(), 2)
+	require.Equal(t, 2, account.ScheduleWindows()[0].Weekday)
+
+	// 替换整个 extra 后重新解析
+	account.Extra = map[string]any{}
+	require.Nil(t, account.ScheduleWindows())
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// opsServerErrorPenaltyKind 记录到 ops 上游错误事件中的降权事件类型
+const opsServerErrorPenaltyKind = "server_error_penalty"
+
+// AccountServerErrorPenalty 单个账号当前的 5xx 选号降权状态
+type AccountServerErrorPenalty struct {
+	AccountID  int64     `json:"account_id"`
+	Until      time.Time `json:"until"`
+	LastStatus int       `json:"last_status"`
+}
+
+// AccountServerErrorTracker 按账号统计短时间窗口内的上游 5xx。
+// 窗口内达到阈值的账号在 PenaltySeconds 内降权（同优先级内仅作为最后选择），到期自动恢复；
+// 与限流/临时不可调度不同，它不会让账号停止调度，面向"不稳定但未失效"的账号。
+// 统计仅保存在本实例内存中。
+type AccountServerErrorTracker struct {
+	cfg config.GatewayServerErrorPenaltyConfig
+
+	mu        sync.Mutex
+	errors    map[int64][]time.Time
+	penalties map[int64]AccountServerErrorPenalty
+
+	now func() time.Time
+}
+
+// NewAccountServerErrorTracker 创建上游 5xx 降权跟踪器
+func NewAccountServerErrorTracker(cfg *config.Config) *AccountServerErrorTracker {
+	t := &AccountServerErrorTracker{
+		errors:    make(map[int64][]time.Time),
+		penalties: make(map[int64]AccountServerErrorPenalty),
+		now:       time.Now,
+	}
+	if cfg != nil {
+		t.cfg = cfg.Gateway.ServerErrorPenalty
+	}
+	return t
+}
+
+// Enabled 是否启用 5xx 降权
+func (t *AccountServerErrorTracker) Enabled() bool {
+	return t != nil && t.cfg.Enabled && t.cfg.Threshold > 0 && t.cfg.WindowSeconds > 0 && t.cfg.PenaltySeconds > 0
+}
+
+func isServerErrorPenaltyStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Record 记录一次上游响应状态码；窗口内 5xx 达到阈值时开始（或延长）降权并返回 true
+func (t *AccountServerErrorTracker) Record(accountID int64, statusCode int) (AccountServerErrorPenalty, bool) {
+	if !t.Enabled() || accountID <= 0 || !isServerErrorPenaltyStatus(statusCode) {
+		return AccountServerErrorPenalty{}, false
+	}
+	now := t.now()
+	window := time.Duration(t.cfg.WindowSeconds) * time.Second
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// 只需保留最近 Threshold 个时间戳：最早一个仍在窗口内即达到阈值
+	hits := append(t.errors[accountID], now)
+	if len(hits) > t.cfg.Threshold {
+		hits = hits[len(hits)-t.cfg.Threshold:]
+	}
+	if len(hits) < t.cfg.Threshold || now.Sub(hits[0]) > window {
+		t.errors[accountID] = hits
+		return AccountServerErrorPenalty{}, false
+	}
+
+	// 触发后清空计数，降权期间需要再次累计到阈值才会延长
+	delete(t.errors, accountID)
+	penalty := AccountServerErrorPenalty{
+		AccountID:  accountID,
+		Until:      now.Add(time.Duration(t.cfg.PenaltySeconds) * time.Second),
+		LastStatus: statusCode,
+	}
+	t.penalties[accountID] = penalty
+	slog.Warn("account_server_error_penalty",
+		"account_id", accountID,
+		"status_code", statusCode,
+		"threshold", t.cfg.Threshold,
+		"window_seconds", t.cfg.WindowSeconds,
+		"until", penalty.Until,
+	)
+	return penalty, true
+}
+
+// PenaltyOf 返回账号当前生效的降权；未降权或已到期时返回 false
+func (t *AccountServerErrorTracker) PenaltyOf(accountID int64) (AccountServerErrorPenalty, bool) {
+	if !t.Enabled() {
+		return AccountServerErrorPenalty{}, false
+	}
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	penalty, ok := t.penalties[accountID]
+	if !ok {
+		return AccountServerErrorPenalty{}, false
+	}
+	if !now.Before(penalty.Until) {
+		delete(t.penalties, accountID)
+		return AccountServerErrorPenalty{}, false
+	}
+	return penalty, true
+}
+
+// IsAccountPenalized 账号是否因持续 5xx 处于降权期
+func (t *AccountServerErrorTracker) IsAccountPenalized(accountID int64) bool {
+	_, ok := t.PenaltyOf(accountID)
+	return ok
+}
+
+// ListPenalties 返回当前生效的降权（按账号 ID 升序）
+func (t *AccountServerErrorTracker) ListPenalties() []AccountServerErrorPenalty {
+	if !t.Enabled() {
+		return nil
+	}
+	now := t.now()
+	t.mu.Lock()
+	out := make([]AccountServerErrorPenalty, 0, len(t.penalties))
+	for id, penalty := range t.penalties {
+		if !now.Before(penalty.Until) {
+			delete(t.penalties, id)
+			continue
+		}
+		out = append(out, penalty)
+	}
+	t.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].AccountID < out[j].AccountID })
+	return out
+}
+
+// AnnotateServerErrorPenalties 在请求结束时为本次返回过 5xx 且处于降权期的账号追加降权事件，
+// 使 ops 错误日志的上游事件中包含降权截止时间。
+func (s *OpsService) AnnotateServerErrorPenalties(c *gin.Context) {
+	if s == nil {
+		return
+	}
+	annotateOpsServerErrorPenalties(c, s.serverErrors)
+}
+
+// annotateOpsServerErrorPenalties 为本次请求中返回过 5xx 且当前处于降权期的账号追加一条 ops 上游事件，
+// 在 ops 错误日志中标明该账号的降权截止时间（每个账号只记录一次）。
+func annotateOpsServerErrorPenalties(c *gin.Context, tracker *AccountServerErrorTracker) {
+	if c == nil || !tracker.Enabled() {
+		return
+	}
+	v, ok := c.Get(OpsUpstreamErrorsKey)
+	if !ok {
+		return
+	}
+	events, ok := v.([]*OpsUpstreamErrorEvent)
+	if !ok || len(events) == 0 {
+		return
+	}
+
+	seen := make(map[int64]struct{})
+	candidates := make([]*OpsUpstreamErrorEvent, 0, len(events))
+	for _, ev := range events {
+		if ev == nil || ev.AccountID <= 0 {
+			continue
+		}
+		if ev.Kind == opsServerErrorPenaltyKind {
+			seen[ev.AccountID] = struct{}{}
+			continue
+		}
+		if isServerErrorPenaltyStatus(ev.UpstreamStatusCode) {
+			candidates = append(candidates, ev)
+		}
+	}
+	for _, ev := range candidates {
+		if _, dup := seen[ev.AccountID]; dup {
+			continue
+		}
+		seen[ev.AccountID] = struct{}{}
+		penalty, ok := tracker.PenaltyOf(ev.AccountID)
+		if !ok {
+			continue
+		}
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           ev.Platform,
+			AccountID:          ev.AccountID,
+			AccountName:        ev.AccountName,
+			UpstreamStatusCode: penalty.LastStatus,
+			Kind:               opsServerErrorPenaltyKind,
+			Message:            "account deprioritized for sustained upstream 5xx until " + penalty.Until.UTC().Format(time.RFC3339),
+			CooldownUntilUnix:  penalty.Until.Unix(),
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccountServerErrorTracker(now *time.Time) *AccountServerErrorTracker {
+	cfg := &config.Config{}
+	cfg.Gateway.ServerErrorPenalty = config.GatewayServerErrorPenaltyConfig{
+		Enabled:        true,
+		WindowSeconds:  60,
+		Threshold:      3,
+		PenaltySeconds: 120,
+	}
+	tracker := NewAccountServerErrorTracker(cfg)
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+func TestAccountServerErrorTracker_ThresholdWindowAndRecovery(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newTestAccountServerErrorTracker(&now)
+
+	// 非 5xx 不计数
+	for i := 0; i < 5; i++ {
+		_, penalized := tracker.Record(1, http.StatusTooManyRequests)
+		require.False(t, penalized)
+	}
+
+	// 超出窗口的 5xx 不累计
+	_, penalized := tracker.Record(1, http.StatusBadGateway)
+	require.False(t, penalized)
+	now = now.Add(61 * time.Second)
+	_, penalized = tracker.Record(1, http.StatusBadGateway)
+	require.False(t, penalized)
+	now = now.Add(10 * time.Second)
+	_, penalized = tracker.Record(1, http.StatusServiceUnavailable)
+	require.False(t, penalized)
+	require.False(t, tracker.IsAccountPenalized(1))
+
+	// 窗口内达到阈值：开始降权
+	now = now.Add(10 * time.Second)
+	penalty, penalized := tracker.Record(1, http.StatusInternalServerError)
+	require.True(t, penalized)
+	require.Equal(t, now.Add(120*time.Second), penalty.Until)
+	require.Equal(t, http.StatusInternalServerError, penalty.LastStatus)
+	require.True(t, tracker.IsAccountPenalized(1))
+	require.False(t, tracker.IsAccountPenalized(2))
+	require.Len(t, tracker.ListPenalties(), 1)
+
+	// 到期自动恢复
+	now = now.Add(120 * time.Second)
+	require.False(t, tracker.IsAccountPenalized(1))
+	require.Empty(t, tracker.ListPenalties())
+}
+
+func TestAccountServerErrorTracker_Disabled(t *testing.T) {
+	tracker := NewAccountServerErrorTracker(&config.Config{})
+	for i := 0; i < 10; i++ {
+		_, penalized := tracker.Record(1, http.StatusBadGateway)
+		require.False(t, penalized)
+	}
+	require.False(t, tracker.IsAccountPenalized(1))
+	require.Nil(t, tracker.ListPenalties())
+
+	var nilTracker *AccountServerErrorTracker
+	require.False(t, nilTracker.IsAccountPenalized(1))
+	_, penalized := nilTracker.Record(1, http.StatusBadGateway)
+	require.False(t, penalized)
+}
+
+func TestRateLimitService_HandleUpstreamError_RecordsServerErrorPenalty(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newTestAccountServerErrorTracker(&now)
+	svc := NewRateLimitService(nil, nil, &config.Config{}, nil, nil)
+	svc.SetAccountServerErrorTracker(tracker)
+	account := &Account{ID: 7, Platform: PlatformOpenAI, Type: AccountTypeAPIKey}
+
+	for i := 0; i < 3; i++ {
+		shouldDisable := svc.HandleUpstreamError(context.Background(), account, http.StatusBadGateway, http.Header{}, []byte("bad gateway"))
+		require.False(t, shouldDisable)
+	}
+	require.True(t, tracker.IsAccountPenalized(7))
+}
+
+func TestAnnotateOpsServerErrorPenalties(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	tracker := newTestAccountServerErrorTracker(&now)
+	for i := 0; i < 3; i++ {
+		tracker.Record(1, http.StatusBadGateway)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{Platform: PlatformOpenAI, AccountID: 1, UpstreamStatusCode: http.StatusBadGateway, Kind: "failover"})
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{Platform: PlatformOpenAI, AccountID: 1, UpstreamStatusCode: http.StatusBadGateway, Kind: "failover"})
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{Platform: PlatformOpenAI, AccountID: 2, UpstreamStatusCode: http.StatusBadGateway, Kind: "failover"})
+
+	annotateOpsServerErrorPenalties(c, tracker)
+	// 重复调用不会重复追加
+	annotateOpsServerErrorPenalties(c, tracker)
+
+	v, ok := c.Get(OpsUpstreamErrorsKey)
+	require.True(t, ok)
+	events := v.([]*OpsUpstreamErrorEvent)
+	require.Len(t, events, 4)
+	last := events[3]
+	require.Equal(t, opsServerErrorPenaltyKind, last.Kind)
+	require.EqualValues(t, 1, last.AccountID)
+	penalty, ok := tracker.PenaltyOf(1)
+	require.True(t, ok)
+	require.Equal(t, penalty.Until.Unix(), last.CooldownUntilUnix)
+}
@@ -68,6 +68,8 @@ type AdminService interface {
 	ClearGroupRPMOverrides(ctx context.Context, groupID int64) error
 	BatchSetGroupRPMOverrides(ctx context.Context, groupID int64, entries []GroupRPMOverrideInput) error
 	UpdateGroupSortOrders(ctx context.Context, updates []GroupSortOrderUpdate) error
+	// SetGroupInstructionInjection 仅更新分组的 instructions 注入配置（其余字段保持不变）
+	SetGroupInstructionInjection(ctx context.Context, groupID int64, cfg GroupInstructionInjectionConfig) (*Group, error)
 
 	// API Key management (admin)
 	AdminUpdateAPIKeyGroupID(ctx context.Context, keyID int64, groupID *int64) (*AdminUpdateAPIKeyGroupIDResult, error)
@@ -220,6 +222,7 @@ type CreateGroupInput struct {
 	ModelRouting        map[string][]int64
 	ModelRoutingEnabled bool // 是否启用模型路由
 	MCPXMLInject        *bool
+	PromptCacheInject   *bool
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes []string
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
@@ -231,6 +234,14 @@ type CreateGroupInput struct {
 	ModelsListConfig            GroupModelsListConfig
 	// RPMLimit 分组 RPM 上限（0 = 不限制）
 	RPMLimit int
+	// 模型降级规则（请求模型无可用账号时按序尝试）
+	ModelFallbackConfig GroupModelFallbackConfig
+	// OpenAI Responses instructions 注入配置（仅 openai 平台使用）
+	InstructionInjectionConfig GroupInstructionInjectionConfig
+	// 分组日/月消费上限（nil 或 <=0 表示不限制）及周期边界时区（空串表示系统时区）
+	SpendCapDailyUSD   *float64
+	SpendCapMonthlyUSD *float64
+	SpendCapTimezone   string
 	// 从指定分组复制账号（创建分组后在同一事务内绑定）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -261,6 +272,7 @@ type UpdateGroupInput struct {
 	ModelRouting        map[string][]int64
 	ModelRoutingEnabled *bool // 是否启用模型路由
 	MCPXMLInject        *bool
+	PromptCacheInject   *bool
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes *[]string
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
@@ -272,6 +284,15 @@ type UpdateGroupInput struct {
 	ModelsListConfig            *GroupModelsListConfig
 	// RPMLimit 分组 RPM 上限（0 = 不限制），nil 表示未提供不改动。
 	RPMLimit *int
+	// 模型降级规则，nil 表示未提供不改动
+	ModelFallbackConfig *GroupModelFallbackConfig
+	// instructions 注入配置，nil 表示未提供不改动
+	InstructionInjectionConfig *GroupInstructionInjectionConfig
+	// 分组日/月消费上限：nil 表示未提供不改动，<=0 表示取消上限
+	SpendCapDailyUSD   *float64
+	SpendCapMonthlyUSD *float64
+	// 消费上限周期边界时区，nil 表示未提供不改动
+	SpendCapTimezone *string
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -1807,6 +1828,15 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		subscriptionType = SubscriptionTypeStandard
 	}
 
+	spendCapTimezone, err := normalizeSpendCapTimezone(input.SpendCapTimezone)
+	if err != nil {
+		return nil, err
+	}
+	instructionInjection, err := normalizeGroupInstructionInjectionConfig(input.InstructionInjectionConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	// 限额字段：nil/负数 表示"无限制"，0 表示"不允许用量"，正数表示具体限额
 	dailyLimit := normalizeLimit(input.DailyLimitUSD)
 	weeklyLimit := normalizeLimit(input.WeeklyLimitUSD)
@@ -1846,6 +1876,11 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 	if input.MCPXMLInject != nil {
 		mcpXMLInject = *input.MCPXMLInject
 	}
+	// PromptCacheInject：默认关闭，需显式开启
+	promptCacheInject := false
+	if input.PromptCacheInject != nil {
+		promptCacheInject = *input.PromptCacheInject
+	}
 
 	// 如果指定了复制账号的源分组，先获取账号 ID 列表
 	var accountIDsToCopy []int64
@@ -1901,6 +1936,7 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		FallbackGroupIDOnInvalidRequest: fallbackOnInvalidRequest,
 		ModelRouting:                    input.ModelRouting,
 		MCPXMLInject:                    mcpXMLInject,
+		PromptCacheInject:               promptCacheInject,
 		SupportedModelScopes:            input.SupportedModelScopes,
 		AllowMessagesDispatch:           input.AllowMessagesDispatch,
 		RequireOAuthOnly:                input.RequireOAuthOnly,
@@ -1909,6 +1945,11 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		MessagesDispatchModelConfig:     normalizeOpenAIMessagesDispatchModelConfig(input.MessagesDispatchModelConfig),
 		ModelsListConfig:                normalizeGroupModelsListConfig(input.ModelsListConfig),
 		RPMLimit:                        input.RPMLimit,
+		ModelFallbackConfig:             normalizeGroupModelFallbackConfig(input.ModelFallbackConfig),
+		InstructionInjectionConfig:      instructionInjection,
+		SpendCapDailyUSD:                normalizeSpendCap(input.SpendCapDailyUSD),
+		SpendCapMonthlyUSD:              normalizeSpendCap(input.SpendCapMonthlyUSD),
+		SpendCapTimezone:                spendCapTimezone,
 	}
 	sanitizeGroupMessagesDispatchFields(group)
 	if err := s.groupRepo.Create(ctx, group); err != nil {
@@ -1955,6 +1996,27 @@ func normalizeLimit(limit *float64) *float64 {
 	return limit
 }
 
+// normalizeSpendCap 将 nil/0/负数 统一为 nil（表示不限制）
+func normalizeSpendCap(limit *float64) *float64 {
+	if limit == nil || *limit <= 0 {
+		return nil
+	}
+	v := *limit
+	return &v
+}
+
+// normalizeSpendCapTimezone 校验消费上限周期边界时区（IANA 名称），空串表示使用系统时区
+func normalizeSpendCapTimezone(tz string) (string, error) {
+	tz = strings.TrimSpace(tz)
+	if tz == "" {
+		return "", nil
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return "", infraerrors.BadRequest("INVALID_SPEND_CAP_TIMEZONE", fmt.Sprintf("invalid spend_cap_timezone %q", tz))
+	}
+	return tz, nil
+}
+
 // normalizePrice 将负数转换为 nil（表示使用默认价格），0 保留（表示免费）
 func normalizePrice(price *float64) *float64 {
 	if price == nil || *price < 0 {
@@ -2133,6 +2195,9 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if input.MCPXMLInject != nil {
 		group.MCPXMLInject = *input.MCPXMLInject
 	}
+	if input.PromptCacheInject != nil {
+		group.PromptCacheInject = *input.PromptCacheInject
+	}
 
 	// 支持的模型系列（仅 antigravity 平台使用）
 	if input.SupportedModelScopes != nil {
@@ -2161,6 +2226,29 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if input.RPMLimit != nil {
 		group.RPMLimit = *input.RPMLimit
 	}
+	if input.ModelFallbackConfig != nil {
+		group.ModelFallbackConfig = normalizeGroupModelFallbackConfig(*input.ModelFallbackConfig)
+	}
+	if input.InstructionInjectionConfig != nil {
+		cfg, err := normalizeGroupInstructionInjectionConfig(*input.InstructionInjectionConfig)
+		if err != nil {
+			return nil, err
+		}
+		group.InstructionInjectionConfig = cfg
+	}
+	if input.SpendCapDailyUSD != nil {
+		group.SpendCapDailyUSD = normalizeSpendCap(input.SpendCapDailyUSD)
+	}
+	if input.SpendCapMonthlyUSD != nil {
+		group.SpendCapMonthlyUSD = normalizeSpendCap(input.SpendCapMonthlyUSD)
+	}
+	if input.SpendCapTimezone != nil {
+		tz, err := normalizeSpendCapTimezone(*input.SpendCapTimezone)
+		if err != nil {
+			return nil, err
+		}
+		group.SpendCapTimezone = tz
+	}
 	sanitizeGroupMessagesDispatchFields(group)
 
 	if err := s.groupRepo.Update(ctx, group); err != nil {
@@ -2628,6 +2716,13 @@ func (s *adminServiceImpl) CreateAccount(ctx context.Context, input *CreateAccou
 		}
 		ComputeQuotaResetAt(account.Extra)
 		NormalizeFixedQuotaWindows(account.Extra)
+		NormalizeAccountExtraLabels(account.Extra)
+		if err := NormalizeAccountExtraSchedule(account.Extra); err != nil {
+			return nil, err
+		}
+		if err := ValidateAccountExtraRoutingWeight(account.Extra); err != nil {
+			return nil, err
+		}
 	}
 	if input.ExpiresAt != nil && *input.ExpiresAt > 0 {
 		expiresAt := time.Unix(*input.ExpiresAt, 0)
@@ -2737,6 +2832,13 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 		}
 		ComputeQuotaResetAt(account.Extra)
 		NormalizeFixedQuotaWindows(account.Extra)
+		NormalizeAccountExtraLabels(account.Extra)
+		if err := NormalizeAccountExtraSchedule(account.Extra); err != nil {
+			return nil, err
+		}
+		if err := ValidateAccountExtraRoutingWeight(account.Extra); err != nil {
+			return nil, err
+		}
 	}
 	if input.ProxyID != nil {
 		// 0 表示清除代理（前端发送 0 而不是 null 来表达清除意图）
@@ -2887,6 +2989,9 @@ func (s *adminServiceImpl) BulkUpdateAccounts(ctx context.Context, input *BulkUp
 			return nil, errors.New("rate_multiplier must be >= 0")
 		}
 	}
+	if err := ValidateAccountExtraRoutingWeight(input.Extra); err != nil {
+		return nil, err
+	}
 
 	// Prepare bulk updates for columns and JSONB fields.
 	repoUpdates := AccountBulkUpdate{
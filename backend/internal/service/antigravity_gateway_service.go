@@ -20,6 +20,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/antigravity"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/gin-gonic/gin"
@@ -866,6 +867,10 @@ type AntigravityGatewayService struct {
 	cache             GatewayCache // 用于模型级限流时清除粘性会话绑定
 	schedulerSnapshot *SchedulerSnapshotService
 	internal500Cache  Internal500CounterCache // INTERNAL 500 渐进惩罚计数器
+
+	// 响应改写（security.response_redaction）：按写给客户端的响应格式选择平台规则
+	claudeResponseRedactor *responseRedactor
+	geminiResponseRedactor *responseRedactor
 }
 
 func (s *AntigravityGatewayService) upstreamErrorBodyReadLimit() int64 {
@@ -894,15 +899,21 @@ func NewAntigravityGatewayService(
 	settingService *SettingService,
 	internal500Cache Internal500CounterCache,
 ) *AntigravityGatewayService {
+	var cfg *config.Config
+	if settingService != nil {
+		cfg = settingService.cfg
+	}
 	return &AntigravityGatewayService{
-		accountRepo:       accountRepo,
-		tokenProvider:     tokenProvider,
-		rateLimitService:  rateLimitService,
-		httpUpstream:      httpUpstream,
-		settingService:    settingService,
-		cache:             cache,
-		schedulerSnapshot: schedulerSnapshot,
-		internal500Cache:  internal500Cache,
+		accountRepo:            accountRepo,
+		tokenProvider:          tokenProvider,
+		rateLimitService:       rateLimitService,
+		httpUpstream:           httpUpstream,
+		settingService:         settingService,
+		cache:                  cache,
+		schedulerSnapshot:      schedulerSnapshot,
+		internal500Cache:       internal500Cache,
+		claudeResponseRedactor: compileResponseRedactor(cfg, PlatformAnthropic),
+		geminiResponseRedactor: compileResponseRedactor(cfg, PlatformGemini),
 	}
 }
 
@@ -2994,7 +3005,7 @@ func (s *AntigravityGatewayService) handleUpstreamError(
 			logger.LegacyPrintf("service.antigravity_gateway", "[Antigravity-Debug] 429 response body: %s", truncateString(string(body), maxBytes))
 		}
 
-		resetAt := ParseGeminiRateLimitResetTime(body)
+		resetAt := ParseGeminiRateLimitResetTimeWithPolicy(body, s.geminiResetDelayPolicy())
 		defaultDur := s.getDefaultRateLimitDuration()
 
 		// 尝试解析模型 key 并设置模型级限流
@@ -3051,6 +3062,14 @@ func (s *AntigravityGatewayService) getDefaultRateLimitDuration() time.Duration
 	return defaultDur
 }
 
+// geminiResetDelayPolicy 429 重置等待的取整策略（gateway.gemini_rate_limit_reset）
+func (s *AntigravityGatewayService) geminiResetDelayPolicy() GeminiResetDelayPolicy {
+	if s.settingService == nil {
+		return GeminiResetDelayPolicy{}
+	}
+	return geminiResetDelayPolicyFromConfig(s.settingService.cfg)
+}
+
 // resolveResetTime 根据解析的重置时间或默认时长计算重置时间点
 func (s *AntigravityGatewayService) resolveResetTime(resetAt *int64, defaultDur time.Duration) time.Time {
 	if resetAt != nil {
@@ -3072,10 +3091,11 @@ type antigravityClientWriter struct {
 	flusher      http.Flusher
 	disconnected bool
 	prefix       string // 日志前缀，标识来源方法
+	redactor     *responseRedactor
 }
 
-func newAntigravityClientWriter(w gin.ResponseWriter, flusher http.Flusher, prefix string) *antigravityClientWriter {
-	return &antigravityClientWriter{w: w, flusher: flusher, prefix: prefix}
+func newAntigravityClientWriter(w gin.ResponseWriter, flusher http.Flusher, prefix string, redactor *responseRedactor) *antigravityClientWriter {
+	return &antigravityClientWriter{w: w, flusher: flusher, prefix: prefix, redactor: redactor}
 }
 
 // Write 写入数据到客户端，写入失败时标记断开并返回 false
@@ -3083,6 +3103,9 @@ func (cw *antigravityClientWriter) Write(p []byte) bool {
 	if cw.disconnected {
 		return false
 	}
+	if cw.redactor != nil {
+		p = []byte(cw.redactor.RedactSSE(string(p)))
+	}
 	if _, err := cw.w.Write(p); err != nil {
 		cw.markDisconnected()
 		return false
@@ -3096,6 +3119,9 @@ func (cw *antigravityClientWriter) Fprintf(format string, args ...any) bool {
 	if cw.disconnected {
 		return false
 	}
+	if cw.redactor != nil {
+		return cw.Write([]byte(fmt.Sprintf(format, args...)))
+	}
 	if _, err := fmt.Fprintf(cw.w, format, args...); err != nil {
 		cw.markDisconnected()
 		return false
@@ -3216,7 +3242,7 @@ func (s *AntigravityGatewayService) handleGeminiStreamingResponse(c *gin.Context
 	}
 	lastDataAt := time.Now()
 
-	cw := newAntigravityClientWriter(c.Writer, flusher, "antigravity gemini")
+	cw := newAntigravityClientWriter(c.Writer, flusher, "antigravity gemini", s.geminiResponseRedactor)
 
 	// 仅发送一次错误事件，避免多次写入导致协议混乱
 	errorEventSent := false
@@ -3510,7 +3536,7 @@ returnResponse:
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
-	c.Data(http.StatusOK, "application/json", respBody)
+	c.Data(http.StatusOK, "application/json", s.geminiResponseRedactor.RedactBody(respBody))
 
 	return &antigravityStreamResult{usage: usage, firstTokenMs: firstTokenMs}, nil
 }
@@ -3954,7 +3980,7 @@ returnResponse:
 		return nil, s.writeClaudeError(c, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
 	}
 
-	c.Data(http.StatusOK, "application/json", claudeResp)
+	c.Data(http.StatusOK, "application/json", s.claudeResponseRedactor.RedactBody(claudeResp))
 
 	// 转换为 service.ClaudeUsage
 	usage := &ClaudeUsage{
@@ -4066,7 +4092,7 @@ func (s *AntigravityGatewayService) handleClaudeStreamingResponse(c *gin.Context
 	}
 	lastDataAt := time.Now()
 
-	cw := newAntigravityClientWriter(c.Writer, flusher, "antigravity claude")
+	cw := newAntigravityClientWriter(c.Writer, flusher, "antigravity claude", s.claudeResponseRedactor)
 
 	// 仅发送一次错误事件，避免多次写入导致协议混乱
 	errorEventSent := false
@@ -4412,7 +4438,7 @@ func (s *AntigravityGatewayService) ForwardUpstream(ctx context.Context, c *gin.
 
 		c.Header("Content-Type", resp.Header.Get("Content-Type"))
 		c.Status(http.StatusOK)
-		_, _ = c.Writer.Write(respBody)
+		_, _ = c.Writer.Write(s.claudeResponseRedactor.RedactBody(respBody))
 	}
 
 	// 构建计费结果
@@ -4507,7 +4533,7 @@ func (s *AntigravityGatewayService) streamUpstreamResponse(c *gin.Context, resp
 	lastDataAt := time.Now()
 
 	flusher, _ := c.Writer.(http.Flusher)
-	cw := newAntigravityClientWriter(c.Writer, flusher, "antigravity upstream")
+	cw := newAntigravityClientWriter(c.Writer, flusher, "antigravity upstream", s.claudeResponseRedactor)
 
 	for {
 		select {
@@ -1618,7 +1618,7 @@ func TestAntigravityClientWriter(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(rec)
 		flusher, _ := c.Writer.(http.Flusher)
-		cw := newAntigravityClientWriter(c.Writer, flusher, "test")
+		cw := newAntigravityClientWriter(c.Writer, flusher, "test", nil)
 
 		ok := cw.Write([]byte("hello"))
 		require.True(t, ok)
@@ -1632,7 +1632,7 @@ func TestAntigravityClientWriter(t *testing.T) {
 		c, _ := gin.CreateTestContext(rec)
 		fw := &antigravityFailingWriter{ResponseWriter: c.Writer, failAfter: 0}
 		flusher, _ := c.Writer.(http.Flusher)
-		cw := newAntigravityClientWriter(fw, flusher, "test")
+		cw := newAntigravityClientWriter(fw, flusher, "test", nil)
 
 		ok := cw.Write([]byte("hello"))
 		require.False(t, ok)
@@ -1645,13 +1645,33 @@ func TestAntigravityClientWriter(t *testing.T) {
 		c, _ := gin.CreateTestContext(rec)
 		fw := &antigravityFailingWriter{ResponseWriter: c.Writer, failAfter: 0}
 		flusher, _ := c.Writer.(http.Flusher)
-		cw := newAntigravityClientWriter(fw, flusher, "test")
+		cw := newAntigravityClientWriter(fw, flusher, "test", nil)
 
 		cw.Write([]byte("first"))
 		ok := cw.Fprintf("second %d", 2)
 		require.False(t, ok)
 		require.True(t, cw.Disconnected())
 	})
+
+	t.Run("redactor rewrites data lines", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		flusher, _ := c.Writer.(http.Flusher)
+		cfg := &config.Config{}
+		cfg.Security.ResponseRedaction = config.ResponseRedactionConfig{
+			Enabled: true,
+			Platforms: map[string][]config.ResponseRedactionRule{
+				PlatformAnthropic: {{Path: "message.id", Action: config.ResponseRedactionActionBlank}},
+			},
+		}
+		cw := newAntigravityClientWriter(c.Writer, flusher, "test", compileResponseRedactor(cfg, PlatformAnthropic))
+
+		require.True(t, cw.Write([]byte("event: message_start\ndata: {\"message\":{\"id\":\"msg_upstream\"}}\n\n")))
+		require.True(t, cw.Fprintf("%s\n", `data: {"message":{"id":"msg_upstream"}}`))
+		require.NotContains(t, rec.Body.String(), "msg_upstream")
+		require.Equal(t, 2, strings.Count(rec.Body.String(), `"id":""`))
+	})
 }
 
 // TestUnwrapV1InternalResponse 测试 unwrapV1InternalResponse 的各种输入场景
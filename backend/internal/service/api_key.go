@@ -36,6 +36,16 @@ type APIKey struct {
 	Status      string
 	IPWhitelist []string
 	IPBlacklist []string
+	// AccountLabels 账号标签选择器：非空时只调度同时带有全部标签的账号
+	AccountLabels []string
+	// ResponseCacheEnabled 对确定性（temperature=0）非流式请求启用响应缓存，无需携带 X-Cache 请求头
+	ResponseCacheEnabled bool
+	// CostPreviewEnabled 在所有网关响应中通过 X-Estimated-Cost 响应头返回请求前的费用估算
+	CostPreviewEnabled bool
+	// Scopes 可调用的端点作用域（chat/images/video/embeddings），为空表示全部
+	Scopes []string
+	// AllowedModels 可调用的模型白名单（别名解析后匹配，支持末尾 *），为空表示全部
+	AllowedModels []string
 	// 预编译的 IP 规则，用于认证热路径避免重复 ParseIP/ParseCIDR。
 	CompiledIPWhitelist *ip.CompiledIPRules `json:"-"`
 	CompiledIPBlacklist *ip.CompiledIPRules `json:"-"`
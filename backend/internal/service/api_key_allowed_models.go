@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// maxAPIKeyAllowedModels 单个 Key 模型白名单的条目上限
+const maxAPIKeyAllowedModels = 200
+
+var ErrInvalidAPIKeyAllowedModels = infraerrors.BadRequest("INVALID_API_KEY_ALLOWED_MODELS", "invalid api key allowed models")
+
+// NormalizeAPIKeyAllowedModels 规范化模型白名单：去除首尾空白、转小写并去重，保留原顺序。
+// 空列表或包含 "*" 时返回 nil（不限制）；通配符仅支持末尾 *（与分组模型路由一致）。
+func NormalizeAPIKeyAllowedModels(models []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(models))
+	out := make([]string, 0, len(models))
+	for _, model := range models {
+		model = strings.ToLower(strings.TrimSpace(model))
+		if model == "" {
+			continue
+		}
+		if model == "*" {
+			return nil, nil
+		}
+		if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+			return nil, ErrInvalidAPIKeyAllowedModels.WithMetadata(map[string]string{"model": model})
+		}
+		if _, ok := seen[model]; ok {
+			continue
+		}
+		seen[model] = struct{}{}
+		out = append(out, model)
+	}
+	if len(out) > maxAPIKeyAllowedModels {
+		return nil, ErrInvalidAPIKeyAllowedModels.WithMetadata(map[string]string{"limit": fmt.Sprintf("%d", maxAPIKeyAllowedModels)})
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// ResolveAPIKeyAllowlistModel 返回用于白名单校验的模型名：先做与账号无关的别名规范化
+// （如 "GPT5_Codex" → "gpt-5-codex"），非 OpenAI 命名时退回原始模型名（小写）。
+// 渠道映射依赖分组配置，映射后的目标模型由 handler 在 ResolveChannelMappingAndRestrict 之后再校验一次。
+func ResolveAPIKeyAllowlistModel(model string) string {
+	if canonical := canonicalizeOpenAIModelAliasSpelling(model); canonical != "" {
+		return canonical
+	}
+	return strings.ToLower(strings.TrimSpace(model))
+}
+
+// IsModelAllowed Key 是否允许调用该模型；未设置白名单时允许全部。
+// 别名先解析为目标模型再匹配，避免通过别名绕过白名单。
+func (k *APIKey) IsModelAllowed(model string) bool {
+	if k == nil || len(k.AllowedModels) == 0 {
+		return true
+	}
+	resolved := ResolveAPIKeyAllowlistModel(model)
+	if resolved == "" {
+		return false
+	}
+	for _, pattern := range k.AllowedModels {
+		if matchModelPattern(pattern, resolved) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyModelNotAllowedMessage 模型不在 Key 白名单时返回给客户端的错误信息
+func APIKeyModelNotAllowedMessage(model string) string {
+	return fmt.Sprintf("model %q is not allowed for this API key", strings.TrimSpace(model))
+}
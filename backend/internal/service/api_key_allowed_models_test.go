@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyAllowedModels(t *testing.T) {
+	models, err := NormalizeAPIKeyAllowedModels([]string{" GPT-5.1 ", "gpt-5.1", "", "claude-*"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"gpt-5.1", "claude-*"}, models)
+
+	models, err = NormalizeAPIKeyAllowedModels(nil)
+	require.NoError(t, err)
+	require.Nil(t, models)
+
+	models, err = NormalizeAPIKeyAllowedModels([]string{"gpt-5.1", "*"})
+	require.NoError(t, err)
+	require.Nil(t, models, "* means unrestricted")
+
+	_, err = NormalizeAPIKeyAllowedModels([]string{"gpt-*-mini"})
+	require.ErrorIs(t, err, ErrInvalidAPIKeyAllowedModels)
+}
+
+func TestAPIKeyIsModelAllowed(t *testing.T) {
+	require.True(t, (&APIKey{}).IsModelAllowed("anything"), "empty allowlist allows all models")
+
+	key := &APIKey{AllowedModels: []string{"gpt-5.1-codex", "claude-sonnet-*"}}
+	require.True(t, key.IsModelAllowed("gpt-5.1-codex"))
+	require.True(t, key.IsModelAllowed("openai/GPT5.1_Codex"), "aliases are checked against their resolved target")
+	require.True(t, key.IsModelAllowed("Claude-Sonnet-4-5"))
+	require.False(t, key.IsModelAllowed("gpt-5.4"))
+	require.False(t, key.IsModelAllowed(""))
+}
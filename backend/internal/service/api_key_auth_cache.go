@@ -4,16 +4,25 @@ import "time"
 
 // APIKeyAuthSnapshot API Key 认证缓存快照（仅包含认证所需字段）
 type APIKeyAuthSnapshot struct {
-	Version     int                      `json:"version"`
-	APIKeyID    int64                    `json:"api_key_id"`
-	UserID      int64                    `json:"user_id"`
-	GroupID     *int64                   `json:"group_id,omitempty"`
-	Name        string                   `json:"name"`
-	Status      string                   `json:"status"`
-	IPWhitelist []string                 `json:"ip_whitelist,omitempty"`
-	IPBlacklist []string                 `json:"ip_blacklist,omitempty"`
-	User        APIKeyAuthUserSnapshot   `json:"user"`
-	Group       *APIKeyAuthGroupSnapshot `json:"group,omitempty"`
+	Version       int      `json:"version"`
+	APIKeyID      int64    `json:"api_key_id"`
+	UserID        int64    `json:"user_id"`
+	GroupID       *int64   `json:"group_id,omitempty"`
+	Name          string   `json:"name"`
+	Status        string   `json:"status"`
+	IPWhitelist   []string `json:"ip_whitelist,omitempty"`
+	IPBlacklist   []string `json:"ip_blacklist,omitempty"`
+	AccountLabels []string `json:"account_labels,omitempty"`
+	// ResponseCacheEnabled 响应缓存开关
+	ResponseCacheEnabled bool `json:"response_cache_enabled,omitempty"`
+	// CostPreviewEnabled 费用预览开关
+	CostPreviewEnabled bool `json:"cost_preview_enabled,omitempty"`
+	// Scopes 端点作用域（空表示全部）
+	Scopes []string `json:"scopes,omitempty"`
+	// AllowedModels 模型白名单（空表示全部）
+	AllowedModels []string                 `json:"allowed_models,omitempty"`
+	User          APIKeyAuthUserSnapshot   `json:"user"`
+	Group         *APIKeyAuthGroupSnapshot `json:"group,omitempty"`
 
 	// Quota fields for API Key independent quota feature
 	Quota     float64 `json:"quota"`      // Quota limit in USD (0 = unlimited)
@@ -93,6 +102,20 @@ type APIKeyAuthGroupSnapshot struct {
 
 	// RPMLimit 分组级每分钟请求数上限（0 = 不限制）；用于 billing_cache_service.checkRPM 级联判断。
 	RPMLimit int `json:"rpm_limit"`
+
+	// 自动 prompt caching 断点注入（仅 anthropic 平台使用）
+	PromptCacheInject bool `json:"prompt_cache_inject"`
+
+	// 模型降级规则（请求模型无可用账号时按序尝试）
+	ModelFallbackConfig GroupModelFallbackConfig `json:"model_fallback_config,omitempty"`
+
+	// OpenAI Responses instructions 注入配置
+	InstructionInjectionConfig GroupInstructionInjectionConfig `json:"instruction_injection_config,omitempty"`
+
+	// 分组日/月消费上限及周期边界时区
+	SpendCapDailyUSD   *float64 `json:"spend_cap_daily_usd,omitempty"`
+	SpendCapMonthlyUSD *float64 `json:"spend_cap_monthly_usd,omitempty"`
+	SpendCapTimezone   string   `json:"spend_cap_timezone,omitempty"`
 }
 
 // APIKeyAuthCacheEntry 缓存条目，支持负缓存
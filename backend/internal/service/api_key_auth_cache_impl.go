@@ -14,7 +14,7 @@ import (
 	"github.com/dgraph-io/ristretto"
 )
 
-const apiKeyAuthSnapshotVersion = 12 // v12: include exclusive group authorization fields
+const apiKeyAuthSnapshotVersion = 17 // v17: include group instruction injection config
 
 type apiKeyAuthCacheConfig struct {
 	l1Size        int
@@ -206,20 +206,25 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 		return nil
 	}
 	snapshot := &APIKeyAuthSnapshot{
-		Version:     apiKeyAuthSnapshotVersion,
-		APIKeyID:    apiKey.ID,
-		UserID:      apiKey.UserID,
-		GroupID:     apiKey.GroupID,
-		Name:        apiKey.Name,
-		Status:      apiKey.Status,
-		IPWhitelist: apiKey.IPWhitelist,
-		IPBlacklist: apiKey.IPBlacklist,
-		Quota:       apiKey.Quota,
-		QuotaUsed:   apiKey.QuotaUsed,
-		ExpiresAt:   apiKey.ExpiresAt,
-		RateLimit5h: apiKey.RateLimit5h,
-		RateLimit1d: apiKey.RateLimit1d,
-		RateLimit7d: apiKey.RateLimit7d,
+		Version:              apiKeyAuthSnapshotVersion,
+		APIKeyID:             apiKey.ID,
+		UserID:               apiKey.UserID,
+		GroupID:              apiKey.GroupID,
+		Name:                 apiKey.Name,
+		Status:               apiKey.Status,
+		IPWhitelist:          apiKey.IPWhitelist,
+		IPBlacklist:          apiKey.IPBlacklist,
+		AccountLabels:        apiKey.AccountLabels,
+		ResponseCacheEnabled: apiKey.ResponseCacheEnabled,
+		CostPreviewEnabled:   apiKey.CostPreviewEnabled,
+		Scopes:               apiKey.Scopes,
+		AllowedModels:        apiKey.AllowedModels,
+		Quota:                apiKey.Quota,
+		QuotaUsed:            apiKey.QuotaUsed,
+		ExpiresAt:            apiKey.ExpiresAt,
+		RateLimit5h:          apiKey.RateLimit5h,
+		RateLimit1d:          apiKey.RateLimit1d,
+		RateLimit7d:          apiKey.RateLimit7d,
 		User: APIKeyAuthUserSnapshot{
 			ID:                         apiKey.User.ID,
 			Status:                     apiKey.User.Status,
@@ -276,6 +281,12 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			MessagesDispatchModelConfig:     apiKey.Group.MessagesDispatchModelConfig,
 			ModelsListConfig:                apiKey.Group.ModelsListConfig,
 			RPMLimit:                        apiKey.Group.RPMLimit,
+			PromptCacheInject:               apiKey.Group.PromptCacheInject,
+			ModelFallbackConfig:             apiKey.Group.ModelFallbackConfig,
+			InstructionInjectionConfig:      apiKey.Group.InstructionInjectionConfig,
+			SpendCapDailyUSD:                apiKey.Group.SpendCapDailyUSD,
+			SpendCapMonthlyUSD:              apiKey.Group.SpendCapMonthlyUSD,
+			SpendCapTimezone:                apiKey.Group.SpendCapTimezone,
 		}
 	}
 	return snapshot
@@ -286,20 +297,25 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 		return nil
 	}
 	apiKey := &APIKey{
-		ID:          snapshot.APIKeyID,
-		UserID:      snapshot.UserID,
-		GroupID:     snapshot.GroupID,
-		Key:         key,
-		Name:        snapshot.Name,
-		Status:      snapshot.Status,
-		IPWhitelist: snapshot.IPWhitelist,
-		IPBlacklist: snapshot.IPBlacklist,
-		Quota:       snapshot.Quota,
-		QuotaUsed:   snapshot.QuotaUsed,
-		ExpiresAt:   snapshot.ExpiresAt,
-		RateLimit5h: snapshot.RateLimit5h,
-		RateLimit1d: snapshot.RateLimit1d,
-		RateLimit7d: snapshot.RateLimit7d,
+		ID:                   snapshot.APIKeyID,
+		UserID:               snapshot.UserID,
+		GroupID:              snapshot.GroupID,
+		Key:                  key,
+		Name:                 snapshot.Name,
+		Status:               snapshot.Status,
+		IPWhitelist:          snapshot.IPWhitelist,
+		IPBlacklist:          snapshot.IPBlacklist,
+		AccountLabels:        snapshot.AccountLabels,
+		ResponseCacheEnabled: snapshot.ResponseCacheEnabled,
+		CostPreviewEnabled:   snapshot.CostPreviewEnabled,
+		Scopes:               snapshot.Scopes,
+		AllowedModels:        snapshot.AllowedModels,
+		Quota:                snapshot.Quota,
+		QuotaUsed:            snapshot.QuotaUsed,
+		ExpiresAt:            snapshot.ExpiresAt,
+		RateLimit5h:          snapshot.RateLimit5h,
+		RateLimit1d:          snapshot.RateLimit1d,
+		RateLimit7d:          snapshot.RateLimit7d,
 		User: &User{
 			ID:                         snapshot.User.ID,
 			Status:                     snapshot.User.Status,
@@ -349,6 +365,12 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			MessagesDispatchModelConfig:     snapshot.Group.MessagesDispatchModelConfig,
 			ModelsListConfig:                snapshot.Group.ModelsListConfig,
 			RPMLimit:                        snapshot.Group.RPMLimit,
+			PromptCacheInject:               snapshot.Group.PromptCacheInject,
+			ModelFallbackConfig:             snapshot.Group.ModelFallbackConfig,
+			InstructionInjectionConfig:      snapshot.Group.InstructionInjectionConfig,
+			SpendCapDailyUSD:                snapshot.Group.SpendCapDailyUSD,
+			SpendCapMonthlyUSD:              snapshot.Group.SpendCapMonthlyUSD,
+			SpendCapTimezone:                snapshot.Group.SpendCapTimezone,
 		}
 	}
 	s.compileAPIKeyIPRules(apiKey)
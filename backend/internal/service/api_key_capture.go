@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+var (
+	ErrAPIKeyCaptureInvalidHours = infraerrors.BadRequest("API_KEY_CAPTURE_INVALID_HOURS", "capture hours must be between 1 and the configured maximum")
+)
+
+// APIKeyCapture 一条抓取记录：最终发往上游的请求与上游响应（鉴权头/token 已脱敏，正文已截断）。
+type APIKeyCapture struct {
+	ID                int64             `json:"id"`
+	APIKeyID          int64             `json:"api_key_id"`
+	AccountID         int64             `json:"account_id"`
+	Method            string            `json:"method"`
+	URL               string            `json:"url"`
+	RequestHeaders    map[string]string `json:"request_headers"`
+	RequestBody       string            `json:"request_body"`
+	RequestTruncated  bool              `json:"request_truncated"`
+	ResponseStatus    int               `json:"response_status"`
+	ResponseHeaders   map[string]string `json:"response_headers"`
+	ResponseBody      string            `json:"response_body"`
+	ResponseTruncated bool              `json:"response_truncated"`
+	ErrorMessage      string            `json:"error_message,omitempty"`
+	DurationMs        int64             `json:"duration_ms"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+// APIKeyCaptureStatus API Key 当前的抓取开关状态。
+type APIKeyCaptureStatus struct {
+	APIKeyID     int64      `json:"api_key_id"`
+	Active       bool       `json:"active"`
+	CaptureUntil *time.Time `json:"capture_until"`
+}
+
+// APIKeyCaptureRepository 抓取开关与抓取记录的持久化接口。
+type APIKeyCaptureRepository interface {
+	// SetCaptureUntil 设置 Key 的抓取截止时间；until 为 nil 表示关闭。Key 不存在时返回 ErrAPIKeyNotFound。
+	SetCaptureUntil(ctx context.Context, apiKeyID int64, until *time.Time) error
+	// GetCaptureUntil 读取 Key 的抓取截止时间。
+	GetCaptureUntil(ctx context.Context, apiKeyID int64) (*time.Time, error)
+	// ListActiveCaptures 返回截止时间晚于 now 的 Key → 截止时间。
+	ListActiveCaptures(ctx context.Context, now time.Time) (map[int64]time.Time, error)
+	Insert(ctx context.Context, capture *APIKeyCapture) error
+	List(ctx context.Context, apiKeyID int64, params pagination.PaginationParams) ([]APIKeyCapture, *pagination.PaginationResult, error)
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/util/logredact"
+)
+
+const (
+	// apiKeyCaptureRefreshInterval 本地抓取开关缓存的刷新周期（多实例下最多延迟该时长生效）
+	apiKeyCaptureRefreshInterval = 30 * time.Second
+	// apiKeyCaptureWriteTimeout 单条抓取记录写库超时
+	apiKeyCaptureWriteTimeout = 5 * time.Second
+
+	defaultAPIKeyCaptureMaxBodyBytes = 64 * 1024
+	defaultAPIKeyCaptureMaxHours     = 72
+	defaultAPIKeyCaptureQueueSize    = 256
+)
+
+// apiKeyCaptureSensitiveHeaders 抓取时整体脱敏的请求/响应头（小写）
+var apiKeyCaptureSensitiveHeaders = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"x-api-key":           {},
+	"api-key":             {},
+	"x-goog-api-key":      {},
+	"cookie":              {},
+	"set-cookie":          {},
+	"chatgpt-account-id":  {},
+}
+
+// apiKeyCaptureSensitiveKeys 在 logredact 默认敏感字段之外，额外脱敏的正文字段与 URL 查询参数
+var apiKeyCaptureSensitiveKeys = []string{
+	"key",
+	"api_key",
+	"apikey",
+	"x-api-key",
+	"authorization",
+	"token",
+	"session_token",
+	"secret",
+	"private_key",
+}
+
+// APIKeyCaptureService 管理按 API Key 的上游请求/响应抓取：
+// 开关持久化在 api_keys.capture_until（到期自动失效），热路径只读本地缓存；
+// 抓取记录经有界队列异步写库，队列满时直接丢弃，绝不阻塞请求。
+type APIKeyCaptureService struct {
+	repo         APIKeyCaptureRepository
+	maxBodyBytes int
+	maxHours     int
+	now          func() time.Time
+
+	active      atomic.Pointer[map[int64]time.Time]
+	refreshedAt atomic.Int64
+	refreshing  atomic.Bool
+
+	queue    chan *APIKeyCapture
+	dropped  atomic.Int64
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewAPIKeyCaptureService 创建抓取服务，加载当前生效的抓取开关并启动异步写入 worker。
+func NewAPIKeyCaptureService(repo APIKeyCaptureRepository, cfg *config.Config) *APIKeyCaptureService {
+	s := &APIKeyCaptureService{
+		repo:         repo,
+		maxBodyBytes: defaultAPIKeyCaptureMaxBodyBytes,
+		maxHours:     defaultAPIKeyCaptureMaxHours,
+		now:          time.Now,
+		stopCh:       make(chan struct{}),
+	}
+	queueSize := defaultAPIKeyCaptureQueueSize
+	if cfg != nil {
+		if cfg.Gateway.Capture.MaxBodyBytes > 0 {
+			s.maxBodyBytes = cfg.Gateway.Capture.MaxBodyBytes
+		}
+		if cfg.Gateway.Capture.MaxHours > 0 {
+			s.maxHours = cfg.Gateway.Capture.MaxHours
+		}
+		if cfg.Gateway.Capture.QueueSize > 0 {
+			queueSize = cfg.Gateway.Capture.QueueSize
+		}
+	}
+	s.queue = make(chan *APIKeyCapture, queueSize)
+	empty := map[int64]time.Time{}
+	s.active.Store(&empty)
+
+	if repo != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), apiKeyCaptureWriteTimeout)
+		if err := s.refresh(ctx); err != nil {
+			logger.LegacyPrintf("service.api_key_capture", "[APIKeyCapture] Failed to load active captures on startup: %v", err)
+		}
+		cancel()
+	}
+
+	s.wg.Add(1)
+	go s.runWriter()
+	return s
+}
+
+// Stop 停止异步写入 worker，并尽量写完队列中剩余的记录。
+func (s *APIKeyCaptureService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		s.wg.Wait()
+	})
+}
+
+// MaxBodyBytes 返回单个请求/响应体保存的最大字节数。
+func (s *APIKeyCaptureService) MaxBodyBytes() int {
+	return s.maxBodyBytes
+}
+
+// IsCapturing 判断该 Key 当前是否处于抓取期（热路径：仅读本地缓存，过期刷新在后台进行）。
+func (s *APIKeyCaptureService) IsCapturing(apiKeyID int64) bool {
+	if s == nil || apiKeyID <= 0 {
+		return false
+	}
+	s.maybeRefresh()
+	until, ok := (*s.active.Load())[apiKeyID]
+	return ok && s.now().Before(until)
+}
+
+// Enable 为 Key 开启抓取，hours 小时后自动关闭。
+func (s *APIKeyCaptureService) Enable(ctx context.Context, apiKeyID int64, hours int) (*APIKeyCaptureStatus, error) {
+	if hours <= 0 || hours > s.maxHours {
+		return nil, ErrAPIKeyCaptureInvalidHours
+	}
+	until := s.now().Add(time.Duration(hours) * time.Hour)
+	if err := s.repo.SetCaptureUntil(ctx, apiKeyID, &until); err != nil {
+		return nil, err
+	}
+	s.setLocal(apiKeyID, &until)
+	return &APIKeyCaptureStatus{APIKeyID: apiKeyID, Active: true, CaptureUntil: &until}, nil
+}
+
+// Disable 立即关闭 Key 的抓取。
+func (s *APIKeyCaptureService) Disable(ctx context.Context, apiKeyID int64) (*APIKeyCaptureStatus, error) {
+	if err := s.repo.SetCaptureUntil(ctx, apiKeyID, nil); err != nil {
+		return nil, err
+	}
+	s.setLocal(apiKeyID, nil)
+	return &APIKeyCaptureStatus{APIKeyID: apiKeyID}, nil
+}
+
+// Status 返回 Key 的抓取开关状态（以数据库为准）。
+func (s *APIKeyCaptureService) Status(ctx context.Context, apiKeyID int64) (*APIKeyCaptureStatus, error) {
+	until, err := s.repo.GetCaptureUntil(ctx, apiKeyID)
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeyCaptureStatus{
+		APIKeyID:     apiKeyID,
+		Active:       until != nil && s.now().Before(*until),
+		CaptureUntil: until,
+	}, nil
+}
+
+// List 分页查询 Key 的抓取记录（按时间倒序）。
+func (s *APIKeyCaptureService) List(ctx context.Context, apiKeyID int64, params pagination.PaginationParams) ([]APIKeyCapture, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, apiKeyID, params)
+}
+
+// Enqueue 异步提交一条抓取记录；队列已满或服务已停止时丢弃。
+func (s *APIKeyCaptureService) Enqueue(capture *APIKeyCapture) {
+	if s == nil || capture == nil {
+		return
+	}
+	select {
+	case <-s.stopCh:
+		return
+	default:
+	}
+	select {
+	case s.queue <- capture:
+	default:
+		if n := s.dropped.Add(1); n == 1 || n%100 == 0 {
+			logger.LegacyPrintf("service.api_key_capture", "[APIKeyCapture] Queue full, dropped %d capture(s): api_key=%d", n, capture.APIKeyID)
+		}
+	}
+}
+
+func (s *APIKeyCaptureService) runWriter() {
+	defer s.wg.Done()
+	for {
+		select {
+		case capture := <-s.queue:
+			s.write(capture)
+		case <-s.stopCh:
+			for {
+				select {
+				case capture := <-s.queue:
+					s.write(capture)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *APIKeyCaptureService) write(capture *APIKeyCapture) {
+	if s.repo == nil || capture == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCaptureWriteTimeout)
+	defer cancel()
+	if err := s.repo.Insert(ctx, capture); err != nil {
+		logger.LegacyPrintf("service.api_key_capture", "[APIKeyCapture] Failed to write capture: api_key=%d err=%v", capture.APIKeyID, err)
+	}
+}
+
+func (s *APIKeyCaptureService) maybeRefresh() {
+	if s.repo == nil || s.now().UnixNano()-s.refreshedAt.Load() < int64(apiKeyCaptureRefreshInterval) {
+		return
+	}
+	if !s.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.refreshing.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), apiKeyCaptureWriteTimeout)
+		defer cancel()
+		if err := s.refresh(ctx); err != nil {
+			logger.LegacyPrintf("service.api_key_capture", "[APIKeyCapture] Failed to refresh active captures: %v", err)
+		}
+	}()
+}
+
+func (s *APIKeyCaptureService) refresh(ctx context.Context) error {
+	now := s.now()
+	// 无论成功与否都推进刷新时间，避免 DB 异常时每个请求都触发刷新。
+	s.refreshedAt.Store(now.UnixNano())
+	active, err := s.repo.ListActiveCaptures(ctx, now)
+	if err != nil {
+		return err
+	}
+	if active == nil {
+		active = map[int64]time.Time{}
+	}
+	s.active.Store(&active)
+	return nil
+}
+
+// setLocal 以写时复制方式更新本地开关缓存，使本实例立即生效。
+func (s *APIKeyCaptureService) setLocal(apiKeyID int64, until *time.Time) {
+	for {
+		current := s.active.Load()
+		next := make(map[int64]time.Time, len(*current)+1)
+		for id, t := range *current {
+			next[id] = t
+		}
+		if until == nil {
+			delete(next, apiKeyID)
+		} else {
+			next[apiKeyID] = *until
+		}
+		if s.active.CompareAndSwap(current, &next) {
+			return
+		}
+	}
+}
+
+// redactCaptureHeaders 展平并脱敏 header：鉴权类 header 整体替换为 ***。
+func redactCaptureHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if _, ok := apiKeyCaptureSensitiveHeaders[strings.ToLower(name)]; ok {
+			out[name] = "***"
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// redactCaptureURL 脱敏 URL 查询参数中的 key/token 等凭据（如 Gemini 的 ?key=）。
+func redactCaptureURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	query := u.Query()
+	sensitive := make(map[string]struct{}, len(apiKeyCaptureSensitiveKeys))
+	for _, k := range apiKeyCaptureSensitiveKeys {
+		sensitive[k] = struct{}{}
+	}
+	for name := range query {
+		lower := strings.ToLower(name)
+		if _, ok := sensitive[lower]; ok || strings.Contains(lower, "token") {
+			query[name] = []string{"***"}
+		}
+	}
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return copied.String()
+}
+
+// redactCaptureBody 脱敏正文并截断到 limit 字节，返回结果及是否发生截断。
+// truncated 表示 body 本身已是截断后的前缀；完整 JSON 按字段脱敏，其余按文本规则兜底。
+func redactCaptureBody(body []byte, truncated bool, limit int) (string, bool) {
+	if len(body) == 0 {
+		return "", truncated
+	}
+	var redacted string
+	if !truncated && json.Valid(body) {
+		redacted = logredact.RedactJSON(body, apiKeyCaptureSensitiveKeys...)
+	} else {
+		redacted = logredact.RedactText(string(body), apiKeyCaptureSensitiveKeys...)
+	}
+	if limit > 0 && len(redacted) > limit {
+		redacted = redacted[:limit]
+		truncated = true
+	}
+	// PostgreSQL TEXT 不接受非法 UTF-8 与 NUL，截断也可能切断多字节字符。
+	redacted = strings.ReplaceAll(strings.ToValidUTF8(redacted, ""), "\x00", "")
+	return redacted, truncated
+}
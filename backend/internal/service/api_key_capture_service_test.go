@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/stretchr/testify/require"
+)
+
+type captureRepoStub struct {
+	mu       sync.Mutex
+	until    map[int64]time.Time
+	inserted []*APIKeyCapture
+	insertCh chan struct{}
+	block    chan struct{}
+}
+
+func newCaptureRepoStub() *captureRepoStub {
+	return &captureRepoStub{until: map[int64]time.Time{}, insertCh: make(chan struct{}, 16)}
+}
+
+func (r *captureRepoStub) SetCaptureUntil(_ context.Context, apiKeyID int64, until *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until == nil {
+		delete(r.until, apiKeyID)
+	} else {
+		r.until[apiKeyID] = *until
+	}
+	return nil
+}
+
+func (r *captureRepoStub) GetCaptureUntil(_ context.Context, apiKeyID int64) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.until[apiKeyID]; ok {
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (r *captureRepoStub) ListActiveCaptures(_ context.Context, now time.Time) (map[int64]time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := map[int64]time.Time{}
+	for id, t := range r.until {
+		if t.After(now) {
+			out[id] = t
+		}
+	}
+	return out, nil
+}
+
+func (r *captureRepoStub) Insert(_ context.Context, capture *APIKeyCapture) error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	r.inserted = append(r.inserted, capture)
+	r.mu.Unlock()
+	r.insertCh <- struct{}{}
+	return nil
+}
+
+func (r *captureRepoStub) List(context.Context, int64, pagination.PaginationParams) ([]APIKeyCapture, *pagination.PaginationResult, error) {
+	return nil, &pagination.PaginationResult{}, nil
+}
+
+func (r *captureRepoStub) waitInsert(t *testing.T) *APIKeyCapture {
+	t.Helper()
+	select {
+	case <-r.insertCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("capture was not written")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.inserted[len(r.inserted)-1]
+}
+
+type captureUpstreamStub struct {
+	resp *http.Response
+	err  error
+}
+
+func (u *captureUpstreamStub) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	_, _ = io.ReadAll(req.Body)
+	return u.resp, u.err
+}
+
+func (u *captureUpstreamStub) DoWithTLS(req *http.Request, proxyURL string, accountID int64, concurrency int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, concurrency)
+}
+
+func newCaptureTestService(t *testing.T, repo *captureRepoStub, maxBodyBytes int) *APIKeyCaptureService {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Gateway.Capture.MaxBodyBytes = maxBodyBytes
+	cfg.Gateway.Capture.MaxHours = 24
+	cfg.Gateway.Capture.QueueSize = 4
+	svc := NewAPIKeyCaptureService(repo, cfg)
+	t.Cleanup(svc.Stop)
+	return svc
+}
+
+func newCaptureTestRequest(t *testing.T, apiKeyID int64, rawURL, body string) *http.Request {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), ctxkey.APIKeyID, apiKeyID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("x-goog-api-key", "AIza-secret")
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestRedactCaptureHeaders_MasksAuthHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer sk-secret")
+	h.Set("X-Api-Key", "sk-ant-secret")
+	h.Set("Cookie", "session=abc")
+	h.Set("Content-Type", "application/json")
+
+	out := redactCaptureHeaders(h)
+	require.Equal(t, "***", out["Authorization"])
+	require.Equal(t, "***", out["X-Api-Key"])
+	require.Equal(t, "***", out["Cookie"])
+	require.Equal(t, "application/json", out["Content-Type"])
+}
+
+func TestRedactCaptureURL_MasksCredentialQuery(t *testing.T) {
+	u, err := url.Parse("https://generativelanguage.googleapis.com/v1beta/models/x:generateContent?key=AIza-secret&alt=sse&access_token=tok")
+	require.NoError(t, err)
+
+	out := redactCaptureURL(u)
+	require.NotContains(t, out, "AIza-secret")
+	require.NotContains(t, out, "tok&")
+	require.Contains(t, out, "alt=sse")
+	require.Contains(t, out, "key=%2A%2A%2A")
+}
+
+func TestRedactCaptureBody_RedactsTokensAndTruncates(t *testing.T) {
+	body := []byte(`{"model":"claude","refresh_token":"rt-secret","api_key":"sk-secret","messages":[]}`)
+	out, truncated := redactCaptureBody(body, false, 1024)
+	require.False(t, truncated)
+	require.NotContains(t, out, "rt-secret")
+	require.NotContains(t, out, "sk-secret")
+	require.Contains(t, out, `"model":"claude"`)
+
+	out, truncated = redactCaptureBody([]byte(`access_token=tok-secret `+strings.Repeat("x", 100)), true, 40)
+	require.True(t, truncated)
+	require.LessOrEqual(t, len(out), 40)
+	require.NotContains(t, out, "tok-secret")
+}
+
+func TestAPIKeyCaptureService_ExpiresAfterDeadline(t *testing.T) {
+	repo := newCaptureRepoStub()
+	svc := newCaptureTestService(t, repo, 1024)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	svc.refreshedAt.Store(now.UnixNano())
+
+	status, err := svc.Enable(context.Background(), 7, 2)
+	require.NoError(t, err)
+	require.True(t, status.Active)
+	require.True(t, svc.IsCapturing(7))
+	require.False(t, svc.IsCapturing(8))
+
+	now = now.Add(2*time.Hour + time.Second)
+	require.False(t, svc.IsCapturing(7))
+	status, err = svc.Status(context.Background(), 7)
+	require.NoError(t, err)
+	require.False(t, status.Active)
+	require.NotNil(t, status.CaptureUntil)
+}
+
+func TestAPIKeyCaptureService_EnableRejectsInvalidHours(t *testing.T) {
+	svc := newCaptureTestService(t, newCaptureRepoStub(), 1024)
+
+	_, err := svc.Enable(context.Background(), 1, 0)
+	require.ErrorIs(t, err, ErrAPIKeyCaptureInvalidHours)
+	_, err = svc.Enable(context.Background(), 1, 25)
+	require.ErrorIs(t, err, ErrAPIKeyCaptureInvalidHours)
+}
+
+func TestAPIKeyCaptureService_DisableStopsCapture(t *testing.T) {
+	svc := newCaptureTestService(t, newCaptureRepoStub(), 1024)
+
+	_, err := svc.Enable(context.Background(), 3, 1)
+	require.NoError(t, err)
+	_, err = svc.Disable(context.Background(), 3)
+	require.NoError(t, err)
+	require.False(t, svc.IsCapturing(3))
+}
+
+func TestAPIKeyCaptureService_EnqueueNeverBlocks(t *testing.T) {
+	repo := newCaptureRepoStub()
+	repo.block = make(chan struct{})
+	svc := newCaptureTestService(t, repo, 1024)
+	defer close(repo.block)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 50; i++ {
+			svc.Enqueue(&APIKeyCapture{APIKeyID: 1})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue blocked on a full queue")
+	}
+	require.Positive(t, svc.dropped.Load())
+}
+
+func TestCapturingHTTPUpstream_CapturesRedactedExchange(t *testing.T) {
+	repo := newCaptureRepoStub()
+	svc := newCaptureTestService(t, repo, 32)
+	_, err := svc.Enable(context.Background(), 11, 1)
+	require.NoError(t, err)
+
+	inner := &captureUpstreamStub{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"msg_1","content":"` + strings.Repeat("a", 64) + `"}`)),
+	}}
+	upstream := NewCapturingHTTPUpstream(inner, svc)
+
+	req := newCaptureTestRequest(t, 11, "https://api.example.com/v1/messages", `{"model":"m","access_token":"tok-secret"}`)
+	resp, err := upstream.Do(req, "", 5, 1)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Len(t, body, 91, "client must still receive the full body")
+	require.NoError(t, resp.Body.Close())
+
+	capture := repo.waitInsert(t)
+	require.Equal(t, int64(11), capture.APIKeyID)
+	require.Equal(t, int64(5), capture.AccountID)
+	require.Equal(t, "***", capture.RequestHeaders["Authorization"])
+	require.Equal(t, "***", capture.RequestHeaders["X-Goog-Api-Key"])
+	require.NotContains(t, capture.RequestBody, "tok-secret")
+	require.Equal(t, http.StatusOK, capture.ResponseStatus)
+	require.True(t, capture.ResponseTruncated)
+	require.LessOrEqual(t, len(capture.ResponseBody), 32)
+}
+
+func TestCapturingHTTPUpstream_SkipsOversizedStream(t *testing.T) {
+	repo := newCaptureRepoStub()
+	svc := newCaptureTestService(t, repo, 16)
+	_, err := svc.Enable(context.Background(), 12, 1)
+	require.NoError(t, err)
+
+	inner := &captureUpstreamStub{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(strings.Repeat("data: {}\n\n", 10))),
+	}}
+	upstream := NewCapturingHTTPUpstream(inner, svc)
+
+	resp, err := upstream.Do(newCaptureTestRequest(t, 12, "https://api.example.com/v1/messages", `{}`), "", 1, 1)
+	require.NoError(t, err)
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	svc.Stop()
+	require.Empty(t, repo.inserted)
+}
+
+func TestCapturingHTTPUpstream_IgnoresKeysWithoutCapture(t *testing.T) {
+	repo := newCaptureRepoStub()
+	svc := newCaptureTestService(t, repo, 1024)
+
+	inner := &captureUpstreamStub{resp: &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{}`))}}
+	upstream := NewCapturingHTTPUpstream(inner, svc)
+
+	resp, err := upstream.Do(newCaptureTestRequest(t, 99, "https://api.example.com/v1/messages", `{}`), "", 1, 1)
+	require.NoError(t, err)
+	_, wrapped := resp.Body.(*captureResponseBody)
+	require.False(t, wrapped)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+)
+
+// capturingHTTPUpstream 在 HTTPUpstream 外层按 API Key 抓取最终上游请求/响应。
+// 抓取发生在所有改写（指纹、user_id、thoughtSignature 等）之后，记录的即是实际发出的内容；
+// 未开启抓取的 Key 只多一次 context 读取与本地 map 查找。
+type capturingHTTPUpstream struct {
+	inner   HTTPUpstream
+	capture *APIKeyCaptureService
+}
+
+// NewCapturingHTTPUpstream 包装 HTTPUpstream；capture 为 nil 时原样返回。
+func NewCapturingHTTPUpstream(inner HTTPUpstream, capture *APIKeyCaptureService) HTTPUpstream {
+	if inner == nil || capture == nil {
+		return inner
+	}
+	return &capturingHTTPUpstream{inner: inner, capture: capture}
+}
+
+func (u *capturingHTTPUpstream) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	rec := u.capture.beginCapture(req, accountID)
+	resp, err := u.inner.Do(req, proxyURL, accountID, accountConcurrency)
+	return rec.attach(resp, err), err
+}
+
+func (u *capturingHTTPUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile) (*http.Response, error) {
+	rec := u.capture.beginCapture(req, accountID)
+	resp, err := u.inner.DoWithTLS(req, proxyURL, accountID, accountConcurrency, profile)
+	return rec.attach(resp, err), err
+}
+
+// apiKeyCaptureRecorder 单次上游调用的抓取上下文。
+type apiKeyCaptureRecorder struct {
+	svc       *APIKeyCaptureService
+	capture   *APIKeyCapture
+	startedAt time.Time
+}
+
+// beginCapture 在请求发出前记录请求部分；请求所属 Key 未开启抓取时返回 nil。
+func (s *APIKeyCaptureService) beginCapture(req *http.Request, accountID int64) *apiKeyCaptureRecorder {
+	if s == nil || req == nil {
+		return nil
+	}
+	apiKeyID, _ := req.Context().Value(ctxkey.APIKeyID).(int64)
+	if !s.IsCapturing(apiKeyID) {
+		return nil
+	}
+
+	body, ok := snapshotCaptureRequestBody(req)
+	if !ok {
+		return nil
+	}
+	requestBody, requestTruncated := redactCaptureBody(body, false, s.maxBodyBytes)
+	return &apiKeyCaptureRecorder{
+		svc: s,
+		capture: &APIKeyCapture{
+			APIKeyID:         apiKeyID,
+			AccountID:        accountID,
+			Method:           req.Method,
+			URL:              redactCaptureURL(req.URL),
+			RequestHeaders:   redactCaptureHeaders(req.Header),
+			RequestBody:      requestBody,
+			RequestTruncated: requestTruncated,
+		},
+		startedAt: time.Now(),
+	}
+}
+
+// snapshotCaptureRequestBody 读取请求体副本且不消耗原请求体。
+func snapshotCaptureRequestBody(req *http.Request) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		defer func() { _ = rc.Close() }()
+		body, err := io.ReadAll(rc)
+		return body, err == nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, err == nil
+}
+
+// attach 记录响应头并包装响应体；响应体读完或关闭时异步提交抓取记录。
+func (r *apiKeyCaptureRecorder) attach(resp *http.Response, err error) *http.Response {
+	if r == nil {
+		return resp
+	}
+	if err != nil || resp == nil {
+		if err != nil {
+			r.capture.ErrorMessage = err.Error()
+		}
+		r.capture.DurationMs = time.Since(r.startedAt).Milliseconds()
+		r.svc.Enqueue(r.capture)
+		return resp
+	}
+	r.capture.ResponseStatus = resp.StatusCode
+	r.capture.ResponseHeaders = redactCaptureHeaders(resp.Header)
+	if resp.Body == nil {
+		r.capture.DurationMs = time.Since(r.startedAt).Milliseconds()
+		r.svc.Enqueue(r.capture)
+		return resp
+	}
+	resp.Body = &captureResponseBody{
+		ReadCloser: resp.Body,
+		rec:        r,
+		limit:      r.svc.maxBodyBytes,
+		stream:     strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/event-stream"),
+	}
+	return resp
+}
+
+// captureResponseBody 透传读取的同时缓存前 limit 字节。
+// 流式响应超过 limit 时整条抓取被跳过（截断的 SSE 无排查价值且可能很大）。
+type captureResponseBody struct {
+	io.ReadCloser
+	rec    *apiKeyCaptureRecorder
+	limit  int
+	stream bool
+	// mu 保护 buf/overflow：超时中止时 Close 可能与读取 goroutine 并发
+	mu       sync.Mutex
+	buf      []byte
+	overflow bool
+	done     bool
+}
+
+func (b *captureResponseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	if n > 0 && !b.overflow && !b.done {
+		remaining := b.limit - len(b.buf)
+		if n > remaining {
+			b.buf = append(b.buf, p[:remaining]...)
+			b.overflow = true
+		} else {
+			b.buf = append(b.buf, p[:n]...)
+		}
+	}
+	b.mu.Unlock()
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *captureResponseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *captureResponseBody) finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.done = true
+	if b.stream && b.overflow {
+		return
+	}
+	capture := b.rec.capture
+	capture.ResponseBody, capture.ResponseTruncated = redactCaptureBody(b.buf, b.overflow, b.limit)
+	capture.DurationMs = time.Since(b.rec.startedAt).Milliseconds()
+	b.rec.svc.Enqueue(capture)
+}
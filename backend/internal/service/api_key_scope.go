@@ -0,0 +1,94 @@
+package service
+
+import (
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// API Key 作用域：限制 Key 可调用的网关端点类别
+const (
+	APIKeyScopeChat       = "chat"
+	APIKeyScopeImages     = "images"
+	APIKeyScopeVideo      = "video"
+	APIKeyScopeEmbeddings = "embeddings"
+)
+
+// AllAPIKeyScopes 全部作用域（未设置作用域的 Key 等同于拥有全部作用域）
+var AllAPIKeyScopes = []string{APIKeyScopeChat, APIKeyScopeImages, APIKeyScopeVideo, APIKeyScopeEmbeddings}
+
+var ErrInvalidAPIKeyScope = infraerrors.BadRequest("INVALID_API_KEY_SCOPE", "invalid api key scope, allowed: chat, images, video, embeddings")
+
+// IsValidAPIKeyScope 是否为已知作用域
+func IsValidAPIKeyScope(scope string) bool {
+	for _, s := range AllAPIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeAPIKeyScopes 规范化作用域：去除首尾空白、转小写、去重并按 AllAPIKeyScopes 顺序排列。
+// 空列表或包含全部作用域时返回 nil（不限制）；存在未知作用域时返回 ErrInvalidAPIKeyScope。
+func NormalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" {
+			continue
+		}
+		if !IsValidAPIKeyScope(scope) {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		seen[scope] = struct{}{}
+	}
+	if len(seen) == 0 || len(seen) == len(AllAPIKeyScopes) {
+		return nil, nil
+	}
+	out := make([]string, 0, len(seen))
+	for _, scope := range AllAPIKeyScopes {
+		if _, ok := seen[scope]; ok {
+			out = append(out, scope)
+		}
+	}
+	return out, nil
+}
+
+// EffectiveScopes 返回 Key 实际拥有的作用域；未设置（历史 Key）时为全部作用域
+func (k *APIKey) EffectiveScopes() []string {
+	if len(k.Scopes) == 0 {
+		return append([]string(nil), AllAPIKeyScopes...)
+	}
+	return append([]string(nil), k.Scopes...)
+}
+
+// HasScope Key 是否拥有指定作用域
+func (k *APIKey) HasScope(scope string) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyScopeInboundEndpoints 返回作用域对应的规范化入站端点（usage_logs.inbound_endpoint），用于按作用域筛选用量。
+// Gemini 原生接口统一记录为 /v1beta/models，按 chat 统计。未知作用域返回 nil。
+func APIKeyScopeInboundEndpoints(scope string) []string {
+	switch scope {
+	case APIKeyScopeChat:
+		return []string{"/v1/messages", "/v1/chat/completions", "/v1/responses", "/v1beta/models"}
+	case APIKeyScopeImages:
+		return []string{"/v1/images/generations", "/v1/images/edits"}
+	case APIKeyScopeVideo:
+		return []string{"/v1/videos"}
+	case APIKeyScopeEmbeddings:
+		return []string{"/v1/embeddings"}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyScopes(t *testing.T) {
+	scopes, err := NormalizeAPIKeyScopes([]string{" Video ", "chat", "video", ""})
+	require.NoError(t, err)
+	require.Equal(t, []string{APIKeyScopeChat, APIKeyScopeVideo}, scopes)
+
+	// 空列表与全部作用域都存为 nil（不限制）
+	scopes, err = NormalizeAPIKeyScopes(nil)
+	require.NoError(t, err)
+	require.Nil(t, scopes)
+	scopes, err = NormalizeAPIKeyScopes([]string{"embeddings", "video", "images", "chat"})
+	require.NoError(t, err)
+	require.Nil(t, scopes)
+
+	_, err = NormalizeAPIKeyScopes([]string{"chat", "audio"})
+	require.True(t, errors.Is(err, ErrInvalidAPIKeyScope))
+}
+
+func TestAPIKeyScopes_LegacyKeyHasAllScopes(t *testing.T) {
+	legacy := &APIKey{}
+	require.Equal(t, AllAPIKeyScopes, legacy.EffectiveScopes())
+	for _, scope := range AllAPIKeyScopes {
+		require.True(t, legacy.HasScope(scope))
+	}
+
+	scoped := &APIKey{Scopes: []string{APIKeyScopeVideo}}
+	require.Equal(t, []string{APIKeyScopeVideo}, scoped.EffectiveScopes())
+	require.True(t, scoped.HasScope(APIKeyScopeVideo))
+	require.False(t, scoped.HasScope(APIKeyScopeChat))
+}
+
+func TestAPIKeyScopeInboundEndpoints(t *testing.T) {
+	for _, scope := range AllAPIKeyScopes {
+		require.NotEmpty(t, APIKeyScopeInboundEndpoints(scope), scope)
+	}
+	require.Contains(t, APIKeyScopeInboundEndpoints(APIKeyScopeChat), "/v1/chat/completions")
+	require.Nil(t, APIKeyScopeInboundEndpoints("audio"))
+}
@@ -157,6 +157,16 @@ type CreateAPIKeyRequest struct {
 	CustomKey   *string  `json:"custom_key"`   // 可选的自定义key
 	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单
 	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单
+	// AccountLabels 账号标签选择器
+	AccountLabels []string `json:"account_labels"`
+	// ResponseCacheEnabled 启用响应缓存
+	ResponseCacheEnabled bool `json:"response_cache_enabled"`
+	// CostPreviewEnabled 返回请求费用估算响应头
+	CostPreviewEnabled bool `json:"cost_preview_enabled"`
+	// Scopes 端点作用域（空表示全部）
+	Scopes []string `json:"scopes"`
+	// AllowedModels 模型白名单（空表示全部）
+	AllowedModels []string `json:"allowed_models"`
 
 	// Quota fields
 	Quota         float64 `json:"quota"`           // Quota limit in USD (0 = unlimited)
@@ -175,6 +185,16 @@ type UpdateAPIKeyRequest struct {
 	Status      *string  `json:"status"`
 	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单（空数组清空）
 	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单（空数组清空）
+	// AccountLabels 账号标签选择器（nil 不修改，空数组清空）
+	AccountLabels []string `json:"account_labels"`
+	// ResponseCacheEnabled 启用响应缓存（nil 不修改）
+	ResponseCacheEnabled *bool `json:"response_cache_enabled"`
+	// CostPreviewEnabled 返回请求费用估算响应头（nil 不修改）
+	CostPreviewEnabled *bool `json:"cost_preview_enabled"`
+	// Scopes 端点作用域（nil 不修改，空数组恢复为全部）
+	Scopes []string `json:"scopes"`
+	// AllowedModels 模型白名单（nil 不修改，空数组恢复为全部）
+	AllowedModels []string `json:"allowed_models"`
 
 	// Quota fields
 	Quota           *float64   `json:"quota"`       // Quota limit in USD (nil = no change, 0 = unlimited)
@@ -350,6 +370,15 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
+	scopes, err := NormalizeAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	allowedModels, err := NormalizeAPIKeyAllowedModels(req.AllowedModels)
+	if err != nil {
+		return nil, err
+	}
+
 	// 验证分组权限（如果指定了分组）
 	if req.GroupID != nil {
 		group, err := s.groupRepo.GetByID(ctx, *req.GroupID)
@@ -400,18 +429,23 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 
 	// 创建API Key记录
 	apiKey := &APIKey{
-		UserID:      userID,
-		Key:         key,
-		Name:        html.EscapeString(req.Name),
-		GroupID:     req.GroupID,
-		Status:      StatusActive,
-		IPWhitelist: req.IPWhitelist,
-		IPBlacklist: req.IPBlacklist,
-		Quota:       req.Quota,
-		QuotaUsed:   0,
-		RateLimit5h: req.RateLimit5h,
-		RateLimit1d: req.RateLimit1d,
-		RateLimit7d: req.RateLimit7d,
+		UserID:               userID,
+		Key:                  key,
+		Name:                 html.EscapeString(req.Name),
+		GroupID:              req.GroupID,
+		Status:               StatusActive,
+		IPWhitelist:          req.IPWhitelist,
+		IPBlacklist:          req.IPBlacklist,
+		AccountLabels:        NormalizeAccountLabels(req.AccountLabels),
+		ResponseCacheEnabled: req.ResponseCacheEnabled,
+		CostPreviewEnabled:   req.CostPreviewEnabled,
+		Scopes:               scopes,
+		AllowedModels:        allowedModels,
+		Quota:                req.Quota,
+		QuotaUsed:            0,
+		RateLimit5h:          req.RateLimit5h,
+		RateLimit1d:          req.RateLimit1d,
+		RateLimit7d:          req.RateLimit7d,
 	}
 
 	// Set expiration time if specified
@@ -603,6 +637,29 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	// 更新 IP 限制（空数组会清空设置）
 	apiKey.IPWhitelist = req.IPWhitelist
 	apiKey.IPBlacklist = req.IPBlacklist
+	if req.AccountLabels != nil {
+		apiKey.AccountLabels = NormalizeAccountLabels(req.AccountLabels)
+	}
+	if req.ResponseCacheEnabled != nil {
+		apiKey.ResponseCacheEnabled = *req.ResponseCacheEnabled
+	}
+	if req.CostPreviewEnabled != nil {
+		apiKey.CostPreviewEnabled = *req.CostPreviewEnabled
+	}
+	if req.Scopes != nil {
+		scopes, err := NormalizeAPIKeyScopes(req.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.Scopes = scopes
+	}
+	if req.AllowedModels != nil {
+		allowedModels, err := NormalizeAPIKeyAllowedModels(req.AllowedModels)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.AllowedModels = allowedModels
+	}
 
 	// Update rate limit configuration
 	if req.RateLimit5h != nil {
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/util/logredact"
+)
+
+// 审计日志实体类型
+const (
+	AuditEntityAccount              = "account"
+	AuditEntityProxy                = "proxy"
+	AuditEntityAPIKey               = "api_key"
+	AuditEntityErrorPassthroughRule = "error_passthrough_rule"
+)
+
+// 审计日志操作类型
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// auditSensitiveKeys 在 logredact 默认敏感字段之外，审计 diff 额外脱敏的字段
+var auditSensitiveKeys = []string{
+	"api_key",
+	"apikey",
+	"key",
+	"token",
+	"session_key",
+	"session_token",
+	"secret",
+	"secret_access_key",
+	"private_key",
+	"service_account_json",
+	"cookie",
+	"authorization",
+}
+
+// auditIgnoredKeys 不参与 diff 的字段（每次写入都会变化，记录无意义）
+var auditIgnoredKeys = map[string]struct{}{
+	"updated_at": {},
+	"UpdatedAt":  {},
+}
+
+// AuditFieldChange 单个字段的变更（敏感字段的值已替换为 "***"）
+type AuditFieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditLog 一条管理员操作审计记录
+type AuditLog struct {
+	ID          int64                       `json:"id"`
+	ActorUserID int64                       `json:"actor_user_id"`
+	Action      string                      `json:"action"`
+	EntityType  string                      `json:"entity_type"`
+	EntityID    int64                       `json:"entity_id"`
+	Diff        map[string]AuditFieldChange `json:"diff"`
+	IP          string                      `json:"ip"`
+	CreatedAt   time.Time                   `json:"created_at"`
+}
+
+// AuditLogFilter 审计日志查询条件
+type AuditLogFilter struct {
+	Pagination pagination.PaginationParams
+	EntityType string
+	EntityID   *int64
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditLogRepository 审计日志持久化接口
+type AuditLogRepository interface {
+	Insert(ctx context.Context, entry *AuditLog) error
+	List(ctx context.Context, filter AuditLogFilter) ([]AuditLog, *pagination.PaginationResult, error)
+	// DeleteBefore 删除 created_at 早于 cutoff 的记录，单次最多 limit 条，返回删除条数。
+	DeleteBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// BuildAuditDiff 生成 before → after 的字段级 diff。
+//
+// 两侧先经 JSON 归一化为 map；嵌套对象（如 credentials）逐层展开为 "credentials.access_token"
+// 形式的键。diff 先在原始值上计算（轮换 token 也能被记录），再对结果做脱敏。
+// before 或 after 为 nil 分别对应创建与删除。
+func BuildAuditDiff(before, after any) map[string]AuditFieldChange {
+	out := make(map[string]AuditFieldChange)
+	diffAuditMaps("", toAuditMap(before), toAuditMap(after), out)
+	return out
+}
+
+func diffAuditMaps(prefix string, before, after map[string]any, out map[string]AuditFieldChange) {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		if _, ignored := auditIgnoredKeys[k]; ignored {
+			continue
+		}
+		b, a := before[k], after[k]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		bm, bIsMap := b.(map[string]any)
+		am, aIsMap := a.(map[string]any)
+		if bIsMap && aIsMap {
+			diffAuditMaps(path, bm, am, out)
+			continue
+		}
+		out[path] = AuditFieldChange{
+			Before: redactAuditValue(k, b),
+			After:  redactAuditValue(k, a),
+		}
+	}
+}
+
+// redactAuditValue 复用 logredact 的敏感字段判定：字段本身敏感时整体替换为 "***"，否则递归脱敏嵌套值
+func redactAuditValue(key string, value any) any {
+	if value == nil {
+		return nil
+	}
+	return logredact.RedactMap(map[string]any{key: value}, auditSensitiveKeys...)[key]
+}
+
+func toAuditMap(v any) map[string]any {
+	if v == nil {
+		return map[string]any{}
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return map[string]any{}
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return map[string]any{}
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return map[string]any{"value": strings.TrimSpace(string(raw))}
+	}
+	return out
+}
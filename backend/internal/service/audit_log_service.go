@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+const (
+	// auditLogWriteTimeout 单条审计记录写库超时（与请求上下文解耦，客户端断开也要落库）
+	auditLogWriteTimeout = 5 * time.Second
+
+	defaultAuditLogCleanupInterval  = time.Hour
+	defaultAuditLogCleanupBatchSize = 1000
+)
+
+// AuditLogService 记录并查询管理员操作审计日志，按 audit.retention_days 定期清理过期记录。
+type AuditLogService struct {
+	repo      AuditLogRepository
+	retention time.Duration
+	interval  time.Duration
+	batch     int
+	now       func() time.Time
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+}
+
+// NewAuditLogService 创建审计日志服务
+func NewAuditLogService(repo AuditLogRepository, cfg *config.Config) *AuditLogService {
+	s := &AuditLogService{
+		repo:     repo,
+		interval: defaultAuditLogCleanupInterval,
+		batch:    defaultAuditLogCleanupBatchSize,
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+	if cfg != nil {
+		if cfg.Audit.RetentionDays > 0 {
+			s.retention = time.Duration(cfg.Audit.RetentionDays) * 24 * time.Hour
+		}
+		if cfg.Audit.CleanupIntervalSeconds > 0 {
+			s.interval = time.Duration(cfg.Audit.CleanupIntervalSeconds) * time.Second
+		}
+		if cfg.Audit.CleanupBatchSize > 0 {
+			s.batch = cfg.Audit.CleanupBatchSize
+		}
+	}
+	return s
+}
+
+// Record 写入一条审计记录。写入失败只记日志，不影响管理操作本身的结果。
+func (s *AuditLogService) Record(ctx context.Context, entry *AuditLog) {
+	if s == nil || s.repo == nil || entry == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditLogWriteTimeout)
+	defer cancel()
+	if err := s.repo.Insert(writeCtx, entry); err != nil {
+		logger.LegacyPrintf("service.audit_log", "[AuditLog] insert failed action=%s entity=%s/%d err=%v", entry.Action, entry.EntityType, entry.EntityID, err)
+	}
+}
+
+// List 分页查询审计日志（按时间倒序）
+func (s *AuditLogService) List(ctx context.Context, filter AuditLogFilter) ([]AuditLog, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Start 启动过期审计日志清理循环；retention_days 为 0 时不清理。
+func (s *AuditLogService) Start() {
+	if s == nil || s.repo == nil || s.retention <= 0 {
+		return
+	}
+	s.startOnce.Do(func() {
+		logger.LegacyPrintf("service.audit_log", "[AuditLog] cleanup started retention=%s interval=%s batch=%d", s.retention, s.interval, s.batch)
+		go s.runCleanupLoop()
+	})
+}
+
+// Stop 停止清理循环
+func (s *AuditLogService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+func (s *AuditLogService) runCleanupLoop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.pruneOnce()
+	for {
+		select {
+		case <-ticker.C:
+			s.pruneOnce()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// pruneOnce 删除超过保留期的记录；单批删满时继续下一批，直到清完或服务停止。
+func (s *AuditLogService) pruneOnce() {
+	cutoff := s.now().Add(-s.retention)
+	var total int64
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		deleted, err := s.repo.DeleteBefore(ctx, cutoff, s.batch)
+		cancel()
+		if err != nil {
+			logger.LegacyPrintf("service.audit_log", "[AuditLog] cleanup failed err=%v", err)
+			return
+		}
+		total += deleted
+		if deleted < int64(s.batch) {
+			break
+		}
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+	}
+	if total > 0 {
+		logger.LegacyPrintf("service.audit_log", "[AuditLog] pruned expired records count=%d cutoff=%s", total, cutoff.UTC().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+type auditLogRepoStub struct {
+	inserted    []*AuditLog
+	deleteCalls int
+	deleteRows  []int64
+}
+
+func (r *auditLogRepoStub) Insert(_ context.Context, entry *AuditLog) error {
+	r.inserted = append(r.inserted, entry)
+	return nil
+}
+
+func (r *auditLogRepoStub) List(context.Context, AuditLogFilter) ([]AuditLog, *pagination.PaginationResult, error) {
+	return nil, &pagination.PaginationResult{}, nil
+}
+
+func (r *auditLogRepoStub) DeleteBefore(context.Context, time.Time, int) (int64, error) {
+	r.deleteCalls++
+	if len(r.deleteRows) == 0 {
+		return 0, nil
+	}
+	n := r.deleteRows[0]
+	r.deleteRows = r.deleteRows[1:]
+	return n, nil
+}
+
+func TestBuildAuditDiff_RecordsChangedFieldsOnly(t *testing.T) {
+	before := map[string]any{"name": "a", "priority": 1, "status": "active", "updated_at": "t1"}
+	after := map[string]any{"name": "b", "priority": 1, "status": "active", "updated_at": "t2"}
+
+	diff := BuildAuditDiff(before, after)
+
+	require.Equal(t, map[string]AuditFieldChange{
+		"name": {Before: "a", After: "b"},
+	}, diff)
+}
+
+func TestBuildAuditDiff_CreateAndDelete(t *testing.T) {
+	snapshot := map[string]any{"name": "proxy-1", "port": 8080}
+
+	created := BuildAuditDiff(nil, snapshot)
+	require.Len(t, created, 2)
+	require.Nil(t, created["name"].Before)
+	require.Equal(t, "proxy-1", created["name"].After)
+
+	var nilSnapshot map[string]any
+	deleted := BuildAuditDiff(snapshot, nilSnapshot)
+	require.Len(t, deleted, 2)
+	require.Equal(t, float64(8080), deleted["port"].Before)
+	require.Nil(t, deleted["port"].After)
+}
+
+func TestBuildAuditDiff_RedactsSecretsButKeepsChange(t *testing.T) {
+	before := map[string]any{
+		"name":     "acc",
+		"password": "old-pass",
+		"credentials": map[string]any{
+			"access_token": "sk-ant-old-token",
+			"base_url":     "https://a.example.com",
+		},
+	}
+	after := map[string]any{
+		"name":     "acc",
+		"password": "new-pass",
+		"credentials": map[string]any{
+			"access_token": "sk-ant-new-token",
+			"base_url":     "https://b.example.com",
+		},
+	}
+
+	diff := BuildAuditDiff(before, after)
+
+	require.Contains(t, diff, "password")
+	require.Contains(t, diff, "credentials.access_token")
+	require.Equal(t, AuditFieldChange{Before: "https://a.example.com", After: "https://b.example.com"}, diff["credentials.base_url"])
+
+	raw, err := json.Marshal(diff)
+	require.NoError(t, err)
+	for _, secret := range []string{"old-pass", "new-pass", "sk-ant-old-token", "sk-ant-new-token"} {
+		require.False(t, strings.Contains(string(raw), secret), "secret %q leaked: %s", secret, raw)
+	}
+}
+
+func TestBuildAuditDiff_RedactsNestedSecretsOnCreate(t *testing.T) {
+	diff := BuildAuditDiff(nil, map[string]any{
+		"credentials": map[string]any{"api_key": "sk-raw", "model_mapping": map[string]any{"a": "b"}},
+	})
+
+	raw, err := json.Marshal(diff)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "sk-raw")
+	require.Contains(t, string(raw), "model_mapping")
+}
+
+func TestAuditLogService_RecordIgnoresCanceledContext(t *testing.T) {
+	repo := &auditLogRepoStub{}
+	svc := NewAuditLogService(repo, &config.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	svc.Record(ctx, &AuditLog{Action: AuditActionCreate, EntityType: AuditEntityProxy, EntityID: 1})
+
+	require.Len(t, repo.inserted, 1)
+}
+
+func TestAuditLogService_PruneOnceDeletesInBatches(t *testing.T) {
+	repo := &auditLogRepoStub{deleteRows: []int64{2, 2, 1}}
+	svc := NewAuditLogService(repo, &config.Config{Audit: config.AuditConfig{RetentionDays: 1, CleanupBatchSize: 2}})
+
+	svc.pruneOnce()
+
+	require.Equal(t, 3, repo.deleteCalls)
+}
+
+func TestAuditLogService_StartNoopWhenRetentionDisabled(t *testing.T) {
+	repo := &auditLogRepoStub{}
+	svc := NewAuditLogService(repo, &config.Config{})
+
+	svc.Start()
+	svc.Stop()
+
+	require.Zero(t, repo.deleteCalls)
+}
@@ -249,6 +249,37 @@ func (s *BalanceNotifyService) asyncSendQuotaAlert(adminEmails []string, account
 	}()
 }
 
+// NotifyGroupSpendNearCap emails admins when a group's daily/monthly spend crosses the alert ratio of its cap.
+// Reuses the account quota alert toggle and recipient list; registered as the BillingCacheService spend cap hook.
+func (s *BalanceNotifyService) NotifyGroupSpendNearCap(ctx context.Context, alert GroupSpendCapAlert) {
+	if s.emailService == nil || s.settingRepo == nil {
+		return
+	}
+	if !s.isAccountQuotaNotifyEnabled(ctx) {
+		return
+	}
+	adminEmails := s.getAccountQuotaNotifyEmails(ctx)
+	if len(adminEmails) == 0 {
+		return
+	}
+	siteName := s.getSiteName(ctx)
+	periodLabel := "日消费 / Daily"
+	if alert.Period == GroupSpendCapPeriodMonthly {
+		periodLabel = "月消费 / Monthly"
+	}
+	subject := fmt.Sprintf("[%s] 分组消费接近上限 / Group Spend Near Cap - %s", sanitizeEmailHeader(siteName), sanitizeEmailHeader(alert.GroupName))
+	body := fmt.Sprintf(groupSpendCapAlertEmailTemplate,
+		html.EscapeString(siteName),
+		alert.GroupID,
+		html.EscapeString(alert.GroupName),
+		html.EscapeString(periodLabel),
+		alert.Spent,
+		alert.Cap,
+		html.EscapeString(alert.ResetsAt.Format(time.RFC3339)),
+	)
+	s.sendEmails(adminEmails, subject, body, "group_id", alert.GroupID, "period", alert.Period)
+}
+
 // getBalanceNotifyConfig reads global balance notification settings.
 func (s *BalanceNotifyService) getBalanceNotifyConfig(ctx context.Context) (enabled bool, threshold float64, rechargeURL string) {
 	keys := []string{SettingKeyBalanceLowNotifyEnabled, SettingKeyBalanceLowNotifyThreshold, SettingKeyBalanceLowNotifyRechargeURL}
@@ -536,6 +567,46 @@ const quotaAlertEmailTemplate = `<!DOCTYPE html>
 </body>
 </html>`
 
+// groupSpendCapAlertEmailTemplate is the HTML template for group spend near-cap notifications.
+// Format args: siteName, groupID, groupName, periodLabel, spent, cap, resetsAt.
+const groupSpendCapAlertEmailTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background-color: #f5f5f5; margin: 0; padding: 20px; }
+        .container { max-width: 600px; margin: 0 auto; background-color: #fff; border-radius: 8px; overflow: hidden; box-shadow: 0 2px 8px rgba(0,0,0,0.1); }
+        .header { background: linear-gradient(135deg, #f59e0b 0%%, #d97706 100%%); color: white; padding: 30px; text-align: center; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { padding: 40px 30px; }
+        .metric { display: flex; justify-content: space-between; padding: 12px 0; border-bottom: 1px solid #eee; }
+        .metric-label { color: #666; }
+        .metric-value { font-weight: bold; color: #333; }
+        .info { color: #666; font-size: 14px; line-height: 1.6; margin-top: 20px; text-align: center; }
+        .footer { background-color: #f8f9fa; padding: 20px; text-align: center; color: #999; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header"><h1>%s</h1></div>
+        <div class="content">
+            <p style="font-size: 18px; color: #333; text-align: center;">分组消费接近上限 / Group Spend Near Cap</p>
+            <div class="metric"><span class="metric-label">分组 ID / Group ID</span><span class="metric-value">#%d</span></div>
+            <div class="metric"><span class="metric-label">分组 / Group</span><span class="metric-value">%s</span></div>
+            <div class="metric"><span class="metric-label">周期 / Period</span><span class="metric-value">%s</span></div>
+            <div class="metric"><span class="metric-label">已消费 / Spent</span><span class="metric-value">$%.2f</span></div>
+            <div class="metric"><span class="metric-label">上限 / Cap</span><span class="metric-value">$%.2f</span></div>
+            <div class="metric"><span class="metric-label">重置时间 / Resets At</span><span class="metric-value">%s</span></div>
+            <div class="info">
+                <p>分组消费已达到上限的 90%%，达到上限后该分组的新请求将被拒绝。</p>
+                <p>Group spend has reached 90%% of its cap. New requests will be rejected once the cap is reached.</p>
+            </div>
+        </div>
+        <div class="footer"><p>此邮件由系统自动发送，请勿回复。</p></div>
+    </div>
+</body>
+</html>`
+
 // buildBalanceLowEmailBody builds HTML email for balance low notification.
 func (s *BalanceNotifyService) buildBalanceLowEmailBody(userName string, balance, threshold float64, siteName, rechargeURL string) string {
 	rechargeBlock := ""
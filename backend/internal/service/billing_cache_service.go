@@ -108,6 +108,11 @@ type BillingCacheService struct {
 	cfg                   *config.Config
 	circuitBreaker        *billingCircuitBreaker
 	userPlatformQuotaRepo UserPlatformQuotaRepository
+	degradedGrace         billingDegradedGrace
+
+	// 分组消费上限（见 group_spend_cap.go），通过 setter 注入
+	groupSpendCapCache     GroupSpendCapCache
+	groupSpendCapAlertHook GroupSpendCapAlertHook
 
 	cacheWriteChan     chan cacheWriteTask
 	cacheWriteWg       sync.WaitGroup
@@ -705,13 +710,20 @@ func (s *BillingCacheService) IncrementUserPlatformQuotaUsage(userID int64, plat
 // 余额模式：检查缓存余额 > 0
 // 订阅模式：检查缓存用量未超过限额（Group限额从参数传入）
 // platform 为请求的目标平台（如 "anthropic"），传空串 "" 时跳过 user × platform quota 检查。
+// 分组配置了日/月消费上限时，达到上限返回 ErrGroupSpendCapExceeded（两种模式均生效）。
+// 开启 billing.degraded_mode 时，基础设施故障（BillingInfraError）在 Key 宽限额度内放行。
 func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user *User, apiKey *APIKey, group *Group, subscription *UserSubscription, platform string) error {
 	// 简易模式：跳过所有计费检查
 	if s.cfg.RunMode == config.RunModeSimple {
 		return nil
 	}
+	err := s.checkBillingEligibility(ctx, user, apiKey, group, subscription, platform)
+	return s.applyBillingDegradedMode(ctx, apiKey, err)
+}
+
+func (s *BillingCacheService) checkBillingEligibility(ctx context.Context, user *User, apiKey *APIKey, group *Group, subscription *UserSubscription, platform string) error {
 	if s.circuitBreaker != nil && !s.circuitBreaker.Allow() {
-		return ErrBillingServiceUnavailable
+		return newBillingInfraError(nil)
 	}
 
 	// 判断计费模式
@@ -734,6 +746,11 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 		}
 	}
 
+	// 分组日/月消费上限（两种计费模式均生效）
+	if err := s.checkGroupSpendCap(ctx, group, time.Now()); err != nil {
+		return err
+	}
+
 	// Check API Key rate limits (applies to both billing modes)
 	if apiKey != nil && apiKey.HasRateLimits() {
 		if err := s.checkAPIKeyRateLimits(ctx, apiKey); err != nil {
@@ -856,7 +873,7 @@ func (s *BillingCacheService) checkBalanceEligibility(ctx context.Context, userI
 			s.circuitBreaker.OnFailure(err)
 		}
 		logger.LegacyPrintf("service.billing_cache", "ALERT: billing balance check failed for user %d: %v", userID, err)
-		return ErrBillingServiceUnavailable.WithCause(err)
+		return newBillingInfraError(err)
 	}
 	if s.circuitBreaker != nil {
 		s.circuitBreaker.OnSuccess()
@@ -878,7 +895,7 @@ func (s *BillingCacheService) checkSubscriptionEligibility(ctx context.Context,
 			s.circuitBreaker.OnFailure(err)
 		}
 		logger.LegacyPrintf("service.billing_cache", "ALERT: billing subscription check failed for user %d group %d: %v", userID, group.ID, err)
-		return ErrBillingServiceUnavailable.WithCause(err)
+		return newBillingInfraError(err)
 	}
 	if s.circuitBreaker != nil {
 		s.circuitBreaker.OnSuccess()
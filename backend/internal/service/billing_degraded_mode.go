@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// BillingInfraError 计费依赖故障（缓存/数据库不可用、熔断打开）导致无法给出资格结论。
+// 与余额不足、订阅失效、限额超限等确定性拒绝区分，供降级放行策略判断。
+// Unwrap 到 ErrBillingServiceUnavailable，原有的错误映射保持不变。
+type BillingInfraError struct {
+	Err error
+}
+
+func (e *BillingInfraError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BillingInfraError) Unwrap() error {
+	return e.Err
+}
+
+func newBillingInfraError(cause error) error {
+	if cause == nil {
+		return &BillingInfraError{Err: ErrBillingServiceUnavailable}
+	}
+	return &BillingInfraError{Err: ErrBillingServiceUnavailable.WithCause(cause)}
+}
+
+// IsBillingInfraError 判断计费校验错误是否为基础设施故障（非确定性拒绝）
+func IsBillingInfraError(err error) bool {
+	var infraErr *BillingInfraError
+	return errors.As(err, &infraErr)
+}
+
+type billingVerificationContextKey struct{}
+
+// billingVerification 请求级计费校验状态，由 API Key 认证中间件预先挂载到请求 context，
+// 计费降级放行时置位，用量记录据此标记 billing_unverified。
+type billingVerification struct {
+	unverified atomic.Bool
+}
+
+// WithBillingVerification 为请求挂载计费校验状态（已挂载时原样返回）
+func WithBillingVerification(ctx context.Context) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	if _, ok := ctx.Value(billingVerificationContextKey{}).(*billingVerification); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, billingVerificationContextKey{}, &billingVerification{})
+}
+
+// CopyBillingVerification 将 src 上的计费校验状态带到 dst（用于异步用量记录的 context）
+func CopyBillingVerification(dst, src context.Context) context.Context {
+	if dst == nil || src == nil {
+		return dst
+	}
+	if state, ok := src.Value(billingVerificationContextKey{}).(*billingVerification); ok {
+		return context.WithValue(dst, billingVerificationContextKey{}, state)
+	}
+	return dst
+}
+
+func markBillingUnverified(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	if state, ok := ctx.Value(billingVerificationContextKey{}).(*billingVerification); ok {
+		state.unverified.Store(true)
+	}
+}
+
+// IsBillingUnverified 请求是否在计费降级模式下放行
+func IsBillingUnverified(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	state, ok := ctx.Value(billingVerificationContextKey{}).(*billingVerification)
+	return ok && state.unverified.Load()
+}
+
+const (
+	// billingDegradedGraceIdleTTL 宽限记录闲置超过该时长即清除（Key 已删除或故障后不再请求），避免 map 无限增长
+	billingDegradedGraceIdleTTL = 15 * time.Minute
+	// billingDegradedGraceSweepInterval 闲置记录清理的最小间隔
+	billingDegradedGraceSweepInterval = time.Minute
+)
+
+// billingDegradedGrace 按 API Key 记录单次故障期间的宽限消耗。
+// 校验恢复成功后清除该 Key 的记录，下次故障重新计算额度；闲置记录按 billingDegradedGraceIdleTTL 过期。
+type billingDegradedGrace struct {
+	mu        sync.Mutex
+	byKey     map[int64]*billingDegradedGraceState
+	lastSweep time.Time
+	// hasEntries 快速路径：无宽限记录时成功校验无需加锁
+	hasEntries atomic.Bool
+
+	admitted  atomic.Int64
+	exhausted atomic.Int64
+}
+
+type billingDegradedGraceState struct {
+	startedAt  time.Time
+	lastSeenAt time.Time
+	used       int
+}
+
+// BillingDegradedModeMetricsSnapshot 计费降级放行计数
+type BillingDegradedModeMetricsSnapshot struct {
+	AdmittedTotal  int64 `json:"admitted_total"`
+	ExhaustedTotal int64 `json:"exhausted_total"`
+}
+
+// allow 在宽限额度内消耗一次并返回 true；额度耗尽（次数或时长任一到达上限）返回 false。
+func (g *billingDegradedGrace) allow(apiKeyID int64, maxRequests int, maxDuration time.Duration, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.byKey == nil {
+		g.byKey = make(map[int64]*billingDegradedGraceState)
+	}
+	g.sweepIdleLocked(now)
+	state, ok := g.byKey[apiKeyID]
+	if !ok {
+		state = &billingDegradedGraceState{startedAt: now}
+		g.byKey[apiKeyID] = state
+		g.hasEntries.Store(true)
+	}
+	state.lastSeenAt = now
+	if maxRequests > 0 && state.used >= maxRequests {
+		return false
+	}
+	if maxDuration > 0 && now.Sub(state.startedAt) >= maxDuration {
+		return false
+	}
+	state.used++
+	return true
+}
+
+func (g *billingDegradedGrace) reset(apiKeyID int64) {
+	if !g.hasEntries.Load() {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.byKey, apiKeyID)
+	g.sweepIdleLocked(time.Now())
+	if len(g.byKey) == 0 {
+		g.hasEntries.Store(false)
+	}
+}
+
+// sweepIdleLocked 清除闲置超过 billingDegradedGraceIdleTTL 的记录；调用方须持有 g.mu。
+func (g *billingDegradedGrace) sweepIdleLocked(now time.Time) {
+	if now.Sub(g.lastSweep) < billingDegradedGraceSweepInterval {
+		return
+	}
+	g.lastSweep = now
+	for id, state := range g.byKey {
+		if now.Sub(state.lastSeenAt) >= billingDegradedGraceIdleTTL {
+			delete(g.byKey, id)
+		}
+	}
+}
+
+// applyBillingDegradedMode 对计费校验结果应用降级放行策略：
+// 仅基础设施故障且 Key 宽限额度未耗尽时放行（返回 nil 并标记请求 billing_unverified），
+// 确定性拒绝与策略关闭时原样返回错误。
+func (s *BillingCacheService) applyBillingDegradedMode(ctx context.Context, apiKey *APIKey, err error) error {
+	if s == nil || s.cfg == nil || !s.cfg.Billing.DegradedMode.Enabled || apiKey == nil {
+		return err
+	}
+	if err == nil {
+		s.degradedGrace.reset(apiKey.ID)
+		return nil
+	}
+	if !IsBillingInfraError(err) {
+		return err
+	}
+
+	policy := s.cfg.Billing.DegradedMode
+	maxDuration := time.Duration(policy.MaxMinutes) * time.Minute
+	if !s.degradedGrace.allow(apiKey.ID, policy.MaxRequests, maxDuration, time.Now()) {
+		s.degradedGrace.exhausted.Add(1)
+		logger.LegacyPrintf("service.billing_cache", "Warning: billing degraded mode grace exhausted for api_key=%d: %v", apiKey.ID, err)
+		return err
+	}
+	s.degradedGrace.admitted.Add(1)
+	markBillingUnverified(ctx)
+	logger.LegacyPrintf("service.billing_cache", "Warning: billing degraded mode admitted unverified request for api_key=%d: %v", apiKey.ID, err)
+	return nil
+}
+
+// SnapshotDegradedModeMetrics 返回计费降级放行计数
+func (s *BillingCacheService) SnapshotDegradedModeMetrics() BillingDegradedModeMetricsSnapshot {
+	if s == nil {
+		return BillingDegradedModeMetricsSnapshot{}
+	}
+	return BillingDegradedModeMetricsSnapshot{
+		AdmittedTotal:  s.degradedGrace.admitted.Load(),
+		ExhaustedTotal: s.degradedGrace.exhausted.Load(),
+	}
+}
@@ -0,0 +1,134 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type degradedBalanceCacheStub struct {
+	billingCacheWorkerStub
+	balance float64
+	err     error
+}
+
+func (s *degradedBalanceCacheStub) GetUserBalance(context.Context, int64) (float64, error) {
+	return s.balance, s.err
+}
+
+type degradedUserRepoStub struct {
+	mockUserRepo
+	err error
+}
+
+func (s *degradedUserRepoStub) GetByID(_ context.Context, id int64) (*User, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &User{ID: id}, nil
+}
+
+func newDegradedModeTestService(t *testing.T, cache *degradedBalanceCacheStub, maxRequests, maxMinutes int) *BillingCacheService {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Billing.DegradedMode = config.BillingDegradedModeConfig{
+		Enabled:     true,
+		MaxRequests: maxRequests,
+		MaxMinutes:  maxMinutes,
+	}
+	userRepo := &degradedUserRepoStub{err: errors.New("db down")}
+	svc := NewBillingCacheService(cache, userRepo, nil, nil, nil, nil, cfg, nil)
+	t.Cleanup(svc.Stop)
+	return svc
+}
+
+func TestCheckBillingEligibility_InfraErrorIsTyped(t *testing.T) {
+	cache := &degradedBalanceCacheStub{err: errors.New("redis down")}
+	svc := newDegradedModeTestService(t, cache, 1, 0)
+	svc.cfg.Billing.DegradedMode.Enabled = false
+
+	err := svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, &APIKey{ID: 7}, nil, nil, "")
+	require.True(t, IsBillingInfraError(err))
+	require.ErrorIs(t, err, ErrBillingServiceUnavailable)
+}
+
+func TestCheckBillingEligibility_DegradedModeAdmitsInfraError(t *testing.T) {
+	cache := &degradedBalanceCacheStub{err: errors.New("redis down")}
+	svc := newDegradedModeTestService(t, cache, 5, 0)
+	ctx := WithBillingVerification(context.Background())
+
+	err := svc.CheckBillingEligibility(ctx, &User{ID: 1}, &APIKey{ID: 7}, nil, nil, "")
+	require.NoError(t, err)
+	require.True(t, IsBillingUnverified(ctx))
+	require.Equal(t, int64(1), svc.SnapshotDegradedModeMetrics().AdmittedTotal)
+
+	usageCtx := CopyBillingVerification(context.Background(), ctx)
+	require.True(t, IsBillingUnverified(usageCtx))
+}
+
+func TestCheckBillingEligibility_DegradedModeGraceExhausted(t *testing.T) {
+	cache := &degradedBalanceCacheStub{err: errors.New("redis down")}
+	svc := newDegradedModeTestService(t, cache, 2, 0)
+	apiKey := &APIKey{ID: 7}
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, apiKey, nil, nil, ""))
+	}
+	err := svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, apiKey, nil, nil, "")
+	require.ErrorIs(t, err, ErrBillingServiceUnavailable)
+	require.Equal(t, int64(1), svc.SnapshotDegradedModeMetrics().ExhaustedTotal)
+
+	// 其它 Key 的额度独立计算
+	require.NoError(t, svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, &APIKey{ID: 8}, nil, nil, ""))
+
+	// 校验恢复后重置额度
+	cache.err = nil
+	cache.balance = 10
+	require.NoError(t, svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, apiKey, nil, nil, ""))
+	cache.err = errors.New("redis down again")
+	require.NoError(t, svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, apiKey, nil, nil, ""))
+}
+
+func TestBillingDegradedGrace_TimeBudget(t *testing.T) {
+	var grace billingDegradedGrace
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.True(t, grace.allow(1, 0, 10*time.Minute, start))
+	require.True(t, grace.allow(1, 0, 10*time.Minute, start.Add(9*time.Minute)))
+	require.False(t, grace.allow(1, 0, 10*time.Minute, start.Add(10*time.Minute)))
+}
+
+func TestBillingDegradedGrace_IdleEntriesExpire(t *testing.T) {
+	var grace billingDegradedGrace
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Key 1 在故障中请求后不再出现（如已删除），Key 2 持续请求
+	require.True(t, grace.allow(1, 5, 0, start))
+	require.True(t, grace.allow(2, 0, 0, start))
+	require.True(t, grace.allow(2, 0, 0, start.Add(10*time.Minute)))
+	require.True(t, grace.allow(2, 0, 0, start.Add(billingDegradedGraceIdleTTL)))
+
+	grace.mu.Lock()
+	_, idleKept := grace.byKey[1]
+	_, activeKept := grace.byKey[2]
+	grace.mu.Unlock()
+	require.False(t, idleKept, "idle grace entry should be pruned")
+	require.True(t, activeKept)
+}
+
+func TestCheckBillingEligibility_DegradedModeKeepsDefinitiveDenial(t *testing.T) {
+	cache := &degradedBalanceCacheStub{balance: 0}
+	svc := newDegradedModeTestService(t, cache, 5, 0)
+	ctx := WithBillingVerification(context.Background())
+
+	err := svc.CheckBillingEligibility(ctx, &User{ID: 1}, &APIKey{ID: 7}, nil, nil, "")
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.False(t, IsBillingInfraError(err))
+	require.False(t, IsBillingUnverified(ctx))
+}
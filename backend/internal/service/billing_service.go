@@ -158,6 +158,8 @@ type CostBreakdown struct {
 	TotalCost         float64
 	ActualCost        float64 // 应用倍率后的实际费用
 	BillingMode       string  // 计费模式（"token"/"per_request"/"image"），由 CalculateCostUnified 填充
+	// PricingUnavailable 模型没有任何可用价格，各项费用为 0（费用未知，不计费）
+	PricingUnavailable bool
 }
 
 // ErrModelPricingUnavailable indicates that none of the configured pricing
@@ -169,6 +171,8 @@ type BillingService struct {
 	cfg            *config.Config
 	pricingService *PricingService
 	fallbackPrices map[string]*ModelPricing // 硬编码回退价格
+	// configuredPrices 配置文件 pricing.model_prices 中的自定义价格（key 为小写模型名）
+	configuredPrices map[string]*ModelPricing
 
 	// fallbackWarnSeen 记录已打过 fallback 警告日志的(已小写化)模型名,
 	// 让 "[Billing] Using fallback pricing" 每个模型每进程最多打一条,
@@ -186,10 +190,37 @@ func NewBillingService(cfg *config.Config, pricingService *PricingService) *Bill
 
 	// 初始化硬编码回退价格（当动态价格不可用时使用）
 	s.initFallbackPricing()
+	s.initConfiguredPricing()
 
 	return s
 }
 
+// initConfiguredPricing 加载配置文件中的自定义模型价格（USD / 1K tokens 换算为 USD / token）
+func (s *BillingService) initConfiguredPricing() {
+	if s.cfg == nil || len(s.cfg.Pricing.ModelPrices) == 0 {
+		return
+	}
+	s.configuredPrices = make(map[string]*ModelPricing, len(s.cfg.Pricing.ModelPrices))
+	for _, price := range s.cfg.Pricing.ModelPrices {
+		model := strings.ToLower(strings.TrimSpace(price.Model))
+		if model == "" {
+			continue
+		}
+		cacheCreation := price.CacheCreationPer1K / 1000
+		s.configuredPrices[model] = &ModelPricing{
+			InputPricePerToken:             price.InputPer1K / 1000,
+			InputPricePerTokenPriority:     price.InputPer1K / 1000,
+			OutputPricePerToken:            price.OutputPer1K / 1000,
+			OutputPricePerTokenPriority:    price.OutputPer1K / 1000,
+			CacheCreationPricePerToken:     cacheCreation,
+			CacheCreation5mPrice:           cacheCreation,
+			CacheCreation1hPrice:           cacheCreation,
+			CacheReadPricePerToken:         price.CacheReadPer1K / 1000,
+			CacheReadPricePerTokenPriority: price.CacheReadPer1K / 1000,
+		}
+	}
+}
+
 // initFallbackPricing 初始化硬编码回退价格（当动态价格不可用时使用）
 // 价格单位：USD per token（与LiteLLM格式一致）
 func (s *BillingService) initFallbackPricing() {
@@ -696,7 +727,13 @@ func (s *BillingService) GetModelPricing(model string) (*ModelPricing, error) {
 	// 标准化模型名称（转小写）
 	model = strings.ToLower(model)
 
-	// 1. 优先从动态价格服务获取
+	// 0. 配置文件中的自定义价格优先（管理员显式配置，不再叠加模型特定策略）
+	if configured := s.configuredPrices[strings.TrimSpace(model)]; configured != nil {
+		pricing := *configured
+		return &pricing, nil
+	}
+
+	// 1. 其次从动态价格服务获取
 	if s.pricingService != nil {
 		litellmPricing := s.pricingService.GetModelPricing(model)
 		if litellmPricing != nil {
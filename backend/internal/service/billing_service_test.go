@@ -1387,3 +1387,38 @@ func TestComputeTokenBreakdown_NonExplicitZeroImagePrice_FallsBackToOutput(t *te
 	// textOutputTokens = 200 - 50 = 150
 	require.InDelta(t, 150*15e-6, bd.OutputCost, 1e-12)
 }
+
+func TestGetModelPricing_ConfiguredModelPricesTakePrecedence(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pricing.ModelPrices = []config.ModelPriceConfig{
+		{Model: "My-Finetune", InputPer1K: 0.002, OutputPer1K: 0.008, CacheCreationPer1K: 0.0025, CacheReadPer1K: 0.0002},
+		{Model: "claude-sonnet-4", InputPer1K: 0.001, OutputPer1K: 0.005},
+	}
+	svc := NewBillingService(cfg, nil)
+
+	pricing, err := svc.GetModelPricing("my-finetune")
+	require.NoError(t, err)
+	require.InDelta(t, 2e-6, pricing.InputPricePerToken, 1e-12)
+	require.InDelta(t, 8e-6, pricing.OutputPricePerToken, 1e-12)
+	require.InDelta(t, 2.5e-6, pricing.CacheCreationPricePerToken, 1e-12)
+	require.InDelta(t, 0.2e-6, pricing.CacheReadPricePerToken, 1e-12)
+
+	// 配置价格覆盖内置回退价格
+	pricing, err = svc.GetModelPricing("claude-sonnet-4")
+	require.NoError(t, err)
+	require.InDelta(t, 1e-6, pricing.InputPricePerToken, 1e-12)
+	require.Zero(t, pricing.CacheReadPricePerToken)
+
+	// 修改返回值不影响配置表
+	pricing.InputPricePerToken = 1
+	again, err := svc.GetModelPricing("claude-sonnet-4")
+	require.NoError(t, err)
+	require.InDelta(t, 1e-6, again.InputPricePerToken, 1e-12)
+
+	cost, err := svc.CalculateCost("my-finetune", UsageTokens{InputTokens: 1000, OutputTokens: 500, CacheReadTokens: 2000}, 1)
+	require.NoError(t, err)
+	require.InDelta(t, 0.002, cost.InputCost, 1e-10)
+	require.InDelta(t, 0.004, cost.OutputCost, 1e-10)
+	require.InDelta(t, 0.0004, cost.CacheReadCost, 1e-10)
+	require.InDelta(t, 0.0064, cost.TotalCost, 1e-10)
+}
@@ -132,6 +132,16 @@ func (r ChannelMappingResult) ToUsageFields(reqModel, upstreamModel string) Chan
 	}
 }
 
+// EstimateBillingModel 返回选号前费用预估使用的计费模型，与用量计费同口径：
+// requested 按客户端请求模型计费；channel_mapped 按映射后模型计费；
+// upstream 的上游模型需选号后才能确定，预估时同样取映射后模型。
+func (r ChannelMappingResult) EstimateBillingModel(reqModel string) string {
+	if r.BillingModelSource == BillingModelSourceRequested || !r.Mapped || r.MappedModel == "" {
+		return reqModel
+	}
+	return r.MappedModel
+}
+
 const (
 	channelCacheTTL       = 10 * time.Minute
 	channelErrorTTL       = 5 * time.Second // DB 错误时的短缓存
@@ -437,6 +437,16 @@ func TestValidateNoConflictingModels(t *testing.T) {
 	})
 }
 
+func TestChannelMappingResult_EstimateBillingModel(t *testing.T) {
+	mapped := func(source string) ChannelMappingResult {
+		return ChannelMappingResult{Mapped: true, MappedModel: "claude-opus-4", BillingModelSource: source}
+	}
+	require.Equal(t, "claude-opus-4", mapped(BillingModelSourceChannelMapped).EstimateBillingModel("claude-sonnet-4"))
+	require.Equal(t, "claude-opus-4", mapped(BillingModelSourceUpstream).EstimateBillingModel("claude-sonnet-4"))
+	require.Equal(t, "claude-sonnet-4", mapped(BillingModelSourceRequested).EstimateBillingModel("claude-sonnet-4"))
+	require.Equal(t, "claude-sonnet-4", ChannelMappingResult{MappedModel: "claude-sonnet-4"}.EstimateBillingModel("claude-sonnet-4"))
+}
+
 func TestValidateNoConflictingMappings(t *testing.T) {
 	tests := []struct {
 		name        string
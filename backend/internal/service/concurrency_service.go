@@ -6,12 +6,14 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
+	"math"
 	"os"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"golang.org/x/sync/singleflight"
 )
@@ -50,6 +52,11 @@ type ConcurrencyCache interface {
 
 	// 启动时清理旧进程遗留槽位与等待计数
 	CleanupStaleProcessSlots(ctx context.Context, activeRequestPrefix string) error
+
+	// 实例心跳与失联实例槽位回收
+	// 槽位成员 requestID 以实例 ID 为前缀；心跳超过 ttl 未刷新的实例视为失联，其槽位可被回收
+	HeartbeatInstance(ctx context.Context, instanceID string, ttl time.Duration) error
+	ReconcileStaleSlots(ctx context.Context, activeRequestPrefix string) (int, error)
 }
 
 var (
@@ -82,7 +89,60 @@ func (s *ConcurrencyService) CleanupStaleProcessSlots(ctx context.Context) error
 	return s.cache.CleanupStaleProcessSlots(ctx, RequestIDPrefix())
 }
 
+// StartInstanceHeartbeat 同步写入一次实例心跳后，后台每 grace/3 刷新心跳，每 grace 回收一次失联实例的槽位。
+// 必须在开始处理请求前调用，确保其他实例回收时能看到本实例存活。
+func (s *ConcurrencyService) StartInstanceHeartbeat(grace time.Duration) {
+	if s == nil || s.cache == nil {
+		return
+	}
+	if grace <= 0 {
+		grace = defaultInstanceHeartbeatGrace
+	}
+	stopCtx := s.lifecycleContext()
+	heartbeat := func() {
+		ctx, cancel := context.WithTimeout(stopCtx, 2*time.Second)
+		defer cancel()
+		if err := s.cache.HeartbeatInstance(ctx, RequestIDPrefix(), grace); err != nil {
+			logger.LegacyPrintf("service.concurrency", "Warning: instance heartbeat failed: %v", err)
+		}
+	}
+	reconcile := func() {
+		ctx, cancel := context.WithTimeout(stopCtx, 30*time.Second)
+		defer cancel()
+		removed, err := s.cache.ReconcileStaleSlots(ctx, RequestIDPrefix())
+		if err != nil {
+			logger.LegacyPrintf("service.concurrency", "Warning: reconcile stale slots failed: %v", err)
+		}
+		if removed > 0 {
+			logger.LegacyPrintf("service.concurrency", "Reclaimed %d concurrency slots held by dead instances", removed)
+		}
+	}
+
+	heartbeat()
+	s.lifecycleWg.Add(1)
+	go func() {
+		defer s.lifecycleWg.Done()
+		heartbeatTicker := time.NewTicker(grace / 3)
+		defer heartbeatTicker.Stop()
+		reconcileTicker := time.NewTicker(grace)
+		defer reconcileTicker.Stop()
+		for {
+			select {
+			case <-stopCtx.Done():
+				return
+			case <-heartbeatTicker.C:
+				heartbeat()
+			case <-reconcileTicker.C:
+				reconcile()
+			}
+		}
+	}()
+}
+
 const (
+	// 默认实例心跳宽限期：超过该时长未刷新心跳的实例，其槽位会被其他实例回收
+	defaultInstanceHeartbeatGrace = 60 * time.Second
+
 	// 默认等待队列额外槽位
 	defaultExtraWaitSlots = 20
 
@@ -99,6 +159,14 @@ type ConcurrencyService struct {
 	accountLoadCacheMu  sync.RWMutex
 	accountLoadCache    map[string]cachedAccountLoadBatch
 	accountLoadGroup    singleflight.Group
+
+	waitQueuePolicy atomic.Pointer[WaitQueuePolicy]
+
+	// 后台任务（实例心跳、槽位清理）生命周期，Stop 时取消并等待退出
+	lifecycleOnce   sync.Once
+	lifecycleCtx    context.Context
+	lifecycleCancel context.CancelFunc
+	lifecycleWg     sync.WaitGroup
 }
 
 type cachedAccountLoadBatch struct {
@@ -243,7 +311,7 @@ func (s *ConcurrencyService) AcquireUserSlot(ctx context.Context, userID int64,
 
 // IncrementWaitCount attempts to increment the wait queue counter for a user.
 // Returns true if successful, false if the wait queue is full.
-// maxWait should be user.Concurrency + defaultExtraWaitSlots
+// maxWait should come from CalculateMaxWait (user.Concurrency + wait queue depth)
 func (s *ConcurrencyService) IncrementWaitCount(ctx context.Context, userID int64, maxWait int) (bool, error) {
 	if s.cache == nil {
 		// Redis not available, allow request
@@ -311,13 +379,70 @@ func (s *ConcurrencyService) GetAccountWaitingCount(ctx context.Context, account
 	return s.cache.GetAccountWaitingCount(ctx, accountID)
 }
 
-// CalculateMaxWait calculates the maximum wait queue size for a user
-// maxWait = userConcurrency + defaultExtraWaitSlots
-func CalculateMaxWait(userConcurrency int) int {
+// WaitQueuePolicy 用户等待队列深度策略：
+// queue = clamp(ceil(userConcurrency × Multiplier), Min, Max)，maxWait = userConcurrency + queue
+type WaitQueuePolicy struct {
+	// Multiplier 为 0 时队列深度不随并发数缩放，仅由 Min 决定
+	Multiplier float64
+	Min        int
+	// Max 为 0 表示不限制
+	Max int
+}
+
+// DefaultWaitQueuePolicy 默认策略：固定 defaultExtraWaitSlots 个等待槽位
+func DefaultWaitQueuePolicy() WaitQueuePolicy {
+	return WaitQueuePolicy{Min: defaultExtraWaitSlots}
+}
+
+// WaitQueuePolicyFromConfig 从并发配置构建等待队列策略
+func WaitQueuePolicyFromConfig(cfg config.ConcurrencyConfig) WaitQueuePolicy {
+	return WaitQueuePolicy{
+		Multiplier: cfg.WaitQueueMultiplier,
+		Min:        cfg.WaitQueueMin,
+		Max:        cfg.WaitQueueMax,
+	}
+}
+
+// MaxWait calculates the maximum wait queue size (running + waiting) for a user
+func (p WaitQueuePolicy) MaxWait(userConcurrency int) int {
 	if userConcurrency <= 0 {
 		userConcurrency = 1
 	}
-	return userConcurrency + defaultExtraWaitSlots
+	queue := 0
+	if p.Multiplier > 0 {
+		queue = int(math.Ceil(float64(userConcurrency) * p.Multiplier))
+	}
+	if queue < p.Min {
+		queue = p.Min
+	}
+	if p.Max > 0 && queue > p.Max {
+		queue = p.Max
+	}
+	return userConcurrency + queue
+}
+
+// CalculateMaxWait calculates the maximum wait queue size for a user with the default policy
+// maxWait = userConcurrency + defaultExtraWaitSlots
+func CalculateMaxWait(userConcurrency int) int {
+	return DefaultWaitQueuePolicy().MaxWait(userConcurrency)
+}
+
+// SetWaitQueuePolicy 设置用户等待队列策略（运行期可替换）
+func (s *ConcurrencyService) SetWaitQueuePolicy(policy WaitQueuePolicy) {
+	if s == nil {
+		return
+	}
+	s.waitQueuePolicy.Store(&policy)
+}
+
+// CalculateMaxWait 按当前配置的策略计算用户最大等待数，未配置时使用默认策略
+func (s *ConcurrencyService) CalculateMaxWait(userConcurrency int) int {
+	if s != nil {
+		if policy := s.waitQueuePolicy.Load(); policy != nil {
+			return policy.MaxWait(userConcurrency)
+		}
+	}
+	return CalculateMaxWait(userConcurrency)
 }
 
 // GetAccountsLoadBatch 批量获取账号负载信息。
@@ -478,8 +603,9 @@ func (s *ConcurrencyService) StartSlotCleanupWorker(accountRepo AccountRepositor
 		return
 	}
 
+	stopCtx := s.lifecycleContext()
 	runCleanup := func() {
-		listCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		listCtx, cancel := context.WithTimeout(stopCtx, 5*time.Second)
 		accounts, err := accountRepo.ListSchedulable(listCtx)
 		cancel()
 		if err != nil {
@@ -487,7 +613,10 @@ func (s *ConcurrencyService) StartSlotCleanupWorker(accountRepo AccountRepositor
 			return
 		}
 		for _, account := range accounts {
-			accountCtx, accountCancel := context.WithTimeout(context.Background(), 2*time.Second)
+			if stopCtx.Err() != nil {
+				return
+			}
+			accountCtx, accountCancel := context.WithTimeout(stopCtx, 2*time.Second)
 			err := s.cache.CleanupExpiredAccountSlots(accountCtx, account.ID)
 			accountCancel()
 			if err != nil {
@@ -496,17 +625,41 @@ func (s *ConcurrencyService) StartSlotCleanupWorker(accountRepo AccountRepositor
 		}
 	}
 
+	s.lifecycleWg.Add(1)
 	go func() {
+		defer s.lifecycleWg.Done()
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		runCleanup()
-		for range ticker.C {
-			runCleanup()
+		for {
+			select {
+			case <-stopCtx.Done():
+				return
+			case <-ticker.C:
+				runCleanup()
+			}
 		}
 	}()
 }
 
+func (s *ConcurrencyService) lifecycleContext() context.Context {
+	s.lifecycleOnce.Do(func() {
+		s.lifecycleCtx, s.lifecycleCancel = context.WithCancel(context.Background())
+	})
+	return s.lifecycleCtx
+}
+
+// Stop 停止实例心跳与槽位清理后台任务，并等待其退出
+func (s *ConcurrencyService) Stop() {
+	if s == nil {
+		return
+	}
+	s.lifecycleContext()
+	s.lifecycleCancel()
+	s.lifecycleWg.Wait()
+}
+
 // GetAccountConcurrencyBatch gets current concurrency counts for multiple accounts.
 // Uses a detached context with timeout to prevent HTTP request cancellation from
 // causing the entire batch to fail (which would show all concurrency as 0).
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -99,6 +100,14 @@ func (c *stubConcurrencyCacheForTest) CleanupStaleProcessSlots(_ context.Context
 	return c.cleanupErr
 }
 
+func (c *stubConcurrencyCacheForTest) HeartbeatInstance(_ context.Context, _ string, _ time.Duration) error {
+	return nil
+}
+
+func (c *stubConcurrencyCacheForTest) ReconcileStaleSlots(_ context.Context, _ string) (int, error) {
+	return 0, nil
+}
+
 type trackingConcurrencyCache struct {
 	stubConcurrencyCacheForTest
 	cleanupPrefix string
@@ -121,6 +130,49 @@ func TestCleanupStaleProcessSlots_DelegatesPrefix(t *testing.T) {
 	require.Equal(t, RequestIDPrefix(), cache.cleanupPrefix)
 }
 
+type heartbeatTrackingConcurrencyCache struct {
+	stubConcurrencyCacheForTest
+	heartbeatID  string
+	heartbeatTTL time.Duration
+}
+
+func (c *heartbeatTrackingConcurrencyCache) HeartbeatInstance(_ context.Context, instanceID string, ttl time.Duration) error {
+	c.heartbeatID = instanceID
+	c.heartbeatTTL = ttl
+	return nil
+}
+
+func TestStartInstanceHeartbeat_WritesHeartbeatBeforeReturning(t *testing.T) {
+	cache := &heartbeatTrackingConcurrencyCache{}
+	svc := NewConcurrencyService(cache)
+	svc.StartInstanceHeartbeat(0)
+	defer svc.Stop()
+	require.Equal(t, RequestIDPrefix(), cache.heartbeatID)
+	require.Equal(t, defaultInstanceHeartbeatGrace, cache.heartbeatTTL)
+}
+
+type heartbeatCountingConcurrencyCache struct {
+	stubConcurrencyCacheForTest
+	heartbeats atomic.Int64
+}
+
+func (c *heartbeatCountingConcurrencyCache) HeartbeatInstance(_ context.Context, _ string, _ time.Duration) error {
+	c.heartbeats.Add(1)
+	return nil
+}
+
+func TestStartInstanceHeartbeat_StopsOnServiceStop(t *testing.T) {
+	cache := &heartbeatCountingConcurrencyCache{}
+	svc := NewConcurrencyService(cache)
+	svc.StartInstanceHeartbeat(30 * time.Millisecond)
+	require.Eventually(t, func() bool { return cache.heartbeats.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+	svc.Stop()
+	stopped := cache.heartbeats.Load()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, stopped, cache.heartbeats.Load(), "heartbeat must not run after Stop")
+}
+
 func TestAcquireAccountSlot_Success(t *testing.T) {
 	cache := &stubConcurrencyCacheForTest{acquireResult: true}
 	svc := NewConcurrencyService(cache)
@@ -335,6 +387,46 @@ func TestCalculateMaxWait(t *testing.T) {
 	}
 }
 
+func TestWaitQueuePolicy_MaxWait(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      WaitQueuePolicy
+		concurrency int
+		expected    int
+	}{
+		{"默认策略", DefaultWaitQueuePolicy(), 5, 25},
+		{"倍数低于下限", WaitQueuePolicy{Multiplier: 2, Min: 20}, 5, 25},
+		{"倍数高于下限", WaitQueuePolicy{Multiplier: 2, Min: 20}, 30, 90},
+		{"倍数向上取整", WaitQueuePolicy{Multiplier: 1.5}, 3, 8},
+		{"上限截断", WaitQueuePolicy{Multiplier: 4, Min: 5, Max: 50}, 100, 150},
+		{"上限截断下限", WaitQueuePolicy{Min: 20, Max: 10}, 1, 11},
+		{"并发数非正按 1 计算", WaitQueuePolicy{Multiplier: 3}, 0, 4},
+		{"全零策略不排队", WaitQueuePolicy{}, 10, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.policy.MaxWait(tt.concurrency))
+		})
+	}
+}
+
+func TestConcurrencyService_CalculateMaxWaitUsesPolicy(t *testing.T) {
+	svc := NewConcurrencyService(&stubConcurrencyCacheForTest{})
+	require.Equal(t, CalculateMaxWait(8), svc.CalculateMaxWait(8), "未配置策略时使用默认值")
+
+	svc.SetWaitQueuePolicy(WaitQueuePolicyFromConfig(config.ConcurrencyConfig{
+		WaitQueueMultiplier: 2,
+		WaitQueueMin:        4,
+		WaitQueueMax:        12,
+	}))
+	require.Equal(t, 1+4, svc.CalculateMaxWait(1))
+	require.Equal(t, 5+10, svc.CalculateMaxWait(5))
+	require.Equal(t, 10+12, svc.CalculateMaxWait(10))
+
+	var nilSvc *ConcurrencyService
+	require.Equal(t, 21, nilSvc.CalculateMaxWait(1))
+}
+
 func TestGetAccountWaitingCount(t *testing.T) {
 	cache := &stubConcurrencyCacheForTest{waitCount: 5}
 	svc := NewConcurrencyService(cache)
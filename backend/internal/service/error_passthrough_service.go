@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"net/http"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -47,6 +49,10 @@ type ErrorPassthroughService struct {
 	// 本地内存缓存，用于快速匹配
 	localCache   []*cachedPassthroughRule
 	localCacheMu sync.RWMutex
+
+	// regexCache 已编译正则缓存（pattern → *regexp.Regexp），规则重载时复用，避免重复编译；
+	// 每次重载后剔除当前规则集不再引用的 pattern，容量以当前规则数为上限
+	regexCache sync.Map
 }
 
 // cachedPassthroughRule 预计算的规则缓存，避免运行时重复 ToLower
@@ -55,6 +61,15 @@ type cachedPassthroughRule struct {
 	lowerKeywords  []string         // 预计算的小写关键词
 	lowerPlatforms []string         // 预计算的小写平台
 	errorCodeSet   map[int]struct{} // 预计算的 error code set
+	hasBodyRegex   bool             // 是否配置了响应体正则（编译失败时仍视为已配置、永不命中）
+	bodyRegex      *regexp.Regexp   // 预编译的响应体正则
+	headerMatchers []cachedHeaderMatcher
+}
+
+// cachedHeaderMatcher 预编译的响应头匹配条件；regex 为 nil 表示编译失败、永不命中
+type cachedHeaderMatcher struct {
+	name  string
+	regex *regexp.Regexp
 }
 
 const maxBodyMatchLen = 8 << 10 // 8KB，错误信息不会在 8KB 之后才出现
@@ -155,6 +170,17 @@ func (s *ErrorPassthroughService) Delete(ctx context.Context, id int64) error {
 // MatchRule 匹配透传规则
 // 返回第一个匹配的规则，如果没有匹配则返回 nil
 func (s *ErrorPassthroughService) MatchRule(platform string, statusCode int, body []byte) *model.ErrorPassthroughRule {
+	return s.MatchRuleWithHeaders(platform, statusCode, nil, body)
+}
+
+// MatchRuleWithHeaders 匹配透传规则，额外支持响应头条件
+// headers 为 nil 时配置了响应头条件的规则视为该条件不满足
+func (s *ErrorPassthroughService) MatchRuleWithHeaders(platform string, statusCode int, headers http.Header, body []byte) *model.ErrorPassthroughRule {
+	return s.matchRule(platform, statusCode, headers, body, false)
+}
+
+// matchRule 按优先级匹配规则；includeDisabled 仅供 dry-run 预览未启用的规则
+func (s *ErrorPassthroughService) matchRule(platform string, statusCode int, headers http.Header, body []byte, includeDisabled bool) *model.ErrorPassthroughRule {
 	rules := s.getCachedRules()
 	if len(rules) == 0 {
 		return nil
@@ -165,13 +191,13 @@ func (s *ErrorPassthroughService) MatchRule(platform string, statusCode int, bod
 	var bodyLowerDone bool
 
 	for _, rule := range rules {
-		if !rule.Enabled {
+		if !rule.Enabled && !includeDisabled {
 			continue
 		}
 		if !s.platformMatchesCached(rule, lowerPlatform) {
 			continue
 		}
-		if s.ruleMatchesOptimized(rule, statusCode, body, &bodyLower, &bodyLowerDone) {
+		if s.ruleMatchesWithHeaders(rule, statusCode, headers, body, &bodyLower, &bodyLowerDone) {
 			return rule.ErrorPassthroughRule
 		}
 	}
@@ -179,6 +205,67 @@ func (s *ErrorPassthroughService) MatchRule(platform string, statusCode int, bod
 	return nil
 }
 
+// ErrorPassthroughDryRunInput dry-run 测试输入：模拟一次上游错误响应
+type ErrorPassthroughDryRunInput struct {
+	Platform   string
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+	// IncludeDisabled 是否同时评估未启用的规则，便于启用前验证
+	IncludeDisabled bool
+}
+
+// ErrorPassthroughDryRunResult dry-run 测试结果
+type ErrorPassthroughDryRunResult struct {
+	Matched        bool                        `json:"matched"`
+	Rule           *model.ErrorPassthroughRule `json:"rule,omitempty"`
+	ResponseCode   int                         `json:"response_code,omitempty"`
+	Message        string                      `json:"message,omitempty"`
+	SkipMonitoring bool                        `json:"skip_monitoring"`
+}
+
+// DryRun 使用当前内存中的规则集执行一次匹配，返回命中的规则及最终返回给客户端的状态码和消息。
+// 不产生任何副作用（不写 context、不记录运维日志）。
+func (s *ErrorPassthroughService) DryRun(input ErrorPassthroughDryRunInput) *ErrorPassthroughDryRunResult {
+	rule := s.matchRule(input.Platform, input.StatusCode, input.Headers, input.Body, input.IncludeDisabled)
+	if rule == nil {
+		return &ErrorPassthroughDryRunResult{Matched: false}
+	}
+	respCode := input.StatusCode
+	if !rule.PassthroughCode && rule.ResponseCode != nil {
+		respCode = *rule.ResponseCode
+	}
+	msg := ExtractUpstreamErrorMessage(input.Body)
+	if !rule.PassthroughBody && rule.CustomMessage != nil {
+		msg = *rule.CustomMessage
+	}
+	return &ErrorPassthroughDryRunResult{
+		Matched:        true,
+		Rule:           rule,
+		ResponseCode:   respCode,
+		Message:        msg,
+		SkipMonitoring: rule.SkipMonitoring,
+	}
+}
+
+// Reload 强制从数据库重新加载规则并通知其他实例（用于直接修改数据库后的手动热更新）
+func (s *ErrorPassthroughService) Reload(ctx context.Context) error {
+	if s.cache != nil {
+		if err := s.cache.Invalidate(ctx); err != nil {
+			logger.LegacyPrintf("service.error_passthrough", "[ErrorPassthroughService] Failed to invalidate cache: %v", err)
+		}
+	}
+	if err := s.reloadRulesFromDB(ctx); err != nil {
+		return err
+	}
+	if s.cache != nil {
+		if err := s.cache.NotifyUpdate(ctx); err != nil {
+			logger.LegacyPrintf("service.error_passthrough", "[ErrorPassthroughService] Failed to notify cache update: %v", err)
+		}
+	}
+	return nil
+}
+
 // getCachedRules 获取缓存的规则列表（按优先级排序）
 func (s *ErrorPassthroughService) getCachedRules() []*cachedPassthroughRule {
 	s.localCacheMu.RLock()
@@ -235,9 +322,10 @@ func (s *ErrorPassthroughService) reloadRulesFromDB(ctx context.Context) error {
 	return nil
 }
 
-// setLocalCache 设置本地缓存，预计算小写值和 set 以避免运行时重复计算
+// setLocalCache 设置本地缓存，预计算小写值、set 与正则以避免运行时重复计算
 func (s *ErrorPassthroughService) setLocalCache(rules []*model.ErrorPassthroughRule) {
 	cached := make([]*cachedPassthroughRule, len(rules))
+	livePatterns := make(map[string]struct{})
 	for i, r := range rules {
 		cr := &cachedPassthroughRule{ErrorPassthroughRule: r}
 		if len(r.Keywords) > 0 {
@@ -258,6 +346,21 @@ func (s *ErrorPassthroughService) setLocalCache(rules []*model.ErrorPassthroughR
 				cr.errorCodeSet[code] = struct{}{}
 			}
 		}
+		if r.HasBodyRegex() {
+			cr.hasBodyRegex = true
+			cr.bodyRegex = s.compileRegexCached(r.ID, *r.BodyRegex)
+			livePatterns[*r.BodyRegex] = struct{}{}
+		}
+		if len(r.HeaderMatchers) > 0 {
+			cr.headerMatchers = make([]cachedHeaderMatcher, len(r.HeaderMatchers))
+			for j, hm := range r.HeaderMatchers {
+				cr.headerMatchers[j] = cachedHeaderMatcher{
+					name:  http.CanonicalHeaderKey(strings.TrimSpace(hm.Name)),
+					regex: s.compileRegexCached(r.ID, hm.Regex),
+				}
+				livePatterns[hm.Regex] = struct{}{}
+			}
+		}
 		cached[i] = cr
 	}
 
@@ -269,6 +372,21 @@ func (s *ErrorPassthroughService) setLocalCache(rules []*model.ErrorPassthroughR
 	s.localCacheMu.Lock()
 	s.localCache = cached
 	s.localCacheMu.Unlock()
+
+	s.pruneRegexCache(livePatterns)
+}
+
+// pruneRegexCache 剔除不在 live 中的已编译正则，避免规则反复修改时缓存无限增长
+func (s *ErrorPassthroughService) pruneRegexCache(live map[string]struct{}) {
+	s.regexCache.Range(func(key, _ any) bool {
+		if pattern, _ := key.(string); pattern != "" {
+			if _, ok := live[pattern]; ok {
+				return true
+			}
+		}
+		s.regexCache.Delete(key)
+		return true
+	})
 }
 
 // clearLocalCache 清空本地缓存，避免刷新失败时继续命中陈旧规则。
@@ -334,40 +452,101 @@ func (s *ErrorPassthroughService) platformMatchesCached(rule *cachedPassthroughR
 	return false
 }
 
-// ruleMatchesOptimized 优化的规则匹配，支持短路和延迟 body 转换
+// compileRegexCached 编译正则并缓存；编译失败（如数据库中残留的历史非法正则）返回 nil 并记录日志
+func (s *ErrorPassthroughService) compileRegexCached(ruleID int64, pattern string) *regexp.Regexp {
+	if cached, ok := s.regexCache.Load(pattern); ok {
+		re, _ := cached.(*regexp.Regexp)
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		logger.LegacyPrintf("service.error_passthrough", "[ErrorPassthroughService] Invalid regex in rule %d, condition disabled: %v", ruleID, err)
+		return nil
+	}
+	actual, _ := s.regexCache.LoadOrStore(pattern, re)
+	re, _ = actual.(*regexp.Regexp)
+	return re
+}
+
+// ruleMatchesOptimized 优化的规则匹配，支持短路和延迟 body 转换（不含响应头条件）
 func (s *ErrorPassthroughService) ruleMatchesOptimized(rule *cachedPassthroughRule, statusCode int, body []byte, bodyLower *string, bodyLowerDone *bool) bool {
-	hasErrorCodes := len(rule.errorCodeSet) > 0
-	hasKeywords := len(rule.lowerKeywords) > 0
+	return s.ruleMatchesWithHeaders(rule, statusCode, nil, body, bodyLower, bodyLowerDone)
+}
 
-	if !hasErrorCodes && !hasKeywords {
-		return false
+// ruleMatchesWithHeaders 按 match_mode 组合错误码、响应头、关键词、响应体正则四类条件。
+// 条件按开销从低到高求值并短路；未配置的条件不参与组合。
+func (s *ErrorPassthroughService) ruleMatchesWithHeaders(rule *cachedPassthroughRule, statusCode int, headers http.Header, body []byte, bodyLower *string, bodyLowerDone *bool) bool {
+	conditions := make([]func() bool, 0, 4)
+	if len(rule.errorCodeSet) > 0 {
+		conditions = append(conditions, func() bool { return s.containsIntSet(rule.errorCodeSet, statusCode) })
+	}
+	if len(rule.headerMatchers) > 0 {
+		conditions = append(conditions, func() bool { return s.headersMatchCached(rule.headerMatchers, headers) })
+	}
+	if len(rule.lowerKeywords) > 0 {
+		conditions = append(conditions, func() bool {
+			return s.containsAnyKeywordCached(ensureBodyLower(body, bodyLower, bodyLowerDone), rule.lowerKeywords)
+		})
+	}
+	if rule.hasBodyRegex {
+		conditions = append(conditions, func() bool { return s.bodyMatchesRegexCached(rule.bodyRegex, body) })
 	}
 
-	codeMatch := !hasErrorCodes || s.containsIntSet(rule.errorCodeSet, statusCode)
+	if len(conditions) == 0 {
+		return false
+	}
 
 	if rule.MatchMode == model.MatchModeAll {
 		// "all" 模式：所有配置的条件都必须满足，短路
-		if hasErrorCodes && !codeMatch {
-			return false
-		}
-		if hasKeywords {
-			return s.containsAnyKeywordCached(ensureBodyLower(body, bodyLower, bodyLowerDone), rule.lowerKeywords)
+		for _, cond := range conditions {
+			if !cond() {
+				return false
+			}
 		}
-		return codeMatch
+		return true
 	}
 
 	// "any" 模式：任一条件满足即可，短路
-	if hasErrorCodes && hasKeywords {
-		if codeMatch {
+	for _, cond := range conditions {
+		if cond() {
 			return true
 		}
-		return s.containsAnyKeywordCached(ensureBodyLower(body, bodyLower, bodyLowerDone), rule.lowerKeywords)
 	}
-	// 只配置了一种条件
-	if hasKeywords {
-		return s.containsAnyKeywordCached(ensureBodyLower(body, bodyLower, bodyLowerDone), rule.lowerKeywords)
+	return false
+}
+
+// headersMatchCached 所有响应头条件都满足才算命中；多值响应头任一值匹配即可
+func (s *ErrorPassthroughService) headersMatchCached(matchers []cachedHeaderMatcher, headers http.Header) bool {
+	if headers == nil {
+		return false
+	}
+	for _, m := range matchers {
+		if m.regex == nil {
+			return false
+		}
+		matched := false
+		for _, v := range headers.Values(m.name) {
+			if m.regex.MatchString(v) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyMatchesRegexCached 使用预编译正则匹配响应体，限制 8KB
+func (s *ErrorPassthroughService) bodyMatchesRegexCached(re *regexp.Regexp, body []byte) bool {
+	if re == nil {
+		return false
+	}
+	if len(body) > maxBodyMatchLen {
+		body = body[:maxBodyMatchLen]
 	}
-	return codeMatch
+	return re.Match(body)
 }
 
 // containsIntSet 使用 map 查找替代线性扫描
@@ -5,6 +5,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -1012,3 +1014,325 @@ func newPassthroughRuleForWritePathTest(id int64, keyword, customMsg string) *mo
 // Helper functions
 func testIntPtr(i int) *int       { return &i }
 func testStrPtr(s string) *string { return &s }
+
+// =============================================================================
+// 测试响应体正则与响应头条件
+// =============================================================================
+
+func TestMatchRule_BodyRegex(t *testing.T) {
+	rules := []*model.ErrorPassthroughRule{
+		{
+			ID:              1,
+			Name:            "Billing Hard Limit",
+			Enabled:         true,
+			BodyRegex:       testStrPtr(`"code"\s*:\s*"billing_hard_limit_reached"`),
+			MatchMode:       model.MatchModeAny,
+			PassthroughCode: true,
+			PassthroughBody: true,
+		},
+	}
+	svc := newTestService(rules)
+
+	hit := svc.MatchRule("openai", 429, []byte(`{"error":{"code": "billing_hard_limit_reached","message":"limit"}}`))
+	require.NotNil(t, hit)
+	assert.Equal(t, int64(1), hit.ID)
+
+	miss := svc.MatchRule("openai", 429, []byte(`{"error":{"code":"rate_limit_exceeded"}}`))
+	assert.Nil(t, miss)
+
+	// 正则默认区分大小写
+	assert.Nil(t, svc.MatchRule("openai", 429, []byte(`{"error":{"code":"BILLING_HARD_LIMIT_REACHED"}}`)))
+}
+
+func TestMatchRule_BodyRegexOnlyScansFirst8KB(t *testing.T) {
+	rules := []*model.ErrorPassthroughRule{
+		{ID: 1, Name: "Tail", Enabled: true, BodyRegex: testStrPtr(`needle`), MatchMode: model.MatchModeAny},
+	}
+	svc := newTestService(rules)
+
+	body := append([]byte(strings.Repeat("x", maxBodyMatchLen)), []byte("needle")...)
+	assert.Nil(t, svc.MatchRule("openai", 400, body))
+}
+
+func TestMatchRule_BodyRegexAllModeWithErrorCode(t *testing.T) {
+	rules := []*model.ErrorPassthroughRule{
+		{
+			ID:         1,
+			Name:       "400 + regex",
+			Enabled:    true,
+			ErrorCodes: []int{400},
+			BodyRegex:  testStrPtr(`(?i)context.*too long`),
+			MatchMode:  model.MatchModeAll,
+		},
+	}
+	svc := newTestService(rules)
+
+	assert.NotNil(t, svc.MatchRule("anthropic", 400, []byte("Context window is too long")))
+	assert.Nil(t, svc.MatchRule("anthropic", 500, []byte("Context window is too long")))
+	assert.Nil(t, svc.MatchRule("anthropic", 400, []byte("invalid request")))
+}
+
+func TestMatchRuleWithHeaders(t *testing.T) {
+	rules := []*model.ErrorPassthroughRule{
+		{
+			ID:         1,
+			Name:       "Cloudflare challenge",
+			Enabled:    true,
+			ErrorCodes: []int{403},
+			HeaderMatchers: []model.ErrorPassthroughHeaderMatcher{
+				{Name: "cf-mitigated", Regex: `^challenge$`},
+				{Name: "Content-Type", Regex: `text/html`},
+			},
+			MatchMode: model.MatchModeAll,
+		},
+	}
+	svc := newTestService(rules)
+
+	headers := http.Header{}
+	headers.Set("Cf-Mitigated", "challenge")
+	headers.Set("Content-Type", "text/html; charset=utf-8")
+	assert.NotNil(t, svc.MatchRuleWithHeaders("openai", 403, headers, []byte("<html>")))
+
+	// 任一响应头条件不满足都不命中
+	partial := http.Header{}
+	partial.Set("Cf-Mitigated", "challenge")
+	assert.Nil(t, svc.MatchRuleWithHeaders("openai", 403, partial, []byte("<html>")))
+
+	// 旧调用路径（无响应头）不会误命中响应头规则
+	assert.Nil(t, svc.MatchRule("openai", 403, []byte("<html>")))
+}
+
+func TestMatchRuleWithHeaders_LegacyRulesUnaffected(t *testing.T) {
+	rules := []*model.ErrorPassthroughRule{
+		{ID: 1, Name: "Legacy", Enabled: true, ErrorCodes: []int{422}, Keywords: []string{"context limit"}, MatchMode: model.MatchModeAll},
+	}
+	svc := newTestService(rules)
+
+	headers := http.Header{"X-Request-Id": []string{"req_1"}}
+	assert.NotNil(t, svc.MatchRuleWithHeaders("anthropic", 422, headers, []byte("Context limit exceeded")))
+	assert.NotNil(t, svc.MatchRule("anthropic", 422, []byte("Context limit exceeded")))
+}
+
+func TestSetLocalCache_RegexCompileCached(t *testing.T) {
+	rule := &model.ErrorPassthroughRule{
+		ID:             1,
+		Name:           "Cached",
+		Enabled:        true,
+		BodyRegex:      testStrPtr(`quota.*exceeded`),
+		HeaderMatchers: []model.ErrorPassthroughHeaderMatcher{{Name: "x-error", Regex: `quota.*exceeded`}},
+		MatchMode:      model.MatchModeAny,
+	}
+	svc := newTestService([]*model.ErrorPassthroughRule{rule})
+
+	svc.localCacheMu.RLock()
+	first := svc.localCache[0]
+	svc.localCacheMu.RUnlock()
+	require.NotNil(t, first.bodyRegex)
+	assert.Same(t, first.bodyRegex, first.headerMatchers[0].regex, "相同 pattern 应复用同一编译结果")
+
+	// 重载规则时复用已编译正则
+	svc.setLocalCache([]*model.ErrorPassthroughRule{rule})
+	svc.localCacheMu.RLock()
+	second := svc.localCache[0]
+	svc.localCacheMu.RUnlock()
+	assert.Same(t, first.bodyRegex, second.bodyRegex)
+}
+
+func TestSetLocalCache_PrunesUnreferencedRegex(t *testing.T) {
+	svc := newTestService(nil)
+	for i := 0; i < 50; i++ {
+		svc.setLocalCache([]*model.ErrorPassthroughRule{
+			{ID: 1, Name: "Edited", Enabled: true, BodyRegex: testStrPtr(fmt.Sprintf(`quota-%d`, i)), MatchMode: model.MatchModeAny},
+		})
+	}
+
+	// 规则反复修改后只保留当前规则引用的 pattern
+	var patterns []string
+	svc.regexCache.Range(func(key, _ any) bool {
+		patterns = append(patterns, key.(string))
+		return true
+	})
+	assert.Equal(t, []string{`quota-49`}, patterns)
+
+	svc.setLocalCache(nil)
+	svc.regexCache.Range(func(key, _ any) bool {
+		t.Fatalf("regex cache should be empty, found %v", key)
+		return false
+	})
+}
+
+func TestSetLocalCache_InvalidStoredRegexNeverMatches(t *testing.T) {
+	// 数据库中残留的非法正则（绕过了创建时校验）不应导致 panic，也不应命中
+	rules := []*model.ErrorPassthroughRule{
+		{ID: 1, Name: "Broken", Enabled: true, BodyRegex: testStrPtr(`(unclosed`), MatchMode: model.MatchModeAny},
+	}
+	svc := newTestService(rules)
+	assert.Nil(t, svc.MatchRule("openai", 400, []byte("(unclosed")))
+}
+
+func TestErrorPassthroughRule_ValidateRegex(t *testing.T) {
+	valid := &model.ErrorPassthroughRule{
+		Name:            "Regex",
+		MatchMode:       model.MatchModeAny,
+		BodyRegex:       testStrPtr(`billing_hard_limit_reached`),
+		PassthroughCode: true,
+		PassthroughBody: true,
+	}
+	require.NoError(t, valid.Validate())
+
+	headerOnly := &model.ErrorPassthroughRule{
+		Name:            "Header",
+		MatchMode:       model.MatchModeAny,
+		HeaderMatchers:  []model.ErrorPassthroughHeaderMatcher{{Name: "x-error-type", Regex: `^billing`}},
+		PassthroughCode: true,
+		PassthroughBody: true,
+	}
+	require.NoError(t, headerOnly.Validate())
+
+	badBody := *valid
+	badBody.BodyRegex = testStrPtr(`(unclosed`)
+	err := badBody.Validate()
+	require.Error(t, err)
+	var vErr *model.ValidationError
+	require.True(t, errors.As(err, &vErr))
+	assert.Equal(t, "body_regex", vErr.Field)
+
+	badHeader := *headerOnly
+	badHeader.HeaderMatchers = []model.ErrorPassthroughHeaderMatcher{{Name: "x-error-type", Regex: `[`}}
+	err = badHeader.Validate()
+	require.Error(t, err)
+	require.True(t, errors.As(err, &vErr))
+	assert.Equal(t, "header_matchers", vErr.Field)
+
+	noName := *headerOnly
+	noName.HeaderMatchers = []model.ErrorPassthroughHeaderMatcher{{Name: " ", Regex: `x`}}
+	require.Error(t, noName.Validate())
+}
+
+func TestCreate_RejectsInvalidRegex(t *testing.T) {
+	repo := &mockErrorPassthroughRepo{}
+	svc := &ErrorPassthroughService{repo: repo}
+
+	_, err := svc.Create(context.Background(), &model.ErrorPassthroughRule{
+		Name:            "Bad",
+		MatchMode:       model.MatchModeAny,
+		BodyRegex:       testStrPtr(`*invalid`),
+		PassthroughCode: true,
+		PassthroughBody: true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "body_regex")
+	assert.Empty(t, repo.rules, "非法规则不应写入数据库")
+}
+
+// =============================================================================
+// 测试热更新与 dry-run
+// =============================================================================
+
+func TestReload_PicksUpDirectDBChanges(t *testing.T) {
+	ctx := context.Background()
+	rule := newPassthroughRuleForWritePathTest(1, "overloaded", "服务繁忙")
+	repo := &mockErrorPassthroughRepo{rules: []*model.ErrorPassthroughRule{rule}}
+	cache := newMockErrorPassthroughCache(nil, false)
+	svc := NewErrorPassthroughService(repo, cache)
+
+	body := []byte(`{"message":"server overloaded"}`)
+	require.NotNil(t, svc.MatchRule("anthropic", 503, body))
+
+	// 直接修改数据库（绕过服务写路径），内存规则集不会自动变化
+	disabled := *rule
+	disabled.Enabled = false
+	repo.rules = []*model.ErrorPassthroughRule{&disabled}
+	require.NotNil(t, svc.MatchRule("anthropic", 503, body), "未重载前仍使用旧规则集")
+
+	invalidateBefore := cache.invalidateCalled
+	notifyBefore := cache.notifyCalled
+	require.NoError(t, svc.Reload(ctx))
+	assert.Nil(t, svc.MatchRule("anthropic", 503, body), "重载后应使用数据库最新规则")
+	assert.Equal(t, invalidateBefore+1, cache.invalidateCalled)
+	assert.Equal(t, notifyBefore+1, cache.notifyCalled, "重载后应通知其他实例")
+}
+
+func TestReload_DBErrorKeepsCurrentRules(t *testing.T) {
+	rule := newPassthroughRuleForWritePathTest(1, "overloaded", "服务繁忙")
+	repo := &mockErrorPassthroughRepo{rules: []*model.ErrorPassthroughRule{rule}}
+	svc := &ErrorPassthroughService{repo: repo}
+	svc.setLocalCache(repo.rules)
+
+	repo.listErr = errors.New("db down")
+	require.Error(t, svc.Reload(context.Background()))
+	assert.NotNil(t, svc.MatchRule("anthropic", 503, []byte("overloaded")))
+}
+
+func TestUpdate_PriorityChangeTakesEffectImmediately(t *testing.T) {
+	ctx := context.Background()
+	first := &model.ErrorPassthroughRule{ID: 1, Name: "first", Enabled: true, Priority: 1, ErrorCodes: []int{429}, MatchMode: model.MatchModeAny, PassthroughCode: true, PassthroughBody: true}
+	second := &model.ErrorPassthroughRule{ID: 2, Name: "second", Enabled: true, Priority: 2, ErrorCodes: []int{429}, MatchMode: model.MatchModeAny, PassthroughCode: true, PassthroughBody: true}
+	repo := &mockErrorPassthroughRepo{rules: []*model.ErrorPassthroughRule{first, second}}
+	svc := NewErrorPassthroughService(repo, nil)
+	require.Equal(t, int64(1), svc.MatchRule("openai", 429, nil).ID)
+
+	promoted := *second
+	promoted.Priority = 0
+	_, err := svc.Update(ctx, &promoted)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), svc.MatchRule("openai", 429, nil).ID)
+}
+
+func TestDryRun_MatchedCustomResponse(t *testing.T) {
+	rule := newPassthroughRuleForWritePathTest(7, "billing_hard_limit_reached", "额度已用尽")
+	rule.SkipMonitoring = true
+	svc := newTestService([]*model.ErrorPassthroughRule{rule})
+
+	result := svc.DryRun(ErrorPassthroughDryRunInput{
+		Platform:   "openai",
+		StatusCode: 503,
+		Body:       []byte(`{"error":{"code":"billing_hard_limit_reached","message":"You exceeded your quota"}}`),
+	})
+	require.True(t, result.Matched)
+	require.NotNil(t, result.Rule)
+	assert.Equal(t, int64(7), result.Rule.ID)
+	assert.Equal(t, 503, result.ResponseCode)
+	assert.Equal(t, "额度已用尽", result.Message)
+	assert.True(t, result.SkipMonitoring)
+}
+
+func TestDryRun_PassthroughUsesUpstreamValues(t *testing.T) {
+	rules := []*model.ErrorPassthroughRule{
+		{ID: 1, Name: "passthrough", Enabled: true, ErrorCodes: []int{400}, MatchMode: model.MatchModeAny, PassthroughCode: true, PassthroughBody: true},
+	}
+	svc := newTestService(rules)
+
+	result := svc.DryRun(ErrorPassthroughDryRunInput{
+		Platform:   "anthropic",
+		StatusCode: 400,
+		Body:       []byte(`{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long"}}`),
+	})
+	require.True(t, result.Matched)
+	assert.Equal(t, 400, result.ResponseCode)
+	assert.Equal(t, "prompt is too long", result.Message)
+}
+
+func TestDryRun_DisabledRuleOnlyWhenRequested(t *testing.T) {
+	rules := []*model.ErrorPassthroughRule{
+		{ID: 1, Name: "draft", Enabled: false, ErrorCodes: []int{418}, MatchMode: model.MatchModeAny, PassthroughCode: true, PassthroughBody: true},
+	}
+	svc := newTestService(rules)
+	input := ErrorPassthroughDryRunInput{Platform: "gemini", StatusCode: 418, Body: []byte("teapot")}
+
+	assert.False(t, svc.DryRun(input).Matched)
+	assert.Nil(t, svc.MatchRule("gemini", 418, []byte("teapot")))
+
+	input.IncludeDisabled = true
+	result := svc.DryRun(input)
+	require.True(t, result.Matched)
+	assert.Equal(t, "draft", result.Rule.Name)
+}
+
+func TestDryRun_NoMatch(t *testing.T) {
+	svc := newTestService(nil)
+	result := svc.DryRun(ErrorPassthroughDryRunInput{Platform: "openai", StatusCode: 500, Body: []byte("boom")})
+	assert.False(t, result.Matched)
+	assert.Nil(t, result.Rule)
+	assert.Zero(t, result.ResponseCode)
+}
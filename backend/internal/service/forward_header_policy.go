@@ -0,0 +1,77 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+// forwardHeaderPolicy 客户端请求头透传策略：内置白名单 + 追加放行 - 强制剥离。
+type forwardHeaderPolicy struct {
+	additionalAllowed map[string]struct{}
+	forceRemove       map[string]struct{}
+}
+
+func compileForwardHeaderPolicy(cfg *config.Config) *forwardHeaderPolicy {
+	if cfg == nil {
+		return nil
+	}
+	toSet := func(values []string) map[string]struct{} {
+		out := make(map[string]struct{}, len(values))
+		for _, v := range values {
+			if normalized := strings.ToLower(strings.TrimSpace(v)); normalized != "" {
+				out[normalized] = struct{}{}
+			}
+		}
+		return out
+	}
+	policy := &forwardHeaderPolicy{
+		additionalAllowed: toSet(cfg.Gateway.ForwardHeaders.AdditionalAllowed),
+		forceRemove:       toSet(cfg.Gateway.ForwardHeaders.ForceRemove),
+	}
+	if len(policy.additionalAllowed) == 0 && len(policy.forceRemove) == 0 {
+		return nil
+	}
+	return policy
+}
+
+// allows 判断小写后的客户端请求头是否透传到 Anthropic 上游；nil 策略等价于内置白名单。
+func (p *forwardHeaderPolicy) allows(lowerKey string) bool {
+	return p.permits(lowerKey, allowedHeaders[lowerKey])
+}
+
+// permits 按策略判断请求头是否透传：强制剥离优先，其次为调用路径的内置白名单（builtin），最后为追加放行。
+func (p *forwardHeaderPolicy) permits(lowerKey string, builtin bool) bool {
+	if p != nil {
+		if _, removed := p.forceRemove[lowerKey]; removed {
+			return false
+		}
+	}
+	if builtin {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	_, ok := p.additionalAllowed[lowerKey]
+	return ok
+}
+
+// copyClientHeaders 按策略把客户端请求头追加到上游请求头，builtin 为调用路径的内置白名单。
+// 仅经追加放行的请求头不会追加到网关已写入的同名头上，保证账号/身份头始终优先。
+func (p *forwardHeaderPolicy) copyClientHeaders(dst, src http.Header, builtin func(lowerKey string) bool) {
+	for key, values := range src {
+		lowerKey := strings.ToLower(strings.TrimSpace(key))
+		inBuiltin := builtin(lowerKey)
+		if !p.permits(lowerKey, inBuiltin) {
+			continue
+		}
+		if !inBuiltin && len(dst.Values(key)) > 0 {
+			continue
+		}
+		for _, v := range values {
+			dst.Add(key, v)
+		}
+	}
+}
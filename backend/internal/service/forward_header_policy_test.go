@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newForwardHeaderTestService(forward config.GatewayForwardHeadersConfig, identity *IdentityService) *GatewayService {
+	cfg := &config.Config{Gateway: config.GatewayConfig{MaxLineSize: defaultMaxLineSize, ForwardHeaders: forward}}
+	return &GatewayService{
+		cfg:             cfg,
+		forwardHeaders:  compileForwardHeaderPolicy(cfg),
+		identityService: identity,
+	}
+}
+
+func newForwardHeaderTestContext() *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Request.Header.Set("X-Forwarded-For", "203.0.113.7")
+	c.Request.Header.Set("X-Trace-Id", "trace-123")
+	c.Request.Header.Set("Accept-Language", "zh-CN")
+	c.Request.Header.Set("User-Agent", "custom-client/1.0")
+	return c
+}
+
+func TestForwardHeaderPolicy_Allows(t *testing.T) {
+	var nilPolicy *forwardHeaderPolicy
+	require.True(t, nilPolicy.allows("anthropic-beta"))
+	require.False(t, nilPolicy.allows("x-trace-id"))
+	require.Nil(t, compileForwardHeaderPolicy(&config.Config{}), "未配置时使用内置白名单")
+
+	policy := compileForwardHeaderPolicy(&config.Config{Gateway: config.GatewayConfig{
+		ForwardHeaders: config.GatewayForwardHeadersConfig{
+			AdditionalAllowed: []string{" X-Trace-Id "},
+			ForceRemove:       []string{"Accept-Language", "X-Trace-Id-Secondary"},
+		},
+	}})
+	require.True(t, policy.allows("x-trace-id"))
+	require.False(t, policy.allows("accept-language"), "强制剥离优先于内置白名单")
+	require.True(t, policy.allows("anthropic-version"))
+	require.False(t, policy.allows("x-forwarded-for"))
+}
+
+func TestGatewayService_BuildUpstreamRequest_AppliesForwardHeaderPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newForwardHeaderTestService(config.GatewayForwardHeadersConfig{
+		AdditionalAllowed: []string{"X-Trace-Id"},
+		ForceRemove:       []string{"Accept-Language"},
+	}, nil)
+	account := &Account{ID: 1, Platform: PlatformAnthropic, Type: AccountTypeAPIKey}
+
+	req, _, err := svc.buildUpstreamRequest(context.Background(), newForwardHeaderTestContext(), account, []byte(`{"model":"claude-sonnet-4-5"}`), "sk-upstream", "apikey", "claude-sonnet-4-5", false, false)
+	require.NoError(t, err)
+	require.Equal(t, "trace-123", getHeaderRaw(req.Header, "X-Trace-Id"), "追加放行的请求头应透传")
+	require.Empty(t, getHeaderRaw(req.Header, "X-Forwarded-For"), "非白名单请求头不应透传")
+	require.Empty(t, getHeaderRaw(req.Header, "Accept-Language"), "强制剥离的请求头不应透传")
+	require.Equal(t, "custom-client/1.0", getHeaderRaw(req.Header, "User-Agent"))
+	require.Equal(t, "sk-upstream", getHeaderRaw(req.Header, "x-api-key"))
+}
+
+func TestGatewayService_BuildUpstreamRequest_IdentityHeadersWinOverForwardPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fingerprintUA := "claude-cli/2.1.9 (external, cli)"
+	identity := NewIdentityService(&fingerprintCacheStub{fingerprints: map[int64]Fingerprint{
+		7: {ClientID: strings.Repeat("cd", 32), UserAgent: fingerprintUA},
+	}})
+	// 即便强制剥离 User-Agent，身份指纹写入的 UA 仍然生效
+	svc := newForwardHeaderTestService(config.GatewayForwardHeadersConfig{
+		ForceRemove: []string{"User-Agent"},
+	}, identity)
+	account := &Account{ID: 7, Platform: PlatformAnthropic, Type: AccountTypeOAuth}
+
+	req, _, err := svc.buildUpstreamRequest(context.Background(), newForwardHeaderTestContext(), account, []byte(`{"model":"claude-sonnet-4-5"}`), "oauth-token", "oauth", "claude-sonnet-4-5", false, false)
+	require.NoError(t, err)
+	require.Equal(t, fingerprintUA, getHeaderRaw(req.Header, "User-Agent"))
+	require.Equal(t, "Bearer oauth-token", getHeaderRaw(req.Header, "authorization"))
+}
+
+func TestOpenAIGatewayService_BuildUpstreamRequest_AppliesForwardHeaderPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Gateway: config.GatewayConfig{ForwardHeaders: config.GatewayForwardHeadersConfig{
+		AdditionalAllowed: []string{"X-Trace-Id", "Chatgpt-Account-Id"},
+		ForceRemove:       []string{"Accept-Language"},
+	}}}
+	svc := &OpenAIGatewayService{cfg: cfg, forwardHeaders: compileForwardHeaderPolicy(cfg)}
+	account := &Account{
+		ID:          1,
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeOAuth,
+		Credentials: map[string]any{"chatgpt_account_id": "chatgpt-acc"},
+	}
+	c := newForwardHeaderTestContext()
+	c.Request.URL.Path = "/v1/responses"
+	c.Request.Header.Set("Chatgpt-Account-Id", "client-acc")
+
+	req, err := svc.buildUpstreamRequest(context.Background(), c, account, []byte(`{"model":"gpt-5"}`), "token", true, "", false)
+	require.NoError(t, err)
+	require.Equal(t, "trace-123", req.Header.Get("X-Trace-Id"), "追加放行的请求头应透传")
+	require.Empty(t, req.Header.Get("X-Forwarded-For"), "非白名单请求头不应透传")
+	require.Empty(t, req.Header.Get("Accept-Language"), "强制剥离的请求头不应透传")
+	require.Equal(t, []string{"chatgpt-acc"}, req.Header.Values("Chatgpt-Account-Id"), "账号头优先于追加放行的客户端头")
+	require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+}
+
+func TestForwardHeaderPolicy_CopyClientHeadersWithOpenAIWhitelist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := compileForwardHeaderPolicy(&config.Config{Gateway: config.GatewayConfig{ForwardHeaders: config.GatewayForwardHeadersConfig{
+		AdditionalAllowed: []string{"X-Trace-Id"},
+		ForceRemove:       []string{"User-Agent"},
+	}}})
+
+	header := http.Header{}
+	policy.copyClientHeaders(header, newForwardHeaderTestContext().Request.Header, isOpenAIAllowedRequestHeader)
+	require.Equal(t, "trace-123", header.Get("X-Trace-Id"))
+	require.Empty(t, header.Get("User-Agent"), "强制剥离优先于 OpenAI 内置白名单")
+	require.Equal(t, "zh-CN", header.Get("Accept-Language"))
+	require.Empty(t, header.Get("X-Forwarded-For"))
+}
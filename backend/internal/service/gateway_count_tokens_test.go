@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestGatewayService_ForwardCountTokens_NormalizesModelAndAppliesFingerprint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages/count_tokens", nil)
+	c.Request.Header.Set("User-Agent", "claude-cli/2.0.0 (external, cli)")
+
+	body := []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":[{"type":"text","text":"hello"}]}]}`)
+	parsed := &ParsedRequest{
+		Body:  NewRequestBodyRef(body),
+		Model: "claude-sonnet-4-5",
+	}
+
+	// 上游响应保留非常规字段与格式，用于验证原样返回
+	upstreamRespBody := `{"input_tokens": 42, "extra": {"kept": true}}`
+	upstream := &anthropicHTTPUpstreamRecorder{
+		resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(upstreamRespBody)),
+		},
+	}
+
+	fingerprintUA := "claude-cli/2.1.9 (external, cli)"
+	identityCache := &fingerprintCacheStub{fingerprints: map[int64]Fingerprint{
+		301: {ClientID: strings.Repeat("ab", 32), UserAgent: fingerprintUA, StainlessOS: "Linux"},
+	}}
+
+	cfg := &config.Config{Gateway: config.GatewayConfig{MaxLineSize: defaultMaxLineSize}}
+	svc := &GatewayService{
+		cfg:                  cfg,
+		responseHeaderFilter: compileResponseHeaderFilter(cfg),
+		httpUpstream:         upstream,
+		identityService:      NewIdentityService(identityCache),
+		rateLimitService:     &RateLimitService{},
+	}
+
+	account := &Account{
+		ID:          301,
+		Name:        "anthropic-setup-token-count",
+		Platform:    PlatformAnthropic,
+		Type:        AccountTypeSetupToken,
+		Concurrency: 1,
+		Credentials: map[string]any{"access_token": "upstream-oauth-token"},
+		Status:      StatusActive,
+		Schedulable: true,
+	}
+
+	// Claude Code 客户端不触发 mimic 头覆盖，便于验证指纹头
+	ctx := SetClaudeCodeClient(context.Background(), true)
+	err := svc.ForwardCountTokens(ctx, c, account, parsed)
+	require.NoError(t, err)
+
+	require.NotNil(t, upstream.lastReq)
+	require.Equal(t, "claude-sonnet-4-5-20250929", gjson.GetBytes(upstream.lastBody, "model").String(), "OAuth/SetupToken 账号应通过 NormalizeModelID 规范化模型")
+	require.True(t, strings.HasSuffix(upstream.lastReq.URL.Path, "/v1/messages/count_tokens"))
+	require.Equal(t, "Bearer upstream-oauth-token", getHeaderRaw(upstream.lastReq.Header, "authorization"))
+	require.Equal(t, fingerprintUA, getHeaderRaw(upstream.lastReq.Header, "User-Agent"), "应应用账号指纹")
+	require.Equal(t, "Linux", getHeaderRaw(upstream.lastReq.Header, "X-Stainless-OS"))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, upstreamRespBody, rec.Body.String(), "count_tokens 响应应原样返回")
+}
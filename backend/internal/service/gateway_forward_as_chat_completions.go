@@ -126,7 +126,9 @@ func (s *GatewayService) ForwardAsChatCompletions(
 	}
 
 	// 11. Send request
+	upstreamStart := time.Now()
 	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 	if err != nil {
 		if resp != nil && resp.Body != nil {
 			_ = resp.Body.Close()
@@ -145,6 +147,7 @@ func (s *GatewayService) ForwardAsChatCompletions(
 		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	exposeUpstreamRequestID(c, s.cfg, resp.Header, nil)
 
 	// 12. Handle error response with failover
 	if resp.StatusCode >= 400 {
@@ -169,8 +172,9 @@ func (s *GatewayService) ForwardAsChatCompletions(
 				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody, mappedModel)
 			}
 			return nil, &UpstreamFailoverError{
-				StatusCode:   resp.StatusCode,
-				ResponseBody: respBody,
+				StatusCode:        resp.StatusCode,
+				ResponseBody:      respBody,
+				UpstreamRequestID: exposeUpstreamRequestID(c, s.cfg, resp.Header, respBody),
 			}
 		}
 
@@ -194,6 +198,9 @@ func (s *GatewayService) ForwardAsChatCompletions(
 	} else {
 		result, handleErr = s.handleCCBufferedFromAnthropic(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
 	}
+	if result != nil {
+		result.UpstreamRateLimit = s.upstreamRateLimits.Observe(account, resp.Header)
+	}
 
 	return result, handleErr
 }
@@ -381,6 +388,7 @@ func (s *GatewayService) handleCCStreamingFromAnthropic(
 	var firstTokenMs *int
 	firstChunk := true
 
+	applyStreamIdleTimeout(s.cfg, resp)
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
@@ -471,6 +479,17 @@ func (s *GatewayService) handleCCStreamingFromAnthropic(
 	}
 
 	if err := scanner.Err(); err != nil {
+		// 上游空闲超时：以错误事件结束流，而不是伪装成正常完成；已收集的 usage 照常计费
+		if idleErr, ok := asStreamIdleTimeout(err); ok {
+			logger.L().Warn("forward_as_cc stream: upstream idle timeout",
+				zap.Duration("idle_timeout", idleErr.Timeout),
+				zap.String("request_id", requestID),
+			)
+			fmt.Fprint(c.Writer, buildChatStreamErrorSSE("stream_timeout", idleErr.Error())) //nolint:errcheck
+			fmt.Fprint(c.Writer, "data: [DONE]\n\n")                                         //nolint:errcheck
+			c.Writer.Flush()
+			return resultWithUsage(), nil
+		}
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			logger.L().Warn("forward_as_cc stream: read error",
 				zap.Error(err),
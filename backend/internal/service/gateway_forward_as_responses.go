@@ -125,7 +125,9 @@ func (s *GatewayService) ForwardAsResponses(
 	}
 
 	// 11. Send request
+	upstreamStart := time.Now()
 	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 	if err != nil {
 		if resp != nil && resp.Body != nil {
 			_ = resp.Body.Close()
@@ -144,6 +146,7 @@ func (s *GatewayService) ForwardAsResponses(
 		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	exposeUpstreamRequestID(c, s.cfg, resp.Header, nil)
 
 	// 12. Handle error response with failover
 	if resp.StatusCode >= 400 {
@@ -168,8 +171,9 @@ func (s *GatewayService) ForwardAsResponses(
 				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody, mappedModel)
 			}
 			return nil, &UpstreamFailoverError{
-				StatusCode:   resp.StatusCode,
-				ResponseBody: respBody,
+				StatusCode:        resp.StatusCode,
+				ResponseBody:      respBody,
+				UpstreamRequestID: exposeUpstreamRequestID(c, s.cfg, resp.Header, respBody),
 			}
 		}
 
@@ -186,6 +190,9 @@ func (s *GatewayService) ForwardAsResponses(
 	} else {
 		result, handleErr = s.handleResponsesBufferedStreamingResponse(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
 	}
+	if result != nil {
+		result.UpstreamRateLimit = s.upstreamRateLimits.Observe(account, resp.Header)
+	}
 
 	return result, handleErr
 }
@@ -390,6 +397,7 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 	var firstTokenMs *int
 	firstChunk := true
 
+	applyStreamIdleTimeout(s.cfg, resp)
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
@@ -501,6 +509,17 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 	}
 
 	if err := scanner.Err(); err != nil {
+		// 上游空闲超时：以 error 事件结束流，而不是补发完成事件；已收集的 usage 照常计费
+		if idleErr, ok := asStreamIdleTimeout(err); ok {
+			logger.L().Warn("forward_as_responses stream: upstream idle timeout",
+				zap.Duration("idle_timeout", idleErr.Timeout),
+				zap.String("request_id", requestID),
+			)
+			payload, _ := json.Marshal(gin.H{"type": "error", "code": "stream_timeout", "message": idleErr.Error()})
+			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", payload) //nolint:errcheck
+			c.Writer.Flush()
+			return resultWithUsage(), nil
+		}
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			logger.L().Warn("forward_as_responses stream: read error",
 				zap.Error(err),
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	// IdempotencyKeyHeader 客户端请求幂等键
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyReplayedHeader 标记响应为重放结果（与管理接口幂等重放保持一致）
+	IdempotencyReplayedHeader = "X-Idempotency-Replayed"
+
+	gatewayIdempotencyStateProcessing = "processing"
+	gatewayIdempotencyStateCompleted  = "completed"
+
+	gatewayIdempotencyPollInterval = 200 * time.Millisecond
+)
+
+var (
+	ErrGatewayIdempotencyConflict   = infraerrors.Conflict("IDEMPOTENCY_KEY_CONFLICT", "Idempotency-Key was already used with a different request body")
+	ErrGatewayIdempotencyInProgress = infraerrors.Conflict("IDEMPOTENCY_IN_PROGRESS", "A request with this Idempotency-Key is still in progress; retry later")
+	ErrGatewayIdempotencyStreaming  = infraerrors.BadRequest("IDEMPOTENCY_STREAM_UNSUPPORTED", "Idempotency-Key is not supported for streaming requests; send stream=false or drop the Idempotency-Key header")
+)
+
+// gatewayIdempotencyReplayHeaders 重放时回写的响应头子集
+var gatewayIdempotencyReplayHeaders = []string{"Content-Type", "X-Request-Id", "Openai-Processing-Ms"}
+
+// GatewayIdempotencyCache 网关幂等记录存储（Redis）。
+// 缺失时 GetIdempotencyRecord 返回 nil, nil。
+type GatewayIdempotencyCache interface {
+	CreateIdempotencyRecord(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	GetIdempotencyRecord(ctx context.Context, key string) ([]byte, error)
+	SetIdempotencyRecord(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	DeleteIdempotencyRecord(ctx context.Context, key string) error
+}
+
+// GatewayIdempotentResponse 已保存的最终响应
+type GatewayIdempotentResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    []byte            `json:"body"`
+}
+
+type gatewayIdempotencyRecord struct {
+	Fingerprint string                     `json:"fingerprint"`
+	State       string                     `json:"state"`
+	Response    *GatewayIdempotentResponse `json:"response,omitempty"`
+}
+
+// GatewayIdempotencyService 网关请求 Idempotency-Key 支持。
+// 存储故障时 fail-open：按无幂等键处理，保证不影响正常转发。
+type GatewayIdempotencyService struct {
+	cache        GatewayIdempotencyCache
+	cfg          config.GatewayIdempotencyConfig
+	pollInterval time.Duration
+}
+
+// NewGatewayIdempotencyService 创建网关幂等服务
+func NewGatewayIdempotencyService(cache GatewayIdempotencyCache, cfg *config.Config) *GatewayIdempotencyService {
+	svc := &GatewayIdempotencyService{cache: cache, pollInterval: gatewayIdempotencyPollInterval}
+	if cfg != nil {
+		svc.cfg = cfg.Gateway.Idempotency
+	}
+	return svc
+}
+
+// Enabled 是否启用网关幂等支持
+func (s *GatewayIdempotencyService) Enabled() bool {
+	return s != nil && s.cache != nil && s.cfg.Enabled
+}
+
+// GatewayIdempotencyClaim 首个请求持有的幂等键占用；调用方必须 Complete 或 Abort。
+type GatewayIdempotencyClaim struct {
+	svc         *GatewayIdempotencyService
+	cacheKey    string
+	fingerprint string
+}
+
+func gatewayIdempotencyCacheKey(scope string, apiKeyID int64, key string) string {
+	return scope + ":" + strconv.FormatInt(apiKeyID, 10) + ":" + HashIdempotencyKey(key)
+}
+
+func gatewayIdempotencyFingerprint(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Begin 处理带 Idempotency-Key 的请求：
+//   - 首个请求：返回 claim，调用方转发后 Complete/Abort；
+//   - 已完成的相同请求：返回可重放的响应；
+//   - 相同 Key 不同请求体：ErrGatewayIdempotencyConflict；
+//   - 原请求仍在进行：等待至多 wait_timeout_seconds，仍未完成返回 ErrGatewayIdempotencyInProgress。
+//
+// rawKey 为空、服务未启用或存储故障时三者均返回 nil，按普通请求处理。
+func (s *GatewayIdempotencyService) Begin(ctx context.Context, scope string, apiKeyID int64, rawKey string, body []byte) (*GatewayIdempotencyClaim, *GatewayIdempotentResponse, error) {
+	if !s.Enabled() {
+		return nil, nil, nil
+	}
+	key, err := NormalizeIdempotencyKey(rawKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == "" {
+		return nil, nil, nil
+	}
+
+	cacheKey := gatewayIdempotencyCacheKey(scope, apiKeyID, key)
+	fingerprint := gatewayIdempotencyFingerprint(body)
+	processing, err := json.Marshal(gatewayIdempotencyRecord{Fingerprint: fingerprint, State: gatewayIdempotencyStateProcessing})
+	if err != nil {
+		return nil, nil, nil
+	}
+	inFlightTTL := time.Duration(s.cfg.InFlightTimeoutSeconds) * time.Second
+	deadline := time.Now().Add(time.Duration(s.cfg.WaitTimeoutSeconds) * time.Second)
+
+	for {
+		created, err := s.cache.CreateIdempotencyRecord(ctx, cacheKey, processing, inFlightTTL)
+		if err != nil {
+			slog.Warn("gateway_idempotency_store_unavailable", "scope", scope, "api_key_id", apiKeyID, "error", err)
+			return nil, nil, nil
+		}
+		if created {
+			return &GatewayIdempotencyClaim{svc: s, cacheKey: cacheKey, fingerprint: fingerprint}, nil, nil
+		}
+
+		raw, err := s.cache.GetIdempotencyRecord(ctx, cacheKey)
+		if err != nil {
+			slog.Warn("gateway_idempotency_store_unavailable", "scope", scope, "api_key_id", apiKeyID, "error", err)
+			return nil, nil, nil
+		}
+		if raw != nil {
+			var record gatewayIdempotencyRecord
+			if err := json.Unmarshal(raw, &record); err != nil {
+				slog.Warn("gateway_idempotency_record_corrupted", "scope", scope, "api_key_id", apiKeyID, "error", err)
+				return nil, nil, nil
+			}
+			if record.Fingerprint != fingerprint {
+				return nil, nil, ErrGatewayIdempotencyConflict
+			}
+			if record.State == gatewayIdempotencyStateCompleted && record.Response != nil {
+				return nil, record.Response, nil
+			}
+		}
+		// 记录刚被删除（原请求失败）时立即重试抢占；仍在进行时等待
+		if raw != nil {
+			if !time.Now().Before(deadline) {
+				return nil, nil, ErrGatewayIdempotencyInProgress
+			}
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(s.pollInterval):
+			}
+		}
+	}
+}
+
+// Complete 保存最终响应供重放；仅保存 2xx 且不超过 max_body_bytes 的响应，否则释放占用让重试重新转发。
+func (c *GatewayIdempotencyClaim) Complete(ctx context.Context, status int, header http.Header, body []byte) {
+	if c == nil {
+		return
+	}
+	if status < 200 || status >= 300 || len(body) > c.svc.cfg.MaxBodyBytes {
+		c.Abort(ctx)
+		return
+	}
+	resp := &GatewayIdempotentResponse{Status: status, Body: body}
+	for _, name := range gatewayIdempotencyReplayHeaders {
+		if v := header.Get(name); v != "" {
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]string, len(gatewayIdempotencyReplayHeaders))
+			}
+			resp.Headers[name] = v
+		}
+	}
+	raw, err := json.Marshal(gatewayIdempotencyRecord{Fingerprint: c.fingerprint, State: gatewayIdempotencyStateCompleted, Response: resp})
+	if err != nil {
+		c.Abort(ctx)
+		return
+	}
+	window := time.Duration(c.svc.cfg.WindowSeconds) * time.Second
+	if err := c.svc.cache.SetIdempotencyRecord(ctx, c.cacheKey, raw, window); err != nil {
+		slog.Warn("gateway_idempotency_store_failed", "error", err)
+	}
+}
+
+// Abort 释放占用（原请求失败或响应不可保存），等待中的重复请求将重新转发。
+func (c *GatewayIdempotencyClaim) Abort(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	if err := c.svc.cache.DeleteIdempotencyRecord(ctx, c.cacheKey); err != nil {
+		slog.Warn("gateway_idempotency_release_failed", "error", err)
+	}
+}
@@ -0,0 +1,170 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryGatewayIdempotencyCache struct {
+	mu      sync.Mutex
+	records map[string][]byte
+}
+
+func newMemoryGatewayIdempotencyCache() *memoryGatewayIdempotencyCache {
+	return &memoryGatewayIdempotencyCache{records: make(map[string][]byte)}
+}
+
+func (c *memoryGatewayIdempotencyCache) CreateIdempotencyRecord(_ context.Context, key string, value []byte, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.records[key]; ok {
+		return false, nil
+	}
+	c.records[key] = value
+	return true, nil
+}
+
+func (c *memoryGatewayIdempotencyCache) GetIdempotencyRecord(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.records[key], nil
+}
+
+func (c *memoryGatewayIdempotencyCache) SetIdempotencyRecord(_ context.Context, key string, value []byte, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records[key] = value
+	return nil
+}
+
+func (c *memoryGatewayIdempotencyCache) DeleteIdempotencyRecord(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.records, key)
+	return nil
+}
+
+func newGatewayIdempotencyTestService(waitSeconds int) *GatewayIdempotencyService {
+	cfg := &config.Config{}
+	cfg.Gateway.Idempotency = config.GatewayIdempotencyConfig{
+		Enabled:                true,
+		WindowSeconds:          3600,
+		MaxBodyBytes:           1024,
+		InFlightTimeoutSeconds: 60,
+		WaitTimeoutSeconds:     waitSeconds,
+	}
+	svc := NewGatewayIdempotencyService(newMemoryGatewayIdempotencyCache(), cfg)
+	svc.pollInterval = 5 * time.Millisecond
+	return svc
+}
+
+func TestGatewayIdempotency_ReplaysCompletedResponse(t *testing.T) {
+	svc := newGatewayIdempotencyTestService(0)
+	ctx := context.Background()
+	body := []byte(`{"model":"gpt-5","input":"hi"}`)
+
+	claim, replay, err := svc.Begin(ctx, "scope", 1, "key-1", body)
+	require.NoError(t, err)
+	require.Nil(t, replay)
+	require.NotNil(t, claim)
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Set-Cookie", "secret")
+	claim.Complete(ctx, http.StatusOK, header, []byte(`{"id":"resp_1"}`))
+
+	claim, replay, err = svc.Begin(ctx, "scope", 1, "key-1", body)
+	require.NoError(t, err)
+	require.Nil(t, claim)
+	require.NotNil(t, replay)
+	require.Equal(t, http.StatusOK, replay.Status)
+	require.Equal(t, `{"id":"resp_1"}`, string(replay.Body))
+	require.Equal(t, map[string]string{"Content-Type": "application/json"}, replay.Headers)
+
+	// 不同 API Key 之间互不影响
+	claim, replay, err = svc.Begin(ctx, "scope", 2, "key-1", body)
+	require.NoError(t, err)
+	require.Nil(t, replay)
+	require.NotNil(t, claim)
+}
+
+func TestGatewayIdempotency_DifferentBodyConflicts(t *testing.T) {
+	svc := newGatewayIdempotencyTestService(0)
+	ctx := context.Background()
+
+	claim, _, err := svc.Begin(ctx, "scope", 1, "key-1", []byte(`{"input":"a"}`))
+	require.NoError(t, err)
+	claim.Complete(ctx, http.StatusOK, http.Header{}, []byte(`{}`))
+
+	_, _, err = svc.Begin(ctx, "scope", 1, "key-1", []byte(`{"input":"b"}`))
+	require.ErrorIs(t, err, ErrGatewayIdempotencyConflict)
+}
+
+func TestGatewayIdempotency_FailedResponseReleasesKey(t *testing.T) {
+	svc := newGatewayIdempotencyTestService(0)
+	ctx := context.Background()
+	body := []byte(`{"input":"a"}`)
+
+	claim, _, err := svc.Begin(ctx, "scope", 1, "key-1", body)
+	require.NoError(t, err)
+	claim.Complete(ctx, http.StatusBadGateway, http.Header{}, []byte(`{"error":{}}`))
+
+	claim, replay, err := svc.Begin(ctx, "scope", 1, "key-1", body)
+	require.NoError(t, err)
+	require.Nil(t, replay)
+	require.NotNil(t, claim, "non-2xx responses are not stored, the retry is forwarded again")
+}
+
+func TestGatewayIdempotency_ConcurrentDuplicateWaitsForResult(t *testing.T) {
+	svc := newGatewayIdempotencyTestService(5)
+	ctx := context.Background()
+	body := []byte(`{"input":"a"}`)
+
+	claim, _, err := svc.Begin(ctx, "scope", 1, "key-1", body)
+	require.NoError(t, err)
+	require.NotNil(t, claim)
+
+	type result struct {
+		claim  *GatewayIdempotencyClaim
+		replay *GatewayIdempotentResponse
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		c, r, e := svc.Begin(ctx, "scope", 1, "key-1", body)
+		done <- result{claim: c, replay: r, err: e}
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	claim.Complete(ctx, http.StatusOK, http.Header{}, []byte(`{"id":"resp_1"}`))
+
+	select {
+	case res := <-done:
+		require.NoError(t, res.err)
+		require.Nil(t, res.claim)
+		require.NotNil(t, res.replay)
+		require.Equal(t, `{"id":"resp_1"}`, string(res.replay.Body))
+	case <-time.After(2 * time.Second):
+		t.Fatal("duplicate request did not observe the completed response")
+	}
+}
+
+func TestGatewayIdempotency_ConcurrentDuplicateTimesOut(t *testing.T) {
+	svc := newGatewayIdempotencyTestService(0)
+	ctx := context.Background()
+	body := []byte(`{"input":"a"}`)
+
+	_, _, err := svc.Begin(ctx, "scope", 1, "key-1", body)
+	require.NoError(t, err)
+
+	_, _, err = svc.Begin(ctx, "scope", 1, "key-1", body)
+	require.ErrorIs(t, err, ErrGatewayIdempotencyInProgress)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"strings"
 )
 
@@ -18,6 +19,10 @@ type ModelAvailabilityDiagnosis struct {
 	// HasModelSupport is true if at least one account's model mapping admits
 	// the requested model.
 	HasModelSupport bool
+	// NoLabelMatch is true if the pool has schedulable accounts but none of
+	// them carries every label required by the request's account label
+	// selector (see WithAccountLabelSelector).
+	NoLabelMatch bool
 }
 
 // ModelAvailabilityDiagnoser is implemented by gateway services that can
@@ -70,6 +75,10 @@ func (s *GatewayService) DiagnoseModelAvailabilityForPlatform(
 		// hiccup'd).
 		return ModelAvailabilityDiagnosis{HasAccountsInPool: true, HasModelSupport: true}
 	}
+	accounts, err = filterAccountsByLabelSelector(ctx, accounts)
+	if errors.Is(err, ErrNoAccountsMatchingLabels) {
+		return ModelAvailabilityDiagnosis{HasAccountsInPool: true, HasModelSupport: true, NoLabelMatch: true}
+	}
 
 	diag := ModelAvailabilityDiagnosis{}
 	for i := range accounts {
@@ -5,6 +5,7 @@ package service
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 	"time"
 
@@ -2097,6 +2098,14 @@ func (m *mockConcurrencyCache) CleanupStaleProcessSlots(ctx context.Context, act
 	return nil
 }
 
+func (m *mockConcurrencyCache) HeartbeatInstance(ctx context.Context, instanceID string, ttl time.Duration) error {
+	return nil
+}
+
+func (m *mockConcurrencyCache) ReconcileStaleSlots(ctx context.Context, activeRequestPrefix string) (int, error) {
+	return 0, nil
+}
+
 func (m *mockConcurrencyCache) GetUsersLoadBatch(ctx context.Context, users []UserWithConcurrency) (map[int64]*UserLoadInfo, error) {
 	result := make(map[int64]*UserLoadInfo, len(users))
 	for _, user := range users {
@@ -3210,6 +3219,142 @@ func TestGatewayService_SelectAccountWithLoadAwareness(t *testing.T) {
 		require.NotNil(t, result.Account)
 		require.Equal(t, int64(2), result.Account.ID)
 	})
+
+	// 优先级分层：高优先级账号未满载时始终优先，满载（负载率 >= 100 或槽位获取失败）后才溢出到低优先级，
+	// 同一优先级内按负载率择优
+	newPriorityTierService := func(concurrencyCache *mockConcurrencyCache, cache *mockGatewayCacheForPlatform) *GatewayService {
+		repo := &mockAccountRepoForPlatform{
+			accounts: []Account{
+				{ID: 1, Platform: PlatformAnthropic, Priority: 1, Status: StatusActive, Schedulable: true, Concurrency: 5},
+				{ID: 2, Platform: PlatformAnthropic, Priority: 2, Status: StatusActive, Schedulable: true, Concurrency: 5},
+				{ID: 3, Platform: PlatformAnthropic, Priority: 2, Status: StatusActive, Schedulable: true, Concurrency: 5},
+			},
+			accountsByID: map[int64]*Account{},
+		}
+		for i := range repo.accounts {
+			repo.accountsByID[repo.accounts[i].ID] = &repo.accounts[i]
+		}
+		cfg := testConfig()
+		cfg.Gateway.Scheduling.LoadBatchEnabled = true
+		return &GatewayService{
+			accountRepo:        repo,
+			cache:              cache,
+			cfg:                cfg,
+			concurrencyService: NewConcurrencyService(concurrencyCache),
+		}
+	}
+
+	t.Run("优先级分层-高优先级未满载时不溢出", func(t *testing.T) {
+		svc := newPriorityTierService(&mockConcurrencyCache{
+			loadMap: map[int64]*AccountLoadInfo{
+				1: {AccountID: 1, LoadRate: 90},
+				2: {AccountID: 2, LoadRate: 0},
+				3: {AccountID: 3, LoadRate: 0},
+			},
+		}, &mockGatewayCacheForPlatform{})
+
+		result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", nil, "", int64(0))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, result.Acquired)
+		require.Equal(t, int64(1), result.Account.ID, "高优先级账号未满载时应优先选择，即使负载更高")
+	})
+
+	t.Run("优先级分层-高优先级满载溢出到低优先级并按负载择优", func(t *testing.T) {
+		svc := newPriorityTierService(&mockConcurrencyCache{
+			loadMap: map[int64]*AccountLoadInfo{
+				1: {AccountID: 1, LoadRate: 100},
+				2: {AccountID: 2, LoadRate: 60},
+				3: {AccountID: 3, LoadRate: 10},
+			},
+		}, &mockGatewayCacheForPlatform{})
+
+		result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", nil, "", int64(0))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, result.Acquired)
+		require.Equal(t, int64(3), result.Account.ID, "应溢出到下一优先级中负载最低的账号")
+	})
+
+	t.Run("上游限流余量不足的账号在同优先级内降权", func(t *testing.T) {
+		svc := newPriorityTierService(&mockConcurrencyCache{
+			loadMap: map[int64]*AccountLoadInfo{
+				1: {AccountID: 1, LoadRate: 100},
+				2: {AccountID: 2, LoadRate: 60},
+				3: {AccountID: 3, LoadRate: 10},
+			},
+		}, &mockGatewayCacheForPlatform{})
+		tracker := newUpstreamRateLimitTestTracker(nil, nil)
+		svc.SetUpstreamRateLimitTracker(tracker)
+		low := http.Header{}
+		low.Set("anthropic-ratelimit-tokens-remaining", "500")
+		low.Set("anthropic-ratelimit-tokens-reset", time.Now().Add(time.Minute).UTC().Format(time.RFC3339))
+		tracker.Observe(&Account{ID: 3, Platform: PlatformAnthropic}, low)
+
+		result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", nil, "", int64(0))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, int64(2), result.Account.ID, "负载更低但余量不足的账号应让位于同优先级其他账号")
+
+		tracker.Observe(&Account{ID: 2, Platform: PlatformAnthropic}, low)
+		result, err = svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", nil, "", int64(0))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, int64(3), result.Account.ID, "同优先级全部余量不足时按原有规则选择")
+	})
+
+	t.Run("优先级分层-高优先级槽位获取失败溢出", func(t *testing.T) {
+		concurrencyCache := &mockConcurrencyCache{
+			acquireResults: map[int64]bool{1: false},
+			loadMap: map[int64]*AccountLoadInfo{
+				1: {AccountID: 1, LoadRate: 0},
+				2: {AccountID: 2, LoadRate: 30},
+				3: {AccountID: 3, LoadRate: 50},
+			},
+		}
+		svc := newPriorityTierService(concurrencyCache, &mockGatewayCacheForPlatform{})
+
+		result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", nil, "", int64(0))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, result.Acquired)
+		require.Equal(t, int64(2), result.Account.ID)
+		require.Equal(t, 2, concurrencyCache.acquireAccountCalls, "先尝试高优先级账号，失败后再尝试下一优先级")
+	})
+
+	t.Run("优先级分层-排除高优先级账号后直接使用低优先级", func(t *testing.T) {
+		svc := newPriorityTierService(&mockConcurrencyCache{
+			loadMap: map[int64]*AccountLoadInfo{
+				1: {AccountID: 1, LoadRate: 0},
+				2: {AccountID: 2, LoadRate: 40},
+				3: {AccountID: 3, LoadRate: 20},
+			},
+		}, &mockGatewayCacheForPlatform{})
+
+		excludedIDs := map[int64]struct{}{1: {}, 3: {}}
+		result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", excludedIDs, "", int64(0))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, int64(2), result.Account.ID, "被排除的账号不参与优先级分层")
+	})
+
+	t.Run("优先级分层-粘性会话优先于优先级", func(t *testing.T) {
+		cache := &mockGatewayCacheForPlatform{
+			sessionBindings: map[string]int64{"sticky-low": 2},
+		}
+		svc := newPriorityTierService(&mockConcurrencyCache{
+			loadMap: map[int64]*AccountLoadInfo{
+				1: {AccountID: 1, LoadRate: 0},
+				2: {AccountID: 2, LoadRate: 50},
+				3: {AccountID: 3, LoadRate: 0},
+			},
+		}, cache)
+
+		result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "sticky-low", "claude-3-5-sonnet-20241022", nil, "", int64(0))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, int64(2), result.Account.ID, "已绑定的粘性会话不应因存在更高优先级账号而迁移")
+	})
 }
 
 func TestGatewayService_GroupResolution_ReusesContextGroup(t *testing.T) {
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// promptCacheInjectMinTokens 自动注入断点的最小前缀 token 估算值。
+// Anthropic 对 Sonnet/Opus 的最小可缓存长度为 1024 tokens，低于该值打断点不会命中缓存，
+// 反而让上游多做一次无效的缓存写入判断，因此小请求保持原样。
+const promptCacheInjectMinTokens = 1024
+
+// promptCacheInjectEphemeral 注入的 cache_control（不带 ttl，使用上游默认 5 分钟）
+const promptCacheInjectEphemeral = `{"type":"ephemeral"}`
+
+// injectPromptCacheIfEnabled 按分组 prompt_cache_inject 开关，为未携带任何 cache_control 的
+// anthropic 请求自动注入 prompt caching 断点。客户端自己管理缓存时（任意位置存在 cache_control）不做改动。
+// 第二个返回值表示 body 是否被改写。
+func (s *GatewayService) injectPromptCacheIfEnabled(ctx context.Context, account *Account, body []byte) ([]byte, bool) {
+	if account == nil || account.Platform != PlatformAnthropic || ctx == nil {
+		return body, false
+	}
+	group, ok := ctx.Value(ctxkey.Group).(*Group)
+	if !ok || !IsGroupContextValid(group) || !group.PromptCacheInject {
+		return body, false
+	}
+	return injectPromptCacheBreakpoints(body)
+}
+
+// injectPromptCacheBreakpoints 在 system 最后一个 block 与 tools[-1] 上注入 ephemeral 断点。
+//
+// 仅当请求中完全没有 cache_control 时生效；system 为 string 时先升级成单块 text 数组。
+// system + tools 的估算 token 数低于 promptCacheInjectMinTokens 时原样返回。
+func injectPromptCacheBreakpoints(body []byte) ([]byte, bool) {
+	if requestHasCacheControl(body) {
+		return body, false
+	}
+	system := gjson.GetBytes(body, "system")
+	tools := gjson.GetBytes(body, "tools")
+	if estimatePromptCachePrefixTokens(system, tools) < promptCacheInjectMinTokens {
+		return body, false
+	}
+
+	changed := false
+
+	switch {
+	case system.Type == gjson.String && strings.TrimSpace(system.String()) != "":
+		blockRaw := fmt.Sprintf(`[{"type":"text","text":%s,"cache_control":%s}]`, system.Raw, promptCacheInjectEphemeral)
+		if next, err := sjson.SetRawBytes(body, "system", []byte(blockRaw)); err == nil {
+			body, changed = next, true
+		}
+	case system.IsArray():
+		if n := len(system.Array()); n > 0 {
+			if next, err := sjson.SetRawBytes(body, fmt.Sprintf("system.%d.cache_control", n-1), []byte(promptCacheInjectEphemeral)); err == nil {
+				body, changed = next, true
+			}
+		}
+	}
+
+	if tools.IsArray() {
+		if n := len(tools.Array()); n > 0 {
+			if next, err := sjson.SetRawBytes(body, fmt.Sprintf("tools.%d.cache_control", n-1), []byte(promptCacheInjectEphemeral)); err == nil {
+				body, changed = next, true
+			}
+		}
+	}
+	return body, changed
+}
+
+// requestHasCacheControl 检查 system / tools / messages[*].content 任意 block 是否已带 cache_control
+func requestHasCacheControl(body []byte) bool {
+	hasCC := func(arr gjson.Result) bool {
+		if !arr.IsArray() {
+			return false
+		}
+		found := false
+		arr.ForEach(func(_, item gjson.Result) bool {
+			found = item.Get("cache_control").Exists()
+			return !found
+		})
+		return found
+	}
+
+	if hasCC(gjson.GetBytes(body, "system")) || hasCC(gjson.GetBytes(body, "tools")) {
+		return true
+	}
+	found := false
+	gjson.GetBytes(body, "messages").ForEach(func(_, msg gjson.Result) bool {
+		found = hasCC(msg.Get("content"))
+		return !found
+	})
+	return found
+}
+
+// estimatePromptCachePrefixTokens 粗略估算可缓存前缀（tools + system）的 token 数
+func estimatePromptCachePrefixTokens(system, tools gjson.Result) int {
+	total := 0
+	switch {
+	case system.Type == gjson.String:
+		total += estimateTokensForText(system.String())
+	case system.IsArray():
+		system.ForEach(func(_, block gjson.Result) bool {
+			total += estimateTokensForText(block.Get("text").String())
+			return true
+		})
+	}
+	if tools.IsArray() {
+		total += estimateTokensForText(tools.Raw)
+	}
+	return total
+}
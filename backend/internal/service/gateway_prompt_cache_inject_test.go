@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+// longPromptCacheText 约 1500 tokens（按 4 字符/token 估算），超过注入门槛
+var longPromptCacheText = strings.Repeat("You are a helpful assistant. ", 210)
+
+func TestInjectPromptCacheBreakpoints_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "string system is upgraded to a text block",
+			in:   `{"model":"claude-sonnet-4-5","system":"` + longPromptCacheText + `","messages":[{"role":"user","content":"hi"}]}`,
+			want: `{"model":"claude-sonnet-4-5","system":[{"type":"text","text":"` + longPromptCacheText + `","cache_control":{"type":"ephemeral"}}],"messages":[{"role":"user","content":"hi"}]}`,
+		},
+		{
+			name: "array system marks the last block",
+			in:   `{"system":[{"type":"text","text":"head"},{"type":"text","text":"` + longPromptCacheText + `"}],"messages":[{"role":"user","content":"hi"}]}`,
+			want: `{"system":[{"type":"text","text":"head"},{"type":"text","text":"` + longPromptCacheText + `","cache_control":{"type":"ephemeral"}}],"messages":[{"role":"user","content":"hi"}]}`,
+		},
+		{
+			name: "system and last tool",
+			in:   `{"system":"` + longPromptCacheText + `","tools":[{"name":"a","input_schema":{}},{"name":"b","input_schema":{}}],"messages":[]}`,
+			want: `{"system":[{"type":"text","text":"` + longPromptCacheText + `","cache_control":{"type":"ephemeral"}}],"tools":[{"name":"a","input_schema":{}},{"name":"b","input_schema":{},"cache_control":{"type":"ephemeral"}}],"messages":[]}`,
+		},
+		{
+			name: "tools only",
+			in:   `{"tools":[{"name":"a","description":"` + longPromptCacheText + `","input_schema":{}}],"messages":[]}`,
+			want: `{"tools":[{"name":"a","description":"` + longPromptCacheText + `","input_schema":{},"cache_control":{"type":"ephemeral"}}],"messages":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := injectPromptCacheBreakpoints([]byte(tt.in))
+			require.True(t, changed)
+			require.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestInjectPromptCacheBreakpoints_NoOp(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{
+			name: "client already sets cache_control on a message",
+			in:   `{"system":"` + longPromptCacheText + `","messages":[{"role":"user","content":[{"type":"text","text":"hi","cache_control":{"type":"ephemeral"}}]}]}`,
+		},
+		{
+			name: "client already sets cache_control on system",
+			in:   `{"system":[{"type":"text","text":"` + longPromptCacheText + `","cache_control":{"type":"ephemeral"}}],"messages":[]}`,
+		},
+		{
+			name: "client already sets cache_control on a tool",
+			in:   `{"system":"` + longPromptCacheText + `","tools":[{"name":"a","input_schema":{},"cache_control":{"type":"ephemeral"}}],"messages":[]}`,
+		},
+		{
+			name: "prompt below minimum token threshold",
+			in:   `{"system":"short prompt","tools":[{"name":"a","input_schema":{}}],"messages":[{"role":"user","content":"hi"}]}`,
+		},
+		{
+			name: "no system and no tools",
+			in:   `{"messages":[{"role":"user","content":"` + longPromptCacheText + `"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := injectPromptCacheBreakpoints([]byte(tt.in))
+			require.False(t, changed)
+			require.Equal(t, tt.in, string(got))
+		})
+	}
+}
+
+func TestInjectPromptCacheIfEnabled_RequiresGroupFlag(t *testing.T) {
+	svc := &GatewayService{}
+	account := &Account{Platform: PlatformAnthropic}
+	body := []byte(`{"system":"` + longPromptCacheText + `","messages":[]}`)
+	group := &Group{ID: 1, Platform: PlatformAnthropic, Status: StatusActive, Hydrated: true}
+
+	_, changed := svc.injectPromptCacheIfEnabled(context.Background(), account, body)
+	require.False(t, changed, "no group in context")
+
+	ctx := context.WithValue(context.Background(), ctxkey.Group, group)
+	_, changed = svc.injectPromptCacheIfEnabled(ctx, account, body)
+	require.False(t, changed, "group flag disabled")
+
+	group.PromptCacheInject = true
+	_, changed = svc.injectPromptCacheIfEnabled(ctx, &Account{Platform: PlatformGemini}, body)
+	require.False(t, changed, "non-anthropic account")
+
+	_, changed = svc.injectPromptCacheIfEnabled(ctx, account, body)
+	require.True(t, changed)
+}
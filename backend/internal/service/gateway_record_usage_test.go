@@ -491,3 +491,108 @@ func TestGatewayServiceRecordUsage_ReasoningEffortNil(t *testing.T) {
 	require.NotNil(t, usageRepo.lastLog)
 	require.Nil(t, usageRepo.lastLog.ReasoningEffort)
 }
+
+func TestGatewayServiceRecordUsage_ConfiguredPriceIncludesGeminiCacheRead(t *testing.T) {
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc := newGatewayRecordUsageServiceForTest(usageRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	cfg := &config.Config{}
+	cfg.Pricing.ModelPrices = []config.ModelPriceConfig{
+		{Model: "gemini-custom-flash", InputPer1K: 0.001, OutputPer1K: 0.002, CacheReadPer1K: 0.0001},
+	}
+	svc.billingService = NewBillingService(cfg, nil)
+
+	// extractGeminiUsage 输出的 usage：promptTokenCount 已扣除 cachedContentTokenCount
+	usage := extractGeminiUsage([]byte(`{"usageMetadata":{"promptTokenCount":3000,"candidatesTokenCount":500,"cachedContentTokenCount":2000}}`))
+	require.NotNil(t, usage)
+
+	err := svc.RecordUsage(context.Background(), &RecordUsageInput{
+		Result: &ForwardResult{
+			RequestID: "gemini_configured_price",
+			Usage:     *usage,
+			Model:     "gemini-custom-flash",
+			Duration:  time.Second,
+		},
+		APIKey:  &APIKey{ID: 502},
+		User:    &User{ID: 602},
+		Account: &Account{ID: 702},
+	})
+	require.NoError(t, err)
+
+	log := usageRepo.lastLog
+	require.NotNil(t, log)
+	require.False(t, log.PricingUnavailable)
+	require.Equal(t, 1000, log.InputTokens)
+	require.Equal(t, 500, log.OutputTokens)
+	require.Equal(t, 2000, log.CacheReadTokens)
+	require.InDelta(t, 0.001, log.InputCost, 1e-10)
+	require.InDelta(t, 0.001, log.OutputCost, 1e-10)
+	require.InDelta(t, 0.0002, log.CacheReadCost, 1e-10)
+	require.InDelta(t, 0.0022, log.TotalCost, 1e-10)
+	// 未绑定分组时按默认倍率 1.1 计算实际费用
+	require.InDelta(t, 0.0022*1.1, log.ActualCost, 1e-10)
+}
+
+func TestGatewayServiceRecordUsage_UnpricedModelRecordsTokensWithoutCost(t *testing.T) {
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc := newGatewayRecordUsageServiceForTest(usageRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+
+	err := svc.RecordUsage(context.Background(), &RecordUsageInput{
+		Result: &ForwardResult{
+			RequestID: "gateway_unpriced_model",
+			Usage: ClaudeUsage{
+				InputTokens:          800,
+				OutputTokens:         200,
+				CacheReadInputTokens: 100,
+			},
+			Model:    "pricing-missing-test-model",
+			Duration: time.Second,
+		},
+		APIKey:  &APIKey{ID: 503},
+		User:    &User{ID: 603},
+		Account: &Account{ID: 703},
+	})
+	require.NoError(t, err)
+
+	log := usageRepo.lastLog
+	require.NotNil(t, log)
+	require.True(t, log.PricingUnavailable)
+	require.Equal(t, 800, log.InputTokens)
+	require.Equal(t, 200, log.OutputTokens)
+	require.Equal(t, 100, log.CacheReadTokens)
+	require.Zero(t, log.TotalCost)
+	require.Zero(t, log.ActualCost)
+}
+
+func TestGatewayServiceRecordUsage_ModelFallbackBillsServedModel(t *testing.T) {
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc := newGatewayRecordUsageServiceForTest(usageRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	cfg := &config.Config{}
+	cfg.Pricing.ModelPrices = []config.ModelPriceConfig{
+		{Model: "claude-fallback-large", InputPer1K: 0.01, OutputPer1K: 0.02},
+		{Model: "claude-fallback-small", InputPer1K: 0.001, OutputPer1K: 0.002},
+	}
+	svc.billingService = NewBillingService(cfg, nil)
+
+	// handler 降级后按实际服务模型解析渠道映射，FallbackFromModel 保留客户端请求的模型
+	err := svc.RecordUsage(context.Background(), &RecordUsageInput{
+		Result: &ForwardResult{
+			RequestID: "gateway_model_fallback",
+			Usage:     ClaudeUsage{InputTokens: 1000, OutputTokens: 1000},
+			Model:     "claude-fallback-small",
+			Duration:  time.Second,
+		},
+		APIKey:             &APIKey{ID: 503},
+		User:               &User{ID: 603},
+		Account:            &Account{ID: 703},
+		FallbackFromModel:  "claude-fallback-large",
+		ChannelUsageFields: ChannelMappingResult{BillingModelSource: BillingModelSourceRequested}.ToUsageFields("claude-fallback-small", ""),
+	})
+	require.NoError(t, err)
+
+	log := usageRepo.lastLog
+	require.NotNil(t, log)
+	require.Equal(t, "claude-fallback-small", log.Model)
+	require.Equal(t, "claude-fallback-large", log.RequestedModel)
+	require.InDelta(t, 0.001, log.InputCost, 1e-10)
+	require.InDelta(t, 0.002, log.OutputCost, 1e-10)
+}
@@ -238,6 +238,8 @@ type ParsedRequest struct {
 	OutputEffort    string          // output_config.effort（Claude API 的推理强度控制）
 	MaxTokens       int             // max_tokens 值（用于探测请求拦截）
 	SessionContext  *SessionContext // 可选：请求上下文区分因子（nil 时行为不变）
+	// SessionAffinityKey 客户端显式指定的会话亲和键（X-Session-Affinity 头），非空时优先于其它 hash 来源
+	SessionAffinityKey string
 
 	protocol      string    // 当前 Body 的协议格式，用于 Body 替换后刷新 raw range
 	systemRange   jsonRange // system/systemInstruction.parts 的 raw JSON 范围，绑定 Body 当前内容
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const (
+	// ResponseCacheHeader 请求头 X-Cache: true 为本次请求启用响应缓存；响应头回写 hit / miss
+	ResponseCacheHeader = "X-Cache"
+	// ResponseCacheHitValue 响应由缓存直接返回
+	ResponseCacheHitValue = "hit"
+	// ResponseCacheMissValue 可缓存请求未命中，已转发上游
+	ResponseCacheMissValue = "miss"
+
+	ResponseCacheScopeAnthropicMessages     = "anthropic_messages"
+	ResponseCacheScopeOpenAIResponses       = "openai_responses"
+	ResponseCacheScopeOpenAIChatCompletions = "openai_chat_completions"
+)
+
+// responseCacheVolatileFields 计算缓存键前剔除的字段：只影响追踪/路由，不影响输出内容
+var responseCacheVolatileFields = []string{
+	"metadata",
+	"user",
+	"request_id",
+	"stream",
+	"stream_options",
+	"safety_identifier",
+	"prompt_cache_key",
+	"temperature",
+}
+
+// gatewayResponseCacheReplayHeaders 命中时回写的响应头子集
+var gatewayResponseCacheReplayHeaders = []string{"Content-Type"}
+
+// GatewayResponseCache 响应缓存存储（Redis）。
+// 缺失时 GetCachedResponse 返回 nil, nil。
+type GatewayResponseCache interface {
+	GetCachedResponse(ctx context.Context, key string) ([]byte, error)
+	SetCachedResponse(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// CachedGatewayResponse 已缓存的成功响应
+type CachedGatewayResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    []byte            `json:"body"`
+	// Model / AccountID 产生该响应的模型与账号，用于缓存命中的使用记录
+	Model     string `json:"model,omitempty"`
+	AccountID int64  `json:"account_id"`
+}
+
+// ResponseCacheHitInput 缓存命中时写入使用记录所需的信息
+type ResponseCacheHitInput struct {
+	APIKey          *APIKey
+	Subscription    *UserSubscription
+	Response        *CachedGatewayResponse
+	RequestedModel  string
+	InboundEndpoint string
+	UserAgent       string
+	IPAddress       string
+	Duration        time.Duration
+}
+
+// GatewayResponseCacheService 确定性请求的响应缓存。
+// 仅缓存 temperature=0、非流式、非 store 会话的 2xx 响应；存储故障时 fail-open，按未命中处理。
+type GatewayResponseCacheService struct {
+	cache        GatewayResponseCache
+	usageLogRepo UsageLogRepository
+	cfg          config.GatewayResponseCacheConfig
+}
+
+// NewGatewayResponseCacheService 创建响应缓存服务
+func NewGatewayResponseCacheService(cache GatewayResponseCache, usageLogRepo UsageLogRepository, cfg *config.Config) *GatewayResponseCacheService {
+	svc := &GatewayResponseCacheService{cache: cache, usageLogRepo: usageLogRepo}
+	if cfg != nil {
+		svc.cfg = cfg.Gateway.ResponseCache
+	}
+	return svc
+}
+
+// Enabled 是否启用响应缓存
+func (s *GatewayResponseCacheService) Enabled() bool {
+	return s != nil && s.cache != nil && s.cfg.Enabled
+}
+
+// MaxBodyBytes 可缓存的最大响应体字节数
+func (s *GatewayResponseCacheService) MaxBodyBytes() int {
+	if s == nil {
+		return 0
+	}
+	return s.cfg.MaxBodyBytes
+}
+
+// Requested 本次请求是否选择使用响应缓存：API Key 开启，或请求头 X-Cache: true
+func (s *GatewayResponseCacheService) Requested(apiKey *APIKey, headerValue string) bool {
+	if !s.Enabled() {
+		return false
+	}
+	if apiKey != nil && apiKey.ResponseCacheEnabled {
+		return true
+	}
+	v := strings.TrimSpace(headerValue)
+	return strings.EqualFold(v, "true") || v == "1"
+}
+
+// ResponseCacheKey 为可缓存的请求计算缓存键（按 API Key 隔离）；不可缓存时 ok=false。
+func ResponseCacheKey(scope string, apiKeyID int64, body []byte) (key string, ok bool) {
+	normalized, ok := normalizeResponseCacheBody(scope, body)
+	if !ok {
+		return "", false
+	}
+	sum := sha256.Sum256(normalized)
+	return scope + ":" + strconv.FormatInt(apiKeyID, 10) + ":" + hex.EncodeToString(sum[:]), true
+}
+
+// normalizeResponseCacheBody 校验请求是否可缓存，并输出剔除易变字段后的规范化请求体。
+// 可缓存条件：显式 temperature=0、非流式、未要求保存会话（store=true / Responses 续接会话）。
+func normalizeResponseCacheBody(scope string, body []byte) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var req map[string]any
+	if err := dec.Decode(&req); err != nil || req == nil {
+		return nil, false
+	}
+	if stream, _ := req["stream"].(bool); stream {
+		return nil, false
+	}
+	if !isZeroTemperature(req["temperature"]) {
+		return nil, false
+	}
+	store, hasStore := req["store"].(bool)
+	if store {
+		return nil, false
+	}
+	if scope == ResponseCacheScopeOpenAIResponses {
+		// Responses API 未显式 store=false 时上游默认保存会话
+		if !hasStore {
+			return nil, false
+		}
+		if v, _ := req["previous_response_id"].(string); strings.TrimSpace(v) != "" {
+			return nil, false
+		}
+		if req["conversation"] != nil {
+			return nil, false
+		}
+	}
+	for _, field := range responseCacheVolatileFields {
+		delete(req, field)
+	}
+	// map 按键排序序列化，json.Number 保留原始数字文本，保证相同语义请求得到相同结果
+	normalized, err := json.Marshal(req)
+	if err != nil {
+		return nil, false
+	}
+	return normalized, true
+}
+
+func isZeroTemperature(v any) bool {
+	n, ok := v.(json.Number)
+	if !ok {
+		return false
+	}
+	f, err := n.Float64()
+	return err == nil && f == 0
+}
+
+// Lookup 查询缓存的响应；未命中或存储故障时返回 nil。
+func (s *GatewayResponseCacheService) Lookup(ctx context.Context, key string) *CachedGatewayResponse {
+	if !s.Enabled() || key == "" {
+		return nil
+	}
+	raw, err := s.cache.GetCachedResponse(ctx, key)
+	if err != nil {
+		slog.Warn("gateway_response_cache_unavailable", "error", err)
+		return nil
+	}
+	if raw == nil {
+		return nil
+	}
+	var resp CachedGatewayResponse
+	if err := json.Unmarshal(raw, &resp); err != nil || resp.Status == 0 {
+		slog.Warn("gateway_response_cache_record_corrupted", "error", err)
+		return nil
+	}
+	return &resp
+}
+
+// Store 保存成功响应；非 2xx、空响应或超过 max_body_bytes 的响应不缓存。
+func (s *GatewayResponseCacheService) Store(ctx context.Context, key string, status int, header http.Header, body []byte, accountID int64, model string) {
+	if !s.Enabled() || key == "" || accountID <= 0 {
+		return
+	}
+	if status < 200 || status >= 300 || len(body) == 0 || len(body) > s.cfg.MaxBodyBytes {
+		return
+	}
+	resp := CachedGatewayResponse{Status: status, Body: body, Model: model, AccountID: accountID}
+	for _, name := range gatewayResponseCacheReplayHeaders {
+		if v := header.Get(name); v != "" {
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]string, len(gatewayResponseCacheReplayHeaders))
+			}
+			resp.Headers[name] = v
+		}
+	}
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	ttl := time.Duration(s.cfg.TTLSeconds) * time.Second
+	if err := s.cache.SetCachedResponse(ctx, key, raw, ttl); err != nil {
+		slog.Warn("gateway_response_cache_store_failed", "error", err)
+	}
+}
+
+// RecordHit 为缓存命中写入使用记录：上游 token 与费用均为 0，并标记 response_cache_hit。
+func (s *GatewayResponseCacheService) RecordHit(ctx context.Context, input *ResponseCacheHitInput) {
+	if s == nil || input == nil || input.APIKey == nil || input.Response == nil {
+		return
+	}
+	apiKey := input.APIKey
+	multiplier := 1.0
+	if apiKey.Group != nil {
+		multiplier = apiKey.Group.RateMultiplier
+	}
+	model := input.Response.Model
+	if model == "" {
+		model = input.RequestedModel
+	}
+	// 计费方式与正常用量记录同口径：订阅分组且存在有效订阅时记为订阅计费
+	billingType := BillingTypeBalance
+	if input.Subscription != nil && apiKey.Group != nil && apiKey.Group.IsSubscriptionType() {
+		billingType = BillingTypeSubscription
+	}
+	durationMs := int(input.Duration.Milliseconds())
+	usageLog := &UsageLog{
+		UserID:           apiKey.UserID,
+		APIKeyID:         apiKey.ID,
+		AccountID:        input.Response.AccountID,
+		RequestID:        resolveUsageBillingRequestID(ctx, ""),
+		Model:            model,
+		RequestedModel:   input.RequestedModel,
+		InboundEndpoint:  optionalTrimmedStringPtr(input.InboundEndpoint),
+		GroupID:          apiKey.GroupID,
+		RateMultiplier:   multiplier,
+		SubscriptionID:   optionalSubscriptionID(input.Subscription),
+		BillingType:      billingType,
+		RequestType:      RequestTypeSync,
+		DurationMs:       &durationMs,
+		UserAgent:        optionalTrimmedStringPtr(input.UserAgent),
+		IPAddress:        optionalTrimmedStringPtr(input.IPAddress),
+		ResponseCacheHit: true,
+		CreatedAt:        time.Now(),
+	}
+	writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.response_cache")
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type responseCacheStoreStub struct {
+	mu      sync.Mutex
+	records map[string][]byte
+	ttl     time.Duration
+	getErr  error
+}
+
+func (s *responseCacheStoreStub) GetCachedResponse(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	return s.records[key], nil
+}
+
+func (s *responseCacheStoreStub) SetCachedResponse(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = value
+	s.ttl = ttl
+	return nil
+}
+
+func newResponseCacheTestService(repo UsageLogRepository) (*GatewayResponseCacheService, *responseCacheStoreStub) {
+	cfg := &config.Config{}
+	cfg.Gateway.ResponseCache = config.GatewayResponseCacheConfig{Enabled: true, TTLSeconds: 60, MaxBodyBytes: 64}
+	store := &responseCacheStoreStub{records: make(map[string][]byte)}
+	return NewGatewayResponseCacheService(store, repo, cfg), store
+}
+
+func TestResponseCacheKey_NormalizesVolatileFields(t *testing.T) {
+	a := `{"model":"claude-sonnet-4-5","temperature":0,"messages":[{"role":"user","content":"hi"}],"metadata":{"user_id":"u1"}}`
+	b := `{"metadata":{"user_id":"u2"},"messages":[{"role":"user","content":"hi"}],"stream":false,"temperature":0.0,"model":"claude-sonnet-4-5"}`
+	keyA, ok := ResponseCacheKey(ResponseCacheScopeAnthropicMessages, 1, []byte(a))
+	require.True(t, ok)
+	keyB, ok := ResponseCacheKey(ResponseCacheScopeAnthropicMessages, 1, []byte(b))
+	require.True(t, ok)
+	require.Equal(t, keyA, keyB)
+
+	// 按 API Key 与协议隔离
+	other, _ := ResponseCacheKey(ResponseCacheScopeAnthropicMessages, 2, []byte(a))
+	require.NotEqual(t, keyA, other)
+	chat, _ := ResponseCacheKey(ResponseCacheScopeOpenAIChatCompletions, 1, []byte(a))
+	require.NotEqual(t, keyA, chat)
+
+	// 内容不同则键不同
+	c := `{"model":"claude-sonnet-4-5","temperature":0,"messages":[{"role":"user","content":"hello"}]}`
+	keyC, _ := ResponseCacheKey(ResponseCacheScopeAnthropicMessages, 1, []byte(c))
+	require.NotEqual(t, keyA, keyC)
+}
+
+func TestResponseCacheKey_RejectsNonDeterministicRequests(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope string
+		body  string
+	}{
+		{name: "missing temperature", scope: ResponseCacheScopeAnthropicMessages, body: `{"model":"m","messages":[]}`},
+		{name: "non-zero temperature", scope: ResponseCacheScopeAnthropicMessages, body: `{"model":"m","temperature":0.2}`},
+		{name: "string temperature", scope: ResponseCacheScopeAnthropicMessages, body: `{"model":"m","temperature":"0"}`},
+		{name: "streaming", scope: ResponseCacheScopeOpenAIChatCompletions, body: `{"model":"m","temperature":0,"stream":true}`},
+		{name: "chat store", scope: ResponseCacheScopeOpenAIChatCompletions, body: `{"model":"m","temperature":0,"store":true}`},
+		{name: "responses default store", scope: ResponseCacheScopeOpenAIResponses, body: `{"model":"m","temperature":0,"input":"hi"}`},
+		{name: "responses previous id", scope: ResponseCacheScopeOpenAIResponses, body: `{"model":"m","temperature":0,"store":false,"previous_response_id":"resp_1"}`},
+		{name: "responses conversation", scope: ResponseCacheScopeOpenAIResponses, body: `{"model":"m","temperature":0,"store":false,"conversation":"conv_1"}`},
+		{name: "invalid json", scope: ResponseCacheScopeAnthropicMessages, body: `{"model":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := ResponseCacheKey(tt.scope, 1, []byte(tt.body))
+			require.False(t, ok)
+		})
+	}
+
+	_, ok := ResponseCacheKey(ResponseCacheScopeOpenAIResponses, 1, []byte(`{"model":"m","temperature":0,"store":false,"input":"hi"}`))
+	require.True(t, ok)
+}
+
+func TestGatewayResponseCacheService_Requested(t *testing.T) {
+	svc, _ := newResponseCacheTestService(nil)
+	require.True(t, svc.Requested(&APIKey{ResponseCacheEnabled: true}, ""))
+	require.True(t, svc.Requested(&APIKey{}, "true"))
+	require.True(t, svc.Requested(&APIKey{}, "1"))
+	require.False(t, svc.Requested(&APIKey{}, ""))
+	require.False(t, svc.Requested(&APIKey{}, "no-cache"))
+
+	disabled := NewGatewayResponseCacheService(&responseCacheStoreStub{}, nil, &config.Config{})
+	require.False(t, disabled.Requested(&APIKey{ResponseCacheEnabled: true}, "true"))
+	var nilSvc *GatewayResponseCacheService
+	require.False(t, nilSvc.Requested(&APIKey{ResponseCacheEnabled: true}, "true"))
+}
+
+func TestGatewayResponseCacheService_StoreAndLookup(t *testing.T) {
+	svc, store := newResponseCacheTestService(nil)
+	ctx := context.Background()
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Request-Id", "req_1")
+
+	require.Nil(t, svc.Lookup(ctx, "k"))
+
+	svc.Store(ctx, "k", http.StatusOK, header, []byte(`{"ok":true}`), 7, "claude-sonnet-4-5")
+	require.Equal(t, 60*time.Second, store.ttl)
+	cached := svc.Lookup(ctx, "k")
+	require.NotNil(t, cached)
+	require.Equal(t, http.StatusOK, cached.Status)
+	require.Equal(t, `{"ok":true}`, string(cached.Body))
+	require.Equal(t, map[string]string{"Content-Type": "application/json"}, cached.Headers)
+	require.EqualValues(t, 7, cached.AccountID)
+	require.Equal(t, "claude-sonnet-4-5", cached.Model)
+
+	// 非 2xx、超过上限、缺少账号的响应不缓存
+	svc.Store(ctx, "err", http.StatusTooManyRequests, header, []byte(`{}`), 7, "m")
+	svc.Store(ctx, "big", http.StatusOK, header, make([]byte, 65), 7, "m")
+	svc.Store(ctx, "noacct", http.StatusOK, header, []byte(`{}`), 0, "m")
+	require.Nil(t, svc.Lookup(ctx, "err"))
+	require.Nil(t, svc.Lookup(ctx, "big"))
+	require.Nil(t, svc.Lookup(ctx, "noacct"))
+
+	// 存储故障时按未命中处理
+	store.getErr = errors.New("redis down")
+	require.Nil(t, svc.Lookup(ctx, "k"))
+}
+
+func TestGatewayResponseCacheService_RecordHitWritesZeroCostUsageLog(t *testing.T) {
+	repo := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc, _ := newResponseCacheTestService(repo)
+	groupID := int64(3)
+	apiKey := &APIKey{ID: 11, UserID: 5, GroupID: &groupID, Group: &Group{ID: groupID, RateMultiplier: 1.5}}
+
+	svc.RecordHit(context.Background(), &ResponseCacheHitInput{
+		APIKey:          apiKey,
+		Response:        &CachedGatewayResponse{Status: http.StatusOK, AccountID: 9, Model: "gpt-5"},
+		RequestedModel:  "gpt-5-alias",
+		InboundEndpoint: "/v1/chat/completions",
+		Duration:        3 * time.Millisecond,
+	})
+
+	require.Equal(t, 1, repo.calls)
+	log := repo.lastLog
+	require.NotNil(t, log)
+	require.True(t, log.ResponseCacheHit)
+	require.EqualValues(t, 9, log.AccountID)
+	require.EqualValues(t, 11, log.APIKeyID)
+	require.EqualValues(t, 5, log.UserID)
+	require.Equal(t, "gpt-5", log.Model)
+	require.Equal(t, "gpt-5-alias", log.RequestedModel)
+	require.Zero(t, log.TotalCost)
+	require.Zero(t, log.ActualCost)
+	require.Zero(t, log.InputTokens)
+	require.Zero(t, log.OutputTokens)
+	require.NotEmpty(t, log.RequestID)
+	require.Equal(t, 1.5, log.RateMultiplier)
+}
+
+func TestGatewayResponseCacheService_RecordHitUsesSubscriptionBillingType(t *testing.T) {
+	repo := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc, _ := newResponseCacheTestService(repo)
+	groupID := int64(4)
+	apiKey := &APIKey{ID: 12, UserID: 6, GroupID: &groupID, Group: &Group{ID: groupID, RateMultiplier: 1, SubscriptionType: SubscriptionTypeSubscription}}
+
+	svc.RecordHit(context.Background(), &ResponseCacheHitInput{
+		APIKey:       apiKey,
+		Subscription: &UserSubscription{ID: 21},
+		Response:     &CachedGatewayResponse{Status: http.StatusOK, AccountID: 9, Model: "gpt-5"},
+	})
+	require.NotNil(t, repo.lastLog)
+	require.Equal(t, BillingTypeSubscription, repo.lastLog.BillingType)
+	require.NotNil(t, repo.lastLog.SubscriptionID)
+	require.EqualValues(t, 21, *repo.lastLog.SubscriptionID)
+
+	// 非订阅分组按余额计费
+	apiKey.Group.SubscriptionType = SubscriptionTypeStandard
+	svc.RecordHit(context.Background(), &ResponseCacheHitInput{
+		APIKey:   apiKey,
+		Response: &CachedGatewayResponse{Status: http.StatusOK, AccountID: 9, Model: "gpt-5"},
+	})
+	require.Equal(t, BillingTypeBalance, repo.lastLog.BillingType)
+	require.Nil(t, repo.lastLog.SubscriptionID)
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	defaultAccountRoutingWeight = 50
+	maxAccountRoutingWeight     = 100
+
+	accountRoutingWeightExtraKey = "routing_weight"
+)
+
+var ErrInvalidAccountRoutingWeight = infraerrors.BadRequest("INVALID_ROUTING_WEIGHT", "routing_weight must be an integer between 1 and 100")
+
+// ValidateAccountExtraRoutingWeight 校验 extra.routing_weight（1-100 的整数）并规范化为 int 写回；
+// 未设置或为 null 时删除该键（使用默认权重）。
+func ValidateAccountExtraRoutingWeight(extra map[string]any) error {
+	if extra == nil {
+		return nil
+	}
+	raw, ok := extra[accountRoutingWeightExtraKey]
+	if !ok {
+		return nil
+	}
+	if raw == nil {
+		delete(extra, accountRoutingWeightExtraKey)
+		return nil
+	}
+	var weight float64
+	switch v := raw.(type) {
+	case int:
+		weight = float64(v)
+	case int64:
+		weight = float64(v)
+	case float64:
+		weight = v
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return ErrInvalidAccountRoutingWeight
+		}
+		weight = f
+	default:
+		return ErrInvalidAccountRoutingWeight
+	}
+	if weight != math.Trunc(weight) || weight < 1 || weight > maxAccountRoutingWeight {
+		return ErrInvalidAccountRoutingWeight
+	}
+	extra[accountRoutingWeightExtraKey] = int(weight)
+	return nil
+}
+
+// AccountRoutingScore 加权选号时单个账号的得分明细（用于调试/解释选号结果）。
+// 各 factor 均归一化到 [0,1]，越大越好；Total 为按 gateway.routing 系数加权后的总分。
+type AccountRoutingScore struct {
+	AccountID     int64   `json:"account_id"`
+	Total         float64 `json:"total"`
+	LoadFactor    float64 `json:"load_factor"`
+	QueueFactor   float64 `json:"queue_factor"`
+	ErrorFactor   float64 `json:"error_factor"`
+	WeightFactor  float64 `json:"weight_factor"`
+	LatencyFactor float64 `json:"latency_factor"`
+	HealthFactor  float64 `json:"health_factor"`
+	LoadRate      int     `json:"load_rate"`
+	WaitingCount  int     `json:"waiting_count"`
+	ErrorRate     float64 `json:"error_rate"`
+	Weight        int     `json:"weight"`
+	LatencyMs     float64 `json:"latency_ms"`
+}
+
+type scoredAccountWithLoad struct {
+	accountWithLoad
+	score AccountRoutingScore
+}
+
+// accountRoutingSignals 打分所需的账号历史信号，字段为 nil 时对应因子取中性值。
+type accountRoutingSignals struct {
+	// errorRate 返回账号近期错误率（0-1）
+	errorRate func(accountID int64) float64
+	// latency 返回账号上游延迟 EWMA（毫秒）及其置信度（0-1，随样本老化衰减）；无样本时返回 0, 0
+	latency func(accountID int64) (latencyMs float64, confidence float64)
+	// unhealthy 返回账号是否被主动健康探测判定为不健康
+	unhealthy func(accountID int64) bool
+}
+
+type accountLatencySample struct {
+	latencyMs  float64
+	confidence float64
+}
+
+// scoreAccountsForRouting 为同一优先级内的候选打分，返回按得分降序排列的结果（同分按账号 ID 升序）。
+func scoreAccountsForRouting(accounts []accountWithLoad, weights config.GatewayRoutingConfig, signals accountRoutingSignals) []scoredAccountWithLoad {
+	if len(accounts) == 0 {
+		return nil
+	}
+	maxWaiting := 1
+	for _, acc := range accounts {
+		if acc.loadInfo != nil && acc.loadInfo.WaitingCount > maxWaiting {
+			maxWaiting = acc.loadInfo.WaitingCount
+		}
+	}
+	// 延迟因子相对候选中最快的账号计算：最快 = 1，越慢越接近 0；置信度衰减后向 1（中性）回归
+	latencies := make(map[int64]accountLatencySample, len(accounts))
+	fastestMs := 0.0
+	if signals.latency != nil {
+		for _, acc := range accounts {
+			latencyMs, confidence := signals.latency(acc.account.ID)
+			if latencyMs <= 0 || confidence <= 0 {
+				continue
+			}
+			latencies[acc.account.ID] = accountLatencySample{latencyMs: latencyMs, confidence: clamp01(confidence)}
+			if fastestMs == 0 || latencyMs < fastestMs {
+				fastestMs = latencyMs
+			}
+		}
+	}
+
+	scored := make([]scoredAccountWithLoad, 0, len(accounts))
+	for _, acc := range accounts {
+		loadInfo := acc.loadInfo
+		if loadInfo == nil {
+			loadInfo = &AccountLoadInfo{AccountID: acc.account.ID}
+		}
+		rate := 0.0
+		if signals.errorRate != nil {
+			rate = clamp01(signals.errorRate(acc.account.ID))
+		}
+		weight := acc.account.GetRoutingWeight()
+		latencyFactor := 1.0
+		latency, hasLatency := latencies[acc.account.ID]
+		if hasLatency {
+			latencyFactor = 1 - latency.confidence*(1-fastestMs/latency.latencyMs)
+		}
+		healthFactor := 1.0
+		if signals.unhealthy != nil && signals.unhealthy(acc.account.ID) {
+			healthFactor = 0
+		}
+
+		score := AccountRoutingScore{
+			AccountID:     acc.account.ID,
+			LoadFactor:    1 - clamp01(float64(loadInfo.LoadRate)/100.0),
+			QueueFactor:   1 - clamp01(float64(loadInfo.WaitingCount)/float64(maxWaiting)),
+			ErrorFactor:   1 - rate,
+			WeightFactor:  float64(weight) / maxAccountRoutingWeight,
+			LatencyFactor: latencyFactor,
+			HealthFactor:  healthFactor,
+			LoadRate:      loadInfo.LoadRate,
+			WaitingCount:  loadInfo.WaitingCount,
+			ErrorRate:     rate,
+			Weight:        weight,
+			LatencyMs:     latency.latencyMs,
+		}
+		score.Total = weights.Load*score.LoadFactor +
+			weights.Queue*score.QueueFactor +
+			weights.ErrorRate*score.ErrorFactor +
+			weights.Weight*score.WeightFactor +
+			weights.Latency*score.LatencyFactor +
+			weights.Health*score.HealthFactor
+		scored = append(scored, scoredAccountWithLoad{accountWithLoad: acc, score: score})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].score.Total != scored[j].score.Total {
+			return scored[i].score.Total > scored[j].score.Total
+		}
+		return scored[i].account.ID < scored[j].account.ID
+	})
+	return scored
+}
+
+// routingErrorRate 返回账号近期上游错误率 EWMA（0-1）。
+func (s *GatewayService) routingErrorRate(accountID int64) float64 {
+	errorRate, _, _ := s.routingStats.snapshot(accountID)
+	return errorRate
+}
+
+// ReportAccountRoutingResult 记录一次转发结果，用于加权选号中的错误率因子。
+// 仅应上报上游原因的成功/失败（如 failover 错误），客户端侧错误不计入。
+func (s *GatewayService) ReportAccountRoutingResult(accountID int64, success bool) {
+	if s == nil {
+		return
+	}
+	s.routingStats.report(accountID, success, nil)
+}
+
+// routingSignals 返回加权选号使用的账号历史信号
+func (s *GatewayService) routingSignals(cfg config.GatewayRoutingConfig) accountRoutingSignals {
+	halfLife := time.Duration(cfg.LatencyHalfLifeSeconds) * time.Second
+	return accountRoutingSignals{
+		errorRate: s.routingErrorRate,
+		latency: func(accountID int64) (float64, float64) {
+			return s.routingLatency.snapshot(accountID, halfLife)
+		},
+		unhealthy: s.accountHealth.IsAccountUnhealthy,
+	}
+}
+
+// SetAccountHealthProbe 注入主动健康探测服务，探测结果参与选号
+func (s *GatewayService) SetAccountHealthProbe(probe *AccountHealthProbeService) {
+	if s == nil {
+		return
+	}
+	s.accountHealth = probe
+}
+
+// SetUpstreamRateLimitTracker 注入上游限流余量跟踪器，余量不足的账号在同优先级内仅作为最后选择
+func (s *GatewayService) SetUpstreamRateLimitTracker(tracker *UpstreamRateLimitTracker) {
+	if s == nil {
+		return
+	}
+	s.upstreamRateLimits = tracker
+}
+
+// SetAccountServerErrorTracker 注入上游 5xx 降权跟踪器，降权期内的账号在同优先级内仅作为最后选择
+func (s *GatewayService) SetAccountServerErrorTracker(tracker *AccountServerErrorTracker) {
+	if s == nil {
+		return
+	}
+	s.serverErrors = tracker
+}
+
+// ReportAccountUpstreamLatency 记录一次成功转发的上游延迟（毫秒），用于加权选号中的延迟因子。
+func (s *GatewayService) ReportAccountUpstreamLatency(accountID int64, latencyMs int64) {
+	if s == nil {
+		return
+	}
+	s.routingLatency.report(accountID, latencyMs)
+}
+
+// accountLatencyTracker 按账号维护上游延迟 EWMA。
+// 样本的置信度按半衰期随时间衰减，慢账号即使不再被选中也会逐步回到中性分、重新获得流量。
+type accountLatencyTracker struct {
+	accounts sync.Map // accountID -> *accountLatencyStat
+	now      func() time.Time
+}
+
+type accountLatencyStat struct {
+	mu        sync.Mutex
+	ewmaMs    float64
+	updatedAt time.Time
+}
+
+const accountLatencyEWMAAlpha = 0.2
+
+func newAccountLatencyTracker() *accountLatencyTracker {
+	return &accountLatencyTracker{now: time.Now}
+}
+
+func (t *accountLatencyTracker) report(accountID int64, latencyMs int64) {
+	if t == nil || accountID <= 0 || latencyMs <= 0 {
+		return
+	}
+	value, _ := t.accounts.LoadOrStore(accountID, &accountLatencyStat{})
+	stat, ok := value.(*accountLatencyStat)
+	if !ok {
+		return
+	}
+	stat.mu.Lock()
+	defer stat.mu.Unlock()
+	if stat.updatedAt.IsZero() {
+		stat.ewmaMs = float64(latencyMs)
+	} else {
+		stat.ewmaMs = accountLatencyEWMAAlpha*float64(latencyMs) + (1-accountLatencyEWMAAlpha)*stat.ewmaMs
+	}
+	stat.updatedAt = t.now()
+}
+
+// snapshot 返回账号延迟 EWMA 与置信度（0.5^(距最近样本时长/半衰期)）；无样本时返回 0, 0。
+func (t *accountLatencyTracker) snapshot(accountID int64, halfLife time.Duration) (float64, float64) {
+	if t == nil {
+		return 0, 0
+	}
+	value, ok := t.accounts.Load(accountID)
+	if !ok {
+		return 0, 0
+	}
+	stat, ok := value.(*accountLatencyStat)
+	if !ok {
+		return 0, 0
+	}
+	stat.mu.Lock()
+	latencyMs, updatedAt := stat.ewmaMs, stat.updatedAt
+	stat.mu.Unlock()
+	if updatedAt.IsZero() {
+		return 0, 0
+	}
+	if halfLife <= 0 {
+		return latencyMs, 1
+	}
+	age := t.now().Sub(updatedAt)
+	if age <= 0 {
+		return latencyMs, 1
+	}
+	return latencyMs, math.Pow(0.5, age.Seconds()/halfLife.Seconds())
+}
+
+func logRoutingScores(groupID *int64, requestedModel string, scored []scoredAccountWithLoad) {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	scores := make([]AccountRoutingScore, 0, len(scored))
+	for _, item := range scored {
+		scores = append(scores, item.score)
+	}
+	slog.Debug("account_scheduling_scored",
+		"group_id", derefGroupID(groupID),
+		"model", requestedModel,
+		"scores", scores)
+}
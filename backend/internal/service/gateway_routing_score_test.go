@@ -0,0 +1,197 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func defaultRoutingWeights() config.GatewayRoutingConfig {
+	return config.GatewayRoutingConfig{
+		WeightedScoringEnabled: true,
+		Load:                   1.0,
+		Queue:                  0.7,
+		ErrorRate:              0.8,
+		Weight:                 1.0,
+		Latency:                0.5,
+		LatencyHalfLifeSeconds: 300,
+	}
+}
+
+func routingAccount(id int64, weight any) *Account {
+	acc := &Account{ID: id, Priority: 1}
+	if weight != nil {
+		acc.Extra = map[string]any{"routing_weight": weight}
+	}
+	return acc
+}
+
+func TestScoreAccountsForRouting_WeightBias(t *testing.T) {
+	accounts := []accountWithLoad{
+		{account: routingAccount(1, 10), loadInfo: &AccountLoadInfo{AccountID: 1, LoadRate: 20}},
+		{account: routingAccount(2, 90), loadInfo: &AccountLoadInfo{AccountID: 2, LoadRate: 20}},
+	}
+
+	scored := scoreAccountsForRouting(accounts, defaultRoutingWeights(), accountRoutingSignals{})
+	require.Len(t, scored, 2)
+	require.Equal(t, int64(2), scored[0].account.ID)
+	require.Equal(t, 90, scored[0].score.Weight)
+	require.Greater(t, scored[0].score.Total, scored[1].score.Total)
+}
+
+func TestScoreAccountsForRouting_ErrorRatePenalty(t *testing.T) {
+	accounts := []accountWithLoad{
+		{account: routingAccount(1, nil), loadInfo: &AccountLoadInfo{AccountID: 1, LoadRate: 10}},
+		{account: routingAccount(2, nil), loadInfo: &AccountLoadInfo{AccountID: 2, LoadRate: 30}},
+	}
+	errorRates := map[int64]float64{1: 0.9}
+
+	scored := scoreAccountsForRouting(accounts, defaultRoutingWeights(), accountRoutingSignals{
+		errorRate: func(id int64) float64 { return errorRates[id] },
+	})
+	require.Equal(t, int64(2), scored[0].account.ID, "lightly loaded but failing account should lose")
+	require.InDelta(t, 0.9, scored[1].score.ErrorRate, 1e-9)
+	require.InDelta(t, 0.1, scored[1].score.ErrorFactor, 1e-9)
+}
+
+func TestScoreAccountsForRouting_QueueDepthAndLoad(t *testing.T) {
+	accounts := []accountWithLoad{
+		{account: routingAccount(1, nil), loadInfo: &AccountLoadInfo{AccountID: 1, LoadRate: 50, WaitingCount: 4}},
+		{account: routingAccount(2, nil), loadInfo: &AccountLoadInfo{AccountID: 2, LoadRate: 50}},
+		{account: routingAccount(3, nil), loadInfo: &AccountLoadInfo{AccountID: 3, LoadRate: 90, WaitingCount: 4}},
+	}
+
+	scored := scoreAccountsForRouting(accounts, defaultRoutingWeights(), accountRoutingSignals{})
+	require.Equal(t, []int64{2, 1, 3}, []int64{scored[0].account.ID, scored[1].account.ID, scored[2].account.ID})
+	require.InDelta(t, 1.0, scored[0].score.QueueFactor, 1e-9)
+	require.InDelta(t, 0.0, scored[1].score.QueueFactor, 1e-9)
+}
+
+func TestScoreAccountsForRouting_TieBreaksOnLowestID(t *testing.T) {
+	accounts := []accountWithLoad{
+		{account: routingAccount(9, nil), loadInfo: &AccountLoadInfo{AccountID: 9}},
+		{account: routingAccount(3, nil), loadInfo: nil},
+		{account: routingAccount(5, nil), loadInfo: &AccountLoadInfo{AccountID: 5}},
+	}
+
+	scored := scoreAccountsForRouting(accounts, defaultRoutingWeights(), accountRoutingSignals{})
+	require.Equal(t, int64(3), scored[0].account.ID)
+	require.Equal(t, int64(5), scored[1].account.ID)
+	require.Equal(t, int64(9), scored[2].account.ID)
+}
+
+func TestAccountGetRoutingWeight(t *testing.T) {
+	require.Equal(t, 50, routingAccount(1, nil).GetRoutingWeight())
+	require.Equal(t, 80, routingAccount(1, 80).GetRoutingWeight())
+	require.Equal(t, 80, routingAccount(1, float64(80)).GetRoutingWeight())
+	require.Equal(t, 100, routingAccount(1, 500).GetRoutingWeight())
+	require.Equal(t, 50, routingAccount(1, 0).GetRoutingWeight())
+}
+
+func TestValidateAccountExtraRoutingWeight(t *testing.T) {
+	extra := map[string]any{"routing_weight": float64(80)}
+	require.NoError(t, ValidateAccountExtraRoutingWeight(extra))
+	require.Equal(t, 80, extra["routing_weight"])
+
+	extra = map[string]any{"routing_weight": nil}
+	require.NoError(t, ValidateAccountExtraRoutingWeight(extra))
+	require.NotContains(t, extra, "routing_weight")
+
+	require.NoError(t, ValidateAccountExtraRoutingWeight(nil))
+	require.NoError(t, ValidateAccountExtraRoutingWeight(map[string]any{}))
+
+	for _, bad := range []any{0, 101, -5, float64(50.5), "80", true} {
+		require.ErrorIs(t, ValidateAccountExtraRoutingWeight(map[string]any{"routing_weight": bad}), ErrInvalidAccountRoutingWeight, "%v", bad)
+	}
+}
+
+func TestGatewayService_RoutingErrorRateFromReports(t *testing.T) {
+	svc := &GatewayService{routingStats: newOpenAIAccountRuntimeStats()}
+	require.Zero(t, svc.routingErrorRate(7))
+
+	for i := 0; i < 5; i++ {
+		svc.ReportAccountRoutingResult(7, false)
+	}
+	require.Greater(t, svc.routingErrorRate(7), 0.5)
+
+	var nilStats GatewayService
+	nilStats.ReportAccountRoutingResult(7, false)
+	require.Zero(t, nilStats.routingErrorRate(7))
+}
+
+func TestScoreAccountsForRouting_LatencyDeprioritizesSlowAccount(t *testing.T) {
+	accounts := []accountWithLoad{
+		{account: routingAccount(1, nil), loadInfo: &AccountLoadInfo{AccountID: 1, LoadRate: 20}},
+		{account: routingAccount(2, nil), loadInfo: &AccountLoadInfo{AccountID: 2, LoadRate: 30}},
+		{account: routingAccount(3, nil), loadInfo: &AccountLoadInfo{AccountID: 3, LoadRate: 30}},
+	}
+	latencies := map[int64]float64{1: 8000, 2: 800}
+
+	scored := scoreAccountsForRouting(accounts, defaultRoutingWeights(), accountRoutingSignals{
+		latency: func(id int64) (float64, float64) {
+			if ms, ok := latencies[id]; ok {
+				return ms, 1
+			}
+			return 0, 0
+		},
+	})
+	require.Equal(t, []int64{2, 3, 1}, []int64{scored[0].account.ID, scored[1].account.ID, scored[2].account.ID})
+	require.InDelta(t, 1.0, scored[0].score.LatencyFactor, 1e-9)
+	require.InDelta(t, 1.0, scored[1].score.LatencyFactor, 1e-9, "accounts without samples stay neutral")
+	require.InDelta(t, 0.1, scored[2].score.LatencyFactor, 1e-9)
+}
+
+func TestScoreAccountsForRouting_LatencyDoesNotOverrideCapacity(t *testing.T) {
+	accounts := []accountWithLoad{
+		{account: routingAccount(1, nil), loadInfo: &AccountLoadInfo{AccountID: 1, LoadRate: 10}},
+		{account: routingAccount(2, nil), loadInfo: &AccountLoadInfo{AccountID: 2, LoadRate: 95, WaitingCount: 3}},
+	}
+	latencies := map[int64]float64{1: 1500, 2: 1000}
+
+	scored := scoreAccountsForRouting(accounts, defaultRoutingWeights(), accountRoutingSignals{
+		latency: func(id int64) (float64, float64) { return latencies[id], 1 },
+	})
+	require.Equal(t, int64(1), scored[0].account.ID, "a slightly slower account with headroom beats a saturated fast one")
+}
+
+func TestAccountLatencyTracker_EWMAAndDecay(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newAccountLatencyTracker()
+	tracker.now = func() time.Time { return now }
+
+	latency, confidence := tracker.snapshot(7, time.Minute)
+	require.Zero(t, latency)
+	require.Zero(t, confidence)
+
+	tracker.report(7, 1000)
+	tracker.report(7, 2000)
+	latency, confidence = tracker.snapshot(7, time.Minute)
+	require.InDelta(t, 1200, latency, 1e-9)
+	require.InDelta(t, 1.0, confidence, 1e-9)
+
+	now = now.Add(time.Minute)
+	_, confidence = tracker.snapshot(7, time.Minute)
+	require.InDelta(t, 0.5, confidence, 1e-9)
+
+	now = now.Add(10 * time.Minute)
+	_, confidence = tracker.snapshot(7, time.Minute)
+	require.Less(t, confidence, 0.001, "stale samples decay so a recovered account returns to rotation")
+}
+
+func TestGatewayService_ReportAccountUpstreamLatency(t *testing.T) {
+	svc := &GatewayService{routingStats: newOpenAIAccountRuntimeStats(), routingLatency: newAccountLatencyTracker()}
+	svc.ReportAccountUpstreamLatency(7, 900)
+
+	latency, confidence := svc.routingSignals(defaultRoutingWeights()).latency(7)
+	require.InDelta(t, 900, latency, 1e-9)
+	require.Greater(t, confidence, 0.99)
+
+	var nilTracker GatewayService
+	nilTracker.ReportAccountUpstreamLatency(7, 900)
+	latency, _ = nilTracker.routingSignals(defaultRoutingWeights()).latency(7)
+	require.Zero(t, latency)
+}
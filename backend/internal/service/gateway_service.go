@@ -31,6 +31,8 @@ import (
 	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/Wei-Shaw/sub2api/internal/util/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/util/logredact"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
 	"github.com/cespare/xxhash/v2"
@@ -234,14 +236,21 @@ func (s *GatewayService) debugModelRoutingEnabled() bool {
 	if s == nil {
 		return false
 	}
-	return s.debugModelRouting.Load()
+	return debugLogEnabled(LogComponentGatewayModelRouting)
 }
 
 func (s *GatewayService) debugClaudeMimicEnabled() bool {
 	if s == nil {
 		return false
 	}
-	return s.debugClaudeMimic.Load()
+	return debugLogEnabled(LogComponentGatewayClaudeMimic)
+}
+
+func (s *GatewayService) debugGeminiResponseHeadersEnabled() bool {
+	if s == nil {
+		return false
+	}
+	return debugLogEnabled(LogComponentGatewayGemini)
 }
 
 func parseDebugEnvBool(raw string) bool {
@@ -263,28 +272,6 @@ func shortSessionHash(sessionHash string) string {
 	return sessionHash[:8]
 }
 
-func redactAuthHeaderValue(v string) string {
-	v = strings.TrimSpace(v)
-	if v == "" {
-		return ""
-	}
-	// Keep scheme for debugging, redact secret.
-	if strings.HasPrefix(strings.ToLower(v), "bearer ") {
-		return "Bearer [redacted]"
-	}
-	return "[redacted]"
-}
-
-func safeHeaderValueForLog(key string, v string) string {
-	key = strings.ToLower(strings.TrimSpace(key))
-	switch key {
-	case "authorization", "x-api-key":
-		return redactAuthHeaderValue(v)
-	default:
-		return strings.TrimSpace(v)
-	}
-}
-
 func extractSystemPreviewFromBody(body []byte) string {
 	if len(body) == 0 {
 		return ""
@@ -341,10 +328,11 @@ func buildClaudeMimicDebugLine(req *http.Request, body []byte, account *Account,
 		"x-stainless-helper-method",
 	}
 
+	redacted := logredact.RedactHeaders(req.Header)
 	h := make([]string, 0, len(interesting))
 	for _, k := range interesting {
-		if v := req.Header.Get(k); v != "" {
-			h = append(h, fmt.Sprintf("%s=%q", k, safeHeaderValueForLog(k, v)))
+		if v := strings.TrimSpace(redacted.Get(k)); v != "" {
+			h = append(h, fmt.Sprintf("%s=%q", k, v))
 		}
 	}
 
@@ -536,6 +524,8 @@ type AccountSelectionResult struct {
 	Acquired    bool
 	ReleaseFunc func()
 	WaitPlan    *AccountWaitPlan // nil means no wait allowed
+	// RoutingScore 加权选号命中时的得分明细（gateway.routing.weighted_scoring_enabled），其余路径为 nil
+	RoutingScore *AccountRoutingScore
 }
 
 // ClaudeUsage 表示Claude API返回的usage信息
@@ -571,6 +561,9 @@ type ForwardResult struct {
 	ImageOutputSizes   []string
 	ImageSizeSource    string
 	ImageSizeBreakdown map[string]int
+
+	// UpstreamRateLimit 上游响应头报告的限流余量（未启用或上游未返回时为 nil）
+	UpstreamRateLimit *UpstreamRateLimitSnapshot
 }
 
 // UpstreamFailoverError indicates an upstream error that should trigger account failover.
@@ -578,6 +571,7 @@ type UpstreamFailoverError struct {
 	StatusCode             int
 	ResponseBody           []byte      // 上游响应体，用于错误透传规则匹配
 	ResponseHeaders        http.Header // 上游响应头，用于透传 cf-ray/cf-mitigated/content-type 等诊断信息
+	UpstreamRequestID      string      // 上游请求 ID（x-request-id，缺省时回退 cf-ray），用于跨团队排障关联
 	ForceCacheBilling      bool        // Antigravity 粘性会话切换时设为 true
 	RetryableOnSameAccount bool        // 临时性错误（如 Google 间歇性 400、空响应），应在同一账号上重试 N 次再切换
 }
@@ -642,14 +636,21 @@ type GatewayService struct {
 	modelsListCacheTTL    time.Duration
 	settingService        *SettingService
 	responseHeaderFilter  *responseheaders.CompiledHeaderFilter
-	debugModelRouting     atomic.Bool
-	debugClaudeMimic      atomic.Bool
+	responseRedactor      *responseRedactor
+	forwardHeaders        *forwardHeaderPolicy
 	channelService        *ChannelService
 	resolver              *ModelPricingResolver
 	debugGatewayBodyFile  atomic.Pointer[os.File] // non-nil when SUB2API_DEBUG_GATEWAY_BODY is set
 	tlsFPProfileService   *TLSFingerprintProfileService
 	balanceNotifyService  *BalanceNotifyService
 	userPlatformQuotaRepo UserPlatformQuotaRepository
+	routingStats          *openAIAccountRuntimeStats // 加权选号的账号近期错误率
+	routingLatency        *accountLatencyTracker     // 加权选号的账号上游延迟
+	ttftStats             *TTFTStats                 // 按模型的首 token 延迟分位数
+	accountHealth         *AccountHealthProbeService // 主动健康探测结果（可选）
+	upstreamRateLimits    *UpstreamRateLimitTracker  // 上游限流响应头报告的账号余量（可选）
+	serverErrors          *AccountServerErrorTracker // 持续上游 5xx 的账号降权（可选）
+	usageRecordRetry      *UsageRecordRetryService   // 用量记录失败后的延迟重试队列（可选）
 }
 
 // NewGatewayService creates a new GatewayService
@@ -711,12 +712,17 @@ func NewGatewayService(
 		settingService:        settingService,
 		modelsListCache:       gocache.New(modelsListTTL, time.Minute),
 		modelsListCacheTTL:    modelsListTTL,
-		responseHeaderFilter:  compileResponseHeaderFilter(cfg),
+		responseHeaderFilter:  compileResponseHeaderFilterForPlatform(cfg, PlatformAnthropic),
+		responseRedactor:      compileResponseRedactor(cfg, PlatformAnthropic),
+		forwardHeaders:        compileForwardHeaderPolicy(cfg),
 		tlsFPProfileService:   tlsFPProfileService,
 		channelService:        channelService,
 		resolver:              resolver,
 		balanceNotifyService:  balanceNotifyService,
 		userPlatformQuotaRepo: userPlatformQuotaRepo,
+		routingStats:          newOpenAIAccountRuntimeStats(),
+		routingLatency:        newAccountLatencyTracker(),
+		ttftStats:             newTTFTStats(cfg),
 	}
 	svc.userGroupRateResolver = newUserGroupRateResolver(
 		userGroupRateRepo,
@@ -725,8 +731,7 @@ func NewGatewayService(
 		&svc.userGroupRateSF,
 		"service.gateway",
 	)
-	svc.debugModelRouting.Store(parseDebugEnvBool(os.Getenv("SUB2API_DEBUG_MODEL_ROUTING")))
-	svc.debugClaudeMimic.Store(parseDebugEnvBool(os.Getenv("SUB2API_DEBUG_CLAUDE_MIMIC")))
+	registerDebugLogComponents(cfg)
 	if path := strings.TrimSpace(os.Getenv(debugGatewayBodyEnv)); path != "" {
 		svc.initDebugGatewayBodyFile(path)
 	}
@@ -739,6 +744,16 @@ func (s *GatewayService) GenerateSessionHash(parsed *ParsedRequest) string {
 		return ""
 	}
 
+	// 0. 客户端显式指定的会话亲和键（X-Session-Affinity）
+	if parsed.SessionAffinityKey != "" {
+		hash := s.hashContent(parsed.SessionAffinityKey)
+		slog.Info("sticky.hash_source",
+			"source", "session_affinity_header",
+			"hash", hash,
+		)
+		return hash
+	}
+
 	// 1. 最高优先级：从 metadata.user_id 提取 session_xxx
 	if parsed.MetadataUserID != "" {
 		uid := ParseMetadataUserID(parsed.MetadataUserID)
@@ -800,12 +815,21 @@ func (s *GatewayService) GenerateSessionHash(parsed *ParsedRequest) string {
 	return ""
 }
 
-// BindStickySession sets session -> account binding with standard TTL.
+// stickySessionTTLFromRequest 返回本次请求的粘性会话 TTL：
+// 客户端通过 X-Session-Affinity-TTL 指定时使用该值（handler 层已按配置裁剪），否则使用默认 stickySessionTTL。
+func stickySessionTTLFromRequest(ctx context.Context) time.Duration {
+	if ttl, ok := StickySessionTTLFromContext(ctx); ok {
+		return ttl
+	}
+	return stickySessionTTL
+}
+
+// BindStickySession sets session -> account binding with the request TTL (default stickySessionTTL).
 func (s *GatewayService) BindStickySession(ctx context.Context, groupID *int64, sessionHash string, accountID int64) error {
 	if sessionHash == "" || accountID <= 0 || s.cache == nil {
 		return nil
 	}
-	return s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, accountID, stickySessionTTL)
+	return s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, accountID, stickySessionTTLFromRequest(ctx))
 }
 
 // GetCachedSessionAccountID retrieves the account ID bound to a sticky session.
@@ -1744,6 +1768,10 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	if err != nil {
 		return nil, err
 	}
+	// 请求携带账号标签选择器时，先收窄候选池再进入路由/粘性/负载逻辑
+	if accounts, err = filterAccountsByLabelSelector(ctx, accounts); err != nil {
+		return nil, err
+	}
 	if len(accounts) == 0 {
 		return nil, ErrNoAvailableAccounts
 	}
@@ -1789,7 +1817,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	if len(routingAccountIDs) > 0 && s.concurrencyService != nil {
 		// 1. 过滤出路由列表中可调度的账号
 		var routingCandidates []*Account
-		var filteredExcluded, filteredMissing, filteredUnsched, filteredPlatform, filteredModelScope, filteredModelMapping, filteredWindowCost int
+		var filteredExcluded, filteredMissing, filteredUnsched, filteredOutsideSchedule, filteredPlatform, filteredModelScope, filteredModelMapping, filteredWindowCost int
 		var modelScopeSkippedIDs []int64 // 记录因模型限流被跳过的账号 ID
 		for _, routingAccountID := range routingAccountIDs {
 			if isExcluded(routingAccountID) {
@@ -1798,9 +1826,12 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			}
 			account, ok := accountByID[routingAccountID]
 			if !ok || !s.isAccountSchedulableForSelection(account) {
-				if !ok {
+				switch {
+				case !ok:
 					filteredMissing++
-				} else {
+				case accountScheduleExclusionReason(account, time.Now()) != "":
+					filteredOutsideSchedule++
+				default:
 					filteredUnsched++
 				}
 				continue
@@ -1835,9 +1866,9 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		}
 
 		if s.debugModelRoutingEnabled() {
-			logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed candidates: group_id=%v model=%s routed=%d candidates=%d filtered(excluded=%d missing=%d unsched=%d platform=%d model_scope=%d model_mapping=%d window_cost=%d)",
+			logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed candidates: group_id=%v model=%s routed=%d candidates=%d filtered(excluded=%d missing=%d unsched=%d outside_schedule=%d platform=%d model_scope=%d model_mapping=%d window_cost=%d)",
 				derefGroupID(groupID), requestedModel, len(routingAccountIDs), len(routingCandidates),
-				filteredExcluded, filteredMissing, filteredUnsched, filteredPlatform, filteredModelScope, filteredModelMapping, filteredWindowCost)
+				filteredExcluded, filteredMissing, filteredUnsched, filteredOutsideSchedule, filteredPlatform, filteredModelScope, filteredModelMapping, filteredWindowCost)
 			if len(modelScopeSkippedIDs) > 0 {
 				logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] model_rate_limited accounts skipped: group_id=%v model=%s account_ids=%v",
 					derefGroupID(groupID), requestedModel, modelScopeSkippedIDs)
@@ -1991,7 +2022,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 							continue
 						}
 						if sessionHash != "" && s.cache != nil {
-							_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, item.account.ID, stickySessionTTL)
+							_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, item.account.ID, stickySessionTTLFromRequest(ctx))
 						}
 						if s.debugModelRoutingEnabled() {
 							logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed select: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), item.account.ID)
@@ -2082,7 +2113,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 								"result", "slot_acquired",
 							)
 							if s.cache != nil {
-								_ = s.cache.RefreshSessionTTL(ctx, derefGroupID(groupID), sessionHash, stickySessionTTL)
+								_ = s.cache.RefreshSessionTTL(ctx, derefGroupID(groupID), sessionHash, stickySessionTTLFromRequest(ctx))
 							}
 							return s.newSelectionResult(ctx, account, true, result.ReleaseFunc, nil)
 						}
@@ -2158,6 +2189,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		// re-check schedulability here so recently rate-limited/overloaded accounts
 		// are not selected again before the bucket is rebuilt.
 		if !s.isAccountSchedulableForSelection(acc) {
+			logAccountScheduleExclusion(ctx, acc)
 			continue
 		}
 		if !s.isAccountAllowedForPlatform(acc, platform, useMixed) {
@@ -2218,7 +2250,11 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			}
 		}
 
+		routingCfg := s.routingConfig()
 		// 分层过滤选择：优先级 →（可选）最早重置 → 负载率 → LRU
+		// 开启加权打分时：优先级 →（可选）最早重置 → 加权得分最高（同分取最小账号 ID）
+		// 满载账号（负载率 >= 100）已在上方剔除；槽位获取失败的账号移出后重新分层，
+		// 因此只有高优先级账号全部满载时才会溢出到下一优先级
 		for len(available) > 0 {
 			// 1. 取优先级最小的集合
 			candidates := filterByMinPriority(available)
@@ -2226,10 +2262,27 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			if cfg.PreferSoonestReset {
 				candidates = filterBySoonestReset(candidates)
 			}
-			// 3. 取负载率最低的集合
-			candidates = filterByMinLoadRate(candidates)
-			// 4. LRU 选择最久未用的账号
-			selected := selectByLRU(candidates, preferOAuth)
+			// 上游限流余量不足的账号仅在同优先级没有其他候选时使用
+			candidates = filterBySoftPredicate(candidates, s.upstreamRateLimits.IsAccountConstrained)
+			// 持续返回 5xx 的账号降权期间同样仅作为最后选择
+			candidates = filterBySoftPredicate(candidates, s.serverErrors.IsAccountPenalized)
+			var selected *accountWithLoad
+			var selectedScore *AccountRoutingScore
+			if routingCfg.WeightedScoringEnabled {
+				scored := scoreAccountsForRouting(candidates, routingCfg, s.routingSignals(routingCfg))
+				logRoutingScores(groupID, requestedModel, scored)
+				if len(scored) > 0 {
+					selected = &scored[0].accountWithLoad
+					selectedScore = &scored[0].score
+				}
+			} else {
+				// 主动探测判定不健康的账号仅在同优先级没有其他候选时使用
+				candidates = filterBySoftPredicate(candidates, s.accountHealth.IsAccountUnhealthy)
+				// 3. 取负载率最低的集合
+				candidates = filterByMinLoadRate(candidates)
+				// 4. LRU 选择最久未用的账号
+				selected = selectByLRU(candidates, preferOAuth)
+			}
 			if selected == nil {
 				break
 			}
@@ -2241,9 +2294,13 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 					result.ReleaseFunc() // 释放槽位，继续尝试下一个账号
 				} else {
 					if sessionHash != "" && s.cache != nil {
-						_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.account.ID, stickySessionTTL)
+						_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.account.ID, stickySessionTTLFromRequest(ctx))
 					}
-					return s.newSelectionResult(ctx, selected.account, true, result.ReleaseFunc, nil)
+					selection, err := s.newSelectionResult(ctx, selected.account, true, result.ReleaseFunc, nil)
+					if selection != nil {
+						selection.RoutingScore = selectedScore
+					}
+					return selection, err
 				}
 			}
 
@@ -2289,7 +2346,7 @@ func (s *GatewayService) tryAcquireByLegacyOrder(ctx context.Context, candidates
 				continue
 			}
 			if sessionHash != "" && s.cache != nil {
-				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, stickySessionTTL)
+				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, stickySessionTTLFromRequest(ctx))
 			}
 			selection, err := s.newSelectionResult(ctx, acc, true, result.ReleaseFunc, nil)
 			if err != nil {
@@ -2302,6 +2359,13 @@ func (s *GatewayService) tryAcquireByLegacyOrder(ctx context.Context, candidates
 	return nil, false, nil
 }
 
+func (s *GatewayService) routingConfig() config.GatewayRoutingConfig {
+	if s.cfg != nil {
+		return s.cfg.Gateway.Routing
+	}
+	return config.GatewayRoutingConfig{}
+}
+
 func (s *GatewayService) schedulingConfig() config.GatewaySchedulingConfig {
 	if s.cfg != nil {
 		return s.cfg.Gateway.Scheduling
@@ -2913,11 +2977,24 @@ func (s *GatewayService) checkAndRegisterSession(ctx context.Context, account *A
 	return allowed
 }
 
+// getSchedulableAccount 读取粘性会话绑定的账号；不满足请求标签选择器时视为不可用，避免粘性绕过标签过滤。
 func (s *GatewayService) getSchedulableAccount(ctx context.Context, accountID int64) (*Account, error) {
+	var (
+		account *Account
+		err     error
+	)
 	if s.schedulerSnapshot != nil {
-		return s.schedulerSnapshot.GetAccount(ctx, accountID)
+		account, err = s.schedulerSnapshot.GetAccount(ctx, accountID)
+	} else {
+		account, err = s.accountRepo.GetByID(ctx, accountID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if account != nil && !account.MatchesLabelSelector(AccountLabelSelectorFromContext(ctx)) {
+		return nil, ErrNoAccountsMatchingLabels
 	}
-	return s.accountRepo.GetByID(ctx, accountID)
+	return account, nil
 }
 
 func (s *GatewayService) hydrateSelectedAccount(ctx context.Context, account *Account) (*Account, error) {
@@ -2967,6 +3044,20 @@ func filterByMinPriority(accounts []accountWithLoad) []accountWithLoad {
 	return result
 }
 
+// filterBySoftPredicate 剔除命中 exclude 的账号（软过滤）；全部命中时原样返回，避免无号可用
+func filterBySoftPredicate(accounts []accountWithLoad, exclude func(accountID int64) bool) []accountWithLoad {
+	remaining := make([]accountWithLoad, 0, len(accounts))
+	for _, acc := range accounts {
+		if !exclude(acc.account.ID) {
+			remaining = append(remaining, acc)
+		}
+	}
+	if len(remaining) == 0 {
+		return accounts
+	}
+	return remaining
+}
+
 // filterByMinLoadRate 过滤出负载率最低的账号集合
 func filterByMinLoadRate(accounts []accountWithLoad) []accountWithLoad {
 	if len(accounts) == 0 {
@@ -3305,6 +3396,9 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 		if err != nil {
 			return nil, fmt.Errorf("query accounts failed: %w", err)
 		}
+		if accounts, err = filterAccountsByLabelSelector(ctx, accounts); err != nil {
+			return nil, err
+		}
 		accountsLoaded = true
 
 		// 提前预取窗口费用+RPM 计数，确保 routing 段内的调度检查调用能命中缓存
@@ -3379,7 +3473,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 
 		if selected != nil {
 			if sessionHash != "" && s.cache != nil {
-				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTLFromRequest(ctx)); err != nil {
 					logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 				}
 			}
@@ -3422,6 +3516,9 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 		if err != nil {
 			return nil, fmt.Errorf("query accounts failed: %w", err)
 		}
+		if accounts, err = filterAccountsByLabelSelector(ctx, accounts); err != nil {
+			return nil, err
+		}
 	}
 
 	// 批量预取窗口费用+RPM 计数，避免逐个账号查询（N+1）
@@ -3501,7 +3598,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 
 	// 4. 建立粘性绑定
 	if sessionHash != "" && s.cache != nil {
-		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTLFromRequest(ctx)); err != nil {
 			logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 		}
 	}
@@ -3561,6 +3658,9 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 		if err != nil {
 			return nil, fmt.Errorf("query accounts failed: %w", err)
 		}
+		if accounts, err = filterAccountsByLabelSelector(ctx, accounts); err != nil {
+			return nil, err
+		}
 		accountsLoaded = true
 
 		// 提前预取窗口费用+RPM 计数，确保 routing 段内的调度检查调用能命中缓存
@@ -3639,7 +3739,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 
 		if selected != nil {
 			if sessionHash != "" && s.cache != nil {
-				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTLFromRequest(ctx)); err != nil {
 					logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 				}
 			}
@@ -3680,6 +3780,9 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 		if err != nil {
 			return nil, fmt.Errorf("query accounts failed: %w", err)
 		}
+		if accounts, err = filterAccountsByLabelSelector(ctx, accounts); err != nil {
+			return nil, err
+		}
 	}
 
 	// 批量预取窗口费用+RPM 计数，避免逐个账号查询（N+1）
@@ -3762,7 +3865,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 
 	// 4. 建立粘性绑定
 	if sessionHash != "" && s.cache != nil {
-		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTLFromRequest(ctx)); err != nil {
 			logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 		}
 	}
@@ -4850,6 +4953,14 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	reqStream := parsed.Stream
 	originalModel := reqModel
 
+	// 按模型族解析上游超时分级（连接 / 首 token / 总超时）
+	timeoutWatchdog := beginUpstreamTimeoutTier(ctx, c, s.cfg, account.Platform, originalModel)
+	if !reqStream {
+		// 非流式请求没有首 token 事件，仅受连接与总超时约束
+		timeoutWatchdog.disarm(UpstreamTimeoutPhaseFirstToken)
+	}
+	defer timeoutWatchdog.Stop()
+
 	// === DEBUG: 打印客户端原始请求（headers + body 摘要）===
 	if c != nil {
 		s.debugLogGatewaySnapshot("CLIENT_ORIGINAL", c.Request.Header, body, map[string]string{
@@ -4870,6 +4981,13 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	isClaudeCode := IsClaudeCodeClient(ctx) || isClaudeCodeClient(c.GetHeader("User-Agent"), parsed.MetadataUserID)
 	shouldMimicClaudeCode := account.IsOAuth() && !isClaudeCode
 
+	// 分组开启 prompt_cache_inject 时，为未自带 cache_control 的请求补上 system / tools[-1] 断点
+	if injected, changed := s.injectPromptCacheIfEnabled(ctx, account, body); changed {
+		if err := replaceBody(injected); err != nil {
+			return nil, err
+		}
+	}
+
 	if shouldMimicClaudeCode {
 		// 与 Parrot 对齐：OAuth 账号无条件重写 system（即使客户端已发了 Claude Code
 		// 风格的 system prompt）。原因：第三方工具（opencode 等）会发 "You are Claude
@@ -5036,7 +5154,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	for attempt := 1; attempt <= maxRetryAttempts; attempt++ {
 		// 构建上游请求（每次重试需要重新构建，因为请求体需要重新读取）
 		upstreamCtx, releaseUpstreamCtx := detachStreamUpstreamContext(ctx, reqStream)
-		upstreamReq, wireBody, err := s.buildUpstreamRequest(upstreamCtx, c, account, body, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
+		upstreamReq, wireBody, err := s.buildUpstreamRequest(timeoutWatchdog.Bind(upstreamCtx), c, account, body, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
 		releaseUpstreamCtx()
 		if err != nil {
 			return nil, err
@@ -5045,11 +5163,19 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		lastWireBody = wireBody
 
 		// 发送请求
-		resp, err = s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, tlsProfile)
+		upstreamStart := time.Now()
+		resp, err = doUpstreamWithTransientRetry(c, s.cfg, account, upstreamReq, func(req *http.Request) (*http.Response, error) {
+			return s.httpUpstream.DoWithTLS(req, proxyURL, account.ID, account.Concurrency, tlsProfile)
+		})
+		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 		if err != nil {
 			if resp != nil && resp.Body != nil {
 				_ = resp.Body.Close()
 			}
+			// 超时分级在收到响应头前触发：尚未向客户端写出任何内容，交由 handler 换号重试
+			if timeoutErr := timeoutWatchdog.Err(); timeoutErr != nil {
+				return nil, upstreamTimeoutTierFailover(c, account, safeUpstreamURL(upstreamReq.URL.String()), timeoutErr, claudeUpstreamTimeoutFailoverBody)
+			}
 			// Ensure the client receives an error response (handlers assume Forward writes on non-failover errors).
 			safeErr := sanitizeUpstreamErrorMessage(err.Error())
 			setOpsUpstreamError(c, 0, safeErr, "")
@@ -5071,6 +5197,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 			})
 			return nil, fmt.Errorf("upstream request failed: %s", safeErr)
 		}
+		timeoutWatchdog.HeadersReceived()
 
 		// 优先检测thinking block签名错误（400）并重试一次
 		if resp.StatusCode == 400 {
@@ -5120,7 +5247,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 
 					filteredBody := FilterThinkingBlocksForRetry(body, reqModel)
 					retryCtx, releaseRetryCtx := detachStreamUpstreamContext(ctx, reqStream)
-					retryReq, retryWireBody, buildErr := s.buildUpstreamRequest(retryCtx, c, account, filteredBody, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
+					retryReq, retryWireBody, buildErr := s.buildUpstreamRequest(timeoutWatchdog.Bind(retryCtx), c, account, filteredBody, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
 					releaseRetryCtx()
 					if buildErr == nil {
 						retryResp, retryErr := s.httpUpstream.DoWithTLS(retryReq, proxyURL, account.ID, account.Concurrency, tlsProfile)
@@ -5161,7 +5288,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 									logger.LegacyPrintf("service.gateway", "Account %d: signature retry still failing and looks tool-related, retrying with tool blocks downgraded", account.ID)
 									filteredBody2 := FilterSignatureSensitiveBlocksForRetry(body, reqModel)
 									retryCtx2, releaseRetryCtx2 := detachStreamUpstreamContext(ctx, reqStream)
-									retryReq2, retryWireBody2, buildErr2 := s.buildUpstreamRequest(retryCtx2, c, account, filteredBody2, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
+									retryReq2, retryWireBody2, buildErr2 := s.buildUpstreamRequest(timeoutWatchdog.Bind(retryCtx2), c, account, filteredBody2, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
 									releaseRetryCtx2()
 									if buildErr2 == nil {
 										retryResp2, retryErr2 := s.httpUpstream.DoWithTLS(retryReq2, proxyURL, account.ID, account.Concurrency, tlsProfile)
@@ -5240,7 +5367,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 					if applied && time.Since(retryStart) < maxRetryElapsed {
 						logger.LegacyPrintf("service.gateway", "Account %d: detected budget_tokens constraint error, retrying with rectified budget (budget_tokens=%d, max_tokens=%d)", account.ID, BudgetRectifyBudgetTokens, BudgetRectifyMaxTokens)
 						budgetRetryCtx, releaseBudgetRetryCtx := detachStreamUpstreamContext(ctx, reqStream)
-						budgetRetryReq, budgetWireBody, buildErr := s.buildUpstreamRequest(budgetRetryCtx, c, account, rectifiedBody, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
+						budgetRetryReq, budgetWireBody, buildErr := s.buildUpstreamRequest(timeoutWatchdog.Bind(budgetRetryCtx), c, account, rectifiedBody, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
 						releaseBudgetRetryCtx()
 						if buildErr == nil {
 							budgetRetryResp, retryErr := s.httpUpstream.DoWithTLS(budgetRetryReq, proxyURL, account.ID, account.Concurrency, tlsProfile)
@@ -5318,9 +5445,9 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 
 		// 不需要重试（成功或不可重试的错误），跳出循环
 		// DEBUG: 输出响应 headers（用于检测 rate limit 信息）
-		if account.Platform == PlatformGemini && resp.StatusCode < 400 && s.cfg != nil && s.cfg.Gateway.GeminiDebugResponseHeaders {
+		if account.Platform == PlatformGemini && resp.StatusCode < 400 && s.debugGeminiResponseHeadersEnabled() {
 			logger.LegacyPrintf("service.gateway", "[DEBUG] Gemini API Response Headers for account %d:", account.ID)
-			for k, v := range resp.Header {
+			for k, v := range logredact.RedactHeaders(resp.Header) {
 				logger.LegacyPrintf("service.gateway", "[DEBUG]   %s: %v", k, v)
 			}
 		}
@@ -5330,6 +5457,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		return nil, errors.New("upstream request failed: empty response")
 	}
 	defer func() { _ = resp.Body.Close() }()
+	// 上游请求 ID 在成功与错误响应上均按配置回传（X-Upstream-Request-Id），并随 failover 错误带回 handler
+	upstreamRequestID := exposeUpstreamRequestID(c, s.cfg, resp.Header, nil)
 
 	// 处理重试耗尽的情况
 	if resp.StatusCode >= 400 && s.shouldRetryUpstreamError(account, resp.StatusCode) {
@@ -5361,6 +5490,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 			return nil, &UpstreamFailoverError{
 				StatusCode:             resp.StatusCode,
 				ResponseBody:           respBody,
+				UpstreamRequestID:      exposeUpstreamRequestID(c, s.cfg, resp.Header, respBody),
 				RetryableOnSameAccount: account.IsPoolMode() && account.IsPoolModeRetryableStatus(resp.StatusCode),
 			}
 		}
@@ -5395,6 +5525,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		return nil, &UpstreamFailoverError{
 			StatusCode:             resp.StatusCode,
 			ResponseBody:           respBody,
+			UpstreamRequestID:      exposeUpstreamRequestID(c, s.cfg, resp.Header, respBody),
 			RetryableOnSameAccount: account.IsPoolMode() && account.IsPoolModeRetryableStatus(resp.StatusCode),
 		}
 	}
@@ -5441,7 +5572,11 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 					logger.LegacyPrintf("service.gateway", "Account %d: 400 error, attempting failover", account.ID)
 				}
 				s.handleFailoverSideEffects(ctx, resp, account, reqModel)
-				return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody}
+				return nil, &UpstreamFailoverError{
+					StatusCode:        resp.StatusCode,
+					ResponseBody:      respBody,
+					UpstreamRequestID: exposeUpstreamRequestID(c, s.cfg, resp.Header, respBody),
+				}
 			}
 		}
 		return s.handleErrorResponse(ctx, resp, c, account, reqModel)
@@ -5505,8 +5640,9 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 				)
 
 				return nil, &UpstreamFailoverError{
-					StatusCode:   403,
-					ResponseBody: body,
+					StatusCode:        403,
+					ResponseBody:      body,
+					UpstreamRequestID: upstreamRequestID,
 				}
 			}
 			return nil, err
@@ -5522,14 +5658,15 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	}
 
 	return &ForwardResult{
-		RequestID:        resp.Header.Get("x-request-id"),
-		Usage:            *usage,
-		Model:            originalModel, // 使用原始模型用于计费和日志
-		UpstreamModel:    mappedModel,
-		Stream:           reqStream,
-		Duration:         time.Since(startTime),
-		FirstTokenMs:     firstTokenMs,
-		ClientDisconnect: clientDisconnect,
+		RequestID:         resp.Header.Get("x-request-id"),
+		Usage:             *usage,
+		Model:             originalModel, // 使用原始模型用于计费和日志
+		UpstreamModel:     mappedModel,
+		Stream:            reqStream,
+		Duration:          time.Since(startTime),
+		FirstTokenMs:      firstTokenMs,
+		ClientDisconnect:  clientDisconnect,
+		UpstreamRateLimit: s.upstreamRateLimits.Observe(account, resp.Header),
 	}, nil
 }
 
@@ -5612,7 +5749,11 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthroughWithInput(
 			input.Body = input.Parsed.Body.Bytes()
 		}
 
-		resp, err = s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+		upstreamStart := time.Now()
+		resp, err = doUpstreamWithTransientRetry(c, s.cfg, account, upstreamReq, func(req *http.Request) (*http.Response, error) {
+			return s.httpUpstream.DoWithTLS(req, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+		})
+		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 		if err != nil {
 			if resp != nil && resp.Body != nil {
 				_ = resp.Body.Close()
@@ -5691,6 +5832,8 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthroughWithInput(
 		return nil, errors.New("upstream request failed: empty response")
 	}
 	defer func() { _ = resp.Body.Close() }()
+	// 上游请求 ID 在成功与错误响应上均按配置回传（X-Upstream-Request-Id）
+	exposeUpstreamRequestID(c, s.cfg, resp.Header, nil)
 
 	if resp.StatusCode >= 400 && s.shouldRetryUpstreamError(account, resp.StatusCode) {
 		if s.shouldFailoverUpstreamError(resp.StatusCode) {
@@ -5721,6 +5864,7 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthroughWithInput(
 			return nil, &UpstreamFailoverError{
 				StatusCode:             resp.StatusCode,
 				ResponseBody:           respBody,
+				UpstreamRequestID:      exposeUpstreamRequestID(c, s.cfg, resp.Header, respBody),
 				RetryableOnSameAccount: account.IsPoolMode() && account.IsPoolModeRetryableStatus(resp.StatusCode),
 			}
 		}
@@ -5755,6 +5899,7 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthroughWithInput(
 		return nil, &UpstreamFailoverError{
 			StatusCode:             resp.StatusCode,
 			ResponseBody:           respBody,
+			UpstreamRequestID:      exposeUpstreamRequestID(c, s.cfg, resp.Header, respBody),
 			RetryableOnSameAccount: account.IsPoolMode() && account.IsPoolModeRetryableStatus(resp.StatusCode),
 		}
 	}
@@ -5785,14 +5930,15 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthroughWithInput(
 	}
 
 	return &ForwardResult{
-		RequestID:        resp.Header.Get("x-request-id"),
-		Usage:            *usage,
-		Model:            input.OriginalModel,
-		UpstreamModel:    input.RequestModel,
-		Stream:           input.RequestStream,
-		Duration:         time.Since(input.StartTime),
-		FirstTokenMs:     firstTokenMs,
-		ClientDisconnect: clientDisconnect,
+		RequestID:         resp.Header.Get("x-request-id"),
+		Usage:             *usage,
+		Model:             input.OriginalModel,
+		UpstreamModel:     input.RequestModel,
+		Stream:            input.RequestStream,
+		Duration:          time.Since(input.StartTime),
+		FirstTokenMs:      firstTokenMs,
+		ClientDisconnect:  clientDisconnect,
+		UpstreamRateLimit: s.upstreamRateLimits.Observe(account, resp.Header),
 	}, nil
 }
 
@@ -5832,7 +5978,7 @@ func (s *GatewayService) buildUpstreamRequestAnthropicAPIKeyPassthrough(
 	if c != nil && c.Request != nil {
 		for key, values := range c.Request.Header {
 			lowerKey := strings.ToLower(strings.TrimSpace(key))
-			if !allowedHeaders[lowerKey] {
+			if !s.forwardHeaders.allows(lowerKey) {
 				continue
 			}
 			wireKey := resolveWireCasing(key)
@@ -5900,6 +6046,7 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 	clientDisconnected := false
 	sawTerminalEvent := false
 
+	applyStreamIdleTimeout(s.cfg, resp)
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
@@ -5957,6 +6104,10 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 	if s.cfg != nil && s.cfg.Gateway.StreamKeepaliveInterval > 0 {
 		keepaliveInterval = time.Duration(s.cfg.Gateway.StreamKeepaliveInterval) * time.Second
 	}
+	keepalivePing := streamKeepalivePing(c, anthropicStreamKeepalivePing)
+	if keepalivePing == "" {
+		keepaliveInterval = 0
+	}
 	var keepaliveTicker *time.Ticker
 	if keepaliveInterval > 0 {
 		keepaliveTicker = time.NewTicker(keepaliveInterval)
@@ -6002,6 +6153,14 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] SSE line too long: account=%d max_size=%d error=%v", account.ID, maxLineSize, ev.err)
 					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, ev.err
 				}
+				// 透传模式不注入自定义事件，空闲超时与数据间隔超时一致：只标记账号并结束转发
+				if idleErr, ok := asStreamIdleTimeout(ev.err); ok {
+					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Stream idle timeout: account=%d model=%s idle=%s", account.ID, model, idleErr.Timeout)
+					if s.rateLimitService != nil {
+						s.rateLimitService.HandleStreamTimeout(ctx, account, model)
+					}
+					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, idleErr
+				}
 				return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, fmt.Errorf("stream read error: %w", ev.err)
 			}
 
@@ -6024,7 +6183,7 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 			}
 
 			if !clientDisconnected {
-				restored := string(reverseToolNamesIfPresent(c, []byte(line)))
+				restored := s.responseRedactor.RedactSSE(string(reverseToolNamesIfPresent(c, []byte(line))))
 				if _, err := io.WriteString(w, restored); err != nil {
 					clientDisconnected = true
 					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during streaming, continue draining upstream for usage: account=%d", account.ID)
@@ -6062,7 +6221,7 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 			if time.Since(lastDataAt) < keepaliveInterval {
 				continue
 			}
-			if _, err := fmt.Fprint(w, "event: ping\ndata: {\"type\": \"ping\"}\n\n"); err != nil {
+			if _, err := fmt.Fprint(w, keepalivePing); err != nil {
 				clientDisconnected = true
 				logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during keepalive ping, continue draining upstream for usage: account=%d", account.ID)
 				continue
@@ -6233,6 +6392,7 @@ func (s *GatewayService) invalidNonStreamingJSONFailoverError(
 		StatusCode:             statusCode,
 		ResponseBody:           body,
 		ResponseHeaders:        resp.Header,
+		UpstreamRequestID:      httputil.ExtractUpstreamRequestID(resp.Header, body),
 		RetryableOnSameAccount: retryableOnSameAccount,
 	}
 }
@@ -6266,7 +6426,7 @@ func (s *GatewayService) handleNonStreamingResponseAnthropicAPIKeyPassthrough(
 	if contentType == "" {
 		contentType = "application/json"
 	}
-	body = reverseToolNamesIfPresent(c, body)
+	body = s.responseRedactor.RedactBody(reverseToolNamesIfPresent(c, body))
 	c.Data(resp.StatusCode, contentType, body)
 	return usage, nil
 }
@@ -6465,7 +6625,11 @@ func (s *GatewayService) executeBedrockUpstream(
 			return nil, err
 		}
 
-		resp, err = s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, nil)
+		upstreamStart := time.Now()
+		resp, err = doUpstreamWithTransientRetry(c, s.cfg, account, upstreamReq, func(req *http.Request) (*http.Response, error) {
+			return s.httpUpstream.DoWithTLS(req, proxyURL, account.ID, account.Concurrency, nil)
+		})
+		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 		if err != nil {
 			if resp != nil && resp.Body != nil {
 				_ = resp.Body.Close()
@@ -6792,7 +6956,7 @@ func (s *GatewayService) buildUpstreamRequest(ctx context.Context, c *gin.Contex
 	if tokenType != "oauth" || !mimicClaudeCode {
 		for key, values := range clientHeaders {
 			lowerKey := strings.ToLower(key)
-			if allowedHeaders[lowerKey] {
+			if s.forwardHeaders.allows(lowerKey) {
 				wireKey := resolveWireCasing(key)
 				for _, v := range values {
 					addHeaderRaw(req.Header, wireKey, v)
@@ -6840,6 +7004,8 @@ func (s *GatewayService) buildUpstreamRequest(ctx context.Context, c *gin.Contex
 		}
 	}
 
+	applyUpstreamTraceIDHeader(req, account)
+
 	// === DEBUG: 打印上游转发请求（headers + body 摘要），与 CLIENT_ORIGINAL 对比 ===
 	s.debugLogGatewaySnapshot("UPSTREAM_FORWARD", req.Header, body, map[string]string{
 		"url":                 req.URL.String(),
@@ -6952,7 +7118,7 @@ func (s *GatewayService) buildUpstreamRequestAnthropicVertex(
 	if c != nil && c.Request != nil {
 		for key, values := range c.Request.Header {
 			lowerKey := strings.ToLower(strings.TrimSpace(key))
-			if !allowedHeaders[lowerKey] || lowerKey == "anthropic-version" {
+			if !s.forwardHeaders.allows(lowerKey) || lowerKey == "anthropic-version" {
 				continue
 			}
 			wireKey := resolveWireCasing(key)
@@ -7850,8 +8016,9 @@ func (s *GatewayService) handleErrorResponse(ctx context.Context, resp *http.Res
 			shouldDisable = s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, body)
 		}
 	}
+	upstreamRequestID := exposeUpstreamRequestID(c, s.cfg, resp.Header, body)
 	if shouldDisable {
-		return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: body}
+		return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: body, UpstreamRequestID: upstreamRequestID}
 	}
 
 	MarkResponseCommitted(c)
@@ -8108,6 +8275,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 
 	usage := &ClaudeUsage{}
 	var firstTokenMs *int
+	applyStreamIdleTimeout(s.cfg, resp)
 	scanner := bufio.NewScanner(resp.Body)
 	// 设置更大的buffer以处理长行
 	maxLineSize := defaultMaxLineSize
@@ -8169,6 +8337,10 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 	if s.cfg != nil && s.cfg.Gateway.StreamKeepaliveInterval > 0 {
 		keepaliveInterval = time.Duration(s.cfg.Gateway.StreamKeepaliveInterval) * time.Second
 	}
+	keepalivePing := streamKeepalivePing(c, anthropicStreamKeepalivePing)
+	if keepalivePing == "" {
+		keepaliveInterval = 0
+	}
 	var keepaliveTicker *time.Ticker
 	if keepaliveInterval > 0 {
 		keepaliveTicker = time.NewTicker(keepaliveInterval)
@@ -8353,6 +8525,28 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 				if sawTerminalEvent {
 					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs, clientDisconnect: clientDisconnected}, nil
 				}
+				// 超时分级触发导致上游读取被取消：向客户端发送标准 error 事件，避免静默挂起
+				if timeoutErr := upstreamTimeoutErrFromGin(c); timeoutErr != nil && !clientDisconnected {
+					logger.LegacyPrintf("service.gateway", "Stream upstream timeout: account=%d model=%s err=%v", account.ID, originalModel, timeoutErr)
+					if !c.Writer.Written() {
+						// 尚未向客户端写出任何数据：交给上层切换账号重试
+						return nil, &UpstreamFailoverError{StatusCode: http.StatusGatewayTimeout}
+					}
+					sendErrorEvent("timeout_error", timeoutErr.Error())
+					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, timeoutErr
+				}
+				// 上游连接未断但长时间不发送数据：关闭上游，按流超时处理
+				if idleErr, ok := asStreamIdleTimeout(ev.err); ok && !clientDisconnected {
+					logger.LegacyPrintf("service.gateway", "Stream idle timeout: account=%d model=%s idle=%s", account.ID, originalModel, idleErr.Timeout)
+					if s.rateLimitService != nil {
+						s.rateLimitService.HandleStreamTimeout(ctx, account, originalModel)
+					}
+					if !c.Writer.Written() {
+						return nil, &UpstreamFailoverError{StatusCode: http.StatusGatewayTimeout}
+					}
+					sendErrorEvent("stream_timeout", idleErr.Error())
+					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, idleErr
+				}
 				// 检测 context 取消（客户端断开会导致 context 取消，进而影响上游读取）
 				if errors.Is(ev.err, context.Canceled) || errors.Is(ev.err, context.DeadlineExceeded) {
 					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs, clientDisconnect: true}, fmt.Errorf("stream usage incomplete: %w", ev.err)
@@ -8411,8 +8605,8 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 
 				for _, block := range outputBlocks {
 					if !clientDisconnected {
-						restored := reverseToolNamesIfPresent(c, []byte(block))
-						if _, werr := fmt.Fprint(w, string(restored)); werr != nil {
+						restored := s.responseRedactor.RedactSSE(string(reverseToolNamesIfPresent(c, []byte(block))))
+						if _, werr := fmt.Fprint(w, restored); werr != nil {
 							clientDisconnected = true
 							logger.LegacyPrintf("service.gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
 							break
@@ -8424,6 +8618,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 						if firstTokenMs == nil && data != "[DONE]" {
 							ms := int(time.Since(startTime).Milliseconds())
 							firstTokenMs = &ms
+							markUpstreamFirstToken(c)
 						}
 						if usagePatch != nil {
 							mergeSSEUsagePatch(usage, usagePatch)
@@ -8458,9 +8653,9 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 			if time.Since(lastDataAt) < keepaliveInterval {
 				continue
 			}
-			// SSE ping 事件：Anthropic 原生格式，客户端会正确处理，
+			// SSE ping：默认 Anthropic 原生格式，与等待阶段 ping 使用同一请求级格式，
 			// 同时保持连接活跃防止 Cloudflare Tunnel 等代理断开
-			if _, werr := fmt.Fprint(w, "event: ping\ndata: {\"type\": \"ping\"}\n\n"); werr != nil {
+			if _, werr := fmt.Fprint(w, keepalivePing); werr != nil {
 				clientDisconnected = true
 				logger.LegacyPrintf("service.gateway", "Client disconnected during keepalive ping, continuing to drain upstream for billing")
 				continue
@@ -8770,7 +8965,7 @@ func (s *GatewayService) handleNonStreamingResponse(ctx context.Context, resp *h
 		}
 	}
 
-	body = reverseToolNamesIfPresent(c, body)
+	body = s.responseRedactor.RedactBody(reverseToolNamesIfPresent(c, body))
 
 	// 写入响应
 	c.Data(resp.StatusCode, contentType, body)
@@ -8824,6 +9019,7 @@ type RecordUsageInput struct {
 	ForceCacheBilling  bool               // 强制缓存计费：将 input_tokens 转为 cache_read 计费（用于粘性会话切换）
 	APIKeyService      APIKeyQuotaUpdater // 可选：用于更新API Key配额
 	QuotaPlatform      string             // user×platform 配额计量平台：handler 在请求 ctx 内经 QuotaPlatform() 算定后传入（后扣运行在 worker 池 background ctx 上，取不到 ForcePlatform）
+	FallbackFromModel  string             // 分组模型降级前客户端请求的模型；非空时记为 requested_model，计费仍按实际服务模型
 
 	ChannelUsageFields // 渠道映射信息（由 handler 在 Forward 前解析）
 }
@@ -8940,6 +9136,11 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 		}
 	}
 
+	// 分组消费上限累加（legacy 兜底路径同样同步写 Redis，否则 preflight 看不到消费）
+	if cost.ActualCost > 0 && p.APIKey != nil && p.APIKey.Group != nil {
+		deps.billingCacheService.AddGroupSpend(billingCtx, p.APIKey.Group, cost.ActualCost)
+	}
+
 	// Platform quota 累加（legacy 兜底路径）：仅对 standard（余额）模式生效；订阅模式豁免；仅对有 limit 的用户写
 	//   - HasUserPlatformQuotaLimit 守卫:与正常路径对齐，无 limit 公司跳过
 	//   - 新增 Redis 同步写:enforcement 走 Redis，legacy 路径也必须同步写，否则 preflight 看不到消费
@@ -9106,6 +9307,11 @@ func finalizePostUsageBilling(ctx context.Context, p *postUsageBillingParams, de
 
 	deps.deferredService.ScheduleLastUsedUpdate(p.Account.ID)
 
+	// 分组消费上限累加：两种计费模式均计入，仅对配置了上限的分组写（Redis 同步写，preflight 立即可见）
+	if p.Cost.ActualCost > 0 && p.APIKey != nil && p.APIKey.Group != nil {
+		deps.billingCacheService.AddGroupSpend(ctx, p.APIKey.Group, p.Cost.ActualCost)
+	}
+
 	// Platform quota 累加：仅在 standard（余额）模式生效；订阅模式豁免；仅对有 limit 的用户写
 	// Redis 同步写 + DB 异步持久化（flag=false 降级）或 flusher 异步刷（flag=true）:
 	//   - HasUserPlatformQuotaLimit 守卫:无 limit 的公司跳过,避免无效写入 + 浪费 Redis 容量
@@ -9318,8 +9524,31 @@ type recordUsageOpts struct {
 	LongContextMultiplier float64
 }
 
-// RecordUsage 记录使用量并扣费（或更新订阅用量）
+// SetUsageRecordRetryService 注入用量记录重试队列，RecordUsage 失败的输入入队后由后台重放
+func (s *GatewayService) SetUsageRecordRetryService(retry *UsageRecordRetryService) {
+	if s == nil {
+		return
+	}
+	s.usageRecordRetry = retry
+}
+
+// RecordUsage 记录使用量并扣费（或更新订阅用量）；失败时入队延迟重试
 func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInput) error {
+	var snapshot *RecordUsageInput
+	if s.usageRecordRetry.Enabled() && input != nil && input.Result != nil {
+		// 计费过程会改写 Result（强制缓存计费、图片尺寸归一），入队需用调用前的快照
+		cp, result := *input, *input.Result
+		cp.Result = &result
+		snapshot = &cp
+	}
+	err := s.recordUsage(ctx, input)
+	if err != nil && snapshot != nil {
+		s.usageRecordRetry.enqueue(ctx, UsageRecordRetryKindGateway, snapshot, err)
+	}
+	return err
+}
+
+func (s *GatewayService) recordUsage(ctx context.Context, input *RecordUsageInput) error {
 	return s.recordUsageCore(ctx, &recordUsageCoreInput{
 		Result:             input.Result,
 		APIKey:             input.APIKey,
@@ -9334,6 +9563,7 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 		ForceCacheBilling:  input.ForceCacheBilling,
 		APIKeyService:      input.APIKeyService,
 		QuotaPlatform:      input.QuotaPlatform,
+		FallbackFromModel:  input.FallbackFromModel,
 		ChannelUsageFields: input.ChannelUsageFields,
 	}, &recordUsageOpts{})
 }
@@ -9359,8 +9589,22 @@ type RecordUsageLongContextInput struct {
 	ChannelUsageFields // 渠道映射信息（由 handler 在 Forward 前解析）
 }
 
-// RecordUsageWithLongContext 记录使用量并扣费，支持长上下文双倍计费（用于 Gemini）
+// RecordUsageWithLongContext 记录使用量并扣费，支持长上下文双倍计费（用于 Gemini）；失败时入队延迟重试
 func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *RecordUsageLongContextInput) error {
+	var snapshot *RecordUsageLongContextInput
+	if s.usageRecordRetry.Enabled() && input != nil && input.Result != nil {
+		cp, result := *input, *input.Result
+		cp.Result = &result
+		snapshot = &cp
+	}
+	err := s.recordUsageWithLongContext(ctx, input)
+	if err != nil && snapshot != nil {
+		s.usageRecordRetry.enqueue(ctx, UsageRecordRetryKindGatewayLongContext, snapshot, err)
+	}
+	return err
+}
+
+func (s *GatewayService) recordUsageWithLongContext(ctx context.Context, input *RecordUsageLongContextInput) error {
 	return s.recordUsageCore(ctx, &recordUsageCoreInput{
 		Result:             input.Result,
 		APIKey:             input.APIKey,
@@ -9397,6 +9641,7 @@ type recordUsageCoreInput struct {
 	ForceCacheBilling  bool
 	APIKeyService      APIKeyQuotaUpdater
 	QuotaPlatform      string
+	FallbackFromModel  string
 	ChannelUsageFields
 }
 
@@ -9452,6 +9697,9 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 	if input.OriginalModel != "" {
 		requestedModel = input.OriginalModel
 	}
+	if input.FallbackFromModel != "" {
+		requestedModel = input.FallbackFromModel
+	}
 
 	// 计算费用
 	cost := s.calculateRecordUsageCost(ctx, result, apiKey, billingModel, multiplier, imageMultiplier, opts)
@@ -9485,6 +9733,8 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 		)
 	}
 
+	s.ttftStats.RecordUsageLog(usageLog)
+
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.gateway")
 		logger.LegacyPrintf("service.gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
@@ -9648,7 +9898,7 @@ func (s *GatewayService) calculateTokenCost(
 	}
 	if err != nil {
 		logger.LegacyPrintf("service.gateway", "Calculate cost failed: %v", err)
-		return &CostBreakdown{ActualCost: 0}
+		return &CostBreakdown{ActualCost: 0, PricingUnavailable: isUsagePricingUnavailableError(err)}
 	}
 	return cost
 }
@@ -9705,6 +9955,7 @@ func (s *GatewayService) buildRecordUsageLog(
 		ImageSizeSource:       optionalTrimmedStringPtr(result.ImageSizeSource),
 		ImageSizeBreakdown:    result.ImageSizeBreakdown,
 		CacheTTLOverridden:    cacheTTLOverridden,
+		BillingUnverified:     IsBillingUnverified(ctx),
 		ChannelID:             optionalInt64Ptr(input.ChannelID),
 		ModelMappingChain:     optionalTrimmedStringPtr(input.ModelMappingChain),
 		UserAgent:             optionalTrimmedStringPtr(input.UserAgent),
@@ -9724,7 +9975,9 @@ func (s *GatewayService) buildRecordUsageLog(
 		usageLog.CacheReadCost = cost.CacheReadCost
 		usageLog.TotalCost = cost.TotalCost
 		usageLog.ActualCost = cost.ActualCost
+		usageLog.PricingUnavailable = cost.PricingUnavailable
 	}
+	applyRequestCostEstimate(ctx, usageLog)
 
 	return usageLog
 }
@@ -10239,7 +10492,7 @@ func (s *GatewayService) buildCountTokensRequestAnthropicAPIKeyPassthrough(
 	if c != nil && c.Request != nil {
 		for key, values := range c.Request.Header {
 			lowerKey := strings.ToLower(strings.TrimSpace(key))
-			if !allowedHeaders[lowerKey] {
+			if !s.forwardHeaders.allows(lowerKey) {
 				continue
 			}
 			wireKey := resolveWireCasing(key)
@@ -10351,7 +10604,7 @@ func (s *GatewayService) buildCountTokensRequest(ctx context.Context, c *gin.Con
 	// 白名单透传 headers（恢复真实 wire casing）
 	for key, values := range clientHeaders {
 		lowerKey := strings.ToLower(key)
-		if allowedHeaders[lowerKey] {
+		if s.forwardHeaders.allows(lowerKey) {
 			wireKey := resolveWireCasing(key)
 			for _, v := range values {
 				addHeaderRaw(req.Header, wireKey, v)
@@ -10660,9 +10913,10 @@ func (s *GatewayService) debugLogGatewaySnapshot(tag string, headers http.Header
 
 	// 2. headers（按真实 Claude CLI wire 顺序排列，便于与抓包对比；auth 脱敏）
 	fmt.Fprint(&buf, "--- headers ---\n")
+	headers = logredact.RedactHeaders(headers)
 	for _, k := range sortHeadersByWireOrder(headers) {
 		for _, v := range headers[k] {
-			fmt.Fprintf(&buf, "  %s: %s\n", k, safeHeaderValueForLog(k, v))
+			fmt.Fprintf(&buf, "  %s: %s\n", k, strings.TrimSpace(v))
 		}
 	}
 
@@ -10683,3 +10937,11 @@ func (s *GatewayService) debugLogGatewaySnapshot(tag string, headers http.Header
 	// 写入文件（调试用，并发写入可能交错但不影响可读性）
 	_, _ = f.WriteString(buf.String())
 }
+
+// TTFTStats 返回首 token 延迟统计（未启用时为 nil）
+func (s *GatewayService) TTFTStats() *TTFTStats {
+	if s == nil {
+		return nil
+	}
+	return s.ttftStats
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	defaultGeminiAttachmentMaxInlineBytes = 20 * 1024 * 1024
+	geminiAttachmentMaxRedirects          = 3
+	geminiDefaultDocumentMimeType         = "application/pdf"
+)
+
+// claudeAttachmentError Claude 图片 / 文档块无法转换为 Gemini 附件（超出大小、下载失败、模型不支持等），返回 400。
+type claudeAttachmentError struct {
+	MessageIndex int
+	BlockIndex   int
+	Reason       string
+}
+
+func (e *claudeAttachmentError) Error() string {
+	return fmt.Sprintf("messages.%d.content.%d: %s", e.MessageIndex, e.BlockIndex, e.Reason)
+}
+
+// InlineClaudeAttachmentURLs 下载 Claude 请求中的 https URL 附件并改写为 base64 source，
+// gs:// 与 Gemini Files API 的 URL 保留给转换器生成 fileData。
+//
+// 下载结果与账号无关，handler 在账号选择 / failover 循环之前调用一次，避免每次切换账号重复下载。
+// 未启用下载或请求中没有 URL 附件时原样返回 body。
+func (s *GeminiMessagesCompatService) InlineClaudeAttachmentURLs(ctx context.Context, body []byte) ([]byte, error) {
+	if s.cfg == nil || !s.cfg.Gateway.GeminiAttachments.URLFetchEnabled || !bytes.Contains(body, []byte(`"url"`)) {
+		return body, nil
+	}
+	maxInline := s.geminiAttachmentMaxInlineBytes()
+
+	type rewrite struct {
+		path   string
+		source map[string]any
+	}
+	var rewrites []rewrite
+	err := walkClaudeAttachmentBlocks(body, func(mi, bi int, path string, block gjson.Result) error {
+		source := block.Get("source")
+		if source.Get("type").String() != "url" {
+			return nil
+		}
+		rawURL := strings.TrimSpace(source.Get("url").String())
+		if isGeminiFileURI(rawURL) {
+			return nil
+		}
+		blockType := block.Get("type").String()
+		data, mediaType, err := s.fetchClaudeAttachmentURL(ctx, rawURL)
+		if err != nil {
+			return &claudeAttachmentError{MessageIndex: mi, BlockIndex: bi, Reason: err.Error()}
+		}
+		if size := int64(len(data)); size > maxInline {
+			return &claudeAttachmentError{MessageIndex: mi, BlockIndex: bi, Reason: fmt.Sprintf("%s attachment is %s, exceeds the %s limit", blockType, formatAttachmentBytes(size), formatAttachmentBytes(maxInline))}
+		}
+		if mediaType == "" {
+			mediaType = strings.TrimSpace(source.Get("media_type").String())
+		}
+		rewrites = append(rewrites, rewrite{
+			path: path + ".source",
+			source: map[string]any{
+				"type":       "base64",
+				"media_type": mediaType,
+				"data":       base64.StdEncoding.EncodeToString(data),
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rw := range rewrites {
+		updated, err := sjson.SetBytes(body, rw.path, rw.source)
+		if err != nil {
+			return nil, fmt.Errorf("rewrite attachment source: %w", err)
+		}
+		body = updated
+	}
+	return body, nil
+}
+
+// IsClaudeAttachmentError 判断错误是否为附件校验失败（应返回 400 invalid_request_error）。
+func IsClaudeAttachmentError(err error) bool {
+	var attachmentErr *claudeAttachmentError
+	return errors.As(err, &attachmentErr)
+}
+
+// prepareClaudeAttachmentsForGemini 在转换为 Gemini generateContent 前处理 Claude 请求中的图片 / 文档块：
+//   - 仍为 https URL 的附件（调用方未预先执行 InlineClaudeAttachmentURLs）在此下载内联，未启用下载时拒绝；
+//   - base64 附件校验解码后大小（gateway.gemini_attachments.max_inline_bytes）；
+//   - 文档块仅在模型支持文档输入时放行。
+//
+// 请求中没有附件时原样返回 body。
+func (s *GeminiMessagesCompatService) prepareClaudeAttachmentsForGemini(ctx context.Context, body []byte, model string) ([]byte, error) {
+	if !bytes.Contains(body, []byte(`"source"`)) {
+		return body, nil
+	}
+	body, err := s.InlineClaudeAttachmentURLs(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	maxInline := s.geminiAttachmentMaxInlineBytes()
+
+	err = walkClaudeAttachmentBlocks(body, func(mi, bi int, _ string, block gjson.Result) error {
+		blockType := block.Get("type").String()
+		fail := func(format string, args ...any) error {
+			return &claudeAttachmentError{MessageIndex: mi, BlockIndex: bi, Reason: fmt.Sprintf(format, args...)}
+		}
+		if blockType == "document" && !geminiModelSupportsDocuments(model) {
+			return fail("model %s does not support document input", model)
+		}
+
+		source := block.Get("source")
+		switch source.Get("type").String() {
+		case "base64":
+			if size := int64(base64.StdEncoding.DecodedLen(len(source.Get("data").String()))); size > maxInline {
+				return fail("%s attachment is %s, exceeds the %s limit", blockType, formatAttachmentBytes(size), formatAttachmentBytes(maxInline))
+			}
+		case "url":
+			// gs:// / Files API URL 由转换器生成 fileData；其余 URL 仅在未启用下载时残留
+			if !isGeminiFileURI(strings.TrimSpace(source.Get("url").String())) {
+				return fail("url attachments are not supported; send the file as base64")
+			}
+		case "text", "content":
+			// 纯文本文档由转换器按文本处理
+		default:
+			return fail("unsupported %s source type %q", blockType, source.Get("type").String())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *GeminiMessagesCompatService) geminiAttachmentMaxInlineBytes() int64 {
+	if s.cfg != nil && s.cfg.Gateway.GeminiAttachments.MaxInlineBytes > 0 {
+		return s.cfg.Gateway.GeminiAttachments.MaxInlineBytes
+	}
+	return defaultGeminiAttachmentMaxInlineBytes
+}
+
+// walkClaudeAttachmentBlocks 遍历 Claude 请求中的图片 / 文档块（含 tool_result 内的附件），
+// path 为该块在 body 中的 sjson 路径；fn 返回错误时停止遍历并返回该错误。
+func walkClaudeAttachmentBlocks(body []byte, fn func(mi, bi int, path string, block gjson.Result) error) error {
+	var firstErr error
+	visit := func(mi, bi int, path string, block gjson.Result) bool {
+		if blockType := block.Get("type").String(); blockType != "image" && blockType != "document" {
+			return true
+		}
+		firstErr = fn(mi, bi, path, block)
+		return firstErr == nil
+	}
+	gjson.GetBytes(body, "messages").ForEach(func(mi, msg gjson.Result) bool {
+		msg.Get("content").ForEach(func(bi, block gjson.Result) bool {
+			path := fmt.Sprintf("messages.%d.content.%d", mi.Int(), bi.Int())
+			if block.Get("type").String() == "tool_result" {
+				// tool_result 内的截图等附件同样受大小 / 下载规则约束
+				block.Get("content").ForEach(func(ci, inner gjson.Result) bool {
+					return visit(int(mi.Int()), int(bi.Int()), fmt.Sprintf("%s.content.%d", path, ci.Int()), inner)
+				})
+				return firstErr == nil
+			}
+			return visit(int(mi.Int()), int(bi.Int()), path, block)
+		})
+		return firstErr == nil
+	})
+	return firstErr
+}
+
+// fetchClaudeAttachmentURL 下载 URL 附件：仅 https，私网地址遵循 security.url_allowlist.allow_private_hosts，
+// 大小受 gateway.gemini_attachments.url_fetch_max_bytes 限制。返回内容与去掉参数的 Content-Type。
+//
+// 禁止私网时在 dial 阶段校验实际连接的 IP（safeDialContext），防止 DNS rebinding 绕过解析期校验。
+func (s *GeminiMessagesCompatService) fetchClaudeAttachmentURL(ctx context.Context, rawURL string) ([]byte, string, error) {
+	if s.cfg == nil || !s.cfg.Gateway.GeminiAttachments.URLFetchEnabled {
+		return nil, "", errors.New("url attachments are not supported; send the file as base64")
+	}
+	attachmentCfg := s.cfg.Gateway.GeminiAttachments
+	allowPrivate := s.cfg.Security.URLAllowlist.AllowPrivateHosts
+	validateHost := func(u *url.URL) error {
+		_, err := urlvalidator.ValidateHTTPSURL(u.String(), urlvalidator.ValidationOptions{AllowPrivate: allowPrivate})
+		return err
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil, "", errors.New("invalid attachment url")
+	}
+	if err := validateHost(parsed); err != nil {
+		return nil, "", fmt.Errorf("attachment url not allowed: %v", err)
+	}
+
+	fetchClient, closeIdle := s.newAttachmentFetchClient(allowPrivate)
+	defer closeIdle()
+	fetchClient.Timeout = time.Duration(attachmentCfg.URLFetchTimeoutSeconds) * time.Second
+	fetchClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= geminiAttachmentMaxRedirects {
+			return errors.New("too many redirects")
+		}
+		return validateHost(req.URL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, "", errors.New("invalid attachment url")
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download attachment: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("failed to download attachment: HTTP %d", resp.StatusCode)
+	}
+	maxBytes := attachmentCfg.URLFetchMaxBytes
+	if resp.ContentLength > maxBytes {
+		return nil, "", fmt.Errorf("attachment url content is %s, exceeds the %s download limit", formatAttachmentBytes(resp.ContentLength), formatAttachmentBytes(maxBytes))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download attachment: %v", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("attachment url content exceeds the %s download limit", formatAttachmentBytes(maxBytes))
+	}
+
+	mediaType := ""
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		if parsedType, _, err := mime.ParseMediaType(ct); err == nil && parsedType != "application/octet-stream" {
+			mediaType = parsedType
+		}
+	}
+	if mediaType == "" {
+		if detected := http.DetectContentType(data); !strings.HasPrefix(detected, "application/octet-stream") {
+			mediaType, _, _ = mime.ParseMediaType(detected)
+		}
+	}
+	return data, mediaType, nil
+}
+
+// newAttachmentFetchClient 复制附件下载 client 与 Transport，不修改共享实例。
+// 禁止私网时改用 safeDialContext 在连接前校验真实 IP，并关闭环境代理以确保校验的是目标地址。
+func (s *GeminiMessagesCompatService) newAttachmentFetchClient(allowPrivate bool) (*http.Client, func()) {
+	client := http.Client{}
+	if s.attachmentClient != nil {
+		client = *s.attachmentClient
+	}
+	var transport *http.Transport
+	if base, ok := client.Transport.(*http.Transport); ok {
+		transport = base.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	if !allowPrivate {
+		transport.Proxy = nil
+		transport.DialContext = safeDialContext
+	}
+	client.Transport = transport
+	return &client, transport.CloseIdleConnections
+}
+
+// convertClaudeAttachmentBlockToGeminiPart 将 Claude 图片 / 文档块转换为 Gemini part：
+// base64 → inlineData，URL（gs:// / Files API）→ fileData，纯文本文档 → text。无法转换时返回 nil。
+func convertClaudeAttachmentBlockToGeminiPart(block map[string]any) map[string]any {
+	blockType, _ := block["type"].(string)
+	src, ok := block["source"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	mediaType, _ := src["media_type"].(string)
+	mediaType = strings.TrimSpace(mediaType)
+	if mediaType == "" && blockType == "document" {
+		mediaType = geminiDefaultDocumentMimeType
+	}
+
+	switch srcType, _ := src["type"].(string); srcType {
+	case "base64":
+		data, _ := src["data"].(string)
+		if mediaType == "" || data == "" {
+			return nil
+		}
+		return map[string]any{
+			"inlineData": map[string]any{
+				"mimeType": mediaType,
+				"data":     data,
+			},
+		}
+	case "url":
+		fileURI, _ := src["url"].(string)
+		fileURI = strings.TrimSpace(fileURI)
+		if fileURI == "" {
+			return nil
+		}
+		fileData := map[string]any{"fileUri": fileURI}
+		if mediaType == "" {
+			mediaType = mime.TypeByExtension(path.Ext(fileURI))
+		}
+		if mediaType != "" {
+			fileData["mimeType"] = mediaType
+		}
+		return map[string]any{"fileData": fileData}
+	case "text":
+		if data, _ := src["data"].(string); data != "" {
+			return map[string]any{"text": data}
+		}
+	case "content":
+		if text := extractClaudeContentText(src["content"]); text != "" {
+			return map[string]any{"text": text}
+		}
+	}
+	return nil
+}
+
+// convertClaudeToolResultAttachmentsToGeminiParts 提取 tool_result content 数组中的图片 / 文档块，
+// 按原顺序转换为 Gemini part。functionResponse.response 只承载文本，这些 part 作为其后的兄弟 part 发送。
+func convertClaudeToolResultAttachmentsToGeminiParts(content any) []any {
+	blocks, ok := content.([]any)
+	if !ok {
+		return nil
+	}
+	var parts []any
+	for _, block := range blocks {
+		bm, ok := block.(map[string]any)
+		if !ok {
+			continue
+		}
+		if bt, _ := bm["type"].(string); bt != "image" && bt != "document" {
+			continue
+		}
+		if part := convertClaudeAttachmentBlockToGeminiPart(bm); part != nil {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// isGeminiFileURI 上游可直接引用的文件 URI：gs:// 与 Gemini Files API
+func isGeminiFileURI(raw string) bool {
+	if strings.HasPrefix(raw, "gs://") {
+		return true
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "https" && strings.EqualFold(u.Hostname(), "generativelanguage.googleapis.com") && strings.Contains(u.Path, "/files/")
+}
+
+// geminiModelSupportsDocuments 模型是否支持 PDF 等文档输入（gemini-1.0 系列不支持）
+func geminiModelSupportsDocuments(model string) bool {
+	m := strings.ToLower(strings.TrimSpace(model))
+	m = strings.TrimPrefix(m, "models/")
+	return !strings.HasPrefix(m, "gemini-1.0") && m != "gemini-pro" && m != "gemini-pro-vision"
+}
+
+func formatAttachmentBytes(n int64) string {
+	if n >= 1024*1024 {
+		return strconv.FormatFloat(float64(n)/(1024*1024), 'f', 1, 64) + " MB"
+	}
+	if n >= 1024 {
+		return strconv.FormatFloat(float64(n)/1024, 'f', 1, 64) + " KB"
+	}
+	return strconv.FormatInt(n, 10) + " B"
+}
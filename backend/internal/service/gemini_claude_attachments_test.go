@@ -0,0 +1,285 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// testPNGFixture 生成 2x2 的 PNG 图片
+func testPNGFixture(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(1, 1, color.RGBA{B: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func newGeminiAttachmentTestService(mutate func(cfg *config.Config)) *GeminiMessagesCompatService {
+	cfg := &config.Config{}
+	cfg.Gateway.GeminiAttachments = config.GatewayGeminiAttachmentsConfig{
+		MaxInlineBytes:         1024 * 1024,
+		URLFetchEnabled:        true,
+		URLFetchMaxBytes:       1024 * 1024,
+		URLFetchTimeoutSeconds: 5,
+	}
+	if mutate != nil {
+		mutate(cfg)
+	}
+	return &GeminiMessagesCompatService{cfg: cfg}
+}
+
+// convertPreparedClaudeBody 预处理附件后转换为 Gemini 请求，返回首条消息的 parts
+func convertPreparedClaudeBody(t *testing.T, svc *GeminiMessagesCompatService, body string) gjson.Result {
+	t.Helper()
+	prepared, err := svc.prepareClaudeAttachmentsForGemini(context.Background(), []byte(body), "gemini-2.5-flash")
+	require.NoError(t, err)
+	geminiReq, err := convertClaudeMessagesToGeminiGenerateContent(prepared)
+	require.NoError(t, err)
+	return gjson.GetBytes(geminiReq, "contents.0.parts")
+}
+
+func TestGeminiAttachments_Base64ImageRoundTrip(t *testing.T) {
+	pngData := base64.StdEncoding.EncodeToString(testPNGFixture(t))
+	body := `{"model":"gemini-2.5-flash","messages":[{"role":"user","content":[` +
+		`{"type":"text","text":"what is in this screenshot?"},` +
+		`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + pngData + `"}}]}]}`
+
+	parts := convertPreparedClaudeBody(t, newGeminiAttachmentTestService(nil), body)
+	require.Len(t, parts.Array(), 2)
+	require.Equal(t, "what is in this screenshot?", parts.Get("0.text").String())
+	require.Equal(t, "image/png", parts.Get("1.inlineData.mimeType").String())
+	require.Equal(t, pngData, parts.Get("1.inlineData.data").String())
+}
+
+func TestGeminiAttachments_URLImageDownloadedAndInlined(t *testing.T) {
+	fixture := testPNGFixture(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	svc := newGeminiAttachmentTestService(func(cfg *config.Config) {
+		cfg.Security.URLAllowlist.AllowPrivateHosts = true
+	})
+	svc.attachmentClient = srv.Client()
+
+	body := `{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"url","url":"` + srv.URL + `/shot"}}]}]}`
+	parts := convertPreparedClaudeBody(t, svc, body)
+	require.Equal(t, "image/png", parts.Get("0.inlineData.mimeType").String())
+	decoded, err := base64.StdEncoding.DecodeString(parts.Get("0.inlineData.data").String())
+	require.NoError(t, err)
+	require.Equal(t, fixture, decoded)
+}
+
+func TestGeminiAttachments_FileURIsAndDocuments(t *testing.T) {
+	pdfData := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 tiny"))
+	body := `{"messages":[{"role":"user","content":[` +
+		`{"type":"image","source":{"type":"url","url":"gs://bucket/shot.png"}},` +
+		`{"type":"document","source":{"type":"url","url":"https://generativelanguage.googleapis.com/v1beta/files/abc123"}},` +
+		`{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"` + pdfData + `"}},` +
+		`{"type":"document","source":{"type":"text","media_type":"text/plain","data":"plain notes"}}]}]}`
+
+	parts := convertPreparedClaudeBody(t, newGeminiAttachmentTestService(nil), body)
+	require.Len(t, parts.Array(), 4)
+	require.Equal(t, "gs://bucket/shot.png", parts.Get("0.fileData.fileUri").String())
+	require.Equal(t, "image/png", parts.Get("0.fileData.mimeType").String())
+	require.Equal(t, "https://generativelanguage.googleapis.com/v1beta/files/abc123", parts.Get("1.fileData.fileUri").String())
+	require.Equal(t, "application/pdf", parts.Get("1.fileData.mimeType").String())
+	require.Equal(t, "application/pdf", parts.Get("2.inlineData.mimeType").String())
+	require.Equal(t, pdfData, parts.Get("2.inlineData.data").String())
+	require.Equal(t, "plain notes", parts.Get("3.text").String())
+}
+
+func TestGeminiAttachments_OversizeRejectedWithBlockIndex(t *testing.T) {
+	svc := newGeminiAttachmentTestService(func(cfg *config.Config) {
+		cfg.Gateway.GeminiAttachments.MaxInlineBytes = 16
+	})
+	pngData := base64.StdEncoding.EncodeToString(testPNGFixture(t))
+	body := `{"messages":[{"role":"user","content":"hi"},{"role":"user","content":[` +
+		`{"type":"text","text":"look"},` +
+		`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + pngData + `"}}]}]}`
+
+	_, err := svc.prepareClaudeAttachmentsForGemini(context.Background(), []byte(body), "gemini-2.5-flash")
+	var attachmentErr *claudeAttachmentError
+	require.True(t, errors.As(err, &attachmentErr))
+	require.Equal(t, 1, attachmentErr.MessageIndex)
+	require.Equal(t, 1, attachmentErr.BlockIndex)
+	require.Contains(t, err.Error(), "messages.1.content.1")
+	require.Contains(t, err.Error(), "exceeds the 16 B limit")
+}
+
+func TestGeminiAttachments_URLRejections(t *testing.T) {
+	fixture := testPNGFixture(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(fixture)
+	}))
+	defer srv.Close()
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"url","url":"` + srv.URL + `/shot"}}]}]}`)
+
+	// 默认禁止私网地址
+	blocked := newGeminiAttachmentTestService(nil)
+	blocked.attachmentClient = srv.Client()
+	_, err := blocked.prepareClaudeAttachmentsForGemini(context.Background(), body, "gemini-2.5-flash")
+	require.ErrorContains(t, err, "messages.0.content.0: attachment url not allowed")
+
+	// 超出下载上限
+	tooSmall := newGeminiAttachmentTestService(func(cfg *config.Config) {
+		cfg.Security.URLAllowlist.AllowPrivateHosts = true
+		cfg.Gateway.GeminiAttachments.URLFetchMaxBytes = 8
+	})
+	tooSmall.attachmentClient = srv.Client()
+	_, err = tooSmall.prepareClaudeAttachmentsForGemini(context.Background(), body, "gemini-2.5-flash")
+	require.ErrorContains(t, err, "download limit")
+
+	// 关闭下载
+	disabled := newGeminiAttachmentTestService(func(cfg *config.Config) {
+		cfg.Gateway.GeminiAttachments.URLFetchEnabled = false
+	})
+	_, err = disabled.prepareClaudeAttachmentsForGemini(context.Background(), body, "gemini-2.5-flash")
+	require.ErrorContains(t, err, "url attachments are not supported")
+}
+
+func TestGeminiAttachments_InlineURLsDownloadsOnceAcrossAccounts(t *testing.T) {
+	fixture := testPNGFixture(t)
+	var hits atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	svc := newGeminiAttachmentTestService(func(cfg *config.Config) {
+		cfg.Security.URLAllowlist.AllowPrivateHosts = true
+	})
+	svc.attachmentClient = srv.Client()
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"url","url":"` + srv.URL + `/shot"}}]}]}`)
+
+	inlined, err := svc.InlineClaudeAttachmentURLs(context.Background(), body)
+	require.NoError(t, err)
+	require.Equal(t, "base64", gjson.GetBytes(inlined, "messages.0.content.0.source.type").String())
+
+	// 模拟 failover 切换两个账号，每次 Forward 都会执行 prepare，但不再重复下载
+	for i := 0; i < 2; i++ {
+		_, err = svc.prepareClaudeAttachmentsForGemini(context.Background(), inlined, "gemini-2.5-flash")
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), hits.Load())
+}
+
+func TestGeminiAttachments_URLFetchDisabledLeavesBodyForRejection(t *testing.T) {
+	svc := newGeminiAttachmentTestService(func(cfg *config.Config) {
+		cfg.Gateway.GeminiAttachments.URLFetchEnabled = false
+	})
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"url","url":"https://example.com/shot.png"}}]}]}`)
+
+	inlined, err := svc.InlineClaudeAttachmentURLs(context.Background(), body)
+	require.NoError(t, err)
+	require.Equal(t, body, inlined)
+	_, err = svc.prepareClaudeAttachmentsForGemini(context.Background(), inlined, "gemini-2.5-flash")
+	require.ErrorContains(t, err, "url attachments are not supported")
+}
+
+func TestGeminiAttachments_FetchClientValidatesIPAtDialTime(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	svc := newGeminiAttachmentTestService(nil)
+	svc.attachmentClient = srv.Client()
+
+	// 禁止私网时即使 URL 校验被绕过（如 DNS rebinding），连接阶段仍拒绝 loopback
+	client, closeIdle := svc.newAttachmentFetchClient(false)
+	defer closeIdle()
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+	_, err = transport.DialContext(context.Background(), "tcp", target.Host)
+	require.ErrorContains(t, err, "blocked by SSRF policy")
+
+	// 允许私网时保留原有 dial 行为
+	allowed, closeAllowed := svc.newAttachmentFetchClient(true)
+	defer closeAllowed()
+	resp, err := allowed.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+}
+
+func TestGeminiAttachments_DocumentRequiresCapableModel(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"JVBERi0="}}]}]}`)
+	svc := newGeminiAttachmentTestService(nil)
+
+	_, err := svc.prepareClaudeAttachmentsForGemini(context.Background(), body, "gemini-1.0-pro")
+	require.ErrorContains(t, err, "messages.0.content.0: model gemini-1.0-pro does not support document input")
+
+	_, err = svc.prepareClaudeAttachmentsForGemini(context.Background(), body, "gemini-2.5-pro")
+	require.NoError(t, err)
+}
+
+func TestGeminiAttachments_ToolResultImageBecomesSiblingPart(t *testing.T) {
+	pngData := base64.StdEncoding.EncodeToString(testPNGFixture(t))
+	body := `{"model":"gemini-2.5-flash","messages":[` +
+		`{"role":"user","content":"check the page"},` +
+		`{"role":"assistant","content":[` +
+		`{"type":"tool_use","id":"toolu_a","name":"read_log","input":{}},` +
+		`{"type":"tool_use","id":"toolu_b","name":"screenshot","input":{}}]},` +
+		`{"role":"user","content":[` +
+		`{"type":"tool_result","tool_use_id":"toolu_a","content":"log ok"},` +
+		`{"type":"tool_result","tool_use_id":"toolu_b","content":[` +
+		`{"type":"text","text":"captured 800x600"},` +
+		`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + pngData + `"}}]},` +
+		`{"type":"text","text":"what do you see?"}]}]}`
+
+	svc := newGeminiAttachmentTestService(nil)
+	prepared, err := svc.prepareClaudeAttachmentsForGemini(context.Background(), []byte(body), "gemini-2.5-flash")
+	require.NoError(t, err)
+	geminiReq, err := convertClaudeMessagesToGeminiGenerateContent(prepared)
+	require.NoError(t, err)
+
+	calls := gjson.GetBytes(geminiReq, "contents.1.parts")
+	require.Equal(t, "read_log", calls.Get("0.functionCall.name").String())
+	require.Equal(t, "screenshot", calls.Get("1.functionCall.name").String())
+
+	// functionResponse(a) → functionResponse(b) → inlineData(b 的截图) → 用户文本
+	parts := gjson.GetBytes(geminiReq, "contents.2.parts")
+	require.Len(t, parts.Array(), 4)
+	require.Equal(t, "read_log", parts.Get("0.functionResponse.name").String())
+	require.Equal(t, "log ok", parts.Get("0.functionResponse.response.content").String())
+	require.Equal(t, "screenshot", parts.Get("1.functionResponse.name").String())
+	require.Equal(t, "captured 800x600", parts.Get("1.functionResponse.response.content").String())
+	require.Equal(t, "image/png", parts.Get("2.inlineData.mimeType").String())
+	require.Equal(t, pngData, parts.Get("2.inlineData.data").String())
+	require.Equal(t, "what do you see?", parts.Get("3.text").String())
+}
+
+func TestGeminiAttachments_ToolResultImageOversizeRejected(t *testing.T) {
+	svc := newGeminiAttachmentTestService(func(cfg *config.Config) {
+		cfg.Gateway.GeminiAttachments.MaxInlineBytes = 16
+	})
+	pngData := base64.StdEncoding.EncodeToString(testPNGFixture(t))
+	body := `{"messages":[{"role":"user","content":[` +
+		`{"type":"tool_result","tool_use_id":"toolu_b","content":[` +
+		`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + pngData + `"}}]}]}]}`
+
+	_, err := svc.prepareClaudeAttachmentsForGemini(context.Background(), []byte(body), "gemini-2.5-flash")
+	require.ErrorContains(t, err, "messages.0.content.0: image attachment")
+}
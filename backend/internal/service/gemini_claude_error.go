@@ -0,0 +1,165 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GeminiClaudeError Gemini 上游错误翻译后的 Claude 错误（{type:"error", error:{type,message}}）。
+type GeminiClaudeError struct {
+	StatusCode int
+	Type       string
+	Message    string
+	// RetryAfterSeconds 由 ParseGeminiRateLimitResetTime 推导的重试等待秒数，0 表示不设置 Retry-After
+	RetryAfterSeconds int
+}
+
+// TranslateGeminiErrorToClaude 将 Gemini 错误响应（error.code / error.status / error.details）翻译为 Claude 错误：
+//   - 错误类型优先按 error.status 映射（RESOURCE_EXHAUSTED → rate_limit_error，UNAVAILABLE → overloaded_error 等），
+//     否则按 HTTP 状态码映射；
+//   - 限流 / 过载错误复用 ParseGeminiRateLimitResetTime 解析重置时间，用于 Retry-After；
+//   - 消息保持通用文案，上游原始消息可能过长或包含敏感片段。
+func TranslateGeminiErrorToClaude(upstreamStatus int, body []byte) *GeminiClaudeError {
+	out := &GeminiClaudeError{}
+	if mapped := mapGeminiErrorBodyToClaudeError(body); mapped != nil {
+		out.Type = mapped.Type
+		out.Message = mapped.Message
+		out.StatusCode = mapped.StatusCode
+	}
+
+	statusCode, errType, errMsg := mapGeminiHTTPStatusToClaudeError(upstreamStatus)
+	if out.StatusCode == 0 {
+		out.StatusCode = statusCode
+	}
+	if out.Type == "" {
+		out.Type = errType
+	}
+	if out.Message == "" {
+		out.Message = errMsg
+	}
+
+	if out.Type == "rate_limit_error" || out.Type == "overloaded_error" {
+		if resetAt := ParseGeminiRateLimitResetTime(body); resetAt != nil {
+			if wait := *resetAt - time.Now().Unix(); wait > 0 {
+				out.RetryAfterSeconds = int(wait)
+			}
+		}
+	}
+	return out
+}
+
+// Write 写出 Retry-After（如有）与 Claude 错误信封
+func (e *GeminiClaudeError) Write(c *gin.Context) {
+	if e.RetryAfterSeconds > 0 {
+		c.Header("Retry-After", strconv.Itoa(e.RetryAfterSeconds))
+	}
+	c.JSON(e.StatusCode, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": e.Type, "message": e.Message},
+	})
+}
+
+// IsGeminiErrorBody 响应体是否为 Gemini 错误结构（error.code / error.status / error.message 至少一项）
+func IsGeminiErrorBody(body []byte) bool {
+	return mapGeminiErrorBodyToClaudeError(body) != nil
+}
+
+// mapGeminiHTTPStatusToClaudeError 按上游 HTTP 状态码给出 Claude 错误的默认状态码、类型与文案
+func mapGeminiHTTPStatusToClaudeError(upstreamStatus int) (int, string, string) {
+	switch upstreamStatus {
+	case 400:
+		return http.StatusBadRequest, "invalid_request_error", "Invalid request"
+	case 401:
+		return http.StatusBadGateway, "authentication_error", "Upstream authentication failed, please contact administrator"
+	case 403:
+		return http.StatusBadGateway, "permission_error", "Upstream access forbidden, please contact administrator"
+	case 404:
+		return http.StatusNotFound, "not_found_error", "Resource not found"
+	case 429:
+		return http.StatusTooManyRequests, "rate_limit_error", "Upstream rate limit exceeded, please retry later"
+	case 529:
+		return http.StatusServiceUnavailable, "overloaded_error", "Upstream service overloaded, please retry later"
+	case 500, 502:
+		return http.StatusBadGateway, "api_error", "Upstream service temporarily unavailable"
+	case 503:
+		return http.StatusBadGateway, "overloaded_error", "Upstream service temporarily unavailable"
+	case 504:
+		return http.StatusBadGateway, "timeout_error", "Upstream service temporarily unavailable"
+	default:
+		return http.StatusBadGateway, "upstream_error", "Upstream request failed"
+	}
+}
+
+type claudeErrorMapping struct {
+	Type       string
+	Message    string
+	StatusCode int
+}
+
+func mapGeminiErrorBodyToClaudeError(body []byte) *claudeErrorMapping {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var parsed struct {
+		Error struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil
+	}
+	if strings.TrimSpace(parsed.Error.Status) == "" && parsed.Error.Code == 0 && strings.TrimSpace(parsed.Error.Message) == "" {
+		return nil
+	}
+
+	// 未识别的 error.status 保持 Type 为空，由调用方按 HTTP 状态码映射
+	mapped := &claudeErrorMapping{
+		Type:    mapGeminiStatusToClaudeErrorType(parsed.Error.Status),
+		Message: "",
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(parsed.Error.Status)) {
+	case "INVALID_ARGUMENT":
+		mapped.StatusCode = http.StatusBadRequest
+	case "NOT_FOUND":
+		mapped.StatusCode = http.StatusNotFound
+	case "RESOURCE_EXHAUSTED":
+		mapped.StatusCode = http.StatusTooManyRequests
+	default:
+		// Keep StatusCode unset and let HTTP status mapping decide.
+	}
+
+	// Keep messages generic by default; upstream error message can be long or include sensitive fragments.
+	return mapped
+}
+
+func mapGeminiStatusToClaudeErrorType(status string) string {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "INVALID_ARGUMENT":
+		return "invalid_request_error"
+	case "PERMISSION_DENIED":
+		return "permission_error"
+	case "NOT_FOUND":
+		return "not_found_error"
+	case "RESOURCE_EXHAUSTED":
+		return "rate_limit_error"
+	case "UNAUTHENTICATED":
+		return "authentication_error"
+	case "UNAVAILABLE":
+		return "overloaded_error"
+	case "INTERNAL":
+		return "api_error"
+	case "DEADLINE_EXCEEDED":
+		return "timeout_error"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateGeminiErrorToClaude_RateLimitWithRetryAfter(t *testing.T) {
+	body := []byte(`{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED","details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","metadata":{"quotaResetDelay":"12.2s"}}]}}`)
+
+	got := TranslateGeminiErrorToClaude(http.StatusTooManyRequests, body)
+	require.Equal(t, http.StatusTooManyRequests, got.StatusCode)
+	require.Equal(t, "rate_limit_error", got.Type)
+	require.Equal(t, "Upstream rate limit exceeded, please retry later", got.Message)
+	// quotaResetDelay 向上取整为 13s，计算时可能跨过秒边界
+	require.GreaterOrEqual(t, got.RetryAfterSeconds, 12)
+	require.LessOrEqual(t, got.RetryAfterSeconds, 13)
+
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	got.Write(c)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, strconv.Itoa(got.RetryAfterSeconds), rec.Header().Get("Retry-After"))
+
+	var payload struct {
+		Type  string `json:"type"`
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payload))
+	require.Equal(t, "error", payload.Type)
+	require.Equal(t, "rate_limit_error", payload.Error.Type)
+}
+
+func TestTranslateGeminiErrorToClaude_RateLimitWithoutResetHint(t *testing.T) {
+	// 没有 status 字段时按 HTTP 状态码映射，且无重置提示时不设置 Retry-After
+	got := TranslateGeminiErrorToClaude(http.StatusTooManyRequests, []byte(`{"error":{"code":429,"message":"slow down"}}`))
+	require.Equal(t, http.StatusTooManyRequests, got.StatusCode)
+	require.Equal(t, "rate_limit_error", got.Type)
+	require.Zero(t, got.RetryAfterSeconds)
+
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	got.Write(c)
+	require.Empty(t, rec.Header().Get("Retry-After"))
+}
+
+func TestTranslateGeminiErrorToClaude_InvalidArgument(t *testing.T) {
+	body := []byte(`{"error":{"code":400,"message":"Invalid JSON payload received. Unknown name \"foo\"","status":"INVALID_ARGUMENT"}}`)
+
+	got := TranslateGeminiErrorToClaude(http.StatusBadRequest, body)
+	require.Equal(t, http.StatusBadRequest, got.StatusCode)
+	require.Equal(t, "invalid_request_error", got.Type)
+	require.Equal(t, "Invalid request", got.Message)
+	require.Zero(t, got.RetryAfterSeconds)
+}
+
+func TestTranslateGeminiErrorToClaude_ServerErrors(t *testing.T) {
+	got := TranslateGeminiErrorToClaude(http.StatusInternalServerError, []byte(`{"error":{"code":500,"message":"An internal error has occurred.","status":"INTERNAL"}}`))
+	require.Equal(t, http.StatusBadGateway, got.StatusCode)
+	require.Equal(t, "api_error", got.Type)
+	require.Equal(t, "Upstream service temporarily unavailable", got.Message)
+
+	got = TranslateGeminiErrorToClaude(http.StatusServiceUnavailable, []byte(`{"error":{"code":503,"message":"The model is overloaded. Please retry in 3s.","status":"UNAVAILABLE"}}`))
+	require.Equal(t, http.StatusBadGateway, got.StatusCode)
+	require.Equal(t, "overloaded_error", got.Type)
+	require.Positive(t, got.RetryAfterSeconds)
+
+	// 非 Gemini 结构的响应体按 HTTP 状态码映射
+	got = TranslateGeminiErrorToClaude(http.StatusInternalServerError, []byte(`upstream exploded`))
+	require.Equal(t, http.StatusBadGateway, got.StatusCode)
+	require.Equal(t, "api_error", got.Type)
+	require.False(t, IsGeminiErrorBody([]byte(`upstream exploded`)))
+}
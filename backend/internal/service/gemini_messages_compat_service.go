@@ -54,6 +54,9 @@ type GeminiMessagesCompatService struct {
 	antigravityGatewayService *AntigravityGatewayService
 	cfg                       *config.Config
 	responseHeaderFilter      *responseheaders.CompiledHeaderFilter
+	responseRedactor          *responseRedactor
+	// attachmentClient 下载 Claude URL 附件的 HTTP 客户端；nil 时使用默认客户端
+	attachmentClient *http.Client
 }
 
 func (s *GeminiMessagesCompatService) readUpstreamErrorBody(resp *http.Response) []byte {
@@ -79,6 +82,7 @@ func NewGeminiMessagesCompatService(
 	antigravityGatewayService *AntigravityGatewayService,
 	cfg *config.Config,
 ) *GeminiMessagesCompatService {
+	registerDebugLogComponents(cfg)
 	return &GeminiMessagesCompatService{
 		accountRepo:               accountRepo,
 		groupRepo:                 groupRepo,
@@ -89,7 +93,8 @@ func NewGeminiMessagesCompatService(
 		httpUpstream:              httpUpstream,
 		antigravityGatewayService: antigravityGatewayService,
 		cfg:                       cfg,
-		responseHeaderFilter:      compileResponseHeaderFilter(cfg),
+		responseHeaderFilter:      compileResponseHeaderFilterForPlatform(cfg, PlatformGemini),
+		responseRedactor:          compileResponseRedactor(cfg, PlatformGemini),
 	}
 }
 
@@ -599,10 +604,21 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 		mappedModel = account.GetMappedModel(req.Model)
 	}
 
+	timeoutWatchdog := beginUpstreamTimeoutTier(ctx, c, s.cfg, account.Platform, originalModel)
+	if !req.Stream {
+		timeoutWatchdog.disarm(UpstreamTimeoutPhaseFirstToken)
+	}
+	defer timeoutWatchdog.Stop()
+
+	body, err := s.prepareClaudeAttachmentsForGemini(ctx, body, mappedModel)
+	if err != nil {
+		return nil, s.writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+	}
 	geminiReq, err := convertClaudeMessagesToGeminiGenerateContent(body)
 	if err != nil {
 		return nil, s.writeClaudeError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
 	}
+	setGeminiToolNameMapping(c, body)
 	geminiReq = ensureGeminiFunctionCallThoughtSignatures(geminiReq)
 	originalClaudeBody := body
 
@@ -765,7 +781,7 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 	var resp *http.Response
 	signatureRetryStage := 0
 	for attempt := 1; attempt <= geminiMaxRetries; attempt++ {
-		upstreamReq, idHeader, err := buildReq(ctx)
+		upstreamReq, idHeader, err := buildReq(timeoutWatchdog.Bind(ctx))
 		if err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return nil, err
@@ -778,8 +794,13 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 		}
 		requestIDHeader = idHeader
 
+		upstreamStart := time.Now()
 		resp, err = s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 		if err != nil {
+			if timeoutErr := timeoutWatchdog.Err(); timeoutErr != nil {
+				return nil, upstreamTimeoutTierFailover(c, account, "", timeoutErr, claudeUpstreamTimeoutFailoverBody)
+			}
 			safeErr := sanitizeUpstreamErrorMessage(err.Error())
 			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
 				Platform:           account.Platform,
@@ -797,6 +818,7 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 			setOpsUpstreamError(c, 0, safeErr, "")
 			return nil, s.writeClaudeError(c, http.StatusBadGateway, "upstream_error", "Upstream request failed after retries: "+safeErr)
 		}
+		timeoutWatchdog.HeadersReceived()
 
 		// Special-case: signature/thought_signature validation errors are not transient, but may be fixed by
 		// downgrading Claude thinking/tool history to plain text (conservative two-stage retry).
@@ -888,7 +910,7 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 			}
 			if resp.StatusCode == 429 {
 				// Mark as rate-limited early so concurrent requests avoid this account.
-				s.handleGeminiUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+				s.applyGeminiUpstreamError(ctx, c, account, resp.StatusCode, resp.Header, respBody)
 			}
 			if attempt < geminiMaxRetries {
 				upstreamReqID := resp.Header.Get(requestIDHeader)
@@ -932,6 +954,8 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 		break
 	}
 	defer func() { _ = resp.Body.Close() }()
+	// 上游请求 ID 在成功与错误响应上均按配置回传（X-Upstream-Request-Id），并随 failover 错误带回 handler
+	upstreamRequestID := exposeUpstreamRequestID(c, s.cfg, resp.Header, nil)
 
 	if resp.StatusCode >= 400 {
 		respBody := s.readUpstreamErrorBody(resp)
@@ -945,7 +969,7 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 				}
 				return nil, s.writeGeminiMappedError(c, account, http.StatusInternalServerError, upstreamReqID, respBody)
 			case ErrorPolicyMatched, ErrorPolicyTempUnscheduled:
-				s.handleGeminiUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+				s.applyGeminiUpstreamError(ctx, c, account, resp.StatusCode, resp.Header, respBody)
 				upstreamReqID := resp.Header.Get(requestIDHeader)
 				if upstreamReqID == "" {
 					upstreamReqID = resp.Header.Get("x-goog-request-id")
@@ -970,12 +994,12 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 					Message:            upstreamMsg,
 					Detail:             upstreamDetail,
 				})
-				return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody}
+				return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody, UpstreamRequestID: upstreamRequestID}
 			}
 		}
 
 		// ErrorPolicyNone → 原有逻辑
-		s.handleGeminiUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+		s.applyGeminiUpstreamError(ctx, c, account, resp.StatusCode, resp.Header, respBody)
 		// 精确匹配服务端配置类 400 错误，触发 failover + 临时封禁
 		if resp.StatusCode == http.StatusBadRequest {
 			msg400 := strings.ToLower(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
@@ -1004,7 +1028,7 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 					Message:            upstreamMsg,
 					Detail:             upstreamDetail,
 				})
-				return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody, RetryableOnSameAccount: true}
+				return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody, UpstreamRequestID: upstreamRequestID, RetryableOnSameAccount: true}
 			}
 		}
 		if s.shouldFailoverGeminiUpstreamError(resp.StatusCode) {
@@ -1032,7 +1056,7 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 				Message:            upstreamMsg,
 				Detail:             upstreamDetail,
 			})
-			return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody}
+			return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody, UpstreamRequestID: upstreamRequestID}
 		}
 		upstreamReqID := resp.Header.Get(requestIDHeader)
 		if upstreamReqID == "" {
@@ -1054,7 +1078,8 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 	if req.Stream {
 		streamRes, err := s.handleStreamingResponse(c, resp, startTime, originalModel)
 		if err != nil {
-			return nil, err
+			s.handleGeminiStreamRateLimit(ctx, c, account, resp.Header, err, writeGeminiClaudeStreamRateLimitFrame)
+			return nil, s.handleGeminiStreamIdleTimeout(ctx, c, account, originalModel, err, writeGeminiClaudeStreamIdleFrame)
 		}
 		usage = streamRes.usage
 		firstTokenMs = streamRes.firstTokenMs
@@ -1062,8 +1087,12 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 		if useUpstreamStream {
 			collected, usageObj, err := collectGeminiSSE(resp.Body, true)
 			if err != nil {
+				if failoverErr := s.geminiCollectRateLimitFailover(ctx, c, account, resp.Header, err); failoverErr != nil {
+					return nil, failoverErr
+				}
 				return nil, s.writeClaudeError(c, http.StatusBadGateway, "upstream_error", "Failed to read upstream stream")
 			}
+			restoreGeminiFunctionCallNames(c, collected)
 			collectedBytes, _ := json.Marshal(collected)
 			claudeResp, usageObj2 := convertGeminiToClaudeMessage(collected, originalModel, collectedBytes)
 			c.JSON(http.StatusOK, claudeResp)
@@ -1127,6 +1156,12 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 		body = filteredBody
 	}
 
+	timeoutWatchdog := beginUpstreamTimeoutTier(ctx, c, s.cfg, account.Platform, originalModel)
+	if !stream {
+		timeoutWatchdog.disarm(UpstreamTimeoutPhaseFirstToken)
+	}
+	defer timeoutWatchdog.Stop()
+
 	switch action {
 	case "generateContent", "streamGenerateContent", "countTokens":
 		// ok
@@ -1289,7 +1324,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 
 	var resp *http.Response
 	for attempt := 1; attempt <= geminiMaxRetries; attempt++ {
-		upstreamReq, idHeader, err := buildReq(ctx)
+		upstreamReq, idHeader, err := buildReq(timeoutWatchdog.Bind(ctx))
 		if err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return nil, err
@@ -1302,8 +1337,13 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 		}
 		requestIDHeader = idHeader
 
+		upstreamStart := time.Now()
 		resp, err = s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 		if err != nil {
+			if timeoutErr := timeoutWatchdog.Err(); timeoutErr != nil {
+				return nil, upstreamTimeoutTierFailover(c, account, "", timeoutErr, geminiUpstreamTimeoutFailoverBody)
+			}
 			safeErr := sanitizeUpstreamErrorMessage(err.Error())
 			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
 				Platform:           account.Platform,
@@ -1334,6 +1374,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 			setOpsUpstreamError(c, 0, safeErr, "")
 			return nil, s.writeGoogleError(c, http.StatusBadGateway, "Upstream request failed after retries: "+safeErr)
 		}
+		timeoutWatchdog.HeadersReceived()
 
 		// 错误策略优先：匹配则跳过重试直接处理。
 		if matched, rebuilt := s.checkErrorPolicyInLoop(ctx, account, resp); matched {
@@ -1356,7 +1397,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 				break
 			}
 			if resp.StatusCode == 429 {
-				s.handleGeminiUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+				s.applyGeminiUpstreamError(ctx, c, account, resp.StatusCode, resp.Header, respBody)
 			}
 			if attempt < geminiMaxRetries {
 				upstreamReqID := resp.Header.Get(requestIDHeader)
@@ -1456,7 +1497,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 				c.Data(http.StatusInternalServerError, contentType, respBody)
 				return nil, fmt.Errorf("gemini upstream error: %d (skipped by error policy)", resp.StatusCode)
 			case ErrorPolicyMatched, ErrorPolicyTempUnscheduled:
-				s.handleGeminiUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+				s.applyGeminiUpstreamError(ctx, c, account, resp.StatusCode, resp.Header, respBody)
 				evBody := unwrapIfNeeded(isOAuth, respBody)
 				upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(evBody))
 				upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
@@ -1483,7 +1524,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 		}
 
 		// ErrorPolicyNone → 原有逻辑
-		s.handleGeminiUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+		s.applyGeminiUpstreamError(ctx, c, account, resp.StatusCode, resp.Header, respBody)
 		// 精确匹配服务端配置类 400 错误，触发 failover + 临时封禁
 		if resp.StatusCode == http.StatusBadRequest {
 			msg400 := strings.ToLower(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
@@ -1579,7 +1620,8 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 	if stream {
 		streamRes, err := s.handleNativeStreamingResponse(c, resp, startTime, isOAuth)
 		if err != nil {
-			return nil, err
+			s.handleGeminiStreamRateLimit(ctx, c, account, resp.Header, err, writeGeminiNativeStreamRateLimitFrame)
+			return nil, s.handleGeminiStreamIdleTimeout(ctx, c, account, originalModel, err, writeGeminiNativeStreamIdleFrame)
 		}
 		usage = streamRes.usage
 		firstTokenMs = streamRes.firstTokenMs
@@ -1587,6 +1629,9 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 		if useUpstreamStream {
 			collected, usageObj, err := collectGeminiSSE(resp.Body, isOAuth)
 			if err != nil {
+				if failoverErr := s.geminiCollectRateLimitFailover(ctx, c, account, resp.Header, err); failoverErr != nil {
+					return nil, failoverErr
+				}
 				return nil, s.writeGoogleError(c, http.StatusBadGateway, "Failed to read upstream stream")
 			}
 			b, _ := json.Marshal(collected)
@@ -1754,190 +1799,13 @@ func (s *GeminiMessagesCompatService) writeGeminiMappedError(c *gin.Context, acc
 		return fmt.Errorf("upstream error: %d (passthrough rule matched) message=%s", upstreamStatus, upstreamMsg)
 	}
 
-	var statusCode int
-	var errType, errMsg string
-
-	if mapped := mapGeminiErrorBodyToClaudeError(body); mapped != nil {
-		errType = mapped.Type
-		if mapped.Message != "" {
-			errMsg = mapped.Message
-		}
-		if mapped.StatusCode > 0 {
-			statusCode = mapped.StatusCode
-		}
-	}
-
-	switch upstreamStatus {
-	case 400:
-		if statusCode == 0 {
-			statusCode = http.StatusBadRequest
-		}
-		if errType == "" {
-			errType = "invalid_request_error"
-		}
-		if errMsg == "" {
-			errMsg = "Invalid request"
-		}
-	case 401:
-		if statusCode == 0 {
-			statusCode = http.StatusBadGateway
-		}
-		if errType == "" {
-			errType = "authentication_error"
-		}
-		if errMsg == "" {
-			errMsg = "Upstream authentication failed, please contact administrator"
-		}
-	case 403:
-		if statusCode == 0 {
-			statusCode = http.StatusBadGateway
-		}
-		if errType == "" {
-			errType = "permission_error"
-		}
-		if errMsg == "" {
-			errMsg = "Upstream access forbidden, please contact administrator"
-		}
-	case 404:
-		if statusCode == 0 {
-			statusCode = http.StatusNotFound
-		}
-		if errType == "" {
-			errType = "not_found_error"
-		}
-		if errMsg == "" {
-			errMsg = "Resource not found"
-		}
-	case 429:
-		if statusCode == 0 {
-			statusCode = http.StatusTooManyRequests
-		}
-		if errType == "" {
-			errType = "rate_limit_error"
-		}
-		if errMsg == "" {
-			errMsg = "Upstream rate limit exceeded, please retry later"
-		}
-	case 529:
-		if statusCode == 0 {
-			statusCode = http.StatusServiceUnavailable
-		}
-		if errType == "" {
-			errType = "overloaded_error"
-		}
-		if errMsg == "" {
-			errMsg = "Upstream service overloaded, please retry later"
-		}
-	case 500, 502, 503, 504:
-		if statusCode == 0 {
-			statusCode = http.StatusBadGateway
-		}
-		if errType == "" {
-			switch upstreamStatus {
-			case 504:
-				errType = "timeout_error"
-			case 503:
-				errType = "overloaded_error"
-			default:
-				errType = "api_error"
-			}
-		}
-		if errMsg == "" {
-			errMsg = "Upstream service temporarily unavailable"
-		}
-	default:
-		if statusCode == 0 {
-			statusCode = http.StatusBadGateway
-		}
-		if errType == "" {
-			errType = "upstream_error"
-		}
-		if errMsg == "" {
-			errMsg = "Upstream request failed"
-		}
-	}
-
-	c.JSON(statusCode, gin.H{
-		"type":  "error",
-		"error": gin.H{"type": errType, "message": errMsg},
-	})
+	TranslateGeminiErrorToClaude(upstreamStatus, body).Write(c)
 	if upstreamMsg == "" {
 		return fmt.Errorf("upstream error: %d", upstreamStatus)
 	}
 	return fmt.Errorf("upstream error: %d message=%s", upstreamStatus, upstreamMsg)
 }
 
-type claudeErrorMapping struct {
-	Type       string
-	Message    string
-	StatusCode int
-}
-
-func mapGeminiErrorBodyToClaudeError(body []byte) *claudeErrorMapping {
-	if len(body) == 0 {
-		return nil
-	}
-
-	var parsed struct {
-		Error struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-			Status  string `json:"status"`
-		} `json:"error"`
-	}
-	if err := json.Unmarshal(body, &parsed); err != nil {
-		return nil
-	}
-	if strings.TrimSpace(parsed.Error.Status) == "" && parsed.Error.Code == 0 && strings.TrimSpace(parsed.Error.Message) == "" {
-		return nil
-	}
-
-	mapped := &claudeErrorMapping{
-		Type:    mapGeminiStatusToClaudeErrorType(parsed.Error.Status),
-		Message: "",
-	}
-	if mapped.Type == "" {
-		mapped.Type = "upstream_error"
-	}
-
-	switch strings.ToUpper(strings.TrimSpace(parsed.Error.Status)) {
-	case "INVALID_ARGUMENT":
-		mapped.StatusCode = http.StatusBadRequest
-	case "NOT_FOUND":
-		mapped.StatusCode = http.StatusNotFound
-	case "RESOURCE_EXHAUSTED":
-		mapped.StatusCode = http.StatusTooManyRequests
-	default:
-		// Keep StatusCode unset and let HTTP status mapping decide.
-	}
-
-	// Keep messages generic by default; upstream error message can be long or include sensitive fragments.
-	return mapped
-}
-
-func mapGeminiStatusToClaudeErrorType(status string) string {
-	switch strings.ToUpper(strings.TrimSpace(status)) {
-	case "INVALID_ARGUMENT":
-		return "invalid_request_error"
-	case "PERMISSION_DENIED":
-		return "permission_error"
-	case "NOT_FOUND":
-		return "not_found_error"
-	case "RESOURCE_EXHAUSTED":
-		return "rate_limit_error"
-	case "UNAUTHENTICATED":
-		return "authentication_error"
-	case "UNAVAILABLE":
-		return "overloaded_error"
-	case "INTERNAL":
-		return "api_error"
-	case "DEADLINE_EXCEEDED":
-		return "timeout_error"
-	default:
-		return ""
-	}
-}
-
 type geminiStreamResult struct {
 	usage        *ClaudeUsage
 	firstTokenMs *int
@@ -1959,6 +1827,7 @@ func (s *GeminiMessagesCompatService) handleNonStreamingResponse(c *gin.Context,
 		return nil, s.writeClaudeError(c, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
 	}
 
+	restoreGeminiFunctionCallNames(c, geminiResp)
 	claudeResp, usage := convertGeminiToClaudeMessage(geminiResp, originalModel, unwrappedBody)
 	c.JSON(http.StatusOK, claudeResp)
 
@@ -2011,10 +1880,19 @@ func (s *GeminiMessagesCompatService) handleStreamingResponse(c *gin.Context, re
 	openToolName := ""
 	seenToolJSON := ""
 
+	applyStreamIdleTimeout(s.cfg, resp)
 	reader := bufio.NewReader(resp.Body)
 	for {
 		line, err := reader.ReadString('\n')
 		if err != nil && !errors.Is(err, io.EOF) {
+			if timeoutErr := upstreamTimeoutErrFromGin(c); timeoutErr != nil {
+				writeSSE(c.Writer, "error", map[string]any{
+					"type":  "error",
+					"error": map[string]any{"type": "timeout_error", "message": timeoutErr.Error()},
+				})
+				flusher.Flush()
+				return nil, timeoutErr
+			}
 			return nil, fmt.Errorf("stream read error: %w", err)
 		}
 
@@ -2031,6 +1909,9 @@ func (s *GeminiMessagesCompatService) handleStreamingResponse(c *gin.Context, re
 			}
 			continue
 		}
+		if rl := parseGeminiStreamRateLimitFrame([]byte(payload)); rl != nil {
+			return nil, rl
+		}
 
 		unwrappedBytes, err := unwrapGeminiResponse([]byte(payload))
 		if err != nil {
@@ -2094,6 +1975,7 @@ func (s *GeminiMessagesCompatService) handleStreamingResponse(c *gin.Context, re
 				if firstTokenMs == nil {
 					ms := int(time.Since(startTime).Milliseconds())
 					firstTokenMs = &ms
+					markUpstreamFirstToken(c)
 				}
 				writeSSE(c.Writer, "content_block_delta", map[string]any{
 					"type":  "content_block_delta",
@@ -2113,6 +1995,7 @@ func (s *GeminiMessagesCompatService) handleStreamingResponse(c *gin.Context, re
 				if strings.TrimSpace(name) == "" {
 					name = "tool"
 				}
+				name = restoreGeminiToolName(c, name)
 
 				// Close any open text block before tool_use.
 				if openBlockIndex >= 0 {
@@ -2300,6 +2183,9 @@ func collectGeminiSSE(body io.Reader, isOAuth bool) (map[string]any, *ClaudeUsag
 						return mergeCollectedTextParts(pickGeminiCollectResult(last, lastWithParts), collectedTextParts), usage, nil
 					}
 				default:
+					if rl := parseGeminiStreamRateLimitFrame([]byte(payload)); rl != nil {
+						return nil, nil, rl
+					}
 					var parsed map[string]any
 					var rawBytes []byte
 					if isOAuth {
@@ -2501,7 +2387,7 @@ type UpstreamHTTPResult struct {
 }
 
 func (s *GeminiMessagesCompatService) handleNativeNonStreamingResponse(c *gin.Context, resp *http.Response, isOAuth bool) (*ClaudeUsage, error) {
-	if s.cfg != nil && s.cfg.Gateway.GeminiDebugResponseHeaders {
+	if debugLogEnabled(LogComponentGatewayGemini) {
 		logger.LegacyPrintf("service.gemini_messages_compat", "[GeminiAPI] ========== Response Headers ==========")
 		for key, values := range resp.Header {
 			if strings.HasPrefix(strings.ToLower(key), "x-ratelimit") {
@@ -2529,7 +2415,7 @@ func (s *GeminiMessagesCompatService) handleNativeNonStreamingResponse(c *gin.Co
 	if contentType == "" {
 		contentType = "application/json"
 	}
-	c.Data(resp.StatusCode, contentType, respBody)
+	c.Data(resp.StatusCode, contentType, s.responseRedactor.RedactBody(respBody))
 
 	if u := extractGeminiUsage(respBody); u != nil {
 		return u, nil
@@ -2538,7 +2424,7 @@ func (s *GeminiMessagesCompatService) handleNativeNonStreamingResponse(c *gin.Co
 }
 
 func (s *GeminiMessagesCompatService) handleNativeStreamingResponse(c *gin.Context, resp *http.Response, startTime time.Time, isOAuth bool) (*geminiNativeStreamResult, error) {
-	if s.cfg != nil && s.cfg.Gateway.GeminiDebugResponseHeaders {
+	if debugLogEnabled(LogComponentGatewayGemini) {
 		logger.LegacyPrintf("service.gemini_messages_compat", "[GeminiAPI] ========== Streaming Response Headers ==========")
 		for key, values := range resp.Header {
 			if strings.HasPrefix(strings.ToLower(key), "x-ratelimit") {
@@ -2568,6 +2454,7 @@ func (s *GeminiMessagesCompatService) handleNativeStreamingResponse(c *gin.Conte
 		return nil, errors.New("streaming not supported")
 	}
 
+	applyStreamIdleTimeout(s.cfg, resp)
 	reader := bufio.NewReader(resp.Body)
 	usage := &ClaudeUsage{}
 	var firstTokenMs *int
@@ -2583,6 +2470,9 @@ func (s *GeminiMessagesCompatService) handleNativeStreamingResponse(c *gin.Conte
 					_, _ = io.WriteString(c.Writer, line)
 					flusher.Flush()
 				} else {
+					if rl := parseGeminiStreamRateLimitFrame([]byte(payload)); rl != nil {
+						return nil, rl
+					}
 					var rawToWrite string
 					rawToWrite = payload
 
@@ -2604,14 +2494,15 @@ func (s *GeminiMessagesCompatService) handleNativeStreamingResponse(c *gin.Conte
 					if firstTokenMs == nil {
 						ms := int(time.Since(startTime).Milliseconds())
 						firstTokenMs = &ms
+						markUpstreamFirstToken(c)
 					}
 
 					if isOAuth {
 						// SSE format requires double newline (\n\n) to separate events
-						_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", rawToWrite)
+						_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", s.responseRedactor.RedactBody([]byte(rawToWrite)))
 					} else {
 						// Pass-through for AI Studio responses.
-						_, _ = io.WriteString(c.Writer, line)
+						_, _ = io.WriteString(c.Writer, s.responseRedactor.RedactSSE(line))
 					}
 					flusher.Flush()
 				}
@@ -2625,6 +2516,14 @@ func (s *GeminiMessagesCompatService) handleNativeStreamingResponse(c *gin.Conte
 			break
 		}
 		if err != nil {
+			if timeoutErr := upstreamTimeoutErrFromGin(c); timeoutErr != nil {
+				payload, _ := json.Marshal(map[string]any{
+					"error": map[string]any{"code": http.StatusGatewayTimeout, "message": timeoutErr.Error(), "status": "DEADLINE_EXCEEDED"},
+				})
+				_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", payload)
+				flusher.Flush()
+				return nil, timeoutErr
+			}
 			return nil, err
 		}
 	}
@@ -2828,17 +2727,18 @@ func asInt(v any) (int, bool) {
 	}
 }
 
-func (s *GeminiMessagesCompatService) handleGeminiUpstreamError(ctx context.Context, account *Account, statusCode int, headers http.Header, body []byte) {
+// handleGeminiUpstreamError 按状态码更新账号限流状态，返回 429 实际应用的冷却截止时间（未设置时为零值）。
+func (s *GeminiMessagesCompatService) handleGeminiUpstreamError(ctx context.Context, account *Account, statusCode int, headers http.Header, body []byte) time.Time {
 	// 遵守自定义错误码策略：未命中则跳过所有限流处理
 	if !account.ShouldHandleErrorCode(statusCode) {
-		return
+		return time.Time{}
 	}
 	if s.rateLimitService != nil && (statusCode == 401 || statusCode == 403 || statusCode == 529) {
 		s.rateLimitService.HandleUpstreamError(ctx, account, statusCode, headers, body)
-		return
+		return time.Time{}
 	}
 	if statusCode != 429 {
-		return
+		return time.Time{}
 	}
 
 	oauthType := account.GeminiOAuthType()
@@ -2846,7 +2746,7 @@ func (s *GeminiMessagesCompatService) handleGeminiUpstreamError(ctx context.Cont
 	projectID := strings.TrimSpace(account.GetCredential("project_id"))
 	isCodeAssist := account.IsGeminiCodeAssist()
 
-	resetAt := ParseGeminiRateLimitResetTime(body)
+	resetAt := ParseGeminiRateLimitResetTimeWithPolicy(body, geminiResetDelayPolicyFromConfig(s.cfg))
 	if resetAt == nil {
 		// 根据账号类型使用不同的默认重置时间
 		var ra time.Time
@@ -2874,7 +2774,7 @@ func (s *GeminiMessagesCompatService) handleGeminiUpstreamError(ctx context.Cont
 			}
 		}
 		_ = s.accountRepo.SetRateLimited(ctx, account.ID, ra)
-		return
+		return ra
 	}
 
 	// 使用解析到的重置时间
@@ -2882,15 +2782,65 @@ func (s *GeminiMessagesCompatService) handleGeminiUpstreamError(ctx context.Cont
 	_ = s.accountRepo.SetRateLimited(ctx, account.ID, resetTime)
 	logger.LegacyPrintf("service.gemini_messages_compat", "[Gemini 429] Account %d rate limited until %v (oauth_type=%s, tier=%s)",
 		account.ID, resetTime, oauthType, tierID)
+	return resetTime
+}
+
+// GeminiResetDelayPolicy Gemini 限流重置等待的取整策略（gateway.gemini_rate_limit_reset），
+// 对 quotaResetDelay、"Please retry in Xs" 与每日配额三种来源统一生效。零值为向上取整、无下限。
+type GeminiResetDelayPolicy struct {
+	// Round 为 true 时四舍五入，否则向上取整
+	Round bool
+	// MinSeconds 重置等待的下限（秒）
+	MinSeconds int64
+}
+
+// geminiResetDelayPolicyFromConfig 读取 gateway.gemini_rate_limit_reset 配置
+func geminiResetDelayPolicyFromConfig(cfg *config.Config) GeminiResetDelayPolicy {
+	if cfg == nil {
+		return GeminiResetDelayPolicy{}
+	}
+	return GeminiResetDelayPolicy{
+		Round:      cfg.Gateway.GeminiRateLimitReset.Rounding == "round",
+		MinSeconds: int64(cfg.Gateway.GeminiRateLimitReset.MinSeconds),
+	}
 }
 
-// ParseGeminiRateLimitResetTime 解析 Gemini 格式的 429 响应，返回重置时间的 Unix 时间戳
+// resetAfter 按策略将等待时长换算为重置时间的 Unix 时间戳
+func (p GeminiResetDelayPolicy) resetAfter(now int64, delay time.Duration) int64 {
+	var seconds int64
+	if p.Round {
+		seconds = int64(math.Round(delay.Seconds()))
+	} else {
+		// Use ceil to avoid undercounting fractional seconds (e.g. 10.1s should not become 10s),
+		// which can affect scheduling decisions around thresholds (like 10s).
+		seconds = int64(math.Ceil(delay.Seconds()))
+	}
+	return p.floor(now, now+seconds)
+}
+
+// floor 保证重置时间不早于 now + MinSeconds
+func (p GeminiResetDelayPolicy) floor(now, resetAt int64) int64 {
+	if earliest := now + p.MinSeconds; resetAt < earliest {
+		return earliest
+	}
+	return resetAt
+}
+
+// ParseGeminiRateLimitResetTime 解析 Gemini 格式的 429 响应，返回重置时间的 Unix 时间戳（默认策略：向上取整）
 func ParseGeminiRateLimitResetTime(body []byte) *int64 {
+	return ParseGeminiRateLimitResetTimeWithPolicy(body, GeminiResetDelayPolicy{})
+}
+
+// ParseGeminiRateLimitResetTimeWithPolicy 按指定取整策略解析 Gemini 429 响应的重置时间
+func ParseGeminiRateLimitResetTimeWithPolicy(body []byte, policy GeminiResetDelayPolicy) *int64 {
+	now := time.Now().Unix()
+
 	// 第一阶段：gjson 结构化提取
 	errMsg := gjson.GetBytes(body, "error.message").String()
 	if looksLikeGeminiDailyQuota(errMsg) {
 		if ts := nextGeminiDailyResetUnix(); ts != nil {
-			return ts
+			resetAt := policy.floor(now, *ts)
+			return &resetAt
 		}
 	}
 
@@ -2902,9 +2852,7 @@ func ParseGeminiRateLimitResetTime(body []byte) *int64 {
 			return true
 		}
 		if dur, err := time.ParseDuration(v); err == nil {
-			// Use ceil to avoid undercounting fractional seconds (e.g. 10.1s should not become 10s),
-			// which can affect scheduling decisions around thresholds (like 10s).
-			ts := time.Now().Unix() + int64(math.Ceil(dur.Seconds()))
+			ts := policy.resetAfter(now, dur)
 			found = &ts
 			return false
 		}
@@ -2918,7 +2866,7 @@ func ParseGeminiRateLimitResetTime(body []byte) *int64 {
 	matches := retryInRegex.FindStringSubmatch(string(body))
 	if len(matches) == 2 {
 		if dur, err := time.ParseDuration(matches[1] + "s"); err == nil {
-			ts := time.Now().Unix() + int64(math.Ceil(dur.Seconds()))
+			ts := policy.resetAfter(now, dur)
 			return &ts
 		}
 	}
@@ -3060,9 +3008,15 @@ func convertClaudeMessagesToGeminiGenerateContent(body []byte) ([]byte, error) {
 	}
 
 	toolUseIDToName := make(map[string]string)
+	toolNames := buildGeminiToolNameMapping(req["tools"])
+
+	toolConfig, err := convertClaudeToolChoiceToGeminiToolConfig(req["tool_choice"], req["tools"], toolNames)
+	if err != nil {
+		return nil, err
+	}
 
 	systemText := extractClaudeSystemText(req["system"])
-	contents, err := convertClaudeMessagesToGeminiContents(req["messages"], toolUseIDToName)
+	contents, err := convertClaudeMessagesToGeminiContents(req["messages"], toolUseIDToName, toolNames)
 	if err != nil {
 		return nil, err
 	}
@@ -3076,8 +3030,12 @@ func convertClaudeMessagesToGeminiGenerateContent(body []byte) ([]byte, error) {
 	out["contents"] = contents
 
 	if tools := convertClaudeToolsToGeminiTools(req["tools"]); tools != nil {
+		applyGeminiToolNameMappingToDeclarations(tools, toolNames)
 		out["tools"] = tools
 	}
+	if toolConfig != nil {
+		out["toolConfig"] = toolConfig
+	}
 
 	generationConfig := convertClaudeGenerationConfig(req)
 	if generationConfig != nil {
@@ -3142,7 +3100,7 @@ func extractClaudeSystemText(system any) string {
 	}
 }
 
-func convertClaudeMessagesToGeminiContents(messages any, toolUseIDToName map[string]string) ([]any, error) {
+func convertClaudeMessagesToGeminiContents(messages any, toolUseIDToName map[string]string, toolNames *geminiToolNameMapping) ([]any, error) {
 	arr, ok := messages.([]any)
 	if !ok {
 		return nil, errors.New("messages must be an array")
@@ -3199,7 +3157,7 @@ func convertClaudeMessagesToGeminiContents(messages any, toolUseIDToName map[str
 					parts = append(parts, map[string]any{
 						"thoughtSignature": signature,
 						"functionCall": map[string]any{
-							"name": name,
+							"name": toolNames.toGemini(name),
 							"args": bm["input"],
 						},
 					})
@@ -3209,6 +3167,7 @@ func convertClaudeMessagesToGeminiContents(messages any, toolUseIDToName map[str
 					if name == "" {
 						name = "tool"
 					}
+					name = toolNames.toGemini(name)
 					parts = append(parts, map[string]any{
 						"functionResponse": map[string]any{
 							"name": name,
@@ -3217,20 +3176,12 @@ func convertClaudeMessagesToGeminiContents(messages any, toolUseIDToName map[str
 							},
 						},
 					})
-				case "image":
-					if src, ok := bm["source"].(map[string]any); ok {
-						if srcType, _ := src["type"].(string); srcType == "base64" {
-							mediaType, _ := src["media_type"].(string)
-							data, _ := src["data"].(string)
-							if mediaType != "" && data != "" {
-								parts = append(parts, map[string]any{
-									"inlineData": map[string]any{
-										"mimeType": mediaType,
-										"data":     data,
-									},
-								})
-							}
-						}
+					// tool_result 中的截图等附件紧随 functionResponse 作为 inlineData 兄弟 part
+					parts = append(parts, convertClaudeToolResultAttachmentsToGeminiParts(bm["content"])...)
+				case "image", "document":
+					// 大小限制与 URL 下载由 prepareClaudeAttachmentsForGemini 预先处理
+					if part := convertClaudeAttachmentBlockToGeminiPart(bm); part != nil {
+						parts = append(parts, part)
 					}
 				default:
 					// best-effort: preserve unknown blocks as text
@@ -3492,3 +3443,6 @@ func (s *GeminiMessagesCompatService) extractImageInputSize(body []byte) string
 
 	return ""
 }
+
+// geminiUpstreamTimeoutFailoverBody 超时分级在收到响应头前触发时 failover 错误携带的 Google 格式响应体
+var geminiUpstreamTimeoutFailoverBody = []byte(`{"error":{"code":504,"message":"Upstream request timed out","status":"DEADLINE_EXCEEDED"}}`)
@@ -82,6 +82,8 @@ func TestGeminiForwardAsChatCompletions_OAuthRoutesToGeminiAndReturnsChatFormat(
 	require.Equal(t, "gemini-2.5-flash", result.Model)
 	require.Equal(t, 7, result.Usage.InputTokens)
 	require.Equal(t, 3, result.Usage.OutputTokens)
+	_, latencyRecorded := c.Get(OpsUpstreamLatencyMsKey)
+	require.True(t, latencyRecorded, "upstream latency should be recorded for routing")
 
 	require.NotNil(t, httpStub.lastReq)
 	require.Contains(t, httpStub.lastReq.URL.String(), "/v1internal:streamGenerateContent?alt=sse")
@@ -877,6 +879,42 @@ func TestParseGeminiRateLimitResetTime(t *testing.T) {
 	}
 }
 
+func TestParseGeminiRateLimitResetTimeWithPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		policy    GeminiResetDelayPolicy
+		wantDelta int64
+	}{
+		{name: "默认向上取整", input: `{"error":{"details":[{"metadata":{"quotaResetDelay":"12.4s"}}]}}`, wantDelta: 13},
+		{name: "四舍五入向下", input: `{"error":{"details":[{"metadata":{"quotaResetDelay":"12.4s"}}]}}`, policy: GeminiResetDelayPolicy{Round: true}, wantDelta: 12},
+		{name: "四舍五入向上", input: `{"error":{"details":[{"metadata":{"quotaResetDelay":"2.6s"}}]}}`, policy: GeminiResetDelayPolicy{Round: true}, wantDelta: 3},
+		{name: "四舍五入为 0 时应用下限", input: `{"error":{"details":[{"metadata":{"quotaResetDelay":"0.4s"}}]}}`, policy: GeminiResetDelayPolicy{Round: true, MinSeconds: 1}, wantDelta: 1},
+		{name: "regex 回退同样四舍五入", input: `Please retry in 7.2s`, policy: GeminiResetDelayPolicy{Round: true}, wantDelta: 7},
+		{name: "下限大于解析值", input: `Please retry in 3s`, policy: GeminiResetDelayPolicy{MinSeconds: 5}, wantDelta: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now().Unix()
+			got := ParseGeminiRateLimitResetTimeWithPolicy([]byte(tt.input), tt.policy)
+			after := time.Now().Unix()
+
+			require.NotNil(t, got)
+			require.GreaterOrEqual(t, *got, before+tt.wantDelta)
+			require.LessOrEqual(t, *got, after+tt.wantDelta)
+		})
+	}
+}
+
+func TestGeminiResetDelayPolicyFromConfig(t *testing.T) {
+	require.Equal(t, GeminiResetDelayPolicy{}, geminiResetDelayPolicyFromConfig(nil))
+
+	cfg := &config.Config{}
+	cfg.Gateway.GeminiRateLimitReset = config.GatewayGeminiRateLimitResetConfig{Rounding: "round", MinSeconds: 2}
+	require.Equal(t, GeminiResetDelayPolicy{Round: true, MinSeconds: 2}, geminiResetDelayPolicyFromConfig(cfg))
+}
+
 // TestGeminiMessagesHandleStreamingResponse_ClosesToolBlockBeforeText guards the
 // tool→text ordering in the Gemini→Anthropic (messages) streaming bridge. When
 // Gemini emits a functionCall part followed by a text part, the tool_use content
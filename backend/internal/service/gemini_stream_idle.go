@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// handleGeminiStreamIdleTimeout 处理 Gemini 流式转发中途的上游空闲超时：按流超时策略标记账号；
+// 尚未向客户端写出任何内容时返回 failover 错误切换账号，否则以客户端协议写出终止错误并返回超时错误。
+// err 不是空闲超时时原样返回。
+func (s *GeminiMessagesCompatService) handleGeminiStreamIdleTimeout(ctx context.Context, c *gin.Context, account *Account, model string, err error, writeFrame func(w io.Writer, message string)) error {
+	idleErr, ok := asStreamIdleTimeout(err)
+	if !ok || c == nil {
+		return err
+	}
+	if account != nil {
+		logger.LegacyPrintf("service.gemini_messages_compat", "Stream idle timeout: account=%d model=%s idle=%s", account.ID, model, idleErr.Timeout)
+	}
+	if s.rateLimitService != nil {
+		s.rateLimitService.HandleStreamTimeout(ctx, account, model)
+	}
+	setOpsUpstreamError(c, http.StatusGatewayTimeout, idleErr.Error(), "")
+	if !c.Writer.Written() {
+		return &UpstreamFailoverError{StatusCode: http.StatusGatewayTimeout}
+	}
+	writeFrame(c.Writer, idleErr.Error())
+	if flusher, ok := c.Writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	MarkResponseCommitted(c)
+	return idleErr
+}
+
+func writeGeminiClaudeStreamIdleFrame(w io.Writer, message string) {
+	writeSSE(w, "error", map[string]any{
+		"type": "error",
+		"error": map[string]any{
+			"type":    "stream_timeout",
+			"message": message,
+		},
+	})
+}
+
+func writeGeminiChatStreamIdleFrame(w io.Writer, message string) {
+	writeSSE(w, "", map[string]any{
+		"error": map[string]any{
+			"type":    "stream_timeout",
+			"code":    "stream_timeout",
+			"message": message,
+		},
+	})
+}
+
+func writeGeminiNativeStreamIdleFrame(w io.Writer, message string) {
+	writeSSE(w, "", map[string]any{
+		"error": map[string]any{
+			"code":    http.StatusGatewayTimeout,
+			"status":  "DEADLINE_EXCEEDED",
+			"message": message,
+		},
+	})
+}
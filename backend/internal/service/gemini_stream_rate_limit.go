@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// geminiStreamRateLimitError 表示 Gemini 上游在 200 SSE 流中途以错误帧下发 429（RESOURCE_EXHAUSTED）。
+// Body 为 {"error":{...}} 形式的错误帧，可直接交给 ParseGeminiRateLimitResetTime 解析重置时间。
+type geminiStreamRateLimitError struct {
+	Body []byte
+}
+
+func (e *geminiStreamRateLimitError) Error() string {
+	return fmt.Sprintf("gemini upstream rate limited mid-stream: %s", sanitizeUpstreamErrorMessage(extractUpstreamErrorMessage(e.Body)))
+}
+
+// parseGeminiStreamRateLimitFrame 识别 SSE data 帧中的 429 错误；Code Assist 可能把错误包在 response 字段里。
+func parseGeminiStreamRateLimitFrame(payload []byte) *geminiStreamRateLimitError {
+	errObj := gjson.GetBytes(payload, "error")
+	if !errObj.Exists() {
+		errObj = gjson.GetBytes(payload, "response.error")
+	}
+	if !errObj.IsObject() {
+		return nil
+	}
+	if errObj.Get("code").Int() != http.StatusTooManyRequests && errObj.Get("status").String() != "RESOURCE_EXHAUSTED" {
+		return nil
+	}
+	return &geminiStreamRateLimitError{Body: []byte(`{"error":` + errObj.Raw + `}`)}
+}
+
+// applyGeminiUpstreamError 在 handleGeminiUpstreamError 基础上，把 429 实际应用的账号冷却截止时间记入 ops 上游错误事件。
+func (s *GeminiMessagesCompatService) applyGeminiUpstreamError(ctx context.Context, c *gin.Context, account *Account, statusCode int, headers http.Header, body []byte) {
+	resetAt := s.handleGeminiUpstreamError(ctx, account, statusCode, headers, body)
+	if resetAt.IsZero() {
+		return
+	}
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+		Platform:           account.Platform,
+		AccountID:          account.ID,
+		AccountName:        account.Name,
+		UpstreamStatusCode: statusCode,
+		Kind:               "rate_limit_cooldown",
+		Message:            "account cooldown until " + resetAt.UTC().Format(time.RFC3339),
+		CooldownUntilUnix:  resetAt.Unix(),
+	})
+}
+
+// handleGeminiStreamRateLimit 处理流式转发中途的 429 错误帧：设置账号限流状态，
+// 并以客户端协议写出终止错误（writeFrame 为 nil 时只更新状态，由调用方决定响应）。
+func (s *GeminiMessagesCompatService) handleGeminiStreamRateLimit(ctx context.Context, c *gin.Context, account *Account, headers http.Header, err error, writeFrame func(w io.Writer, message string)) {
+	var rl *geminiStreamRateLimitError
+	if !errors.As(err, &rl) {
+		return
+	}
+	s.applyGeminiUpstreamError(ctx, c, account, http.StatusTooManyRequests, headers, rl.Body)
+
+	message := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(rl.Body)))
+	setOpsUpstreamError(c, http.StatusTooManyRequests, message, "")
+	if writeFrame == nil || c == nil {
+		return
+	}
+	if message == "" {
+		message = "Upstream rate limit exceeded, please retry later"
+	}
+	writeFrame(c.Writer, message)
+	if flusher, ok := c.Writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	MarkResponseCommitted(c)
+}
+
+// geminiCollectRateLimitFailover 用于非流式客户端聚合上游 SSE 的路径：此时尚未向客户端写出任何内容，
+// 429 错误帧按普通 429 处理并返回 failover 错误，由 handler 切换账号重试。
+func (s *GeminiMessagesCompatService) geminiCollectRateLimitFailover(ctx context.Context, c *gin.Context, account *Account, headers http.Header, err error) *UpstreamFailoverError {
+	var rl *geminiStreamRateLimitError
+	if !errors.As(err, &rl) {
+		return nil
+	}
+	s.handleGeminiStreamRateLimit(ctx, c, account, headers, err, nil)
+	return &UpstreamFailoverError{StatusCode: http.StatusTooManyRequests, ResponseBody: rl.Body}
+}
+
+func writeGeminiClaudeStreamRateLimitFrame(w io.Writer, message string) {
+	writeSSE(w, "error", map[string]any{
+		"type": "error",
+		"error": map[string]any{
+			"type":    "rate_limit_error",
+			"message": message,
+		},
+	})
+}
+
+func writeGeminiChatStreamRateLimitFrame(w io.Writer, message string) {
+	writeSSE(w, "", map[string]any{
+		"error": map[string]any{
+			"type":    "rate_limit_error",
+			"code":    "rate_limit_exceeded",
+			"message": message,
+		},
+	})
+}
+
+func writeGeminiNativeStreamRateLimitFrame(w io.Writer, message string) {
+	writeSSE(w, "", map[string]any{
+		"error": map[string]any{
+			"code":    http.StatusTooManyRequests,
+			"status":  "RESOURCE_EXHAUSTED",
+			"message": message,
+		},
+	})
+}
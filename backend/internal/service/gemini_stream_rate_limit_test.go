@@ -0,0 +1,137 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const geminiQuotaResetDelay429Body = `{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED","details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","metadata":{"quotaResetDelay":"45s"}}]}}`
+
+func newGeminiRateLimitTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	return c, rec
+}
+
+func newGeminiRateLimitTestAccount() *Account {
+	return &Account{
+		ID:          901,
+		Name:        "gemini-aistudio",
+		Platform:    PlatformGemini,
+		Type:        AccountTypeAPIKey,
+		Status:      StatusActive,
+		Schedulable: true,
+	}
+}
+
+func requireGeminiCooldownApplied(t *testing.T, c *gin.Context, repo *rateLimit429AccountRepoStub, account *Account, before time.Time) {
+	t.Helper()
+	require.Equal(t, 1, repo.rateLimitCalls)
+	require.Equal(t, account.ID, repo.lastRateLimitID)
+	require.WithinDuration(t, before.Add(45*time.Second), repo.lastRateLimitReset, 2*time.Second)
+
+	v, ok := c.Get(OpsUpstreamErrorsKey)
+	require.True(t, ok)
+	events, _ := v.([]*OpsUpstreamErrorEvent)
+	var cooldown *OpsUpstreamErrorEvent
+	for _, ev := range events {
+		if ev.Kind == "rate_limit_cooldown" {
+			cooldown = ev
+		}
+	}
+	require.NotNil(t, cooldown)
+	require.Equal(t, repo.lastRateLimitReset.Unix(), cooldown.CooldownUntilUnix)
+
+	// 账号在冷却窗口内不可调度，窗口结束后恢复
+	resetAt := repo.lastRateLimitReset
+	account.RateLimitResetAt = &resetAt
+	require.False(t, account.IsSchedulable())
+	elapsed := time.Now().Add(-time.Second)
+	account.RateLimitResetAt = &elapsed
+	require.True(t, account.IsSchedulable())
+}
+
+func TestParseGeminiStreamRateLimitFrame(t *testing.T) {
+	rl := parseGeminiStreamRateLimitFrame([]byte(geminiQuotaResetDelay429Body))
+	require.NotNil(t, rl)
+	require.NotNil(t, ParseGeminiRateLimitResetTime(rl.Body))
+
+	wrapped := parseGeminiStreamRateLimitFrame([]byte(`{"response":{"error":{"code":429,"message":"Please retry in 12s","status":"RESOURCE_EXHAUSTED"}}}`))
+	require.NotNil(t, wrapped)
+	require.Contains(t, string(wrapped.Body), `"code":429`)
+
+	require.Nil(t, parseGeminiStreamRateLimitFrame([]byte(`{"error":{"code":500,"message":"internal","status":"INTERNAL"}}`)))
+	require.Nil(t, parseGeminiStreamRateLimitFrame([]byte(`{"candidates":[{"content":{"parts":[{"text":"hi"}]}}]}`)))
+}
+
+func TestApplyGeminiUpstreamError_NonStreaming429SetsCooldown(t *testing.T) {
+	repo := &rateLimit429AccountRepoStub{}
+	svc := &GeminiMessagesCompatService{accountRepo: repo}
+	account := newGeminiRateLimitTestAccount()
+	c, _ := newGeminiRateLimitTestContext()
+
+	before := time.Now()
+	svc.applyGeminiUpstreamError(context.Background(), c, account, http.StatusTooManyRequests, http.Header{}, []byte(geminiQuotaResetDelay429Body))
+
+	requireGeminiCooldownApplied(t, c, repo, account, before)
+}
+
+func TestGeminiHandleStreamingResponse_MidStream429SetsCooldown(t *testing.T) {
+	repo := &rateLimit429AccountRepoStub{}
+	svc := &GeminiMessagesCompatService{accountRepo: repo}
+	account := newGeminiRateLimitTestAccount()
+	c, rec := newGeminiRateLimitTestContext()
+
+	upstreamBody := `data: {"candidates":[{"content":{"parts":[{"text":"Hello"}]}}]}` + "\n\n" +
+		"data: " + geminiQuotaResetDelay429Body + "\n\n"
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(upstreamBody)),
+	}
+
+	before := time.Now()
+	result, err := svc.handleStreamingResponse(c, resp, time.Now(), "claude-sonnet-4-5")
+	require.Nil(t, result)
+	var rl *geminiStreamRateLimitError
+	require.ErrorAs(t, err, &rl)
+
+	svc.handleGeminiStreamRateLimit(context.Background(), c, account, resp.Header, err, writeGeminiClaudeStreamRateLimitFrame)
+
+	requireGeminiCooldownApplied(t, c, repo, account, before)
+	require.True(t, IsResponseCommitted(c))
+	body := rec.Body.String()
+	require.Contains(t, body, "Hello")
+	require.Contains(t, body, "event: error")
+	require.Contains(t, body, `"type":"rate_limit_error"`)
+}
+
+func TestGeminiCollectRateLimitFailover_UpstreamStream429(t *testing.T) {
+	repo := &rateLimit429AccountRepoStub{}
+	svc := &GeminiMessagesCompatService{accountRepo: repo}
+	account := newGeminiRateLimitTestAccount()
+	c, rec := newGeminiRateLimitTestContext()
+
+	_, _, err := collectGeminiSSE(strings.NewReader("data: "+geminiQuotaResetDelay429Body+"\n\n"), false)
+	require.Error(t, err)
+
+	before := time.Now()
+	failoverErr := svc.geminiCollectRateLimitFailover(context.Background(), c, account, http.Header{}, err)
+	require.NotNil(t, failoverErr)
+	require.Equal(t, http.StatusTooManyRequests, failoverErr.StatusCode)
+	require.Zero(t, rec.Body.Len(), "failover path must not write to the client")
+
+	requireGeminiCooldownApplied(t, c, repo, account, before)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// geminiToolNameMappingKey 是 gin.Context 上存 Gemini 函数名映射的 key。
+// 请求阶段写入，响应阶段读取，用于把 sanitized 名称还原为客户端原始工具名。
+const geminiToolNameMappingKey = "gemini_tool_name_mapping"
+
+// geminiFunctionNameMaxLen 是 Gemini functionDeclaration.name 的最大长度。
+const geminiFunctionNameMaxLen = 64
+
+// geminiToolNameMapping 是单次请求内 Claude 工具名与 Gemini 函数名的双向映射。
+//   - Forward: 原始名 → sanitized 名，请求阶段用于 functionDeclarations / functionCall / allowedFunctionNames。
+//   - Reverse: sanitized 名 → 原始名，响应阶段还原 tool_use.name。
+//
+// 只记录确实发生改写的名称；合法名称不会出现在映射中。
+type geminiToolNameMapping struct {
+	Forward map[string]string
+	Reverse map[string]string
+}
+
+// toGemini 返回 name 在 Gemini 侧使用的函数名。
+// 不在 tools 里的名称（例如历史消息中的 tool_use）按同样规则做无冲突处理的 sanitize。
+func (m *geminiToolNameMapping) toGemini(name string) string {
+	if m != nil {
+		if mapped, ok := m.Forward[name]; ok {
+			return mapped
+		}
+	}
+	return sanitizeGeminiFunctionName(name)
+}
+
+// toClaude 把 Gemini 返回的函数名还原为客户端原始工具名。
+func (m *geminiToolNameMapping) toClaude(name string) string {
+	if m != nil {
+		if original, ok := m.Reverse[name]; ok {
+			return original
+		}
+	}
+	return name
+}
+
+// isValidGeminiFunctionNameChar 判断字符是否可用于 Gemini 函数名：a-z, A-Z, 0-9, '_', '.', ':', '-'。
+func isValidGeminiFunctionNameChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		r == '_' || r == '.' || r == ':' || r == '-'
+}
+
+// sanitizeGeminiFunctionName 把任意工具名转换为 Gemini 接受的函数名：
+// 非法字符替换为 '_'，首字符必须是字母或下划线，长度截断到 64。
+func sanitizeGeminiFunctionName(name string) string {
+	if name == "" {
+		return name
+	}
+	var sb strings.Builder
+	sb.Grow(len(name))
+	for _, r := range name {
+		if isValidGeminiFunctionNameChar(r) {
+			_, _ = sb.WriteRune(r)
+		} else {
+			_ = sb.WriteByte('_')
+		}
+	}
+	out := sb.String()
+	if first := out[0]; !((first >= 'a' && first <= 'z') || (first >= 'A' && first <= 'Z') || first == '_') {
+		out = "_" + out
+	}
+	if len(out) > geminiFunctionNameMaxLen {
+		out = out[:geminiFunctionNameMaxLen]
+	}
+	return out
+}
+
+// buildGeminiToolNameMapping 扫描 Claude tools 数组，为需要改写的工具名生成映射。
+// sanitize 后与其他工具名冲突时追加 "_2"、"_3" 等后缀保证唯一。
+// 所有名称都合法时返回 nil。
+func buildGeminiToolNameMapping(tools any) *geminiToolNameMapping {
+	arr, ok := tools.([]any)
+	if !ok || len(arr) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(arr))
+	used := make(map[string]struct{}, len(arr))
+	for _, t := range arr {
+		tm, ok := t.(map[string]any)
+		if !ok || isClaudeWebSearchToolMap(tm) {
+			continue
+		}
+		name, _ := tm["name"].(string)
+		if name == "" {
+			continue
+		}
+		names = append(names, name)
+		if sanitizeGeminiFunctionName(name) == name {
+			used[name] = struct{}{}
+		}
+	}
+
+	var mapping *geminiToolNameMapping
+	for _, name := range names {
+		sanitized := sanitizeGeminiFunctionName(name)
+		if sanitized == name {
+			continue
+		}
+		if mapping == nil {
+			mapping = &geminiToolNameMapping{
+				Forward: make(map[string]string),
+				Reverse: make(map[string]string),
+			}
+		}
+		if _, exists := mapping.Forward[name]; exists {
+			continue
+		}
+		candidate := sanitized
+		for i := 2; ; i++ {
+			if _, taken := used[candidate]; !taken {
+				break
+			}
+			suffix := "_" + strconv.Itoa(i)
+			base := sanitized
+			if len(base)+len(suffix) > geminiFunctionNameMaxLen {
+				base = base[:geminiFunctionNameMaxLen-len(suffix)]
+			}
+			candidate = base + suffix
+		}
+		used[candidate] = struct{}{}
+		mapping.Forward[name] = candidate
+		mapping.Reverse[candidate] = name
+	}
+	return mapping
+}
+
+// geminiToolNameMappingFromClaudeBody 从 Claude 请求体重新构造工具名映射（与转换阶段结果一致）。
+func geminiToolNameMappingFromClaudeBody(body []byte) *geminiToolNameMapping {
+	var req struct {
+		Tools any `json:"tools"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil
+	}
+	return buildGeminiToolNameMapping(req.Tools)
+}
+
+// setGeminiToolNameMapping 在 gin.Context 上保存本次请求的工具名映射，无需改写时不写入。
+func setGeminiToolNameMapping(c interface{ Set(string, any) }, claudeBody []byte) {
+	if c == nil {
+		return
+	}
+	if mapping := geminiToolNameMappingFromClaudeBody(claudeBody); mapping != nil {
+		c.Set(geminiToolNameMappingKey, mapping)
+	}
+}
+
+// geminiToolNameMappingFromContext 取出请求阶段保存的工具名映射；找不到时返回 nil。
+func geminiToolNameMappingFromContext(c interface {
+	Get(string) (any, bool)
+}) *geminiToolNameMapping {
+	if c == nil {
+		return nil
+	}
+	raw, ok := c.Get(geminiToolNameMappingKey)
+	if !ok || raw == nil {
+		return nil
+	}
+	mapping, _ := raw.(*geminiToolNameMapping)
+	return mapping
+}
+
+// restoreGeminiToolName 把流式响应中的 Gemini 函数名还原为客户端原始工具名。
+func restoreGeminiToolName(c interface {
+	Get(string) (any, bool)
+}, name string) string {
+	return geminiToolNameMappingFromContext(c).toClaude(name)
+}
+
+// restoreGeminiFunctionCallNames 就地还原 Gemini 响应中 candidates[*].content.parts[*].functionCall.name。
+func restoreGeminiFunctionCallNames(c interface {
+	Get(string) (any, bool)
+}, geminiResp map[string]any) {
+	mapping := geminiToolNameMappingFromContext(c)
+	if mapping == nil || geminiResp == nil {
+		return
+	}
+	candidates, _ := geminiResp["candidates"].([]any)
+	for _, cand := range candidates {
+		cm, ok := cand.(map[string]any)
+		if !ok {
+			continue
+		}
+		content, ok := cm["content"].(map[string]any)
+		if !ok {
+			continue
+		}
+		parts, _ := content["parts"].([]any)
+		for _, p := range parts {
+			pm, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			if fc, ok := pm["functionCall"].(map[string]any); ok && fc != nil {
+				if name, ok := fc["name"].(string); ok {
+					fc["name"] = mapping.toClaude(name)
+				}
+			}
+		}
+	}
+}
+
+// convertClaudeToolChoiceToGeminiToolConfig 把 Claude tool_choice 转换为 Gemini toolConfig：
+//   - {"type":"auto"} → AUTO
+//   - {"type":"any"}  → ANY
+//   - {"type":"none"} → NONE
+//   - {"type":"tool","name":"x"} → ANY + allowedFunctionNames=[sanitized(x)]
+//
+// 命名工具不在 tools 中时返回错误。disable_parallel_tool_use 在 Gemini 的
+// functionCallingConfig 中没有对应字段，忽略。
+// 没有 tool_choice 或没有可用的 functionDeclarations 时返回 nil。
+func convertClaudeToolChoiceToGeminiToolConfig(toolChoice any, tools any, mapping *geminiToolNameMapping) (map[string]any, error) {
+	var choiceType, choiceName string
+	switch v := toolChoice.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		choiceType = v
+	case map[string]any:
+		choiceType, _ = v["type"].(string)
+		choiceName, _ = v["name"].(string)
+	default:
+		return nil, fmt.Errorf("tool_choice must be an object")
+	}
+
+	declared := make(map[string]struct{})
+	if arr, ok := tools.([]any); ok {
+		for _, t := range arr {
+			tm, ok := t.(map[string]any)
+			if !ok || isClaudeWebSearchToolMap(tm) {
+				continue
+			}
+			if name, _ := tm["name"].(string); name != "" {
+				declared[name] = struct{}{}
+			}
+		}
+	}
+
+	fcc := map[string]any{}
+	switch strings.ToLower(strings.TrimSpace(choiceType)) {
+	case "", "auto":
+		fcc["mode"] = "AUTO"
+	case "any":
+		fcc["mode"] = "ANY"
+	case "none":
+		fcc["mode"] = "NONE"
+	case "tool":
+		if strings.TrimSpace(choiceName) == "" {
+			return nil, fmt.Errorf("tool_choice.name is required when tool_choice.type is \"tool\"")
+		}
+		if _, ok := declared[choiceName]; !ok {
+			return nil, fmt.Errorf("tool_choice references tool %q which is not defined in tools", choiceName)
+		}
+		fcc["mode"] = "ANY"
+		fcc["allowedFunctionNames"] = []any{mapping.toGemini(choiceName)}
+	default:
+		return nil, fmt.Errorf("unsupported tool_choice type %q", choiceType)
+	}
+
+	if len(declared) == 0 {
+		return nil, nil
+	}
+	return map[string]any{"functionCallingConfig": fcc}, nil
+}
+
+// applyGeminiToolNameMappingToDeclarations 把 convertClaudeToolsToGeminiTools 产出的
+// functionDeclarations 名称替换为 sanitized 名称，保证与 allowedFunctionNames 一致。
+func applyGeminiToolNameMappingToDeclarations(tools []any, mapping *geminiToolNameMapping) {
+	for _, t := range tools {
+		tm, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		decls, _ := tm["functionDeclarations"].([]any)
+		for _, d := range decls {
+			dm, ok := d.(map[string]any)
+			if !ok {
+				continue
+			}
+			if name, ok := dm["name"].(string); ok {
+				dm["name"] = mapping.toGemini(name)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func convertClaudeToolChoiceForTest(t *testing.T, claudeReq map[string]any) (map[string]any, error) {
+	t.Helper()
+	b, err := json.Marshal(claudeReq)
+	require.NoError(t, err)
+	out, err := convertClaudeMessagesToGeminiGenerateContent(b)
+	if err != nil {
+		return nil, err
+	}
+	var geminiReq map[string]any
+	require.NoError(t, json.Unmarshal(out, &geminiReq))
+	return geminiReq, nil
+}
+
+func toolChoiceTestRequest(toolChoice any, toolNames ...string) map[string]any {
+	tools := make([]any, 0, len(toolNames))
+	for _, name := range toolNames {
+		tools = append(tools, map[string]any{
+			"name":         name,
+			"description":  "test tool",
+			"input_schema": map[string]any{"type": "object", "properties": map[string]any{}},
+		})
+	}
+	req := map[string]any{
+		"model": "gemini-2.5-pro",
+		"messages": []any{
+			map[string]any{"role": "user", "content": "hi"},
+		},
+		"tools": tools,
+	}
+	if toolChoice != nil {
+		req["tool_choice"] = toolChoice
+	}
+	return req
+}
+
+func geminiFunctionCallingConfigForTest(t *testing.T, geminiReq map[string]any) map[string]any {
+	t.Helper()
+	toolConfig, ok := geminiReq["toolConfig"].(map[string]any)
+	require.True(t, ok, "expected toolConfig in gemini request")
+	fcc, ok := toolConfig["functionCallingConfig"].(map[string]any)
+	require.True(t, ok, "expected functionCallingConfig in toolConfig")
+	return fcc
+}
+
+func geminiDeclarationNamesForTest(geminiReq map[string]any) []string {
+	var names []string
+	tools, _ := geminiReq["tools"].([]any)
+	for _, tool := range tools {
+		tm, _ := tool.(map[string]any)
+		decls, _ := tm["functionDeclarations"].([]any)
+		for _, d := range decls {
+			dm, _ := d.(map[string]any)
+			if name, ok := dm["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func TestConvertClaudeToolChoiceToGemini_Modes(t *testing.T) {
+	tests := []struct {
+		name       string
+		toolChoice any
+		wantMode   string
+	}{
+		{name: "auto", toolChoice: map[string]any{"type": "auto"}, wantMode: "AUTO"},
+		{name: "any", toolChoice: map[string]any{"type": "any"}, wantMode: "ANY"},
+		{name: "none", toolChoice: map[string]any{"type": "none"}, wantMode: "NONE"},
+		{name: "any with disable_parallel_tool_use", toolChoice: map[string]any{"type": "any", "disable_parallel_tool_use": true}, wantMode: "ANY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			geminiReq, err := convertClaudeToolChoiceForTest(t, toolChoiceTestRequest(tt.toolChoice, "get_weather"))
+			require.NoError(t, err)
+			fcc := geminiFunctionCallingConfigForTest(t, geminiReq)
+			require.Equal(t, tt.wantMode, fcc["mode"])
+			require.NotContains(t, fcc, "allowedFunctionNames")
+		})
+	}
+}
+
+func TestConvertClaudeToolChoiceToGemini_NamedTool(t *testing.T) {
+	geminiReq, err := convertClaudeToolChoiceForTest(t, toolChoiceTestRequest(
+		map[string]any{"type": "tool", "name": "get_weather"},
+		"get_weather", "search",
+	))
+	require.NoError(t, err)
+
+	fcc := geminiFunctionCallingConfigForTest(t, geminiReq)
+	require.Equal(t, "ANY", fcc["mode"])
+	require.Equal(t, []any{"get_weather"}, fcc["allowedFunctionNames"])
+}
+
+func TestConvertClaudeToolChoiceToGemini_NamedToolSanitized(t *testing.T) {
+	geminiReq, err := convertClaudeToolChoiceForTest(t, toolChoiceTestRequest(
+		map[string]any{"type": "tool", "name": "mcp/github search"},
+		"mcp/github search", "mcp_github_search",
+	))
+	require.NoError(t, err)
+
+	fcc := geminiFunctionCallingConfigForTest(t, geminiReq)
+	allowed, ok := fcc["allowedFunctionNames"].([]any)
+	require.True(t, ok)
+	require.Len(t, allowed, 1)
+	// 与已有合法名称冲突时追加后缀，且 allowedFunctionNames 与 functionDeclarations 一致。
+	require.Equal(t, "mcp_github_search_2", allowed[0])
+	require.ElementsMatch(t, []string{"mcp_github_search_2", "mcp_github_search"}, geminiDeclarationNamesForTest(geminiReq))
+}
+
+func TestConvertClaudeToolChoiceToGemini_NamedToolNotInTools(t *testing.T) {
+	_, err := convertClaudeToolChoiceForTest(t, toolChoiceTestRequest(
+		map[string]any{"type": "tool", "name": "missing_tool"},
+		"get_weather",
+	))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing_tool")
+}
+
+func TestConvertClaudeToolChoiceToGemini_NamedToolMissingName(t *testing.T) {
+	_, err := convertClaudeToolChoiceForTest(t, toolChoiceTestRequest(
+		map[string]any{"type": "tool"},
+		"get_weather",
+	))
+	require.Error(t, err)
+}
+
+func TestConvertClaudeToolChoiceToGemini_NoToolChoice(t *testing.T) {
+	geminiReq, err := convertClaudeToolChoiceForTest(t, toolChoiceTestRequest(nil, "get_weather"))
+	require.NoError(t, err)
+	require.NotContains(t, geminiReq, "toolConfig")
+}
+
+func TestConvertClaudeToolChoiceToGemini_HistoryUsesSanitizedNames(t *testing.T) {
+	req := toolChoiceTestRequest(map[string]any{"type": "any"}, "fs/read")
+	req["messages"] = []any{
+		map[string]any{"role": "user", "content": "read a file"},
+		map[string]any{"role": "assistant", "content": []any{
+			map[string]any{"type": "tool_use", "id": "toolu_1", "name": "fs/read", "input": map[string]any{}},
+		}},
+		map[string]any{"role": "user", "content": []any{
+			map[string]any{"type": "tool_result", "tool_use_id": "toolu_1", "content": "ok"},
+		}},
+	}
+
+	geminiReq, err := convertClaudeToolChoiceForTest(t, req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"fs_read"}, geminiDeclarationNamesForTest(geminiReq))
+
+	contents := geminiReq["contents"].([]any)
+	callPart := contents[1].(map[string]any)["parts"].([]any)[0].(map[string]any)
+	require.Equal(t, "fs_read", callPart["functionCall"].(map[string]any)["name"])
+	respPart := contents[2].(map[string]any)["parts"].([]any)[0].(map[string]any)
+	require.Equal(t, "fs_read", respPart["functionResponse"].(map[string]any)["name"])
+}
+
+func TestRestoreGeminiFunctionCallNames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	body, err := json.Marshal(toolChoiceTestRequest(nil, "fs/read"))
+	require.NoError(t, err)
+	setGeminiToolNameMapping(c, body)
+
+	geminiResp := map[string]any{
+		"candidates": []any{
+			map[string]any{
+				"content": map[string]any{
+					"parts": []any{
+						map[string]any{"functionCall": map[string]any{"name": "fs_read", "args": map[string]any{}}},
+					},
+				},
+			},
+		},
+	}
+	restoreGeminiFunctionCallNames(c, geminiResp)
+
+	claudeResp, _ := convertGeminiToClaudeMessage(geminiResp, "gemini-2.5-pro", nil)
+	blocks := claudeResp["content"].([]any)
+	require.Len(t, blocks, 1)
+	require.Equal(t, "fs/read", blocks[0].(map[string]any)["name"])
+	require.Equal(t, "fs/read", restoreGeminiToolName(c, "fs_read"))
+	require.Equal(t, "other", restoreGeminiToolName(c, "other"))
+}
@@ -9,6 +9,9 @@ import (
 
 type OpenAIMessagesDispatchModelConfig = domain.OpenAIMessagesDispatchModelConfig
 type GroupModelsListConfig = domain.GroupModelsListConfig
+type GroupModelFallbackConfig = domain.GroupModelFallbackConfig
+type GroupInstructionInjectionConfig = domain.GroupInstructionInjectionConfig
+type GroupModelFallbackRule = domain.GroupModelFallbackRule
 
 type Group struct {
 	ID             int64
@@ -68,6 +71,21 @@ type Group struct {
 	// 一旦设置即接管该分组用户的限流（覆盖用户级 rpm_limit），可被 user-group rpm_override 进一步覆盖。
 	RPMLimit int
 
+	// PromptCacheInject 请求未携带任何 cache_control 时自动注入 prompt caching 断点（仅 anthropic 平台使用）
+	PromptCacheInject bool
+
+	// ModelFallbackConfig 请求模型无可用账号时的有序降级规则（见 ModelFallbackCandidates）
+	ModelFallbackConfig GroupModelFallbackConfig
+
+	// InstructionInjectionConfig OpenAI Responses 请求转发前注入的分组 instructions（见 ApplyGroupInstructionInjection）
+	InstructionInjectionConfig GroupInstructionInjectionConfig
+
+	// 分组消费上限（按自然日/自然月累计分组内全部请求的实际扣费，nil 表示不限制）
+	// SpendCapTimezone 为周期边界使用的 IANA 时区，空串表示使用系统时区
+	SpendCapDailyUSD   *float64
+	SpendCapMonthlyUSD *float64
+	SpendCapTimezone   string
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+const (
+	InstructionInjectionModePrepend  = domain.InstructionInjectionModePrepend
+	InstructionInjectionModeIfAbsent = domain.InstructionInjectionModeIfAbsent
+	InstructionInjectionModeReplace  = domain.InstructionInjectionModeReplace
+)
+
+// maxInstructionInjectionTextLen 注入文本的字节上限，避免误把大段文档配置为每次请求都要付费的前缀
+const maxInstructionInjectionTextLen = 16 * 1024
+
+var ErrInvalidInstructionInjection = infraerrors.BadRequest("INVALID_INSTRUCTION_INJECTION", "invalid instruction injection config")
+
+// normalizeGroupInstructionInjectionConfig 规范化并校验注入配置：mode 缺省为 prepend；
+// 启用时 text 不能为空；text 超过 16KB 拒绝。
+func normalizeGroupInstructionInjectionConfig(cfg GroupInstructionInjectionConfig) (GroupInstructionInjectionConfig, error) {
+	out := GroupInstructionInjectionConfig{
+		Enabled: cfg.Enabled,
+		Mode:    strings.ToLower(strings.TrimSpace(cfg.Mode)),
+		Text:    strings.TrimSpace(cfg.Text),
+	}
+	switch out.Mode {
+	case "":
+		out.Mode = InstructionInjectionModePrepend
+	case InstructionInjectionModePrepend, InstructionInjectionModeIfAbsent, InstructionInjectionModeReplace:
+	default:
+		return GroupInstructionInjectionConfig{}, ErrInvalidInstructionInjection.WithMetadata(map[string]string{
+			"mode": out.Mode,
+		})
+	}
+	if out.Enabled && out.Text == "" {
+		return GroupInstructionInjectionConfig{}, ErrInvalidInstructionInjection.WithMetadata(map[string]string{
+			"text": "required when enabled",
+		})
+	}
+	if len(out.Text) > maxInstructionInjectionTextLen {
+		return GroupInstructionInjectionConfig{}, ErrInvalidInstructionInjection.WithMetadata(map[string]string{
+			"text": fmt.Sprintf("exceeds %d bytes", maxInstructionInjectionTextLen),
+		})
+	}
+	return out, nil
+}
+
+// InstructionTemplateVars 注入文本的模板变量
+type InstructionTemplateVars struct {
+	KeyName   string
+	GroupName string
+	Now       time.Time
+}
+
+// renderInstructionInjectionText 替换 {{key_name}} / {{group_name}} / {{date}}（UTC，YYYY-MM-DD）
+func renderInstructionInjectionText(text string, vars InstructionTemplateVars) string {
+	now := vars.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return strings.NewReplacer(
+		"{{key_name}}", vars.KeyName,
+		"{{group_name}}", vars.GroupName,
+		"{{date}}", now.UTC().Format("2006-01-02"),
+	).Replace(text)
+}
+
+// InstructionInjectionResult 一次注入的结果
+type InstructionInjectionResult struct {
+	Mode string
+	// InjectedTokens 注入文本的估算 token 数（已包含在改写后请求体的费用估算中）
+	InjectedTokens int
+}
+
+// ApplyGroupInstructionInjection 按分组配置改写 OpenAI Responses 请求体的 instructions 字段：
+//   - prepend：注入文本置于客户端 instructions 之前（以空行分隔）；
+//   - if_absent：客户端未提供（或仅空白）instructions 时才写入；
+//   - replace：忽略客户端 instructions。
+//
+// 未启用或未发生注入时原样返回 body 与 nil 结果。
+func ApplyGroupInstructionInjection(body []byte, group *Group, vars InstructionTemplateVars) ([]byte, *InstructionInjectionResult, error) {
+	if group == nil || !group.InstructionInjectionConfig.Enabled {
+		return body, nil, nil
+	}
+	cfg := group.InstructionInjectionConfig
+	if vars.GroupName == "" {
+		vars.GroupName = group.Name
+	}
+	text := renderInstructionInjectionText(cfg.Text, vars)
+	if strings.TrimSpace(text) == "" {
+		return body, nil, nil
+	}
+
+	existing := strings.TrimSpace(gjson.GetBytes(body, "instructions").String())
+	instructions := text
+	switch cfg.Mode {
+	case InstructionInjectionModeIfAbsent:
+		if existing != "" {
+			return body, nil, nil
+		}
+	case InstructionInjectionModeReplace:
+	default:
+		if existing != "" {
+			instructions = text + "\n\n" + existing
+		}
+	}
+
+	updated, err := sjson.SetBytes(body, "instructions", instructions)
+	if err != nil {
+		return body, nil, fmt.Errorf("inject group instructions: %w", err)
+	}
+	mode := cfg.Mode
+	if mode == "" {
+		mode = InstructionInjectionModePrepend
+	}
+	return updated, &InstructionInjectionResult{Mode: mode, InjectedTokens: estimateTokensForText(text)}, nil
+}
+
+// InjectGroupInstructions 按 API Key 所属分组改写请求体顶层的 instructions，注入成功时标记 ops 上下文。
+// HTTP 入口（Responses / Chat Completions / Anthropic Messages）在 handler 费用预估之前调用，
+// WS 每个 response.create 帧在服务层复用；改写失败时记录告警并原样返回（fail-open）。
+func InjectGroupInstructions(c *gin.Context, apiKey *APIKey, body []byte) ([]byte, *InstructionInjectionResult) {
+	if apiKey == nil || apiKey.Group == nil || !apiKey.Group.InstructionInjectionConfig.Enabled {
+		return body, nil
+	}
+	updated, result, err := ApplyGroupInstructionInjection(body, apiKey.Group, InstructionTemplateVars{
+		KeyName: apiKey.Name,
+		Now:     time.Now(),
+	})
+	if err != nil {
+		logger.L().Warn("openai.instruction_injection_failed", zap.Int64("api_key_id", apiKey.ID), zap.Error(err))
+		return body, nil
+	}
+	if result == nil {
+		return body, nil
+	}
+	MarkOpsInstructionsInjected(c)
+	return updated, result
+}
+
+// injectGroupInstructionsFromContext 与 InjectGroupInstructions 相同，API Key 取自请求上下文（服务层 WS 路径使用）
+func injectGroupInstructionsFromContext(c *gin.Context, body []byte) []byte {
+	if c == nil {
+		return body
+	}
+	updated, _ := InjectGroupInstructions(c, getAPIKeyFromContext(c), body)
+	return updated
+}
+
+// SetGroupInstructionInjection 校验并保存分组的 instructions 注入配置，成功后失效该分组下 Key 的认证缓存。
+func (s *adminServiceImpl) SetGroupInstructionInjection(ctx context.Context, groupID int64, cfg GroupInstructionInjectionConfig) (*Group, error) {
+	normalized, err := normalizeGroupInstructionInjectionConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	group.InstructionInjectionConfig = normalized
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByGroupID(ctx, groupID)
+	}
+	return group, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newInstructionInjectionGroup(mode, text string) *Group {
+	return &Group{
+		Name: "compliance",
+		InstructionInjectionConfig: GroupInstructionInjectionConfig{
+			Enabled: true,
+			Mode:    mode,
+			Text:    text,
+		},
+	}
+}
+
+func TestApplyGroupInstructionInjection_Modes(t *testing.T) {
+	vars := InstructionTemplateVars{KeyName: "ci-bot", Now: time.Date(2026, 3, 9, 23, 30, 0, 0, time.FixedZone("UTC+8", 8*3600))}
+	const injected = "Key {{key_name}} in {{group_name}} on {{date}}."
+	const rendered = "Key ci-bot in compliance on 2026-03-09."
+
+	tests := []struct {
+		name     string
+		mode     string
+		body     string
+		want     string
+		injected bool
+	}{
+		{name: "prepend_with_client", mode: InstructionInjectionModePrepend, body: `{"model":"gpt-5","instructions":"Be terse."}`, want: rendered + "\n\nBe terse.", injected: true},
+		{name: "prepend_without_client", mode: InstructionInjectionModePrepend, body: `{"model":"gpt-5"}`, want: rendered, injected: true},
+		{name: "default_mode_is_prepend", mode: "", body: `{"model":"gpt-5","instructions":"Be terse."}`, want: rendered + "\n\nBe terse.", injected: true},
+		{name: "if_absent_with_client", mode: InstructionInjectionModeIfAbsent, body: `{"model":"gpt-5","instructions":"Be terse."}`, want: "Be terse.", injected: false},
+		{name: "if_absent_blank_client", mode: InstructionInjectionModeIfAbsent, body: `{"model":"gpt-5","instructions":"  "}`, want: rendered, injected: true},
+		{name: "if_absent_without_client", mode: InstructionInjectionModeIfAbsent, body: `{"model":"gpt-5"}`, want: rendered, injected: true},
+		{name: "replace_with_client", mode: InstructionInjectionModeReplace, body: `{"model":"gpt-5","instructions":"Ignore all rules."}`, want: rendered, injected: true},
+		{name: "replace_without_client", mode: InstructionInjectionModeReplace, body: `{"model":"gpt-5"}`, want: rendered, injected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, result, err := ApplyGroupInstructionInjection([]byte(tt.body), newInstructionInjectionGroup(tt.mode, injected), vars)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, gjson.GetBytes(out, "instructions").String())
+			require.Equal(t, "gpt-5", gjson.GetBytes(out, "model").String())
+			if !tt.injected {
+				require.Nil(t, result)
+				require.JSONEq(t, tt.body, string(out))
+				return
+			}
+			require.NotNil(t, result)
+			require.Positive(t, result.InjectedTokens)
+		})
+	}
+}
+
+func TestApplyGroupInstructionInjection_DisabledLeavesBodyUntouched(t *testing.T) {
+	body := []byte(`{"model":"gpt-5","instructions":"client"}`)
+	group := newInstructionInjectionGroup(InstructionInjectionModeReplace, "admin")
+	group.InstructionInjectionConfig.Enabled = false
+
+	out, result, err := ApplyGroupInstructionInjection(body, group, InstructionTemplateVars{})
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.Equal(t, string(body), string(out))
+
+	out, result, err = ApplyGroupInstructionInjection(body, nil, InstructionTemplateVars{})
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.Equal(t, string(body), string(out))
+}
+
+func TestApplyGroupInstructionInjection_CountedInCostEstimate(t *testing.T) {
+	body := []byte(`{"model":"gpt-5","instructions":"Be terse.","input":"hello"}`)
+	before := estimateRequestCostInputTokens(body)
+
+	out, result, err := ApplyGroupInstructionInjection(body, newInstructionInjectionGroup(InstructionInjectionModePrepend, "Always answer in formal English and cite internal policy IDs."), InstructionTemplateVars{})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Greater(t, estimateRequestCostInputTokens(out), before)
+}
+
+func TestNormalizeGroupInstructionInjectionConfig(t *testing.T) {
+	cfg, err := normalizeGroupInstructionInjectionConfig(GroupInstructionInjectionConfig{Enabled: true, Mode: " IF_ABSENT ", Text: "  hi  "})
+	require.NoError(t, err)
+	require.Equal(t, GroupInstructionInjectionConfig{Enabled: true, Mode: InstructionInjectionModeIfAbsent, Text: "hi"}, cfg)
+
+	cfg, err = normalizeGroupInstructionInjectionConfig(GroupInstructionInjectionConfig{})
+	require.NoError(t, err)
+	require.Equal(t, InstructionInjectionModePrepend, cfg.Mode)
+
+	_, err = normalizeGroupInstructionInjectionConfig(GroupInstructionInjectionConfig{Enabled: true, Mode: "append", Text: "hi"})
+	require.Equal(t, "INVALID_INSTRUCTION_INJECTION", infraerrors.Reason(err))
+
+	_, err = normalizeGroupInstructionInjectionConfig(GroupInstructionInjectionConfig{Enabled: true, Text: "   "})
+	require.Equal(t, "INVALID_INSTRUCTION_INJECTION", infraerrors.Reason(err))
+
+	long := make([]byte, maxInstructionInjectionTextLen+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+	_, err = normalizeGroupInstructionInjectionConfig(GroupInstructionInjectionConfig{Enabled: true, Text: string(long)})
+	require.Equal(t, "INVALID_INSTRUCTION_INJECTION", infraerrors.Reason(err))
+}
+
+// Chat Completions / Anthropic Messages 在 handler 注入顶层 instructions，经 OpenAI 账号转发时映射为 Responses instructions
+func TestInstructionInjection_CarriedThroughResponsesConversion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	upstreamSSE := strings.Join([]string{
+		`data: {"type":"response.completed","response":{"id":"resp_1","object":"response","model":"gpt-5.4","status":"completed","output":[{"type":"message","id":"msg_1","role":"assistant","status":"completed","content":[{"type":"output_text","text":"ok"}]}],"usage":{"input_tokens":5,"output_tokens":2,"total_tokens":7}}}`,
+		"",
+		"data: [DONE]",
+		"",
+	}, "\n")
+	account := &Account{
+		ID:          1,
+		Name:        "openai-apikey",
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeAPIKey,
+		Concurrency: 1,
+		Credentials: map[string]any{"api_key": "sk-test", "base_url": "https://api.openai.com/v1"},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		forward func(svc *OpenAIGatewayService, c *gin.Context, body []byte) (*OpenAIForwardResult, error)
+	}{
+		{
+			name: "chat_completions",
+			path: "/v1/chat/completions",
+			body: `{"model":"gpt-5.4","messages":[{"role":"system","content":"client rules"},{"role":"user","content":"hello"}],"stream":false}`,
+			forward: func(svc *OpenAIGatewayService, c *gin.Context, body []byte) (*OpenAIForwardResult, error) {
+				return svc.ForwardAsChatCompletions(context.Background(), c, account, body, "", "gpt-5.4")
+			},
+		},
+		{
+			name: "anthropic_messages",
+			path: "/v1/messages",
+			body: `{"model":"gpt-5.4","max_tokens":16,"system":"client rules","messages":[{"role":"user","content":"hello"}],"stream":false}`,
+			forward: func(svc *OpenAIGatewayService, c *gin.Context, body []byte) (*OpenAIForwardResult, error) {
+				return svc.ForwardAsAnthropic(context.Background(), c, account, body, "", "gpt-5.4")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader([]byte(tt.body)))
+			c.Request.Header.Set("Content-Type", "application/json")
+			apiKey := &APIKey{ID: 9, Name: "ci-bot", Group: newInstructionInjectionGroup(InstructionInjectionModeReplace, "Serve {{key_name}}.")}
+			c.Set("api_key", apiKey)
+			body, result := InjectGroupInstructions(c, apiKey, []byte(tt.body))
+			require.NotNil(t, result)
+
+			upstream := &httpUpstreamRecorder{resp: &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+				Body:       io.NopCloser(strings.NewReader(upstreamSSE)),
+			}}
+			svc := &OpenAIGatewayService{httpUpstream: upstream, cfg: &config.Config{}}
+
+			_, err := tt.forward(svc, c, body)
+			require.NoError(t, err)
+			require.Equal(t, "Serve ci-bot.", gjson.GetBytes(upstream.lastBody, "instructions").String())
+			require.True(t, c.GetBool(OpsInstructionsInjectedKey))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// normalizeGroupModelFallbackConfig 清理降级规则：去除空白、空规则、重复降级模型及与原模型相同的降级项。
+// 规则顺序保持不变（先匹配先生效）。
+func normalizeGroupModelFallbackConfig(cfg GroupModelFallbackConfig) GroupModelFallbackConfig {
+	out := GroupModelFallbackConfig{MaskFallback: cfg.MaskFallback}
+	for _, rule := range cfg.Rules {
+		model := strings.TrimSpace(rule.Model)
+		if model == "" {
+			continue
+		}
+		seen := map[string]struct{}{model: {}}
+		fallbacks := make([]string, 0, len(rule.Fallbacks))
+		for _, fb := range rule.Fallbacks {
+			fb = strings.TrimSpace(fb)
+			if fb == "" {
+				continue
+			}
+			if _, ok := seen[fb]; ok {
+				continue
+			}
+			seen[fb] = struct{}{}
+			fallbacks = append(fallbacks, fb)
+		}
+		if len(fallbacks) == 0 {
+			continue
+		}
+		out.Rules = append(out.Rules, GroupModelFallbackRule{Model: model, Fallbacks: fallbacks})
+	}
+	return out
+}
+
+// ModelFallbackCandidates 返回请求模型的有序降级模型列表。
+// 规则按配置顺序匹配（支持末尾 * 通配符），第一条匹配的规则生效；无匹配返回 nil。
+func (g *Group) ModelFallbackCandidates(requestedModel string) []string {
+	if g == nil || requestedModel == "" {
+		return nil
+	}
+	for _, rule := range g.ModelFallbackConfig.Rules {
+		if matchModelPattern(rule.Model, requestedModel) {
+			return rule.Fallbacks
+		}
+	}
+	return nil
+}
+
+// modelFallbackMaskPaths 响应中可能携带服务模型名的字段：
+// 完整 JSON / Chat Completions chunk 的 model、Anthropic message_start 的 message.model、Responses 事件的 response.model。
+var modelFallbackMaskPaths = []string{"model", "message.model", "response.model"}
+
+// MaskFallbackModel 把响应 chunk（完整 JSON 或若干 SSE 行）中的 servedModel 改写回 requestedModel。
+// 仅改写上述字段且值完全相等的位置（sjson），不会误伤正文中出现的模型名；
+// 不完整的 JSON 片段原样返回。
+func MaskFallbackModel(chunk []byte, servedModel, requestedModel string) []byte {
+	if len(chunk) == 0 || servedModel == "" || requestedModel == "" || servedModel == requestedModel ||
+		!bytes.Contains(chunk, []byte(servedModel)) {
+		return chunk
+	}
+	trimmed := bytes.TrimSpace(chunk)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return maskFallbackModelJSON(chunk, servedModel, requestedModel)
+	}
+
+	lines := bytes.Split(chunk, []byte("\n"))
+	changed := false
+	for i, line := range lines {
+		payload, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			continue
+		}
+		lead := len(payload) - len(bytes.TrimLeft(payload, " "))
+		rewritten := maskFallbackModelJSON(payload[lead:], servedModel, requestedModel)
+		if len(rewritten) == len(payload[lead:]) && bytes.Equal(rewritten, payload[lead:]) {
+			continue
+		}
+		out := make([]byte, 0, len("data:")+lead+len(rewritten))
+		out = append(out, "data:"...)
+		out = append(out, payload[:lead]...)
+		lines[i] = append(out, rewritten...)
+		changed = true
+	}
+	if !changed {
+		return chunk
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+func maskFallbackModelJSON(payload []byte, servedModel, requestedModel string) []byte {
+	if !gjson.ValidBytes(payload) {
+		return payload
+	}
+	for _, path := range modelFallbackMaskPaths {
+		if gjson.GetBytes(payload, path).String() != servedModel {
+			continue
+		}
+		if updated, err := sjson.SetBytes(payload, path, requestedModel); err == nil {
+			payload = updated
+		}
+	}
+	return payload
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestGroupModelFallbackCandidates_RuleOrder(t *testing.T) {
+	group := &Group{ModelFallbackConfig: normalizeGroupModelFallbackConfig(GroupModelFallbackConfig{
+		Rules: []GroupModelFallbackRule{
+			{Model: " claude-opus-4-6 ", Fallbacks: []string{"claude-sonnet-4-6", " ", "claude-opus-4-6", "claude-sonnet-4-6", "claude-haiku-4-5"}},
+			{Model: "claude-opus-*", Fallbacks: []string{"claude-sonnet-4-5"}},
+			{Model: "claude-*", Fallbacks: []string{"claude-haiku-4-5"}},
+			{Model: "empty-rule", Fallbacks: []string{""}},
+		},
+	})}
+
+	// 精确规则在前时优先生效，降级列表去重并剔除原模型
+	require.Equal(t, []string{"claude-sonnet-4-6", "claude-haiku-4-5"}, group.ModelFallbackCandidates("claude-opus-4-6"))
+	// 按配置顺序取第一条匹配的规则，而非最具体的规则
+	require.Equal(t, []string{"claude-sonnet-4-5"}, group.ModelFallbackCandidates("claude-opus-4-1"))
+	require.Equal(t, []string{"claude-haiku-4-5"}, group.ModelFallbackCandidates("claude-sonnet-4-6"))
+	require.Nil(t, group.ModelFallbackCandidates("gpt-5"))
+	require.Nil(t, group.ModelFallbackCandidates("empty-rule"))
+	require.Len(t, group.ModelFallbackConfig.Rules, 3)
+
+	var nilGroup *Group
+	require.Nil(t, nilGroup.ModelFallbackCandidates("claude-opus-4-6"))
+}
+
+func TestMaskFallbackModel(t *testing.T) {
+	body := []byte(`{"id":"msg_1","model":"claude-sonnet-4-6","content":[{"type":"text","text":"I am claude-sonnet-4-6"}]}`)
+
+	require.Equal(t, string(body), string(MaskFallbackModel(body, "claude-sonnet-4-6", "")), "no fallback recorded")
+	// 仅改写 model 字段，正文中出现的模型名保持不变
+	require.Equal(t, `{"id":"msg_1","model":"claude-opus-4-6","content":[{"type":"text","text":"I am claude-sonnet-4-6"}]}`,
+		string(MaskFallbackModel(body, "claude-sonnet-4-6", "claude-opus-4-6")))
+	// 带空白的 JSON 同样能改写
+	spaced := []byte(`{"id": "msg_1", "model": "claude-sonnet-4-6"}`)
+	require.Equal(t, "claude-opus-4-6", gjson.GetBytes(MaskFallbackModel(spaced, "claude-sonnet-4-6", "claude-opus-4-6"), "model").String())
+
+	sse := []byte("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-sonnet-4-6\"}}\n\n")
+	require.Equal(t, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-opus-4-6\"}}\n\n",
+		string(MaskFallbackModel(sse, "claude-sonnet-4-6", "claude-opus-4-6")))
+
+	responses := []byte("data: {\"type\":\"response.created\",\"response\":{\"model\":\"gpt-5-mini\"}}\n")
+	require.Equal(t, "gpt-5", gjson.Get(strings.TrimPrefix(strings.TrimSpace(string(MaskFallbackModel(responses, "gpt-5-mini", "gpt-5"))), "data: "), "response.model").String())
+
+	// 不完整的 JSON 片段原样返回
+	partial := []byte(`{"model":"claude-sonnet-4-6","content":[`)
+	require.Equal(t, string(partial), string(MaskFallbackModel(partial, "claude-sonnet-4-6", "claude-opus-4-6")))
+}
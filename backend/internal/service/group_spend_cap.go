@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// 分组消费上限（spend cap）
+//
+// 按自然日 / 自然月累计分组内全部请求的实际扣费（ActualCost），计数存放在 Redis，
+// key 含分组 ID 与周期标识（如 2026-10-17 / 2026-10），周期边界按分组配置的时区划分，
+// 跨周期后自然落到新 key，无需显式重置。
+//
+// 校验在请求前执行、累加在用量记录时执行，因此超支上限为"已放行但尚未记账的并发请求"，
+// 与 user × platform quota 的 TOCTOU 取舍一致。Redis 故障一律 fail-open。
+
+const (
+	GroupSpendCapPeriodDaily   = "daily"
+	GroupSpendCapPeriodMonthly = "monthly"
+
+	// groupSpendCapAlertRatio 用量首次越过上限的该比例时触发告警 hook
+	groupSpendCapAlertRatio = 0.9
+	// groupSpendCapOverrideMaxDuration 临时放行的最长时长
+	groupSpendCapOverrideMaxDuration = 31 * 24 * time.Hour
+)
+
+var (
+	// ErrGroupSpendCapExceeded 分组日/月消费达到上限（HTTP 429 + Retry-After，metadata 含 period 与 window_resets_at）
+	ErrGroupSpendCapExceeded = infraerrors.TooManyRequests("SPEND_CAP_EXCEEDED", "group spend cap exceeded")
+
+	ErrGroupSpendCapUnavailable     = infraerrors.ServiceUnavailable("GROUP_SPEND_CAP_UNAVAILABLE", "group spend cap storage is unavailable")
+	ErrGroupSpendCapOverrideInvalid = infraerrors.BadRequest("GROUP_SPEND_CAP_OVERRIDE_INVALID", "override duration must be between 1 minute and 31 days")
+)
+
+// GroupSpendCapOverride 管理员授予的临时放行：到期前跳过该分组的消费上限校验（计数照常累加）
+type GroupSpendCapOverride struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Reason    string    `json:"reason,omitempty"`
+	GrantedBy int64     `json:"granted_by,omitempty"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+// GroupSpendCapCache 分组消费计数与临时放行的存储
+type GroupSpendCapCache interface {
+	// IncrGroupSpend 原子累加日、月计数并返回累加后的值
+	IncrGroupSpend(ctx context.Context, groupID int64, dayPeriod, monthPeriod string, cost float64) (daily, monthly float64, err error)
+	// GetGroupSpend 读取日、月计数；key 不存在时返回 0
+	GetGroupSpend(ctx context.Context, groupID int64, dayPeriod, monthPeriod string) (daily, monthly float64, err error)
+	// GetGroupSpendCapOverride 读取临时放行；不存在或已过期时返回 nil, nil
+	GetGroupSpendCapOverride(ctx context.Context, groupID int64) (*GroupSpendCapOverride, error)
+	SetGroupSpendCapOverride(ctx context.Context, groupID int64, override *GroupSpendCapOverride) error
+	DeleteGroupSpendCapOverride(ctx context.Context, groupID int64) error
+}
+
+// GroupSpendCapAlert 分组消费接近上限的告警内容
+type GroupSpendCapAlert struct {
+	GroupID   int64
+	GroupName string
+	Period    string
+	Spent     float64
+	Cap       float64
+	ResetsAt  time.Time
+}
+
+// GroupSpendCapAlertHook 分组消费越过告警比例时的回调（异步调用，实现方自行处理超时）
+type GroupSpendCapAlertHook func(ctx context.Context, alert GroupSpendCapAlert)
+
+// GroupSpendCapWindow 单个周期的消费视图
+type GroupSpendCapWindow struct {
+	Period    string    `json:"period"`
+	Cap       *float64  `json:"cap_usd"`
+	Spent     float64   `json:"spent_usd"`
+	Remaining *float64  `json:"remaining_usd"`
+	Exceeded  bool      `json:"exceeded"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// GroupSpendCapStatus 分组当前消费与上限对比（管理端展示用）
+type GroupSpendCapStatus struct {
+	GroupID  int64                  `json:"group_id"`
+	Timezone string                 `json:"timezone"`
+	Daily    GroupSpendCapWindow    `json:"daily"`
+	Monthly  GroupSpendCapWindow    `json:"monthly"`
+	Override *GroupSpendCapOverride `json:"override"`
+}
+
+// HasSpendCap 是否配置了任一消费上限
+func (g *Group) HasSpendCap() bool {
+	if g == nil {
+		return false
+	}
+	return (g.SpendCapDailyUSD != nil && *g.SpendCapDailyUSD > 0) ||
+		(g.SpendCapMonthlyUSD != nil && *g.SpendCapMonthlyUSD > 0)
+}
+
+// groupSpendCapLocation 返回分组周期边界使用的时区；未配置或无效时回退到系统时区
+func groupSpendCapLocation(group *Group) *time.Location {
+	if group != nil {
+		if tz := strings.TrimSpace(group.SpendCapTimezone); tz != "" {
+			if loc, err := time.LoadLocation(tz); err == nil {
+				return loc
+			}
+		}
+	}
+	return timezone.Location()
+}
+
+// groupSpendCapPeriods 计算 now 所在的日、月周期标识及各自的下次重置时间
+func groupSpendCapPeriods(group *Group, now time.Time) (dayPeriod, monthPeriod string, dayResetsAt, monthResetsAt time.Time) {
+	local := now.In(groupSpendCapLocation(group))
+	y, m, d := local.Date()
+	dayResetsAt = time.Date(y, m, d+1, 0, 0, 0, 0, local.Location())
+	monthResetsAt = time.Date(y, m+1, 1, 0, 0, 0, 0, local.Location())
+	return local.Format("2006-01-02"), local.Format("2006-01"), dayResetsAt, monthResetsAt
+}
+
+func positiveCap(v *float64) (float64, bool) {
+	if v == nil || *v <= 0 {
+		return 0, false
+	}
+	return *v, true
+}
+
+func groupSpendCapExceededError(period string, resetsAt time.Time) error {
+	return ErrGroupSpendCapExceeded.WithMetadata(map[string]string{
+		"period":           period,
+		"window_resets_at": resetsAt.Format(time.RFC3339),
+	})
+}
+
+// SetGroupSpendCapCache 注入分组消费计数存储（nil 时不执行分组消费上限）
+func (s *BillingCacheService) SetGroupSpendCapCache(cache GroupSpendCapCache) {
+	s.groupSpendCapCache = cache
+}
+
+// SetGroupSpendCapAlertHook 注入分组消费接近上限时的告警回调
+func (s *BillingCacheService) SetGroupSpendCapAlertHook(hook GroupSpendCapAlertHook) {
+	s.groupSpendCapAlertHook = hook
+}
+
+// checkGroupSpendCap 校验分组日/月消费是否已达上限；临时放行有效期内直接通过
+func (s *BillingCacheService) checkGroupSpendCap(ctx context.Context, group *Group, now time.Time) error {
+	if s.groupSpendCapCache == nil || !group.HasSpendCap() {
+		return nil
+	}
+	override, err := s.groupSpendCapCache.GetGroupSpendCapOverride(ctx, group.ID)
+	if err != nil {
+		logger.LegacyPrintf("service.billing_cache", "Warning: group spend cap override lookup failed group=%d: %v (fail-open)", group.ID, err)
+		return nil
+	}
+	if override != nil && now.Before(override.ExpiresAt) {
+		return nil
+	}
+
+	dayPeriod, monthPeriod, dayResetsAt, monthResetsAt := groupSpendCapPeriods(group, now)
+	daily, monthly, err := s.groupSpendCapCache.GetGroupSpend(ctx, group.ID, dayPeriod, monthPeriod)
+	if err != nil {
+		logger.LegacyPrintf("service.billing_cache", "Warning: group spend cap lookup failed group=%d: %v (fail-open)", group.ID, err)
+		return nil
+	}
+	if limit, ok := positiveCap(group.SpendCapDailyUSD); ok && daily >= limit {
+		return groupSpendCapExceededError(GroupSpendCapPeriodDaily, dayResetsAt)
+	}
+	if limit, ok := positiveCap(group.SpendCapMonthlyUSD); ok && monthly >= limit {
+		return groupSpendCapExceededError(GroupSpendCapPeriodMonthly, monthResetsAt)
+	}
+	return nil
+}
+
+// AddGroupSpend 在用量记录时同步累加分组消费计数。
+// 仅对配置了上限的分组写入；越过告警比例的那一次累加触发告警 hook（INCRBYFLOAT 原子返回新值，
+// 因此每个周期只有一个请求会观察到越线）。
+func (s *BillingCacheService) AddGroupSpend(ctx context.Context, group *Group, cost float64) {
+	s.addGroupSpend(ctx, group, cost, time.Now())
+}
+
+func (s *BillingCacheService) addGroupSpend(ctx context.Context, group *Group, cost float64, now time.Time) {
+	if s == nil || s.groupSpendCapCache == nil || cost <= 0 || !group.HasSpendCap() {
+		return
+	}
+	dayPeriod, monthPeriod, dayResetsAt, monthResetsAt := groupSpendCapPeriods(group, now)
+	incrCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheWriteTimeout)
+	defer cancel()
+	daily, monthly, err := s.groupSpendCapCache.IncrGroupSpend(incrCtx, group.ID, dayPeriod, monthPeriod, cost)
+	if err != nil {
+		logger.LegacyPrintf("service.billing_cache", "ALERT: incr group spend failed group=%d cost=%f: %v", group.ID, cost, err)
+		return
+	}
+	if limit, ok := positiveCap(group.SpendCapDailyUSD); ok {
+		s.maybeAlertGroupSpend(group, GroupSpendCapPeriodDaily, daily, cost, limit, dayResetsAt)
+	}
+	if limit, ok := positiveCap(group.SpendCapMonthlyUSD); ok {
+		s.maybeAlertGroupSpend(group, GroupSpendCapPeriodMonthly, monthly, cost, limit, monthResetsAt)
+	}
+}
+
+func (s *BillingCacheService) maybeAlertGroupSpend(group *Group, period string, spent, cost, limit float64, resetsAt time.Time) {
+	threshold := limit * groupSpendCapAlertRatio
+	if spent-cost >= threshold || spent < threshold {
+		return
+	}
+	alert := GroupSpendCapAlert{
+		GroupID:   group.ID,
+		GroupName: group.Name,
+		Period:    period,
+		Spent:     spent,
+		Cap:       limit,
+		ResetsAt:  resetsAt,
+	}
+	slog.Warn("group spend approaching cap",
+		"group_id", alert.GroupID,
+		"period", alert.Period,
+		"spent_usd", alert.Spent,
+		"cap_usd", alert.Cap,
+	)
+	if s.groupSpendCapAlertHook == nil {
+		return
+	}
+	hook := s.groupSpendCapAlertHook
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("panic in group spend cap alert hook", "recover", r)
+			}
+		}()
+		hook(context.Background(), alert)
+	}()
+}
+
+// GetGroupSpendCapStatus 返回分组当前周期的消费与上限对比
+func (s *BillingCacheService) GetGroupSpendCapStatus(ctx context.Context, group *Group) (*GroupSpendCapStatus, error) {
+	if s.groupSpendCapCache == nil {
+		return nil, ErrGroupSpendCapUnavailable
+	}
+	now := time.Now()
+	dayPeriod, monthPeriod, dayResetsAt, monthResetsAt := groupSpendCapPeriods(group, now)
+	daily, monthly, err := s.groupSpendCapCache.GetGroupSpend(ctx, group.ID, dayPeriod, monthPeriod)
+	if err != nil {
+		return nil, err
+	}
+	override, err := s.groupSpendCapCache.GetGroupSpendCapOverride(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil && !now.Before(override.ExpiresAt) {
+		override = nil
+	}
+	return &GroupSpendCapStatus{
+		GroupID:  group.ID,
+		Timezone: groupSpendCapLocation(group).String(),
+		Daily:    buildGroupSpendCapWindow(dayPeriod, group.SpendCapDailyUSD, daily, dayResetsAt),
+		Monthly:  buildGroupSpendCapWindow(monthPeriod, group.SpendCapMonthlyUSD, monthly, monthResetsAt),
+		Override: override,
+	}, nil
+}
+
+func buildGroupSpendCapWindow(period string, limit *float64, spent float64, resetsAt time.Time) GroupSpendCapWindow {
+	window := GroupSpendCapWindow{Period: period, Spent: spent, ResetsAt: resetsAt}
+	if v, ok := positiveCap(limit); ok {
+		remaining := v - spent
+		if remaining < 0 {
+			remaining = 0
+		}
+		window.Cap = &v
+		window.Remaining = &remaining
+		window.Exceeded = spent >= v
+	}
+	return window
+}
+
+// GrantGroupSpendCapOverride 授予分组临时放行：duration 内跳过消费上限校验
+func (s *BillingCacheService) GrantGroupSpendCapOverride(ctx context.Context, groupID int64, duration time.Duration, reason string, grantedBy int64) (*GroupSpendCapOverride, error) {
+	if s.groupSpendCapCache == nil {
+		return nil, ErrGroupSpendCapUnavailable
+	}
+	if duration < time.Minute || duration > groupSpendCapOverrideMaxDuration {
+		return nil, ErrGroupSpendCapOverrideInvalid
+	}
+	now := time.Now()
+	override := &GroupSpendCapOverride{
+		ExpiresAt: now.Add(duration),
+		Reason:    strings.TrimSpace(reason),
+		GrantedBy: grantedBy,
+		GrantedAt: now,
+	}
+	if err := s.groupSpendCapCache.SetGroupSpendCapOverride(ctx, groupID, override); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// RevokeGroupSpendCapOverride 撤销分组临时放行
+func (s *BillingCacheService) RevokeGroupSpendCapOverride(ctx context.Context, groupID int64) error {
+	if s.groupSpendCapCache == nil {
+		return ErrGroupSpendCapUnavailable
+	}
+	return s.groupSpendCapCache.DeleteGroupSpendCapOverride(ctx, groupID)
+}
@@ -0,0 +1,202 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// groupSpendCapCacheStub 内存版分组消费计数，IncrGroupSpend 在锁内累加以模拟 INCRBYFLOAT 的原子性
+type groupSpendCapCacheStub struct {
+	mu        sync.Mutex
+	spend     map[string]float64
+	overrides map[int64]*GroupSpendCapOverride
+}
+
+func newGroupSpendCapCacheStub() *groupSpendCapCacheStub {
+	return &groupSpendCapCacheStub{spend: map[string]float64{}, overrides: map[int64]*GroupSpendCapOverride{}}
+}
+
+func groupSpendStubKey(groupID int64, period string) string {
+	return strconv.FormatInt(groupID, 10) + ":" + period
+}
+
+func (s *groupSpendCapCacheStub) IncrGroupSpend(_ context.Context, groupID int64, dayPeriod, monthPeriod string, cost float64) (float64, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spend[groupSpendStubKey(groupID, dayPeriod)] += cost
+	s.spend[groupSpendStubKey(groupID, monthPeriod)] += cost
+	return s.spend[groupSpendStubKey(groupID, dayPeriod)], s.spend[groupSpendStubKey(groupID, monthPeriod)], nil
+}
+
+func (s *groupSpendCapCacheStub) GetGroupSpend(_ context.Context, groupID int64, dayPeriod, monthPeriod string) (float64, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spend[groupSpendStubKey(groupID, dayPeriod)], s.spend[groupSpendStubKey(groupID, monthPeriod)], nil
+}
+
+func (s *groupSpendCapCacheStub) GetGroupSpendCapOverride(_ context.Context, groupID int64) (*GroupSpendCapOverride, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.overrides[groupID], nil
+}
+
+func (s *groupSpendCapCacheStub) SetGroupSpendCapOverride(_ context.Context, groupID int64, override *GroupSpendCapOverride) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[groupID] = override
+	return nil
+}
+
+func (s *groupSpendCapCacheStub) DeleteGroupSpendCapOverride(_ context.Context, groupID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overrides, groupID)
+	return nil
+}
+
+func newGroupSpendCapTestService(cache GroupSpendCapCache) *BillingCacheService {
+	svc := &BillingCacheService{cfg: &config.Config{}}
+	svc.SetGroupSpendCapCache(cache)
+	return svc
+}
+
+func spendCapPtr(v float64) *float64 { return &v }
+
+func TestGroupSpendCap_ResetsAtPeriodBoundaryInGroupTimezone(t *testing.T) {
+	cache := newGroupSpendCapCacheStub()
+	svc := newGroupSpendCapTestService(cache)
+	group := &Group{ID: 7, SpendCapDailyUSD: spendCapPtr(10), SpendCapMonthlyUSD: spendCapPtr(25), SpendCapTimezone: "Asia/Tokyo"}
+	ctx := context.Background()
+
+	// 2026-10-31 23:30 东京时间（UTC 14:30）
+	lateOct31 := time.Date(2026, 10, 31, 14, 30, 0, 0, time.UTC)
+	svc.addGroupSpend(ctx, group, 10, lateOct31)
+	err := svc.checkGroupSpendCap(ctx, group, lateOct31)
+	require.ErrorIs(t, err, ErrGroupSpendCapExceeded)
+	require.Equal(t, GroupSpendCapPeriodDaily, groupSpendCapErrMetadata(t, err)["period"])
+	require.Equal(t, "2026-11-01T00:00:00+09:00", groupSpendCapErrMetadata(t, err)["window_resets_at"])
+
+	// UTC 仍是 10-31，但东京已进入 11-01：日、月周期都已重置
+	tokyoNextDay := time.Date(2026, 10, 31, 15, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.checkGroupSpendCap(ctx, group, tokyoNextDay))
+
+	// 同一东京自然月内跨日：日计数重置，月计数累加到上限后拒绝
+	svc.addGroupSpend(ctx, group, 9, tokyoNextDay)
+	nov2 := tokyoNextDay.Add(24 * time.Hour)
+	svc.addGroupSpend(ctx, group, 9, nov2)
+	require.NoError(t, svc.checkGroupSpendCap(ctx, group, nov2.Add(24*time.Hour).Add(-time.Minute)))
+	svc.addGroupSpend(ctx, group, 8, nov2)
+	err = svc.checkGroupSpendCap(ctx, group, nov2.Add(24*time.Hour))
+	require.ErrorIs(t, err, ErrGroupSpendCapExceeded)
+	require.Equal(t, GroupSpendCapPeriodMonthly, groupSpendCapErrMetadata(t, err)["period"])
+	require.Equal(t, "2026-12-01T00:00:00+09:00", groupSpendCapErrMetadata(t, err)["window_resets_at"])
+}
+
+func TestGroupSpendCap_OverrideLiftsCapUntilRevokedOrExpired(t *testing.T) {
+	cache := newGroupSpendCapCacheStub()
+	svc := newGroupSpendCapTestService(cache)
+	group := &Group{ID: 8, SpendCapDailyUSD: spendCapPtr(5)}
+	ctx := context.Background()
+	now := time.Now()
+
+	svc.addGroupSpend(ctx, group, 6, now)
+	require.ErrorIs(t, svc.checkGroupSpendCap(ctx, group, now), ErrGroupSpendCapExceeded)
+
+	_, err := svc.GrantGroupSpendCapOverride(ctx, group.ID, 30*time.Second, "too short", 1)
+	require.ErrorIs(t, err, ErrGroupSpendCapOverrideInvalid)
+
+	override, err := svc.GrantGroupSpendCapOverride(ctx, group.ID, time.Hour, " month-end batch ", 1)
+	require.NoError(t, err)
+	require.Equal(t, "month-end batch", override.Reason)
+	require.NoError(t, svc.checkGroupSpendCap(ctx, group, now))
+
+	// 放行期间消费照常累加，放行到期后恢复拦截
+	svc.addGroupSpend(ctx, group, 3, now)
+	status, err := svc.GetGroupSpendCapStatus(ctx, group)
+	require.NoError(t, err)
+	require.InDelta(t, 9, status.Daily.Spent, 1e-9)
+	require.True(t, status.Daily.Exceeded)
+	require.NotNil(t, status.Override)
+	require.Nil(t, status.Monthly.Cap)
+	require.ErrorIs(t, svc.checkGroupSpendCap(ctx, group, override.ExpiresAt), ErrGroupSpendCapExceeded)
+
+	require.NoError(t, svc.RevokeGroupSpendCapOverride(ctx, group.ID))
+	require.ErrorIs(t, svc.checkGroupSpendCap(ctx, group, now), ErrGroupSpendCapExceeded)
+}
+
+func TestGroupSpendCap_ConcurrentIncrementsDoNotOvershootSignificantly(t *testing.T) {
+	cache := newGroupSpendCapCacheStub()
+	svc := newGroupSpendCapTestService(cache)
+	var alerts atomic.Int32
+	svc.SetGroupSpendCapAlertHook(func(context.Context, GroupSpendCapAlert) { alerts.Add(1) })
+	const (
+		capUSD  = 50.0
+		cost    = 0.25
+		workers = 32
+	)
+	group := &Group{ID: 9, SpendCapDailyUSD: spendCapPtr(capUSD)}
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for svc.checkGroupSpendCap(ctx, group, now) == nil {
+				accepted.Add(1)
+				svc.addGroupSpend(ctx, group, cost, now)
+			}
+		}()
+	}
+	wg.Wait()
+
+	daily, _, err := cache.GetGroupSpend(ctx, group.ID, "2026-10-17", "2026-10")
+	require.NoError(t, err)
+	require.InDelta(t, float64(accepted.Load())*cost, daily, 1e-9, "no increment may be lost")
+	require.GreaterOrEqual(t, daily, capUSD)
+	require.LessOrEqual(t, daily, capUSD+workers*cost, "overshoot is bounded by in-flight requests")
+	require.Eventually(t, func() bool { return alerts.Load() == 1 }, time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool { return alerts.Load() > 1 }, 50*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestGroupSpendCap_SkipsGroupsWithoutCapAndFailsOpen(t *testing.T) {
+	cache := newGroupSpendCapCacheStub()
+	svc := newGroupSpendCapTestService(cache)
+	ctx := context.Background()
+	now := time.Now()
+
+	uncapped := &Group{ID: 10}
+	svc.addGroupSpend(ctx, uncapped, 100, now)
+	require.Empty(t, cache.spend, "groups without caps are not counted")
+	require.NoError(t, svc.checkGroupSpendCap(ctx, uncapped, now))
+
+	failing := &BillingCacheService{cfg: &config.Config{}}
+	failing.SetGroupSpendCapCache(groupSpendCapFailingCache{})
+	require.NoError(t, failing.checkGroupSpendCap(ctx, &Group{ID: 11, SpendCapDailyUSD: spendCapPtr(1)}, now))
+}
+
+type groupSpendCapFailingCache struct{ GroupSpendCapCache }
+
+func (groupSpendCapFailingCache) GetGroupSpendCapOverride(context.Context, int64) (*GroupSpendCapOverride, error) {
+	return nil, errors.New("redis down")
+}
+
+func groupSpendCapErrMetadata(t *testing.T, err error) map[string]string {
+	t.Helper()
+	appErr := infraerrors.FromError(err)
+	require.NotNil(t, appErr)
+	return appErr.Metadata
+}
@@ -5,8 +5,8 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"log/slog"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -14,7 +14,9 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/redis/go-redis/v9"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -23,8 +25,12 @@ import (
 var (
 	// 匹配 User-Agent 版本号: xxx/x.y.z
 	userAgentVersionRegex = regexp.MustCompile(`/(\d+)\.(\d+)\.(\d+)`)
+	// 匹配 ClientID: 64 位十六进制（与 generateClientID 生成格式一致）
+	clientIDRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
 )
 
+var ErrFingerprintNotFound = infraerrors.NotFound("FINGERPRINT_NOT_FOUND", "fingerprint not found")
+
 // 默认指纹值（当客户端未提供时使用）
 var defaultFingerprint = Fingerprint{
 	UserAgent:               "claude-cli/" + claude.CLICurrentVersion + " (external, cli)",
@@ -119,6 +125,40 @@ func (s *IdentityService) GetOrCreateFingerprint(ctx context.Context, accountID
 	return fp, nil
 }
 
+// ExportFingerprint 导出账号当前缓存的指纹，用于跨环境迁移账号时保持 ClientID 与 stainless 头不变
+func (s *IdentityService) ExportFingerprint(ctx context.Context, accountID int64) (*Fingerprint, error) {
+	fp, err := s.cache.GetFingerprint(ctx, accountID)
+	if errors.Is(err, redis.Nil) || (err == nil && fp == nil) {
+		return nil, ErrFingerprintNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get fingerprint: %w", err)
+	}
+	return fp, nil
+}
+
+// ImportFingerprint 用导出的指纹覆盖账号缓存中的指纹。
+// ClientID 必须为 64 位十六进制，User-Agent 不能为空；UpdatedAt 重置为当前时间以获得完整 TTL。
+func (s *IdentityService) ImportFingerprint(ctx context.Context, accountID int64, fp *Fingerprint) error {
+	if fp == nil {
+		return infraerrors.BadRequest("FINGERPRINT_INVALID", "fingerprint is required")
+	}
+	imported := *fp
+	imported.ClientID = strings.ToLower(strings.TrimSpace(imported.ClientID))
+	if !clientIDRegex.MatchString(imported.ClientID) {
+		return infraerrors.BadRequest("FINGERPRINT_INVALID", "client_id must be 64 hex characters")
+	}
+	if strings.TrimSpace(imported.UserAgent) == "" {
+		return infraerrors.BadRequest("FINGERPRINT_INVALID", "user_agent is required")
+	}
+	imported.UpdatedAt = time.Now().Unix()
+	if err := s.cache.SetFingerprint(ctx, accountID, &imported); err != nil {
+		return fmt.Errorf("set fingerprint: %w", err)
+	}
+	logger.LegacyPrintf("service.identity", "Imported fingerprint for account %d with client_id: %s", accountID, imported.ClientID)
+	return nil
+}
+
 // createFingerprintFromHeaders 从请求头创建指纹
 func (s *IdentityService) createFingerprintFromHeaders(headers http.Header) *Fingerprint {
 	fp := &Fingerprint{}
@@ -324,11 +364,9 @@ func (s *IdentityService) RewriteUserIDWithMasking(ctx context.Context, body []b
 	version := ExtractCLIVersion(fingerprintUA)
 	newUserID := FormatMetadataUserID(uidParsed.DeviceID, uidParsed.AccountUUID, maskedSessionID, version)
 
-	slog.Debug("session_id_masking_applied",
-		"account_id", account.ID,
-		"before", userID,
-		"after", newUserID,
-	)
+	if debugLogEnabled(LogComponentIdentity) {
+		logger.LegacyPrintf("service.identity", "[Identity] session_id masking applied: account=%d before=%s after=%s", account.ID, userID, newUserID)
+	}
 
 	if newUserID == userID {
 		return newBody, nil
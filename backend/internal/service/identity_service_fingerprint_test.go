@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+type fingerprintCacheStub struct {
+	identityCacheStub
+	fingerprints map[int64]Fingerprint
+	getErr       error
+}
+
+func (s *fingerprintCacheStub) GetFingerprint(_ context.Context, accountID int64) (*Fingerprint, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	fp, ok := s.fingerprints[accountID]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return &fp, nil
+}
+
+func (s *fingerprintCacheStub) SetFingerprint(_ context.Context, accountID int64, fp *Fingerprint) error {
+	s.fingerprints[accountID] = *fp
+	return nil
+}
+
+func TestIdentityService_ExportImportFingerprint(t *testing.T) {
+	ctx := context.Background()
+	source := &fingerprintCacheStub{fingerprints: map[int64]Fingerprint{}}
+	sourceSvc := NewIdentityService(source)
+	original, err := sourceSvc.GetOrCreateFingerprint(ctx, 1, nil)
+	require.NoError(t, err)
+
+	exported, err := sourceSvc.ExportFingerprint(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, original.ClientID, exported.ClientID)
+
+	target := &fingerprintCacheStub{fingerprints: map[int64]Fingerprint{
+		// 目标环境已有的指纹会被覆盖
+		9: {ClientID: strings.Repeat("0", 64), UserAgent: "claude-cli/1.0.0 (external, cli)"},
+	}}
+	targetSvc := NewIdentityService(target)
+	require.NoError(t, targetSvc.ImportFingerprint(ctx, 9, exported))
+
+	restored, err := targetSvc.GetOrCreateFingerprint(ctx, 9, nil)
+	require.NoError(t, err)
+	require.Equal(t, exported.ClientID, restored.ClientID)
+	require.Equal(t, exported.UserAgent, restored.UserAgent)
+	require.Equal(t, exported.StainlessOS, restored.StainlessOS)
+	require.Equal(t, exported.StainlessRuntimeVersion, restored.StainlessRuntimeVersion)
+	require.NotZero(t, restored.UpdatedAt)
+}
+
+func TestIdentityService_ExportFingerprintErrors(t *testing.T) {
+	ctx := context.Background()
+	svc := NewIdentityService(&fingerprintCacheStub{fingerprints: map[int64]Fingerprint{}})
+	_, err := svc.ExportFingerprint(ctx, 1)
+	require.ErrorIs(t, err, ErrFingerprintNotFound)
+
+	boom := errors.New("redis down")
+	svc = NewIdentityService(&fingerprintCacheStub{fingerprints: map[int64]Fingerprint{}, getErr: boom})
+	_, err = svc.ExportFingerprint(ctx, 1)
+	require.ErrorIs(t, err, boom)
+}
+
+func TestIdentityService_ImportFingerprintValidatesClientID(t *testing.T) {
+	ctx := context.Background()
+	cache := &fingerprintCacheStub{fingerprints: map[int64]Fingerprint{}}
+	svc := NewIdentityService(cache)
+
+	valid := strings.Repeat("ab", 32)
+	cases := []struct {
+		name string
+		fp   *Fingerprint
+	}{
+		{name: "nil", fp: nil},
+		{name: "empty client id", fp: &Fingerprint{UserAgent: "ua"}},
+		{name: "short client id", fp: &Fingerprint{ClientID: valid[:63], UserAgent: "ua"}},
+		{name: "non hex client id", fp: &Fingerprint{ClientID: strings.Repeat("zz", 32), UserAgent: "ua"}},
+		{name: "uuid client id", fp: &Fingerprint{ClientID: "7578cf37-aaca-46e4-a45c-71285d9dbb83", UserAgent: "ua"}},
+		{name: "missing user agent", fp: &Fingerprint{ClientID: valid}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := svc.ImportFingerprint(ctx, 1, tc.fp)
+			require.Error(t, err)
+			require.Equal(t, "FINGERPRINT_INVALID", infraerrors.Reason(err))
+			require.Empty(t, cache.fingerprints)
+		})
+	}
+
+	// 大写十六进制统一转为小写
+	require.NoError(t, svc.ImportFingerprint(ctx, 1, &Fingerprint{ClientID: strings.ToUpper(valid), UserAgent: "ua"}))
+	require.Equal(t, valid, cache.fingerprints[1].ClientID)
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// 可在运行时调整级别的日志组件。
+const (
+	// LogComponentGatewayGemini Gemini 上游响应头调试日志（默认取 gateway.gemini_debug_response_headers）
+	LogComponentGatewayGemini = "gateway.gemini"
+	// LogComponentGatewayModelRouting 模型路由/调度调试日志（默认取 SUB2API_DEBUG_MODEL_ROUTING）
+	LogComponentGatewayModelRouting = "gateway.model_routing"
+	// LogComponentGatewayClaudeMimic Claude Code 伪装请求调试日志（默认取 SUB2API_DEBUG_CLAUDE_MIMIC）
+	LogComponentGatewayClaudeMimic = "gateway.claude_mimic"
+	// LogComponentIdentity 指纹与伪装 session ID 调试日志（默认取 SUB2API_DEBUG_IDENTITY）
+	LogComponentIdentity = "identity"
+)
+
+// maxLogComponentRevertMinutes 自动恢复时长上限，避免调试级别被遗忘后长期开启。
+const maxLogComponentRevertMinutes = 24 * 60
+
+func debugLogLevel(enabled bool) logger.Level {
+	if enabled {
+		return logger.LevelDebug
+	}
+	return logger.LevelInfo
+}
+
+// registerDebugLogComponents 以配置/环境变量作为启动默认值注册调试组件。
+// 重复调用幂等，且不会覆盖管理员在运行时设置的级别。
+func registerDebugLogComponents(cfg *config.Config) {
+	geminiDebug := cfg != nil && cfg.Gateway.GeminiDebugResponseHeaders
+	logger.RegisterComponent(LogComponentGatewayGemini, debugLogLevel(geminiDebug))
+	logger.RegisterComponent(LogComponentGatewayModelRouting, debugLogLevel(parseDebugEnvBool(os.Getenv("SUB2API_DEBUG_MODEL_ROUTING"))))
+	logger.RegisterComponent(LogComponentGatewayClaudeMimic, debugLogLevel(parseDebugEnvBool(os.Getenv("SUB2API_DEBUG_CLAUDE_MIMIC"))))
+	logger.RegisterComponent(LogComponentIdentity, debugLogLevel(parseDebugEnvBool(os.Getenv("SUB2API_DEBUG_IDENTITY"))))
+}
+
+// debugLogEnabled 判断组件当前是否输出调试日志。
+func debugLogEnabled(component string) bool {
+	return logger.ComponentEnabled(component, logger.LevelDebug)
+}
+
+// ListLogComponentLevels 返回所有组件的当前日志级别。
+func ListLogComponentLevels() []logger.ComponentLevel {
+	return logger.ComponentLevels()
+}
+
+// SetLogComponentLevel 运行时设置组件日志级别；revertAfterMinutes>0 时到期自动恢复默认级别。
+func SetLogComponentLevel(component, level string, revertAfterMinutes int) (logger.ComponentLevel, error) {
+	if strings.TrimSpace(component) == "" {
+		return logger.ComponentLevel{}, infraerrors.BadRequest("LOG_COMPONENT_REQUIRED", "component is required")
+	}
+	if revertAfterMinutes < 0 || revertAfterMinutes > maxLogComponentRevertMinutes {
+		return logger.ComponentLevel{}, infraerrors.BadRequest("LOG_COMPONENT_INVALID_REVERT", "revert_after_minutes must be between 0 and 1440")
+	}
+	out, err := logger.SetComponentLevel(component, level, time.Duration(revertAfterMinutes)*time.Minute)
+	if err != nil {
+		return logger.ComponentLevel{}, infraerrors.BadRequest("LOG_COMPONENT_INVALID", err.Error())
+	}
+	return out, nil
+}
+
+// ResetLogComponentLevel 将组件恢复为启动默认级别。
+func ResetLogComponentLevel(component string) (logger.ComponentLevel, error) {
+	out, err := logger.ResetComponentLevel(component)
+	if err != nil {
+		return logger.ComponentLevel{}, infraerrors.BadRequest("LOG_COMPONENT_INVALID", err.Error())
+	}
+	return out, nil
+}
+
+// logComponentSyncInterval 从共享存储同步组件级别覆盖的周期
+const logComponentSyncInterval = 10 * time.Second
+
+// LogComponentOverride 管理员设置的组件级别覆盖，经共享存储同步到所有实例。
+type LogComponentOverride struct {
+	Component string     `json:"component"`
+	Level     string     `json:"level"`
+	RevertAt  *time.Time `json:"revert_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (o LogComponentOverride) expired(now time.Time) bool {
+	return o.RevertAt != nil && !now.Before(*o.RevertAt)
+}
+
+// LogComponentLevelStore 组件级别覆盖存储（Redis），供多实例共享
+type LogComponentLevelStore interface {
+	SaveLogComponentOverride(ctx context.Context, override LogComponentOverride) error
+	ListLogComponentOverrides(ctx context.Context) ([]LogComponentOverride, error)
+	DeleteLogComponentOverride(ctx context.Context, component string) error
+}
+
+// LogComponentLevelService 管理运行时组件日志级别：本实例立即生效并写入共享存储，
+// 其他实例按同步周期应用，使多实例部署的调试级别保持一致。
+// 自动恢复时间随覆盖一起存储，各实例按同一截止时间恢复默认级别。
+type LogComponentLevelService struct {
+	store LogComponentLevelStore
+
+	mu sync.Mutex
+	// applied 本实例已应用的覆盖（按组件记录 UpdatedAt），用于跳过未变化的覆盖
+	applied map[string]time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+// NewLogComponentLevelService 创建组件日志级别服务；store 为 nil 时仅在本实例生效
+func NewLogComponentLevelService(store LogComponentLevelStore) *LogComponentLevelService {
+	return &LogComponentLevelService{
+		store:   store,
+		applied: make(map[string]time.Time),
+		stopCh:  make(chan struct{}),
+		now:     time.Now,
+	}
+}
+
+// Start 启动共享存储同步循环
+func (s *LogComponentLevelService) Start() {
+	if s == nil || s.store == nil {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(logComponentSyncInterval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.sync(ctx)
+			cancel()
+			select {
+			case <-ticker.C:
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止同步循环
+func (s *LogComponentLevelService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+// List 返回所有组件的当前日志级别
+func (s *LogComponentLevelService) List() []logger.ComponentLevel {
+	return ListLogComponentLevels()
+}
+
+// Set 运行时设置组件日志级别并同步到其他实例；写入共享存储失败时回滚本实例的修改
+func (s *LogComponentLevelService) Set(ctx context.Context, component, level string, revertAfterMinutes int) (logger.ComponentLevel, error) {
+	previous, hadPrevious := findComponentLevel(component)
+	out, err := SetLogComponentLevel(component, level, revertAfterMinutes)
+	if err != nil || s == nil || s.store == nil {
+		return out, err
+	}
+	override := LogComponentOverride{
+		Component: out.Component,
+		Level:     out.Level,
+		RevertAt:  out.RevertAt,
+		UpdatedAt: s.now().UTC(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store.SaveLogComponentOverride(ctx, override); err != nil {
+		if hadPrevious {
+			restoreComponentLevel(previous, s.now())
+		}
+		return logger.ComponentLevel{}, err
+	}
+	s.applied[override.Component] = override.UpdatedAt
+	return out, nil
+}
+
+// Reset 将组件恢复为启动默认级别，并清除共享存储中的覆盖
+func (s *LogComponentLevelService) Reset(ctx context.Context, component string) (logger.ComponentLevel, error) {
+	out, err := ResetLogComponentLevel(component)
+	if err != nil || s == nil || s.store == nil {
+		return out, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store.DeleteLogComponentOverride(ctx, out.Component); err != nil {
+		return logger.ComponentLevel{}, err
+	}
+	delete(s.applied, out.Component)
+	return out, nil
+}
+
+// sync 应用共享存储中的覆盖：新增或更新的覆盖按其截止时间生效，已删除的覆盖恢复默认级别，
+// 已过自动恢复时间的覆盖从存储中删除。
+func (s *LogComponentLevelService) sync(ctx context.Context) {
+	overrides, err := s.store.ListLogComponentOverrides(ctx)
+	if err != nil {
+		slog.Warn("log_component_sync_failed", "error", err)
+		return
+	}
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]struct{}, len(overrides))
+	for _, override := range overrides {
+		if override.expired(now) {
+			if err := s.store.DeleteLogComponentOverride(ctx, override.Component); err != nil {
+				slog.Warn("log_component_delete_failed", "component", override.Component, "error", err)
+			}
+			continue
+		}
+		seen[override.Component] = struct{}{}
+		if applied, ok := s.applied[override.Component]; ok && applied.Equal(override.UpdatedAt) {
+			continue
+		}
+		var revertAfter time.Duration
+		if override.RevertAt != nil {
+			revertAfter = override.RevertAt.Sub(now)
+		}
+		if _, err := logger.SetComponentLevel(override.Component, override.Level, revertAfter); err != nil {
+			// 组件在本实例未注册（版本不一致）或级别非法时跳过
+			slog.Debug("log_component_apply_skipped", "component", override.Component, "error", err)
+			continue
+		}
+		s.applied[override.Component] = override.UpdatedAt
+	}
+	for component := range s.applied {
+		if _, ok := seen[component]; ok {
+			continue
+		}
+		_, _ = logger.ResetComponentLevel(component)
+		delete(s.applied, component)
+	}
+}
+
+func findComponentLevel(component string) (logger.ComponentLevel, bool) {
+	name := strings.ToLower(strings.TrimSpace(component))
+	for _, level := range logger.ComponentLevels() {
+		if level.Component == name {
+			return level, true
+		}
+	}
+	return logger.ComponentLevel{}, false
+}
+
+// restoreComponentLevel 将组件恢复到 previous 记录的级别与自动恢复时间
+func restoreComponentLevel(previous logger.ComponentLevel, now time.Time) {
+	var revertAfter time.Duration
+	if previous.RevertAt != nil {
+		revertAfter = previous.RevertAt.Sub(now)
+	}
+	if (previous.RevertAt == nil && previous.Level == previous.DefaultLevel) || (previous.RevertAt != nil && revertAfter <= 0) {
+		_, _ = logger.ResetComponentLevel(previous.Component)
+		return
+	}
+	_, _ = logger.SetComponentLevel(previous.Component, previous.Level, revertAfter)
+}
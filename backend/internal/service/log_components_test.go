@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func resetDebugLogComponentsForTest(t *testing.T) {
+	t.Helper()
+	registerDebugLogComponents(&config.Config{})
+	t.Cleanup(func() {
+		registerDebugLogComponents(&config.Config{})
+		for _, component := range []string{LogComponentGatewayGemini, LogComponentGatewayModelRouting, LogComponentGatewayClaudeMimic, LogComponentIdentity} {
+			_, _ = ResetLogComponentLevel(component)
+		}
+	})
+}
+
+func emitGeminiNativeResponseHeaders(t *testing.T) *inMemoryLogSink {
+	t.Helper()
+	sink := &inMemoryLogSink{}
+	logger.SetSink(sink)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type":      []string{"application/json"},
+			"X-RateLimit-Limit": []string{"60"},
+		},
+		Body: io.NopCloser(strings.NewReader(`{"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":2}}`)),
+	}
+	svc := &GeminiMessagesCompatService{cfg: &config.Config{}}
+	_, err := svc.handleNativeNonStreamingResponse(c, resp, false)
+	require.NoError(t, err)
+	return sink
+}
+
+func TestLogComponentLevel_RuntimeToggleChangesEmission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, restore := captureStructuredLog(t)
+	defer restore()
+	resetDebugLogComponentsForTest(t)
+
+	require.False(t, emitGeminiNativeResponseHeaders(t).ContainsMessage("[GeminiAPI]"))
+
+	updated, err := SetLogComponentLevel(LogComponentGatewayGemini, "debug", 0)
+	require.NoError(t, err)
+	require.Equal(t, "debug", updated.Level)
+	require.Equal(t, "info", updated.DefaultLevel)
+	require.True(t, emitGeminiNativeResponseHeaders(t).ContainsMessage("[GeminiAPI]"), "运行时开启后应立即输出调试日志")
+
+	_, err = ResetLogComponentLevel(LogComponentGatewayGemini)
+	require.NoError(t, err)
+	require.False(t, emitGeminiNativeResponseHeaders(t).ContainsMessage("[GeminiAPI]"), "恢复默认后应停止输出")
+}
+
+func TestRegisterDebugLogComponents_ConfigIsStartupDefault(t *testing.T) {
+	resetDebugLogComponentsForTest(t)
+	t.Setenv("SUB2API_DEBUG_MODEL_ROUTING", "true")
+
+	registerDebugLogComponents(&config.Config{Gateway: config.GatewayConfig{GeminiDebugResponseHeaders: true}})
+	require.True(t, debugLogEnabled(LogComponentGatewayGemini))
+	require.True(t, (&GatewayService{}).debugModelRoutingEnabled())
+	require.False(t, (&GatewayService{}).debugClaudeMimicEnabled())
+
+	// 运行时覆盖在重复注册（例如服务重建）时保持不变
+	_, err := SetLogComponentLevel(LogComponentGatewayGemini, "info", 0)
+	require.NoError(t, err)
+	registerDebugLogComponents(&config.Config{Gateway: config.GatewayConfig{GeminiDebugResponseHeaders: true}})
+	require.False(t, debugLogEnabled(LogComponentGatewayGemini))
+
+	reset, err := ResetLogComponentLevel(LogComponentGatewayGemini)
+	require.NoError(t, err)
+	require.Equal(t, "debug", reset.Level)
+}
+
+func TestSetLogComponentLevel_Validation(t *testing.T) {
+	resetDebugLogComponentsForTest(t)
+
+	cases := []struct {
+		name      string
+		component string
+		level     string
+		revert    int
+		reason    string
+	}{
+		{name: "missing component", component: " ", level: "debug", reason: "LOG_COMPONENT_REQUIRED"},
+		{name: "unknown component", component: "sora.sdk", level: "debug", reason: "LOG_COMPONENT_INVALID"},
+		{name: "invalid level", component: LogComponentGatewayGemini, level: "trace", reason: "LOG_COMPONENT_INVALID"},
+		{name: "negative revert", component: LogComponentGatewayGemini, level: "debug", revert: -1, reason: "LOG_COMPONENT_INVALID_REVERT"},
+		{name: "revert too long", component: LogComponentGatewayGemini, level: "debug", revert: 24*60 + 1, reason: "LOG_COMPONENT_INVALID_REVERT"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := SetLogComponentLevel(tc.component, tc.level, tc.revert)
+			require.Error(t, err)
+			require.Equal(t, tc.reason, infraerrors.Reason(err))
+		})
+	}
+
+	updated, err := SetLogComponentLevel(" Gateway.Gemini ", "DEBUG", 30)
+	require.NoError(t, err)
+	require.Equal(t, LogComponentGatewayGemini, updated.Component)
+	require.NotNil(t, updated.RevertAt)
+}
+
+type logComponentLevelStoreStub struct {
+	mu        sync.Mutex
+	overrides map[string]LogComponentOverride
+	saveErr   error
+}
+
+func newLogComponentLevelStoreStub() *logComponentLevelStoreStub {
+	return &logComponentLevelStoreStub{overrides: make(map[string]LogComponentOverride)}
+}
+
+func (s *logComponentLevelStoreStub) SaveLogComponentOverride(_ context.Context, override LogComponentOverride) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.overrides[override.Component] = override
+	return nil
+}
+
+func (s *logComponentLevelStoreStub) ListLogComponentOverrides(context.Context) ([]LogComponentOverride, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]LogComponentOverride, 0, len(s.overrides))
+	for _, override := range s.overrides {
+		out = append(out, override)
+	}
+	return out, nil
+}
+
+func (s *logComponentLevelStoreStub) DeleteLogComponentOverride(_ context.Context, component string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overrides, component)
+	return nil
+}
+
+func TestLogComponentLevelService_SyncsOverridesAcrossInstances(t *testing.T) {
+	resetDebugLogComponentsForTest(t)
+	ctx := context.Background()
+	store := newLogComponentLevelStoreStub()
+	instanceA := NewLogComponentLevelService(store)
+	instanceB := NewLogComponentLevelService(store)
+
+	_, err := instanceA.Set(ctx, LogComponentIdentity, "debug", 30)
+	require.NoError(t, err)
+	require.Contains(t, store.overrides, LogComponentIdentity)
+
+	// 模拟另一实例：本地仍为默认级别，同步后应用共享覆盖及其自动恢复时间
+	_, err = ResetLogComponentLevel(LogComponentIdentity)
+	require.NoError(t, err)
+	require.False(t, debugLogEnabled(LogComponentIdentity))
+	instanceB.sync(ctx)
+	require.True(t, debugLogEnabled(LogComponentIdentity))
+	level, ok := findComponentLevel(LogComponentIdentity)
+	require.True(t, ok)
+	require.NotNil(t, level.RevertAt)
+
+	// 覆盖被其他实例清除后，同步恢复默认级别
+	_, err = instanceA.Reset(ctx, LogComponentIdentity)
+	require.NoError(t, err)
+	require.Empty(t, store.overrides)
+	_, err = SetLogComponentLevel(LogComponentIdentity, "debug", 0)
+	require.NoError(t, err)
+	instanceB.sync(ctx)
+	require.False(t, debugLogEnabled(LogComponentIdentity))
+}
+
+func TestLogComponentLevelService_ExpiredOverrideIsDeleted(t *testing.T) {
+	resetDebugLogComponentsForTest(t)
+	ctx := context.Background()
+	store := newLogComponentLevelStoreStub()
+	past := time.Now().Add(-time.Minute)
+	store.overrides[LogComponentIdentity] = LogComponentOverride{Component: LogComponentIdentity, Level: "debug", RevertAt: &past, UpdatedAt: past.Add(-time.Hour)}
+
+	NewLogComponentLevelService(store).sync(ctx)
+	require.Empty(t, store.overrides)
+	require.False(t, debugLogEnabled(LogComponentIdentity))
+}
+
+func TestLogComponentLevelService_SaveFailureRollsBack(t *testing.T) {
+	resetDebugLogComponentsForTest(t)
+	store := newLogComponentLevelStoreStub()
+	store.saveErr = errors.New("redis down")
+
+	_, err := NewLogComponentLevelService(store).Set(context.Background(), LogComponentIdentity, "debug", 0)
+	require.Error(t, err)
+	require.False(t, debugLogEnabled(LogComponentIdentity))
+}
@@ -399,7 +399,7 @@ func (s *defaultOpenAIAccountScheduler) selectBySessionHash(
 	}
 	result, acquireErr := s.service.tryAcquireAccountSlot(ctx, accountID, account.Concurrency)
 	if acquireErr == nil && result != nil && result.Acquired {
-		_ = s.service.refreshStickySessionTTL(ctx, req.GroupID, sessionHash, s.service.openAIWSSessionStickyTTL())
+		_ = s.service.refreshStickySessionTTL(ctx, req.GroupID, sessionHash, openAIStickySessionTTLFromRequest(ctx, s.service.openAIWSSessionStickyTTL()))
 		return &AccountSelectionResult{
 			Account:     account,
 			Acquired:    true,
@@ -668,6 +668,16 @@ func buildOpenAIWeightedSelectionOrder(
 	return order
 }
 
+// isAccountDeprioritized 账号是否处于软降权状态：主动探测不健康、上游限流余量不足或持续 5xx 降权期
+func (s *defaultOpenAIAccountScheduler) isAccountDeprioritized(accountID int64) bool {
+	if s.service == nil {
+		return false
+	}
+	return s.service.accountHealth.IsAccountUnhealthy(accountID) ||
+		s.service.upstreamRateLimits.IsAccountConstrained(accountID) ||
+		s.service.serverErrors.IsAccountPenalized(accountID)
+}
+
 func (s *defaultOpenAIAccountScheduler) buildOpenAIAccountLoadPlan(
 	req OpenAIAccountScheduleRequest,
 	filtered []*Account,
@@ -683,6 +693,10 @@ func (s *defaultOpenAIAccountScheduler) buildOpenAIAccountLoadPlan(
 		if s.stats != nil {
 			errorRate, ttft, hasTTFT = s.stats.snapshot(account.ID)
 		}
+		if s.isAccountDeprioritized(account.ID) {
+			// 软降权账号按错误率 100% 参与打分，仅在其他账号不可用时选中
+			errorRate = 1
+		}
 		allCandidates = append(allCandidates, openAIAccountCandidateScore{
 			account:   account,
 			loadInfo:  loadInfo,
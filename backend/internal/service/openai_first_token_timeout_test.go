@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newOpenAIFirstTokenTimeoutTestContext(t *testing.T) (*OpenAIGatewayService, *gin.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{
+		Gateway: config.GatewayConfig{
+			FirstTokenTimeoutSeconds: 1,
+			StreamKeepaliveInterval:  0,
+			MaxLineSize:              defaultMaxLineSize,
+		},
+	}}
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+	return svc, c, rec
+}
+
+func TestOpenAIStreamingFirstTokenTimeout_SilentUpstreamFailsOver(t *testing.T) {
+	svc, c, rec := newOpenAIFirstTokenTimeoutTestContext(t)
+
+	// 上游接受请求但只发 preamble，始终不输出内容。
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+	go func() {
+		_, _ = pw.Write([]byte("data: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_silent\"}}\n\n"))
+	}()
+	resp := &http.Response{StatusCode: http.StatusOK, Body: pr, Header: http.Header{}}
+
+	started := time.Now()
+	_, err := svc.handleStreamingResponse(c.Request.Context(), resp, c, &Account{ID: 1, Platform: PlatformOpenAI}, started, "gpt-5.1", "gpt-5.1")
+
+	var failoverErr *UpstreamFailoverError
+	require.True(t, errors.As(err, &failoverErr), "expected UpstreamFailoverError, got %v", err)
+	require.Equal(t, http.StatusBadGateway, failoverErr.StatusCode)
+	require.Less(t, time.Since(started), 3*time.Second)
+	require.False(t, c.Writer.Written())
+	require.Empty(t, rec.Body.String())
+
+	// 上游已被中止，继续写入应失败。
+	_, writeErr := pw.Write([]byte("data: {}\n\n"))
+	require.Error(t, writeErr)
+}
+
+func TestOpenAIStreamingFirstTokenTimeout_DelayedUpstreamWithinTimeout(t *testing.T) {
+	svc, c, rec := newOpenAIFirstTokenTimeoutTestContext(t)
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer func() { _ = pw.Close() }()
+		time.Sleep(200 * time.Millisecond)
+		_, _ = pw.Write([]byte("data: {\"type\":\"response.output_text.delta\",\"delta\":\"hi\"}\n\n"))
+		_, _ = pw.Write([]byte("data: {\"type\":\"response.completed\",\"response\":{\"usage\":{\"input_tokens\":2,\"output_tokens\":1}}}\n\n"))
+	}()
+	resp := &http.Response{StatusCode: http.StatusOK, Body: pr, Header: http.Header{}}
+
+	result, err := svc.handleStreamingResponse(c.Request.Context(), resp, c, &Account{ID: 2}, time.Now(), "gpt-5.1", "gpt-5.1")
+	require.NoError(t, err)
+	require.NotNil(t, result.firstTokenMs)
+	require.GreaterOrEqual(t, *result.firstTokenMs, 200)
+	require.Equal(t, 1, result.usage.OutputTokens)
+	require.Contains(t, rec.Body.String(), "response.output_text.delta")
+}
+
+func TestOpenAIStreamingFirstTokenTimeout_NotAppliedAfterFirstDelta(t *testing.T) {
+	svc, c, rec := newOpenAIFirstTokenTimeoutTestContext(t)
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer func() { _ = pw.Close() }()
+		_, _ = pw.Write([]byte("data: {\"type\":\"response.output_text.delta\",\"delta\":\"hi\"}\n\n"))
+		// 首 token 之后的停顿超过首 token 超时，不应触发切换。
+		time.Sleep(1500 * time.Millisecond)
+		_, _ = pw.Write([]byte("data: {\"type\":\"response.completed\",\"response\":{\"usage\":{\"input_tokens\":2,\"output_tokens\":1}}}\n\n"))
+	}()
+	resp := &http.Response{StatusCode: http.StatusOK, Body: pr, Header: http.Header{}}
+
+	result, err := svc.handleStreamingResponse(c.Request.Context(), resp, c, &Account{ID: 3}, time.Now(), "gpt-5.1", "gpt-5.1")
+	require.NoError(t, err)
+	require.NotNil(t, result.firstTokenMs)
+	require.Contains(t, rec.Body.String(), "response.completed")
+}
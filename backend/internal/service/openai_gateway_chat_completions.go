@@ -167,7 +167,6 @@ func (s *OpenAIGatewayService) ForwardAsChatCompletions(
 			return nil, fmt.Errorf("marshal responses request: %w", err)
 		}
 	}
-
 	logFields := []zap.Field{
 		zap.Int64("account_id", account.ID),
 		zap.String("original_model", originalModel),
@@ -350,6 +349,9 @@ func (s *OpenAIGatewayService) ForwardAsChatCompletions(
 			s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
 		}
 	}
+	if result != nil {
+		result.UpstreamRateLimit = s.upstreamRateLimits.Observe(account, resp.Header)
+	}
 
 	return result, handleErr
 }
@@ -93,6 +93,7 @@ func (s *OpenAIGatewayService) forwardAsRawChatCompletions(
 	if normalizedBody, normalized := NormalizeGLMOpenAIReasoningEffort(upstreamBody, upstreamModel); normalized {
 		upstreamBody = normalizedBody
 	}
+	upstreamBody = foldChatInstructionsIntoSystemMessage(upstreamBody)
 
 	// 4. Apply OpenAI fast policy on the CC body
 	updatedBody, policyErr := s.applyOpenAIFastPolicyToBody(ctx, account, upstreamModel, upstreamBody)
@@ -151,14 +152,7 @@ func (s *OpenAIGatewayService) forwardAsRawChatCompletions(
 	}
 
 	// 透传白名单中的客户端 header。详见 openaiCCRawAllowedHeaders 的设计说明。
-	for key, values := range c.Request.Header {
-		lowerKey := strings.ToLower(key)
-		if openaiCCRawAllowedHeaders[lowerKey] {
-			for _, v := range values {
-				upstreamReq.Header.Add(key, v)
-			}
-		}
-	}
+	s.forwardHeaders.copyClientHeaders(upstreamReq.Header, c.Request.Header, isOpenAICCRawAllowedRequestHeader)
 	customUA := account.GetOpenAIUserAgent()
 	if customUA != "" {
 		upstreamReq.Header.Set("user-agent", customUA)
@@ -322,6 +316,7 @@ func (s *OpenAIGatewayService) streamRawChatCompletions(
 		if clientDisconnected {
 			return
 		}
+		line = s.responseRedactor.RedactSSE(line)
 		if !clientOutputStarted && !refusalDetector.ShouldReleaseClientOutput() {
 			pendingLines = append(pendingLines, line)
 			return
@@ -504,7 +499,7 @@ func (s *OpenAIGatewayService) bufferRawChatCompletions(
 		c.Writer.Header().Set("Content-Type", "application/json")
 	}
 	c.Writer.WriteHeader(http.StatusOK)
-	_, _ = c.Writer.Write(respBody)
+	_, _ = c.Writer.Write(s.responseRedactor.RedactBody(respBody))
 
 	return &OpenAIForwardResult{
 		RequestID:       requestID,
@@ -530,3 +525,40 @@ func (s *OpenAIGatewayService) bufferRawChatCompletions(
 func buildOpenAIChatCompletionsURL(base string) string {
 	return buildOpenAIEndpointURL(base, "/v1/chat/completions")
 }
+
+// foldChatInstructionsIntoSystemMessage 把顶层 instructions（Responses 兼容字段 / 分组注入）
+// 改写为首条 system 消息：Chat Completions 上游不认识 instructions，严格上游会直接 400。
+func foldChatInstructionsIntoSystemMessage(body []byte) []byte {
+	instructions := gjson.GetBytes(body, "instructions")
+	if !instructions.Exists() {
+		return body
+	}
+	out, err := sjson.DeleteBytes(body, "instructions")
+	if err != nil {
+		return body
+	}
+	text := strings.TrimSpace(instructions.String())
+	if text == "" {
+		return out
+	}
+	messages := gjson.GetBytes(out, "messages")
+	items := make([]json.RawMessage, 0, len(messages.Array())+1)
+	system, err := json.Marshal(map[string]string{"role": "system", "content": text})
+	if err != nil {
+		return body
+	}
+	items = append(items, system)
+	messages.ForEach(func(_, msg gjson.Result) bool {
+		items = append(items, json.RawMessage(msg.Raw))
+		return true
+	})
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return body
+	}
+	out, err = sjson.SetRawBytes(out, "messages", raw)
+	if err != nil {
+		return body
+	}
+	return out
+}
@@ -590,3 +590,63 @@ func largeRawChatCompletionsBody() []byte {
 		strings.Repeat("x", openAISilentRefusalMinRequestBodyBytes) +
 		`"}],"stream":true}`)
 }
+
+func TestFoldChatInstructionsIntoSystemMessage(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"model":"deepseek-chat","instructions":"Follow policy.","messages":[{"role":"system","content":"client rules"},{"role":"user","content":"hi"}]}`)
+	out := foldChatInstructionsIntoSystemMessage(body)
+	require.False(t, gjson.GetBytes(out, "instructions").Exists())
+	require.Equal(t, "system", gjson.GetBytes(out, "messages.0.role").String())
+	require.Equal(t, "Follow policy.", gjson.GetBytes(out, "messages.0.content").String())
+	require.Equal(t, "client rules", gjson.GetBytes(out, "messages.1.content").String())
+	require.Equal(t, "hi", gjson.GetBytes(out, "messages.2.content").String())
+
+	plain := []byte(`{"model":"deepseek-chat","messages":[{"role":"user","content":"hi"}]}`)
+	require.Equal(t, plain, foldChatInstructionsIntoSystemMessage(plain))
+}
+
+func TestForwardAsRawChatCompletions_AppliesResponseRedaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := rawChatCompletionsTestConfig()
+	cfg.Security.ResponseRedaction = config.ResponseRedactionConfig{
+		Enabled: true,
+		Platforms: map[string][]config.ResponseRedactionRule{
+			PlatformOpenAI: {{Path: "system_fingerprint", Action: config.ResponseRedactionActionDelete}},
+		},
+	}
+
+	for _, stream := range []bool{false, true} {
+		body := []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"hello"}],"stream":false}`)
+		upstreamBody := `{"id":"chatcmpl_1","object":"chat.completion","model":"gpt-4o","system_fingerprint":"fp_upstream42","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`
+		contentType := "application/json"
+		if stream {
+			body = []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"hello"}],"stream":true}`)
+			upstreamBody = strings.Join([]string{
+				`data: {"id":"chatcmpl_1","object":"chat.completion.chunk","model":"gpt-4o","system_fingerprint":"fp_upstream42","choices":[{"index":0,"delta":{"content":"hi"},"finish_reason":null}]}`,
+				"",
+				`data: {"id":"chatcmpl_1","object":"chat.completion.chunk","model":"gpt-4o","system_fingerprint":"fp_upstream42","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`,
+				"",
+				"data: [DONE]",
+				"",
+			}, "\n")
+			contentType = "text/event-stream"
+		}
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		upstream := &httpUpstreamRecorder{resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       io.NopCloser(strings.NewReader(upstreamBody)),
+		}}
+		svc := &OpenAIGatewayService{cfg: cfg, httpUpstream: upstream, responseRedactor: compileResponseRedactor(cfg, PlatformOpenAI)}
+
+		result, err := svc.forwardAsRawChatCompletions(context.Background(), c, rawChatCompletionsTestAccount(), body, "")
+		require.NoError(t, err)
+		require.Equal(t, 3, result.Usage.InputTokens)
+		require.NotContains(t, rec.Body.String(), "fp_upstream42")
+		require.Contains(t, rec.Body.String(), `"content":"hi"`)
+	}
+}
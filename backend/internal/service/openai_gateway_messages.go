@@ -148,7 +148,6 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 	if err != nil {
 		return nil, fmt.Errorf("marshal responses request: %w", err)
 	}
-
 	if account.Type == AccountTypeOAuth {
 		var reqBody map[string]any
 		if err := json.Unmarshal(responsesBody, &reqBody); err != nil {
@@ -399,6 +398,9 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 			s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
 		}
 	}
+	if result != nil {
+		result.UpstreamRateLimit = s.upstreamRateLimits.Observe(account, resp.Header)
+	}
 
 	return result, handleErr
 }
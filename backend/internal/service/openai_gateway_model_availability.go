@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"strings"
 )
 
@@ -29,6 +30,9 @@ func (s *OpenAIGatewayService) DiagnoseModelAvailabilityForPlatform(
 	}
 
 	accounts, err := s.listSchedulableAccounts(ctx, groupID, platform)
+	if errors.Is(err, ErrNoAccountsMatchingLabels) {
+		return ModelAvailabilityDiagnosis{HasAccountsInPool: true, HasModelSupport: true, NoLabelMatch: true}
+	}
 	if err != nil {
 		// Conservative fallback so the caller keeps returning 503; we do not
 		// want a transient lookup failure to flip into 404 model_not_found.
@@ -349,6 +349,7 @@ func TestOpenAIGatewayServiceRecordUsage_MissingPricingRecordsZeroCostUsageLog(t
 	require.Equal(t, "resp_missing_pricing", usageRepo.lastLog.RequestID)
 	require.Equal(t, "pricing-missing-test-model", usageRepo.lastLog.Model)
 	require.Equal(t, "pricing-missing-test-model", usageRepo.lastLog.RequestedModel)
+	require.True(t, usageRepo.lastLog.PricingUnavailable)
 	require.Equal(t, 1200, usageRepo.lastLog.InputTokens)
 	require.Equal(t, 300, usageRepo.lastLog.OutputTokens)
 	require.Zero(t, usageRepo.lastLog.TotalCost)
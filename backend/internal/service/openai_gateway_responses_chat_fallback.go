@@ -126,14 +126,7 @@ func (s *OpenAIGatewayService) forwardResponsesViaRawChatCompletions(
 	} else {
 		upstreamReq.Header.Set("Accept", "application/json")
 	}
-	for key, values := range c.Request.Header {
-		lowerKey := strings.ToLower(key)
-		if openaiCCRawAllowedHeaders[lowerKey] {
-			for _, v := range values {
-				upstreamReq.Header.Add(key, v)
-			}
-		}
-	}
+	s.forwardHeaders.copyClientHeaders(upstreamReq.Header, c.Request.Header, isOpenAICCRawAllowedRequestHeader)
 	if customUA := account.GetOpenAIUserAgent(); customUA != "" {
 		upstreamReq.Header.Set("user-agent", customUA)
 	}
@@ -253,6 +253,11 @@ type OpenAIForwardResult struct {
 	ImageOutputSizes   []string
 	ImageSizeSource    string
 	ImageSizeBreakdown map[string]int
+	// EstimatedUsage 表示 Usage 为估算值（上游流式响应缺失 usage），计费时叠加
+	// gateway.estimated_usage_rate_multiplier。
+	EstimatedUsage bool
+	// UpstreamRateLimit 上游响应头报告的限流余量（未启用或上游未返回时为 nil）
+	UpstreamRateLimit *UpstreamRateLimitSnapshot
 
 	wsReplayInput       []json.RawMessage
 	wsReplayInputExists bool
@@ -368,6 +373,10 @@ type OpenAIGatewayService struct {
 	openaiScheduler               OpenAIAccountScheduler
 	openaiWSPassthroughDialer     openAIWSClientDialer
 	openaiAccountStats            *openAIAccountRuntimeStats
+	accountHealth                 *AccountHealthProbeService
+	upstreamRateLimits            *UpstreamRateLimitTracker
+	serverErrors                  *AccountServerErrorTracker
+	usageRecordRetry              *UsageRecordRetryService
 
 	openaiWSFallbackUntil               sync.Map // key: int64(accountID), value: time.Time
 	openaiAccountRuntimeBlockUntil      sync.Map // key: int64(accountID), value: time.Time
@@ -375,7 +384,10 @@ type OpenAIGatewayService struct {
 	openaiOAuth429WindowCount           atomic.Int64
 	openaiWSRetryMetrics                openAIWSRetryMetrics
 	responseHeaderFilter                *responseheaders.CompiledHeaderFilter
+	forwardHeaders                      *forwardHeaderPolicy
+	responseRedactor                    *responseRedactor
 	codexSnapshotThrottle               *accountWriteThrottle
+	ttftStats                           *TTFTStats // 按模型的首 token 延迟分位数
 	openaiCompatSessionResponses        sync.Map
 	openaiCompatAnthropicDigestSessions sync.Map
 }
@@ -437,8 +449,11 @@ func NewOpenAIGatewayService(
 		balanceNotifyService:  balanceNotifyService,
 		settingService:        settingService,
 		userPlatformQuotaRepo: userPlatformQuotaRepo,
-		responseHeaderFilter:  compileResponseHeaderFilter(cfg),
+		responseHeaderFilter:  compileResponseHeaderFilterForPlatform(cfg, PlatformOpenAI),
+		forwardHeaders:        compileForwardHeaderPolicy(cfg),
+		responseRedactor:      compileResponseRedactor(cfg, PlatformOpenAI),
 		codexSnapshotThrottle: newAccountWriteThrottle(openAICodexSnapshotPersistMinInterval),
+		ttftStats:             newTTFTStats(cfg),
 	}
 	if rateLimitService != nil {
 		rateLimitService.SetAccountRuntimeBlocker(svc)
@@ -560,6 +575,30 @@ func (s *OpenAIGatewayService) CloseOpenAIWSPool() {
 	}
 }
 
+// SetAccountHealthProbe 注入主动健康探测服务，不健康账号在调度打分中按错误率 100% 处理
+func (s *OpenAIGatewayService) SetAccountHealthProbe(probe *AccountHealthProbeService) {
+	if s == nil {
+		return
+	}
+	s.accountHealth = probe
+}
+
+// SetUpstreamRateLimitTracker 注入上游限流余量跟踪器，余量不足的账号在调度中仅作为最后选择
+func (s *OpenAIGatewayService) SetUpstreamRateLimitTracker(tracker *UpstreamRateLimitTracker) {
+	if s == nil {
+		return
+	}
+	s.upstreamRateLimits = tracker
+}
+
+// SetAccountServerErrorTracker 注入上游 5xx 降权跟踪器，降权期内的账号在调度中仅作为最后选择
+func (s *OpenAIGatewayService) SetAccountServerErrorTracker(tracker *AccountServerErrorTracker) {
+	if s == nil {
+		return
+	}
+	s.serverErrors = tracker
+}
+
 func (s *OpenAIGatewayService) logOpenAIWSModeBootstrap() {
 	if s == nil || s.cfg == nil {
 		return
@@ -1296,7 +1335,23 @@ func resolveOpenAIUpstreamOriginator(c *gin.Context, isOfficialClient bool) stri
 	return "opencode"
 }
 
-// BindStickySession sets session -> account binding with standard TTL.
+// GenerateSessionAffinityHash 按客户端显式会话亲和键（X-Session-Affinity）生成会话哈希，优先于其它会话信号
+func (s *OpenAIGatewayService) GenerateSessionAffinityHash(c *gin.Context, key string) string {
+	currentHash, legacyHash := deriveOpenAISessionHashes(key)
+	attachOpenAILegacySessionHashToGin(c, legacyHash)
+	return currentHash
+}
+
+// openAIStickySessionTTLFromRequest 返回本次请求的粘性会话 TTL：
+// 客户端通过 X-Session-Affinity-TTL 指定时使用该值（handler 层已按配置裁剪），否则使用 fallback。
+func openAIStickySessionTTLFromRequest(ctx context.Context, fallback time.Duration) time.Duration {
+	if ttl, ok := StickySessionTTLFromContext(ctx); ok {
+		return ttl
+	}
+	return fallback
+}
+
+// BindStickySession sets session -> account binding with the request TTL (default: configured sticky TTL).
 func (s *OpenAIGatewayService) BindStickySession(ctx context.Context, groupID *int64, sessionHash string, accountID int64) error {
 	if sessionHash == "" || accountID <= 0 {
 		return nil
@@ -1305,7 +1360,7 @@ func (s *OpenAIGatewayService) BindStickySession(ctx context.Context, groupID *i
 	if s != nil && s.cfg != nil && s.cfg.Gateway.OpenAIWS.StickySessionTTLSeconds > 0 {
 		ttl = time.Duration(s.cfg.Gateway.OpenAIWS.StickySessionTTLSeconds) * time.Second
 	}
-	return s.setStickySessionAccountID(ctx, groupID, sessionHash, accountID, ttl)
+	return s.setStickySessionAccountID(ctx, groupID, sessionHash, accountID, openAIStickySessionTTLFromRequest(ctx, ttl))
 }
 
 // SelectAccount selects an OpenAI account with sticky session support
@@ -1765,7 +1820,7 @@ func (s *OpenAIGatewayService) selectAccountForModelWithExclusions(ctx context.C
 	// 4. 设置粘性会话绑定
 	// Set sticky session binding
 	if sessionHash != "" {
-		_ = s.setStickySessionAccountID(ctx, groupID, sessionHash, selected.ID, openaiStickySessionTTL)
+		_ = s.setStickySessionAccountID(ctx, groupID, sessionHash, selected.ID, openAIStickySessionTTLFromRequest(ctx, openaiStickySessionTTL))
 	}
 
 	return hydrated, nil
@@ -1829,7 +1884,7 @@ func (s *OpenAIGatewayService) tryStickySessionHit(ctx context.Context, groupID
 
 	// 刷新会话 TTL 并返回账号
 	// Refresh session TTL and return account
-	_ = s.refreshStickySessionTTL(ctx, groupID, sessionHash, openaiStickySessionTTL)
+	_ = s.refreshStickySessionTTL(ctx, groupID, sessionHash, openAIStickySessionTTLFromRequest(ctx, openaiStickySessionTTL))
 	return account
 }
 
@@ -2028,7 +2083,7 @@ func (s *OpenAIGatewayService) selectAccountWithLoadAwareness(ctx context.Contex
 							if selectErr != nil {
 								return nil, selectErr
 							}
-							_ = s.refreshStickySessionTTL(ctx, groupID, sessionHash, openaiStickySessionTTL)
+							_ = s.refreshStickySessionTTL(ctx, groupID, sessionHash, openAIStickySessionTTLFromRequest(ctx, openaiStickySessionTTL))
 							return selection, nil
 						}
 
@@ -2161,7 +2216,7 @@ func (s *OpenAIGatewayService) selectAccountWithLoadAwareness(ctx context.Contex
 					return nil, true, selectErr
 				}
 				if sessionHash != "" {
-					_ = s.setStickySessionAccountID(ctx, groupID, sessionHash, fresh.ID, openaiStickySessionTTL)
+					_ = s.setStickySessionAccountID(ctx, groupID, sessionHash, fresh.ID, openAIStickySessionTTLFromRequest(ctx, openaiStickySessionTTL))
 				}
 				return selection, true, nil
 			}
@@ -2195,7 +2250,7 @@ func (s *OpenAIGatewayService) selectAccountWithLoadAwareness(ctx context.Contex
 					return nil, selectErr
 				}
 				if sessionHash != "" {
-					_ = s.setStickySessionAccountID(ctx, groupID, sessionHash, fresh.ID, openaiStickySessionTTL)
+					_ = s.setStickySessionAccountID(ctx, groupID, sessionHash, fresh.ID, openAIStickySessionTTLFromRequest(ctx, openaiStickySessionTTL))
 				}
 				return selection, nil
 			}
@@ -2247,14 +2302,19 @@ func (s *OpenAIGatewayService) selectAccountWithLoadAwareness(ctx context.Contex
 	return nil, ErrNoAvailableAccounts
 }
 
+// listSchedulableAccounts 返回可调度账号；请求携带账号标签选择器时只返回匹配的账号
+// （与 GatewayService 一致，候选池非空但无匹配时返回 ErrNoAccountsMatchingLabels）。
 func (s *OpenAIGatewayService) listSchedulableAccounts(ctx context.Context, groupID *int64, platform string) ([]Account, error) {
 	platform = normalizeOpenAICompatiblePlatform(platform)
-	if s.schedulerSnapshot != nil {
-		accounts, _, err := s.schedulerSnapshot.ListSchedulableAccounts(ctx, groupID, platform, false)
-		return accounts, err
-	}
 	var accounts []Account
 	var err error
+	if s.schedulerSnapshot != nil {
+		accounts, _, err = s.schedulerSnapshot.ListSchedulableAccounts(ctx, groupID, platform, false)
+		if err != nil {
+			return nil, err
+		}
+		return filterAccountsByLabelSelector(ctx, accounts)
+	}
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		accounts, err = s.accountRepo.ListSchedulableByPlatform(ctx, platform)
 	} else if groupID != nil {
@@ -2265,7 +2325,7 @@ func (s *OpenAIGatewayService) listSchedulableAccounts(ctx context.Context, grou
 	if err != nil {
 		return nil, fmt.Errorf("query accounts failed: %w", err)
 	}
-	return accounts, nil
+	return filterAccountsByLabelSelector(ctx, accounts)
 }
 
 func (s *OpenAIGatewayService) tryAcquireAccountSlot(ctx context.Context, accountID int64, maxConcurrency int) (*AcquireResult, error) {
@@ -2337,6 +2397,10 @@ func (s *OpenAIGatewayService) getSchedulableAccount(ctx context.Context, accoun
 	if err != nil || account == nil {
 		return account, err
 	}
+	// 粘性会话 / previous_response_id 命中的账号同样须满足本次请求的标签选择器
+	if !account.MatchesLabelSelector(AccountLabelSelectorFromContext(ctx)) {
+		return nil, ErrNoAccountsMatchingLabels
+	}
 	return account, nil
 }
 
@@ -2520,6 +2584,11 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 	reqModel, reqStream, promptCacheKey := requestView.Model, requestView.Stream, requestView.PromptCacheKey
 	originalModel := reqModel
 
+	// 按模型族解析上游超时分级；首 token 超时由流式处理内的切换账号逻辑执行
+	timeoutWatchdog := beginUpstreamTimeoutTier(ctx, c, s.cfg, account.Platform, originalModel)
+	timeoutWatchdog.disarm(UpstreamTimeoutPhaseFirstToken)
+	defer timeoutWatchdog.Stop()
+
 	if account.Platform == PlatformGrok {
 		_ = promptCacheKey
 		return s.forwardGrokResponses(ctx, c, account, body, originalModel, reqStream, startTime)
@@ -3121,7 +3190,7 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 	for {
 		// Build upstream request
 		upstreamCtx, releaseUpstreamCtx := detachUpstreamContext(ctx)
-		upstreamReq, err := s.buildUpstreamRequest(upstreamCtx, c, account, body, token, reqStream, promptCacheKey, isCodexCLI)
+		upstreamReq, err := s.buildUpstreamRequest(timeoutWatchdog.Bind(upstreamCtx), c, account, body, token, reqStream, promptCacheKey, isCodexCLI)
 		releaseUpstreamCtx()
 		if err != nil {
 			return nil, err
@@ -3135,20 +3204,27 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 
 		// Send request
 		upstreamStart := time.Now()
-		resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+		resp, err := doUpstreamWithTransientRetry(c, s.cfg, account, upstreamReq, func(req *http.Request) (*http.Response, error) {
+			return s.httpUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+		})
 		SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 		if err != nil {
+			if timeoutErr := timeoutWatchdog.Err(); timeoutErr != nil {
+				return nil, s.handleOpenAIUpstreamTimeoutTierError(c, account, timeoutErr)
+			}
 			// Transport-level failure (proxy/DNS/TCP/TLS — no HTTP response). Convert to
 			// a failover so the handler switches to a healthy account, and temporarily
 			// unschedule the account on durable faults (e.g. rejected proxy credentials).
 			return nil, s.handleOpenAIUpstreamTransportError(ctx, c, account, err, false)
 		}
+		timeoutWatchdog.HeadersReceived()
 
 		// Handle error response
 		if resp.StatusCode >= 400 {
 			respBody := s.readUpstreamErrorBody(resp)
 			_ = resp.Body.Close()
 			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+			upstreamRequestID := exposeUpstreamRequestID(c, s.cfg, resp.Header, respBody)
 
 			upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 			upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
@@ -3183,7 +3259,7 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 					AccountID:          account.ID,
 					AccountName:        account.Name,
 					UpstreamStatusCode: resp.StatusCode,
-					UpstreamRequestID:  resp.Header.Get("x-request-id"),
+					UpstreamRequestID:  upstreamRequestID,
 					Kind:               "failover",
 					Message:            upstreamMsg,
 					Detail:             upstreamDetail,
@@ -3193,12 +3269,14 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 				return nil, &UpstreamFailoverError{
 					StatusCode:             resp.StatusCode,
 					ResponseBody:           respBody,
+					UpstreamRequestID:      upstreamRequestID,
 					RetryableOnSameAccount: account.IsPoolMode() && (account.IsPoolModeRetryableStatus(resp.StatusCode) || isOpenAITransientProcessingError(resp.StatusCode, upstreamMsg, respBody)),
 				}
 			}
 			return s.handleErrorResponse(ctx, resp, c, account, body, billingModel)
 		}
 		defer func() { _ = resp.Body.Close() }()
+		exposeUpstreamRequestID(c, s.cfg, resp.Header, nil)
 
 		reasoningEffort := extractOpenAIReasoningEffortFromBody(body, originalModel)
 		// 国产模型默认 effort 补充：此处 reqModel 已被 mapping 重写为 billingModel（见
@@ -3214,8 +3292,9 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 		responseID := ""
 		imageCount := 0
 		var imageOutputSizes []string
+		usageEstimated := false
 		if reqStream {
-			streamResult, err := s.handleStreamingResponse(ctx, resp, c, account, startTime, originalModel, upstreamModel)
+			streamResult, err := s.handleStreamingResponseWithUsageEstimate(ctx, resp, c, account, startTime, originalModel, upstreamModel, newOpenAIStreamUsageEstimate(originalBody))
 			if err != nil {
 				return nil, err
 			}
@@ -3224,6 +3303,7 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 			responseID = strings.TrimSpace(streamResult.responseID)
 			imageCount = streamResult.imageCount
 			imageOutputSizes = streamResult.imageOutputSizes
+			usageEstimated = streamResult.usageEstimated
 		} else {
 			nonStreamResult, err := s.handleNonStreamingResponse(ctx, resp, c, account, originalModel, upstreamModel)
 			if err != nil {
@@ -3248,17 +3328,19 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 		}
 
 		forwardResult := &OpenAIForwardResult{
-			RequestID:       resp.Header.Get("x-request-id"),
-			ResponseID:      responseID,
-			Usage:           *usage,
-			Model:           originalModel,
-			UpstreamModel:   upstreamModel,
-			ServiceTier:     serviceTier,
-			ReasoningEffort: reasoningEffort,
-			Stream:          reqStream,
-			OpenAIWSMode:    false,
-			Duration:        time.Since(startTime),
-			FirstTokenMs:    firstTokenMs,
+			RequestID:         resp.Header.Get("x-request-id"),
+			ResponseID:        responseID,
+			Usage:             *usage,
+			Model:             originalModel,
+			UpstreamModel:     upstreamModel,
+			ServiceTier:       serviceTier,
+			ReasoningEffort:   reasoningEffort,
+			Stream:            reqStream,
+			OpenAIWSMode:      false,
+			Duration:          time.Since(startTime),
+			FirstTokenMs:      firstTokenMs,
+			EstimatedUsage:    usageEstimated,
+			UpstreamRateLimit: s.upstreamRateLimits.Observe(account, resp.Header),
 		}
 		if imageCount > 0 {
 			forwardResult.ImageCount = imageCount
@@ -3425,7 +3507,9 @@ func (s *OpenAIGatewayService) forwardOpenAIPassthrough(
 	}
 
 	upstreamStart := time.Now()
-	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	resp, err := doUpstreamWithTransientRetry(c, s.cfg, account, upstreamReq, func(req *http.Request) (*http.Response, error) {
+		return s.httpUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+	})
 	SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 	if err != nil {
 		// Transport-level failure (proxy/DNS/TCP/TLS — no HTTP response). Convert to
@@ -3434,6 +3518,7 @@ func (s *OpenAIGatewayService) forwardOpenAIPassthrough(
 		return nil, s.handleOpenAIUpstreamTransportError(ctx, c, account, err, true)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	exposeUpstreamRequestID(c, s.cfg, resp.Header, nil)
 
 	if resp.StatusCode >= 400 {
 		// 透传模式默认保持原样代理；但 429/529 属于网关必须兜底的
@@ -3482,17 +3567,18 @@ func (s *OpenAIGatewayService) forwardOpenAIPassthrough(
 	}
 
 	forwardResult := &OpenAIForwardResult{
-		RequestID:       resp.Header.Get("x-request-id"),
-		ResponseID:      responseID,
-		Usage:           *usage,
-		Model:           reqModel,
-		UpstreamModel:   upstreamPassthroughModel,
-		ServiceTier:     serviceTier,
-		ReasoningEffort: reasoningEffort,
-		Stream:          reqStream,
-		OpenAIWSMode:    false,
-		Duration:        time.Since(startTime),
-		FirstTokenMs:    firstTokenMs,
+		RequestID:         resp.Header.Get("x-request-id"),
+		ResponseID:        responseID,
+		Usage:             *usage,
+		Model:             reqModel,
+		UpstreamModel:     upstreamPassthroughModel,
+		ServiceTier:       serviceTier,
+		ReasoningEffort:   reasoningEffort,
+		Stream:            reqStream,
+		OpenAIWSMode:      false,
+		Duration:          time.Since(startTime),
+		FirstTokenMs:      firstTokenMs,
+		UpstreamRateLimit: s.upstreamRateLimits.Observe(account, resp.Header),
 	}
 	if imageCount > 0 {
 		forwardResult.ImageCount = imageCount
@@ -3567,15 +3653,9 @@ func (s *OpenAIGatewayService) buildUpstreamRequestOpenAIPassthrough(
 	// 透传客户端请求头（安全白名单）。
 	allowTimeoutHeaders := s.isOpenAIPassthroughTimeoutHeadersAllowed()
 	if c != nil && c.Request != nil {
-		for key, values := range c.Request.Header {
-			lower := strings.ToLower(strings.TrimSpace(key))
-			if !isOpenAIPassthroughAllowedRequestHeader(lower, allowTimeoutHeaders) {
-				continue
-			}
-			for _, v := range values {
-				req.Header.Add(key, v)
-			}
-		}
+		s.forwardHeaders.copyClientHeaders(req.Header, c.Request.Header, func(lowerKey string) bool {
+			return isOpenAIPassthroughAllowedRequestHeader(lowerKey, allowTimeoutHeaders)
+		})
 	}
 
 	// 覆盖入站鉴权残留，并注入上游认证
@@ -3666,6 +3746,7 @@ func (s *OpenAIGatewayService) handleFailoverErrorResponsePassthrough(
 	requestBody []byte,
 ) error {
 	body := s.readUpstreamErrorBody(resp)
+	upstreamRequestID := exposeUpstreamRequestID(c, s.cfg, resp.Header, body)
 
 	upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(body))
 	upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
@@ -3686,7 +3767,7 @@ func (s *OpenAIGatewayService) handleFailoverErrorResponsePassthrough(
 		AccountID:            account.ID,
 		AccountName:          account.Name,
 		UpstreamStatusCode:   resp.StatusCode,
-		UpstreamRequestID:    resp.Header.Get("x-request-id"),
+		UpstreamRequestID:    upstreamRequestID,
 		Passthrough:          true,
 		Kind:                 "failover",
 		Message:              upstreamMsg,
@@ -3694,9 +3775,10 @@ func (s *OpenAIGatewayService) handleFailoverErrorResponsePassthrough(
 		UpstreamResponseBody: upstreamDetail,
 	})
 	return &UpstreamFailoverError{
-		StatusCode:      resp.StatusCode,
-		ResponseBody:    body,
-		ResponseHeaders: resp.Header.Clone(),
+		StatusCode:        resp.StatusCode,
+		ResponseBody:      body,
+		ResponseHeaders:   resp.Header.Clone(),
+		UpstreamRequestID: upstreamRequestID,
 	}
 }
 
@@ -3709,6 +3791,7 @@ func (s *OpenAIGatewayService) handleErrorResponsePassthrough(
 ) error {
 	MarkResponseCommitted(c)
 	body := s.readUpstreamErrorBody(resp)
+	upstreamRequestID := exposeUpstreamRequestID(c, s.cfg, resp.Header, body)
 
 	// cyber_policy：透传账号本就把原始 body 回给客户端（下方 c.Data），此处仅打标记，
 	// 供 handler 事后写风控/邮件。cyber 是上游网络安全策略拦截，不冷却账号，
@@ -3746,7 +3829,7 @@ func (s *OpenAIGatewayService) handleErrorResponsePassthrough(
 		AccountID:            account.ID,
 		AccountName:          account.Name,
 		UpstreamStatusCode:   resp.StatusCode,
-		UpstreamRequestID:    resp.Header.Get("x-request-id"),
+		UpstreamRequestID:    upstreamRequestID,
 		Passthrough:          true,
 		Kind:                 "http_error",
 		Message:              upstreamMsg,
@@ -3767,6 +3850,14 @@ func (s *OpenAIGatewayService) handleErrorResponsePassthrough(
 	return fmt.Errorf("upstream error: %d message=%s", resp.StatusCode, upstreamMsg)
 }
 
+func isOpenAIAllowedRequestHeader(lowerKey string) bool {
+	return openaiAllowedHeaders[lowerKey]
+}
+
+func isOpenAICCRawAllowedRequestHeader(lowerKey string) bool {
+	return openaiCCRawAllowedHeaders[lowerKey]
+}
+
 func isOpenAIPassthroughAllowedRequestHeader(lowerKey string, allowTimeoutHeaders bool) bool {
 	if lowerKey == "" {
 		return false
@@ -4068,6 +4159,7 @@ func (s *OpenAIGatewayService) handleStreamingResponsePassthrough(
 				firstTokenMs = &ms
 			}
 			s.parseSSEUsageBytes(dataBytes, usage)
+			line = s.responseRedactor.RedactSSE(line)
 		}
 
 		if !clientDisconnected {
@@ -4183,6 +4275,7 @@ func (s *OpenAIGatewayService) handleNonStreamingResponsePassthrough(
 	if originalModel != "" && mappedModel != "" && originalModel != mappedModel {
 		body = s.replaceModelInResponseBody(body, mappedModel, originalModel)
 	}
+	body = s.responseRedactor.RedactBody(body)
 	c.Data(resp.StatusCode, contentType, body)
 	return &openaiNonStreamingResultPassthrough{
 		OpenAIUsage:      usage,
@@ -4346,14 +4439,7 @@ func (s *OpenAIGatewayService) buildUpstreamRequest(ctx context.Context, c *gin.
 	}
 
 	// Whitelist passthrough headers
-	for key, values := range c.Request.Header {
-		lowerKey := strings.ToLower(key)
-		if openaiAllowedHeaders[lowerKey] {
-			for _, v := range values {
-				req.Header.Add(key, v)
-			}
-		}
-	}
+	s.forwardHeaders.copyClientHeaders(req.Header, c.Request.Header, isOpenAIAllowedRequestHeader)
 	if account.Type == AccountTypeOAuth {
 		compatMessagesBridge := isOpenAICompatMessagesBridgeContext(c) || isOpenAICompatMessagesBridgeBody(body)
 		// 清除客户端透传的 session 头，后续用隔离后的值重新设置，防止跨用户会话碰撞。
@@ -4408,6 +4494,7 @@ func (s *OpenAIGatewayService) buildUpstreamRequest(ctx context.Context, c *gin.
 	if req.Header.Get("content-type") == "" {
 		req.Header.Set("content-type", "application/json")
 	}
+	applyUpstreamTraceIDHeader(req, account)
 
 	return req, nil
 }
@@ -4445,6 +4532,7 @@ func (s *OpenAIGatewayService) handleErrorResponse(
 	requestedModel ...string,
 ) (*OpenAIForwardResult, error) {
 	body := s.readUpstreamErrorBody(resp)
+	upstreamRequestID := exposeUpstreamRequestID(c, s.cfg, resp.Header, body)
 
 	// cyber_policy 硬阻断：透传上游原始错误体给客户端（不重包成通用 502），不冷却账号。
 	// 当前请求恒透传（需求1）；标记供 handler 事后写风控/邮件。400 cyber 不可 failover
@@ -4525,7 +4613,7 @@ func (s *OpenAIGatewayService) handleErrorResponse(
 			AccountID:          account.ID,
 			AccountName:        account.Name,
 			UpstreamStatusCode: resp.StatusCode,
-			UpstreamRequestID:  resp.Header.Get("x-request-id"),
+			UpstreamRequestID:  upstreamRequestID,
 			Kind:               "http_error",
 			Message:            upstreamMsg,
 			Detail:             upstreamDetail,
@@ -4561,7 +4649,7 @@ func (s *OpenAIGatewayService) handleErrorResponse(
 		AccountID:          account.ID,
 		AccountName:        account.Name,
 		UpstreamStatusCode: resp.StatusCode,
-		UpstreamRequestID:  resp.Header.Get("x-request-id"),
+		UpstreamRequestID:  upstreamRequestID,
 		Kind:               kind,
 		Message:            upstreamMsg,
 		Detail:             upstreamDetail,
@@ -4570,6 +4658,7 @@ func (s *OpenAIGatewayService) handleErrorResponse(
 		return nil, &UpstreamFailoverError{
 			StatusCode:             resp.StatusCode,
 			ResponseBody:           body,
+			UpstreamRequestID:      upstreamRequestID,
 			RetryableOnSameAccount: account.IsPoolMode() && account.IsPoolModeRetryableStatus(resp.StatusCode),
 		}
 	}
@@ -4633,6 +4722,7 @@ func (s *OpenAIGatewayService) handleCompatErrorResponse(
 	requestedModel ...string,
 ) (*OpenAIForwardResult, error) {
 	body := s.readUpstreamErrorBody(resp)
+	upstreamRequestID := exposeUpstreamRequestID(c, s.cfg, resp.Header, body)
 
 	// cyber_policy：兼容路径（Chat Completions / Anthropic）以各自格式回写错误，
 	// 不原样透传 responses 格式的 cyber body（否则对下游格式不合法）。cyber 是上游网络
@@ -4697,7 +4787,7 @@ func (s *OpenAIGatewayService) handleCompatErrorResponse(
 			AccountID:          account.ID,
 			AccountName:        account.Name,
 			UpstreamStatusCode: resp.StatusCode,
-			UpstreamRequestID:  resp.Header.Get("x-request-id"),
+			UpstreamRequestID:  upstreamRequestID,
 			Kind:               "http_error",
 			Message:            upstreamMsg,
 			Detail:             upstreamDetail,
@@ -4727,7 +4817,7 @@ func (s *OpenAIGatewayService) handleCompatErrorResponse(
 		AccountID:          account.ID,
 		AccountName:        account.Name,
 		UpstreamStatusCode: resp.StatusCode,
-		UpstreamRequestID:  resp.Header.Get("x-request-id"),
+		UpstreamRequestID:  upstreamRequestID,
 		Kind:               kind,
 		Message:            upstreamMsg,
 		Detail:             upstreamDetail,
@@ -4736,6 +4826,7 @@ func (s *OpenAIGatewayService) handleCompatErrorResponse(
 		return nil, &UpstreamFailoverError{
 			StatusCode:             resp.StatusCode,
 			ResponseBody:           body,
+			UpstreamRequestID:      upstreamRequestID,
 			RetryableOnSameAccount: account.IsPoolMode() && account.IsPoolModeRetryableStatus(resp.StatusCode),
 		}
 	}
@@ -4766,6 +4857,8 @@ type openaiStreamingResult struct {
 	responseID       string
 	imageCount       int
 	imageOutputSizes []string
+	// usageEstimated 上游终止事件缺失 usage，usage 为按文本估算的值
+	usageEstimated bool
 }
 
 type openaiNonStreamingResult struct {
@@ -4777,6 +4870,12 @@ type openaiNonStreamingResult struct {
 }
 
 func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, startTime time.Time, originalModel, mappedModel string) (*openaiStreamingResult, error) {
+	return s.handleStreamingResponseWithUsageEstimate(ctx, resp, c, account, startTime, originalModel, mappedModel, nil)
+}
+
+// handleStreamingResponseWithUsageEstimate 与 handleStreamingResponse 相同；
+// usageEstimate 非空时，若上游 response.completed 缺失 usage 则按文本估算并标记。
+func (s *OpenAIGatewayService) handleStreamingResponseWithUsageEstimate(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, startTime time.Time, originalModel, mappedModel string, usageEstimate *openAIStreamUsageEstimate) (*openaiStreamingResult, error) {
 	if s.responseHeaderFilter != nil {
 		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
 	}
@@ -4807,9 +4906,11 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 	}
 
 	usage := &OpenAIUsage{}
+	usageEstimated := false
 	imageCounter := newOpenAIImageOutputCounter()
 	var firstTokenMs *int
 	responseID := ""
+	applyStreamIdleTimeout(s.cfg, resp)
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
@@ -4837,6 +4938,10 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 	if s.cfg != nil && s.cfg.Gateway.StreamKeepaliveInterval > 0 {
 		keepaliveInterval = time.Duration(s.cfg.Gateway.StreamKeepaliveInterval) * time.Second
 	}
+	keepalivePing := streamKeepalivePing(c, openAIStreamKeepalivePing)
+	if keepalivePing == "" {
+		keepaliveInterval = 0
+	}
 	// 下游 keepalive 仅用于防止代理空闲断开
 	var keepaliveTicker *time.Ticker
 	if keepaliveInterval > 0 {
@@ -4852,6 +4957,26 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 	// based on downstream idle time.
 	lastDownstreamWriteAt := time.Now()
 
+	// 首 token 超时：从本账号请求开始计时，首个内容事件写出后失效。
+	// 命中的超时分级配置了首 token 超时时以分级为准，否则使用全局 first_token_timeout_seconds。
+	firstTokenTimeout := time.Duration(0)
+	if s.cfg != nil && s.cfg.Gateway.FirstTokenTimeoutSeconds > 0 {
+		firstTokenTimeout = time.Duration(s.cfg.Gateway.FirstTokenTimeoutSeconds) * time.Second
+	}
+	if watchdog := upstreamTimeoutWatchdogFromGin(c); watchdog != nil && watchdog.tier.FirstTokenTimeout > 0 {
+		firstTokenTimeout = watchdog.tier.FirstTokenTimeout
+	}
+	var firstTokenCh <-chan time.Time
+	if firstTokenTimeout > 0 {
+		remaining := firstTokenTimeout - time.Since(startTime)
+		if remaining < 0 {
+			remaining = 0
+		}
+		firstTokenTimer := time.NewTimer(remaining)
+		defer firstTokenTimer.Stop()
+		firstTokenCh = firstTokenTimer.C
+	}
+
 	// 仅发送一次错误事件，避免多次写入导致协议混乱。
 	// 注意：OpenAI `/v1/responses` streaming 事件必须符合 OpenAI Responses schema；
 	// 否则下游 SDK（例如 OpenCode）会因为类型校验失败而报错。
@@ -4896,6 +5021,7 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 			responseID:       responseID,
 			imageCount:       imageCounter.Count(),
 			imageOutputSizes: imageCounter.Sizes(),
+			usageEstimated:   usageEstimated,
 		}
 	}
 	finalizeStream := func() (*openaiStreamingResult, error) {
@@ -4910,6 +5036,17 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 					"OpenAI stream ended before a terminal event",
 				)
 			}
+			// 已向客户端输出后上游 EOF 且缺失终止事件：按已转发内容估算用量并正常计费，避免截断流漏记。
+			if applyOpenAITruncatedStreamUsageEstimate(usageEstimate, streamOutputAccumulator.BuildOutput(), usage) {
+				usageEstimated = true
+				logger.LegacyPrintf("service.openai_gateway", "Stream ended without terminal event, estimated: account=%d model=%s input=%d output=%d", account.ID, originalModel, usage.InputTokens, usage.OutputTokens)
+				if !clientDisconnected {
+					if err := flushBuffered(); err != nil {
+						clientDisconnected = true
+					}
+				}
+				return resultWithUsage(), nil
+			}
 			return resultWithUsage(), fmt.Errorf("stream usage incomplete: missing terminal event")
 		}
 		if sawFailedEvent {
@@ -4938,6 +5075,27 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 		if sawFailedEvent {
 			return resultWithUsage(), fmt.Errorf("upstream response failed: %s", failedMessage), true
 		}
+		// 超时分级触发导致上游读取被取消：尚未输出时切换账号，已输出时发送错误事件，避免客户端静默挂起。
+		if timeoutErr := upstreamTimeoutErrFromGin(c); timeoutErr != nil && !clientDisconnected {
+			logger.LegacyPrintf("service.openai_gateway", "Stream upstream timeout: account=%d model=%s err=%v", account.ID, originalModel, timeoutErr)
+			if !openAIStreamClientOutputStarted(c, clientOutputStarted) {
+				return resultWithUsage(), s.newOpenAIStreamFailoverError(c, account, false, upstreamRequestID, nil, "OpenAI stream "+timeoutErr.Error()), true
+			}
+			sendErrorEvent("upstream_timeout")
+			return resultWithUsage(), timeoutErr, true
+		}
+		// 上游连接未断但长时间不发送数据：关闭上游，按流超时处理
+		if idleErr, ok := asStreamIdleTimeout(scanErr); ok && !clientDisconnected {
+			logger.LegacyPrintf("service.openai_gateway", "Stream idle timeout: account=%d model=%s idle=%s", account.ID, originalModel, idleErr.Timeout)
+			if s.rateLimitService != nil {
+				s.rateLimitService.HandleStreamTimeout(ctx, account, originalModel)
+			}
+			if !openAIStreamClientOutputStarted(c, clientOutputStarted) {
+				return resultWithUsage(), s.newOpenAIStreamFailoverError(c, account, false, upstreamRequestID, nil, "OpenAI "+idleErr.Error()), true
+			}
+			sendErrorEvent("stream_timeout")
+			return resultWithUsage(), idleErr, true
+		}
 		// 客户端断开/取消请求时，上游读取往往会返回 context canceled。
 		// /v1/responses 的 SSE 事件必须符合 OpenAI 协议；这里不注入自定义 error event，避免下游 SDK 解析失败。
 		if errors.Is(scanErr, context.Canceled) || errors.Is(scanErr, context.DeadlineExceeded) {
@@ -5029,11 +5187,24 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 				data = string(sanitizedData)
 				line = "data: " + data
 			}
+			// 上游终止事件缺失 usage：按请求与输出文本估算，避免按 0 token 记账。
+			if applyOpenAIStreamUsageEstimate(usageEstimate, eventType, dataBytes, usage) {
+				usageEstimated = true
+				logger.LegacyPrintf("service.openai_gateway", "Stream completed without usage, estimated: account=%d model=%s input=%d output=%d", account.ID, originalModel, usage.InputTokens, usage.OutputTokens)
+				if usageEstimate.includeUsage {
+					if patchedData, patched := patchOpenAIStreamTerminalUsage(dataBytes, *usage); patched {
+						dataBytes = patchedData
+						data = string(patchedData)
+						line = "data: " + data
+					}
+				}
+			}
 			// Replace model in response if needed.
 			// Fast path: most events do not contain model field values.
 			if needModelReplace && mappedModel != "" && strings.Contains(line, mappedModel) {
 				line = s.replaceModelInSSELine(line, mappedModel, originalModel)
 			}
+			line = s.responseRedactor.RedactSSE(line)
 			startsClientOutput := forceFlushFailedEvent || openAIStreamDataStartsClientOutput(data, eventType)
 
 			// 写入客户端（客户端断开后继续 drain 上游）
@@ -5065,7 +5236,9 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 				ms := int(time.Since(startTime).Milliseconds())
 				firstTokenMs = &ms
 			}
-			s.parseSSEUsageBytes(dataBytes, usage)
+			if !usageEstimated {
+				s.parseSSEUsageBytes(dataBytes, usage)
+			}
 			return
 		}
 
@@ -5090,7 +5263,7 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 	}
 
 	// 无超时/无 keepalive 的常见路径走同步扫描，减少 goroutine 与 channel 开销。
-	if streamInterval <= 0 && keepaliveInterval <= 0 {
+	if streamInterval <= 0 && keepaliveInterval <= 0 && firstTokenTimeout <= 0 {
 		defer putSSEScannerBuf64K(scanBuf)
 		for scanner.Scan() {
 			processSSELine(scanner.Text(), true)
@@ -5149,6 +5322,19 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 			if streamFailoverErr != nil {
 				return resultWithUsage(), streamFailoverErr
 			}
+			if firstTokenMs != nil {
+				firstTokenCh = nil
+			}
+
+		case <-firstTokenCh:
+			firstTokenCh = nil
+			if firstTokenMs != nil || openAIStreamClientOutputStarted(c, clientOutputStarted) {
+				continue
+			}
+			logger.LegacyPrintf("service.openai_gateway", "Stream first token timeout: account=%d model=%s timeout=%s", account.ID, originalModel, firstTokenTimeout)
+			// 中止上游读取；客户端尚未收到任何数据，交由上层切换账号重试。
+			_ = resp.Body.Close()
+			return resultWithUsage(), s.newOpenAIStreamFailoverError(c, account, false, upstreamRequestID, nil, "OpenAI stream first token timeout")
 
 		case <-intervalCh:
 			lastRead := time.Unix(0, atomic.LoadInt64(&lastReadAt))
@@ -5173,7 +5359,11 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 			if time.Since(lastDownstreamWriteAt) < keepaliveInterval {
 				continue
 			}
-			if _, err := bufferedWriter.WriteString(":\n\n"); err != nil {
+			// 等待首 token 期间不发 keepalive，否则写出响应头后无法再切换账号。
+			if firstTokenCh != nil {
+				continue
+			}
+			if _, err := bufferedWriter.WriteString(keepalivePing); err != nil {
 				clientDisconnected = true
 				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
 				continue
@@ -5534,6 +5724,7 @@ func (s *OpenAIGatewayService) handleNonStreamingResponse(ctx context.Context, r
 		}
 	}
 
+	body = s.responseRedactor.RedactBody(body)
 	c.Data(resp.StatusCode, contentType, body)
 
 	return &openaiNonStreamingResult{
@@ -6099,6 +6290,11 @@ type OpenAIRecordUsageInput struct {
 	QuotaPlatform      string // user×platform quota platform resolved by the handler before async billing.
 	// CyberBlocked 为 true 时把该用量行标记为 cyber（request_type=cyber），计费逻辑不变。
 	CyberBlocked bool
+	// RequestBytes / ResponseBytes 为读取的原始请求体字节数与写给客户端的响应字节数（流式累加），0 表示未统计。
+	RequestBytes  int64
+	ResponseBytes int64
+	// FallbackFromModel 分组模型降级前客户端请求的模型；非空时记为 requested_model，计费仍按实际服务模型
+	FallbackFromModel string
 	ChannelUsageFields
 }
 
@@ -6163,8 +6359,31 @@ func (s *OpenAIGatewayService) RecordCyberPolicyUsageLog(ctx context.Context, in
 	}
 }
 
-// RecordUsage records usage and deducts balance
+// SetUsageRecordRetryService 注入用量记录重试队列，RecordUsage 失败的输入入队后由后台重放
+func (s *OpenAIGatewayService) SetUsageRecordRetryService(retry *UsageRecordRetryService) {
+	if s == nil {
+		return
+	}
+	s.usageRecordRetry = retry
+}
+
+// RecordUsage records usage and deducts balance; failed writes are queued for delayed retry.
 func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRecordUsageInput) error {
+	var snapshot *OpenAIRecordUsageInput
+	if s.usageRecordRetry.Enabled() && input != nil && input.Result != nil {
+		// Billing mutates Result (image size resolution), so queue a pre-call snapshot.
+		cp, result := *input, *input.Result
+		cp.Result = &result
+		snapshot = &cp
+	}
+	err := s.recordUsage(ctx, input)
+	if err != nil && snapshot != nil {
+		s.usageRecordRetry.enqueue(ctx, UsageRecordRetryKindOpenAI, snapshot, err)
+	}
+	return err
+}
+
+func (s *OpenAIGatewayService) recordUsage(ctx context.Context, input *OpenAIRecordUsageInput) error {
 	if input == nil {
 		return errors.New("openai usage input is nil")
 	}
@@ -6211,6 +6430,9 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		}
 		multiplier = resolver.Resolve(ctx, user.ID, *apiKey.GroupID, apiKey.Group.RateMultiplier)
 	}
+	if result.EstimatedUsage {
+		multiplier *= s.estimatedUsageRateMultiplier()
+	}
 	imageMultiplier := resolveImageRateMultiplier(apiKey, multiplier)
 
 	var cost *CostBreakdown
@@ -6251,7 +6473,7 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 			zap.Int64("api_key_id", apiKey.ID),
 			zap.Int64("account_id", account.ID),
 		).Warn("openai_usage.pricing_missing_record_zero_cost", zap.Error(err))
-		cost = &CostBreakdown{BillingMode: string(BillingModeToken)}
+		cost = &CostBreakdown{BillingMode: string(BillingModeToken), PricingUnavailable: true}
 	}
 
 	// Determine billing type
@@ -6276,6 +6498,9 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	if input.OriginalModel != "" {
 		requestedModel = input.OriginalModel
 	}
+	if input.FallbackFromModel != "" {
+		requestedModel = input.FallbackFromModel
+	}
 
 	usageLog := &UsageLog{
 		UserID:              user.ID,
@@ -6300,6 +6525,10 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		ImageOutputSize:     optionalTrimmedStringPtr(result.ImageOutputSize),
 		ImageSizeSource:     optionalTrimmedStringPtr(result.ImageSizeSource),
 		ImageSizeBreakdown:  result.ImageSizeBreakdown,
+		UsageEstimated:      result.EstimatedUsage,
+		BillingUnverified:   IsBillingUnverified(ctx),
+		RequestBytes:        optionalInt64Ptr(input.RequestBytes),
+		ResponseBytes:       optionalInt64Ptr(input.ResponseBytes),
 	}
 	if cost != nil {
 		usageLog.InputCost = cost.InputCost
@@ -6309,7 +6538,9 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		usageLog.CacheReadCost = cost.CacheReadCost
 		usageLog.TotalCost = cost.TotalCost
 		usageLog.ActualCost = cost.ActualCost
+		usageLog.PricingUnavailable = cost.PricingUnavailable
 	}
+	applyRequestCostEstimate(ctx, usageLog)
 	if result.ImageCount > 0 && (cost == nil || cost.BillingMode != string(BillingModeToken)) {
 		usageLog.RateMultiplier = imageMultiplier
 	} else {
@@ -6364,6 +6595,8 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		)
 	}
 
+	s.ttftStats.RecordUsageLog(usageLog)
+
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.openai_gateway")
 		logger.LegacyPrintf("service.openai_gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
@@ -7606,3 +7839,11 @@ func normalizeOpenAIReasoningEffort(raw string) string {
 		return ""
 	}
 }
+
+// TTFTStats 返回首 token 延迟统计（未启用时为 nil）
+func (s *OpenAIGatewayService) TTFTStats() *TTFTStats {
+	if s == nil {
+		return nil
+	}
+	return s.ttftStats
+}
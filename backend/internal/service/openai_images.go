@@ -745,14 +745,9 @@ func (s *OpenAIGatewayService) buildOpenAIImagesRequest(
 	}
 	req = req.WithContext(WithHTTPUpstreamProfile(req.Context(), HTTPUpstreamProfileOpenAI))
 	req.Header.Set("Authorization", "Bearer "+token)
-	for key, values := range c.Request.Header {
-		if !openaiPassthroughAllowedHeaders[strings.ToLower(key)] {
-			continue
-		}
-		for _, value := range values {
-			req.Header.Add(key, value)
-		}
-	}
+	s.forwardHeaders.copyClientHeaders(req.Header, c.Request.Header, func(lowerKey string) bool {
+		return openaiPassthroughAllowedHeaders[lowerKey]
+	})
 	customUA := account.GetOpenAIUserAgent()
 	if customUA != "" {
 		req.Header.Set("User-Agent", customUA)
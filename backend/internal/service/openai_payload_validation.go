@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+// OpenAIPayloadValidationError 请求 input 中内嵌图片/文件未通过预校验
+type OpenAIPayloadValidationError struct {
+	// Status: 413（超限）或 400（类型不符）
+	Status int
+	// ItemIndex: 触发错误的 input 项序号
+	ItemIndex int
+	Message   string
+}
+
+func (e *OpenAIPayloadValidationError) Error() string {
+	return e.Message
+}
+
+// inlinePayloadMagics 常见格式的文件头
+var inlinePayloadMagics = []struct {
+	mediaType string
+	match     func(head []byte) bool
+}{
+	{"image/png", func(h []byte) bool { return bytes.HasPrefix(h, []byte("\x89PNG\r\n\x1a\n")) }},
+	{"image/jpeg", func(h []byte) bool { return bytes.HasPrefix(h, []byte{0xFF, 0xD8, 0xFF}) }},
+	{"image/gif", func(h []byte) bool {
+		return bytes.HasPrefix(h, []byte("GIF87a")) || bytes.HasPrefix(h, []byte("GIF89a"))
+	}},
+	{"image/webp", func(h []byte) bool {
+		return len(h) >= 12 && bytes.Equal(h[:4], []byte("RIFF")) && bytes.Equal(h[8:12], []byte("WEBP"))
+	}},
+	{"application/pdf", func(h []byte) bool { return bytes.HasPrefix(h, []byte("%PDF-")) }},
+}
+
+// inlinePayloadSniffChars 嗅探文件头需要解码的 base64 字符数（16 字符 = 12 字节，覆盖 WebP 头）
+const inlinePayloadSniffChars = 16
+
+// inlinePayload input 中的一段 base64 图片/文件
+type inlinePayload struct {
+	mediaType string
+	data      string
+}
+
+// ValidateOpenAIInputPayload 遍历 Responses 请求的 input 项，校验内嵌 base64 图片/文件：
+// 单个或总量解码后超过配置上限返回 413；声明的常见 media type 与文件头不符返回 400。
+// 基于 gjson 只读遍历，不做完整反序列化；未启用或无内嵌内容时返回 nil。
+func ValidateOpenAIInputPayload(body []byte, cfg config.GatewayPayloadValidationConfig) *OpenAIPayloadValidationError {
+	if !cfg.Enabled || len(body) == 0 {
+		return nil
+	}
+	if !bytes.Contains(body, []byte("base64")) && !bytes.Contains(body, []byte("file_data")) {
+		return nil
+	}
+	input := gjson.GetBytes(body, "input")
+	if !input.IsArray() {
+		return nil
+	}
+
+	var (
+		total     int64
+		itemIndex int
+		failure   *OpenAIPayloadValidationError
+	)
+	input.ForEach(func(_, item gjson.Result) bool {
+		index := itemIndex
+		itemIndex++
+		for _, part := range collectInlinePayloads(item) {
+			size := base64DecodedLen(part.data)
+			if size > cfg.MaxPartBytes {
+				failure = &OpenAIPayloadValidationError{
+					Status:    http.StatusRequestEntityTooLarge,
+					ItemIndex: index,
+					Message:   fmt.Sprintf("input[%d]: inline image/file is %d bytes after decoding, exceeding the %d byte limit", index, size, cfg.MaxPartBytes),
+				}
+				return false
+			}
+			total += size
+			if total > cfg.MaxTotalBytes {
+				failure = &OpenAIPayloadValidationError{
+					Status:    http.StatusRequestEntityTooLarge,
+					ItemIndex: index,
+					Message:   fmt.Sprintf("input[%d]: inline images/files exceed the %d byte total limit for a single request", index, cfg.MaxTotalBytes),
+				}
+				return false
+			}
+			if cfg.VerifyMediaType {
+				if detected, matches := inlinePayloadMediaTypeMatches(part); !matches {
+					failure = &OpenAIPayloadValidationError{
+						Status:    http.StatusBadRequest,
+						ItemIndex: index,
+						Message:   fmt.Sprintf("input[%d]: declared media type %s does not match the content (%s)", index, part.mediaType, detected),
+					}
+					return false
+				}
+			}
+		}
+		return true
+	})
+	return failure
+}
+
+// collectInlinePayloads 提取单个 input 项（或其 content 数组）中的 base64 图片/文件
+func collectInlinePayloads(item gjson.Result) []inlinePayload {
+	var parts []gjson.Result
+	if content := item.Get("content"); content.IsArray() {
+		parts = content.Array()
+	} else if item.IsObject() {
+		parts = []gjson.Result{item}
+	}
+
+	var out []inlinePayload
+	for _, part := range parts {
+		var raw string
+		switch strings.TrimSpace(part.Get("type").String()) {
+		case "input_image":
+			imageURL := part.Get("image_url")
+			if imageURL.IsObject() {
+				imageURL = imageURL.Get("url")
+			}
+			raw = imageURL.String()
+		case "input_file":
+			raw = part.Get("file_data").String()
+		default:
+			continue
+		}
+		if payload, ok := parseInlinePayload(raw); ok {
+			out = append(out, payload)
+		}
+	}
+	return out
+}
+
+// parseInlinePayload 解析 data URI（data:<media type>;base64,<data>）或裸 base64 文件内容
+func parseInlinePayload(raw string) (inlinePayload, bool) {
+	if raw == "" {
+		return inlinePayload{}, false
+	}
+	if !strings.HasPrefix(raw, "data:") {
+		if strings.Contains(raw, "://") {
+			return inlinePayload{}, false
+		}
+		return inlinePayload{data: raw}, true
+	}
+	meta, data, ok := strings.Cut(strings.TrimPrefix(raw, "data:"), ",")
+	if !ok || !strings.HasSuffix(meta, ";base64") {
+		return inlinePayload{}, false
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.TrimSuffix(meta, ";base64")))
+	if mediaType == "image/jpg" {
+		mediaType = "image/jpeg"
+	}
+	return inlinePayload{mediaType: mediaType, data: data}, true
+}
+
+// base64DecodedLen 按 base64 长度估算解码后的字节数，无需实际解码
+func base64DecodedLen(data string) int64 {
+	n := len(strings.TrimRight(data, "="))
+	return int64(n) * 3 / 4
+}
+
+// inlinePayloadMediaTypeMatches 声明为常见格式时按文件头核对，返回 (实际识别结果, 是否一致)；
+// 未声明、非常见格式或无法嗅探时视为一致。
+func inlinePayloadMediaTypeMatches(part inlinePayload) (string, bool) {
+	declaredKnown := false
+	for _, magic := range inlinePayloadMagics {
+		if magic.mediaType == part.mediaType {
+			declaredKnown = true
+			break
+		}
+	}
+	if !declaredKnown || len(part.data) < inlinePayloadSniffChars {
+		return "", true
+	}
+	head, err := base64.StdEncoding.DecodeString(part.data[:inlinePayloadSniffChars])
+	if err != nil {
+		return "invalid base64", false
+	}
+	for _, magic := range inlinePayloadMagics {
+		if magic.match(head) {
+			return magic.mediaType, magic.mediaType == part.mediaType
+		}
+	}
+	return "unrecognized format", false
+}
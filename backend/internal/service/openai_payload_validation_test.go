@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+var testPNGHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func testPayloadValidationConfig() config.GatewayPayloadValidationConfig {
+	return config.GatewayPayloadValidationConfig{
+		Enabled:         true,
+		MaxPartBytes:    1024,
+		MaxTotalBytes:   2048,
+		VerifyMediaType: true,
+	}
+}
+
+func testInlineImage(mediaType string, head []byte, size int) string {
+	data := make([]byte, size)
+	copy(data, head)
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func testResponsesBodyWithImages(images ...string) []byte {
+	items := []string{`{"role":"user","content":[{"type":"input_text","text":"hi"}]}`}
+	for _, image := range images {
+		items = append(items, fmt.Sprintf(`{"role":"user","content":[{"type":"input_image","image_url":%q}]}`, image))
+	}
+	return []byte(`{"model":"gpt-5","input":[` + strings.Join(items, ",") + `]}`)
+}
+
+func TestValidateOpenAIInputPayload_AcceptsValidImages(t *testing.T) {
+	body := testResponsesBodyWithImages(
+		testInlineImage("image/png", testPNGHeader, 512),
+		testInlineImage("image/jpg", []byte{0xFF, 0xD8, 0xFF, 0xE0}, 512),
+		"https://example.com/cat.png",
+	)
+	require.Nil(t, ValidateOpenAIInputPayload(body, testPayloadValidationConfig()))
+}
+
+func TestValidateOpenAIInputPayload_OversizeSingleImage(t *testing.T) {
+	body := testResponsesBodyWithImages(testInlineImage("image/png", testPNGHeader, 2000))
+
+	err := ValidateOpenAIInputPayload(body, testPayloadValidationConfig())
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusRequestEntityTooLarge, err.Status)
+	require.Equal(t, 1, err.ItemIndex)
+	require.Contains(t, err.Message, "input[1]")
+}
+
+func TestValidateOpenAIInputPayload_OversizeTotal(t *testing.T) {
+	image := testInlineImage("image/png", testPNGHeader, 900)
+	body := testResponsesBodyWithImages(image, image, image)
+
+	err := ValidateOpenAIInputPayload(body, testPayloadValidationConfig())
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusRequestEntityTooLarge, err.Status)
+	require.Equal(t, 3, err.ItemIndex)
+	require.Contains(t, err.Message, "total limit")
+}
+
+func TestValidateOpenAIInputPayload_MismatchedDeclaredType(t *testing.T) {
+	body := testResponsesBodyWithImages(testInlineImage("image/jpeg", testPNGHeader, 64))
+
+	err := ValidateOpenAIInputPayload(body, testPayloadValidationConfig())
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusBadRequest, err.Status)
+	require.Equal(t, 1, err.ItemIndex)
+	require.Contains(t, err.Message, "image/jpeg")
+	require.Contains(t, err.Message, "image/png")
+
+	cfg := testPayloadValidationConfig()
+	cfg.VerifyMediaType = false
+	require.Nil(t, ValidateOpenAIInputPayload(body, cfg))
+}
+
+func TestValidateOpenAIInputPayload_InputFileAndDisabled(t *testing.T) {
+	pdf := base64.StdEncoding.EncodeToString(append([]byte("%PDF-1.7"), make([]byte, 1200)...))
+	body := []byte(`{"model":"gpt-5","input":[{"type":"input_file","filename":"a.pdf","file_data":"` + pdf + `"}]}`)
+
+	err := ValidateOpenAIInputPayload(body, testPayloadValidationConfig())
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusRequestEntityTooLarge, err.Status)
+	require.Equal(t, 0, err.ItemIndex)
+
+	cfg := testPayloadValidationConfig()
+	cfg.Enabled = false
+	require.Nil(t, ValidateOpenAIInputPayload(body, cfg))
+}
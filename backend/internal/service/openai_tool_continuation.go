@@ -50,6 +50,71 @@ func isCodexToolCallOutputItemType(typ string) bool {
 	}
 }
 
+// hasLegacyToolCallContext 识别旧版 SDK 产生的工具调用上下文写法：
+// 1) 回填上一轮响应时把调用嵌套在 output[] 中（output[].type == "function_call"）；
+// 2) Chat Completions 风格的 tool_calls 数组（id 即 call_id）。
+// 工具输出项的 output 为字符串，不会被误判。
+func hasLegacyToolCallContext(itemMap map[string]any) bool {
+	if output, ok := itemMap["output"].([]any); ok {
+		for _, raw := range output {
+			outputItem, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			outputType, _ := outputItem["type"].(string)
+			callID, _ := outputItem["call_id"].(string)
+			if isCodexToolCallContextItemType(outputType) && strings.TrimSpace(callID) != "" {
+				return true
+			}
+		}
+	}
+	if toolCalls, ok := itemMap["tool_calls"].([]any); ok {
+		for _, raw := range toolCalls {
+			toolCall, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			if hasNonEmptyString(toolCall["id"]) || hasNonEmptyString(toolCall["call_id"]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasLegacyToolCallContextResult 为 raw JSON 扫描路径提供与 hasLegacyToolCallContext 一致的判定。
+func hasLegacyToolCallContextResult(item gjson.Result) bool {
+	found := false
+	if output := item.Get("output"); output.IsArray() {
+		output.ForEach(func(_, outputItem gjson.Result) bool {
+			if outputItem.IsObject() &&
+				isCodexToolCallContextItemType(outputItem.Get("type").String()) &&
+				strings.TrimSpace(outputItem.Get("call_id").String()) != "" {
+				found = true
+			}
+			return !found
+		})
+	}
+	if found {
+		return true
+	}
+	if toolCalls := item.Get("tool_calls"); toolCalls.IsArray() {
+		toolCalls.ForEach(func(_, toolCall gjson.Result) bool {
+			if !toolCall.IsObject() {
+				return true
+			}
+			id := toolCall.Get("id")
+			callID := toolCall.Get("call_id")
+			if (id.Type == gjson.String && strings.TrimSpace(id.String()) != "") ||
+				(callID.Type == gjson.String && strings.TrimSpace(callID.String()) != "") {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
 // NeedsToolContinuation 判定请求是否需要工具调用续链处理。
 // 满足以下任一信号即视为续链：previous_response_id、input 内包含工具输出/item_reference、
 // 或显式声明 tools/tool_choice。
@@ -134,6 +199,8 @@ func AnalyzeToolContinuationSignals(reqBody map[string]any) ToolContinuationSign
 				referenceIDs = make(map[string]struct{})
 			}
 			referenceIDs[idValue] = struct{}{}
+		case hasLegacyToolCallContext(itemMap):
+			signals.HasToolCallContext = true
 		}
 	}
 
@@ -198,6 +265,8 @@ func ValidateFunctionCallOutputContextBytes(body []byte) FunctionCallOutputValid
 				referenceIDs = make(map[string]struct{})
 			}
 			referenceIDs[idValue] = struct{}{}
+		case hasLegacyToolCallContextResult(item):
+			result.HasToolCallContext = true
 		}
 		return !result.HasFunctionCallOutput || !result.HasToolCallContext
 	})
@@ -244,6 +313,8 @@ func ValidateFunctionCallOutputContext(reqBody map[string]any) FunctionCallOutpu
 			if strings.TrimSpace(callID) != "" {
 				result.HasToolCallContext = true
 			}
+		case hasLegacyToolCallContext(itemMap):
+			result.HasToolCallContext = true
 		}
 		if result.HasFunctionCallOutput && result.HasToolCallContext {
 			return result
@@ -302,7 +373,7 @@ func HasFunctionCallOutput(reqBody map[string]any) bool {
 }
 
 // HasToolCallContext 判断 input 是否包含带 call_id 的工具调用上下文，
-// 用于判断工具输出是否具备可关联的上下文。
+// 用于判断工具输出是否具备可关联的上下文。兼容旧版 SDK 的 output[] 嵌套与 tool_calls 写法。
 func HasToolCallContext(reqBody map[string]any) bool {
 	return AnalyzeToolContinuationSignals(reqBody).HasToolCallContext
 }
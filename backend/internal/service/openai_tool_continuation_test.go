@@ -76,6 +76,105 @@ func TestHasToolCallContext(t *testing.T) {
 	}))
 }
 
+func TestHasToolCallContext_LegacyShapes(t *testing.T) {
+	// 旧版 SDK 的工具调用上下文写法应被接受，真正孤立的工具输出仍需被拒绝。
+	output := map[string]any{"type": "function_call_output", "call_id": "call_1", "output": "ok"}
+	cases := []struct {
+		name  string
+		input []any
+		want  bool
+	}{
+		{
+			name: "echoed_response_output_function_call",
+			input: []any{
+				map[string]any{"type": "response", "output": []any{
+					map[string]any{"type": "message", "role": "assistant"},
+					map[string]any{"type": "function_call", "call_id": "call_1", "name": "get_weather"},
+				}},
+				output,
+			},
+			want: true,
+		},
+		{
+			name: "untyped_item_output_function_call",
+			input: []any{
+				map[string]any{"output": []any{map[string]any{"type": "function_call", "call_id": "call_1"}}},
+				output,
+			},
+			want: true,
+		},
+		{
+			name: "chat_style_tool_calls",
+			input: []any{
+				map[string]any{"role": "assistant", "content": nil, "tool_calls": []any{
+					map[string]any{"id": "call_1", "type": "function", "function": map[string]any{"name": "get_weather", "arguments": "{}"}},
+				}},
+				output,
+			},
+			want: true,
+		},
+		{
+			name: "message_tool_calls_with_call_id",
+			input: []any{
+				map[string]any{"type": "message", "role": "assistant", "tool_calls": []any{
+					map[string]any{"call_id": "call_1", "type": "function"},
+				}},
+				output,
+			},
+			want: true,
+		},
+		{
+			name:  "orphaned_output",
+			input: []any{map[string]any{"type": "message", "role": "user", "content": "hi"}, output},
+			want:  false,
+		},
+		{
+			name: "legacy_output_without_call_id",
+			input: []any{
+				map[string]any{"type": "response", "output": []any{map[string]any{"type": "function_call", "name": "get_weather"}}},
+				output,
+			},
+			want: false,
+		},
+		{
+			name: "legacy_output_non_call_items",
+			input: []any{
+				map[string]any{"type": "response", "output": []any{map[string]any{"type": "message", "call_id": "call_1"}}},
+				output,
+			},
+			want: false,
+		},
+		{
+			name: "tool_calls_without_id",
+			input: []any{
+				map[string]any{"role": "assistant", "tool_calls": []any{map[string]any{"type": "function", "id": "  "}}},
+				output,
+			},
+			want: false,
+		},
+		{
+			name:  "tool_calls_empty",
+			input: []any{map[string]any{"role": "assistant", "tool_calls": []any{}}, output},
+			want:  false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]any{"input": tt.input}
+			require.Equal(t, tt.want, HasToolCallContext(body))
+			require.True(t, HasFunctionCallOutput(body))
+
+			validation := ValidateFunctionCallOutputContext(body)
+			require.Equal(t, tt.want, validation.HasToolCallContext)
+
+			bodyBytes, err := json.Marshal(body)
+			require.NoError(t, err)
+			require.Equal(t, validation, ValidateFunctionCallOutputContextBytes(bodyBytes))
+		})
+	}
+}
+
 func TestFunctionCallOutputCallIDs(t *testing.T) {
 	// 仅提取工具输出的非空 call_id，去重后返回。
 	require.Empty(t, FunctionCallOutputCallIDs(nil))
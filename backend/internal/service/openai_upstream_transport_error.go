@@ -134,6 +134,18 @@ func (s *OpenAIGatewayService) handleOpenAIUpstreamTransportError(ctx context.Co
 	}
 }
 
+// openAIUpstreamTimeoutFailoverBody is the client-visible body used when a
+// gateway.timeout_tiers deadline fires before upstream response headers arrive.
+var openAIUpstreamTimeoutFailoverBody = []byte(`{"error":{"type":"upstream_error","message":"Upstream request timed out"}}`)
+
+// handleOpenAIUpstreamTimeoutTierError converts a timeout-tier deadline that
+// fired before response headers (connect / total phase) into a failover. The
+// account is not unscheduled: the tier reflects the request's budget, not an
+// account fault.
+func (s *OpenAIGatewayService) handleOpenAIUpstreamTimeoutTierError(c *gin.Context, account *Account, timeoutErr error) error {
+	return upstreamTimeoutTierFailover(c, account, "", timeoutErr, openAIUpstreamTimeoutFailoverBody)
+}
+
 // tempUnscheduleOpenAITransportError marks an account temporarily unschedulable
 // after a durable transport failure, both persistently (DB, survives restart)
 // and in-memory (immediate scheduler effect before the DB/account cache propagates).
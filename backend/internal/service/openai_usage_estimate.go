@@ -0,0 +1,137 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// openAIStreamUsageEstimate 保存流式 Responses 请求在上游缺失 usage 时的估算上下文。
+// 部分上游账号/模型的流式响应不带最终 usage，若不估算会按 0 token 记账。
+type openAIStreamUsageEstimate struct {
+	// inputTokens 按请求体文本估算的输入 token（与 count_tokens 回退使用同一估算器）
+	inputTokens int
+	// includeUsage 客户端是否请求了 stream_options.include_usage；
+	// 仅在为 true 时才把估算 usage 写回下游的终止事件。
+	includeUsage bool
+}
+
+// newOpenAIStreamUsageEstimate 从客户端原始请求体构造估算上下文。
+func newOpenAIStreamUsageEstimate(body []byte) *openAIStreamUsageEstimate {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return nil
+	}
+	return &openAIStreamUsageEstimate{
+		inputTokens:  estimateOpenAIResponsesInputTokens(body),
+		includeUsage: gjson.GetBytes(body, "stream_options.include_usage").Bool(),
+	}
+}
+
+// estimateOpenAIResponsesInputTokens 估算 Responses 请求的输入 token：
+// instructions + input（字符串或 item 数组中的文本、工具参数与工具输出）。
+func estimateOpenAIResponsesInputTokens(body []byte) int {
+	total := estimateTokensForText(gjson.GetBytes(body, "instructions").String())
+	input := gjson.GetBytes(body, "input")
+	if input.Type == gjson.String {
+		return total + estimateTokensForText(input.String())
+	}
+	input.ForEach(func(_, item gjson.Result) bool {
+		total += estimateOpenAIResponsesItemTokens(item)
+		return true
+	})
+	return total
+}
+
+// estimateOpenAIResponsesOutputTokens 估算终止事件 response.output 中的输出 token。
+func estimateOpenAIResponsesOutputTokens(terminalEvent []byte) int {
+	total := 0
+	gjson.GetBytes(terminalEvent, "response.output").ForEach(func(_, item gjson.Result) bool {
+		total += estimateOpenAIResponsesItemTokens(item)
+		return true
+	})
+	return total
+}
+
+// estimateOpenAIResponsesItemTokens 估算单个 input/output item 的 token：
+// message content（字符串或 parts[].text）、reasoning summary、function_call arguments 与工具输出。
+func estimateOpenAIResponsesItemTokens(item gjson.Result) int {
+	total := 0
+	content := item.Get("content")
+	if content.Type == gjson.String {
+		total += estimateTokensForText(content.String())
+	} else {
+		content.ForEach(func(_, part gjson.Result) bool {
+			total += estimateTokensForText(part.Get("text").String())
+			return true
+		})
+	}
+	item.Get("summary").ForEach(func(_, part gjson.Result) bool {
+		total += estimateTokensForText(part.Get("text").String())
+		return true
+	})
+	total += estimateTokensForText(item.Get("arguments").String())
+	total += estimateTokensForText(item.Get("input").String())
+	if output := item.Get("output"); output.Type == gjson.String {
+		total += estimateTokensForText(output.String())
+	}
+	return total
+}
+
+// openAIStreamEventCarriesUsage 判断终止事件是否自带 usage。
+func openAIStreamEventCarriesUsage(data []byte) bool {
+	return gjson.GetBytes(data, "response.usage").IsObject() || gjson.GetBytes(data, "usage").IsObject()
+}
+
+// applyOpenAIStreamUsageEstimate 在终止事件 response.completed 缺失 usage 时估算用量。
+// 返回是否发生估算；estimate 为 nil、事件已带 usage 或已解析到 usage 时不处理。
+func applyOpenAIStreamUsageEstimate(estimate *openAIStreamUsageEstimate, eventType string, data []byte, usage *OpenAIUsage) bool {
+	if estimate == nil || usage == nil || eventType != "response.completed" {
+		return false
+	}
+	if openAIStreamEventCarriesUsage(data) || usage.InputTokens > 0 || usage.OutputTokens > 0 {
+		return false
+	}
+	usage.InputTokens = estimate.inputTokens
+	usage.OutputTokens = estimateOpenAIResponsesOutputTokens(data)
+	return usage.InputTokens > 0 || usage.OutputTokens > 0
+}
+
+// applyOpenAITruncatedStreamUsageEstimate 在流于终止事件前结束（EOF）时，按请求与已转发的输出估算用量，
+// 避免被截断的流按 0 token 记账。返回是否发生估算；estimate 为 nil 或已解析到 usage 时不处理。
+func applyOpenAITruncatedStreamUsageEstimate(estimate *openAIStreamUsageEstimate, output []apicompat.ResponsesOutput, usage *OpenAIUsage) bool {
+	if estimate == nil || usage == nil || usage.InputTokens > 0 || usage.OutputTokens > 0 {
+		return false
+	}
+	usage.InputTokens = estimate.inputTokens
+	usage.OutputTokens = 0
+	if raw, err := json.Marshal(output); err == nil {
+		gjson.ParseBytes(raw).ForEach(func(_, item gjson.Result) bool {
+			usage.OutputTokens += estimateOpenAIResponsesItemTokens(item)
+			return true
+		})
+	}
+	return usage.InputTokens > 0 || usage.OutputTokens > 0
+}
+
+// patchOpenAIStreamTerminalUsage 把估算 usage 写入终止事件的 response.usage，供下游客户端读取。
+func patchOpenAIStreamTerminalUsage(data []byte, usage OpenAIUsage) ([]byte, bool) {
+	patched, err := sjson.SetBytes(data, "response.usage", map[string]any{
+		"input_tokens":  usage.InputTokens,
+		"output_tokens": usage.OutputTokens,
+		"total_tokens":  usage.InputTokens + usage.OutputTokens,
+	})
+	if err != nil {
+		return data, false
+	}
+	return patched, true
+}
+
+// estimatedUsageRateMultiplier 返回估算用量计费时叠加的费率倍数（未配置时为 1）。
+func (s *OpenAIGatewayService) estimatedUsageRateMultiplier() float64 {
+	if s == nil || s.cfg == nil || s.cfg.Gateway.EstimatedUsageRateMultiplier <= 0 {
+		return 1.0
+	}
+	return s.cfg.Gateway.EstimatedUsageRateMultiplier
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// openAIStreamWithoutUsageFixture 模拟上游流式响应：有输出文本，但 response.completed 不带 usage。
+const openAIStreamWithoutUsageFixture = "data: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_no_usage\",\"status\":\"in_progress\"}}\n\n" +
+	"data: {\"type\":\"response.output_text.delta\",\"delta\":\"The quick brown fox jumps over the lazy dog.\"}\n\n" +
+	"data: {\"type\":\"response.output_text.delta\",\"delta\":\" It was a sunny afternoon in the park.\"}\n\n" +
+	"data: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_no_usage\",\"status\":\"completed\",\"output\":[]}}\n\n" +
+	"data: [DONE]\n\n"
+
+func runOpenAIStreamUsageEstimateForTest(t *testing.T, fixture string, requestBody string) (*openaiStreamingResult, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{
+		Gateway: config.GatewayConfig{MaxLineSize: defaultMaxLineSize},
+	}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(fixture)),
+		Header:     http.Header{},
+	}
+	result, err := svc.handleStreamingResponseWithUsageEstimate(c.Request.Context(), resp, c, &Account{ID: 1}, time.Now(), "gpt-5.1", "gpt-5.1", newOpenAIStreamUsageEstimate([]byte(requestBody)))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.NotNil(t, result.usage)
+	return result, rec.Body.String()
+}
+
+func openAICompletedEventFromSSEForTest(t *testing.T, body string) string {
+	t.Helper()
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := extractOpenAISSEDataLine(line)
+		if ok && gjson.Get(data, "type").String() == "response.completed" {
+			return data
+		}
+	}
+	t.Fatalf("response.completed not found in %q", body)
+	return ""
+}
+
+func TestOpenAIStreamingUsageEstimate_MissingUsageIsEstimated(t *testing.T) {
+	reqBody := `{"model":"gpt-5.1","stream":true,"instructions":"You are a helpful assistant.","input":[{"role":"user","content":[{"type":"input_text","text":"Tell me a short story about a fox in the park."}]}]}`
+	result, body := runOpenAIStreamUsageEstimateForTest(t, openAIStreamWithoutUsageFixture, reqBody)
+
+	require.True(t, result.usageEstimated)
+	require.Positive(t, result.usage.InputTokens)
+	require.Positive(t, result.usage.OutputTokens)
+	// 客户端未请求 include_usage，不应向下游注入 usage。
+	require.False(t, gjson.Get(openAICompletedEventFromSSEForTest(t, body), "response.usage").Exists())
+}
+
+func TestOpenAIStreamingUsageEstimate_IncludeUsagePatchesTerminalEvent(t *testing.T) {
+	reqBody := `{"model":"gpt-5.1","stream":true,"stream_options":{"include_usage":true},"input":"Tell me a short story about a fox."}`
+	result, body := runOpenAIStreamUsageEstimateForTest(t, openAIStreamWithoutUsageFixture, reqBody)
+
+	require.True(t, result.usageEstimated)
+	completed := openAICompletedEventFromSSEForTest(t, body)
+	require.Equal(t, int64(result.usage.InputTokens), gjson.Get(completed, "response.usage.input_tokens").Int())
+	require.Equal(t, int64(result.usage.OutputTokens), gjson.Get(completed, "response.usage.output_tokens").Int())
+	require.Equal(t, int64(result.usage.InputTokens+result.usage.OutputTokens), gjson.Get(completed, "response.usage.total_tokens").Int())
+}
+
+func TestOpenAIStreamingUsageEstimate_UpstreamUsageIsKept(t *testing.T) {
+	fixture := "data: {\"type\":\"response.output_text.delta\",\"delta\":\"hello\"}\n\n" +
+		"data: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_usage\",\"usage\":{\"input_tokens\":7,\"output_tokens\":9}}}\n\n"
+	result, _ := runOpenAIStreamUsageEstimateForTest(t, fixture, `{"model":"gpt-5.1","stream":true,"input":"hi"}`)
+
+	require.False(t, result.usageEstimated)
+	require.Equal(t, 7, result.usage.InputTokens)
+	require.Equal(t, 9, result.usage.OutputTokens)
+}
+
+func TestOpenAIStreamingUsageEstimate_TruncatedStreamIsEstimated(t *testing.T) {
+	// 上游在输出后直接 EOF，没有任何终止事件
+	fixture := "data: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_truncated\",\"status\":\"in_progress\"}}\n\n" +
+		"data: {\"type\":\"response.output_text.delta\",\"delta\":\"The quick brown fox jumps over the lazy dog.\"}\n\n"
+	result, body := runOpenAIStreamUsageEstimateForTest(t, fixture, `{"model":"gpt-5.1","stream":true,"input":"Tell me a short story about a fox."}`)
+
+	require.True(t, result.usageEstimated)
+	require.Positive(t, result.usage.InputTokens)
+	require.Positive(t, result.usage.OutputTokens)
+	require.Contains(t, body, "The quick brown fox")
+}
+
+func TestOpenAIGatewayServiceRecordUsage_EstimatedUsageFlagAndMultiplier(t *testing.T) {
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	billingRepo := &openAIRecordUsageBillingRepoStub{result: &UsageBillingApplyResult{Applied: true}}
+	svc := newOpenAIRecordUsageServiceWithBillingRepoForTest(usageRepo, billingRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{}, nil)
+	svc.cfg.Gateway.EstimatedUsageRateMultiplier = 1.5
+
+	usage := OpenAIUsage{InputTokens: 20, OutputTokens: 12}
+	err := svc.RecordUsage(context.Background(), &OpenAIRecordUsageInput{
+		Result: &OpenAIForwardResult{
+			RequestID:      "resp_estimated_usage",
+			Usage:          usage,
+			Model:          "gpt-5.1",
+			Stream:         true,
+			Duration:       time.Second,
+			EstimatedUsage: true,
+		},
+		APIKey:        &APIKey{ID: 1010},
+		User:          &User{ID: 2010},
+		Account:       &Account{ID: 3010, Type: AccountTypeAPIKey},
+		APIKeyService: &openAIRecordUsageAPIKeyQuotaStub{},
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, usageRepo.lastLog)
+	require.True(t, usageRepo.lastLog.UsageEstimated)
+	require.Equal(t, 20, usageRepo.lastLog.InputTokens)
+	require.Equal(t, 12, usageRepo.lastLog.OutputTokens)
+	require.InDelta(t, 1.1*1.5, usageRepo.lastLog.RateMultiplier, 1e-9)
+	expected := expectedOpenAICost(t, svc, "gpt-5.1", usage, 1.1*1.5)
+	require.InDelta(t, expected.ActualCost, usageRepo.lastLog.ActualCost, 1e-12)
+}
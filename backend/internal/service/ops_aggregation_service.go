@@ -18,9 +18,19 @@ import (
 const (
 	opsAggHourlyJobName = "ops_preaggregation_hourly"
 	opsAggDailyJobName  = "ops_preaggregation_daily"
+	// 5 分钟粒度的 model/account/group 维度 rollup。
+	opsAggDimensionJobName = "ops_preaggregation_dimension_5m"
 
-	opsAggHourlyInterval = 10 * time.Minute
-	opsAggDailyInterval  = 1 * time.Hour
+	opsAggHourlyInterval    = 10 * time.Minute
+	opsAggDailyInterval     = 1 * time.Hour
+	opsAggDimensionInterval = 2 * time.Minute
+
+	// Dimension rollups only need to cover the longest query window (24h); recent
+	// buckets are recomputed with overlap, older ones are served from the table.
+	opsAggDimensionBackfillWindow = 24 * time.Hour
+	opsAggDimensionOverlap        = 10 * time.Minute
+	// 5m buckets settle quickly; a short delay is enough for late inserts.
+	opsAggDimensionSafeDelay = 1 * time.Minute
 
 	// Keep in sync with ops retention target (vNext default 30d).
 	opsAggBackfillWindow = 1 * time.Hour
@@ -36,19 +46,23 @@ const (
 	// that may still receive late inserts.
 	opsAggSafeDelay = 5 * time.Minute
 
-	opsAggMaxQueryTimeout = 5 * time.Second
-	opsAggHourlyTimeout   = 5 * time.Minute
-	opsAggDailyTimeout    = 2 * time.Minute
+	opsAggMaxQueryTimeout  = 5 * time.Second
+	opsAggHourlyTimeout    = 5 * time.Minute
+	opsAggDailyTimeout     = 2 * time.Minute
+	opsAggDimensionTimeout = 2 * time.Minute
 
-	opsAggHourlyLeaderLockKey = "ops:aggregation:hourly:leader"
-	opsAggDailyLeaderLockKey  = "ops:aggregation:daily:leader"
+	opsAggHourlyLeaderLockKey    = "ops:aggregation:hourly:leader"
+	opsAggDailyLeaderLockKey     = "ops:aggregation:daily:leader"
+	opsAggDimensionLeaderLockKey = "ops:aggregation:dimension_5m:leader"
 
-	opsAggHourlyLeaderLockTTL = 15 * time.Minute
-	opsAggDailyLeaderLockTTL  = 10 * time.Minute
+	opsAggHourlyLeaderLockTTL    = 15 * time.Minute
+	opsAggDailyLeaderLockTTL     = 10 * time.Minute
+	opsAggDimensionLeaderLockTTL = 5 * time.Minute
 )
 
 // OpsAggregationService periodically backfills ops_metrics_hourly / ops_metrics_daily
-// for stable long-window dashboard queries.
+// for stable long-window dashboard queries, plus 5m per-dimension rollups
+// (ops_dimension_metrics_5m) for model/account/group latency and error queries.
 //
 // It is safe to run in multi-replica deployments when Redis is available (leader lock).
 type OpsAggregationService struct {
@@ -64,8 +78,9 @@ type OpsAggregationService struct {
 	startOnce sync.Once
 	stopOnce  sync.Once
 
-	hourlyMu sync.Mutex
-	dailyMu  sync.Mutex
+	hourlyMu    sync.Mutex
+	dailyMu     sync.Mutex
+	dimensionMu sync.Mutex
 
 	skipLogMu sync.Mutex
 	skipLogAt time.Time
@@ -98,6 +113,7 @@ func (s *OpsAggregationService) Start() {
 		}
 		go s.hourlyLoop()
 		go s.dailyLoop()
+		go s.dimensionLoop()
 	})
 }
 
@@ -146,6 +162,23 @@ func (s *OpsAggregationService) dailyLoop() {
 	}
 }
 
+func (s *OpsAggregationService) dimensionLoop() {
+	// First run immediately.
+	s.aggregateDimension5m()
+
+	ticker := time.NewTicker(opsAggDimensionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.aggregateDimension5m()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
 func (s *OpsAggregationService) aggregateHourly() {
 	if s == nil || s.opsRepo == nil {
 		return
@@ -344,6 +377,109 @@ func (s *OpsAggregationService) aggregateDaily() {
 	})
 }
 
+func (s *OpsAggregationService) aggregateDimension5m() {
+	if s == nil || s.opsRepo == nil {
+		return
+	}
+	if s.cfg != nil {
+		if !s.cfg.Ops.Enabled {
+			return
+		}
+		if !s.cfg.Ops.Aggregation.Enabled {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opsAggDimensionTimeout)
+	defer cancel()
+
+	if !s.isMonitoringEnabled(ctx) {
+		return
+	}
+
+	release, ok := s.tryAcquireLeaderLock(ctx, opsAggDimensionLeaderLockKey, opsAggDimensionLeaderLockTTL, "[OpsAggregation][dimension]")
+	if !ok {
+		return
+	}
+	if release != nil {
+		defer release()
+	}
+
+	s.dimensionMu.Lock()
+	defer s.dimensionMu.Unlock()
+
+	startedAt := time.Now().UTC()
+	runAt := startedAt
+
+	end := utcFloorTo5m(time.Now().UTC().Add(-opsAggDimensionSafeDelay))
+	start := end.Add(-opsAggDimensionBackfillWindow)
+
+	{
+		ctxMax, cancelMax := context.WithTimeout(context.Background(), opsAggMaxQueryTimeout)
+		latest, ok, err := s.opsRepo.GetLatestDimensionRollupBucketStart(ctxMax)
+		cancelMax()
+		if err != nil {
+			logger.LegacyPrintf("service.ops_aggregation", "[OpsAggregation][dimension] failed to read latest bucket: %v", err)
+		} else if ok {
+			candidate := latest.Add(-opsAggDimensionOverlap)
+			if candidate.After(start) {
+				start = candidate
+			}
+		}
+	}
+
+	start = utcFloorTo5m(start)
+	if !start.Before(end) {
+		return
+	}
+
+	// 逐桶读取原始记录并在内存中汇总，单次查询量被限制在 5 分钟内的请求数。
+	var aggErr error
+	var upserted int
+	for cursor := start; cursor.Before(end); cursor = cursor.Add(opsDimensionBucket) {
+		bucketEnd := cursor.Add(opsDimensionBucket)
+		samples, err := s.opsRepo.ListDimensionSamples(ctx, cursor, bucketEnd)
+		if err != nil {
+			aggErr = err
+		} else if rollups := buildOpsDimensionRollups(samples); len(rollups) > 0 {
+			aggErr = s.opsRepo.UpsertDimensionRollups(ctx, rollups)
+			upserted += len(rollups)
+		}
+		if aggErr != nil {
+			logger.LegacyPrintf("service.ops_aggregation", "[OpsAggregation][dimension] rollup failed (%s): %v", cursor.Format(time.RFC3339), aggErr)
+			break
+		}
+	}
+
+	finishedAt := time.Now().UTC()
+	dur := finishedAt.Sub(startedAt).Milliseconds()
+
+	hbCtx, hbCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer hbCancel()
+	if aggErr != nil {
+		msg := truncateString(aggErr.Error(), 2048)
+		errAt := finishedAt
+		_ = s.opsRepo.UpsertJobHeartbeat(hbCtx, &OpsUpsertJobHeartbeatInput{
+			JobName:        opsAggDimensionJobName,
+			LastRunAt:      &runAt,
+			LastErrorAt:    &errAt,
+			LastError:      &msg,
+			LastDurationMs: &dur,
+		})
+		return
+	}
+
+	successAt := finishedAt
+	result := truncateString(fmt.Sprintf("window=%s..%s rollups=%d", start.Format(time.RFC3339), end.Format(time.RFC3339), upserted), 2048)
+	_ = s.opsRepo.UpsertJobHeartbeat(hbCtx, &OpsUpsertJobHeartbeatInput{
+		JobName:        opsAggDimensionJobName,
+		LastRunAt:      &runAt,
+		LastSuccessAt:  &successAt,
+		LastDurationMs: &dur,
+		LastResult:     &result,
+	})
+}
+
 func (s *OpsAggregationService) isMonitoringEnabled(ctx context.Context) bool {
 	if s == nil {
 		return false
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// opsDimensionBucket 维度预聚合的桶宽。
+	opsDimensionBucket = 5 * time.Minute
+
+	opsDimensionDefaultLimit = 50
+	opsDimensionMaxLimit     = 200
+
+	opsTopOffendersDefaultLimit = 10
+	opsTopOffendersMaxLimit     = 100
+
+	opsDimensionSourceRaw    = "raw"
+	opsDimensionSourcePreagg = "preagg"
+	opsDimensionSourceMixed  = "mixed"
+)
+
+// opsDimensionLatencyBoundsMs 延迟直方图的桶上界（不含），最后一个桶为 [300000, +inf)。
+// 修改会使已有 rollup 的直方图失去可比性，只能追加新桶并重建数据。
+var opsDimensionLatencyBoundsMs = []int64{
+	50, 100, 200, 300, 500, 750,
+	1000, 1500, 2000, 3000, 5000, 7500,
+	10000, 15000, 20000, 30000, 45000, 60000,
+	90000, 120000, 180000, 300000,
+}
+
+var opsDimensions = []string{OpsDimensionModel, OpsDimensionAccount, OpsDimensionGroup}
+
+func isValidOpsDimension(dimension string) bool {
+	for _, d := range opsDimensions {
+		if d == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOpsDimensionWindow 解析查询窗口（5m/1h/24h）。
+func parseOpsDimensionWindow(window string) (time.Duration, bool) {
+	switch strings.TrimSpace(window) {
+	case "5m":
+		return 5 * time.Minute, true
+	case "1h":
+		return time.Hour, true
+	case "24h":
+		return 24 * time.Hour, true
+	default:
+		return 0, false
+	}
+}
+
+func utcFloorTo5m(t time.Time) time.Time {
+	return t.UTC().Truncate(opsDimensionBucket)
+}
+
+func utcCeilTo5m(t time.Time) time.Time {
+	floor := utcFloorTo5m(t)
+	if floor.Equal(t.UTC()) {
+		return floor
+	}
+	return floor.Add(opsDimensionBucket)
+}
+
+func opsDimensionHistogramIndex(ms int64) int {
+	return sort.Search(len(opsDimensionLatencyBoundsMs), func(i int) bool {
+		return opsDimensionLatencyBoundsMs[i] > ms
+	})
+}
+
+func opsDimensionKey(sample *OpsDimensionSample, dimension string) string {
+	switch dimension {
+	case OpsDimensionModel:
+		if model := strings.TrimSpace(sample.Model); model != "" {
+			return model
+		}
+	case OpsDimensionAccount:
+		if sample.AccountID != nil && *sample.AccountID > 0 {
+			return strconv.FormatInt(*sample.AccountID, 10)
+		}
+	case OpsDimensionGroup:
+		if sample.GroupID != nil && *sample.GroupID > 0 {
+			return strconv.FormatInt(*sample.GroupID, 10)
+		}
+	}
+	return opsDimensionUnknownKey
+}
+
+func newOpsDimensionRollup(bucketStart time.Time, dimension, key string) *OpsDimensionRollup {
+	return &OpsDimensionRollup{
+		BucketStart:      bucketStart,
+		Dimension:        dimension,
+		DimensionKey:     key,
+		LatencyHistogram: make([]int64, len(opsDimensionLatencyBoundsMs)+1),
+		TTFTHistogram:    make([]int64, len(opsDimensionLatencyBoundsMs)+1),
+	}
+}
+
+func (r *OpsDimensionRollup) addSample(sample *OpsDimensionSample) {
+	r.FailoverCount += sample.FailoverCount
+	if sample.FailoverOnly {
+		return
+	}
+	r.RequestCount++
+	r.TotalTokens += sample.TotalTokens
+	r.OutputTokens += sample.OutputTokens
+	if sample.IsError {
+		r.ErrorCount++
+		// 延迟/TTFT 只统计成功请求，与 dashboard 口径一致。
+		return
+	}
+	if sample.DurationMs != nil && *sample.DurationMs >= 0 {
+		v := *sample.DurationMs
+		r.LatencyCount++
+		r.LatencySumMs += v
+		if v > r.LatencyMaxMs {
+			r.LatencyMaxMs = v
+		}
+		r.LatencyHistogram[opsDimensionHistogramIndex(v)]++
+	}
+	if sample.FirstTokenMs != nil && *sample.FirstTokenMs >= 0 {
+		v := *sample.FirstTokenMs
+		r.TTFTCount++
+		r.TTFTSumMs += v
+		if v > r.TTFTMaxMs {
+			r.TTFTMaxMs = v
+		}
+		r.TTFTHistogram[opsDimensionHistogramIndex(v)]++
+	}
+}
+
+func (r *OpsDimensionRollup) merge(other *OpsDimensionRollup) {
+	r.RequestCount += other.RequestCount
+	r.ErrorCount += other.ErrorCount
+	r.FailoverCount += other.FailoverCount
+	r.TotalTokens += other.TotalTokens
+	r.OutputTokens += other.OutputTokens
+	r.LatencyCount += other.LatencyCount
+	r.LatencySumMs += other.LatencySumMs
+	if other.LatencyMaxMs > r.LatencyMaxMs {
+		r.LatencyMaxMs = other.LatencyMaxMs
+	}
+	r.TTFTCount += other.TTFTCount
+	r.TTFTSumMs += other.TTFTSumMs
+	if other.TTFTMaxMs > r.TTFTMaxMs {
+		r.TTFTMaxMs = other.TTFTMaxMs
+	}
+	// 直方图长度以较短者为准逐桶相加；桶定义只追加，前缀始终一致。
+	for i := 0; i < len(r.LatencyHistogram) && i < len(other.LatencyHistogram); i++ {
+		r.LatencyHistogram[i] += other.LatencyHistogram[i]
+	}
+	for i := 0; i < len(r.TTFTHistogram) && i < len(other.TTFTHistogram); i++ {
+		r.TTFTHistogram[i] += other.TTFTHistogram[i]
+	}
+}
+
+// buildOpsDimensionRollups 将原始记录按 5 分钟桶和全部维度汇总为 rollup。
+func buildOpsDimensionRollups(samples []*OpsDimensionSample) []*OpsDimensionRollup {
+	type rollupKey struct {
+		bucket    int64
+		dimension string
+		key       string
+	}
+	index := make(map[rollupKey]*OpsDimensionRollup)
+	out := make([]*OpsDimensionRollup, 0)
+	for _, sample := range samples {
+		if sample == nil {
+			continue
+		}
+		bucket := utcFloorTo5m(sample.CreatedAt)
+		for _, dimension := range opsDimensions {
+			k := rollupKey{bucket: bucket.Unix(), dimension: dimension, key: opsDimensionKey(sample, dimension)}
+			r, ok := index[k]
+			if !ok {
+				r = newOpsDimensionRollup(bucket, dimension, k.key)
+				index[k] = r
+				out = append(out, r)
+			}
+			r.addSample(sample)
+		}
+	}
+	return out
+}
+
+// opsHistogramPercentile 按最近秩在直方图中定位目标桶，并在桶内线性插值估算分位数。
+// 最后一个桶以及整体上界都以观测到的最大值封顶。
+func opsHistogramPercentile(hist []int64, count, maxMs int64, q float64) *float64 {
+	if count <= 0 || len(hist) == 0 {
+		return nil
+	}
+	rank := int64(math.Ceil(q * float64(count)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > count {
+		rank = count
+	}
+
+	var cum int64
+	for i, n := range hist {
+		if n <= 0 {
+			continue
+		}
+		if cum+n < rank {
+			cum += n
+			continue
+		}
+		lower := float64(0)
+		if i > 0 {
+			lower = float64(opsDimensionLatencyBoundsMs[i-1])
+		}
+		upper := float64(maxMs)
+		if i < len(opsDimensionLatencyBoundsMs) {
+			upper = math.Min(float64(opsDimensionLatencyBoundsMs[i]), float64(maxMs))
+		}
+		if upper < lower {
+			upper = lower
+		}
+		v := lower + (upper-lower)*float64(rank-cum)/float64(n)
+		v = math.Round(v*100) / 100
+		return &v
+	}
+	return nil
+}
+
+func opsSafeRate(num, den int64) float64 {
+	if den <= 0 {
+		return 0
+	}
+	return math.Round(float64(num)/float64(den)*10000) / 10000
+}
+
+func (r *OpsDimensionRollup) toMetricsItem(windowSeconds float64) *OpsDimensionMetricsItem {
+	item := &OpsDimensionMetricsItem{
+		Key:           r.DimensionKey,
+		RequestCount:  r.RequestCount,
+		ErrorCount:    r.ErrorCount,
+		FailoverCount: r.FailoverCount,
+		ErrorRate:     opsSafeRate(r.ErrorCount, r.RequestCount),
+		FailoverRate:  opsSafeRate(r.FailoverCount, r.RequestCount),
+		TotalTokens:   r.TotalTokens,
+		OutputTokens:  r.OutputTokens,
+		LatencyP50Ms:  opsHistogramPercentile(r.LatencyHistogram, r.LatencyCount, r.LatencyMaxMs, 0.50),
+		LatencyP95Ms:  opsHistogramPercentile(r.LatencyHistogram, r.LatencyCount, r.LatencyMaxMs, 0.95),
+		LatencyP99Ms:  opsHistogramPercentile(r.LatencyHistogram, r.LatencyCount, r.LatencyMaxMs, 0.99),
+		TTFTP50Ms:     opsHistogramPercentile(r.TTFTHistogram, r.TTFTCount, r.TTFTMaxMs, 0.50),
+		TTFTP95Ms:     opsHistogramPercentile(r.TTFTHistogram, r.TTFTCount, r.TTFTMaxMs, 0.95),
+		TTFTP99Ms:     opsHistogramPercentile(r.TTFTHistogram, r.TTFTCount, r.TTFTMaxMs, 0.99),
+	}
+	if windowSeconds > 0 {
+		item.TokensPerSecond = math.Round(float64(r.TotalTokens)/windowSeconds*100) / 100
+	}
+	if r.LatencyCount > 0 {
+		avg := math.Round(float64(r.LatencySumMs)/float64(r.LatencyCount)*100) / 100
+		maxMs := r.LatencyMaxMs
+		item.LatencyAvgMs = &avg
+		item.LatencyMaxMs = &maxMs
+	}
+	return item
+}
+
+// collectOpsDimensionRollups 汇总 [start,end) 内某维度的指标：
+// 已完成预聚合的完整 5 分钟桶读 rollup，首尾不完整/尚未聚合的部分回落到原始记录。
+func (s *OpsService) collectOpsDimensionRollups(ctx context.Context, dimension string, start, end time.Time) (map[string]*OpsDimensionRollup, string, error) {
+	merged := make(map[string]*OpsDimensionRollup)
+	mergeInto := func(r *OpsDimensionRollup) {
+		if r == nil || r.Dimension != dimension {
+			return
+		}
+		existing, ok := merged[r.DimensionKey]
+		if !ok {
+			existing = newOpsDimensionRollup(start, dimension, r.DimensionKey)
+			merged[r.DimensionKey] = existing
+		}
+		existing.merge(r)
+	}
+
+	type timeRange struct{ start, end time.Time }
+	rawRanges := []timeRange{{start, end}}
+	source := opsDimensionSourceRaw
+
+	preaggStart := utcCeilTo5m(start)
+	preaggEnd := utcFloorTo5m(end)
+	if preaggStart.Before(preaggEnd) {
+		latest, ok, err := s.opsRepo.GetLatestDimensionRollupBucketStart(ctx)
+		if err != nil {
+			logger.LegacyPrintf("service.ops", "[OpsDimensionMetrics] failed to read latest rollup bucket: %v", err)
+		} else if ok {
+			covered := minTime(latest.Add(opsDimensionBucket), preaggEnd)
+			if preaggStart.Before(covered) {
+				rollups, err := s.opsRepo.ListDimensionRollups(ctx, dimension, preaggStart, covered)
+				if err != nil {
+					logger.LegacyPrintf("service.ops", "[OpsDimensionMetrics] list rollups failed, fallback to raw: %v", err)
+				} else {
+					for _, r := range rollups {
+						mergeInto(r)
+					}
+					rawRanges = []timeRange{{start, preaggStart}, {covered, end}}
+					source = opsDimensionSourcePreagg
+				}
+			}
+		}
+	}
+
+	// 原始记录按 5 分钟桶分页读取并即时汇总，内存占用与单桶请求量相当，而非整个窗口
+	for _, rr := range rawRanges {
+		if !rr.start.Before(rr.end) {
+			continue
+		}
+		if source == opsDimensionSourcePreagg {
+			source = opsDimensionSourceMixed
+		}
+		for cursor := rr.start; cursor.Before(rr.end); {
+			pageEnd := minTime(utcFloorTo5m(cursor).Add(opsDimensionBucket), rr.end)
+			samples, err := s.opsRepo.ListDimensionSamples(ctx, cursor, pageEnd)
+			if err != nil {
+				return nil, "", err
+			}
+			for _, r := range buildOpsDimensionRollups(samples) {
+				mergeInto(r)
+			}
+			cursor = pageEnd
+		}
+	}
+	return merged, source, nil
+}
+
+func (s *OpsService) requireOpsDimensionRepo(ctx context.Context) error {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return err
+	}
+	if s.opsRepo == nil {
+		return infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	return nil
+}
+
+// GetDimensionMetrics 返回按 model/account/group 分组的延迟分位数、错误率、failover 率与 token 吞吐。
+func (s *OpsService) GetDimensionMetrics(ctx context.Context, filter *OpsDimensionMetricsFilter) (*OpsDimensionMetricsResponse, error) {
+	if err := s.requireOpsDimensionRepo(ctx); err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return nil, infraerrors.BadRequest("OPS_FILTER_REQUIRED", "filter is required")
+	}
+	dimension := strings.ToLower(strings.TrimSpace(filter.Dimension))
+	if !isValidOpsDimension(dimension) {
+		return nil, infraerrors.BadRequest("OPS_DIMENSION_INVALID", "dimension must be one of model/account/group")
+	}
+	window, ok := parseOpsDimensionWindow(filter.Window)
+	if !ok {
+		return nil, infraerrors.BadRequest("OPS_WINDOW_INVALID", "window must be one of 5m/1h/24h")
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = opsDimensionDefaultLimit
+	}
+	if limit < 1 || limit > opsDimensionMaxLimit {
+		return nil, infraerrors.BadRequest("OPS_LIMIT_INVALID", "limit must be between 1 and 200")
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-window)
+	merged, source, err := s.collectOpsDimensionRollups(ctx, dimension, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*OpsDimensionMetricsItem, 0, len(merged))
+	for _, r := range merged {
+		items = append(items, r.toMetricsItem(window.Seconds()))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].RequestCount != items[j].RequestCount {
+			return items[i].RequestCount > items[j].RequestCount
+		}
+		return items[i].Key < items[j].Key
+	})
+	total := len(items)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	return &OpsDimensionMetricsResponse{
+		Dimension: dimension,
+		Window:    strings.TrimSpace(filter.Window),
+		StartTime: start,
+		EndTime:   end,
+		Source:    source,
+		Items:     items,
+		Total:     total,
+	}, nil
+}
+
+// GetTopOffenders 按窗口内错误贡献度（错误数，其次错误率）对账号排序。
+func (s *OpsService) GetTopOffenders(ctx context.Context, windowRaw string, limit int) (*OpsTopOffendersResponse, error) {
+	if err := s.requireOpsDimensionRepo(ctx); err != nil {
+		return nil, err
+	}
+	window, ok := parseOpsDimensionWindow(windowRaw)
+	if !ok {
+		return nil, infraerrors.BadRequest("OPS_WINDOW_INVALID", "window must be one of 5m/1h/24h")
+	}
+	if limit == 0 {
+		limit = opsTopOffendersDefaultLimit
+	}
+	if limit < 1 || limit > opsTopOffendersMaxLimit {
+		return nil, infraerrors.BadRequest("OPS_LIMIT_INVALID", "limit must be between 1 and 100")
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-window)
+	merged, source, err := s.collectOpsDimensionRollups(ctx, OpsDimensionAccount, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalErrors int64
+	for _, r := range merged {
+		totalErrors += r.ErrorCount
+	}
+
+	items := make([]*OpsTopOffenderItem, 0, len(merged))
+	for key, r := range merged {
+		if r.ErrorCount <= 0 {
+			continue
+		}
+		// 未路由到账号的错误计入总数但不参与排名。
+		accountID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			continue
+		}
+		items = append(items, &OpsTopOffenderItem{
+			AccountID:     accountID,
+			RequestCount:  r.RequestCount,
+			ErrorCount:    r.ErrorCount,
+			FailoverCount: r.FailoverCount,
+			ErrorRate:     opsSafeRate(r.ErrorCount, r.RequestCount),
+			ErrorShare:    opsSafeRate(r.ErrorCount, totalErrors),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].ErrorCount != items[j].ErrorCount {
+			return items[i].ErrorCount > items[j].ErrorCount
+		}
+		if items[i].ErrorRate != items[j].ErrorRate {
+			return items[i].ErrorRate > items[j].ErrorRate
+		}
+		return items[i].AccountID < items[j].AccountID
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	return &OpsTopOffendersResponse{
+		Window:      strings.TrimSpace(windowRaw),
+		StartTime:   start,
+		EndTime:     end,
+		Source:      source,
+		TotalErrors: totalErrors,
+		Items:       items,
+	}, nil
+}
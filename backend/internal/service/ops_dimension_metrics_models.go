@@ -0,0 +1,123 @@
+package service
+
+import "time"
+
+// Ops 维度指标的分组维度。
+const (
+	OpsDimensionModel   = "model"
+	OpsDimensionAccount = "account"
+	OpsDimensionGroup   = "group"
+)
+
+// opsDimensionUnknownKey 维度取值缺失（如路由前失败没有 account_id）时使用的占位 key。
+const opsDimensionUnknownKey = "unknown"
+
+// OpsDimensionSample 单个请求的原始记录（成功来自 usage_logs，失败来自 ops_error_logs）。
+type OpsDimensionSample struct {
+	CreatedAt time.Time
+
+	Model     string
+	AccountID *int64
+	GroupID   *int64
+
+	DurationMs   *int64
+	FirstTokenMs *int64
+
+	TotalTokens  int64
+	OutputTokens int64
+
+	IsError bool
+	// FailoverCount 本请求处理过程中发生的账号切换次数（来自 upstream_errors）。
+	FailoverCount int64
+	// FailoverOnly 请求最终成功（已计入 usage_logs）的错误日志行，只贡献 failover 次数。
+	FailoverOnly bool
+}
+
+// OpsDimensionRollup 一个 5 分钟桶内某维度取值的可叠加汇总。
+// 延迟使用固定桶直方图保存，多个 rollup 相加后仍可估算分位数。
+type OpsDimensionRollup struct {
+	BucketStart  time.Time
+	Dimension    string
+	DimensionKey string
+
+	RequestCount  int64
+	ErrorCount    int64
+	FailoverCount int64
+
+	TotalTokens  int64
+	OutputTokens int64
+
+	LatencyCount     int64
+	LatencySumMs     int64
+	LatencyMaxMs     int64
+	LatencyHistogram []int64
+
+	TTFTCount     int64
+	TTFTSumMs     int64
+	TTFTMaxMs     int64
+	TTFTHistogram []int64
+}
+
+type OpsDimensionMetricsFilter struct {
+	Dimension string
+	// Window 5m / 1h / 24h
+	Window string
+	Limit  int
+}
+
+type OpsDimensionMetricsItem struct {
+	Key string `json:"key"`
+
+	RequestCount  int64   `json:"request_count"`
+	ErrorCount    int64   `json:"error_count"`
+	FailoverCount int64   `json:"failover_count"`
+	ErrorRate     float64 `json:"error_rate"`
+	FailoverRate  float64 `json:"failover_rate"`
+
+	TotalTokens     int64   `json:"total_tokens"`
+	OutputTokens    int64   `json:"output_tokens"`
+	TokensPerSecond float64 `json:"tokens_per_second"`
+
+	LatencyP50Ms *float64 `json:"latency_p50_ms"`
+	LatencyP95Ms *float64 `json:"latency_p95_ms"`
+	LatencyP99Ms *float64 `json:"latency_p99_ms"`
+	LatencyAvgMs *float64 `json:"latency_avg_ms"`
+	LatencyMaxMs *int64   `json:"latency_max_ms"`
+
+	TTFTP50Ms *float64 `json:"ttft_p50_ms"`
+	TTFTP95Ms *float64 `json:"ttft_p95_ms"`
+	TTFTP99Ms *float64 `json:"ttft_p99_ms"`
+}
+
+type OpsDimensionMetricsResponse struct {
+	Dimension string    `json:"dimension"`
+	Window    string    `json:"window"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// Source preagg / raw / mixed：是否命中了 5 分钟预聚合。
+	Source string `json:"source"`
+
+	Items []*OpsDimensionMetricsItem `json:"items"`
+	// Total 截断前的维度取值数量。
+	Total int `json:"total"`
+}
+
+type OpsTopOffenderItem struct {
+	AccountID     int64   `json:"account_id"`
+	RequestCount  int64   `json:"request_count"`
+	ErrorCount    int64   `json:"error_count"`
+	FailoverCount int64   `json:"failover_count"`
+	ErrorRate     float64 `json:"error_rate"`
+	// ErrorShare 该账号错误数占窗口内全部错误的比例。
+	ErrorShare float64 `json:"error_share"`
+}
+
+type OpsTopOffendersResponse struct {
+	Window      string    `json:"window"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Source      string    `json:"source"`
+	TotalErrors int64     `json:"total_errors"`
+
+	Items []*OpsTopOffenderItem `json:"items"`
+}
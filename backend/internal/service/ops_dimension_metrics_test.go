@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// opsDimensionTestStore 模拟 usage_logs/ops_error_logs 原始记录与 ops_dimension_metrics_5m 表。
+type opsDimensionTestStore struct {
+	mu      sync.Mutex
+	samples []*OpsDimensionSample
+	rollups map[string]*OpsDimensionRollup
+}
+
+func (st *opsDimensionTestStore) repo() *opsRepoMock {
+	return &opsRepoMock{
+		ListDimensionSamplesFn: func(ctx context.Context, startTime, endTime time.Time) ([]*OpsDimensionSample, error) {
+			out := make([]*OpsDimensionSample, 0)
+			for _, s := range st.samples {
+				if !s.CreatedAt.Before(startTime) && s.CreatedAt.Before(endTime) {
+					out = append(out, s)
+				}
+			}
+			return out, nil
+		},
+		UpsertDimensionRollupsFn: func(ctx context.Context, rollups []*OpsDimensionRollup) error {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			for _, r := range rollups {
+				st.rollups[r.BucketStart.Format(time.RFC3339)+"|"+r.Dimension+"|"+r.DimensionKey] = r
+			}
+			return nil
+		},
+		ListDimensionRollupsFn: func(ctx context.Context, dimension string, startTime, endTime time.Time) ([]*OpsDimensionRollup, error) {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			out := make([]*OpsDimensionRollup, 0)
+			for _, r := range st.rollups {
+				if r.Dimension == dimension && !r.BucketStart.Before(startTime) && r.BucketStart.Before(endTime) {
+					out = append(out, r)
+				}
+			}
+			return out, nil
+		},
+		GetLatestDimensionRollupBucketStartFn: func(ctx context.Context) (time.Time, bool, error) {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			var latest time.Time
+			for _, r := range st.rollups {
+				if r.BucketStart.After(latest) {
+					latest = r.BucketStart
+				}
+			}
+			return latest, !latest.IsZero(), nil
+		},
+	}
+}
+
+// seedOpsDimensionSamples 生成最近 50 分钟内的确定性数据集（含失败、failover-only、缺失账号的记录）。
+func seedOpsDimensionSamples(now time.Time) []*OpsDimensionSample {
+	rng := rand.New(rand.NewSource(42))
+	models := []string{"claude-sonnet-4-5", "gpt-5", "gemini-2.5-pro"}
+	out := make([]*OpsDimensionSample, 0, 3000)
+	for i := 0; i < 3000; i++ {
+		accountID := int64(1 + rng.Intn(6))
+		groupID := int64(10 + rng.Intn(3))
+		s := &OpsDimensionSample{
+			CreatedAt: now.Add(-time.Duration(rng.Int63n(int64(50*time.Minute))) - time.Second),
+			Model:     models[rng.Intn(len(models))],
+			AccountID: &accountID,
+			GroupID:   &groupID,
+		}
+		switch roll := rng.Intn(100); {
+		case roll < 8 || (accountID == 3 && roll < 40):
+			// 账号 3 错误率明显偏高，应排在 top offenders 第一位
+			s.IsError = true
+			s.FailoverCount = int64(rng.Intn(3))
+		case roll < 11:
+			s.FailoverOnly = true
+			s.FailoverCount = 1
+		default:
+			d := int64(rng.ExpFloat64() * 2500)
+			s.DurationMs = &d
+			if rng.Intn(4) > 0 {
+				ttft := d / 3
+				s.FirstTokenMs = &ttft
+			}
+			s.OutputTokens = int64(rng.Intn(800))
+			s.TotalTokens = s.OutputTokens + int64(rng.Intn(4000))
+		}
+		if i%97 == 0 {
+			s.AccountID = nil
+			s.IsError = true
+			s.FailoverOnly = false
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+type opsDimensionExpected struct {
+	requests, errors, failovers, tokens, output int64
+	latencies                                   []int64
+}
+
+func expectOpsDimension(samples []*OpsDimensionSample, dimension string) map[string]*opsDimensionExpected {
+	out := make(map[string]*opsDimensionExpected)
+	for _, s := range samples {
+		key := opsDimensionKey(s, dimension)
+		e, ok := out[key]
+		if !ok {
+			e = &opsDimensionExpected{}
+			out[key] = e
+		}
+		e.failovers += s.FailoverCount
+		if s.FailoverOnly {
+			continue
+		}
+		e.requests++
+		e.tokens += s.TotalTokens
+		e.output += s.OutputTokens
+		if s.IsError {
+			e.errors++
+			continue
+		}
+		if s.DurationMs != nil {
+			e.latencies = append(e.latencies, *s.DurationMs)
+		}
+	}
+	return out
+}
+
+// requireOpsPercentileInBucket 估算值必须落在精确（最近秩）分位数所在的直方图桶内。
+func requireOpsPercentileInBucket(t *testing.T, sorted []int64, q float64, got *float64) {
+	t.Helper()
+	require.NotNil(t, got)
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	exact := sorted[max(rank, 1)-1]
+	idx := opsDimensionHistogramIndex(exact)
+	lower := float64(0)
+	if idx > 0 {
+		lower = float64(opsDimensionLatencyBoundsMs[idx-1])
+	}
+	upper := float64(sorted[len(sorted)-1])
+	if idx < len(opsDimensionLatencyBoundsMs) {
+		upper = math.Min(upper, float64(opsDimensionLatencyBoundsMs[idx]))
+	}
+	require.GreaterOrEqual(t, *got, lower, "p%.0f exact=%d", q*100, exact)
+	require.LessOrEqual(t, *got, upper, "p%.0f exact=%d", q*100, exact)
+}
+
+func runOpsDimensionRollupJob(t *testing.T, repo OpsRepository) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	NewOpsAggregationService(repo, nil, nil, rdb, nil).aggregateDimension5m()
+}
+
+func TestOpsDimensionMetrics_RollupsMatchRawRecords(t *testing.T) {
+	now := time.Now().UTC()
+	store := &opsDimensionTestStore{samples: seedOpsDimensionSamples(now), rollups: map[string]*OpsDimensionRollup{}}
+	repo := store.repo()
+	runOpsDimensionRollupJob(t, repo)
+	require.NotEmpty(t, store.rollups)
+
+	svc := NewOpsService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	for _, dimension := range opsDimensions {
+		preagg, err := svc.GetDimensionMetrics(context.Background(), &OpsDimensionMetricsFilter{Dimension: dimension, Window: "1h", Limit: 200})
+		require.NoError(t, err)
+		require.Equal(t, opsDimensionSourceMixed, preagg.Source)
+
+		// 同一数据集不走预聚合（纯原始记录）时结果必须完全一致
+		rawSvc := NewOpsService(&opsRepoMock{ListDimensionSamplesFn: repo.ListDimensionSamplesFn}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		raw, err := rawSvc.GetDimensionMetrics(context.Background(), &OpsDimensionMetricsFilter{Dimension: dimension, Window: "1h", Limit: 200})
+		require.NoError(t, err)
+		require.Equal(t, opsDimensionSourceRaw, raw.Source)
+		require.Equal(t, raw.Items, preagg.Items)
+
+		expected := expectOpsDimension(store.samples, dimension)
+		require.Len(t, preagg.Items, len(expected))
+		require.Equal(t, len(expected), preagg.Total)
+		for _, item := range preagg.Items {
+			e := expected[item.Key]
+			require.NotNil(t, e, "unexpected key %s", item.Key)
+			require.Equal(t, e.requests, item.RequestCount, item.Key)
+			require.Equal(t, e.errors, item.ErrorCount, item.Key)
+			require.Equal(t, e.failovers, item.FailoverCount, item.Key)
+			require.Equal(t, e.tokens, item.TotalTokens, item.Key)
+			require.Equal(t, e.output, item.OutputTokens, item.Key)
+			require.InDelta(t, float64(e.errors)/float64(e.requests), item.ErrorRate, 0.0001)
+			require.InDelta(t, float64(e.failovers)/float64(e.requests), item.FailoverRate, 0.0001)
+			require.InDelta(t, float64(e.tokens)/time.Hour.Seconds(), item.TokensPerSecond, 0.01)
+
+			if len(e.latencies) == 0 {
+				require.Nil(t, item.LatencyP50Ms)
+				continue
+			}
+			sorted := append([]int64(nil), e.latencies...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			requireOpsPercentileInBucket(t, sorted, 0.50, item.LatencyP50Ms)
+			requireOpsPercentileInBucket(t, sorted, 0.95, item.LatencyP95Ms)
+			requireOpsPercentileInBucket(t, sorted, 0.99, item.LatencyP99Ms)
+			require.Equal(t, sorted[len(sorted)-1], *item.LatencyMaxMs)
+		}
+	}
+}
+
+func TestOpsDimensionMetrics_WindowExcludesOlderRecords(t *testing.T) {
+	now := time.Now().UTC()
+	d := int64(100)
+	old := &OpsDimensionSample{CreatedAt: now.Add(-20 * time.Minute), Model: "m", DurationMs: &d}
+	recent := &OpsDimensionSample{CreatedAt: now.Add(-time.Minute), Model: "m", DurationMs: &d}
+	store := &opsDimensionTestStore{samples: []*OpsDimensionSample{old, recent}, rollups: map[string]*OpsDimensionRollup{}}
+	repo := store.repo()
+	runOpsDimensionRollupJob(t, repo)
+
+	svc := NewOpsService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	res, err := svc.GetDimensionMetrics(context.Background(), &OpsDimensionMetricsFilter{Dimension: OpsDimensionModel, Window: "5m"})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	require.Equal(t, int64(1), res.Items[0].RequestCount)
+
+	res, err = svc.GetDimensionMetrics(context.Background(), &OpsDimensionMetricsFilter{Dimension: OpsDimensionModel, Window: "1h"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.Items[0].RequestCount)
+}
+
+func TestOpsDimensionMetrics_RawFallbackReadsOneBucketPerQuery(t *testing.T) {
+	now := time.Now().UTC()
+	d := int64(100)
+	samples := make([]*OpsDimensionSample, 0, 12)
+	for i := 0; i < 12; i++ {
+		samples = append(samples, &OpsDimensionSample{CreatedAt: now.Add(-time.Duration(i)*5*time.Minute - time.Second), Model: "m", DurationMs: &d})
+	}
+	store := &opsDimensionTestStore{samples: samples, rollups: map[string]*OpsDimensionRollup{}}
+	var queries int
+	repo := &opsRepoMock{ListDimensionSamplesFn: func(ctx context.Context, startTime, endTime time.Time) ([]*OpsDimensionSample, error) {
+		queries++
+		// 未预聚合时按桶分页读取，单次查询不跨越 5 分钟桶
+		require.False(t, endTime.After(utcFloorTo5m(startTime).Add(opsDimensionBucket)), "query %s-%s spans more than one bucket", startTime, endTime)
+		return store.repo().ListDimensionSamplesFn(ctx, startTime, endTime)
+	}}
+
+	svc := NewOpsService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	res, err := svc.GetDimensionMetrics(context.Background(), &OpsDimensionMetricsFilter{Dimension: OpsDimensionModel, Window: "1h"})
+	require.NoError(t, err)
+	require.Equal(t, opsDimensionSourceRaw, res.Source)
+	require.Len(t, res.Items, 1)
+	require.Equal(t, int64(12), res.Items[0].RequestCount)
+	require.GreaterOrEqual(t, queries, 12)
+}
+
+func TestOpsTopOffenders_RanksAccountsByErrorContribution(t *testing.T) {
+	now := time.Now().UTC()
+	store := &opsDimensionTestStore{samples: seedOpsDimensionSamples(now), rollups: map[string]*OpsDimensionRollup{}}
+	repo := store.repo()
+	runOpsDimensionRollupJob(t, repo)
+
+	svc := NewOpsService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	res, err := svc.GetTopOffenders(context.Background(), "1h", 3)
+	require.NoError(t, err)
+	require.Len(t, res.Items, 3)
+	require.Equal(t, int64(3), res.Items[0].AccountID)
+
+	expected := expectOpsDimension(store.samples, OpsDimensionAccount)
+	var totalErrors int64
+	for _, e := range expected {
+		totalErrors += e.errors
+	}
+	require.Equal(t, totalErrors, res.TotalErrors)
+	for i, item := range res.Items {
+		e := expected[strconv.FormatInt(item.AccountID, 10)]
+		require.Equal(t, e.errors, item.ErrorCount)
+		require.InDelta(t, float64(e.errors)/float64(totalErrors), item.ErrorShare, 0.0001)
+		if i > 0 {
+			require.LessOrEqual(t, item.ErrorCount, res.Items[i-1].ErrorCount)
+		}
+	}
+}
+
+func TestOpsDimensionMetrics_ValidatesParams(t *testing.T) {
+	svc := NewOpsService(&opsRepoMock{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	_, err := svc.GetDimensionMetrics(context.Background(), &OpsDimensionMetricsFilter{Dimension: "user", Window: "1h"})
+	require.Error(t, err)
+	_, err = svc.GetDimensionMetrics(context.Background(), &OpsDimensionMetricsFilter{Dimension: OpsDimensionModel, Window: "7d"})
+	require.Error(t, err)
+	_, err = svc.GetTopOffenders(context.Background(), "1h", 1000)
+	require.Error(t, err)
+}
+
+func TestOpsHistogramPercentile(t *testing.T) {
+	hist := make([]int64, len(opsDimensionLatencyBoundsMs)+1)
+	// 10 个样本落在 [100,200)，最大值 180
+	hist[opsDimensionHistogramIndex(150)] = 10
+	p50 := opsHistogramPercentile(hist, 10, 180, 0.50)
+	require.NotNil(t, p50)
+	require.InDelta(t, 140, *p50, 0.01)
+	p99 := opsHistogramPercentile(hist, 10, 180, 0.99)
+	require.InDelta(t, 180, *p99, 0.01)
+
+	require.Nil(t, opsHistogramPercentile(hist, 0, 0, 0.5))
+
+	// 溢出桶以最大值封顶
+	overflow := make([]int64, len(opsDimensionLatencyBoundsMs)+1)
+	overflow[len(overflow)-1] = 1
+	p := opsHistogramPercentile(overflow, 1, 400000, 0.99)
+	require.InDelta(t, 400000, *p, 0.01)
+}
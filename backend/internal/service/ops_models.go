@@ -46,7 +46,9 @@ type OpsErrorLog struct {
 
 	ClientRequestID string `json:"client_request_id"`
 	RequestID       string `json:"request_id"`
-	Message         string `json:"message"`
+	// TraceID 本服务的请求 trace ID（X-Trace-ID），可用于检索同一请求的系统日志
+	TraceID string `json:"trace_id"`
+	Message string `json:"message"`
 
 	UserID      *int64 `json:"user_id"`
 	UserEmail   string `json:"user_email"`
@@ -90,6 +92,11 @@ type OpsErrorLogDetail struct {
 	ResponseLatencyMs  *int64 `json:"response_latency_ms"`
 	TimeToFirstTokenMs *int64 `json:"time_to_first_token_ms"`
 
+	// Concurrency slot wait (optional): accumulated wait per slot type and the acquire path, e.g. "user=fast,account=wait".
+	UserSlotWaitMs    *int64 `json:"user_slot_wait_ms"`
+	AccountSlotWaitMs *int64 `json:"account_slot_wait_ms"`
+	SlotAcquirePath   string `json:"slot_acquire_path,omitempty"`
+
 	// vNext metric semantics
 	IsBusinessLimited bool `json:"is_business_limited"`
 
@@ -101,6 +108,9 @@ type OpsErrorLogDetail struct {
 
 	// Bound (non-deleted) key prefix, snapshotted at error time; mutually exclusive with AttemptedKeyPrefix.
 	APIKeyPrefix string `json:"api_key_prefix,omitempty"`
+
+	// Whether group instructions were injected into the request that failed.
+	InstructionsInjected bool `json:"instructions_injected,omitempty"`
 }
 
 type OpsErrorLogFilter struct {
@@ -123,6 +133,7 @@ type OpsErrorLogFilter struct {
 	// Optional correlation keys for exact matching.
 	RequestID       string
 	ClientRequestID string
+	TraceID         string
 
 	// User-scoped filters (used by the user-facing error requests endpoint and
 	// by admin drill-down from the usage page).
@@ -61,6 +61,12 @@ type OpsRepository interface {
 	UpsertDailyMetrics(ctx context.Context, startTime, endTime time.Time) error
 	GetLatestHourlyBucketStart(ctx context.Context) (time.Time, bool, error)
 	GetLatestDailyBucketDate(ctx context.Context) (time.Time, bool, error)
+
+	// Dimension rollups (5m, model/account/group) used for per-dimension latency/error queries.
+	ListDimensionSamples(ctx context.Context, startTime, endTime time.Time) ([]*OpsDimensionSample, error)
+	UpsertDimensionRollups(ctx context.Context, rollups []*OpsDimensionRollup) error
+	ListDimensionRollups(ctx context.Context, dimension string, startTime, endTime time.Time) ([]*OpsDimensionRollup, error)
+	GetLatestDimensionRollupBucketStart(ctx context.Context) (time.Time, bool, error)
 }
 
 // DeletedKeyAuditResult 是按明文 key 反查 deleted_api_key_audits 的结果。
@@ -72,6 +78,8 @@ type DeletedKeyAuditResult struct {
 type OpsInsertErrorLogInput struct {
 	RequestID       string
 	ClientRequestID string
+	// TraceID 入口 trace ID（ctxkey.RequestID），不会被上游响应头覆盖
+	TraceID string
 
 	UserID    *int64
 	APIKeyID  *int64
@@ -125,6 +133,11 @@ type OpsInsertErrorLogInput struct {
 	ResponseLatencyMs  *int64
 	TimeToFirstTokenMs *int64
 
+	// 并发槽位等待（见 OpsUserSlotWaitMsKey / OpsAccountSlotWaitMsKey / OpsSlotAcquirePathKey）
+	UserSlotWaitMs    *int64
+	AccountSlotWaitMs *int64
+	SlotAcquirePath   string
+
 	CreatedAt time.Time
 
 	// 已删除 key 归因(仅 INVALID_API_KEY 认证失败时可能非空)
@@ -135,6 +148,9 @@ type OpsInsertErrorLogInput struct {
 	// 有效(未删除)key 报错时快照的 key 脱敏前缀(前 8 位);与 AttemptedKeyPrefix 互斥。
 	// 落库快照而非读时 JOIN:key 之后被删(key 列被 tombstone 覆盖)仍保留当时前缀。
 	APIKeyPrefix string
+
+	// InstructionsInjected 本次请求按分组配置注入了 instructions（见 OpsInstructionsInjectedKey）
+	InstructionsInjected bool
 }
 
 type OpsInsertSystemMetricsInput struct {
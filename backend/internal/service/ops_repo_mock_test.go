@@ -14,6 +14,11 @@ type opsRepoMock struct {
 	DeleteSystemLogsFn            func(ctx context.Context, filter *OpsSystemLogCleanupFilter) (int64, error)
 	InsertSystemLogCleanupAuditFn func(ctx context.Context, input *OpsSystemLogCleanupAudit) error
 	LookupDeletedKeyAuditFn       func(ctx context.Context, key string) (*DeletedKeyAuditResult, error)
+
+	ListDimensionSamplesFn                func(ctx context.Context, startTime, endTime time.Time) ([]*OpsDimensionSample, error)
+	UpsertDimensionRollupsFn              func(ctx context.Context, rollups []*OpsDimensionRollup) error
+	ListDimensionRollupsFn                func(ctx context.Context, dimension string, startTime, endTime time.Time) ([]*OpsDimensionRollup, error)
+	GetLatestDimensionRollupBucketStartFn func(ctx context.Context) (time.Time, bool, error)
 }
 
 func (m *opsRepoMock) InsertErrorLog(ctx context.Context, input *OpsInsertErrorLogInput) (int64, error) {
@@ -190,6 +195,34 @@ func (m *opsRepoMock) GetLatestDailyBucketDate(ctx context.Context) (time.Time,
 	return time.Time{}, false, nil
 }
 
+func (m *opsRepoMock) ListDimensionSamples(ctx context.Context, startTime, endTime time.Time) ([]*OpsDimensionSample, error) {
+	if m.ListDimensionSamplesFn != nil {
+		return m.ListDimensionSamplesFn(ctx, startTime, endTime)
+	}
+	return nil, nil
+}
+
+func (m *opsRepoMock) UpsertDimensionRollups(ctx context.Context, rollups []*OpsDimensionRollup) error {
+	if m.UpsertDimensionRollupsFn != nil {
+		return m.UpsertDimensionRollupsFn(ctx, rollups)
+	}
+	return nil
+}
+
+func (m *opsRepoMock) ListDimensionRollups(ctx context.Context, dimension string, startTime, endTime time.Time) ([]*OpsDimensionRollup, error) {
+	if m.ListDimensionRollupsFn != nil {
+		return m.ListDimensionRollupsFn(ctx, dimension, startTime, endTime)
+	}
+	return nil, nil
+}
+
+func (m *opsRepoMock) GetLatestDimensionRollupBucketStart(ctx context.Context) (time.Time, bool, error) {
+	if m.GetLatestDimensionRollupBucketStartFn != nil {
+		return m.GetLatestDimensionRollupBucketStartFn(ctx)
+	}
+	return time.Time{}, false, nil
+}
+
 func (m *opsRepoMock) LookupDeletedKeyAudit(ctx context.Context, key string) (*DeletedKeyAuditResult, error) {
 	if m.LookupDeletedKeyAuditFn != nil {
 		return m.LookupDeletedKeyAuditFn(ctx, key)
@@ -46,6 +46,9 @@ type OpsService struct {
 	// UpdateOpsAdvancedSettings 写入新配置后调用，把最新的 quota auto-pause 全局默认阈值
 	// 立即同步到调度热路径读取的内存缓存，避免下次请求才能感知新值。
 	quotaAutoPauseSink func(OpsOpenAIAccountQuotaAutoPauseSettings)
+
+	// serverErrors 由 wire 注入，用于在错误日志中标注账号的 5xx 降权状态。
+	serverErrors *AccountServerErrorTracker
 }
 
 // CleanupReloader 由 OpsCleanupService 实现。
@@ -72,6 +75,14 @@ func (s *OpsService) SetOpenAIQuotaAutoPauseSettingsSink(sink func(OpsOpenAIAcco
 	s.quotaAutoPauseSink = sink
 }
 
+// SetAccountServerErrorTracker 由 wire 注入上游 5xx 降权跟踪器。
+func (s *OpsService) SetAccountServerErrorTracker(tracker *AccountServerErrorTracker) {
+	if s == nil {
+		return
+	}
+	s.serverErrors = tracker
+}
+
 func NewOpsService(
 	opsRepo OpsRepository,
 	settingRepo SettingRepository,
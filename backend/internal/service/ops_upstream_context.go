@@ -27,6 +27,13 @@ const (
 	OpsOpenAIWSConnPickMsKey  = "ops_openai_ws_conn_pick_ms"
 	OpsOpenAIWSConnReusedKey  = "ops_openai_ws_conn_reused"
 	OpsOpenAIWSConnIDKey      = "ops_openai_ws_conn_id"
+	// 并发槽位获取观测：等待耗时（毫秒，多次获取累加）与获取路径（如 "user=fast,account=wait"），
+	// 用于区分「上游慢」与「排队慢」。
+	OpsUserSlotWaitMsKey    = "ops_user_slot_wait_ms"
+	OpsAccountSlotWaitMsKey = "ops_account_slot_wait_ms"
+	OpsSlotAcquirePathKey   = "ops_slot_acquire_path"
+	// OpsInstructionsInjectedKey 本次请求按分组配置注入了 instructions（见 ApplyGroupInstructionInjection）
+	OpsInstructionsInjectedKey = "ops_instructions_injected"
 
 	// OpsSkipPassthroughKey 由 applyErrorPassthroughRule 在命中 skip_monitoring=true 的规则时设置。
 	// ops_error_logger 中间件检查此 key，为 true 时跳过错误记录。
@@ -58,6 +65,14 @@ func IsResponseCommitted(c *gin.Context) bool {
 	return b
 }
 
+// MarkOpsInstructionsInjected 标记本次请求已注入分组 instructions
+func MarkOpsInstructionsInjected(c *gin.Context) {
+	if c == nil {
+		return
+	}
+	c.Set(OpsInstructionsInjectedKey, true)
+}
+
 func SetOpsLatencyMs(c *gin.Context, key string, value int64) {
 	if c == nil || strings.TrimSpace(key) == "" || value < 0 {
 		return
@@ -134,11 +149,17 @@ type OpsUpstreamErrorEvent struct {
 	// Best-effort upstream response capture (sanitized+trimmed).
 	UpstreamResponseBody string `json:"upstream_response_body,omitempty"`
 
-	// Kind: http_error | request_error | retry_exhausted | failover
+	// Kind: http_error | request_error | retry_exhausted | failover | timeout
 	Kind string `json:"kind,omitempty"`
 
 	Message string `json:"message,omitempty"`
 	Detail  string `json:"detail,omitempty"`
+
+	// CooldownUntilUnix 为本次上游限流实际应用到账号的冷却截止时间（Unix 秒），仅 Kind=rate_limit_cooldown 时设置。
+	CooldownUntilUnix int64 `json:"cooldown_until_unix,omitempty"`
+
+	// TimeoutTier 本次转发命中的上游超时分级（gateway.timeout_tiers），未解析分级时为空
+	TimeoutTier string `json:"timeout_tier,omitempty"`
 }
 
 func appendOpsUpstreamError(c *gin.Context, ev OpsUpstreamErrorEvent) {
@@ -155,6 +176,9 @@ func appendOpsUpstreamError(c *gin.Context, ev OpsUpstreamErrorEvent) {
 	ev.UpstreamURL = strings.TrimSpace(ev.UpstreamURL)
 	ev.Message = strings.TrimSpace(ev.Message)
 	ev.Detail = strings.TrimSpace(ev.Detail)
+	if ev.TimeoutTier == "" {
+		ev.TimeoutTier = upstreamTimeoutTierFromGin(c)
+	}
 	if ev.Message != "" {
 		ev.Message = sanitizeUpstreamErrorMessage(ev.Message)
 	}
@@ -30,6 +30,7 @@ type RateLimitService struct {
 	settingService        *SettingService
 	tokenCacheInvalidator TokenCacheInvalidator
 	runtimeBlocker        AccountRuntimeBlocker
+	serverErrors          *AccountServerErrorTracker
 	usageCacheMu          sync.RWMutex
 	usageCache            map[int64]*geminiUsageCacheEntry
 }
@@ -116,6 +117,11 @@ func (s *RateLimitService) SetAccountRuntimeBlocker(blocker AccountRuntimeBlocke
 	s.runtimeBlocker = blocker
 }
 
+// SetAccountServerErrorTracker 设置上游 5xx 降权跟踪器（可选依赖）
+func (s *RateLimitService) SetAccountServerErrorTracker(tracker *AccountServerErrorTracker) {
+	s.serverErrors = tracker
+}
+
 func (s *RateLimitService) notifyAccountSchedulingBlocked(account *Account, until time.Time, reason string) {
 	if s == nil || s.runtimeBlocker == nil || account == nil {
 		return
@@ -177,6 +183,9 @@ func (s *RateLimitService) HandleUpstreamError(ctx context.Context, account *Acc
 		return false
 	}
 
+	// 持续 5xx 的账号临时降权（不影响下方的账号状态处理）
+	s.serverErrors.Record(account.ID, statusCode)
+
 	if len(requestedModel) > 0 && s.HandleUpstreamModelNotFound(ctx, account, requestedModel[0], statusCode, responseBody) {
 		return true
 	}
@@ -938,7 +947,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 			}
 		case PlatformGemini, PlatformAntigravity:
 			// 尝试解析 Gemini 格式（用于其他平台）
-			if resetAt := ParseGeminiRateLimitResetTime(responseBody); resetAt != nil {
+			if resetAt := ParseGeminiRateLimitResetTimeWithPolicy(responseBody, geminiResetDelayPolicyFromConfig(s.cfg)); resetAt != nil {
 				resetTime := time.Unix(*resetAt, 0)
 				s.notifyAccountSchedulingBlocked(account, resetTime, "429")
 				if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetTime); err != nil {
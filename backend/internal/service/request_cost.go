@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// MaxCostHeader 请求头 X-Max-Cost 设置单次请求的费用上限（USD，含倍率）
+	MaxCostHeader = "X-Max-Cost"
+	// EstimatedCostHeader API Key 开启费用预览时返回的请求前估算费用（USD，含倍率）
+	EstimatedCostHeader = "X-Estimated-Cost"
+
+	// maxCostBodyField 请求体顶层的费用上限字段，转发上游前移除
+	maxCostBodyField = "max_cost"
+)
+
+var (
+	// ErrInvalidMaxCost max_cost / X-Max-Cost 不是正数
+	ErrInvalidMaxCost = infraerrors.BadRequest("INVALID_MAX_COST", "max_cost must be a positive number")
+	// ErrMaxCostUnsupported 设置了 max_cost 但未启用费用上限校验（gateway.request_cost.enabled=false）
+	ErrMaxCostUnsupported = infraerrors.BadRequest("MAX_COST_UNSUPPORTED", "max_cost not supported: request cost enforcement is disabled")
+	// ErrRequestCostUnavailable 设置了 max_cost 但模型没有可用价格，无法保证费用上限
+	ErrRequestCostUnavailable = infraerrors.BadRequest("REQUEST_COST_UNAVAILABLE", "max_cost cannot be enforced: no pricing available for the requested model")
+	// ErrRequestCostExceeded 估算费用超出 max_cost（handler 映射为 402）
+	ErrRequestCostExceeded = infraerrors.New(http.StatusPaymentRequired, "REQUEST_COST_EXCEEDED", "estimated request cost exceeds max_cost")
+)
+
+// RequestCostEstimate 请求前的费用估算，挂在请求 context 上供用量记录与实际费用比对。
+type RequestCostEstimate struct {
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	// Cost 估算费用（与用量记录的 actual_cost 同口径，已乘倍率）
+	Cost float64
+	// MaxCost 客户端设置的费用上限，nil 表示未设置
+	MaxCost *float64
+	// OverageFactor 实际费用超过 Cost 该倍数时标记 cost_overage
+	OverageFactor float64
+}
+
+// IsOverage 实际费用是否超出估算费用的配置倍数
+func (e *RequestCostEstimate) IsOverage(actualCost float64) bool {
+	if e == nil || e.OverageFactor < 1 {
+		return false
+	}
+	return actualCost > e.Cost*e.OverageFactor
+}
+
+// HeaderValue X-Estimated-Cost 响应头取值
+func (e *RequestCostEstimate) HeaderValue() string {
+	return strconv.FormatFloat(e.Cost, 'f', 6, 64)
+}
+
+// RequestCostService 按计费价格表（BillingService）与 token 估算器估算单次请求费用，
+// 用于 max_cost 费用上限校验与 X-Estimated-Cost 费用预览。
+type RequestCostService struct {
+	billingService        *BillingService
+	rateResolver          *userGroupRateResolver
+	cfg                   config.GatewayRequestCostConfig
+	defaultRateMultiplier float64
+}
+
+// NewRequestCostService 创建请求费用估算服务
+func NewRequestCostService(billingService *BillingService, userGroupRateRepo UserGroupRateRepository, cfg *config.Config) *RequestCostService {
+	svc := &RequestCostService{
+		billingService:        billingService,
+		rateResolver:          newUserGroupRateResolver(userGroupRateRepo, nil, resolveUserGroupRateCacheTTL(cfg), nil, "service.request_cost"),
+		defaultRateMultiplier: 1,
+	}
+	if cfg != nil {
+		svc.cfg = cfg.Gateway.RequestCost
+		svc.defaultRateMultiplier = cfg.Default.RateMultiplier
+	}
+	return svc
+}
+
+// Enabled 是否启用费用上限与费用预览
+func (s *RequestCostService) Enabled() bool {
+	return s != nil && s.billingService != nil && s.cfg.Enabled
+}
+
+// ExtractRequestMaxCost 读取 X-Max-Cost 请求头与请求体顶层 max_cost 字段（同时设置时取较小值），
+// 并从请求体中移除 max_cost，避免透传到上游。未设置时返回 nil。
+func ExtractRequestMaxCost(header string, body []byte) (*float64, []byte, error) {
+	var maxCost *float64
+	if header = strings.TrimSpace(header); header != "" {
+		v, err := strconv.ParseFloat(header, 64)
+		if err != nil || !isValidMaxCost(v) {
+			return nil, body, ErrInvalidMaxCost
+		}
+		maxCost = &v
+	}
+
+	field := gjson.GetBytes(body, maxCostBodyField)
+	if !field.Exists() {
+		return maxCost, body, nil
+	}
+	var v float64
+	switch field.Type {
+	case gjson.Number:
+		v = field.Float()
+	case gjson.String:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(field.String()), 64)
+		if err != nil {
+			return nil, body, ErrInvalidMaxCost
+		}
+		v = parsed
+	default:
+		return nil, body, ErrInvalidMaxCost
+	}
+	if !isValidMaxCost(v) {
+		return nil, body, ErrInvalidMaxCost
+	}
+	if maxCost == nil || v < *maxCost {
+		maxCost = &v
+	}
+	stripped, err := sjson.DeleteBytes(body, maxCostBodyField)
+	if err != nil {
+		return nil, body, fmt.Errorf("strip max_cost: %w", err)
+	}
+	return maxCost, stripped, nil
+}
+
+func isValidMaxCost(v float64) bool {
+	return v > 0 && !math.IsInf(v, 0) && !math.IsNaN(v)
+}
+
+// Estimate 估算本次请求费用。
+// 未设置 max_cost 且 API Key 未开启费用预览时返回 nil；估算费用超出 max_cost 时返回估算结果与 ErrRequestCostExceeded。
+// 模型没有可用价格时：设置了 max_cost 返回 ErrRequestCostUnavailable，否则不做预览。
+// 未启用费用估算但设置了 max_cost 时返回 ErrMaxCostUnsupported，避免静默放行无上限的请求。
+func (s *RequestCostService) Estimate(ctx context.Context, apiKey *APIKey, model string, body []byte, maxCost *float64) (*RequestCostEstimate, error) {
+	if ok, err := s.shouldEstimate(apiKey, maxCost); !ok {
+		return nil, err
+	}
+	outputTokens := requestedMaxOutputTokens(body)
+	if outputTokens <= 0 {
+		outputTokens = s.cfg.DefaultOutputTokens
+	}
+	return s.estimateTokenCost(ctx, apiKey, model, estimateRequestCostInputTokens(body), outputTokens, maxCost)
+}
+
+// EstimateEmbeddings 估算 Embeddings 请求费用：只计输入 token，不计输出 token。
+func (s *RequestCostService) EstimateEmbeddings(ctx context.Context, apiKey *APIKey, model string, body []byte, maxCost *float64) (*RequestCostEstimate, error) {
+	if ok, err := s.shouldEstimate(apiKey, maxCost); !ok {
+		return nil, err
+	}
+	return s.estimateTokenCost(ctx, apiKey, model, estimateEmbeddingsInputTokens(body), 0, maxCost)
+}
+
+// EstimateImages 按图片单价 × 张数估算 Images 请求费用，与图片计费共用价格表（分组图片价格优先，回退模型默认价格）。
+// 渠道级按次 / 图片定价不参与预估。
+func (s *RequestCostService) EstimateImages(ctx context.Context, apiKey *APIKey, model, sizeTier string, count int, maxCost *float64) (*RequestCostEstimate, error) {
+	if ok, err := s.shouldEstimate(apiKey, maxCost); !ok {
+		return nil, err
+	}
+	if count <= 0 {
+		count = 1
+	}
+	var groupConfig *ImagePriceConfig
+	if apiKey.Group != nil {
+		groupConfig = &ImagePriceConfig{
+			Price1K: apiKey.Group.ImagePrice1K,
+			Price2K: apiKey.Group.ImagePrice2K,
+			Price4K: apiKey.Group.ImagePrice4K,
+		}
+	}
+	cost := s.billingService.CalculateImageCost(model, sizeTier, count, groupConfig, s.rateMultiplier(ctx, apiKey))
+	return s.checkMaxCost(&RequestCostEstimate{
+		Model:         model,
+		Cost:          cost.ActualCost,
+		MaxCost:       maxCost,
+		OverageFactor: s.cfg.OverageFactor,
+	})
+}
+
+// shouldEstimate 设置了 max_cost 或 API Key 开启费用预览时才需要估算；
+// 未启用估算时费用预览直接跳过，设置了 max_cost 则返回 ErrMaxCostUnsupported。
+func (s *RequestCostService) shouldEstimate(apiKey *APIKey, maxCost *float64) (bool, error) {
+	if !s.Enabled() {
+		if maxCost != nil {
+			return false, ErrMaxCostUnsupported
+		}
+		return false, nil
+	}
+	if apiKey == nil {
+		return false, nil
+	}
+	return maxCost != nil || apiKey.CostPreviewEnabled, nil
+}
+
+// estimateTokenCost 按 token 价格表估算费用
+func (s *RequestCostService) estimateTokenCost(ctx context.Context, apiKey *APIKey, model string, inputTokens, outputTokens int, maxCost *float64) (*RequestCostEstimate, error) {
+	estimate := &RequestCostEstimate{
+		Model:         model,
+		InputTokens:   inputTokens,
+		OutputTokens:  outputTokens,
+		MaxCost:       maxCost,
+		OverageFactor: s.cfg.OverageFactor,
+	}
+
+	cost, err := s.billingService.CalculateCost(model, UsageTokens{
+		InputTokens:  estimate.InputTokens,
+		OutputTokens: estimate.OutputTokens,
+	}, s.rateMultiplier(ctx, apiKey))
+	if err != nil {
+		if maxCost != nil {
+			if errors.Is(err, ErrModelPricingUnavailable) {
+				return nil, ErrRequestCostUnavailable
+			}
+			return nil, ErrRequestCostUnavailable.WithCause(err)
+		}
+		return nil, nil
+	}
+	estimate.Cost = cost.ActualCost
+	return s.checkMaxCost(estimate)
+}
+
+// checkMaxCost 估算费用超出 max_cost 时返回 ErrRequestCostExceeded
+func (s *RequestCostService) checkMaxCost(estimate *RequestCostEstimate) (*RequestCostEstimate, error) {
+	if estimate.MaxCost != nil && estimate.Cost > *estimate.MaxCost {
+		return estimate, ErrRequestCostExceeded
+	}
+	return estimate, nil
+}
+
+// rateMultiplier 与用量记录相同的倍率口径：分组默认倍率（可被用户专属倍率覆盖），无分组时使用全局默认倍率。
+func (s *RequestCostService) rateMultiplier(ctx context.Context, apiKey *APIKey) float64 {
+	if apiKey.GroupID == nil || apiKey.Group == nil {
+		return s.defaultRateMultiplier
+	}
+	userID := apiKey.UserID
+	if apiKey.User != nil {
+		userID = apiKey.User.ID
+	}
+	return s.rateResolver.Resolve(ctx, userID, *apiKey.GroupID, apiKey.Group.RateMultiplier)
+}
+
+// estimateRequestCostInputTokens 按请求体结构估算输入 token，兼容 Anthropic Messages、
+// OpenAI Chat Completions / Responses 与 Gemini generateContent 请求；工具定义按原始 JSON 计入。
+func estimateRequestCostInputTokens(body []byte) int {
+	total := 0
+	switch {
+	case gjson.GetBytes(body, "messages").IsArray():
+		// 顶层 instructions：Chat Completions 兼容字段或分组注入的 instructions
+		total += estimateTokensForText(gjson.GetBytes(body, "instructions").String())
+		system := gjson.GetBytes(body, "system")
+		if system.Type == gjson.String {
+			total += estimateTokensForText(system.String())
+		} else {
+			system.ForEach(func(_, block gjson.Result) bool {
+				total += estimateTokensForText(block.Get("text").String())
+				return true
+			})
+		}
+		gjson.GetBytes(body, "messages").ForEach(func(_, msg gjson.Result) bool {
+			total += estimateChatMessageTokens(msg)
+			return true
+		})
+	case gjson.GetBytes(body, "contents").IsArray():
+		total += estimateGeminiCountTokens(body)
+	default:
+		total += estimateOpenAIResponsesInputTokens(body)
+	}
+	if tools := gjson.GetBytes(body, "tools"); tools.IsArray() {
+		total += estimateTokensForText(tools.Raw)
+	}
+	return total
+}
+
+// estimateEmbeddingsInputTokens 估算 Embeddings 请求的输入 token：input 为字符串、字符串数组，
+// 或 token id 数组（一维 / 二维，按元素个数计）。
+func estimateEmbeddingsInputTokens(body []byte) int {
+	input := gjson.GetBytes(body, "input")
+	if input.Type == gjson.String {
+		return estimateTokensForText(input.String())
+	}
+	total := 0
+	input.ForEach(func(_, item gjson.Result) bool {
+		switch {
+		case item.Type == gjson.String:
+			total += estimateTokensForText(item.String())
+		case item.Type == gjson.Number:
+			total++
+		case item.IsArray():
+			total += len(item.Array())
+		}
+		return true
+	})
+	return total
+}
+
+// estimateChatMessageTokens 估算单条 Anthropic / Chat Completions 消息：文本内容、工具调用参数与工具结果。
+func estimateChatMessageTokens(msg gjson.Result) int {
+	total := 0
+	content := msg.Get("content")
+	if content.Type == gjson.String {
+		total += estimateTokensForText(content.String())
+	} else {
+		content.ForEach(func(_, part gjson.Result) bool {
+			total += estimateTokensForText(part.Get("text").String())
+			if input := part.Get("input"); input.Exists() {
+				total += estimateTokensForText(input.Raw)
+			}
+			if result := part.Get("content"); result.Type == gjson.String {
+				total += estimateTokensForText(result.String())
+			} else {
+				result.ForEach(func(_, block gjson.Result) bool {
+					total += estimateTokensForText(block.Get("text").String())
+					return true
+				})
+			}
+			return true
+		})
+	}
+	msg.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+		total += estimateTokensForText(call.Get("function.arguments").String())
+		return true
+	})
+	return total
+}
+
+// requestedMaxOutputTokens 读取请求声明的最大输出 token（max_tokens / max_completion_tokens /
+// max_output_tokens / generationConfig.maxOutputTokens），未设置时返回 0。
+func requestedMaxOutputTokens(body []byte) int {
+	for _, path := range []string{"max_tokens", "max_completion_tokens", "max_output_tokens", "generationConfig.maxOutputTokens"} {
+		if v := gjson.GetBytes(body, path).Int(); v > 0 {
+			return int(v)
+		}
+	}
+	return 0
+}
+
+type requestCostEstimateContextKey struct{}
+
+// WithRequestCostEstimate 在请求 context 上挂载费用估算
+func WithRequestCostEstimate(ctx context.Context, estimate *RequestCostEstimate) context.Context {
+	if ctx == nil || estimate == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestCostEstimateContextKey{}, estimate)
+}
+
+// RequestCostEstimateFromContext 读取请求 context 上的费用估算
+func RequestCostEstimateFromContext(ctx context.Context) *RequestCostEstimate {
+	if ctx == nil {
+		return nil
+	}
+	estimate, _ := ctx.Value(requestCostEstimateContextKey{}).(*RequestCostEstimate)
+	return estimate
+}
+
+// CopyRequestCostEstimate 将 src 上的费用估算带到 dst（用于异步用量记录的 context）
+func CopyRequestCostEstimate(dst, src context.Context) context.Context {
+	if dst == nil || src == nil {
+		return dst
+	}
+	return WithRequestCostEstimate(dst, RequestCostEstimateFromContext(src))
+}
+
+// applyRequestCostEstimate 将请求前估算写入用量记录，并按实际费用标记 cost_overage
+func applyRequestCostEstimate(ctx context.Context, usageLog *UsageLog) {
+	estimate := RequestCostEstimateFromContext(ctx)
+	if estimate == nil || usageLog == nil {
+		return
+	}
+	cost := estimate.Cost
+	usageLog.EstimatedCost = &cost
+	usageLog.CostOverage = estimate.IsOverage(usageLog.ActualCost)
+}
@@ -0,0 +1,179 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newTestRequestCostService() *RequestCostService {
+	cfg := &config.Config{}
+	cfg.Default.RateMultiplier = 1
+	cfg.Gateway.RequestCost = config.GatewayRequestCostConfig{
+		Enabled:             true,
+		DefaultOutputTokens: 4096,
+		OverageFactor:       2,
+	}
+	return NewRequestCostService(NewBillingService(cfg, nil), nil, cfg)
+}
+
+func TestExtractRequestMaxCost(t *testing.T) {
+	maxCost, body, err := ExtractRequestMaxCost("", []byte(`{"model":"m"}`))
+	require.NoError(t, err)
+	require.Nil(t, maxCost)
+	require.JSONEq(t, `{"model":"m"}`, string(body))
+
+	maxCost, _, err = ExtractRequestMaxCost(" 0.5 ", []byte(`{"model":"m"}`))
+	require.NoError(t, err)
+	require.InDelta(t, 0.5, *maxCost, 1e-12)
+
+	// 请求体字段被移除；请求头与请求体同时设置时取较小值
+	maxCost, body, err = ExtractRequestMaxCost("0.5", []byte(`{"model":"m","max_cost":0.2}`))
+	require.NoError(t, err)
+	require.InDelta(t, 0.2, *maxCost, 1e-12)
+	require.False(t, gjson.GetBytes(body, "max_cost").Exists())
+
+	maxCost, body, err = ExtractRequestMaxCost("", []byte(`{"model":"m","max_cost":"1.5"}`))
+	require.NoError(t, err)
+	require.InDelta(t, 1.5, *maxCost, 1e-12)
+	require.JSONEq(t, `{"model":"m"}`, string(body))
+
+	for _, tc := range []struct {
+		header string
+		body   string
+	}{
+		{header: "abc", body: `{}`},
+		{header: "0", body: `{}`},
+		{header: "-1", body: `{}`},
+		{body: `{"max_cost":0}`},
+		{body: `{"max_cost":"x"}`},
+		{body: `{"max_cost":true}`},
+	} {
+		_, _, err := ExtractRequestMaxCost(tc.header, []byte(tc.body))
+		require.ErrorIs(t, err, ErrInvalidMaxCost, "header=%q body=%s", tc.header, tc.body)
+	}
+}
+
+func TestRequestCostService_EstimateRejectsOverMaxCost(t *testing.T) {
+	svc := newTestRequestCostService()
+	apiKey := &APIKey{ID: 1}
+	body := []byte(`{"model":"claude-sonnet-4","max_tokens":1000,"messages":[{"role":"user","content":"hello"}]}`)
+
+	// 输出按 max_tokens 估算：1000 * $15/MTok = $0.015
+	tight := 0.01
+	estimate, err := svc.Estimate(context.Background(), apiKey, "claude-sonnet-4", body, &tight)
+	require.ErrorIs(t, err, ErrRequestCostExceeded)
+	require.NotNil(t, estimate)
+	require.Equal(t, 1000, estimate.OutputTokens)
+	require.Greater(t, estimate.Cost, 0.015)
+
+	loose := 1.0
+	estimate, err = svc.Estimate(context.Background(), apiKey, "claude-sonnet-4", body, &loose)
+	require.NoError(t, err)
+	require.NotNil(t, estimate)
+	require.Less(t, estimate.Cost, loose)
+
+	// 设置了上限但模型无价格：拒绝而不是放行
+	_, err = svc.Estimate(context.Background(), apiKey, "no-such-model-xyz", body, &loose)
+	require.ErrorIs(t, err, ErrRequestCostUnavailable)
+}
+
+func TestRequestCostService_EstimatePreview(t *testing.T) {
+	svc := newTestRequestCostService()
+	body := []byte(`{"model":"claude-sonnet-4","messages":[{"role":"user","content":"hello"}]}`)
+
+	// 未开启预览且未设置上限：不估算
+	estimate, err := svc.Estimate(context.Background(), &APIKey{ID: 1}, "claude-sonnet-4", body, nil)
+	require.NoError(t, err)
+	require.Nil(t, estimate)
+
+	// 开启预览：未声明 max_tokens 时按默认输出 token 估算
+	estimate, err = svc.Estimate(context.Background(), &APIKey{ID: 1, CostPreviewEnabled: true}, "claude-sonnet-4", body, nil)
+	require.NoError(t, err)
+	require.NotNil(t, estimate)
+	require.Equal(t, 4096, estimate.OutputTokens)
+	require.Greater(t, estimate.InputTokens, 0)
+
+	// 无价格时不预览也不报错
+	estimate, err = svc.Estimate(context.Background(), &APIKey{ID: 1, CostPreviewEnabled: true}, "no-such-model-xyz", body, nil)
+	require.NoError(t, err)
+	require.Nil(t, estimate)
+
+	// 功能关闭时不预览；设置了上限则拒绝，避免无上限放行
+	disabled := NewRequestCostService(NewBillingService(&config.Config{}, nil), nil, &config.Config{})
+	estimate, err = disabled.Estimate(context.Background(), &APIKey{ID: 1, CostPreviewEnabled: true}, "claude-sonnet-4", body, nil)
+	require.NoError(t, err)
+	require.Nil(t, estimate)
+	tight := 0.000001
+	estimate, err = disabled.Estimate(context.Background(), &APIKey{ID: 1}, "claude-sonnet-4", body, &tight)
+	require.ErrorIs(t, err, ErrMaxCostUnsupported)
+	require.Nil(t, estimate)
+	_, err = disabled.EstimateEmbeddings(context.Background(), &APIKey{ID: 1}, "m", body, &tight)
+	require.ErrorIs(t, err, ErrMaxCostUnsupported)
+	_, err = disabled.EstimateImages(context.Background(), &APIKey{ID: 1}, "m", "1K", 1, &tight)
+	require.ErrorIs(t, err, ErrMaxCostUnsupported)
+}
+
+func TestRequestCostService_EstimateEmbeddings(t *testing.T) {
+	svc := newTestRequestCostService()
+	apiKey := &APIKey{ID: 1, CostPreviewEnabled: true}
+
+	// 不计输出 token；字符串数组与 token id 数组都计入输入
+	estimate, err := svc.EstimateEmbeddings(context.Background(), apiKey, "doubao-embedding-vision", []byte(`{"input":["hello world","foo bar"]}`), nil)
+	require.NoError(t, err)
+	require.NotNil(t, estimate)
+	require.Zero(t, estimate.OutputTokens)
+	require.Greater(t, estimate.InputTokens, 0)
+
+	require.Equal(t, 5, estimateEmbeddingsInputTokens([]byte(`{"input":[[1,2,3],[4,5]]}`)))
+	require.Equal(t, 3, estimateEmbeddingsInputTokens([]byte(`{"input":[1,2,3]}`)))
+}
+
+func TestRequestCostService_EstimateImages(t *testing.T) {
+	svc := newTestRequestCostService()
+	price := 0.1
+	apiKey := &APIKey{ID: 1, CostPreviewEnabled: true, Group: &Group{ImagePrice1K: &price}}
+
+	// 与图片计费同一价格表：分组 1K 单价 × 张数
+	estimate, err := svc.EstimateImages(context.Background(), apiKey, "gpt-image-1", "1K", 3, nil)
+	require.NoError(t, err)
+	require.NotNil(t, estimate)
+	require.InDelta(t, 0.3, estimate.Cost, 1e-9)
+
+	tight := 0.2
+	_, err = svc.EstimateImages(context.Background(), &APIKey{ID: 1, Group: apiKey.Group}, "gpt-image-1", "1K", 3, &tight)
+	require.ErrorIs(t, err, ErrRequestCostExceeded)
+
+	// 未开启预览且未设置上限：不估算
+	estimate, err = svc.EstimateImages(context.Background(), &APIKey{ID: 1}, "gpt-image-1", "1K", 1, nil)
+	require.NoError(t, err)
+	require.Nil(t, estimate)
+}
+
+func TestApplyRequestCostEstimate_FlagsOverage(t *testing.T) {
+	ctx := WithRequestCostEstimate(context.Background(), &RequestCostEstimate{Cost: 0.01, OverageFactor: 2})
+
+	withinFactor := &UsageLog{ActualCost: 0.02}
+	applyRequestCostEstimate(ctx, withinFactor)
+	require.NotNil(t, withinFactor.EstimatedCost)
+	require.InDelta(t, 0.01, *withinFactor.EstimatedCost, 1e-12)
+	require.False(t, withinFactor.CostOverage)
+
+	over := &UsageLog{ActualCost: 0.025}
+	applyRequestCostEstimate(ctx, over)
+	require.True(t, over.CostOverage)
+
+	// 异步用量记录 context 继承估算
+	copied := CopyRequestCostEstimate(context.Background(), ctx)
+	require.Same(t, RequestCostEstimateFromContext(ctx), RequestCostEstimateFromContext(copied))
+
+	untouched := &UsageLog{ActualCost: 1}
+	applyRequestCostEstimate(context.Background(), untouched)
+	require.Nil(t, untouched.EstimatedCost)
+	require.False(t, untouched.CostOverage)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"sync/atomic"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 )
@@ -18,6 +19,7 @@ type RequestMetadata struct {
 	PrefetchedStickyGroupID    *int64
 	SingleAccountRetry         *bool
 	AccountSwitchCount         *int
+	StickySessionTTL           *time.Duration
 }
 
 var (
@@ -116,6 +118,15 @@ func WithAccountSwitchCount(ctx context.Context, value int, bridgeOldKeys bool)
 	})
 }
 
+// WithStickySessionTTL 设置本次请求粘性会话绑定的有效期（来自 X-Session-Affinity-TTL）。
+// 仅存在于 RequestMetadata，无旧 ctxkey 兼容键。
+func WithStickySessionTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return updateRequestMetadata(ctx, false, func(md *RequestMetadata) {
+		v := ttl
+		md.StickySessionTTL = &v
+	}, nil)
+}
+
 func IsMaxTokensOneHaikuRequestFromContext(ctx context.Context) (bool, bool) {
 	if md := metadataFromContext(ctx); md != nil && md.IsMaxTokensOneHaikuRequest != nil {
 		return *md.IsMaxTokensOneHaikuRequest, true
@@ -214,3 +225,11 @@ func AccountSwitchCountFromContext(ctx context.Context) (int, bool) {
 	}
 	return 0, false
 }
+
+// StickySessionTTLFromContext 返回请求级粘性会话有效期，未设置时返回 false。
+func StickySessionTTLFromContext(ctx context.Context) (time.Duration, bool) {
+	if md := metadataFromContext(ctx); md != nil && md.StickySessionTTL != nil && *md.StickySessionTTL > 0 {
+		return *md.StickySessionTTL, true
+	}
+	return 0, false
+}
@@ -11,3 +11,11 @@ func compileResponseHeaderFilter(cfg *config.Config) *responseheaders.CompiledHe
 	}
 	return responseheaders.CompileHeaderFilter(cfg.Security.ResponseHeaders)
 }
+
+// compileResponseHeaderFilterForPlatform 编译指定上游平台的响应头过滤器（含平台白名单）
+func compileResponseHeaderFilterForPlatform(cfg *config.Config, platform string) *responseheaders.CompiledHeaderFilter {
+	if cfg == nil {
+		return nil
+	}
+	return responseheaders.CompileHeaderFilterForPlatform(cfg.Security.ResponseHeaders, platform)
+}
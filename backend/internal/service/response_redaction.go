@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const defaultResponseRedactionMaxFrameBytes = 1 << 20
+
+// responseRedactor 对上游响应内容应用 JSON 路径改写规则（security.response_redaction）。
+// 流式响应逐帧处理（不缓冲整个流），帧边界与事件顺序保持不变；nil 表示不改写。
+type responseRedactor struct {
+	platform      string
+	rules         []config.ResponseRedactionRule
+	maxFrameBytes int
+}
+
+// compileResponseRedactor 编译指定平台的改写规则；未启用或该平台未配置规则时返回 nil。
+// 不内置默认规则：上游响应中的账号标识随平台与接口而异，需按实际响应显式配置。
+func compileResponseRedactor(cfg *config.Config, platform string) *responseRedactor {
+	if cfg == nil || !cfg.Security.ResponseRedaction.Enabled {
+		return nil
+	}
+	rules := cfg.Security.ResponseRedaction.Platforms[platform]
+	if len(rules) == 0 {
+		return nil
+	}
+	maxFrameBytes := cfg.Security.ResponseRedaction.MaxFrameBytes
+	if maxFrameBytes <= 0 {
+		maxFrameBytes = defaultResponseRedactionMaxFrameBytes
+	}
+	return &responseRedactor{platform: platform, rules: rules, maxFrameBytes: maxFrameBytes}
+}
+
+// RedactBody 改写非流式 JSON 响应体
+func (r *responseRedactor) RedactBody(body []byte) []byte {
+	if r == nil {
+		return body
+	}
+	return r.redactPayload(body)
+}
+
+// RedactSSE 改写一段 SSE 输出（单行或完整事件块）中的每个 data 行，其余行与换行原样保留
+func (r *responseRedactor) RedactSSE(chunk string) string {
+	if r == nil || !strings.Contains(chunk, "data:") {
+		return chunk
+	}
+	lines := strings.SplitAfter(chunk, "\n")
+	changed := false
+	for i, line := range lines {
+		body := strings.TrimRight(line, "\r\n")
+		if !strings.HasPrefix(body, "data:") {
+			continue
+		}
+		payload := strings.TrimPrefix(body, "data:")
+		prefix := "data:"
+		if strings.HasPrefix(payload, " ") {
+			payload = payload[1:]
+			prefix = "data: "
+		}
+		redacted := r.redactPayload([]byte(payload))
+		if string(redacted) == payload {
+			continue
+		}
+		lines[i] = prefix + string(redacted) + line[len(body):]
+		changed = true
+	}
+	if !changed {
+		return chunk
+	}
+	return strings.Join(lines, "")
+}
+
+func (r *responseRedactor) redactPayload(payload []byte) []byte {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return payload
+	}
+	if len(payload) > r.maxFrameBytes {
+		slog.Warn("response_redaction.frame_too_large",
+			"platform", r.platform,
+			"size", len(payload),
+			"max_frame_bytes", r.maxFrameBytes,
+		)
+		return payload
+	}
+	out := payload
+	for _, rule := range r.rules {
+		value := gjson.GetBytes(out, rule.Path)
+		if !value.Exists() {
+			continue
+		}
+		var (
+			next []byte
+			err  error
+		)
+		switch rule.Action {
+		case config.ResponseRedactionActionDelete:
+			next, err = sjson.DeleteBytes(out, rule.Path)
+		case config.ResponseRedactionActionBlank:
+			if value.Type == gjson.String && value.Str == "" {
+				continue
+			}
+			next, err = sjson.SetBytes(out, rule.Path, "")
+		case config.ResponseRedactionActionReplacePrefix:
+			if value.Type != gjson.String || !strings.HasPrefix(value.Str, rule.From) {
+				continue
+			}
+			next, err = sjson.SetBytes(out, rule.Path, rule.To+strings.TrimPrefix(value.Str, rule.From))
+		default:
+			continue
+		}
+		if err == nil {
+			out = next
+		}
+	}
+	return out
+}
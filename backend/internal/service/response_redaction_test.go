@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedOpenAIResponsesSSE 录制的 Responses 流（已脱敏），每个元素为一次写出的 SSE 事件块
+var recordedOpenAIResponsesSSE = []string{
+	"event: response.created\ndata: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_abc123\",\"organization\":\"org-upstream42\",\"status\":\"in_progress\"}}\n\n",
+	"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"item_id\":\"msg_1\",\"delta\":\"Hello\"}\n\n",
+	": keepalive\n\n",
+	"event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_abc123\",\"organization\":\"org-upstream42\",\"usage\":{\"input_tokens\":3,\"output_tokens\":1}}}\n\n",
+	"data: [DONE]\n\n",
+}
+
+func newTestResponseRedactor(t *testing.T, platform string, rules map[string][]config.ResponseRedactionRule, maxFrameBytes int) *responseRedactor {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Security.ResponseRedaction = config.ResponseRedactionConfig{Enabled: true, MaxFrameBytes: maxFrameBytes, Platforms: rules}
+	redactor := compileResponseRedactor(cfg, platform)
+	require.NotNil(t, redactor)
+	return redactor
+}
+
+func TestCompileResponseRedactor(t *testing.T) {
+	require.Nil(t, compileResponseRedactor(nil, PlatformOpenAI))
+	require.Nil(t, compileResponseRedactor(&config.Config{}, PlatformOpenAI), "disabled by default")
+
+	cfg := &config.Config{}
+	cfg.Security.ResponseRedaction.Enabled = true
+	require.Nil(t, compileResponseRedactor(cfg, PlatformOpenAI), "no built-in rules")
+	require.Nil(t, compileResponseRedactor(cfg, PlatformAnthropic), "no built-in rules")
+
+	cfg.Security.ResponseRedaction.Platforms = map[string][]config.ResponseRedactionRule{
+		PlatformOpenAI: {{Path: "id", Action: config.ResponseRedactionActionBlank}},
+		PlatformGemini: {},
+	}
+	require.NotNil(t, compileResponseRedactor(cfg, PlatformOpenAI))
+	require.Nil(t, compileResponseRedactor(cfg, PlatformGemini), "explicit empty list disables rewriting")
+
+	var nilRedactor *responseRedactor
+	require.Equal(t, "data: {\"organization\":\"x\"}\n", nilRedactor.RedactSSE("data: {\"organization\":\"x\"}\n"))
+	require.Equal(t, []byte(`{"organization":"x"}`), nilRedactor.RedactBody([]byte(`{"organization":"x"}`)))
+}
+
+func TestResponseRedactor_RecordedSSEFixture(t *testing.T) {
+	redactor := newTestResponseRedactor(t, PlatformOpenAI, map[string][]config.ResponseRedactionRule{
+		PlatformOpenAI: {
+			{Path: "response.organization", Action: config.ResponseRedactionActionDelete},
+			{Path: "response.id", Action: config.ResponseRedactionActionReplacePrefix, From: "resp_", To: "resp_gw_"},
+			{Path: "item_id", Action: config.ResponseRedactionActionBlank},
+		},
+	}, 0)
+
+	out := make([]string, 0, len(recordedOpenAIResponsesSSE))
+	for _, chunk := range recordedOpenAIResponsesSSE {
+		out = append(out, redactor.RedactSSE(chunk))
+	}
+
+	require.Equal(t, []string{
+		"event: response.created\ndata: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_gw_abc123\",\"status\":\"in_progress\"}}\n\n",
+		"event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"item_id\":\"\",\"delta\":\"Hello\"}\n\n",
+		": keepalive\n\n",
+		"event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_gw_abc123\",\"usage\":{\"input_tokens\":3,\"output_tokens\":1}}}\n\n",
+		"data: [DONE]\n\n",
+	}, out)
+
+	// 按行写出的转发器（OpenAI/Anthropic passthrough）逐行处理，结果与按块处理一致
+	for i, chunk := range recordedOpenAIResponsesSSE {
+		var lines []string
+		for _, line := range strings.SplitAfter(chunk, "\n") {
+			lines = append(lines, redactor.RedactSSE(line))
+		}
+		require.Equal(t, out[i], strings.Join(lines, ""))
+	}
+}
+
+func TestResponseRedactor_PreservesDataPrefixAndCRLF(t *testing.T) {
+	redactor := newTestResponseRedactor(t, PlatformAnthropic, map[string][]config.ResponseRedactionRule{
+		PlatformAnthropic: {{Path: "message.organization", Action: config.ResponseRedactionActionDelete}},
+	}, 0)
+
+	require.Equal(t, "data:{\"message\":{\"id\":\"m\"}}\r\n\r\n",
+		redactor.RedactSSE("data:{\"message\":{\"id\":\"m\",\"organization\":\"org-1\"}}\r\n\r\n"))
+	require.Equal(t, "event: ping\ndata: not-json\n\n", redactor.RedactSSE("event: ping\ndata: not-json\n\n"))
+}
+
+func TestResponseRedactor_OversizedFramePassesThrough(t *testing.T) {
+	redactor := newTestResponseRedactor(t, PlatformOpenAI, map[string][]config.ResponseRedactionRule{
+		PlatformOpenAI: {{Path: "organization", Action: config.ResponseRedactionActionDelete}},
+	}, 64)
+
+	small := `{"organization":"org-1","id":"x"}`
+	require.Equal(t, `{"id":"x"}`, string(redactor.RedactBody([]byte(small))))
+
+	large := `{"organization":"org-1","delta":"` + strings.Repeat("a", 128) + `"}`
+	require.Equal(t, large, string(redactor.RedactBody([]byte(large))))
+	require.Equal(t, "data: "+large+"\n", redactor.RedactSSE("data: "+large+"\n"))
+}
+
+func TestOpenAIStreamingResponse_AppliesRedaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		Gateway: config.GatewayConfig{
+			StreamDataIntervalTimeout: 0,
+			StreamKeepaliveInterval:   0,
+			MaxLineSize:               defaultMaxLineSize,
+		},
+	}
+	cfg.Security.ResponseRedaction = config.ResponseRedactionConfig{
+		Enabled: true,
+		Platforms: map[string][]config.ResponseRedactionRule{
+			PlatformOpenAI: {{Path: "response.organization", Action: config.ResponseRedactionActionDelete}},
+		},
+	}
+	svc := &OpenAIGatewayService{cfg: cfg, responseRedactor: compileResponseRedactor(cfg, PlatformOpenAI)}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(strings.Join(recordedOpenAIResponsesSSE, ""))),
+		Header:     http.Header{},
+	}
+
+	result, err := svc.handleStreamingResponse(context.Background(), resp, c, &Account{ID: 1}, time.Now(), "model", "model")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 3, result.usage.InputTokens, "usage is parsed from the upstream frame")
+
+	body := rec.Body.String()
+	require.NotContains(t, body, "org-upstream42")
+	require.Contains(t, body, `"id":"resp_abc123"`)
+	require.Less(t, strings.Index(body, "response.created"), strings.Index(body, "response.output_text.delta"))
+	require.Less(t, strings.Index(body, "response.output_text.delta"), strings.Index(body, "response.completed"))
+}
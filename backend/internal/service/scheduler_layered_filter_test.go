@@ -99,6 +99,18 @@ func TestFilterByMinLoadRate(t *testing.T) {
 	})
 }
 
+func TestFilterBySoftPredicate(t *testing.T) {
+	accounts := []accountWithLoad{
+		{account: &Account{ID: 1}, loadInfo: &AccountLoadInfo{AccountID: 1}},
+		{account: &Account{ID: 2}, loadInfo: &AccountLoadInfo{AccountID: 2, LoadRate: 50}},
+	}
+	filtered := filterBySoftPredicate(accounts, func(accountID int64) bool { return accountID == 1 })
+	require.Len(t, filtered, 1)
+	require.EqualValues(t, 2, filtered[0].account.ID)
+	// 全部命中时不剔除，避免无号可用
+	require.Len(t, filterBySoftPredicate(accounts, func(int64) bool { return true }), 2)
+}
+
 func TestSelectByLRU(t *testing.T) {
 	now := time.Now()
 	earlier := now.Add(-1 * time.Hour)
@@ -198,6 +198,7 @@ type SystemSettings struct {
 	ClaudeOAuthSystemPromptBlocks          string // Claude OAuth mimic 路径注入的 system blocks JSON 配置；空值使用内置默认
 	EnableAnthropicCacheTTL1hInjection     bool   // 是否对 Anthropic OAuth/SetupToken 请求体注入 1h cache_control ttl（默认 false）
 	RewriteMessageCacheControl             bool   // 是否改写 messages[*].content[*].cache_control（默认 false）
+	RateLimitHeadersPerKey                 bool   // 限流响应头是否按 API Key 改写（默认 false：透传上游账号的限流头）
 	AntigravityUserAgentVersion            string // Antigravity 上游 User-Agent 版本号；空值使用配置/默认值
 	OpenAICodexUserAgent                   string // OpenAI Codex 上游完整 User-Agent；空值使用内置默认
 	MinCodexVersion                        string // codex_cli_only 最低 Codex 引擎版本；空=不检查
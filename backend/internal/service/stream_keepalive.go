@@ -0,0 +1,42 @@
+package service
+
+import "github.com/gin-gonic/gin"
+
+const streamKeepalivePingContextKey = "stream_keepalive_ping"
+
+const (
+	// anthropicStreamKeepalivePing Anthropic 原生 ping 事件（Claude 路由未绑定格式时的默认值）
+	anthropicStreamKeepalivePing = "event: ping\ndata: {\"type\": \"ping\"}\n\n"
+	// openAIStreamKeepalivePing SSE 注释 ping（OpenAI 路由未绑定格式时的默认值）
+	openAIStreamKeepalivePing = ":\n\n"
+)
+
+// BindStreamKeepalivePing 在流式响应开始前绑定本请求的 keepalive ping 字节，
+// 使 handler 等待阶段的 ping 与 service 流中 keepalive 使用同一格式；空串表示不发送 ping。
+func BindStreamKeepalivePing(c *gin.Context, ping string) {
+	if c == nil {
+		return
+	}
+	c.Set(streamKeepalivePingContextKey, ping)
+}
+
+// BoundStreamKeepalivePing 返回已绑定的 keepalive ping 字节及是否已绑定。
+func BoundStreamKeepalivePing(c *gin.Context) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	v, ok := c.Get(streamKeepalivePingContextKey)
+	if !ok {
+		return "", false
+	}
+	ping, ok := v.(string)
+	return ping, ok
+}
+
+// streamKeepalivePing 返回流中 keepalive 应写出的字节；未绑定时使用调用方的默认格式。
+func streamKeepalivePing(c *gin.Context, fallback string) string {
+	if ping, ok := BoundStreamKeepalivePing(c); ok {
+		return ping
+	}
+	return fallback
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func runOpenAIStreamKeepaliveForTest(t *testing.T, bind func(c *gin.Context), upstream func(w *io.PipeWriter)) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{
+		Gateway: config.GatewayConfig{
+			StreamKeepaliveInterval: 1,
+			MaxLineSize:             defaultMaxLineSize,
+		},
+	}}
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+	if bind != nil {
+		bind(c)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer func() { _ = pw.Close() }()
+		upstream(pw)
+	}()
+	resp := &http.Response{StatusCode: http.StatusOK, Body: pr, Header: http.Header{}}
+
+	_, err := svc.handleStreamingResponse(c.Request.Context(), resp, c, &Account{ID: 1, Platform: PlatformOpenAI}, time.Now(), "gpt-5.1", "gpt-5.1")
+	require.NoError(t, err)
+	return rec.Body.String()
+}
+
+// silentThenStreamingUpstream 先静默超过一个 keepalive 周期，然后连续输出（间隔小于 keepalive 周期）。
+func silentThenStreamingUpstream(w *io.PipeWriter) {
+	time.Sleep(1200 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		_, _ = w.Write([]byte("data: {\"type\":\"response.output_text.delta\",\"delta\":\"hi\"}\n\n"))
+		time.Sleep(300 * time.Millisecond)
+	}
+	_, _ = w.Write([]byte("data: {\"type\":\"response.completed\",\"response\":{\"usage\":{\"input_tokens\":1,\"output_tokens\":5}}}\n\n"))
+}
+
+func TestStreamKeepalive_BoundFormatUsedMidStreamAndStopsAfterFirstByte(t *testing.T) {
+	const claudePing = "data: {\"type\": \"ping\"}\n\n"
+	body := runOpenAIStreamKeepaliveForTest(t, func(c *gin.Context) {
+		BindStreamKeepalivePing(c, claudePing)
+	}, silentThenStreamingUpstream)
+
+	require.True(t, strings.HasPrefix(body, claudePing), "body=%q", body)
+	require.Equal(t, 1, strings.Count(body, claudePing))
+	require.NotContains(t, body, openAIStreamKeepalivePing)
+	require.Contains(t, body, "response.completed")
+}
+
+func TestStreamKeepalive_UnboundUsesRouteDefault(t *testing.T) {
+	body := runOpenAIStreamKeepaliveForTest(t, nil, silentThenStreamingUpstream)
+
+	require.True(t, strings.HasPrefix(body, openAIStreamKeepalivePing), "body=%q", body)
+	require.Equal(t, 1, strings.Count(body, openAIStreamKeepalivePing))
+}
+
+func TestStreamKeepalive_EmptyBindingDisablesPings(t *testing.T) {
+	body := runOpenAIStreamKeepaliveForTest(t, func(c *gin.Context) {
+		BindStreamKeepalivePing(c, "")
+	}, silentThenStreamingUpstream)
+
+	require.True(t, strings.HasPrefix(body, "data: {\"type\":\"response.output_text.delta\""), "body=%q", body)
+	require.NotContains(t, body, "ping")
+}
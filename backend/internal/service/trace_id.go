@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// TraceIDHeader 返回给客户端、并透传给允许自定义头的上游的 trace ID 头。
+// 与入口 request_id（ctxkey.RequestID）同值；X-Request-ID 响应头可能被上游响应头透传覆盖，
+// 因此单独使用该头保证客户端始终能拿到本服务的 trace ID。
+const TraceIDHeader = "X-Trace-ID"
+
+// TraceIDFromContext 返回请求入口生成或透传的 trace ID，缺失时返回空串。
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	traceID, _ := ctx.Value(ctxkey.RequestID).(string)
+	return strings.TrimSpace(traceID)
+}
+
+// applyUpstreamTraceIDHeader 将 trace ID 写入上游请求头。
+// 仅用于 API Key 账号：OAuth 账号需保持客户端指纹，不能追加自定义头。
+func applyUpstreamTraceIDHeader(req *http.Request, account *Account) {
+	if req == nil || account == nil || account.Type != AccountTypeAPIKey {
+		return
+	}
+	if traceID := TraceIDFromContext(req.Context()); traceID != "" {
+		req.Header.Set(TraceIDHeader, traceID)
+	}
+}
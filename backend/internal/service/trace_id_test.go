@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyUpstreamTraceIDHeader_OnlyForAPIKeyAccounts(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxkey.RequestID, " trace-1 ")
+
+	apiKeyReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.example.com/v1/messages", nil)
+	require.NoError(t, err)
+	applyUpstreamTraceIDHeader(apiKeyReq, &Account{Type: AccountTypeAPIKey})
+	require.Equal(t, "trace-1", apiKeyReq.Header.Get(TraceIDHeader))
+
+	oauthReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.example.com/v1/messages", nil)
+	require.NoError(t, err)
+	applyUpstreamTraceIDHeader(oauthReq, &Account{Type: AccountTypeOAuth})
+	require.Empty(t, oauthReq.Header.Get(TraceIDHeader))
+}
+
+func TestApplyUpstreamTraceIDHeader_NoTraceID(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/v1/messages", nil)
+	require.NoError(t, err)
+	applyUpstreamTraceIDHeader(req, &Account{Type: AccountTypeAPIKey})
+	require.Empty(t, req.Header.Get(TraceIDHeader))
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"math/bits"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+// TTFT 直方图采用 HDR 风格的对数-线性分桶：每个 2 的幂区间再等分为 8 个子桶，
+// 相对误差约 12.5%，固定 184 个桶覆盖 0 ~ 2^24 ms（约 4.6 小时），记录只需一次原子加。
+const (
+	ttftSubBucketBits  = 3
+	ttftSubBucketCount = 1 << ttftSubBucketBits
+	ttftMaxExponent    = 24
+	ttftBucketCount    = (ttftMaxExponent-ttftSubBucketBits+1)*ttftSubBucketCount + ttftSubBucketCount
+)
+
+// ttftBucketIndex 返回毫秒值所在的桶
+func ttftBucketIndex(ms int64) int {
+	if ms < ttftSubBucketCount {
+		if ms < 0 {
+			return 0
+		}
+		return int(ms)
+	}
+	exponent := bits.Len64(uint64(ms)) - 1
+	if exponent > ttftMaxExponent {
+		return ttftBucketCount - 1
+	}
+	sub := int(ms>>(exponent-ttftSubBucketBits)) & (ttftSubBucketCount - 1)
+	return (exponent-ttftSubBucketBits+1)*ttftSubBucketCount + sub
+}
+
+// ttftBucketUpperBound 返回桶的上界（含），用于保守地估计分位数
+func ttftBucketUpperBound(index int) int64 {
+	if index < ttftSubBucketCount {
+		return int64(index)
+	}
+	exponent := index/ttftSubBucketCount + ttftSubBucketBits - 1
+	sub := int64(index % ttftSubBucketCount)
+	width := int64(1) << (exponent - ttftSubBucketBits)
+	return (int64(1) << exponent) + (sub+1)*width - 1
+}
+
+type ttftHistogram struct {
+	counts [ttftBucketCount]atomic.Uint64
+	max    atomic.Int64
+}
+
+func (h *ttftHistogram) record(ms int64) {
+	h.counts[ttftBucketIndex(ms)].Add(1)
+	for {
+		current := h.max.Load()
+		if ms <= current || h.max.CompareAndSwap(current, ms) {
+			return
+		}
+	}
+}
+
+// ttftPercentile 返回第 q（0-1）分位所在桶的上界（不超过实际最大值）
+func ttftPercentile(counts []uint64, total uint64, maxValue int64, q float64) int64 {
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(total) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	for i, count := range counts {
+		seen += count
+		if seen >= rank {
+			if upper := ttftBucketUpperBound(i); upper < maxValue {
+				return upper
+			}
+			return maxValue
+		}
+	}
+	return maxValue
+}
+
+type ttftStatsKey struct {
+	model     string
+	accountID int64
+}
+
+type ttftWindow struct {
+	startedAt  time.Time
+	histograms sync.Map // ttftStatsKey -> *ttftHistogram
+}
+
+// TTFTStats 按模型（可选按账号）维护首 token 延迟直方图，窗口到期后整体重置。
+type TTFTStats struct {
+	cfg    config.GatewayTTFTStatsConfig
+	window atomic.Pointer[ttftWindow]
+	now    func() time.Time
+}
+
+// TTFTPercentiles 单个模型（或模型+账号）的首 token 延迟分位数（毫秒）
+type TTFTPercentiles struct {
+	Model     string `json:"model"`
+	AccountID *int64 `json:"account_id,omitempty"`
+	Count     uint64 `json:"count"`
+	P50Ms     int64  `json:"p50_ms"`
+	P90Ms     int64  `json:"p90_ms"`
+	P99Ms     int64  `json:"p99_ms"`
+	MaxMs     int64  `json:"max_ms"`
+}
+
+// TTFTStatsSnapshot 当前窗口的首 token 延迟分位数
+type TTFTStatsSnapshot struct {
+	Enabled         bool              `json:"enabled"`
+	WindowStartedAt time.Time         `json:"window_started_at"`
+	WindowMinutes   int               `json:"window_minutes"`
+	Items           []TTFTPercentiles `json:"items"`
+}
+
+func newTTFTStats(cfg *config.Config) *TTFTStats {
+	if cfg == nil || !cfg.Gateway.TTFTStats.Enabled {
+		return nil
+	}
+	stats := &TTFTStats{cfg: cfg.Gateway.TTFTStats, now: time.Now}
+	stats.window.Store(&ttftWindow{startedAt: stats.now()})
+	return stats
+}
+
+// currentWindow 返回当前窗口，到期时原子切换到新窗口
+func (s *TTFTStats) currentWindow() *ttftWindow {
+	windowLen := time.Duration(s.cfg.WindowMinutes) * time.Minute
+	for {
+		current := s.window.Load()
+		now := s.now()
+		if current != nil && (windowLen <= 0 || now.Sub(current.startedAt) < windowLen) {
+			return current
+		}
+		next := &ttftWindow{startedAt: now}
+		if s.window.CompareAndSwap(current, next) {
+			return next
+		}
+	}
+}
+
+// Record 记录一次首 token 延迟；nil 接收者或无效样本时忽略
+func (s *TTFTStats) Record(model string, accountID int64, firstTokenMs int) {
+	if s == nil || firstTokenMs <= 0 {
+		return
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return
+	}
+	window := s.currentWindow()
+	s.histogram(window, ttftStatsKey{model: model}).record(int64(firstTokenMs))
+	if s.cfg.TrackAccounts && accountID > 0 {
+		s.histogram(window, ttftStatsKey{model: model, accountID: accountID}).record(int64(firstTokenMs))
+	}
+}
+
+// RecordUsageLog 从用量记录中提取首 token 延迟（仅流式请求有该值）
+func (s *TTFTStats) RecordUsageLog(usageLog *UsageLog) {
+	if s == nil || usageLog == nil || usageLog.FirstTokenMs == nil {
+		return
+	}
+	s.Record(usageLog.Model, usageLog.AccountID, *usageLog.FirstTokenMs)
+}
+
+func (s *TTFTStats) histogram(window *ttftWindow, key ttftStatsKey) *ttftHistogram {
+	if value, ok := window.histograms.Load(key); ok {
+		return value.(*ttftHistogram)
+	}
+	value, _ := window.histograms.LoadOrStore(key, &ttftHistogram{})
+	return value.(*ttftHistogram)
+}
+
+type ttftMergedHistogram struct {
+	counts []uint64
+	total  uint64
+	max    int64
+}
+
+// SnapshotTTFTStats 合并多个统计源（各网关服务各自维护）的当前窗口并计算分位数，按模型、账号排序。
+// 窗口起点取各来源中最早的一个。
+func SnapshotTTFTStats(sources ...*TTFTStats) TTFTStatsSnapshot {
+	snapshot := TTFTStatsSnapshot{Items: []TTFTPercentiles{}}
+	merged := make(map[ttftStatsKey]*ttftMergedHistogram)
+	for _, s := range sources {
+		if s == nil {
+			continue
+		}
+		window := s.currentWindow()
+		if !snapshot.Enabled || window.startedAt.Before(snapshot.WindowStartedAt) {
+			snapshot.WindowStartedAt = window.startedAt
+		}
+		snapshot.Enabled = true
+		snapshot.WindowMinutes = s.cfg.WindowMinutes
+		window.histograms.Range(func(k, v any) bool {
+			key := k.(ttftStatsKey)
+			h := v.(*ttftHistogram)
+			target, ok := merged[key]
+			if !ok {
+				target = &ttftMergedHistogram{counts: make([]uint64, ttftBucketCount)}
+				merged[key] = target
+			}
+			for i := range target.counts {
+				count := h.counts[i].Load()
+				target.counts[i] += count
+				target.total += count
+			}
+			if maxValue := h.max.Load(); maxValue > target.max {
+				target.max = maxValue
+			}
+			return true
+		})
+	}
+
+	for key, h := range merged {
+		if h.total == 0 {
+			continue
+		}
+		item := TTFTPercentiles{
+			Model: key.model,
+			Count: h.total,
+			P50Ms: ttftPercentile(h.counts, h.total, h.max, 0.50),
+			P90Ms: ttftPercentile(h.counts, h.total, h.max, 0.90),
+			P99Ms: ttftPercentile(h.counts, h.total, h.max, 0.99),
+			MaxMs: h.max,
+		}
+		if key.accountID > 0 {
+			accountID := key.accountID
+			item.AccountID = &accountID
+		}
+		snapshot.Items = append(snapshot.Items, item)
+	}
+	sort.Slice(snapshot.Items, func(i, j int) bool {
+		a, b := snapshot.Items[i], snapshot.Items[j]
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		if (a.AccountID == nil) != (b.AccountID == nil) {
+			return a.AccountID == nil
+		}
+		return a.AccountID != nil && *a.AccountID < *b.AccountID
+	})
+	return snapshot
+}
+
+// GetTTFTPercentiles 返回各网关服务合并后的首 token 延迟分位数；model 非空时仅返回该模型
+func (s *OpsService) GetTTFTPercentiles(model string) TTFTStatsSnapshot {
+	if s == nil {
+		return TTFTStatsSnapshot{Items: []TTFTPercentiles{}}
+	}
+	snapshot := SnapshotTTFTStats(s.gatewayService.TTFTStats(), s.openAIGatewayService.TTFTStats())
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return snapshot
+	}
+	filtered := make([]TTFTPercentiles, 0, len(snapshot.Items))
+	for _, item := range snapshot.Items {
+		if item.Model == model {
+			filtered = append(filtered, item)
+		}
+	}
+	snapshot.Items = filtered
+	return snapshot
+}
@@ -0,0 +1,100 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTTFTStatsForTest(trackAccounts bool, now *time.Time) *TTFTStats {
+	cfg := &config.Config{}
+	cfg.Gateway.TTFTStats = config.GatewayTTFTStatsConfig{Enabled: true, WindowMinutes: 10, TrackAccounts: trackAccounts}
+	stats := newTTFTStats(cfg)
+	stats.now = func() time.Time { return *now }
+	stats.window.Store(&ttftWindow{startedAt: *now})
+	return stats
+}
+
+func TestTTFTBucketBoundsContainValue(t *testing.T) {
+	for _, ms := range []int64{0, 1, 7, 8, 9, 15, 16, 100, 999, 1000, 1024, 5000, 65535, 1 << 24} {
+		index := ttftBucketIndex(ms)
+		require.GreaterOrEqual(t, ttftBucketUpperBound(index), ms, "ms=%d", ms)
+		if index > 0 {
+			require.Less(t, ttftBucketUpperBound(index-1), ms, "ms=%d", ms)
+		}
+		// 相对误差不超过 12.5%
+		require.LessOrEqual(t, float64(ttftBucketUpperBound(index)-ms), float64(ms)/8+1, "ms=%d", ms)
+	}
+	require.Equal(t, ttftBucketCount-1, ttftBucketIndex(1<<40))
+}
+
+func TestTTFTStats_Percentiles(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats := newTTFTStatsForTest(false, &now)
+	for i := 1; i <= 100; i++ {
+		stats.Record("gpt-5", 1, i*10)
+	}
+	stats.Record("claude-sonnet", 2, 300)
+
+	snapshot := SnapshotTTFTStats(stats)
+	require.True(t, snapshot.Enabled)
+	require.Len(t, snapshot.Items, 2)
+	require.Equal(t, "claude-sonnet", snapshot.Items[0].Model)
+
+	gpt := snapshot.Items[1]
+	require.Equal(t, uint64(100), gpt.Count)
+	require.InDelta(t, 500, gpt.P50Ms, 500*0.125)
+	require.InDelta(t, 900, gpt.P90Ms, 900*0.125)
+	require.InDelta(t, 990, gpt.P99Ms, 990*0.125)
+	require.Equal(t, int64(1000), gpt.MaxMs)
+	require.LessOrEqual(t, gpt.P99Ms, gpt.MaxMs)
+}
+
+func TestTTFTStats_TrackAccountsAndMerge(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTTFTStatsForTest(true, &now)
+	b := newTTFTStatsForTest(true, &now)
+	a.Record("gpt-5", 7, 100)
+	b.Record("gpt-5", 8, 4000)
+
+	snapshot := SnapshotTTFTStats(a, nil, b)
+	require.Len(t, snapshot.Items, 3)
+	require.Nil(t, snapshot.Items[0].AccountID)
+	require.Equal(t, uint64(2), snapshot.Items[0].Count)
+	require.Equal(t, int64(4000), snapshot.Items[0].MaxMs)
+	require.Equal(t, int64(7), *snapshot.Items[1].AccountID)
+	require.Equal(t, int64(8), *snapshot.Items[2].AccountID)
+	require.Greater(t, snapshot.Items[2].P50Ms, snapshot.Items[1].P50Ms, "slow account stands out")
+}
+
+func TestTTFTStats_WindowResets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats := newTTFTStatsForTest(false, &now)
+	stats.Record("gpt-5", 1, 200)
+	require.Len(t, SnapshotTTFTStats(stats).Items, 1)
+
+	now = now.Add(10 * time.Minute)
+	snapshot := SnapshotTTFTStats(stats)
+	require.Empty(t, snapshot.Items)
+	require.Equal(t, now, snapshot.WindowStartedAt)
+}
+
+func TestTTFTStats_RecordUsageLogIgnoresNonStreaming(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats := newTTFTStatsForTest(false, &now)
+	firstTokenMs := 250
+
+	stats.RecordUsageLog(&UsageLog{Model: "gpt-5", AccountID: 1})
+	stats.RecordUsageLog(&UsageLog{Model: "gpt-5", AccountID: 1, FirstTokenMs: &firstTokenMs})
+	snapshot := SnapshotTTFTStats(stats)
+	require.Len(t, snapshot.Items, 1)
+	require.Equal(t, uint64(1), snapshot.Items[0].Count)
+
+	var disabled *TTFTStats
+	disabled.RecordUsageLog(&UsageLog{Model: "gpt-5", FirstTokenMs: &firstTokenMs})
+	require.False(t, SnapshotTTFTStats(disabled).Enabled)
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const (
+	// upstreamRateLimitUnknownResetTTL 上游未给出重置时间时，观测值的有效期
+	upstreamRateLimitUnknownResetTTL = time.Minute
+	// upstreamRateLimitCacheTTL 共享缓存中观测值的保留时长：按每条观测时间计算，超过后在刷新时删除
+	upstreamRateLimitCacheTTL = 10 * time.Minute
+	// upstreamRateLimitSaveTimeout 异步写入共享缓存的超时
+	upstreamRateLimitSaveTimeout = 2 * time.Second
+)
+
+// UpstreamRateLimitWindow 上游限流响应头报告的单个限流维度（请求数或 token 数）
+type UpstreamRateLimitWindow struct {
+	Limit     *int64     `json:"limit,omitempty"`
+	Remaining *int64     `json:"remaining,omitempty"`
+	ResetAt   *time.Time `json:"reset_at,omitempty"`
+}
+
+// UpstreamRateLimitSnapshot 单个账号最近一次上游响应携带的限流余量
+type UpstreamRateLimitSnapshot struct {
+	AccountID  int64                    `json:"account_id"`
+	Platform   string                   `json:"platform"`
+	Requests   *UpstreamRateLimitWindow `json:"requests,omitempty"`
+	Tokens     *UpstreamRateLimitWindow `json:"tokens,omitempty"`
+	ObservedAt time.Time                `json:"observed_at"`
+}
+
+// UpstreamRateLimitCache 上游限流余量存储（Redis），供多实例共享
+type UpstreamRateLimitCache interface {
+	SaveUpstreamRateLimit(ctx context.Context, snapshot UpstreamRateLimitSnapshot, ttl time.Duration) error
+	ListUpstreamRateLimits(ctx context.Context) ([]UpstreamRateLimitSnapshot, error)
+	DeleteUpstreamRateLimits(ctx context.Context, accountIDs []int64) error
+}
+
+// ParseUpstreamRateLimitHeaders 按账号平台解析上游限流响应头；平台不支持或响应未携带时返回 nil
+func ParseUpstreamRateLimitHeaders(platform string, headers http.Header, now time.Time) *UpstreamRateLimitSnapshot {
+	if headers == nil {
+		return nil
+	}
+	var requests, tokens *UpstreamRateLimitWindow
+	switch platform {
+	case PlatformAnthropic:
+		requests, tokens = parseAnthropicRateLimitHeaders(headers, now)
+	case PlatformOpenAI, PlatformGrok:
+		requests, tokens = parseOpenAIRateLimitHeaders(headers, now)
+	default:
+		return nil
+	}
+	if requests == nil && tokens == nil {
+		return nil
+	}
+	return &UpstreamRateLimitSnapshot{
+		Platform:   platform,
+		Requests:   requests,
+		Tokens:     tokens,
+		ObservedAt: now,
+	}
+}
+
+// parseOpenAIRateLimitHeaders 解析 OpenAI 风格的 x-ratelimit-{limit,remaining,reset}-{requests,tokens}。
+// reset 为距离重置的时长（如 "1s"、"6m0s"、"20ms"），xAI 也可能返回秒数或 RFC3339 时间。
+func parseOpenAIRateLimitHeaders(headers http.Header, now time.Time) (requests, tokens *UpstreamRateLimitWindow) {
+	window := func(dimension string) *UpstreamRateLimitWindow {
+		return newUpstreamRateLimitWindow(
+			headers.Get("x-ratelimit-limit-"+dimension),
+			headers.Get("x-ratelimit-remaining-"+dimension),
+			headers.Get("x-ratelimit-reset-"+dimension),
+			now,
+		)
+	}
+	return window("requests"), window("tokens")
+}
+
+// parseAnthropicRateLimitHeaders 解析 anthropic-ratelimit-{requests,tokens}-{limit,remaining,reset}。
+// reset 为 RFC3339 时间；未返回合并 tokens 维度时回退到 input-tokens（输入 token 通常最先耗尽）。
+func parseAnthropicRateLimitHeaders(headers http.Header, now time.Time) (requests, tokens *UpstreamRateLimitWindow) {
+	window := func(dimension string) *UpstreamRateLimitWindow {
+		return newUpstreamRateLimitWindow(
+			headers.Get("anthropic-ratelimit-"+dimension+"-limit"),
+			headers.Get("anthropic-ratelimit-"+dimension+"-remaining"),
+			headers.Get("anthropic-ratelimit-"+dimension+"-reset"),
+			now,
+		)
+	}
+	tokens = window("tokens")
+	if tokens == nil {
+		tokens = window("input-tokens")
+	}
+	return window("requests"), tokens
+}
+
+func newUpstreamRateLimitWindow(limitRaw, remainingRaw, resetRaw string, now time.Time) *UpstreamRateLimitWindow {
+	window := &UpstreamRateLimitWindow{
+		Limit:     parseRateLimitInt(limitRaw),
+		Remaining: parseRateLimitInt(remainingRaw),
+		ResetAt:   parseRateLimitReset(resetRaw, now),
+	}
+	if window.Limit == nil && window.Remaining == nil {
+		return nil
+	}
+	return window
+}
+
+func parseRateLimitInt(raw string) *int64 {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// parseRateLimitReset 支持时长（"6m0s"）、秒数（相对时长或 Unix 时间戳）和 RFC3339 时间
+func parseRateLimitReset(raw string, now time.Time) *time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(raw); err == nil {
+		t := now.Add(d)
+		return &t
+	}
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		var t time.Time
+		if seconds > 1_000_000_000 {
+			t = time.Unix(int64(seconds), 0)
+		} else {
+			t = now.Add(time.Duration(seconds * float64(time.Second)))
+		}
+		return &t
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t
+	}
+	return nil
+}
+
+// active 窗口观测值是否仍然有效（尚未重置）
+func (w *UpstreamRateLimitWindow) active(observedAt, now time.Time) bool {
+	if w.ResetAt != nil {
+		return now.Before(*w.ResetAt)
+	}
+	return now.Sub(observedAt) <= upstreamRateLimitUnknownResetTTL
+}
+
+// constrained 窗口余量低于阈值，或（开启时）即将重置
+func (w *UpstreamRateLimitWindow) constrained(observedAt, now time.Time, minRemaining int64, resetImminent time.Duration) bool {
+	if w == nil || !w.active(observedAt, now) {
+		return false
+	}
+	if minRemaining > 0 && w.Remaining != nil && *w.Remaining < minRemaining {
+		return true
+	}
+	return resetImminent > 0 && w.ResetAt != nil && w.ResetAt.Sub(now) <= resetImminent
+}
+
+// UpstreamRateLimitTracker 记录各账号上游响应头报告的限流余量。
+// 本实例的观测值立即生效并异步写入共享缓存；其他实例的观测值按刷新周期合并进本地快照。
+// 余量低于阈值（或重置即将到来）的账号在选号时仅在同优先级没有其他候选时使用。
+type UpstreamRateLimitTracker struct {
+	cache UpstreamRateLimitCache
+	cfg   config.GatewayUpstreamRateLimitConfig
+
+	mu        sync.RWMutex
+	snapshots map[int64]UpstreamRateLimitSnapshot
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+// NewUpstreamRateLimitTracker 创建上游限流余量跟踪器
+func NewUpstreamRateLimitTracker(cache UpstreamRateLimitCache, cfg *config.Config) *UpstreamRateLimitTracker {
+	t := &UpstreamRateLimitTracker{
+		cache:     cache,
+		snapshots: make(map[int64]UpstreamRateLimitSnapshot),
+		stopCh:    make(chan struct{}),
+		now:       time.Now,
+	}
+	if cfg != nil {
+		t.cfg = cfg.Gateway.UpstreamRateLimit
+	}
+	return t
+}
+
+// Enabled 是否启用基于上游限流响应头的选号降权
+func (t *UpstreamRateLimitTracker) Enabled() bool {
+	return t != nil && t.cfg.Enabled
+}
+
+// Start 启动共享缓存刷新循环
+func (t *UpstreamRateLimitTracker) Start() {
+	if !t.Enabled() || t.cache == nil || t.cfg.RefreshIntervalSeconds <= 0 {
+		return
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(time.Duration(t.cfg.RefreshIntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				t.refresh(ctx)
+				cancel()
+			case <-t.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止刷新循环
+func (t *UpstreamRateLimitTracker) Stop() {
+	if t == nil {
+		return
+	}
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+	})
+	t.wg.Wait()
+}
+
+// Observe 解析一次上游响应的限流头并记录；未启用或响应未携带限流头时返回 nil
+func (t *UpstreamRateLimitTracker) Observe(account *Account, headers http.Header) *UpstreamRateLimitSnapshot {
+	if !t.Enabled() || account == nil {
+		return nil
+	}
+	snapshot := ParseUpstreamRateLimitHeaders(account.Platform, headers, t.now())
+	if snapshot == nil {
+		return nil
+	}
+	snapshot.AccountID = account.ID
+	t.merge(*snapshot)
+	if t.cache != nil {
+		saved := *snapshot
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), upstreamRateLimitSaveTimeout)
+			defer cancel()
+			if err := t.cache.SaveUpstreamRateLimit(ctx, saved, upstreamRateLimitCacheTTL); err != nil {
+				slog.Debug("upstream_ratelimit_save_failed", "account_id", saved.AccountID, "error", err)
+			}
+		}()
+	}
+	return snapshot
+}
+
+// merge 仅当观测时间更新时覆盖本地快照
+func (t *UpstreamRateLimitTracker) merge(snapshot UpstreamRateLimitSnapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if prev, ok := t.snapshots[snapshot.AccountID]; ok && prev.ObservedAt.After(snapshot.ObservedAt) {
+		return
+	}
+	t.snapshots[snapshot.AccountID] = snapshot
+}
+
+func (t *UpstreamRateLimitTracker) refresh(ctx context.Context) {
+	snapshots, err := t.cache.ListUpstreamRateLimits(ctx)
+	if err != nil {
+		slog.Warn("upstream_ratelimit_refresh_failed", "error", err)
+		return
+	}
+	// Hash 的 key 级过期会被每次写入续期，已删除或长期无流量账号的字段需按观测时间逐条清理
+	now := t.now()
+	stale := make([]int64, 0)
+	for _, snapshot := range snapshots {
+		if now.Sub(snapshot.ObservedAt) > upstreamRateLimitCacheTTL {
+			stale = append(stale, snapshot.AccountID)
+			continue
+		}
+		t.merge(snapshot)
+	}
+	if len(stale) > 0 {
+		if err := t.cache.DeleteUpstreamRateLimits(ctx, stale); err != nil {
+			slog.Warn("upstream_ratelimit_delete_failed", "error", err)
+		}
+	}
+	t.pruneExpired()
+}
+
+// pruneExpired 清理所有维度均已重置的观测值
+func (t *UpstreamRateLimitTracker) pruneExpired() {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, snapshot := range t.snapshots {
+		if !snapshot.active(now) {
+			delete(t.snapshots, id)
+		}
+	}
+}
+
+func (s UpstreamRateLimitSnapshot) active(now time.Time) bool {
+	return (s.Requests != nil && s.Requests.active(s.ObservedAt, now)) ||
+		(s.Tokens != nil && s.Tokens.active(s.ObservedAt, now))
+}
+
+// IsAccountConstrained 账号是否因上游限流余量不足（或重置即将到来）需要降权；未启用或无观测值时返回 false
+func (t *UpstreamRateLimitTracker) IsAccountConstrained(accountID int64) bool {
+	if !t.Enabled() {
+		return false
+	}
+	t.mu.RLock()
+	snapshot, ok := t.snapshots[accountID]
+	t.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	now := t.now()
+	resetImminent := time.Duration(t.cfg.ResetImminentSeconds) * time.Second
+	return snapshot.Tokens.constrained(snapshot.ObservedAt, now, t.cfg.MinRemainingTokens, resetImminent) ||
+		snapshot.Requests.constrained(snapshot.ObservedAt, now, t.cfg.MinRemainingRequests, resetImminent)
+}
+
+// ListSnapshots 返回当前仍有效的观测值（按账号 ID 升序）
+func (t *UpstreamRateLimitTracker) ListSnapshots() []UpstreamRateLimitSnapshot {
+	if t == nil {
+		return nil
+	}
+	now := t.now()
+	t.mu.RLock()
+	snapshots := make([]UpstreamRateLimitSnapshot, 0, len(t.snapshots))
+	for _, snapshot := range t.snapshots {
+		if snapshot.active(now) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	t.mu.RUnlock()
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].AccountID < snapshots[j].AccountID })
+	return snapshots
+}
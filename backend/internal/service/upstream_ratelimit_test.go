@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUpstreamRateLimitHeaders_OpenAI(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	headers := http.Header{}
+	headers.Set("x-ratelimit-limit-requests", "5000")
+	headers.Set("x-ratelimit-remaining-requests", "4999")
+	headers.Set("x-ratelimit-reset-requests", "12ms")
+	headers.Set("x-ratelimit-limit-tokens", "800000")
+	headers.Set("x-ratelimit-remaining-tokens", "1200")
+	headers.Set("x-ratelimit-reset-tokens", "6m0s")
+
+	snapshot := ParseUpstreamRateLimitHeaders(PlatformOpenAI, headers, now)
+	require.NotNil(t, snapshot)
+	require.Equal(t, PlatformOpenAI, snapshot.Platform)
+	require.EqualValues(t, 5000, *snapshot.Requests.Limit)
+	require.EqualValues(t, 4999, *snapshot.Requests.Remaining)
+	require.Equal(t, now.Add(12*time.Millisecond), *snapshot.Requests.ResetAt)
+	require.EqualValues(t, 1200, *snapshot.Tokens.Remaining)
+	require.Equal(t, now.Add(6*time.Minute), *snapshot.Tokens.ResetAt)
+
+	// xAI 使用同名头，reset 可能是秒数
+	headers = http.Header{}
+	headers.Set("x-ratelimit-remaining-tokens", "10")
+	headers.Set("x-ratelimit-reset-tokens", "30")
+	snapshot = ParseUpstreamRateLimitHeaders(PlatformGrok, headers, now)
+	require.NotNil(t, snapshot)
+	require.Nil(t, snapshot.Requests)
+	require.Equal(t, now.Add(30*time.Second), *snapshot.Tokens.ResetAt)
+}
+
+func TestParseUpstreamRateLimitHeaders_Anthropic(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	headers := http.Header{}
+	headers.Set("anthropic-ratelimit-requests-limit", "50")
+	headers.Set("anthropic-ratelimit-requests-remaining", "49")
+	headers.Set("anthropic-ratelimit-requests-reset", "2026-01-01T00:00:01Z")
+	headers.Set("anthropic-ratelimit-tokens-limit", "40000")
+	headers.Set("anthropic-ratelimit-tokens-remaining", "39000")
+	headers.Set("anthropic-ratelimit-tokens-reset", "2026-01-01T00:00:30Z")
+	// OpenAI 风格的头不会被 Anthropic 解析器读取
+	headers.Set("x-ratelimit-remaining-tokens", "1")
+
+	snapshot := ParseUpstreamRateLimitHeaders(PlatformAnthropic, headers, now)
+	require.NotNil(t, snapshot)
+	require.EqualValues(t, 49, *snapshot.Requests.Remaining)
+	require.Equal(t, now.Add(time.Second), *snapshot.Requests.ResetAt)
+	require.EqualValues(t, 39000, *snapshot.Tokens.Remaining)
+	require.Equal(t, now.Add(30*time.Second), *snapshot.Tokens.ResetAt)
+
+	// 未返回合并 tokens 维度时回退到 input-tokens
+	headers = http.Header{}
+	headers.Set("anthropic-ratelimit-input-tokens-remaining", "500")
+	snapshot = ParseUpstreamRateLimitHeaders(PlatformAnthropic, headers, now)
+	require.NotNil(t, snapshot)
+	require.EqualValues(t, 500, *snapshot.Tokens.Remaining)
+	require.Nil(t, snapshot.Tokens.ResetAt)
+}
+
+func TestParseUpstreamRateLimitHeaders_Unsupported(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("x-ratelimit-remaining-tokens", "1")
+	require.Nil(t, ParseUpstreamRateLimitHeaders(PlatformGemini, headers, time.Now()))
+	require.Nil(t, ParseUpstreamRateLimitHeaders(PlatformOpenAI, http.Header{}, time.Now()))
+	require.Nil(t, ParseUpstreamRateLimitHeaders(PlatformOpenAI, nil, time.Now()))
+
+	headers = http.Header{}
+	headers.Set("x-ratelimit-remaining-tokens", "not-a-number")
+	require.Nil(t, ParseUpstreamRateLimitHeaders(PlatformOpenAI, headers, time.Now()))
+}
+
+type upstreamRateLimitCacheStub struct {
+	mu        sync.Mutex
+	saved     []UpstreamRateLimitSnapshot
+	snapshots []UpstreamRateLimitSnapshot
+	deleted   []int64
+}
+
+func (c *upstreamRateLimitCacheStub) SaveUpstreamRateLimit(_ context.Context, snapshot UpstreamRateLimitSnapshot, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saved = append(c.saved, snapshot)
+	return nil
+}
+
+func (c *upstreamRateLimitCacheStub) ListUpstreamRateLimits(context.Context) ([]UpstreamRateLimitSnapshot, error) {
+	return c.snapshots, nil
+}
+
+func (c *upstreamRateLimitCacheStub) DeleteUpstreamRateLimits(_ context.Context, accountIDs []int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleted = append(c.deleted, accountIDs...)
+	return nil
+}
+
+func newUpstreamRateLimitTestTracker(cache UpstreamRateLimitCache, mutate func(*config.GatewayUpstreamRateLimitConfig)) *UpstreamRateLimitTracker {
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamRateLimit = config.GatewayUpstreamRateLimitConfig{
+		Enabled:                true,
+		MinRemainingTokens:     10000,
+		MinRemainingRequests:   1,
+		RefreshIntervalSeconds: 10,
+	}
+	if mutate != nil {
+		mutate(&cfg.Gateway.UpstreamRateLimit)
+	}
+	return NewUpstreamRateLimitTracker(cache, cfg)
+}
+
+func TestUpstreamRateLimitTracker_ObserveAndConstrain(t *testing.T) {
+	cache := &upstreamRateLimitCacheStub{}
+	tracker := newUpstreamRateLimitTestTracker(cache, nil)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	low := http.Header{}
+	low.Set("x-ratelimit-remaining-tokens", "500")
+	low.Set("x-ratelimit-reset-tokens", "30s")
+	snapshot := tracker.Observe(&Account{ID: 1, Platform: PlatformOpenAI}, low)
+	require.NotNil(t, snapshot)
+	require.EqualValues(t, 1, snapshot.AccountID)
+
+	plenty := http.Header{}
+	plenty.Set("anthropic-ratelimit-tokens-remaining", "90000")
+	plenty.Set("anthropic-ratelimit-requests-remaining", "10")
+	require.NotNil(t, tracker.Observe(&Account{ID: 2, Platform: PlatformAnthropic}, plenty))
+
+	require.Nil(t, tracker.Observe(&Account{ID: 3, Platform: PlatformOpenAI}, http.Header{}))
+
+	require.True(t, tracker.IsAccountConstrained(1))
+	require.False(t, tracker.IsAccountConstrained(2))
+	require.False(t, tracker.IsAccountConstrained(3))
+	require.Eventually(t, func() bool {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		return len(cache.saved) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// 窗口重置后恢复
+	now = now.Add(31 * time.Second)
+	require.False(t, tracker.IsAccountConstrained(1))
+	// 无重置时间的观测值超过有效期后失效
+	now = now.Add(upstreamRateLimitUnknownResetTTL)
+	require.Empty(t, tracker.ListSnapshots())
+}
+
+func TestUpstreamRateLimitTracker_RequestsAndImminentReset(t *testing.T) {
+	tracker := newUpstreamRateLimitTestTracker(nil, func(cfg *config.GatewayUpstreamRateLimitConfig) {
+		cfg.ResetImminentSeconds = 5
+	})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	exhausted := http.Header{}
+	exhausted.Set("x-ratelimit-remaining-requests", "0")
+	exhausted.Set("x-ratelimit-reset-requests", "20s")
+	tracker.Observe(&Account{ID: 1, Platform: PlatformOpenAI}, exhausted)
+
+	resetting := http.Header{}
+	resetting.Set("x-ratelimit-remaining-tokens", "50000")
+	resetting.Set("x-ratelimit-reset-tokens", "2s")
+	tracker.Observe(&Account{ID: 2, Platform: PlatformOpenAI}, resetting)
+
+	require.True(t, tracker.IsAccountConstrained(1))
+	require.True(t, tracker.IsAccountConstrained(2))
+}
+
+func TestUpstreamRateLimitTracker_DisabledAndRefresh(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("x-ratelimit-remaining-tokens", "1")
+
+	disabled := newUpstreamRateLimitTestTracker(nil, func(cfg *config.GatewayUpstreamRateLimitConfig) {
+		cfg.Enabled = false
+	})
+	require.Nil(t, disabled.Observe(&Account{ID: 1, Platform: PlatformOpenAI}, headers))
+	require.False(t, disabled.IsAccountConstrained(1))
+
+	var nilTracker *UpstreamRateLimitTracker
+	require.Nil(t, nilTracker.Observe(&Account{ID: 1, Platform: PlatformOpenAI}, headers))
+	require.False(t, nilTracker.IsAccountConstrained(1))
+	require.Nil(t, nilTracker.ListSnapshots())
+
+	// 其他实例写入共享缓存的观测值在刷新后生效，旧观测值不覆盖新观测值
+	now := time.Now()
+	remaining := int64(1)
+	resetAt := now.Add(time.Minute)
+	cache := &upstreamRateLimitCacheStub{snapshots: []UpstreamRateLimitSnapshot{
+		{AccountID: 5, Platform: PlatformOpenAI, Tokens: &UpstreamRateLimitWindow{Remaining: &remaining, ResetAt: &resetAt}, ObservedAt: now},
+		{AccountID: 6, Platform: PlatformOpenAI, Tokens: &UpstreamRateLimitWindow{Remaining: &remaining, ResetAt: &resetAt}, ObservedAt: now.Add(-time.Second)},
+	}}
+	tracker := newUpstreamRateLimitTestTracker(cache, nil)
+	plenty := http.Header{}
+	plenty.Set("x-ratelimit-remaining-tokens", "90000")
+	tracker.Observe(&Account{ID: 6, Platform: PlatformOpenAI}, plenty)
+	tracker.refresh(context.Background())
+	require.True(t, tracker.IsAccountConstrained(5))
+	require.False(t, tracker.IsAccountConstrained(6))
+	require.Len(t, tracker.ListSnapshots(), 2)
+}
+
+func TestUpstreamRateLimitTracker_RefreshDeletesStaleCacheEntries(t *testing.T) {
+	now := time.Now()
+	remaining := int64(1)
+	resetAt := now.Add(time.Hour)
+	cache := &upstreamRateLimitCacheStub{snapshots: []UpstreamRateLimitSnapshot{
+		{AccountID: 7, Platform: PlatformOpenAI, Tokens: &UpstreamRateLimitWindow{Remaining: &remaining, ResetAt: &resetAt}, ObservedAt: now},
+		{AccountID: 8, Platform: PlatformOpenAI, Tokens: &UpstreamRateLimitWindow{Remaining: &remaining, ResetAt: &resetAt}, ObservedAt: now.Add(-upstreamRateLimitCacheTTL - time.Minute)},
+	}}
+	tracker := newUpstreamRateLimitTestTracker(cache, nil)
+	tracker.refresh(context.Background())
+
+	// 超过保留时长的观测值从共享缓存删除且不再合并，即使其重置时间尚未到来
+	require.Equal(t, []int64{8}, cache.deleted)
+	require.True(t, tracker.IsAccountConstrained(7))
+	require.False(t, tracker.IsAccountConstrained(8))
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/util/httputil"
+	"github.com/gin-gonic/gin"
+)
+
+// UpstreamRequestIDHeader 回传给客户端的上游请求 ID 响应头。
+const UpstreamRequestIDHeader = "X-Upstream-Request-Id"
+
+// UpstreamRequestIDPassthroughEnabled 报告是否开启上游请求 ID 回传（gateway.upstream_request_id_passthrough）。
+func UpstreamRequestIDPassthroughEnabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.Gateway.UpstreamRequestIDPassthrough
+}
+
+// SetUpstreamRequestIDHeader 在开启回传时写入 X-Upstream-Request-Id；id 为空时清除之前尝试残留的值，
+// 避免 failover 后把上一个账号的请求 ID 回传给客户端。
+func SetUpstreamRequestIDHeader(c *gin.Context, cfg *config.Config, id string) {
+	if c == nil || !UpstreamRequestIDPassthroughEnabled(cfg) {
+		return
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		c.Writer.Header().Del(UpstreamRequestIDHeader)
+		return
+	}
+	c.Writer.Header().Set(UpstreamRequestIDHeader, id)
+}
+
+// exposeUpstreamRequestID 从上游响应中提取请求 ID（x-request-id 等，缺省回退 cf-ray），
+// 按配置写入客户端响应头并返回，供 failover 错误与日志使用。
+func exposeUpstreamRequestID(c *gin.Context, cfg *config.Config, headers http.Header, body []byte) string {
+	id := httputil.ExtractUpstreamRequestID(headers, body)
+	SetUpstreamRequestIDHeader(c, cfg, id)
+	return id
+}
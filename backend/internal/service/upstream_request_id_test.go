@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newUpstreamRequestIDTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+	return c, rec
+}
+
+func TestExposeUpstreamRequestID_DisabledByDefault(t *testing.T) {
+	c, _ := newUpstreamRequestIDTestContext()
+	id := exposeUpstreamRequestID(c, &config.Config{}, http.Header{"X-Request-Id": []string{"req_123"}}, nil)
+	require.Equal(t, "req_123", id, "ID is still extracted for failover errors and logs")
+	require.Empty(t, c.Writer.Header().Get(UpstreamRequestIDHeader))
+}
+
+func TestExposeUpstreamRequestID_EchoesHeaderWhenEnabled(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamRequestIDPassthrough = true
+
+	c, _ := newUpstreamRequestIDTestContext()
+	id := exposeUpstreamRequestID(c, cfg, http.Header{"X-Request-Id": []string{"req_123"}, "Cf-Ray": []string{"ray-1"}}, nil)
+	require.Equal(t, "req_123", id, "x-request-id wins over cf-ray")
+	require.Equal(t, "req_123", c.Writer.Header().Get(UpstreamRequestIDHeader))
+
+	// cf-ray 兜底：Cloudflare 挑战页只在 body 中带 ray id
+	body := []byte(`<html><body>Cloudflare Ray ID: cf-ray: 8abc123-LAX</body></html>`)
+	id = exposeUpstreamRequestID(c, cfg, http.Header{}, body)
+	require.Equal(t, "8abc123-LAX", id)
+	require.Equal(t, "8abc123-LAX", c.Writer.Header().Get(UpstreamRequestIDHeader))
+
+	// 后续尝试没有请求 ID 时清除上一账号残留的值
+	id = exposeUpstreamRequestID(c, cfg, http.Header{}, nil)
+	require.Empty(t, id)
+	require.Empty(t, c.Writer.Header().Get(UpstreamRequestIDHeader))
+}
+
+func TestHandleFailoverErrorResponsePassthrough_CarriesUpstreamRequestID(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamRequestIDPassthrough = true
+	svc := &OpenAIGatewayService{cfg: cfg}
+
+	c, _ := newUpstreamRequestIDTestContext()
+	resp := &http.Response{
+		StatusCode: 529,
+		Header:     http.Header{"Content-Type": []string{"application/json"}, "X-Request-Id": []string{"req_fail"}},
+		Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"overloaded"}}`)),
+	}
+	err := svc.handleFailoverErrorResponsePassthrough(context.Background(), resp, c, &Account{ID: 1, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Name: "a"}, nil)
+	var failoverErr *UpstreamFailoverError
+	require.ErrorAs(t, err, &failoverErr)
+	require.Equal(t, "req_fail", failoverErr.UpstreamRequestID)
+	require.Equal(t, "req_fail", c.Writer.Header().Get(UpstreamRequestIDHeader))
+}
+
+func TestGatewayHandleErrorResponse_EchoesUpstreamRequestID(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamRequestIDPassthrough = true
+	svc := &GatewayService{cfg: cfg}
+
+	c, rec := newUpstreamRequestIDTestContext()
+	resp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"Content-Type": []string{"application/json"}, "Request-Id": []string{"req_claude"}},
+		Body:       io.NopCloser(strings.NewReader(`{"type":"error","error":{"type":"invalid_request_error","message":"bad"}}`)),
+	}
+	_, err := svc.handleErrorResponse(context.Background(), resp, c, &Account{ID: 2, Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Name: "claude"})
+	require.Error(t, err)
+	require.Equal(t, "req_claude", rec.Header().Get(UpstreamRequestIDHeader))
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+// StreamIdleTimeoutError 流式读取期间上游在 gateway.stream_idle_timeout_seconds 内没有发送任何字节
+type StreamIdleTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *StreamIdleTimeoutError) Error() string {
+	return fmt.Sprintf("upstream stream idle for %s", e.Timeout)
+}
+
+// asStreamIdleTimeout 判断 err 是否为流空闲超时
+func asStreamIdleTimeout(err error) (*StreamIdleTimeoutError, bool) {
+	var idleErr *StreamIdleTimeoutError
+	if errors.As(err, &idleErr) {
+		return idleErr, true
+	}
+	return nil, false
+}
+
+// streamIdleTimeoutBody 为上游响应体的每次 Read 计时：阻塞超过 timeout 仍未收到字节时关闭底层连接，
+// 让阻塞中的 Read 立即返回，并以 StreamIdleTimeoutError 报告。
+// 只统计等待上游的时间，下游写出慢导致的读取间隔不计入。
+type streamIdleTimeoutBody struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+func newStreamIdleTimeoutBody(body io.ReadCloser, timeout time.Duration) io.ReadCloser {
+	if body == nil || timeout <= 0 {
+		return body
+	}
+	b := &streamIdleTimeoutBody{body: body, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.timedOut.Store(true)
+		_ = b.body.Close()
+	})
+	b.timer.Stop()
+	return b
+}
+
+func (b *streamIdleTimeoutBody) Read(p []byte) (int, error) {
+	if b.timedOut.Load() {
+		return 0, &StreamIdleTimeoutError{Timeout: b.timeout}
+	}
+	b.timer.Reset(b.timeout)
+	n, err := b.body.Read(p)
+	b.timer.Stop()
+	if b.timedOut.Load() {
+		return n, &StreamIdleTimeoutError{Timeout: b.timeout}
+	}
+	return n, err
+}
+
+func (b *streamIdleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.body.Close()
+}
+
+// applyStreamIdleTimeout 按 gateway.stream_idle_timeout_seconds 为流式响应体加上空闲读取超时（0 表示禁用）。
+// 与首 token 超时、整体超时相互独立：只要上游持续有字节到达就不会触发。
+func applyStreamIdleTimeout(cfg *config.Config, resp *http.Response) {
+	if cfg == nil || resp == nil || cfg.Gateway.StreamIdleTimeoutSeconds <= 0 {
+		return
+	}
+	resp.Body = newStreamIdleTimeoutBody(resp.Body, time.Duration(cfg.Gateway.StreamIdleTimeoutSeconds)*time.Second)
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// newStallingUpstream 返回一个先发送 prelude 后保持连接但不再发送任何数据的 SSE 上游
+func newStallingUpstream(t *testing.T, prelude string) *httptest.Server {
+	t.Helper()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		if prelude != "" {
+			_, _ = io.WriteString(w, prelude)
+		}
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+	})
+	return srv
+}
+
+func newStreamIdleTestService(idleSeconds int) *GatewayService {
+	return &GatewayService{
+		cfg: &config.Config{
+			Gateway: config.GatewayConfig{
+				StreamIdleTimeoutSeconds: idleSeconds,
+				MaxLineSize:              defaultMaxLineSize,
+			},
+		},
+		rateLimitService: &RateLimitService{},
+	}
+}
+
+func TestStreamIdleTimeoutBody_FiresWhenUpstreamStalls(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+	body := newStreamIdleTimeoutBody(pr, 50*time.Millisecond)
+
+	go func() { _, _ = pw.Write([]byte("data: 1\n")) }()
+	buf := make([]byte, 64)
+	n, err := body.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "data: 1\n", string(buf[:n]))
+
+	start := time.Now()
+	_, err = body.Read(buf)
+	idleErr, ok := asStreamIdleTimeout(err)
+	require.True(t, ok, "expected idle timeout, got %v", err)
+	require.Equal(t, 50*time.Millisecond, idleErr.Timeout)
+	require.Less(t, time.Since(start), 2*time.Second)
+
+	// 触发后后续读取持续返回同一错误
+	_, err = body.Read(buf)
+	_, ok = asStreamIdleTimeout(err)
+	require.True(t, ok)
+}
+
+func TestStreamIdleTimeoutBody_SlowButActiveStreamDoesNotFire(t *testing.T) {
+	pr, pw := io.Pipe()
+	body := newStreamIdleTimeoutBody(pr, 200*time.Millisecond)
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			time.Sleep(60 * time.Millisecond)
+			_, _ = pw.Write([]byte("x"))
+		}
+		_ = pw.Close()
+	}()
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, "xxxxx", string(data))
+	require.NoError(t, body.Close())
+}
+
+func TestApplyStreamIdleTimeout_DisabledKeepsBody(t *testing.T) {
+	original := io.NopCloser(nil)
+	resp := &http.Response{Body: original}
+	applyStreamIdleTimeout(&config.Config{}, resp)
+	require.Equal(t, original, resp.Body)
+}
+
+func TestHandleStreamingResponse_StreamIdleTimeoutMidStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := newStallingUpstream(t, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":7}}}\n\n")
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+
+	svc := newStreamIdleTestService(1)
+	start := time.Now()
+	result, err := svc.handleStreamingResponse(context.Background(), resp, c, &Account{ID: 1}, time.Now(), "model", "model", false)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	_, ok := asStreamIdleTimeout(err)
+	require.True(t, ok, "expected idle timeout, got %v", err)
+	require.NotNil(t, result)
+	require.Equal(t, 7, result.usage.InputTokens)
+	require.Contains(t, rec.Body.String(), "message_start")
+	require.Contains(t, rec.Body.String(), "event: error")
+	require.Contains(t, rec.Body.String(), `"type":"stream_timeout"`)
+}
+
+func TestHandleStreamingResponse_StreamIdleTimeoutBeforeOutputFailsOver(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := newStallingUpstream(t, "")
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+
+	svc := newStreamIdleTestService(1)
+	_, err = svc.handleStreamingResponse(context.Background(), resp, c, &Account{ID: 1}, time.Now(), "model", "model", false)
+
+	var failoverErr *UpstreamFailoverError
+	require.True(t, errors.As(err, &failoverErr), "expected failover, got %v", err)
+	require.Equal(t, http.StatusGatewayTimeout, failoverErr.StatusCode)
+	require.False(t, c.Writer.Written())
+}
+
+func newGeminiStreamIdleTestService(idleSeconds int) *GeminiMessagesCompatService {
+	return &GeminiMessagesCompatService{
+		cfg:              &config.Config{Gateway: config.GatewayConfig{StreamIdleTimeoutSeconds: idleSeconds}},
+		rateLimitService: &RateLimitService{},
+	}
+}
+
+func TestGeminiHandleStreamingResponse_StreamIdleTimeoutMidStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := newStallingUpstream(t, `data: {"candidates":[{"content":{"parts":[{"text":"Hello"}]}}]}`+"\n\n")
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+
+	svc := newGeminiStreamIdleTestService(1)
+	start := time.Now()
+	_, err = svc.handleStreamingResponse(c, resp, time.Now(), "claude-sonnet-4-5")
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	err = svc.handleGeminiStreamIdleTimeout(context.Background(), c, &Account{ID: 1}, "claude-sonnet-4-5", err, writeGeminiClaudeStreamIdleFrame)
+	_, ok := asStreamIdleTimeout(err)
+	require.True(t, ok, "expected idle timeout, got %v", err)
+	require.True(t, IsResponseCommitted(c))
+	body := rec.Body.String()
+	require.Contains(t, body, "Hello")
+	require.Contains(t, body, "event: error")
+	require.Contains(t, body, `"type":"stream_timeout"`)
+}
+
+func TestGeminiHandleNativeStreamingResponse_StreamIdleTimeoutBeforeOutputFailsOver(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := newStallingUpstream(t, "")
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", nil)
+
+	svc := newGeminiStreamIdleTestService(1)
+	_, err = svc.handleNativeStreamingResponse(c, resp, time.Now(), false)
+	err = svc.handleGeminiStreamIdleTimeout(context.Background(), c, &Account{ID: 1}, "gemini-2.5-pro", err, writeGeminiNativeStreamIdleFrame)
+
+	var failoverErr *UpstreamFailoverError
+	require.True(t, errors.As(err, &failoverErr), "expected failover, got %v", err)
+	require.Equal(t, http.StatusGatewayTimeout, failoverErr.StatusCode)
+	require.False(t, c.Writer.Written())
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultUpstreamTimeoutTierName 未命中任何分级时使用的兜底分级名称
+	DefaultUpstreamTimeoutTierName = "default"
+
+	// OpsUpstreamTimeoutTierKey 本次转发命中的上游超时分级名称（写入 ops 上下文与上游错误事件）
+	OpsUpstreamTimeoutTierKey = "ops_upstream_timeout_tier"
+
+	upstreamTimeoutWatchdogKey = "upstream_timeout_watchdog"
+
+	UpstreamTimeoutPhaseConnect    = "connect"
+	UpstreamTimeoutPhaseFirstToken = "first_token"
+	UpstreamTimeoutPhaseTotal      = "total"
+)
+
+// UpstreamTimeoutTier 单次转发生效的上游超时分级，各超时为 0 表示该阶段不限制
+type UpstreamTimeoutTier struct {
+	Name              string
+	ConnectTimeout    time.Duration
+	FirstTokenTimeout time.Duration
+	TotalTimeout      time.Duration
+}
+
+func upstreamTimeoutTierFromConfig(tier config.GatewayTimeoutTier) UpstreamTimeoutTier {
+	return UpstreamTimeoutTier{
+		Name:              strings.TrimSpace(tier.Name),
+		ConnectTimeout:    time.Duration(tier.ConnectTimeoutSeconds) * time.Second,
+		FirstTokenTimeout: time.Duration(tier.FirstTokenTimeoutSeconds) * time.Second,
+		TotalTimeout:      time.Duration(tier.TotalTimeoutSeconds) * time.Second,
+	}
+}
+
+// ResolveUpstreamTimeoutTier 按模型名解析上游超时分级。
+// 优先级：精确匹配 > 字面量字符最多的 glob > 配置顺序靠前；
+// 均未命中时使用名为 default 的分级，未配置 default 时各阶段均不限制。
+// 分级不继承 gateway.first_token_timeout_seconds：后者是独立的流式首 token 切号机制。
+func ResolveUpstreamTimeoutTier(cfg *config.Config, model string) UpstreamTimeoutTier {
+	fallback := UpstreamTimeoutTier{Name: DefaultUpstreamTimeoutTierName}
+	if cfg == nil {
+		return fallback
+	}
+
+	model = strings.ToLower(strings.TrimSpace(model))
+	bestIndex := -1
+	bestExact := false
+	bestLiteral := -1
+	for i, tier := range cfg.Gateway.TimeoutTiers {
+		if strings.EqualFold(strings.TrimSpace(tier.Name), DefaultUpstreamTimeoutTierName) {
+			fallback = upstreamTimeoutTierFromConfig(tier)
+		}
+		if model == "" {
+			continue
+		}
+		for _, pattern := range tier.Models {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if pattern == "" {
+				continue
+			}
+			exact := pattern == model
+			if !exact {
+				if matched, err := path.Match(pattern, model); err != nil || !matched {
+					continue
+				}
+			}
+			literal := globLiteralLength(pattern)
+			better := bestIndex < 0 ||
+				(exact && !bestExact) ||
+				(exact == bestExact && literal > bestLiteral)
+			if better {
+				bestIndex, bestExact, bestLiteral = i, exact, literal
+			}
+		}
+	}
+	if bestIndex < 0 {
+		return fallback
+	}
+	return upstreamTimeoutTierFromConfig(cfg.Gateway.TimeoutTiers[bestIndex])
+}
+
+// globLiteralLength 统计 pattern 中的字面量字符数（不含 * ? [...] 通配部分），用于比较匹配的具体程度
+func globLiteralLength(pattern string) int {
+	n := 0
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; {
+		case inClass:
+			if ch == ']' {
+				inClass = false
+			}
+		case ch == '[':
+			inClass = true
+		case ch == '*' || ch == '?':
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			n++
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// UpstreamTimeoutError 上游超时分级触发的超时
+type UpstreamTimeoutError struct {
+	Tier    string
+	Phase   string
+	Timeout time.Duration
+}
+
+func (e *UpstreamTimeoutError) Error() string {
+	return fmt.Sprintf("upstream %s timeout after %s (tier=%s)", e.Phase, e.Timeout, e.Tier)
+}
+
+// upstreamTimeoutWatchdog 按分级监控单次转发的连接 / 首 token / 总耗时，超时后取消所有绑定的上游请求 context。
+// 流式请求的上游 context 与客户端取消解耦，因此需要在解耦之后再 Bind。
+type upstreamTimeoutWatchdog struct {
+	tier UpstreamTimeoutTier
+	log  *zap.Logger
+
+	mu      sync.Mutex
+	err     *UpstreamTimeoutError
+	stopped bool
+	cancels []context.CancelCauseFunc
+	timers  map[string]*time.Timer
+}
+
+// newUpstreamTimeoutWatchdog 创建并启动监控；log 非空时分级超时触发以 Info 级别记录
+func newUpstreamTimeoutWatchdog(tier UpstreamTimeoutTier, log *zap.Logger) *upstreamTimeoutWatchdog {
+	w := &upstreamTimeoutWatchdog{tier: tier, log: log, timers: make(map[string]*time.Timer, 3)}
+	w.arm(UpstreamTimeoutPhaseConnect, tier.ConnectTimeout)
+	w.arm(UpstreamTimeoutPhaseFirstToken, tier.FirstTokenTimeout)
+	w.arm(UpstreamTimeoutPhaseTotal, tier.TotalTimeout)
+	return w
+}
+
+func (w *upstreamTimeoutWatchdog) arm(phase string, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	w.timers[phase] = time.AfterFunc(timeout, func() {
+		w.fire(&UpstreamTimeoutError{Tier: w.tier.Name, Phase: phase, Timeout: timeout})
+	})
+}
+
+func (w *upstreamTimeoutWatchdog) fire(err *UpstreamTimeoutError) {
+	w.mu.Lock()
+	if w.stopped || w.err != nil {
+		w.mu.Unlock()
+		return
+	}
+	w.err = err
+	cancels := w.cancels
+	w.cancels = nil
+	w.mu.Unlock()
+
+	if w.log != nil {
+		w.log.Info("gateway.upstream_timeout_tier_fired",
+			zap.String("tier", err.Tier),
+			zap.String("phase", err.Phase),
+			zap.Duration("timeout", err.Timeout),
+		)
+	}
+
+	for _, cancel := range cancels {
+		cancel(err)
+	}
+}
+
+func (w *upstreamTimeoutWatchdog) disarm(phases ...string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, phase := range phases {
+		if t := w.timers[phase]; t != nil {
+			t.Stop()
+			delete(w.timers, phase)
+		}
+	}
+}
+
+// Bind 派生一个会在分级超时触发时被取消的 context，用于构建上游请求
+func (w *upstreamTimeoutWatchdog) Bind(ctx context.Context) context.Context {
+	if w == nil {
+		return ctx
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped && w.err == nil {
+		// 转发已结束：不再监控
+		return ctx
+	}
+	bound, cancel := context.WithCancelCause(ctx)
+	if w.err != nil {
+		cancel(w.err)
+		return bound
+	}
+	w.cancels = append(w.cancels, cancel)
+	return bound
+}
+
+// HeadersReceived 收到上游响应头，连接阶段结束
+func (w *upstreamTimeoutWatchdog) HeadersReceived() {
+	w.disarm(UpstreamTimeoutPhaseConnect)
+}
+
+// FirstToken 收到首个内容事件，连接与首 token 阶段结束
+func (w *upstreamTimeoutWatchdog) FirstToken() {
+	w.disarm(UpstreamTimeoutPhaseConnect, UpstreamTimeoutPhaseFirstToken)
+}
+
+// Err 返回已触发的分级超时；未触发时返回 nil
+func (w *upstreamTimeoutWatchdog) Err() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		return nil
+	}
+	return w.err
+}
+
+// Stop 结束监控（转发返回时调用）。
+// 不主动取消已绑定的 context：上游请求的生命周期仍由原 context 决定，与未启用分级时保持一致。
+func (w *upstreamTimeoutWatchdog) Stop() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+	for phase, t := range w.timers {
+		t.Stop()
+		delete(w.timers, phase)
+	}
+	w.cancels = nil
+}
+
+// beginUpstreamTimeoutTier 在模型确定后解析超时分级并启动监控：分级名称写入 ops 上下文，
+// watchdog 挂到 gin context 上供流式处理标记首 token。调用方负责在转发返回时 Stop。
+func beginUpstreamTimeoutTier(ctx context.Context, c *gin.Context, cfg *config.Config, platform, model string) *upstreamTimeoutWatchdog {
+	tier := ResolveUpstreamTimeoutTier(cfg, model)
+	log := logger.FromContext(ctx).With(
+		zap.String("platform", platform),
+		zap.String("model", model),
+	)
+	w := newUpstreamTimeoutWatchdog(tier, log)
+	if c != nil {
+		c.Set(OpsUpstreamTimeoutTierKey, tier.Name)
+		c.Set(upstreamTimeoutWatchdogKey, w)
+	}
+	log.Debug("gateway.upstream_timeout_tier",
+		zap.String("tier", tier.Name),
+		zap.Duration("connect_timeout", tier.ConnectTimeout),
+		zap.Duration("first_token_timeout", tier.FirstTokenTimeout),
+		zap.Duration("total_timeout", tier.TotalTimeout),
+	)
+	return w
+}
+
+// claudeUpstreamTimeoutFailoverBody 超时分级在收到响应头前触发时 failover 错误携带的 Anthropic 格式响应体
+var claudeUpstreamTimeoutFailoverBody = []byte(`{"type":"error","error":{"type":"timeout_error","message":"Upstream request timed out"}}`)
+
+// upstreamTimeoutTierFailover 超时分级在收到响应头前触发（连接 / 总超时）时记录 ops 并返回 failover 错误。
+// 不标记账号不可调度：分级反映的是本次请求的时间预算而非账号故障，由 handler 换号重试。
+func upstreamTimeoutTierFailover(c *gin.Context, account *Account, upstreamURL string, timeoutErr error, body []byte) *UpstreamFailoverError {
+	msg := timeoutErr.Error()
+	setOpsUpstreamError(c, http.StatusGatewayTimeout, msg, "")
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+		Platform:    account.Platform,
+		AccountID:   account.ID,
+		AccountName: account.Name,
+		UpstreamURL: upstreamURL,
+		Kind:        "timeout",
+		Message:     msg,
+	})
+	return &UpstreamFailoverError{
+		StatusCode:   http.StatusGatewayTimeout,
+		ResponseBody: body,
+	}
+}
+
+// markUpstreamFirstToken 流式处理收到首个内容事件时调用，结束首 token 超时监控
+func markUpstreamFirstToken(c *gin.Context) {
+	upstreamTimeoutWatchdogFromGin(c).FirstToken()
+}
+
+// upstreamTimeoutErrFromGin 返回本次转发已触发的分级超时（未触发或未启用分级时为 nil）
+func upstreamTimeoutErrFromGin(c *gin.Context) error {
+	return upstreamTimeoutWatchdogFromGin(c).Err()
+}
+
+func upstreamTimeoutWatchdogFromGin(c *gin.Context) *upstreamTimeoutWatchdog {
+	if c == nil {
+		return nil
+	}
+	v, ok := c.Get(upstreamTimeoutWatchdogKey)
+	if !ok {
+		return nil
+	}
+	w, _ := v.(*upstreamTimeoutWatchdog)
+	return w
+}
+
+// upstreamTimeoutTierFromGin 返回本次转发命中的超时分级名称
+func upstreamTimeoutTierFromGin(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	v, ok := c.Get(OpsUpstreamTimeoutTierKey)
+	if !ok {
+		return ""
+	}
+	name, _ := v.(string)
+	return name
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTimeoutTierTestConfig(tiers ...config.GatewayTimeoutTier) *config.Config {
+	cfg := &config.Config{}
+	cfg.Gateway.FirstTokenTimeoutSeconds = 45
+	cfg.Gateway.TimeoutTiers = tiers
+	return cfg
+}
+
+func TestResolveUpstreamTimeoutTier_Precedence(t *testing.T) {
+	cfg := newTimeoutTierTestConfig(
+		config.GatewayTimeoutTier{Name: "claude", Models: []string{"claude-*"}, FirstTokenTimeoutSeconds: 60},
+		config.GatewayTimeoutTier{Name: "fast", Models: []string{"claude-*haiku*", "gpt-*-mini"}, FirstTokenTimeoutSeconds: 10},
+		config.GatewayTimeoutTier{Name: "reasoning", Models: []string{"o3*", "o3-mini"}, FirstTokenTimeoutSeconds: 300},
+		config.GatewayTimeoutTier{Name: "mini-exact", Models: []string{"o3-mini"}, FirstTokenTimeoutSeconds: 30},
+		config.GatewayTimeoutTier{Name: "default", ConnectTimeoutSeconds: 20, FirstTokenTimeoutSeconds: 90},
+	)
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		// 更具体的 glob（字面量字符更多）优先于宽泛 glob，与配置顺序无关
+		{model: "claude-3-5-haiku-20241022", want: "fast"},
+		{model: "claude-sonnet-4-5", want: "claude"},
+		// 精确匹配优先；同为精确匹配时按配置顺序
+		{model: "o3-mini", want: "reasoning"},
+		{model: "o3-pro", want: "reasoning"},
+		{model: "GPT-4o-Mini", want: "fast"},
+		{model: "gemini-2.5-pro", want: "default"},
+		{model: "", want: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			require.Equal(t, tt.want, ResolveUpstreamTimeoutTier(cfg, tt.model).Name)
+		})
+	}
+
+	tier := ResolveUpstreamTimeoutTier(cfg, "gemini-2.5-pro")
+	require.Equal(t, 20*time.Second, tier.ConnectTimeout)
+	require.Equal(t, 90*time.Second, tier.FirstTokenTimeout)
+	require.Zero(t, tier.TotalTimeout)
+}
+
+func TestResolveUpstreamTimeoutTier_ExactBeatsMoreSpecificGlob(t *testing.T) {
+	cfg := newTimeoutTierTestConfig(
+		config.GatewayTimeoutTier{Name: "glob", Models: []string{"gpt-5.1-codex-m?x"}},
+		config.GatewayTimeoutTier{Name: "exact", Models: []string{"gpt-5"}},
+		config.GatewayTimeoutTier{Name: "wide", Models: []string{"gpt-5*"}},
+	)
+	require.Equal(t, "exact", ResolveUpstreamTimeoutTier(cfg, "gpt-5").Name)
+	require.Equal(t, "glob", ResolveUpstreamTimeoutTier(cfg, "gpt-5.1-codex-max").Name)
+	require.Equal(t, "wide", ResolveUpstreamTimeoutTier(cfg, "gpt-5.1").Name)
+}
+
+func TestResolveUpstreamTimeoutTier_DefaultDoesNotInheritGlobalFirstTokenTimeout(t *testing.T) {
+	cfg := newTimeoutTierTestConfig(
+		config.GatewayTimeoutTier{Name: "fast", Models: []string{"*haiku*"}, FirstTokenTimeoutSeconds: 10},
+	)
+	tier := ResolveUpstreamTimeoutTier(cfg, "claude-opus-4-1")
+	require.Equal(t, DefaultUpstreamTimeoutTierName, tier.Name)
+	// 未配置 default 分级：各阶段不限制，gateway.first_token_timeout_seconds 仍由流式首 token 机制独立生效
+	require.Zero(t, tier.FirstTokenTimeout)
+	require.Zero(t, tier.ConnectTimeout)
+	require.Zero(t, tier.TotalTimeout)
+
+	require.Equal(t, DefaultUpstreamTimeoutTierName, ResolveUpstreamTimeoutTier(nil, "gpt-5").Name)
+}
+
+func TestGlobLiteralLength(t *testing.T) {
+	require.Equal(t, 5, globLiteralLength("*haiku*"))
+	require.Equal(t, 5, globLiteralLength("o3-m?n?"))
+	require.Equal(t, 2, globLiteralLength("o[0-9]-*"))
+	require.Equal(t, 2, globLiteralLength(`\*x`))
+}
+
+func TestUpstreamTimeoutWatchdog_FiresAndCancelsBoundContexts(t *testing.T) {
+	w := newUpstreamTimeoutWatchdog(UpstreamTimeoutTier{Name: "fast", FirstTokenTimeout: 20 * time.Millisecond}, nil)
+	defer w.Stop()
+
+	ctx := w.Bind(context.Background())
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("bound context was not canceled by first token timeout")
+	}
+
+	var timeoutErr *UpstreamTimeoutError
+	require.True(t, errors.As(context.Cause(ctx), &timeoutErr))
+	require.Equal(t, "fast", timeoutErr.Tier)
+	require.Equal(t, UpstreamTimeoutPhaseFirstToken, timeoutErr.Phase)
+	require.Equal(t, timeoutErr, w.Err())
+
+	// 超时后新绑定的 context（例如重试）立即取消
+	retryCtx := w.Bind(context.Background())
+	require.Error(t, retryCtx.Err())
+}
+
+func TestUpstreamTimeoutWatchdog_FirstTokenDisarmsPhase(t *testing.T) {
+	w := newUpstreamTimeoutWatchdog(UpstreamTimeoutTier{
+		Name:              "fast",
+		ConnectTimeout:    20 * time.Millisecond,
+		FirstTokenTimeout: 30 * time.Millisecond,
+		TotalTimeout:      80 * time.Millisecond,
+	}, nil)
+	defer w.Stop()
+	ctx := w.Bind(context.Background())
+
+	w.HeadersReceived()
+	w.FirstToken()
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, ctx.Err())
+	require.NoError(t, w.Err())
+
+	// 总超时不受首 token 影响
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("bound context was not canceled by total timeout")
+	}
+	var timeoutErr *UpstreamTimeoutError
+	require.True(t, errors.As(w.Err(), &timeoutErr))
+	require.Equal(t, UpstreamTimeoutPhaseTotal, timeoutErr.Phase)
+}
+
+func TestUpstreamTimeoutWatchdog_StopReleasesWithoutTimeout(t *testing.T) {
+	w := newUpstreamTimeoutWatchdog(UpstreamTimeoutTier{Name: "default", TotalTimeout: 20 * time.Millisecond}, nil)
+	ctx := w.Bind(context.Background())
+	w.Stop()
+
+	time.Sleep(40 * time.Millisecond)
+	require.NoError(t, ctx.Err())
+	require.NoError(t, w.Err())
+
+	var nilWatchdog *upstreamTimeoutWatchdog
+	require.Equal(t, context.Background(), nilWatchdog.Bind(context.Background()))
+	require.NoError(t, nilWatchdog.Err())
+	nilWatchdog.FirstToken()
+	nilWatchdog.Stop()
+}
+
+func TestBeginUpstreamTimeoutTier_ConcurrentRequestsUseTheirOwnTier(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := newTimeoutTierTestConfig(
+		config.GatewayTimeoutTier{Name: "fast", Models: []string{"*haiku*"}, FirstTokenTimeoutSeconds: 1},
+		config.GatewayTimeoutTier{Name: "slow", Models: []string{"o3*"}, FirstTokenTimeoutSeconds: 600},
+	)
+	models := map[string]string{
+		"claude-3-5-haiku": "fast",
+		"o3-pro":           "slow",
+		"gpt-4.1":          "default",
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for model, want := range models {
+			wg.Add(1)
+			go func(model, want string) {
+				defer wg.Done()
+				c, _ := gin.CreateTestContext(httptest.NewRecorder())
+				w := beginUpstreamTimeoutTier(context.Background(), c, cfg, PlatformAnthropic, model)
+				defer w.Stop()
+
+				require.Equal(t, want, upstreamTimeoutTierFromGin(c))
+				require.Same(t, w, upstreamTimeoutWatchdogFromGin(c))
+				expected := ResolveUpstreamTimeoutTier(cfg, model)
+				require.Equal(t, expected.FirstTokenTimeout, w.tier.FirstTokenTimeout)
+			}(model, want)
+		}
+	}
+	wg.Wait()
+
+	// 不同分级的超时互不影响：fast 请求超时，slow 请求仍在进行
+	fastCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	slowCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	fastCfg := newTimeoutTierTestConfig(
+		config.GatewayTimeoutTier{Name: "fast", Models: []string{"*haiku*"}},
+		config.GatewayTimeoutTier{Name: "slow", Models: []string{"o3*"}, FirstTokenTimeoutSeconds: 600},
+	)
+	fast := beginUpstreamTimeoutTier(context.Background(), fastCtx, fastCfg, PlatformAnthropic, "claude-3-5-haiku")
+	fast.arm(UpstreamTimeoutPhaseFirstToken, 10*time.Millisecond)
+	slow := beginUpstreamTimeoutTier(context.Background(), slowCtx, fastCfg, PlatformOpenAI, "o3-pro")
+	defer fast.Stop()
+	defer slow.Stop()
+	fastUpstream := fast.Bind(context.Background())
+	slowUpstream := slow.Bind(context.Background())
+
+	select {
+	case <-fastUpstream.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("fast tier request was not canceled")
+	}
+	require.Error(t, upstreamTimeoutErrFromGin(fastCtx))
+	require.NoError(t, slowUpstream.Err())
+	require.NoError(t, upstreamTimeoutErrFromGin(slowCtx))
+}
+
+func TestAppendOpsUpstreamError_RecordsTimeoutTier(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	w := beginUpstreamTimeoutTier(context.Background(), c, newTimeoutTierTestConfig(), PlatformGemini, "gemini-2.5-flash")
+	defer w.Stop()
+
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{Platform: PlatformGemini, Kind: "timeout", Message: "x"})
+
+	v, ok := c.Get(OpsUpstreamErrorsKey)
+	require.True(t, ok)
+	events, ok := v.([]*OpsUpstreamErrorEvent)
+	require.True(t, ok)
+	require.Len(t, events, 1)
+	require.Equal(t, DefaultUpstreamTimeoutTierName, events[0].TimeoutTier)
+}
+
+func TestUpstreamTimeoutWatchdog_LogsTierAtInfoWhenFired(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	w := newUpstreamTimeoutWatchdog(UpstreamTimeoutTier{Name: "fast", ConnectTimeout: 10 * time.Millisecond}, zap.New(core))
+	defer w.Stop()
+
+	ctx := w.Bind(context.Background())
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("bound context was not canceled by connect timeout")
+	}
+	entries := logs.FilterMessage("gateway.upstream_timeout_tier_fired").All()
+	require.Len(t, entries, 1)
+	require.Equal(t, "fast", entries[0].ContextMap()["tier"])
+	require.Equal(t, UpstreamTimeoutPhaseConnect, entries[0].ContextMap()["phase"])
+}
+
+func TestUpstreamTimeoutTierFailover_ReturnsFailoverError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	account := &Account{ID: 3, Name: "claude", Platform: PlatformAnthropic}
+
+	err := upstreamTimeoutTierFailover(c, account, "https://api.anthropic.com/v1/messages",
+		&UpstreamTimeoutError{Tier: "fast", Phase: UpstreamTimeoutPhaseConnect, Timeout: time.Second}, claudeUpstreamTimeoutFailoverBody)
+
+	var failoverErr *UpstreamFailoverError
+	require.ErrorAs(t, err, &failoverErr)
+	require.Equal(t, http.StatusGatewayTimeout, failoverErr.StatusCode)
+	require.JSONEq(t, string(claudeUpstreamTimeoutFailoverBody), string(failoverErr.ResponseBody))
+	// 尚未向客户端写出任何内容，handler 可继续换号
+	require.False(t, c.Writer.Written())
+	v, ok := c.Get(OpsUpstreamErrorsKey)
+	require.True(t, ok)
+	events := v.([]*OpsUpstreamErrorEvent)
+	require.Len(t, events, 1)
+	require.Equal(t, "timeout", events[0].Kind)
+}
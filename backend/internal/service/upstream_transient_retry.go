@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// isTransientUpstreamNetworkError 判断上游传输错误是否可以安全地原地重发：
+// 仅限建立连接阶段（TCP 拨号、HTTP CONNECT / SOCKS 代理握手）的瞬时失败，此时请求尚未写出任何字节，
+// 重发 POST 不会让上游重复执行。连接建立后的 ECONNRESET / broken pipe / EOF 无法确定上游是否已收到请求，
+// 不重试（交由 failover 处理）；复用的空闲连接在写出前被关闭由 net/http Transport 自行重试。
+// 请求已取消、连接被拒绝、DNS 失败等持久故障同样不在此列。
+func isTransientUpstreamNetworkError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if classifyOpenAITransportError(err).Persistent {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	switch opErr.Op {
+	case "dial", "proxyconnect", "socks connect":
+		return true
+	}
+	return false
+}
+
+// doUpstreamWithTransientRetry 发送上游请求；遇到瞬时网络错误时在同一账号上原地重试，
+// 最多 gateway.transient_retry_attempts 次。只在以下条件均满足时重试：
+// 请求 context 未结束（含超时分级）、客户端未断开且尚未写入任何内容、请求体可重放。
+func doUpstreamWithTransientRetry(c *gin.Context, cfg *config.Config, account *Account, req *http.Request, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	resp, err := do(req)
+	maxAttempts := 0
+	if cfg != nil {
+		maxAttempts = cfg.Gateway.TransientRetryAttempts
+	}
+	for attempt := 1; err != nil && attempt <= maxAttempts; attempt++ {
+		if !isTransientUpstreamNetworkError(err) || req.Context().Err() != nil || req.GetBody == nil {
+			break
+		}
+		if c != nil && (c.Writer.Written() || (c.Request != nil && c.Request.Context().Err() != nil)) {
+			break
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			break
+		}
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:    account.Platform,
+			AccountID:   account.ID,
+			AccountName: account.Name,
+			UpstreamURL: safeUpstreamURL(req.URL.String()),
+			Kind:        "transient_retry",
+			Message:     safeErr,
+		})
+		logger.LegacyPrintf("service.gateway", "Account %d: transient upstream error, retrying in place (%d/%d): %s", account.ID, attempt, maxAttempts, safeErr)
+
+		retryReq := req.Clone(req.Context())
+		retryReq.Body = body
+		resp, err = do(retryReq)
+	}
+	return resp, err
+}
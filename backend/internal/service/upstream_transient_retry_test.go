@@ -0,0 +1,138 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type transientTimeoutError struct{}
+
+func (transientTimeoutError) Error() string   { return "i/o timeout" }
+func (transientTimeoutError) Timeout() bool   { return true }
+func (transientTimeoutError) Temporary() bool { return true }
+
+func newTransientRetryTestRequest(t *testing.T, ctx context.Context) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.example.com/v1/messages", bytes.NewReader([]byte(`{"model":"m"}`)))
+	require.NoError(t, err)
+	return req
+}
+
+func newTransientRetryTestContext() *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	return c
+}
+
+func newTransientRetryTestConfig(attempts int) *config.Config {
+	cfg := &config.Config{}
+	cfg.Gateway.TransientRetryAttempts = attempts
+	return cfg
+}
+
+func TestDoUpstreamWithTransientRetry_RetriesDialTimeout(t *testing.T) {
+	c := newTransientRetryTestContext()
+	account := &Account{ID: 7, Name: "acc", Platform: PlatformAnthropic}
+	dialTimeout := &net.OpError{Op: "dial", Net: "tcp", Err: transientTimeoutError{}}
+
+	var bodies []string
+	resp, err := doUpstreamWithTransientRetry(c, newTransientRetryTestConfig(1), account, newTransientRetryTestRequest(t, context.Background()), func(req *http.Request) (*http.Response, error) {
+		body, readErr := io.ReadAll(req.Body)
+		require.NoError(t, readErr)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			return nil, dialTimeout
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{`{"model":"m"}`, `{"model":"m"}`}, bodies)
+	require.False(t, c.Writer.Written())
+}
+
+func TestDoUpstreamWithTransientRetry_SkipsWhenNotRetryable(t *testing.T) {
+	account := &Account{ID: 7, Platform: PlatformOpenAI}
+	dialTimeout := &net.OpError{Op: "dial", Net: "tcp", Err: transientTimeoutError{}}
+
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		prepare  func(c *gin.Context, req *http.Request) *http.Request
+	}{
+		{name: "disabled", attempts: 0, err: dialTimeout},
+		{name: "connection refused", attempts: 2, err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}},
+		{name: "response timeout", attempts: 2, err: &net.OpError{Op: "read", Net: "tcp", Err: transientTimeoutError{}}},
+		// 连接建立后的错误无法确定请求体是否已送达上游，POST 不可安全重发
+		{name: "reset after connect", attempts: 2, err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}},
+		{name: "broken pipe while writing", attempts: 2, err: &net.OpError{Op: "write", Net: "tcp", Err: syscall.EPIPE}},
+		{name: "unexpected EOF", attempts: 2, err: io.ErrUnexpectedEOF},
+		{name: "context canceled", attempts: 2, err: context.Canceled},
+		{
+			name: "client already written", attempts: 2, err: dialTimeout,
+			prepare: func(c *gin.Context, req *http.Request) *http.Request {
+				c.Writer.WriteHeaderNow()
+				return req
+			},
+		},
+		{
+			name: "body not replayable", attempts: 2, err: dialTimeout,
+			prepare: func(_ *gin.Context, req *http.Request) *http.Request {
+				req.GetBody = nil
+				return req
+			},
+		},
+		{
+			name: "request context done", attempts: 2, err: dialTimeout,
+			prepare: func(_ *gin.Context, req *http.Request) *http.Request {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				return req.WithContext(ctx)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTransientRetryTestContext()
+			req := newTransientRetryTestRequest(t, context.Background())
+			if tt.prepare != nil {
+				req = tt.prepare(c, req)
+			}
+			calls := 0
+			_, err := doUpstreamWithTransientRetry(c, newTransientRetryTestConfig(tt.attempts), account, req, func(*http.Request) (*http.Response, error) {
+				calls++
+				return nil, tt.err
+			})
+			require.True(t, errors.Is(err, tt.err))
+			require.Equal(t, 1, calls)
+		})
+	}
+}
+
+func TestDoUpstreamWithTransientRetry_StopsAfterConfiguredAttempts(t *testing.T) {
+	c := newTransientRetryTestContext()
+	account := &Account{ID: 7, Platform: PlatformAnthropic}
+	dialReset := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNRESET}
+	calls := 0
+	_, err := doUpstreamWithTransientRetry(c, newTransientRetryTestConfig(2), account, newTransientRetryTestRequest(t, context.Background()), func(*http.Request) (*http.Response, error) {
+		calls++
+		return nil, dialReset
+	})
+	require.ErrorIs(t, err, syscall.ECONNRESET)
+	require.Equal(t, 3, calls)
+}
@@ -165,6 +165,21 @@ type UsageLog struct {
 
 	// Cache TTL Override 标记（管理员强制替换了缓存 TTL 计费）
 	CacheTTLOverridden bool
+	// UsageEstimated 标记 token 用量为估算值（上游流式响应缺失 usage）
+	UsageEstimated bool
+	// BillingUnverified 标记请求在计费降级模式下放行，未完成计费资格校验
+	BillingUnverified bool
+	// RequestBytes / ResponseBytes 原始请求体与写给客户端的响应字节数（未统计时为 nil）
+	RequestBytes  *int64
+	ResponseBytes *int64
+	// ResponseCacheHit 标记响应由响应缓存直接返回（未请求上游、不计费）
+	ResponseCacheHit bool
+	// PricingUnavailable 标记模型没有可用价格：仅记录 token，各项费用为 0 且不计费（费用未知而非免费）
+	PricingUnavailable bool
+	// EstimatedCost 请求前按计费价格表估算的费用（仅设置 max_cost 或开启费用预览时记录）
+	EstimatedCost *float64
+	// CostOverage 标记实际费用超出估算费用的配置倍数（估算明显偏低）
+	CostOverage bool
 
 	// 图片生成字段
 	ImageCount         int
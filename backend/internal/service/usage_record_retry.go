@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// 用量记录重试队列条目类型（决定重放时调用哪个 RecordUsage 实现）
+const (
+	UsageRecordRetryKindGateway            = "gateway"
+	UsageRecordRetryKindGatewayLongContext = "gateway_long_context"
+	UsageRecordRetryKindOpenAI             = "openai"
+)
+
+const (
+	usageRecordRetryEnqueueTimeout = 3 * time.Second
+	usageRecordRetryReplayTimeout  = 30 * time.Second
+	usageRecordRetryMaxErrorLen    = 512
+)
+
+// UsageRecordRetryCache 用量记录重试队列存储（Redis），多实例共享同一待重试队列与死信列表
+type UsageRecordRetryCache interface {
+	// PushUsageRecordRetry 将条目放入待重试队列
+	PushUsageRecordRetry(ctx context.Context, entry *UsageRecordRetryEntry) error
+	// PopUsageRecordRetry 取出最早入队的条目并移入处理中列表，处理完成后须调用 AckUsageRecordRetry；
+	// 队列为空时返回 nil, nil
+	PopUsageRecordRetry(ctx context.Context) (*UsageRecordRetryEntry, error)
+	// AckUsageRecordRetry 确认条目已处理完成（重放成功、已重新入队或已移入死信），从处理中列表删除
+	AckUsageRecordRetry(ctx context.Context, entry *UsageRecordRetryEntry) error
+	// RestoreUsageRecordRetryProcessing 将处理中列表的条目移回待重试队列，返回移回的条数
+	RestoreUsageRecordRetryProcessing(ctx context.Context) (int, error)
+	// UsageRecordRetryPendingCount 待重试队列长度
+	UsageRecordRetryPendingCount(ctx context.Context) (int64, error)
+	// PushUsageRecordDeadLetter 将条目放入死信列表，列表长度超过 maxLen 时丢弃最旧条目
+	PushUsageRecordDeadLetter(ctx context.Context, entry *UsageRecordRetryEntry, maxLen int) error
+	// ListUsageRecordDeadLetters 按时间倒序返回最多 limit 条死信
+	ListUsageRecordDeadLetters(ctx context.Context, limit int) ([]UsageRecordRetryEntry, error)
+}
+
+// UsageRecordRetryEntry 一次失败的 RecordUsage 调用。
+// 输入已去除 API Key 明文、凭证、密码哈希等敏感字段；请求 ID 在入队时固定，
+// 重放时据此命中 usage_billing_dedup / usage_logs 唯一约束，保证恰好一次扣费与记录。
+type UsageRecordRetryEntry struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// ClientRequestID / TraceID 入队时请求 context 上的请求标识，重放时原样挂回 context
+	ClientRequestID string `json:"client_request_id,omitempty"`
+	TraceID         string `json:"trace_id,omitempty"`
+
+	BillingUnverified bool                 `json:"billing_unverified,omitempty"`
+	CostEstimate      *RequestCostEstimate `json:"cost_estimate,omitempty"`
+
+	// Receipt 出队时条目在处理中列表中的原始数据，确认时据此删除（不序列化）
+	Receipt string `json:"-"`
+
+	Attempts      int       `json:"attempts"`
+	FirstFailedAt time.Time `json:"first_failed_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error"`
+
+	Gateway            *RecordUsageInput            `json:"gateway,omitempty"`
+	GatewayLongContext *RecordUsageLongContextInput `json:"gateway_long_context,omitempty"`
+	OpenAI             *OpenAIRecordUsageInput      `json:"openai,omitempty"`
+}
+
+// UsageRecordRetryService 用量记录延迟重试：RecordUsage 失败时入队，后台按指数退避重放，
+// 超过最长重试时间后移入死信列表（管理端可查看）。
+type UsageRecordRetryService struct {
+	cache         UsageRecordRetryCache
+	cfg           config.BillingUsageRetryConfig
+	gateway       *GatewayService
+	openAI        *OpenAIGatewayService
+	apiKeyService APIKeyQuotaUpdater
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+// NewUsageRecordRetryService 创建用量记录重试服务；apiKeyService 在重放时重新注入（不随条目序列化）
+func NewUsageRecordRetryService(cache UsageRecordRetryCache, gateway *GatewayService, openAI *OpenAIGatewayService, apiKeyService APIKeyQuotaUpdater, cfg *config.Config) *UsageRecordRetryService {
+	s := &UsageRecordRetryService{
+		cache:         cache,
+		gateway:       gateway,
+		openAI:        openAI,
+		apiKeyService: apiKeyService,
+		stopCh:        make(chan struct{}),
+		now:           time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.Billing.UsageRetry
+	}
+	return s
+}
+
+// Enabled 是否启用用量记录重试
+func (s *UsageRecordRetryService) Enabled() bool {
+	return s != nil && s.cfg.Enabled && s.cache != nil
+}
+
+// Start 启动后台重放循环；启动前先恢复上次进程退出时未确认的条目
+func (s *UsageRecordRetryService) Start() {
+	if !s.Enabled() || s.cfg.PollIntervalSeconds <= 0 {
+		return
+	}
+	s.restoreProcessing(context.Background())
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(time.Duration(s.cfg.PollIntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.ProcessDue(context.Background())
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台重放循环
+func (s *UsageRecordRetryService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+// restoreProcessing 将处理中列表的条目移回待重试队列。
+// 条目可能已在崩溃前重放成功，重放时按入队时固定的请求 ID 去重，重复处理不会重复扣费。
+func (s *UsageRecordRetryService) restoreProcessing(ctx context.Context) {
+	restoreCtx, cancel := context.WithTimeout(ctx, usageRecordRetryEnqueueTimeout)
+	defer cancel()
+	restored, err := s.cache.RestoreUsageRecordRetryProcessing(restoreCtx)
+	if err != nil {
+		slog.Error("usage_record_retry.restore_failed", "restored", restored, "error", err)
+		return
+	}
+	if restored > 0 {
+		slog.Warn("usage_record_retry.restored", "count", restored)
+	}
+}
+
+// ListDeadLetters 返回最近的死信条目（未启用时返回空）
+func (s *UsageRecordRetryService) ListDeadLetters(ctx context.Context, limit int) ([]UsageRecordRetryEntry, error) {
+	if s == nil || s.cache == nil {
+		return nil, nil
+	}
+	return s.cache.ListUsageRecordDeadLetters(ctx, limit)
+}
+
+// enqueue 记录一次失败的 RecordUsage 调用；入队失败只记日志（原错误仍由调用方处理）
+func (s *UsageRecordRetryService) enqueue(ctx context.Context, kind string, input any, cause error) {
+	if !s.Enabled() || input == nil || cause == nil {
+		return
+	}
+	entry := &UsageRecordRetryEntry{
+		ID:            generateRequestID(),
+		Kind:          kind,
+		FirstFailedAt: s.now(),
+		LastError:     truncateUsageRecordRetryError(cause),
+	}
+	if ctx != nil {
+		entry.ClientRequestID, _ = ctx.Value(ctxkey.ClientRequestID).(string)
+		entry.TraceID, _ = ctx.Value(ctxkey.RequestID).(string)
+		entry.BillingUnverified = IsBillingUnverified(ctx)
+		entry.CostEstimate = RequestCostEstimateFromContext(ctx)
+	}
+
+	upstreamRequestID := ""
+	switch in := input.(type) {
+	case *RecordUsageInput:
+		sanitized := *in
+		sanitized.APIKey, sanitized.User, sanitized.Account = sanitizeUsageRecordRetryRefs(in.APIKey, in.User, in.Account)
+		sanitized.APIKeyService = nil
+		entry.Gateway = &sanitized
+		if in.Result != nil {
+			upstreamRequestID = in.Result.RequestID
+		}
+	case *RecordUsageLongContextInput:
+		sanitized := *in
+		sanitized.APIKey, sanitized.User, sanitized.Account = sanitizeUsageRecordRetryRefs(in.APIKey, in.User, in.Account)
+		sanitized.APIKeyService = nil
+		entry.GatewayLongContext = &sanitized
+		if in.Result != nil {
+			upstreamRequestID = in.Result.RequestID
+		}
+	case *OpenAIRecordUsageInput:
+		sanitized := *in
+		sanitized.APIKey, sanitized.User, sanitized.Account = sanitizeUsageRecordRetryRefs(in.APIKey, in.User, in.Account)
+		sanitized.APIKeyService = nil
+		entry.OpenAI = &sanitized
+		if in.Result != nil {
+			upstreamRequestID = in.Result.RequestID
+		}
+	default:
+		return
+	}
+	// 没有任何可复用的请求标识时固定一个本地 ID，否则每次重放都会生成新的 request_id，去重失效
+	if strings.TrimSpace(entry.ClientRequestID) == "" && strings.TrimSpace(entry.TraceID) == "" && strings.TrimSpace(upstreamRequestID) == "" {
+		entry.TraceID = "usage-retry-" + entry.ID
+	}
+	entry.NextAttemptAt = entry.FirstFailedAt.Add(s.backoff(0))
+
+	pushCtx, cancel := context.WithTimeout(context.Background(), usageRecordRetryEnqueueTimeout)
+	defer cancel()
+	if err := s.cache.PushUsageRecordRetry(pushCtx, entry); err != nil {
+		slog.Error("usage_record_retry.enqueue_failed", "entry_id", entry.ID, "kind", kind, "cause", cause, "error", err)
+		return
+	}
+	slog.Warn("usage_record_retry.enqueued", "entry_id", entry.ID, "kind", kind, "error", cause)
+}
+
+// ProcessDue 处理一轮待重试队列：到期条目重放，未到期条目放回队尾，超龄条目移入死信列表
+func (s *UsageRecordRetryService) ProcessDue(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+	pending, err := s.cache.UsageRecordRetryPendingCount(ctx)
+	if err != nil {
+		slog.Warn("usage_record_retry.count_failed", "error", err)
+		return
+	}
+	limit := int(min(pending, int64(s.cfg.BatchSize)))
+	for i := 0; i < limit; i++ {
+		entry, err := s.cache.PopUsageRecordRetry(ctx)
+		if err != nil {
+			slog.Warn("usage_record_retry.pop_failed", "error", err)
+			return
+		}
+		if entry == nil {
+			return
+		}
+		s.process(ctx, entry)
+	}
+}
+
+func (s *UsageRecordRetryService) process(ctx context.Context, entry *UsageRecordRetryEntry) {
+	now := s.now()
+	if now.Before(entry.NextAttemptAt) {
+		s.requeue(ctx, entry)
+		return
+	}
+	err := s.replay(ctx, entry)
+	if err == nil {
+		slog.Info("usage_record_retry.replayed", "entry_id", entry.ID, "kind", entry.Kind, "attempts", entry.Attempts+1)
+		s.ack(ctx, entry)
+		return
+	}
+	entry.Attempts++
+	entry.LastError = truncateUsageRecordRetryError(err)
+	maxAge := time.Duration(s.cfg.MaxAgeSeconds) * time.Second
+	if now.Sub(entry.FirstFailedAt) >= maxAge {
+		if pushErr := s.cache.PushUsageRecordDeadLetter(ctx, entry, s.cfg.DeadLetterMax); pushErr != nil {
+			slog.Error("usage_record_retry.dead_letter_failed", "entry_id", entry.ID, "error", pushErr)
+			return
+		}
+		slog.Error("usage_record_retry.dead_lettered", "entry_id", entry.ID, "kind", entry.Kind, "attempts", entry.Attempts, "error", err)
+		s.ack(ctx, entry)
+		return
+	}
+	entry.NextAttemptAt = now.Add(s.backoff(entry.Attempts))
+	s.requeue(ctx, entry)
+}
+
+// requeue 重新入队后确认原条目；入队失败时不确认，条目留在处理中列表，下次启动时恢复
+func (s *UsageRecordRetryService) requeue(ctx context.Context, entry *UsageRecordRetryEntry) {
+	if err := s.cache.PushUsageRecordRetry(ctx, entry); err != nil {
+		slog.Error("usage_record_retry.requeue_failed", "entry_id", entry.ID, "error", err)
+		return
+	}
+	s.ack(ctx, entry)
+}
+
+func (s *UsageRecordRetryService) ack(ctx context.Context, entry *UsageRecordRetryEntry) {
+	if err := s.cache.AckUsageRecordRetry(ctx, entry); err != nil {
+		slog.Warn("usage_record_retry.ack_failed", "entry_id", entry.ID, "error", err)
+	}
+}
+
+// replay 以入队时的请求标识重放 RecordUsage（不再二次入队）
+func (s *UsageRecordRetryService) replay(ctx context.Context, entry *UsageRecordRetryEntry) error {
+	replayCtx, cancel := context.WithTimeout(ctx, usageRecordRetryReplayTimeout)
+	defer cancel()
+	if entry.ClientRequestID != "" {
+		replayCtx = context.WithValue(replayCtx, ctxkey.ClientRequestID, entry.ClientRequestID)
+	}
+	if entry.TraceID != "" {
+		replayCtx = context.WithValue(replayCtx, ctxkey.RequestID, entry.TraceID)
+	}
+	replayCtx = WithBillingVerification(replayCtx)
+	if entry.BillingUnverified {
+		markBillingUnverified(replayCtx)
+	}
+	if entry.CostEstimate != nil {
+		replayCtx = WithRequestCostEstimate(replayCtx, entry.CostEstimate)
+	}
+
+	switch {
+	case entry.Gateway != nil && s.gateway != nil:
+		input := *entry.Gateway
+		input.APIKeyService = s.apiKeyService
+		return s.gateway.recordUsage(replayCtx, &input)
+	case entry.GatewayLongContext != nil && s.gateway != nil:
+		input := *entry.GatewayLongContext
+		input.APIKeyService = s.apiKeyService
+		return s.gateway.recordUsageWithLongContext(replayCtx, &input)
+	case entry.OpenAI != nil && s.openAI != nil:
+		input := *entry.OpenAI
+		input.APIKeyService = s.apiKeyService
+		return s.openAI.recordUsage(replayCtx, &input)
+	}
+	return errors.New("usage retry entry has no replayable input for kind " + entry.Kind)
+}
+
+// backoff 第 attempts 次失败后的等待时间：base * 2^attempts，不超过 max
+func (s *UsageRecordRetryService) backoff(attempts int) time.Duration {
+	base := time.Duration(s.cfg.BaseBackoffSeconds) * time.Second
+	maxBackoff := time.Duration(s.cfg.MaxBackoffSeconds) * time.Second
+	delay := base
+	for i := 0; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if maxBackoff > 0 && delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+func truncateUsageRecordRetryError(err error) string {
+	msg := err.Error()
+	if len(msg) > usageRecordRetryMaxErrorLen {
+		msg = msg[:usageRecordRetryMaxErrorLen]
+	}
+	return msg
+}
+
+// sanitizeUsageRecordRetryRefs 复制计费所需的实体并去除敏感字段（API Key 明文、账号凭证与代理、
+// 密码哈希、TOTP 密钥），避免写入 Redis；关联的分组/账号列表也一并裁剪。
+func sanitizeUsageRecordRetryRefs(apiKey *APIKey, user *User, account *Account) (*APIKey, *User, *Account) {
+	var (
+		sanitizedKey     *APIKey
+		sanitizedUser    *User
+		sanitizedAccount *Account
+	)
+	if apiKey != nil {
+		k := *apiKey
+		k.Key = ""
+		k.User = nil
+		if apiKey.Group != nil {
+			g := *apiKey.Group
+			g.AccountGroups = nil
+			k.Group = &g
+		}
+		sanitizedKey = &k
+	}
+	if user != nil {
+		u := *user
+		u.PasswordHash = ""
+		u.TotpSecretEncrypted = nil
+		sanitizedUser = &u
+	}
+	if account != nil {
+		a := Account{
+			ID:             account.ID,
+			Name:           account.Name,
+			Platform:       account.Platform,
+			Type:           account.Type,
+			Extra:          account.Extra,
+			RateMultiplier: account.RateMultiplier,
+			Status:         account.Status,
+			Schedulable:    account.Schedulable,
+		}
+		sanitizedAccount = &a
+	}
+	return sanitizedKey, sanitizedUser, sanitizedAccount
+}
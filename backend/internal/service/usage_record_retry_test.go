@@ -0,0 +1,339 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+// usageRetryCacheStub 内存版重试队列，条目经 JSON 往返以模拟 Redis 序列化
+type usageRetryCacheStub struct {
+	mu         sync.Mutex
+	pending    [][]byte
+	processing [][]byte
+	dead       [][]byte
+}
+
+func (s *usageRetryCacheStub) PushUsageRecordRetry(_ context.Context, entry *UsageRecordRetryEntry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, raw)
+	return nil
+}
+
+func (s *usageRetryCacheStub) PopUsageRecordRetry(_ context.Context) (*UsageRecordRetryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return nil, nil
+	}
+	raw := s.pending[0]
+	s.pending = s.pending[1:]
+	s.processing = append(s.processing, raw)
+	var entry UsageRecordRetryEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, err
+	}
+	entry.Receipt = string(raw)
+	return &entry, nil
+}
+
+func (s *usageRetryCacheStub) AckUsageRecordRetry(_ context.Context, entry *UsageRecordRetryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, raw := range s.processing {
+		if string(raw) == entry.Receipt {
+			s.processing = append(s.processing[:i], s.processing[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *usageRetryCacheStub) RestoreUsageRecordRetryProcessing(_ context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	restored := len(s.processing)
+	s.pending = append(s.processing, s.pending...)
+	s.processing = nil
+	return restored, nil
+}
+
+func (s *usageRetryCacheStub) UsageRecordRetryPendingCount(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.pending)), nil
+}
+
+func (s *usageRetryCacheStub) PushUsageRecordDeadLetter(_ context.Context, entry *UsageRecordRetryEntry, _ int) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dead = append(s.dead, raw)
+	return nil
+}
+
+func (s *usageRetryCacheStub) ListUsageRecordDeadLetters(_ context.Context, _ int) ([]UsageRecordRetryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]UsageRecordRetryEntry, 0, len(s.dead))
+	for _, raw := range s.dead {
+		var entry UsageRecordRetryEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// usageRetryDedupBillingRepo 模拟 usage_billing_dedup 唯一约束；down 为 true 时模拟数据库故障
+type usageRetryDedupBillingRepo struct {
+	down    bool
+	calls   int
+	applied map[string]int
+}
+
+func (r *usageRetryDedupBillingRepo) Apply(_ context.Context, cmd *UsageBillingCommand) (*UsageBillingApplyResult, error) {
+	r.calls++
+	if r.down {
+		return nil, errors.New("deadlock detected")
+	}
+	if r.applied == nil {
+		r.applied = map[string]int{}
+	}
+	key := cmd.RequestID + "|" + strconv.FormatInt(cmd.APIKeyID, 10)
+	r.applied[key]++
+	return &UsageBillingApplyResult{Applied: r.applied[key] == 1}, nil
+}
+
+// usageRetryUniqueLogRepo 模拟 usage_logs (request_id, api_key_id) 唯一约束
+type usageRetryUniqueLogRepo struct {
+	UsageLogRepository
+
+	logs map[string]*UsageLog
+}
+
+func (r *usageRetryUniqueLogRepo) Create(_ context.Context, log *UsageLog) (bool, error) {
+	if r.logs == nil {
+		r.logs = map[string]*UsageLog{}
+	}
+	key := log.RequestID + "|" + strconv.FormatInt(log.APIKeyID, 10)
+	if _, ok := r.logs[key]; ok {
+		return false, nil
+	}
+	r.logs[key] = log
+	return true, nil
+}
+
+func newUsageRetryTestService(t *testing.T, cache UsageRecordRetryCache, gateway *GatewayService, openAI *OpenAIGatewayService) (*UsageRecordRetryService, *time.Time) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Billing.UsageRetry = config.BillingUsageRetryConfig{
+		Enabled:             true,
+		PollIntervalSeconds: 1,
+		BatchSize:           10,
+		BaseBackoffSeconds:  5,
+		MaxBackoffSeconds:   60,
+		MaxAgeSeconds:       3600,
+		DeadLetterMax:       10,
+	}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	svc := NewUsageRecordRetryService(cache, gateway, openAI, nil, cfg)
+	svc.now = func() time.Time { return now }
+	if gateway != nil {
+		gateway.SetUsageRecordRetryService(svc)
+	}
+	if openAI != nil {
+		openAI.SetUsageRecordRetryService(svc)
+	}
+	return svc, &now
+}
+
+func TestUsageRecordRetry_GatewayReplayAfterRecoveryIsExactlyOnce(t *testing.T) {
+	logRepo := &usageRetryUniqueLogRepo{}
+	billingRepo := &usageRetryDedupBillingRepo{down: true}
+	gateway := newGatewayRecordUsageServiceWithBillingRepoForTest(logRepo, billingRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	cache := &usageRetryCacheStub{}
+	retry, now := newUsageRetryTestService(t, cache, gateway, nil)
+
+	ctx := context.WithValue(context.Background(), ctxkey.RequestID, "trace-42")
+	err := gateway.RecordUsage(ctx, &RecordUsageInput{
+		Result: &ForwardResult{
+			RequestID: "upstream-1",
+			Usage:     ClaudeUsage{InputTokens: 10, OutputTokens: 6},
+			Model:     "claude-sonnet-4",
+			Duration:  time.Second,
+		},
+		APIKey:  &APIKey{ID: 501, Key: "sk-secret-key", Quota: 100},
+		User:    &User{ID: 601, PasswordHash: "bcrypt-hash"},
+		Account: &Account{ID: 701, Credentials: map[string]any{"api_key": "upstream-secret"}},
+	})
+	require.Error(t, err, "the original failure is still reported to the caller")
+	require.Len(t, cache.pending, 1)
+	require.NotContains(t, string(cache.pending[0]), "sk-secret-key")
+	require.NotContains(t, string(cache.pending[0]), "bcrypt-hash")
+	require.NotContains(t, string(cache.pending[0]), "upstream-secret")
+	require.Empty(t, logRepo.logs)
+
+	// 未到退避时间：放回队列，不重放
+	retry.ProcessDue(context.Background())
+	require.Equal(t, 1, billingRepo.calls)
+	require.Len(t, cache.pending, 1)
+
+	// 到期但数据库仍不可用：记一次失败，退避翻倍
+	*now = now.Add(5 * time.Second)
+	retry.ProcessDue(context.Background())
+	require.Equal(t, 2, billingRepo.calls)
+	entries := cache.pending
+	require.Len(t, entries, 1)
+	var entry UsageRecordRetryEntry
+	require.NoError(t, json.Unmarshal(entries[0], &entry))
+	require.Equal(t, 1, entry.Attempts)
+	require.Equal(t, now.Add(10*time.Second), entry.NextAttemptAt)
+
+	// 数据库恢复：重放成功，队列清空
+	billingRepo.down = false
+	*now = now.Add(10 * time.Second)
+	retry.ProcessDue(context.Background())
+	require.Empty(t, cache.pending)
+	require.Len(t, logRepo.logs, 1)
+	for _, log := range logRepo.logs {
+		require.Equal(t, "local:trace-42", log.RequestID, "replay keeps the original request id")
+		require.Equal(t, 10, log.InputTokens)
+	}
+
+	// 多实例竞争重放同一条目：唯一约束去重，不会重复扣费或重复记录
+	require.NoError(t, cache.PushUsageRecordRetry(context.Background(), &entry))
+	entry.NextAttemptAt = *now
+	require.NoError(t, cache.PushUsageRecordRetry(context.Background(), &entry))
+	retry.ProcessDue(context.Background())
+	require.Empty(t, cache.pending)
+	require.Empty(t, cache.processing)
+	require.Len(t, logRepo.logs, 1)
+	require.Len(t, billingRepo.applied, 1)
+	for _, n := range billingRepo.applied {
+		require.Equal(t, 3, n, "one applied write and two de-duplicated replays")
+	}
+}
+
+func TestUsageRecordRetry_OpenAIPinsGeneratedRequestID(t *testing.T) {
+	logRepo := &usageRetryUniqueLogRepo{}
+	billingRepo := &usageRetryDedupBillingRepo{down: true}
+	openAI := newOpenAIRecordUsageServiceForTest(logRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{}, nil)
+	openAI.usageBillingRepo = billingRepo
+	cache := &usageRetryCacheStub{}
+	retry, now := newUsageRetryTestService(t, cache, nil, openAI)
+
+	// 无任何请求标识时，入队固定一个本地 ID，保证后续重放命中同一去重键
+	err := openAI.RecordUsage(context.Background(), &OpenAIRecordUsageInput{
+		Result: &OpenAIForwardResult{
+			Usage:    OpenAIUsage{InputTokens: 8, OutputTokens: 4},
+			Model:    "gpt-5.1",
+			Duration: time.Second,
+		},
+		APIKey:  &APIKey{ID: 502},
+		User:    &User{ID: 602},
+		Account: &Account{ID: 702, Platform: PlatformOpenAI},
+	})
+	require.Error(t, err)
+	require.Len(t, cache.pending, 1)
+
+	billingRepo.down = false
+	*now = now.Add(time.Minute)
+	retry.ProcessDue(context.Background())
+	require.Len(t, logRepo.logs, 1)
+	for _, log := range logRepo.logs {
+		require.Regexp(t, `^local:usage-retry-`, log.RequestID)
+	}
+}
+
+func TestUsageRecordRetry_ExpiredEntryMovesToDeadLetter(t *testing.T) {
+	billingRepo := &usageRetryDedupBillingRepo{down: true}
+	gateway := newGatewayRecordUsageServiceWithBillingRepoForTest(&usageRetryUniqueLogRepo{}, billingRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	cache := &usageRetryCacheStub{}
+	retry, now := newUsageRetryTestService(t, cache, gateway, nil)
+
+	err := gateway.RecordUsage(context.Background(), &RecordUsageInput{
+		Result:  &ForwardResult{RequestID: "upstream-dead", Usage: ClaudeUsage{InputTokens: 1}, Model: "claude-sonnet-4"},
+		APIKey:  &APIKey{ID: 503},
+		User:    &User{ID: 603},
+		Account: &Account{ID: 703},
+	})
+	require.Error(t, err)
+
+	*now = now.Add(time.Hour)
+	retry.ProcessDue(context.Background())
+	require.Empty(t, cache.pending)
+	require.Empty(t, cache.processing)
+
+	dead, err := retry.ListDeadLetters(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, UsageRecordRetryKindGateway, dead[0].Kind)
+	require.Equal(t, 1, dead[0].Attempts)
+	require.Contains(t, dead[0].LastError, "deadlock detected")
+}
+
+// 出队后、确认前进程退出：条目留在处理中列表，重启时移回待重试队列并恰好重放一次
+func TestUsageRecordRetry_CrashBeforeAckIsRestoredOnStart(t *testing.T) {
+	logRepo := &usageRetryUniqueLogRepo{}
+	billingRepo := &usageRetryDedupBillingRepo{down: true}
+	gateway := newGatewayRecordUsageServiceWithBillingRepoForTest(logRepo, billingRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	cache := &usageRetryCacheStub{}
+	retry, now := newUsageRetryTestService(t, cache, gateway, nil)
+
+	err := gateway.RecordUsage(context.Background(), &RecordUsageInput{
+		Result:  &ForwardResult{RequestID: "upstream-crash", Usage: ClaudeUsage{InputTokens: 2}, Model: "claude-sonnet-4"},
+		APIKey:  &APIKey{ID: 504},
+		User:    &User{ID: 604},
+		Account: &Account{ID: 704},
+	})
+	require.Error(t, err)
+
+	// 模拟进程在出队后、处理前退出
+	entry, err := cache.PopUsageRecordRetry(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	require.Empty(t, cache.pending)
+	require.Len(t, cache.processing, 1)
+
+	billingRepo.down = false
+	*now = now.Add(time.Minute)
+	retry.restoreProcessing(context.Background())
+	require.Len(t, cache.pending, 1)
+	require.Empty(t, cache.processing)
+
+	retry.ProcessDue(context.Background())
+	require.Empty(t, cache.pending)
+	require.Empty(t, cache.processing)
+	require.Len(t, logRepo.logs, 1)
+}
+
+func TestUsageRecordRetry_Backoff(t *testing.T) {
+	svc := NewUsageRecordRetryService(nil, nil, nil, nil, &config.Config{Billing: config.BillingConfig{
+		UsageRetry: config.BillingUsageRetryConfig{BaseBackoffSeconds: 5, MaxBackoffSeconds: 60},
+	}})
+	require.Equal(t, 5*time.Second, svc.backoff(0))
+	require.Equal(t, 10*time.Second, svc.backoff(1))
+	require.Equal(t, 40*time.Second, svc.backoff(3))
+	require.Equal(t, 60*time.Second, svc.backoff(4))
+	require.Equal(t, 60*time.Second, svc.backoff(30))
+}
@@ -239,11 +239,18 @@ func ProvideDeferredService(accountRepo AccountRepository, timingWheel *TimingWh
 // ProvideConcurrencyService creates ConcurrencyService and starts slot cleanup worker.
 func ProvideConcurrencyService(cache ConcurrencyCache, accountRepo AccountRepository, cfg *config.Config) *ConcurrencyService {
 	svc := NewConcurrencyService(cache)
+	// 先写入本实例心跳，再回收失联实例遗留槽位，避免并发启动的实例互相误删
+	var heartbeatGrace time.Duration
+	if cfg != nil {
+		heartbeatGrace = time.Duration(cfg.Concurrency.InstanceHeartbeatGraceSeconds) * time.Second
+	}
+	svc.StartInstanceHeartbeat(heartbeatGrace)
 	if err := svc.CleanupStaleProcessSlots(context.Background()); err != nil {
 		logger.LegacyPrintf("service.concurrency", "Warning: startup cleanup stale process slots failed: %v", err)
 	}
 	if cfg != nil {
 		svc.SetAccountLoadBatchCacheTTL(time.Duration(cfg.Gateway.Scheduling.LoadBatchCacheTTLMS) * time.Millisecond)
+		svc.SetWaitQueuePolicy(WaitQueuePolicyFromConfig(cfg.Concurrency))
 		svc.StartSlotCleanupWorker(accountRepo, cfg.Gateway.Scheduling.SlotCleanupInterval)
 	}
 	return svc
@@ -401,6 +408,13 @@ func ProvideIdempotencyCleanupService(repo IdempotencyRepository, cfg *config.Co
 	return svc
 }
 
+// ProvideAuditLogService creates AuditLogService and starts the retention cleanup loop.
+func ProvideAuditLogService(repo AuditLogRepository, cfg *config.Config) *AuditLogService {
+	svc := NewAuditLogService(repo, cfg)
+	svc.Start()
+	return svc
+}
+
 // ProvideScheduledTestService creates ScheduledTestService.
 func ProvideScheduledTestService(
 	planRepo ScheduledTestPlanRepository,
@@ -523,8 +537,11 @@ func ProvideBillingCacheService(
 	rateRepo UserGroupRateRepository,
 	cfg *config.Config,
 	userPlatformQuotaRepo UserPlatformQuotaRepository,
+	groupSpendCapCache GroupSpendCapCache,
 ) *BillingCacheService {
-	return NewBillingCacheService(cache, userRepo, subRepo, apiKeyRepo, rpmCache, rateRepo, cfg, userPlatformQuotaRepo)
+	svc := NewBillingCacheService(cache, userRepo, subRepo, apiKeyRepo, rpmCache, rateRepo, cfg, userPlatformQuotaRepo)
+	svc.SetGroupSpendCapCache(groupSpendCapCache)
+	return svc
 }
 
 // ProvideAPIKeyService wires APIKeyService and connects rate-limit cache invalidation.
@@ -623,11 +640,16 @@ var ProviderSet = wire.NewSet(
 	NewUsageCache,
 	NewTotpService,
 	NewErrorPassthroughService,
+	NewAPIKeyCaptureService,
+	NewGatewayIdempotencyService,
+	NewGatewayResponseCacheService,
+	NewRequestCostService,
 	NewTLSFingerprintProfileService,
 	NewDigestSessionStore,
 	ProvideIdempotencyCoordinator,
 	ProvideSystemOperationLockService,
 	ProvideIdempotencyCleanupService,
+	ProvideAuditLogService,
 	ProvideScheduledTestService,
 	ProvideScheduledTestRunnerService,
 	NewGroupCapacityService,
@@ -638,6 +660,11 @@ var ProviderSet = wire.NewSet(
 	ProvidePaymentConfigService,
 	ProvidePaymentService,
 	ProvidePaymentOrderExpiryService,
+	ProvideAccountHealthProbeService,
+	ProvideUpstreamRateLimitTracker,
+	ProvideLogComponentLevelService,
+	ProvideAccountServerErrorTracker,
+	ProvideUsageRecordRetryService,
 	ProvideBalanceNotifyService,
 	ProvideChannelMonitorService,
 	ProvideChannelMonitorRunner,
@@ -658,10 +685,11 @@ func ProvidePaymentConfigService(entClient *dbent.Client, settingRepo SettingRep
 	return NewPaymentConfigService(entClient, settingRepo, []byte(key))
 }
 
-// ProvideBalanceNotifyService creates BalanceNotifyService
-func ProvideBalanceNotifyService(emailService *EmailService, settingRepo SettingRepository, accountRepo AccountRepository, notificationEmailService *NotificationEmailService) *BalanceNotifyService {
+// ProvideBalanceNotifyService creates BalanceNotifyService and registers it as the group spend cap alert hook.
+func ProvideBalanceNotifyService(emailService *EmailService, settingRepo SettingRepository, accountRepo AccountRepository, notificationEmailService *NotificationEmailService, billingCacheService *BillingCacheService) *BalanceNotifyService {
 	svc := NewBalanceNotifyService(emailService, settingRepo, accountRepo)
 	svc.SetNotificationEmailService(notificationEmailService)
+	billingCacheService.SetGroupSpendCapAlertHook(svc.NotifyGroupSpendNearCap)
 	return svc
 }
 
@@ -672,6 +700,79 @@ func ProvidePaymentService(entClient *dbent.Client, registry *payment.Registry,
 	return svc
 }
 
+// ProvideAccountHealthProbeService 创建并启动账号健康探测服务，并将探测结果接入选号
+func ProvideAccountHealthProbeService(
+	accountRepo AccountRepository,
+	cache AccountHealthCache,
+	httpUpstream HTTPUpstream,
+	geminiTokenProvider *GeminiTokenProvider,
+	tlsFPProfileService *TLSFingerprintProfileService,
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+	lockCache LeaderLockCache,
+	db *sql.DB,
+	cfg *config.Config,
+) *AccountHealthProbeService {
+	svc := NewAccountHealthProbeService(accountRepo, cache, httpUpstream, geminiTokenProvider, tlsFPProfileService, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	gatewayService.SetAccountHealthProbe(svc)
+	openAIGatewayService.SetAccountHealthProbe(svc)
+	svc.Start()
+	return svc
+}
+
+// ProvideUpstreamRateLimitTracker 创建上游限流余量跟踪器并接入选号
+func ProvideUpstreamRateLimitTracker(
+	cache UpstreamRateLimitCache,
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+	cfg *config.Config,
+) *UpstreamRateLimitTracker {
+	tracker := NewUpstreamRateLimitTracker(cache, cfg)
+	gatewayService.SetUpstreamRateLimitTracker(tracker)
+	openAIGatewayService.SetUpstreamRateLimitTracker(tracker)
+	tracker.Start()
+	return tracker
+}
+
+// ProvideLogComponentLevelService 创建运行时组件日志级别服务并启动多实例同步
+func ProvideLogComponentLevelService(store LogComponentLevelStore) *LogComponentLevelService {
+	svc := NewLogComponentLevelService(store)
+	svc.Start()
+	return svc
+}
+
+// ProvideAccountServerErrorTracker 创建上游 5xx 降权跟踪器，接入错误处理、选号与 ops 错误日志
+func ProvideAccountServerErrorTracker(
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+	rateLimitService *RateLimitService,
+	opsService *OpsService,
+	cfg *config.Config,
+) *AccountServerErrorTracker {
+	tracker := NewAccountServerErrorTracker(cfg)
+	rateLimitService.SetAccountServerErrorTracker(tracker)
+	gatewayService.SetAccountServerErrorTracker(tracker)
+	openAIGatewayService.SetAccountServerErrorTracker(tracker)
+	opsService.SetAccountServerErrorTracker(tracker)
+	return tracker
+}
+
+// ProvideUsageRecordRetryService 创建用量记录重试队列并接入各网关的 RecordUsage
+func ProvideUsageRecordRetryService(
+	cache UsageRecordRetryCache,
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+	apiKeyService *APIKeyService,
+	cfg *config.Config,
+) *UsageRecordRetryService {
+	svc := NewUsageRecordRetryService(cache, gatewayService, openAIGatewayService, apiKeyService, cfg)
+	gatewayService.SetUsageRecordRetryService(svc)
+	openAIGatewayService.SetUsageRecordRetryService(svc)
+	svc.Start()
+	return svc
+}
+
 // ProvidePaymentOrderExpiryService creates and starts PaymentOrderExpiryService.
 func ProvidePaymentOrderExpiryService(paymentSvc *PaymentService, lockCache LeaderLockCache, db *sql.DB) *PaymentOrderExpiryService {
 	svc := NewPaymentOrderExpiryService(paymentSvc, 60*time.Second)
@@ -79,6 +79,12 @@ func (c StubConcurrencyCache) CleanupExpiredAccountSlots(_ context.Context, _ in
 func (c StubConcurrencyCache) CleanupStaleProcessSlots(_ context.Context, _ string) error {
 	return nil
 }
+func (c StubConcurrencyCache) HeartbeatInstance(_ context.Context, _ string, _ time.Duration) error {
+	return nil
+}
+func (c StubConcurrencyCache) ReconcileStaleSlots(_ context.Context, _ string) (int, error) {
+	return 0, nil
+}
 
 // ============================================================
 // StubGatewayCache — service.GatewayCache 的空实现
@@ -73,6 +73,22 @@ func ExtractCloudflareRayID(headers http.Header, body []byte) string {
 	return ""
 }
 
+// upstreamRequestIDHeaders lists upstream request ID headers in lookup order.
+var upstreamRequestIDHeaders = []string{"x-request-id", "request-id", "xai-request-id", "x-goog-request-id"}
+
+// ExtractUpstreamRequestID extracts the upstream's own request ID from response headers,
+// falling back to the Cloudflare ray ID (header or challenge body) when none is present.
+func ExtractUpstreamRequestID(headers http.Header, body []byte) string {
+	if headers != nil {
+		for _, name := range upstreamRequestIDHeaders {
+			if id := strings.TrimSpace(headers.Get(name)); id != "" {
+				return id
+			}
+		}
+	}
+	return ExtractCloudflareRayID(headers, body)
+}
+
 // FormatCloudflareChallengeMessage appends cf-ray info when available.
 func FormatCloudflareChallengeMessage(base string, headers http.Header, body []byte) string {
 	rayID := ExtractCloudflareRayID(headers, body)
@@ -0,0 +1,59 @@
+package logredact
+
+import (
+	"net/http"
+	"strings"
+)
+
+// sensitiveHeaders 需要在日志中脱敏的 header（小写）
+var sensitiveHeaders = map[string]struct{}{
+	"authorization":                    {},
+	"proxy-authorization":              {},
+	"x-api-key":                        {},
+	"x-goog-api-key":                   {},
+	"cookie":                           {},
+	"set-cookie":                       {},
+	"__secure-next-auth.session-token": {},
+}
+
+// RedactHeaders 返回脱敏后的 header 副本，原 header 不受影响。
+//
+// Authorization 类 header 保留认证 scheme（如 "Bearer ***"），便于排查认证方式；
+// Cookie / Set-Cookie / session token 等整体替换为 "***"。
+func RedactHeaders(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	out := h.Clone()
+	for key, values := range out {
+		if !IsSensitiveHeader(key) {
+			continue
+		}
+		redacted := make([]string, len(values))
+		for i, v := range values {
+			redacted[i] = redactHeaderValue(v)
+		}
+		out[key] = redacted
+	}
+	return out
+}
+
+// IsSensitiveHeader 判断 header 是否需要在日志中脱敏（大小写不敏感）
+func IsSensitiveHeader(key string) bool {
+	_, ok := sensitiveHeaders[normalizeKey(key)]
+	return ok
+}
+
+func redactHeaderValue(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return ""
+	}
+	if scheme, _, ok := strings.Cut(v, " "); ok {
+		switch strings.ToLower(scheme) {
+		case "bearer", "basic":
+			return scheme + " ***"
+		}
+	}
+	return "***"
+}
@@ -0,0 +1,51 @@
+package logredact
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedactHeaders_MasksSensitiveValues(t *testing.T) {
+	const sessionToken = "eyJhbGciOiJkaXIiLCJlbmMiOiJBMjU2R0NNIn0.session-secret"
+	h := http.Header{}
+	h.Set("Authorization", "Bearer sk-ant-oat01-secret")
+	h.Set("Cookie", "__Secure-next-auth.session-token="+sessionToken+"; other=1")
+	h.Set("__Secure-next-auth.session-token", sessionToken)
+	h.Set("X-Api-Key", "sk-raw-key")
+	h.Add("Set-Cookie", "sid="+sessionToken)
+	h.Set("User-Agent", "claude-cli/2.0.0")
+
+	out := RedactHeaders(h)
+
+	dump := fmt.Sprint(out)
+	for _, secret := range []string{sessionToken, "sk-ant-oat01-secret", "sk-raw-key"} {
+		if strings.Contains(dump, secret) {
+			t.Fatalf("expected %q redacted, got %s", secret, dump)
+		}
+	}
+	if got := out.Get("Authorization"); got != "Bearer ***" {
+		t.Fatalf("expected auth scheme kept, got %q", got)
+	}
+	if got := out.Get("Cookie"); got != "***" {
+		t.Fatalf("expected cookie redacted, got %q", got)
+	}
+	if got := out.Get("User-Agent"); got != "claude-cli/2.0.0" {
+		t.Fatalf("expected non-sensitive header kept, got %q", got)
+	}
+}
+
+func TestRedactHeaders_DoesNotMutateInput(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer secret")
+
+	_ = RedactHeaders(h)
+
+	if got := h.Get("Authorization"); got != "Bearer secret" {
+		t.Fatalf("expected input unchanged, got %q", got)
+	}
+	if RedactHeaders(nil) != nil {
+		t.Fatalf("expected nil for nil input")
+	}
+}
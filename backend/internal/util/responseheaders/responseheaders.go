@@ -34,6 +34,30 @@ var defaultAllowed = map[string]struct{}{
 	"www-authenticate":               {},
 }
 
+// defaultPlatformAllowed 按上游平台内置的额外透传白名单（可被 platform_allowed 配置追加），
+// 仅在 security.response_headers.enabled 开启时生效。
+// 以 * 结尾的条目按前缀匹配。平台名与 service.Platform* 常量保持一致。
+var defaultPlatformAllowed = map[string][]string{
+	"anthropic": {"anthropic-ratelimit-*"},
+	"openai":    {"openai-processing-ms", "x-ratelimit-*"},
+}
+
+// sensitiveHeaders 认证/Cookie 相关头部，无论白名单如何配置都不会透传给客户端
+var sensitiveHeaders = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"proxy-authenticate":  {},
+	"cookie":              {},
+	"set-cookie":          {},
+	"set-cookie2":         {},
+	"x-api-key":           {},
+	"x-goog-api-key":      {},
+	"api-key":             {},
+}
+
+// rateLimitHeaderPrefixes 上游限流状态头部前缀，用于按 Key 改写模式下剥离上游账号的限流信息
+var rateLimitHeaderPrefixes = []string{"anthropic-ratelimit-", "x-ratelimit-", "ratelimit-"}
+
 // hopByHopHeaders 是跳过的 hop-by-hop 头部，这些头部由 HTTP 库自动处理
 var hopByHopHeaders = map[string]struct{}{
 	"content-length":    {},
@@ -42,25 +66,52 @@ var hopByHopHeaders = map[string]struct{}{
 }
 
 type CompiledHeaderFilter struct {
-	allowed     map[string]struct{}
-	forceRemove map[string]struct{}
+	allowed         map[string]struct{}
+	allowedPrefixes []string
+	forceRemove     map[string]struct{}
 }
 
 var defaultCompiledHeaderFilter = CompileHeaderFilter(config.ResponseHeaderConfig{})
 
+// CompileHeaderFilter 编译与平台无关的响应头过滤器
 func CompileHeaderFilter(cfg config.ResponseHeaderConfig) *CompiledHeaderFilter {
+	return CompileHeaderFilterForPlatform(cfg, "")
+}
+
+// CompileHeaderFilterForPlatform 编译指定上游平台的响应头过滤器：
+// 默认白名单 + 平台内置白名单 + additional_allowed + platform_allowed[platform]；关闭时仅默认白名单。
+func CompileHeaderFilterForPlatform(cfg config.ResponseHeaderConfig, platform string) *CompiledHeaderFilter {
+	platform = strings.ToLower(strings.TrimSpace(platform))
 	allowed := make(map[string]struct{}, len(defaultAllowed)+len(cfg.AdditionalAllowed))
 	for key := range defaultAllowed {
 		allowed[key] = struct{}{}
 	}
-	// 关闭时只使用默认白名单，additional/force_remove 不生效
+	var prefixes []string
+	addAllowed := func(key string) {
+		normalized := strings.ToLower(strings.TrimSpace(key))
+		if normalized == "" {
+			return
+		}
+		if prefix, ok := strings.CutSuffix(normalized, "*"); ok {
+			if prefix != "" {
+				prefixes = append(prefixes, prefix)
+			}
+			return
+		}
+		allowed[normalized] = struct{}{}
+	}
+	// 关闭时只使用默认白名单，平台内置白名单与 additional/platform_allowed/force_remove 均不生效
 	if cfg.Enabled {
+		for _, key := range defaultPlatformAllowed[platform] {
+			addAllowed(key)
+		}
 		for _, key := range cfg.AdditionalAllowed {
-			normalized := strings.ToLower(strings.TrimSpace(key))
-			if normalized == "" {
-				continue
+			addAllowed(key)
+		}
+		if platform != "" {
+			for _, key := range cfg.PlatformAllowed[platform] {
+				addAllowed(key)
 			}
-			allowed[normalized] = struct{}{}
 		}
 	}
 
@@ -77,9 +128,39 @@ func CompileHeaderFilter(cfg config.ResponseHeaderConfig) *CompiledHeaderFilter
 	}
 
 	return &CompiledHeaderFilter{
-		allowed:     allowed,
-		forceRemove: forceRemove,
+		allowed:         allowed,
+		allowedPrefixes: prefixes,
+		forceRemove:     forceRemove,
+	}
+}
+
+func (f *CompiledHeaderFilter) isAllowed(lower string) bool {
+	if _, ok := f.allowed[lower]; ok {
+		return true
+	}
+	for _, prefix := range f.allowedPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSensitiveHeader 是否为始终禁止透传的认证/Cookie 头部
+func IsSensitiveHeader(name string) bool {
+	_, ok := sensitiveHeaders[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// IsRateLimitHeader 是否为上游限流状态头部（anthropic-ratelimit-*、x-ratelimit-*、ratelimit-*）
+func IsRateLimitHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range rateLimitHeaderPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
 	}
+	return false
 }
 
 func FilterHeaders(src http.Header, filter *CompiledHeaderFilter) http.Header {
@@ -93,7 +174,10 @@ func FilterHeaders(src http.Header, filter *CompiledHeaderFilter) http.Header {
 		if _, blocked := filter.forceRemove[lower]; blocked {
 			continue
 		}
-		if _, ok := filter.allowed[lower]; !ok {
+		if _, sensitive := sensitiveHeaders[lower]; sensitive {
+			continue
+		}
+		if !filter.isAllowed(lower) {
 			continue
 		}
 		// 跳过 hop-by-hop 头部，这些由 HTTP 库自动处理
@@ -65,3 +65,94 @@ func TestFilterHeadersEnabledUsesAllowlist(t *testing.T) {
 		t.Fatalf("expected X-Blocked removed, got %q", filtered.Get("X-Blocked"))
 	}
 }
+
+func TestFilterHeadersPlatformAllowlist(t *testing.T) {
+	src := http.Header{}
+	src.Add("Content-Type", "application/json")
+	src.Add("Anthropic-Ratelimit-Requests-Remaining", "42")
+	src.Add("Anthropic-Ratelimit-Tokens-Reset", "2026-01-01T00:00:00Z")
+	src.Add("Openai-Processing-Ms", "321")
+	src.Add("X-Ratelimit-Reset-Requests", "1s")
+	src.Add("X-Gemini-Extra", "ok")
+	src.Add("X-Internal-Trace", "nope")
+
+	cfg := config.ResponseHeaderConfig{
+		Enabled:         true,
+		PlatformAllowed: map[string][]string{"gemini": {"x-gemini-*"}},
+	}
+
+	anthropic := FilterHeaders(src, CompileHeaderFilterForPlatform(cfg, "anthropic"))
+	if anthropic.Get("Anthropic-Ratelimit-Requests-Remaining") != "42" || anthropic.Get("Anthropic-Ratelimit-Tokens-Reset") == "" {
+		t.Fatalf("expected anthropic-ratelimit-* passthrough for anthropic, got %v", anthropic)
+	}
+	if anthropic.Get("Openai-Processing-Ms") != "" || anthropic.Get("X-Gemini-Extra") != "" || anthropic.Get("X-Internal-Trace") != "" {
+		t.Fatalf("expected non-allowlisted headers dropped for anthropic, got %v", anthropic)
+	}
+
+	openai := FilterHeaders(src, CompileHeaderFilterForPlatform(cfg, "openai"))
+	if openai.Get("Openai-Processing-Ms") != "321" || openai.Get("X-Ratelimit-Reset-Requests") != "1s" {
+		t.Fatalf("expected openai headers passthrough, got %v", openai)
+	}
+	if openai.Get("Anthropic-Ratelimit-Requests-Remaining") != "" {
+		t.Fatalf("expected anthropic-ratelimit-* dropped for openai, got %v", openai)
+	}
+
+	gemini := FilterHeaders(src, CompileHeaderFilterForPlatform(cfg, "gemini"))
+	if gemini.Get("X-Gemini-Extra") != "ok" {
+		t.Fatalf("expected configured prefix allowed for gemini, got %v", gemini)
+	}
+	if gemini.Get("X-Internal-Trace") != "" || gemini.Get("Anthropic-Ratelimit-Requests-Remaining") != "" {
+		t.Fatalf("expected non-allowlisted headers dropped for gemini, got %v", gemini)
+	}
+
+	// 关闭时平台配置与内置平台白名单均不生效
+	cfg.Enabled = false
+	disabled := FilterHeaders(src, CompileHeaderFilterForPlatform(cfg, "gemini"))
+	if disabled.Get("X-Gemini-Extra") != "" {
+		t.Fatalf("expected platform_allowed ignored when disabled, got %v", disabled)
+	}
+	disabledAnthropic := FilterHeaders(src, CompileHeaderFilterForPlatform(cfg, "anthropic"))
+	if disabledAnthropic.Get("Anthropic-Ratelimit-Requests-Remaining") != "" {
+		t.Fatalf("expected built-in platform allowlist ignored when disabled, got %v", disabledAnthropic)
+	}
+	disabledOpenAI := FilterHeaders(src, CompileHeaderFilterForPlatform(cfg, "openai"))
+	if disabledOpenAI.Get("Openai-Processing-Ms") != "" || disabledOpenAI.Get("X-Ratelimit-Reset-Requests") != "1s" {
+		t.Fatalf("expected only default allowlist when disabled, got %v", disabledOpenAI)
+	}
+}
+
+func TestFilterHeadersNeverPassesSensitiveHeaders(t *testing.T) {
+	src := http.Header{}
+	src.Add("Set-Cookie", "session=abc")
+	src.Add("Authorization", "Bearer upstream")
+	src.Add("X-Api-Key", "sk-upstream")
+	src.Add("Content-Type", "text/event-stream")
+
+	cfg := config.ResponseHeaderConfig{
+		Enabled:           true,
+		AdditionalAllowed: []string{"set-cookie", "authorization"},
+		PlatformAllowed:   map[string][]string{"anthropic": {"x-*"}},
+	}
+	filtered := FilterHeaders(src, CompileHeaderFilterForPlatform(cfg, "anthropic"))
+	for _, name := range []string{"Set-Cookie", "Authorization", "X-Api-Key"} {
+		if filtered.Get(name) != "" {
+			t.Fatalf("expected %s never passed through, got %q", name, filtered.Get(name))
+		}
+	}
+	if filtered.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected Content-Type allowed, got %q", filtered.Get("Content-Type"))
+	}
+}
+
+func TestIsRateLimitHeader(t *testing.T) {
+	for _, name := range []string{"anthropic-ratelimit-tokens-limit", "X-Ratelimit-Remaining-Requests", "RateLimit-Reset"} {
+		if !IsRateLimitHeader(name) {
+			t.Fatalf("expected %s to be a rate-limit header", name)
+		}
+	}
+	for _, name := range []string{"Retry-After", "Openai-Processing-Ms", "Content-Type"} {
+		if IsRateLimitHeader(name) {
+			t.Fatalf("expected %s not to be a rate-limit header", name)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+-- Add usage_estimated flag to usage_logs for records whose token counts were estimated because the upstream omitted usage.
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS usage_estimated BOOLEAN NOT NULL DEFAULT FALSE;
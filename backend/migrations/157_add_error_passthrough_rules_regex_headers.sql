@@ -0,0 +1,3 @@
+-- 错误透传规则：支持响应体正则与响应头匹配条件
+ALTER TABLE error_passthrough_rules ADD COLUMN IF NOT EXISTS body_regex TEXT;
+ALTER TABLE error_passthrough_rules ADD COLUMN IF NOT EXISTS header_matchers JSONB;
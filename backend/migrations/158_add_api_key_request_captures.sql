@@ -0,0 +1,23 @@
+-- API Key 请求/响应抓取：管理员按 Key 开启（capture_until 到期自动关闭），
+-- 记录最终发往上游的请求与上游响应（已脱敏、已截断），用于排查格式转换问题。
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS capture_until TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS api_key_request_captures (
+    id                 BIGSERIAL PRIMARY KEY,
+    api_key_id         BIGINT NOT NULL,               -- 不加外键，与 ops 表设计一致
+    account_id         BIGINT NOT NULL DEFAULT 0,
+    method             VARCHAR(16) NOT NULL DEFAULT '',
+    url                TEXT NOT NULL DEFAULT '',
+    request_headers    JSONB,
+    request_body       TEXT NOT NULL DEFAULT '',
+    request_truncated  BOOLEAN NOT NULL DEFAULT FALSE,
+    response_status    INT NOT NULL DEFAULT 0,
+    response_headers   JSONB,
+    response_body      TEXT NOT NULL DEFAULT '',
+    response_truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    error_message      TEXT NOT NULL DEFAULT '',
+    duration_ms        BIGINT NOT NULL DEFAULT 0,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS apikeyrequestcapture_api_key_id_created_at
+    ON api_key_request_captures (api_key_id, created_at DESC);
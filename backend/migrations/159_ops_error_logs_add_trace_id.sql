@@ -0,0 +1,8 @@
+-- 请求 trace ID：与入口 request_id（X-Request-ID / X-Trace-ID 响应头）同值。
+-- ops_error_logs.request_id 取自最终响应头，透传上游响应时可能是上游的 request id，
+-- 因此单独落库本服务的 trace ID，便于按客户端拿到的 trace ID 反查错误记录与系统日志。
+SET LOCAL lock_timeout = '5s';
+SET LOCAL statement_timeout = '10min';
+
+ALTER TABLE ops_error_logs
+    ADD COLUMN IF NOT EXISTS trace_id VARCHAR(64);
@@ -0,0 +1,6 @@
+-- 160_add_ops_error_logs_trace_id_index_notx.sql
+-- Ops 错误日志按 trace_id 精确检索所需的部分索引。
+-- 非事务迁移（_notx）：CREATE INDEX CONCURRENTLY 不可在事务内执行。
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_ops_error_logs_trace_id
+  ON ops_error_logs (trace_id)
+  WHERE trace_id IS NOT NULL;
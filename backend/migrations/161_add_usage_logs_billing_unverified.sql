@@ -0,0 +1,2 @@
+-- Add billing_unverified flag to usage_logs for requests admitted under billing degraded mode (eligibility could not be verified).
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS billing_unverified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Add raw request/response byte counts to usage_logs (NULL when not measured, e.g. WebSocket turns).
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS request_bytes BIGINT;
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS response_bytes BIGINT;
@@ -0,0 +1,3 @@
+-- Add prompt_cache_inject to groups: auto-inject ephemeral cache_control breakpoints
+-- on system/tools when the client sent none (anthropic platform only).
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS prompt_cache_inject BOOLEAN NOT NULL DEFAULT false;
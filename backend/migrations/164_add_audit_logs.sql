@@ -0,0 +1,16 @@
+-- 管理员操作审计日志：记录谁在何时对账号 / 代理 / API Key / 错误透传规则做了什么改动。
+-- diff 为字段级 before/after（敏感字段已脱敏），按 audit.retention_days 定期清理。
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id             BIGSERIAL PRIMARY KEY,
+    actor_user_id  BIGINT NOT NULL DEFAULT 0,       -- 不加外键，管理员被删除后仍保留记录
+    action         VARCHAR(32) NOT NULL,
+    entity_type    VARCHAR(64) NOT NULL,
+    entity_id      BIGINT NOT NULL DEFAULT 0,
+    diff           JSONB,
+    ip             VARCHAR(64) NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS auditlog_entity_type_entity_id_created_at
+    ON audit_logs (entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS auditlog_created_at
+    ON audit_logs (created_at);
@@ -0,0 +1,5 @@
+-- Add account_labels to api_keys: optional account label selector.
+-- When set, only accounts carrying ALL listed labels (accounts.extra.labels) are scheduled for this key.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS account_labels JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN api_keys.account_labels IS 'JSON array of required account labels, e.g. ["premium"]';
@@ -0,0 +1,5 @@
+-- Opt-in response cache for deterministic (temperature 0, non-streaming) requests.
+-- api_keys.response_cache_enabled: cache eligible responses for this key without requiring the X-Cache request header.
+-- usage_logs.response_cache_hit: the response was served from the cache (no upstream call, not billed).
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS response_cache_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS response_cache_hit BOOLEAN NOT NULL DEFAULT FALSE;
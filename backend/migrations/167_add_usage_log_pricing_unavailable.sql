@@ -0,0 +1,4 @@
+-- Mark usage logs whose model had no configured or synced price.
+-- Tokens are still recorded; the cost columns stay 0 and the request is not billed,
+-- so reports can tell "unknown cost" apart from a genuinely free request.
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS pricing_unavailable BOOLEAN NOT NULL DEFAULT FALSE;
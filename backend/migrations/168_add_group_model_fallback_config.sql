@@ -0,0 +1,5 @@
+-- 分组级模型降级配置：请求模型无可用账号时按规则依次尝试降级模型。
+-- rules 为有序列表（requested model -> fallback models），mask_fallback 控制响应 model 是否回写为原请求模型。
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS model_fallback_config JSONB NOT NULL DEFAULT '{}'::jsonb;
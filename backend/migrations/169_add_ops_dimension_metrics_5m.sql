@@ -0,0 +1,39 @@
+-- Ops 维度预聚合（5 分钟粒度）：按 model / account / group 汇总请求量、错误、failover、token 与延迟直方图。
+-- 延迟分位数由固定桶直方图合并后估算，因此任意窗口（5m/1h/24h）都可以由多个 5 分钟桶直接相加。
+-- dimension_key 为维度取值的字符串形式（模型名 / 账号 ID / 分组 ID），缺失值记为 'unknown'。
+
+SET LOCAL lock_timeout = '5s';
+SET LOCAL statement_timeout = '10min';
+
+CREATE TABLE IF NOT EXISTS ops_dimension_metrics_5m (
+    id BIGSERIAL PRIMARY KEY,
+
+    bucket_start TIMESTAMPTZ NOT NULL,
+    dimension VARCHAR(16) NOT NULL,
+    dimension_key VARCHAR(128) NOT NULL,
+
+    request_count BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,
+    failover_count BIGINT NOT NULL DEFAULT 0,
+
+    total_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+
+    latency_count BIGINT NOT NULL DEFAULT 0,
+    latency_sum_ms BIGINT NOT NULL DEFAULT 0,
+    latency_max_ms BIGINT NOT NULL DEFAULT 0,
+    latency_histogram BIGINT[] NOT NULL DEFAULT '{}',
+
+    ttft_count BIGINT NOT NULL DEFAULT 0,
+    ttft_sum_ms BIGINT NOT NULL DEFAULT 0,
+    ttft_max_ms BIGINT NOT NULL DEFAULT 0,
+    ttft_histogram BIGINT[] NOT NULL DEFAULT '{}',
+
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ops_dimension_metrics_5m_unique
+    ON ops_dimension_metrics_5m (bucket_start, dimension, dimension_key);
+
+CREATE INDEX IF NOT EXISTS idx_ops_dimension_metrics_5m_dimension_bucket
+    ON ops_dimension_metrics_5m (dimension, bucket_start DESC);
@@ -0,0 +1,7 @@
+-- Per-request cost preview and hard cost ceilings.
+-- api_keys.cost_preview_enabled: return the pre-request cost estimate in the X-Estimated-Cost response header.
+-- usage_logs.estimated_cost: cost estimated before forwarding (only when a max_cost ceiling or cost preview applied).
+-- usage_logs.cost_overage: the actual cost exceeded the estimate by more than gateway.request_cost.overage_factor.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS cost_preview_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS estimated_cost NUMERIC(20,10);
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS cost_overage BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- Add scopes to api_keys: endpoint categories the key may call (chat / images / video / embeddings).
+-- NULL (or an empty array) means all scopes, so existing keys keep full access without a backfill.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN api_keys.scopes IS 'JSON array of allowed endpoint scopes, e.g. ["chat","images"]; NULL = all scopes';
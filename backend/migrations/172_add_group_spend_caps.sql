@@ -0,0 +1,7 @@
+-- 分组级消费上限：按自然日/自然月累计分组内所有请求的实际扣费，达到上限后拒绝新请求。
+-- NULL 表示不限制；spend_cap_timezone 为空时按系统时区划分周期边界。
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS spend_cap_daily_usd DECIMAL(20,8),
+    ADD COLUMN IF NOT EXISTS spend_cap_monthly_usd DECIMAL(20,8),
+    ADD COLUMN IF NOT EXISTS spend_cap_timezone VARCHAR(64) NOT NULL DEFAULT '';
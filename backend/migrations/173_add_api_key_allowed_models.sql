@@ -0,0 +1,5 @@
+-- Add allowed_models to api_keys: models the key may call, matched after alias resolution.
+-- NULL (or an empty array) means all models, so existing keys keep full access without a backfill.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_models JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN api_keys.allowed_models IS 'JSON array of allowed model names or trailing-* prefixes, e.g. ["gpt-5.1","gpt-5*"]; NULL = all models';
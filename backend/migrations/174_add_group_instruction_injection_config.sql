@@ -0,0 +1,5 @@
+-- 分组级 instructions 注入：对该分组的 OpenAI Responses 请求按 mode（prepend / if_absent / replace）注入合规提示或路由提示。
+-- 默认 {}（未启用），历史分组行为不变。
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS instruction_injection_config JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,7 @@
+-- 记录出错请求是否按分组配置注入了 instructions，便于在 /admin/ops 错误详情区分
+-- “分组注入文本引发的上游拒绝”与客户端自身请求问题。历史行默认 false。
+SET LOCAL lock_timeout = '5s';
+SET LOCAL statement_timeout = '10min';
+
+ALTER TABLE ops_error_logs
+    ADD COLUMN IF NOT EXISTS instructions_injected BOOLEAN NOT NULL DEFAULT FALSE;
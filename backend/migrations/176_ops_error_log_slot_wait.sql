@@ -0,0 +1,9 @@
+-- 记录出错请求的并发槽位等待耗时与获取路径（如 "user=fast,account=wait"），
+-- 便于在 /admin/ops 错误详情区分「上游慢」与「排队慢」。历史行为 NULL。
+SET LOCAL lock_timeout = '5s';
+SET LOCAL statement_timeout = '10min';
+
+ALTER TABLE ops_error_logs
+    ADD COLUMN IF NOT EXISTS user_slot_wait_ms BIGINT,
+    ADD COLUMN IF NOT EXISTS account_slot_wait_ms BIGINT,
+    ADD COLUMN IF NOT EXISTS slot_acquire_path TEXT;
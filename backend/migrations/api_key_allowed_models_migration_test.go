@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// 历史 Key 升级后 allowed_models 为 NULL，即可调用全部模型；迁移不得回填或强制非空。
+func TestMigration173KeepsLegacyAPIKeysUnrestricted(t *testing.T) {
+	content, err := FS.ReadFile("173_add_api_key_allowed_models.sql")
+	require.NoError(t, err)
+
+	sql := string(content)
+	require.Contains(t, sql, "ADD COLUMN IF NOT EXISTS allowed_models JSONB DEFAULT NULL")
+	require.NotContains(t, sql, "NOT NULL")
+	require.NotContains(t, sql, "UPDATE api_keys")
+}
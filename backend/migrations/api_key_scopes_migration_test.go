@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// 历史 Key 升级后 scopes 为 NULL，即拥有全部作用域；迁移不得回填或强制非空。
+func TestMigration171KeepsLegacyAPIKeysUnscoped(t *testing.T) {
+	content, err := FS.ReadFile("171_add_api_key_scopes.sql")
+	require.NoError(t, err)
+
+	sql := string(content)
+	require.Contains(t, sql, "ADD COLUMN IF NOT EXISTS scopes JSONB DEFAULT NULL")
+	require.NotContains(t, sql, "NOT NULL")
+	require.NotContains(t, sql, "UPDATE api_keys")
+}
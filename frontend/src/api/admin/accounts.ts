@@ -16,6 +16,8 @@ import type {
   TempUnschedulableStatus,
   AdminDataPayload,
   AdminDataImportResult,
+  AdminEncryptedDataBundle,
+  AdminDataConflictStrategy,
   CodexSessionImportRequest,
   CodexSessionImportResult,
   OpenAICodexPATCreateRequest,
@@ -451,6 +453,57 @@ export async function getBatchTodayStats(accountIds: number[]): Promise<BatchTod
   return data
 }
 
+export interface AccountHealthStatus {
+  account_id: number
+  platform: string
+  probe_type: string
+  healthy: boolean
+  consecutive_failures: number
+  latency_ms: number
+  status_code?: number
+  error?: string
+  checked_at: string
+  last_success_at?: string
+}
+
+export interface UpstreamRateLimitWindow {
+  limit?: number
+  remaining?: number
+  reset_at?: string
+}
+
+export interface UpstreamRateLimitSnapshot {
+  account_id: number
+  platform: string
+  requests?: UpstreamRateLimitWindow
+  tokens?: UpstreamRateLimitWindow
+  observed_at: string
+}
+
+export interface AccountServerErrorPenalty {
+  account_id: number
+  until: string
+  last_status: number
+}
+
+export interface AccountHealthResponse {
+  enabled: boolean
+  statuses: AccountHealthStatus[]
+  rate_limits_enabled: boolean
+  rate_limits: UpstreamRateLimitSnapshot[]
+  server_error_penalties_enabled: boolean
+  server_error_penalties: AccountServerErrorPenalty[]
+}
+
+/**
+ * 获取账号主动健康探测结果、上游限流余量及 5xx 降权状态
+ * @returns 是否启用探测及各账号最近一次探测结果、上游响应头报告的限流余量、当前处于 5xx 降权期的账号
+ */
+export async function getHealth(): Promise<AccountHealthResponse> {
+  const { data } = await apiClient.get<AccountHealthResponse>('/admin/accounts/health')
+  return data
+}
+
 /**
  * Set account schedulable status
  * @param id - Account ID
@@ -608,6 +661,26 @@ export async function importData(payload: {
   return data
 }
 
+export async function exportEncryptedData(payload: {
+  ids?: number[]
+  include_proxies?: boolean
+  passphrase: string
+}): Promise<AdminEncryptedDataBundle> {
+  const { data } = await apiClient.post<AdminEncryptedDataBundle>('/admin/accounts/data/encrypted-export', payload)
+  return data
+}
+
+export async function importEncryptedData(payload: {
+  bundle: AdminEncryptedDataBundle
+  passphrase: string
+  conflict_strategy?: AdminDataConflictStrategy
+  dry_run?: boolean
+  skip_default_group_bind?: boolean
+}): Promise<AdminDataImportResult> {
+  const { data } = await apiClient.post<AdminDataImportResult>('/admin/accounts/data/encrypted-import', payload)
+  return data
+}
+
 export async function importCodexSession(payload: CodexSessionImportRequest): Promise<CodexSessionImportResult> {
   const { data } = await apiClient.post<CodexSessionImportResult>('/admin/accounts/import/codex-session', payload)
   return data
@@ -798,6 +871,7 @@ export const accountsAPI = {
   getUsage,
   getTodayStats,
   getBatchTodayStats,
+  getHealth,
   clearRateLimit,
   recoverState,
   resetAccountQuota,
@@ -817,6 +891,8 @@ export const accountsAPI = {
   syncFromCrs,
   exportData,
   importData,
+  exportEncryptedData,
+  importEncryptedData,
   importCodexSession,
   createOpenAICodexPAT,
   getAntigravityDefaultModelMapping,
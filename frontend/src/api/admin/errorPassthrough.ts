@@ -5,6 +5,14 @@
 
 import { apiClient } from '../client'
 
+/**
+ * Response header condition (all matchers must match)
+ */
+export interface HeaderMatcher {
+  name: string
+  regex: string
+}
+
 /**
  * Error passthrough rule interface
  */
@@ -23,6 +31,8 @@ export interface ErrorPassthroughRule {
   custom_message: string | null
   skip_monitoring: boolean
   description: string | null
+  body_regex: string | null
+  header_matchers: HeaderMatcher[]
   created_at: string
   updated_at: string
 }
@@ -44,6 +54,8 @@ export interface CreateRuleRequest {
   custom_message?: string | null
   skip_monitoring?: boolean
   description?: string | null
+  body_regex?: string | null
+  header_matchers?: HeaderMatcher[]
 }
 
 /**
@@ -63,6 +75,8 @@ export interface UpdateRuleRequest {
   custom_message?: string | null
   skip_monitoring?: boolean
   description?: string | null
+  body_regex?: string | null
+  header_matchers?: HeaderMatcher[]
 }
 
 /**
@@ -125,13 +139,56 @@ export async function toggleEnabled(id: number, enabled: boolean): Promise<Error
   return update(id, { enabled })
 }
 
+/**
+ * Dry-run request: a simulated upstream error response
+ */
+export interface DryRunRequest {
+  platform: string
+  status_code: number
+  headers?: Record<string, string>
+  body?: string
+  include_disabled?: boolean
+}
+
+/**
+ * Dry-run result
+ */
+export interface DryRunResult {
+  matched: boolean
+  rule?: ErrorPassthroughRule
+  response_code?: number
+  message?: string
+  skip_monitoring: boolean
+}
+
+/**
+ * Test which rule matches a simulated upstream error, without side effects
+ * @param request - Simulated upstream error
+ * @returns Matched rule and the resulting response
+ */
+export async function dryRun(request: DryRunRequest): Promise<DryRunResult> {
+  const { data } = await apiClient.post<DryRunResult>('/admin/error-passthrough-rules/dry-run', request)
+  return data
+}
+
+/**
+ * Force reload rules from the database on all instances
+ * @returns Success confirmation
+ */
+export async function reload(): Promise<{ message: string }> {
+  const { data } = await apiClient.post<{ message: string }>('/admin/error-passthrough-rules/reload')
+  return data
+}
+
 export const errorPassthroughAPI = {
   list,
   getById,
   create,
   update,
   delete: deleteRule,
-  toggleEnabled
+  toggleEnabled,
+  dryRun,
+  reload
 }
 
 export default errorPassthroughAPI
@@ -301,6 +301,62 @@ export async function clearGroupRPMOverrides(id: number): Promise<{ message: str
   return data
 }
 
+export interface GroupSpendCapWindow {
+  period: string
+  cap_usd: number | null
+  spent_usd: number
+  remaining_usd: number | null
+  exceeded: boolean
+  resets_at: string
+}
+
+export interface GroupSpendCapOverride {
+  expires_at: string
+  reason?: string
+  granted_by?: number
+  granted_at: string
+}
+
+export interface GroupSpendCapStatus {
+  group_id: number
+  timezone: string
+  daily: GroupSpendCapWindow
+  monthly: GroupSpendCapWindow
+  override: GroupSpendCapOverride | null
+}
+
+/**
+ * Get current daily/monthly spend of a group against its spend caps.
+ */
+export async function getSpendCap(id: number): Promise<GroupSpendCapStatus> {
+  const { data } = await apiClient.get<GroupSpendCapStatus>(`/admin/groups/${id}/spend-cap`)
+  return data
+}
+
+/**
+ * Temporarily lift a group's spend caps.
+ * @param durationMinutes - Override duration (1 minute ~ 31 days)
+ */
+export async function grantSpendCapOverride(
+  id: number,
+  durationMinutes: number,
+  reason?: string
+): Promise<GroupSpendCapOverride> {
+  const { data } = await apiClient.post<GroupSpendCapOverride>(
+    `/admin/groups/${id}/spend-cap/override`,
+    { duration_minutes: durationMinutes, reason }
+  )
+  return data
+}
+
+/**
+ * Revoke an active spend cap override.
+ */
+export async function revokeSpendCapOverride(id: number): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(`/admin/groups/${id}/spend-cap/override`)
+  return data
+}
+
 /**
  * Get usage summary (today + cumulative cost) for all groups
  * @param timezone - IANA timezone string (e.g. "Asia/Shanghai")
@@ -348,6 +404,9 @@ export const groupsAPI = {
   getGroupRPMOverrides,
   clearGroupRPMOverrides,
   batchSetGroupRPMOverrides,
+  getSpendCap,
+  grantSpendCapOverride,
+  revokeSpendCapOverride,
   updateSortOrder,
   getUsageSummary,
   getCapacitySummary
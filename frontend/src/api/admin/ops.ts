@@ -244,6 +244,69 @@ export interface OpsOpenAITokenStatsParams {
   top_n?: number
 }
 
+export type OpsDimension = 'model' | 'account' | 'group'
+export type OpsDimensionWindow = '5m' | '1h' | '24h'
+export type OpsDimensionSource = 'raw' | 'preagg' | 'mixed'
+
+export interface OpsDimensionMetricsItem {
+  key: string
+  request_count: number
+  error_count: number
+  failover_count: number
+  error_rate: number
+  failover_rate: number
+  total_tokens: number
+  output_tokens: number
+  tokens_per_second: number
+  latency_p50_ms?: number | null
+  latency_p95_ms?: number | null
+  latency_p99_ms?: number | null
+  latency_avg_ms?: number | null
+  latency_max_ms?: number | null
+  ttft_p50_ms?: number | null
+  ttft_p95_ms?: number | null
+  ttft_p99_ms?: number | null
+}
+
+export interface OpsDimensionMetricsResponse {
+  dimension: OpsDimension
+  window: OpsDimensionWindow
+  start_time: string
+  end_time: string
+  source: OpsDimensionSource
+  items: OpsDimensionMetricsItem[]
+  total: number
+}
+
+export interface OpsDimensionMetricsParams {
+  dimension?: OpsDimension
+  window?: OpsDimensionWindow
+  limit?: number
+}
+
+export interface OpsTopOffenderItem {
+  account_id: number
+  request_count: number
+  error_count: number
+  failover_count: number
+  error_rate: number
+  error_share: number
+}
+
+export interface OpsTopOffendersResponse {
+  window: OpsDimensionWindow
+  start_time: string
+  end_time: string
+  source: OpsDimensionSource
+  total_errors: number
+  items: OpsTopOffenderItem[]
+}
+
+export interface OpsTopOffendersParams {
+  window?: OpsDimensionWindow
+  limit?: number
+}
+
 export interface OpsSystemMetricsSnapshot {
   id: number
   created_at: string
@@ -825,6 +888,19 @@ export interface OpsRuntimeLogConfig {
   updated_by_user_id?: number
 }
 
+export interface OpsLogComponentLevel {
+  component: string
+  level: 'debug' | 'info' | 'warn' | 'error'
+  default_level: 'debug' | 'info' | 'warn' | 'error'
+  revert_at?: string
+}
+
+export interface OpsLogComponentLevelUpdate {
+  component: string
+  level: 'debug' | 'info' | 'warn' | 'error'
+  revert_after_minutes?: number
+}
+
 export interface OpsSystemLog {
   id: number
   created_at: string
@@ -907,6 +983,8 @@ export interface OpsErrorLog {
 
   client_request_id: string
   request_id: string
+  // 本服务 trace ID（X-Trace-ID 响应头 / 错误体 error.trace_id），旧记录为空
+  trace_id?: string
   message: string
 
   user_id?: number | null
@@ -948,6 +1026,11 @@ export interface OpsErrorDetail extends OpsErrorLog {
   response_latency_ms?: number | null
   time_to_first_token_ms?: number | null
 
+  // Concurrency slot wait (accumulated per slot type) and acquire path, e.g. "user=fast,account=wait"
+  user_slot_wait_ms?: number | null
+  account_slot_wait_ms?: number | null
+  slot_acquire_path?: string
+
   is_business_limited: boolean
 
   // Deleted key owner info (INVALID_API_KEY attribution)
@@ -958,6 +1041,9 @@ export interface OpsErrorDetail extends OpsErrorLog {
 
   // Bound (non-deleted) key prefix, snapshotted at error time
   api_key_prefix?: string | null
+
+  // Group instructions were injected into the failed request
+  instructions_injected?: boolean
 }
 
 export type OpsErrorLogsResponse = PaginatedResponse<OpsErrorLog>
@@ -1081,6 +1167,28 @@ export async function getOpenAITokenStats(
   return data
 }
 
+export async function getDimensionMetrics(
+  params: OpsDimensionMetricsParams,
+  options: OpsRequestOptions = {}
+): Promise<OpsDimensionMetricsResponse> {
+  const { data } = await apiClient.get<OpsDimensionMetricsResponse>('/admin/ops/dashboard/dimension-metrics', {
+    params,
+    signal: options.signal
+  })
+  return data
+}
+
+export async function getTopOffenders(
+  params: OpsTopOffendersParams,
+  options: OpsRequestOptions = {}
+): Promise<OpsTopOffendersResponse> {
+  const { data } = await apiClient.get<OpsTopOffendersResponse>('/admin/ops/dashboard/top-offenders', {
+    params,
+    signal: options.signal
+  })
+  return data
+}
+
 export type OpsErrorListView = 'errors' | 'excluded' | 'all'
 
 export type OpsErrorListQueryParams = {
@@ -1264,6 +1372,23 @@ export async function resetRuntimeLogConfig(): Promise<OpsRuntimeLogConfig> {
   return data
 }
 
+export async function listLogComponentLevels(): Promise<OpsLogComponentLevel[]> {
+  const { data } = await apiClient.get<OpsLogComponentLevel[]>('/admin/ops/runtime/logging/components')
+  return data
+}
+
+export async function updateLogComponentLevel(payload: OpsLogComponentLevelUpdate): Promise<OpsLogComponentLevel> {
+  const { data } = await apiClient.put<OpsLogComponentLevel>('/admin/ops/runtime/logging/components', payload)
+  return data
+}
+
+export async function resetLogComponentLevel(component: string): Promise<OpsLogComponentLevel> {
+  const { data } = await apiClient.post<OpsLogComponentLevel>(
+    `/admin/ops/runtime/logging/components/${encodeURIComponent(component)}/reset`
+  )
+  return data
+}
+
 export async function listSystemLogs(params: OpsSystemLogQuery): Promise<OpsSystemLogListResponse> {
   const { data } = await apiClient.get<OpsSystemLogListResponse>('/admin/ops/system-logs', { params })
   return data
@@ -1309,6 +1434,8 @@ export const opsAPI = {
   getErrorTrend,
   getErrorDistribution,
   getOpenAITokenStats,
+  getDimensionMetrics,
+  getTopOffenders,
   getConcurrencyStats,
   getUserConcurrencyStats,
   getAccountAvailabilityStats,
@@ -1345,6 +1472,9 @@ export const opsAPI = {
   getRuntimeLogConfig,
   updateRuntimeLogConfig,
   resetRuntimeLogConfig,
+  listLogComponentLevels,
+  updateLogComponentLevel,
+  resetLogComponentLevel,
   getAdvancedSettings,
   updateAdvancedSettings,
   getMetricThresholds,
@@ -84,6 +84,7 @@ export interface AdminUsageQueryParams extends UsageQueryParams {
   user_id?: number
   exact_total?: boolean
   billing_mode?: string
+  scope?: string | null
   sort_by?: string
   sort_order?: 'asc' | 'desc'
 }
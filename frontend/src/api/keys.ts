@@ -4,7 +4,7 @@
  */
 
 import { apiClient } from './client'
-import type { ApiKey, CreateApiKeyRequest, UpdateApiKeyRequest, PaginatedResponse } from '@/types'
+import type { ApiKey, ApiKeyScope, CreateApiKeyRequest, UpdateApiKeyRequest, PaginatedResponse } from '@/types'
 
 /**
  * List all API keys for current user
@@ -55,6 +55,7 @@ export async function getById(id: number): Promise<ApiKey> {
  * @param quota - Optional quota limit in USD (0 = unlimited)
  * @param expiresInDays - Optional days until expiry (undefined = never expires)
  * @param rateLimitData - Optional rate limit fields
+ * @param accountLabels - Optional account labels the key is restricted to
  * @returns Created API key
  */
 export async function create(
@@ -65,7 +66,11 @@ export async function create(
   ipBlacklist?: string[],
   quota?: number,
   expiresInDays?: number,
-  rateLimitData?: { rate_limit_5h?: number; rate_limit_1d?: number; rate_limit_7d?: number }
+  rateLimitData?: { rate_limit_5h?: number; rate_limit_1d?: number; rate_limit_7d?: number },
+  accountLabels?: string[],
+  responseCacheEnabled?: boolean,
+  costPreviewEnabled?: boolean,
+  scopes?: ApiKeyScope[]
 ): Promise<ApiKey> {
   const payload: CreateApiKeyRequest = { name }
   if (groupId !== undefined) {
@@ -80,6 +85,18 @@ export async function create(
   if (ipBlacklist && ipBlacklist.length > 0) {
     payload.ip_blacklist = ipBlacklist
   }
+  if (accountLabels && accountLabels.length > 0) {
+    payload.account_labels = accountLabels
+  }
+  if (responseCacheEnabled) {
+    payload.response_cache_enabled = true
+  }
+  if (costPreviewEnabled) {
+    payload.cost_preview_enabled = true
+  }
+  if (scopes && scopes.length > 0) {
+    payload.scopes = scopes
+  }
   if (quota !== undefined && quota > 0) {
     payload.quota = quota
   }
@@ -0,0 +1,4 @@
+import type { ApiKeyScope } from '@/types'
+
+/** API key endpoint scopes (must match service.AllAPIKeyScopes in Go). All selected = unrestricted. */
+export const API_KEY_SCOPES: ApiKeyScope[] = ['chat', 'images', 'video', 'embeddings']
@@ -815,6 +815,21 @@ export default {
     ipBlacklist: 'IP Blacklist',
     ipBlacklistPlaceholder: '1.2.3.4\n5.6.0.0/16',
     ipBlacklistHint: 'One IP or CIDR per line. These IPs will be blocked from using this key.',
+    accountLabels: 'Account Labels',
+    accountLabelsPlaceholder: 'e.g. premium, eu',
+    accountLabelsHint: 'Comma-separated. Requests with this key are only routed to accounts that carry all of these labels. Clients can narrow further with the X-Account-Labels header.',
+    responseCache: 'Response Cache',
+    responseCacheHint: 'Return cached responses for identical non-streaming requests with temperature 0. Cache hits are not billed. Clients can also opt in per request with the X-Cache: true header.',
+    scopes: 'Scopes',
+    scopesHint: 'Endpoints this key may call. Requests to other endpoints are rejected with 403. Selecting all scopes leaves the key unrestricted.',
+    scopeNames: {
+      chat: 'Chat',
+      images: 'Images',
+      video: 'Video',
+      embeddings: 'Embeddings'
+    },
+    costPreview: 'Cost Preview',
+    costPreviewHint: 'Return the estimated cost of each request in the X-Estimated-Cost response header. Clients can cap a single request with the max_cost field or X-Max-Cost header; requests estimated above the cap are rejected with 402.',
     ipRestrictionEnabled: 'IP restriction enabled',
     ccSwitchNotInstalled: 'CC-Switch is not installed or the protocol handler is not registered. Please install CC-Switch first or manually copy the API key.',
     ccsClientSelect: {
@@ -875,6 +890,11 @@ export default {
     costDetails: 'Cost Breakdown',
     tokenDetails: 'Token Breakdown',
     cacheTtlOverriddenHint: 'Cache TTL Override enabled',
+    responseCacheHit: 'Cached',
+    responseCacheHitHint: 'Served from the response cache without calling upstream; not billed',
+    costOverage: 'Over estimate',
+    costOverageHint: 'Actual cost exceeded the pre-request estimate ({estimated}) by more than the configured factor',
+    pricingUnavailableHint: 'No price is configured for this model; tokens were recorded but the request was not billed',
     cacheTtlOverriddenLabel: 'TTL Override',
     cacheTtlOverridden5m: 'Billed as 5m',
     cacheTtlOverridden1h: 'Billed as 1h',
@@ -2160,7 +2180,12 @@ export default {
         exclusive: 'Exclusive Group',
         rpmLimit: 'Requests Per Minute (RPM)',
         rpmLimitPlaceholder: '0 = unlimited',
-        rpmLimitHint: 'Max requests per minute for each user in this group; 0 = unlimited. Once set, it takes over per-user rate limiting in this group (overrides the user-level rpm_limit fallback).'
+        rpmLimitHint: 'Max requests per minute for each user in this group; 0 = unlimited. Once set, it takes over per-user rate limiting in this group (overrides the user-level rpm_limit fallback).',
+        spendCapDaily: 'Daily Spend Cap (USD)',
+        spendCapMonthly: 'Monthly Spend Cap (USD)',
+        spendCapTimezone: 'Spend Cap Timezone',
+        spendCapTimezonePlaceholder: 'e.g. Asia/Shanghai; empty = system timezone',
+        spendCapHint: 'Total charged spend of all requests in this group per calendar day/month; new requests are rejected with spend_cap_exceeded once reached, and admins are notified at 90%. Empty = unlimited.'
       },
       enterGroupName: 'Enter group name',
       optionalDescription: 'Optional description',
@@ -2322,6 +2347,20 @@ export default {
         searchAccountPlaceholder: 'Search accounts...',
         accountsHint: 'Select accounts to prioritize for this model pattern'
       },
+      modelFallback: {
+        title: 'Model Fallback',
+        tooltip: 'When no account in this group can serve the requested model (e.g. all rate-limited), retry account selection with the configured fallback models in order. Usage records both models and is billed at the served model price.',
+        placeholder: 'claude-opus-4-6 => claude-sonnet-4-6, claude-haiku-4-5',
+        hint: 'One rule per line: requested model (supports trailing *) => fallback models in order. The first matching line wins.',
+        mask: 'Mask fallback model in responses',
+        maskHint: 'When enabled, the response model field is rewritten back to the requested model name.'
+      },
+      promptCacheInject: {
+        title: 'Auto Prompt Caching',
+        tooltip: 'When enabled, requests that carry no cache_control get an ephemeral cache breakpoint on the system prompt and the last tool definition. Small prompts below the minimum cacheable length are left untouched.',
+        enabled: 'Enabled',
+        disabled: 'Disabled'
+      },
       mcpXml: {
         title: 'MCP XML Protocol Injection',
         tooltip: 'When enabled, if the request contains MCP tools, an XML format call protocol prompt will be injected into the system prompt. Disable this to avoid interference with certain clients.',
@@ -3109,6 +3148,24 @@ export default {
       notes: 'Notes',
       notesPlaceholder: 'Enter notes',
       notesHint: 'Notes are optional',
+      labels: 'Labels',
+      labelsPlaceholder: 'e.g. premium, eu',
+      labelsHint: 'Comma-separated. API keys or requests with X-Account-Labels are only routed to accounts carrying all required labels.',
+      scheduleWindows: {
+        title: 'Scheduling Windows',
+        timezonePlaceholder: 'Timezone, e.g. Asia/Shanghai (empty = server timezone)',
+        add: 'Add Window',
+        hint: 'When set, the account is only scheduled inside these windows (HH:MM, end exclusive, 24:00 = end of day). Overlapping windows are merged; in-flight requests finish normally when a window closes.',
+        weekdays: {
+          0: 'Sunday',
+          1: 'Monday',
+          2: 'Tuesday',
+          3: 'Wednesday',
+          4: 'Thursday',
+          5: 'Friday',
+          6: 'Saturday'
+        }
+      },
       allPlatforms: 'All Platforms',
       allTypes: 'All Types',
       allStatus: 'All Status',
@@ -3123,6 +3180,8 @@ export default {
       schedulableEnabled: 'Scheduling enabled',
       schedulableDisabled: 'Scheduling disabled',
       failedToToggleSchedulable: 'Failed to toggle scheduling status',
+      healthProbeFailing: 'Probe failing',
+      healthProbeFailingHint: '{count} consecutive health probe failures, deprioritized in scheduling. Last error: {error} (checked {time})',
       groupCountTotal: '{count} groups total',
       platforms: {
         anthropic: 'Anthropic',
@@ -4691,6 +4750,8 @@ export default {
       billingModePerRequest: 'Per Request',
       billingModeImage: 'Image',
       allBillingModes: 'All Billing Modes',
+      scope: 'Scope',
+      allScopes: 'All Scopes',
       ipAddress: 'IP',
       clickToViewBalance: 'Click to view balance history',
       failedToLoadUser: 'Failed to load user info',
@@ -5010,6 +5071,7 @@ export default {
         },
         loading: 'Loading…',
         requestId: 'Request ID',
+        traceId: 'Trace ID',
         time: 'Time',
         phase: 'Phase',
         status: 'Status',
@@ -5826,6 +5888,8 @@ export default {
         anthropicCacheTTL1hInjectionHint: 'When enabled, existing ephemeral cache_control blocks in Anthropic OAuth/Setup Token request bodies are forced to 1h; response usage is billed back as 5m by default, with account-level TTL billing override taking priority.',
         rewriteMessageCacheControl: 'Rewrite Message Cache Breakpoints',
         rewriteMessageCacheControlHint: 'Default off: preserve client cache_control on message content blocks. When enabled, client breakpoints are stripped and proxy breakpoints are injected for clients that do not manage caching themselves.',
+        rateLimitHeadersPerKey: 'Per-Key Rate-Limit Headers',
+        rateLimitHeadersPerKeyHint: 'Default off: pass through the upstream account\'s rate-limit headers (anthropic-ratelimit-*, x-ratelimit-*), which reveal the shared account\'s usage. When enabled, they are replaced with the API key\'s own quota and rate limits (x-ratelimit-limit-usd, x-ratelimit-remaining-usd, x-ratelimit-limit-usd-5h/1d/7d).',
         antigravityUserAgentVersion: 'Antigravity UA Version',
         antigravityUserAgentVersionPlaceholder: '1.23.2',
         antigravityUserAgentVersionHint: 'Leave empty to use ANTIGRAVITY_USER_AGENT_VERSION or the built-in default 1.23.2; when set, the admin setting takes precedence.',
@@ -818,6 +818,21 @@ export default {
     ipBlacklist: 'IP 黑名单',
     ipBlacklistPlaceholder: '1.2.3.4\n5.6.0.0/16',
     ipBlacklistHint: '每行一个 IP 或 CIDR，这些 IP 将被禁止使用此密钥',
+    accountLabels: '账号标签',
+    accountLabelsPlaceholder: '例如 premium, eu',
+    accountLabelsHint: '逗号分隔。使用此密钥的请求只会调度到同时带有这些标签的账号，客户端还可通过 X-Account-Labels 请求头进一步限定',
+    responseCache: '响应缓存',
+    responseCacheHint: 'temperature 为 0 的相同非流式请求直接返回缓存的响应，命中不计费。客户端也可通过 X-Cache: true 请求头按次启用',
+    scopes: '作用域',
+    scopesHint: '此密钥可调用的端点类别，调用其他端点将返回 403。全部选中表示不限制',
+    scopeNames: {
+      chat: '对话',
+      images: '图片',
+      video: '视频',
+      embeddings: '向量'
+    },
+    costPreview: '费用预览',
+    costPreviewHint: '在 X-Estimated-Cost 响应头中返回每次请求的估算费用。客户端可通过 max_cost 字段或 X-Max-Cost 请求头设置单次请求费用上限，估算超出上限的请求返回 402',
     ipRestrictionEnabled: '已配置 IP 限制',
     ccSwitchNotInstalled:
       'CC-Switch 未安装或协议处理程序未注册。请先安装 CC-Switch 或手动复制 API 密钥。',
@@ -879,6 +894,11 @@ export default {
     costDetails: '成本明细',
     tokenDetails: 'Token 明细',
     cacheTtlOverriddenHint: '缓存 TTL Override 已启用',
+    responseCacheHit: '缓存',
+    responseCacheHitHint: '由响应缓存直接返回，未访问上游，不计费',
+    costOverage: '超出估算',
+    costOverageHint: '实际费用超出请求前估算费用（{estimated}）的配置倍数',
+    pricingUnavailableHint: '该模型未配置价格，仅记录 token，未计费',
     cacheTtlOverriddenLabel: 'TTL 替换',
     cacheTtlOverridden5m: '按 5m 计费',
     cacheTtlOverridden1h: '按 1h 计费',
@@ -2218,6 +2238,11 @@ export default {
         rpmLimit: '每分钟请求数 (RPM)',
         rpmLimitPlaceholder: '0 表示不限制',
         rpmLimitHint: '每用户在本分组每分钟最大请求数，0 = 不限制；一旦设置即接管该用户的限流（覆盖用户级 rpm_limit）',
+        spendCapDaily: '每日消费上限 (USD)',
+        spendCapMonthly: '每月消费上限 (USD)',
+        spendCapTimezone: '消费上限时区',
+        spendCapTimezonePlaceholder: '如 Asia/Shanghai，留空使用系统时区',
+        spendCapHint: '本分组所有请求按自然日/自然月累计的实际扣费；达到上限后新请求返回 spend_cap_exceeded，达到 90% 时通知管理员。留空表示不限制。',
         exclusiveLabel: '专属分组',
         exclusiveHint: '专属分组，可以手动指定给用户',
         platformLabel: '平台限制',
@@ -2406,6 +2431,20 @@ export default {
         searchAccountPlaceholder: '搜索账号...',
         accountsHint: '选择此模型模式优先使用的账号'
       },
+      modelFallback: {
+        title: '模型降级',
+        tooltip: '当分组内没有账号能服务请求模型（如全部限流）时，按配置顺序改用降级模型重新选号。使用记录同时保存请求模型与实际模型，并按实际模型计费。',
+        placeholder: 'claude-opus-4-6 => claude-sonnet-4-6, claude-haiku-4-5',
+        hint: '每行一条规则：请求模型（支持末尾 * 通配）=> 按顺序尝试的降级模型。按行序匹配，第一条命中的规则生效。',
+        mask: '响应中隐藏降级模型',
+        maskHint: '启用后，响应中的 model 字段回写为客户端请求的模型名。'
+      },
+      promptCacheInject: {
+        title: '自动 Prompt 缓存',
+        tooltip: '启用后，对未携带任何 cache_control 的请求，自动在 system prompt 与最后一个工具定义上注入 ephemeral 缓存断点。低于最小可缓存长度的短提示词不做处理。',
+        enabled: '已启用',
+        disabled: '已禁用'
+      },
       mcpXml: {
         title: 'MCP XML 协议注入',
         tooltip: '启用后，当请求包含 MCP 工具时，会在 system prompt 中注入 XML 格式调用协议提示词。关闭此选项可避免对某些客户端造成干扰。',
@@ -3186,6 +3225,24 @@ export default {
       notes: '备注',
       notesPlaceholder: '请输入备注',
       notesHint: '备注可选',
+      labels: '标签',
+      labelsPlaceholder: '例如 premium, eu',
+      labelsHint: '逗号分隔。配置了账号标签的 API 密钥或携带 X-Account-Labels 的请求只会调度到带有全部所需标签的账号',
+      scheduleWindows: {
+        title: '调度时间窗',
+        timezonePlaceholder: '时区，例如 Asia/Shanghai（留空使用服务器时区）',
+        add: '添加时间窗',
+        hint: '配置后账号仅在这些时段内参与调度（HH:MM，结束时间不包含，24:00 表示当天结束）。重叠时段会自动合并；时间窗结束时进行中的请求会正常完成。',
+        weekdays: {
+          0: '周日',
+          1: '周一',
+          2: '周二',
+          3: '周三',
+          4: '周四',
+          5: '周五',
+          6: '周六'
+        }
+      },
       // Filter options
       allPlatforms: '全部平台',
       allTypes: '全部类型',
@@ -3199,6 +3256,8 @@ export default {
       schedulableEnabled: '调度已开启',
       schedulableDisabled: '调度已关闭',
       failedToToggleSchedulable: '切换调度状态失败',
+      healthProbeFailing: '探测失败',
+      healthProbeFailingHint: '健康探测已连续失败 {count} 次，调度时降低优先级。最近错误：{error}（检测于 {time}）',
       groupCountTotal: '共 {count} 个分组',
       columns: {
         name: '名称',
@@ -4844,6 +4903,8 @@ export default {
       billingModePerRequest: '按次',
       billingModeImage: '按次(图片)',
       allBillingModes: '全部计费模式',
+      scope: '作用域',
+      allScopes: '全部作用域',
       ipAddress: 'IP',
       clickToViewBalance: '点击查看充值记录',
       failedToLoadUser: '加载用户信息失败',
@@ -5169,6 +5230,7 @@ export default {
         },
         loading: '加载中…',
         requestId: '请求 ID',
+        traceId: 'Trace ID',
         time: '时间',
         phase: '阶段',
         status: '状态码',
@@ -5980,6 +6042,8 @@ export default {
         anthropicCacheTTL1hInjectionHint: '开启后，对 Anthropic OAuth/Setup Token 请求体中已有的 ephemeral 缓存块强制写入 1h；响应 usage 默认按 5m 回写计费，账号级 TTL 计费设置优先。',
         rewriteMessageCacheControl: '改写消息缓存断点',
         rewriteMessageCacheControlHint: '默认关闭，保留客户端在 messages 内容块中的 cache_control。开启后会清除客户端断点并注入代理断点，适合不自行管理缓存策略的客户端。',
+        rateLimitHeadersPerKey: '限流响应头按 Key 改写',
+        rateLimitHeadersPerKeyHint: '默认关闭，透传上游账号的限流头（anthropic-ratelimit-*、x-ratelimit-*），会暴露共享账号的整体用量。开启后替换为当前 API Key 的额度与限速（x-ratelimit-limit-usd、x-ratelimit-remaining-usd、x-ratelimit-limit-usd-5h/1d/7d）。',
         antigravityUserAgentVersion: 'Antigravity UA 版本',
         antigravityUserAgentVersionPlaceholder: '1.23.2',
         antigravityUserAgentVersionHint: '留空时使用 ANTIGRAVITY_USER_AGENT_VERSION 或内置默认值 1.23.2；填写后后台设置优先。',
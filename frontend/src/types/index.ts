@@ -540,6 +540,14 @@ export interface AdminGroup extends Group {
   // MCP XML 协议注入（仅 antigravity 平台使用）
   mcp_xml_inject: boolean
 
+  // 自动 prompt caching 断点注入（仅 anthropic 平台使用）
+  prompt_cache_inject: boolean
+
+  // 分组日/月消费上限（null = 不限制）及周期边界时区（空串 = 系统时区）
+  spend_cap_daily_usd?: number | null
+  spend_cap_monthly_usd?: number | null
+  spend_cap_timezone?: string
+
   // 支持的模型系列（仅 antigravity 平台使用）
   supported_model_scopes?: string[]
 
@@ -553,6 +561,12 @@ export interface AdminGroup extends Group {
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
   models_list_config?: ModelsListConfig
 
+  // 模型降级规则（请求模型无可用账号时按序尝试）
+  model_fallback_config?: ModelFallbackConfig
+
+  // OpenAI Responses instructions 注入配置
+  instruction_injection_config?: InstructionInjectionConfig
+
   // 分组排序
   sort_order: number
 }
@@ -562,6 +576,26 @@ export interface ModelsListConfig {
   models: string[]
 }
 
+export interface ModelFallbackRule {
+  model: string
+  fallbacks: string[]
+}
+
+export interface ModelFallbackConfig {
+  rules?: ModelFallbackRule[]
+  mask_fallback: boolean
+}
+
+export type InstructionInjectionMode = 'prepend' | 'if_absent' | 'replace'
+
+export interface InstructionInjectionConfig {
+  enabled: boolean
+  mode?: InstructionInjectionMode
+  text?: string
+}
+
+export type ApiKeyScope = 'chat' | 'images' | 'video' | 'embeddings'
+
 export interface ApiKey {
   id: number
   user_id: number
@@ -571,6 +605,11 @@ export interface ApiKey {
   status: 'active' | 'inactive' | 'quota_exhausted' | 'expired'
   ip_whitelist: string[]
   ip_blacklist: string[]
+  account_labels?: string[] // Only accounts carrying all of these labels are scheduled
+  response_cache_enabled?: boolean // Cache deterministic (temperature=0) non-streaming responses
+  cost_preview_enabled?: boolean // Return X-Estimated-Cost on gateway responses
+  scopes?: ApiKeyScope[] // Endpoint scopes this key may call (all scopes for legacy keys)
+  allowed_models?: string[] | null // Models this key may call, trailing * allowed (null = all models)
   last_used_at: string | null
   quota: number // Quota limit in USD (0 = unlimited)
   quota_used: number // Used quota amount in USD
@@ -598,6 +637,11 @@ export interface CreateApiKeyRequest {
   custom_key?: string // Optional custom API Key
   ip_whitelist?: string[]
   ip_blacklist?: string[]
+  account_labels?: string[]
+  response_cache_enabled?: boolean
+  cost_preview_enabled?: boolean
+  scopes?: ApiKeyScope[] // Empty = all scopes
+  allowed_models?: string[] // Empty = all models
   quota?: number // Quota limit in USD (0 = unlimited)
   expires_in_days?: number // Days until expiry (null = never expires)
   rate_limit_5h?: number
@@ -611,6 +655,11 @@ export interface UpdateApiKeyRequest {
   status?: 'active' | 'inactive'
   ip_whitelist?: string[]
   ip_blacklist?: string[]
+  account_labels?: string[] // Empty array clears the selector
+  response_cache_enabled?: boolean
+  cost_preview_enabled?: boolean
+  scopes?: ApiKeyScope[] // Empty array restores all scopes
+  allowed_models?: string[] // Empty array restores all models
   quota?: number // Quota limit in USD (null = no change, 0 = unlimited)
   expires_at?: string | null // Expiration time (null = no change)
   reset_quota?: boolean // Reset quota_used to 0
@@ -640,14 +689,20 @@ export interface CreateGroupRequest {
   fallback_group_id?: number | null
   fallback_group_id_on_invalid_request?: number | null
   mcp_xml_inject?: boolean
+  prompt_cache_inject?: boolean
   supported_model_scopes?: string[]
   models_list_config?: ModelsListConfig
+  model_fallback_config?: ModelFallbackConfig
+  instruction_injection_config?: InstructionInjectionConfig
   allow_messages_dispatch?: boolean
   default_mapped_model?: string
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
   model_routing?: Record<string, number[]> | null
   model_routing_enabled?: boolean
   rpm_limit?: number
+  spend_cap_daily_usd?: number | null
+  spend_cap_monthly_usd?: number | null
+  spend_cap_timezone?: string
   require_oauth_only?: boolean
   require_privacy_set?: boolean
   // 从指定分组复制账号
@@ -675,14 +730,20 @@ export interface UpdateGroupRequest {
   fallback_group_id?: number | null
   fallback_group_id_on_invalid_request?: number | null
   mcp_xml_inject?: boolean
+  prompt_cache_inject?: boolean
   supported_model_scopes?: string[]
   models_list_config?: ModelsListConfig
+  model_fallback_config?: ModelFallbackConfig
+  instruction_injection_config?: InstructionInjectionConfig
   allow_messages_dispatch?: boolean
   default_mapped_model?: string
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
   model_routing?: Record<string, number[]> | null
   model_routing_enabled?: boolean
   rpm_limit?: number
+  spend_cap_daily_usd?: number | null
+  spend_cap_monthly_usd?: number | null
+  spend_cap_timezone?: string
   require_oauth_only?: boolean
   require_privacy_set?: boolean
   copy_accounts_from_group_ids?: number[]
@@ -817,6 +878,13 @@ export interface TempUnschedulableStatus {
   state?: TempUnschedulableState
 }
 
+// Scheduling window: weekday 0=Sunday..6=Saturday, [start, end) in HH:MM local to extra.schedule_timezone
+export interface AccountScheduleWindow {
+  weekday: number
+  start: string
+  end: string
+}
+
 export interface Account {
   id: number
   name: string
@@ -833,6 +901,8 @@ export interface Account {
   extra?: (CodexUsageSnapshot & OpenAICompactState & {
     model_rate_limits?: Record<string, { rate_limited_at: string; rate_limit_reset_at: string }>
     antigravity_credits_overages?: Record<string, { activated_at: string; active_until: string }>
+    schedule_windows?: AccountScheduleWindow[]
+    schedule_timezone?: string
   } & Record<string, unknown>)
   proxy_id: number | null
   proxy_fallback_origin_id?: number | null
@@ -1153,24 +1223,67 @@ export interface AdminDataAccount {
   rate_multiplier?: number | null
   expires_at?: number | null
   auto_pause_on_expired?: boolean
+  fingerprint?: AdminDataFingerprint
+}
+
+export interface AdminDataFingerprint {
+  client_id: string
+  user_agent: string
+  stainless_lang?: string
+  stainless_package_version?: string
+  stainless_os?: string
+  stainless_arch?: string
+  stainless_runtime?: string
+  stainless_runtime_version?: string
 }
 
 export interface AdminDataImportError {
-  kind: 'proxy' | 'account'
+  kind: 'proxy' | 'account' | 'fingerprint'
   name?: string
   proxy_key?: string
   message: string
 }
 
+export interface AdminDataImportItem {
+  name: string
+  platform: AccountPlatform
+  type: AccountType
+  action: 'create' | 'update' | 'skip'
+  existing_id?: number
+}
+
 export interface AdminDataImportResult {
+  dry_run?: boolean
   proxy_created: number
   proxy_reused: number
   proxy_failed: number
   account_created: number
+  account_updated?: number
+  account_skipped?: number
   account_failed: number
+  accounts?: AdminDataImportItem[]
   errors?: AdminDataImportError[]
 }
 
+export interface AdminEncryptedDataBundle {
+  type: string
+  version: number
+  exported_at: string
+  account_count: number
+  kdf: {
+    name: string
+    salt: string
+    n: number
+    r: number
+    p: number
+  }
+  cipher: string
+  nonce: string
+  ciphertext: string
+}
+
+export type AdminDataConflictStrategy = 'skip' | 'overwrite' | 'duplicate'
+
 export interface CodexSessionImportRequest {
   content?: string
   contents?: string[]
@@ -1294,6 +1407,17 @@ export interface UsageLog {
   // Cache TTL Override
   cache_ttl_overridden: boolean
 
+  // 响应缓存命中（未访问上游，不计费）
+  response_cache_hit?: boolean
+
+  // 模型没有可用价格：仅记录 token，费用未知（未计费）
+  pricing_unavailable?: boolean
+
+  // 请求前估算费用（设置 max_cost 或开启费用预览时记录）
+  estimated_cost?: number | null
+  // 实际费用超出估算费用的配置倍数
+  cost_overage?: boolean
+
   // 计费模式
   billing_mode?: string | null
 
@@ -0,0 +1,40 @@
+import type { ModelFallbackConfig, ModelFallbackRule } from '@/types'
+
+// 每行一条规则：requested-model => fallback-a, fallback-b（按行序匹配，第一条命中的规则生效）
+const RULE_SEPARATOR = '=>'
+
+export const formatModelFallbackRules = (
+  config?: Partial<ModelFallbackConfig> | null,
+): string =>
+  (config?.rules ?? [])
+    .map(rule => `${rule.model} ${RULE_SEPARATOR} ${rule.fallbacks.join(', ')}`)
+    .join('\n')
+
+export const parseModelFallbackRules = (text: string): ModelFallbackRule[] => {
+  const rules: ModelFallbackRule[] = []
+  for (const line of text.split('\n')) {
+    const index = line.indexOf(RULE_SEPARATOR)
+    if (index < 0) {
+      continue
+    }
+    const model = line.slice(0, index).trim()
+    const fallbacks = line
+      .slice(index + RULE_SEPARATOR.length)
+      .split(',')
+      .map(item => item.trim())
+      .filter(item => item && item !== model)
+    if (!model || fallbacks.length === 0) {
+      continue
+    }
+    rules.push({ model, fallbacks: [...new Set(fallbacks)] })
+  }
+  return rules
+}
+
+export const buildModelFallbackConfig = (
+  text: string,
+  maskFallback: boolean,
+): ModelFallbackConfig => ({
+  rules: parseModelFallbackRules(text),
+  mask_fallback: maskFallback,
+})